@@ -0,0 +1,82 @@
+// Command backfill fills hand history for hands dealt before it was recorded,
+// from the game server's logs and exported hand.completed stats events, so
+// players' histories and stats aren't empty at launch.
+//
+// Usage:
+//
+//	backfill [-apply] server.log events.jsonl ...
+//	zcat logs/server-*.log.gz | backfill
+//
+// It reads slog text or JSON records and stats event envelopes, one per line,
+// from the files given or stdin. Records for the same hand are merged, and
+// hands already in hand history are skipped, so overlapping logs and repeated
+// runs are safe. Nothing is written unless -apply is passed; a dry run prints
+// every hand it would insert and the report.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/anhbaysgalan1/gp/internal/backfill"
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "insert hands instead of only reporting them")
+	flag.Parse()
+
+	parser := backfill.NewParser()
+	if flag.NArg() == 0 {
+		if err := parser.Read(os.Stdin); err != nil {
+			slog.Error("Failed to read stdin", "error", err)
+			os.Exit(1)
+		}
+	}
+	for _, name := range flag.Args() {
+		if err := readFile(parser, name); err != nil {
+			slog.Error("Failed to read input", "file", name, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	report, err := backfill.NewImporter(db.DB, *apply, os.Stdout).Import(context.Background(), parser)
+	fmt.Println(report)
+	if err != nil {
+		slog.Error("backfill failed", "error", err)
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func readFile(parser *backfill.Parser, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return parser.Read(f)
+}
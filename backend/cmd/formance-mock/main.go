@@ -0,0 +1,70 @@
+// Command formance-mock serves an in-memory Formance ledger v2 API for local
+// development and integration tests.
+//
+// Usage:
+//
+//	formance-mock [-addr :3068] [-ledgers poker-platform-mnt] [-start 2026-01-01T00:00:00Z]
+//	              [-fail-rate 0.1 -fail-status 503 -fail-paths /transactions -seed 42] [-latency 200ms]
+//
+// State lives in memory and is lost on exit; POST /_mock/reset clears it.
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/formancemock"
+)
+
+func main() {
+	addr := flag.String("addr", ":3068", "address to listen on")
+	ledgers := flag.String("ledgers", "poker-platform-mnt", "comma separated ledgers that exist at startup")
+	start := flag.String("start", "", "RFC3339 time to stamp the first transaction with, ticking a second per transaction (default wall clock)")
+	failRate := flag.Float64("fail-rate", 0, "share of API requests to fail, from 0 to 1")
+	failStatus := flag.Int("fail-status", http.StatusInternalServerError, "HTTP status of injected failures")
+	failPaths := flag.String("fail-paths", "", "comma separated path substrings that failures are limited to (default all)")
+	latency := flag.Duration("latency", 0, "delay added to every API request")
+	seed := flag.Int64("seed", 1, "seed for injected failures, so runs repeat")
+	flag.Parse()
+
+	opts := formancemock.Options{
+		Ledgers:    splitList(*ledgers),
+		FailRate:   *failRate,
+		FailStatus: *failStatus,
+		FailPaths:  splitList(*failPaths),
+		Latency:    *latency,
+		Seed:       *seed,
+	}
+	if *start != "" {
+		t, err := time.Parse(time.RFC3339, *start)
+		if err != nil {
+			slog.Error("Invalid -start time", "error", err)
+			os.Exit(2)
+		}
+		opts.Start = t
+	}
+	if opts.FailRate < 0 || opts.FailRate > 1 {
+		slog.Error("-fail-rate must be between 0 and 1", "fail_rate", opts.FailRate)
+		os.Exit(2)
+	}
+
+	slog.Info("Formance mock listening", "addr", *addr, "ledgers", opts.Ledgers, "fail_rate", opts.FailRate, "latency", opts.Latency)
+	if err := http.ListenAndServe(*addr, formancemock.NewServer(opts)); err != nil {
+		slog.Error("Formance mock stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
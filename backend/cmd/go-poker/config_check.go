@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/go-redis/redis/v8"
+)
+
+// runConfigCheck validates the configuration, prints the effective settings
+// with secrets masked and probes each external dependency. It returns an
+// error if anything needed to start the server is wrong.
+func runConfigCheck(out io.Writer) error {
+	cfg, err := config.Load()
+
+	fmt.Fprintln(out, "Effective configuration:")
+	for _, setting := range cfg.Summary() {
+		fmt.Fprintf(out, "  %-26s %s\n", setting.Var, setting.Value)
+	}
+	fmt.Fprintln(out)
+
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return err
+	}
+	fmt.Fprintln(out, "Configuration: ok")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	failed := false
+	report := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Fprintf(out, "%s: FAILED: %v\n", name, err)
+			return
+		}
+		fmt.Fprintf(out, "%s: ok\n", name)
+	}
+
+	report("Database", checkDatabase(ctx, cfg))
+	if cfg.RedisURL == "" {
+		fmt.Fprintln(out, "Redis: skipped (REDIS_URL unset)")
+	} else {
+		report("Redis", checkRedis(ctx, cfg))
+	}
+	if cfg.Sandbox {
+		fmt.Fprintln(out, "Formance: skipped (SANDBOX)")
+	} else {
+		report("Formance", checkFormance(ctx, cfg))
+	}
+
+	if failed {
+		return errors.New("one or more dependencies are unreachable")
+	}
+	return nil
+}
+
+func checkDatabase(ctx context.Context, cfg *config.Config) error {
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func checkRedis(ctx context.Context, cfg *config.Config) error {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	if cfg.RedisPassword != "" {
+		opts.Password = cfg.RedisPassword
+	}
+
+	client := redis.NewClient(opts)
+	defer client.Close()
+	return client.Ping(ctx).Err()
+}
+
+func checkFormance(ctx context.Context, cfg *config.Config) error {
+	_, err := formance.NewClient(cfg).ListAccounts(ctx, 1, "")
+	return err
+}
@@ -1,3 +1,9 @@
+// Command go-poker runs the poker server.
+//
+// Usage:
+//
+//	go-poker                start the server
+//	go-poker config-check   validate configuration and probe dependencies, then exit
 package main
 
 import (
@@ -14,6 +20,13 @@ func main() {
 		slog.Warn("No .env file found, using environment variables")
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "config-check" {
+		if err := runConfigCheck(os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create and start poker server
 	pokerServer, err := server.NewPokerServer()
 	if err != nil {
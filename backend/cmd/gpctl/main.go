@@ -0,0 +1,212 @@
+// Command gpctl runs admin operations against a running server through the
+// admin API, so operators can act during an incident without the frontend.
+//
+// Usage:
+//
+//	gpctl tables list [-status active]
+//	gpctl tables close -table "Table 1" [-reason "..."]
+//	gpctl tables recording -table "Table 1" > recording.json
+//	gpctl users credit -user <id> -amount 5000 -reason "..."
+//	gpctl users debit -user <id> -amount 5000 -reason "..."
+//	gpctl tournaments pause -id <id>
+//	gpctl tournaments resume -id <id>
+//	gpctl ledger reconcile
+//	gpctl flags list
+//	gpctl flags set -key withdrawals -enabled=false [-reason "..."]
+//
+// The API root and an admin's access token come from GPCTL_URL and
+// GPCTL_TOKEN, or the -url and -token flags given before the command.
+// Responses are printed as JSON.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/gpctl"
+)
+
+// errFindings makes gpctl exit with 3 when reconciliation finds problems
+var errFindings = errors.New("ledger reconciliation found problems")
+
+func main() {
+	baseURL := flag.String("url", envOrDefault("GPCTL_URL", gpctl.DefaultBaseURL), "API root, e.g. https://poker.example.com/api/v1")
+	token := flag.String("token", os.Getenv("GPCTL_TOKEN"), "admin access token")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+	if *token == "" {
+		slog.Error("No access token: set GPCTL_TOKEN or pass -token")
+		os.Exit(2)
+	}
+
+	client := gpctl.New(*baseURL, *token)
+	ctx := context.Background()
+	group, command, args := flag.Arg(0), flag.Arg(1), flag.Args()[2:]
+
+	var run func(context.Context, *gpctl.Client, []string) (json.RawMessage, error)
+	switch group + " " + command {
+	case "tables list":
+		run = runTablesList
+	case "tables close":
+		run = runTablesClose
+	case "tables recording":
+		run = runTablesRecording
+	case "users credit":
+		run = adjustBalance(1)
+	case "users debit":
+		run = adjustBalance(-1)
+	case "tournaments pause":
+		run = tournamentRunState((*gpctl.Client).PauseTournament)
+	case "tournaments resume":
+		run = tournamentRunState((*gpctl.Client).ResumeTournament)
+	case "ledger reconcile":
+		run = runReconcile
+	case "flags list":
+		run = runFlagsList
+	case "flags set":
+		run = runFlagsSet
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	result, err := run(ctx, client, args)
+	if result != nil {
+		printJSON(result)
+	}
+	if errors.Is(err, errFindings) {
+		os.Exit(3)
+	}
+	if err != nil {
+		slog.Error("gpctl failed", "command", group+" "+command, "error", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gpctl [-url URL] [-token TOKEN] <tables|users|tournaments|ledger|flags> <command> [flags]")
+	fmt.Fprintln(os.Stderr, "  tables list|close|recording, users credit|debit, tournaments pause|resume, ledger reconcile, flags list|set")
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func printJSON(data json.RawMessage) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		fmt.Println(string(data))
+		return
+	}
+	fmt.Println(out.String())
+}
+
+func runTablesList(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	fs := flag.NewFlagSet("tables list", flag.ExitOnError)
+	status := fs.String("status", "", "only tables with this status, e.g. active")
+	fs.Parse(args)
+
+	return client.ListTables(ctx, *status)
+}
+
+func runTablesClose(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	fs := flag.NewFlagSet("tables close", flag.ExitOnError)
+	table := fs.String("table", "", "name of the running table to close")
+	reason := fs.String("reason", "", "reason shown to the players")
+	fs.Parse(args)
+
+	if *table == "" {
+		return nil, fmt.Errorf("-table is required")
+	}
+	return client.ForceCloseTable(ctx, *table, *reason)
+}
+
+func runTablesRecording(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	fs := flag.NewFlagSet("tables recording", flag.ExitOnError)
+	table := fs.String("table", "", "name of the running table")
+	fs.Parse(args)
+
+	if *table == "" {
+		return nil, fmt.Errorf("-table is required")
+	}
+	return client.TableRecording(ctx, *table)
+}
+
+// adjustBalance credits (sign 1) or debits (sign -1) a user's wallet
+func adjustBalance(sign int64) func(context.Context, *gpctl.Client, []string) (json.RawMessage, error) {
+	return func(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+		fs := flag.NewFlagSet("users", flag.ExitOnError)
+		user := fs.String("user", "", "user ID")
+		amount := fs.Int64("amount", 0, "amount in MNT")
+		reason := fs.String("reason", "", "ticket reference and why the balance is wrong")
+		fs.Parse(args)
+
+		if *user == "" || *amount <= 0 || strings.TrimSpace(*reason) == "" {
+			return nil, fmt.Errorf("-user, a positive -amount and -reason are required")
+		}
+		return client.AdjustBalance(ctx, *user, sign**amount, *reason)
+	}
+}
+
+func tournamentRunState(call func(*gpctl.Client, context.Context, string) (json.RawMessage, error)) func(context.Context, *gpctl.Client, []string) (json.RawMessage, error) {
+	return func(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+		fs := flag.NewFlagSet("tournaments", flag.ExitOnError)
+		id := fs.String("id", "", "tournament ID")
+		fs.Parse(args)
+
+		if *id == "" {
+			return nil, fmt.Errorf("-id is required")
+		}
+		return call(client, ctx, *id)
+	}
+}
+
+func runReconcile(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	result, err := client.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		OK bool `json:"ok"`
+	}
+	if err := json.Unmarshal(result, &report); err != nil {
+		return result, fmt.Errorf("failed to read reconciliation report: %w", err)
+	}
+	if !report.OK {
+		return result, errFindings
+	}
+	return result, nil
+}
+
+func runFlagsList(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	return client.ListFlags(ctx)
+}
+
+func runFlagsSet(ctx context.Context, client *gpctl.Client, args []string) (json.RawMessage, error) {
+	fs := flag.NewFlagSet("flags set", flag.ExitOnError)
+	key := fs.String("key", "", "flag to switch, see gpctl flags list")
+	enabled := fs.Bool("enabled", true, "whether the feature is on")
+	reason := fs.String("reason", "", "why the flag is being switched")
+	fs.Parse(args)
+
+	if *key == "" {
+		return nil, fmt.Errorf("-key is required")
+	}
+	return client.SetFlag(ctx, *key, *enabled, *reason)
+}
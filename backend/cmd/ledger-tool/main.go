@@ -0,0 +1,165 @@
+// Command ledger-tool runs maintenance operations against the Formance ledger.
+//
+// Usage:
+//
+//	ledger-tool backfill [-apply]
+//	ledger-tool migrate-accounts -from "session:{user}:{session}" -to "session:{user}:game:{session}" [-apply]
+//	ledger-tool replay-metadata -file fixes.jsonl [-apply]
+//	ledger-tool verify
+//
+// Operations that write to the ledger are dry runs unless -apply is passed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/ledgertool"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	ctx := context.Background()
+	command, args := os.Args[1], os.Args[2:]
+
+	switch command {
+	case "backfill":
+		err = runBackfill(ctx, cfg, args)
+	case "migrate-accounts":
+		err = runMigrateAccounts(ctx, cfg, args)
+	case "replay-metadata":
+		err = runReplayMetadata(ctx, cfg, args)
+	case "verify":
+		err = runVerify(ctx, cfg, args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		slog.Error("ledger-tool failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ledger-tool <backfill|migrate-accounts|replay-metadata|verify> [flags]")
+}
+
+func connect(cfg *config.Config) (*gorm.DB, error) {
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return db.DB, nil
+}
+
+func runBackfill(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	apply := fs.Bool("apply", false, "post transactions instead of only reporting them")
+	fs.Parse(args)
+
+	db, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+
+	tool := ledgertool.New(formance.NewClient(cfg), db, *apply, os.Stdout)
+	report, err := tool.Backfill(ctx)
+	fmt.Println(report)
+	return err
+}
+
+func runMigrateAccounts(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("migrate-accounts", flag.ExitOnError)
+	from := fs.String("from", "", "current account template, e.g. session:{user}:{session}")
+	to := fs.String("to", "", "new account template using the same placeholders")
+	apply := fs.Bool("apply", false, "move balances instead of only reporting them")
+	fs.Parse(args)
+
+	fromTemplate, err := ledgertool.ParseAccountTemplate(*from)
+	if err != nil {
+		return err
+	}
+	toTemplate, err := ledgertool.ParseAccountTemplate(*to)
+	if err != nil {
+		return err
+	}
+
+	tool := ledgertool.New(formance.NewClient(cfg), nil, *apply, os.Stdout)
+	report, err := tool.MigrateAccounts(ctx, fromTemplate, toTemplate)
+	fmt.Println(report)
+	return err
+}
+
+func runReplayMetadata(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("replay-metadata", flag.ExitOnError)
+	file := fs.String("file", "", "JSON lines file of {\"transaction_id\":N,\"metadata\":{...}} fixes")
+	apply := fs.Bool("apply", false, "write metadata instead of only reporting it")
+	fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *file, err)
+	}
+	defer f.Close()
+
+	tool := ledgertool.New(formance.NewClient(cfg), nil, *apply, os.Stdout)
+	report, err := tool.ReplayMetadata(ctx, f)
+	fmt.Println(report)
+	return err
+}
+
+func runVerify(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	skipDB := fs.Bool("ledger-only", false, "skip checks against game sessions in the database")
+	fs.Parse(args)
+
+	var db *gorm.DB
+	if !*skipDB {
+		var err error
+		if db, err = connect(cfg); err != nil {
+			return err
+		}
+	}
+
+	tool := ledgertool.New(formance.NewClient(cfg), db, false, os.Stdout)
+	findings, err := tool.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("%d invariant violations found", len(findings))
+	}
+	fmt.Println("verify: all invariants hold")
+	return nil
+}
@@ -0,0 +1,87 @@
+// Command seed provisions demo data for a development or staging
+// environment: an admin and players with funded wallets, cash tables across
+// the stakes, an upcoming scheduled tournament and a finished one with
+// results.
+//
+// Usage:
+//
+//	seed [-apply] [-env staging] [-password secret]
+//
+// The environment defaults to ENVIRONMENT and decides how much is seeded;
+// production is refused. Demo accounts share one password, which must be
+// given outside development. Records that already exist are left alone, so
+// it is safe to run again. Nothing is written unless -apply is passed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/seed"
+	"github.com/joho/godotenv"
+)
+
+// developmentPassword is the demo password on developer machines
+const developmentPassword = "DemoPassword1!"
+
+func main() {
+	apply := flag.Bool("apply", false, "create the demo data instead of only reporting it")
+	env := flag.String("env", "", "environment to seed (default: ENVIRONMENT)")
+	password := flag.String("password", os.Getenv("SEED_PASSWORD"), "password for every demo account (default: SEED_PASSWORD)")
+	noLedger := flag.Bool("no-ledger", false, "create demo accounts without funding their wallets")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	if *env == "" {
+		*env = cfg.Environment
+	}
+
+	dataset, err := seed.ForEnvironment(*env)
+	if err != nil {
+		slog.Error("Cannot seed environment", "environment", *env, "error", err)
+		os.Exit(1)
+	}
+	if *password == "" {
+		if *env != "development" {
+			slog.Error("A demo password is required outside development: pass -password or set SEED_PASSWORD")
+			os.Exit(1)
+		}
+		*password = developmentPassword
+	}
+
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	var ledger *formance.Client
+	if !*noLedger {
+		ledger = formance.NewClient(cfg)
+	}
+
+	report, err := seed.New(db.DB, ledger, *password, *apply, os.Stdout).Run(context.Background(), dataset)
+	fmt.Println(report)
+	if err != nil {
+		slog.Error("seed failed", "error", err)
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,63 @@
+// Command table-replay replays a table's flight recording against a fresh
+// game, dealing each recorded hand again from its snapshot and applying the
+// recorded actions, to reproduce a bug away from the running server.
+//
+// Usage:
+//
+//	gpctl tables recording -table "Table 1" > recording.json
+//	table-replay recording.json
+//	table-replay < recordings/Table_1-1a2b3c4d.jsonl
+//
+// It reads a dump from the admin API or a file written by the flight
+// recorder's file sink, and prints what it replayed as JSON. It exits with 3
+// when the replay diverges from the recording.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
+)
+
+func main() {
+	var in io.Reader = os.Stdin
+	switch len(os.Args) {
+	case 1:
+	case 2:
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			slog.Error("Failed to open recording", "error", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	default:
+		fmt.Fprintln(os.Stderr, "usage: table-replay [recording.json]")
+		os.Exit(2)
+	}
+
+	recording, err := flightrecorder.ReadRecording(in)
+	if err != nil {
+		slog.Error("Failed to read recording", "error", err)
+		os.Exit(1)
+	}
+
+	result, err := flightrecorder.Replay(recording)
+	if errors.Is(err, flightrecorder.ErrNoSnapshot) {
+		slog.Warn("Nothing to replay: record a longer stretch of play", "entries", len(recording.Entries))
+	} else if err != nil {
+		slog.Error("Replay failed", "error", err)
+		os.Exit(1)
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+	if result.Divergence != nil {
+		os.Exit(3)
+	}
+}
@@ -53,6 +53,7 @@ type PotView struct {
 	Amount          int64       `json:"amount"`
 	EligiblePlayers []uuid.UUID `json:"eligible_players"`
 	WinningPlayers  []uuid.UUID `json:"winning_players,omitempty"`
+	LowPlayers      []uuid.UUID `json:"low_players,omitempty"` // Hi-lo games: the winners of the low half
 	IsSidePot       bool        `json:"is_side_pot"`
 }
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/anhbaysgalan1/gp/internal/application/dto"
 	"github.com/anhbaysgalan1/gp/internal/engine/domain/aggregates"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
 	"github.com/anhbaysgalan1/gp/internal/engine/domain/table"
 	"github.com/anhbaysgalan1/gp/internal/engine/repositories"
 	"github.com/google/uuid"
@@ -35,6 +36,9 @@ func (ch *CommandHandler) CreateTable(ctx context.Context, cmd dto.CreateTableCo
 	if cmd.SmallBlind <= 0 || cmd.BigBlind <= cmd.SmallBlind {
 		return nil, fmt.Errorf("invalid blind structure")
 	}
+	if _, err := game.ParseVariant(string(cmd.Config.GameType)); err != nil {
+		return nil, fmt.Errorf("invalid game type %q: %w", cmd.Config.GameType, err)
+	}
 
 	// Create new table aggregate
 	tableID := uuid.New()
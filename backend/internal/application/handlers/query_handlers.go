@@ -112,6 +112,7 @@ func (qh *QueryHandler) convertToGameStateView(tableAggregate *aggregates.TableA
 			Amount:          pot.Amount,
 			EligiblePlayers: pot.EligiblePlayers,
 			WinningPlayers:  pot.WinningPlayers,
+			LowPlayers:      pot.LowPlayers,
 			IsSidePot:       pot.IsSidePot,
 		}
 	}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// MaxImpersonationDuration caps how long an impersonation token lasts
+const MaxImpersonationDuration = time.Hour
+
+// impersonationAudience marks impersonation tokens so ValidateToken never
+// accepts them as the user's own
+const impersonationAudience = "impersonation"
+
+const (
+	ImpersonatorIDKey  contextKey = "impersonator_id"
+	ImpersonationIDKey contextKey = "impersonation_id"
+)
+
+// ImpersonationClaims let an admin see the API as the user does, read-only,
+// for one audited impersonation session
+type ImpersonationClaims struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	ImpersonatorID uuid.UUID `json:"impersonator_id"`
+	SessionID      uuid.UUID `json:"session_id"`
+	jwt.RegisteredClaims
+}
+
+// ImpersonationAuditor decides whether an impersonation session is still
+// open and records every request made under it
+type ImpersonationAuditor interface {
+	ImpersonationActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	RecordImpersonatedRequest(ctx context.Context, sessionID uuid.UUID, method, path string, status int, blocked bool)
+}
+
+// GenerateImpersonationToken issues a token for an impersonation session
+// that expires at expiresAt
+func (manager *JWTManager) GenerateImpersonationToken(userID uuid.UUID, username, email string, impersonatorID, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := ImpersonationClaims{
+		UserID:         userID,
+		Username:       username,
+		Email:          email,
+		ImpersonatorID: impersonatorID,
+		SessionID:      sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    manager.issuer,
+			Audience:  jwt.ClaimStrings{impersonationAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(manager.secretKey)
+}
+
+// ValidateImpersonationToken checks a token from GenerateImpersonationToken
+func (manager *JWTManager) ValidateImpersonationToken(tokenString string) (*ImpersonationClaims, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&ImpersonationClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return manager.secretKey, nil
+		},
+		jwt.WithAudience(impersonationAudience),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid impersonation token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*ImpersonationClaims)
+	if !ok || !token.Valid || claims.ImpersonatorID == uuid.Nil || claims.SessionID == uuid.Nil {
+		return nil, fmt.Errorf("invalid impersonation token claims")
+	}
+
+	return claims, nil
+}
+
+// SetImpersonationAuditor enables impersonation tokens. Without an auditor
+// they are refused, since their use couldn't be recorded.
+func (m *AuthMiddleware) SetImpersonationAuditor(auditor ImpersonationAuditor) {
+	m.impersonation = auditor
+}
+
+// serveImpersonated handles a request made with an impersonation token. Only
+// reads are let through, and every request is recorded, blocked or not.
+func (m *AuthMiddleware) serveImpersonated(w http.ResponseWriter, r *http.Request, next http.Handler, claims *ImpersonationClaims) {
+	if m.impersonation == nil {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	ctx := r.Context()
+	active, err := m.impersonation.ImpersonationActive(ctx, claims.SessionID)
+	if err != nil {
+		slog.Error("Failed to check impersonation session", "session_id", claims.SessionID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check impersonation session")
+		return
+	}
+	if !active {
+		writeErrorResponse(w, http.StatusUnauthorized, "Impersonation session has ended")
+		return
+	}
+
+	// Anything that changes state, money movements above all, stays with the user
+	if !isReadOnlyMethod(r.Method) {
+		m.impersonation.RecordImpersonatedRequest(ctx, claims.SessionID, r.Method, r.URL.Path, http.StatusForbidden, true)
+		writeErrorResponse(w, http.StatusForbidden, "Impersonation is read-only")
+		return
+	}
+
+	ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+	ctx = context.WithValue(ctx, EmailKey, claims.Email)
+	ctx = context.WithValue(ctx, ImpersonatorIDKey, claims.ImpersonatorID)
+	ctx = context.WithValue(ctx, ImpersonationIDKey, claims.SessionID)
+
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	next.ServeHTTP(ww, r.WithContext(ctx))
+
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	m.impersonation.RecordImpersonatedRequest(ctx, claims.SessionID, r.Method, r.URL.Path, status, false)
+}
+
+// GetImpersonatorIDFromContext returns the admin behind an impersonated
+// request. ok is false for requests made by the user themselves.
+func GetImpersonatorIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	impersonatorID, ok := ctx.Value(ImpersonatorIDKey).(uuid.UUID)
+	return impersonatorID, ok
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isImpersonationToken(claims *Claims) bool {
+	return slices.Contains(claims.Audience, impersonationAudience)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/anhbaysgalan1/gp/internal/models"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -11,12 +12,17 @@ import (
 type JWTManager struct {
 	secretKey []byte
 	issuer    string
+	sessions  SessionPolicy
 }
 
 type Claims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
+	Role     models.UserRole  `json:"role,omitempty"`
+	// When the user signed in; refreshed tokens keep it, so the session
+	// can't be slid past its maximum lifetime
+	SessionStart *jwt.NumericDate `json:"session_start,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -24,24 +30,15 @@ func NewJWTManager(secretKey, issuer string) *JWTManager {
 	return &JWTManager{
 		secretKey: []byte(secretKey),
 		issuer:    issuer,
+		sessions:  defaultSessionPolicy,
 	}
 }
 
+// GenerateToken starts a new session for a user whose role isn't known,
+// which gets the default idle timeout
 func (manager *JWTManager) GenerateToken(userID uuid.UUID, username, email string) (string, error) {
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    manager.issuer,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(manager.secretKey)
+	token, _, err := manager.GenerateSessionToken(userID, username, email, "", time.Now())
+	return token, err
 }
 
 func (manager *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
@@ -61,7 +58,7 @@ func (manager *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || isReconnectTicket(claims) || isImpersonationToken(claims) || isOAuthSignupToken(claims) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
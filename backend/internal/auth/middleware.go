@@ -3,8 +3,11 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
@@ -16,10 +19,13 @@ const (
 	UserIDKey   contextKey = "user_id"
 	UsernameKey contextKey = "username"
 	EmailKey    contextKey = "email"
+	ClaimsKey   contextKey = "claims"
 )
 
 type AuthMiddleware struct {
-	jwtManager *JWTManager
+	jwtManager    *JWTManager
+	impersonation ImpersonationAuditor
+	revocations   SessionRevocations
 }
 
 func NewAuthMiddleware(jwtManager *JWTManager) *AuthMiddleware {
@@ -44,19 +50,66 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 
 		claims, err := m.jwtManager.ValidateToken(tokenString)
 		if err != nil {
+			if impersonation, err := m.jwtManager.ValidateImpersonationToken(tokenString); err == nil {
+				m.serveImpersonated(w, r, next, impersonation)
+				return
+			}
 			writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
 			return
 		}
 
+		if err := m.CheckSession(r.Context(), claims); err != nil {
+			if errors.Is(err, ErrSessionRevoked) || errors.Is(err, ErrAccountDisabled) {
+				writeErrorResponse(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			slog.Error("Failed to check session", "user_id", claims.UserID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to check session")
+			return
+		}
+
+		m.slideSession(w, claims)
+
 		// Add user info to context
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, UsernameKey, claims.Username)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
+		ctx = context.WithValue(ctx, ClaimsKey, claims)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// slideSession renews the token of an active user once it is half way to
+// expiring, and tells the client when the session ends either way
+func (m *AuthMiddleware) slideSession(w http.ResponseWriter, claims *Claims) {
+	if claims.ExpiresAt == nil {
+		return
+	}
+	expiresAt := claims.ExpiresAt.Time
+	if m.jwtManager.ShouldRefresh(claims, time.Now()) {
+		token, refreshedExpiry, err := m.jwtManager.RefreshToken(claims)
+		if err == nil {
+			w.Header().Set(RefreshedTokenHeader, token)
+			expiresAt = refreshedExpiry
+		} else if !errors.Is(err, ErrSessionExpired) {
+			slog.Warn("Failed to refresh session token", "user_id", claims.UserID, "error", err)
+		}
+	}
+
+	w.Header().Set(SessionExpiresHeader, expiresAt.UTC().Format(time.RFC3339))
+	if end, ok := m.jwtManager.SessionEnd(claims.StartedAt()); ok {
+		w.Header().Set(SessionMaxExpiresHeader, end.UTC().Format(time.RFC3339))
+	}
+}
+
+// GetClaimsFromContext returns the claims of the token a request was
+// authenticated with. ok is false for impersonated requests.
+func GetClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(ClaimsKey).(*Claims)
+	return claims, ok
+}
+
 // Helper function for consistent error responses
 func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -71,7 +124,7 @@ func (m *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
 		if authHeader != "" {
 			tokenString := m.jwtManager.ExtractTokenFromBearer(authHeader)
 			if tokenString != "" {
-				if claims, err := m.jwtManager.ValidateToken(tokenString); err == nil {
+				if claims, err := m.jwtManager.ValidateToken(tokenString); err == nil && m.CheckSession(r.Context(), claims) == nil {
 					ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 					ctx = context.WithValue(ctx, UsernameKey, claims.Username)
 					ctx = context.WithValue(ctx, EmailKey, claims.Email)
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OAuthSignupTTL is how long a player has to pick a username after signing
+// in with a provider for the first time
+const OAuthSignupTTL = 15 * time.Minute
+
+// oauthSignupAudience marks signup tokens so they can't be used as access
+// tokens, nor access tokens as signup tokens
+const oauthSignupAudience = "oauth_signup"
+
+// OAuthSignupClaims carry a provider's verified identity until the player
+// has picked a username
+type OAuthSignupClaims struct {
+	Provider      string `json:"provider"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+// GenerateOAuthSignupToken issues a short-lived token for creating the
+// account of a provider's user, identified by subject, and returns when it
+// expires
+func (manager *JWTManager) GenerateOAuthSignupToken(provider, subject, email string, emailVerified bool) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(OAuthSignupTTL)
+	claims := OAuthSignupClaims{
+		Provider:      provider,
+		Email:         email,
+		EmailVerified: emailVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    manager.issuer,
+			Audience:  jwt.ClaimStrings{oauthSignupAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(manager.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateOAuthSignupToken checks a token from GenerateOAuthSignupToken
+func (manager *JWTManager) ValidateOAuthSignupToken(signupToken string) (*OAuthSignupClaims, error) {
+	token, err := jwt.ParseWithClaims(
+		signupToken,
+		&OAuthSignupClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return manager.secretKey, nil
+		},
+		jwt.WithAudience(oauthSignupAudience),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid signup token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*OAuthSignupClaims)
+	if !ok || !token.Valid || claims.Provider == "" || claims.Subject == "" {
+		return nil, fmt.Errorf("invalid signup token claims")
+	}
+
+	return claims, nil
+}
+
+func isOAuthSignupToken(claims *Claims) bool {
+	return slices.Contains(claims.Audience, oauthSignupAudience)
+}
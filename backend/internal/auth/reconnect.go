@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ReconnectTicketTTL is how long a reconnect ticket can be used. Tickets are
+// fetched right before connecting, so they are kept short.
+const ReconnectTicketTTL = 2 * time.Minute
+
+// reconnectAudience marks reconnect tickets so they can't be used as access
+// tokens, nor access tokens as tickets
+const reconnectAudience = "reconnect"
+
+// ReconnectClaims let a user open a WebSocket straight into one table
+type ReconnectClaims struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Table    string    `json:"table"`
+	jwt.RegisteredClaims
+}
+
+// GenerateReconnectTicket issues a short-lived ticket for rejoining the named
+// table, and returns when it expires
+func (manager *JWTManager) GenerateReconnectTicket(userID uuid.UUID, username, table string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ReconnectTicketTTL)
+	claims := ReconnectClaims{
+		UserID:   userID,
+		Username: username,
+		Table:    table,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    manager.issuer,
+			Audience:  jwt.ClaimStrings{reconnectAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	ticket, err := token.SignedString(manager.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return ticket, expiresAt, nil
+}
+
+// ValidateReconnectTicket checks a ticket from GenerateReconnectTicket
+func (manager *JWTManager) ValidateReconnectTicket(ticket string) (*ReconnectClaims, error) {
+	token, err := jwt.ParseWithClaims(
+		ticket,
+		&ReconnectClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return manager.secretKey, nil
+		},
+		jwt.WithAudience(reconnectAudience),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid reconnect ticket: %w", err)
+	}
+
+	claims, ok := token.Claims.(*ReconnectClaims)
+	if !ok || !token.Valid || claims.Table == "" {
+		return nil, fmt.Errorf("invalid reconnect ticket claims")
+	}
+
+	return claims, nil
+}
+
+// SessionClaims returns the ticket as the claims of the session it came from,
+// for CheckSession. Tickets are only issued to live sessions, so one issued
+// before the user's sessions were revoked is revoked with them.
+func (c *ReconnectClaims) SessionClaims() *Claims {
+	return &Claims{
+		UserID:   c.UserID,
+		Username: c.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt: c.IssuedAt,
+		},
+	}
+}
+
+func isReconnectTicket(claims *Claims) bool {
+	return slices.Contains(claims.Audience, reconnectAudience)
+}
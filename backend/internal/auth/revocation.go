@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionRevoked is returned for a token from a sign-in that has since
+// been revoked, e.g. by a password reset; the user has to sign in again
+var ErrSessionRevoked = errors.New("session has been revoked, sign in again")
+
+// ErrAccountDisabled is returned for a token of a user whose account has been
+// disabled, e.g. merged into another; none of their sessions work any more
+var ErrAccountDisabled = errors.New("account has been disabled")
+
+// SessionRevocations says when a user's sign-ins were last revoked, nil if
+// they never were. It returns ErrAccountDisabled for a disabled account.
+type SessionRevocations interface {
+	SessionsRevokedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+}
+
+// SetSessionRevocations has the middleware refuse tokens from sign-ins that
+// started before the user's sessions were revoked
+func (m *AuthMiddleware) SetSessionRevocations(revocations SessionRevocations) {
+	m.revocations = revocations
+}
+
+// CheckSession returns ErrSessionRevoked when the sign-in the claims belong
+// to has been revoked, and ErrAccountDisabled when the user's account has
+func (m *AuthMiddleware) CheckSession(ctx context.Context, claims *Claims) error {
+	if m.revocations == nil {
+		return nil
+	}
+	revokedAt, err := m.revocations.SessionsRevokedAt(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if SessionRevoked(claims, revokedAt) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// SessionRevoked reports whether the claims' sign-in started before
+// revokedAt. Tokens only keep whole seconds, so a sign-in in the same
+// second as the revocation still counts as after it.
+func SessionRevoked(claims *Claims, revokedAt *time.Time) bool {
+	return revokedAt != nil && claims.StartedAt().Before(revokedAt.Truncate(time.Second))
+}
@@ -76,6 +76,11 @@ func (rm *RoleMiddleware) RequireModerator(next http.Handler) http.Handler {
 	return rm.RequireRole(models.UserRoleMod, models.UserRoleAdmin)(next)
 }
 
+// RequireFinance is a convenience method for finance staff and admin endpoints
+func (rm *RoleMiddleware) RequireFinance(next http.Handler) http.Handler {
+	return rm.RequireRole(models.UserRoleFinance, models.UserRoleAdmin)(next)
+}
+
 // GetUserRoleFromContext retrieves the user role from the request context
 func GetUserRoleFromContext(ctx context.Context) (models.UserRole, bool) {
 	role, ok := ctx.Value("user_role").(models.UserRole)
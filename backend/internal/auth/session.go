@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Headers the auth middleware uses to tell clients when their session ends,
+// so they can warn before logging the user out
+const (
+	// SessionExpiresHeader is when the access token expires unless the user
+	// is active before then, RFC 3339
+	SessionExpiresHeader = "X-Session-Expires-At"
+	// SessionMaxExpiresHeader is when the session ends however active the
+	// user is, RFC 3339. Not sent when sessions have no maximum lifetime.
+	SessionMaxExpiresHeader = "X-Session-Max-Expires-At"
+	// RefreshedTokenHeader carries a renewed access token, which the client
+	// should use from then on
+	RefreshedTokenHeader = "X-Refreshed-Token"
+)
+
+// ErrSessionExpired is returned when refreshing a session that has reached
+// its maximum lifetime; the user has to sign in again
+var ErrSessionExpired = errors.New("session has expired, sign in again")
+
+// SessionPolicy is how long a sign-in lasts. Access tokens expire after the
+// idle timeout for the user's role and are renewed while they are used,
+// until the session reaches its maximum lifetime.
+type SessionPolicy struct {
+	IdleTimeout      time.Duration
+	RoleIdleTimeouts map[models.UserRole]time.Duration // Overrides for roles, e.g. shorter for admins
+	MaxLifetime      time.Duration                     // From sign-in, 0 for no limit
+}
+
+// defaultSessionPolicy keeps day-long tokens with no sliding, until a policy
+// is configured
+var defaultSessionPolicy = SessionPolicy{IdleTimeout: 24 * time.Hour}
+
+// SetSessionPolicy sets how long sessions last from now on. Tokens already
+// issued keep their expiry.
+func (manager *JWTManager) SetSessionPolicy(policy SessionPolicy) {
+	manager.sessions = policy
+}
+
+// IdleTimeoutFor is how long a token for the role lasts without activity
+func (p SessionPolicy) IdleTimeoutFor(role models.UserRole) time.Duration {
+	if timeout, ok := p.RoleIdleTimeouts[role]; ok && timeout > 0 {
+		return timeout
+	}
+	return p.IdleTimeout
+}
+
+// SessionEnd is when a session started at start ends however active the
+// user is. ok is false when sessions have no maximum lifetime.
+func (p SessionPolicy) SessionEnd(start time.Time) (end time.Time, ok bool) {
+	if p.MaxLifetime <= 0 {
+		return time.Time{}, false
+	}
+	return start.Add(p.MaxLifetime), true
+}
+
+// tokenExpiry is when a token issued now for the role expires: after the
+// idle timeout, or at the end of the session if that comes first
+func (p SessionPolicy) tokenExpiry(role models.UserRole, start, now time.Time) time.Time {
+	expiry := now.Add(p.IdleTimeoutFor(role))
+	if end, ok := p.SessionEnd(start); ok && end.Before(expiry) {
+		return end
+	}
+	return expiry
+}
+
+// GenerateSessionToken issues an access token for a session that started at
+// sessionStart, and returns when it expires
+func (manager *JWTManager) GenerateSessionToken(userID uuid.UUID, username, email string, role models.UserRole, sessionStart time.Time) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := manager.sessions.tokenExpiry(role, sessionStart, now)
+	claims := Claims{
+		UserID:       userID,
+		Username:     username,
+		Email:        email,
+		Role:         role,
+		SessionStart: jwt.NewNumericDate(sessionStart),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    manager.issuer,
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(manager.secretKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// RefreshToken issues a new access token for the same session, with a fresh
+// idle timeout. It fails with ErrSessionExpired once the session has reached
+// its maximum lifetime.
+func (manager *JWTManager) RefreshToken(claims *Claims) (string, time.Time, error) {
+	start := claims.StartedAt()
+	if end, ok := manager.sessions.SessionEnd(start); ok && !time.Now().Before(end) {
+		return "", time.Time{}, ErrSessionExpired
+	}
+	return manager.GenerateSessionToken(claims.UserID, claims.Username, claims.Email, claims.Role, start)
+}
+
+// ShouldRefresh reports whether a token is far enough through its idle
+// timeout to be renewed on activity, which keeps renewals to about one per
+// half timeout. Tokens that already run to the end of their session aren't.
+func (manager *JWTManager) ShouldRefresh(claims *Claims, now time.Time) bool {
+	if claims.ExpiresAt == nil {
+		return false
+	}
+	if end, ok := manager.sessions.SessionEnd(claims.StartedAt()); ok && !claims.ExpiresAt.Time.Before(end) {
+		return false
+	}
+	return claims.ExpiresAt.Time.Sub(now) < manager.sessions.IdleTimeoutFor(claims.Role)/2
+}
+
+// SessionEnd is when a session started at start ends however active the
+// user is. ok is false when sessions have no maximum lifetime.
+func (manager *JWTManager) SessionEnd(start time.Time) (time.Time, bool) {
+	return manager.sessions.SessionEnd(start)
+}
+
+// StartedAt is when the user signed in. Tokens issued before sessions were
+// tracked started when they were issued.
+func (c *Claims) StartedAt() time.Time {
+	if c.SessionStart != nil {
+		return c.SessionStart.Time
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Now()
+}
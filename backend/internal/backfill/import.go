@@ -0,0 +1,214 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lookupBatch is the number of hand IDs checked against the database at once
+const lookupBatch = 500
+
+// Report summarises a backfill
+type Report struct {
+	Lines      int
+	Records    int
+	Duplicates int // Records repeated in the input
+	Unreadable int
+	Hands      int // Distinct hand IDs found
+	Existing   int // Already in hand history
+	Incomplete int // No table or time to file the hand under
+	Inserted   int // Or, in a dry run, would be inserted
+	Failed     int
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("backfill: lines=%d records=%d duplicates=%d unreadable=%d hands=%d existing=%d incomplete=%d inserted=%d failed=%d",
+		r.Lines, r.Records, r.Duplicates, r.Unreadable, r.Hands, r.Existing, r.Incomplete, r.Inserted, r.Failed)
+}
+
+// Importer writes parsed hands into hand history. Without apply it only
+// reports what it would insert.
+type Importer struct {
+	db    *gorm.DB
+	apply bool
+	out   io.Writer
+}
+
+// NewImporter creates an importer
+func NewImporter(db *gorm.DB, apply bool, out io.Writer) *Importer {
+	return &Importer{db: db, apply: apply, out: out}
+}
+
+// Import inserts every hand the parser found that isn't already in hand
+// history. Hands already there, live-recorded or from an earlier run, are
+// left alone, so re-running over the same or overlapping logs is safe.
+func (im *Importer) Import(ctx context.Context, parser *Parser) (Report, error) {
+	hands := parser.Hands()
+	report := Report{
+		Lines:      parser.Lines,
+		Records:    parser.Records,
+		Duplicates: parser.Duplicates,
+		Unreadable: parser.Unreadable,
+		Hands:      len(hands),
+	}
+
+	existing, err := im.existingHands(ctx, hands)
+	if err != nil {
+		return report, err
+	}
+	tableIDs, err := im.tableIDs(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, hand := range hands {
+		if existing[hand.HandID] {
+			report.Existing++
+			continue
+		}
+		if hand.TableID == uuid.Nil {
+			hand.TableID = tableIDs[hand.TableName]
+		}
+
+		history, err := handHistory(hand)
+		if err != nil {
+			report.Incomplete++
+			im.logf("skip %s: %v", hand.HandID, err)
+			continue
+		}
+
+		im.logf("insert %s table=%q started=%s pot=%d winners=%d voided=%t",
+			hand.HandID, hand.TableName, history.StartedAt.Format("2006-01-02T15:04:05Z07:00"), hand.TotalPot, len(hand.Winners), hand.Voided)
+		if !im.apply {
+			report.Inserted++
+			continue
+		}
+		if err := im.insert(ctx, history, hand.Winners); err != nil {
+			report.Failed++
+			im.logf("failed %s: %v", hand.HandID, err)
+			continue
+		}
+		report.Inserted++
+	}
+	return report, nil
+}
+
+// existingHands returns which of the hands are already in hand history,
+// counting deleted rows so a purge isn't undone
+func (im *Importer) existingHands(ctx context.Context, hands []Hand) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for start := 0; start < len(hands); start += lookupBatch {
+		end := start + lookupBatch
+		if end > len(hands) {
+			end = len(hands)
+		}
+		ids := make([]string, 0, end-start)
+		for _, hand := range hands[start:end] {
+			ids = append(ids, hand.HandID)
+		}
+
+		var found []string
+		err := im.db.WithContext(ctx).Unscoped().Model(&models.HandHistory{}).
+			Where("hand_id IN ?", ids).Pluck("hand_id", &found).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing hands: %w", err)
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// tableIDs maps table names to IDs, for hands only logged by table name
+func (im *Importer) tableIDs(ctx context.Context) (map[string]uuid.UUID, error) {
+	var tables []models.PokerTable
+	if err := im.db.WithContext(ctx).Unscoped().Select("id", "name").Find(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tables: %w", err)
+	}
+	ids := make(map[string]uuid.UUID, len(tables))
+	for _, table := range tables {
+		ids[table.Name] = table.ID
+	}
+	return ids, nil
+}
+
+// insert writes the hand and lets each winner read it. Another writer may
+// have recorded the hand meanwhile, in which case it is kept.
+func (im *Importer) insert(ctx context.Context, history *models.HandHistory, winners []models.HandWinner) error {
+	return im.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(history).Error; err != nil {
+			return fmt.Errorf("failed to insert hand history: %w", err)
+		}
+
+		var presence []models.HandPresence
+		for _, winner := range winners {
+			if winner.UserID != uuid.Nil {
+				presence = append(presence, models.HandPresence{HandID: history.HandID, UserID: winner.UserID})
+			}
+		}
+		if len(presence) == 0 {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&presence).Error; err != nil {
+			return fmt.Errorf("failed to insert hand presence: %w", err)
+		}
+		return nil
+	})
+}
+
+// logf writes a progress line, prefixed so dry runs are obvious in the output
+func (im *Importer) logf(format string, args ...interface{}) {
+	prefix := "[dry-run] "
+	if im.apply {
+		prefix = ""
+	}
+	fmt.Fprintf(im.out, prefix+format+"\n", args...)
+}
+
+// handHistory builds the row for a hand. A hand seen only at its end is
+// filed under its end time.
+func handHistory(hand Hand) (*models.HandHistory, error) {
+	if hand.TableName == "" {
+		return nil, fmt.Errorf("no table name")
+	}
+	startedAt := hand.StartedAt
+	if startedAt.IsZero() {
+		if hand.EndedAt == nil {
+			return nil, fmt.Errorf("no start or end time")
+		}
+		startedAt = *hand.EndedAt
+	}
+
+	history := &models.HandHistory{
+		HandID:    hand.HandID,
+		TableID:   hand.TableID,
+		TableName: hand.TableName,
+		Sequence:  hand.Sequence,
+		StartedAt: startedAt,
+		EndedAt:   hand.EndedAt,
+		TotalPot:  hand.TotalPot,
+	}
+	if hand.Settled || hand.Voided {
+		encoded, err := json.Marshal(hand.Winners)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal hand winners: %w", err)
+		}
+		history.Winners = encoded
+	}
+	if len(hand.Board) > 0 {
+		encoded, err := json.Marshal(hand.Board)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal board: %w", err)
+		}
+		history.Board = encoded
+	}
+	return history, nil
+}
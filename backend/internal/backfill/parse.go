@@ -0,0 +1,284 @@
+// Package backfill rebuilds hand history for hands dealt before it was
+// recorded, from the game server's log output and exported hand.completed
+// stats events, so histories and stats aren't empty at launch.
+package backfill
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
+	"github.com/google/uuid"
+)
+
+// maxLineSize bounds a single log line; misdeal records carry every refund
+const maxLineSize = 1 << 20
+
+// Log messages the game server writes over a hand's life
+const (
+	msgHandStarted = "Hand started"
+	msgHandEnded   = "Hand ended, game state reset"
+	msgMisdeal     = "Hand voided by misdeal"
+)
+
+// Hand is everything the input says about one hand
+type Hand struct {
+	HandID    string
+	TableID   uuid.UUID
+	TableName string
+	Sequence  int64
+	StartedAt time.Time
+	EndedAt   *time.Time
+	TotalPot  int64
+	Winners   []models.HandWinner
+	Board     []string
+	Voided    bool // Misdealt: no pot, no winners
+	Settled   bool // A hand.completed event gave the pot and winners
+}
+
+// Parser collects hands from log lines and stats events, merging every
+// record for the same hand ID into one hand
+type Parser struct {
+	hands map[string]*Hand
+	seen  map[string]bool // Message or event type and hand ID
+
+	Lines      int // Non-empty lines read
+	Records    int // Lines about a hand
+	Duplicates int // Records already seen, e.g. from overlapping log files
+	Unreadable int // Lines that were neither a log record nor an event
+}
+
+// NewParser creates an empty parser
+func NewParser() *Parser {
+	return &Parser{hands: make(map[string]*Hand), seen: make(map[string]bool)}
+}
+
+// Read parses every line of r. Lines may be slog JSON or text records, or
+// stats event envelopes; anything else is counted and skipped.
+func (p *Parser) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		p.Lines++
+		if !p.parseLine(line) {
+			p.Unreadable++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return nil
+}
+
+// Hands returns the hands found so far in the order they were dealt
+func (p *Parser) Hands() []Hand {
+	hands := make([]Hand, 0, len(p.hands))
+	for _, hand := range p.hands {
+		hands = append(hands, *hand)
+	}
+	sort.Slice(hands, func(i, j int) bool {
+		if !hands[i].StartedAt.Equal(hands[j].StartedAt) {
+			return hands[i].StartedAt.Before(hands[j].StartedAt)
+		}
+		return hands[i].HandID < hands[j].HandID
+	})
+	return hands
+}
+
+// parseLine applies one line, reporting whether it could be read at all
+func (p *Parser) parseLine(line string) bool {
+	var fields map[string]interface{}
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return false
+		}
+		if _, ok := fields["data"]; ok {
+			return p.parseEvent(line)
+		}
+	} else {
+		var ok bool
+		if fields, ok = parseTextRecord(line); !ok {
+			return false
+		}
+	}
+
+	msg, _ := fields["msg"].(string)
+	if msg == "" {
+		return false
+	}
+	p.parseLogRecord(msg, fields)
+	return true
+}
+
+// parseLogRecord applies a log record about the start or end of a hand
+func (p *Parser) parseLogRecord(msg string, fields map[string]interface{}) {
+	if msg != msgHandStarted && msg != msgHandEnded && msg != msgMisdeal {
+		return
+	}
+	handID, _ := fields["hand_id"].(string)
+	at, err := time.Parse(time.RFC3339Nano, fmt.Sprint(fields["time"]))
+	if err != nil {
+		return
+	}
+	hand := p.hand(handID)
+	if hand == nil || p.duplicate(msg, handID) {
+		return
+	}
+	if table, _ := fields["table"].(string); hand.TableName == "" {
+		hand.TableName = table
+	}
+
+	switch msg {
+	case msgHandStarted:
+		hand.StartedAt = at
+	case msgMisdeal:
+		hand.Voided = true
+		hand.EndedAt = &at
+	case msgHandEnded:
+		// A settled hand's event time is only a fallback for the log's
+		if hand.EndedAt == nil || hand.Settled {
+			hand.EndedAt = &at
+		}
+	}
+}
+
+// parseEvent applies a stats event envelope. Only hand.completed is about a
+// single hand's outcome; other event types are read but ignored.
+func (p *Parser) parseEvent(line string) bool {
+	var event statsevents.Event
+	if err := json.Unmarshal([]byte(line), &event); err != nil || event.Type == "" {
+		return false
+	}
+	if event.Type != statsevents.TypeHandCompleted {
+		return true
+	}
+
+	var completed statsevents.HandCompleted
+	if err := json.Unmarshal(event.Data, &completed); err != nil {
+		return false
+	}
+	hand := p.hand(completed.HandID)
+	if hand == nil || p.duplicate(event.Type, completed.HandID) {
+		return true
+	}
+
+	hand.Settled = true
+	hand.TotalPot = completed.TotalPot
+	hand.Board = completed.Board
+	hand.Winners = make([]models.HandWinner, len(completed.Winners))
+	for i, winner := range completed.Winners {
+		hand.Winners[i] = models.HandWinner{UserID: winner.UserID, Username: winner.Username, Amount: winner.Amount, Half: winner.Half}
+	}
+	if completed.TableName != "" {
+		hand.TableName = completed.TableName
+	}
+	if completed.TableID != uuid.Nil {
+		hand.TableID = completed.TableID
+	}
+	if hand.EndedAt == nil && !event.OccurredAt.IsZero() {
+		occurredAt := event.OccurredAt
+		hand.EndedAt = &occurredAt
+	}
+	return true
+}
+
+// duplicate counts a record, reporting whether the same record about the
+// same hand was already read
+func (p *Parser) duplicate(kind, handID string) bool {
+	p.Records++
+	key := kind + "\x00" + handID
+	if p.seen[key] {
+		p.Duplicates++
+		return true
+	}
+	p.seen[key] = true
+	return false
+}
+
+// hand returns the hand with this ID, adding it on first sight. It returns
+// nil for IDs that weren't issued by the server, whose sequence is unknown.
+func (p *Parser) hand(handID string) *Hand {
+	if hand, ok := p.hands[handID]; ok {
+		return hand
+	}
+	sequence, ok := parseSequence(handID)
+	if !ok {
+		return nil
+	}
+	hand := &Hand{HandID: handID, Sequence: sequence}
+	p.hands[handID] = hand
+	return hand
+}
+
+// parseSequence reads the hand number from a hand ID such as
+// "ABC-1F2E-000042"
+func parseSequence(handID string) (int64, bool) {
+	i := strings.LastIndex(handID, "-")
+	if i <= 0 || len(handID) > 50 {
+		return 0, false
+	}
+	sequence, err := strconv.ParseInt(handID[i+1:], 10, 64)
+	if err != nil || sequence <= 0 {
+		return 0, false
+	}
+	return sequence, true
+}
+
+// parseTextRecord splits a slog text record, key=value pairs with values
+// quoted when they contain spaces or quotes, into its fields
+func parseTextRecord(line string) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	for line != "" {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || strings.ContainsAny(line[:eq], " \"") {
+			return nil, false
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, "\"") {
+			end := closingQuote(line)
+			if end < 0 {
+				return nil, false
+			}
+			unquoted, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, line = unquoted, line[end+1:]
+		} else if sp := strings.IndexByte(line, ' '); sp >= 0 {
+			value, line = line[:sp], line[sp:]
+		} else {
+			value, line = line, ""
+		}
+		fields[key] = value
+		line = strings.TrimLeft(line, " ")
+	}
+	return fields, len(fields) > 0
+}
+
+// closingQuote returns the index of the quote ending the quoted string s
+// starts with, or -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
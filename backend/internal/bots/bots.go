@@ -0,0 +1,88 @@
+// Package bots plays simple rule-based opponents at practice tables. A bot
+// weighs its equity against the price of calling, raises strong hands and
+// never bluffs, so new players meet a steady, readable game.
+package bots
+
+import (
+	"math/rand"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+// Actions a bot can choose
+const (
+	ActionFold  = "fold"
+	ActionCheck = "check"
+	ActionCall  = "call"
+	ActionRaise = "raise"
+)
+
+// Thresholds on the bot's share of the pot at showdown
+const (
+	// RaiseEquity is the equity at which a bot bets or raises
+	RaiseEquity = 0.6
+	// equityIterations is the number of runouts dealt per decision
+	equityIterations = 500
+)
+
+// Spot is what a bot can see when it is asked to act
+type Spot struct {
+	Hole      []eval.Card
+	Board     []eval.Card // Zero for cards not dealt yet
+	Opponents int         // Other players still in the hand
+	Pot       uint        // Chips in the pots and bets, before the bot acts
+	ToCall    uint        // Already capped at the bot's stack
+	Stack     uint
+	MinRaise  uint // Smallest raise on top of a call
+}
+
+// Decision is a bot's action. Amount is the chips put in for a raise,
+// the call included.
+type Decision struct {
+	Action string
+	Amount uint
+}
+
+// Bot decides actions for one seat
+type Bot struct {
+	rng *rand.Rand
+}
+
+func New(seed int64) *Bot {
+	return &Bot{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Decide picks an action for the spot: raise with RaiseEquity or better,
+// otherwise check when it is free and call only when the pot lays the price
+func (b *Bot) Decide(s Spot) Decision {
+	equity := b.equity(s)
+
+	if equity >= RaiseEquity && s.Stack > s.ToCall {
+		return Decision{Action: ActionRaise, Amount: raiseAmount(s)}
+	}
+	if s.ToCall == 0 {
+		return Decision{Action: ActionCheck}
+	}
+	if equity >= float64(s.ToCall)/float64(s.Pot+s.ToCall) {
+		return Decision{Action: ActionCall}
+	}
+	return Decision{Action: ActionFold}
+}
+
+// equity estimates the bot's share of the pot. Equity is only worked out for
+// Hold'em hands; with more hole cards the bot takes an even share, which has
+// it check and call cheap bets but never raise.
+func (b *Bot) equity(s Spot) float64 {
+	if len(s.Hole) != 2 || s.Opponents < 1 {
+		return 1 / float64(s.Opponents+1)
+	}
+	return poker.Equity([2]eval.Card{s.Hole[0], s.Hole[1]}, s.Board, s.Opponents, equityIterations, b.rng)
+}
+
+// raiseAmount sizes a raise at two thirds of the pot after calling, and at
+// least the minimum raise. A raise the stack can't cover goes all in.
+func raiseAmount(s Spot) uint {
+	raise := max((s.Pot+s.ToCall)*2/3, s.MinRaise)
+	return min(s.ToCall+raise, s.Stack)
+}
@@ -1,14 +1,27 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// defaultJWTSecret is only acceptable outside production
+const defaultJWTSecret = "poker-platform-secret-key-change-in-production"
+
 type Config struct {
 	// Environment
 	Environment string
 
+	// Log output, "text" or "json" for shipping to a central log store
+	LogFormat string
+
 	// Database
 	DatabaseURL      string
 	PostgresDB       string
@@ -24,9 +37,81 @@ type Config struct {
 	// Server
 	Port string
 
+	// Name this server reports in the cluster registry, the hostname when unset
+	InstanceID string
+
+	// Browser origins allowed to call the REST API and to open WebSockets.
+	// Entries are exact origins or a single wildcard such as
+	// "https://*.example.com" or "http://localhost:*".
+	AllowedOrigins   []string
+	WSAllowedOrigins []string
+
+	// Outbound messages a WebSocket client may have waiting before chat and
+	// stale game views are shed
+	WSSendQueueSize int
+	// How long a disconnected player's seat and stack wait for them to
+	// reconnect before they are cashed out, 0 to cash out at once
+	WSReconnectWindow time.Duration
+	// Most time added to a player's decisions for their connection's round
+	// trip, so slow connections aren't timed out on actions made in time;
+	// 0 to add none
+	WSLatencyAllowance time.Duration
+
+	// Practice tables seat bots until this many players are at the table,
+	// 0 for no bots
+	PracticeTablePlayers int
+
+	// Commands, broadcasts and hand state each table keeps for debugging,
+	// 0 to keep none. With a directory set every entry is also appended to
+	// a file per table there; nothing rotates those files.
+	FlightRecorderEntries int
+	FlightRecorderDir     string
+
+	// Table chat: words blocked on top of the built-in profanity list, a
+	// trailing "*" matching any ending, and how many messages a player may
+	// send per window, 0 for no limit
+	ChatBlockedWords []string
+	ChatRateLimit    int
+	ChatRateWindow   time.Duration
+
 	// Authentication
 	JWTSecret string
 
+	// Sign-in sessions slide: a token lasts the idle timeout for the user's
+	// role and is renewed while they are active, until the session has
+	// lasted SessionMaxLifetime, 0 for no limit
+	SessionIdleTimeout      time.Duration
+	SessionIdleTimeoutRoles map[string]time.Duration // Replaces the idle timeout for these roles
+	SessionMaxLifetime      time.Duration
+
+	// Password resets: how long an emailed link works, and how many resets
+	// an account or an IP address may request per window
+	PasswordResetTTL          time.Duration
+	PasswordResetAccountLimit int
+	PasswordResetIPLimit      int
+	PasswordResetWindow       time.Duration
+
+	// PEM encoded Ed25519 private key signing the manifests of regulator
+	// betting exports, which are off until it is set. Regulators verify
+	// exports with its public key, which is all they are given.
+	RegulatorExportKey string
+
+	// Sign-in with Google and Apple, each off until its client ID is set.
+	// Providers send players back to OAuthCallbackURL, a frontend page;
+	// Apple posts its response to AppleRedirectURL on the API instead, which
+	// forwards it there.
+	OAuthCallbackURL   string
+	GoogleClientID     string
+	GoogleClientSecret string
+	AppleClientID      string // Services ID
+	AppleTeamID        string
+	AppleKeyID         string
+	ApplePrivateKey    string // PEM encoded .p8 key
+	AppleRedirectURL   string
+
+	// Bearer token required to scrape /metrics, open when unset
+	MetricsToken string
+
 	// SMTP
 	SMTPHost     string
 	SMTPPort     string
@@ -39,12 +124,144 @@ type Config struct {
 	FormanceAPIKey     string
 	FormanceLedgerName string
 	FormanceCurrency   string
+
+	// Route every ledger call to an in-memory ledger so staging and demo
+	// environments can play without moving real money. Balances are lost on
+	// restart and the FORMANCE_* settings are not used to reach Formance.
+	Sandbox bool
+
+	// Background workers
+	NightlyWorkersHour     int           // UTC hour (0-23) at which nightly jobs run
+	TableAutoscaleInterval time.Duration // How often templated tables are opened/closed for demand
+
+	// Consistency markers for restoring the database and ledger from backups
+	BackupMarkerInterval time.Duration // How often a marker is taken, 0 only on demand
+	BackupQuiesceTimeout time.Duration // Longest wait for ledger writes in flight to finish
+	BackupManifestDir    string        // Where marker manifests are written
+
+	// Skill ratings and quick seat matchmaking
+	SkillRatingInterval        time.Duration // How often finished results are rated
+	QuickSeatLowStakesBigBlind int64         // Big blind (MNT) up to which beginners and sharks are kept apart, 0 for never
+
+	// Scheduled maintenance windows
+	MaintenanceNoticeLead time.Duration // How far ahead players see banners and tournament registrants are emailed
+
+	// Serve the platform-wide card distribution report without authentication
+	PublicFairnessReport bool
+
+	// Fraction (0-1) of referred players' rake paid to their affiliate
+	AffiliateRevenueShare float64
+
+	// Wallet velocity limits, zero disables a limit
+	VelocityDepositsPerHour    int
+	VelocityDepositsPerDay     int
+	VelocityWithdrawalsPerDay  int
+	VelocityWithdrawalCooldown time.Duration // Minimum time between two withdrawals
+	VelocityDailyCaps          []int64       // MNT per 24 hours, by KYC tier
+
+	// Withdrawal minimums and fees by asset and KYC tier
+	WithdrawalFees []WithdrawalFee
+
+	// Username changes, zero disables a rule
+	UsernameChangeCooldown time.Duration // Minimum time between two changes by one user
+	UsernameReservation    time.Duration // How long a given-up name is held for its former owner
+
+	// Responsible gaming limits on rebuys, by jurisdiction
+	DefaultJurisdiction string // Jurisdiction of users an admin hasn't assigned one
+	RebuyLimits         []RebuyLimit
+
+	// Most MNT a user may have in game sessions at once, zero for no limit
+	MaxExposure      []int64          // By KYC tier
+	MaxExposureRoles map[string]int64 // Replaces the tier's limit for these roles
+
+	// Room policy for hand histories; players can only add privacy on top
+	HandHistoryStoreMucked      bool // Keep hole cards of players who did not show down
+	HandHistoryRevealMucked     bool // Show those cards to the other players in histories
+	HandHistoryAnonymizeExports bool // Replace opponents' names with seats in exports
+
+	// Push notifications
+	FCMServerKey   string
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsBundleID   string
+	APNsPrivateKey string // PEM encoded .p8 key
+	APNsProduction bool
+
+	// Domain events for analytics, fraud and notification consumers
+	StatsEventsNATSURL       string // Empty delivers events in process only
+	StatsEventsSubjectPrefix string // Events go to <prefix>.<type>
+
+	// Internal gRPC API other services drive tables through, off when the
+	// address is empty. Callers need a certificate signed by the client CA.
+	EngineGRPCAddr         string
+	EngineGRPCCertFile     string
+	EngineGRPCKeyFile      string
+	EngineGRPCClientCAFile string
+	EngineGRPCAllow        map[string][]string // Certificate names allowed each method, written as method:name pairs
+}
+
+// WithdrawalFee is one row of the withdrawal fee schedule, written in
+// WITHDRAWAL_FEES as asset:kyc_tier:minimum:flat_fee:fee_bps:free_above with
+// "*" for every asset, e.g. "*:0:1000:500:100:0,*:2:1000:0:50:1000000"
+type WithdrawalFee struct {
+	Asset     string
+	KYCTier   int
+	Minimum   int64
+	FlatFee   int64
+	FeeBps    int64 // Basis points, 100 = 1%
+	FreeAbove int64 // Withdrawals of at least this much pay no fee, 0 never
 }
 
-func Load() *Config {
-	return &Config{
+// RebuyLimit is one jurisdiction's limits on buying back in at a table after
+// losing a full stack there, written in REBUY_LIMITS as
+// jurisdiction:cooldown:warn_percent with "*" for every other jurisdiction,
+// e.g. "*:0s:50,KR:30m:25"
+type RebuyLimit struct {
+	Jurisdiction string
+	Cooldown     time.Duration // Wait before rebuying at the same table, 0 none
+	WarnPercent  int           // Warn when a rebuy is more than this share of the wallet, 0 never
+}
+
+// Problem describes one environment variable that is missing or invalid
+type Problem struct {
+	Var     string
+	Message string
+}
+
+// ValidationError lists every configuration problem found at startup, so an
+// operator can fix them all in one pass instead of one restart at a time
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d problem(s)):", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  - %s: %s", p.Var, p.Message)
+	}
+	return b.String()
+}
+
+// MissingVars returns the names of the variables that need attention
+func (e *ValidationError) MissingVars() []string {
+	vars := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		vars = append(vars, p.Var)
+	}
+	return vars
+}
+
+// Load reads the configuration from the environment and validates it. The
+// returned config is populated even when validation fails so callers can
+// report on it.
+func Load() (*Config, error) {
+	var problems []Problem
+
+	cfg := &Config{
 		// Environment
 		Environment: getEnvOrDefault("ENVIRONMENT", "development"),
+		LogFormat:   getEnvOrDefault("LOG_FORMAT", "text"),
 
 		// Database
 		DatabaseURL:      getEnvOrDefault("DATABASE_URL", ""),
@@ -59,10 +276,27 @@ func Load() *Config {
 		RedisPassword: getEnvOrDefault("REDIS_PASSWORD", "password"),
 
 		// Server
-		Port: getEnvOrDefault("PORT", "8080"),
+		Port:       getEnvOrDefault("PORT", "8080"),
+		InstanceID: getEnvOrDefault("INSTANCE_ID", defaultInstanceID()),
 
 		// Authentication
-		JWTSecret: getEnvOrDefault("JWT_SECRET", "poker-platform-secret-key-change-in-production"),
+		JWTSecret: getEnvOrDefault("JWT_SECRET", defaultJWTSecret),
+
+		// Regulator exports
+		RegulatorExportKey: getEnvOrDefault("REGULATOR_EXPORT_KEY", ""),
+
+		// Sign-in providers
+		OAuthCallbackURL:   getEnvOrDefault("OAUTH_CALLBACK_URL", ""),
+		GoogleClientID:     getEnvOrDefault("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret: getEnvOrDefault("GOOGLE_CLIENT_SECRET", ""),
+		AppleClientID:      getEnvOrDefault("APPLE_CLIENT_ID", ""),
+		AppleTeamID:        getEnvOrDefault("APPLE_TEAM_ID", ""),
+		AppleKeyID:         getEnvOrDefault("APPLE_KEY_ID", ""),
+		ApplePrivateKey:    getEnvOrDefault("APPLE_PRIVATE_KEY", ""),
+		AppleRedirectURL:   getEnvOrDefault("APPLE_REDIRECT_URL", ""),
+
+		// Metrics
+		MetricsToken: getEnvOrDefault("METRICS_TOKEN", ""),
 
 		// SMTP
 		SMTPHost:     getEnvOrDefault("SMTP_HOST", "smtp.resend.com"),
@@ -76,6 +310,654 @@ func Load() *Config {
 		FormanceAPIKey:     getEnvOrDefault("FORMANCE_API_KEY", ""),
 		FormanceLedgerName: getEnvOrDefault("FORMANCE_LEDGER_NAME", "poker-platform-mnt"),
 		FormanceCurrency:   getEnvOrDefault("FORMANCE_CURRENCY", "MNT"),
+
+		// Push notifications
+		FCMServerKey:   getEnvOrDefault("FCM_SERVER_KEY", ""),
+		APNsKeyID:      getEnvOrDefault("APNS_KEY_ID", ""),
+		APNsTeamID:     getEnvOrDefault("APNS_TEAM_ID", ""),
+		APNsBundleID:   getEnvOrDefault("APNS_BUNDLE_ID", ""),
+		APNsPrivateKey: getEnvOrDefault("APNS_PRIVATE_KEY", ""),
+
+		// Stats events
+		StatsEventsNATSURL:       getEnvOrDefault("STATS_EVENTS_NATS_URL", ""),
+		StatsEventsSubjectPrefix: getEnvOrDefault("STATS_EVENTS_SUBJECT_PREFIX", "gp.stats"),
+
+		// Engine API
+		EngineGRPCAddr:         getEnvOrDefault("ENGINE_GRPC_ADDR", ""),
+		EngineGRPCCertFile:     getEnvOrDefault("ENGINE_GRPC_CERT_FILE", ""),
+		EngineGRPCKeyFile:      getEnvOrDefault("ENGINE_GRPC_KEY_FILE", ""),
+		EngineGRPCClientCAFile: getEnvOrDefault("ENGINE_GRPC_CLIENT_CA_FILE", ""),
+	}
+
+	// Origins, with local development defaults only outside staging and production
+	var defaultOrigins string
+	switch cfg.Environment {
+	case "development", "test":
+		defaultOrigins = "http://localhost:*,http://127.0.0.1:*"
+	}
+	cfg.AllowedOrigins = splitList(getEnvOrDefault("ALLOWED_ORIGINS", defaultOrigins))
+	cfg.WSAllowedOrigins = cfg.AllowedOrigins
+	if ws := splitList(os.Getenv("WS_ALLOWED_ORIGINS")); len(ws) > 0 {
+		cfg.WSAllowedOrigins = ws
+	}
+
+	// WebSocket send queues
+	// (unparseable values keep their default so they are reported only once)
+	cfg.WSSendQueueSize = 256
+	if size, err := strconv.Atoi(getEnvOrDefault("WS_SEND_QUEUE_SIZE", "256")); err != nil {
+		problems = append(problems, Problem{"WS_SEND_QUEUE_SIZE", "must be a whole number of messages"})
+	} else {
+		cfg.WSSendQueueSize = size
+	}
+	cfg.WSReconnectWindow = time.Minute
+	if window, err := time.ParseDuration(getEnvOrDefault("WS_RECONNECT_WINDOW", "60s")); err != nil {
+		problems = append(problems, Problem{"WS_RECONNECT_WINDOW", `must be a duration such as "60s" or "2m"`})
+	} else {
+		cfg.WSReconnectWindow = window
+	}
+	cfg.WSLatencyAllowance = time.Second
+	if allowance, err := time.ParseDuration(getEnvOrDefault("WS_LATENCY_ALLOWANCE", "1s")); err != nil {
+		problems = append(problems, Problem{"WS_LATENCY_ALLOWANCE", `must be a duration such as "1s" or "500ms"`})
+	} else {
+		cfg.WSLatencyAllowance = allowance
+	}
+
+	cfg.PracticeTablePlayers = 4
+	if players, err := strconv.Atoi(getEnvOrDefault("PRACTICE_TABLE_PLAYERS", "4")); err != nil {
+		problems = append(problems, Problem{"PRACTICE_TABLE_PLAYERS", "must be a whole number of players"})
+	} else {
+		cfg.PracticeTablePlayers = players
+	}
+
+	cfg.FlightRecorderEntries = 5000
+	if entries, err := strconv.Atoi(getEnvOrDefault("FLIGHT_RECORDER_ENTRIES", "5000")); err != nil {
+		problems = append(problems, Problem{"FLIGHT_RECORDER_ENTRIES", "must be a whole number of entries"})
+	} else {
+		cfg.FlightRecorderEntries = entries
+	}
+	cfg.FlightRecorderDir = os.Getenv("FLIGHT_RECORDER_DIR")
+
+	// Table chat
+	cfg.ChatBlockedWords = splitList(os.Getenv("CHAT_BLOCKED_WORDS"))
+	cfg.ChatRateLimit = 5
+	if limit, err := strconv.Atoi(getEnvOrDefault("CHAT_RATE_LIMIT", "5")); err != nil {
+		problems = append(problems, Problem{"CHAT_RATE_LIMIT", "must be a whole number of messages"})
+	} else {
+		cfg.ChatRateLimit = limit
+	}
+	cfg.ChatRateWindow = 10 * time.Second
+	if window, err := time.ParseDuration(getEnvOrDefault("CHAT_RATE_WINDOW", "10s")); err != nil {
+		problems = append(problems, Problem{"CHAT_RATE_WINDOW", `must be a duration such as "10s" or "1m"`})
+	} else {
+		cfg.ChatRateWindow = window
+	}
+
+	// Background workers
+	cfg.NightlyWorkersHour = 3
+	if hour, err := strconv.Atoi(getEnvOrDefault("NIGHTLY_WORKERS_HOUR", "3")); err != nil {
+		problems = append(problems, Problem{"NIGHTLY_WORKERS_HOUR", "must be a whole number of hours"})
+	} else {
+		cfg.NightlyWorkersHour = hour
+	}
+
+	cfg.TableAutoscaleInterval = 30 * time.Second
+	if interval, err := time.ParseDuration(getEnvOrDefault("TABLE_AUTOSCALE_INTERVAL", "30s")); err != nil {
+		problems = append(problems, Problem{"TABLE_AUTOSCALE_INTERVAL", `must be a duration such as "30s" or "2m"`})
+	} else {
+		cfg.TableAutoscaleInterval = interval
+	}
+
+	backupDuration := func(envVar, fallback string) time.Duration {
+		d, err := time.ParseDuration(getEnvOrDefault(envVar, fallback))
+		if err != nil {
+			problems = append(problems, Problem{envVar, `must be a duration such as "6h" or "5s"`})
+			d, _ = time.ParseDuration(fallback)
+		}
+		return d
+	}
+	cfg.BackupMarkerInterval = backupDuration("BACKUP_MARKER_INTERVAL", "6h")
+	cfg.BackupQuiesceTimeout = backupDuration("BACKUP_QUIESCE_TIMEOUT", "5s")
+	cfg.BackupManifestDir = getEnvOrDefault("BACKUP_MANIFEST_DIR", "backups")
+
+	cfg.SkillRatingInterval = 10 * time.Minute
+	if interval, err := time.ParseDuration(getEnvOrDefault("SKILL_RATING_INTERVAL", "10m")); err != nil {
+		problems = append(problems, Problem{"SKILL_RATING_INTERVAL", `must be a duration such as "10m" or "1h"`})
+	} else {
+		cfg.SkillRatingInterval = interval
+	}
+
+	cfg.QuickSeatLowStakesBigBlind = 200
+	if bigBlind, err := strconv.ParseInt(getEnvOrDefault("QUICK_SEAT_LOW_STAKES_BIG_BLIND", "200"), 10, 64); err != nil {
+		problems = append(problems, Problem{"QUICK_SEAT_LOW_STAKES_BIG_BLIND", "must be a whole number of MNT"})
+	} else {
+		cfg.QuickSeatLowStakesBigBlind = bigBlind
+	}
+
+	cfg.MaintenanceNoticeLead = 24 * time.Hour
+	if lead, err := time.ParseDuration(getEnvOrDefault("MAINTENANCE_NOTICE_LEAD", "24h")); err != nil {
+		problems = append(problems, Problem{"MAINTENANCE_NOTICE_LEAD", `must be a duration such as "24h" or "90m"`})
+	} else {
+		cfg.MaintenanceNoticeLead = lead
+	}
+
+	sandbox, err := strconv.ParseBool(getEnvOrDefault("SANDBOX", "false"))
+	if err != nil {
+		problems = append(problems, Problem{"SANDBOX", "must be true or false"})
+	}
+	cfg.Sandbox = sandbox
+
+	publicFairness, err := strconv.ParseBool(getEnvOrDefault("PUBLIC_FAIRNESS_REPORT", "false"))
+	if err != nil {
+		problems = append(problems, Problem{"PUBLIC_FAIRNESS_REPORT", "must be true or false"})
+	}
+	cfg.PublicFairnessReport = publicFairness
+
+	cfg.AffiliateRevenueShare = 0.25
+	if share, err := strconv.ParseFloat(getEnvOrDefault("AFFILIATE_REVENUE_SHARE", "0.25"), 64); err != nil {
+		problems = append(problems, Problem{"AFFILIATE_REVENUE_SHARE", "must be a number such as 0.25"})
+	} else {
+		cfg.AffiliateRevenueShare = share
+	}
+
+	// Wallet velocity limits
+	velocityCount := func(envVar, fallback string) int {
+		n, err := strconv.Atoi(getEnvOrDefault(envVar, fallback))
+		if err != nil {
+			problems = append(problems, Problem{envVar, "must be a whole number of operations"})
+			n, _ = strconv.Atoi(fallback)
+		}
+		return n
+	}
+	cfg.VelocityDepositsPerHour = velocityCount("VELOCITY_DEPOSITS_PER_HOUR", "5")
+	cfg.VelocityDepositsPerDay = velocityCount("VELOCITY_DEPOSITS_PER_DAY", "20")
+	cfg.VelocityWithdrawalsPerDay = velocityCount("VELOCITY_WITHDRAWALS_PER_DAY", "3")
+
+	cfg.VelocityWithdrawalCooldown = time.Hour
+	if cooldown, err := time.ParseDuration(getEnvOrDefault("VELOCITY_WITHDRAWAL_COOLDOWN", "1h")); err != nil {
+		problems = append(problems, Problem{"VELOCITY_WITHDRAWAL_COOLDOWN", `must be a duration such as "1h" or "30m"`})
+	} else {
+		cfg.VelocityWithdrawalCooldown = cooldown
+	}
+
+	cfg.VelocityDailyCaps = []int64{2_000_000, 20_000_000, 200_000_000}
+	if caps, err := parseAmounts(getEnvOrDefault("VELOCITY_DAILY_CAPS", "2000000,20000000,200000000")); err != nil {
+		problems = append(problems, Problem{"VELOCITY_DAILY_CAPS", "must be comma separated MNT amounts, one per KYC tier"})
+	} else {
+		cfg.VelocityDailyCaps = caps
+	}
+
+	// Sign-in sessions
+	sessionDuration := func(envVar, fallback string) time.Duration {
+		d, err := time.ParseDuration(getEnvOrDefault(envVar, fallback))
+		if err != nil {
+			problems = append(problems, Problem{envVar, `must be a duration such as "30m"`})
+			d, _ = time.ParseDuration(fallback)
+		}
+		return d
+	}
+	cfg.SessionIdleTimeout = sessionDuration("SESSION_IDLE_TIMEOUT", "30m")
+	cfg.SessionMaxLifetime = sessionDuration("SESSION_MAX_LIFETIME", "12h")
+	cfg.SessionIdleTimeoutRoles = map[string]time.Duration{"admin": 15 * time.Minute, "finance": 15 * time.Minute}
+	if timeouts, err := parseRoleDurations(getEnvOrDefault("SESSION_IDLE_TIMEOUT_ROLES", "admin:15m,finance:15m")); err != nil {
+		problems = append(problems, Problem{"SESSION_IDLE_TIMEOUT_ROLES", "must be comma separated role:duration pairs"})
+	} else {
+		cfg.SessionIdleTimeoutRoles = timeouts
+	}
+
+	// Password resets
+	cfg.PasswordResetTTL = sessionDuration("PASSWORD_RESET_TTL", "1h")
+	cfg.PasswordResetWindow = sessionDuration("PASSWORD_RESET_WINDOW", "1h")
+	cfg.PasswordResetAccountLimit = 3
+	if limit, err := strconv.Atoi(getEnvOrDefault("PASSWORD_RESET_ACCOUNT_LIMIT", "3")); err != nil {
+		problems = append(problems, Problem{"PASSWORD_RESET_ACCOUNT_LIMIT", "must be a whole number of requests"})
+	} else {
+		cfg.PasswordResetAccountLimit = limit
+	}
+	cfg.PasswordResetIPLimit = 10
+	if limit, err := strconv.Atoi(getEnvOrDefault("PASSWORD_RESET_IP_LIMIT", "10")); err != nil {
+		problems = append(problems, Problem{"PASSWORD_RESET_IP_LIMIT", "must be a whole number of requests"})
+	} else {
+		cfg.PasswordResetIPLimit = limit
+	}
+
+	// Username changes
+	usernameDuration := func(envVar, fallback string) time.Duration {
+		d, err := time.ParseDuration(getEnvOrDefault(envVar, fallback))
+		if err != nil {
+			problems = append(problems, Problem{envVar, `must be a duration such as "720h"`})
+			d, _ = time.ParseDuration(fallback)
+		}
+		return d
+	}
+	cfg.UsernameChangeCooldown = usernameDuration("USERNAME_CHANGE_COOLDOWN", "720h")
+	cfg.UsernameReservation = usernameDuration("USERNAME_RESERVATION", "2160h")
+
+	// Rebuy limits
+	cfg.DefaultJurisdiction = getEnvOrDefault("DEFAULT_JURISDICTION", "MN")
+	cfg.RebuyLimits = []RebuyLimit{{Jurisdiction: "*", WarnPercent: 50}}
+	if limits, err := parseRebuyLimits(getEnvOrDefault("REBUY_LIMITS", "*:0s:50")); err != nil {
+		problems = append(problems, Problem{"REBUY_LIMITS", "must be comma separated jurisdiction:cooldown:warn_percent rules"})
+	} else {
+		cfg.RebuyLimits = limits
+	}
+
+	// Exposure across live game sessions
+	cfg.MaxExposure = []int64{1_000_000, 10_000_000, 100_000_000}
+	if limits, err := parseAmounts(getEnvOrDefault("MAX_EXPOSURE", "1000000,10000000,100000000")); err != nil {
+		problems = append(problems, Problem{"MAX_EXPOSURE", "must be comma separated MNT amounts, one per KYC tier"})
+	} else {
+		cfg.MaxExposure = limits
+	}
+	cfg.MaxExposureRoles = map[string]int64{}
+	if limits, err := parseRoleAmounts(getEnvOrDefault("MAX_EXPOSURE_ROLES", "")); err != nil {
+		problems = append(problems, Problem{"MAX_EXPOSURE_ROLES", "must be comma separated role:amount pairs"})
+	} else {
+		cfg.MaxExposureRoles = limits
+	}
+
+	// Hand history privacy
+	handHistoryFlag := func(envVar, fallback string) bool {
+		b, err := strconv.ParseBool(getEnvOrDefault(envVar, fallback))
+		if err != nil {
+			problems = append(problems, Problem{envVar, "must be true or false"})
+			b, _ = strconv.ParseBool(fallback)
+		}
+		return b
+	}
+	cfg.HandHistoryStoreMucked = handHistoryFlag("HAND_HISTORY_STORE_MUCKED", "true")
+	cfg.HandHistoryRevealMucked = handHistoryFlag("HAND_HISTORY_REVEAL_MUCKED", "false")
+	cfg.HandHistoryAnonymizeExports = handHistoryFlag("HAND_HISTORY_ANONYMIZE_EXPORTS", "true")
+
+	cfg.WithdrawalFees = []WithdrawalFee{{Asset: "*", Minimum: 1000}}
+	if fees, err := parseWithdrawalFees(getEnvOrDefault("WITHDRAWAL_FEES", "*:0:1000:0:0:0")); err != nil {
+		problems = append(problems, Problem{"WITHDRAWAL_FEES", "must be comma separated asset:kyc_tier:minimum:flat_fee:fee_bps:free_above rules"})
+	} else {
+		cfg.WithdrawalFees = fees
+	}
+
+	cfg.EngineGRPCAllow = map[string][]string{}
+	if allow, err := parseMethodAllowList(getEnvOrDefault("ENGINE_GRPC_ALLOW", "")); err != nil {
+		problems = append(problems, Problem{"ENGINE_GRPC_ALLOW", "must be comma separated method:name pairs"})
+	} else {
+		cfg.EngineGRPCAllow = allow
+	}
+
+	production, err := strconv.ParseBool(getEnvOrDefault("APNS_PRODUCTION", "false"))
+	if err != nil {
+		problems = append(problems, Problem{"APNS_PRODUCTION", "must be true or false"})
+	}
+	cfg.APNsProduction = production
+
+	if err := cfg.validate(problems); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings that must hold before the server can start
+func (c *Config) Validate() error {
+	return c.validate(nil)
+}
+
+func (c *Config) validate(problems []Problem) error {
+	require := func(value, envVar string) {
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, Problem{envVar, "is required"})
+		}
+	}
+	port := func(value, envVar string) {
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			problems = append(problems, Problem{envVar, "must be a port number between 1 and 65535"})
+		}
+	}
+
+	switch c.Environment {
+	case "development", "test", "staging", "production":
+	default:
+		problems = append(problems, Problem{"ENVIRONMENT", "must be one of development, test, staging, production"})
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		problems = append(problems, Problem{"LOG_FORMAT", "must be text or json"})
+	}
+
+	port(c.Port, "PORT")
+	require(c.JWTSecret, "JWT_SECRET")
+
+	if c.DatabaseURL == "" {
+		require(c.PostgresHost, "POSTGRES_HOST")
+		require(c.PostgresDB, "POSTGRES_DB")
+		require(c.PostgresUser, "POSTGRES_USER")
+		port(c.PostgresPort, "POSTGRES_PORT")
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if !validOriginPattern(origin) {
+			problems = append(problems, Problem{"ALLOWED_ORIGINS", fmt.Sprintf("%q is not an origin such as https://poker.example.com", origin)})
+		}
+	}
+	for _, origin := range c.WSAllowedOrigins {
+		if !validOriginPattern(origin) {
+			problems = append(problems, Problem{"WS_ALLOWED_ORIGINS", fmt.Sprintf("%q is not an origin such as https://poker.example.com", origin)})
+		}
+	}
+
+	// Too small a queue sheds game views during an ordinary burst of updates
+	if c.WSSendQueueSize < 16 {
+		problems = append(problems, Problem{"WS_SEND_QUEUE_SIZE", "must be at least 16"})
+	}
+	if c.WSReconnectWindow < 0 || c.WSReconnectWindow > 10*time.Minute {
+		problems = append(problems, Problem{"WS_RECONNECT_WINDOW", "must be between 0 and 10m"})
+	}
+	if c.WSLatencyAllowance < 0 || c.WSLatencyAllowance > 5*time.Second {
+		problems = append(problems, Problem{"WS_LATENCY_ALLOWANCE", "must be between 0 and 5s"})
+	}
+	if c.PracticeTablePlayers < 0 || c.PracticeTablePlayers > 9 {
+		problems = append(problems, Problem{"PRACTICE_TABLE_PLAYERS", "must be between 0 and 9"})
+	}
+	if c.FlightRecorderEntries < 0 || c.FlightRecorderEntries > 100000 {
+		problems = append(problems, Problem{"FLIGHT_RECORDER_ENTRIES", "must be between 0 and 100000"})
+	}
+	if c.ChatRateLimit < 0 {
+		problems = append(problems, Problem{"CHAT_RATE_LIMIT", "must not be negative"})
+	}
+	if c.ChatRateWindow < time.Second || c.ChatRateWindow > time.Hour {
+		problems = append(problems, Problem{"CHAT_RATE_WINDOW", "must be between 1s and 1h"})
+	}
+
+	require(c.FormanceAPIURL, "FORMANCE_API_URL")
+	require(c.FormanceLedgerName, "FORMANCE_LEDGER_NAME")
+	require(c.FormanceCurrency, "FORMANCE_CURRENCY")
+
+	if c.SMTPHost != "" {
+		port(c.SMTPPort, "SMTP_PORT")
+	}
+
+	if c.NightlyWorkersHour < 0 || c.NightlyWorkersHour > 23 {
+		problems = append(problems, Problem{"NIGHTLY_WORKERS_HOUR", "must be between 0 and 23"})
+	}
+	if c.TableAutoscaleInterval <= 0 {
+		problems = append(problems, Problem{"TABLE_AUTOSCALE_INTERVAL", "must be greater than zero"})
+	}
+	if c.BackupMarkerInterval < 0 {
+		problems = append(problems, Problem{"BACKUP_MARKER_INTERVAL", "must not be negative"})
+	}
+	if c.BackupQuiesceTimeout <= 0 {
+		problems = append(problems, Problem{"BACKUP_QUIESCE_TIMEOUT", "must be greater than zero"})
+	}
+	require(c.BackupManifestDir, "BACKUP_MANIFEST_DIR")
+	if c.SkillRatingInterval <= 0 {
+		problems = append(problems, Problem{"SKILL_RATING_INTERVAL", "must be greater than zero"})
+	}
+	if c.QuickSeatLowStakesBigBlind < 0 {
+		problems = append(problems, Problem{"QUICK_SEAT_LOW_STAKES_BIG_BLIND", "must not be negative"})
+	}
+	if c.MaintenanceNoticeLead <= 0 {
+		problems = append(problems, Problem{"MAINTENANCE_NOTICE_LEAD", "must be greater than zero"})
+	}
+	// Paying out all of the rake, or more, would run the revenue account dry
+	if c.AffiliateRevenueShare < 0 || c.AffiliateRevenueShare >= 1 {
+		problems = append(problems, Problem{"AFFILIATE_REVENUE_SHARE", "must be at least 0 and less than 1"})
+	}
+
+	for _, count := range []struct {
+		envVar string
+		value  int
+	}{
+		{"VELOCITY_DEPOSITS_PER_HOUR", c.VelocityDepositsPerHour},
+		{"VELOCITY_DEPOSITS_PER_DAY", c.VelocityDepositsPerDay},
+		{"VELOCITY_WITHDRAWALS_PER_DAY", c.VelocityWithdrawalsPerDay},
+	} {
+		if count.value < 0 {
+			problems = append(problems, Problem{count.envVar, "must not be negative"})
+		}
+	}
+	if c.VelocityWithdrawalCooldown < 0 {
+		problems = append(problems, Problem{"VELOCITY_WITHDRAWAL_COOLDOWN", "must not be negative"})
+	}
+	// A higher KYC tier should never be allowed less than a lower one
+	for i, limit := range c.VelocityDailyCaps {
+		if limit < 0 || (i > 0 && limit < c.VelocityDailyCaps[i-1]) {
+			problems = append(problems, Problem{"VELOCITY_DAILY_CAPS", "must not be negative and must not decrease with KYC tier"})
+			break
+		}
+	}
+
+	if c.SessionIdleTimeout <= 0 {
+		problems = append(problems, Problem{"SESSION_IDLE_TIMEOUT", "must be greater than zero"})
+	}
+	for role, timeout := range c.SessionIdleTimeoutRoles {
+		if !knownRoles[role] || timeout <= 0 {
+			problems = append(problems, Problem{"SESSION_IDLE_TIMEOUT_ROLES", "roles must be player, moderator, finance or admin and timeouts must be greater than zero"})
+			break
+		}
+	}
+	if c.SessionMaxLifetime < 0 || (c.SessionMaxLifetime > 0 && c.SessionMaxLifetime < c.SessionIdleTimeout) {
+		problems = append(problems, Problem{"SESSION_MAX_LIFETIME", "must be 0 for no limit or at least SESSION_IDLE_TIMEOUT"})
+	}
+	if c.PasswordResetTTL < 5*time.Minute || c.PasswordResetTTL > 24*time.Hour {
+		problems = append(problems, Problem{"PASSWORD_RESET_TTL", "must be between 5m and 24h"})
+	}
+	if c.PasswordResetWindow < time.Minute || c.PasswordResetWindow > 24*time.Hour {
+		problems = append(problems, Problem{"PASSWORD_RESET_WINDOW", "must be between 1m and 24h"})
+	}
+	if c.PasswordResetAccountLimit < 1 {
+		problems = append(problems, Problem{"PASSWORD_RESET_ACCOUNT_LIMIT", "must be at least 1"})
+	}
+	if c.PasswordResetIPLimit < 1 {
+		problems = append(problems, Problem{"PASSWORD_RESET_IP_LIMIT", "must be at least 1"})
+	}
+	if c.RegulatorExportKey != "" && !validEd25519Key(c.RegulatorExportKey) {
+		problems = append(problems, Problem{"REGULATOR_EXPORT_KEY", "must be a PEM encoded PKCS #8 Ed25519 private key"})
+	}
+
+	if c.UsernameChangeCooldown < 0 {
+		problems = append(problems, Problem{"USERNAME_CHANGE_COOLDOWN", "must not be negative"})
+	}
+	if c.UsernameReservation < 0 {
+		problems = append(problems, Problem{"USERNAME_RESERVATION", "must not be negative"})
+	}
+
+	// A higher KYC tier should never be allowed less than a lower one
+	for i, limit := range c.MaxExposure {
+		if limit < 0 || (i > 0 && limit != 0 && (limit < c.MaxExposure[i-1] || c.MaxExposure[i-1] == 0)) {
+			problems = append(problems, Problem{"MAX_EXPOSURE", "must not be negative and must not decrease with KYC tier"})
+			break
+		}
+	}
+	for role, limit := range c.MaxExposureRoles {
+		if !knownRoles[role] || limit < 0 {
+			problems = append(problems, Problem{"MAX_EXPOSURE_ROLES", "roles must be player, moderator, finance or admin and amounts must not be negative"})
+			break
+		}
+	}
+
+	for _, limit := range c.RebuyLimits {
+		if limit.Cooldown < 0 || limit.WarnPercent < 0 || limit.WarnPercent > 100 {
+			problems = append(problems, Problem{"REBUY_LIMITS", "cooldown must not be negative and warn_percent must be between 0 and 100"})
+			break
+		}
+	}
+
+	for _, fee := range c.WithdrawalFees {
+		if fee.KYCTier < 0 || fee.Minimum < 0 || fee.FlatFee < 0 || fee.FreeAbove < 0 {
+			problems = append(problems, Problem{"WITHDRAWAL_FEES", "must not contain negative tiers or amounts"})
+			break
+		}
+		// A fee of the whole amount would pay nothing out
+		if fee.FeeBps < 0 || fee.FeeBps >= 10000 {
+			problems = append(problems, Problem{"WITHDRAWAL_FEES", "fee_bps must be at least 0 and less than 10000"})
+			break
+		}
+	}
+
+	// A partial APNs setup fails on the first push rather than at startup
+	if c.APNsPrivateKey != "" {
+		require(c.APNsKeyID, "APNS_KEY_ID")
+		require(c.APNsTeamID, "APNS_TEAM_ID")
+		require(c.APNsBundleID, "APNS_BUNDLE_ID")
+	}
+
+	// Sign-in providers are either fully configured or off
+	if c.GoogleClientID != "" {
+		require(c.GoogleClientSecret, "GOOGLE_CLIENT_SECRET")
+	}
+	if c.AppleClientID != "" {
+		require(c.AppleTeamID, "APPLE_TEAM_ID")
+		require(c.AppleKeyID, "APPLE_KEY_ID")
+		require(c.ApplePrivateKey, "APPLE_PRIVATE_KEY")
+		require(c.AppleRedirectURL, "APPLE_REDIRECT_URL")
+	}
+	if c.GoogleClientID != "" || c.AppleClientID != "" {
+		require(c.OAuthCallbackURL, "OAUTH_CALLBACK_URL")
+	}
+
+	// The engine API is never served without mutual TLS
+	if c.EngineGRPCAddr != "" {
+		require(c.EngineGRPCCertFile, "ENGINE_GRPC_CERT_FILE")
+		require(c.EngineGRPCKeyFile, "ENGINE_GRPC_KEY_FILE")
+		require(c.EngineGRPCClientCAFile, "ENGINE_GRPC_CLIENT_CA_FILE")
+	}
+	for method := range c.EngineGRPCAllow {
+		if !engineMethods[method] {
+			problems = append(problems, Problem{"ENGINE_GRPC_ALLOW", "methods must be CreateTable, SeatPlayer, SubmitAction or StreamState"})
+			break
+		}
+	}
+
+	// Defaults that are fine locally must be overridden in production
+	if c.IsProduction() {
+		if c.JWTSecret == defaultJWTSecret {
+			problems = append(problems, Problem{"JWT_SECRET", "must be changed from the development default"})
+		}
+		if c.DatabaseURL == "" && c.PostgresPassword == "poker_password" {
+			problems = append(problems, Problem{"DATABASE_URL", "must be set, or POSTGRES_PASSWORD changed from the development default"})
+		}
+		require(c.FormanceAPIKey, "FORMANCE_API_KEY")
+		require(c.SMTPPassword, "SMTP_PASSWORD")
+		// Real players must never be handed play money
+		if c.Sandbox {
+			problems = append(problems, Problem{"SANDBOX", "must not be enabled in production"})
+		}
+	}
+
+	// Deployed environments must list their frontends explicitly
+	if c.Environment == "staging" || c.IsProduction() {
+		if len(c.AllowedOrigins) == 0 {
+			problems = append(problems, Problem{"ALLOWED_ORIGINS", "is required outside development"})
+		}
+		for _, origin := range append(append([]string{}, c.AllowedOrigins...), c.WSAllowedOrigins...) {
+			if origin == "*" {
+				problems = append(problems, Problem{"ALLOWED_ORIGINS", `"*" is only allowed in development`})
+				break
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// IsProduction reports whether the server runs with production settings
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// Setting is one configuration value prepared for display
+type Setting struct {
+	Var   string
+	Value string
+}
+
+// Summary lists the effective settings with secrets masked, for the
+// config self-test and startup diagnostics
+func (c *Config) Summary() []Setting {
+	return []Setting{
+		{"ENVIRONMENT", c.Environment},
+		{"LOG_FORMAT", c.LogFormat},
+		{"DATABASE_URL", redactURL(c.GetDatabaseURL())},
+		{"REDIS_URL", redactURL(c.RedisURL)},
+		{"REDIS_PASSWORD", mask(c.RedisPassword)},
+		{"PORT", c.Port},
+		{"INSTANCE_ID", c.InstanceID},
+		{"ALLOWED_ORIGINS", strings.Join(c.AllowedOrigins, ",")},
+		{"WS_ALLOWED_ORIGINS", strings.Join(c.WSAllowedOrigins, ",")},
+		{"WS_SEND_QUEUE_SIZE", strconv.Itoa(c.WSSendQueueSize)},
+		{"WS_RECONNECT_WINDOW", c.WSReconnectWindow.String()},
+		{"WS_LATENCY_ALLOWANCE", c.WSLatencyAllowance.String()},
+		{"PRACTICE_TABLE_PLAYERS", strconv.Itoa(c.PracticeTablePlayers)},
+		{"FLIGHT_RECORDER_ENTRIES", strconv.Itoa(c.FlightRecorderEntries)},
+		{"FLIGHT_RECORDER_DIR", c.FlightRecorderDir},
+		{"CHAT_BLOCKED_WORDS", strings.Join(c.ChatBlockedWords, ",")},
+		{"CHAT_RATE_LIMIT", strconv.Itoa(c.ChatRateLimit)},
+		{"CHAT_RATE_WINDOW", c.ChatRateWindow.String()},
+		{"JWT_SECRET", mask(c.JWTSecret)},
+		{"SESSION_IDLE_TIMEOUT", c.SessionIdleTimeout.String()},
+		{"SESSION_IDLE_TIMEOUT_ROLES", formatRoleDurations(c.SessionIdleTimeoutRoles)},
+		{"SESSION_MAX_LIFETIME", c.SessionMaxLifetime.String()},
+		{"PASSWORD_RESET_TTL", c.PasswordResetTTL.String()},
+		{"PASSWORD_RESET_ACCOUNT_LIMIT", strconv.Itoa(c.PasswordResetAccountLimit)},
+		{"PASSWORD_RESET_IP_LIMIT", strconv.Itoa(c.PasswordResetIPLimit)},
+		{"PASSWORD_RESET_WINDOW", c.PasswordResetWindow.String()},
+		{"REGULATOR_EXPORT_KEY", mask(c.RegulatorExportKey)},
+		{"METRICS_TOKEN", mask(c.MetricsToken)},
+		{"OAUTH_CALLBACK_URL", c.OAuthCallbackURL},
+		{"GOOGLE_CLIENT_ID", c.GoogleClientID},
+		{"GOOGLE_CLIENT_SECRET", mask(c.GoogleClientSecret)},
+		{"APPLE_CLIENT_ID", c.AppleClientID},
+		{"APPLE_TEAM_ID", c.AppleTeamID},
+		{"APPLE_KEY_ID", c.AppleKeyID},
+		{"APPLE_PRIVATE_KEY", mask(c.ApplePrivateKey)},
+		{"APPLE_REDIRECT_URL", c.AppleRedirectURL},
+		{"SMTP_HOST", c.SMTPHost},
+		{"SMTP_PORT", c.SMTPPort},
+		{"SMTP_USERNAME", c.SMTPUsername},
+		{"SMTP_PASSWORD", mask(c.SMTPPassword)},
+		{"SMTP_FROM", c.SMTPFrom},
+		{"FORMANCE_API_URL", c.FormanceAPIURL},
+		{"FORMANCE_API_KEY", mask(c.FormanceAPIKey)},
+		{"FORMANCE_LEDGER_NAME", c.FormanceLedgerName},
+		{"FORMANCE_CURRENCY", c.FormanceCurrency},
+		{"SANDBOX", strconv.FormatBool(c.Sandbox)},
+		{"NIGHTLY_WORKERS_HOUR", strconv.Itoa(c.NightlyWorkersHour)},
+		{"TABLE_AUTOSCALE_INTERVAL", c.TableAutoscaleInterval.String()},
+		{"BACKUP_MARKER_INTERVAL", c.BackupMarkerInterval.String()},
+		{"BACKUP_QUIESCE_TIMEOUT", c.BackupQuiesceTimeout.String()},
+		{"BACKUP_MANIFEST_DIR", c.BackupManifestDir},
+		{"SKILL_RATING_INTERVAL", c.SkillRatingInterval.String()},
+		{"QUICK_SEAT_LOW_STAKES_BIG_BLIND", strconv.FormatInt(c.QuickSeatLowStakesBigBlind, 10)},
+		{"MAINTENANCE_NOTICE_LEAD", c.MaintenanceNoticeLead.String()},
+		{"PUBLIC_FAIRNESS_REPORT", strconv.FormatBool(c.PublicFairnessReport)},
+		{"AFFILIATE_REVENUE_SHARE", strconv.FormatFloat(c.AffiliateRevenueShare, 'f', -1, 64)},
+		{"VELOCITY_DEPOSITS_PER_HOUR", strconv.Itoa(c.VelocityDepositsPerHour)},
+		{"VELOCITY_DEPOSITS_PER_DAY", strconv.Itoa(c.VelocityDepositsPerDay)},
+		{"VELOCITY_WITHDRAWALS_PER_DAY", strconv.Itoa(c.VelocityWithdrawalsPerDay)},
+		{"VELOCITY_WITHDRAWAL_COOLDOWN", c.VelocityWithdrawalCooldown.String()},
+		{"VELOCITY_DAILY_CAPS", formatAmounts(c.VelocityDailyCaps)},
+		{"WITHDRAWAL_FEES", formatWithdrawalFees(c.WithdrawalFees)},
+		{"USERNAME_CHANGE_COOLDOWN", c.UsernameChangeCooldown.String()},
+		{"USERNAME_RESERVATION", c.UsernameReservation.String()},
+		{"DEFAULT_JURISDICTION", c.DefaultJurisdiction},
+		{"REBUY_LIMITS", formatRebuyLimits(c.RebuyLimits)},
+		{"MAX_EXPOSURE", formatAmounts(c.MaxExposure)},
+		{"MAX_EXPOSURE_ROLES", formatRoleAmounts(c.MaxExposureRoles)},
+		{"HAND_HISTORY_STORE_MUCKED", strconv.FormatBool(c.HandHistoryStoreMucked)},
+		{"HAND_HISTORY_REVEAL_MUCKED", strconv.FormatBool(c.HandHistoryRevealMucked)},
+		{"HAND_HISTORY_ANONYMIZE_EXPORTS", strconv.FormatBool(c.HandHistoryAnonymizeExports)},
+		{"FCM_SERVER_KEY", mask(c.FCMServerKey)},
+		{"APNS_KEY_ID", c.APNsKeyID},
+		{"APNS_TEAM_ID", c.APNsTeamID},
+		{"APNS_BUNDLE_ID", c.APNsBundleID},
+		{"APNS_PRIVATE_KEY", mask(c.APNsPrivateKey)},
+		{"APNS_PRODUCTION", strconv.FormatBool(c.APNsProduction)},
+		{"STATS_EVENTS_NATS_URL", redactURL(c.StatsEventsNATSURL)},
+		{"STATS_EVENTS_SUBJECT_PREFIX", c.StatsEventsSubjectPrefix},
+		{"ENGINE_GRPC_ADDR", c.EngineGRPCAddr},
+		{"ENGINE_GRPC_CERT_FILE", c.EngineGRPCCertFile},
+		{"ENGINE_GRPC_KEY_FILE", c.EngineGRPCKeyFile},
+		{"ENGINE_GRPC_CLIENT_CA_FILE", c.EngineGRPCClientCAFile},
+		{"ENGINE_GRPC_ALLOW", formatMethodAllowList(c.EngineGRPCAllow)},
 	}
 }
 
@@ -92,9 +974,253 @@ func (c *Config) GetDatabaseURL() string {
 	)
 }
 
+// defaultInstanceID names the server after its host, falling back to the
+// process ID where the hostname is unavailable
+func defaultInstanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return fmt.Sprintf("instance-%d", os.Getpid())
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// splitList parses a comma separated variable, ignoring blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseAmounts parses a comma separated list of whole amounts
+func parseAmounts(value string) ([]int64, error) {
+	var amounts []int64
+	for _, item := range splitList(value) {
+		amount, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, amount)
+	}
+	return amounts, nil
+}
+
+func formatAmounts(amounts []int64) string {
+	items := make([]string, len(amounts))
+	for i, amount := range amounts {
+		items[i] = strconv.FormatInt(amount, 10)
+	}
+	return strings.Join(items, ",")
+}
+
+// parseWithdrawalFees parses the WITHDRAWAL_FEES schedule
+func parseWithdrawalFees(value string) ([]WithdrawalFee, error) {
+	var fees []WithdrawalFee
+	for _, item := range splitList(value) {
+		fields := strings.Split(item, ":")
+		if len(fields) != 6 || strings.TrimSpace(fields[0]) == "" {
+			return nil, fmt.Errorf("invalid withdrawal fee rule %q", item)
+		}
+		numbers := make([]int64, 5)
+		for i, field := range fields[1:] {
+			n, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid withdrawal fee rule %q: %w", item, err)
+			}
+			numbers[i] = n
+		}
+		fees = append(fees, WithdrawalFee{
+			Asset:     strings.TrimSpace(fields[0]),
+			KYCTier:   int(numbers[0]),
+			Minimum:   numbers[1],
+			FlatFee:   numbers[2],
+			FeeBps:    numbers[3],
+			FreeAbove: numbers[4],
+		})
+	}
+	return fees, nil
+}
+
+func formatWithdrawalFees(fees []WithdrawalFee) string {
+	items := make([]string, len(fees))
+	for i, fee := range fees {
+		items[i] = fmt.Sprintf("%s:%d:%d:%d:%d:%d", fee.Asset, fee.KYCTier, fee.Minimum, fee.FlatFee, fee.FeeBps, fee.FreeAbove)
+	}
+	return strings.Join(items, ",")
+}
+
+// parseRebuyLimits parses the REBUY_LIMITS rules
+func parseRebuyLimits(value string) ([]RebuyLimit, error) {
+	var limits []RebuyLimit
+	for _, item := range splitList(value) {
+		fields := strings.Split(item, ":")
+		if len(fields) != 3 || strings.TrimSpace(fields[0]) == "" {
+			return nil, fmt.Errorf("invalid rebuy limit %q", item)
+		}
+		cooldown, err := time.ParseDuration(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rebuy limit %q: %w", item, err)
+		}
+		percent, err := strconv.Atoi(strings.TrimSpace(fields[2]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rebuy limit %q: %w", item, err)
+		}
+		limits = append(limits, RebuyLimit{
+			Jurisdiction: strings.TrimSpace(fields[0]),
+			Cooldown:     cooldown,
+			WarnPercent:  percent,
+		})
+	}
+	return limits, nil
+}
+
+func formatRebuyLimits(limits []RebuyLimit) string {
+	items := make([]string, len(limits))
+	for i, limit := range limits {
+		items[i] = fmt.Sprintf("%s:%s:%d", limit.Jurisdiction, limit.Cooldown, limit.WarnPercent)
+	}
+	return strings.Join(items, ",")
+}
+
+// knownRoles are the user roles MAX_EXPOSURE_ROLES and
+// SESSION_IDLE_TIMEOUT_ROLES may name
+var knownRoles = map[string]bool{"player": true, "moderator": true, "finance": true, "admin": true}
+
+// parseRoleAmounts parses role:amount pairs such as "admin:0,finance:0"
+func parseRoleAmounts(value string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+	for _, item := range splitList(value) {
+		role, amount, ok := strings.Cut(item, ":")
+		if !ok || strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("invalid role amount %q", item)
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(amount), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role amount %q: %w", item, err)
+		}
+		amounts[strings.TrimSpace(role)] = parsed
+	}
+	return amounts, nil
+}
+
+func formatRoleAmounts(amounts map[string]int64) string {
+	items := make([]string, 0, len(amounts))
+	for role, amount := range amounts {
+		items = append(items, fmt.Sprintf("%s:%d", role, amount))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// parseRoleDurations parses role:duration pairs such as "admin:15m"
+func parseRoleDurations(value string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+	for _, item := range splitList(value) {
+		role, duration, ok := strings.Cut(item, ":")
+		if !ok || strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("invalid role duration %q", item)
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid role duration %q: %w", item, err)
+		}
+		durations[strings.TrimSpace(role)] = parsed
+	}
+	return durations, nil
+}
+
+func formatRoleDurations(durations map[string]time.Duration) string {
+	items := make([]string, 0, len(durations))
+	for role, duration := range durations {
+		items = append(items, fmt.Sprintf("%s:%s", role, duration))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// engineMethods are the engine API methods ENGINE_GRPC_ALLOW may name
+var engineMethods = map[string]bool{"CreateTable": true, "SeatPlayer": true, "SubmitAction": true, "StreamState": true}
+
+// parseMethodAllowList parses method:name pairs such as
+// "CreateTable:matchmaker,StreamState:*", one name per pair
+func parseMethodAllowList(value string) (map[string][]string, error) {
+	allow := make(map[string][]string)
+	for _, item := range splitList(value) {
+		method, name, ok := strings.Cut(item, ":")
+		method, name = strings.TrimSpace(method), strings.TrimSpace(name)
+		if !ok || method == "" || name == "" {
+			return nil, fmt.Errorf("invalid method allow rule %q", item)
+		}
+		allow[method] = append(allow[method], name)
+	}
+	return allow, nil
+}
+
+func formatMethodAllowList(allow map[string][]string) string {
+	var items []string
+	for method, names := range allow {
+		for _, name := range names {
+			items = append(items, method+":"+name)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// validEd25519Key reports whether the value is a PEM encoded PKCS #8 Ed25519
+// private key
+func validEd25519Key(value string) bool {
+	block, _ := pem.Decode([]byte(value))
+	if block == nil {
+		return false
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return false
+	}
+	_, ok := key.(ed25519.PrivateKey)
+	return ok
+}
+
+// validOriginPattern accepts "*", or scheme://host[:port] with at most one
+// wildcard and no path
+func validOriginPattern(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	rest, ok := strings.CutPrefix(origin, "https://")
+	if !ok {
+		rest, ok = strings.CutPrefix(origin, "http://")
+	}
+	return ok && rest != "" && strings.Count(origin, "*") <= 1 && !strings.ContainsAny(rest, "/?#@ ")
+}
+
+func mask(secret string) string {
+	if secret == "" {
+		return "(unset)"
+	}
+	return "********"
+}
+
+// redactURL hides the password in a user:password@host URL
+func redactURL(raw string) string {
+	scheme := strings.Index(raw, "://")
+	at := strings.LastIndex(raw, "@")
+	if scheme < 0 || at < scheme {
+		return raw
+	}
+	userinfo := raw[scheme+3 : at]
+	if colon := strings.Index(userinfo, ":"); colon >= 0 {
+		userinfo = userinfo[:colon+1] + "********"
+	}
+	return raw[:scheme+3] + userinfo + raw[at:]
+}
@@ -62,12 +62,64 @@ func (db *DB) AutoMigrate() error {
 	err := db.DB.AutoMigrate(
 		&models.User{},
 		&models.EmailVerification{},
+		&models.PasswordReset{},
 		&models.PokerTable{},
 		&models.Tournament{},
 		&models.TournamentRegistration{},
 		&models.GameSession{},
 		&models.LeaderboardEntry{},
 		&models.UserStatistics{},
+		&models.RakeContribution{},
+		&models.RakebackPayout{},
+		&models.HandHistory{},
+		&models.DeviceToken{},
+		&models.NotificationPreferences{},
+		&models.StakeTemplate{},
+		&models.DirectMessage{},
+		&models.UserBlock{},
+		&models.MessageReport{},
+		&models.SeatingSeparation{},
+		&models.TableBan{},
+		&models.PartialCashOut{},
+		&models.ColorUpEvent{},
+		&models.TutorialCompletion{},
+		&models.FairnessReport{},
+		&models.HandPresence{},
+		&models.ReferralCode{},
+		&models.Referral{},
+		&models.AffiliatePayout{},
+		&models.ModeratedMessage{},
+		&models.TableChatMute{},
+		&models.ChatMessage{},
+		&models.WalletOperation{},
+		&models.VelocityOverride{},
+		&models.ExposureOverride{},
+		&models.Friendship{},
+		&models.PresenceSettings{},
+		&models.UsernameHistory{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.SupportAccessGrant{},
+		&models.ImpersonationSession{},
+		&models.ImpersonationAction{},
+		&models.BankrollSettings{},
+		&models.HandPrivacySettings{},
+		&models.AccountMerge{},
+		&models.UserNote{},
+		&models.FeatureFlag{},
+		&models.HandAdjudication{},
+		&models.BackupMarker{},
+		&models.SkillRating{},
+		&models.RatedResult{},
+		&models.MaintenanceWindow{},
+		&models.SpinFormat{},
+		&models.SpinDraw{},
+		&models.LastLongerPool{},
+		&models.LastLongerEntry{},
+		&models.DepositReference{},
+		&models.BankStatementRow{},
+		&models.RakeFreeWindow{},
+		&models.SeatReservation{},
 	)
 
 	if err != nil {
@@ -43,6 +43,23 @@ func (db *DB) SetupIndexes() error {
 		return err
 	}
 
+	// Case-insensitive username lookups. Not unique, since existing names may
+	// differ only by case, and built concurrently so the users table isn't
+	// locked against writes while it builds.
+	if err := db.Exec(`
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_users_username_lower
+		ON users(LOWER(username))
+	`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_username_histories_old_username_lower
+		ON username_histories(LOWER(old_username), changed_at)
+	`).Error; err != nil {
+		return err
+	}
+
 	slog.Info("Additional database indexes created successfully")
 	return nil
 }
\ No newline at end of file
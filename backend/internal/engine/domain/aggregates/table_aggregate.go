@@ -2,6 +2,7 @@ package aggregates
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/anhbaysgalan1/gp/internal/engine/domain/events"
 	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
@@ -41,7 +42,11 @@ func NewTableAggregate(tableID uuid.UUID, name string, tableType table.TableType
 	}
 
 	// Apply table created event
-	event := events.NewTableCreated(tableID, name, maxPlayers, smallBlind, bigBlind, config.MaxBuyIn, nil)
+	metadata := map[string]string{
+		"game_type": string(aggregate.Table.Game.Variant),
+		"hi_lo":     strconv.FormatBool(aggregate.Table.Game.HiLo),
+	}
+	event := events.NewTableCreated(tableID, name, maxPlayers, smallBlind, bigBlind, config.MaxBuyIn, metadata)
 	aggregate.ApplyChange(event)
 
 	return aggregate
@@ -133,7 +138,7 @@ func (ta *TableAggregate) StartHand() error {
 	}
 
 	handID := *ta.Table.Game.HandID
-	activePlayers := ta.Table.Game.GetActivePlayers()
+	activePlayers := ta.Table.Game.GetDealtInPlayers()
 	playerIDs := make([]uuid.UUID, len(activePlayers))
 	for i, player := range activePlayers {
 		playerIDs[i] = player.ID
@@ -201,6 +206,10 @@ func (ta *TableAggregate) PlayerAction(playerID uuid.UUID, action string, amount
 			event = events.NewPlayerFold(ta.ID, *handID, playerID, ta.Version+1)
 		}
 	case "call", "bet", "raise":
+		// A call costs what the game says it does, whatever was asked for
+		if action == "call" {
+			amount = ta.Table.Game.Actions.CallAmount(ta.Table.Game, player)
+		}
 		// For betting actions, use the game engine to handle the bet
 		err = ta.Table.Game.Actions.PlayerBet(ta.Table.Game, playerIDStr, amount)
 		if err == nil {
@@ -250,7 +259,27 @@ func (ta *TableAggregate) applyEvent(event events.DomainEvent) {
 }
 
 func (ta *TableAggregate) applyTableCreated(event *events.TableCreated) {
-	// Table is already created in constructor
+	// Tables made by NewTableAggregate are already set up; tables loaded from
+	// history start from the event, including the game they deal
+	if ta.Table.Game != nil {
+		return
+	}
+	variant, err := game.ParseVariant(event.Metadata["game_type"])
+	if err != nil {
+		variant = game.VariantHoldem
+	}
+	ta.Table.ID = ta.ID
+	ta.Table.Name = event.TableName
+	ta.Table.MaxPlayers = event.MaxPlayers
+	ta.Table.SmallBlind = event.SmallBlind
+	ta.Table.BigBlind = event.BigBlind
+	ta.Table.MaxBuyIn = event.MaxBuyIn
+	ta.Table.Config.MaxBuyIn = event.MaxBuyIn
+	ta.Table.Config.GameType = variant
+	ta.Table.Config.HiLo = event.Metadata["hi_lo"] == "true"
+	ta.Table.Game = game.NewGame(ta.ID, event.SmallBlind, event.BigBlind, event.MaxPlayers)
+	ta.Table.Game.Variant = variant
+	ta.Table.Game.HiLo = ta.Table.Config.HiLo
 }
 
 func (ta *TableAggregate) applyPlayerJoined(event *events.PlayerJoined) {
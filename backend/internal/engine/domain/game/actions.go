@@ -19,7 +19,9 @@ var (
 )
 
 // GameActions provides methods for all poker game actions
-type GameActions struct{}
+type GameActions struct {
+	pots PotManager
+}
 
 // NewGameActions creates a new GameActions instance
 func NewGameActions() *GameActions {
@@ -40,23 +42,28 @@ func (ga *GameActions) DealCards(g *Game) error {
 	return ErrIllegalAction
 }
 
-// dealHoleCards deals 2 cards to each active player
+// dealHoleCards deals each active player the hole cards of the game's variant
 func (ga *GameActions) dealHoleCards(g *Game) error {
 	if g.Deck == nil {
 		g.Deck = NewDeck()
 	}
 
-	// Shuffle deck multiple times for randomness
-	for i := 0; i < 3; i++ {
-		g.Deck.Shuffle()
+	// Shuffle deck multiple times for randomness, unless it was stacked for
+	// this hand
+	if g.Deck.stacked {
+		g.Deck.stacked = false
+	} else {
+		for i := 0; i < 3; i++ {
+			g.Deck.Shuffle()
+		}
 	}
 
-	// Deal 2 cards to each active player
+	// Deal 2 cards to each player dealt in, 4 in Omaha
 	for _, player := range g.Players {
-		if player.IsActive {
-			player.HoleCards = []Card{
-				g.Deck.Deal(),
-				g.Deck.Deal(),
+		if player.IsDealtIn() {
+			player.HoleCards = make([]Card, g.Variant.HoleCards())
+			for i := range player.HoleCards {
+				player.HoleCards[i] = g.Deck.Deal()
 			}
 		}
 	}
@@ -117,15 +124,20 @@ func (ga *GameActions) PlayerBet(g *Game, playerID string, amount int64) error {
 		return ErrIllegalAction
 	}
 
-	// Validate amount
+	// Validate amount. A player short of a full call may call all in.
 	currentBet := g.GetCurrentBet()
-	callAmount := currentBet - player.CurrentBet
+	callAmount := ga.CallAmount(g, player)
 
 	if amount < callAmount {
 		return ErrIllegalAction
 	}
 
-	if amount > player.Chips {
+	// Pot limit games cap every bet at the pot, whatever the player's stack
+	if g.Variant.PotLimit() && amount > g.PotLimitMax(player) {
+		return ErrExceedsPotLimit
+	}
+
+	if amount >= player.Chips {
 		// All-in
 		amount = player.Chips
 		player.IsAllIn = true
@@ -138,8 +150,8 @@ func (ga *GameActions) PlayerBet(g *Game, playerID string, amount int64) error {
 	player.HasActed = true
 
 	// Update min raise if this is a raise
-	if amount > callAmount {
-		g.MinRaise = amount - callAmount
+	if player.CurrentBet > currentBet {
+		g.MinRaise = player.CurrentBet - currentBet
 		ga.resetActedFlags(g, playerID)
 	}
 
@@ -251,6 +263,35 @@ func (ga *GameActions) RemovePlayer(g *Game, playerID string) error {
 	return ErrPlayerNotInHand
 }
 
+// SitOut keeps a player's seat and chips but stops dealing them in. A player
+// in the current hand plays it out and sits out from the next one.
+func (ga *GameActions) SitOut(g *Game, playerID string) error {
+	player := ga.findPlayerByStringID(g, playerID)
+	if player == nil {
+		return ErrPlayerNotInHand
+	}
+	if !player.IsActive || player.SittingOut {
+		return ErrIllegalAction
+	}
+
+	player.SittingOut = true
+	return nil
+}
+
+// SitIn deals a player who was sitting out back in from the next hand
+func (ga *GameActions) SitIn(g *Game, playerID string) error {
+	player := ga.findPlayerByStringID(g, playerID)
+	if player == nil {
+		return ErrPlayerNotInHand
+	}
+	if !player.SittingOut || player.Chips == 0 {
+		return ErrIllegalAction
+	}
+
+	player.SittingOut = false
+	return nil
+}
+
 // StartHand initializes a new hand
 func (ga *GameActions) StartHand(g *Game) error {
 	if !g.CanStart() {
@@ -262,6 +303,14 @@ func (ga *GameActions) StartHand(g *Game) error {
 	g.IsRunning = true
 	g.HandNumber++
 
+	// Players sitting out keep their seat but sit the hand out folded
+	for _, player := range g.Players {
+		if player.IsActive && player.SittingOut {
+			player.IsFolded = true
+			player.HasActed = true
+		}
+	}
+
 	// Create new deck if needed
 	if g.Deck == nil {
 		g.Deck = NewDeck()
@@ -282,11 +331,18 @@ func (ga *GameActions) StartHand(g *Game) error {
 	return nil
 }
 
+// CallAmount is what the player must put in to call, capped at their stack
+func (ga *GameActions) CallAmount(g *Game, player *Player) int64 {
+	return ga.pots.CallAmount(g.GetCurrentBet(), player.CurrentBet, player.Chips)
+}
+
 // EndHand ends the current hand and distributes pots
 func (ga *GameActions) EndHand(g *Game) error {
-	// Calculate pots and winners
+	// Return any bet nobody called, then calculate pots and winners
+	ga.returnUncalledBet(g)
 	ga.calculatePots(g)
 	ga.evaluateWinners(g)
+	ga.awardPots(g)
 
 	// Reset for next hand
 	g.Stage = PreDeal
@@ -339,7 +395,7 @@ func (ga *GameActions) resetActedFlags(g *Game, raisingPlayerID string) {
 }
 
 func (ga *GameActions) setPositions(g *Game) {
-	activePlayers := g.GetActivePlayers()
+	activePlayers := g.GetDealtInPlayers()
 	if len(activePlayers) < 2 {
 		return
 	}
@@ -408,73 +464,61 @@ func (ga *GameActions) getNextActionSeat(g *Game, currentSeat int) int {
 	return currentSeat
 }
 
-func (ga *GameActions) calculatePots(g *Game) {
-	// Implementation of pot calculation with side pots
-	// This is complex logic that handles all-in situations
-	g.Pots = []Pot{} // Reset pots
-
-	// Get all players with bets
-	playersWithBets := []*Player{}
+// contributions lists what each player has put in the pot this hand
+func (ga *GameActions) contributions(g *Game) []Contribution {
+	contributions := make([]Contribution, 0, len(g.Players))
 	for _, player := range g.Players {
 		if player.TotalBet > 0 {
-			playersWithBets = append(playersWithBets, player)
+			contributions = append(contributions, Contribution{
+				PlayerID: player.ID,
+				Amount:   player.TotalBet,
+				InHand:   player.IsInHand(),
+			})
 		}
 	}
+	return contributions
+}
 
-	if len(playersWithBets) == 0 {
-		return
-	}
-
-	// Sort players by total bet amount
-	sort.Slice(playersWithBets, func(i, j int) bool {
-		return playersWithBets[i].TotalBet < playersWithBets[j].TotalBet
-	})
-
-	// Create pots for each betting level
-	prevBetLevel := int64(0)
-	for i, player := range playersWithBets {
-		betLevel := player.TotalBet
-		if betLevel > prevBetLevel {
-			// Create pot for this betting level
-			potAmount := (betLevel - prevBetLevel) * int64(len(playersWithBets)-i)
-
-			eligiblePlayers := []uuid.UUID{}
-			for j := i; j < len(playersWithBets); j++ {
-				if playersWithBets[j].IsInHand() {
-					eligiblePlayers = append(eligiblePlayers, playersWithBets[j].ID)
-				}
-			}
-
-			if potAmount > 0 && len(eligiblePlayers) > 0 {
-				pot := Pot{
-					ID:              uuid.New(),
-					Amount:          potAmount,
-					EligiblePlayers: eligiblePlayers,
-					IsSidePot:       len(g.Pots) > 0,
-				}
-				g.Pots = append(g.Pots, pot)
-			}
-
-			prevBetLevel = betLevel
+// returnUncalledBet gives back the part of a bet nobody called, so it is
+// never won from a pot
+func (ga *GameActions) returnUncalledBet(g *Game) {
+	playerID, amount := ga.pots.UncalledBet(ga.contributions(g))
+	if player := g.GetPlayer(playerID); player != nil && amount > 0 {
+		player.Chips += amount
+		player.TotalBet -= amount
+		player.CurrentBet -= amount
+		if player.CurrentBet < 0 {
+			player.CurrentBet = 0
 		}
+		player.IsAllIn = false
 	}
 }
 
-func (ga *GameActions) evaluateWinners(g *Game) {
-	if len(g.CommunityCards) != 5 {
-		return // Can't evaluate without all community cards
-	}
+func (ga *GameActions) calculatePots(g *Game) {
+	g.Pots = ga.pots.Pots(ga.contributions(g))
+}
 
+func (ga *GameActions) evaluateWinners(g *Game) {
 	for i := range g.Pots {
 		pot := &g.Pots[i]
+
+		// A pot only one player can win needs no showdown
+		if len(pot.EligiblePlayers) == 1 {
+			pot.WinningPlayers = pot.EligiblePlayers
+			continue
+		}
+		if len(g.CommunityCards) != 5 {
+			continue // Can't evaluate without all community cards
+		}
+
 		bestScore := int(^uint(0) >> 1) // Max int
 		winners := []uuid.UUID{}
 
 		// Evaluate each eligible player's hand
 		for _, playerID := range pot.EligiblePlayers {
 			player := g.GetPlayer(playerID)
-			if player != nil && len(player.HoleCards) == 2 {
-				_, score, handRank := EvaluateHand(player.HoleCards, g.CommunityCards)
+			if player != nil && len(player.HoleCards) == g.Variant.HoleCards() {
+				_, score, handRank := g.Variant.Evaluate(player.HoleCards, g.CommunityCards)
 
 				if score < bestScore {
 					bestScore = score
@@ -488,6 +532,69 @@ func (ga *GameActions) evaluateWinners(g *Game) {
 		}
 
 		pot.WinningPlayers = winners
+		if g.HiLo {
+			ga.evaluateLow(g, pot)
+		}
+	}
+}
+
+// evaluateLow finds the players with the best qualifying low in a pot of a
+// hi-lo game. Nothing is declared: the low goes by the cards.
+func (ga *GameActions) evaluateLow(g *Game, pot *Pot) {
+	bestScore := 0
+	for _, playerID := range pot.EligiblePlayers {
+		player := g.GetPlayer(playerID)
+		if player == nil || len(player.HoleCards) != g.Variant.HoleCards() {
+			continue
+		}
+		hand, score, ok := g.Variant.EvaluateLow(player.HoleCards, g.CommunityCards)
+		if !ok {
+			continue
+		}
+		if len(pot.LowPlayers) == 0 || score < bestScore {
+			bestScore = score
+			pot.LowPlayers = []uuid.UUID{playerID}
+			pot.LowHand = hand
+		} else if score == bestScore {
+			pot.LowPlayers = append(pot.LowPlayers, playerID)
+		}
+	}
+}
+
+// awardPots pays each pot to its winners. In a hi-lo game with a qualifying
+// low the high and low hands take half each, the odd chip going to the high
+// half, so a player tied for one half is quartered. Odd chips left over from
+// a split go to the winners closest to the dealer's left.
+func (ga *GameActions) awardPots(g *Game) {
+	for _, pot := range g.Pots {
+		high := ga.fromDealersLeft(g, pot.WinningPlayers)
+		if len(pot.LowPlayers) == 0 {
+			ga.payShares(g, ga.pots.Split(pot.Amount, high))
+			continue
+		}
+		lowHalf := pot.Amount / 2
+		ga.payShares(g, ga.pots.Split(pot.Amount-lowHalf, high))
+		ga.payShares(g, ga.pots.Split(lowHalf, ga.fromDealersLeft(g, pot.LowPlayers)))
 	}
 }
 
+func (ga *GameActions) payShares(g *Game, shares map[uuid.UUID]int64) {
+	for playerID, share := range shares {
+		if player := g.GetPlayer(playerID); player != nil {
+			player.Chips += share
+		}
+	}
+}
+
+// fromDealersLeft orders players by how soon after the dealer they act
+func (ga *GameActions) fromDealersLeft(g *Game, playerIDs []uuid.UUID) []uuid.UUID {
+	dealtIn := g.GetDealtInPlayers()
+	order := make(map[uuid.UUID]int, len(dealtIn))
+	for i, player := range dealtIn {
+		order[player.ID] = (i - g.DealerSeat - 1 + 2*len(dealtIn)) % len(dealtIn)
+	}
+
+	ordered := append([]uuid.UUID{}, playerIDs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return order[ordered[i]] < order[ordered[j]] })
+	return ordered
+}
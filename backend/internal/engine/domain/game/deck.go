@@ -9,8 +9,9 @@ import (
 
 // Deck represents a deck of playing cards
 type Deck struct {
-	cards []Card
-	index int
+	cards   []Card
+	index   int
+	stacked bool // Dealt as arranged by Stack instead of shuffled
 }
 
 // NewDeck creates a new standard 52-card deck
@@ -50,6 +51,36 @@ func (d *Deck) Shuffle() {
 	}
 }
 
+// Stack arranges the deck for the next hand instead of shuffling it, for
+// scripted deals. Cards come off the deck in the order given, including burn
+// cards, and the rest of the deck is shuffled behind them.
+func (d *Deck) Stack(order []Card) error {
+	full := NewDeck()
+	known := make(map[Card]bool, len(full.cards))
+	for _, card := range full.cards {
+		known[card] = true
+	}
+	for _, card := range order {
+		if !known[card] {
+			return ErrIllegalAction
+		}
+		known[card] = false
+	}
+
+	var rest []Card
+	for _, card := range full.cards {
+		if known[card] {
+			rest = append(rest, card)
+		}
+	}
+	rand.Shuffle(len(rest), func(i, j int) { rest[i], rest[j] = rest[j], rest[i] })
+
+	d.cards = append(append([]Card{}, order...), rest...)
+	d.index = 0
+	d.stacked = true
+	return nil
+}
+
 // Deal returns the next card from the deck
 func (d *Deck) Deal() Card {
 	if d.index >= len(d.cards) {
@@ -74,58 +105,18 @@ func (d *Deck) RemainingCards() int {
 
 // ToRiverboatCard converts our Card to riverboat Card for hand evaluation
 func ToRiverboatCard(card Card) eval.Card {
-	// Convert our card format to riverboat's format
-	// This is a mapping function between our domain model and the evaluation library
-
-	var suit int
+	// Riverboat packs rank, suit and a prime per rank into its cards, so go
+	// through its own parser rather than building the bits here
+	suit := "S"
 	switch card.Suit {
-	case "♠":
-		suit = 0
 	case "♥":
-		suit = 1
+		suit = "H"
 	case "♦":
-		suit = 2
+		suit = "D"
 	case "♣":
-		suit = 3
-	default:
-		suit = 0
+		suit = "C"
 	}
-
-	var rank int
-	switch card.Rank {
-	case "2":
-		rank = 0
-	case "3":
-		rank = 1
-	case "4":
-		rank = 2
-	case "5":
-		rank = 3
-	case "6":
-		rank = 4
-	case "7":
-		rank = 5
-	case "8":
-		rank = 6
-	case "9":
-		rank = 7
-	case "T":
-		rank = 8
-	case "J":
-		rank = 9
-	case "Q":
-		rank = 10
-	case "K":
-		rank = 11
-	case "A":
-		rank = 12
-	default:
-		rank = 0
-	}
-
-	// Create riverboat card using bit representation
-	// Riverboat Card format: rank + suit*13
-	return eval.Card(rank + suit*13)
+	return eval.MustParseCardString(card.Rank + suit)
 }
 
 // EvaluateHand evaluates the best 5-card hand from 7 cards (2 hole + 5 community)
@@ -158,16 +149,32 @@ func EvaluateHand(holeCards []Card, communityCards []Card) ([]Card, int, string)
 	return resultCards, score, handRank
 }
 
+// ParseCard reads a card written the riverboat way, e.g. "As" or "Td"
+func ParseCard(s string) (Card, error) {
+	rbCard, err := eval.ParseCardBytes([]byte(s))
+	if err != nil {
+		return Card{}, err
+	}
+	return fromRiverboatCard(rbCard), nil
+}
+
 // fromRiverboatCard converts riverboat Card back to our Card
 func fromRiverboatCard(rbCard eval.Card) Card {
-	suits := []string{"♠", "♥", "♦", "♣"}
 	ranks := []string{"2", "3", "4", "5", "6", "7", "8", "9", "T", "J", "Q", "K", "A"}
 
-	suit := int(rbCard) % 4
-	rank := int(rbCard) / 4
+	rank := (int(rbCard) >> 8) & 0x0F
+	suit := "♠"
+	switch int(rbCard) & 0xF000 {
+	case 0x2000:
+		suit = "♥"
+	case 0x4000:
+		suit = "♦"
+	case 0x8000:
+		suit = "♣"
+	}
 
 	return Card{
-		Suit:  suits[suit],
+		Suit:  suit,
 		Rank:  ranks[rank],
 		Value: rank + 2,
 	}
@@ -175,19 +182,18 @@ func fromRiverboatCard(rbCard eval.Card) Card {
 
 // getHandRankName converts score to hand ranking name
 func getHandRankName(score int) string {
-	// Riverboat uses lower scores for better hands
-	// This is a simplified mapping - you may want to use riverboat's own classification
-	if score <= 10 {
+	// Riverboat uses lower scores for better hands, 1 being a royal flush
+	if score == 1 {
 		return "Royal Flush"
-	} else if score <= 166 {
+	} else if score <= 10 {
 		return "Straight Flush"
-	} else if score <= 322 {
+	} else if score <= 166 {
 		return "Four of a Kind"
-	} else if score <= 1599 {
+	} else if score <= 322 {
 		return "Full House"
-	} else if score <= 1609 {
+	} else if score <= 1599 {
 		return "Flush"
-	} else if score <= 1619 {
+	} else if score <= 1609 {
 		return "Straight"
 	} else if score <= 2467 {
 		return "Three of a Kind"
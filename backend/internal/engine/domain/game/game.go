@@ -58,6 +58,10 @@ type Pot struct {
 	WinningPlayers   []uuid.UUID `json:"winning_players,omitempty"`
 	WinningHand      []Card      `json:"winning_hand,omitempty"`
 	HandRank         string      `json:"hand_rank,omitempty"`
+	// Hi-lo games only: the players with the best qualifying low, who win
+	// half the pot
+	LowPlayers       []uuid.UUID `json:"low_players,omitempty"`
+	LowHand          []Card      `json:"low_hand,omitempty"`
 	IsSidePot        bool        `json:"is_side_pot"`
 	MaxContribution  int64       `json:"max_contribution,omitempty"`
 }
@@ -75,6 +79,7 @@ type Player struct {
 	IsFolded       bool      `json:"is_folded"`
 	IsAllIn        bool      `json:"is_all_in"`
 	HasActed       bool      `json:"has_acted"`
+	SittingOut     bool      `json:"sitting_out"` // Keeps the seat but is not dealt in
 	SessionID      uuid.UUID `json:"session_id"`
 	Position       PlayerPosition `json:"position"`
 }
@@ -92,6 +97,11 @@ func (p *Player) CanAct() bool {
 	return p.IsActive && !p.IsFolded && !p.IsAllIn
 }
 
+// IsDealtIn returns true if the player is dealt into the next hand
+func (p *Player) IsDealtIn() bool {
+	return p.IsActive && !p.SittingOut
+}
+
 // IsInHand returns true if the player is still in the current hand
 func (p *Player) IsInHand() bool {
 	return p.IsActive && !p.IsFolded
@@ -106,6 +116,8 @@ type Game struct {
 	CommunityCards []Card        `json:"community_cards"`
 	Pots           []Pot         `json:"pots"`
 	Stage          GameStage     `json:"stage"`
+	Variant        Variant       `json:"variant"`
+	HiLo           bool          `json:"hi_lo"` // Pots are split between the high hand and a qualifying low
 	IsRunning      bool          `json:"is_running"`
 	DealerSeat     int           `json:"dealer_seat"`
 	ActionSeat     int           `json:"action_seat"`
@@ -127,6 +139,7 @@ func NewGame(tableID uuid.UUID, smallBlind, bigBlind int64, maxPlayers int) *Gam
 		CommunityCards: make([]Card, 0, 5),
 		Pots:           make([]Pot, 0),
 		Stage:          PreDeal,
+		Variant:        VariantHoldem,
 		IsRunning:      false,
 		DealerSeat:     0,
 		ActionSeat:     0,
@@ -171,6 +184,17 @@ func (g *Game) GetActivePlayers() []*Player {
 	return activePlayers
 }
 
+// GetDealtInPlayers returns the active players who are not sitting out
+func (g *Game) GetDealtInPlayers() []*Player {
+	dealtIn := make([]*Player, 0)
+	for _, player := range g.Players {
+		if player.IsDealtIn() {
+			dealtIn = append(dealtIn, player)
+		}
+	}
+	return dealtIn
+}
+
 // GetPlayersInHand returns all players still in the current hand
 func (g *Game) GetPlayersInHand() []*Player {
 	playersInHand := make([]*Player, 0)
@@ -204,8 +228,7 @@ func (g *Game) GetTotalPot() int64 {
 
 // CanStart returns true if the game can be started
 func (g *Game) CanStart() bool {
-	activePlayers := g.GetActivePlayers()
-	return len(activePlayers) >= 2 && !g.IsRunning
+	return len(g.GetDealtInPlayers()) >= 2 && !g.IsRunning
 }
 
 // SortPlayersByPosition sorts players by their seat number
@@ -215,9 +238,9 @@ func (g *Game) SortPlayersByPosition() {
 	})
 }
 
-// GetNextActiveSeat returns the next active seat after the given seat
+// GetNextActiveSeat returns the next seat dealt in after the given seat
 func (g *Game) GetNextActiveSeat(currentSeat int) int {
-	activePlayers := g.GetActivePlayers()
+	activePlayers := g.GetDealtInPlayers()
 	if len(activePlayers) == 0 {
 		return currentSeat
 	}
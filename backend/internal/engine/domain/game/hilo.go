@@ -0,0 +1,60 @@
+package game
+
+import "sort"
+
+// lowQualifier is the highest card a low hand may hold: eight or better
+const lowQualifier = 8
+
+// lowValue is a card's value in a low hand, where the ace counts as one
+func lowValue(c Card) int {
+	if c.Value == 14 {
+		return 1
+	}
+	return c.Value
+}
+
+// lowScore scores five cards as a low hand. A low needs five different
+// ranks, all eight or under; straights and flushes don't count against it.
+// Lower scores are better: the highest card is compared first, then the next.
+func lowScore(cards []Card) (int, bool) {
+	values := make([]int, 0, len(cards))
+	seen := 0
+	for _, c := range cards {
+		v := lowValue(c)
+		if v > lowQualifier || seen&(1<<v) != 0 {
+			return 0, false
+		}
+		seen |= 1 << v
+		values = append(values, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(values)))
+
+	score := 0
+	for _, v := range values {
+		score = score<<4 | v
+	}
+	return score, true
+}
+
+// EvaluateLow finds the best qualifying low a player can make with the full
+// board and its score (lower is better). Omaha lows use exactly two hole
+// cards, as high hands do. ok is false when the player has no low.
+func (v Variant) EvaluateLow(holeCards []Card, communityCards []Card) (hand []Card, score int, ok bool) {
+	consider := func(cards []Card) {
+		if s, qualifies := lowScore(cards); qualifies && (!ok || s < score) {
+			hand = append([]Card{}, cards...)
+			score, ok = s, true
+		}
+	}
+
+	if v == VariantOmaha {
+		eachCombination(holeCards, 2, func(fromHand []Card) {
+			eachCombination(communityCards, 3, func(fromBoard []Card) {
+				consider(append(append([]Card{}, fromHand...), fromBoard...))
+			})
+		})
+		return hand, score, ok
+	}
+	eachCombination(append(append([]Card{}, holeCards...), communityCards...), 5, consider)
+	return hand, score, ok
+}
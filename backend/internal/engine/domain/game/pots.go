@@ -0,0 +1,140 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Contribution is what one player has put into the pot this hand
+type Contribution struct {
+	PlayerID uuid.UUID
+	Amount   int64
+	InHand   bool // Still able to win; a folded player's chips are dead
+}
+
+// PotManager is the one place the pot is worked out: how much a call costs,
+// the part of a bet nobody called, the main and side pots all-ins leave, and
+// who gets the odd chips of a split. It keeps no state, so the zero value is
+// ready to use.
+type PotManager struct{}
+
+// CallAmount is what a player who has bet playerBet this round must put in
+// to call currentBet. A player who can't cover it calls all in for their
+// stack.
+func (PotManager) CallAmount(currentBet, playerBet, stack int64) int64 {
+	toCall := currentBet - playerBet
+	if toCall < 0 {
+		toCall = 0
+	}
+	if toCall > stack {
+		toCall = stack
+	}
+	return toCall
+}
+
+// UncalledBet finds the part of the biggest contribution that nobody else
+// matched, which goes back to the player who put it in. It returns uuid.Nil
+// and 0 when the top contribution was matched.
+func (PotManager) UncalledBet(contributions []Contribution) (uuid.UUID, int64) {
+	var top, second int64
+	var topPlayer uuid.UUID
+	for _, c := range contributions {
+		switch {
+		case c.Amount > top:
+			second = top
+			top = c.Amount
+			topPlayer = c.PlayerID
+		case c.Amount > second:
+			second = c.Amount
+		}
+	}
+	if top == second {
+		return uuid.Nil, 0
+	}
+	return topPlayer, top - second
+}
+
+// Pots splits the contributions into a main pot and side pots, one for each
+// level an all-in player capped. A pot can be won by the players still in
+// hand who paid all of it. Chips from folded players above the last level
+// anyone still in reached go into the pot below, and neighbouring levels the
+// same players can win are one pot. Uncalled bets should be returned first.
+func (PotManager) Pots(contributions []Contribution) []Pot {
+	sorted := make([]Contribution, 0, len(contributions))
+	for _, c := range contributions {
+		if c.Amount > 0 {
+			sorted = append(sorted, c)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Amount < sorted[j].Amount })
+
+	pots := []Pot{}
+	var level, deadChips int64
+	for i, c := range sorted {
+		if c.Amount == level {
+			continue
+		}
+		amount := (c.Amount - level) * int64(len(sorted)-i)
+
+		eligible := []uuid.UUID{}
+		for _, above := range sorted[i:] {
+			if above.InHand {
+				eligible = append(eligible, above.PlayerID)
+			}
+		}
+
+		switch {
+		case len(eligible) == 0 && len(pots) > 0:
+			pots[len(pots)-1].Amount += amount
+			pots[len(pots)-1].MaxContribution = c.Amount
+		case len(eligible) == 0:
+			deadChips += amount
+		case len(pots) > 0 && sameIDs(pots[len(pots)-1].EligiblePlayers, eligible):
+			pots[len(pots)-1].Amount += amount
+			pots[len(pots)-1].MaxContribution = c.Amount
+		default:
+			pots = append(pots, Pot{
+				ID:              uuid.New(),
+				Amount:          amount + deadChips,
+				EligiblePlayers: eligible,
+				IsSidePot:       len(pots) > 0,
+				MaxContribution: c.Amount,
+			})
+			deadChips = 0
+		}
+		level = c.Amount
+	}
+	return pots
+}
+
+// Split divides a pot between its winners, who must be listed in order from
+// the dealer's left. Chips that don't divide evenly go one each to the
+// first winners.
+func (PotManager) Split(amount int64, winners []uuid.UUID) map[uuid.UUID]int64 {
+	shares := make(map[uuid.UUID]int64, len(winners))
+	if len(winners) == 0 {
+		return shares
+	}
+	share := amount / int64(len(winners))
+	oddChips := amount % int64(len(winners))
+	for i, playerID := range winners {
+		shares[playerID] += share
+		if int64(i) < oddChips {
+			shares[playerID]++
+		}
+	}
+	return shares
+}
+
+func sameIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// Variant is the poker game dealt at a table
+type Variant string
+
+const (
+	// Texas Hold'em, no limit: two hole cards, any five of seven
+	VariantHoldem Variant = "texas_holdem"
+	// Pot limit Omaha: four hole cards, exactly two of them with three from
+	// the board
+	VariantOmaha Variant = "omaha"
+)
+
+var (
+	ErrUnsupportedVariant = errors.New("unsupported game variant")
+	ErrExceedsPotLimit    = errors.New("bet exceeds pot limit")
+)
+
+// ParseVariant returns the variant for a table's game type. An empty game
+// type is Hold'em.
+func ParseVariant(gameType string) (Variant, error) {
+	switch Variant(gameType) {
+	case "", VariantHoldem:
+		return VariantHoldem, nil
+	case VariantOmaha:
+		return VariantOmaha, nil
+	}
+	return "", ErrUnsupportedVariant
+}
+
+// HoleCards is how many cards each player is dealt
+func (v Variant) HoleCards() int {
+	if v == VariantOmaha {
+		return 4
+	}
+	return 2
+}
+
+// PotLimit reports whether a bet may be no bigger than the pot
+func (v Variant) PotLimit() bool {
+	return v == VariantOmaha
+}
+
+// Evaluate finds the best five card hand a player can make with the full
+// board, its score (lower is better) and its name
+func (v Variant) Evaluate(holeCards []Card, communityCards []Card) ([]Card, int, string) {
+	if v == VariantOmaha {
+		return EvaluateOmahaHand(holeCards, communityCards)
+	}
+	return EvaluateHand(holeCards, communityCards)
+}
+
+// EvaluateOmahaHand evaluates the best 5-card hand using exactly 2 of the 4
+// hole cards and 3 of the 5 community cards
+func EvaluateOmahaHand(holeCards []Card, communityCards []Card) ([]Card, int, string) {
+	if len(holeCards) != 4 || len(communityCards) != 5 {
+		return nil, 0, "invalid"
+	}
+
+	var best []Card
+	bestScore := 0
+	eachCombination(holeCards, 2, func(fromHand []Card) {
+		eachCombination(communityCards, 3, func(fromBoard []Card) {
+			hand := append(append([]Card{}, fromHand...), fromBoard...)
+			score := eval.HandValue(ToRiverboatCard(hand[0]), ToRiverboatCard(hand[1]), ToRiverboatCard(hand[2]), ToRiverboatCard(hand[3]), ToRiverboatCard(hand[4]))
+			if best == nil || score < bestScore {
+				best, bestScore = hand, score
+			}
+		})
+	})
+	return best, bestScore, getHandRankName(bestScore)
+}
+
+// eachCombination calls fn with every way of choosing k of the cards, in a
+// slice fn must not keep
+func eachCombination(cards []Card, k int, fn func([]Card)) {
+	chosen := make([]Card, 0, k)
+	var choose func(start int)
+	choose = func(start int) {
+		if len(chosen) == k {
+			fn(chosen)
+			return
+		}
+		for i := start; i <= len(cards)-(k-len(chosen)); i++ {
+			chosen = append(chosen, cards[i])
+			choose(i + 1)
+			chosen = chosen[:len(chosen)-1]
+		}
+	}
+	choose(0)
+}
+
+// PotLimitMax is the most a player may put in with one bet in a pot limit
+// game: enough to call, plus the pot as it will stand after calling
+func (g *Game) PotLimitMax(player *Player) int64 {
+	toCall := g.GetCurrentBet() - player.CurrentBet
+
+	var pot int64
+	for _, p := range g.Players {
+		pot += p.TotalBet
+	}
+	return 2*toCall + pot
+}
@@ -61,6 +61,8 @@ type TableConfig struct {
 	IsPrivate       bool          `json:"is_private"`
 	RakePercentage  float64       `json:"rake_percentage,omitempty"`
 	MaxRake         int64         `json:"max_rake,omitempty"`
+	GameType        game.Variant  `json:"game_type,omitempty"` // Hold'em when empty
+	HiLo            bool          `json:"hi_lo,omitempty"`     // See game.Game.HiLo
 }
 
 // Seat represents a seat at the table
@@ -105,6 +107,13 @@ func NewTable(name string, tableType TableType, maxPlayers int, smallBlind, bigB
 		config.ActionTimeout = 30 * time.Second
 	}
 
+	if config.GameType == "" {
+		config.GameType = game.VariantHoldem
+	}
+	tableGame := game.NewGame(tableID, smallBlind, bigBlind, maxPlayers)
+	tableGame.Variant = config.GameType
+	tableGame.HiLo = config.HiLo
+
 	return &Table{
 		ID:         tableID,
 		Name:       name,
@@ -117,7 +126,7 @@ func NewTable(name string, tableType TableType, maxPlayers int, smallBlind, bigB
 		MinBuyIn:   config.MinBuyIn,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
-		Game:       game.NewGame(tableID, smallBlind, bigBlind, maxPlayers),
+		Game:       tableGame,
 		Config:     config,
 		Metadata:   make(map[string]string),
 	}
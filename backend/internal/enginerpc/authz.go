@@ -0,0 +1,86 @@
+package enginerpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AnyCaller in a Policy allows every caller with a valid certificate
+const AnyCaller = "*"
+
+// Policy lists, by method name, the identities allowed to call it. An
+// identity is a client certificate's common name or one of its DNS names.
+// Methods missing from the policy can't be called at all.
+type Policy map[string][]string
+
+// Allows reports whether a caller with any of the identities may call the
+// method
+func (p Policy) Allows(method string, identities []string) bool {
+	for _, allowed := range p[method] {
+		if allowed == AnyCaller {
+			return true
+		}
+		for _, identity := range identities {
+			if identity == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callerIdentities returns the names on the verified client certificate of
+// the call
+func callerIdentities(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := info.State.VerifiedChains[0][0]
+	var identities []string
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	return append(identities, cert.DNSNames...)
+}
+
+// authorize checks the caller of fullMethod against the policy
+func (s *Server) authorize(ctx context.Context, fullMethod string) error {
+	identities := callerIdentities(ctx)
+	if len(identities) == 0 {
+		return status.Error(codes.Unauthenticated, "a verified client certificate is required")
+	}
+
+	method := strings.TrimPrefix(fullMethod, "/"+ServiceName+"/")
+	if !s.policy.Allows(method, identities) {
+		slog.Warn("Engine API call denied", "method", method, "caller", identities)
+		return status.Errorf(codes.PermissionDenied, "%s may not call %s", identities[0], method)
+	}
+	return nil
+}
+
+func (s *Server) unaryAuthorization(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuthorization(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
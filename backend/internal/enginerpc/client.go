@@ -0,0 +1,103 @@
+package enginerpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Client calls the engine API from another Go service
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the engine API at target. tlsConfig carries the
+// caller's certificate, as ClientTLSConfig's does.
+func Dial(target string, tlsConfig *tls.Config) (*Client, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial engine API: %w", err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// ClientTLSConfig loads the caller's certificate and the CA the server's
+// certificate must be signed by
+func ClientTLSConfig(certFile, keyFile, serverCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load engine API client certificate: %w", err)
+	}
+	rootCAs, err := loadCertPool(serverCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) CreateTable(ctx context.Context, req *CreateTableRequest) (*CreateTableResponse, error) {
+	out := new(CreateTableResponse)
+	if err := c.conn.Invoke(ctx, fullMethod(MethodCreateTable), req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) SeatPlayer(ctx context.Context, req *SeatPlayerRequest) (*SeatPlayerResponse, error) {
+	out := new(SeatPlayerResponse)
+	if err := c.conn.Invoke(ctx, fullMethod(MethodSeatPlayer), req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) SubmitAction(ctx context.Context, req *SubmitActionRequest) (*SubmitActionResponse, error) {
+	out := new(SubmitActionResponse)
+	if err := c.conn.Invoke(ctx, fullMethod(MethodSubmitAction), req, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamState calls receive with every update for the table until ctx is
+// done, the server ends the stream or receive returns an error
+func (c *Client) StreamState(ctx context.Context, req *StreamStateRequest, receive func(*StateUpdate) error) error {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], fullMethod(MethodStreamState))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		update := new(StateUpdate)
+		if err := stream.RecvMsg(update); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := receive(update); err != nil {
+			return err
+		}
+	}
+}
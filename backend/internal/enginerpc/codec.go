@@ -0,0 +1,21 @@
+package enginerpc
+
+import "encoding/json"
+
+// codecName is the content subtype messages are sent with
+const codecName = "json"
+
+// jsonCodec carries the service's messages as JSON in place of protobuf
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
@@ -0,0 +1,84 @@
+// Package enginerpc serves the table engine over gRPC to other services,
+// such as dedicated game servers and matchmakers, so they can create
+// tables, seat players, act for them and follow play without going through
+// the public WebSocket layer.
+//
+// The service is gp.engine.v1.Engine with the methods CreateTable,
+// SeatPlayer, SubmitAction and the server-streaming StreamState. Messages
+// are the JSON encoded types in this package, carried with the "json"
+// content subtype, so callers in any language need only a gRPC library.
+//
+// Every caller presents a client certificate signed by the configured CA
+// (mutual TLS) and may call only the methods its certificate's identity is
+// allowed, see Policy.
+package enginerpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// ServiceName is the fully qualified gRPC service name
+const ServiceName = "gp.engine.v1.Engine"
+
+// Method names, as used in a Policy
+const (
+	MethodCreateTable  = "CreateTable"
+	MethodSeatPlayer   = "SeatPlayer"
+	MethodSubmitAction = "SubmitAction"
+	MethodStreamState  = "StreamState"
+)
+
+// Methods lists every method of the service
+var Methods = []string{MethodCreateTable, MethodSeatPlayer, MethodSubmitAction, MethodStreamState}
+
+// Engine is what the service drives, the hub's tables in the game server
+type Engine interface {
+	CreateTable(ctx context.Context, name string) error
+	SeatPlayer(ctx context.Context, table string, userID uuid.UUID, seatID, buyIn uint) error
+	SubmitAction(ctx context.Context, table string, userID uuid.UUID, action string, amount uint, actionToken string) error
+	StreamState(ctx context.Context, table string, send func([]byte) error) error
+}
+
+type CreateTableRequest struct {
+	Table string `json:"table"`
+}
+
+type CreateTableResponse struct {
+	Table string `json:"table"`
+}
+
+// SeatPlayerRequest buys a user in from their wallet, as taking a seat at
+// the table would
+type SeatPlayerRequest struct {
+	Table  string    `json:"table"`
+	UserID uuid.UUID `json:"user_id"`
+	SeatID uint      `json:"seat_id"` // 0 lets the table's seating policy choose
+	BuyIn  uint      `json:"buy_in"`  // MNT
+}
+
+type SeatPlayerResponse struct{}
+
+// SubmitActionRequest plays call, check, fold or raise for a seated user
+type SubmitActionRequest struct {
+	Table       string    `json:"table"`
+	UserID      uuid.UUID `json:"user_id"`
+	Action      string    `json:"action"`
+	Amount      uint      `json:"amount,omitempty"`       // Raises only
+	ActionToken string    `json:"action_token,omitempty"` // Must match the turn when set
+}
+
+type SubmitActionResponse struct{}
+
+type StreamStateRequest struct {
+	Table string `json:"table"`
+}
+
+// StateUpdate is one message sent to the table, the same JSON a WebSocket
+// client at the table receives
+type StateUpdate struct {
+	Table   string          `json:"table"`
+	Message json.RawMessage `json:"message"`
+}
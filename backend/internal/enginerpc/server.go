@@ -0,0 +1,235 @@
+package enginerpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+
+	"github.com/anhbaysgalan1/gp/server"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Server serves an Engine over gRPC to callers the policy allows
+type Server struct {
+	engine Engine
+	policy Policy
+	grpc   *grpc.Server
+}
+
+// NewServer creates a server for the engine. tlsConfig must require and
+// verify client certificates, as ServerTLSConfig's does.
+func NewServer(engine Engine, policy Policy, tlsConfig *tls.Config) *Server {
+	s := &Server{
+		engine: engine,
+		policy: policy,
+	}
+	s.grpc = grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(s.unaryAuthorization),
+		grpc.StreamInterceptor(s.streamAuthorization),
+	)
+	s.grpc.RegisterService(&serviceDesc, s)
+	return s
+}
+
+// ListenAndServe serves on addr until Stop is called
+func (s *Server) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for engine API: %w", err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve serves on the listener until Stop is called
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Stop closes every connection, ending state streams in progress
+func (s *Server) Stop() {
+	s.grpc.Stop()
+}
+
+// ServerTLSConfig loads the server's certificate and the CA client
+// certificates must be signed by
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load engine API certificate: %w", err)
+	}
+	clientCAs, err := loadCertPool(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// engineServer is implemented by Server; grpc checks handlers against it
+type engineServer interface {
+	createTable(ctx context.Context, req *CreateTableRequest) (*CreateTableResponse, error)
+	seatPlayer(ctx context.Context, req *SeatPlayerRequest) (*SeatPlayerResponse, error)
+	submitAction(ctx context.Context, req *SubmitActionRequest) (*SubmitActionResponse, error)
+	streamState(req *StreamStateRequest, stream grpc.ServerStream) error
+}
+
+func (s *Server) createTable(ctx context.Context, req *CreateTableRequest) (*CreateTableResponse, error) {
+	if req.Table == "" {
+		return nil, status.Error(codes.InvalidArgument, "table is required")
+	}
+	if err := s.engine.CreateTable(ctx, req.Table); err != nil {
+		return nil, toStatus(err)
+	}
+	return &CreateTableResponse{Table: req.Table}, nil
+}
+
+func (s *Server) seatPlayer(ctx context.Context, req *SeatPlayerRequest) (*SeatPlayerResponse, error) {
+	if req.Table == "" || req.UserID == uuid.Nil || req.BuyIn == 0 {
+		return nil, status.Error(codes.InvalidArgument, "table, user_id and buy_in are required")
+	}
+	if err := s.engine.SeatPlayer(ctx, req.Table, req.UserID, req.SeatID, req.BuyIn); err != nil {
+		return nil, toStatus(err)
+	}
+	return &SeatPlayerResponse{}, nil
+}
+
+func (s *Server) submitAction(ctx context.Context, req *SubmitActionRequest) (*SubmitActionResponse, error) {
+	if req.Table == "" || req.UserID == uuid.Nil || req.Action == "" {
+		return nil, status.Error(codes.InvalidArgument, "table, user_id and action are required")
+	}
+	if err := s.engine.SubmitAction(ctx, req.Table, req.UserID, req.Action, req.Amount, req.ActionToken); err != nil {
+		return nil, toStatus(err)
+	}
+	return &SubmitActionResponse{}, nil
+}
+
+func (s *Server) streamState(req *StreamStateRequest, stream grpc.ServerStream) error {
+	if req.Table == "" {
+		return status.Error(codes.InvalidArgument, "table is required")
+	}
+	err := s.engine.StreamState(stream.Context(), req.Table, func(message []byte) error {
+		return stream.SendMsg(&StateUpdate{Table: req.Table, Message: message})
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// toStatus maps engine errors to gRPC status codes
+func toStatus(err error) error {
+	var refused *server.CommandError
+	switch {
+	case errors.Is(err, server.ErrTableNotFound), errors.Is(err, server.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, server.ErrTableExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, server.ErrUnknownAction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, server.ErrStreamBehind):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.As(err, &refused):
+		return status.Error(codes.FailedPrecondition, refused.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	slog.Error("Engine API call failed", "error", err)
+	return status.Error(codes.Internal, "engine error")
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*engineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: MethodCreateTable, Handler: createTableHandler},
+		{MethodName: MethodSeatPlayer, Handler: seatPlayerHandler},
+		{MethodName: MethodSubmitAction, Handler: submitActionHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: MethodStreamState, Handler: streamStateHandler, ServerStreams: true},
+	},
+}
+
+func fullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+func createTableHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(engineServer).createTable(ctx, req.(*CreateTableRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(MethodCreateTable)}, handler)
+}
+
+func seatPlayerHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeatPlayerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(engineServer).seatPlayer(ctx, req.(*SeatPlayerRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(MethodSeatPlayer)}, handler)
+}
+
+func submitActionHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(engineServer).submitAction(ctx, req.(*SubmitActionRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(MethodSubmitAction)}, handler)
+}
+
+func streamStateHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(StreamStateRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(engineServer).streamState(in, stream)
+}
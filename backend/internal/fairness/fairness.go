@@ -0,0 +1,270 @@
+// Package fairness tallies dealt cards and tests them against the
+// distributions a uniformly shuffled deck should produce
+package fairness
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// Expected rates for a fair 52-card deck
+const (
+	// Two hole cards of the same rank: 3/51
+	ExpectedPocketPairRate = 3.0 / 51.0
+	// A flop holding at least two cards of one rank: 1 - C(13,3)*4^3/C(52,3)
+	ExpectedPairedFlopRate = 1 - 286.0*64.0/22100.0
+	// A five-card board holding at least two cards of one rank: 1 - C(13,5)*4^5/C(52,5)
+	ExpectedPairedBoardRate = 1 - 1287.0*1024.0/2598960.0
+)
+
+// SignificanceLevel is the p-value below which a test is flagged. With many
+// tables tested every day a few flags are expected by chance alone; a table
+// that is flagged day after day is the signal worth investigating.
+const SignificanceLevel = 0.01
+
+// Tally counts the cards seen in a set of hands. Tallies are additive, so
+// per-table daily tallies can be merged into platform-wide figures.
+type Tally struct {
+	Hands        int64     `json:"hands"`
+	RankCounts   [13]int64 `json:"rank_counts"` // Deuce to ace
+	SuitCounts   [4]int64  `json:"suit_counts"` // Spades, hearts, diamonds, clubs
+	HoleHands    int64     `json:"hole_hands"`
+	PocketPairs  int64     `json:"pocket_pairs"`
+	Flops        int64     `json:"flops"`
+	PairedFlops  int64     `json:"paired_flops"`
+	Boards       int64     `json:"boards"`
+	PairedBoards int64     `json:"paired_boards"`
+}
+
+// AddHand counts one hand's hole cards and board. Undealt cards are zero and
+// are skipped, so a hand that ended before the river still contributes its
+// hole cards and flop.
+func (t *Tally) AddHand(holes [][2]eval.Card, board []eval.Card) {
+	t.Hands++
+
+	for _, hole := range holes {
+		if hole[0] == 0 || hole[1] == 0 {
+			continue
+		}
+		t.count(hole[0])
+		t.count(hole[1])
+		t.HoleHands++
+		if rank(hole[0]) == rank(hole[1]) {
+			t.PocketPairs++
+		}
+	}
+
+	var dealt []eval.Card
+	for _, card := range board {
+		if card != 0 {
+			dealt = append(dealt, card)
+			t.count(card)
+		}
+	}
+	if len(dealt) >= 3 {
+		t.Flops++
+		if hasPair(dealt[:3]) {
+			t.PairedFlops++
+		}
+	}
+	if len(dealt) == 5 {
+		t.Boards++
+		if hasPair(dealt) {
+			t.PairedBoards++
+		}
+	}
+}
+
+// Merge adds another tally into this one
+func (t *Tally) Merge(other Tally) {
+	t.Hands += other.Hands
+	for i := range t.RankCounts {
+		t.RankCounts[i] += other.RankCounts[i]
+	}
+	for i := range t.SuitCounts {
+		t.SuitCounts[i] += other.SuitCounts[i]
+	}
+	t.HoleHands += other.HoleHands
+	t.PocketPairs += other.PocketPairs
+	t.Flops += other.Flops
+	t.PairedFlops += other.PairedFlops
+	t.Boards += other.Boards
+	t.PairedBoards += other.PairedBoards
+}
+
+func (t *Tally) count(card eval.Card) {
+	t.RankCounts[rank(card)]++
+	t.SuitCounts[suit(card)]++
+}
+
+// rank is 0 for a deuce up to 12 for an ace
+func rank(card eval.Card) int {
+	return int(card>>8) & 0x0F
+}
+
+// suit is 0 for spades, 1 hearts, 2 diamonds and 3 clubs
+func suit(card eval.Card) int {
+	return bits.TrailingZeros32(uint32(card>>12) & 0x0F)
+}
+
+func hasPair(cards []eval.Card) bool {
+	seen := make(map[int]bool, len(cards))
+	for _, card := range cards {
+		if seen[rank(card)] {
+			return true
+		}
+		seen[rank(card)] = true
+	}
+	return false
+}
+
+// Test is the outcome of a chi-square goodness-of-fit test
+type Test struct {
+	ChiSquare float64 `json:"chi_square"`
+	DF        int     `json:"df"`
+	PValue    float64 `json:"p_value"`
+	Flagged   bool    `json:"flagged"`
+}
+
+// RateCheck compares an observed rate with the rate a fair deck gives
+type RateCheck struct {
+	Observed float64 `json:"observed"`
+	Expected float64 `json:"expected"`
+	Samples  int64   `json:"samples"`
+	Test
+}
+
+// Report is the statistical analysis of a tally
+type Report struct {
+	Tally
+	Ranks        Test      `json:"ranks"`
+	Suits        Test      `json:"suits"`
+	PocketPairs  RateCheck `json:"pocket_pairs_rate"`
+	PairedFlops  RateCheck `json:"paired_flops_rate"`
+	PairedBoards RateCheck `json:"paired_boards_rate"`
+	Flagged      bool      `json:"flagged"`
+}
+
+// Analyze runs every distribution test on a tally
+func Analyze(t Tally) Report {
+	report := Report{
+		Tally:        t,
+		Ranks:        uniformTest(t.RankCounts[:]),
+		Suits:        uniformTest(t.SuitCounts[:]),
+		PocketPairs:  rateCheck(t.PocketPairs, t.HoleHands, ExpectedPocketPairRate),
+		PairedFlops:  rateCheck(t.PairedFlops, t.Flops, ExpectedPairedFlopRate),
+		PairedBoards: rateCheck(t.PairedBoards, t.Boards, ExpectedPairedBoardRate),
+	}
+	report.Flagged = report.Ranks.Flagged || report.Suits.Flagged ||
+		report.PocketPairs.Flagged || report.PairedFlops.Flagged || report.PairedBoards.Flagged
+	return report
+}
+
+// uniformTest checks that every category is equally likely
+func uniformTest(counts []int64) Test {
+	var total int64
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 {
+		return Test{DF: len(counts) - 1, PValue: 1}
+	}
+
+	expected := float64(total) / float64(len(counts))
+	var chi float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi += d * d / expected
+	}
+	return newTest(chi, len(counts)-1)
+}
+
+// rateCheck is a two-category chi-square test of hits out of samples
+func rateCheck(hits, samples int64, expected float64) RateCheck {
+	check := RateCheck{Expected: expected, Samples: samples, Test: Test{DF: 1, PValue: 1}}
+	if samples == 0 {
+		return check
+	}
+
+	n := float64(samples)
+	observed := float64(hits)
+	check.Observed = observed / n
+
+	expHits := expected * n
+	expMisses := n - expHits
+	chi := (observed-expHits)*(observed-expHits)/expHits +
+		(n-observed-expMisses)*(n-observed-expMisses)/expMisses
+	check.Test = newTest(chi, 1)
+	return check
+}
+
+func newTest(chi float64, df int) Test {
+	p := ChiSquarePValue(chi, df)
+	return Test{
+		ChiSquare: chi,
+		DF:        df,
+		PValue:    p,
+		Flagged:   p < SignificanceLevel,
+	}
+}
+
+// ChiSquarePValue is the probability of a chi-square statistic at least this
+// large with df degrees of freedom if the null hypothesis holds
+func ChiSquarePValue(chi float64, df int) float64 {
+	if chi <= 0 || df <= 0 {
+		return 1
+	}
+	return upperGamma(float64(df)/2, chi/2)
+}
+
+// upperGamma is the regularized upper incomplete gamma function Q(a, x),
+// using the series expansion below a+1 and a continued fraction above
+func upperGamma(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-14
+		tiny    = 1e-300
+	)
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+
+	if x < a+1 {
+		sum := 1 / a
+		term := sum
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Lentz's method
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIter; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return prefix * h
+}
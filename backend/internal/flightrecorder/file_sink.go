@@ -0,0 +1,144 @@
+package flightrecorder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// fileSinkQueue is how many entries may wait to be written before new ones
+// are dropped
+const fileSinkQueue = 4096
+
+// sinkLine is an entry as a file sink writes it, one JSON object per line
+type sinkLine struct {
+	Table string `json:"table"`
+	Entry
+}
+
+type queuedEntry struct {
+	table string
+	entry Entry
+}
+
+// FileSink appends every entry to a file per table in a directory, written
+// from a goroutine of its own so that a slow disk never holds up a hand.
+// Nothing rotates or removes the files.
+type FileSink struct {
+	dir     string
+	queue   chan queuedEntry
+	dropped atomic.Uint64
+	stop    chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+}
+
+// NewFileSink creates dir if needed and starts writing entries to it
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create flight recorder directory: %w", err)
+	}
+	s := &FileSink{
+		dir:   dir,
+		queue: make(chan queuedEntry, fileSinkQueue),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write queues the entry, dropping it if the writer has fallen behind
+func (s *FileSink) Write(table string, entry Entry) {
+	select {
+	case s.queue <- queuedEntry{table: table, entry: entry}:
+	default:
+		if s.dropped.Add(1)%1000 == 1 {
+			slog.Warn("Flight recorder sink falling behind, dropping entries", "dropped", s.dropped.Load())
+		}
+	}
+}
+
+// Path is the file entries of the table are appended to. Table names are
+// chosen by players, so only their safe characters are kept, followed by a
+// hash telling apart names that differ in the rest.
+func (s *FileSink) Path(table string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, table)
+	if len(safe) > 64 {
+		safe = safe[:64]
+	}
+	sum := sha256.Sum256([]byte(table))
+	return filepath.Join(s.dir, safe+"-"+hex.EncodeToString(sum[:4])+".jsonl")
+}
+
+// Close writes the entries already queued and stops. Entries written after
+// it are dropped.
+func (s *FileSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+	return nil
+}
+
+func (s *FileSink) run() {
+	defer close(s.done)
+
+	files := make(map[string]*os.File)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	for {
+		select {
+		case queued := <-s.queue:
+			s.write(files, queued)
+		case <-s.stop:
+			for {
+				select {
+				case queued := <-s.queue:
+					s.write(files, queued)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *FileSink) write(files map[string]*os.File, queued queuedEntry) {
+	f, ok := files[queued.table]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(s.Path(queued.table), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+		if err != nil {
+			slog.Warn("Failed to open flight recorder file", "table", queued.table, "error", err)
+			return
+		}
+		files[queued.table] = f
+	}
+
+	line, err := json.Marshal(sinkLine{Table: queued.table, Entry: queued.entry})
+	if err != nil {
+		slog.Warn("Failed to marshal flight recorder entry", "table", queued.table, "error", err)
+		return
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		slog.Warn("Failed to write flight recorder entry", "table", queued.table, "error", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package flightrecorder keeps a rolling record of what happens at a table:
+// every command players send, every message broadcast back, the state of the
+// game as each hand is dealt and each action applied to it. A recording can
+// be dumped while the table runs and replayed against a fresh game to
+// reproduce a bug exactly.
+package flightrecorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// Entry kinds
+const (
+	KindInbound  = "in"       // A command from a player
+	KindOutbound = "out"      // A message broadcast to the table
+	KindSnapshot = "snapshot" // The game as a hand is dealt
+	KindAction   = "action"   // A betting action applied to the game
+)
+
+// Entry is one thing that happened at a table. Which fields are set depends
+// on the kind.
+type Entry struct {
+	Seq    uint64    `json:"seq"`
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"`
+	HandID string    `json:"hand_id,omitempty"`
+	UserID string    `json:"user_id,omitempty"`
+	// Inbound commands and outbound messages. Chat is kept without its text.
+	Action  string          `json:"action,omitempty"` // The command, or the betting action applied
+	Message json.RawMessage `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	// Snapshots: the omniscient game view and the cards left in the deck
+	State json.RawMessage `json:"state,omitempty"`
+	Deck  eval.Deck       `json:"deck,omitempty"`
+	// Actions: who acted for how much, and the game's digest either side
+	Seat   uint   `json:"seat,omitempty"`
+	Amount uint   `json:"amount,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Recording is a table's entries, oldest first
+type Recording struct {
+	Table       string  `json:"table"`
+	Capacity    int     `json:"capacity"`
+	Overwritten uint64  `json:"overwritten"` // Older entries no longer held
+	Entries     []Entry `json:"entries"`
+}
+
+// Sink keeps entries beyond what the ring buffer holds. Write must not block:
+// it is called from the game loop.
+type Sink interface {
+	Write(table string, entry Entry)
+}
+
+// Recorder holds a table's most recent entries in a ring buffer and passes
+// each on to the sink, if there is one. A nil Recorder records nothing.
+type Recorder struct {
+	table string
+	sink  Sink
+
+	mu      sync.Mutex
+	entries []Entry
+	next    int // Where the next entry goes once the buffer is full
+	seq     uint64
+}
+
+// New returns a recorder for the table keeping its last capacity entries, or
+// nil when there is nowhere to keep anything
+func New(table string, capacity int, sink Sink) *Recorder {
+	if capacity <= 0 && sink == nil {
+		return nil
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &Recorder{
+		table:   table,
+		sink:    sink,
+		entries: make([]Entry, 0, capacity),
+	}
+}
+
+// Record numbers and timestamps the entry and keeps it
+func (r *Recorder) Record(entry Entry) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.seq++
+	entry.Seq = r.seq
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	switch {
+	case cap(r.entries) == 0:
+	case len(r.entries) < cap(r.entries):
+		r.entries = append(r.entries, entry)
+	default:
+		r.entries[r.next] = entry
+		r.next = (r.next + 1) % len(r.entries)
+	}
+	r.mu.Unlock()
+
+	if r.sink != nil {
+		r.sink.Write(r.table, entry)
+	}
+}
+
+// Inbound records a command from a player. Messages that are not JSON are
+// kept as the error they caused.
+func (r *Recorder) Inbound(userID, action string, message []byte, err error) {
+	if r == nil {
+		return
+	}
+	entry := Entry{Kind: KindInbound, UserID: userID, Action: action}
+	if json.Valid(message) {
+		entry.Message = append(json.RawMessage(nil), message...)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.Record(entry)
+}
+
+// Outbound records a message broadcast to the table
+func (r *Recorder) Outbound(message []byte) {
+	if r == nil || !json.Valid(message) {
+		return
+	}
+	var base struct {
+		Action string `json:"action"`
+	}
+	_ = json.Unmarshal(message, &base)
+	r.Record(Entry{Kind: KindOutbound, Action: base.Action, Message: append(json.RawMessage(nil), message...)})
+}
+
+// Recording returns a copy of the entries held, oldest first
+func (r *Recorder) Recording() *Recording {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]Entry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	entries = append(entries, r.entries[:r.next]...)
+	return &Recording{
+		Table:       r.table,
+		Capacity:    cap(r.entries),
+		Overwritten: r.seq - uint64(len(entries)),
+		Entries:     entries,
+	}
+}
+
+// ReadRecording reads a recording dumped by the admin API, or the entries
+// a file sink appended one per line
+func ReadRecording(in io.Reader) (*Recording, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err == nil && recording.Entries != nil {
+		return &recording, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var line sinkLine
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("failed to decode entry %d: %w", len(recording.Entries)+1, err)
+		}
+		if recording.Table == "" {
+			recording.Table = line.Table
+		}
+		recording.Entries = append(recording.Entries, line.Entry)
+	}
+	if len(recording.Entries) == 0 {
+		return nil, fmt.Errorf("no entries in recording")
+	}
+	return &recording, nil
+}
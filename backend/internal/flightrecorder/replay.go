@@ -0,0 +1,177 @@
+package flightrecorder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+// ErrNoSnapshot is returned when a recording holds no hand to replay from
+var ErrNoSnapshot = errors.New("no hand was dealt within the recording")
+
+// Digest identifies a game state, deck included, for comparing a replay
+// with what the table recorded
+func Digest(view *poker.GameView) string {
+	state, _ := json.Marshal(view)
+	deck, _ := json.Marshal(view.Deck)
+	sum := sha256.New()
+	sum.Write(state)
+	sum.Write(deck)
+	return hex.EncodeToString(sum.Sum(nil)[:8])
+}
+
+// Snapshot records the game as a hand is dealt, everything needed to replay
+// the hand from there
+func (r *Recorder) Snapshot(handID string, view *poker.GameView) {
+	if r == nil {
+		return
+	}
+	state, err := json.Marshal(view)
+	if err != nil {
+		return
+	}
+	r.Record(Entry{
+		Kind:   KindSnapshot,
+		HandID: handID,
+		State:  state,
+		Deck:   append(view.Deck[:0:0], view.Deck...),
+		After:  Digest(view),
+	})
+}
+
+// Action records a betting action applied to the game, with the game either
+// side of it
+func (r *Recorder) Action(handID, userID, action string, seat, amount uint, before, after *poker.GameView, err error) {
+	if r == nil {
+		return
+	}
+	entry := Entry{
+		Kind:   KindAction,
+		HandID: handID,
+		UserID: userID,
+		Action: action,
+		Seat:   seat,
+		Amount: amount,
+		Before: Digest(before),
+		After:  Digest(after),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.Record(entry)
+}
+
+// Divergence is the first point at which a replay stopped matching the
+// recording
+type Divergence struct {
+	Seq     uint64 `json:"seq"`
+	HandID  string `json:"hand_id,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Seat    uint   `json:"seat"`
+	Amount  uint   `json:"amount,omitempty"`
+	Reason  string `json:"reason"`
+	Want    string `json:"want,omitempty"`
+	Got     string `json:"got,omitempty"`
+	WantErr string `json:"want_error,omitempty"`
+	GotErr  string `json:"got_error,omitempty"`
+	// The replayed game where it diverged
+	State *poker.GameView `json:"state,omitempty"`
+}
+
+// ReplayResult reports how a replay went
+type ReplayResult struct {
+	Table      string      `json:"table"`
+	Hands      int         `json:"hands"`   // Hands replayed from their snapshot
+	Actions    int         `json:"actions"` // Actions applied again
+	Skipped    int         `json:"skipped"` // Actions before the first snapshot held
+	Divergence *Divergence `json:"divergence,omitempty"`
+}
+
+// Replay deals each recorded hand again in a fresh game from its snapshot
+// and applies the recorded actions to it, checking the game before and
+// after each one and that each succeeds or fails as it did at the table. It
+// stops at the first divergence.
+func Replay(recording *Recording) (*ReplayResult, error) {
+	result := &ReplayResult{Table: recording.Table}
+	var game *poker.Game
+
+	for _, entry := range recording.Entries {
+		switch entry.Kind {
+		case KindSnapshot:
+			var view poker.GameView
+			if err := json.Unmarshal(entry.State, &view); err != nil {
+				return nil, fmt.Errorf("failed to decode snapshot %d: %w", entry.Seq, err)
+			}
+			view.Deck = append(view.Deck[:0:0], entry.Deck...)
+			game = poker.NewGame()
+			game.FillFromView(&view)
+			result.Hands++
+
+			if got := Digest(game.GenerateOmniView()); entry.After != "" && got != entry.After {
+				result.Divergence = &Divergence{Seq: entry.Seq, HandID: entry.HandID, Reason: "snapshot does not load as recorded", Want: entry.After, Got: got}
+				return result, nil
+			}
+
+		case KindAction:
+			if game == nil {
+				result.Skipped++
+				continue
+			}
+			if d := replayAction(game, entry); d != nil {
+				result.Divergence = d
+				return result, nil
+			}
+			result.Actions++
+		}
+	}
+
+	if result.Hands == 0 {
+		return result, ErrNoSnapshot
+	}
+	return result, nil
+}
+
+func replayAction(game *poker.Game, entry Entry) *Divergence {
+	diverged := func(reason string) *Divergence {
+		return &Divergence{
+			Seq:    entry.Seq,
+			HandID: entry.HandID,
+			Action: entry.Action,
+			Seat:   entry.Seat,
+			Amount: entry.Amount,
+			Reason: reason,
+			State:  game.GenerateOmniView(),
+		}
+	}
+
+	if got := Digest(game.GenerateOmniView()); got != entry.Before {
+		d := diverged("game changed outside the recorded actions")
+		d.Want, d.Got = entry.Before, got
+		return d
+	}
+
+	action := poker.Bet
+	if entry.Action == "fold" {
+		action = poker.Fold
+	}
+	var gotErr string
+	if err := action(game, entry.Seat, entry.Amount); err != nil {
+		gotErr = err.Error()
+	}
+	if gotErr != entry.Error {
+		d := diverged("action succeeded or failed differently")
+		d.WantErr, d.GotErr = entry.Error, gotErr
+		return d
+	}
+
+	if got := Digest(game.GenerateOmniView()); got != entry.After {
+		d := diverged("action left the game in a different state")
+		d.Want, d.Got = entry.After, got
+		return d
+	}
+	return nil
+}
@@ -17,6 +17,11 @@ const (
 
 	// System account types
 	SystemHouseAccount = "system:house"
+	SpinJackpotAccount = "system:spin_jackpot" // Keeps the house edge of spins and funds the big prize pools
+
+	// Revenue accounts
+	RevenueRakeAccount           = "revenue:rake"
+	RevenueWithdrawalFeesAccount = "revenue:withdrawal_fees"
 
 	// Account suffixes
 	WalletSuffix = "wallet"
@@ -37,6 +42,12 @@ func TournamentPoolAccount(tournamentID uuid.UUID) string {
 	return fmt.Sprintf("%s:tournament_pool:%s", SystemAccountPrefix, tournamentID.String())
 }
 
+// LastLongerEscrowAccount returns the account holding a last-longer pool's
+// stakes until it is settled
+func LastLongerEscrowAccount(poolID uuid.UUID) string {
+	return fmt.Sprintf("%s:last_longer:%s", SystemAccountPrefix, poolID.String())
+}
+
 // SessionPrefix returns the prefix for filtering user session accounts
 func SessionPrefix(userID uuid.UUID) string {
 	return fmt.Sprintf("%s:%s:", SessionAccountPrefix, userID.String())
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/config"
@@ -21,6 +24,9 @@ type Client struct {
 	apiKey     string
 	ledgerName string
 	currency   string
+	// Held shared by every ledger write, and exclusively while Quiesce has
+	// writes paused
+	writes sync.RWMutex
 }
 
 func NewClient(cfg *config.Config) *Client {
@@ -45,6 +51,19 @@ func (e FormanceError) Error() string {
 	return fmt.Sprintf("formance error %s: %s", e.Code, e.Message)
 }
 
+// IsNotFound reports whether err is Formance saying a resource doesn't exist
+func IsNotFound(err error) bool {
+	var formanceErr FormanceError
+	return errors.As(err, &formanceErr) && formanceErr.Code == "NOT_FOUND"
+}
+
+// IsConflict reports whether err is Formance rejecting a transaction whose
+// reference was already used
+func IsConflict(err error) bool {
+	var formanceErr FormanceError
+	return errors.As(err, &formanceErr) && formanceErr.Code == "CONFLICT"
+}
+
 // CreateLedgerRequest represents the request to create a ledger
 type CreateLedgerRequest struct {
 	Name     string                 `json:"name"`
@@ -114,9 +133,19 @@ func (c *Client) GetBalance(ctx context.Context, account string) (int64, error)
 
 // TransactionRequest represents a transaction request to Formance
 type TransactionRequest struct {
-	Postings []PostingSimple        `json:"postings"`
-	Script   *string                `json:"script,omitempty"`
-	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Postings  []PostingSimple        `json:"postings"`
+	Script    *string                `json:"script,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Reference string                 `json:"reference,omitempty"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+}
+
+// TransactionOptions carries optional transaction fields. A reference makes
+// the transaction idempotent: Formance rejects a second one with the same reference.
+// A timestamp backdates the transaction, which is used when backfilling history.
+type TransactionOptions struct {
+	Reference string
+	Timestamp *time.Time
 }
 
 // TransactionResponse represents a transaction response from Formance v2 API
@@ -130,6 +159,14 @@ type TransactionResponse struct {
 }
 
 func (c *Client) CreateTransaction(ctx context.Context, postings []PostingSimple, metadata map[string]string) (string, error) {
+	return c.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{})
+}
+
+// CreateTransactionWithOptions creates a transaction with an optional reference and timestamp
+func (c *Client) CreateTransactionWithOptions(ctx context.Context, postings []PostingSimple, metadata map[string]string, opts TransactionOptions) (string, error) {
+	c.writes.RLock()
+	defer c.writes.RUnlock()
+
 	// Use v2 API endpoint for transactions
 	url := fmt.Sprintf("%s/v2/%s/transactions", c.baseURL, c.ledgerName)
 
@@ -140,8 +177,10 @@ func (c *Client) CreateTransaction(ctx context.Context, postings []PostingSimple
 	}
 
 	reqBody := TransactionRequest{
-		Postings: postings,
-		Metadata: metadataInterface,
+		Postings:  postings,
+		Metadata:  metadataInterface,
+		Reference: opts.Reference,
+		Timestamp: opts.Timestamp,
 	}
 
 	var response TransactionResponse
@@ -261,7 +300,7 @@ func (c *Client) GetTransactionHistory(ctx context.Context, userID string, limit
 	}
 
 	// Filter transactions that involve the user's accounts
-	var userTransactions []TransactionData
+	userTransactions := []TransactionData{}
 	userWalletAccount := PlayerWalletAccount(uuid.MustParse(userID))
 	userSessionPrefix := SessionPrefix(uuid.MustParse(userID))
 
@@ -298,3 +337,232 @@ func (c *Client) GetTransactionHistory(ctx context.Context, userID string, limit
 
 	return userTransactions[start:end], nil
 }
+
+// Currency returns the asset used for all postings
+func (c *Client) Currency() string {
+	return c.currency
+}
+
+// TransactionPage is a single page of ledger transactions
+type TransactionPage struct {
+	Transactions []TransactionData
+	Next         string
+	HasMore      bool
+}
+
+// ListTransactions returns one page of ledger transactions, newest first.
+// Pass the previous page's Next cursor to continue.
+func (c *Client) ListTransactions(ctx context.Context, pageSize int, cursor string) (*TransactionPage, error) {
+	url := fmt.Sprintf("%s/v2/%s/transactions?pageSize=%d", c.baseURL, c.ledgerName, pageSize)
+	if cursor != "" {
+		url = fmt.Sprintf("%s/v2/%s/transactions?cursor=%s", c.baseURL, c.ledgerName, cursor)
+	}
+
+	var response struct {
+		Cursor struct {
+			HasMore bool              `json:"hasMore"`
+			Next    string            `json:"next,omitempty"`
+			Data    []TransactionData `json:"data"`
+		} `json:"cursor"`
+	}
+
+	if err := c.makeRequest(ctx, "GET", url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list transactions from Formance: %w", err)
+	}
+
+	return &TransactionPage{
+		Transactions: response.Cursor.Data,
+		Next:         response.Cursor.Next,
+		HasMore:      response.Cursor.HasMore,
+	}, nil
+}
+
+// GetTransaction fetches a single transaction by ID
+func (c *Client) GetTransaction(ctx context.Context, txID int64) (*TransactionData, error) {
+	url := fmt.Sprintf("%s/v2/%s/transactions/%d", c.baseURL, c.ledgerName, txID)
+
+	var response struct {
+		Data TransactionData `json:"data"`
+	}
+	if err := c.makeRequest(ctx, "GET", url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get transaction %d from Formance: %w", txID, err)
+	}
+	return &response.Data, nil
+}
+
+// AddTransactionMetadata sets metadata keys on an existing transaction.
+// Existing keys not present in metadata are left untouched.
+func (c *Client) AddTransactionMetadata(ctx context.Context, txID int64, metadata map[string]string) error {
+	c.writes.RLock()
+	defer c.writes.RUnlock()
+
+	url := fmt.Sprintf("%s/v2/%s/transactions/%d/metadata", c.baseURL, c.ledgerName, txID)
+
+	if err := c.makeRequest(ctx, "PUT", url, metadata, nil); err != nil {
+		return fmt.Errorf("failed to add metadata to transaction %d: %w", txID, err)
+	}
+	return nil
+}
+
+// AccountData is a ledger account with its balance in every asset
+type AccountData struct {
+	Address  string            `json:"address"`
+	Metadata map[string]string `json:"metadata"`
+	Volumes  map[string]struct {
+		Input   int64 `json:"input"`
+		Output  int64 `json:"output"`
+		Balance int64 `json:"balance"`
+	} `json:"volumes"`
+}
+
+// Balance returns the account balance in the given asset
+func (a AccountData) Balance(asset string) int64 {
+	return a.Volumes[asset].Balance
+}
+
+// AccountPage is a single page of ledger accounts
+type AccountPage struct {
+	Accounts []AccountData
+	Next     string
+	HasMore  bool
+}
+
+// ListAccounts returns one page of ledger accounts with volumes expanded
+func (c *Client) ListAccounts(ctx context.Context, pageSize int, cursor string) (*AccountPage, error) {
+	url := fmt.Sprintf("%s/v2/%s/accounts?pageSize=%d&expand=volumes", c.baseURL, c.ledgerName, pageSize)
+	if cursor != "" {
+		url = fmt.Sprintf("%s/v2/%s/accounts?cursor=%s&expand=volumes", c.baseURL, c.ledgerName, cursor)
+	}
+
+	var response struct {
+		Cursor struct {
+			HasMore bool          `json:"hasMore"`
+			Next    string        `json:"next,omitempty"`
+			Data    []AccountData `json:"data"`
+		} `json:"cursor"`
+	}
+
+	if err := c.makeRequest(ctx, "GET", url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list accounts from Formance: %w", err)
+	}
+
+	return &AccountPage{
+		Accounts: response.Cursor.Data,
+		Next:     response.Cursor.Next,
+		HasMore:  response.Cursor.HasMore,
+	}, nil
+}
+
+// TransactionFilter narrows a ledger transaction query. Zero fields match
+// everything. Account uses Formance address matching, where an empty segment
+// matches any value, so "player::wallet" covers every player wallet.
+type TransactionFilter struct {
+	Account      string    // Source or destination address
+	MetadataType string    // Value of the "type" metadata key
+	Reference    string    // Exact transaction reference
+	StartTime    time.Time // Inclusive
+	EndTime      time.Time // Exclusive
+}
+
+// query builds the Formance v2 filter expression, or nil when unfiltered
+func (f TransactionFilter) query() map[string]interface{} {
+	var clauses []map[string]interface{}
+	if f.Account != "" {
+		clauses = append(clauses, map[string]interface{}{"$match": map[string]string{"account": f.Account}})
+	}
+	if f.MetadataType != "" {
+		clauses = append(clauses, map[string]interface{}{"$match": map[string]string{"metadata[type]": f.MetadataType}})
+	}
+	if f.Reference != "" {
+		clauses = append(clauses, map[string]interface{}{"$match": map[string]string{"reference": f.Reference}})
+	}
+	if !f.StartTime.IsZero() {
+		clauses = append(clauses, map[string]interface{}{"$gte": map[string]string{"timestamp": f.StartTime.UTC().Format(time.RFC3339)}})
+	}
+	if !f.EndTime.IsZero() {
+		clauses = append(clauses, map[string]interface{}{"$lt": map[string]string{"timestamp": f.EndTime.UTC().Format(time.RFC3339)}})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil
+	case 1:
+		return clauses[0]
+	default:
+		return map[string]interface{}{"$and": clauses}
+	}
+}
+
+// QueryTransactions returns one page of ledger transactions matching filter,
+// newest first. The filter is encoded in the cursor, so it is only applied
+// to the first page.
+func (c *Client) QueryTransactions(ctx context.Context, filter TransactionFilter, pageSize int, cursor string) (*TransactionPage, error) {
+	endpoint := fmt.Sprintf("%s/v2/%s/transactions?pageSize=%d", c.baseURL, c.ledgerName, pageSize)
+	var body interface{}
+	if cursor != "" {
+		endpoint = fmt.Sprintf("%s/v2/%s/transactions?cursor=%s", c.baseURL, c.ledgerName, url.QueryEscape(cursor))
+	} else if q := filter.query(); q != nil {
+		body = q
+	}
+
+	var response struct {
+		Cursor struct {
+			HasMore bool              `json:"hasMore"`
+			Next    string            `json:"next,omitempty"`
+			Data    []TransactionData `json:"data"`
+		} `json:"cursor"`
+	}
+
+	if err := c.makeRequest(ctx, "GET", endpoint, body, &response); err != nil {
+		return nil, fmt.Errorf("failed to query transactions from Formance: %w", err)
+	}
+
+	return &TransactionPage{
+		Transactions: response.Cursor.Data,
+		Next:         response.Cursor.Next,
+		HasMore:      response.Cursor.HasMore,
+	}, nil
+}
+
+// QueryAccounts returns one page of ledger accounts whose address matches
+// pattern, with volumes expanded. An empty pattern lists every account.
+func (c *Client) QueryAccounts(ctx context.Context, pattern string, pageSize int, cursor string) (*AccountPage, error) {
+	endpoint := fmt.Sprintf("%s/v2/%s/accounts?pageSize=%d&expand=volumes", c.baseURL, c.ledgerName, pageSize)
+	var body interface{}
+	if cursor != "" {
+		endpoint = fmt.Sprintf("%s/v2/%s/accounts?cursor=%s&expand=volumes", c.baseURL, c.ledgerName, url.QueryEscape(cursor))
+	} else if pattern != "" {
+		body = map[string]interface{}{"$match": map[string]string{"address": pattern}}
+	}
+
+	var response struct {
+		Cursor struct {
+			HasMore bool          `json:"hasMore"`
+			Next    string        `json:"next,omitempty"`
+			Data    []AccountData `json:"data"`
+		} `json:"cursor"`
+	}
+
+	if err := c.makeRequest(ctx, "GET", endpoint, body, &response); err != nil {
+		return nil, fmt.Errorf("failed to query accounts from Formance: %w", err)
+	}
+
+	return &AccountPage{
+		Accounts: response.Cursor.Data,
+		Next:     response.Cursor.Next,
+		HasMore:  response.Cursor.HasMore,
+	}, nil
+}
+
+// GetAccount fetches a single account with volumes expanded
+func (c *Client) GetAccount(ctx context.Context, address string) (*AccountData, error) {
+	endpoint := fmt.Sprintf("%s/v2/%s/accounts/%s?expand=volumes", c.baseURL, c.ledgerName, url.PathEscape(address))
+
+	var response struct {
+		Data AccountData `json:"data"`
+	}
+	if err := c.makeRequest(ctx, "GET", endpoint, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get account %s from Formance: %w", address, err)
+	}
+	return &response.Data, nil
+}
@@ -0,0 +1,115 @@
+package formance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// StakeLastLonger moves a player's last-longer stake from their wallet into
+// the pool's escrow account
+func (s *Service) StakeLastLonger(ctx context.Context, userID, poolID, tournamentID uuid.UUID, stake int64) (string, error) {
+	if stake <= 0 {
+		return "", fmt.Errorf("stake must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      PlayerWalletAccount(userID),
+			Destination: LastLongerEscrowAccount(poolID),
+			Amount:      stake,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":          "last_longer_stake",
+		"user_id":       userID.String(),
+		"pool_id":       poolID.String(),
+		"tournament_id": tournamentID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to stake last-longer pool: %w", err)
+	}
+
+	slog.Info("Staked last-longer pool", "user_id", userID, "pool_id", poolID, "amount", stake, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// RefundLastLonger returns a stake from a pool's escrow account to the
+// player's wallet
+func (s *Service) RefundLastLonger(ctx context.Context, userID, poolID uuid.UUID, stake int64) (string, error) {
+	if stake <= 0 {
+		return "", fmt.Errorf("refund amount must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      LastLongerEscrowAccount(poolID),
+			Destination: PlayerWalletAccount(userID),
+			Amount:      stake,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":    "last_longer_refund",
+		"user_id": userID.String(),
+		"pool_id": poolID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to refund last-longer stake: %w", err)
+	}
+
+	slog.Info("Refunded last-longer stake", "user_id", userID, "pool_id", poolID, "amount", stake, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// PayLastLonger pays a pool's escrow to its winner. The pool is the
+// reference, so it is paid once; paying it again returns the transaction
+// that already did.
+func (s *Service) PayLastLonger(ctx context.Context, winnerID, poolID uuid.UUID, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("payout must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      LastLongerEscrowAccount(poolID),
+			Destination: PlayerWalletAccount(winnerID),
+			Amount:      amount,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":    "last_longer_payout",
+		"user_id": winnerID.String(),
+		"pool_id": poolID.String(),
+	}
+
+	reference := "last_longer:" + poolID.String()
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: reference})
+	if IsConflict(err) {
+		page, lookupErr := s.client.QueryTransactions(ctx, TransactionFilter{Reference: reference}, 1, "")
+		if lookupErr != nil {
+			return "", fmt.Errorf("failed to look up last-longer payout: %w", lookupErr)
+		}
+		if len(page.Transactions) == 0 {
+			return "", fmt.Errorf("last-longer pool %s was paid but its transaction was not found", poolID)
+		}
+		return fmt.Sprintf("%d", page.Transactions[0].ID), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to pay last-longer pool: %w", err)
+	}
+
+	slog.Info("Paid last-longer pool", "user_id", winnerID, "pool_id", poolID, "amount", amount, "transaction_id", transactionID)
+	return transactionID, nil
+}
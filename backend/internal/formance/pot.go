@@ -0,0 +1,174 @@
+package formance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// potDistributionAttempts is how many times a pot settlement is posted
+// before giving up
+const potDistributionAttempts = 3
+
+// potDistributionBackoff is the wait before the second attempt, doubling
+// after each further failure
+const potDistributionBackoff = 200 * time.Millisecond
+
+// PotID identifies a pot for settlement. It doubles as the idempotency key
+// of the pot's transaction, so the same pot can never be paid twice.
+func PotID(table, handID string, pot int) string {
+	return fmt.Sprintf("pot:%s:%s:%d", table, handID, pot)
+}
+
+// DistributePot pays a pot's winners from their sessions to their wallets as
+// one transaction, so either every winner is paid or none is. winners maps
+// each winner's user ID to their share and playerSessions to their session.
+// The transaction's reference is potID: posting it again, after a timeout
+// or a retry that already went through, returns the original transaction
+// instead of paying out twice.
+func (s *Service) DistributePot(ctx context.Context, potID string, winners map[uuid.UUID]int64, playerSessions map[uuid.UUID]uuid.UUID, extra map[string]string) (string, error) {
+	return s.DistributeRakedPot(ctx, potID, winners, nil, playerSessions, extra)
+}
+
+// DistributeRakedPot is DistributePot for a raked pot. rake maps winners to
+// the rake taken from their share, which goes to the house in the same
+// transaction; winners holds what they are paid after it.
+func (s *Service) DistributeRakedPot(ctx context.Context, potID string, winners, rake map[uuid.UUID]int64, playerSessions map[uuid.UUID]uuid.UUID, extra map[string]string) (string, error) {
+	if potID == "" {
+		return "", fmt.Errorf("pot ID is required")
+	}
+	if len(winners) == 0 {
+		return "", fmt.Errorf("pot has no winners")
+	}
+
+	postings, err := s.potPostings(winners, rake, playerSessions)
+	if err != nil {
+		return "", err
+	}
+
+	metadata := map[string]string{
+		"type":    "game_cashout",
+		"reason":  "pot_settlement",
+		"pot_id":  potID,
+		"winners": fmt.Sprintf("%d", len(winners)),
+	}
+	if total := sumAmounts(rake); total > 0 {
+		metadata["rake"] = fmt.Sprintf("%d", total)
+	}
+	for k, v := range extra {
+		if _, reserved := metadata[k]; !reserved {
+			metadata[k] = v
+		}
+	}
+
+	backoff := potDistributionBackoff
+	for attempt := 1; ; attempt++ {
+		transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: potID})
+		if err == nil {
+			slog.Info("Distributed pot", "pot_id", potID, "winners", len(winners), "transaction_id", transactionID)
+			return transactionID, nil
+		}
+		if IsConflict(err) {
+			// An earlier attempt went through; its response was lost
+			return s.potTransaction(ctx, potID)
+		}
+		if !retryablePotError(err) || attempt == potDistributionAttempts {
+			return "", fmt.Errorf("failed to distribute pot: %w", err)
+		}
+
+		slog.Warn("Retrying pot distribution", "pot_id", potID, "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed to distribute pot: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// potPostings moves each winner's share from their session to their wallet,
+// and any rake taken from it to the house, ordered by user so every attempt
+// posts the same transaction
+func (s *Service) potPostings(winners, rake map[uuid.UUID]int64, playerSessions map[uuid.UUID]uuid.UUID) ([]PostingSimple, error) {
+	userIDs := make([]uuid.UUID, 0, len(winners))
+	for userID, amount := range winners {
+		if amount < 0 || rake[userID] < 0 || amount+rake[userID] == 0 {
+			return nil, fmt.Errorf("winnings of user %s must be positive", userID)
+		}
+		if playerSessions[userID] == uuid.Nil {
+			return nil, fmt.Errorf("no session for winner %s", userID)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	for userID := range rake {
+		if _, ok := winners[userID]; !ok {
+			return nil, fmt.Errorf("rake taken from user %s who did not win the pot", userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return bytes.Compare(userIDs[i][:], userIDs[j][:]) < 0
+	})
+
+	postings := make([]PostingSimple, 0, len(userIDs))
+	for _, userID := range userIDs {
+		session := SessionAccount(userID, playerSessions[userID])
+		if amount := winners[userID]; amount > 0 {
+			postings = append(postings, PostingSimple{
+				Source:      session,
+				Destination: PlayerWalletAccount(userID),
+				Amount:      amount,
+				Asset:       s.currency,
+			})
+		}
+		if amount := rake[userID]; amount > 0 {
+			postings = append(postings, PostingSimple{
+				Source:      session,
+				Destination: RevenueRakeAccount,
+				Amount:      amount,
+				Asset:       s.currency,
+			})
+		}
+	}
+	return postings, nil
+}
+
+// sumAmounts totals per-user amounts
+func sumAmounts(amounts map[uuid.UUID]int64) int64 {
+	var total int64
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+// potTransaction returns the ID of the transaction that already settled the
+// pot
+func (s *Service) potTransaction(ctx context.Context, potID string) (string, error) {
+	page, err := s.client.QueryTransactions(ctx, TransactionFilter{Reference: potID}, 1, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up settled pot: %w", err)
+	}
+	if len(page.Transactions) == 0 {
+		return "", fmt.Errorf("pot %s was settled but its transaction was not found", potID)
+	}
+	transactionID := fmt.Sprintf("%d", page.Transactions[0].ID)
+	slog.Info("Pot already distributed", "pot_id", potID, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// retryablePotError reports whether a failed pot settlement may succeed if
+// posted again. Errors the ledger returns about the transaction itself, such
+// as insufficient funds, will not.
+func retryablePotError(err error) bool {
+	var formanceErr FormanceError
+	if errors.As(err, &formanceErr) {
+		return formanceErr.Code == "INTERNAL"
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
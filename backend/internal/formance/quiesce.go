@@ -0,0 +1,77 @@
+package formance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrWritesBusy is returned by Quiesce when ledger writes already in flight
+// do not finish in time
+var ErrWritesBusy = errors.New("ledger writes did not finish in time")
+
+// LogData is an entry of the ledger's append-only log. Every transaction and
+// metadata change adds one, so the latest log ID marks the ledger's state.
+type LogData struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+	Date string `json:"date"`
+}
+
+// Quiesce waits for the ledger writes in flight from this process to finish
+// and holds back new ones until release is called. It gives up with
+// ErrWritesBusy when ctx ends first. Writes wait rather than fail while
+// paused, so keep the pause short.
+func (c *Client) Quiesce(ctx context.Context) (release func(), err error) {
+	acquired := make(chan struct{})
+	go func() {
+		c.writes.Lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		return c.writes.Unlock, nil
+	case <-ctx.Done():
+		// Let writes through again as soon as the pending pause takes hold
+		go func() {
+			<-acquired
+			c.writes.Unlock()
+		}()
+		return nil, fmt.Errorf("%w: %w", ErrWritesBusy, ctx.Err())
+	}
+}
+
+// LatestLog returns the newest entry of the ledger's log, or nil if the
+// ledger has none yet
+func (c *Client) LatestLog(ctx context.Context) (*LogData, error) {
+	url := fmt.Sprintf("%s/v2/%s/logs?pageSize=1", c.baseURL, c.ledgerName)
+
+	var response struct {
+		Cursor struct {
+			Data []LogData `json:"data"`
+		} `json:"cursor"`
+	}
+	if err := c.makeRequest(ctx, "GET", url, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get latest ledger log from Formance: %w", err)
+	}
+	if len(response.Cursor.Data) == 0 {
+		return nil, nil
+	}
+	return &response.Cursor.Data[0], nil
+}
+
+// Quiesce pauses this process's ledger writes, see Client.Quiesce
+func (s *Service) Quiesce(ctx context.Context) (func(), error) {
+	return s.client.Quiesce(ctx)
+}
+
+// LatestLog returns the newest entry of the ledger's log, or nil if empty
+func (s *Service) LatestLog(ctx context.Context) (*LogData, error) {
+	return s.client.LatestLog(ctx)
+}
+
+// LedgerName is the Formance ledger the service writes to
+func (s *Service) LedgerName() string {
+	return s.client.ledgerName
+}
@@ -0,0 +1,64 @@
+package formance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/formancemock"
+)
+
+// sandboxBaseURL is never dialled: sandbox requests are served in-process
+const sandboxBaseURL = "http://sandbox.ledger.invalid"
+
+// NewSandboxService returns a Service backed by an in-memory ledger instead
+// of Formance. Every call goes through the same client code as in
+// production, but balances live only as long as the process and no real
+// money moves.
+func NewSandboxService(cfg *config.Config) *Service {
+	ledger := formancemock.NewServer(formancemock.Options{
+		Ledgers: []string{cfg.FormanceLedgerName},
+	})
+
+	return &Service{
+		client: &Client{
+			httpClient: &http.Client{
+				Transport: inProcessTransport{handler: ledger},
+				Timeout:   30 * time.Second,
+			},
+			baseURL:    sandboxBaseURL,
+			ledgerName: cfg.FormanceLedgerName,
+			currency:   cfg.FormanceCurrency,
+		},
+		currency: cfg.FormanceCurrency,
+		sandbox:  true,
+	}
+}
+
+// Sandbox reports whether the service moves play money in an in-memory
+// ledger rather than real money in Formance
+func (s *Service) Sandbox() bool {
+	return s != nil && s.sandbox
+}
+
+// inProcessTransport serves requests with a handler instead of the network
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (t inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Handlers expect a server request, whose body is never nil
+	served := req
+	if req.Body == nil {
+		served = req.Clone(req.Context())
+		served.Body = http.NoBody
+	}
+
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, served)
+
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
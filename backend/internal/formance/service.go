@@ -14,6 +14,7 @@ import (
 type Service struct {
 	client   *Client // Improved client with better filtering
 	currency string
+	sandbox  bool // Backed by an in-memory ledger, see NewSandboxService
 }
 
 func NewService(cfg *config.Config) *Service {
@@ -83,6 +84,7 @@ func (s *Service) GetUserBalance(ctx context.Context, userID uuid.UUID, db *gorm
 		MainBalance:  mainBalance,
 		GameBalance:  totalGameBalance,
 		TotalBalance: mainBalance + totalGameBalance,
+		Sandbox:      s.sandbox,
 	}, nil
 }
 
@@ -154,6 +156,12 @@ func (s *Service) TransferToGame(ctx context.Context, userID uuid.UUID, amount i
 
 // TransferFromGame transfers MNT from user session account back to main account
 func (s *Service) TransferFromGame(ctx context.Context, userID uuid.UUID, amount int64, sessionID uuid.UUID) (string, error) {
+	return s.TransferFromGameWithMetadata(ctx, userID, amount, sessionID, nil)
+}
+
+// TransferFromGameWithMetadata is TransferFromGame with extra ledger metadata
+// (e.g. the hand ID of a pot settlement) merged into the transaction
+func (s *Service) TransferFromGameWithMetadata(ctx context.Context, userID uuid.UUID, amount int64, sessionID uuid.UUID, extra map[string]string) (string, error) {
 	if amount <= 0 {
 		return "", fmt.Errorf("amount must be positive")
 	}
@@ -175,6 +183,11 @@ func (s *Service) TransferFromGame(ctx context.Context, userID uuid.UUID, amount
 		"user_id":    userID.String(),
 		"session_id": sessionID.String(),
 	}
+	for k, v := range extra {
+		if _, reserved := metadata[k]; !reserved {
+			metadata[k] = v
+		}
+	}
 
 	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
 	if err != nil {
@@ -251,6 +264,194 @@ func (s *Service) DistributeTournamentPrize(ctx context.Context, userID uuid.UUI
 	return transactionID, nil
 }
 
+// RefundTournamentBuyIn returns a buy-in from the tournament pool to the
+// player's wallet
+func (s *Service) RefundTournamentBuyIn(ctx context.Context, userID uuid.UUID, tournamentID uuid.UUID, buyIn int64) (string, error) {
+	if buyIn <= 0 {
+		return "", fmt.Errorf("refund amount must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      TournamentPoolAccount(tournamentID),
+			Destination: PlayerWalletAccount(userID),
+			Amount:      buyIn,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":          "tournament_refund",
+		"user_id":       userID.String(),
+		"tournament_id": tournamentID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to refund tournament buy-in: %w", err)
+	}
+
+	slog.Info("Refunded tournament buy-in", "user_id", userID, "tournament_id", tournamentID, "amount", buyIn, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// SpinSettlement is how a spin's entries were made up to its drawn prize
+// pool
+type SpinSettlement struct {
+	TransactionID   string
+	JackpotTransfer int64 // To the jackpot account, negative when it paid in
+	HouseTopUp      int64 // Added by the house when the jackpot fell short
+}
+
+// SettleSpinDraw brings a spin's tournament pool from the entries to the
+// drawn prize pool. Entries above the prize pool go to the spin jackpot
+// account, and prize pools above the entries are paid from it, with the
+// house covering whatever the jackpot can't. The tournament is the
+// reference, so a draw is settled once.
+func (s *Service) SettleSpinDraw(ctx context.Context, tournamentID uuid.UUID, entries, prizePool int64) (*SpinSettlement, error) {
+	settlement := &SpinSettlement{JackpotTransfer: entries - prizePool}
+	if settlement.JackpotTransfer == 0 {
+		return settlement, nil
+	}
+
+	pool := TournamentPoolAccount(tournamentID)
+	var postings []PostingSimple
+	if settlement.JackpotTransfer > 0 {
+		postings = append(postings, PostingSimple{
+			Source:      pool,
+			Destination: SpinJackpotAccount,
+			Amount:      settlement.JackpotTransfer,
+			Asset:       s.currency,
+		})
+	} else {
+		jackpot, err := s.client.GetBalance(ctx, SpinJackpotAccount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spin jackpot balance: %w", err)
+		}
+
+		owed := -settlement.JackpotTransfer
+		if jackpot < owed {
+			settlement.JackpotTransfer = -jackpot
+			settlement.HouseTopUp = owed - jackpot
+		}
+		if jackpot > 0 {
+			postings = append(postings, PostingSimple{
+				Source:      SpinJackpotAccount,
+				Destination: pool,
+				Amount:      -settlement.JackpotTransfer,
+				Asset:       s.currency,
+			})
+		}
+		if settlement.HouseTopUp > 0 {
+			postings = append(postings, PostingSimple{
+				Source:      WorldAccount,
+				Destination: pool,
+				Amount:      settlement.HouseTopUp,
+				Asset:       s.currency,
+			})
+		}
+	}
+
+	metadata := map[string]string{
+		"type":          "spin_draw",
+		"tournament_id": tournamentID.String(),
+		"entries":       fmt.Sprintf("%d", entries),
+		"prize_pool":    fmt.Sprintf("%d", prizePool),
+	}
+
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: "spin_draw:" + tournamentID.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle spin draw: %w", err)
+	}
+	settlement.TransactionID = transactionID
+
+	slog.Info("Settled spin draw", "tournament_id", tournamentID, "entries", entries, "prize_pool", prizePool, "jackpot_transfer", settlement.JackpotTransfer, "house_top_up", settlement.HouseTopUp, "transaction_id", transactionID)
+	return settlement, nil
+}
+
+// SpinJackpotBalance returns what the spin jackpot account holds
+func (s *Service) SpinJackpotBalance(ctx context.Context) (int64, error) {
+	return s.client.GetBalance(ctx, SpinJackpotAccount)
+}
+
+// MoveSessionToSession closes out a finishing session straight into a new
+// session at another table as one transaction: buyIn goes to the new session
+// and anything left over to the wallet. The finishing session is the
+// reference, so it can't be moved twice.
+func (s *Service) MoveSessionToSession(ctx context.Context, userID, fromSessionID, toSessionID uuid.UUID, buyIn, remainder int64) (string, error) {
+	if buyIn <= 0 {
+		return "", fmt.Errorf("buy-in amount must be positive")
+	}
+
+	postings := s.sessionMovePostings(userID, fromSessionID, SessionAccount(userID, toSessionID), buyIn, remainder)
+
+	metadata := map[string]string{
+		"type":            "session_transfer",
+		"user_id":         userID.String(),
+		"from_session_id": fromSessionID.String(),
+		"session_id":      toSessionID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: sessionMoveReference(fromSessionID)})
+	if err != nil {
+		return "", fmt.Errorf("failed to move session funds: %w", err)
+	}
+
+	slog.Info("Moved session to another table", "user_id", userID, "from_session_id", fromSessionID, "to_session_id", toSessionID, "buy_in", buyIn, "remainder", remainder, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// MoveSessionToTournament closes out a finishing session straight into a
+// tournament's buy-in as one transaction, returning anything left over to
+// the wallet
+func (s *Service) MoveSessionToTournament(ctx context.Context, userID, fromSessionID, tournamentID uuid.UUID, buyIn, remainder int64) (string, error) {
+	if buyIn <= 0 {
+		return "", fmt.Errorf("buy-in amount must be positive")
+	}
+
+	postings := s.sessionMovePostings(userID, fromSessionID, TournamentPoolAccount(tournamentID), buyIn, remainder)
+
+	metadata := map[string]string{
+		"type":            "tournament_buyin",
+		"user_id":         userID.String(),
+		"tournament_id":   tournamentID.String(),
+		"from_session_id": fromSessionID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: sessionMoveReference(fromSessionID)})
+	if err != nil {
+		return "", fmt.Errorf("failed to process tournament buy-in from session: %w", err)
+	}
+
+	slog.Info("Processed tournament buy-in from session", "user_id", userID, "from_session_id", fromSessionID, "tournament_id", tournamentID, "buy_in", buyIn, "remainder", remainder, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+func (s *Service) sessionMovePostings(userID, fromSessionID uuid.UUID, destination string, buyIn, remainder int64) []PostingSimple {
+	source := SessionAccount(userID, fromSessionID)
+	postings := []PostingSimple{
+		{
+			Source:      source,
+			Destination: destination,
+			Amount:      buyIn,
+			Asset:       s.currency,
+		},
+	}
+	if remainder > 0 {
+		postings = append(postings, PostingSimple{
+			Source:      source,
+			Destination: PlayerWalletAccount(userID),
+			Amount:      remainder,
+			Asset:       s.currency,
+		})
+	}
+	return postings
+}
+
+func sessionMoveReference(fromSessionID uuid.UUID) string {
+	return "session_move:" + fromSessionID.String()
+}
+
 // RakeStrategy defines different rake collection methods
 type RakeStrategy string
 
@@ -264,17 +465,46 @@ const (
 type RakeConfig struct {
 	Strategy   RakeStrategy
 	Percentage float64 // For per-hand rake (e.g., 0.05 for 5%)
-	MaxRake    int64   // Maximum rake per hand
+	MaxRake    int64   // Maximum rake per hand, 0 for no cap
 	MinPot     int64   // Minimum pot size to collect rake
 	TimeAmount int64   // Fixed amount for time-based rake
 	TableID    uuid.UUID
 	HandID     string
 }
 
+// HandRake returns the per-hand rake due on a pot: Percentage of it, up to
+// MaxRake, or nothing below MinPot
+func (c RakeConfig) HandRake(pot int64) int64 {
+	if pot <= 0 || pot < c.MinPot || c.Percentage <= 0 {
+		return 0
+	}
+	rake := int64(float64(pot) * c.Percentage)
+	if c.MaxRake > 0 && rake > c.MaxRake {
+		rake = c.MaxRake
+	}
+	return rake
+}
+
+// RakeCollection describes a completed rake collection and how much each player contributed
+type RakeCollection struct {
+	TransactionID string
+	Shares        map[uuid.UUID]int64 // player ID -> rake taken from that player's session
+}
+
 // CollectRake transfers rake to house account using specified strategy
 func (s *Service) CollectRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (string, error) {
+	collection, err := s.CollectRakeShares(ctx, config, playerSessions)
+	if err != nil || collection == nil {
+		return "", err
+	}
+	return collection.TransactionID, nil
+}
+
+// CollectRakeShares collects rake like CollectRake but also reports the per-player
+// contributions so callers can attribute rake (e.g. for loyalty tracking)
+func (s *Service) CollectRakeShares(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (*RakeCollection, error) {
 	if len(playerSessions) == 0 {
-		return "", nil // No players, no rake to collect
+		return nil, nil // No players, no rake to collect
 	}
 
 	switch config.Strategy {
@@ -285,12 +515,12 @@ func (s *Service) CollectRake(ctx context.Context, config RakeConfig, playerSess
 	case RakeStrategyTournament:
 		return s.collectTournamentRake(ctx, config, playerSessions)
 	default:
-		return "", fmt.Errorf("unsupported rake strategy: %s", config.Strategy)
+		return nil, fmt.Errorf("unsupported rake strategy: %s", config.Strategy)
 	}
 }
 
 // collectPerHandRake collects percentage-based rake from pot
-func (s *Service) collectPerHandRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (string, error) {
+func (s *Service) collectPerHandRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (*RakeCollection, error) {
 	potAmount := int64(0)
 
 	// Calculate total pot from all player sessions
@@ -303,31 +533,25 @@ func (s *Service) collectPerHandRake(ctx context.Context, config RakeConfig, pla
 		potAmount += balance
 	}
 
-	if potAmount < config.MinPot {
-		return "", nil // Pot too small for rake
-	}
-
-	rakeAmount := int64(float64(potAmount) * config.Percentage)
-	if rakeAmount > config.MaxRake {
-		rakeAmount = config.MaxRake
-	}
-
+	rakeAmount := config.HandRake(potAmount)
 	if rakeAmount <= 0 {
-		return "", nil
+		return nil, nil
 	}
 
 	// Distribute rake collection among players proportionally
 	rakePerPlayer := rakeAmount / int64(len(playerSessions))
 	if rakePerPlayer <= 0 {
-		return "", nil
+		return nil, nil
 	}
 
 	var postings []PostingSimple
+	shares := make(map[uuid.UUID]int64, len(playerSessions))
 	for playerID, sessionID := range playerSessions {
 		sessionAccount := SessionAccount(playerID, sessionID)
+		shares[playerID] = rakePerPlayer
 		postings = append(postings, PostingSimple{
 			Source:      sessionAccount,
-			Destination: "revenue:rake",
+			Destination: RevenueRakeAccount,
 			Amount:      rakePerPlayer,
 			Asset:       s.currency,
 		})
@@ -345,7 +569,7 @@ func (s *Service) collectPerHandRake(ctx context.Context, config RakeConfig, pla
 
 	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
 	if err != nil {
-		return "", fmt.Errorf("failed to collect per-hand rake: %w", err)
+		return nil, fmt.Errorf("failed to collect per-hand rake: %w", err)
 	}
 
 	slog.Info("Collected per-hand rake",
@@ -356,26 +580,28 @@ func (s *Service) collectPerHandRake(ctx context.Context, config RakeConfig, pla
 		"players", len(playerSessions),
 		"transaction_id", transactionID)
 
-	return transactionID, nil
+	return &RakeCollection{TransactionID: transactionID, Shares: shares}, nil
 }
 
 // collectTimeBasedRake collects fixed rake amount per time period
-func (s *Service) collectTimeBasedRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (string, error) {
+func (s *Service) collectTimeBasedRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (*RakeCollection, error) {
 	if config.TimeAmount <= 0 {
-		return "", fmt.Errorf("time-based rake amount must be positive")
+		return nil, fmt.Errorf("time-based rake amount must be positive")
 	}
 
 	rakePerPlayer := config.TimeAmount / int64(len(playerSessions))
 	if rakePerPlayer <= 0 {
-		return "", nil
+		return nil, nil
 	}
 
 	var postings []PostingSimple
+	shares := make(map[uuid.UUID]int64, len(playerSessions))
 	for playerID, sessionID := range playerSessions {
 		sessionAccount := SessionAccount(playerID, sessionID)
+		shares[playerID] = rakePerPlayer
 		postings = append(postings, PostingSimple{
 			Source:      sessionAccount,
-			Destination: "revenue:rake",
+			Destination: RevenueRakeAccount,
 			Amount:      rakePerPlayer,
 			Asset:       s.currency,
 		})
@@ -391,7 +617,7 @@ func (s *Service) collectTimeBasedRake(ctx context.Context, config RakeConfig, p
 
 	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
 	if err != nil {
-		return "", fmt.Errorf("failed to collect time-based rake: %w", err)
+		return nil, fmt.Errorf("failed to collect time-based rake: %w", err)
 	}
 
 	slog.Info("Collected time-based rake",
@@ -400,18 +626,178 @@ func (s *Service) collectTimeBasedRake(ctx context.Context, config RakeConfig, p
 		"players", len(playerSessions),
 		"transaction_id", transactionID)
 
-	return transactionID, nil
+	return &RakeCollection{TransactionID: transactionID, Shares: shares}, nil
 }
 
 // collectTournamentRake collects rake as part of tournament buy-in (no actual collection needed)
-func (s *Service) collectTournamentRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (string, error) {
+func (s *Service) collectTournamentRake(ctx context.Context, config RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (*RakeCollection, error) {
 	// Tournament rake is collected during buy-in, this is just for logging
 	slog.Info("Tournament rake already collected during buy-in",
 		"table_id", config.TableID,
 		"players", len(playerSessions),
 		"strategy", string(RakeStrategyTournament))
 
-	return "tournament-rake-collected", nil
+	return &RakeCollection{TransactionID: "tournament-rake-collected"}, nil
+}
+
+// PayRakeback returns a share of collected rake from the revenue account to a player's wallet.
+// Each player is paid at most once per period; a repeat returns the first payout's transaction.
+func (s *Service) PayRakeback(ctx context.Context, userID uuid.UUID, amount int64, tier string, periodEnd string) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("rakeback amount must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      RevenueRakeAccount,
+			Destination: PlayerWalletAccount(userID),
+			Amount:      amount,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":       "rakeback",
+		"user_id":    userID.String(),
+		"tier":       tier,
+		"period_end": periodEnd,
+	}
+
+	reference := "rakeback:" + userID.String() + ":" + periodEnd
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: reference})
+	if IsConflict(err) {
+		// Already paid for this period; the earlier run's commit was lost
+		page, lookupErr := s.client.QueryTransactions(ctx, TransactionFilter{Reference: reference}, 1, "")
+		if lookupErr != nil {
+			return "", fmt.Errorf("failed to look up rakeback payout: %w", lookupErr)
+		}
+		if len(page.Transactions) == 0 {
+			return "", fmt.Errorf("rakeback for %s was paid but its transaction was not found", userID)
+		}
+		return fmt.Sprintf("%d", page.Transactions[0].ID), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to pay rakeback: %w", err)
+	}
+
+	slog.Info("Paid rakeback", "user_id", userID, "amount", amount, "tier", tier, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// PayAffiliateCommission pays an affiliate their share of the rake generated by
+// the players they referred, out of the rake revenue account. Each affiliate is
+// paid at most once per period; a repeat returns the first payout's transaction.
+func (s *Service) PayAffiliateCommission(ctx context.Context, affiliateID uuid.UUID, amount int64, periodEnd string) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("affiliate commission must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      RevenueRakeAccount,
+			Destination: PlayerWalletAccount(affiliateID),
+			Amount:      amount,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":         "affiliate_commission",
+		"affiliate_id": affiliateID.String(),
+		"period_end":   periodEnd,
+	}
+
+	reference := "affiliate:" + affiliateID.String() + ":" + periodEnd
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: reference})
+	if IsConflict(err) {
+		// Already paid for this period; the earlier run's commit was lost
+		page, lookupErr := s.client.QueryTransactions(ctx, TransactionFilter{Reference: reference}, 1, "")
+		if lookupErr != nil {
+			return "", fmt.Errorf("failed to look up affiliate commission: %w", lookupErr)
+		}
+		if len(page.Transactions) == 0 {
+			return "", fmt.Errorf("affiliate commission for %s was paid but its transaction was not found", affiliateID)
+		}
+		return fmt.Sprintf("%d", page.Transactions[0].ID), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to pay affiliate commission: %w", err)
+	}
+
+	slog.Info("Paid affiliate commission", "affiliate_id", affiliateID, "amount", amount, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// MergeWallets moves the whole balance of a duplicate account's wallet into
+// the surviving account's wallet. The merge ID is the transaction reference,
+// so retrying a merge can't move the funds twice.
+func (s *Service) MergeWallets(ctx context.Context, fromUserID, toUserID, mergeID uuid.UUID, amount int64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("merge amount must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      PlayerWalletAccount(fromUserID),
+			Destination: PlayerWalletAccount(toUserID),
+			Amount:      amount,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":         "account_merge",
+		"merge_id":     mergeID.String(),
+		"from_user_id": fromUserID.String(),
+		"to_user_id":   toUserID.String(),
+	}
+
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: "merge:" + mergeID.String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to merge wallets: %w", err)
+	}
+
+	slog.Info("Merged wallets", "from_user_id", fromUserID, "to_user_id", toUserID, "amount", amount, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// AdjustBalance corrects a user's wallet by a signed amount against the
+// world account, recording the admin and their reason on the transaction
+func (s *Service) AdjustBalance(ctx context.Context, userID, adminID uuid.UUID, amount int64, reason string) (string, error) {
+	if amount == 0 {
+		return "", fmt.Errorf("adjustment amount must not be zero")
+	}
+
+	posting := PostingSimple{
+		Source:      WorldAccount,
+		Destination: PlayerWalletAccount(userID),
+		Amount:      amount,
+		Asset:       s.currency,
+	}
+	if amount < 0 {
+		posting.Source, posting.Destination, posting.Amount = posting.Destination, posting.Source, -amount
+	}
+
+	metadata := map[string]string{
+		"type":     "admin_adjustment",
+		"user_id":  userID.String(),
+		"admin_id": adminID.String(),
+		"reason":   reason,
+	}
+
+	transactionID, err := s.client.CreateTransaction(ctx, []PostingSimple{posting}, metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to adjust balance: %w", err)
+	}
+
+	slog.Info("Adjusted user balance", "user_id", userID, "admin_id", adminID, "amount", amount, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
+// Client returns the underlying ledger client, for maintenance tools that
+// work on the ledger as a whole
+func (s *Service) Client() *Client {
+	return s.client
 }
 
 // DepositMoney adds money to a user's main account from the world (development)
@@ -445,11 +831,54 @@ func (s *Service) DepositMoney(ctx context.Context, userID uuid.UUID, amount int
 	return transactionID, nil
 }
 
+// CreditBankDeposit credits a bank transfer from the statement to the user's
+// wallet. The bank's transaction ID is the reference, so a transfer can't be
+// credited twice.
+func (s *Service) CreditBankDeposit(ctx context.Context, userID uuid.UUID, amount int64, bankTransactionID, depositReference string) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("amount must be positive")
+	}
+
+	postings := []PostingSimple{
+		{
+			Source:      WorldAccount,
+			Destination: PlayerWalletAccount(userID),
+			Amount:      amount,
+			Asset:       s.currency,
+		},
+	}
+
+	metadata := map[string]string{
+		"type":                "deposit",
+		"method":              "bank_transfer",
+		"user_id":             userID.String(),
+		"bank_transaction_id": bankTransactionID,
+		"deposit_reference":   depositReference,
+	}
+
+	transactionID, err := s.client.CreateTransactionWithOptions(ctx, postings, metadata, TransactionOptions{Reference: "bank-deposit:" + bankTransactionID})
+	if err != nil {
+		return "", fmt.Errorf("failed to credit bank deposit: %w", err)
+	}
+
+	slog.Info("Credited bank deposit", "user_id", userID, "amount", amount, "bank_transaction_id", bankTransactionID, "transaction_id", transactionID)
+	return transactionID, nil
+}
+
 // WithdrawMoney removes money from a user's main account to the world (development)
 func (s *Service) WithdrawMoney(ctx context.Context, userID uuid.UUID, amount int64) (string, error) {
+	return s.WithdrawMoneyWithFee(ctx, userID, amount, 0)
+}
+
+// WithdrawMoneyWithFee withdraws amount from the user's wallet, paying the
+// fee to the withdrawal fees account and the rest out of the ledger
+func (s *Service) WithdrawMoneyWithFee(ctx context.Context, userID uuid.UUID, amount, fee int64) (string, error) {
 	if amount <= 0 {
 		return "", fmt.Errorf("amount must be positive")
 	}
+	if fee < 0 || fee >= amount {
+		return "", fmt.Errorf("fee must be at least zero and less than the amount")
+	}
 
 	userAccount := PlayerWalletAccount(userID)
 
@@ -457,22 +886,33 @@ func (s *Service) WithdrawMoney(ctx context.Context, userID uuid.UUID, amount in
 		{
 			Source:      userAccount,
 			Destination: WorldAccount,
-			Amount:      amount,
+			Amount:      amount - fee,
 			Asset:       s.currency,
 		},
 	}
+	if fee > 0 {
+		postings = append(postings, PostingSimple{
+			Source:      userAccount,
+			Destination: RevenueWithdrawalFeesAccount,
+			Amount:      fee,
+			Asset:       s.currency,
+		})
+	}
 
 	metadata := map[string]string{
 		"type":    "withdrawal",
 		"user_id": userID.String(),
 	}
+	if fee > 0 {
+		metadata["fee"] = fmt.Sprintf("%d", fee)
+	}
 
 	transactionID, err := s.client.CreateTransaction(ctx, postings, metadata)
 	if err != nil {
 		return "", fmt.Errorf("failed to withdraw money: %w", err)
 	}
 
-	slog.Info("Withdrew money from user account", "user_id", userID, "amount", amount, "transaction_id", transactionID)
+	slog.Info("Withdrew money from user account", "user_id", userID, "amount", amount, "fee", fee, "transaction_id", transactionID)
 	return transactionID, nil
 }
 
@@ -598,7 +1038,7 @@ func (s *Service) GetWalletTransactions(ctx context.Context, userID uuid.UUID, l
 			if typeStr, ok := txType.(string); ok {
 				// Only include wallet-level transactions
 				if typeStr == "deposit" || typeStr == "withdrawal" || typeStr == "tournament_buyin" ||
-					typeStr == "tournament_prize" || typeStr == "rake_collection" {
+					typeStr == "tournament_prize" || typeStr == "rake_collection" || typeStr == "rakeback" {
 					walletTransactions = append(walletTransactions, tx)
 					if len(walletTransactions) >= limit {
 						break
@@ -624,7 +1064,7 @@ func (s *Service) GetGameTransactions(ctx context.Context, userID uuid.UUID, lim
 		if txType, exists := tx.Metadata["type"]; exists {
 			if typeStr, ok := txType.(string); ok {
 				// Only include game-level transactions
-				if typeStr == "game_buyin" || typeStr == "game_cashout" {
+				if typeStr == "game_buyin" || typeStr == "game_cashout" || typeStr == "session_transfer" {
 					gameTransactions = append(gameTransactions, tx)
 					if len(gameTransactions) >= limit {
 						break
@@ -636,3 +1076,23 @@ func (s *Service) GetGameTransactions(ctx context.Context, userID uuid.UUID, lim
 
 	return gameTransactions, nil
 }
+
+// QueryTransactions returns a filtered page of ledger transactions for the admin ledger explorer
+func (s *Service) QueryTransactions(ctx context.Context, filter TransactionFilter, pageSize int, cursor string) (*TransactionPage, error) {
+	return s.client.QueryTransactions(ctx, filter, pageSize, cursor)
+}
+
+// GetTransaction fetches a single ledger transaction by ID
+func (s *Service) GetTransaction(ctx context.Context, txID int64) (*TransactionData, error) {
+	return s.client.GetTransaction(ctx, txID)
+}
+
+// QueryAccounts returns a page of ledger accounts matching an address pattern
+func (s *Service) QueryAccounts(ctx context.Context, pattern string, pageSize int, cursor string) (*AccountPage, error) {
+	return s.client.QueryAccounts(ctx, pattern, pageSize, cursor)
+}
+
+// GetAccount fetches a single ledger account with its volumes
+func (s *Service) GetAccount(ctx context.Context, address string) (*AccountData, error) {
+	return s.client.GetAccount(ctx, address)
+}
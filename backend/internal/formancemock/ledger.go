@@ -0,0 +1,357 @@
+// Package formancemock is an in-memory stand-in for the parts of the
+// Formance ledger v2 API that internal/formance uses, so local development
+// and integration tests can run without a Formance stack.
+package formancemock
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Formance error codes returned by the mock, as the client reads them
+const (
+	codeNotFound         = "NOT_FOUND"
+	codeValidation       = "VALIDATION"
+	codeConflict         = "CONFLICT"
+	codeInsufficientFund = "INSUFFICIENT_FUND"
+	codeInternal         = "INTERNAL"
+)
+
+// worldAccount is the only account allowed to go negative, as in Formance
+const worldAccount = "world"
+
+// ledgerError is a failure reported to the client with a Formance error code
+type ledgerError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *ledgerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+var errLedgerNotFound = errors.New("ledger not found")
+
+// Posting moves an amount of one asset between two accounts
+type Posting struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Amount      int64  `json:"amount"`
+	Asset       string `json:"asset"`
+}
+
+// Transaction is a committed ledger transaction
+type Transaction struct {
+	ID        int64             `json:"id"`
+	Postings  []Posting         `json:"postings"`
+	Metadata  map[string]string `json:"metadata"`
+	Reference string            `json:"reference,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Log types, as Formance names them
+const (
+	logNewTransaction = "NEW_TRANSACTION"
+	logSetMetadata    = "SET_METADATA"
+)
+
+// Log is one entry of the ledger's append-only log. Every write adds one.
+type Log struct {
+	ID   int64     `json:"id"`
+	Type string    `json:"type"`
+	Date time.Time `json:"date"`
+}
+
+// Volume is an account's movements in one asset
+type Volume struct {
+	Input   int64 `json:"input"`
+	Output  int64 `json:"output"`
+	Balance int64 `json:"balance"`
+}
+
+// Account is a ledger account with its volumes per asset
+type Account struct {
+	Address  string            `json:"address"`
+	Metadata map[string]string `json:"metadata"`
+	Volumes  map[string]Volume `json:"volumes"`
+}
+
+// ledger holds one named ledger's transactions and account volumes
+type ledger struct {
+	name         string
+	transactions []*Transaction // In ID order
+	logs         []Log          // In ID order
+	references   map[string]int64
+	accounts     map[string]*Account
+}
+
+func newLedger(name string) *ledger {
+	return &ledger{
+		name:       name,
+		references: make(map[string]int64),
+		accounts:   make(map[string]*Account),
+	}
+}
+
+// store is every ledger the mock serves. Transaction IDs start at zero in
+// each ledger and timestamps come from the mock's clock, so the same
+// requests always produce the same ledger.
+type store struct {
+	mu      sync.Mutex
+	clock   func() time.Time
+	ledgers map[string]*ledger
+}
+
+func (s *store) ledger(name string) (*ledger, error) {
+	l, ok := s.ledgers[name]
+	if !ok {
+		return nil, errLedgerNotFound
+	}
+	return l, nil
+}
+
+// createLedger adds an empty ledger, reporting false if it already existed
+func (s *store) createLedger(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ledgers[name]; ok {
+		return false
+	}
+	s.ledgers[name] = newLedger(name)
+	return true
+}
+
+// commit validates and applies a transaction. Postings apply in order and no
+// account but world may end up with a negative balance.
+func (s *store) commit(ledgerName string, postings []Posting, metadata map[string]string, reference string, timestamp *time.Time) (*Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return nil, err
+	}
+	if len(postings) == 0 {
+		return nil, &ledgerError{400, codeValidation, "transaction has no postings"}
+	}
+	if reference != "" {
+		if _, exists := l.references[reference]; exists {
+			return nil, &ledgerError{400, codeConflict, fmt.Sprintf("transaction with reference %s already exists", reference)}
+		}
+	}
+
+	balances := make(map[string]map[string]int64)
+	balance := func(address, asset string) int64 {
+		if assets, ok := balances[address]; ok {
+			if amount, ok := assets[asset]; ok {
+				return amount
+			}
+		}
+		if account, ok := l.accounts[address]; ok {
+			return account.Volumes[asset].Balance
+		}
+		return 0
+	}
+	setBalance := func(address, asset string, amount int64) {
+		if balances[address] == nil {
+			balances[address] = make(map[string]int64)
+		}
+		balances[address][asset] = amount
+	}
+
+	for i, p := range postings {
+		if p.Source == "" || p.Destination == "" || p.Asset == "" {
+			return nil, &ledgerError{400, codeValidation, fmt.Sprintf("posting %d needs a source, destination and asset", i)}
+		}
+		if p.Amount < 0 {
+			return nil, &ledgerError{400, codeValidation, fmt.Sprintf("posting %d has a negative amount", i)}
+		}
+		remaining := balance(p.Source, p.Asset) - p.Amount
+		if remaining < 0 && p.Source != worldAccount {
+			return nil, &ledgerError{400, codeInsufficientFund, fmt.Sprintf("account %s has insufficient funds for %d %s", p.Source, p.Amount, p.Asset)}
+		}
+		setBalance(p.Source, p.Asset, remaining)
+		setBalance(p.Destination, p.Asset, balance(p.Destination, p.Asset)+p.Amount)
+	}
+
+	tx := &Transaction{
+		ID:        int64(len(l.transactions)),
+		Postings:  postings,
+		Metadata:  copyMetadata(metadata),
+		Reference: reference,
+		Timestamp: s.clock(),
+	}
+	if timestamp != nil {
+		tx.Timestamp = timestamp.UTC()
+	}
+
+	for _, p := range postings {
+		source := l.account(p.Source)
+		v := source.Volumes[p.Asset]
+		v.Output += p.Amount
+		v.Balance = v.Input - v.Output
+		source.Volumes[p.Asset] = v
+
+		destination := l.account(p.Destination)
+		v = destination.Volumes[p.Asset]
+		v.Input += p.Amount
+		v.Balance = v.Input - v.Output
+		destination.Volumes[p.Asset] = v
+	}
+	l.transactions = append(l.transactions, tx)
+	l.appendLog(logNewTransaction, tx.Timestamp)
+	if reference != "" {
+		l.references[reference] = tx.ID
+	}
+	return tx, nil
+}
+
+// transaction returns a copy of one transaction
+func (s *store) transaction(ledgerName string, id int64) (Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if id < 0 || id >= int64(len(l.transactions)) {
+		return Transaction{}, &ledgerError{404, codeNotFound, fmt.Sprintf("transaction %d not found", id)}
+	}
+	return l.transactions[id].copy(), nil
+}
+
+// addMetadata sets metadata keys on a transaction, keeping the others
+func (s *store) addMetadata(ledgerName string, id int64, metadata map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return err
+	}
+	if id < 0 || id >= int64(len(l.transactions)) {
+		return &ledgerError{404, codeNotFound, fmt.Sprintf("transaction %d not found", id)}
+	}
+	tx := l.transactions[id]
+	for k, v := range metadata {
+		tx.Metadata[k] = v
+	}
+	// Stamped with the wall clock so a fixed clock keeps ticking only for
+	// transactions
+	l.appendLog(logSetMetadata, time.Now().UTC())
+	return nil
+}
+
+// logs returns the ledger's log, newest first
+func (s *store) logs(ledgerName string) ([]Log, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]Log, len(l.logs))
+	for i, entry := range l.logs {
+		logs[len(l.logs)-1-i] = entry
+	}
+	return logs, nil
+}
+
+// transactions returns copies of the transactions matching q, newest first
+func (s *store) transactions(ledgerName string, q *query) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return nil, err
+	}
+	matched := []Transaction{}
+	for i := len(l.transactions) - 1; i >= 0; i-- {
+		if q.matchTransaction(l.transactions[i]) {
+			matched = append(matched, l.transactions[i].copy())
+		}
+	}
+	return matched, nil
+}
+
+// account returns a copy of an account. Accounts that never moved funds
+// exist with no volumes, as Formance reports them.
+func (s *store) account(ledgerName, address string) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return Account{}, err
+	}
+	if account, ok := l.accounts[address]; ok {
+		return account.copy(), nil
+	}
+	return Account{Address: address, Metadata: map[string]string{}, Volumes: map[string]Volume{}}, nil
+}
+
+// accounts returns copies of the accounts matching q, by address
+func (s *store) accounts(ledgerName string, q *query) ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, err := s.ledger(ledgerName)
+	if err != nil {
+		return nil, err
+	}
+	matched := []Account{}
+	for _, account := range l.accounts {
+		if q.matchAccount(account) {
+			matched = append(matched, account.copy())
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Address < matched[j].Address })
+	return matched, nil
+}
+
+func (l *ledger) appendLog(logType string, date time.Time) {
+	l.logs = append(l.logs, Log{ID: int64(len(l.logs)), Type: logType, Date: date})
+}
+
+// account returns the ledger's account for address, creating it on first use
+func (l *ledger) account(address string) *Account {
+	account, ok := l.accounts[address]
+	if !ok {
+		account = &Account{Address: address, Metadata: map[string]string{}, Volumes: map[string]Volume{}}
+		l.accounts[address] = account
+	}
+	return account
+}
+
+func (tx *Transaction) copy() Transaction {
+	c := *tx
+	c.Postings = append([]Posting(nil), tx.Postings...)
+	c.Metadata = copyMetadata(tx.Metadata)
+	return c
+}
+
+func (a *Account) copy() Account {
+	c := *a
+	c.Metadata = copyMetadata(a.Metadata)
+	c.Volumes = make(map[string]Volume, len(a.Volumes))
+	for asset, v := range a.Volumes {
+		c.Volumes[asset] = v
+	}
+	return c
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	c := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,206 @@
+package formancemock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// query is a parsed Formance v2 filter expression. A nil query matches
+// everything. Supported operators are $and, $or, $not, $match, $gte, $gt,
+// $lte and $lt over the fields the client filters on.
+type query struct {
+	op       string
+	field    string
+	value    string
+	children []*query
+}
+
+// parseQuery reads a filter expression from a request body. An empty body
+// or empty object is no filter.
+func parseQuery(body []byte) (*query, error) {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return parseClause(raw)
+}
+
+func parseClause(raw map[string]json.RawMessage) (*query, error) {
+	if len(raw) != 1 {
+		return nil, fmt.Errorf("query clause must have exactly one operator, got %d", len(raw))
+	}
+
+	for op, arg := range raw {
+		switch op {
+		case "$and", "$or":
+			var clauses []map[string]json.RawMessage
+			if err := json.Unmarshal(arg, &clauses); err != nil {
+				return nil, fmt.Errorf("%s takes a list of clauses: %w", op, err)
+			}
+			q := &query{op: op}
+			for _, clause := range clauses {
+				child, err := parseClause(clause)
+				if err != nil {
+					return nil, err
+				}
+				q.children = append(q.children, child)
+			}
+			return q, nil
+		case "$not":
+			var clause map[string]json.RawMessage
+			if err := json.Unmarshal(arg, &clause); err != nil {
+				return nil, fmt.Errorf("$not takes a clause: %w", err)
+			}
+			child, err := parseClause(clause)
+			if err != nil {
+				return nil, err
+			}
+			return &query{op: op, children: []*query{child}}, nil
+		case "$match", "$gte", "$gt", "$lte", "$lt":
+			var fields map[string]string
+			if err := json.Unmarshal(arg, &fields); err != nil || len(fields) != 1 {
+				return nil, fmt.Errorf("%s takes one field and string value", op)
+			}
+			for field, value := range fields {
+				return &query{op: op, field: field, value: value}, nil
+			}
+		default:
+			return nil, fmt.Errorf("unsupported operator %s", op)
+		}
+	}
+	return nil, nil
+}
+
+// matchTransaction reports whether a transaction satisfies the query.
+// Fields are account, source, destination, reference, timestamp, id and
+// metadata[key].
+func (q *query) matchTransaction(tx *Transaction) bool {
+	if q == nil {
+		return true
+	}
+	switch q.op {
+	case "$and":
+		for _, child := range q.children {
+			if !child.matchTransaction(tx) {
+				return false
+			}
+		}
+		return true
+	case "$or":
+		for _, child := range q.children {
+			if child.matchTransaction(tx) {
+				return true
+			}
+		}
+		return false
+	case "$not":
+		return !q.children[0].matchTransaction(tx)
+	}
+
+	switch {
+	case q.field == "account" || q.field == "source" || q.field == "destination":
+		for _, p := range tx.Postings {
+			if q.field != "destination" && matchAddress(q.value, p.Source) {
+				return true
+			}
+			if q.field != "source" && matchAddress(q.value, p.Destination) {
+				return true
+			}
+		}
+		return false
+	case q.field == "reference":
+		return q.op == "$match" && tx.Reference == q.value
+	case q.field == "timestamp":
+		at, err := time.Parse(time.RFC3339Nano, q.value)
+		if err != nil {
+			return false
+		}
+		return compare(q.op, tx.Timestamp.Compare(at))
+	case q.field == "id":
+		return q.op == "$match" && fmt.Sprintf("%d", tx.ID) == q.value
+	case strings.HasPrefix(q.field, "metadata[") && strings.HasSuffix(q.field, "]"):
+		key := q.field[len("metadata[") : len(q.field)-1]
+		value, ok := tx.Metadata[key]
+		return ok && q.op == "$match" && value == q.value
+	}
+	return false
+}
+
+// matchAccount reports whether an account satisfies the query. Fields are
+// address and metadata[key].
+func (q *query) matchAccount(account *Account) bool {
+	if q == nil {
+		return true
+	}
+	switch q.op {
+	case "$and":
+		for _, child := range q.children {
+			if !child.matchAccount(account) {
+				return false
+			}
+		}
+		return true
+	case "$or":
+		for _, child := range q.children {
+			if child.matchAccount(account) {
+				return true
+			}
+		}
+		return false
+	case "$not":
+		return !q.children[0].matchAccount(account)
+	}
+
+	switch {
+	case q.field == "address":
+		return q.op == "$match" && matchAddress(q.value, account.Address)
+	case strings.HasPrefix(q.field, "metadata[") && strings.HasSuffix(q.field, "]"):
+		key := q.field[len("metadata[") : len(q.field)-1]
+		value, ok := account.Metadata[key]
+		return ok && q.op == "$match" && value == q.value
+	}
+	return false
+}
+
+// matchAddress applies Formance address matching: the pattern has as many
+// segments as the address and an empty segment matches any value
+func matchAddress(pattern, address string) bool {
+	if !strings.Contains(pattern, ":") {
+		return pattern == address
+	}
+	want := strings.Split(pattern, ":")
+	got := strings.Split(address, ":")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func compare(op string, cmp int) bool {
+	switch op {
+	case "$match":
+		return cmp == 0
+	case "$gte":
+		return cmp >= 0
+	case "$gt":
+		return cmp > 0
+	case "$lte":
+		return cmp <= 0
+	case "$lt":
+		return cmp < 0
+	}
+	return false
+}
@@ -0,0 +1,455 @@
+package formancemock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultPageSize = 15
+	maxPageSize     = 1000
+)
+
+// Options configures a mock ledger server
+type Options struct {
+	// Ledgers exist from the start; others can be created with POST /v2/{ledger}
+	Ledgers []string
+	// Start fixes the clock: the first transaction is stamped Start and each
+	// later one a second after the previous. Zero uses the wall clock.
+	Start time.Time
+
+	// FailRate is the share of API requests, from 0 to 1, answered with
+	// FailStatus instead of being served
+	FailRate   float64
+	FailStatus int
+	// FailPaths limits failures to requests whose path contains one of these
+	FailPaths []string
+	// Latency delays every API request
+	Latency time.Duration
+	// Seed makes injected failures repeat across runs
+	Seed int64
+}
+
+// Server is an in-memory Formance ledger v2 API
+type Server struct {
+	opts   Options
+	store  *store
+	router chi.Router
+
+	faultMu sync.Mutex
+	rand    *rand.Rand
+}
+
+// NewServer returns a mock ledger holding opts.Ledgers, all empty
+func NewServer(opts Options) *Server {
+	if opts.FailStatus == 0 {
+		opts.FailStatus = http.StatusInternalServerError
+	}
+
+	s := &Server{
+		opts:  opts,
+		store: &store{},
+		rand:  rand.New(rand.NewSource(opts.Seed)),
+	}
+	s.Reset()
+
+	r := chi.NewRouter()
+	r.Get("/_healthcheck", s.healthcheck)
+	r.Post("/_mock/reset", s.reset)
+	r.Route("/v2/{ledger}", func(r chi.Router) {
+		r.Use(s.faults)
+
+		r.Post("/", s.createLedger)
+		r.Get("/_info", s.ledgerInfo)
+		r.Get("/accounts", s.listAccounts)
+		r.Get("/accounts/{address}", s.getAccount)
+		r.Get("/aggregate/balances", s.aggregateBalances)
+		r.Get("/logs", s.listLogs)
+		r.Get("/transactions", s.listTransactions)
+		r.Post("/transactions", s.createTransaction)
+		r.Get("/transactions/{id}", s.getTransaction)
+		r.Post("/transactions/{id}/metadata", s.addMetadata)
+		r.Put("/transactions/{id}/metadata", s.addMetadata)
+	})
+	s.router = r
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// Reset drops every transaction and account and restarts the clock and the
+// failure sequence, leaving the configured ledgers empty
+func (s *Server) Reset() {
+	s.store.mu.Lock()
+	s.store.ledgers = make(map[string]*ledger)
+	for _, name := range s.opts.Ledgers {
+		s.store.ledgers[name] = newLedger(name)
+	}
+	s.store.clock = newClock(s.opts.Start)
+	s.store.mu.Unlock()
+
+	s.faultMu.Lock()
+	s.rand = rand.New(rand.NewSource(s.opts.Seed))
+	s.faultMu.Unlock()
+}
+
+// newClock returns the wall clock, or one that ticks a second per reading
+// from start
+func newClock(start time.Time) func() time.Time {
+	if start.IsZero() {
+		return func() time.Time { return time.Now().UTC() }
+	}
+	next := start.UTC()
+	return func() time.Time {
+		now := next
+		next = next.Add(time.Second)
+		return now
+	}
+}
+
+// faults delays requests and fails a share of them as configured
+func (s *Server) faults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Latency > 0 {
+			select {
+			case <-time.After(s.opts.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if s.shouldFail(r.URL.Path) {
+			slog.Info("Injecting ledger failure", "method", r.Method, "path", r.URL.Path, "status", s.opts.FailStatus)
+			writeError(w, &ledgerError{s.opts.FailStatus, codeInternal, "injected failure"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) shouldFail(path string) bool {
+	if s.opts.FailRate <= 0 {
+		return false
+	}
+	if len(s.opts.FailPaths) > 0 {
+		matched := false
+		for _, p := range s.opts.FailPaths {
+			if strings.Contains(path, p) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	s.faultMu.Lock()
+	defer s.faultMu.Unlock()
+	return s.rand.Float64() < s.opts.FailRate
+}
+
+func (s *Server) healthcheck(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) reset(w http.ResponseWriter, r *http.Request) {
+	s.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createLedger(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "ledger")
+	if !s.store.createLedger(name) {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeConflict, "ledger " + name + " already exists"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) ledgerInfo(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "ledger")
+	s.store.mu.Lock()
+	_, err := s.store.ledger(name)
+	s.store.mu.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"name":    name,
+			"storage": map[string]interface{}{"migrations": []interface{}{}},
+		},
+	})
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, r *http.Request) {
+	account, err := s.store.account(chi.URLParam(r, "ledger"), chi.URLParam(r, "address"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": account})
+}
+
+func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	page, err := readPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q, err := parseQuery(page.Query)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, err.Error()})
+		return
+	}
+
+	accounts, err := s.store.accounts(chi.URLParam(r, "ledger"), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeCursor(w, page, accounts)
+}
+
+// aggregateBalances sums the balances of the accounts matching the filter
+func (s *Server) aggregateBalances(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "failed to read body"})
+		return
+	}
+	q, err := parseQuery(body)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, err.Error()})
+		return
+	}
+
+	accounts, err := s.store.accounts(chi.URLParam(r, "ledger"), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	totals := make(map[string]int64)
+	for _, account := range accounts {
+		for asset, v := range account.Volumes {
+			totals[asset] += v.Balance
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": totals})
+}
+
+func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	page, err := readPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q, err := parseQuery(page.Query)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, err.Error()})
+		return
+	}
+
+	transactions, err := s.store.transactions(chi.URLParam(r, "ledger"), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeCursor(w, page, transactions)
+}
+
+func (s *Server) listLogs(w http.ResponseWriter, r *http.Request) {
+	page, err := readPage(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	logs, err := s.store.logs(chi.URLParam(r, "ledger"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeCursor(w, page, logs)
+}
+
+// createTransactionRequest is the body of POST /transactions. Numscript
+// transactions are not supported.
+type createTransactionRequest struct {
+	Postings  []Posting         `json:"postings"`
+	Script    json.RawMessage   `json:"script"`
+	Metadata  map[string]string `json:"metadata"`
+	Reference string            `json:"reference"`
+	Timestamp *time.Time        `json:"timestamp"`
+}
+
+func (s *Server) createTransaction(w http.ResponseWriter, r *http.Request) {
+	var req createTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "invalid transaction: " + err.Error()})
+		return
+	}
+	if len(req.Script) > 0 && string(req.Script) != "null" {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "numscript transactions are not supported by the mock"})
+		return
+	}
+
+	tx, err := s.store.commit(chi.URLParam(r, "ledger"), req.Postings, req.Metadata, req.Reference, req.Timestamp)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": tx})
+}
+
+func (s *Server) getTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "invalid transaction ID"})
+		return
+	}
+
+	tx, err := s.store.transaction(chi.URLParam(r, "ledger"), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": tx})
+}
+
+func (s *Server) addMetadata(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "invalid transaction ID"})
+		return
+	}
+	var metadata map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&metadata); err != nil {
+		writeError(w, &ledgerError{http.StatusBadRequest, codeValidation, "metadata must be an object of strings"})
+		return
+	}
+
+	if err := s.store.addMetadata(chi.URLParam(r, "ledger"), id, metadata); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pageRequest is where a list request starts. The filter and page size of
+// the first request are carried in the cursor of the following ones.
+type pageRequest struct {
+	Offset   int             `json:"offset"`
+	PageSize int             `json:"pageSize"`
+	Query    json.RawMessage `json:"query,omitempty"`
+}
+
+func readPage(r *http.Request) (pageRequest, error) {
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		var page pageRequest
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			err = json.Unmarshal(raw, &page)
+		}
+		if err != nil {
+			return pageRequest{}, &ledgerError{http.StatusBadRequest, codeValidation, "invalid cursor"}
+		}
+		return page, nil
+	}
+
+	page := pageRequest{PageSize: defaultPageSize}
+	if raw := r.URL.Query().Get("pageSize"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size <= 0 {
+			return pageRequest{}, &ledgerError{http.StatusBadRequest, codeValidation, "invalid pageSize"}
+		}
+		page.PageSize = size
+	}
+	if page.PageSize > maxPageSize {
+		page.PageSize = maxPageSize
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return pageRequest{}, &ledgerError{http.StatusBadRequest, codeValidation, "failed to read body"}
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		page.Query = body
+	}
+	return page, nil
+}
+
+// writeCursor writes one page of items in the Formance cursor envelope
+func writeCursor[T any](w http.ResponseWriter, page pageRequest, items []T) {
+	start := page.Offset
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + page.PageSize
+	if end > len(items) {
+		end = len(items)
+	}
+
+	cursor := map[string]interface{}{
+		"pageSize": page.PageSize,
+		"hasMore":  end < len(items),
+		"data":     items[start:end],
+	}
+	if end < len(items) {
+		next := page
+		next.Offset = end
+		raw, _ := json.Marshal(next)
+		cursor["next"] = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	if start > 0 {
+		previous := page
+		previous.Offset = start - page.PageSize
+		if previous.Offset < 0 {
+			previous.Offset = 0
+		}
+		raw, _ := json.Marshal(previous)
+		cursor["previous"] = base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cursor": cursor})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError reports err with the code and message fields the client reads
+func writeError(w http.ResponseWriter, err error) {
+	var ledgerErr *ledgerError
+	switch {
+	case errors.As(err, &ledgerErr):
+	case errors.Is(err, errLedgerNotFound):
+		ledgerErr = &ledgerError{http.StatusNotFound, codeNotFound, "ledger not found"}
+	default:
+		ledgerErr = &ledgerError{http.StatusInternalServerError, codeInternal, err.Error()}
+	}
+
+	writeJSON(w, ledgerErr.status, map[string]string{
+		"code":    ledgerErr.code,
+		"message": ledgerErr.message,
+	})
+}
@@ -0,0 +1,158 @@
+// Package gpctl is a small client for the admin API, used by the gpctl
+// command so operators can act on a running server without the frontend.
+package gpctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the API root used when none is configured
+const DefaultBaseURL = "http://localhost:8080/api/v1"
+
+// APIError is a non-2xx response from the server
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+// Client calls the API as the user the token belongs to
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New creates a client for the API rooted at baseURL, e.g.
+// https://poker.example.com/api/v1
+func New(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Do sends a request with an optional JSON body and returns the raw JSON
+// response
+func (c *Client) Do(ctx context.Context, method, path string, body interface{}) (json.RawMessage, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Status: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errorBody struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &errorBody) == nil && errorBody.Error != "" {
+			apiErr.Message = errorBody.Error
+		}
+		return nil, apiErr
+	}
+	return data, nil
+}
+
+// ListTables returns the lobby's tables, optionally only those with a status
+func (c *Client) ListTables(ctx context.Context, status string) (json.RawMessage, error) {
+	query := url.Values{"limit": {"100"}}
+	if status != "" {
+		query.Set("status", status)
+	}
+	return c.Do(ctx, http.MethodGet, "/tables?"+query.Encode(), nil)
+}
+
+// CloseTables closes empty tables by ID; tables with players are skipped
+func (c *Client) CloseTables(ctx context.Context, tableIDs []string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/tables/close", map[string]interface{}{
+		"table_ids": tableIDs,
+	})
+}
+
+// ForceCloseTable closes a running table by name, cashing out its players
+func (c *Client) ForceCloseTable(ctx context.Context, name, reason string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/tables/force-close", map[string]string{
+		"table":  name,
+		"reason": reason,
+	})
+}
+
+// TableRecording dumps a running table's flight recording, for replaying
+// with table-replay
+func (c *Client) TableRecording(ctx context.Context, name string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodGet, "/admin/websocket/recording?"+url.Values{"table": {name}}.Encode(), nil)
+}
+
+// AdjustBalance credits a user's wallet, or debits it for a negative amount
+func (c *Client) AdjustBalance(ctx context.Context, userID string, amount int64, reason string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/users/"+url.PathEscape(userID)+"/adjustments", map[string]interface{}{
+		"amount": amount,
+		"reason": reason,
+	})
+}
+
+// PauseTournament pauses a running tournament
+func (c *Client) PauseTournament(ctx context.Context, tournamentID string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/tournaments/"+url.PathEscape(tournamentID)+"/pause", nil)
+}
+
+// ResumeTournament resumes a paused tournament
+func (c *Client) ResumeTournament(ctx context.Context, tournamentID string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/tournaments/"+url.PathEscape(tournamentID)+"/resume", nil)
+}
+
+// Reconcile checks the ledger against the database
+func (c *Client) Reconcile(ctx context.Context) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPost, "/admin/ledger/reconcile", nil)
+}
+
+// ListFlags returns the feature flags
+func (c *Client) ListFlags(ctx context.Context) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodGet, "/admin/feature-flags", nil)
+}
+
+// SetFlag switches a feature flag on or off
+func (c *Client) SetFlag(ctx context.Context, key string, enabled bool, reason string) (json.RawMessage, error) {
+	return c.Do(ctx, http.MethodPut, "/admin/feature-flags/"+url.PathEscape(key), map[string]interface{}{
+		"enabled": enabled,
+		"reason":  reason,
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ActivePlayHandler struct {
+	activePlayService *services.ActivePlayService
+}
+
+func NewActivePlayHandler(activePlayService *services.ActivePlayService) *ActivePlayHandler {
+	return &ActivePlayHandler{
+		activePlayService: activePlayService,
+	}
+}
+
+func (h *ActivePlayHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetActivePlay)
+
+	return r
+}
+
+// GetActivePlay returns every table and tournament the user is playing, with
+// a reconnect ticket for each running table, so a new device can resume them
+func (h *ActivePlayHandler) GetActivePlay(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+	username, _ := auth.GetUsernameFromContext(r.Context())
+
+	play, err := h.activePlayService.ActivePlay(r.Context(), userID, username)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get active play")
+		return
+	}
+
+	// Reconnect tickets would let support take the user's seat
+	if _, impersonated := auth.GetImpersonatorIDFromContext(r.Context()); impersonated {
+		for i := range play.Tables {
+			play.Tables[i].ReconnectTicket = ""
+			play.Tables[i].TicketExpiresAt = nil
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSONResponse(w, http.StatusOK, play)
+}
@@ -9,36 +9,250 @@ import (
 	"github.com/anhbaysgalan1/gp/internal/database"
 	"github.com/anhbaysgalan1/gp/internal/formance"
 	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type AdminHandler struct {
-	db              *database.DB
-	formanceService *formance.Service
+	db                   *database.DB
+	formanceService      *formance.Service
+	stakeTemplateService *services.StakeTemplateService
+	directMessageService *services.DirectMessageService
+	chatModeration       *services.ChatModerationService
+	seatingService       *services.SeatingService
+	gameSessionService   *services.GameSessionService
+	fairnessService      *services.FairnessService
+	payoutService        *services.TournamentPayoutService
+	cloneService         *services.CloneService
+	branding             *services.BrandingService
+	accountMerge         *services.AccountMergeService
+	tableMaintenance     TableMaintenance
+	sendQueueMonitor     SendQueueMonitor
+	tableRecorder        TableRecorder
+	liveTables           LiveTableMonitor
+	cluster              ClusterRegistry
+	velocity             *services.VelocityService
+	exposure             *services.ExposureService
+	impersonation        *services.ImpersonationService
+	bankroll             *services.BankrollService
+	featureFlags         *services.FeatureFlagService
+	handDisputes         HandDisputes
+	tableControl         TableControl
+	handAdjudications    *services.HandAdjudicationService
+	backups              *services.BackupService
+	maintenance          *services.MaintenanceService
+	spins                *services.SpinService
+	bankDeposits         *services.BankDepositService
+	engineMigration      *services.EngineMigrationService
+	treasury             *services.TreasuryService
+	rakeFree             *services.RakeFreeService
+	seatHolds            *services.SeatReservationService
+	regulatorExports     *services.RegulatorExportService
+	pushService          *services.PushService
 }
 
 func NewAdminHandler(db *database.DB, formanceService *formance.Service) *AdminHandler {
 	return &AdminHandler{
-		db:              db,
-		formanceService: formanceService,
+		db:                   db,
+		formanceService:      formanceService,
+		stakeTemplateService: services.NewStakeTemplateService(db),
+		directMessageService: services.NewDirectMessageService(db),
+		chatModeration:       services.NewChatModerationService(db),
+		seatingService:       services.NewSeatingService(db),
+		gameSessionService:   services.NewGameSessionService(db),
+		fairnessService:      services.NewFairnessService(db),
+		payoutService:        services.NewTournamentPayoutService(db),
+		cloneService:         services.NewCloneService(db),
+		branding:             services.NewBrandingService(db),
+		accountMerge:         services.NewAccountMergeService(db, formanceService),
+		handAdjudications:    services.NewHandAdjudicationService(db),
+		engineMigration:      services.NewEngineMigrationService(db),
+		treasury:             services.NewTreasuryService(db, formanceService),
 	}
 }
 
 func (h *AdminHandler) Routes(roleMiddleware *auth.RoleMiddleware) chi.Router {
 	r := chi.NewRouter()
 
-	// All admin routes require admin role
-	r.Use(roleMiddleware.RequireAdmin)
-
-	r.Get("/users", h.ListUsers)
-	r.Put("/users/{userID}/role", h.UpdateUserRole)
-	r.Delete("/users/{userID}", h.DeleteUser)
-	r.Get("/stats", h.GetSystemStats)
-
-	// Development only - balance management endpoints
-	r.Post("/users/{userID}/deposit", h.DepositMoney)
-	r.Post("/users/{userID}/withdraw", h.WithdrawMoney)
+	// Ledger explorer is also open to finance staff
+	r.With(roleMiddleware.RequireFinance).Mount("/ledger", h.ledgerRoutes())
+
+	// Bank statement import and the deposit review queue are finance work
+	r.With(roleMiddleware.RequireFinance).Mount("/bank-deposits", h.bankDepositRoutes())
+
+	// Liabilities, house funds and net exposure across the ledger
+	r.With(roleMiddleware.RequireFinance).Get("/treasury", h.GetTreasury)
+
+	// Signed reports of a player's or a table's betting for regulators
+	r.With(roleMiddleware.RequireFinance).Get("/regulator-export", h.ExportForRegulator)
+	r.With(roleMiddleware.RequireFinance).Get("/regulator-export/public-key", h.GetRegulatorExportPublicKey)
+
+	// Disputed hands can be frozen and ruled on by moderators
+	r.With(roleMiddleware.RequireModerator).Mount("/disputes", h.disputeRoutes())
+
+	// Moderators can step in at a running table
+	r.Group(func(r chi.Router) {
+		r.Use(roleMiddleware.RequireModerator)
+
+		r.Post("/tables/{tableID}/force-fold", h.ForceFold)
+		r.Post("/tables/{tableID}/pause", h.PauseTable)
+		r.Post("/tables/{tableID}/resume", h.ResumeTable)
+		r.Post("/tables/{tableID}/kick", h.KickPlayer)
+		r.Post("/tables/{tableID}/cancel-hand", h.CancelHand)
+
+		// Table chat: the full history, and players muted at tables
+		r.Get("/chat-history", h.ListChatHistory)
+		r.Get("/chat-mutes", h.ListTableChatMutes)
+		r.Delete("/chat-mutes/{muteID}", h.LiftTableChatMute)
+	})
+
+	// All other admin routes require admin role
+	r.Group(func(r chi.Router) {
+		r.Use(roleMiddleware.RequireAdmin)
+
+		r.Get("/users", h.ListUsers)
+		r.Put("/users/{userID}/role", h.UpdateUserRole)
+		r.Delete("/users/{userID}", h.DeleteUser)
+		r.Get("/stats", h.GetSystemStats)
+
+		// Stake templates and bulk table operations
+		r.Get("/stake-templates", h.ListStakeTemplates)
+		r.Post("/stake-templates", h.CreateStakeTemplate)
+		r.Get("/stake-templates/{templateID}", h.GetStakeTemplate)
+		r.Put("/stake-templates/{templateID}", h.UpdateStakeTemplate)
+		r.Delete("/stake-templates/{templateID}", h.DeleteStakeTemplate)
+		r.Post("/stake-templates/{templateID}/open-tables", h.OpenTablesFromTemplate)
+		r.Post("/tables/close", h.CloseTables)
+
+		// Incident response: freeze running tables without dropping connections
+		r.Get("/tables/read-only", h.GetReadOnlyTables)
+		r.Put("/tables/read-only", h.SetReadOnly)
+		r.Post("/tables/force-close", h.ForceCloseTable)
+
+		// Switches for turning features off during an incident
+		r.Get("/feature-flags", h.ListFeatureFlags)
+		r.Put("/feature-flags/{key}", h.SetFeatureFlag)
+
+		// Scheduled maintenance, enforced automatically while in force
+		r.Get("/maintenance-windows", h.ListMaintenanceWindows)
+		r.Post("/maintenance-windows", h.CreateMaintenanceWindow)
+		r.Put("/maintenance-windows/{windowID}", h.UpdateMaintenanceWindow)
+		r.Delete("/maintenance-windows/{windowID}", h.DeleteMaintenanceWindow)
+
+		// Promotional hours in which tables take no rake
+		r.Get("/rake-free-windows", h.ListRakeFreeWindows)
+		r.Post("/rake-free-windows", h.CreateRakeFreeWindow)
+		r.Put("/rake-free-windows/{windowID}", h.UpdateRakeFreeWindow)
+		r.Delete("/rake-free-windows/{windowID}", h.DeleteRakeFreeWindow)
+
+		// Seats held for named players, e.g. invite-only featured lineups
+		r.Get("/tables/{tableID}/seat-reservations", h.ListSeatReservations)
+		r.Post("/tables/{tableID}/seat-reservations", h.CreateSeatReservation)
+		r.Delete("/seat-reservations/{reservationID}", h.CancelSeatReservation)
+
+		// WebSocket outbound queue health
+		r.Get("/websocket/send-queues", h.GetSendQueueStats)
+
+		// A table's flight recording, for replaying bugs
+		r.Get("/websocket/recording", h.GetTableRecording)
+
+		// Open tables and where each is in dealing hands
+		r.Get("/websocket/tables", h.GetLiveTables)
+
+		// Game server instances and table ownership leases
+		r.Get("/cluster", h.GetClusterStatus)
+		r.Put("/cluster/leases", h.ReassignTableLease)
+
+		// Direct message moderation
+		r.Get("/message-reports", h.ListMessageReports)
+		r.Put("/message-reports/{reportID}", h.ReviewMessageReport)
+
+		// Chat messages blocked by automatic moderation
+		r.Get("/moderated-messages", h.ListModeratedMessages)
+		r.Put("/moderated-messages/{messageID}", h.ReviewModeratedMessage)
+
+		// Collusion-resistant seating
+		r.Get("/seating-separations", h.ListSeatingSeparations)
+		r.Post("/seating-separations", h.FlagSeatingPair)
+		r.Delete("/seating-separations/{separationID}", h.RemoveSeatingSeparation)
+		r.Put("/tables/{tableID}/seating", h.UpdateTableSeatingPolicy)
+
+		// Partial cash-outs
+		r.Get("/partial-cash-outs", h.ListPartialCashOuts)
+		r.Put("/tables/{tableID}/cash-out-policy", h.UpdateCashOutPolicy)
+
+		// RNG fairness reports
+		r.Get("/fairness", h.ListFairnessReports)
+		r.Post("/fairness/generate", h.GenerateFairnessReports)
+
+		// Spin formats and the jackpot their house edge goes to
+		r.Get("/spin-formats", h.ListSpinFormats)
+		r.Post("/spin-formats", h.CreateSpinFormat)
+		r.Put("/spin-formats/{formatID}", h.UpdateSpinFormat)
+		r.Get("/spins/jackpot", h.GetSpinJackpot)
+
+		// Breaks in a running tournament
+		r.Post("/tournaments/{tournamentID}/pause", h.PauseTournament)
+		r.Post("/tournaments/{tournamentID}/resume", h.ResumeTournament)
+
+		// Tournament payout preview and adjustment
+		r.Get("/tournaments/{tournamentID}/payouts", h.GetTournamentPayouts)
+		r.Get("/tournaments/{tournamentID}/payouts/preview", h.PreviewTournamentPayouts)
+		r.Put("/tournaments/{tournamentID}/payouts", h.AdjustTournamentPayouts)
+
+		// Copy a table or tournament's configuration to a new one
+		r.Post("/tables/{tableID}/clone", h.CloneTable)
+		r.Post("/tournaments/{tournamentID}/clone", h.CloneTournament)
+
+		// Sponsor and partner branding shown in the lobby
+		r.Put("/tables/{tableID}/branding", h.UpdateTableBranding)
+		r.Put("/tournaments/{tournamentID}/branding", h.UpdateTournamentBranding)
+
+		// Moving tables onto the internal engine once it matches the legacy game
+		r.Get("/tables/{tableID}/engine-parity", h.GetTableEngineParity)
+		r.Put("/tables/{tableID}/execution-path", h.UpdateTableExecutionPath)
+
+		// KYC tiers and velocity limit overrides
+		r.Get("/users/{userID}/velocity", h.GetUserVelocity)
+		r.Put("/users/{userID}/kyc-tier", h.UpdateKYCTier)
+		r.Post("/users/{userID}/velocity-overrides", h.GrantVelocityOverride)
+		r.Delete("/velocity-overrides/{overrideID}", h.RevokeVelocityOverride)
+
+		// Maximum exposure across a user's live game sessions
+		r.Get("/users/{userID}/exposure", h.GetUserExposure)
+		r.Post("/users/{userID}/exposure-overrides", h.GrantExposureOverride)
+		r.Delete("/exposure-overrides/{overrideID}", h.RevokeExposureOverride)
+
+		// Jurisdictions and responsible gaming rebuy limits
+		r.Get("/jurisdictions", h.ListJurisdictions)
+		r.Get("/users/{userID}/bankroll", h.GetUserBankroll)
+		r.Put("/users/{userID}/jurisdiction", h.UpdateUserJurisdiction)
+
+		// Duplicate account merges and support notes
+		r.Post("/users/{userID}/merge", h.MergeAccounts)
+		r.Get("/account-merges", h.ListAccountMerges)
+		r.Get("/users/{userID}/notes", h.GetUserNotes)
+		r.Post("/users/{userID}/notes", h.AddUserNote)
+
+		// Read-only impersonation with the user's consent
+		r.Post("/users/{userID}/impersonate", h.StartImpersonation)
+		r.Get("/impersonations", h.ListImpersonations)
+		r.Get("/impersonations/{sessionID}/actions", h.GetImpersonationActions)
+		r.Delete("/impersonations/{sessionID}", h.EndImpersonation)
+
+		// Consistency markers for reconciling restored backups with the ledger
+		r.Get("/backup-markers", h.ListBackupMarkers)
+		r.Post("/backup-markers", h.TakeBackupMarker)
+
+		// Balance corrections with a recorded reason
+		r.Post("/users/{userID}/adjustments", h.AdjustUserBalance)
+
+		// Development only - balance management endpoints
+		r.Post("/users/{userID}/deposit", h.DepositMoney)
+		r.Post("/users/{userID}/withdraw", h.WithdrawMoney)
+	})
 
 	return r
 }
@@ -84,7 +298,7 @@ func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 type UpdateUserRoleRequest struct {
-	Role string `json:"role" validate:"required,oneof=player moderator admin"`
+	Role string `json:"role" validate:"required,oneof=player moderator finance admin"`
 }
 
 // UpdateUserRole updates a user's role (admin only)
@@ -109,6 +323,8 @@ func (h *AdminHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
 		newRole = models.UserRolePlayer
 	case "moderator":
 		newRole = models.UserRoleMod
+	case "finance":
+		newRole = models.UserRoleFinance
 	case "admin":
 		newRole = models.UserRoleAdmin
 	default:
@@ -211,6 +427,10 @@ func (h *AdminHandler) DepositMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeDeposits) {
+		return
+	}
+
 	var req DepositMoneyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
@@ -229,13 +449,21 @@ func (h *AdminHandler) DepositMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reservation, ok := reserveVelocityLimit(w, r, h.velocity, userID, models.WalletOperationDeposit, req.Amount)
+	if !ok {
+		return
+	}
+
 	// Create deposit transaction using Formance
 	transactionID, err := h.formanceService.DepositMoney(r.Context(), userID, req.Amount)
 	if err != nil {
+		releaseVelocityOperation(r, h.velocity, reservation)
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to deposit money: "+err.Error())
 		return
 	}
 
+	completeVelocityOperation(r, h.velocity, reservation, transactionID)
+
 	response := map[string]interface{}{
 		"message":        "Money deposited successfully",
 		"user_id":        userID,
@@ -290,13 +518,21 @@ func (h *AdminHandler) WithdrawMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reservation, ok := reserveVelocityLimit(w, r, h.velocity, userID, models.WalletOperationWithdrawal, req.Amount)
+	if !ok {
+		return
+	}
+
 	// Create withdrawal transaction using Formance
 	transactionID, err := h.formanceService.WithdrawMoney(r.Context(), userID, req.Amount)
 	if err != nil {
+		releaseVelocityOperation(r, h.velocity, reservation)
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to withdraw money: "+err.Error())
 		return
 	}
 
+	completeVelocityOperation(r, h.velocity, reservation, transactionID)
+
 	response := map[string]interface{}{
 		"message":        "Money withdrawn successfully",
 		"user_id":        userID,
@@ -306,3 +542,66 @@ func (h *AdminHandler) WithdrawMoney(w http.ResponseWriter, r *http.Request) {
 
 	writeJSONResponse(w, http.StatusOK, response)
 }
+
+type adjustBalanceRequest struct {
+	Amount int64  `json:"amount" validate:"required"`                // MNT, negative to debit
+	Reason string `json:"reason" validate:"required,min=10,max=500"` // Ticket reference and why the balance is wrong
+}
+
+// AdjustUserBalance credits or debits a user's wallet to correct it. The
+// reason and the admin are recorded on the ledger transaction (admin only).
+func (h *AdminHandler) AdjustUserBalance(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req adjustBalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if req.Amount < 0 {
+		balance, err := h.formanceService.GetUserBalance(r.Context(), userID, h.db.DB)
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to check user balance")
+			return
+		}
+		if balance.MainBalance < -req.Amount {
+			writeErrorResponse(w, http.StatusBadRequest, "Insufficient balance for debit")
+			return
+		}
+	}
+
+	transactionID, err := h.formanceService.AdjustBalance(r.Context(), userID, adminUserID, req.Amount, req.Reason)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to adjust balance")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":        "Balance adjusted",
+		"user_id":        userID,
+		"amount":         req.Amount,
+		"reason":         req.Reason,
+		"transaction_id": transactionID,
+	})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+)
+
+// SetBackupService enables the backup consistency marker endpoints
+func (h *AdminHandler) SetBackupService(backups *services.BackupService) {
+	h.backups = backups
+}
+
+// ListBackupMarkers returns the newest consistency markers, 20 by default
+// (admin only)
+func (h *AdminHandler) ListBackupMarkers(w http.ResponseWriter, r *http.Request) {
+	if h.backups == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Backup markers are not available")
+		return
+	}
+
+	limit := 20
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			writeErrorResponse(w, http.StatusBadRequest, "Limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	markers, err := h.backups.ListMarkers(r.Context(), limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list backup markers")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"markers": markers,
+	})
+}
+
+// TakeBackupMarker records a consistency marker now, e.g. just before a
+// manual database backup (admin only)
+func (h *AdminHandler) TakeBackupMarker(w http.ResponseWriter, r *http.Request) {
+	if h.backups == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Backup markers are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	marker, err := h.backups.TakeMarker(r.Context(), models.BackupMarkerManual, &adminUserID)
+	if err != nil {
+		if errors.Is(err, formance.ErrWritesBusy) {
+			writeErrorResponse(w, http.StatusConflict, "Ledger writes did not pause in time, try again shortly")
+			return
+		}
+		if marker != nil {
+			// Recorded, but the manifest could not be written
+			writeJSONResponse(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":  "Backup marker recorded but its manifest could not be written",
+				"marker": marker,
+			})
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to take backup marker")
+		return
+	}
+	writeJSONResponse(w, http.StatusCreated, marker)
+}
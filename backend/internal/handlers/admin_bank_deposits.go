@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetBankDeposits enables bank statement import and the deposit review queue
+func (h *AdminHandler) SetBankDeposits(bankDeposits *services.BankDepositService) {
+	h.bankDeposits = bankDeposits
+}
+
+// bankDepositRoutes lets finance staff import bank statements and work
+// through the transfers that couldn't be matched to a player
+func (h *AdminHandler) bankDepositRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/statements", h.ImportBankStatement)
+	r.Get("/rows", h.ListBankStatementRows)
+	r.Post("/rows/{rowID}/assign", h.AssignBankStatementRow)
+	r.Post("/rows/{rowID}/reject", h.RejectBankStatementRow)
+
+	return r
+}
+
+// ImportBankStatement records a statement's incoming transfers, crediting
+// those quoting a deposit reference and queueing the rest for review
+// (finance and admin only)
+func (h *AdminHandler) ImportBankStatement(w http.ResponseWriter, r *http.Request) {
+	if h.bankDeposits == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bank deposits are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeDeposits) {
+		return
+	}
+
+	var req models.ImportBankStatementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.bankDeposits.ImportStatement(r.Context(), userID, req.Rows)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to import bank statement")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
+
+// ListBankStatementRows returns imported statement rows, newest first. The
+// review queue is status=in_review (finance and admin only).
+func (h *AdminHandler) ListBankStatementRows(w http.ResponseWriter, r *http.Request) {
+	if h.bankDeposits == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bank deposits are not available")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.StatementRowCredited, models.StatementRowInReview, models.StatementRowRejected:
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	rows, total, err := h.bankDeposits.ListRows(r.Context(), status, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch statement rows")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"rows": rows,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// AssignBankStatementRow credits a transfer in the review queue to the
+// player identified as its payer (finance and admin only)
+func (h *AdminHandler) AssignBankStatementRow(w http.ResponseWriter, r *http.Request) {
+	if h.bankDeposits == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bank deposits are not available")
+		return
+	}
+
+	reviewerID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	rowID, err := uuid.Parse(chi.URLParam(r, "rowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid row ID")
+		return
+	}
+
+	var req models.AssignStatementRowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	row, err := h.bankDeposits.AssignRow(r.Context(), rowID, req.UserID, reviewerID, req.Note)
+	if err != nil {
+		writeBankDepositError(w, err, "Failed to credit bank deposit")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, row)
+}
+
+// RejectBankStatementRow takes a transfer out of the review queue without
+// crediting anyone (finance and admin only)
+func (h *AdminHandler) RejectBankStatementRow(w http.ResponseWriter, r *http.Request) {
+	if h.bankDeposits == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bank deposits are not available")
+		return
+	}
+
+	reviewerID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	rowID, err := uuid.Parse(chi.URLParam(r, "rowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid row ID")
+		return
+	}
+
+	var req models.RejectStatementRowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	row, err := h.bankDeposits.RejectRow(r.Context(), rowID, reviewerID, req.Note)
+	if err != nil {
+		writeBankDepositError(w, err, "Failed to reject statement row")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, row)
+}
+
+func writeBankDepositError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrStatementRowNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Statement row not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, services.ErrStatementRowSettled):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrAccountDisabled):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetBankrollService enables the jurisdiction and rebuy limit endpoints
+func (h *AdminHandler) SetBankrollService(bankroll *services.BankrollService) {
+	h.bankroll = bankroll
+}
+
+// ListJurisdictions returns every jurisdiction users can be assigned and its
+// rebuy limits (admin only)
+func (h *AdminHandler) ListJurisdictions(w http.ResponseWriter, r *http.Request) {
+	if h.bankroll == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bankroll limits are not available")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"jurisdictions": h.bankroll.Jurisdictions(),
+	})
+}
+
+// GetUserBankroll returns a user's jurisdiction and rebuy limits (admin only)
+func (h *AdminHandler) GetUserBankroll(w http.ResponseWriter, r *http.Request) {
+	if h.bankroll == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bankroll limits are not available")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	summary, err := h.bankroll.Summary(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get bankroll limits")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
+
+// UpdateUserJurisdiction assigns the jurisdiction whose rebuy limits apply
+// to a user (admin only)
+func (h *AdminHandler) UpdateUserJurisdiction(w http.ResponseWriter, r *http.Request) {
+	if h.bankroll == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bankroll limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateJurisdictionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.bankroll.SetJurisdiction(r.Context(), userID, adminUserID, req.Jurisdiction)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownJurisdiction):
+			writeErrorResponse(w, http.StatusBadRequest, "Unknown jurisdiction")
+		case errors.Is(err, services.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to update jurisdiction")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UpdateTableBranding replaces the banner, sponsor, accent color and
+// description shown for a table (admin only)
+func (h *AdminHandler) UpdateTableBranding(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	req, ok := decodeBrandingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	table, err := h.branding.SetTableBranding(r.Context(), tableID, req, adminUserID)
+	if err != nil {
+		writeBrandingError(w, err, services.ErrTableNotFound, "Table not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, table)
+}
+
+// UpdateTournamentBranding replaces the banner, sponsor, accent color and
+// description shown for a tournament (admin only)
+func (h *AdminHandler) UpdateTournamentBranding(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	req, ok := decodeBrandingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tournament, err := h.branding.SetTournamentBranding(r.Context(), tournamentID, req, adminUserID)
+	if err != nil {
+		writeBrandingError(w, err, services.ErrTournamentNotFound, "Tournament not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, tournament)
+}
+
+func decodeBrandingRequest(w http.ResponseWriter, r *http.Request) (models.UpdateBrandingRequest, bool) {
+	var req models.UpdateBrandingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return req, false
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+func writeBrandingError(w http.ResponseWriter, err, notFound error, notFoundMessage string) {
+	switch {
+	case errors.Is(err, notFound):
+		writeErrorResponse(w, http.StatusNotFound, notFoundMessage)
+	case errors.Is(err, services.ErrInvalidBranding):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update branding")
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListPartialCashOuts returns the audit trail of mid-session withdrawals,
+// optionally filtered by user_id (admin only)
+func (h *AdminHandler) ListPartialCashOuts(w http.ResponseWriter, r *http.Request) {
+	var userID *uuid.UUID
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &parsed
+	}
+
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	records, total, err := h.gameSessionService.ListPartialCashOuts(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch partial cash-outs")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"cash_outs": records,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// UpdateCashOutPolicy allows or forbids partial cash-outs at a table (admin only)
+func (h *AdminHandler) UpdateCashOutPolicy(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req models.UpdateCashOutPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.gameSessionService.SetPartialCashOutPolicy(r.Context(), tableID, req.AllowPartialCashOut); err != nil {
+		if errors.Is(err, services.ErrTableNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Table not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update cash-out policy")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":                "Cash-out policy updated",
+		"allow_partial_cash_out": req.AllowPartialCashOut,
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CloneTable opens a new table with the stakes and policies of an existing
+// one under a new name (admin only)
+func (h *AdminHandler) CloneTable(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req models.CloneTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.CallTime != nil && !req.CallTime.After(time.Now()) {
+		writeErrorResponse(w, http.StatusBadRequest, "Call time must be in the future")
+		return
+	}
+
+	table, err := h.cloneService.CloneTable(r.Context(), tableID, req, adminUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTableNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		case errors.Is(err, services.ErrCloneCallTime):
+			writeErrorResponse(w, http.StatusBadRequest, "Call time and minimum play time are only available at private tables")
+		case database.IsUniqueConstraintError(err):
+			writeErrorResponse(w, http.StatusConflict, "Table name already exists")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to clone table")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, table)
+}
+
+// CloneTournament schedules a new tournament with the buy-in, blind structure
+// and payouts of an existing one under a new name and start time (admin only)
+func (h *AdminHandler) CloneTournament(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var req models.CloneTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.StartTime != nil && req.StartTime.Before(time.Now()) {
+		writeErrorResponse(w, http.StatusBadRequest, "Start time must be in the future")
+		return
+	}
+
+	tournament, err := h.cloneService.CloneTournament(r.Context(), tournamentID, req, adminUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTournamentNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+		case errors.Is(err, services.ErrCloneStartTime):
+			writeErrorResponse(w, http.StatusBadRequest, "Start time is required for scheduled tournaments")
+		case database.IsUniqueConstraintError(err):
+			writeErrorResponse(w, http.StatusConflict, "Tournament name already exists")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to clone tournament")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, tournament)
+}
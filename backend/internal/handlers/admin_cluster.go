@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+)
+
+// ClusterRegistry reports on the game server instances sharing Redis and
+// moves table ownership leases between them. Implemented by the WebSocket hub.
+type ClusterRegistry interface {
+	ClusterStatus(ctx context.Context) (models.ClusterStatus, error)
+	ReassignTable(ctx context.Context, table, instanceID string) error
+}
+
+// SetClusterRegistry enables the cluster endpoints
+func (h *AdminHandler) SetClusterRegistry(cluster ClusterRegistry) {
+	h.cluster = cluster
+}
+
+// GetClusterStatus lists every game server instance with its tables, client
+// counts and health, and the table ownership leases (admin only)
+func (h *AdminHandler) GetClusterStatus(w http.ResponseWriter, r *http.Request) {
+	if h.cluster == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Cluster registry is not available")
+		return
+	}
+
+	status, err := h.cluster.ClusterStatus(r.Context())
+	if err != nil {
+		slog.Error("Failed to read cluster status", "error", err)
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Failed to read cluster status")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, status)
+}
+
+// ReassignTableLease hands a table's ownership lease to another instance
+// that is still reporting (admin only)
+func (h *AdminHandler) ReassignTableLease(w http.ResponseWriter, r *http.Request) {
+	if h.cluster == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Cluster registry is not available")
+		return
+	}
+
+	var req models.ReassignTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	req.Table = strings.TrimSpace(req.Table)
+	req.InstanceID = strings.TrimSpace(req.InstanceID)
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, err := h.cluster.ClusterStatus(r.Context())
+	if err != nil {
+		slog.Error("Failed to read cluster status", "error", err)
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Failed to read cluster status")
+		return
+	}
+
+	target, known := findClusterInstance(status, req.InstanceID)
+	if target == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Instance not found")
+		return
+	}
+	if target.Status == models.ClusterInstanceStale {
+		writeErrorResponse(w, http.StatusConflict, "Instance has stopped reporting")
+		return
+	}
+	if _, leased := status.Leases[req.Table]; !leased && !known[req.Table] {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	if err := h.cluster.ReassignTable(r.Context(), req.Table, req.InstanceID); err != nil {
+		slog.Error("Failed to reassign table lease", "table", req.Table, "instance", req.InstanceID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to reassign table")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"table":       req.Table,
+		"lease_owner": req.InstanceID,
+	})
+}
+
+// findClusterInstance returns the named instance, if any, and the names of
+// every table running anywhere in the cluster
+func findClusterInstance(status models.ClusterStatus, id string) (*models.ClusterInstance, map[string]bool) {
+	var found *models.ClusterInstance
+	tables := make(map[string]bool)
+	for i := range status.Instances {
+		if status.Instances[i].ID == id {
+			found = &status.Instances[i]
+		}
+		for _, t := range status.Instances[i].Tables {
+			tables[t.Name] = true
+		}
+	}
+	return found, tables
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// HandDisputes freezes a running table's hand in place so a moderator can
+// look into a dispute and rule on it. Implemented by the WebSocket hub.
+type HandDisputes interface {
+	FreezeHand(table, reason string) error
+	ResumeHand(table string) error
+	InspectHand(table string) (*models.DisputedHand, error)
+	AdjudicateHand(table, outcome string, positions []uint) (*models.HandAdjudication, error)
+}
+
+// SetHandDisputes enables the disputed hand endpoints
+func (h *AdminHandler) SetHandDisputes(handDisputes HandDisputes) {
+	h.handDisputes = handDisputes
+}
+
+func (h *AdminHandler) disputeRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/hand", h.InspectHand)
+	r.Post("/freeze", h.FreezeHand)
+	r.Post("/resume", h.ResumeHand)
+	r.Post("/adjudicate", h.AdjudicateHand)
+	r.Get("/adjudications", h.ListHandAdjudications)
+
+	return r
+}
+
+// InspectHand returns the full state of a table's hand, every player's hole
+// cards included (moderator and admin only)
+func (h *AdminHandler) InspectHand(w http.ResponseWriter, r *http.Request) {
+	if h.handDisputes == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Hand disputes are not available")
+		return
+	}
+
+	table := strings.TrimSpace(r.URL.Query().Get("table"))
+	if table == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "table is required")
+		return
+	}
+
+	hand, err := h.handDisputes.InspectHand(table)
+	if err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, hand)
+}
+
+// FreezeHand stops the hand in progress at a table: no player can act until
+// it is resumed or adjudicated (moderator and admin only)
+func (h *AdminHandler) FreezeHand(w http.ResponseWriter, r *http.Request) {
+	if h.handDisputes == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Hand disputes are not available")
+		return
+	}
+
+	var req models.FreezeHandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.handDisputes.FreezeHand(strings.TrimSpace(req.Table), req.Reason); err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	h.writeDisputedHand(w, req.Table)
+}
+
+// ResumeHand lets a frozen hand carry on without a ruling (moderator and
+// admin only)
+func (h *AdminHandler) ResumeHand(w http.ResponseWriter, r *http.Request) {
+	if h.handDisputes == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Hand disputes are not available")
+		return
+	}
+
+	var req models.ResumeHandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.handDisputes.ResumeHand(strings.TrimSpace(req.Table)); err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	h.writeDisputedHand(w, req.Table)
+}
+
+// AdjudicateHand settles a frozen hand: "award" gives the pot to the players
+// at the positions given, "void" returns every bet. The ruling goes to the
+// audit log and the hand's history (moderator and admin only).
+func (h *AdminHandler) AdjudicateHand(w http.ResponseWriter, r *http.Request) {
+	if h.handDisputes == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Hand disputes are not available")
+		return
+	}
+
+	moderatorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.AdjudicateHandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if (req.Outcome == models.HandRulingAward) != (len(req.Positions) > 0) {
+		writeErrorResponse(w, http.StatusBadRequest, "An award needs the positions of its winners, a void takes none")
+		return
+	}
+
+	adjudication, err := h.handDisputes.AdjudicateHand(strings.TrimSpace(req.Table), req.Outcome, req.Positions)
+	if err != nil {
+		writeDisputeError(w, err)
+		return
+	}
+
+	// The ruling has been applied at the table by now, so a failure to record
+	// it must not be reported as the ruling failing
+	adjudication.ModeratorID = moderatorID
+	adjudication.Reason = req.Reason
+	if err := h.handAdjudications.Record(r.Context(), adjudication); err != nil {
+		slog.Error("Failed to record hand adjudication", "hand_id", adjudication.HandID, "table", adjudication.TableName, "moderator_id", moderatorID, "error", err)
+	}
+
+	writeJSONResponse(w, http.StatusOK, adjudication)
+}
+
+// ListHandAdjudications returns the most recent rulings on disputed hands,
+// optionally at a single table (moderator and admin only)
+func (h *AdminHandler) ListHandAdjudications(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+
+	adjudications, err := h.handAdjudications.List(r.Context(), strings.TrimSpace(r.URL.Query().Get("table")), limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list adjudications")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"adjudications": adjudications,
+	})
+}
+
+func (h *AdminHandler) writeDisputedHand(w http.ResponseWriter, table string) {
+	hand, err := h.handDisputes.InspectHand(strings.TrimSpace(table))
+	if err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, hand)
+}
+
+func writeDisputeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoHandInProgress):
+		writeErrorResponse(w, http.StatusConflict, "No hand is in progress at this table")
+	case errors.Is(err, services.ErrHandNotFrozen):
+		writeErrorResponse(w, http.StatusConflict, "The hand is not frozen: freeze it before ruling on it")
+	case errors.Is(err, services.ErrInvalidRuling):
+		writeErrorResponse(w, http.StatusBadRequest, "Winners must be players dealt into the hand, named once each")
+	case errors.Is(err, services.ErrRulingNotAllowed):
+		writeErrorResponse(w, http.StatusConflict, "Hands at this table cannot be adjudicated; void it from the engine instead")
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/parity"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetTableEngineParity runs the parity suite at the table's game and stakes
+// without moving it, to see whether it could go onto the engine (admin only)
+func (h *AdminHandler) GetTableEngineParity(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	table, reports, err := h.engineMigration.CheckParity(r.Context(), tableID)
+	if err != nil && !errors.Is(err, services.ErrParityMismatch) {
+		writeEngineMigrationError(w, err, nil, "Failed to run the parity suite")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"table_id":       table.ID,
+		"game_type":      table.GameType,
+		"execution_path": table.ExecutionPath,
+		"agree":          err == nil,
+		"reports":        reports,
+	})
+}
+
+// UpdateTableExecutionPath moves a table to the legacy game or the internal
+// engine from the next time it opens. The move to the engine is refused,
+// with the differences, unless the parity suite passes (admin only).
+func (h *AdminHandler) UpdateTableExecutionPath(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req models.UpdateExecutionPathRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	table, reports, err := h.engineMigration.SetExecutionPath(r.Context(), tableID, req.ExecutionPath)
+	if err != nil {
+		writeEngineMigrationError(w, err, reports, "Failed to update execution path")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":        "Execution path updated, the table switches the next time it opens",
+		"table_id":       table.ID,
+		"execution_path": req.ExecutionPath,
+		"reports":        reports,
+	})
+}
+
+func writeEngineMigrationError(w http.ResponseWriter, err error, reports []parity.Report, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTableNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+	case errors.Is(err, services.ErrParityMismatch):
+		writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+			"error":   err.Error(),
+			"code":    "parity_mismatch",
+			"reports": reports,
+		})
+	case errors.Is(err, services.ErrEngineUnsupportedGame):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, fallback)
+	}
+}
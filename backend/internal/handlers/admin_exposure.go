@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetExposureService enables the maximum exposure endpoints
+func (h *AdminHandler) SetExposureService(exposure *services.ExposureService) {
+	h.exposure = exposure
+}
+
+// GetUserExposure returns how much a user has in play across their tables,
+// their maximum exposure and override history (admin only)
+func (h *AdminHandler) GetUserExposure(w http.ResponseWriter, r *http.Request) {
+	if h.exposure == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Exposure limits are not available")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	summary, err := h.exposure.Summary(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get exposure")
+		return
+	}
+
+	overrides, err := h.exposure.ListOverrides(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get exposure overrides")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"exposure":  summary,
+		"overrides": overrides,
+	})
+}
+
+// GrantExposureOverride replaces a user's maximum exposure, for a number of
+// hours or until revoked (admin only)
+func (h *AdminHandler) GrantExposureOverride(w http.ResponseWriter, r *http.Request) {
+	if h.exposure == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Exposure limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.GrantExposureOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	override, err := h.exposure.GrantOverride(r.Context(), userID, adminUserID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to grant exposure override")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, override)
+}
+
+// RevokeExposureOverride puts a user back on their usual maximum exposure
+// (admin only)
+func (h *AdminHandler) RevokeExposureOverride(w http.ResponseWriter, r *http.Request) {
+	if h.exposure == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Exposure limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	overrideID, err := uuid.Parse(chi.URLParam(r, "overrideID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid override ID")
+		return
+	}
+
+	if err := h.exposure.RevokeOverride(r.Context(), overrideID, adminUserID); err != nil {
+		if errors.Is(err, services.ErrExposureOverrideNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Exposure override not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke exposure override")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"message": "Exposure override revoked"})
+}
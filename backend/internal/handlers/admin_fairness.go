@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+)
+
+// ListFairnessReports returns every table's card distribution report for a
+// day, flagged tables first (admin only). Defaults to yesterday (UTC).
+func (h *AdminHandler) ListFairnessReports(w http.ResponseWriter, r *http.Request) {
+	day, ok := fairnessDay(w, r)
+	if !ok {
+		return
+	}
+
+	reports, err := h.fairnessService.ListTableReports(r.Context(), day)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch fairness reports")
+		return
+	}
+
+	flagged := 0
+	for _, report := range reports {
+		if report.Flagged {
+			flagged++
+		}
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"day":     day.Format("2006-01-02"),
+		"reports": reports,
+		"flagged": flagged,
+	})
+}
+
+// GenerateFairnessReports rebuilds the reports for a day, for days the
+// nightly job missed or the current day so far (admin only)
+func (h *AdminHandler) GenerateFairnessReports(w http.ResponseWriter, r *http.Request) {
+	day, ok := fairnessDay(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.fairnessService.GenerateDailyReports(r.Context(), day); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate fairness reports")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Fairness reports generated",
+		"day":     day.Format("2006-01-02"),
+	})
+}
+
+// fairnessDay reads the ?date=YYYY-MM-DD parameter, defaulting to yesterday
+func fairnessDay(w http.ResponseWriter, r *http.Request) (time.Time, bool) {
+	raw := r.URL.Query().Get("date")
+	if raw == "" {
+		return time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour), true
+	}
+
+	day, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid date, expected YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return day, true
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetFeatureFlags enables the feature flag endpoints
+func (h *AdminHandler) SetFeatureFlags(featureFlags *services.FeatureFlagService) {
+	h.featureFlags = featureFlags
+}
+
+// ListFeatureFlags returns every feature flag and whether it is on (admin only)
+func (h *AdminHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if h.featureFlags == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Feature flags are not available")
+		return
+	}
+
+	flags, err := h.featureFlags.List(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list feature flags")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"flags": flags,
+	})
+}
+
+// SetFeatureFlag switches a feature on or off (admin only)
+func (h *AdminHandler) SetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if h.featureFlags == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Feature flags are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.SetFeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	flag, err := h.featureFlags.Set(r.Context(), chi.URLParam(r, "key"), *req.Enabled, adminUserID, req.Reason)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownFeatureFlag) {
+			writeErrorResponse(w, http.StatusNotFound, "Unknown feature flag")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update feature flag")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, flag)
+}
+
+// featureEnabled refuses the request with 503 when an operator has switched
+// the feature off. Without a flag service every feature is on.
+func featureEnabled(w http.ResponseWriter, r *http.Request, featureFlags *services.FeatureFlagService, key, message string) bool {
+	if featureFlags == nil || featureFlags.Enabled(r.Context(), key) {
+		return true
+	}
+	writeErrorResponse(w, http.StatusServiceUnavailable, message)
+	return false
+}
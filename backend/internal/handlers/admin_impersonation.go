@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetImpersonationService enables read-only impersonation of users who
+// granted support access
+func (h *AdminHandler) SetImpersonationService(impersonation *services.ImpersonationService) {
+	h.impersonation = impersonation
+}
+
+// StartImpersonation issues a time-boxed, read-only token for viewing the
+// API as the user does. The user must have granted support access (admin only).
+func (h *AdminHandler) StartImpersonation(w http.ResponseWriter, r *http.Request) {
+	if h.impersonation == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Impersonation is not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.StartImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.impersonation.Start(r.Context(), adminUserID, userID, req.Reason, time.Duration(req.Minutes)*time.Minute)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, services.ErrNoSupportAccess):
+			writeErrorResponse(w, http.StatusForbidden, "User has not granted support access")
+		case errors.Is(err, services.ErrCannotImpersonate):
+			writeErrorResponse(w, http.StatusForbidden, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to start impersonation")
+		}
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSONResponse(w, http.StatusCreated, token)
+}
+
+// ListImpersonations returns recent impersonation sessions, optionally for
+// one user_id (admin only)
+func (h *AdminHandler) ListImpersonations(w http.ResponseWriter, r *http.Request) {
+	if h.impersonation == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Impersonation is not available")
+		return
+	}
+
+	var userID *uuid.UUID
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &parsed
+	}
+
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+
+	sessions, err := h.impersonation.ListSessions(r.Context(), userID, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list impersonation sessions")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// GetImpersonationActions returns every request made during a session,
+// including those refused (admin only)
+func (h *AdminHandler) GetImpersonationActions(w http.ResponseWriter, r *http.Request) {
+	if h.impersonation == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Impersonation is not available")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	actions, err := h.impersonation.Actions(r.Context(), sessionID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get impersonation actions")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"session_id": sessionID,
+		"actions":    actions,
+	})
+}
+
+// EndImpersonation closes a session before it expires (admin only)
+func (h *AdminHandler) EndImpersonation(w http.ResponseWriter, r *http.Request) {
+	if h.impersonation == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Impersonation is not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	session, err := h.impersonation.End(r.Context(), sessionID, adminUserID)
+	if err != nil {
+		if errors.Is(err, services.ErrImpersonationSessionNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Impersonation session not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to end impersonation session")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, session)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/ledgertool"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultLedgerPageSize = 25
+	maxLedgerPageSize     = 100
+)
+
+// ledgerRoutes proxies read-only Formance queries so finance staff can
+// investigate money movements without access to the Formance console
+func (h *AdminHandler) ledgerRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/transactions", h.ListLedgerTransactions)
+	r.Get("/transactions/{txID}", h.GetLedgerTransaction)
+	r.Get("/accounts", h.ListLedgerAccounts)
+	r.Get("/accounts/{address}", h.GetLedgerAccount)
+	r.Post("/reconcile", h.ReconcileLedger)
+
+	return r
+}
+
+// ListLedgerTransactions returns a page of ledger transactions filtered by
+// account, metadata type and date range (finance and admin only). Dates are
+// RFC 3339 timestamps or YYYY-MM-DD; a bare "to" date includes that whole day.
+func (h *AdminHandler) ListLedgerTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := formance.TransactionFilter{
+		Account:      query.Get("account"),
+		MetadataType: query.Get("type"),
+	}
+
+	var err error
+	if raw := query.Get("from"); raw != "" {
+		if filter.StartTime, _, err = parseLedgerDate(raw); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid from date")
+			return
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		var dateOnly bool
+		if filter.EndTime, dateOnly, err = parseLedgerDate(raw); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid to date")
+			return
+		}
+		if dateOnly {
+			filter.EndTime = filter.EndTime.AddDate(0, 0, 1)
+		}
+	}
+	if !filter.StartTime.IsZero() && !filter.EndTime.IsZero() && !filter.StartTime.Before(filter.EndTime) {
+		writeErrorResponse(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	pageSize := ledgerPageSize(r)
+	page, err := h.formanceService.QueryTransactions(r.Context(), filter, pageSize, query.Get("cursor"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to query ledger transactions")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"transactions": page.Transactions,
+		"pagination":   ledgerPagination(pageSize, page.Next, page.HasMore),
+	})
+}
+
+// GetLedgerTransaction returns a single ledger transaction (finance and admin only)
+func (h *AdminHandler) GetLedgerTransaction(w http.ResponseWriter, r *http.Request) {
+	txID, err := strconv.ParseInt(chi.URLParam(r, "txID"), 10, 64)
+	if err != nil || txID < 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+
+	tx, err := h.formanceService.GetTransaction(r.Context(), txID)
+	if err != nil {
+		if formance.IsNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to fetch ledger transaction")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, tx)
+}
+
+// ListLedgerAccounts returns a page of ledger accounts, optionally matching
+// an address pattern such as "player::wallet" (finance and admin only)
+func (h *AdminHandler) ListLedgerAccounts(w http.ResponseWriter, r *http.Request) {
+	pageSize := ledgerPageSize(r)
+	page, err := h.formanceService.QueryAccounts(r.Context(), r.URL.Query().Get("address"), pageSize, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to query ledger accounts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"accounts":   page.Accounts,
+		"pagination": ledgerPagination(pageSize, page.Next, page.HasMore),
+	})
+}
+
+// GetLedgerAccount returns a single ledger account with its volumes (finance and admin only)
+func (h *AdminHandler) GetLedgerAccount(w http.ResponseWriter, r *http.Request) {
+	address := chi.URLParam(r, "address")
+	if !formance.ValidateAccountFormat(address) {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid account address")
+		return
+	}
+
+	account, err := h.formanceService.GetAccount(r.Context(), address)
+	if err != nil {
+		if formance.IsNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Account not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to fetch ledger account")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, account)
+}
+
+// ReconcileLedger checks the ledger invariants against the database and
+// returns anything that doesn't add up. Nothing is changed (finance and
+// admin only).
+func (h *AdminHandler) ReconcileLedger(w http.ResponseWriter, r *http.Request) {
+	tool := ledgertool.New(h.formanceService.Client(), h.db.DB, false, io.Discard)
+	findings, err := tool.Verify(r.Context())
+	if err != nil {
+		slog.Error("Ledger reconciliation failed", "error", err)
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to reconcile ledger")
+		return
+	}
+	if findings == nil {
+		findings = []ledgertool.Finding{}
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"ok":       len(findings) == 0,
+		"findings": findings,
+	})
+}
+
+func ledgerPageSize(r *http.Request) int {
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= maxLedgerPageSize {
+		return parsed
+	}
+	return defaultLedgerPageSize
+}
+
+// ledgerPagination describes a Formance cursor page. Pass next back as the
+// cursor query parameter to fetch the following page with the same filters.
+func ledgerPagination(limit int, next string, hasMore bool) map[string]interface{} {
+	return map[string]interface{}{
+		"limit":    limit,
+		"next":     next,
+		"has_more": hasMore,
+	}
+}
+
+// parseLedgerDate accepts an RFC 3339 timestamp or a YYYY-MM-DD date in UTC,
+// reporting which form was used
+func parseLedgerDate(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	return t, false, err
+}
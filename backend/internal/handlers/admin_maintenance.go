@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+)
+
+// TableMaintenance switches running tables into read-only mode, or closes
+// them, during an incident. Implemented by the WebSocket hub.
+type TableMaintenance interface {
+	SetTableReadOnly(name string, enabled bool, reason string) error
+	SetAllTablesReadOnly(enabled bool, reason string)
+	ReadOnlyTables() (map[string]string, bool)
+	ForceCloseTable(name, reason string) error
+}
+
+// SetTableMaintenance enables the read-only mode endpoints
+func (h *AdminHandler) SetTableMaintenance(tableMaintenance TableMaintenance) {
+	h.tableMaintenance = tableMaintenance
+}
+
+type forceCloseTableRequest struct {
+	Table  string `json:"table" validate:"required,max=100"`
+	Reason string `json:"reason" validate:"max=200"`
+}
+
+type setReadOnlyRequest struct {
+	Table    string `json:"table" validate:"omitempty,max=100"`
+	All      bool   `json:"all"`
+	ReadOnly bool   `json:"read_only"`
+	Reason   string `json:"reason" validate:"max=200"`
+}
+
+// GetReadOnlyTables lists the tables in read-only mode (admin only)
+func (h *AdminHandler) GetReadOnlyTables(w http.ResponseWriter, r *http.Request) {
+	if h.tableMaintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table maintenance is not available")
+		return
+	}
+
+	tables, all := h.tableMaintenance.ReadOnlyTables()
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"all":    all,
+		"tables": tables,
+	})
+}
+
+// SetReadOnly puts one table, or every table with "all": true, into or out
+// of read-only mode. The hand in progress finishes, then no new hand starts,
+// seats and cash-outs are refused and chat stays open (admin only).
+func (h *AdminHandler) SetReadOnly(w http.ResponseWriter, r *http.Request) {
+	if h.tableMaintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table maintenance is not available")
+		return
+	}
+
+	var req setReadOnlyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Table = strings.TrimSpace(req.Table)
+	if req.All == (req.Table != "") {
+		writeErrorResponse(w, http.StatusBadRequest, "Specify either a table or all")
+		return
+	}
+
+	if req.All {
+		h.tableMaintenance.SetAllTablesReadOnly(req.ReadOnly, req.Reason)
+	} else if err := h.tableMaintenance.SetTableReadOnly(req.Table, req.ReadOnly, req.Reason); err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	tables, all := h.tableMaintenance.ReadOnlyTables()
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"all":    all,
+		"tables": tables,
+	})
+}
+
+// ForceCloseTable closes a running table even with players seated: their
+// chips go back to their wallets and the table stops dealing. A hand in
+// progress must finish first, so put the table in read-only mode and wait
+// for it (admin only).
+func (h *AdminHandler) ForceCloseTable(w http.ResponseWriter, r *http.Request) {
+	if h.tableMaintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table maintenance is not available")
+		return
+	}
+
+	var req forceCloseTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.tableMaintenance.ForceCloseTable(strings.TrimSpace(req.Table), req.Reason); err != nil {
+		if errors.Is(err, services.ErrHandInProgress) {
+			writeErrorResponse(w, http.StatusConflict, "A hand is in progress: put the table in read-only mode and retry once it ends")
+			return
+		}
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Table closed",
+		"table":   req.Table,
+	})
+}
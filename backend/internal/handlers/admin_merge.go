@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// MergeAccounts folds a duplicate registration into the account in the URL,
+// which the player keeps (admin only)
+func (h *AdminHandler) MergeAccounts(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	survivorID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.MergeAccountsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	merge, err := h.accountMerge.Merge(r.Context(), survivorID, req.DuplicateID, adminUserID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, services.ErrMergeSameAccount),
+			errors.Is(err, services.ErrAccountDisabled),
+			errors.Is(err, services.ErrMergeAdminAccount):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrMergeActivePlay):
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to merge accounts")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, merge)
+}
+
+// ListAccountMerges returns merge records, optionally only those involving
+// one user (admin only)
+func (h *AdminHandler) ListAccountMerges(w http.ResponseWriter, r *http.Request) {
+	var userID *uuid.UUID
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		userID = &parsed
+	}
+
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+
+	merges, err := h.accountMerge.ListMerges(r.Context(), userID, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list account merges")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"merges": merges,
+	})
+}
+
+// GetUserNotes returns the support notes on a user's account (admin only)
+func (h *AdminHandler) GetUserNotes(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	notes, err := h.accountMerge.Notes(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list notes")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"notes": notes,
+	})
+}
+
+// AddUserNote records a support note on a user's account (admin only)
+func (h *AdminHandler) AddUserNote(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.CreateUserNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	note, err := h.accountMerge.AddNote(r.Context(), userID, adminUserID, req.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to add note")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, note)
+}
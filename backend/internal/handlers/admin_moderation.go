@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListModeratedMessages returns chat messages blocked by automatic moderation,
+// pending ones by default (admin only)
+func (h *AdminHandler) ListModeratedMessages(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.ModerationPending
+	} else if status == "all" {
+		status = ""
+	}
+
+	limit := 20
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	messages, total, err := h.chatModeration.ListModeratedMessages(r.Context(), status, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch moderated messages")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"messages": messages,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// ReviewModeratedMessage upholds or overturns an automatic moderation
+// decision. Overturning lifts the mute it caused (admin only)
+func (h *AdminHandler) ReviewModeratedMessage(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	messageID, err := uuid.Parse(chi.URLParam(r, "messageID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	var req models.ReviewModeratedMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	message, err := h.chatModeration.ReviewModeratedMessage(r.Context(), messageID, adminUserID, req.Status)
+	if err != nil {
+		if errors.Is(err, services.ErrModeratedMessageNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Moderated message not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to review moderated message")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, message)
+}
+
+// ListChatHistory returns table chat messages newest first, whether or not
+// they were delivered, filtered by table, user_id, status and since
+// (moderator and admin only)
+func (h *AdminHandler) ListChatHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := services.ChatHistoryFilter{
+		Table:  query.Get("table"),
+		Status: query.Get("status"),
+	}
+	if userID := query.Get("user_id"); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		filter.UserID = parsed
+	}
+	if since := query.Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid since, expected RFC 3339")
+			return
+		}
+		filter.Since = parsed
+	}
+
+	limit := 50
+	if parsed, err := strconv.Atoi(query.Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(query.Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	messages, total, err := h.chatModeration.ListChatHistory(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch chat history")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"messages": messages,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// ListTableChatMutes returns the table chat mutes in force, at one table
+// if given (moderator and admin only)
+func (h *AdminHandler) ListTableChatMutes(w http.ResponseWriter, r *http.Request) {
+	mutes, err := h.chatModeration.ListTableMutes(r.Context(), r.URL.Query().Get("table"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch chat mutes")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"mutes": mutes})
+}
+
+// LiftTableChatMute lifts a player's mute at a table (moderator and admin
+// only)
+func (h *AdminHandler) LiftTableChatMute(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	muteID, err := uuid.Parse(chi.URLParam(r, "muteID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid mute ID")
+		return
+	}
+
+	mute, err := h.chatModeration.LiftTableMute(r.Context(), muteID, moderatorID)
+	if err != nil {
+		if errors.Is(err, services.ErrChatMuteNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Chat mute not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to lift chat mute")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, mute)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetTournamentPayouts returns a tournament's payout structure as it stands,
+// with the prize for each place (admin only)
+func (h *AdminHandler) GetTournamentPayouts(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	payouts, err := h.payoutService.Current(r.Context(), tournamentID)
+	if err != nil {
+		writePayoutError(w, err, "Failed to get tournament payouts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, payouts)
+}
+
+// PreviewTournamentPayouts generates a payout structure without saving it.
+// The entrants, paid_percent and steepness query parameters override the
+// tournament's field size and payout model (admin only).
+func (h *AdminHandler) PreviewTournamentPayouts(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	query := r.URL.Query()
+	entrants := 0
+	if raw := query.Get("entrants"); raw != "" {
+		entrants, err = strconv.Atoi(raw)
+		if err != nil || entrants < 1 {
+			writeErrorResponse(w, http.StatusBadRequest, "Entrants must be a positive number")
+			return
+		}
+	}
+
+	var model *models.PayoutModel
+	if query.Get("paid_percent") != "" || query.Get("steepness") != "" {
+		m := models.DefaultPayoutModel()
+		if raw := query.Get("paid_percent"); raw != "" {
+			if m.PaidPercent, err = strconv.ParseFloat(raw, 64); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid paid_percent")
+				return
+			}
+		}
+		if raw := query.Get("steepness"); raw != "" {
+			if m.Steepness, err = strconv.ParseFloat(raw, 64); err != nil {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid steepness")
+				return
+			}
+		}
+		if err := validation.Validate(&m); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		model = &m
+	}
+
+	preview, err := h.payoutService.Preview(r.Context(), tournamentID, model, entrants)
+	if err != nil {
+		writePayoutError(w, err, "Failed to preview tournament payouts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, preview)
+}
+
+// AdjustTournamentPayouts replaces a tournament's payout structure by hand,
+// up until the money bubble bursts (admin only)
+func (h *AdminHandler) AdjustTournamentPayouts(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var req models.AdjustPayoutsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := models.ValidatePayoutStructure(req.Places); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payouts, err := h.payoutService.Adjust(r.Context(), tournamentID, adminUserID, req.Places)
+	if err != nil {
+		writePayoutError(w, err, "Failed to adjust tournament payouts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, payouts)
+}
+
+func writePayoutError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTournamentNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+	case errors.Is(err, models.ErrNoPayoutEntrants), errors.Is(err, models.ErrInvalidPayoutModel):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPayoutsLocked), errors.Is(err, services.ErrTournamentFinished):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetRakeFree enables the rake-free window endpoints
+func (h *AdminHandler) SetRakeFree(rakeFree *services.RakeFreeService) {
+	h.rakeFree = rakeFree
+}
+
+// ListRakeFreeWindows returns the windows not yet over, or with
+// ?include_ended=true the ones from the last 30 days too (admin only)
+func (h *AdminHandler) ListRakeFreeWindows(w http.ResponseWriter, r *http.Request) {
+	if h.rakeFree == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Rake-free windows are not available")
+		return
+	}
+
+	since := time.Now()
+	if includeEnded, _ := strconv.ParseBool(r.URL.Query().Get("include_ended")); includeEnded {
+		since = since.AddDate(0, 0, -30)
+	}
+	windows, err := h.rakeFree.List(r.Context(), since)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list rake-free windows")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"windows": windows,
+	})
+}
+
+// CreateRakeFreeWindow schedules a window in which hands at a table, or at
+// every table of a stake template, are not raked. The tables are told in
+// chat (admin only).
+func (h *AdminHandler) CreateRakeFreeWindow(w http.ResponseWriter, r *http.Request) {
+	if h.rakeFree == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Rake-free windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.CreateRakeFreeWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	window, err := h.rakeFree.Create(r.Context(), req, adminUserID, time.Now())
+	if err != nil {
+		writeRakeFreeWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, window)
+}
+
+// UpdateRakeFreeWindow reschedules or renames a window that has not ended
+// (admin only)
+func (h *AdminHandler) UpdateRakeFreeWindow(w http.ResponseWriter, r *http.Request) {
+	if h.rakeFree == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Rake-free windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	windowID, err := uuid.Parse(chi.URLParam(r, "windowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid rake-free window ID")
+		return
+	}
+
+	var req models.UpdateRakeFreeWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	window, err := h.rakeFree.Update(r.Context(), windowID, req, adminUserID, time.Now())
+	if err != nil {
+		writeRakeFreeWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, window)
+}
+
+// DeleteRakeFreeWindow cancels a window, ending it if it has started
+// (admin only)
+func (h *AdminHandler) DeleteRakeFreeWindow(w http.ResponseWriter, r *http.Request) {
+	if h.rakeFree == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Rake-free windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	windowID, err := uuid.Parse(chi.URLParam(r, "windowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid rake-free window ID")
+		return
+	}
+
+	if err := h.rakeFree.Delete(r.Context(), windowID, adminUserID, time.Now()); err != nil {
+		writeRakeFreeWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Rake-free window cancelled",
+	})
+}
+
+func writeRakeFreeWindowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRakeFreeWindowNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Rake-free window not found")
+	case errors.Is(err, services.ErrTableNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+	case errors.Is(err, services.ErrTemplateNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Stake template not found")
+	case errors.Is(err, services.ErrRakeFreeWindowEnded):
+		writeErrorResponse(w, http.StatusConflict, "Rake-free window has already ended")
+	case errors.Is(err, services.ErrRakeFreeWindowInPast):
+		writeErrorResponse(w, http.StatusBadRequest, "Rake-free window must end in the future")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to save rake-free window")
+	}
+}
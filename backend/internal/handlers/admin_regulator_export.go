@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// SetRegulatorExports enables the regulator betting export
+func (h *AdminHandler) SetRegulatorExports(regulatorExports *services.RegulatorExportService) {
+	h.regulatorExports = regulatorExports
+}
+
+// ExportForRegulator returns a zip of CSV reports of one player's (user_id)
+// or one table's (table_id) wagers and results, rake paid, deposits and
+// withdrawals and table sessions between the from and to query parameters
+// (RFC 3339), with a manifest of the files' checksums signed with the
+// regulator export key (finance and admin only). Regulators verify it with
+// the public key from GetRegulatorExportPublicKey.
+func (h *AdminHandler) ExportForRegulator(w http.ResponseWriter, r *http.Request) {
+	if h.regulatorExports == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Regulator exports are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	query := r.URL.Query()
+	var scope services.RegulatorExportScope
+	var subject string
+	if raw := query.Get("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		scope.UserID = &userID
+		subject = "user-" + userID.String()
+	}
+	if raw := query.Get("table_id"); raw != "" {
+		tableID, err := uuid.Parse(raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+			return
+		}
+		scope.TableID = &tableID
+		subject = "table-" + tableID.String()
+	}
+	if (scope.UserID == nil) == (scope.TableID == nil) {
+		writeErrorResponse(w, http.StatusBadRequest, "Give either user_id or table_id")
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid from time, use RFC 3339")
+		return
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid to time, use RFC 3339")
+		return
+	}
+	if !from.Before(to) || to.Sub(from) > maxHandExportRange {
+		writeErrorResponse(w, http.StatusBadRequest, "From must be before to and at most a year earlier")
+		return
+	}
+	scope.From, scope.To = from, to
+
+	export, err := h.regulatorExports.Export(r.Context(), scope, adminUserID)
+	if err != nil {
+		slog.Error("Failed to build regulator export", "user_id", scope.UserID, "table_id", scope.TableID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to build regulator export")
+		return
+	}
+	var archive bytes.Buffer
+	if err := export.WriteZip(&archive); err != nil {
+		slog.Error("Failed to write regulator export", "user_id", scope.UserID, "table_id", scope.TableID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to build regulator export")
+		return
+	}
+
+	filename := fmt.Sprintf("regulator-%s-%s-%s.zip", subject, from.UTC().Format("20060102"), to.UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+}
+
+// GetRegulatorExportPublicKey returns the PEM encoded Ed25519 public key
+// that verifies regulator exports, for handing to regulators. The private
+// key never leaves the server.
+func (h *AdminHandler) GetRegulatorExportPublicKey(w http.ResponseWriter, r *http.Request) {
+	if h.regulatorExports == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Regulator exports are not available")
+		return
+	}
+
+	publicKey, err := h.regulatorExports.PublicKeyPEM()
+	if err != nil {
+		slog.Error("Failed to encode regulator export public key", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode public key")
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Header().Set("Content-Disposition", `attachment; filename="regulator-export-public-key.pem"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(publicKey)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListMessageReports returns reported direct messages, open ones by default (admin only)
+func (h *AdminHandler) ListMessageReports(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.MessageReportOpen
+	} else if status == "all" {
+		status = ""
+	}
+
+	limit := 20
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	reports, total, err := h.directMessageService.ListReports(r.Context(), status, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch message reports")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// ReviewMessageReport dismisses or actions a message report (admin only)
+func (h *AdminHandler) ReviewMessageReport(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid report ID")
+		return
+	}
+
+	var req models.ReviewMessageReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.directMessageService.ReviewReport(r.Context(), reportID, adminUserID, req.Status)
+	if err != nil {
+		if errors.Is(err, services.ErrReportNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Message report not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to review message report")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+}
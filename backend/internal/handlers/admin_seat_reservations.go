@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetSeatReservations enables the seat reservation endpoints
+func (h *AdminHandler) SetSeatReservations(seatHolds *services.SeatReservationService) {
+	h.seatHolds = seatHolds
+}
+
+// ListSeatReservations returns the seats held at a table (admin only)
+func (h *AdminHandler) ListSeatReservations(w http.ResponseWriter, r *http.Request) {
+	if h.seatHolds == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Seat reservations are not available")
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	reservations, err := h.seatHolds.List(r.Context(), tableID, time.Now())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list seat reservations")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"reservations": reservations,
+	})
+}
+
+// CreateSeatReservation holds a seat at a table for a named player until
+// they sit down or the reservation expires (admin only)
+func (h *AdminHandler) CreateSeatReservation(w http.ResponseWriter, r *http.Request) {
+	if h.seatHolds == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Seat reservations are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req models.CreateSeatReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reservation, err := h.seatHolds.Create(r.Context(), tableID, req, adminUserID, time.Now())
+	if err != nil {
+		writeSeatReservationError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, reservation)
+}
+
+// CancelSeatReservation gives a held seat back to everyone (admin only)
+func (h *AdminHandler) CancelSeatReservation(w http.ResponseWriter, r *http.Request) {
+	if h.seatHolds == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Seat reservations are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	reservationID, err := uuid.Parse(chi.URLParam(r, "reservationID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	if err := h.seatHolds.Cancel(r.Context(), reservationID, adminUserID, time.Now()); err != nil {
+		writeSeatReservationError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Seat reservation cancelled",
+	})
+}
+
+func writeSeatReservationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTableNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, services.ErrSeatReservationNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Seat reservation not found")
+	case errors.Is(err, services.ErrSeatOutOfRange):
+		writeErrorResponse(w, http.StatusBadRequest, "The table has no such seat")
+	case errors.Is(err, services.ErrSeatAlreadyReserved), errors.Is(err, services.ErrPlayerAlreadyReserved):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update seat reservation")
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListSeatingSeparations returns player pairs that must not share a table (admin only)
+func (h *AdminHandler) ListSeatingSeparations(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 200 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	separations, total, err := h.seatingService.ListPairs(r.Context(), limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch seating separations")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"separations": separations,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// FlagSeatingPair adds a pair of players to the must-move list (admin only)
+func (h *AdminHandler) FlagSeatingPair(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.FlagSeatingPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	separation, err := h.seatingService.FlagPair(r.Context(), req, &adminUserID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to flag seating pair")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, separation)
+}
+
+// RemoveSeatingSeparation lifts a seating separation (admin only)
+func (h *AdminHandler) RemoveSeatingSeparation(w http.ResponseWriter, r *http.Request) {
+	separationID, err := uuid.Parse(chi.URLParam(r, "separationID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid separation ID")
+		return
+	}
+
+	if err := h.seatingService.RemovePair(r.Context(), separationID); err != nil {
+		if errors.Is(err, services.ErrSeparationNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Seating separation not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to remove seating separation")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Seating separation removed",
+	})
+}
+
+// UpdateTableSeatingPolicy switches a table between seat choice and random
+// seating and toggles must-move enforcement (admin only)
+func (h *AdminHandler) UpdateTableSeatingPolicy(w http.ResponseWriter, r *http.Request) {
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req models.UpdateSeatingPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	table, err := h.seatingService.UpdateTablePolicy(r.Context(), tableID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTableNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Table not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update seating policy")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, table)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetSpins enables the spin format and jackpot endpoints
+func (h *AdminHandler) SetSpins(spins *services.SpinService) {
+	h.spins = spins
+}
+
+// ListSpinFormats returns every spin format, including those switched off
+// (admin only)
+func (h *AdminHandler) ListSpinFormats(w http.ResponseWriter, r *http.Request) {
+	if h.spins == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Spins are not available")
+		return
+	}
+
+	formats, err := h.spins.ListFormats(r.Context(), false)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch spin formats")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"formats":       formats,
+		"default_tiers": models.DefaultSpinTiers(),
+	})
+}
+
+// CreateSpinFormat adds a spin buy-in level with its prize distribution
+// (admin only)
+func (h *AdminHandler) CreateSpinFormat(w http.ResponseWriter, r *http.Request) {
+	if h.spins == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Spins are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.CreateSpinFormatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format, err := h.spins.CreateFormat(r.Context(), req, adminUserID)
+	if err != nil {
+		if database.IsUniqueConstraintError(err) {
+			writeErrorResponse(w, http.StatusConflict, "Spin format name already exists")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, format)
+}
+
+// UpdateSpinFormat switches a spin format on or off or replaces its prize
+// tiers (admin only)
+func (h *AdminHandler) UpdateSpinFormat(w http.ResponseWriter, r *http.Request) {
+	if h.spins == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Spins are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	formatID, err := uuid.Parse(chi.URLParam(r, "formatID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid spin format ID")
+		return
+	}
+
+	var req models.UpdateSpinFormatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format, err := h.spins.UpdateFormat(r.Context(), formatID, req, adminUserID)
+	if err != nil {
+		if errors.Is(err, services.ErrSpinFormatNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Spin format not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, format)
+}
+
+// GetSpinJackpot returns the spin jackpot balance against what spins have
+// taken in and paid out (admin only)
+func (h *AdminHandler) GetSpinJackpot(w http.ResponseWriter, r *http.Request) {
+	if h.spins == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Spins are not available")
+		return
+	}
+
+	report, err := h.spins.JackpotReport(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch spin jackpot")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+}
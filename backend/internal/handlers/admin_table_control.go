@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// TableControl lets moderators step in at a running table. Implemented by
+// the WebSocket hub.
+type TableControl interface {
+	ForceFold(tableID, userID uuid.UUID, reason string) error
+	PauseTable(tableID uuid.UUID, reason string) error
+	ResumeTable(tableID uuid.UUID) error
+	KickPlayer(tableID, userID uuid.UUID, reason string) (*models.RecoveredSession, error)
+	CancelHand(tableID uuid.UUID, reason string) ([]models.HandPayout, error)
+}
+
+// SetTableControl enables the forced table action endpoints
+func (h *AdminHandler) SetTableControl(tableControl TableControl) {
+	h.tableControl = tableControl
+}
+
+// ForceFold folds the hand of the player the table is waiting on (moderator
+// and admin only)
+func (h *AdminHandler) ForceFold(w http.ResponseWriter, r *http.Request) {
+	tableID, moderatorID, ok := h.tableControlParams(w, r)
+	if !ok {
+		return
+	}
+	var req models.TablePlayerControlRequest
+	if !decodeTableControl(w, r, &req) {
+		return
+	}
+
+	if err := h.tableControl.ForceFold(tableID, req.UserID, req.Reason); err != nil {
+		writeTableControlError(w, err, "Failed to fold the player's hand")
+		return
+	}
+
+	slog.Warn("Moderator force-folded a player", "table_id", tableID, "user_id", req.UserID, "moderator_id", moderatorID, "reason", req.Reason)
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "Player folded",
+		"table_id": tableID,
+		"user_id":  req.UserID,
+	})
+}
+
+// PauseTable freezes the hand in progress and stops new hands being dealt
+// until the table is resumed (moderator and admin only)
+func (h *AdminHandler) PauseTable(w http.ResponseWriter, r *http.Request) {
+	tableID, moderatorID, ok := h.tableControlParams(w, r)
+	if !ok {
+		return
+	}
+	var req models.TableControlRequest
+	if !decodeTableControl(w, r, &req) {
+		return
+	}
+
+	if err := h.tableControl.PauseTable(tableID, req.Reason); err != nil {
+		writeTableControlError(w, err, "Failed to pause the table")
+		return
+	}
+
+	slog.Warn("Moderator paused a table", "table_id", tableID, "moderator_id", moderatorID, "reason", req.Reason)
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "Table paused",
+		"table_id": tableID,
+	})
+}
+
+// ResumeTable lets play carry on at a paused table (moderator and admin
+// only)
+func (h *AdminHandler) ResumeTable(w http.ResponseWriter, r *http.Request) {
+	tableID, moderatorID, ok := h.tableControlParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.tableControl.ResumeTable(tableID); err != nil {
+		writeTableControlError(w, err, "Failed to resume the table")
+		return
+	}
+
+	slog.Warn("Moderator resumed a table", "table_id", tableID, "moderator_id", moderatorID)
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "Table resumed",
+		"table_id": tableID,
+	})
+}
+
+// KickPlayer removes a player from a table, cashing their stack out to
+// their wallet (moderator and admin only)
+func (h *AdminHandler) KickPlayer(w http.ResponseWriter, r *http.Request) {
+	tableID, moderatorID, ok := h.tableControlParams(w, r)
+	if !ok {
+		return
+	}
+	var req models.TablePlayerControlRequest
+	if !decodeTableControl(w, r, &req) {
+		return
+	}
+
+	cashOut, err := h.tableControl.KickPlayer(tableID, req.UserID, req.Reason)
+	if err != nil {
+		writeTableControlError(w, err, "Failed to remove the player")
+		return
+	}
+
+	slog.Warn("Moderator kicked a player", "table_id", tableID, "user_id", req.UserID, "moderator_id", moderatorID, "reason", req.Reason, "amount", cashOut.Amount)
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "Player removed from the table",
+		"table_id": tableID,
+		"user_id":  req.UserID,
+		"cash_out": cashOut,
+	})
+}
+
+// CancelHand voids the hand in progress, returning every bet to the player
+// who made it (moderator and admin only)
+func (h *AdminHandler) CancelHand(w http.ResponseWriter, r *http.Request) {
+	tableID, moderatorID, ok := h.tableControlParams(w, r)
+	if !ok {
+		return
+	}
+	var req models.TableControlRequest
+	if !decodeTableControl(w, r, &req) {
+		return
+	}
+
+	refunds, err := h.tableControl.CancelHand(tableID, req.Reason)
+	if err != nil {
+		writeTableControlError(w, err, "Failed to cancel the hand")
+		return
+	}
+
+	slog.Warn("Moderator cancelled a hand", "table_id", tableID, "moderator_id", moderatorID, "reason", req.Reason, "refunds", len(refunds))
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "Hand cancelled",
+		"table_id": tableID,
+		"refunds":  refunds,
+	})
+}
+
+// tableControlParams checks the table control endpoints are available and
+// returns the table ID and the moderator acting on it
+func (h *AdminHandler) tableControlParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	if h.tableControl == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table control is not available")
+		return uuid.Nil, uuid.Nil, false
+	}
+	moderatorID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return tableID, moderatorID, true
+}
+
+func decodeTableControl(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return false
+	}
+	if err := validation.Validate(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func writeTableControlError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrNoHandInProgress):
+		writeErrorResponse(w, http.StatusConflict, "No hand is in progress at this table")
+	case errors.Is(err, services.ErrNotPlayersTurn):
+		writeErrorResponse(w, http.StatusConflict, "The table is not waiting on this player")
+	case errors.Is(err, services.ErrHandFrozen):
+		writeErrorResponse(w, http.StatusConflict, "The hand is frozen: resume it first")
+	case errors.Is(err, services.ErrHandInProgress):
+		writeErrorResponse(w, http.StatusConflict, "The player is in a hand: fold them on their turn, or retry once it ends")
+	case errors.Is(err, services.ErrTableNotPaused):
+		writeErrorResponse(w, http.StatusConflict, "The table is not paused")
+	case errors.Is(err, services.ErrRulingNotAllowed):
+		writeErrorResponse(w, http.StatusConflict, "Hands at this table cannot be cancelled; void it from the engine instead")
+	case errors.Is(err, services.ErrPlayerNotAtTable):
+		writeErrorResponse(w, http.StatusNotFound, "Player is not seated at this table")
+	case errors.Is(err, services.ErrTableNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Table is not running")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListStakeTemplates returns all stake templates (admin only)
+func (h *AdminHandler) ListStakeTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.stakeTemplateService.ListTemplates(r.Context())
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch stake templates")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// CreateStakeTemplate creates a new stake template (admin only)
+func (h *AdminHandler) CreateStakeTemplate(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.CreateStakeTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := h.stakeTemplateService.CreateTemplate(r.Context(), req, adminUserID)
+	if err != nil {
+		if database.IsUniqueConstraintError(err) {
+			writeErrorResponse(w, http.StatusConflict, "Stake template name already exists")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, template)
+}
+
+// GetStakeTemplate returns a single stake template (admin only)
+func (h *AdminHandler) GetStakeTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.stakeTemplateService.GetTemplate(r.Context(), templateID)
+	if err != nil {
+		writeTemplateError(w, err, "Failed to fetch stake template")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, template)
+}
+
+// UpdateStakeTemplate updates a stake template (admin only)
+func (h *AdminHandler) UpdateStakeTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateStakeTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := h.stakeTemplateService.UpdateTemplate(r.Context(), templateID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTemplateNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Stake template not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, template)
+}
+
+// DeleteStakeTemplate deletes a stake template, leaving its open tables running (admin only)
+func (h *AdminHandler) DeleteStakeTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.stakeTemplateService.DeleteTemplate(r.Context(), templateID); err != nil {
+		writeTemplateError(w, err, "Failed to delete stake template")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":     "Stake template deleted successfully",
+		"template_id": templateID,
+	})
+}
+
+// OpenTablesFromTemplate opens N tables from a stake template in one go (admin only)
+func (h *AdminHandler) OpenTablesFromTemplate(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	templateID, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeNewTables) {
+		return
+	}
+
+	var req models.OpenTablesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tables, err := h.stakeTemplateService.OpenTables(r.Context(), templateID, req.Count, adminUserID)
+	if err != nil {
+		writeTemplateError(w, err, "Failed to open tables")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, map[string]interface{}{
+		"message": "Tables opened successfully",
+		"tables":  tables,
+	})
+}
+
+// CloseTables closes a batch of tables. Tables that still have players are skipped (admin only)
+func (h *AdminHandler) CloseTables(w http.ResponseWriter, r *http.Request) {
+	var req models.CloseTablesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	closed, skipped, err := h.stakeTemplateService.CloseTables(r.Context(), req.TableIDs)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to close tables")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Tables closed",
+		"closed":  closed,
+		"skipped": skipped,
+	})
+}
+
+func parseTemplateID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid template ID")
+		return uuid.Nil, false
+	}
+	return templateID, true
+}
+
+func writeTemplateError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, services.ErrTemplateNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, "Stake template not found")
+		return
+	}
+	writeErrorResponse(w, http.StatusInternalServerError, message)
+}
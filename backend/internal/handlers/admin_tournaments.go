@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetPushService lets tournament controls alert the registered players
+func (h *AdminHandler) SetPushService(pushService *services.PushService) {
+	h.pushService = pushService
+}
+
+// PauseTournament pauses a running tournament, e.g. for a scheduled break
+// (admin only)
+func (h *AdminHandler) PauseTournament(w http.ResponseWriter, r *http.Request) {
+	h.setTournamentRunState(w, r, "running", "paused", "Tournament paused successfully")
+}
+
+// ResumeTournament resumes a paused tournament and alerts registered players
+// (admin only)
+func (h *AdminHandler) ResumeTournament(w http.ResponseWriter, r *http.Request) {
+	tournament, ok := h.setTournamentRunState(w, r, "paused", "running", "Tournament resumed successfully")
+	if ok {
+		notifyTournamentPlayers(h.db, h.pushService, *tournament, "Tournament resuming", fmt.Sprintf("%s is resuming. Get back to your table!", tournament.Name), "resumed")
+	}
+}
+
+// setTournamentRunState moves a tournament between the running and paused states
+func (h *AdminHandler) setTournamentRunState(w http.ResponseWriter, r *http.Request, from, to, message string) (*models.Tournament, bool) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return nil, false
+	}
+
+	var tournament models.Tournament
+	if err := h.db.First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch tournament")
+		}
+		return nil, false
+	}
+
+	if tournament.Status != from {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Tournament is not in %s state", from))
+		return nil, false
+	}
+
+	if err := h.db.Model(&tournament).Update("status", to).Error; err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update tournament")
+		return nil, false
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":    message,
+		"tournament": tournament,
+	})
+	return &tournament, true
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// GetTreasury returns where the money in the ledger sits, per asset: what is
+// owed to players in wallets, sessions and escrows, the house's own funds
+// and the house's net exposure. Summaries are cached for a minute;
+// ?refresh=true builds a fresh one (finance and admin only).
+func (h *AdminHandler) GetTreasury(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.treasury.Summary(r.Context(), r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		slog.Error("Failed to build treasury summary", "error", err)
+		writeErrorResponse(w, http.StatusBadGateway, "Failed to build treasury summary")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SetVelocityService enables the velocity limit endpoints and applies the
+// limits to admin deposits and withdrawals
+func (h *AdminHandler) SetVelocityService(velocity *services.VelocityService) {
+	h.velocity = velocity
+}
+
+// GetUserVelocity returns a user's KYC tier, usage of their velocity limits
+// and override history (admin only)
+func (h *AdminHandler) GetUserVelocity(w http.ResponseWriter, r *http.Request) {
+	if h.velocity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Velocity limits are not available")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	summary, err := h.velocity.Summary(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get velocity limits")
+		return
+	}
+
+	overrides, err := h.velocity.ListOverrides(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get velocity overrides")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"limits":    summary,
+		"overrides": overrides,
+	})
+}
+
+// UpdateKYCTier sets the identity checks a user has passed, which decides
+// their daily deposit and withdrawal caps (admin only)
+func (h *AdminHandler) UpdateKYCTier(w http.ResponseWriter, r *http.Request) {
+	if h.velocity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Velocity limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateKYCTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.velocity.SetKYCTier(r.Context(), userID, adminUserID, req.KYCTier); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update KYC tier")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":  "KYC tier updated",
+		"user_id":  userID,
+		"kyc_tier": req.KYCTier,
+	})
+}
+
+// GrantVelocityOverride lifts a user's deposit or withdrawal limits for a
+// number of hours, e.g. to clear a large verified withdrawal (admin only)
+func (h *AdminHandler) GrantVelocityOverride(w http.ResponseWriter, r *http.Request) {
+	if h.velocity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Velocity limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.GrantVelocityOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	override, err := h.velocity.GrantOverride(r.Context(), userID, adminUserID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to grant velocity override")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, override)
+}
+
+// RevokeVelocityOverride ends an override before it expires (admin only)
+func (h *AdminHandler) RevokeVelocityOverride(w http.ResponseWriter, r *http.Request) {
+	if h.velocity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Velocity limits are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	overrideID, err := uuid.Parse(chi.URLParam(r, "overrideID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid override ID")
+		return
+	}
+
+	if err := h.velocity.RevokeOverride(r.Context(), overrideID, adminUserID); err != nil {
+		if errors.Is(err, services.ErrVelocityOverrideNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Velocity override not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke velocity override")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"message": "Velocity override revoked"})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// SendQueueMonitor reports on the outbound queues of WebSocket clients.
+// Implemented by the WebSocket hub.
+type SendQueueMonitor interface {
+	SendQueueStats() models.SendQueueStats
+}
+
+// SetSendQueueMonitor enables the WebSocket send queue metrics endpoint
+func (h *AdminHandler) SetSendQueueMonitor(sendQueueMonitor SendQueueMonitor) {
+	h.sendQueueMonitor = sendQueueMonitor
+}
+
+// GetSendQueueStats returns queue depth and shedding counters for WebSocket
+// clients, to spot players on slow connections falling behind (admin only)
+func (h *AdminHandler) GetSendQueueStats(w http.ResponseWriter, r *http.Request) {
+	if h.sendQueueMonitor == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "WebSocket metrics are not available")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, h.sendQueueMonitor.SendQueueStats())
+}
+
+// TableRecorder dumps what an open table has recorded for debugging.
+// Implemented by the WebSocket hub.
+type TableRecorder interface {
+	TableRecording(table string) (*flightrecorder.Recording, bool)
+}
+
+// SetTableRecorder enables the table flight recorder endpoint
+func (h *AdminHandler) SetTableRecorder(tableRecorder TableRecorder) {
+	h.tableRecorder = tableRecorder
+}
+
+// GetTableRecording dumps a table's recent commands, broadcasts and hand
+// states, decks included, for replaying with the table-replay tool (admin
+// only)
+func (h *AdminHandler) GetTableRecording(w http.ResponseWriter, r *http.Request) {
+	if h.tableRecorder == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table recordings are not available")
+		return
+	}
+
+	table := strings.TrimSpace(r.URL.Query().Get("table"))
+	if table == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "table is required")
+		return
+	}
+
+	recording, ok := h.tableRecorder.TableRecording(table)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, recording)
+}
+
+// LiveTableMonitor lists open tables with their lifecycle state.
+// Implemented by the WebSocket hub.
+type LiveTableMonitor interface {
+	LiveTables() []models.LiveTable
+}
+
+// SetLiveTableMonitor enables the live table view
+func (h *AdminHandler) SetLiveTableMonitor(liveTables LiveTableMonitor) {
+	h.liveTables = liveTables
+}
+
+// GetLiveTables lists the tables open on this server with their lifecycle
+// state and recent transitions, showing why a table isn't dealing (admin
+// only)
+func (h *AdminHandler) GetLiveTables(w http.ResponseWriter, r *http.Request) {
+	if h.liveTables == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Live tables are not available")
+		return
+	}
+
+	tables := h.liveTables.LiveTables()
+	if state := r.URL.Query().Get("state"); state != "" {
+		filtered := make([]models.LiveTable, 0, len(tables))
+		for _, table := range tables {
+			if table.State == state {
+				filtered = append(filtered, table)
+			}
+		}
+		tables = filtered
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"tables": tables,
+	})
+}
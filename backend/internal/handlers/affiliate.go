@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AffiliateHandler struct {
+	affiliateService *services.AffiliateService
+}
+
+func NewAffiliateHandler(affiliateService *services.AffiliateService) *AffiliateHandler {
+	return &AffiliateHandler{
+		affiliateService: affiliateService,
+	}
+}
+
+func (h *AffiliateHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetDashboard)
+	r.Get("/referrals", h.ListReferrals)
+	r.Get("/payouts", h.ListPayouts)
+
+	return r
+}
+
+// GetDashboard returns the user's referral code and a summary of their
+// referred players and revenue share. The code is created on first use.
+func (h *AffiliateHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	dashboard, err := h.affiliateService.GetDashboard(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get affiliate dashboard")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, dashboard)
+}
+
+// ListReferrals returns the players who signed up with the user's code, newest first
+func (h *AffiliateHandler) ListReferrals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit, offset := affiliatePage(r)
+	players, total, err := h.affiliateService.ListReferredPlayers(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch referrals")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"referrals": players,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// ListPayouts returns the user's revenue share payouts, newest first
+func (h *AffiliateHandler) ListPayouts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit, offset := affiliatePage(r)
+	payouts, total, err := h.affiliateService.ListPayouts(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch affiliate payouts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"payouts": payouts,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+func affiliatePage(r *http.Request) (int, int) {
+	limit := 20
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+	return limit, offset
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/auth"
 	"github.com/anhbaysgalan1/gp/internal/models"
@@ -12,7 +15,15 @@ import (
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
+	authService     *services.AuthService
+	sessionRecovery *services.SessionRecoveryService
+	affiliates      *services.AffiliateService
+	usernames       *services.UsernameService
+	featureFlags    *services.FeatureFlagService
+	identity        *services.IdentityService
+	passwordResets  *services.PasswordResetService
+	// Frontend page Apple's sign-in responses are forwarded to
+	oauthCallbackURL string
 }
 
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
@@ -21,6 +32,26 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	}
 }
 
+// SetSessionRecovery cashes out orphaned game sessions whenever a user logs in
+func (h *AuthHandler) SetSessionRecovery(sessionRecovery *services.SessionRecoveryService) {
+	h.sessionRecovery = sessionRecovery
+}
+
+// SetAffiliates attributes sign-ups made with a referral code to the affiliate
+func (h *AuthHandler) SetAffiliates(affiliates *services.AffiliateService) {
+	h.affiliates = affiliates
+}
+
+// SetUsernames enables username changes
+func (h *AuthHandler) SetUsernames(usernames *services.UsernameService) {
+	h.usernames = usernames
+}
+
+// SetFeatureFlags lets operators switch sign-ups off
+func (h *AuthHandler) SetFeatureFlags(featureFlags *services.FeatureFlagService) {
+	h.featureFlags = featureFlags
+}
+
 func (h *AuthHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 
@@ -28,6 +59,15 @@ func (h *AuthHandler) Routes() chi.Router {
 	r.Post("/register", h.Register)
 	r.Post("/login", h.Login)
 	r.Post("/verify-email", h.VerifyEmail)
+	r.Post("/forgot-password", h.ForgotPassword)
+	r.Post("/reset-password", h.ResetPassword)
+
+	// Sign-in with identity providers
+	r.Get("/oauth/providers", h.OAuthProviders)
+	r.Post("/oauth/signup", h.CompleteOAuthSignup)
+	r.Post("/oauth/apple/callback", h.AppleCallback)
+	r.Get("/oauth/{provider}", h.StartOAuth)
+	r.Post("/oauth/{provider}", h.OAuthSignIn)
 
 	return r
 }
@@ -38,11 +78,18 @@ func (h *AuthHandler) ProtectedRoutes() chi.Router {
 	// Protected routes (auth required)
 	r.Get("/me", h.GetCurrentUser)
 	r.Put("/profile", h.UpdateProfile)
+	r.Put("/username", h.ChangeUsername)
+	r.Get("/username-history", h.GetUsernameHistory)
+	r.Post("/session/refresh", h.RefreshSession)
 
 	return r
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if !featureEnabled(w, r, h.featureFlags, models.FeatureRegistrations, "Registrations are temporarily closed") {
+		return
+	}
+
 	var req models.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
@@ -55,12 +102,31 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check the code up front so a typo doesn't create an unattributed account
+	if req.ReferralCode != "" && h.affiliates != nil {
+		if _, err := h.affiliates.LookupCode(r.Context(), req.ReferralCode); err != nil {
+			if errors.Is(err, services.ErrInvalidReferralCode) {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid referral code")
+				return
+			}
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to check referral code")
+			return
+		}
+	}
+
 	user, err := h.authService.RegisterUser(req)
 	if err != nil {
 		writeErrorResponse(w, http.StatusConflict, err.Error())
 		return
 	}
 
+	// The account exists by now, so attribution problems are only logged
+	if req.ReferralCode != "" && h.affiliates != nil {
+		if err := h.affiliates.AttributeSignup(r.Context(), req.ReferralCode, user.ID); err != nil {
+			slog.Error("Failed to attribute referral", "user_id", user.ID, "code", req.ReferralCode, "error", err)
+		}
+	}
+
 	// Create email verification token
 	verification, err := h.authService.CreateEmailVerification(user.ID)
 	if err != nil {
@@ -92,13 +158,32 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	loginResponse, err := h.authService.LoginUser(req)
 	if err != nil {
+		if errors.Is(err, services.ErrAccountDisabled) {
+			writeErrorResponse(w, http.StatusForbidden, "This account has been disabled")
+			return
+		}
 		writeErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
+	h.recoverSessions(r, loginResponse)
 	writeJSONResponse(w, http.StatusOK, loginResponse)
 }
 
+// recoverSessions cashes out the user's orphaned game sessions as they log
+// in. Recovery problems are logged and retried on the next login rather
+// than blocking sign-in.
+func (h *AuthHandler) recoverSessions(r *http.Request, loginResponse *models.LoginResponse) {
+	if h.sessionRecovery == nil {
+		return
+	}
+	recovered, err := h.sessionRecovery.RecoverOrphanedSessions(r.Context(), loginResponse.User.ID)
+	if err != nil {
+		slog.Error("Failed to recover orphaned sessions", "user_id", loginResponse.User.ID, "error", err)
+	}
+	loginResponse.RecoveredSessions = recovered
+}
+
 func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Token string `json:"token"`
@@ -139,6 +224,32 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, user)
 }
 
+// RefreshSession renews the token for activity the API doesn't see, such as
+// play over the WebSocket. Once the session reaches its maximum lifetime
+// the user has to sign in again.
+func (h *AuthHandler) RefreshSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaimsFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	session, err := h.authService.RefreshSession(claims)
+	if err != nil {
+		if errors.Is(err, auth.ErrSessionExpired) {
+			writeErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to refresh session")
+		return
+	}
+
+	// The middleware may have renewed the token too; this one supersedes it
+	w.Header().Set(auth.RefreshedTokenHeader, session.Token)
+	w.Header().Set(auth.SessionExpiresHeader, session.ExpiresAt.UTC().Format(time.RFC3339))
+	writeJSONResponse(w, http.StatusOK, session)
+}
+
 func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserIDFromContext(r.Context())
 	if !ok {
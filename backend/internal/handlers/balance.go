@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/auth"
 	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,23 +22,67 @@ import (
 type BalanceHandler struct {
 	formanceService *formance.Service
 	db              *gorm.DB
+	pushService     *services.PushService
+	velocity        *services.VelocityService
+	exposure        *services.ExposureService
+	withdrawalFees  *services.WithdrawalFeeService
+	featureFlags    *services.FeatureFlagService
+	maintenance     *services.MaintenanceService
+	bankDeposits    *services.BankDepositService
 }
 
-func NewBalanceHandler(formanceService *formance.Service, db *gorm.DB) *BalanceHandler {
+func NewBalanceHandler(formanceService *formance.Service, db *gorm.DB, pushService *services.PushService) *BalanceHandler {
 	return &BalanceHandler{
 		formanceService: formanceService,
 		db:              db,
+		pushService:     pushService,
 	}
 }
 
+// SetVelocityService enforces deposit and withdrawal velocity limits
+func (h *BalanceHandler) SetVelocityService(velocity *services.VelocityService) {
+	h.velocity = velocity
+}
+
+// SetExposureService caps the MNT a user may have in game sessions at once
+func (h *BalanceHandler) SetExposureService(exposure *services.ExposureService) {
+	h.exposure = exposure
+}
+
+// SetWithdrawalFees applies the withdrawal minimum and fee schedule. Without
+// it withdrawals use services.DefaultWithdrawalFeeSchedule.
+func (h *BalanceHandler) SetWithdrawalFees(withdrawalFees *services.WithdrawalFeeService) {
+	h.withdrawalFees = withdrawalFees
+}
+
+// SetFeatureFlags lets operators switch withdrawals off
+func (h *BalanceHandler) SetFeatureFlags(featureFlags *services.FeatureFlagService) {
+	h.featureFlags = featureFlags
+}
+
+// SetMaintenance refuses withdrawals and buy-ins during maintenance windows
+// covering them
+func (h *BalanceHandler) SetMaintenance(maintenance *services.MaintenanceService) {
+	h.maintenance = maintenance
+}
+
+// SetBankDeposits gives users the reference to quote on bank transfers
+func (h *BalanceHandler) SetBankDeposits(bankDeposits *services.BankDepositService) {
+	h.bankDeposits = bankDeposits
+}
+
 func (h *BalanceHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 
 	// All balance routes require authentication
 	r.Get("/", h.GetBalance)
+	r.Get("/limits", h.GetVelocityLimits)
+	r.Get("/exposure", h.GetExposure)
+	r.Get("/deposit-reference", h.GetDepositReference)
 	r.Post("/transfer-to-game", h.TransferToGame)
 	r.Post("/transfer-from-game", h.TransferFromGame)
 	r.Post("/withdraw", h.WithdrawMoney)
+	r.Get("/withdrawal-fees", h.GetWithdrawalFees)
 	r.Get("/transactions", h.GetTransactionHistory)
 	r.Get("/table-history", h.GetTableTransactionHistory)
 
@@ -56,6 +106,30 @@ func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, balance)
 }
 
+// GetDepositReference returns the reference the user quotes in the payment
+// details of a bank transfer so it is credited to their wallet. It is
+// created on first use and never changes.
+func (h *BalanceHandler) GetDepositReference(w http.ResponseWriter, r *http.Request) {
+	if h.bankDeposits == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Bank deposits are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	reference, err := h.bankDeposits.GetOrCreateReference(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get deposit reference")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, reference)
+}
+
 // TransferToGameRequest represents the request to transfer money to game account
 type TransferToGameRequest struct {
 	Amount    int64     `json:"amount" validate:"required,gt=0"`
@@ -70,6 +144,10 @@ func (h *BalanceHandler) TransferToGame(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeAllPlay) {
+		return
+	}
+
 	var req TransferToGameRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
@@ -99,6 +177,10 @@ func (h *BalanceHandler) TransferToGame(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if h.exposure != nil && !checkExposureLimit(w, r, h.exposure, userID, req.Amount) {
+		return
+	}
+
 	transactionID, err := h.formanceService.TransferToGame(r.Context(), userID, req.Amount, req.SessionID)
 	if err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, err.Error())
@@ -238,6 +320,9 @@ func (h *BalanceHandler) GetTransactionHistory(w http.ResponseWriter, r *http.Re
 			description = "Deposit to wallet"
 		case "tournament_buyin":
 			description = "Tournament entry fee"
+			if _, ok := tx.Metadata["from_session_id"]; ok {
+				description = "Tournament entry from table winnings"
+			}
 		case "tournament_prize":
 			description = "Tournament prize"
 		case "rake_collection":
@@ -284,6 +369,13 @@ func (h *BalanceHandler) WithdrawMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !featureEnabled(w, r, h.featureFlags, models.FeatureWithdrawals, "Withdrawals are temporarily unavailable") {
+		return
+	}
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeWithdrawals) {
+		return
+	}
+
 	var req UserWithdrawRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
@@ -296,9 +388,9 @@ func (h *BalanceHandler) WithdrawMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set minimum withdrawal amount (e.g., 1000 MNT = ~$0.40)
-	if req.Amount < 1000 {
-		writeErrorResponse(w, http.StatusBadRequest, "Minimum withdrawal amount is 1,000 MNT")
+	// Minimum and fees come from the schedule for the user's KYC tier
+	quote, ok := h.quoteWithdrawal(w, r, userID, req.Amount)
+	if !ok {
 		return
 	}
 
@@ -308,23 +400,255 @@ func (h *BalanceHandler) WithdrawMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reservation, ok := reserveVelocityLimit(w, r, h.velocity, userID, models.WalletOperationWithdrawal, req.Amount)
+	if !ok {
+		return
+	}
+
 	// Process withdrawal through Formance
-	transactionID, err := h.formanceService.WithdrawMoney(r.Context(), userID, req.Amount)
+	transactionID, err := h.formanceService.WithdrawMoneyWithFee(r.Context(), userID, req.Amount, quote.Fee)
 	if err != nil {
+		releaseVelocityOperation(r, h.velocity, reservation)
+		h.pushService.NotifyAsync(userID, models.PushEventWithdrawalStatus, services.PushNotification{
+			Title: "Withdrawal failed",
+			Body:  fmt.Sprintf("Your withdrawal of %d MNT could not be processed.", req.Amount),
+			Data:  map[string]string{"status": "failed"},
+		})
 		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Withdrawal failed: %v", err))
 		return
 	}
 
+	completeVelocityOperation(r, h.velocity, reservation, transactionID)
+
+	h.pushService.NotifyAsync(userID, models.PushEventWithdrawalStatus, services.PushNotification{
+		Title: "Withdrawal completed",
+		Body:  fmt.Sprintf("Your withdrawal of %d MNT has been processed.", quote.NetAmount),
+		Data:  map[string]string{"status": "completed", "transaction_id": transactionID},
+	})
+
 	response := map[string]interface{}{
 		"message":        "Withdrawal successful",
 		"transaction_id": transactionID,
 		"amount":         req.Amount,
+		"fee":            quote.Fee,
+		"net_amount":     quote.NetAmount,
+		"fees":           quote,
 		"status":         "completed",
 	}
 
 	writeJSONResponse(w, http.StatusOK, response)
 }
 
+// GetWithdrawalFees returns the minimum and fees for the user's withdrawals
+// and, given an amount, the itemized quote for withdrawing it
+func (h *BalanceHandler) GetWithdrawalFees(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var terms *models.WithdrawalFeeTerms
+	if h.withdrawalFees != nil {
+		var err error
+		terms, err = h.withdrawalFees.Terms(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				writeErrorResponse(w, http.StatusNotFound, "User not found")
+				return
+			}
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to get withdrawal fees")
+			return
+		}
+	} else {
+		defaults := services.DefaultWithdrawalFeeSchedule().Terms("", models.KYCTierNone)
+		terms = &defaults
+	}
+	response := map[string]interface{}{"terms": terms}
+
+	if amountStr := r.URL.Query().Get("amount"); amountStr != "" {
+		amount, err := strconv.ParseInt(amountStr, 10, 64)
+		if err != nil || amount <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "Amount must be positive")
+			return
+		}
+		quote, ok := h.quoteWithdrawal(w, r, userID, amount)
+		if !ok {
+			return
+		}
+		response["quote"] = quote
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// quoteWithdrawal itemizes a withdrawal, writing the refusal and returning
+// false when it is below the user's minimum
+func (h *BalanceHandler) quoteWithdrawal(w http.ResponseWriter, r *http.Request, userID uuid.UUID, amount int64) (*models.WithdrawalQuote, bool) {
+	var quote *models.WithdrawalQuote
+	var err error
+	if h.withdrawalFees != nil {
+		quote, err = h.withdrawalFees.Quote(r.Context(), userID, amount)
+	} else {
+		quote, err = services.DefaultWithdrawalFeeSchedule().Quote("", models.KYCTierNone, amount)
+	}
+	if err == nil {
+		return quote, true
+	}
+
+	var minimumErr *services.WithdrawalMinimumError
+	switch {
+	case errors.As(err, &minimumErr):
+		writeJSONResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   minimumErr.Error(),
+			"minimum": minimumErr.Minimum,
+		})
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to calculate withdrawal fees")
+	}
+	return nil, false
+}
+
+// GetVelocityLimits returns the user's deposit and withdrawal limits, how
+// much of them they have used and when they may next deposit or withdraw
+func (h *BalanceHandler) GetVelocityLimits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if h.velocity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Velocity limits are not available")
+		return
+	}
+
+	summary, err := h.velocity.Summary(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get velocity limits")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
+
+// GetExposure returns how much the user has in play across their tables and
+// the most they may have
+func (h *BalanceHandler) GetExposure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if h.exposure == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Exposure limits are not available")
+		return
+	}
+
+	summary, err := h.exposure.Summary(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get exposure")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
+
+// reserveVelocityLimit counts the operation against the user's velocity
+// limits before its money moves. It writes the refusal and returns false
+// when the operation would break one of them. Without a velocity service
+// every operation is allowed and the reservation is nil.
+func reserveVelocityLimit(w http.ResponseWriter, r *http.Request, velocity *services.VelocityService, userID uuid.UUID, operation string, amount int64) (*models.WalletOperation, bool) {
+	if velocity == nil {
+		return nil, true
+	}
+	reservation, err := velocity.Reserve(r.Context(), userID, operation, amount)
+	if err == nil {
+		return reservation, true
+	}
+
+	var limitErr *services.VelocityLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		writeVelocityLimitError(w, limitErr)
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check velocity limits")
+	}
+	return nil, false
+}
+
+// completeVelocityOperation records the transaction of a reserved operation.
+// The money has already moved, so a failure is logged rather than returned
+// to the client.
+func completeVelocityOperation(r *http.Request, velocity *services.VelocityService, reservation *models.WalletOperation, transactionID string) {
+	if reservation == nil {
+		return
+	}
+	if err := velocity.Complete(r.Context(), reservation, transactionID); err != nil {
+		slog.Error("Failed to record wallet operation", "user_id", reservation.UserID, "operation", reservation.Operation, "transaction_id", transactionID, "error", err)
+	}
+}
+
+// releaseVelocityOperation gives back the slot of a reserved operation whose
+// money didn't move
+func releaseVelocityOperation(r *http.Request, velocity *services.VelocityService, reservation *models.WalletOperation) {
+	if reservation == nil {
+		return
+	}
+	if err := velocity.Release(r.Context(), reservation); err != nil {
+		slog.Error("Failed to release wallet operation", "user_id", reservation.UserID, "operation", reservation.Operation, "error", err)
+	}
+}
+
+// writeVelocityLimitError refuses with 429, naming the rule and when the
+// operation will next be allowed so clients can show a countdown
+func writeVelocityLimitError(w http.ResponseWriter, limitErr *services.VelocityLimitError) {
+	response := map[string]interface{}{
+		"error": limitErr.Message,
+		"rule":  limitErr.Rule,
+	}
+	if !limitErr.NextAllowedAt.IsZero() {
+		response["next_allowed_at"] = limitErr.NextAllowedAt.UTC()
+		retryAfter := int(math.Ceil(time.Until(limitErr.NextAllowedAt).Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	writeJSONResponse(w, http.StatusTooManyRequests, response)
+}
+
+// checkExposureLimit writes the refusal and returns false when buying in for
+// amount would take the user over their maximum exposure
+func checkExposureLimit(w http.ResponseWriter, r *http.Request, exposure *services.ExposureService, userID uuid.UUID, amount int64) bool {
+	err := exposure.Check(r.Context(), userID, amount)
+	if err == nil {
+		return true
+	}
+
+	var limitErr *services.ExposureLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+			"error":        limitErr.Error(),
+			"code":         "exposure_limit",
+			"exposure":     limitErr.Exposure,
+			"max_exposure": limitErr.MaxExposure,
+			"available":    limitErr.Available(),
+		})
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check exposure")
+	}
+	return false
+}
+
 // GetTableTransactionHistory returns game-related transaction history for the user
 func (h *BalanceHandler) GetTableTransactionHistory(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserIDFromContext(r.Context())
@@ -400,6 +724,11 @@ func (h *BalanceHandler) GetTableTransactionHistory(w http.ResponseWriter, r *ht
 				if srcIsUserSession && dstIsUserWallet {
 					netAmount = posting.Amount // Positive because money came from game
 				}
+			} else if transactionType == "session_transfer" {
+				// Table change: winnings carried straight into the new session
+				if srcIsUserSession && dstIsUserSession {
+					netAmount = posting.Amount
+				}
 			}
 		}
 
@@ -409,6 +738,8 @@ func (h *BalanceHandler) GetTableTransactionHistory(w http.ResponseWriter, r *ht
 			description = "Buy-in to table"
 		case "game_cashout":
 			description = "Cash out from table"
+		case "session_transfer":
+			description = "Moved to another table"
 		default:
 			description = "Table transaction"
 		}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// BankrollHandler lets users see and tighten the limits on rebuying after
+// losing a full stack
+type BankrollHandler struct {
+	bankroll *services.BankrollService
+}
+
+func NewBankrollHandler(bankroll *services.BankrollService) *BankrollHandler {
+	return &BankrollHandler{
+		bankroll: bankroll,
+	}
+}
+
+func (h *BankrollHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetBankrollLimits)
+	r.Put("/", h.UpdateBankrollLimits)
+
+	return r
+}
+
+// GetBankrollLimits returns the user's jurisdiction and rebuy limits
+func (h *BankrollHandler) GetBankrollLimits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	summary, err := h.bankroll.Summary(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get bankroll limits")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
+
+// UpdateBankrollLimits replaces the user's own rebuy limits. They can only
+// make their jurisdiction's limits stricter.
+func (h *BankrollHandler) UpdateBankrollLimits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.UpdateBankrollSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.bankroll.UpdateSettings(r.Context(), userID, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update bankroll limits")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DirectMessageHandler exposes direct message history, block lists and
+// reporting. Messages themselves are sent over the WebSocket.
+type DirectMessageHandler struct {
+	directMessageService *services.DirectMessageService
+}
+
+func NewDirectMessageHandler(directMessageService *services.DirectMessageService) *DirectMessageHandler {
+	return &DirectMessageHandler{
+		directMessageService: directMessageService,
+	}
+}
+
+func (h *DirectMessageHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/conversations", h.ListConversations)
+	r.Get("/conversations/{userID}", h.GetConversation)
+	r.Post("/conversations/{userID}/read", h.MarkConversationRead)
+
+	r.Get("/blocks", h.ListBlocked)
+	r.Post("/blocks/{userID}", h.BlockUser)
+	r.Delete("/blocks/{userID}", h.UnblockUser)
+
+	r.Post("/reports", h.ReportMessage)
+
+	return r
+}
+
+// ListConversations returns the user's direct message conversations
+func (h *DirectMessageHandler) ListConversations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	conversations, err := h.directMessageService.ListConversations(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch conversations")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"conversations": conversations,
+	})
+}
+
+// GetConversation returns message history with another player, newest first
+func (h *DirectMessageHandler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	var before time.Time
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		before, err = time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid before timestamp, expected RFC3339")
+			return
+		}
+	}
+
+	messages, err := h.directMessageService.GetConversation(r.Context(), userID, otherID, limit, before)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch messages")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"messages": messages,
+	})
+}
+
+// MarkConversationRead marks all messages from another player as read
+func (h *DirectMessageHandler) MarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.directMessageService.MarkConversationRead(r.Context(), userID, otherID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to mark messages read")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Conversation marked as read",
+	})
+}
+
+// ListBlocked returns the players the user has blocked
+func (h *DirectMessageHandler) ListBlocked(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	blocks, err := h.directMessageService.ListBlocked(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch block list")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"blocks": blocks,
+	})
+}
+
+// BlockUser blocks direct messages to and from another player
+func (h *DirectMessageHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	blockedID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.directMessageService.BlockUser(r.Context(), userID, blockedID); err != nil {
+		if errors.Is(err, services.ErrCannotBlockSelf) {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to block user")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "User blocked successfully",
+	})
+}
+
+// UnblockUser removes a player from the block list
+func (h *DirectMessageHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	blockedID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.directMessageService.UnblockUser(r.Context(), userID, blockedID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to unblock user")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "User unblocked successfully",
+	})
+}
+
+// ReportMessage reports a received direct message to moderators
+func (h *DirectMessageHandler) ReportMessage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.ReportMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.directMessageService.ReportMessage(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMessageNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Message not found")
+		case errors.Is(err, services.ErrNotMessageReceiver):
+			writeErrorResponse(w, http.StatusForbidden, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to report message")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, report)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultFairnessDays = 30
+	maxFairnessDays     = 365
+)
+
+// FairnessHandler serves the public, platform-wide card distribution report
+type FairnessHandler struct {
+	fairnessService *services.FairnessService
+}
+
+func NewFairnessHandler(fairnessService *services.FairnessService) *FairnessHandler {
+	return &FairnessHandler{
+		fairnessService: fairnessService,
+	}
+}
+
+func (h *FairnessHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetPlatformReport)
+
+	return r
+}
+
+// GetPlatformReport returns card frequencies and chi-square tests for every
+// hand dealt on the platform over the last ?days= full days (default 30)
+func (h *FairnessHandler) GetPlatformReport(w http.ResponseWriter, r *http.Request) {
+	days := defaultFairnessDays
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && parsed > 0 && parsed <= maxFairnessDays {
+		days = parsed
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -days)
+
+	report, err := h.fairnessService.PlatformReport(r.Context(), from, to)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to build fairness report")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"report": report,
+	})
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// FriendHandler exposes friend lists, friends' presence in the lobby and
+// the join-a-friend's-table shortcut
+type FriendHandler struct {
+	presenceService *services.PresenceService
+}
+
+func NewFriendHandler(presenceService *services.PresenceService) *FriendHandler {
+	return &FriendHandler{
+		presenceService: presenceService,
+	}
+}
+
+func (h *FriendHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListFriends)
+	r.Delete("/{userID}", h.RemoveFriend)
+	r.Post("/{userID}/join", h.JoinFriendTable)
+
+	r.Get("/requests", h.ListFriendRequests)
+	r.Post("/requests", h.SendFriendRequest)
+	r.Post("/requests/{userID}/accept", h.AcceptFriendRequest)
+
+	r.Get("/presence", h.GetPresenceSettings)
+	r.Put("/presence", h.UpdatePresenceSettings)
+
+	return r
+}
+
+// ListFriends returns the user's friends and what each is playing, as far as
+// their presence settings allow
+func (h *FriendHandler) ListFriends(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	friends, err := h.presenceService.ListFriends(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch friends")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"friends": friends,
+	})
+}
+
+// RemoveFriend ends a friendship, or declines or withdraws a request
+func (h *FriendHandler) RemoveFriend(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	otherID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.presenceService.RemoveFriend(r.Context(), userID, otherID); err != nil {
+		if errors.Is(err, services.ErrNotFriends) {
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to remove friend")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{"message": "Friend removed"})
+}
+
+// JoinFriendTable returns the table a friend is playing so the client can
+// join it through the table join endpoint, which still asks for the
+// password of a private table
+func (h *FriendHandler) JoinFriendTable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	friendID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	target, err := h.presenceService.JoinFriendTable(r.Context(), userID, friendID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFriends):
+			writeErrorResponse(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrFriendNotAtTable):
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrFriendJoinNotAllowed),
+			errors.Is(err, services.ErrFriendTableTournament):
+			writeErrorResponse(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrFriendTableFull),
+			errors.Is(err, services.ErrFriendTableUnavailable):
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to find friend's table")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, target)
+}
+
+// ListFriendRequests returns requests waiting for the user's answer
+func (h *FriendHandler) ListFriendRequests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	requests, err := h.presenceService.ListFriendRequests(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch friend requests")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"requests": requests,
+	})
+}
+
+// SendFriendRequest asks another player to be friends
+func (h *FriendHandler) SendFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.SendFriendRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	friendship, err := h.presenceService.SendFriendRequest(r.Context(), userID, req.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, services.ErrCannotFriendSelf):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrFriendshipBlocked):
+			writeErrorResponse(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrFriendRequestExists):
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to send friend request")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, friendship)
+}
+
+// AcceptFriendRequest accepts a pending request from another player
+func (h *FriendHandler) AcceptFriendRequest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	requesterID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	friendship, err := h.presenceService.AcceptFriendRequest(r.Context(), userID, requesterID)
+	if err != nil {
+		if errors.Is(err, services.ErrFriendRequestNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to accept friend request")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, friendship)
+}
+
+// GetPresenceSettings returns what the user shares with their friends
+func (h *FriendHandler) GetPresenceSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	settings, err := h.presenceService.GetSettings(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get presence settings")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
+
+// UpdatePresenceSettings changes invisible mode and what friends can see
+func (h *FriendHandler) UpdatePresenceSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.UpdatePresenceSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	settings, err := h.presenceService.UpdateSettings(r.Context(), userID, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update presence settings")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
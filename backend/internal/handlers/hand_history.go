@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxHandExportRange is the longest period one export may cover
+const maxHandExportRange = 366 * 24 * time.Hour
+
+// HandHistoryHandler serves the histories of hands a user saw, as a player
+// or spectator
+type HandHistoryHandler struct {
+	handHistoryService *services.HandHistoryService
+}
+
+func NewHandHistoryHandler(handHistoryService *services.HandHistoryService) *HandHistoryHandler {
+	return &HandHistoryHandler{
+		handHistoryService: handHistoryService,
+	}
+}
+
+func (h *HandHistoryHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListHands)
+	r.Get("/export", h.ExportHands)
+	r.Get("/privacy", h.GetPrivacy)
+	r.Put("/privacy", h.UpdatePrivacy)
+	r.Get("/{handID}", h.GetHand)
+
+	return r
+}
+
+// ListHands returns the finished hands the user was at the table for, newest first
+func (h *HandHistoryHandler) ListHands(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	limit := 20
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	hands, total, err := h.handHistoryService.ListForViewer(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch hand histories")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"hands": hands,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
+
+// GetHand returns a single finished hand. Only hole cards the user saw at the
+// table, their own and any shown at showdown, are included.
+func (h *HandHistoryHandler) GetHand(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	hand, err := h.handHistoryService.GetForViewer(r.Context(), chi.URLParam(r, "handID"), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrHandNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Hand not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch hand history")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, hand)
+}
+
+// ExportHands returns the user's finished hands between the from and to
+// query parameters (RFC 3339, default the last 30 days), oldest first.
+// Opponents are anonymized according to the room's policy and their own
+// privacy settings.
+func (h *HandHistoryHandler) ExportHands(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	to := time.Now()
+	if raw := r.URL.Query().Get("to"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid to time, use RFC 3339")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-30 * 24 * time.Hour)
+	if raw := r.URL.Query().Get("from"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid from time, use RFC 3339")
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) || to.Sub(from) > maxHandExportRange {
+		writeErrorResponse(w, http.StatusBadRequest, "From must be before to and at most a year earlier")
+		return
+	}
+
+	hands, anonymized, err := h.handHistoryService.ExportForViewer(r.Context(), userID, from, to)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to export hand histories")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="hands.json"`)
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"from":       from,
+		"to":         to,
+		"anonymized": anonymized,
+		"hands":      hands,
+	})
+}
+
+// GetPrivacy returns the user's hand history privacy settings
+func (h *HandHistoryHandler) GetPrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	settings, err := h.handHistoryService.GetPrivacy(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch hand privacy settings")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
+
+// UpdatePrivacy changes whether the user's mucked cards are stored and who
+// sees them by name in hand histories. Hands already played are unaffected.
+func (h *HandHistoryHandler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.UpdateHandPrivacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := h.handHistoryService.UpdatePrivacy(r.Context(), userID, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update hand privacy settings")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, settings)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListLastLongerPools returns a tournament's last-longer pools for its lobby
+func (h *TournamentHandler) ListLastLongerPools(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	pools, err := h.lastLonger.List(r.Context(), tournamentID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch last-longer pools")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"tournament_id": tournamentID,
+		"pools":         pools,
+	})
+}
+
+// CreateLastLongerPool opens a last-longer pool with the player as its
+// first entrant
+func (h *TournamentHandler) CreateLastLongerPool(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var req models.CreateLastLongerPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pool, err := h.lastLonger.Create(r.Context(), tournamentID, userID, req)
+	if err != nil {
+		writeLastLongerError(w, err, "Failed to create last-longer pool")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, pool)
+}
+
+// JoinLastLongerPool stakes the player into a last-longer pool
+func (h *TournamentHandler) JoinLastLongerPool(w http.ResponseWriter, r *http.Request) {
+	userID, tournamentID, poolID, ok := lastLongerParams(w, r)
+	if !ok {
+		return
+	}
+
+	pool, err := h.lastLonger.Join(r.Context(), tournamentID, poolID, userID)
+	if err != nil {
+		writeLastLongerError(w, err, "Failed to join last-longer pool")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, pool)
+}
+
+// LeaveLastLongerPool refunds the player's stake before the tournament starts
+func (h *TournamentHandler) LeaveLastLongerPool(w http.ResponseWriter, r *http.Request) {
+	userID, tournamentID, poolID, ok := lastLongerParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.lastLonger.Leave(r.Context(), tournamentID, poolID, userID); err != nil {
+		writeLastLongerError(w, err, "Failed to leave last-longer pool")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Left last-longer pool",
+		"pool_id": poolID,
+	})
+}
+
+// settleLastLonger pays the tournament's last-longer pools that have been
+// decided. Pools that fail are settled on the next elimination or finish.
+func (h *TournamentHandler) settleLastLonger(ctx context.Context, tournamentID uuid.UUID) {
+	if _, err := h.lastLonger.Settle(ctx, tournamentID); err != nil {
+		slog.Error("Failed to settle last-longer pools", "tournament_id", tournamentID, "error", err)
+	}
+}
+
+func lastLongerParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	poolID, err := uuid.Parse(chi.URLParam(r, "poolID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid pool ID")
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return userID, tournamentID, poolID, true
+}
+
+// writeLastLongerError maps a failed last-longer request to a response
+func writeLastLongerError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTournamentNotFound), errors.Is(err, services.ErrLastLongerNotFound),
+		errors.Is(err, services.ErrNotInLastLonger):
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrLastLongerClosed), errors.Is(err, services.ErrAlreadyInLastLonger):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrLastLongerNotRegistered):
+		writeErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrLastLongerStake):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.Error(message, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
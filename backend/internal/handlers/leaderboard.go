@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLeaderboardLimit = 25
+	maxLeaderboardLimit     = 100
+)
+
+// LeaderboardHandler serves the public daily, weekly and monthly leaderboards
+type LeaderboardHandler struct {
+	leaderboards *services.LeaderboardService
+}
+
+func NewLeaderboardHandler(leaderboards *services.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{
+		leaderboards: leaderboards,
+	}
+}
+
+func (h *LeaderboardHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{kind}", h.GetLeaderboard)
+
+	return r
+}
+
+// GetLeaderboard returns the top ?limit= players (default 25, at most 100) of
+// a leaderboard, winners, hands or pots, for the current ?period= (daily,
+// weekly or monthly, default weekly). Signed in players also get their own
+// standing.
+func (h *LeaderboardHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = models.LeaderboardWeekly
+	}
+	limit := defaultLeaderboardLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxLeaderboardLimit {
+			writeErrorResponse(w, http.StatusBadRequest, "Limit must be between 1 and 100")
+			return
+		}
+		limit = parsed
+	}
+
+	viewerID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		viewerID = uuid.Nil
+	}
+
+	standings, err := h.leaderboards.Get(r.Context(), kind, period, limit, viewerID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownLeaderboard):
+			writeErrorResponse(w, http.StatusNotFound, "Unknown leaderboard, use winners, hands or pots")
+		case errors.Is(err, services.ErrUnknownLeaderboardPeriod):
+			writeErrorResponse(w, http.StatusBadRequest, "Period must be daily, weekly or monthly")
+		default:
+			slog.Error("Failed to get leaderboard", "kind", kind, "period", period, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to get leaderboard")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, standings)
+}
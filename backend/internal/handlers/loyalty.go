@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService *services.LoyaltyService
+}
+
+func NewLoyaltyHandler(loyaltyService *services.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+	}
+}
+
+func (h *LoyaltyHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetLoyaltyStatus)
+
+	return r
+}
+
+// GetLoyaltyStatus returns the user's loyalty tier and progress towards the next tier
+func (h *LoyaltyHandler) GetLoyaltyStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	status, err := h.loyaltyService.GetLoyaltyStatus(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get loyalty status")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, status)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// MaintenanceHandler serves the lobby's banners for scheduled maintenance
+type MaintenanceHandler struct {
+	maintenance *services.MaintenanceService
+}
+
+func NewMaintenanceHandler(maintenance *services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+	}
+}
+
+func (h *MaintenanceHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetNotices)
+
+	return r
+}
+
+// GetNotices returns the maintenance windows in force and those coming up
+// soon, for banners in the lobby
+func (h *MaintenanceHandler) GetNotices(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"windows": h.maintenance.Notices(r.Context(), time.Now()),
+	})
+}
+
+// SetMaintenance enables the maintenance window endpoints and refuses
+// deposits during windows covering them
+func (h *AdminHandler) SetMaintenance(maintenance *services.MaintenanceService) {
+	h.maintenance = maintenance
+}
+
+// ListMaintenanceWindows returns the windows not yet over, or with
+// ?include_ended=true the ones from the last 30 days too (admin only)
+func (h *AdminHandler) ListMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	if h.maintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Maintenance windows are not available")
+		return
+	}
+
+	since := time.Now()
+	if includeEnded, _ := strconv.ParseBool(r.URL.Query().Get("include_ended")); includeEnded {
+		since = since.AddDate(0, 0, -30)
+	}
+	windows, err := h.maintenance.List(r.Context(), since)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list maintenance windows")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"windows": windows,
+	})
+}
+
+// CreateMaintenanceWindow schedules maintenance the server enforces on its
+// own at the given time (admin only)
+func (h *AdminHandler) CreateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if h.maintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Maintenance windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.CreateMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	window, err := h.maintenance.Create(r.Context(), req, adminUserID, time.Now())
+	if err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, window)
+}
+
+// UpdateMaintenanceWindow reschedules or changes a window that has not
+// ended (admin only)
+func (h *AdminHandler) UpdateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if h.maintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Maintenance windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	windowID, err := uuid.Parse(chi.URLParam(r, "windowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	var req models.UpdateMaintenanceWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	window, err := h.maintenance.Update(r.Context(), windowID, req, adminUserID, time.Now())
+	if err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, window)
+}
+
+// DeleteMaintenanceWindow cancels a window, lifting it if it is in force
+// (admin only)
+func (h *AdminHandler) DeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	if h.maintenance == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Maintenance windows are not available")
+		return
+	}
+
+	adminUserID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	windowID, err := uuid.Parse(chi.URLParam(r, "windowID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid maintenance window ID")
+		return
+	}
+
+	if err := h.maintenance.Delete(r.Context(), windowID, adminUserID); err != nil {
+		writeMaintenanceWindowError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Maintenance window cancelled",
+	})
+}
+
+func writeMaintenanceWindowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrMaintenanceWindowNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Maintenance window not found")
+	case errors.Is(err, services.ErrMaintenanceWindowEnded):
+		writeErrorResponse(w, http.StatusConflict, "Maintenance window has already ended")
+	case errors.Is(err, services.ErrMaintenanceWindowInPast):
+		writeErrorResponse(w, http.StatusBadRequest, "Maintenance window must end in the future")
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to save maintenance window")
+	}
+}
+
+// maintenanceClear refuses the request with 503 while a maintenance window
+// covers scope, saying when it ends. Without a maintenance service nothing
+// is refused.
+func maintenanceClear(w http.ResponseWriter, r *http.Request, maintenance *services.MaintenanceService, scope string) bool {
+	if maintenance == nil {
+		return true
+	}
+	now := time.Now()
+	window := maintenance.Active(r.Context(), scope, now)
+	if window == nil {
+		return true
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(window.EndsAt.Sub(now).Seconds())+1))
+	writeErrorResponse(w, http.StatusServiceUnavailable, fmt.Sprintf("Unavailable during scheduled maintenance (%s) until %s UTC", window.Title, window.EndsAt.UTC().Format("2006-01-02 15:04")))
+	return false
+}
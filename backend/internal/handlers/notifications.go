@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	pushService *services.PushService
+}
+
+func NewNotificationHandler(pushService *services.PushService) *NotificationHandler {
+	return &NotificationHandler{
+		pushService: pushService,
+	}
+}
+
+func (h *NotificationHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/devices", h.RegisterDevice)
+	r.Delete("/devices/{token}", h.UnregisterDevice)
+	r.Get("/preferences", h.GetPreferences)
+	r.Put("/preferences", h.UpdatePreferences)
+
+	return r
+}
+
+// RegisterDevice registers an FCM or APNs device token for the current user
+func (h *NotificationHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(r.Context(), userID, req.Token, req.Platform)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to register device")
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Device registered successfully",
+		"device":  device,
+	}
+
+	writeJSONResponse(w, http.StatusCreated, response)
+}
+
+// UnregisterDevice removes a device token so it no longer receives pushes
+func (h *NotificationHandler) UnregisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	token := chi.URLParam(r, "token")
+	if err := h.pushService.UnregisterDevice(r.Context(), userID, token); err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "Device not found")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Device unregistered successfully",
+	})
+}
+
+// GetPreferences returns the current user's push opt-in preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	prefs, err := h.pushService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get notification preferences")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes which push events the current user receives
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	prefs, err := h.pushService.UpdatePreferences(r.Context(), userID, req)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to update notification preferences")
+		return
+	}
+
+	response := map[string]interface{}{
+		"message":     "Notification preferences updated successfully",
+		"preferences": prefs,
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetIdentity enables sign-in with identity providers. Apple's responses
+// are forwarded to callbackURL, the frontend page other providers send
+// players back to.
+func (h *AuthHandler) SetIdentity(identity *services.IdentityService, callbackURL string) {
+	h.identity = identity
+	h.oauthCallbackURL = callbackURL
+}
+
+// OAuthProviders lists the providers players can sign in with
+func (h *AuthHandler) OAuthProviders(w http.ResponseWriter, r *http.Request) {
+	providers := []models.IdentityProvider{}
+	if h.identity != nil {
+		providers = h.identity.Providers()
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{"providers": providers})
+}
+
+// StartOAuth returns where to send the player to sign in with a provider
+func (h *AuthHandler) StartOAuth(w http.ResponseWriter, r *http.Request) {
+	if h.identity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Sign-in providers are not available")
+		return
+	}
+
+	start, err := h.identity.Start(r.Context(), models.IdentityProvider(chi.URLParam(r, "provider")))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownIdentityProvider) {
+			writeErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to start sign-in")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, start)
+}
+
+// OAuthSignIn finishes a sign-in with the code the provider sent the player
+// back with. A player who has no account yet gets 202 and a signup token to
+// pick a username with.
+func (h *AuthHandler) OAuthSignIn(w http.ResponseWriter, r *http.Request) {
+	if h.identity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Sign-in providers are not available")
+		return
+	}
+
+	var req models.OAuthSignInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	provider := models.IdentityProvider(chi.URLParam(r, "provider"))
+	loginResponse, signup, err := h.identity.SignIn(r.Context(), provider, req.Code, req.State)
+	if err != nil {
+		h.writeOAuthError(w, err, provider)
+		return
+	}
+	if signup != nil {
+		if !featureEnabled(w, r, h.featureFlags, models.FeatureRegistrations, "Registrations are temporarily closed") {
+			return
+		}
+		writeJSONResponse(w, http.StatusAccepted, signup)
+		return
+	}
+
+	h.recoverSessions(r, loginResponse)
+	writeJSONResponse(w, http.StatusOK, loginResponse)
+}
+
+// CompleteOAuthSignup creates the account of a player signing in with a
+// provider for the first time, with the username they picked
+func (h *AuthHandler) CompleteOAuthSignup(w http.ResponseWriter, r *http.Request) {
+	if h.identity == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Sign-in providers are not available")
+		return
+	}
+	if !featureEnabled(w, r, h.featureFlags, models.FeatureRegistrations, "Registrations are temporarily closed") {
+		return
+	}
+
+	var req models.CompleteOAuthSignupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Check the code up front so a typo doesn't create an unattributed account
+	if req.ReferralCode != "" && h.affiliates != nil {
+		if _, err := h.affiliates.LookupCode(r.Context(), req.ReferralCode); err != nil {
+			if errors.Is(err, services.ErrInvalidReferralCode) {
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid referral code")
+				return
+			}
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to check referral code")
+			return
+		}
+	}
+
+	loginResponse, err := h.identity.CompleteSignup(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSignupToken):
+			writeErrorResponse(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrUsernameReserved),
+			errors.Is(err, services.ErrEmailTaken), errors.Is(err, services.ErrIdentityLinked):
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrIdentityRejected):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			slog.Error("Failed to complete sign-up", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to create account")
+		}
+		return
+	}
+
+	// The account exists by now, so attribution problems are only logged
+	if req.ReferralCode != "" && h.affiliates != nil {
+		if err := h.affiliates.AttributeSignup(r.Context(), req.ReferralCode, loginResponse.User.ID); err != nil {
+			slog.Error("Failed to attribute referral", "user_id", loginResponse.User.ID, "code", req.ReferralCode, "error", err)
+		}
+	}
+
+	writeJSONResponse(w, http.StatusCreated, loginResponse)
+}
+
+// AppleCallback receives the response Apple posts back after a sign-in and
+// forwards its code and state to the frontend, which finishes the sign-in
+// like any other provider's
+func (h *AuthHandler) AppleCallback(w http.ResponseWriter, r *http.Request) {
+	if h.oauthCallbackURL == "" {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Sign-in providers are not available")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid form")
+		return
+	}
+
+	target, err := url.Parse(h.oauthCallbackURL)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Sign-in callback is misconfigured")
+		return
+	}
+	query := target.Query()
+	query.Set("provider", string(models.IdentityProviderApple))
+	for _, key := range []string{"code", "state", "error"} {
+		if value := r.PostForm.Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusSeeOther)
+}
+
+// writeOAuthError maps a failed provider sign-in to a response
+func (h *AuthHandler) writeOAuthError(w http.ResponseWriter, err error, provider models.IdentityProvider) {
+	switch {
+	case errors.Is(err, services.ErrUnknownIdentityProvider):
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrIdentityRejected):
+		writeErrorResponse(w, http.StatusUnauthorized, "Sign-in was not accepted, try again")
+	case errors.Is(err, services.ErrInvalidOAuthState):
+		writeErrorResponse(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrAccountDisabled):
+		writeErrorResponse(w, http.StatusForbidden, "This account has been disabled")
+	case errors.Is(err, services.ErrIdentityLinked):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error("Identity provider sign-in failed", "provider", provider, "error", err)
+		writeErrorResponse(w, http.StatusBadGateway, "Sign-in provider is unavailable, try again")
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+)
+
+// SetPasswordResets enables resetting forgotten passwords by email
+func (h *AuthHandler) SetPasswordResets(passwordResets *services.PasswordResetService) {
+	h.passwordResets = passwordResets
+}
+
+// ForgotPassword emails a reset link. The response is the same whether or
+// not the address has an account.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if h.passwordResets == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Password resets are not available")
+		return
+	}
+
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.passwordResets.RequestReset(r.Context(), req.Email, requestIP(r)); err != nil {
+		if errors.Is(err, services.ErrPasswordResetRateLimited) {
+			writeErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		slog.Error("Failed to request password reset", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "If an account uses that address, a reset link is on its way",
+	})
+}
+
+// ResetPassword sets a new password with an emailed reset token and signs
+// the user out everywhere
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if h.passwordResets == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Password resets are not available")
+		return
+	}
+
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.passwordResets.ResetPassword(r.Context(), req.Token, req.NewPassword, requestIP(r)); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.Error("Failed to reset password", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Password reset, sign in with the new password",
+	})
+}
+
+// requestIP is the caller's address, which the RealIP middleware has already
+// taken from proxy headers where there are any
+func requestIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// SetSeatReservations keeps players out of seats held for others
+func (h *TableHandler) SetSeatReservations(seatHolds *services.SeatReservationService) {
+	h.seatHolds = seatHolds
+}
+
+// checkSeatReservations refuses a player when every free seat at the table
+// is held for someone else. It reports whether they may join.
+func (h *TableHandler) checkSeatReservations(w http.ResponseWriter, r *http.Request, table *models.PokerTable, userID uuid.UUID) bool {
+	if h.seatHolds == nil {
+		return true
+	}
+
+	err := h.seatHolds.CheckJoin(r.Context(), table, userID, table.CurrentPlayers, time.Now())
+	if errors.Is(err, services.ErrSeatsReserved) {
+		writeErrorResponse(w, http.StatusForbidden, "The remaining seats at this table are reserved")
+		return false
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check seat reservations")
+		return false
+	}
+	return true
+}
+
+// claimSeatReservation releases the seat held for a player who has joined
+func (h *TableHandler) claimSeatReservation(r *http.Request, tableID, userID uuid.UUID) {
+	if h.seatHolds == nil {
+		return
+	}
+	if err := h.seatHolds.Claim(r.Context(), tableID, userID, time.Now()); err != nil {
+		slog.Warn("Failed to claim seat reservation", "table_id", tableID, "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MoveTableRequest buys in at a table with the chips of a session the user
+// is finishing at another
+type MoveTableRequest struct {
+	FromTableID uuid.UUID `json:"from_table_id" validate:"required"`
+	BuyInAmount int64     `json:"buy_in_amount" validate:"required,gt=0"`
+	Password    string    `json:"password,omitempty"`
+}
+
+// errNoFinishingSession is returned when the user has no session to move
+// funds from at the table they named
+var errNoFinishingSession = errors.New("no active session at that table")
+
+// finishingSession returns the user's active session at a table and its
+// ledger balance, which is what the session has to move on
+func finishingSession(ctx context.Context, db *database.DB, formanceService *formance.Service, userID, tableID uuid.UUID) (*models.GameSession, int64, error) {
+	var session models.GameSession
+	if err := db.Where("user_id = ? AND table_id = ? AND status = ?", userID, tableID, models.GameSessionStatusActive).First(&session).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, 0, errNoFinishingSession
+		}
+		return nil, 0, err
+	}
+	balance, err := formanceService.GetSessionBalance(ctx, userID, session.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get session balance: %w", err)
+	}
+	return &session, balance, nil
+}
+
+// closeMovedSession finishes a session whose chips were moved on without
+// passing through the wallet, and frees its seat
+func closeMovedSession(tx *gorm.DB, session *models.GameSession, chips int64) error {
+	session.CurrentChips = chips
+	session.Finish()
+	if err := tx.Save(session).Error; err != nil {
+		return err
+	}
+	return releaseTableSeat(tx, session.TableID)
+}
+
+// takeTableSeat counts a player in at a table, marking it full when the
+// last seat is taken
+func takeTableSeat(tx *gorm.DB, table *models.PokerTable) error {
+	updates := map[string]interface{}{
+		"current_players": gorm.Expr("current_players + 1"),
+	}
+	if table.CurrentPlayers+1 >= table.MaxPlayers {
+		updates["status"] = "full"
+	} else if table.Status == "waiting" {
+		updates["status"] = "active"
+	}
+	return tx.Model(table).Updates(updates).Error
+}
+
+// releaseTableSeat counts a player out of a table
+func releaseTableSeat(tx *gorm.DB, tableID uuid.UUID) error {
+	var table models.PokerTable
+	if err := tx.First(&table, "id = ?", tableID).Error; err != nil {
+		return err
+	}
+	if table.CurrentPlayers <= 0 {
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"current_players": table.CurrentPlayers - 1,
+	}
+	if table.CurrentPlayers == 1 {
+		updates["status"] = "waiting"
+	} else if table.Status == "full" {
+		updates["status"] = "active"
+	}
+	return tx.Model(&table).Updates(updates).Error
+}
+
+// MoveTable changes tables in one step: the session at the table being left
+// buys in here directly, as a single ledger posting, and whatever it holds
+// beyond the buy-in goes back to the wallet
+func (h *TableHandler) MoveTable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeAllPlay) {
+		return
+	}
+
+	tableID, err := uuid.Parse(chi.URLParam(r, "tableID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid table ID")
+		return
+	}
+
+	var req MoveTableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if req.FromTableID == uuid.Nil || req.FromTableID == tableID {
+		writeErrorResponse(w, http.StatusBadRequest, "from_table_id must be another table")
+		return
+	}
+
+	var table models.PokerTable
+	if err := h.db.First(&table, "id = ?", tableID).Error; err != nil {
+		writeErrorResponse(w, http.StatusNotFound, "Table not found")
+		return
+	}
+	if table.TableType == "practice" {
+		writeErrorResponse(w, http.StatusBadRequest, "Practice tables play for play chips only")
+		return
+	}
+	if table.CurrentPlayers >= table.MaxPlayers {
+		writeErrorResponse(w, http.StatusBadRequest, "Table is full")
+		return
+	}
+	if table.IsPrivate && table.PasswordHash != nil {
+		if err := bcrypt.CompareHashAndPassword([]byte(*table.PasswordHash), []byte(req.Password)); err != nil {
+			writeErrorResponse(w, http.StatusUnauthorized, "Incorrect table password")
+			return
+		}
+	}
+	if !h.checkTableBan(w, r, &table, userID) {
+		return
+	}
+	if err := services.CheckBuyIn(&table, req.BuyInAmount); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var existingSession models.GameSession
+	if err := h.db.Where("user_id = ? AND table_id = ? AND status = ?", userID, tableID, models.GameSessionStatusActive).First(&existingSession).Error; err == nil {
+		writeErrorResponse(w, http.StatusBadRequest, "User already has an active session at this table")
+		return
+	}
+
+	from, balance, err := finishingSession(r.Context(), h.db, h.formanceService, userID, req.FromTableID)
+	if errors.Is(err, errNoFinishingSession) {
+		writeErrorResponse(w, http.StatusBadRequest, "User is not currently at the table being left")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to find user session")
+		return
+	}
+	// Moving never adds to what the user has in play, so exposure limits
+	// don't apply; topping up from the wallet is a separate buy-in
+	if balance < req.BuyInAmount {
+		writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Session holds %d MNT, less than the buy-in", balance))
+		return
+	}
+
+	session := models.GameSession{
+		UserID:       userID,
+		TableID:      tableID,
+		BuyInAmount:  req.BuyInAmount,
+		CurrentChips: req.BuyInAmount,
+		Status:       models.GameSessionStatusActive,
+	}
+
+	// The ledger posting is the last step, so a failed posting rolls the
+	// sessions and seats back with it
+	var transactionID string
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		if err := closeMovedSession(tx, from, balance); err != nil {
+			return err
+		}
+		if err := takeTableSeat(tx, &table); err != nil {
+			return err
+		}
+		transactionID, err = h.formanceService.MoveSessionToSession(r.Context(), userID, from.ID, session.ID, req.BuyInAmount, balance-req.BuyInAmount)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to move session to another table", "user_id", userID, "from_session_id", from.ID, "table_id", tableID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to move to table")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":         "Successfully moved to table",
+		"table_id":        tableID,
+		"user_id":         userID,
+		"buy_in_amount":   req.BuyInAmount,
+		"session_id":      session.ID,
+		"from_session_id": from.ID,
+		"returned_amount": balance - req.BuyInAmount,
+		"transaction_id":  transactionID,
+	})
+}
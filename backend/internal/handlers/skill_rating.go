@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GetSkill returns the skill band shown on a player's profile. The rating
+// behind it is never shown.
+func (h *UserHandler) GetSkill(w http.ResponseWriter, r *http.Request) {
+	if h.skillRatings == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Skill ratings are not available")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	profile, err := h.skillRatings.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get skill rating")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, profile)
+}
+
+// QuickSeat suggests open cash tables for the current user. At low stakes
+// tables are skipped where new players would sit with sharks. The optional
+// max_big_blind parameter limits the stakes.
+func (h *TableHandler) QuickSeat(w http.ResponseWriter, r *http.Request) {
+	if h.skillRatings == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Quick seat is not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var maxBigBlind int64
+	if raw := r.URL.Query().Get("max_big_blind"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "max_big_blind must be a positive whole number")
+			return
+		}
+		maxBigBlind = parsed
+	}
+	limit := 5
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 20 {
+		limit = parsed
+	}
+
+	suggestions, err := h.skillRatings.SuggestTables(r.Context(), userID, maxBigBlind, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to find tables")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"tables": suggestions,
+	})
+}
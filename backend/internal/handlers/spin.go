@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SpinHandler lets players queue for spins and check their draws
+type SpinHandler struct {
+	spins        *services.SpinService
+	featureFlags *services.FeatureFlagService
+}
+
+func NewSpinHandler(spins *services.SpinService) *SpinHandler {
+	return &SpinHandler{
+		spins: spins,
+	}
+}
+
+// SetFeatureFlags closes the spin queue along with tournament registration
+func (h *SpinHandler) SetFeatureFlags(featureFlags *services.FeatureFlagService) {
+	h.featureFlags = featureFlags
+}
+
+func (h *SpinHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListFormats)
+	r.Post("/{formatID}/join", h.Join)
+	r.Post("/{formatID}/leave", h.Leave)
+	r.Get("/draws/{tournamentID}", h.GetDraw)
+
+	return r
+}
+
+// ListFormats returns the spin formats taking players, with their prize
+// tiers and how many players are waiting
+func (h *SpinHandler) ListFormats(w http.ResponseWriter, r *http.Request) {
+	formats, err := h.spins.ListFormats(r.Context(), true)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch spin formats")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"formats": formats,
+	})
+}
+
+// Join buys the user into the next spin of a format, seating them as soon
+// as it has three players
+func (h *SpinHandler) Join(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	formatID, err := uuid.Parse(chi.URLParam(r, "formatID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid spin format ID")
+		return
+	}
+
+	// The body is optional
+	var req models.JoinSpinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !featureEnabled(w, r, h.featureFlags, models.FeatureTournamentRegistration, "Tournament registration is temporarily closed") {
+		return
+	}
+
+	join, err := h.spins.Join(r.Context(), userID, formatID, strings.TrimSpace(req.ClientSeed))
+	if err != nil {
+		writeSpinError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, join)
+}
+
+// Leave takes the user out of a spin that hasn't filled and refunds the
+// buy-in
+func (h *SpinHandler) Leave(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	formatID, err := uuid.Parse(chi.URLParam(r, "formatID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid spin format ID")
+		return
+	}
+
+	if err := h.spins.Leave(r.Context(), userID, formatID); err != nil {
+		writeSpinError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Left the spin queue, buy-in refunded",
+	})
+}
+
+// GetDraw returns a spin's draw proof: the server seed's hash from the
+// start, and once drawn the seeds that check it
+func (h *SpinHandler) GetDraw(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	proof, err := h.spins.Proof(r.Context(), tournamentID)
+	if err != nil {
+		writeSpinError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, proof)
+}
+
+func writeSpinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrSpinFormatNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Spin format not found")
+	case errors.Is(err, services.ErrTournamentNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "Spin not found")
+	case errors.Is(err, services.ErrSpinFormatInactive):
+		writeErrorResponse(w, http.StatusConflict, "Spin format is not taking players")
+	case errors.Is(err, services.ErrAlreadyInSpinQueue):
+		writeErrorResponse(w, http.StatusConflict, "Already waiting for a spin of this format")
+	case errors.Is(err, services.ErrNotInSpinQueue):
+		writeErrorResponse(w, http.StatusNotFound, "Not waiting for a spin of this format")
+	case errors.Is(err, services.ErrSpinBuyIn):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to process spin")
+	}
+}
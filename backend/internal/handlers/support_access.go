@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+)
+
+// SupportAccessHandler lets users consent to support staff viewing their
+// account and see every time they did
+type SupportAccessHandler struct {
+	impersonation *services.ImpersonationService
+}
+
+func NewSupportAccessHandler(impersonation *services.ImpersonationService) *SupportAccessHandler {
+	return &SupportAccessHandler{
+		impersonation: impersonation,
+	}
+}
+
+func (h *SupportAccessHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.GetSupportAccess)
+	r.Put("/", h.GrantSupportAccess)
+	r.Delete("/", h.RevokeSupportAccess)
+
+	return r
+}
+
+// GetSupportAccess returns the user's current grant and the impersonation
+// sessions held on their account
+func (h *SupportAccessHandler) GetSupportAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	grant, sessions, err := h.impersonation.SupportAccess(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get support access")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"grant":    grant,
+		"sessions": sessions,
+	})
+}
+
+// GrantSupportAccess lets support staff view the account for some hours
+func (h *SupportAccessHandler) GrantSupportAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.GrantSupportAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	grant, err := h.impersonation.GrantSupportAccess(r.Context(), userID, time.Duration(req.Hours)*time.Hour)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to grant support access")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, grant)
+}
+
+// RevokeSupportAccess withdraws consent and ends any open impersonation
+func (h *SupportAccessHandler) RevokeSupportAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	if err := h.impersonation.RevokeSupportAccess(r.Context(), userID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to revoke support access")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Support access revoked",
+	})
+}
@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/auth"
 	"github.com/anhbaysgalan1/gp/internal/database"
 	"github.com/anhbaysgalan1/gp/internal/formance"
 	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -17,6 +23,12 @@ import (
 type TableHandler struct {
 	db              *database.DB
 	formanceService *formance.Service
+	exposure        *services.ExposureService
+	skillRatings    *services.SkillRatingService
+	maintenance     *services.MaintenanceService
+	tableBans       *services.TableBanService
+	rakeFree        *services.RakeFreeService
+	seatHolds       *services.SeatReservationService
 }
 
 func NewTableHandler(db *database.DB, formanceService *formance.Service) *TableHandler {
@@ -26,24 +38,67 @@ func NewTableHandler(db *database.DB, formanceService *formance.Service) *TableH
 	}
 }
 
+// SetExposureService caps the MNT a user may have in game sessions at once
+func (h *TableHandler) SetExposureService(exposure *services.ExposureService) {
+	h.exposure = exposure
+}
+
+// SetSkillRatings enables quick seat, which suggests tables by skill band
+func (h *TableHandler) SetSkillRatings(skillRatings *services.SkillRatingService) {
+	h.skillRatings = skillRatings
+}
+
+// SetMaintenance refuses new tables and seats during maintenance windows
+// covering them
+func (h *TableHandler) SetMaintenance(maintenance *services.MaintenanceService) {
+	h.maintenance = maintenance
+}
+
+// SetTableBans lets private table owners keep players they've banned out of
+// their tables
+func (h *TableHandler) SetTableBans(tableBans *services.TableBanService) {
+	h.tableBans = tableBans
+}
+
+// SetRakeFree badges tables in a rake-free window in the lobby
+func (h *TableHandler) SetRakeFree(rakeFree *services.RakeFreeService) {
+	h.rakeFree = rakeFree
+}
+
+// markRakeFree sets RakeFreeUntil on a cash table in a rake-free window
+func (h *TableHandler) markRakeFree(ctx context.Context, table *models.PokerTable) {
+	if h.rakeFree == nil || table.TableType != "cash" {
+		return
+	}
+	if window := h.rakeFree.Active(ctx, table.ID, table.TemplateID, time.Now()); window != nil {
+		table.RakeFreeUntil = &window.EndsAt
+	}
+}
+
 func (h *TableHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/", h.ListTables)
 	r.Post("/", h.CreateTable)
+	r.Get("/quick-seat", h.QuickSeat)
+	r.Get("/bans", h.ListTableBans)
+	r.Post("/bans", h.BanTableUser)
+	r.Delete("/bans/{userID}", h.UnbanTableUser)
 	r.Get("/{tableID}", h.GetTable)
 	r.Put("/{tableID}", h.UpdateTable)
 	r.Delete("/{tableID}", h.DeleteTable)
 	r.Post("/{tableID}/join", h.JoinTable)
 	r.Post("/{tableID}/leave", h.LeaveTable)
+	r.Post("/{tableID}/move", h.MoveTable)
 
 	return r
 }
 
 type CreateTableRequest struct {
 	Name       string `json:"name" validate:"required,min=3,max=100"`
-	TableType  string `json:"table_type" validate:"required,oneof=cash tournament"`
-	GameType   string `json:"game_type" validate:"oneof=texas_holdem omaha stud"`
+	TableType  string `json:"table_type" validate:"required,oneof=cash tournament practice"`
+	GameType   string `json:"game_type" validate:"oneof=texas_holdem omaha short_deck stud"`
+	HiLo       bool   `json:"hi_lo,omitempty"`
 	MaxPlayers int    `json:"max_players" validate:"min=2,max=10"`
 	MinBuyIn   int64  `json:"min_buy_in" validate:"required,gt=0"`
 	MaxBuyIn   int64  `json:"max_buy_in" validate:"required,gt=0"`
@@ -51,6 +106,30 @@ type CreateTableRequest struct {
 	BigBlind   int64  `json:"big_blind" validate:"required,gt=0"`
 	IsPrivate  bool   `json:"is_private"`
 	Password   string `json:"password,omitempty"`
+
+	// Private game policies
+	CallTime       *time.Time `json:"call_time,omitempty"`
+	CallTimeHands  int        `json:"call_time_hands,omitempty"`
+	MinPlayMinutes int        `json:"min_play_minutes,omitempty"`
+	BigWinAmount   int64      `json:"big_win_amount,omitempty"`
+	DealersChoice  []string   `json:"dealers_choice,omitempty"` // Games the button picks from, including game_type
+
+	// Closing cash tables that stay short-handed, 10 minutes below 2 players by default
+	MinPlayers         int  `json:"min_players,omitempty"`
+	ShortHandedMinutes *int `json:"short_handed_minutes,omitempty"` // 0 keeps the table open
+
+	// Effective stacks: standard by default, cap tables cash out chips above
+	// chip_cap between hands, deep tables allow a larger max buy-in
+	StackType string `json:"stack_type,omitempty"`
+	ChipCap   int64  `json:"chip_cap,omitempty"`
+
+	// Cash tables: a player who takes longer than action_timeout_seconds
+	// checks or folds, and is sat out after sit_out_after_timeouts of those in
+	// a row. Players sitting out sit_out_cash_out_minutes are cashed out. 0
+	// turns each off; 30 seconds, 2 timeouts and 10 minutes by default.
+	ActionTimeoutSeconds *int `json:"action_timeout_seconds,omitempty"`
+	SitOutAfterTimeouts  *int `json:"sit_out_after_timeouts,omitempty"`
+	SitOutCashOutMinutes *int `json:"sit_out_cash_out_minutes,omitempty"`
 }
 
 type UpdateTableRequest struct {
@@ -61,6 +140,123 @@ type UpdateTableRequest struct {
 	MinBuyIn   *int64  `json:"min_buy_in,omitempty"`
 	SmallBlind *int64  `json:"small_blind,omitempty"`
 	BigBlind   *int64  `json:"big_blind,omitempty"`
+
+	// Private game policies
+	CallTime       *time.Time `json:"call_time,omitempty"`
+	ClearCallTime  bool       `json:"clear_call_time,omitempty"`
+	CallTimeHands  *int       `json:"call_time_hands,omitempty"`
+	MinPlayMinutes *int       `json:"min_play_minutes,omitempty"`
+	BigWinAmount   *int64     `json:"big_win_amount,omitempty"`
+	DealersChoice  *[]string  `json:"dealers_choice,omitempty"` // Empty turns dealer's choice off
+
+	MinPlayers         *int `json:"min_players,omitempty"`
+	ShortHandedMinutes *int `json:"short_handed_minutes,omitempty"`
+
+	StackType *string `json:"stack_type,omitempty"`
+	ChipCap   *int64  `json:"chip_cap,omitempty"`
+
+	ActionTimeoutSeconds *int `json:"action_timeout_seconds,omitempty"`
+	SitOutAfterTimeouts  *int `json:"sit_out_after_timeouts,omitempty"`
+	SitOutCashOutMinutes *int `json:"sit_out_cash_out_minutes,omitempty"`
+}
+
+const (
+	maxCallTimeHands  = 20
+	maxMinPlayMinutes = 240
+
+	defaultMinPlayers         = 2
+	defaultShortHandedMinutes = 10
+	maxShortHandedMinutes     = 24 * 60
+
+	defaultActionTimeoutSeconds = 30
+	minActionTimeoutSeconds     = 10
+	maxActionTimeoutSeconds     = 5 * 60
+	defaultSitOutAfterTimeouts  = 2
+	maxSitOutAfterTimeouts      = 10
+	defaultSitOutCashOutMinutes = 10
+	maxSitOutCashOutMinutes     = 24 * 60
+)
+
+// shortHandedPolicyError checks when a cash table closes for lack of players
+// and returns a message for the first problem, or "" if acceptable
+func shortHandedPolicyError(maxPlayers, minPlayers, shortHandedMinutes int) string {
+	if minPlayers < 2 || minPlayers > maxPlayers {
+		return fmt.Sprintf("Minimum players must be between 2 and %d", maxPlayers)
+	}
+	if shortHandedMinutes < 0 || shortHandedMinutes > maxShortHandedMinutes {
+		return fmt.Sprintf("Short-handed close time must be between 0 and %d minutes", maxShortHandedMinutes)
+	}
+	return ""
+}
+
+// sitOutPolicyError checks the action timeout and sitting out thresholds and
+// returns a message for the first problem, or "" if acceptable
+func sitOutPolicyError(actionTimeoutSeconds, sitOutAfterTimeouts, sitOutCashOutMinutes int) string {
+	if actionTimeoutSeconds != 0 && (actionTimeoutSeconds < minActionTimeoutSeconds || actionTimeoutSeconds > maxActionTimeoutSeconds) {
+		return fmt.Sprintf("Action timeout must be 0 or between %d and %d seconds", minActionTimeoutSeconds, maxActionTimeoutSeconds)
+	}
+	if sitOutAfterTimeouts < 0 || sitOutAfterTimeouts > maxSitOutAfterTimeouts {
+		return fmt.Sprintf("Timeouts before sitting out must be between 0 and %d", maxSitOutAfterTimeouts)
+	}
+	if sitOutCashOutMinutes < 0 || sitOutCashOutMinutes > maxSitOutCashOutMinutes {
+		return fmt.Sprintf("Sitting out cash-out time must be between 0 and %d minutes", maxSitOutCashOutMinutes)
+	}
+	return ""
+}
+
+// intOrDefault returns *v, or def when it was not given
+func intOrDefault(v *int, def int) int {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// dealersChoiceGames are the games a dealer's choice table can offer
+var dealersChoiceGames = []string{"texas_holdem", "omaha", "short_deck"}
+
+// dealersChoiceError checks the games offered at a dealer's choice table and
+// returns a message for the first problem, or "" if acceptable
+func dealersChoiceError(isPrivate bool, gameType string, games []string) string {
+	if len(games) == 0 {
+		return ""
+	}
+	if !isPrivate {
+		return "Dealer's choice is only available at private tables"
+	}
+	for i, game := range games {
+		if !slices.Contains(dealersChoiceGames, game) {
+			return fmt.Sprintf("Dealer's choice games must be among %s", strings.Join(dealersChoiceGames, ", "))
+		}
+		if slices.Contains(games[:i], game) {
+			return fmt.Sprintf("%s is listed more than once", game)
+		}
+	}
+	if len(games) < 2 {
+		return "Dealer's choice needs at least two games"
+	}
+	if !slices.Contains(games, gameType) {
+		return "The table's game type must be one of the dealer's choice games"
+	}
+	return ""
+}
+
+// tablePolicyError checks a table's private game policies and returns a
+// message for the first problem, or "" if they are acceptable
+func tablePolicyError(isPrivate bool, callTime *time.Time, callTimeHands, minPlayMinutes int, bigWinAmount int64) string {
+	if !isPrivate && (callTime != nil || callTimeHands != 0 || minPlayMinutes != 0 || bigWinAmount != 0) {
+		return "Call time and minimum play time are only available at private tables"
+	}
+	if callTimeHands < 0 || callTimeHands > maxCallTimeHands {
+		return fmt.Sprintf("Call time hands must be between 0 and %d", maxCallTimeHands)
+	}
+	if minPlayMinutes < 0 || minPlayMinutes > maxMinPlayMinutes {
+		return fmt.Sprintf("Minimum play time must be between 0 and %d minutes", maxMinPlayMinutes)
+	}
+	if bigWinAmount < 0 {
+		return "Big win amount cannot be negative"
+	}
+	return ""
 }
 
 type JoinTableRequest struct {
@@ -131,6 +327,9 @@ func (h *TableHandler) ListTables(w http.ResponseWriter, r *http.Request) {
 	}
 	countQuery.Count(&total)
 
+	for i := range tables {
+		h.markRakeFree(r.Context(), &tables[i])
+	}
 	response := map[string]interface{}{
 		"tables": tables,
 		"pagination": map[string]interface{}{
@@ -139,6 +338,10 @@ func (h *TableHandler) ListTables(w http.ResponseWriter, r *http.Request) {
 			"total":  total,
 		},
 	}
+	// Lets the lobby warn that stakes here are play money
+	if h.formanceService.Sandbox() {
+		response["sandbox"] = true
+	}
 
 	writeJSONResponse(w, http.StatusOK, response)
 }
@@ -151,6 +354,10 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeNewTables) {
+		return
+	}
+
 	var req CreateTableRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
@@ -185,11 +392,51 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.CallTime != nil && !req.CallTime.After(time.Now()) {
+		writeErrorResponse(w, http.StatusBadRequest, "Call time must be in the future")
+		return
+	}
+	if msg := tablePolicyError(req.IsPrivate, req.CallTime, req.CallTimeHands, req.MinPlayMinutes, req.BigWinAmount); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := dealersChoiceError(req.IsPrivate, req.GameType, req.DealersChoice); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if req.MinPlayers == 0 {
+		req.MinPlayers = defaultMinPlayers
+	}
+	shortHandedMinutes := defaultShortHandedMinutes
+	if req.ShortHandedMinutes != nil {
+		shortHandedMinutes = *req.ShortHandedMinutes
+	}
+	if msg := shortHandedPolicyError(req.MaxPlayers, req.MinPlayers, shortHandedMinutes); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	actionTimeoutSeconds := intOrDefault(req.ActionTimeoutSeconds, defaultActionTimeoutSeconds)
+	sitOutAfterTimeouts := intOrDefault(req.SitOutAfterTimeouts, defaultSitOutAfterTimeouts)
+	sitOutCashOutMinutes := intOrDefault(req.SitOutCashOutMinutes, defaultSitOutCashOutMinutes)
+	if msg := sitOutPolicyError(actionTimeoutSeconds, sitOutAfterTimeouts, sitOutCashOutMinutes); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	if req.StackType == "" {
+		req.StackType = services.StackTypeStandard
+	}
+	if msg := services.StackPolicyError(req.StackType, req.MaxBuyIn, req.BigBlind, req.ChipCap); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+
 	// Create table
 	table := models.PokerTable{
 		Name:       req.Name,
 		TableType:  req.TableType,
 		GameType:   req.GameType,
+		HiLo:       req.HiLo,
 		MaxPlayers: req.MaxPlayers,
 		MinBuyIn:   req.MinBuyIn,
 		MaxBuyIn:   req.MaxBuyIn,
@@ -198,6 +445,22 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		IsPrivate:  req.IsPrivate,
 		Status:     "waiting",
 		CreatedBy:  userID,
+
+		CallTime:       req.CallTime,
+		CallTimeHands:  req.CallTimeHands,
+		MinPlayMinutes: req.MinPlayMinutes,
+		BigWinAmount:   req.BigWinAmount,
+		DealersChoice:  strings.Join(req.DealersChoice, ","),
+
+		MinPlayers:         req.MinPlayers,
+		ShortHandedMinutes: shortHandedMinutes,
+
+		StackType: req.StackType,
+		ChipCap:   req.ChipCap,
+
+		ActionTimeoutSeconds: actionTimeoutSeconds,
+		SitOutAfterTimeouts:  sitOutAfterTimeouts,
+		SitOutCashOutMinutes: sitOutCashOutMinutes,
 	}
 
 	// Hash password if provided
@@ -211,6 +474,7 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		table.PasswordHash = &hashedPasswordStr
 	}
 
+	zeroed := table.ZeroedDefaults()
 	if err := h.db.Create(&table).Error; err != nil {
 		if database.IsUniqueConstraintError(err) {
 			writeErrorResponse(w, http.StatusConflict, "Table name already exists")
@@ -220,6 +484,18 @@ func (h *TableHandler) CreateTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Zero is skipped on insert in favour of the column default
+	if len(zeroed) > 0 {
+		if err := h.db.Model(&table).Updates(zeroed).Error; err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to create table")
+			return
+		}
+		table.ShortHandedMinutes = shortHandedMinutes
+		table.ActionTimeoutSeconds = actionTimeoutSeconds
+		table.SitOutAfterTimeouts = sitOutAfterTimeouts
+		table.SitOutCashOutMinutes = sitOutCashOutMinutes
+	}
+
 	writeJSONResponse(w, http.StatusCreated, table)
 }
 
@@ -240,6 +516,7 @@ func (h *TableHandler) GetTable(w http.ResponseWriter, r *http.Request) {
 
 	// Hide password hash from response
 	table.PasswordHash = nil
+	h.markRakeFree(r.Context(), &table)
 
 	writeJSONResponse(w, http.StatusOK, table)
 }
@@ -297,7 +574,11 @@ func (h *TableHandler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 			updates["password_hash"] = string(hashedPassword)
 		}
 	}
+	// Policies are checked as they will stand after the update. Running
+	// tables pick up changes at the start of the next hand.
+	policy := table
 	if req.MaxBuyIn != nil && *req.MaxBuyIn > 0 {
+		policy.MaxBuyIn = *req.MaxBuyIn
 		updates["max_buy_in"] = *req.MaxBuyIn
 	}
 	if req.MinBuyIn != nil && *req.MinBuyIn > 0 {
@@ -307,8 +588,98 @@ func (h *TableHandler) UpdateTable(w http.ResponseWriter, r *http.Request) {
 		updates["small_blind"] = *req.SmallBlind
 	}
 	if req.BigBlind != nil && *req.BigBlind > 0 {
+		policy.BigBlind = *req.BigBlind
 		updates["big_blind"] = *req.BigBlind
 	}
+	if req.IsPrivate != nil {
+		policy.IsPrivate = *req.IsPrivate
+	}
+	if req.ClearCallTime {
+		policy.CallTime = nil
+		updates["call_time"] = nil
+	} else if req.CallTime != nil {
+		policy.CallTime = req.CallTime
+		updates["call_time"] = *req.CallTime
+	}
+	if req.CallTimeHands != nil {
+		policy.CallTimeHands = *req.CallTimeHands
+		updates["call_time_hands"] = *req.CallTimeHands
+	}
+	if req.MinPlayMinutes != nil {
+		policy.MinPlayMinutes = *req.MinPlayMinutes
+		updates["min_play_minutes"] = *req.MinPlayMinutes
+	}
+	if req.BigWinAmount != nil {
+		policy.BigWinAmount = *req.BigWinAmount
+		updates["big_win_amount"] = *req.BigWinAmount
+	}
+	if req.DealersChoice != nil {
+		policy.DealersChoice = strings.Join(*req.DealersChoice, ",")
+		updates["dealers_choice"] = policy.DealersChoice
+	}
+	if req.MinPlayers != nil {
+		policy.MinPlayers = *req.MinPlayers
+		updates["min_players"] = *req.MinPlayers
+	}
+	if req.ShortHandedMinutes != nil {
+		policy.ShortHandedMinutes = *req.ShortHandedMinutes
+		updates["short_handed_minutes"] = *req.ShortHandedMinutes
+	}
+	if req.StackType != nil {
+		policy.StackType = *req.StackType
+		updates["stack_type"] = *req.StackType
+		// Leaving a cap table drops its cap
+		if *req.StackType != services.StackTypeCap && req.ChipCap == nil {
+			policy.ChipCap = 0
+			updates["chip_cap"] = 0
+		}
+	}
+	if req.ChipCap != nil {
+		policy.ChipCap = *req.ChipCap
+		updates["chip_cap"] = *req.ChipCap
+	}
+	if req.ActionTimeoutSeconds != nil {
+		policy.ActionTimeoutSeconds = *req.ActionTimeoutSeconds
+		updates["action_timeout_seconds"] = *req.ActionTimeoutSeconds
+	}
+	if req.SitOutAfterTimeouts != nil {
+		policy.SitOutAfterTimeouts = *req.SitOutAfterTimeouts
+		updates["sit_out_after_timeouts"] = *req.SitOutAfterTimeouts
+	}
+	if req.SitOutCashOutMinutes != nil {
+		policy.SitOutCashOutMinutes = *req.SitOutCashOutMinutes
+		updates["sit_out_cash_out_minutes"] = *req.SitOutCashOutMinutes
+	}
+	if req.CallTime != nil && !req.ClearCallTime && !req.CallTime.After(time.Now()) {
+		writeErrorResponse(w, http.StatusBadRequest, "Call time must be in the future")
+		return
+	}
+	if msg := tablePolicyError(policy.IsPrivate, policy.CallTime, policy.CallTimeHands, policy.MinPlayMinutes, policy.BigWinAmount); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	if policy.DealersChoice != "" {
+		if msg := dealersChoiceError(policy.IsPrivate, policy.GameType, strings.Split(policy.DealersChoice, ",")); msg != "" {
+			writeErrorResponse(w, http.StatusBadRequest, msg)
+			return
+		}
+	}
+	if msg := shortHandedPolicyError(policy.MaxPlayers, policy.MinPlayers, policy.ShortHandedMinutes); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := sitOutPolicyError(policy.ActionTimeoutSeconds, policy.SitOutAfterTimeouts, policy.SitOutCashOutMinutes); msg != "" {
+		writeErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+	// Only checked when the stakes change, so tables opened before stack
+	// types existed can still be renamed
+	if req.MaxBuyIn != nil || req.BigBlind != nil || req.StackType != nil || req.ChipCap != nil {
+		if msg := services.StackPolicyError(policy.StackType, policy.MaxBuyIn, policy.BigBlind, policy.ChipCap); msg != "" {
+			writeErrorResponse(w, http.StatusBadRequest, msg)
+			return
+		}
+	}
 
 	if len(updates) == 0 {
 		writeErrorResponse(w, http.StatusBadRequest, "No valid fields to update")
@@ -382,6 +753,10 @@ func (h *TableHandler) JoinTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !maintenanceClear(w, r, h.maintenance, models.MaintenanceScopeAllPlay) {
+		return
+	}
+
 	tableIDStr := chi.URLParam(r, "tableID")
 	tableID, err := uuid.Parse(tableIDStr)
 	if err != nil {
@@ -416,9 +791,17 @@ func (h *TableHandler) JoinTable(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !h.checkTableBan(w, r, &table, userID) {
+		return
+	}
+
+	if !h.checkSeatReservations(w, r, &table, userID) {
+		return
+	}
+
 	// Check buy-in amount
-	if req.BuyInAmount < table.MinBuyIn || req.BuyInAmount > table.MaxBuyIn {
-		writeErrorResponse(w, http.StatusBadRequest, "Buy-in amount must be between min and max buy-in")
+	if err := services.CheckBuyIn(&table, req.BuyInAmount); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -441,6 +824,10 @@ func (h *TableHandler) JoinTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.exposure != nil && !checkExposureLimit(w, r, h.exposure, userID, req.BuyInAmount) {
+		return
+	}
+
 	// Create game session
 	session := models.GameSession{
 		UserID:       userID,
@@ -473,6 +860,8 @@ func (h *TableHandler) JoinTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.claimSeatReservation(r, tableID, userID)
+
 	// Update table status if needed
 	if table.CurrentPlayers+1 >= table.MaxPlayers {
 		h.db.Model(&table).Update("status", "full")
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ListTableBans returns the players the current user has banned from their
+// private tables
+func (h *TableHandler) ListTableBans(w http.ResponseWriter, r *http.Request) {
+	if h.tableBans == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table bans are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	bans, err := h.tableBans.List(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list table bans")
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"bans": bans,
+	})
+}
+
+// BanTableUser bans a player from every private table the current user owns
+func (h *TableHandler) BanTableUser(w http.ResponseWriter, r *http.Request) {
+	if h.tableBans == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table bans are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.BanTableUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ban, err := h.tableBans.Ban(r.Context(), userID, req)
+	switch {
+	case errors.Is(err, services.ErrCannotBanSelf):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrUserNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "User not found")
+	case err != nil:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to ban user")
+	default:
+		writeJSONResponse(w, http.StatusOK, ban)
+	}
+}
+
+// UnbanTableUser lifts the current user's ban on a player
+func (h *TableHandler) UnbanTableUser(w http.ResponseWriter, r *http.Request) {
+	if h.tableBans == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Table bans are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	bannedID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	err = h.tableBans.Unban(r.Context(), userID, bannedID)
+	switch {
+	case errors.Is(err, services.ErrTableBanNotFound):
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to unban user")
+	default:
+		writeJSONResponse(w, http.StatusOK, map[string]string{
+			"message": "User unbanned",
+		})
+	}
+}
+
+// checkTableBan refuses a buy-in at a private table whose owner has banned
+// the user. It reports whether the buy-in may go ahead.
+func (h *TableHandler) checkTableBan(w http.ResponseWriter, r *http.Request, table *models.PokerTable, userID uuid.UUID) bool {
+	if h.tableBans == nil {
+		return true
+	}
+
+	banned, err := h.tableBans.IsBanned(r.Context(), table, userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to check table ban")
+		return false
+	}
+	if banned {
+		writeErrorResponse(w, http.StatusForbidden, "The host of this table has banned you from their games")
+		return false
+	}
+	return true
+}
@@ -2,6 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,33 +14,87 @@ import (
 	"github.com/anhbaysgalan1/gp/internal/database"
 	"github.com/anhbaysgalan1/gp/internal/formance"
 	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
+	"github.com/anhbaysgalan1/gp/internal/validation"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
+// tournamentTableSize is the number of seats per table when a tournament draws seats
+const tournamentTableSize = 9
+
+// defaultStartingChips is the starting stack when a tournament doesn't set one
+const defaultStartingChips = 10000
+
 type TournamentHandler struct {
 	db              *database.DB
 	formanceService *formance.Service
+	pushService     *services.PushService
+	director        *services.TournamentDirector
+	lastLonger      *services.LastLongerService
+	chips           *services.TournamentChipService
+	payouts         *services.TournamentPayoutService
+	featureFlags    *services.FeatureFlagService
+	statsEvents     statsevents.Publisher
 }
 
-func NewTournamentHandler(db *database.DB, formanceService *formance.Service) *TournamentHandler {
+func NewTournamentHandler(db *database.DB, formanceService *formance.Service, pushService *services.PushService) *TournamentHandler {
 	return &TournamentHandler{
 		db:              db,
 		formanceService: formanceService,
+		pushService:     pushService,
+		director:        services.NewTournamentDirector(db, tournamentTableSize),
+		lastLonger:      services.NewLastLongerService(db, formanceService),
+		chips:           services.NewTournamentChipService(db),
+		payouts:         services.NewTournamentPayoutService(db),
 	}
 }
 
-func (h *TournamentHandler) Routes() chi.Router {
+// SetFeatureFlags lets operators switch tournament registration off
+func (h *TournamentHandler) SetFeatureFlags(featureFlags *services.FeatureFlagService) {
+	h.featureFlags = featureFlags
+}
+
+// SetStatsEvents publishes finished tournaments for analytics consumers
+func (h *TournamentHandler) SetStatsEvents(publisher statsevents.Publisher) {
+	h.statsEvents = publisher
+}
+
+// SetTableMoveNotifier tells players when balancing moves them to another table
+func (h *TournamentHandler) SetTableMoveNotifier(notifier services.TableMoveNotifier) {
+	h.director.SetNotifier(notifier)
+}
+
+func (h *TournamentHandler) Routes(roleMiddleware *auth.RoleMiddleware) chi.Router {
 	r := chi.NewRouter()
 
 	r.Get("/", h.ListTournaments)
 	r.Post("/", h.CreateTournament)
 	r.Get("/{tournamentID}", h.GetTournament)
+	r.Get("/{tournamentID}/blind-structure", h.GetBlindStructure)
+	r.Get("/{tournamentID}/standings", h.GetStandings)
+	r.Get("/{tournamentID}/color-ups", h.ListColorUps)
+	r.Get("/{tournamentID}/tables", h.ListTables)
+	r.Get("/{tournamentID}/payouts/preview", h.PreviewPayouts)
 	r.Post("/{tournamentID}/register", h.RegisterForTournament)
 	r.Delete("/{tournamentID}/unregister", h.UnregisterFromTournament)
 	r.Get("/{tournamentID}/registrations", h.GetTournamentRegistrations)
 	r.Post("/{tournamentID}/start", h.StartTournament)
-	r.Post("/{tournamentID}/finish", h.FinishTournament)
+	r.Get("/{tournamentID}/last-longer", h.ListLastLongerPools)
+	r.Post("/{tournamentID}/last-longer", h.CreateLastLongerPool)
+	r.Post("/{tournamentID}/last-longer/{poolID}/join", h.JoinLastLongerPool)
+	r.Delete("/{tournamentID}/last-longer/{poolID}/leave", h.LeaveLastLongerPool)
+
+	// Directing a running tournament is for admins
+	r.Group(func(r chi.Router) {
+		r.Use(roleMiddleware.RequireAdmin)
+
+		r.Post("/{tournamentID}/advance-level", h.AdvanceLevel)
+		r.Post("/{tournamentID}/eliminate", h.EliminatePlayer)
+		r.Post("/{tournamentID}/move", h.MovePlayer)
+		r.Post("/{tournamentID}/finish", h.FinishTournament)
+	})
 
 	return r
 }
@@ -126,6 +184,11 @@ func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Requ
 		req.TournamentType = "sitng" // default to sit-n-go
 	}
 
+	if req.TournamentType == models.TournamentTypeSpin {
+		writeErrorResponse(w, http.StatusBadRequest, "Spins are opened from spin formats")
+		return
+	}
+
 	if req.BuyIn <= 0 {
 		writeErrorResponse(w, http.StatusBadRequest, "Buy-in amount must be positive")
 		return
@@ -148,13 +211,16 @@ func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	// Default blind structure for sit-n-go tournaments
+	// Default blind structure for sit-n-go tournaments, with a big blind ante
+	// from level 4 on to keep late stages moving
 	defaultBlindStructure := `[
 		{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 300},
 		{"level": 2, "small_blind": 50, "big_blind": 100, "duration": 300},
 		{"level": 3, "small_blind": 75, "big_blind": 150, "duration": 300},
-		{"level": 4, "small_blind": 100, "big_blind": 200, "duration": 300},
-		{"level": 5, "small_blind": 150, "big_blind": 300, "duration": 300}
+		{"level": 4, "small_blind": 100, "big_blind": 200, "ante": 200, "big_blind_ante": true, "duration": 300},
+		{"level": 5, "small_blind": 150, "big_blind": 300, "ante": 300, "big_blind_ante": true, "duration": 300},
+		{"level": 6, "small_blind": 200, "big_blind": 400, "ante": 400, "big_blind_ante": true, "duration": 300},
+		{"level": 7, "small_blind": 300, "big_blind": 600, "ante": 600, "big_blind_ante": true, "duration": 300}
 	]`
 
 	// Default payout structure (top 3 get paid)
@@ -170,11 +236,36 @@ func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Requ
 		blindStructure = json.RawMessage(defaultBlindStructure)
 	}
 
+	if _, err := models.ParseBlindStructure(blindStructure); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	payoutStructure := req.PayoutStructure
 	if len(payoutStructure) == 0 {
 		payoutStructure = json.RawMessage(defaultPayoutStructure)
 	}
 
+	if _, err := models.ParsePayoutStructure(payoutStructure); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// A payout model replaces the static structure once the field is known
+	var payoutModel json.RawMessage
+	if req.PayoutModel != nil {
+		if err := validation.Validate(req.PayoutModel); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		payoutModel, _ = json.Marshal(req.PayoutModel)
+	}
+
+	startingChips := req.StartingChips
+	if startingChips <= 0 {
+		startingChips = defaultStartingChips
+	}
+
 	// Create tournament
 	tournament := models.Tournament{
 		Name:            req.Name,
@@ -184,6 +275,8 @@ func (h *TournamentHandler) CreateTournament(w http.ResponseWriter, r *http.Requ
 		StartTime:       req.StartTime,
 		BlindStructure:  blindStructure,
 		PayoutStructure: payoutStructure,
+		PayoutModel:     payoutModel,
+		StartingChips:   startingChips,
 		Status:          "registering",
 	}
 
@@ -221,6 +314,250 @@ func (h *TournamentHandler) GetTournament(w http.ResponseWriter, r *http.Request
 	writeJSONResponse(w, http.StatusOK, tournament)
 }
 
+// blindLevelResponse is a blind level with the time it starts, counted from
+// the start of the tournament
+type blindLevelResponse struct {
+	models.BlindLevel
+	StartsAfter int `json:"starts_after"` // Seconds
+}
+
+// GetBlindStructure returns the blind levels of a tournament, including antes
+func (h *TournamentHandler) GetBlindStructure(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var tournament models.Tournament
+	if err := h.db.First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch tournament")
+		}
+		return
+	}
+
+	levels, err := models.ParseBlindStructure(tournament.BlindStructure)
+	if err != nil {
+		slog.Error("Stored blind structure is invalid", "tournament_id", tournament.ID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Tournament has an invalid blind structure")
+		return
+	}
+
+	response := make([]blindLevelResponse, len(levels))
+	startsAfter := 0
+	for i, level := range levels {
+		response[i] = blindLevelResponse{BlindLevel: level, StartsAfter: startsAfter}
+		startsAfter += level.Duration
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"tournament_id": tournament.ID,
+		"current_level": tournament.CurrentLevel,
+		"live_chips":    models.HasChipDenominations(levels),
+		"levels":        response,
+	})
+}
+
+// GetStandings returns the chip standings of a tournament, with the chip
+// denominations in play for live-style events
+func (h *TournamentHandler) GetStandings(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var tournament models.Tournament
+	if err := h.db.First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+		} else {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch tournament")
+		}
+		return
+	}
+
+	standings, err := h.chips.Standings(r.Context(), tournament.ID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch standings")
+		return
+	}
+
+	response := map[string]interface{}{
+		"tournament_id": tournament.ID,
+		"current_level": tournament.CurrentLevel,
+		"standings":     standings,
+	}
+	if levels, err := models.ParseBlindStructure(tournament.BlindStructure); err == nil &&
+		tournament.CurrentLevel > 0 && tournament.CurrentLevel <= len(levels) {
+		response["chip_denominations"] = levels[tournament.CurrentLevel-1].ChipDenominations
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// ListColorUps returns the color-ups and race-off results of a tournament
+func (h *TournamentHandler) ListColorUps(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	events, err := h.chips.ListColorUps(r.Context(), tournamentID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch color-ups")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"color_ups": events,
+	})
+}
+
+// AdvanceLevel moves a running tournament to its next blind level, coloring
+// up and racing off small chips when the new level removes them (admin only)
+func (h *TournamentHandler) AdvanceLevel(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	tournament, colorUp, err := h.chips.AdvanceLevel(r.Context(), tournamentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTournamentNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Tournament not found")
+		case errors.Is(err, services.ErrTournamentNotRunning), errors.Is(err, services.ErrFinalBlindLevel):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			slog.Error("Failed to advance blind level", "tournament_id", tournamentID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to advance blind level")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"message":       "Blind level advanced successfully",
+		"current_level": tournament.CurrentLevel,
+	}
+	if colorUp != nil {
+		response["color_up"] = colorUp
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// ListTables returns a running tournament's open tables and who sits where
+func (h *TournamentHandler) ListTables(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	tables, err := h.director.Tables(r.Context(), tournamentID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch tournament tables")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"tournament_id": tournamentID,
+		"tables":        tables,
+	})
+}
+
+// EliminatePlayer records a player busting out of a running tournament and
+// rebalances the tables they leave behind (admin only)
+func (h *TournamentHandler) EliminatePlayer(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var req models.EliminateTournamentPlayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	position, moves, err := h.director.EliminatePlayer(r.Context(), tournamentID, req.UserID)
+	if err != nil {
+		h.writeDirectorError(w, err, tournamentID, "Failed to eliminate player")
+		return
+	}
+	h.settleLastLonger(r.Context(), tournamentID)
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message":        "Player eliminated",
+		"final_position": position,
+		"moves":          moves,
+	})
+}
+
+// MovePlayer lets the tournament director move a player to another table
+// (admin only)
+func (h *TournamentHandler) MovePlayer(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	var req models.MoveTournamentPlayerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	move, err := h.director.MovePlayer(r.Context(), tournamentID, req)
+	if err != nil {
+		h.writeDirectorError(w, err, tournamentID, "Failed to move player")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"message": "Player moved",
+		"move":    move,
+	})
+}
+
+// writeDirectorError maps a failed elimination or move to a response
+func (h *TournamentHandler) writeDirectorError(w http.ResponseWriter, err error, tournamentID uuid.UUID, message string) {
+	switch {
+	case errors.Is(err, services.ErrTournamentNotFound), errors.Is(err, services.ErrNotInTournament),
+		errors.Is(err, services.ErrTournamentTableNotFound):
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTournamentNotRunning), errors.Is(err, services.ErrTournamentSeatOutOfRange):
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTournamentSeatTaken), errors.Is(err, services.ErrNoEmptySeat):
+		writeErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error(message, "tournament_id", tournamentID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, message)
+	}
+}
+
+// RegisterTournamentRequest optionally pays the buy-in from a cash session
+// the user is finishing instead of from the wallet
+type RegisterTournamentRequest struct {
+	FromTableID *uuid.UUID `json:"from_table_id,omitempty"`
+}
+
 // RegisterForTournament allows a user to register for a tournament
 func (h *TournamentHandler) RegisterForTournament(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserIDFromContext(r.Context())
@@ -229,6 +566,17 @@ func (h *TournamentHandler) RegisterForTournament(w http.ResponseWriter, r *http
 		return
 	}
 
+	// The body is optional
+	var req RegisterTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if !featureEnabled(w, r, h.featureFlags, models.FeatureTournamentRegistration, "Tournament registration is temporarily closed") {
+		return
+	}
+
 	tournamentIDStr := chi.URLParam(r, "tournamentID")
 	tournamentID, err := uuid.Parse(tournamentIDStr)
 	if err != nil {
@@ -247,6 +595,11 @@ func (h *TournamentHandler) RegisterForTournament(w http.ResponseWriter, r *http
 		return
 	}
 
+	if tournament.TournamentType == models.TournamentTypeSpin {
+		writeErrorResponse(w, http.StatusBadRequest, "Spins are joined from the spin queue")
+		return
+	}
+
 	// Check tournament status
 	if tournament.Status != "registering" {
 		writeErrorResponse(w, http.StatusBadRequest, "Tournament registration is closed")
@@ -271,11 +624,37 @@ func (h *TournamentHandler) RegisterForTournament(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Process buy-in payment
-	transactionID, err := h.formanceService.ProcessTournamentBuyIn(r.Context(), userID, tournamentID, tournament.BuyIn)
-	if err != nil {
-		writeErrorResponse(w, http.StatusBadRequest, err.Error())
-		return
+	// Process buy-in payment, straight from a finishing cash session when
+	// one is named, with the rest of its chips going back to the wallet
+	var fromSession *models.GameSession
+	var transactionID string
+	if req.FromTableID != nil {
+		var balance int64
+		fromSession, balance, err = finishingSession(r.Context(), h.db, h.formanceService, userID, *req.FromTableID)
+		if errors.Is(err, errNoFinishingSession) {
+			writeErrorResponse(w, http.StatusBadRequest, "User is not currently at the table being left")
+			return
+		}
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to find user session")
+			return
+		}
+		if balance < tournament.BuyIn {
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Session holds %d MNT, less than the buy-in", balance))
+			return
+		}
+		transactionID, err = h.formanceService.MoveSessionToTournament(r.Context(), userID, fromSession.ID, tournamentID, tournament.BuyIn, balance-tournament.BuyIn)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		fromSession.CurrentChips = balance
+	} else {
+		transactionID, err = h.formanceService.ProcessTournamentBuyIn(r.Context(), userID, tournamentID, tournament.BuyIn)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
 	// Create registration record
@@ -317,6 +696,14 @@ func (h *TournamentHandler) RegisterForTournament(w http.ResponseWriter, r *http
 		return
 	}
 
+	if fromSession != nil {
+		if err := closeMovedSession(tx, fromSession, fromSession.CurrentChips); err != nil {
+			tx.Rollback()
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to close cash session")
+			return
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to complete registration")
@@ -361,6 +748,11 @@ func (h *TournamentHandler) UnregisterFromTournament(w http.ResponseWriter, r *h
 		return
 	}
 
+	if tournament.TournamentType == models.TournamentTypeSpin {
+		writeErrorResponse(w, http.StatusBadRequest, "Spins are left from the spin queue")
+		return
+	}
+
 	// Check tournament status
 	if tournament.Status != "registering" {
 		writeErrorResponse(w, http.StatusBadRequest, "Cannot unregister from tournament that has started")
@@ -413,6 +805,10 @@ func (h *TournamentHandler) UnregisterFromTournament(w http.ResponseWriter, r *h
 
 	// TODO: Process refund through Formance service
 
+	if err := h.lastLonger.LeaveTournament(r.Context(), tournamentID, userID); err != nil {
+		slog.Error("Failed to refund last-longer stakes", "tournament_id", tournamentID, "user_id", userID, "error", err)
+	}
+
 	response := map[string]interface{}{
 		"message":       "Successfully unregistered from tournament",
 		"tournament_id": tournamentID,
@@ -483,6 +879,11 @@ func (h *TournamentHandler) StartTournament(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if tournament.TournamentType == models.TournamentTypeSpin {
+		writeErrorResponse(w, http.StatusBadRequest, "Spins start when they fill")
+		return
+	}
+
 	// Check if tournament can be started
 	if tournament.Status != "registering" {
 		writeErrorResponse(w, http.StatusBadRequest, "Tournament is not in registering state")
@@ -496,11 +897,34 @@ func (h *TournamentHandler) StartTournament(w http.ResponseWriter, r *http.Reque
 
 	// TODO: Add authorization check - only tournament organizers or admins should be able to start tournaments
 
+	// Draw random seats so players can't arrange to sit together, and open
+	// the tables the draw needs
+	tables, err := h.director.OpenTables(r.Context(), &tournament)
+	if err != nil {
+		slog.Error("Failed to open tournament tables", "tournament_id", tournament.ID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to open tournament tables")
+		return
+	}
+
+	if err := h.chips.SeedChips(r.Context(), tournament.ID, tournament.StartingChips); err != nil {
+		slog.Error("Failed to seed tournament chips", "tournament_id", tournament.ID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to seed tournament chips")
+		return
+	}
+
+	// Registration closes here, so the payouts can be sized to the field
+	if _, err := h.payouts.GenerateAtRegistrationClose(r.Context(), tournament.ID); err != nil {
+		slog.Error("Failed to generate tournament payouts", "tournament_id", tournament.ID, "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate tournament payouts")
+		return
+	}
+
 	// Start the tournament
 	now := time.Now()
 	updates := map[string]interface{}{
-		"status":     "running",
-		"start_time": now,
+		"status":        "running",
+		"start_time":    now,
+		"current_level": 1,
 	}
 
 	if err := h.db.Model(&tournament).Updates(updates).Error; err != nil {
@@ -511,15 +935,73 @@ func (h *TournamentHandler) StartTournament(w http.ResponseWriter, r *http.Reque
 	// Fetch updated tournament
 	h.db.First(&tournament, "id = ?", tournamentID)
 
+	// A pool nobody else joined goes back to its creator
+	h.settleLastLonger(r.Context(), tournament.ID)
+
+	h.notifyRegisteredPlayers(tournament, "Tournament starting", fmt.Sprintf("%s is starting now. Take your seat!", tournament.Name), "started")
+
 	response := map[string]interface{}{
 		"message":    "Tournament started successfully",
 		"tournament": tournament,
+		"tables":     tables,
 	}
 
 	writeJSONResponse(w, http.StatusOK, response)
 }
 
-// FinishTournament finishes a tournament and distributes prizes
+// notifyRegisteredPlayers sends a tournament push alert to every registered player
+func (h *TournamentHandler) notifyRegisteredPlayers(tournament models.Tournament, title, body, event string) {
+	notifyTournamentPlayers(h.db, h.pushService, tournament, title, body, event)
+}
+
+// notifyTournamentPlayers sends a tournament push alert to every registered
+// player, when push notifications are available
+func notifyTournamentPlayers(db *database.DB, pushService *services.PushService, tournament models.Tournament, title, body, event string) {
+	if pushService == nil {
+		return
+	}
+
+	var userIDs []uuid.UUID
+	if err := db.Model(&models.TournamentRegistration{}).
+		Where("tournament_id = ?", tournament.ID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		slog.Warn("Failed to load tournament registrations for push", "tournament_id", tournament.ID, "error", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		pushService.NotifyAsync(userID, models.PushEventTournament, services.PushNotification{
+			Title: title,
+			Body:  body,
+			Data: map[string]string{
+				"tournament_id": tournament.ID.String(),
+				"status":        event,
+			},
+		})
+	}
+}
+
+// PreviewPayouts is a dry run of paying out the tournament: the prize for
+// each place from the actual prize pool, and what the players eliminated so
+// far would be paid
+func (h *TournamentHandler) PreviewPayouts(w http.ResponseWriter, r *http.Request) {
+	tournamentID, err := uuid.Parse(chi.URLParam(r, "tournamentID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid tournament ID")
+		return
+	}
+
+	preview, err := h.payouts.PrizePreview(r.Context(), tournamentID)
+	if err != nil {
+		writePayoutError(w, err, "Failed to preview tournament payouts")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, preview)
+}
+
+// FinishTournament finishes a tournament and pays each player the prize for
+// their final position, worked out from the payout structure and prize pool
 func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Request) {
 	_, ok := auth.GetUserIDFromContext(r.Context())
 	if !ok {
@@ -535,11 +1017,7 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 	}
 
 	type FinishTournamentRequest struct {
-		Results []struct {
-			UserID      uuid.UUID `json:"user_id" validate:"required"`
-			Position    int       `json:"position" validate:"required,gt=0"`
-			PrizeAmount int64     `json:"prize_amount" validate:"gte=0"`
-		} `json:"results" validate:"required,min=1"`
+		Results []models.FinishingPosition `json:"results" validate:"required,min=1,dive"`
 	}
 
 	var req FinishTournamentRequest
@@ -547,6 +1025,10 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var tournament models.Tournament
 	if err := h.db.First(&tournament, "id = ?", tournamentID).Error; err != nil {
@@ -564,7 +1046,17 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// TODO: Add authorization check - only tournament organizers or game server should finish tournaments
+	// Spins pay what their draw set, which is saved as their payout structure
+	places, err := models.ParsePayoutStructure(tournament.PayoutStructure)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Invalid payout structure")
+		return
+	}
+	prizes, err := models.AwardPrizes(tournament.PrizePool, places, req.Results)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Begin transaction
 	tx := h.db.Begin()
@@ -574,10 +1066,10 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Update registrations with final positions and prize amounts
-	for _, result := range req.Results {
+	for _, result := range prizes {
 		updates := map[string]interface{}{
 			"final_position": result.Position,
-			"prize_amount":   result.PrizeAmount,
+			"prize_amount":   result.Prize,
 		}
 
 		if err := tx.Model(&models.TournamentRegistration{}).
@@ -589,8 +1081,8 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 		}
 
 		// Distribute prizes if amount > 0
-		if result.PrizeAmount > 0 {
-			if _, err := h.formanceService.DistributeTournamentPrize(r.Context(), result.UserID, tournamentID, result.PrizeAmount); err != nil {
+		if result.Prize > 0 {
+			if _, err := h.formanceService.DistributeTournamentPrize(r.Context(), result.UserID, tournamentID, result.Prize); err != nil {
 				tx.Rollback()
 				writeErrorResponse(w, http.StatusInternalServerError, "Failed to distribute prize")
 				return
@@ -617,12 +1109,31 @@ func (h *TournamentHandler) FinishTournament(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	h.settleLastLonger(r.Context(), tournamentID)
+
 	// Fetch updated tournament with registrations
 	h.db.Preload("TournamentRegistrations.User").First(&tournament, "id = ?", tournamentID)
 
+	finished := statsevents.TournamentFinished{
+		TournamentID: tournament.ID,
+		Name:         tournament.Name,
+		Entrants:     tournament.RegisteredPlayers,
+		PrizePool:    tournament.PrizePool,
+		Results:      make([]statsevents.TournamentPlace, 0, len(prizes)),
+	}
+	for _, result := range prizes {
+		finished.Results = append(finished.Results, statsevents.TournamentPlace{
+			UserID:   result.UserID,
+			Position: result.Position,
+			Prize:    result.Prize,
+		})
+	}
+	statsevents.Emit(h.statsEvents, statsevents.TypeTournamentFinished, finished)
+
 	response := map[string]interface{}{
 		"message":    "Tournament finished successfully",
 		"tournament": tournament,
+		"prizes":     prizes,
 	}
 
 	writeJSONResponse(w, http.StatusOK, response)
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UserHandler serves public player profile data
+type UserHandler struct {
+	tournamentStats *services.TournamentStatsService
+	usernames       *services.UsernameService
+	skillRatings    *services.SkillRatingService
+}
+
+func NewUserHandler(tournamentStats *services.TournamentStatsService) *UserHandler {
+	return &UserHandler{
+		tournamentStats: tournamentStats,
+	}
+}
+
+// SetUsernames enables resolving former usernames
+func (h *UserHandler) SetUsernames(usernames *services.UsernameService) {
+	h.usernames = usernames
+}
+
+// SetSkillRatings enables showing players' skill bands
+func (h *UserHandler) SetSkillRatings(skillRatings *services.SkillRatingService) {
+	h.skillRatings = skillRatings
+}
+
+func (h *UserHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/resolve", h.ResolveUsername)
+	r.Get("/{userID}/tournament-history", h.GetTournamentHistory)
+	r.Get("/{userID}/skill", h.GetSkill)
+
+	return r
+}
+
+// GetTournamentHistory returns a player's tournament statistics and a page of
+// their finished tournaments
+func (h *UserHandler) GetTournamentHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit := 20
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 && parsed <= 100 {
+		limit = parsed
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+
+	stats, err := h.tournamentStats.GetStats(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get tournament stats")
+		return
+	}
+
+	results, total, err := h.tournamentStats.GetHistory(r.Context(), userID, limit, offset)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get tournament history")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"stats":       stats,
+		"tournaments": results,
+		"pagination": map[string]interface{}{
+			"limit":  limit,
+			"offset": offset,
+			"total":  total,
+		},
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type UserSessionHandler struct {
+	userSessionService *services.UserSessionService
+}
+
+func NewUserSessionHandler(userSessionService *services.UserSessionService) *UserSessionHandler {
+	return &UserSessionHandler{
+		userSessionService: userSessionService,
+	}
+}
+
+func (h *UserSessionHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.ListSessions)
+	r.Get("/{sessionID}", h.GetSession)
+	r.Delete("/{sessionID}", h.CloseSession)
+
+	return r
+}
+
+// ListSessions returns the user's active game sessions with the chips each
+// one holds
+func (h *UserSessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.userSessionService.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// GetSession returns one of the user's active game sessions
+func (h *UserSessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	sessions, err := h.userSessionService.ListSessions(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	for _, session := range sessions {
+		if session.SessionID == sessionID {
+			writeJSONResponse(w, http.StatusOK, session)
+			return
+		}
+	}
+
+	writeErrorResponse(w, http.StatusNotFound, "Session not found")
+}
+
+// CloseSession ends one of the user's active game sessions and returns its
+// chips to their wallet. Refused while the user is in a hand at the table.
+func (h *UserSessionHandler) CloseSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	closed, err := h.userSessionService.CloseSession(r.Context(), userID, sessionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGameSessionNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "Session not found")
+		case errors.Is(err, services.ErrHandInProgress):
+			writeErrorResponse(w, http.StatusConflict, "You are in a hand at this table. Try again once it is over.")
+		default:
+			slog.Error("Failed to close game session", "user_id", userID, "session_id", sessionID, "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to close session")
+		}
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, closed)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+)
+
+// ChangeUsername renames the current user, keeping the old name in their
+// history and reserved for them, and returns a token carrying the new name
+func (h *AuthHandler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	if h.usernames == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Username changes are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req models.ChangeUsernameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := validation.Validate(&req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, nextChangeAt, err := h.usernames.ChangeUsername(r.Context(), userID, req.Username)
+	if err != nil {
+		var cooldownErr *services.UsernameCooldownError
+		switch {
+		case errors.As(err, &cooldownErr):
+			writeJSONResponse(w, http.StatusTooManyRequests, map[string]interface{}{
+				"error":          cooldownErr.Error(),
+				"next_change_at": cooldownErr.NextChangeAt,
+			})
+		case errors.Is(err, services.ErrUsernameUnchanged):
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrUsernameReserved):
+			writeErrorResponse(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrUserNotFound):
+			writeErrorResponse(w, http.StatusNotFound, "User not found")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to change username")
+		}
+		return
+	}
+
+	token, err := h.authService.IssueToken(user)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to issue token")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, models.ChangeUsernameResponse{
+		User:         *user,
+		Token:        token,
+		NextChangeAt: nextChangeAt,
+	})
+}
+
+// GetUsernameHistory returns the names the current user has given up and
+// when they may change their username again
+func (h *AuthHandler) GetUsernameHistory(w http.ResponseWriter, r *http.Request) {
+	if h.usernames == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Username changes are not available")
+		return
+	}
+
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	history, err := h.usernames.History(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get username history")
+		return
+	}
+
+	nextChangeAt, err := h.usernames.NextChangeAt(r.Context(), userID)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get username history")
+		return
+	}
+
+	response := map[string]interface{}{
+		"history": history,
+	}
+	if !nextChangeAt.IsZero() {
+		response["next_change_at"] = nextChangeAt
+	}
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// ResolveUsername finds the player behind a username, including names they
+// have since given up. The optional at parameter (RFC 3339) resolves the
+// name as it was then, e.g. the time of a hand or chat message.
+func (h *UserHandler) ResolveUsername(w http.ResponseWriter, r *http.Request) {
+	if h.usernames == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Username lookup is not available")
+		return
+	}
+
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Username is required")
+		return
+	}
+
+	var at time.Time
+	if raw := r.URL.Query().Get("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid time, expected RFC 3339")
+			return
+		}
+		at = parsed
+	}
+
+	resolution, err := h.usernames.Resolve(r.Context(), username, at)
+	if err != nil {
+		if errors.Is(err, services.ErrUsernameNotFound) {
+			writeErrorResponse(w, http.StatusNotFound, "Username not found")
+			return
+		}
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to resolve username")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, resolution)
+}
@@ -0,0 +1,154 @@
+package ledgertool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// ledgerIndex records which per-user and per-session transactions already exist
+type ledgerIndex struct {
+	wallets  map[string]bool // user ID -> has wallet_creation
+	buyIns   map[string]bool // session ID -> has game_buyin or was moved into
+	cashOuts map[string]bool // session ID -> has game_cashout or was moved out of
+}
+
+func (t *Tool) buildLedgerIndex(ctx context.Context) (*ledgerIndex, error) {
+	index := &ledgerIndex{
+		wallets:  make(map[string]bool),
+		buyIns:   make(map[string]bool),
+		cashOuts: make(map[string]bool),
+	}
+
+	err := t.eachTransaction(ctx, func(tx formance.TransactionData) error {
+		switch metadataString(tx.Metadata, "type") {
+		case "wallet_creation":
+			index.wallets[metadataString(tx.Metadata, "user_id")] = true
+		case "game_buyin":
+			index.buyIns[metadataString(tx.Metadata, "session_id")] = true
+		case "game_cashout":
+			index.cashOuts[metadataString(tx.Metadata, "session_id")] = true
+		case "session_transfer":
+			// A table change closes one session straight into the next
+			index.cashOuts[metadataString(tx.Metadata, "from_session_id")] = true
+			index.buyIns[metadataString(tx.Metadata, "session_id")] = true
+		case "tournament_buyin":
+			if fromSessionID := metadataString(tx.Metadata, "from_session_id"); fromSessionID != "" {
+				index.cashOuts[fromSessionID] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index ledger transactions: %w", err)
+	}
+	return index, nil
+}
+
+// Backfill posts ledger transactions for history recorded in the database but
+// missing from the ledger: user wallets, session buy-ins and, for sessions that
+// have ended, their cash-outs. Transactions are backdated to when the event
+// happened and carry a deterministic reference so re-running is safe.
+func (t *Tool) Backfill(ctx context.Context) (Report, error) {
+	report := Report{Operation: "backfill"}
+	if t.db == nil {
+		return report, fmt.Errorf("backfill requires a database connection")
+	}
+
+	index, err := t.buildLedgerIndex(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var users []models.User
+	if err := t.db.WithContext(ctx).Select("id", "created_at").Find(&users).Error; err != nil {
+		return report, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	for _, user := range users {
+		report.Scanned++
+		if index.wallets[user.ID.String()] {
+			report.Skipped++
+			continue
+		}
+
+		createdAt := user.CreatedAt
+		t.post(ctx, &report, "wallet "+user.ID.String(),
+			[]formance.PostingSimple{{Source: formance.WorldAccount, Destination: formance.PlayerWalletAccount(user.ID), Amount: 0, Asset: t.client.Currency()}},
+			map[string]string{"type": "wallet_creation", "user_id": user.ID.String(), "backfill": "true"},
+			formance.TransactionOptions{Reference: "backfill:wallet:" + user.ID.String(), Timestamp: &createdAt},
+		)
+	}
+
+	var sessions []models.GameSession
+	if err := t.db.WithContext(ctx).Order("joined_at ASC").Find(&sessions).Error; err != nil {
+		return report, fmt.Errorf("failed to load game sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		report.Scanned++
+		sessionID := session.ID.String()
+		sessionAccount := formance.SessionAccount(session.UserID, session.ID)
+		walletAccount := formance.PlayerWalletAccount(session.UserID)
+
+		if index.buyIns[sessionID] {
+			report.Skipped++
+		} else if session.BuyInAmount > 0 {
+			joinedAt := session.JoinedAt
+			t.post(ctx, &report, "buy-in "+sessionID,
+				[]formance.PostingSimple{{Source: walletAccount, Destination: sessionAccount, Amount: session.BuyInAmount, Asset: t.client.Currency()}},
+				sessionMetadata("game_buyin", session.UserID, session.ID),
+				formance.TransactionOptions{Reference: "backfill:buyin:" + sessionID, Timestamp: &joinedAt},
+			)
+		}
+
+		if session.IsActive() || session.LeftAt == nil {
+			continue
+		}
+
+		report.Scanned++
+		if index.cashOuts[sessionID] {
+			report.Skipped++
+			continue
+		}
+		if session.CurrentChips > 0 {
+			t.post(ctx, &report, "cash-out "+sessionID,
+				[]formance.PostingSimple{{Source: sessionAccount, Destination: walletAccount, Amount: session.CurrentChips, Asset: t.client.Currency()}},
+				sessionMetadata("game_cashout", session.UserID, session.ID),
+				formance.TransactionOptions{Reference: "backfill:cashout:" + sessionID, Timestamp: session.LeftAt},
+			)
+		}
+	}
+
+	return report, nil
+}
+
+func sessionMetadata(txType string, userID, sessionID uuid.UUID) map[string]string {
+	return map[string]string{
+		"type":       txType,
+		"user_id":    userID.String(),
+		"session_id": sessionID.String(),
+		"backfill":   "true",
+	}
+}
+
+// post creates a transaction when applying, or just reports it on a dry run
+func (t *Tool) post(ctx context.Context, report *Report, label string, postings []formance.PostingSimple, metadata map[string]string, opts formance.TransactionOptions) {
+	if !t.apply {
+		t.logf("would post %s (%s)", label, opts.Reference)
+		report.Changed++
+		return
+	}
+
+	txID, err := t.client.CreateTransactionWithOptions(ctx, postings, metadata, opts)
+	if err != nil {
+		t.logf("failed to post %s: %v", label, err)
+		report.Failed++
+		return
+	}
+	t.logf("posted %s as transaction %s", label, txID)
+	report.Changed++
+}
@@ -0,0 +1,84 @@
+package ledgertool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MetadataFix is one line of a metadata replay file
+type MetadataFix struct {
+	TransactionID int64             `json:"transaction_id"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// ReplayMetadata applies metadata fixes read as JSON lines from r. Fixes whose
+// values are already present on the transaction are skipped, so a partially
+// applied file can simply be replayed.
+func (t *Tool) ReplayMetadata(ctx context.Context, r io.Reader) (Report, error) {
+	report := Report{Operation: "replay-metadata"}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		report.Scanned++
+
+		var fix MetadataFix
+		if err := json.Unmarshal([]byte(text), &fix); err != nil {
+			t.logf("line %d: invalid fix: %v", line, err)
+			report.Failed++
+			continue
+		}
+		if len(fix.Metadata) == 0 {
+			report.Skipped++
+			continue
+		}
+
+		tx, err := t.client.GetTransaction(ctx, fix.TransactionID)
+		if err != nil {
+			t.logf("line %d: failed to get transaction %d: %v", line, fix.TransactionID, err)
+			report.Failed++
+			continue
+		}
+
+		pending := make(map[string]string)
+		for key, value := range fix.Metadata {
+			if metadataString(tx.Metadata, key) != value {
+				pending[key] = value
+			}
+		}
+		if len(pending) == 0 {
+			report.Skipped++
+			continue
+		}
+
+		if !t.apply {
+			t.logf("would set %v on transaction %d", pending, fix.TransactionID)
+			report.Changed++
+			continue
+		}
+
+		if err := t.client.AddTransactionMetadata(ctx, fix.TransactionID, pending); err != nil {
+			t.logf("line %d: failed to update transaction %d: %v", line, fix.TransactionID, err)
+			report.Failed++
+			continue
+		}
+		t.logf("updated transaction %d", fix.TransactionID)
+		report.Changed++
+	}
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("failed to read metadata fixes: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,158 @@
+package ledgertool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+)
+
+// placeholderPattern matches {name} placeholders in an account template
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// AccountTemplate describes an account naming scheme such as
+// "session:{user}:{session}". Placeholders match one address segment.
+type AccountTemplate struct {
+	raw    string
+	names  []string
+	regexp *regexp.Regexp
+}
+
+// ParseAccountTemplate compiles an account naming template
+func ParseAccountTemplate(template string) (*AccountTemplate, error) {
+	if template == "" {
+		return nil, fmt.Errorf("account template is empty")
+	}
+
+	var names []string
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		name := template[loc[2]:loc[3]]
+		for _, existing := range names {
+			if existing == name {
+				return nil, fmt.Errorf("placeholder {%s} appears more than once in %q", name, template)
+			}
+		}
+		names = append(names, name)
+		pattern.WriteString(`([^:]+)`)
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("account template %q has no placeholders", template)
+	}
+
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile account template %q: %w", template, err)
+	}
+
+	return &AccountTemplate{raw: template, names: names, regexp: compiled}, nil
+}
+
+// Match extracts placeholder values from an account address
+func (at *AccountTemplate) Match(address string) (map[string]string, bool) {
+	groups := at.regexp.FindStringSubmatch(address)
+	if groups == nil {
+		return nil, false
+	}
+
+	values := make(map[string]string, len(at.names))
+	for i, name := range at.names {
+		values[name] = groups[i+1]
+	}
+	return values, true
+}
+
+// Render builds an account address from placeholder values
+func (at *AccountTemplate) Render(values map[string]string) (string, error) {
+	var missing string
+	rendered := placeholderPattern.ReplaceAllStringFunc(at.raw, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := values[name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("no value for placeholder {%s} in %q", missing, at.raw)
+	}
+	return rendered, nil
+}
+
+// MigrateAccounts moves the balance of every account matching from to the
+// account produced by rendering to with the same placeholder values. Every
+// placeholder in to must also appear in from.
+func (t *Tool) MigrateAccounts(ctx context.Context, from, to *AccountTemplate) (Report, error) {
+	report := Report{Operation: "migrate-accounts"}
+
+	for _, name := range to.names {
+		found := false
+		for _, fromName := range from.names {
+			if fromName == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return report, fmt.Errorf("placeholder {%s} in target template is not in source template", name)
+		}
+	}
+
+	asset := t.client.Currency()
+	err := t.eachAccount(ctx, func(account formance.AccountData) error {
+		values, ok := from.Match(account.Address)
+		if !ok {
+			return nil
+		}
+		report.Scanned++
+
+		target, err := to.Render(values)
+		if err != nil {
+			return err
+		}
+		if target == account.Address {
+			report.Skipped++
+			return nil
+		}
+
+		balance := account.Balance(asset)
+		if balance <= 0 {
+			report.Skipped++
+			return nil
+		}
+
+		metadata := map[string]string{
+			"type":         "account_migration",
+			"from_account": account.Address,
+			"to_account":   target,
+		}
+		if userID, ok := values["user"]; ok {
+			metadata["user_id"] = userID
+		}
+		if sessionID, ok := values["session"]; ok {
+			metadata["session_id"] = sessionID
+		}
+
+		t.post(ctx, &report, account.Address+" -> "+target,
+			[]formance.PostingSimple{{Source: account.Address, Destination: target, Amount: balance, Asset: asset}},
+			metadata,
+			formance.TransactionOptions{Reference: "migrate:" + account.Address + "->" + target},
+		)
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to migrate accounts: %w", err)
+	}
+
+	return report, nil
+}
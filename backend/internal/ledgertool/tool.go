@@ -0,0 +1,105 @@
+// Package ledgertool holds maintenance operations for the Formance ledger:
+// backfilling history, migrating account names, replaying metadata fixes and
+// verifying invariants. Every operation is a dry run unless Apply is set.
+package ledgertool
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"gorm.io/gorm"
+)
+
+// pageSize is the number of transactions or accounts fetched per request
+const pageSize = 100
+
+// Tool runs ledger maintenance operations
+type Tool struct {
+	client *formance.Client
+	db     *gorm.DB
+	apply  bool
+	out    io.Writer
+}
+
+// New creates a tool. db may be nil for operations that only touch the ledger.
+// Without apply, operations only report what they would change.
+func New(client *formance.Client, db *gorm.DB, apply bool, out io.Writer) *Tool {
+	return &Tool{
+		client: client,
+		db:     db,
+		apply:  apply,
+		out:    out,
+	}
+}
+
+// Report summarises the outcome of an operation
+type Report struct {
+	Operation string
+	Scanned   int
+	Changed   int
+	Skipped   int
+	Failed    int
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%s: scanned=%d changed=%d skipped=%d failed=%d", r.Operation, r.Scanned, r.Changed, r.Skipped, r.Failed)
+}
+
+// logf writes a progress line, prefixed so dry runs are obvious in the output
+func (t *Tool) logf(format string, args ...interface{}) {
+	prefix := "[dry-run] "
+	if t.apply {
+		prefix = ""
+	}
+	fmt.Fprintf(t.out, prefix+format+"\n", args...)
+}
+
+// eachTransaction calls fn for every transaction in the ledger
+func (t *Tool) eachTransaction(ctx context.Context, fn func(tx formance.TransactionData) error) error {
+	cursor := ""
+	for {
+		page, err := t.client.ListTransactions(ctx, pageSize, cursor)
+		if err != nil {
+			return err
+		}
+		for _, tx := range page.Transactions {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore || page.Next == "" {
+			return nil
+		}
+		cursor = page.Next
+	}
+}
+
+// eachAccount calls fn for every account in the ledger
+func (t *Tool) eachAccount(ctx context.Context, fn func(account formance.AccountData) error) error {
+	cursor := ""
+	for {
+		page, err := t.client.ListAccounts(ctx, pageSize, cursor)
+		if err != nil {
+			return err
+		}
+		for _, account := range page.Accounts {
+			if err := fn(account); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore || page.Next == "" {
+			return nil
+		}
+		cursor = page.Next
+	}
+}
+
+// metadataString reads a metadata value as a string
+func metadataString(metadata map[string]interface{}, key string) string {
+	if v, ok := metadata[key].(string); ok {
+		return v
+	}
+	return ""
+}
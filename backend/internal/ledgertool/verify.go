@@ -0,0 +1,121 @@
+package ledgertool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// Finding is a single invariant the ledger does not satisfy
+type Finding struct {
+	Invariant string `json:"invariant"`
+	Account   string `json:"account,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+func (f Finding) String() string {
+	if f.Account == "" {
+		return fmt.Sprintf("%s: %s", f.Invariant, f.Detail)
+	}
+	return fmt.Sprintf("%s [%s]: %s", f.Invariant, f.Account, f.Detail)
+}
+
+// knownAccountPrefixes are the address prefixes the server writes to
+var knownAccountPrefixes = []string{
+	formance.PlayerAccountPrefix + ":",
+	formance.SessionAccountPrefix + ":",
+	formance.SystemAccountPrefix + ":",
+	"revenue:",
+}
+
+// Verify checks ledger invariants:
+//   - balances across all accounts sum to zero for every asset
+//   - player wallets and session accounts are never negative
+//   - every account follows a known naming scheme
+//   - when a database is available, ended sessions hold no chips and active
+//     sessions have a ledger account
+func (t *Tool) Verify(ctx context.Context) ([]Finding, error) {
+	var findings []Finding
+	totals := make(map[string]int64)
+	sessionBalances := make(map[string]int64)
+	asset := t.client.Currency()
+
+	err := t.eachAccount(ctx, func(account formance.AccountData) error {
+		for a, volume := range account.Volumes {
+			totals[a] += volume.Balance
+		}
+
+		address := account.Address
+		if address == formance.WorldAccount {
+			return nil
+		}
+		if !hasKnownPrefix(address) {
+			findings = append(findings, Finding{Invariant: "account_format", Account: address, Detail: "address does not match a known naming scheme"})
+			return nil
+		}
+
+		balance := account.Balance(asset)
+		isPlayer := strings.HasPrefix(address, formance.PlayerAccountPrefix+":")
+		isSession := strings.HasPrefix(address, formance.SessionAccountPrefix+":")
+		if (isPlayer || isSession) && balance < 0 {
+			findings = append(findings, Finding{Invariant: "negative_balance", Account: address, Detail: fmt.Sprintf("balance is %d %s", balance, asset)})
+		}
+		if isSession {
+			sessionBalances[address] = balance
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan accounts: %w", err)
+	}
+
+	assets := make([]string, 0, len(totals))
+	for a := range totals {
+		assets = append(assets, a)
+	}
+	sort.Strings(assets)
+	for _, a := range assets {
+		if totals[a] != 0 {
+			findings = append(findings, Finding{Invariant: "zero_sum", Detail: fmt.Sprintf("%s balances sum to %d", a, totals[a])})
+		}
+	}
+
+	if t.db == nil {
+		return findings, nil
+	}
+
+	var sessions []models.GameSession
+	if err := t.db.WithContext(ctx).Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load game sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		address := formance.SessionAccount(session.UserID, session.ID)
+		balance, exists := sessionBalances[address]
+
+		if session.IsActive() {
+			if !exists && session.BuyInAmount > 0 {
+				findings = append(findings, Finding{Invariant: "active_session_account", Account: address, Detail: "active session has no ledger account"})
+			}
+			continue
+		}
+		if balance != 0 {
+			findings = append(findings, Finding{Invariant: "ended_session_balance", Account: address, Detail: fmt.Sprintf("%s session still holds %d %s", session.Status, balance, asset)})
+		}
+	}
+
+	return findings, nil
+}
+
+func hasKnownPrefix(address string) bool {
+	for _, prefix := range knownAccountPrefixes {
+		if strings.HasPrefix(address, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+// Package logging keeps secrets and card data out of the server's logs, so
+// they can be shipped to a central log store and kept for incident forensics.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the value of a sensitive attribute
+const Redacted = "[REDACTED]"
+
+// Attribute keys whose values are always redacted, compared lowercased with
+// '_' and '-' removed. Keys ending in one of sensitiveSuffixes are redacted
+// too, e.g. "new_password" or "refresh_token".
+var (
+	sensitiveKeys = map[string]bool{
+		"authorization": true,
+		"cookie":        true,
+		"setcookie":     true,
+		"ticket":        true,
+		"pan":           true,
+		"cvv":           true,
+		"cvc":           true,
+		"otp":           true,
+	}
+	sensitiveSuffixes = []string{"password", "passcode", "secret", "token", "apikey", "cardnumber"}
+)
+
+// cardNumberPattern finds runs of 13 to 19 digits, optionally grouped by
+// spaces or dashes, that could be a card number
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// IsSensitiveKey reports whether values logged or sent under key must be
+// redacted
+func IsSensitiveKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	if sensitiveKeys[normalized] {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(normalized, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString masks card numbers in free text such as error messages,
+// keeping the last four digits
+func RedactString(s string) string {
+	return cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		if !luhnValid(digits) {
+			return match
+		}
+		return "****" + digits[len(digits)-4:]
+	})
+}
+
+// luhnValid reports whether digits pass the card number checksum, so order
+// and ledger IDs that happen to be long numbers are left alone
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// RedactURL returns the URL's path and query with sensitive query
+// parameters, such as WebSocket tokens, redacted
+func RedactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	query := u.Query()
+	for key := range query {
+		if IsSensitiveKey(key) {
+			query[key] = []string{Redacted}
+		}
+	}
+	return u.Path + "?" + query.Encode()
+}
+
+// redactingHandler redacts attributes before passing records on
+type redactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps a handler so sensitive attributes are redacted
+// and card numbers are masked in messages and string values
+func NewRedactingHandler(next slog.Handler) slog.Handler {
+	return &redactingHandler{next: next}
+}
+
+// NewLogger returns a logger writing "json" or "text" records to w, with
+// redaction applied
+func NewLogger(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(NewRedactingHandler(handler))
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, RedactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, member := range group {
+			redacted[i] = redactAttr(member)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		// Errors and other values are logged by their text
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, RedactString(err.Error()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
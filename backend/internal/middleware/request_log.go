@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/logging"
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// CorrelationIDHeader carries a request's correlation ID back to the client,
+// so a report from a player can be matched to the server's logs
+const CorrelationIDHeader = "X-Request-Id"
+
+type requestLogKey struct{}
+
+// requestLog collects what inner handlers learn about a request, such as
+// who made it, for the entry written when it completes
+type requestLog struct {
+	mu     sync.Mutex
+	userID uuid.UUID
+}
+
+// RequestLogger writes one structured entry per request with its
+// correlation ID, user, route, status and latency. It runs after chi's
+// RequestID middleware, whose ID becomes the correlation ID. Query
+// parameters such as WebSocket tokens are redacted.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			correlationID := chimiddleware.GetReqID(r.Context())
+			if correlationID != "" {
+				w.Header().Set(CorrelationIDHeader, correlationID)
+			}
+
+			entry := &requestLog{}
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry)))
+
+			// A WebSocket upgrade hijacks the connection before a status is
+			// written through the wrapper
+			status := ww.Status()
+			if status == 0 && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				status = http.StatusSwitchingProtocols
+			} else if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			attrs := []slog.Attr{
+				slog.String("correlation_id", correlationID),
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", logging.RedactURL(r.URL)),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			entry.mu.Lock()
+			if entry.userID != uuid.Nil {
+				attrs = append(attrs, slog.String("user_id", entry.userID.String()))
+			}
+			entry.mu.Unlock()
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "HTTP request", attrs...)
+		})
+	}
+}
+
+// SetLogUser records the user making the request in its log entry, for
+// handlers that authenticate requests themselves
+func SetLogUser(ctx context.Context, userID uuid.UUID) {
+	if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		entry.mu.Lock()
+		entry.userID = userID
+		entry.mu.Unlock()
+	}
+}
+
+// LogUser records the authenticated user in the request's log entry. It runs
+// after the auth middleware.
+func LogUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := auth.GetUserIDFromContext(r.Context()); ok {
+			SetLogUser(r.Context(), userID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
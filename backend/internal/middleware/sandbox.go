@@ -0,0 +1,15 @@
+package middleware
+
+import "net/http"
+
+// SandboxHeader marks every response from a server running in sandbox mode,
+// where balances are play money in an in-memory ledger
+const SandboxHeader = "X-Sandbox"
+
+// Sandbox labels responses as coming from a sandbox server
+func Sandbox(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(SandboxHeader, "true")
+		next.ServeHTTP(w, r)
+	})
+}
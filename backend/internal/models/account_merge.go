@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account merge states
+const (
+	AccountMergePending   = "pending"
+	AccountMergeCompleted = "completed"
+	AccountMergeFailed    = "failed"
+)
+
+// AccountMerge is the audit record of folding a duplicate registration into
+// the account the player keeps. The counts say what was moved.
+type AccountMerge struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	DuplicateID       uuid.UUID  `json:"duplicate_id" gorm:"type:uuid;not null;index"`
+	SurvivorID        uuid.UUID  `json:"survivor_id" gorm:"type:uuid;not null;index"`
+	AdminID           uuid.UUID  `json:"admin_id" gorm:"type:uuid;not null"`
+	Reason            string     `json:"reason" gorm:"not null;size:500"`
+	Status            string     `json:"status" gorm:"not null;size:20;default:pending"` // 'pending', 'completed', 'failed'
+	WalletAmount      int64      `json:"wallet_amount" gorm:"default:0"`                 // MNT
+	PlayChips         int64      `json:"play_chips" gorm:"default:0"`
+	TransactionID     string     `json:"transaction_id,omitempty" gorm:"size:255"`
+	Sessions          int64      `json:"sessions" gorm:"default:0"`
+	HandHistories     int64      `json:"hand_histories" gorm:"default:0"`
+	TournamentEntries int64      `json:"tournament_entries" gorm:"default:0"`
+	Notes             int64      `json:"notes" gorm:"default:0"`
+	Error             string     `json:"error,omitempty" gorm:"size:500"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// UserNote is a support staff note on a player's account
+type UserNote struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	AuthorID  uuid.UUID `json:"author_id" gorm:"type:uuid;not null"`
+	Body      string    `json:"body" gorm:"not null;size:2000"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type MergeAccountsRequest struct {
+	DuplicateID uuid.UUID `json:"duplicate_id" validate:"required"`
+	Reason      string    `json:"reason" validate:"required,min=10,max=500"` // Ticket reference and how the duplicate was confirmed
+}
+
+type CreateUserNoteRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=2000"`
+}
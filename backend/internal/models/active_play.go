@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LiveSeat is a user's seat at a running table, as the table sees it
+type LiveSeat struct {
+	TableID        uuid.UUID
+	TableName      string
+	SeatNumber     int
+	Stack          int64
+	SmallBlind     int64
+	BigBlind       int64
+	Ante           int64
+	HandInProgress bool
+	ToAct          bool
+}
+
+// ActiveTable is a cash table where the user has a seat. Live tables come
+// with a ticket for reconnecting straight into them; tables lost in a
+// restart have none and their chips are returned on the next login.
+type ActiveTable struct {
+	TableID         uuid.UUID  `json:"table_id"`
+	TableName       string     `json:"table_name"`
+	SessionID       *uuid.UUID `json:"session_id,omitempty"` // Nil for practice seats
+	SeatNumber      *int       `json:"seat_number,omitempty"`
+	Stack           int64      `json:"stack"`       // MNT
+	SmallBlind      int64      `json:"small_blind"` // MNT
+	BigBlind        int64      `json:"big_blind"`   // MNT
+	Ante            int64      `json:"ante"`        // MNT
+	HandInProgress  bool       `json:"hand_in_progress"`
+	ToAct           bool       `json:"to_act"`
+	Live            bool       `json:"live"`
+	ReconnectTicket string     `json:"reconnect_ticket,omitempty"`
+	TicketExpiresAt *time.Time `json:"ticket_expires_at,omitempty"`
+}
+
+// ActiveTournament is a tournament the user is registered for or still
+// playing. Blinds are those of the current level once it is running.
+type ActiveTournament struct {
+	TournamentID uuid.UUID  `json:"tournament_id"`
+	Name         string     `json:"name"`
+	Status       string     `json:"status"`
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	Chips        int64      `json:"chips"` // Tournament chips
+	TableNumber  *int       `json:"table_number,omitempty"`
+	SeatNumber   *int       `json:"seat_number,omitempty"`
+	Level        int        `json:"level"`
+	SmallBlind   int64      `json:"small_blind"`
+	BigBlind     int64      `json:"big_blind"`
+	Ante         int64      `json:"ante"`
+}
+
+// ActivePlay is everything a user is playing, for resuming on another device
+type ActivePlay struct {
+	Tables      []ActiveTable      `json:"tables"`
+	Tournaments []ActiveTournament `json:"tournaments"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReferralCode is the code an affiliate shares to attribute new sign-ups
+type ReferralCode struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User      User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Code      string         `json:"code" gorm:"not null;size:20;uniqueIndex"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Referral attributes a registered player to the affiliate whose code they
+// signed up with. A player is only ever referred once.
+type Referral struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AffiliateID    uuid.UUID      `json:"affiliate_id" gorm:"type:uuid;not null;index"`
+	Affiliate      User           `json:"-" gorm:"foreignKey:AffiliateID;constraint:OnDelete:CASCADE"`
+	ReferredUserID uuid.UUID      `json:"referred_user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	ReferredUser   User           `json:"-" gorm:"foreignKey:ReferredUserID;constraint:OnDelete:CASCADE"`
+	Code           string         `json:"code" gorm:"not null;size:20"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// AffiliatePayout records a periodic revenue share payment to an affiliate
+type AffiliatePayout struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AffiliateID   uuid.UUID      `json:"affiliate_id" gorm:"type:uuid;not null;index"`
+	Affiliate     User           `json:"-" gorm:"foreignKey:AffiliateID;constraint:OnDelete:CASCADE"`
+	Percentage    float64        `json:"percentage" gorm:"not null"`
+	RakeAmount    int64          `json:"rake_amount" gorm:"not null"`   // MNT raked from referred players
+	PayoutAmount  int64          `json:"payout_amount" gorm:"not null"` // MNT
+	PeriodEnd     time.Time      `json:"period_end" gorm:"not null"`
+	TransactionID string         `json:"transaction_id" gorm:"size:100"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ReferredPlayer is one sign-up attributed to an affiliate, with the rake
+// they have generated
+type ReferredPlayer struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	ReferredAt   time.Time `json:"referred_at"`
+	LifetimeRake int64     `json:"lifetime_rake"` // MNT
+	PendingRake  int64     `json:"pending_rake"`  // MNT not yet shared
+}
+
+// AffiliateDashboard summarises an affiliate's code, sign-ups and earnings
+type AffiliateDashboard struct {
+	Code               string  `json:"code"`
+	RevenueShare       float64 `json:"revenue_share"`
+	ReferredPlayers    int64   `json:"referred_players"`
+	ActivePlayers      int64   `json:"active_players"`      // Referred players who paid rake in the last 30 days
+	PendingRake        int64   `json:"pending_rake"`        // MNT
+	PendingCommission  int64   `json:"pending_commission"`  // MNT expected at the next payout
+	LifetimeCommission int64   `json:"lifetime_commission"` // MNT
+}
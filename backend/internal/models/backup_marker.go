@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How a backup marker was taken
+const (
+	BackupMarkerScheduled = "scheduled"
+	BackupMarkerManual    = "manual"
+)
+
+// BackupMarker records a moment when no ledger write was in flight, with the
+// ledger's latest log ID and each table's latest hand. After a restore, the
+// newest marker the database holds is where it and Formance last agreed:
+// ledger logs after LedgerLogID and later hands are what needs reconciling.
+type BackupMarker struct {
+	ID           uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Trigger      string          `json:"trigger" gorm:"not null;size:20"` // 'scheduled', 'manual'
+	RequestedBy  *uuid.UUID      `json:"requested_by,omitempty" gorm:"type:uuid"`
+	Ledger       string          `json:"ledger" gorm:"not null;size:100"`
+	LedgerLogID  *int64          `json:"ledger_log_id,omitempty"`           // Nil while the ledger has no logs
+	Tables       json.RawMessage `json:"tables" gorm:"type:jsonb;not null"` // []BackupTableMarker
+	QuiescedMs   int64           `json:"quiesced_ms"`                       // How long ledger writes were held
+	ManifestPath string          `json:"manifest_path,omitempty" gorm:"size:500"`
+	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// BackupTableMarker is a table's latest hand when a marker was taken
+type BackupTableMarker struct {
+	TableName string `json:"table_name"`
+	HandID    string `json:"hand_id"`
+	Sequence  int64  `json:"sequence"`
+	Ended     bool   `json:"ended"` // False if the hand was still being played
+}
+
+// BackupManifest is written next to the database backups for each marker, so
+// a restore can be reconciled even when the restored database predates it
+type BackupManifest struct {
+	MarkerID    uuid.UUID           `json:"marker_id"`
+	TakenAt     time.Time           `json:"taken_at"`
+	Trigger     string              `json:"trigger"`
+	Database    string              `json:"database"`
+	Ledger      string              `json:"ledger"`
+	LedgerLogID *int64              `json:"ledger_log_id,omitempty"`
+	QuiescedMs  int64               `json:"quiesced_ms"`
+	Tables      []BackupTableMarker `json:"tables"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bank statement row states
+const (
+	StatementRowCredited = "credited"
+	StatementRowInReview = "in_review"
+	StatementRowRejected = "rejected"
+)
+
+// How a statement row was matched to the user it was credited to
+const (
+	StatementMatchAuto   = "auto"
+	StatementMatchManual = "manual"
+)
+
+// DepositReference is the code a player writes in the payment details of a
+// bank transfer so the deposit can be matched to their wallet. Like a wallet
+// address it never changes, and its last character is a check character so
+// a mistyped code doesn't credit someone else.
+type DepositReference struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Code      string    `json:"code" gorm:"not null;size:20;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BankStatementRow is an incoming transfer from the bank statement. Rows
+// matched to a deposit reference are credited straight away; the rest wait
+// in the review queue for finance staff to assign or reject.
+type BankStatementRow struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BankTransactionID string     `json:"bank_transaction_id" gorm:"not null;size:100;uniqueIndex"` // The bank's ID, so re-imported statements are skipped
+	Amount            int64      `json:"amount" gorm:"not null"`                                   // MNT
+	Description       string     `json:"description" gorm:"size:500"`                              // Payment details the reference is looked for in
+	PayerName         string     `json:"payer_name" gorm:"size:200"`
+	PostedAt          time.Time  `json:"posted_at" gorm:"not null"`
+	Status            string     `json:"status" gorm:"not null;size:20;index"` // 'credited', 'in_review', 'rejected'
+	ReviewReason      string     `json:"review_reason,omitempty" gorm:"size:200"`
+	Reference         string     `json:"reference,omitempty" gorm:"size:20"`
+	UserID            *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	MatchedBy         string     `json:"matched_by,omitempty" gorm:"size:10"` // 'auto', 'manual'
+	TransactionID     string     `json:"transaction_id,omitempty" gorm:"size:255"`
+	ImportedBy        uuid.UUID  `json:"imported_by" gorm:"type:uuid;not null"`
+	ReviewedBy        *uuid.UUID `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewNote        string     `json:"review_note,omitempty" gorm:"size:500"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BankStatementImport summarises what happened to the rows of an imported
+// statement
+type BankStatementImport struct {
+	Imported   int                `json:"imported"`
+	Credited   int                `json:"credited"`
+	InReview   int                `json:"in_review"`
+	Duplicates int                `json:"duplicates"` // Rows already imported from an earlier statement
+	Rows       []BankStatementRow `json:"rows"`
+}
+
+type BankStatementRowInput struct {
+	BankTransactionID string    `json:"bank_transaction_id" validate:"required,max=100"`
+	Amount            int64     `json:"amount" validate:"required,gt=0"` // Incoming transfers only
+	Description       string    `json:"description" validate:"max=500"`
+	PayerName         string    `json:"payer_name" validate:"max=200"`
+	PostedAt          time.Time `json:"posted_at" validate:"required"`
+}
+
+type ImportBankStatementRequest struct {
+	Rows []BankStatementRowInput `json:"rows" validate:"required,min=1,max=1000,dive"`
+}
+
+type AssignStatementRowRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Note   string    `json:"note" validate:"required,min=3,max=500"` // How the payer was identified
+}
+
+type RejectStatementRowRequest struct {
+	Note string `json:"note" validate:"required,min=3,max=500"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BankrollSettings are a user's jurisdiction and their own limits on
+// rebuying after losing a full stack. A user's limits only ever tighten
+// their jurisdiction's.
+type BankrollSettings struct {
+	UserID               uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	User                 User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Jurisdiction         string    `json:"jurisdiction" gorm:"size:10"` // Set by admins, empty for the platform default
+	RebuyCooldownMinutes *int      `json:"rebuy_cooldown_minutes"`      // Nil follows the jurisdiction
+	RebuyWarnPercent     *int      `json:"rebuy_warn_percent"`          // Nil follows the jurisdiction
+	UpdatedAt            time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// RebuyLimits are the limits applied when a player buys back in at a table
+// where they lost a full stack. Zero disables a limit.
+type RebuyLimits struct {
+	CooldownMinutes int `json:"cooldown_minutes"`
+	WarnPercent     int `json:"warn_percent"` // Warn when a rebuy is more than this share of the wallet
+}
+
+// BankrollSummary is a user's jurisdiction, its rebuy limits, the user's own
+// and the stricter of the two, which is what is enforced
+type BankrollSummary struct {
+	Jurisdiction         string      `json:"jurisdiction"`
+	JurisdictionLimits   RebuyLimits `json:"jurisdiction_limits"`
+	RebuyCooldownMinutes *int        `json:"rebuy_cooldown_minutes"`
+	RebuyWarnPercent     *int        `json:"rebuy_warn_percent"`
+	Effective            RebuyLimits `json:"effective"`
+}
+
+// UpdateBankrollSettingsRequest replaces a user's own rebuy limits. Omitted
+// limits follow the jurisdiction.
+type UpdateBankrollSettingsRequest struct {
+	RebuyCooldownMinutes *int `json:"rebuy_cooldown_minutes" validate:"omitempty,min=0,max=10080"`
+	RebuyWarnPercent     *int `json:"rebuy_warn_percent" validate:"omitempty,min=1,max=100"`
+}
+
+type UpdateJurisdictionRequest struct {
+	Jurisdiction string `json:"jurisdiction" validate:"required,max=10"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// BlindLevel is one level of a tournament blind structure. Ante is posted by
+// every player unless BigBlindAnte is set, in which case the big blind posts
+// it once for the table. Live-style events list the chip denominations in
+// play at each level; purely numeric tournaments leave them empty.
+type BlindLevel struct {
+	Level             int     `json:"level"`
+	SmallBlind        int64   `json:"small_blind"` // MNT
+	BigBlind          int64   `json:"big_blind"`   // MNT
+	Ante              int64   `json:"ante"`        // MNT
+	BigBlindAnte      bool    `json:"big_blind_ante"`
+	Duration          int     `json:"duration"` // Seconds
+	ChipDenominations []int64 `json:"chip_denominations,omitempty"`
+}
+
+// ErrEmptyBlindStructure is returned for a blind structure without levels
+var ErrEmptyBlindStructure = errors.New("blind structure must have at least one level")
+
+// ParseBlindStructure decodes and validates a tournament blind structure.
+// Levels must be numbered in order, blinds must not decrease and a big blind
+// ante may not exceed the big blind.
+func ParseBlindStructure(raw json.RawMessage) ([]BlindLevel, error) {
+	var levels []BlindLevel
+	if err := json.Unmarshal(raw, &levels); err != nil {
+		return nil, fmt.Errorf("invalid blind structure: %w", err)
+	}
+	if len(levels) == 0 {
+		return nil, ErrEmptyBlindStructure
+	}
+
+	for i, level := range levels {
+		switch {
+		case level.Level != i+1:
+			return nil, fmt.Errorf("blind level %d is out of order, expected level %d", level.Level, i+1)
+		case level.BigBlind <= 0 || level.SmallBlind <= 0:
+			return nil, fmt.Errorf("blind level %d must have positive blinds", level.Level)
+		case level.SmallBlind > level.BigBlind:
+			return nil, fmt.Errorf("blind level %d has a small blind larger than the big blind", level.Level)
+		case level.Ante < 0:
+			return nil, fmt.Errorf("blind level %d has a negative ante", level.Level)
+		case level.BigBlindAnte && level.Ante > level.BigBlind:
+			return nil, fmt.Errorf("blind level %d has a big blind ante larger than the big blind", level.Level)
+		case level.Duration <= 0:
+			return nil, fmt.Errorf("blind level %d must have a positive duration", level.Level)
+		case i > 0 && level.BigBlind < levels[i-1].BigBlind:
+			return nil, fmt.Errorf("blind level %d has a lower big blind than level %d", level.Level, levels[i-1].Level)
+		}
+	}
+
+	if err := validateDenominations(levels); err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// HasChipDenominations reports whether a blind structure tracks physical chips
+func HasChipDenominations(levels []BlindLevel) bool {
+	return len(levels) > 0 && len(levels[0].ChipDenominations) > 0
+}
+
+// ColorUpAt returns the denominations removed when the given level starts and
+// the smallest denomination left in play. Nothing is removed at the first
+// level or in numeric tournaments.
+func ColorUpAt(levels []BlindLevel, level int) (removed []int64, smallest int64) {
+	if level < 2 || level > len(levels) || !HasChipDenominations(levels) {
+		return nil, 0
+	}
+
+	current := levels[level-1].ChipDenominations
+	inPlay := make(map[int64]bool, len(current))
+	for _, d := range current {
+		inPlay[d] = true
+	}
+	for _, d := range levels[level-2].ChipDenominations {
+		if !inPlay[d] {
+			removed = append(removed, d)
+		}
+	}
+
+	return removed, current[0]
+}
+
+// validateDenominations checks that chip denominations, when used, are set on
+// every level, ascending, can post the blinds and ante, and are only ever
+// removed from the bottom so a color-up always trades small chips for larger ones
+func validateDenominations(levels []BlindLevel) error {
+	live := HasChipDenominations(levels)
+
+	for i, level := range levels {
+		denominations := level.ChipDenominations
+		if !live {
+			if len(denominations) > 0 {
+				return fmt.Errorf("blind level %d has chip denominations but level 1 does not", level.Level)
+			}
+			continue
+		}
+		if len(denominations) == 0 {
+			return fmt.Errorf("blind level %d is missing chip denominations", level.Level)
+		}
+
+		for j, d := range denominations {
+			if d <= 0 || (j > 0 && d <= denominations[j-1]) {
+				return fmt.Errorf("blind level %d chip denominations must be positive and ascending", level.Level)
+			}
+			if d%denominations[0] != 0 {
+				return fmt.Errorf("blind level %d chip denomination %d is not a multiple of %d", level.Level, d, denominations[0])
+			}
+		}
+
+		smallest := denominations[0]
+		if level.SmallBlind%smallest != 0 || level.BigBlind%smallest != 0 || level.Ante%smallest != 0 {
+			return fmt.Errorf("blind level %d blinds and ante must be multiples of the smallest chip (%d)", level.Level, smallest)
+		}
+
+		if i > 0 {
+			removed, _ := ColorUpAt(levels, i+1)
+			for _, d := range removed {
+				if d >= smallest {
+					return fmt.Errorf("blind level %d removes chip %d, but only chips smaller than %d can be colored up", level.Level, d, smallest)
+				}
+			}
+		}
+	}
+
+	return nil
+}
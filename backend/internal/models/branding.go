@@ -0,0 +1,19 @@
+package models
+
+// Branding dresses a table or tournament for a sponsor or partner: the lobby
+// and detail views show it without anything hardcoded in the frontend
+type Branding struct {
+	BannerURL   string `json:"banner_url,omitempty" gorm:"size:500"`
+	SponsorName string `json:"sponsor_name,omitempty" gorm:"size:100"`
+	AccentColor string `json:"accent_color,omitempty" gorm:"size:7"`   // "#rrggbb"
+	Description string `json:"description,omitempty" gorm:"type:text"` // Markdown, rendered without raw HTML
+}
+
+// UpdateBrandingRequest replaces a table's or tournament's branding. Fields
+// left empty are cleared.
+type UpdateBrandingRequest struct {
+	BannerURL   string `json:"banner_url" validate:"omitempty,url,max=500"`
+	SponsorName string `json:"sponsor_name" validate:"max=100"`
+	AccentColor string `json:"accent_color" validate:"omitempty,hexcolor"`
+	Description string `json:"description" validate:"max=5000"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Cluster instance statuses
+const (
+	ClusterInstanceHealthy  = "healthy"
+	ClusterInstanceDegraded = "degraded" // Reporting, but with stalled tables
+	ClusterInstanceStale    = "stale"    // Heartbeat overdue, probably gone
+)
+
+// ClusterTable is a table running on a game server instance
+type ClusterTable struct {
+	Name        string `json:"name"`
+	Clients     int    `json:"clients"`
+	Seated      int    `json:"seated"`
+	HandRunning bool   `json:"hand_running"`
+	Stalled     bool   `json:"stalled"`
+	ReadOnly    bool   `json:"read_only"`
+	LeaseOwner  string `json:"lease_owner"` // Instance holding the table's ownership lease, empty if none
+}
+
+// ClusterInstance is a game server instance as of its last heartbeat
+type ClusterInstance struct {
+	ID                 string         `json:"id"`
+	Status             string         `json:"status"`
+	StartedAt          time.Time      `json:"started_at"`
+	LastSeen           time.Time      `json:"last_seen"`
+	Clients            int64          `json:"clients"`
+	StalledTables      int            `json:"stalled_tables"`
+	SendQueueOverflows int64          `json:"send_queue_overflows"`
+	Tables             []ClusterTable `json:"tables"`
+}
+
+// ClusterStatus is every instance that reported recently and the table
+// ownership leases between them
+type ClusterStatus struct {
+	Instance  string            `json:"instance"` // Instance that answered
+	Instances []ClusterInstance `json:"instances"`
+	Leases    map[string]string `json:"leases"`   // Table name to owning instance
+	Orphaned  []string          `json:"orphaned"` // Tables leased to instances that are stale or gone
+}
+
+type ReassignTableRequest struct {
+	Table      string `json:"table" validate:"required,max=100"`
+	InstanceID string `json:"instance_id" validate:"required,max=100"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ColorUpEvent records a color-up at the start of a tournament level: the
+// small denominations taken out of play and the race-off for odd chips
+type ColorUpEvent struct {
+	ID                   uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TournamentID         uuid.UUID       `json:"tournament_id" gorm:"type:uuid;not null;uniqueIndex:idx_color_up_level"`
+	Level                int             `json:"level" gorm:"not null;uniqueIndex:idx_color_up_level"`
+	RemovedDenominations json.RawMessage `json:"removed_denominations" gorm:"type:jsonb"` // []int64
+	NewSmallest          int64           `json:"new_smallest"`
+	ChipsRaced           int             `json:"chips_raced"`               // Chips of NewSmallest won in race-offs
+	Results              json.RawMessage `json:"results" gorm:"type:jsonb"` // []ColorUpResult
+	CreatedAt            time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ColorUpResult is one player's part in a color-up. OddChips is the value of
+// the removed chips that could not be exchanged directly and went to the race.
+type ColorUpResult struct {
+	UserID      uuid.UUID `json:"user_id"`
+	TableNumber int       `json:"table_number"`
+	SeatNumber  int       `json:"seat_number"`
+	StackBefore int64     `json:"stack_before"`
+	OddChips    int64     `json:"odd_chips"`
+	Cards       []string  `json:"cards,omitempty"`
+	WonRace     bool      `json:"won_race"`
+	Protected   bool      `json:"protected"` // Given a chip so the race could not eliminate them
+	StackAfter  int64     `json:"stack_after"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DirectMessage is a private message between two players, separate from table chat
+type DirectMessage struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SenderID    uuid.UUID      `json:"sender_id" gorm:"type:uuid;not null;index:idx_dm_pair"`
+	Sender      User           `json:"-" gorm:"foreignKey:SenderID;constraint:OnDelete:CASCADE"`
+	RecipientID uuid.UUID      `json:"recipient_id" gorm:"type:uuid;not null;index:idx_dm_pair;index"`
+	Recipient   User           `json:"-" gorm:"foreignKey:RecipientID;constraint:OnDelete:CASCADE"`
+	Body        string         `json:"body" gorm:"not null;size:1000"`
+	ReadAt      *time.Time     `json:"read_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// UserBlock stops the blocked user from sending direct messages to the blocker
+type UserBlock struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_block_pair"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_block_pair;index"`
+	Blocked   User      `json:"blocked,omitempty" gorm:"foreignKey:BlockedID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Message report statuses
+const (
+	MessageReportOpen      = "open"
+	MessageReportDismissed = "dismissed"
+	MessageReportActioned  = "actioned"
+)
+
+// MessageReport flags a direct message for moderator review
+type MessageReport struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	MessageID  uuid.UUID     `json:"message_id" gorm:"type:uuid;not null;index"`
+	Message    DirectMessage `json:"message,omitempty" gorm:"foreignKey:MessageID;constraint:OnDelete:CASCADE"`
+	ReporterID uuid.UUID     `json:"reporter_id" gorm:"type:uuid;not null;index"`
+	Reason     string        `json:"reason" gorm:"not null;size:500"`
+	Status     string        `json:"status" gorm:"not null;size:20;default:open;index"` // 'open', 'dismissed', 'actioned'
+	ReviewedBy *uuid.UUID    `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt *time.Time    `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Conversation summarises the latest message exchanged with another player
+type Conversation struct {
+	UserID      uuid.UUID     `json:"user_id"`
+	Username    string        `json:"username"`
+	LastMessage DirectMessage `json:"last_message"`
+	UnreadCount int64         `json:"unread_count"`
+}
+
+type ReportMessageRequest struct {
+	MessageID uuid.UUID `json:"message_id" validate:"required"`
+	Reason    string    `json:"reason" validate:"required,min=3,max=500"`
+}
+
+type ReviewMessageReportRequest struct {
+	Status string `json:"status" validate:"required,oneof=dismissed actioned"`
+}
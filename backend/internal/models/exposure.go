@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a user's maximum exposure comes from
+const (
+	ExposureLimitKYCTier  = "kyc_tier"
+	ExposureLimitRole     = "role"
+	ExposureLimitOverride = "override"
+)
+
+// ExposureOverride replaces a user's maximum exposure, the MNT they may have
+// in game sessions at once, until it expires or is revoked
+type ExposureOverride struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User        User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	MaxExposure int64      `json:"max_exposure" gorm:"not null"` // MNT, 0 for no limit
+	Reason      string     `json:"reason" gorm:"not null;size:500"`
+	GrantedBy   uuid.UUID  `json:"granted_by" gorm:"type:uuid;not null"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Nil until revoked
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ExposureSummary is how much a user has in game sessions and how much they
+// may have
+type ExposureSummary struct {
+	Exposure       int64      `json:"exposure"`     // MNT in active sessions
+	MaxExposure    int64      `json:"max_exposure"` // MNT, 0 for no limit
+	Source         string     `json:"source"`       // 'kyc_tier', 'role', 'override'
+	ActiveSessions int        `json:"active_sessions"`
+	OverrideUntil  *time.Time `json:"override_until,omitempty"`
+}
+
+type GrantExposureOverrideRequest struct {
+	MaxExposure int64  `json:"max_exposure" validate:"min=0"`
+	Hours       int    `json:"hours" validate:"omitempty,min=1,max=8760"` // Omit to keep it until revoked
+	Reason      string `json:"reason" validate:"required,max=500"`
+}
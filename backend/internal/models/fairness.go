@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FairnessReport holds the card tallies dealt at one table on one UTC day.
+// Statistics are derived from the tally when the report is read, so tallies
+// can be summed across tables and days.
+type FairnessReport struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TableName string          `json:"table_name" gorm:"not null;size:100;uniqueIndex:idx_fairness_table_day"`
+	Day       time.Time       `json:"day" gorm:"not null;type:date;uniqueIndex:idx_fairness_table_day;index"`
+	Hands     int64           `json:"hands" gorm:"not null;default:0"`
+	Flagged   bool            `json:"flagged" gorm:"not null;default:false"`
+	Tally     json.RawMessage `json:"tally" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Feature flags operators can switch off during an incident. Every flag is
+// on until an admin turns it off.
+const (
+	FeatureRegistrations          = "registrations"           // New account sign-ups
+	FeatureWithdrawals            = "withdrawals"             // Wallet withdrawals
+	FeatureTournamentRegistration = "tournament_registration" // Registering for tournaments
+)
+
+// FeatureFlags lists the flags the server knows about
+var FeatureFlags = []string{
+	FeatureRegistrations,
+	FeatureWithdrawals,
+	FeatureTournamentRegistration,
+}
+
+// FeatureFlag is an operator switch. Flags without a row are enabled.
+type FeatureFlag struct {
+	Key       string     `json:"key" gorm:"primaryKey;size:100"`
+	Enabled   bool       `json:"enabled" gorm:"not null;default:true"`
+	Reason    string     `json:"reason,omitempty" gorm:"size:200"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type SetFeatureFlagRequest struct {
+	Enabled *bool  `json:"enabled" validate:"required"`
+	Reason  string `json:"reason" validate:"max=200"`
+}
@@ -34,6 +34,15 @@ type GameSession struct {
 	Table PokerTable  `json:"table,omitempty" gorm:"foreignKey:TableID"`
 }
 
+// RecoveredSession describes an orphaned session whose chips were returned to
+// the wallet on login
+type RecoveredSession struct {
+	SessionID     uuid.UUID `json:"session_id"`
+	TableID       uuid.UUID `json:"table_id"`
+	Amount        int64     `json:"amount"` // MNT
+	TransactionID string    `json:"transaction_id,omitempty"`
+}
+
 // BeforeCreate sets the ID if not already set
 func (gs *GameSession) BeforeCreate(tx *gorm.DB) error {
 	if gs.ID == uuid.Nil {
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How a moderator settled a disputed hand
+const (
+	HandRulingAward = "award" // The pot went to the players named
+	HandRulingVoid  = "void"  // Every bet was returned, as in a misdeal
+)
+
+// HandAdjudication is the audit record of a moderator settling a disputed
+// hand that was frozen in place
+type HandAdjudication struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	HandID      string          `json:"hand_id" gorm:"not null;size:50;index"`
+	TableName   string          `json:"table_name" gorm:"not null;size:100;index"`
+	ModeratorID uuid.UUID       `json:"moderator_id" gorm:"type:uuid;not null;index"`
+	Outcome     string          `json:"outcome" gorm:"not null;size:20"` // 'award', 'void'
+	Reason      string          `json:"reason" gorm:"not null;size:500"`
+	TotalPot    int64           `json:"total_pot" gorm:"default:0"`
+	Payouts     json.RawMessage `json:"payouts" gorm:"type:jsonb"` // []HandPayout: chips won, or refunded when void
+	FrozenAt    time.Time       `json:"frozen_at"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// HandPayout is the chips one player got from a ruling
+type HandPayout struct {
+	Position uint      `json:"position"`
+	UserID   uuid.UUID `json:"user_id,omitempty"`
+	Username string    `json:"username"`
+	Amount   int64     `json:"amount"`
+}
+
+// HandRuling is what players see in the history of a hand a moderator
+// settled
+type HandRuling struct {
+	Outcome string    `json:"outcome"`
+	Reason  string    `json:"reason"`
+	RuledAt time.Time `json:"ruled_at"`
+}
+
+// DisputedHand is the full state of a table's hand, every hole card
+// included, as a moderator inspecting it sees it
+type DisputedHand struct {
+	Table        string             `json:"table"`
+	HandID       string             `json:"hand_id"`
+	Running      bool               `json:"running"`
+	Frozen       bool               `json:"frozen"`
+	FrozenReason string             `json:"frozen_reason,omitempty"`
+	FrozenAt     *time.Time         `json:"frozen_at,omitempty"`
+	Stage        string             `json:"stage"`
+	ActionOn     *uint              `json:"action_on,omitempty"` // Position waiting to act
+	Board        []string           `json:"board"`
+	Pot          int64              `json:"pot"`
+	Players      []DisputedHandSeat `json:"players"`
+}
+
+// DisputedHandSeat is one player's part in a disputed hand
+type DisputedHandSeat struct {
+	Position uint      `json:"position"` // Used to name the winners of an award
+	SeatID   uint      `json:"seat_id"`
+	UserID   uuid.UUID `json:"user_id,omitempty"`
+	Username string    `json:"username"`
+	Cards    []string  `json:"cards,omitempty"`
+	In       bool      `json:"in"`
+	Stack    int64     `json:"stack"`
+	Bet      int64     `json:"bet"`
+	TotalBet int64     `json:"total_bet"` // Antes included
+}
+
+type FreezeHandRequest struct {
+	Table  string `json:"table" validate:"required,max=100"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ResumeHandRequest struct {
+	Table string `json:"table" validate:"required,max=100"`
+}
+
+type AdjudicateHandRequest struct {
+	Table     string `json:"table" validate:"required,max=100"`
+	Outcome   string `json:"outcome" validate:"required,oneof=award void"`
+	Positions []uint `json:"positions" validate:"omitempty,max=10"` // Winners of an award
+	Reason    string `json:"reason" validate:"required,min=10,max=500"`
+}
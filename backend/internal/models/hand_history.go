@@ -0,0 +1,280 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HandHistory is a summary row for a single dealt hand. HandID is the
+// human-readable correlation ID shared with chat, logs and ledger metadata.
+type HandHistory struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	HandID    string          `json:"hand_id" gorm:"not null;size:50;uniqueIndex"`
+	TableID   uuid.UUID       `json:"table_id" gorm:"type:uuid;index"`
+	TableName string          `json:"table_name" gorm:"not null;size:100;index"`
+	Sequence  int64           `json:"sequence" gorm:"not null"`
+	Variant   string          `json:"variant,omitempty" gorm:"size:20"` // Empty for Hold'em
+	StartedAt time.Time       `json:"started_at" gorm:"not null"`
+	EndedAt   *time.Time      `json:"ended_at,omitempty"`
+	TotalPot  int64           `json:"total_pot" gorm:"default:0"` // MNT
+	Rake      int64           `json:"rake" gorm:"default:0"`      // MNT taken by the house before the winners were paid
+	Winners   json.RawMessage `json:"winners,omitempty" gorm:"type:jsonb"`
+	// Every dealt card, including folded hands, as []HandPlayerCards and []string.
+	// Never served directly; see HandHistoryView for what a viewer may see.
+	HoleCards json.RawMessage `json:"-" gorm:"type:jsonb"`
+	Board     json.RawMessage `json:"-" gorm:"type:jsonb"`
+	// HandRuling, when a moderator settled the hand after a dispute
+	Ruling json.RawMessage `json:"ruling,omitempty" gorm:"type:jsonb"`
+	// []HandEquityStreet, worked out the first time the hand is viewed. An
+	// empty list means the hand had no showdown to graph.
+	Equities  json.RawMessage `json:"-" gorm:"type:jsonb"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// HandWinner records a single pot award within a hand
+type HandWinner struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Username      string    `json:"username"`
+	Amount        int64     `json:"amount"` // MNT
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Half          string    `json:"half,omitempty"` // Split games: "high", "low" or "high_low"
+}
+
+// HandPlayerCards is one dealt-in player's hole cards. Shown is set when the
+// cards were turned over at showdown.
+type HandPlayerCards struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	SeatID   uint      `json:"seat_id"`
+	Cards    []string  `json:"cards,omitempty"`
+	Shown    bool      `json:"shown"`
+	// MNT the player put into the pot, blinds and antes included. Hands
+	// recorded before wagers were kept have none.
+	Wagered int64 `json:"wagered,omitempty"`
+}
+
+// HandEquityStreet is each showdown player's chance of winning the pot as
+// the board stood after a street, for drawing a win-probability timeline
+type HandEquityStreet struct {
+	Street   string           `json:"street"` // "preflop", "flop", "turn" or "river"
+	Board    []string         `json:"board"`
+	Equities []HandSeatEquity `json:"equities"`
+}
+
+// HandSeatEquity is one player's share of the pot on a street, by seat so
+// it reveals no more than the seat's hole cards shown at showdown
+type HandSeatEquity struct {
+	SeatID uint    `json:"seat_id"`
+	Equity float64 `json:"equity"` // 0 to 1
+}
+
+// HandPresence records that a user was at the table, seated or watching,
+// while a hand was dealt. It decides who may read the hand's history.
+type HandPresence struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	HandID    string    `json:"hand_id" gorm:"not null;size:50;uniqueIndex:idx_hand_presence_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_hand_presence_user;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// HandHistoryView is a hand as one viewer may see it: their own hole cards
+// and those shown at showdown, everyone else's hidden
+type HandHistoryView struct {
+	HandID    string             `json:"hand_id"`
+	TableName string             `json:"table_name"`
+	StartedAt time.Time          `json:"started_at"`
+	EndedAt   *time.Time         `json:"ended_at,omitempty"`
+	TotalPot  int64              `json:"total_pot"` // MNT
+	Rake      int64              `json:"rake"`      // MNT
+	Board     []string           `json:"board"`
+	Players   []HandPlayerCards  `json:"players"`
+	Winners   []HandWinner       `json:"winners"`
+	Ruling    *HandRuling        `json:"ruling,omitempty"`
+	Equity    []HandEquityStreet `json:"equity,omitempty"`
+}
+
+// Who may see a player by name in the histories of hands they were dealt into
+const (
+	HandVisibilityTable   = "table"   // Everyone who was at the table
+	HandVisibilityPlayers = "players" // Only the other players dealt in, not spectators
+	HandVisibilitySelf    = "self"    // Nobody; others see the seat instead
+)
+
+// HandPrivacySettings are a player's own hand history privacy choices, on top
+// of the room's policy. Players without a row use DefaultHandPrivacySettings.
+type HandPrivacySettings struct {
+	UserID      uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	StoreMucked bool      `json:"store_mucked"`                                     // Keep my hole cards when I fold or muck
+	Visibility  string    `json:"visibility" gorm:"not null;size:20;default:table"` // 'table', 'players', 'self'
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultHandPrivacySettings leave everything to the room's policy
+func DefaultHandPrivacySettings(userID uuid.UUID) HandPrivacySettings {
+	return HandPrivacySettings{UserID: userID, StoreMucked: true, Visibility: HandVisibilityTable}
+}
+
+// VisibleTo reports whether a viewer sees the player by name, depending on
+// whether the viewer was dealt into the hand or only watched it
+func (s HandPrivacySettings) VisibleTo(viewerDealtIn bool) bool {
+	switch s.Visibility {
+	case HandVisibilitySelf:
+		return false
+	case HandVisibilityPlayers:
+		return viewerDealtIn
+	default:
+		return true
+	}
+}
+
+type UpdateHandPrivacyRequest struct {
+	StoreMucked *bool   `json:"store_mucked,omitempty"`
+	Visibility  *string `json:"visibility,omitempty" validate:"omitempty,oneof=table players self"`
+}
+
+// HandViewPolicy is what a viewer may see of the other players in a hand
+type HandViewPolicy struct {
+	RevealMucked bool               // Show stored cards that were not shown down
+	Anonymous    map[uuid.UUID]bool // Players the viewer only sees by seat
+}
+
+// SeatLabel is how an anonymous player appears in a hand history
+func SeatLabel(seatID uint) string {
+	return fmt.Sprintf("Seat %d", seatID)
+}
+
+// DealtPlayers returns the recorded hole cards of every dealt-in player.
+// Stored JSON that fails to decode is treated as no players.
+func (h HandHistory) DealtPlayers() []HandPlayerCards {
+	players := []HandPlayerCards{}
+	if len(h.HoleCards) > 0 {
+		if err := json.Unmarshal(h.HoleCards, &players); err != nil {
+			return []HandPlayerCards{}
+		}
+	}
+	return players
+}
+
+// PotWinners returns every pot award in the hand. Stored JSON that fails to
+// decode is treated as no winners.
+func (h HandHistory) PotWinners() []HandWinner {
+	winners := []HandWinner{}
+	if len(h.Winners) > 0 {
+		if err := json.Unmarshal(h.Winners, &winners); err != nil {
+			return []HandWinner{}
+		}
+	}
+	return winners
+}
+
+// ViewFor redacts the hole cards the viewer never saw at the table
+func (h HandHistory) ViewFor(viewerID uuid.UUID) HandHistoryView {
+	return h.ViewWith(viewerID, HandViewPolicy{})
+}
+
+// ViewWith builds the hand as the viewer may see it under a policy. The
+// viewer always sees their own cards; anonymous players lose their name, ID
+// and any cards they did not show. Stored JSON that fails to decode is left
+// out rather than failing the whole view.
+func (h HandHistory) ViewWith(viewerID uuid.UUID, policy HandViewPolicy) HandHistoryView {
+	view := HandHistoryView{
+		HandID:    h.HandID,
+		TableName: h.TableName,
+		StartedAt: h.StartedAt,
+		EndedAt:   h.EndedAt,
+		TotalPot:  h.TotalPot,
+		Rake:      h.Rake,
+		Board:     []string{},
+		Players:   h.DealtPlayers(),
+		Winners:   []HandWinner{},
+	}
+
+	if len(h.Board) > 0 {
+		_ = json.Unmarshal(h.Board, &view.Board)
+	}
+	if len(h.Winners) > 0 {
+		_ = json.Unmarshal(h.Winners, &view.Winners)
+	}
+	if len(h.Ruling) > 0 {
+		var ruling HandRuling
+		if json.Unmarshal(h.Ruling, &ruling) == nil {
+			view.Ruling = &ruling
+		}
+	}
+	if len(h.Equities) > 0 {
+		_ = json.Unmarshal(h.Equities, &view.Equity)
+	}
+
+	seats := make(map[uuid.UUID]uint, len(view.Players))
+	for i := range view.Players {
+		p := &view.Players[i]
+		seats[p.UserID] = p.SeatID
+		if p.UserID == viewerID {
+			continue
+		}
+		anonymous := policy.Anonymous[p.UserID]
+		if !p.Shown && (!policy.RevealMucked || anonymous) {
+			p.Cards = nil
+		}
+		if anonymous {
+			p.UserID = uuid.Nil
+			p.Username = SeatLabel(p.SeatID)
+		}
+	}
+
+	for i := range view.Winners {
+		w := &view.Winners[i]
+		if w.UserID != viewerID && policy.Anonymous[w.UserID] {
+			w.Username = SeatLabel(seats[w.UserID])
+			w.UserID = uuid.Nil
+			w.TransactionID = ""
+		}
+	}
+	return view
+}
+
+// ReassignUser moves a player's recorded cards and pot awards to another
+// account under its username. Reports whether the hand changed.
+func (h *HandHistory) ReassignUser(from, to uuid.UUID, username string) (bool, error) {
+	players := h.DealtPlayers()
+	playersChanged := false
+	for i := range players {
+		if players[i].UserID == from {
+			players[i].UserID, players[i].Username = to, username
+			playersChanged = true
+		}
+	}
+
+	var winners []HandWinner
+	if len(h.Winners) > 0 {
+		if err := json.Unmarshal(h.Winners, &winners); err != nil {
+			return false, fmt.Errorf("failed to decode winners: %w", err)
+		}
+	}
+	winnersChanged := false
+	for i := range winners {
+		if winners[i].UserID == from {
+			winners[i].UserID, winners[i].Username = to, username
+			winnersChanged = true
+		}
+	}
+
+	var err error
+	if playersChanged {
+		if h.HoleCards, err = json.Marshal(players); err != nil {
+			return false, err
+		}
+	}
+	if winnersChanged {
+		if h.Winners, err = json.Marshal(winners); err != nil {
+			return false, err
+		}
+	}
+	return playersChanged || winnersChanged, nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// IdentityProvider is an external service players can sign in with
+type IdentityProvider string
+
+const (
+	IdentityProviderGoogle IdentityProvider = "google"
+	IdentityProviderApple  IdentityProvider = "apple"
+)
+
+// UserIdentity links an account to a sign-in with an identity provider. A
+// provider's account is linked to at most one of ours.
+type UserIdentity struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       User             `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Provider   IdentityProvider `json:"provider" gorm:"type:varchar(20);not null;uniqueIndex:idx_user_identity_subject"`
+	Subject    string           `json:"-" gorm:"not null;size:255;uniqueIndex:idx_user_identity_subject"` // The provider's ID for the account
+	Email      string           `json:"email" gorm:"size:255"`                                            // As the provider gave it when linked
+	LastUsedAt time.Time        `json:"last_used_at"`
+	CreatedAt  time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	DeletedAt  gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// OAuthState is a sign-in started with a provider and not finished yet. Only
+// a hash of the state is stored, and it works once before ExpiresAt. The
+// PKCE code verifier never leaves the server.
+type OAuthState struct {
+	ID           uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	StateHash    string           `json:"-" gorm:"uniqueIndex;not null;size:64"` // Hex SHA-256 of the state
+	Provider     IdentityProvider `json:"provider" gorm:"type:varchar(20);not null"`
+	CodeVerifier string           `json:"-" gorm:"not null;size:128"`
+	ExpiresAt    time.Time        `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time        `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// OAuthStartResponse is where to send the player to sign in with a
+// provider. The frontend keeps state and checks the provider sends it back.
+type OAuthStartResponse struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// OAuthSignInRequest carries the authorization code a provider sent the
+// player back with, and the state it came back with
+type OAuthSignInRequest struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=128"`
+}
+
+// OAuthSignup is returned instead of a login when nobody has signed in with
+// the provider's account before and its email matches no account. The
+// player picks a username and sends it back with SignupToken to finish.
+type OAuthSignup struct {
+	Provider          IdentityProvider `json:"provider"`
+	Email             string           `json:"email"`
+	SuggestedUsername string           `json:"suggested_username,omitempty"`
+	SignupToken       string           `json:"signup_token"`
+	ExpiresAt         time.Time        `json:"expires_at"`
+}
+
+// CompleteOAuthSignupRequest creates the account for an OAuthSignup
+type CompleteOAuthSignupRequest struct {
+	SignupToken string `json:"signup_token" validate:"required"`
+	Username    string `json:"username" validate:"required,min=3,max=50,username"`
+	// Code of the affiliate who referred the player, if any
+	ReferralCode string `json:"referral_code,omitempty" validate:"omitempty,max=20"`
+}
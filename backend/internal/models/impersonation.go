@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SupportAccessGrant is a user's consent for support staff to view their
+// account as they see it, until it expires or they revoke it
+type SupportAccessGrant struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ImpersonationSession is one time-boxed, read-only look at a user's account
+// by an admin. The user is told about it once it ends.
+type ImpersonationSession struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AdminID    uuid.UUID  `json:"admin_id" gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Reason     string     `json:"reason" gorm:"not null;size:500"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty" gorm:"index"` // When the user was told, nil until then
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Active reports whether the session can still be used
+func (s *ImpersonationSession) Active(now time.Time) bool {
+	return s.EndedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// ImpersonationAction is one request made during an impersonation session,
+// including those refused for not being read-only
+type ImpersonationAction struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SessionID uuid.UUID `json:"session_id" gorm:"type:uuid;not null;index"`
+	Method    string    `json:"method" gorm:"not null;size:10"`
+	Path      string    `json:"path" gorm:"not null;size:500"`
+	Status    int       `json:"status"`
+	Blocked   bool      `json:"blocked" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type GrantSupportAccessRequest struct {
+	Hours int `json:"hours" validate:"required,min=1,max=72"`
+}
+
+type StartImpersonationRequest struct {
+	Reason  string `json:"reason" validate:"required,min=10,max=500"` // Ticket reference and why access is needed
+	Minutes int    `json:"minutes" validate:"omitempty,min=1,max=60"` // Defaults to 15
+}
+
+type ImpersonationTokenResponse struct {
+	Token     string               `json:"token"`
+	ExpiresAt time.Time            `json:"expires_at"`
+	Session   ImpersonationSession `json:"session"`
+}
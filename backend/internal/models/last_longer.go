@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Last-longer pool statuses
+const (
+	LastLongerOpen      = "open"      // Taking entries, or waiting for all but one entrant to bust
+	LastLongerSettled   = "settled"   // Paid to the entrant who lasted longest
+	LastLongerCancelled = "cancelled" // Stakes refunded
+)
+
+// LastLongerPool is a side bet between some of a tournament's entrants: each
+// puts in the same stake, held in escrow, and whoever of them lasts longest
+// in the tournament takes it all
+type LastLongerPool struct {
+	ID                  uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TournamentID        uuid.UUID         `json:"tournament_id" gorm:"type:uuid;not null;index"`
+	Name                string            `json:"name" gorm:"not null;size:50"`
+	Stake               int64             `json:"stake" gorm:"not null"` // MNT each entrant puts in
+	Pot                 int64             `json:"pot" gorm:"-"`          // MNT held for the winner
+	Status              string            `json:"status" gorm:"not null;size:20;default:open;index"`
+	CreatedBy           uuid.UUID         `json:"created_by" gorm:"type:uuid;not null"`
+	WinnerID            *uuid.UUID        `json:"winner_id,omitempty" gorm:"type:uuid"`
+	Payout              int64             `json:"payout,omitempty"` // MNT paid to the winner
+	SettleTransactionID *string           `json:"settle_transaction_id,omitempty" gorm:"size:255"`
+	SettledAt           *time.Time        `json:"settled_at,omitempty"`
+	Entries             []LastLongerEntry `json:"entries" gorm:"foreignKey:PoolID;constraint:OnDelete:CASCADE"`
+	CreatedAt           time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// LastLongerEntry is one entrant's stake in a last-longer pool
+type LastLongerEntry struct {
+	ID                 uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PoolID             uuid.UUID `json:"pool_id" gorm:"type:uuid;not null;uniqueIndex:idx_last_longer_entry"`
+	UserID             uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_last_longer_entry;index"`
+	Username           string    `json:"username" gorm:"-"`
+	FinalPosition      *int      `json:"final_position,omitempty" gorm:"-"` // From the tournament, once they bust
+	StakeTransactionID string    `json:"stake_transaction_id" gorm:"size:255"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// CreateLastLongerPoolRequest opens a last-longer pool, with the creator as
+// its first entrant
+type CreateLastLongerPoolRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=50"`
+	Stake int64  `json:"stake" validate:"required,gt=0"`
+}
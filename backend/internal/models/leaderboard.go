@@ -39,4 +39,39 @@ type UserBalance struct {
 	MainBalance int64 `json:"main_balance"` // MNT
 	GameBalance int64 `json:"game_balance"` // MNT
 	TotalBalance int64 `json:"total_balance"` // MNT
-}
\ No newline at end of file
+	Sandbox bool `json:"sandbox,omitempty"` // Play money from the sandbox ledger
+}
+// Leaderboard kinds, ranked from hand history
+const (
+	LeaderboardBiggestWinners = "winners" // Net MNT won: pots won less chips put in
+	LeaderboardMostHands      = "hands"   // Hands dealt into
+	LeaderboardBiggestPots    = "pots"    // MNT won in the player's best single hand
+)
+
+// Leaderboard periods. Days, weeks (from Monday) and months start at
+// midnight UTC.
+const (
+	LeaderboardDaily   = "daily"
+	LeaderboardWeekly  = "weekly"
+	LeaderboardMonthly = "monthly"
+)
+
+// LeaderboardStanding is a player's place on a leaderboard
+type LeaderboardStanding struct {
+	Rank     int       `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Value    int64     `json:"value"` // MNT, or hands for the hands leaderboard
+}
+
+// LeaderboardStandings is the top of a leaderboard for the current period
+type LeaderboardStandings struct {
+	Type         string                `json:"type"`
+	Period       string                `json:"period"`
+	PeriodStart  time.Time             `json:"period_start"`
+	PeriodEnd    time.Time             `json:"period_end"`
+	CalculatedAt time.Time             `json:"calculated_at"`
+	Entries      []LeaderboardStanding `json:"entries"`
+	// The signed in player's own standing, when they are on the board
+	UserRank *LeaderboardStanding `json:"user_rank,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoyaltyTier string
+
+const (
+	LoyaltyTierBronze   LoyaltyTier = "bronze"
+	LoyaltyTierSilver   LoyaltyTier = "silver"
+	LoyaltyTierGold     LoyaltyTier = "gold"
+	LoyaltyTierPlatinum LoyaltyTier = "platinum"
+)
+
+// RakeContribution records the rake taken from a single player in a single collection
+type RakeContribution struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID            uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User              User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	TableID           uuid.UUID      `json:"table_id" gorm:"type:uuid;index"`
+	HandID            string         `json:"hand_id,omitempty" gorm:"size:100"`
+	Amount            int64          `json:"amount" gorm:"not null"` // MNT
+	TransactionID     string         `json:"transaction_id" gorm:"size:100"`
+	RakebackPayoutID  *uuid.UUID     `json:"rakeback_payout_id,omitempty" gorm:"type:uuid;index"`
+	AffiliatePayoutID *uuid.UUID     `json:"affiliate_payout_id,omitempty" gorm:"type:uuid;index"` // Set once counted towards the referrer's revenue share
+	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// RakebackPayout records a periodic rakeback payment to a player
+type RakebackPayout struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID        uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User          User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Tier          LoyaltyTier    `json:"tier" gorm:"not null;size:20"`
+	Percentage    float64        `json:"percentage" gorm:"not null"`
+	RakeAmount    int64          `json:"rake_amount" gorm:"not null"`   // MNT
+	PayoutAmount  int64          `json:"payout_amount" gorm:"not null"` // MNT
+	PeriodEnd     time.Time      `json:"period_end" gorm:"not null"`
+	TransactionID string         `json:"transaction_id" gorm:"size:100"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// LoyaltyStatus describes a player's current tier and progress to the next one
+type LoyaltyStatus struct {
+	Tier               LoyaltyTier  `json:"tier"`
+	RakebackPercentage float64      `json:"rakeback_percentage"`
+	QualifyingRake     int64        `json:"qualifying_rake"` // MNT raked in the qualifying window
+	QualifyingDays     int          `json:"qualifying_days"`
+	NextTier           *LoyaltyTier `json:"next_tier,omitempty"`
+	NextTierThreshold  int64        `json:"next_tier_threshold,omitempty"` // MNT
+	RakeToNextTier     int64        `json:"rake_to_next_tier,omitempty"`   // MNT
+	PendingRake        int64        `json:"pending_rake"`                  // MNT not yet paid out
+	PendingRakeback    int64        `json:"pending_rakeback"`              // MNT expected at the next payout
+	LifetimeRakeback   int64        `json:"lifetime_rakeback"`             // MNT
+}
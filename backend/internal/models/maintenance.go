@@ -0,0 +1,115 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// What a maintenance window takes offline
+const (
+	MaintenanceScopeDeposits    = "deposits"
+	MaintenanceScopeWithdrawals = "withdrawals"
+	MaintenanceScopeNewTables   = "new_tables" // Creating tables
+	MaintenanceScopeAllPlay     = "all_play"   // Every table goes read-only, and no new tables, seats or buy-ins
+)
+
+// MaintenanceScopes lists the scopes a window can cover
+var MaintenanceScopes = []string{
+	MaintenanceScopeDeposits,
+	MaintenanceScopeWithdrawals,
+	MaintenanceScopeNewTables,
+	MaintenanceScopeAllPlay,
+}
+
+// Where a maintenance window is in its schedule
+const (
+	MaintenanceStatusScheduled = "scheduled"
+	MaintenanceStatusActive    = "active"
+	MaintenanceStatusEnded     = "ended"
+)
+
+// MaintenanceWindow is planned downtime the server enforces on its own from
+// StartsAt until EndsAt
+type MaintenanceWindow struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Title         string         `json:"title" gorm:"not null;size:100"`
+	Message       string         `json:"message,omitempty" gorm:"size:500"` // Shown to players in banners and notices
+	StartsAt      time.Time      `json:"starts_at" gorm:"not null;index"`
+	EndsAt        time.Time      `json:"ends_at" gorm:"not null;index"`
+	Scopes        string         `json:"scopes" gorm:"not null;size:100"` // Comma-separated MaintenanceScopes
+	CreatedBy     uuid.UUID      `json:"created_by" gorm:"type:uuid;not null"`
+	StartedAt     *time.Time     `json:"started_at,omitempty"`      // When the server began enforcing it
+	LiftedAt      *time.Time     `json:"lifted_at,omitempty"`       // When the server stopped enforcing it
+	NoticesSentAt *time.Time     `json:"notices_sent_at,omitempty"` // When tournament registrants were emailed
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ScopeList returns the scopes the window covers
+func (m *MaintenanceWindow) ScopeList() []string {
+	if m.Scopes == "" {
+		return nil
+	}
+	return strings.Split(m.Scopes, ",")
+}
+
+// Covers reports whether the window takes scope offline. Stopping all play
+// stops new tables too.
+func (m *MaintenanceWindow) Covers(scope string) bool {
+	scopes := m.ScopeList()
+	if slices.Contains(scopes, scope) {
+		return true
+	}
+	return scope == MaintenanceScopeNewTables && slices.Contains(scopes, MaintenanceScopeAllPlay)
+}
+
+// ActiveAt reports whether the window is in force at t
+func (m *MaintenanceWindow) ActiveAt(t time.Time) bool {
+	return !t.Before(m.StartsAt) && t.Before(m.EndsAt)
+}
+
+// StatusAt returns where the window is in its schedule at t
+func (m *MaintenanceWindow) StatusAt(t time.Time) string {
+	switch {
+	case t.Before(m.StartsAt):
+		return MaintenanceStatusScheduled
+	case t.Before(m.EndsAt):
+		return MaintenanceStatusActive
+	default:
+		return MaintenanceStatusEnded
+	}
+}
+
+type CreateMaintenanceWindowRequest struct {
+	Title           string    `json:"title" validate:"required,max=100"`
+	Message         string    `json:"message" validate:"max=500"`
+	StartsAt        time.Time `json:"starts_at" validate:"required"`
+	DurationMinutes int       `json:"duration_minutes" validate:"required,min=1,max=10080"`
+	Scopes          []string  `json:"scopes" validate:"required,min=1,dive,oneof=deposits withdrawals new_tables all_play"`
+}
+
+// UpdateMaintenanceWindowRequest reschedules or changes a window that has not
+// ended. Omitted fields are left as they are.
+type UpdateMaintenanceWindowRequest struct {
+	Title           *string    `json:"title,omitempty" validate:"omitempty,max=100"`
+	Message         *string    `json:"message,omitempty" validate:"omitempty,max=500"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=10080"`
+	Scopes          []string   `json:"scopes,omitempty" validate:"omitempty,min=1,dive,oneof=deposits withdrawals new_tables all_play"`
+}
+
+// MaintenanceNotice is a window as players see it in the lobby
+type MaintenanceNotice struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message,omitempty"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Scopes   []string  `json:"scopes"`
+	Status   string    `json:"status"`
+}
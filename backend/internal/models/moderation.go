@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Chat channels a moderated message was sent on
+const (
+	ChatChannelTable  = "table"
+	ChatChannelDirect = "direct"
+)
+
+// Moderated message review statuses
+const (
+	ModerationPending    = "pending"
+	ModerationUpheld     = "upheld"
+	ModerationOverturned = "overturned"
+)
+
+// ModeratedMessage is a chat message blocked by automatic moderation, kept
+// for moderators to review. Overturning it lifts the mute it caused.
+type ModeratedMessage struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Channel    string     `json:"channel" gorm:"not null;size:20"` // 'table', 'direct'
+	Target     string     `json:"target" gorm:"size:100"`          // Table name or recipient ID
+	Body       string     `json:"body" gorm:"not null;size:1000"`
+	Reasons    string     `json:"reasons" gorm:"not null;size:200"` // Comma separated, e.g. "profanity,link"
+	Strike     int        `json:"strike" gorm:"not null"`           // Offences in the penalty window, including this one
+	MutedUntil *time.Time `json:"muted_until,omitempty" gorm:"index"`
+	Status     string     `json:"status" gorm:"not null;size:20;default:pending;index"` // 'pending', 'upheld', 'overturned'
+	ReviewedBy *uuid.UUID `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type ReviewModeratedMessageRequest struct {
+	Status string `json:"status" validate:"required,oneof=upheld overturned"`
+}
+
+// What happened to a table chat message, as kept in the chat history
+const (
+	ChatStatusDelivered   = "delivered"
+	ChatStatusBlocked     = "blocked"      // Refused by automatic moderation
+	ChatStatusMuted       = "muted"        // The sender was muted
+	ChatStatusRateLimited = "rate_limited" // The sender was over the chat rate limit
+)
+
+// TableChatMute silences a player in one table's chat, set by a moderator.
+// It lasts until ExpiresAt, or until lifted when that is unset.
+type TableChatMute struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Table     string     `json:"table" gorm:"column:table_name;not null;size:100;index"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Username  string     `json:"username" gorm:"size:50"`
+	MutedBy   uuid.UUID  `json:"muted_by" gorm:"type:uuid;not null"`
+	Reason    string     `json:"reason,omitempty" gorm:"size:200"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+	LiftedAt  *time.Time `json:"lifted_at,omitempty" gorm:"index"`
+	LiftedBy  *uuid.UUID `json:"lifted_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ActiveAt reports whether the mute silences the player at t
+func (m *TableChatMute) ActiveAt(t time.Time) bool {
+	return m.LiftedAt == nil && (m.ExpiresAt == nil || t.Before(*m.ExpiresAt))
+}
+
+// ChatMessage is a table chat message as sent, whether or not it was
+// delivered, kept so chat can be audited
+type ChatMessage struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Table     string    `json:"table" gorm:"column:table_name;not null;size:100;index"`
+	HandID    string    `json:"hand_id,omitempty" gorm:"size:64"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;index"` // Nil for guests
+	Username  string    `json:"username" gorm:"size:50"`
+	Body      string    `json:"body" gorm:"not null;size:1000"`
+	Status    string    `json:"status" gorm:"not null;size:20;index"` // 'delivered', 'blocked', 'muted', 'rate_limited'
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (ChatMessage) TableName() string {
+	return "chat_history"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PartialCashOut is the audit record of chips taken off the table mid-session.
+// Only chips above the table's maximum buy-in may be withdrawn, so a player
+// can't lock in a win and keep playing short (rat-holing). At cap tables the
+// chips above the cap are cashed out automatically between hands.
+type PartialCashOut struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	SessionID     uuid.UUID  `json:"session_id" gorm:"type:uuid;not null;index"` // Ledger session account
+	TableID       *uuid.UUID `json:"table_id,omitempty" gorm:"type:uuid;index"`
+	TableName     string     `json:"table_name" gorm:"not null;size:100"`
+	HandID        string     `json:"hand_id,omitempty" gorm:"size:64"` // Last hand played before the cash-out
+	StackBefore   int64      `json:"stack_before" gorm:"not null"`     // MNT
+	Amount        int64      `json:"amount" gorm:"not null"`           // MNT
+	StackAfter    int64      `json:"stack_after" gorm:"not null"`      // MNT
+	TableMaxBuyIn int64      `json:"table_max_buy_in" gorm:"not null"` // MNT
+	TransactionID string     `json:"transaction_id" gorm:"size:255"`
+	Kind          string     `json:"kind" gorm:"not null;size:20;default:manual"` // 'manual', 'chip_cap'
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+type UpdateCashOutPolicyRequest struct {
+	AllowPartialCashOut bool `json:"allow_partial_cash_out"`
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// PayoutPlace is the share of the prize pool paid to one finishing position
+type PayoutPlace struct {
+	Position   int     `json:"position"`
+	Percentage float64 `json:"percentage"` // Of the prize pool, to two decimals
+}
+
+// PayoutModel generates a payout structure from the number of entrants once
+// registration closes. Steepness shapes the curve: place n gets a share
+// proportional to 1/n^Steepness, so 0 pays every place the same and higher
+// values favour the top finishers.
+type PayoutModel struct {
+	PaidPercent float64 `json:"paid_percent" validate:"gt=0,lte=50"` // Share of the field that cashes, e.g. 10 or 15
+	Steepness   float64 `json:"steepness" validate:"gte=0,lte=3"`
+}
+
+// DefaultPayoutModel pays the top 15% on a moderately steep curve
+func DefaultPayoutModel() PayoutModel {
+	return PayoutModel{PaidPercent: 15, Steepness: 1}
+}
+
+// AdjustPayoutsRequest replaces a tournament's payout structure by hand
+type AdjustPayoutsRequest struct {
+	Places []PayoutPlace `json:"places" validate:"required,min=1"`
+}
+
+var (
+	ErrEmptyPayoutStructure = errors.New("payout structure must have at least one place")
+	ErrNoPayoutEntrants     = errors.New("payouts need at least one entrant")
+	ErrInvalidPayoutModel   = errors.New("payout model must pay more than 0% and at most 50% of the field with a steepness from 0 to 3")
+	ErrInvalidResults       = errors.New("invalid tournament results")
+)
+
+// payoutUnits is 100% in hundredths of a percent
+const payoutUnits = 10000
+
+// ParsePayoutStructure decodes and validates a tournament payout structure.
+// Places must be numbered in order, pay something, never pay a lower place
+// more than a higher one and add up to 100%.
+func ParsePayoutStructure(raw json.RawMessage) ([]PayoutPlace, error) {
+	var places []PayoutPlace
+	if err := json.Unmarshal(raw, &places); err != nil {
+		return nil, fmt.Errorf("invalid payout structure: %w", err)
+	}
+	if err := ValidatePayoutStructure(places); err != nil {
+		return nil, err
+	}
+	return places, nil
+}
+
+// ValidatePayoutStructure checks a decoded payout structure, see
+// ParsePayoutStructure
+func ValidatePayoutStructure(places []PayoutPlace) error {
+	if len(places) == 0 {
+		return ErrEmptyPayoutStructure
+	}
+
+	var total int64
+	for i, place := range places {
+		switch {
+		case place.Position != i+1:
+			return fmt.Errorf("payout place %d is out of order, expected place %d", place.Position, i+1)
+		case place.Percentage <= 0:
+			return fmt.Errorf("payout place %d must pay a positive percentage", place.Position)
+		case i > 0 && place.Percentage > places[i-1].Percentage:
+			return fmt.Errorf("payout place %d pays more than place %d", place.Position, places[i-1].Position)
+		}
+		total += int64(math.Round(place.Percentage * 100))
+	}
+	if total != payoutUnits {
+		return fmt.Errorf("payout percentages add up to %.2f, expected 100", float64(total)/100)
+	}
+	return nil
+}
+
+// PaidPlaces returns how many of the entrants the model pays, at least one
+func (m PayoutModel) PaidPlaces(entrants int) int {
+	paid := int(math.Ceil(float64(entrants) * m.PaidPercent / 100))
+	if paid > entrants {
+		paid = entrants
+	}
+	if paid < 1 {
+		paid = 1
+	}
+	return paid
+}
+
+// GeneratePayoutStructure builds the payout structure for a field. Every
+// paid place gets at least 0.01% and rounding leftovers go to the top places,
+// so the result always passes ValidatePayoutStructure.
+func GeneratePayoutStructure(entrants int, model PayoutModel) ([]PayoutPlace, error) {
+	if entrants < 1 {
+		return nil, ErrNoPayoutEntrants
+	}
+	if model.PaidPercent <= 0 || model.PaidPercent > 50 || model.Steepness < 0 || model.Steepness > 3 {
+		return nil, ErrInvalidPayoutModel
+	}
+
+	paid := model.PaidPlaces(entrants)
+	weights := make([]float64, paid)
+	var totalWeight float64
+	for i := range weights {
+		weights[i] = 1 / math.Pow(float64(i+1), model.Steepness)
+		totalWeight += weights[i]
+	}
+
+	units := make([]int64, paid)
+	spread := int64(payoutUnits - paid)
+	var allocated int64
+	for i, weight := range weights {
+		units[i] = 1 + int64(math.Floor(float64(spread)*weight/totalWeight))
+		allocated += units[i]
+	}
+	for i := 0; allocated < payoutUnits; i = (i + 1) % paid {
+		units[i]++
+		allocated++
+	}
+
+	places := make([]PayoutPlace, paid)
+	for i, u := range units {
+		places[i] = PayoutPlace{Position: i + 1, Percentage: float64(u) / 100}
+	}
+	return places, nil
+}
+
+// FinishingPosition is where a player finished in a tournament. Players
+// who bust on the same hand with the same stack can share a position, a
+// dead heat, and the places below them are skipped: two players tied for
+// third are followed by fifth.
+type FinishingPosition struct {
+	UserID   uuid.UUID `json:"user_id" validate:"required"`
+	Position int       `json:"position" validate:"required,gt=0"`
+}
+
+// TournamentPrize is what a player won for their finishing position
+type TournamentPrize struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Position int       `json:"position"`
+	Prize    int64     `json:"prize"` // MNT
+}
+
+// PlacePrizes splits a prize pool by its payout places, rounding down, with
+// what rounding leaves over going to the winner so the pool is paid in full
+func PlacePrizes(prizePool int64, places []PayoutPlace) []int64 {
+	prizes := make([]int64, len(places))
+	var paid int64
+	for i, place := range places {
+		prizes[i] = prizePool * int64(math.Round(place.Percentage*100)) / 10000
+		paid += prizes[i]
+	}
+	if len(prizes) > 0 {
+		prizes[0] += prizePool - paid
+	}
+	return prizes
+}
+
+// AwardPrizes works out each finisher's prize from the payout places and the
+// prize pool. Players in a dead heat split the prizes of the places they
+// cover evenly, with odd chips going one each to the first of them listed.
+// The prizes are in finishing order.
+func AwardPrizes(prizePool int64, places []PayoutPlace, results []FinishingPosition) ([]TournamentPrize, error) {
+	tied := make(map[int][]uuid.UUID)
+	seen := make(map[uuid.UUID]bool, len(results))
+	for _, result := range results {
+		if result.Position < 1 {
+			return nil, fmt.Errorf("%w: position %d", ErrInvalidResults, result.Position)
+		}
+		if seen[result.UserID] {
+			return nil, fmt.Errorf("%w: player %s finished more than once", ErrInvalidResults, result.UserID)
+		}
+		seen[result.UserID] = true
+		tied[result.Position] = append(tied[result.Position], result.UserID)
+	}
+
+	positions := make([]int, 0, len(tied))
+	for position := range tied {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+
+	placePrizes := PlacePrizes(prizePool, places)
+	awarded := make([]TournamentPrize, 0, len(results))
+	for _, position := range positions {
+		players := tied[position]
+		var pool int64
+		for place := position; place < position+len(players); place++ {
+			if place > position {
+				if _, taken := tied[place]; taken {
+					return nil, fmt.Errorf("%w: place %d is covered by the dead heat for place %d", ErrInvalidResults, place, position)
+				}
+			}
+			if place <= len(placePrizes) {
+				pool += placePrizes[place-1]
+			}
+		}
+
+		share, odd := pool/int64(len(players)), pool%int64(len(players))
+		for i, userID := range players {
+			prize := share
+			if int64(i) < odd {
+				prize++
+			}
+			awarded = append(awarded, TournamentPrize{UserID: userID, Position: position, Prize: prize})
+		}
+	}
+	return awarded, nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friendship statuses
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+)
+
+// Friendship links two players once the addressee accepts the request.
+// Only friends can see each other's presence.
+type Friendship struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RequesterID uuid.UUID  `json:"requester_id" gorm:"type:uuid;not null;uniqueIndex:idx_friendship_pair"`
+	Requester   User       `json:"-" gorm:"foreignKey:RequesterID;constraint:OnDelete:CASCADE"`
+	AddresseeID uuid.UUID  `json:"addressee_id" gorm:"type:uuid;not null;uniqueIndex:idx_friendship_pair;index"`
+	Addressee   User       `json:"-" gorm:"foreignKey:AddresseeID;constraint:OnDelete:CASCADE"`
+	Status      string     `json:"status" gorm:"not null;size:20;default:pending"` // 'pending', 'accepted'
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// PresenceSettings control what a player's friends can see. Players without
+// a row use DefaultPresenceSettings.
+type PresenceSettings struct {
+	UserID       uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Invisible    bool      `json:"invisible"`     // Appear offline to everyone
+	ShowActivity bool      `json:"show_activity"` // Show friends the table or tournament being played
+	AllowJoin    bool      `json:"allow_join"`    // Let friends use the join shortcut
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DefaultPresenceSettings shares activity with friends
+func DefaultPresenceSettings(userID uuid.UUID) PresenceSettings {
+	return PresenceSettings{UserID: userID, ShowActivity: true, AllowJoin: true}
+}
+
+// PresenceTable is the table a friend is seated at
+type PresenceTable struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	TableType string    `json:"table_type"`
+	IsPrivate bool      `json:"is_private"`
+}
+
+// PresenceTournament is the running tournament a friend is playing
+type PresenceTournament struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// FriendPresence is what a player can see of one of their friends
+type FriendPresence struct {
+	UserID     uuid.UUID           `json:"user_id"`
+	Username   string              `json:"username"`
+	Online     bool                `json:"online"`
+	Table      *PresenceTable      `json:"table,omitempty"`
+	Tournament *PresenceTournament `json:"tournament,omitempty"`
+	CanJoin    bool                `json:"can_join"`
+}
+
+// FriendRequest is a pending request sent to the player
+type FriendRequest struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JoinFriendTarget is where the join shortcut sends a player. Joining still
+// goes through the table's own join endpoint and its checks.
+type JoinFriendTarget struct {
+	TableID          uuid.UUID `json:"table_id"`
+	TableName        string    `json:"table_name"`
+	MinBuyIn         int64     `json:"min_buy_in"` // MNT
+	MaxBuyIn         int64     `json:"max_buy_in"` // MNT
+	RequiresPassword bool      `json:"requires_password"`
+}
+
+type SendFriendRequestRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+}
+
+type UpdatePresenceSettingsRequest struct {
+	Invisible    *bool `json:"invisible,omitempty"`
+	ShowActivity *bool `json:"show_activity,omitempty"`
+	AllowJoin    *bool `json:"allow_join,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PushPlatform identifies the push provider used for a device token
+type PushPlatform string
+
+const (
+	PushPlatformFCM  PushPlatform = "fcm"
+	PushPlatformAPNs PushPlatform = "apns"
+)
+
+// PushEvent identifies a category of push notification a user can opt in to
+type PushEvent string
+
+const (
+	PushEventTurnAlert        PushEvent = "turn_alert"
+	PushEventTournament       PushEvent = "tournament"
+	PushEventWithdrawalStatus PushEvent = "withdrawal_status"
+	PushEventTableStatus      PushEvent = "table_status"
+)
+
+type DeviceToken struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Token      string         `json:"token" gorm:"uniqueIndex;not null;size:500"`
+	Platform   PushPlatform   `json:"platform" gorm:"type:varchar(10);not null"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type NotificationPreferences struct {
+	ID                uuid.UUID      `json:"-" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID            uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User              User           `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	TurnAlerts        bool           `json:"turn_alerts" gorm:"default:true"`
+	TournamentAlerts  bool           `json:"tournament_alerts" gorm:"default:true"`
+	WithdrawalUpdates bool           `json:"withdrawal_updates" gorm:"default:true"`
+	TableUpdates      bool           `json:"table_updates" gorm:"default:true"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Allows reports whether the user opted in to the given push event
+func (p *NotificationPreferences) Allows(event PushEvent) bool {
+	switch event {
+	case PushEventTurnAlert:
+		return p.TurnAlerts
+	case PushEventTournament:
+		return p.TournamentAlerts
+	case PushEventWithdrawalStatus:
+		return p.WithdrawalUpdates
+	case PushEventTableStatus:
+		return p.TableUpdates
+	default:
+		return false
+	}
+}
+
+type RegisterDeviceRequest struct {
+	Token    string       `json:"token" validate:"required,max=500"`
+	Platform PushPlatform `json:"platform" validate:"required,oneof=fcm apns"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	TurnAlerts        *bool `json:"turn_alerts,omitempty"`
+	TournamentAlerts  *bool `json:"tournament_alerts,omitempty"`
+	WithdrawalUpdates *bool `json:"withdrawal_updates,omitempty"`
+	TableUpdates      *bool `json:"table_updates,omitempty"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Where a rake-free window is in its schedule
+const (
+	RakeFreeStatusScheduled = "scheduled"
+	RakeFreeStatusActive    = "active"
+	RakeFreeStatusEnded     = "ended"
+)
+
+// RakeFreeWindow is a promotional happy hour: from StartsAt until EndsAt no
+// rake is taken from hands at one table, or at every table opened from one
+// stake template
+type RakeFreeWindow struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Title      string         `json:"title" gorm:"not null;size:100"`
+	TableID    *uuid.UUID     `json:"table_id,omitempty" gorm:"type:uuid;index"`
+	TemplateID *uuid.UUID     `json:"template_id,omitempty" gorm:"type:uuid;index"` // Every table opened from the stake template
+	StartsAt   time.Time      `json:"starts_at" gorm:"not null;index"`
+	EndsAt     time.Time      `json:"ends_at" gorm:"not null;index"`
+	CreatedBy  uuid.UUID      `json:"created_by" gorm:"type:uuid;not null"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"` // When tables were told it started
+	EndedAt    *time.Time     `json:"ended_at,omitempty"`   // When tables were told it ended
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Covers reports whether the window applies to a table, given the stake
+// template it was opened from if any
+func (w *RakeFreeWindow) Covers(tableID uuid.UUID, templateID *uuid.UUID) bool {
+	if w.TableID != nil {
+		return *w.TableID == tableID
+	}
+	return w.TemplateID != nil && templateID != nil && *w.TemplateID == *templateID
+}
+
+// ActiveAt reports whether hands are rake-free at t
+func (w *RakeFreeWindow) ActiveAt(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// StatusAt returns where the window is in its schedule at t
+func (w *RakeFreeWindow) StatusAt(t time.Time) string {
+	switch {
+	case t.Before(w.StartsAt):
+		return RakeFreeStatusScheduled
+	case t.Before(w.EndsAt):
+		return RakeFreeStatusActive
+	default:
+		return RakeFreeStatusEnded
+	}
+}
+
+// CreateRakeFreeWindowRequest schedules a rake-free window for a table or a
+// stake template, one of which must be given
+type CreateRakeFreeWindowRequest struct {
+	Title           string     `json:"title" validate:"required,max=100"`
+	TableID         *uuid.UUID `json:"table_id,omitempty" validate:"required_without=TemplateID,excluded_with=TemplateID"`
+	TemplateID      *uuid.UUID `json:"template_id,omitempty" validate:"required_without=TableID"`
+	StartsAt        time.Time  `json:"starts_at" validate:"required"`
+	DurationMinutes int        `json:"duration_minutes" validate:"required,min=1,max=1440"`
+}
+
+// UpdateRakeFreeWindowRequest reschedules or renames a window that has not
+// ended. Omitted fields are left as they are.
+type UpdateRakeFreeWindowRequest struct {
+	Title           *string    `json:"title,omitempty" validate:"omitempty,max=100"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty" validate:"omitempty,min=1,max=1440"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegulatorExportSignatureAlgorithm is how a regulator export's manifest is
+// signed: the base64 Ed25519 signature of manifest.json's bytes, in
+// manifest.json.sig. Regulators verify it with the published public key.
+const RegulatorExportSignatureAlgorithm = "Ed25519"
+
+// RegulatorExportManifest lists the CSV files of a regulator export with a
+// checksum of each, so the signed manifest vouches for all of them
+type RegulatorExportManifest struct {
+	ExportID           uuid.UUID             `json:"export_id"`
+	GeneratedAt        time.Time             `json:"generated_at"`
+	GeneratedBy        uuid.UUID             `json:"generated_by"`
+	UserID             *uuid.UUID            `json:"user_id,omitempty"`  // Set for a player's export
+	TableID            *uuid.UUID            `json:"table_id,omitempty"` // Set for a table's export
+	From               time.Time             `json:"from"`
+	To                 time.Time             `json:"to"`
+	Currency           string                `json:"currency"`
+	SignatureAlgorithm string                `json:"signature_algorithm"`
+	PublicKeySHA256    string                `json:"public_key_sha256"` // Of the DER public key that verifies the signature
+	Files              []RegulatorExportFile `json:"files"`
+}
+
+// RegulatorExportFile is one CSV file of an export
+type RegulatorExportFile struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"` // Not counting the header
+	SHA256 string `json:"sha256"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why a seat reservation stopped holding its seat
+const (
+	SeatReservationClaimed   = "claimed"   // The player sat down
+	SeatReservationExpired   = "expired"   // They didn't arrive in time
+	SeatReservationCancelled = "cancelled" // An admin cancelled it
+)
+
+// SeatReservation holds one seat at a table for a named player, e.g. for an
+// invite-only lineup at a streamed or featured game. No one else may take
+// the seat until the player sits down or the reservation expires.
+type SeatReservation struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TableID       uuid.UUID  `json:"table_id" gorm:"type:uuid;not null;index"`
+	SeatNumber    int        `json:"seat_number" gorm:"not null"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Username      string     `json:"username" gorm:"size:50"`
+	Note          string     `json:"note,omitempty" gorm:"size:200"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedBy     uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty" gorm:"index"`
+	ReleaseReason string     `json:"release_reason,omitempty" gorm:"size:20"` // 'claimed', 'expired', 'cancelled'
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// HeldAt reports whether the reservation still holds its seat at t
+func (r *SeatReservation) HeldAt(t time.Time) bool {
+	return r.ReleasedAt == nil && t.Before(r.ExpiresAt)
+}
+
+// CreateSeatReservationRequest reserves a seat at a table for a player
+type CreateSeatReservationRequest struct {
+	UserID           uuid.UUID `json:"user_id" validate:"required"`
+	SeatNumber       int       `json:"seat_number" validate:"required,min=1,max=9"`
+	ExpiresInMinutes int       `json:"expires_in_minutes" validate:"required,min=1,max=10080"`
+	Note             string    `json:"note,omitempty" validate:"max=200"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Seat selection modes for a table
+const (
+	SeatSelectionChoice = "choice" // players pick their own seat
+	SeatSelectionRandom = "random" // the server assigns a random empty seat
+)
+
+// Sources of a seating separation
+const (
+	SeparationSourceFraud = "fraud"
+	SeparationSourceAdmin = "admin"
+)
+
+// SeatingSeparation is a pair of players who must not sit at the same table,
+// typically flagged by fraud review for suspected collusion. UserAID is always
+// the lexically smaller ID so each pair is stored once.
+type SeatingSeparation struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserAID   uuid.UUID      `json:"user_a_id" gorm:"type:uuid;not null;uniqueIndex:idx_seating_separation_pair"`
+	UserBID   uuid.UUID      `json:"user_b_id" gorm:"type:uuid;not null;uniqueIndex:idx_seating_separation_pair;index"`
+	Source    string         `json:"source" gorm:"not null;size:20;default:fraud"` // 'fraud', 'admin'
+	Reason    string         `json:"reason" gorm:"size:500"`
+	FlaggedBy *uuid.UUID     `json:"flagged_by,omitempty" gorm:"type:uuid"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty" gorm:"index"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SeatAssignment places a player at a table and seat. Table numbers are only
+// meaningful within a single seating draw.
+type SeatAssignment struct {
+	UserID      uuid.UUID `json:"user_id"`
+	TableNumber int       `json:"table_number"`
+	SeatNumber  int       `json:"seat_number"`
+}
+
+// Reasons a tournament player is moved to another table
+const (
+	TableMoveBalance  = "balance"      // Evening out table sizes
+	TableMoveBreak    = "table_broken" // Their table closed as the field shrank
+	TableMoveDirector = "director"     // Moved by hand
+)
+
+// TableMove is a tournament player moved from one table and seat to another
+type TableMove struct {
+	TournamentID uuid.UUID  `json:"tournament_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	FromTable    int        `json:"from_table"`
+	FromSeat     int        `json:"from_seat"`
+	ToTable      int        `json:"to_table"`
+	ToSeat       int        `json:"to_seat"`
+	TableID      *uuid.UUID `json:"table_id,omitempty"` // The poker_tables row of the new table
+	TableName    string     `json:"table_name,omitempty"`
+	Reason       string     `json:"reason"`
+}
+
+// TournamentTable is one of a running tournament's tables and who sits there
+type TournamentTable struct {
+	TableNumber int            `json:"table_number"`
+	TableID     uuid.UUID      `json:"table_id"`
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	Seats       map[int]string `json:"seats"` // User IDs by seat number
+}
+
+// MoveTournamentPlayerRequest moves a player to a table, at a random free
+// seat unless one is given
+type MoveTournamentPlayerRequest struct {
+	UserID      uuid.UUID `json:"user_id" validate:"required"`
+	TableNumber int       `json:"table_number" validate:"required,min=1"`
+	SeatNumber  int       `json:"seat_number,omitempty" validate:"omitempty,min=1"`
+}
+
+// EliminateTournamentPlayerRequest records that a player busted out
+type EliminateTournamentPlayerRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type FlagSeatingPairRequest struct {
+	UserAID   uuid.UUID  `json:"user_a_id" validate:"required"`
+	UserBID   uuid.UUID  `json:"user_b_id" validate:"required,nefield=UserAID"`
+	Source    string     `json:"source" validate:"omitempty,oneof=fraud admin"`
+	Reason    string     `json:"reason" validate:"required,max=500"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type UpdateSeatingPolicyRequest struct {
+	SeatSelection     string `json:"seat_selection" validate:"required,oneof=choice random"`
+	EnforceSeparation bool   `json:"enforce_separation"`
+}
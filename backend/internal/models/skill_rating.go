@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Skill rating bands. Players see the band, never the rating behind it.
+const (
+	SkillBandNew      = "new" // Too few rated results to place yet
+	SkillBandBeginner = "beginner"
+	SkillBandRegular  = "regular"
+	SkillBandStrong   = "strong"
+	SkillBandShark    = "shark"
+)
+
+// Rating scale and the thresholds between bands
+const (
+	SkillRatingInitial = 1500.0
+
+	SkillProvisionalResults = 10 // Results before a player leaves the new band
+	SkillSharkResults       = 30 // Results before a shark rating is trusted
+
+	skillBeginnerBelow = 1400.0
+	skillStrongFrom    = 1600.0
+	skillSharkFrom     = 1750.0
+)
+
+// Kinds of results folded into skill ratings
+const (
+	RatedResultTournament  = "tournament"
+	RatedResultCashSession = "cash_session"
+)
+
+// SkillRating is a player's Elo-style rating from tournament finishes and
+// cash game results
+type SkillRating struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Rating    float64   `json:"-" gorm:"not null;default:1500"`
+	Results   int       `json:"results" gorm:"not null;default:0"` // Tournaments and cash sessions rated
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Band places the rating in a band. A shark band needs SkillSharkResults
+// results behind it; until then a high rating shows as strong.
+func (r SkillRating) Band() string {
+	switch {
+	case r.Results < SkillProvisionalResults:
+		return SkillBandNew
+	case r.Rating < skillBeginnerBelow:
+		return SkillBandBeginner
+	case r.Rating < skillStrongFrom:
+		return SkillBandRegular
+	case r.Rating < skillSharkFrom || r.Results < SkillSharkResults:
+		return SkillBandStrong
+	default:
+		return SkillBandShark
+	}
+}
+
+// RatedResult marks a finished tournament or cash session as already folded
+// into skill ratings, so each is rated once
+type RatedResult struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Kind    string    `json:"kind" gorm:"not null;size:20;uniqueIndex:idx_rated_result"` // 'tournament', 'cash_session'
+	RefID   uuid.UUID `json:"ref_id" gorm:"type:uuid;not null;uniqueIndex:idx_rated_result"`
+	RatedAt time.Time `json:"rated_at" gorm:"autoCreateTime"`
+}
+
+// SkillProfile is the skill shown on a player's profile
+type SkillProfile struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Band    string    `json:"band"`
+	Results int       `json:"results"`
+}
+
+// TableSuggestion is a cash table quick seat offers a player
+type TableSuggestion struct {
+	Table   PokerTable `json:"table"`
+	Players int        `json:"players"` // Players seated now
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TournamentTypeSpin marks the three-player hyper-turbo sit-n-gos started
+// from the spin queue, whose prize pool is drawn when they fill
+const TournamentTypeSpin = "spin"
+
+// SpinPlayers is the number of players in every spin
+const SpinPlayers = 3
+
+// SpinTier is one prize pool a spin can draw. The chance of drawing it is its
+// weight over the weights of every tier.
+type SpinTier struct {
+	Multiplier int64         `json:"multiplier"` // Prize pool in buy-ins
+	Weight     int64         `json:"weight"`
+	Payouts    []PayoutPlace `json:"payouts"` // How the pool is split, usually winner takes all
+}
+
+// SpinFormat is a spin buy-in level players queue for, with the
+// distribution its prize pools are drawn from
+type SpinFormat struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name           string          `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	BuyIn          int64           `json:"buy_in" gorm:"not null"`                     // MNT
+	StartingChips  int64           `json:"starting_chips" gorm:"not null;default:500"` // Tournament chips, not MNT
+	BlindStructure json.RawMessage `json:"blind_structure" gorm:"type:jsonb;not null"`
+	Tiers          json.RawMessage `json:"tiers" gorm:"type:jsonb;not null"` // []SpinTier
+	Active         bool            `json:"active" gorm:"not null;default:true"`
+	CreatedBy      uuid.UUID       `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// SpinDraw is the provably fair prize pool draw of one spin. The server seed
+// is committed to by its hash when the spin opens, before anyone joins, and
+// only revealed once the draw is made.
+type SpinDraw struct {
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TournamentID    uuid.UUID       `json:"tournament_id" gorm:"type:uuid;not null;uniqueIndex"`
+	FormatID        uuid.UUID       `json:"format_id" gorm:"type:uuid;not null;index"`
+	BuyIn           int64           `json:"buy_in" gorm:"not null"`           // MNT
+	Tiers           json.RawMessage `json:"tiers" gorm:"type:jsonb;not null"` // The format's tiers when the spin opened
+	ServerSeedHash  string          `json:"server_seed_hash" gorm:"not null;size:64"`
+	ServerSeed      string          `json:"-" gorm:"not null;size:64"`
+	ClientSeed      string          `json:"client_seed,omitempty" gorm:"type:text"` // The players' seeds in the order they joined
+	Tier            *int            `json:"tier,omitempty"`                         // Index of the tier drawn
+	Multiplier      int64           `json:"multiplier,omitempty"`
+	PrizePool       int64           `json:"prize_pool,omitempty"`       // MNT
+	JackpotTransfer int64           `json:"jackpot_transfer,omitempty"` // MNT from the entries to the jackpot account, negative when the jackpot paid in
+	HouseTopUp      int64           `json:"house_top_up,omitempty"`     // MNT the house added when the jackpot couldn't cover the prize pool
+	TransactionID   *string         `json:"transaction_id,omitempty" gorm:"size:255"`
+	DrawnAt         *time.Time      `json:"drawn_at,omitempty" gorm:"index"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateSpinFormatRequest struct {
+	Name           string          `json:"name" validate:"required,min=3,max=100"`
+	BuyIn          int64           `json:"buy_in" validate:"required,min=1"`
+	StartingChips  int64           `json:"starting_chips,omitempty" validate:"omitempty,min=1"`
+	BlindStructure json.RawMessage `json:"blind_structure,omitempty"`
+	Tiers          []SpinTier      `json:"tiers" validate:"required,min=1,max=50"`
+}
+
+// UpdateSpinFormatRequest changes a format. Spins already open keep the
+// tiers they opened with.
+type UpdateSpinFormatRequest struct {
+	Active *bool      `json:"active,omitempty"`
+	Tiers  []SpinTier `json:"tiers,omitempty" validate:"omitempty,min=1,max=50"`
+}
+
+type JoinSpinRequest struct {
+	ClientSeed string `json:"client_seed,omitempty" validate:"omitempty,max=64,printascii"` // Mixed into the draw; one is generated when empty
+}
+
+var ErrNoSpinTiers = errors.New("a spin needs at least one prize tier")
+
+// ParseSpinTiers decodes and validates a spin prize distribution
+func ParseSpinTiers(raw json.RawMessage) ([]SpinTier, error) {
+	var tiers []SpinTier
+	if err := json.Unmarshal(raw, &tiers); err != nil {
+		return nil, fmt.Errorf("invalid spin tiers: %w", err)
+	}
+	if err := ValidateSpinTiers(tiers); err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// ValidateSpinTiers checks a spin prize distribution. Every tier must have a
+// chance of being drawn and a valid payout structure paying at most the
+// three players, and on average the prize pool must come to less than the
+// players paid in, which is the house edge.
+func ValidateSpinTiers(tiers []SpinTier) error {
+	if len(tiers) == 0 {
+		return ErrNoSpinTiers
+	}
+
+	for i, tier := range tiers {
+		switch {
+		case tier.Multiplier < 1:
+			return fmt.Errorf("spin tier %d must pay at least one buy-in", i+1)
+		case tier.Weight < 1:
+			return fmt.Errorf("spin tier %d must have a positive weight", i+1)
+		case len(tier.Payouts) > SpinPlayers:
+			return fmt.Errorf("spin tier %d pays more places than a spin has players", i+1)
+		}
+		if err := ValidatePayoutStructure(tier.Payouts); err != nil {
+			return fmt.Errorf("spin tier %d: %w", i+1, err)
+		}
+	}
+
+	if edge := SpinHouseEdge(tiers); edge <= 0 {
+		return fmt.Errorf("spin tiers pay back %.2f%% of the buy-ins on average, which leaves no house edge", (1-edge)*100)
+	}
+	return nil
+}
+
+// SpinExpectedMultiplier is the prize pool, in buy-ins, a spin draws on
+// average
+func SpinExpectedMultiplier(tiers []SpinTier) float64 {
+	var total, weighted float64
+	for _, tier := range tiers {
+		total += float64(tier.Weight)
+		weighted += float64(tier.Weight) * float64(tier.Multiplier)
+	}
+	if total == 0 {
+		return 0
+	}
+	return weighted / total
+}
+
+// SpinHouseEdge is the share of the buy-ins the house keeps on average
+func SpinHouseEdge(tiers []SpinTier) float64 {
+	return 1 - SpinExpectedMultiplier(tiers)/SpinPlayers
+}
+
+// DefaultSpinTiers pays two buy-ins most of the time and up to a thousand
+// rarely, keeping a house edge of about 5%. The biggest prize pools are
+// shared with the players knocked out.
+func DefaultSpinTiers() []SpinTier {
+	winnerTakesAll := []PayoutPlace{{Position: 1, Percentage: 100}}
+	shared := []PayoutPlace{{Position: 1, Percentage: 80}, {Position: 2, Percentage: 10}, {Position: 3, Percentage: 10}}
+	return []SpinTier{
+		{Multiplier: 2, Weight: 700000, Payouts: winnerTakesAll},
+		{Multiplier: 3, Weight: 200000, Payouts: winnerTakesAll},
+		{Multiplier: 5, Weight: 70000, Payouts: winnerTakesAll},
+		{Multiplier: 10, Weight: 22000, Payouts: winnerTakesAll},
+		{Multiplier: 25, Weight: 7500, Payouts: winnerTakesAll},
+		{Multiplier: 100, Weight: 450, Payouts: shared},
+		{Multiplier: 1000, Weight: 50, Payouts: shared},
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Table speeds control how long players get to act
+const (
+	TableSpeedRegular = "regular"
+	TableSpeedTurbo   = "turbo"
+	TableSpeedHyper   = "hyper"
+)
+
+// StakeTemplate describes a reusable cash game configuration that admins can
+// open tables from, either in bulk or automatically as demand grows
+type StakeTemplate struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name           string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	GameType       string         `json:"game_type" gorm:"not null;size:20;default:texas_holdem"`
+	MaxPlayers     int            `json:"max_players" gorm:"not null;default:9"`
+	SmallBlind     int64          `json:"small_blind" gorm:"not null"`                   // MNT
+	BigBlind       int64          `json:"big_blind" gorm:"not null"`                     // MNT
+	MinBuyIn       int64          `json:"min_buy_in" gorm:"not null"`                    // MNT
+	MaxBuyIn       int64          `json:"max_buy_in" gorm:"not null"`                    // MNT
+	RakePercentage float64        `json:"rake_percentage" gorm:"not null;default:0"`     // e.g. 0.05 for 5%
+	RakeCap        int64          `json:"rake_cap" gorm:"not null;default:0"`            // MNT, maximum rake per hand
+	RakeMinPot     int64          `json:"rake_min_pot" gorm:"not null;default:0"`        // MNT, no rake below this pot
+	Speed          string         `json:"speed" gorm:"not null;size:20;default:regular"` // 'regular', 'turbo', 'hyper'
+	AutoManage     bool           `json:"auto_manage" gorm:"default:false"`
+	MinTables      int            `json:"min_tables" gorm:"not null;default:1"`
+	MaxTables      int            `json:"max_tables" gorm:"not null;default:10"`
+	CreatedBy      uuid.UUID      `json:"created_by" gorm:"type:uuid;not null;index"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type CreateStakeTemplateRequest struct {
+	Name           string  `json:"name" validate:"required,min=3,max=100"`
+	GameType       string  `json:"game_type" validate:"omitempty,oneof=texas_holdem omaha"`
+	MaxPlayers     int     `json:"max_players" validate:"omitempty,min=2,max=9"`
+	SmallBlind     int64   `json:"small_blind" validate:"required,min=1"`
+	BigBlind       int64   `json:"big_blind" validate:"required,gtfield=SmallBlind"`
+	MinBuyIn       int64   `json:"min_buy_in" validate:"required,min=1"`
+	MaxBuyIn       int64   `json:"max_buy_in" validate:"required,gtfield=MinBuyIn"`
+	RakePercentage float64 `json:"rake_percentage" validate:"min=0,max=0.1"`
+	RakeCap        int64   `json:"rake_cap" validate:"min=0"`
+	RakeMinPot     int64   `json:"rake_min_pot" validate:"min=0"`
+	Speed          string  `json:"speed" validate:"omitempty,oneof=regular turbo hyper"`
+	AutoManage     bool    `json:"auto_manage"`
+	MinTables      int     `json:"min_tables" validate:"min=0,max=100"`
+	MaxTables      int     `json:"max_tables" validate:"omitempty,min=1,max=100,gtefield=MinTables"`
+}
+
+type UpdateStakeTemplateRequest struct {
+	SmallBlind     *int64   `json:"small_blind,omitempty" validate:"omitempty,min=1"`
+	BigBlind       *int64   `json:"big_blind,omitempty" validate:"omitempty,min=1"`
+	MinBuyIn       *int64   `json:"min_buy_in,omitempty" validate:"omitempty,min=1"`
+	MaxBuyIn       *int64   `json:"max_buy_in,omitempty" validate:"omitempty,min=1"`
+	RakePercentage *float64 `json:"rake_percentage,omitempty" validate:"omitempty,min=0,max=0.1"`
+	RakeCap        *int64   `json:"rake_cap,omitempty" validate:"omitempty,min=0"`
+	RakeMinPot     *int64   `json:"rake_min_pot,omitempty" validate:"omitempty,min=0"`
+	Speed          *string  `json:"speed,omitempty" validate:"omitempty,oneof=regular turbo hyper"`
+	AutoManage     *bool    `json:"auto_manage,omitempty"`
+	MinTables      *int     `json:"min_tables,omitempty" validate:"omitempty,min=0,max=100"`
+	MaxTables      *int     `json:"max_tables,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type OpenTablesRequest struct {
+	Count int `json:"count" validate:"required,min=1,max=50"`
+}
+
+type CloseTablesRequest struct {
+	TableIDs []uuid.UUID `json:"table_ids" validate:"required,min=1,max=100"`
+}
@@ -9,24 +9,82 @@ import (
 )
 
 type PokerTable struct {
-	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Name           string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
-	TableType      string         `json:"table_type" gorm:"not null;size:20;index"` // 'cash', 'tournament', 'sitng'
-	GameType       string         `json:"game_type" gorm:"not null;size:20;default:texas_holdem"` // 'texas_holdem', 'omaha'
-	MaxPlayers     int            `json:"max_players" gorm:"not null;default:9"`
-	MinBuyIn       int64          `json:"min_buy_in" gorm:"not null"`     // MNT
-	MaxBuyIn       int64          `json:"max_buy_in" gorm:"not null"`     // MNT
-	SmallBlind     int64          `json:"small_blind" gorm:"not null"`    // MNT
-	BigBlind       int64          `json:"big_blind" gorm:"not null"`      // MNT
-	IsPrivate      bool           `json:"is_private" gorm:"default:false"`
-	PasswordHash   *string        `json:"-" gorm:"size:255"`
-	Status         string         `json:"status" gorm:"not null;size:20;default:waiting;index"` // 'waiting', 'active', 'finished'
-	CurrentPlayers int            `json:"current_players" gorm:"default:0"`
-	CreatedBy      uuid.UUID      `json:"created_by" gorm:"type:uuid;not null;index"`
-	Creator        User           `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
-	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                   uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name                 string         `json:"name" gorm:"uniqueIndex;not null;size:100"`
+	TableType            string         `json:"table_type" gorm:"not null;size:20;index"`               // 'cash', 'tournament', 'sitng', 'practice' (play chips only)
+	GameType             string         `json:"game_type" gorm:"not null;size:20;default:texas_holdem"` // 'texas_holdem', 'omaha', 'short_deck'
+	HiLo                 bool           `json:"hi_lo" gorm:"not null;default:false"`                    // Split pots between high and eight-or-better low hands
+	MaxPlayers           int            `json:"max_players" gorm:"not null;default:9"`
+	MinBuyIn             int64          `json:"min_buy_in" gorm:"not null"`  // MNT
+	MaxBuyIn             int64          `json:"max_buy_in" gorm:"not null"`  // MNT
+	SmallBlind           int64          `json:"small_blind" gorm:"not null"` // MNT
+	BigBlind             int64          `json:"big_blind" gorm:"not null"`   // MNT
+	IsPrivate            bool           `json:"is_private" gorm:"default:false"`
+	PasswordHash         *string        `json:"-" gorm:"size:255"`
+	Status               string         `json:"status" gorm:"not null;size:20;default:waiting;index"` // 'waiting', 'active', 'finished'
+	CurrentPlayers       int            `json:"current_players" gorm:"default:0"`
+	CreatedBy            uuid.UUID      `json:"created_by" gorm:"type:uuid;not null;index"`
+	Creator              User           `json:"creator,omitempty" gorm:"foreignKey:CreatedBy"`
+	TemplateID           *uuid.UUID     `json:"template_id,omitempty" gorm:"type:uuid;index"`          // Set for tables opened from a stake template
+	TournamentID         *uuid.UUID     `json:"tournament_id,omitempty" gorm:"type:uuid;index"`        // Set for a tournament's tables
+	TournamentTable      int            `json:"tournament_table,omitempty" gorm:"default:0"`           // The table's number within its tournament
+	SeatSelection        string         `json:"seat_selection" gorm:"not null;size:20;default:choice"` // 'choice', 'random'
+	EnforceSeparation    bool           `json:"enforce_separation" gorm:"default:false"`               // Refuse seats to players flagged as a pair with someone seated
+	AllowPartialCashOut  bool           `json:"allow_partial_cash_out" gorm:"default:false"`           // Let players withdraw chips above MaxBuyIn between hands
+	CallTime             *time.Time     `json:"call_time,omitempty"`                                   // Private games: the table closes at this time
+	CallTimeHands        int            `json:"call_time_hands" gorm:"default:0"`                      // Hands still dealt once call time is reached
+	MinPlayMinutes       int            `json:"min_play_minutes" gorm:"default:0"`                     // Private games: how long a big winner must stay before leaving
+	BigWinAmount         int64          `json:"big_win_amount" gorm:"default:0"`                       // MNT profit that counts as a big win for MinPlayMinutes
+	DealersChoice        string         `json:"dealers_choice,omitempty" gorm:"size:100"`              // Private games: comma-separated games the button picks from each hand, empty for off
+	MinPlayers           int            `json:"min_players" gorm:"not null;default:2"`                 // Cash tables: players ready to play needed to keep dealing
+	ShortHandedMinutes   int            `json:"short_handed_minutes" gorm:"not null;default:10"`       // Cash tables: close after this long below MinPlayers, 0 never
+	StackType            string         `json:"stack_type" gorm:"not null;size:20;default:standard"`   // 'standard', 'cap', 'deep'
+	ChipCap              int64          `json:"chip_cap,omitempty" gorm:"default:0"`                   // Cap tables: most MNT a player may have in play, the rest is cashed out between hands
+	ActionTimeoutSeconds int            `json:"action_timeout_seconds" gorm:"not null;default:30"`     // Cash tables: a player who doesn't act in time checks or folds, 0 waits forever
+	SitOutAfterTimeouts  int            `json:"sit_out_after_timeouts" gorm:"not null;default:2"`      // Cash tables: timeouts in a row before the player is sat out, 0 never
+	SitOutCashOutMinutes int            `json:"sit_out_cash_out_minutes" gorm:"not null;default:10"`   // Cash tables: cash out players sitting out this long and free the seat, 0 never
+	RakePercentage       float64        `json:"rake_percentage" gorm:"not null;default:0"`             // Cash tables: share of each pot taken as rake, e.g. 0.05 for 5%
+	RakeCap              int64          `json:"rake_cap" gorm:"not null;default:0"`                    // MNT, most rake taken from one hand, 0 for no cap
+	RakeMinPot           int64          `json:"rake_min_pot" gorm:"not null;default:0"`                // MNT, hands with a smaller pot are not raked
+	ExecutionPath        string         `json:"execution_path" gorm:"not null;size:10;default:legacy"` // 'legacy', 'engine': the game that runs the table's hands from the next time it opens
+	Branding             Branding       `json:"branding" gorm:"embedded;embeddedPrefix:branding_"`
+	RakeFreeUntil        *time.Time     `json:"rake_free_until,omitempty" gorm:"-"` // Set in the lobby while a rake-free window covers the table
+	CreatedAt            time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ZeroedDefaults returns the policy columns set to 0 on a new table whose
+// column default is not 0. Zero is skipped on insert in favour of the
+// default, so these are written once the row exists.
+func (t *PokerTable) ZeroedDefaults() map[string]interface{} {
+	zeroed := map[string]interface{}{}
+	if t.ShortHandedMinutes == 0 {
+		zeroed["short_handed_minutes"] = 0
+	}
+	if t.ActionTimeoutSeconds == 0 {
+		zeroed["action_timeout_seconds"] = 0
+	}
+	if t.SitOutAfterTimeouts == 0 {
+		zeroed["sit_out_after_timeouts"] = 0
+	}
+	if t.SitOutCashOutMinutes == 0 {
+		zeroed["sit_out_cash_out_minutes"] = 0
+	}
+	return zeroed
+}
+
+// Games a table's hands can run through. Tables move to the internal engine
+// one at a time, once it plays the parity suite exactly as the legacy game.
+const (
+	ExecutionPathLegacy = "legacy" // poker.Game
+	ExecutionPathEngine = "engine" // internal/engine
+)
+
+// UpdateExecutionPathRequest moves a table between the legacy game and the
+// internal engine
+type UpdateExecutionPathRequest struct {
+	ExecutionPath string `json:"execution_path" validate:"required,oneof=legacy engine"`
 }
 
 type CreateTableRequest struct {
@@ -42,11 +100,18 @@ type CreateTableRequest struct {
 	Password   string `json:"password,omitempty" validate:"omitempty,min=4"`
 }
 
+// CloneTableRequest names a copy of an existing table. The copy keeps the
+// source's stakes and policies but not its call time.
+type CloneTableRequest struct {
+	Name     string     `json:"name" validate:"required,min=3,max=100"`
+	CallTime *time.Time `json:"call_time,omitempty"` // Private tables only
+}
+
 type Tournament struct {
 	ID                uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	Name              string          `json:"name" gorm:"not null;size:100"`
 	TournamentType    string          `json:"tournament_type" gorm:"not null;size:20;index"` // 'scheduled', 'sitng'
-	BuyIn             int64           `json:"buy_in" gorm:"not null"`                         // MNT
+	BuyIn             int64           `json:"buy_in" gorm:"not null"`                        // MNT
 	PrizePool         int64           `json:"prize_pool" gorm:"default:0"`                   // MNT
 	MaxPlayers        int             `json:"max_players" gorm:"not null"`
 	RegisteredPlayers int             `json:"registered_players" gorm:"default:0"`
@@ -55,6 +120,10 @@ type Tournament struct {
 	EndTime           *time.Time      `json:"end_time"`
 	BlindStructure    json.RawMessage `json:"blind_structure" gorm:"type:jsonb"`
 	PayoutStructure   json.RawMessage `json:"payout_structure" gorm:"type:jsonb"`
+	PayoutModel       json.RawMessage `json:"payout_model,omitempty" gorm:"type:jsonb"`     // Generates PayoutStructure when registration closes
+	StartingChips     int64           `json:"starting_chips" gorm:"not null;default:10000"` // Tournament chips, not MNT
+	CurrentLevel      int             `json:"current_level" gorm:"default:0"`               // 0 until the tournament starts
+	Branding          Branding        `json:"branding" gorm:"embedded;embeddedPrefix:branding_"`
 	CreatedAt         time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt         time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt         gorm.DeletedAt  `json:"-" gorm:"index"`
@@ -68,25 +137,48 @@ type CreateTournamentRequest struct {
 	StartTime       *time.Time      `json:"start_time,omitempty"`
 	BlindStructure  json.RawMessage `json:"blind_structure" validate:"required"`
 	PayoutStructure json.RawMessage `json:"payout_structure" validate:"required"`
+	PayoutModel     *PayoutModel    `json:"payout_model,omitempty"` // Replaces PayoutStructure at registration close
+	StartingChips   int64           `json:"starting_chips,omitempty" validate:"omitempty,min=1"`
+}
+
+// CloneTournamentRequest names and schedules a copy of an existing
+// tournament with the same buy-in, structure and payouts
+type CloneTournamentRequest struct {
+	Name      string     `json:"name" validate:"required,min=3,max=100"`
+	StartTime *time.Time `json:"start_time,omitempty"` // Required for scheduled tournaments
 }
 
 type TournamentRegistration struct {
-	ID                   uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	TournamentID         uuid.UUID      `json:"tournament_id" gorm:"type:uuid;not null;index"`
-	Tournament           Tournament     `json:"tournament,omitempty" gorm:"foreignKey:TournamentID;constraint:OnDelete:CASCADE"`
-	UserID               uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
-	User                 User           `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
-	BuyInTransactionID   *string        `json:"buy_in_transaction_id" gorm:"size:255"`
-	FinalPosition        *int           `json:"final_position"`
-	PrizeAmount          int64          `json:"prize_amount" gorm:"default:0"` // MNT
-	RegisteredAt         time.Time      `json:"registered_at" gorm:"autoCreateTime"`
-	CreatedAt            time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt            time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TournamentID       uuid.UUID      `json:"tournament_id" gorm:"type:uuid;not null;index"`
+	Tournament         Tournament     `json:"tournament,omitempty" gorm:"foreignKey:TournamentID;constraint:OnDelete:CASCADE"`
+	UserID             uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index"`
+	User               User           `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	BuyInTransactionID *string        `json:"buy_in_transaction_id" gorm:"size:255"`
+	FinalPosition      *int           `json:"final_position"`
+	PrizeAmount        int64          `json:"prize_amount" gorm:"default:0"` // MNT
+	TableNumber        *int           `json:"table_number,omitempty"`        // Drawn when the tournament starts
+	SeatNumber         *int           `json:"seat_number,omitempty"`
+	Chips              int64          `json:"chips" gorm:"default:0"`               // Tournament chip count
+	ClientSeed         string         `json:"client_seed,omitempty" gorm:"size:64"` // Spins: the player's part of the prize pool draw
+	RegisteredAt       time.Time      `json:"registered_at" gorm:"autoCreateTime"`
+	CreatedAt          time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TournamentStanding is a player's place in a tournament's chip standings
+type TournamentStanding struct {
+	Rank          int       `json:"rank"`
+	UserID        uuid.UUID `json:"user_id"`
+	Username      string    `json:"username"`
+	Chips         int64     `json:"chips"`
+	TableNumber   *int      `json:"table_number,omitempty"`
+	SeatNumber    *int      `json:"seat_number,omitempty"`
+	FinalPosition *int      `json:"final_position,omitempty"` // Set once eliminated
 }
 
 // Add composite unique index for tournament_id + user_id
 func (TournamentRegistration) TableName() string {
 	return "tournament_registrations"
 }
-
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TableBan keeps a user out of the private tables of the owner who banned
+// them. It is the owner's own list, separate from platform moderation.
+type TableBan struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OwnerID   uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;uniqueIndex:idx_table_ban_pair"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_table_ban_pair;index"`
+	Username  string    `json:"username" gorm:"-"`
+	Reason    string    `json:"reason,omitempty" gorm:"size:500"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BanTableUserRequest adds a user to the owner's ban list, or updates the
+// reason they are on it
+type BanTableUserRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Reason string    `json:"reason,omitempty" validate:"max=500"`
+}
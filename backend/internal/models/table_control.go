@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// TablePlayerControlRequest names the player a moderator is force-folding
+// or removing from a table
+type TablePlayerControlRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Reason string    `json:"reason" validate:"required,max=500"`
+}
+
+// TableControlRequest gives the reason a moderator is pausing a table or
+// cancelling its hand
+type TableControlRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Table lifecycle states, as shown in metrics and the admin live view
+const (
+	TableStateWaitingForPlayers = "waiting_for_players" // Too few ready players to deal
+	TableStateHandRunning       = "hand_running"
+	TableStateSettling          = "settling"           // Hand over, results on show before the next deal is decided
+	TableStateAutoStartPending  = "auto_start_pending" // Next hand decided on and about to be dealt
+	TableStatePaused            = "paused"             // Read-only, past call time or a hand frozen for a dispute
+)
+
+// TableStates lists every lifecycle state
+var TableStates = []string{
+	TableStateWaitingForPlayers,
+	TableStateHandRunning,
+	TableStateSettling,
+	TableStateAutoStartPending,
+	TableStatePaused,
+}
+
+// TableTransition is a table moving from one lifecycle state to another
+type TableTransition struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// LiveTable is an open table as shown in the admin live view, with enough
+// of its recent transitions to tell why the next hand hasn't been dealt
+type LiveTable struct {
+	Table       string            `json:"table"`
+	TableID     *uuid.UUID        `json:"table_id,omitempty"` // The poker_tables row, unset while the table is virtual
+	State       string            `json:"state"`
+	Reason      string            `json:"reason"` // Why the table entered State
+	Since       time.Time         `json:"since"`
+	HandID      string            `json:"hand_id,omitempty"`
+	Clients     int               `json:"clients"`
+	Seated      int               `json:"seated"`
+	Ready       int               `json:"ready"` // Seated, ready and with chips
+	MinPlayers  int               `json:"min_players"`
+	Transitions []TableTransition `json:"transitions"` // Most recent first
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TournamentResult is one finished tournament in a player's history
+type TournamentResult struct {
+	TournamentID   uuid.UUID  `json:"tournament_id"`
+	Name           string     `json:"name"`
+	TournamentType string     `json:"tournament_type"`
+	BuyIn          int64      `json:"buy_in"` // MNT
+	Entrants       int        `json:"entrants"`
+	FinalPosition  *int       `json:"final_position"`
+	PrizeAmount    int64      `json:"prize_amount"` // MNT
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// Cashed reports whether the result paid a prize
+func (r TournamentResult) Cashed() bool {
+	return r.PrizeAmount > 0
+}
+
+// Points is the leaderboard score for this result. Only cashes score, and
+// deep finishes in large fields score the most: 100 * sqrt(entrants / position).
+func (r TournamentResult) Points() int {
+	if !r.Cashed() || r.FinalPosition == nil || *r.FinalPosition <= 0 || r.Entrants <= 0 {
+		return 0
+	}
+	return int(100 * math.Sqrt(float64(r.Entrants)/float64(*r.FinalPosition)))
+}
+
+// TournamentStats summarises a player's finished tournaments
+type TournamentStats struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Entries       int       `json:"entries"`
+	Cashes        int       `json:"cashes"`
+	Wins          int       `json:"wins"`
+	BestFinish    *int      `json:"best_finish"`
+	TotalBuyIns   int64     `json:"total_buy_ins"`  // MNT
+	TotalWinnings int64     `json:"total_winnings"` // MNT
+	ROI           float64   `json:"roi"`            // Percent of buy-ins returned as profit
+	Points        int       `json:"points"`
+}
+
+// Add folds one result into the totals and recomputes ROI
+func (s *TournamentStats) Add(r TournamentResult) {
+	s.Entries++
+	s.TotalBuyIns += r.BuyIn
+	s.TotalWinnings += r.PrizeAmount
+	s.Points += r.Points()
+
+	if r.Cashed() {
+		s.Cashes++
+	}
+	if r.FinalPosition != nil {
+		if *r.FinalPosition == 1 {
+			s.Wins++
+		}
+		if s.BestFinish == nil || *r.FinalPosition < *s.BestFinish {
+			best := *r.FinalPosition
+			s.BestFinish = &best
+		}
+	}
+
+	if s.TotalBuyIns > 0 {
+		s.ROI = math.Round(float64(s.TotalWinnings-s.TotalBuyIns)/float64(s.TotalBuyIns)*10000) / 100
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// TreasuryAsset is where the money in one ledger asset sits. Liabilities are
+// what the house owes players; house funds are its own. Every ledger account
+// is counted once, so liabilities, house funds, other and world add to zero.
+type TreasuryAsset struct {
+	Asset              string           `json:"asset"`
+	WalletLiabilities  int64            `json:"wallet_liabilities"` // In player wallets
+	SessionFunds       int64            `json:"session_funds"`      // Bought in to game sessions
+	TournamentEscrow   int64            `json:"tournament_escrow"`  // Tournament prize pools not yet paid
+	LastLongerEscrow   int64            `json:"last_longer_escrow"` // Last-longer stakes not yet settled
+	PendingWithdrawals int64            `json:"pending_withdrawals"`
+	Liabilities        int64            `json:"liabilities"`
+	House              int64            `json:"house"`
+	SpinJackpot        int64            `json:"spin_jackpot"`
+	Revenue            map[string]int64 `json:"revenue"` // By revenue account
+	RevenueTotal       int64            `json:"revenue_total"`
+	HouseFunds         int64            `json:"house_funds"`  // House, spin jackpot and revenue
+	Other              int64            `json:"other"`        // Accounts that fit none of the above
+	World              int64            `json:"world"`        // Minus what has come into the ledger from outside
+	NetExposure        int64            `json:"net_exposure"` // Liabilities less house funds
+}
+
+// TreasurySummary is the finance overview of the ledger, per asset
+type TreasurySummary struct {
+	Assets   []TreasuryAsset `json:"assets"`
+	Accounts int             `json:"accounts"` // Ledger accounts counted
+	// Bank transfers received but not yet credited to a wallet, in MNT
+	DepositsInReview      int64     `json:"deposits_in_review"`
+	DepositsInReviewCount int       `json:"deposits_in_review_count"`
+	GeneratedAt           time.Time `json:"generated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TutorialCompletion records that a user finished the new player tutorial and
+// the play chips they were given for it. Each user is rewarded only once.
+type TutorialCompletion struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	RewardChips int64     `json:"reward_chips" gorm:"not null"` // Play chips
+	CompletedAt time.Time `json:"completed_at" gorm:"autoCreateTime"`
+}
@@ -11,11 +11,21 @@ import (
 type UserRole string
 
 const (
-	UserRolePlayer UserRole = "player"
-	UserRoleMod    UserRole = "moderator"
-	UserRoleAdmin  UserRole = "admin"
+	UserRolePlayer  UserRole = "player"
+	UserRoleMod     UserRole = "moderator"
+	UserRoleFinance UserRole = "finance"
+	UserRoleAdmin   UserRole = "admin"
 )
 
+// SystemUserID is the account that owns what the platform creates on its
+// own, like tables first opened over the WebSocket. It is disabled, so
+// nobody can sign in to it.
+var SystemUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// SystemUsername is the system account's username. Player usernames can't
+// contain a hyphen, so it is never taken.
+const SystemUsername = "system-owner"
+
 type User struct {
 	ID                  uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	Email               string         `json:"email" gorm:"uniqueIndex;not null;size:255"`
@@ -23,10 +33,15 @@ type User struct {
 	PasswordHash        string         `json:"-" gorm:"not null;size:255"`
 	Role                UserRole       `json:"role" gorm:"type:varchar(20);default:'player'"`
 	IsVerified          bool           `json:"is_verified" gorm:"default:false"`
+	KYCTier             int            `json:"kyc_tier" gorm:"default:0"` // Identity checks passed, sets deposit and withdrawal caps
 	FormanceAccountID   *string        `json:"formance_account_id,omitempty" gorm:"uniqueIndex;size:255"`
 	AvatarURL           *string        `json:"avatar_url,omitempty" gorm:"size:500"`
 	TotalHandsPlayed    int            `json:"total_hands_played" gorm:"default:0"`
 	TotalWinnings       int64          `json:"total_winnings" gorm:"default:0"` // MNT
+	PlayChips           int64          `json:"play_chips" gorm:"default:0"` // Play-money balance, never convertible to MNT
+	DisabledAt          *time.Time     `json:"disabled_at,omitempty"` // Set when the account can no longer sign in
+	MergedIntoID        *uuid.UUID     `json:"merged_into_id,omitempty" gorm:"type:uuid"` // Surviving account of a merged duplicate
+	SessionsRevokedAt   *time.Time     `json:"-"` // Sign-ins that started before this no longer work, e.g. after a password reset
 	CreatedAt           time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt           time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
@@ -36,6 +51,8 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Username string `json:"username" validate:"required,min=3,max=50,username"`
 	Password string `json:"password" validate:"required,min=8,strong_password"`
+	// Code of the affiliate who referred the player, if any
+	ReferralCode string `json:"referral_code,omitempty" validate:"omitempty,max=20"`
 }
 
 type LoginRequest struct {
@@ -46,6 +63,22 @@ type LoginRequest struct {
 type LoginResponse struct {
 	User  User   `json:"user"`
 	Token string `json:"token"`
+	SessionExpiry
+	// Sessions cashed out because their table no longer exists
+	RecoveredSessions []RecoveredSession `json:"recovered_sessions,omitempty"`
+}
+
+// SessionExpiry tells the client when its token expires unless it is
+// renewed by activity, and when the session ends however active the user is
+type SessionExpiry struct {
+	ExpiresAt        time.Time  `json:"expires_at"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"` // Unset when sessions have no maximum lifetime
+}
+
+// SessionToken is a renewed token for the current session
+type SessionToken struct {
+	Token string `json:"token"`
+	SessionExpiry
 }
 
 type EmailVerification struct {
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession is one of a player's active game sessions, with the chips it
+// holds right now
+type UserSession struct {
+	SessionID      uuid.UUID `json:"session_id"`
+	TableID        uuid.UUID `json:"table_id"`
+	TableName      string    `json:"table_name"`
+	SeatNumber     *int      `json:"seat_number,omitempty"`
+	BuyIn          int64     `json:"buy_in"`  // MNT
+	Balance        int64     `json:"balance"` // MNT in the session account
+	Live           bool      `json:"live"`    // Seated at a running table
+	HandInProgress bool      `json:"hand_in_progress"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
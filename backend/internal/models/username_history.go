@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsernameHistory records a username a user gave up. Hand histories, chat
+// logs and reports keep names as they were written, so old names are
+// resolved back to their user through this table.
+type UsernameHistory struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	OldUsername   string    `json:"old_username" gorm:"not null;size:50;index"`
+	NewUsername   string    `json:"new_username" gorm:"not null;size:50"`
+	ChangedAt     time.Time `json:"changed_at" gorm:"not null"`
+	ReservedUntil time.Time `json:"reserved_until" gorm:"not null"` // Nobody else may take OldUsername before then
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50,username"`
+}
+
+// ChangeUsernameResponse carries a fresh token, since the old one still
+// names the user by their old username
+type ChangeUsernameResponse struct {
+	User         User      `json:"user"`
+	Token        string    `json:"token"`
+	NextChangeAt time.Time `json:"next_change_at"`
+}
+
+// UsernameResolution is the user behind a username, current or former
+type UsernameResolution struct {
+	Username        string     `json:"username"`
+	UserID          uuid.UUID  `json:"user_id"`
+	CurrentUsername string     `json:"current_username"`
+	Current         bool       `json:"current"`
+	ChangedAt       *time.Time `json:"changed_at,omitempty"` // When the user gave up the name
+}
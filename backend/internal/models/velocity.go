@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Wallet operations subject to velocity limits
+const (
+	WalletOperationDeposit    = "deposit"
+	WalletOperationWithdrawal = "withdrawal"
+)
+
+// KYC tiers, from unverified up to fully verified identity
+const (
+	KYCTierNone  = 0
+	KYCTierBasic = 1
+	KYCTierFull  = 2
+)
+
+// WalletOperation records a deposit or withdrawal so velocity limits can be
+// checked without querying the ledger. It is reserved before the money
+// moves and counts against the limits from then on.
+type WalletOperation struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_wallet_operations_user_time"`
+	Operation     string     `json:"operation" gorm:"not null;size:20"`
+	Amount        int64      `json:"amount" gorm:"not null"`                 // MNT
+	TransactionID string     `json:"transaction_id" gorm:"size:255"`         // Empty until the money has moved
+	OverrideID    *uuid.UUID `json:"override_id,omitempty" gorm:"type:uuid"` // Set when an admin override let it through
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime;index:idx_wallet_operations_user_time"`
+}
+
+// VelocityOverride lifts a user's velocity limits for one kind of operation
+// until it expires or is revoked
+type VelocityOverride struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Operation string     `json:"operation" gorm:"not null;size:20"`
+	Reason    string     `json:"reason" gorm:"not null;size:500"`
+	GrantedBy uuid.UUID  `json:"granted_by" gorm:"type:uuid;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// VelocityUsage shows how much of one operation's limits a user has used
+type VelocityUsage struct {
+	Operation     string     `json:"operation"`
+	LastHourCount int        `json:"last_hour_count"`
+	LastDayCount  int        `json:"last_day_count"`
+	LastDayAmount int64      `json:"last_day_amount"` // MNT
+	HourlyLimit   int        `json:"hourly_limit,omitempty"`
+	DailyLimit    int        `json:"daily_limit"`
+	DailyCap      int64      `json:"daily_cap"` // MNT, by KYC tier
+	NextAllowedAt *time.Time `json:"next_allowed_at,omitempty"`
+	OverrideUntil *time.Time `json:"override_until,omitempty"`
+}
+
+// VelocitySummary is a user's KYC tier and their usage of each limit
+type VelocitySummary struct {
+	KYCTier     int           `json:"kyc_tier"`
+	Deposits    VelocityUsage `json:"deposits"`
+	Withdrawals VelocityUsage `json:"withdrawals"`
+}
+
+type GrantVelocityOverrideRequest struct {
+	Operation string `json:"operation" validate:"required,oneof=deposit withdrawal"`
+	Hours     int    `json:"hours" validate:"required,min=1,max=72"`
+	Reason    string `json:"reason" validate:"required,max=500"`
+}
+
+type UpdateKYCTierRequest struct {
+	KYCTier int `json:"kyc_tier" validate:"min=0,max=2"`
+}
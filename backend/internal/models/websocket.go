@@ -0,0 +1,13 @@
+package models
+
+// SendQueueStats summarises the outbound WebSocket queues of connected clients
+type SendQueueStats struct {
+	QueueSize int   `json:"queue_size"` // Messages each client may have waiting
+	Queued    int64 `json:"queued"`     // Messages waiting across all clients right now
+	PeakDepth int64 `json:"peak_depth"` // Deepest any single client's queue has been
+	Enqueued  int64 `json:"enqueued"`
+	Delivered int64 `json:"delivered"`
+	Coalesced int64 `json:"coalesced"` // Game views replaced by a newer one before they were sent
+	Dropped   int64 `json:"dropped"`   // Chat, logs and stale game views shed from full queues
+	Overflows int64 `json:"overflows"` // Clients disconnected for falling too far behind
+}
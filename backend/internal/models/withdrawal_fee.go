@@ -0,0 +1,24 @@
+package models
+
+// WithdrawalFeeTerms are the minimum and fees that apply to a user's
+// withdrawals of one asset
+type WithdrawalFeeTerms struct {
+	Asset     string `json:"asset"`
+	KYCTier   int    `json:"kyc_tier"`
+	Minimum   int64  `json:"minimum"`
+	FlatFee   int64  `json:"flat_fee"`
+	FeeBps    int64  `json:"fee_bps"`              // Percentage fee in basis points, 100 = 1%
+	FreeAbove int64  `json:"free_above,omitempty"` // Withdrawals of at least this much are free, 0 never
+}
+
+// WithdrawalQuote itemizes a withdrawal: the full amount leaves the wallet,
+// the fee goes to the platform and the net amount is paid out
+type WithdrawalQuote struct {
+	Asset      string `json:"asset"`
+	Amount     int64  `json:"amount"`
+	FlatFee    int64  `json:"flat_fee"`
+	PercentFee int64  `json:"percent_fee"`
+	Fee        int64  `json:"fee"`
+	FeeWaived  bool   `json:"fee_waived"`
+	NetAmount  int64  `json:"net_amount"`
+}
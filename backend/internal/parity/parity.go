@@ -0,0 +1,439 @@
+// Package parity plays the same scripted hands through the legacy riverboat
+// game in package poker and the internal engine's game, and reports every
+// point where the two disagree about stacks, chips committed, folds or when
+// the hand is over. Tables are only moved to the engine once it plays the
+// suite exactly as the legacy game does.
+package parity
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// ActionKind is a player decision in a script
+type ActionKind string
+
+const (
+	ActionCheck ActionKind = "check"
+	ActionCall  ActionKind = "call"
+	ActionBet   ActionKind = "bet" // Opening or raising
+	ActionFold  ActionKind = "fold"
+)
+
+// Step is one decision by the player in Seat
+type Step struct {
+	Seat   int
+	Action ActionKind
+	Amount int64 // Bets only: the chips added to what the player already has in this round
+}
+
+// Script is one hand dealt from a known deck. Seat 0 has the button, and
+// heads up the button posts the small blind. Steps must be in turn order:
+// the legacy game enforces it and the engine leaves it to its caller.
+type Script struct {
+	Name       string
+	GameType   string // A table game type, Hold'em when empty
+	SmallBlind int64
+	BigBlind   int64
+	Stacks     []int64    // One per seat
+	Holes      [][]string // Each seat's hole cards, e.g. {"As", "Kd"}
+	Board      []string   // Flop, turn and river
+	Steps      []Step
+}
+
+// Diff is a point where the two games disagree. Step 0 is the deal and step
+// n is after the nth step of the script.
+type Diff struct {
+	Step   int    `json:"step"`
+	Seat   int    `json:"seat"` // -1 for the whole table
+	Field  string `json:"field"`
+	Legacy string `json:"legacy"`
+	Engine string `json:"engine"`
+}
+
+func (d Diff) String() string {
+	if d.Seat < 0 {
+		return fmt.Sprintf("step %d: %s: legacy %s, engine %s", d.Step, d.Field, d.Legacy, d.Engine)
+	}
+	return fmt.Sprintf("step %d seat %d: %s: legacy %s, engine %s", d.Step, d.Seat, d.Field, d.Legacy, d.Engine)
+}
+
+// Report is the outcome of running one script
+type Report struct {
+	Script string `json:"script"`
+	Diffs  []Diff `json:"diffs,omitempty"`
+}
+
+// Agree reports whether both games played the script the same way
+func (r Report) Agree() bool {
+	return len(r.Diffs) == 0
+}
+
+var ErrInvalidScript = errors.New("invalid parity script")
+
+// Run plays the script through both games, comparing them after the deal
+// and after every step. A step only one game accepts is reported and ends
+// the run, since the games have nothing left in common to compare.
+func Run(s Script) (Report, error) {
+	report := Report{Script: s.Name}
+
+	legacy, err := newLegacyTable(s)
+	if err != nil {
+		return report, err
+	}
+	engine, err := newEngineTable(s)
+	if err != nil {
+		return report, err
+	}
+
+	report.Diffs = compare(0, legacy.snapshot(), engine.snapshot())
+
+	for i, step := range s.Steps {
+		legacyErr := legacy.act(step)
+		engineErr := engine.act(step)
+		if (legacyErr == nil) != (engineErr == nil) {
+			report.Diffs = append(report.Diffs, Diff{
+				Step:   i + 1,
+				Seat:   step.Seat,
+				Field:  "action " + string(step.Action),
+				Legacy: outcome(legacyErr),
+				Engine: outcome(engineErr),
+			})
+			break
+		}
+
+		report.Diffs = append(report.Diffs, compare(i+1, legacy.snapshot(), engine.snapshot())...)
+	}
+
+	return report, nil
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "rejected: " + err.Error()
+	}
+	return "accepted"
+}
+
+// snapshot is the state compared between the two games. Only stacks are
+// compared once the hand is over, as the games clear up differently.
+type snapshot struct {
+	handOver  bool
+	stacks    []int64
+	committed []int64 // Chips put in this hand
+	folded    []bool
+}
+
+func compare(step int, legacy, engine snapshot) []Diff {
+	var diffs []Diff
+	add := func(seat int, field string, l, e interface{}) {
+		diffs = append(diffs, Diff{Step: step, Seat: seat, Field: field, Legacy: fmt.Sprint(l), Engine: fmt.Sprint(e)})
+	}
+
+	if legacy.handOver != engine.handOver {
+		add(-1, "hand over", legacy.handOver, engine.handOver)
+	}
+	for seat := range legacy.stacks {
+		if legacy.stacks[seat] != engine.stacks[seat] {
+			add(seat, "stack", legacy.stacks[seat], engine.stacks[seat])
+		}
+		if legacy.handOver || engine.handOver {
+			continue
+		}
+		if legacy.committed[seat] != engine.committed[seat] {
+			add(seat, "committed", legacy.committed[seat], engine.committed[seat])
+		}
+		if legacy.folded[seat] != engine.folded[seat] {
+			add(seat, "folded", legacy.folded[seat], engine.folded[seat])
+		}
+	}
+
+	if !legacy.handOver && !engine.handOver {
+		var legacyPot, enginePot int64
+		for seat := range legacy.committed {
+			legacyPot += legacy.committed[seat]
+			enginePot += engine.committed[seat]
+		}
+		if legacyPot != enginePot {
+			add(-1, "pot", legacyPot, enginePot)
+		}
+	}
+
+	return diffs
+}
+
+// legacyTable is the script dealt by poker.Game
+type legacyTable struct {
+	g *poker.Game
+}
+
+func newLegacyTable(s Script) (*legacyTable, error) {
+	if err := validate(s); err != nil {
+		return nil, err
+	}
+
+	g := poker.NewGame()
+	config := poker.GameConfig{SmallBlind: uint(s.SmallBlind), BigBlind: uint(s.BigBlind), Variant: poker.Variant(s.GameType)}
+	if err := g.SetConfig(config); err != nil {
+		return nil, fmt.Errorf("legacy config: %w", err)
+	}
+
+	for pn := uint(0); pn < uint(len(s.Stacks)); pn++ {
+		g.AddPlayer()
+		if err := poker.BuyIn(g, pn, uint(s.Stacks[pn])); err != nil {
+			return nil, fmt.Errorf("legacy buy in: %w", err)
+		}
+		// Seats follow player numbers, so seating doesn't reorder anyone
+		if err := poker.SetSeatID(g, pn, pn+1); err != nil {
+			return nil, fmt.Errorf("legacy seat: %w", err)
+		}
+		if err := poker.ToggleReady(g, pn, 0); err != nil {
+			return nil, fmt.Errorf("legacy ready: %w", err)
+		}
+	}
+
+	var order []eval.Card
+	for _, hole := range s.Holes {
+		for _, card := range hole {
+			order = append(order, eval.MustParseCardString(card))
+		}
+	}
+	for _, card := range s.Board {
+		order = append(order, eval.MustParseCardString(card))
+	}
+	if err := g.StackDeck(order); err != nil {
+		return nil, fmt.Errorf("legacy deck: %w", err)
+	}
+
+	if err := g.Start(); err != nil {
+		return nil, fmt.Errorf("legacy deal: %w", err)
+	}
+	return &legacyTable{g: g}, nil
+}
+
+func (l *legacyTable) act(step Step) error {
+	pn := uint(step.Seat)
+
+	switch step.Action {
+	case ActionCheck:
+		return poker.Bet(l.g, pn, 0)
+	case ActionCall:
+		view := l.g.GenerateOmniView()
+		var maxBet uint
+		for _, p := range view.Players {
+			if p.Bet > maxBet {
+				maxBet = p.Bet
+			}
+		}
+		toCall := maxBet - view.Players[pn].Bet
+		if toCall > view.Players[pn].Stack {
+			toCall = view.Players[pn].Stack
+		}
+		return poker.Bet(l.g, pn, toCall)
+	case ActionBet:
+		return poker.Bet(l.g, pn, uint(step.Amount))
+	case ActionFold:
+		return poker.Fold(l.g, pn, 0)
+	}
+	return fmt.Errorf("%w: unknown action %q", ErrInvalidScript, step.Action)
+}
+
+func (l *legacyTable) snapshot() snapshot {
+	view := l.g.GenerateOmniView()
+	s := snapshot{handOver: !view.Running}
+	for _, p := range view.Players {
+		s.stacks = append(s.stacks, int64(p.Stack))
+		s.committed = append(s.committed, int64(p.TotalBet+p.Ante))
+		s.folded = append(s.folded, !p.In)
+	}
+	return s
+}
+
+// engineTable is the script dealt by the internal engine's game. The engine
+// leaves dealing the streets and ending the hand to its caller, so this does
+// it the way the legacy game does: once everyone still in has acted.
+type engineTable struct {
+	g       *game.Game
+	players []string // Player IDs by seat
+}
+
+func newEngineTable(s Script) (*engineTable, error) {
+	variant, err := game.ParseVariant(s.GameType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+
+	g := game.NewGame(uuid.New(), s.SmallBlind, s.BigBlind, len(s.Stacks))
+	g.Variant = variant
+
+	e := &engineTable{g: g}
+	for seat, stack := range s.Stacks {
+		playerID := uuid.NewString()
+		if err := g.Actions.AddPlayer(g, playerID, fmt.Sprintf("seat %d", seat), seat+1, stack); err != nil {
+			return nil, fmt.Errorf("engine seat: %w", err)
+		}
+		e.players = append(e.players, playerID)
+	}
+
+	order, err := engineDeck(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Deck.Stack(order); err != nil {
+		return nil, fmt.Errorf("engine deck: %w", err)
+	}
+
+	if err := g.Actions.StartHand(g); err != nil {
+		return nil, fmt.Errorf("engine deal: %w", err)
+	}
+	return e, nil
+}
+
+// engineDeck is the script's deck in the order the engine deals it, which
+// burns a card before each street where the legacy game doesn't
+func engineDeck(s Script) ([]game.Card, error) {
+	var order []game.Card
+	used := make(map[game.Card]bool)
+	take := func(text string) error {
+		card, err := game.ParseCard(text)
+		if err != nil {
+			return fmt.Errorf("%w: card %q: %v", ErrInvalidScript, text, err)
+		}
+		order = append(order, card)
+		used[card] = true
+		return nil
+	}
+
+	for _, hole := range s.Holes {
+		for _, card := range hole {
+			if err := take(card); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, card := range s.Board {
+		if err := take(card); err != nil {
+			return nil, err
+		}
+	}
+
+	var burns []game.Card
+	for _, rank := range "23456789TJQKA" {
+		for _, suit := range "shdc" {
+			card, _ := game.ParseCard(string(rank) + string(suit))
+			if !used[card] && len(burns) < 3 {
+				burns = append(burns, card)
+			}
+		}
+	}
+
+	holes := len(order) - len(s.Board)
+	board := order[holes:]
+	deck := append([]game.Card{}, order[:holes]...)
+	deck = append(deck, burns[0], board[0], board[1], board[2])
+	deck = append(deck, burns[1], board[3])
+	deck = append(deck, burns[2], board[4])
+	return deck, nil
+}
+
+func (e *engineTable) act(step Step) error {
+	g := e.g
+	playerID := e.players[step.Seat]
+
+	var err error
+	switch step.Action {
+	case ActionCheck:
+		err = g.Actions.PlayerCheck(g, playerID)
+	case ActionCall:
+		err = g.Actions.PlayerBet(g, playerID, g.Actions.CallAmount(g, g.Players[step.Seat]))
+	case ActionBet:
+		err = g.Actions.PlayerBet(g, playerID, step.Amount)
+	case ActionFold:
+		err = g.Actions.PlayerFold(g, playerID)
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidScript, step.Action)
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.advance()
+}
+
+// advance deals the next street once betting on this one is done, and ends
+// the hand after the river or when only one player is left
+func (e *engineTable) advance() error {
+	g := e.g
+	for g.IsRunning && g.IsActionComplete() {
+		if len(g.GetPlayersInHand()) < 2 || g.Stage == game.River {
+			return g.Actions.EndHand(g)
+		}
+		if err := g.Actions.DealCards(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *engineTable) snapshot() snapshot {
+	s := snapshot{handOver: !e.g.IsRunning}
+	for _, p := range e.g.Players {
+		s.stacks = append(s.stacks, p.Chips)
+		s.committed = append(s.committed, p.TotalBet)
+		s.folded = append(s.folded, p.IsFolded)
+	}
+	return s
+}
+
+// validate checks the script deals each seat a hand and a full board, so
+// neither game is asked to deal from a deck it wasn't given
+func validate(s Script) error {
+	variant, err := game.ParseVariant(s.GameType)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+	if len(s.Stacks) < 2 || len(s.Holes) != len(s.Stacks) {
+		return fmt.Errorf("%w: needs a stack and hole cards for each of at least two seats", ErrInvalidScript)
+	}
+	if s.SmallBlind <= 0 || s.BigBlind < s.SmallBlind {
+		return fmt.Errorf("%w: invalid blinds", ErrInvalidScript)
+	}
+	for seat, hole := range s.Holes {
+		if len(hole) != variant.HoleCards() {
+			return fmt.Errorf("%w: seat %d needs %d hole cards", ErrInvalidScript, seat, variant.HoleCards())
+		}
+		if s.Stacks[seat] <= 0 {
+			return fmt.Errorf("%w: seat %d has no chips", ErrInvalidScript, seat)
+		}
+	}
+	if len(s.Board) != 5 {
+		return fmt.Errorf("%w: the board needs five cards", ErrInvalidScript)
+	}
+	for _, step := range s.Steps {
+		if step.Seat < 0 || step.Seat >= len(s.Stacks) {
+			return fmt.Errorf("%w: no seat %d", ErrInvalidScript, step.Seat)
+		}
+	}
+
+	seen := make(map[string]bool)
+	cards := append([]string{}, s.Board...)
+	for _, hole := range s.Holes {
+		cards = append(cards, hole...)
+	}
+	for _, text := range cards {
+		card, err := eval.ParseCardBytes([]byte(text))
+		if err != nil {
+			return fmt.Errorf("%w: card %q: %v", ErrInvalidScript, text, err)
+		}
+		if seen[card.String()] {
+			return fmt.Errorf("%w: card %s dealt twice", ErrInvalidScript, text)
+		}
+		seen[card.String()] = true
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package parity
+
+import "github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+
+// Suite is the set of hands a table's game must play identically in both
+// games before the table is moved to the engine. Bets are sized from the big
+// blind so every script is legal at any stakes, including pot limit.
+func Suite(gameType string, smallBlind, bigBlind int64) []Script {
+	omaha := gameType == string(game.VariantOmaha)
+	holes := func(holdem, plo [][]string) [][]string {
+		if omaha {
+			return plo
+		}
+		return holdem
+	}
+	bb := bigBlind
+
+	script := func(name string, stacks []int64, holes [][]string, board []string, steps ...Step) Script {
+		return Script{
+			Name:       name,
+			GameType:   gameType,
+			SmallBlind: smallBlind,
+			BigBlind:   bigBlind,
+			Stacks:     stacks,
+			Holes:      holes,
+			Board:      board,
+			Steps:      steps,
+		}
+	}
+
+	return []Script{
+		// Heads up the button posts the small blind and acts first
+		script("button folds to the big blind",
+			[]int64{100 * bb, 100 * bb},
+			holes(
+				[][]string{{"7c", "2d"}, {"Ah", "Kh"}},
+				[][]string{{"7c", "2d", "3s", "8h"}, {"Ah", "Kh", "Qd", "Jd"}},
+			),
+			[]string{"9s", "9d", "4c", "Th", "5s"},
+			Step{Seat: 0, Action: ActionFold},
+		),
+
+		script("three way checked down to a showdown",
+			[]int64{100 * bb, 80 * bb, 120 * bb},
+			holes(
+				[][]string{{"Ac", "Ad"}, {"Kc", "Qc"}, {"7h", "6h"}},
+				[][]string{{"Ac", "Ad", "2s", "3h"}, {"Kc", "Qc", "8d", "8s"}, {"7h", "6h", "5c", "4d"}},
+			),
+			[]string{"As", "Jh", "2c", "9d", "3c"},
+			// Preflop: under the gun is the button, three handed
+			Step{Seat: 0, Action: ActionCall},
+			Step{Seat: 1, Action: ActionCall},
+			Step{Seat: 2, Action: ActionCheck},
+			// Flop, turn and river: first to act is left of the button
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 2, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 2, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 2, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+		),
+
+		script("raise and take it down on the flop",
+			[]int64{100 * bb, 100 * bb, 100 * bb},
+			holes(
+				[][]string{{"Qs", "Qh"}, {"8c", "3d"}, {"Jd", "Td"}},
+				[][]string{{"Qs", "Qh", "9c", "9h"}, {"8c", "3d", "2h", "7s"}, {"Jd", "Td", "6c", "6s"}},
+			),
+			[]string{"Kd", "7c", "2s", "4h", "4s"},
+			Step{Seat: 0, Action: ActionBet, Amount: 3 * bb},
+			Step{Seat: 1, Action: ActionFold},
+			Step{Seat: 2, Action: ActionCall},
+			Step{Seat: 2, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionBet, Amount: 4 * bb},
+			Step{Seat: 2, Action: ActionFold},
+		),
+
+		script("same straight and the pot is split",
+			[]int64{100 * bb, 100 * bb},
+			holes(
+				[][]string{{"2c", "3d"}, {"4h", "5c"}},
+				[][]string{{"Ac", "Kd", "2c", "3d"}, {"Ad", "Kc", "2h", "3h"}},
+			),
+			[]string{"As", "Ks", "Qh", "Jd", "Th"},
+			Step{Seat: 0, Action: ActionCall},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+			Step{Seat: 1, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+		),
+
+		script("bets called down to a showdown",
+			[]int64{100 * bb, 100 * bb, 100 * bb},
+			holes(
+				[][]string{{"Jc", "Js"}, {"9h", "8h"}, {"Ah", "Qd"}},
+				[][]string{{"Jc", "Js", "5h", "6d"}, {"9h", "8h", "Tc", "2d"}, {"Ah", "Qd", "Ac", "3s"}},
+			),
+			[]string{"Jh", "Qc", "4d", "2s", "7d"},
+			Step{Seat: 0, Action: ActionCall},
+			Step{Seat: 1, Action: ActionCall},
+			Step{Seat: 2, Action: ActionBet, Amount: 2 * bb},
+			Step{Seat: 0, Action: ActionCall},
+			Step{Seat: 1, Action: ActionFold},
+			// Flop: the small blind folded, so the big blind is first
+			Step{Seat: 2, Action: ActionBet, Amount: 3 * bb},
+			Step{Seat: 0, Action: ActionCall},
+			Step{Seat: 2, Action: ActionCheck},
+			Step{Seat: 0, Action: ActionCheck},
+			Step{Seat: 2, Action: ActionBet, Amount: 6 * bb},
+			Step{Seat: 0, Action: ActionCall},
+		),
+	}
+}
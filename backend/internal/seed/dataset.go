@@ -0,0 +1,98 @@
+// Package seed provisions demo data, so staging environments and new
+// developer setups have players, tables and tournaments to try out. Every
+// operation is a dry run unless Apply is set, and records that already exist
+// are left alone, so it is safe to run again.
+package seed
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrProductionSeed is returned when asked for demo data in production
+var ErrProductionSeed = errors.New("demo data is never seeded into production")
+
+// DemoUser is a demo account. Every demo account shares the password given
+// to the seeder.
+type DemoUser struct {
+	Username string
+	Admin    bool
+	Balance  int64 // MNT funded into the wallet
+}
+
+// DemoTable is a public cash table
+type DemoTable struct {
+	Name       string
+	GameType   string // 'texas_holdem', 'omaha', 'short_deck'
+	SmallBlind int64  // MNT
+	BigBlind   int64  // MNT
+	MaxPlayers int
+}
+
+// DemoTournament is a scheduled tournament. Finished tournaments are seeded
+// with every demo player registered and placed in the order they are listed.
+type DemoTournament struct {
+	Name       string
+	BuyIn      int64 // MNT
+	MaxPlayers int
+	Finished   bool
+}
+
+// Dataset is the demo data for one environment
+type Dataset struct {
+	Environment string
+	EmailDomain string // Demo accounts are <username>@EmailDomain
+	Users       []DemoUser
+	Tables      []DemoTable
+	Tournaments []DemoTournament
+}
+
+// demoTables spread across the stakes, from micro to high
+var demoTables = []DemoTable{
+	{Name: "Demo Micro", GameType: "texas_holdem", SmallBlind: 50, BigBlind: 100, MaxPlayers: 9},
+	{Name: "Demo Low", GameType: "texas_holdem", SmallBlind: 250, BigBlind: 500, MaxPlayers: 9},
+	{Name: "Demo Mid", GameType: "texas_holdem", SmallBlind: 1000, BigBlind: 2000, MaxPlayers: 6},
+	{Name: "Demo High", GameType: "texas_holdem", SmallBlind: 5000, BigBlind: 10000, MaxPlayers: 6},
+	{Name: "Demo PLO", GameType: "omaha", SmallBlind: 500, BigBlind: 1000, MaxPlayers: 6},
+	{Name: "Demo Short Deck", GameType: "short_deck", SmallBlind: 500, BigBlind: 1000, MaxPlayers: 6},
+}
+
+var demoTournaments = []DemoTournament{
+	{Name: "Demo Sunday Special", BuyIn: 10000, MaxPlayers: 100},
+	{Name: "Demo Weekly Classic", BuyIn: 5000, MaxPlayers: 50, Finished: true},
+}
+
+// ForEnvironment returns the demo data for an environment
+func ForEnvironment(environment string) (Dataset, error) {
+	switch environment {
+	case "development":
+		return Dataset{
+			Environment: environment,
+			EmailDomain: "demo.localhost",
+			Users:       demoUsers(6, 1_000_000),
+			Tables:      demoTables,
+			Tournaments: demoTournaments,
+		}, nil
+	case "staging":
+		return Dataset{
+			Environment: environment,
+			EmailDomain: "demo.staging.invalid",
+			Users:       demoUsers(20, 5_000_000),
+			Tables:      demoTables,
+			Tournaments: demoTournaments,
+		}, nil
+	case "production":
+		return Dataset{}, ErrProductionSeed
+	default:
+		return Dataset{}, fmt.Errorf("no demo data for environment %q", environment)
+	}
+}
+
+// demoUsers returns an admin and the given number of funded players
+func demoUsers(players int, balance int64) []DemoUser {
+	users := []DemoUser{{Username: "demo_admin", Admin: true, Balance: balance}}
+	for i := 1; i <= players; i++ {
+		users = append(users, DemoUser{Username: fmt.Sprintf("demo_player%d", i), Balance: balance})
+	}
+	return users
+}
@@ -0,0 +1,346 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"gorm.io/gorm"
+)
+
+// Structures the demo tournaments are played with
+var (
+	demoBlindStructure = json.RawMessage(`[
+		{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 600},
+		{"level": 2, "small_blind": 50, "big_blind": 100, "duration": 600},
+		{"level": 3, "small_blind": 100, "big_blind": 200, "duration": 600},
+		{"level": 4, "small_blind": 150, "big_blind": 300, "ante": 300, "big_blind_ante": true, "duration": 600},
+		{"level": 5, "small_blind": 200, "big_blind": 400, "ante": 400, "big_blind_ante": true, "duration": 600}
+	]`)
+	demoPayoutStructure = json.RawMessage(`[
+		{"position": 1, "percentage": 60},
+		{"position": 2, "percentage": 30},
+		{"position": 3, "percentage": 10}
+	]`)
+)
+
+// Seeder provisions a dataset
+type Seeder struct {
+	db       *gorm.DB
+	ledger   *formance.Client
+	password string
+	apply    bool
+	out      io.Writer
+	now      func() time.Time
+}
+
+// New creates a seeder. ledger may be nil to seed accounts without funding
+// their wallets. Demo accounts are created with password. Without apply, the
+// seeder only reports what it would create.
+func New(db *gorm.DB, ledger *formance.Client, password string, apply bool, out io.Writer) *Seeder {
+	return &Seeder{
+		db:       db,
+		ledger:   ledger,
+		password: password,
+		apply:    apply,
+		out:      out,
+		now:      time.Now,
+	}
+}
+
+// Report summarises a seeding run
+type Report struct {
+	Created int
+	Skipped int // Already there
+	Failed  int
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("seed: created=%d skipped=%d failed=%d", r.Created, r.Skipped, r.Failed)
+}
+
+// logf writes a progress line, prefixed so dry runs are obvious in the output
+func (s *Seeder) logf(format string, args ...interface{}) {
+	prefix := "[dry-run] "
+	if s.apply {
+		prefix = ""
+	}
+	fmt.Fprintf(s.out, prefix+format+"\n", args...)
+}
+
+// Run seeds the dataset. Failures seeding one record are reported and the
+// rest carry on; only a failure to read the database stops the run.
+func (s *Seeder) Run(ctx context.Context, data Dataset) (Report, error) {
+	var report Report
+	if len(data.Users) == 0 {
+		return report, errors.New("dataset has no users")
+	}
+
+	users := make([]models.User, 0, len(data.Users))
+	var owner *models.User
+	for _, demo := range data.Users {
+		user, err := s.seedUser(ctx, &report, demo, data.EmailDomain)
+		if err != nil {
+			return report, err
+		}
+		if user == nil {
+			continue
+		}
+		users = append(users, *user)
+		if demo.Admin && owner == nil {
+			owner = user
+		}
+	}
+
+	for _, demo := range data.Tables {
+		if err := s.seedTable(ctx, &report, demo, owner); err != nil {
+			return report, err
+		}
+	}
+
+	var players []models.User
+	for _, user := range users {
+		if user.Role == models.UserRolePlayer {
+			players = append(players, user)
+		}
+	}
+	for _, demo := range data.Tournaments {
+		if err := s.seedTournament(ctx, &report, demo, players); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// seedUser creates a verified demo account and funds its wallet. It returns
+// the account, or nil on a dry run of a new account or a failure.
+func (s *Seeder) seedUser(ctx context.Context, report *Report, demo DemoUser, emailDomain string) (*models.User, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("username = ?", demo.Username).First(&user).Error
+	if err == nil {
+		s.logf("user %s already exists", demo.Username)
+		report.Skipped++
+		// Finish funding a wallet an earlier run couldn't
+		if s.apply && s.fundWallet(ctx, report, user, demo.Balance) {
+			s.logf("funded wallet of %s with %d MNT", demo.Username, demo.Balance)
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up user %s: %w", demo.Username, err)
+	}
+
+	if !s.apply {
+		s.logf("would create user %s with %d MNT", demo.Username, demo.Balance)
+		report.Created++
+		return nil, nil
+	}
+
+	hash, err := auth.HashPassword(s.password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash demo password: %w", err)
+	}
+	user = models.User{
+		Email:        strings.ToLower(demo.Username) + "@" + emailDomain,
+		Username:     demo.Username,
+		PasswordHash: hash,
+		Role:         models.UserRolePlayer,
+		IsVerified:   true,
+	}
+	if demo.Admin {
+		user.Role = models.UserRoleAdmin
+	}
+	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
+		s.logf("failed to create user %s: %v", demo.Username, err)
+		report.Failed++
+		return nil, nil
+	}
+
+	s.fundWallet(ctx, report, user, demo.Balance)
+	s.logf("created user %s with %d MNT", demo.Username, demo.Balance)
+	report.Created++
+	return &user, nil
+}
+
+// fundWallet deposits a demo account's balance, once: the deposit's
+// reference is the account, so the ledger refuses a second one. It reports
+// whether this call funded the wallet.
+func (s *Seeder) fundWallet(ctx context.Context, report *Report, user models.User, balance int64) bool {
+	if s.ledger == nil || balance <= 0 {
+		return false
+	}
+
+	_, err := s.ledger.CreateTransactionWithOptions(ctx,
+		[]formance.PostingSimple{{Source: formance.WorldAccount, Destination: formance.PlayerWalletAccount(user.ID), Amount: balance, Asset: s.ledger.Currency()}},
+		map[string]string{"type": "deposit", "user_id": user.ID.String(), "seed": "true"},
+		formance.TransactionOptions{Reference: "seed:wallet:" + user.ID.String()},
+	)
+	if formance.IsConflict(err) {
+		return false
+	}
+	if err != nil {
+		s.logf("failed to fund wallet of %s: %v", user.Username, err)
+		report.Failed++
+		return false
+	}
+	return true
+}
+
+// seedTable opens a public cash table owned by the demo admin
+func (s *Seeder) seedTable(ctx context.Context, report *Report, demo DemoTable, owner *models.User) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.PokerTable{}).Where("name = ?", demo.Name).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up table %s: %w", demo.Name, err)
+	}
+	if count > 0 {
+		s.logf("table %s already exists", demo.Name)
+		report.Skipped++
+		return nil
+	}
+
+	if !s.apply {
+		s.logf("would create table %s (%d/%d %s)", demo.Name, demo.SmallBlind, demo.BigBlind, demo.GameType)
+		report.Created++
+		return nil
+	}
+	if owner == nil {
+		s.logf("skipped table %s: no demo admin to own it", demo.Name)
+		report.Failed++
+		return nil
+	}
+
+	table := models.PokerTable{
+		Name:       demo.Name,
+		TableType:  "cash",
+		GameType:   demo.GameType,
+		MaxPlayers: demo.MaxPlayers,
+		MinBuyIn:   demo.BigBlind * 20,
+		MaxBuyIn:   demo.BigBlind * 100,
+		SmallBlind: demo.SmallBlind,
+		BigBlind:   demo.BigBlind,
+		Status:     "waiting",
+		CreatedBy:  owner.ID,
+	}
+	if err := s.db.WithContext(ctx).Create(&table).Error; err != nil {
+		s.logf("failed to create table %s: %v", demo.Name, err)
+		report.Failed++
+		return nil
+	}
+
+	s.logf("created table %s (%d/%d %s)", demo.Name, demo.SmallBlind, demo.BigBlind, demo.GameType)
+	report.Created++
+	return nil
+}
+
+// seedTournament schedules an upcoming tournament two days out, or records
+// one that finished a week ago with the players placed in order
+func (s *Seeder) seedTournament(ctx context.Context, report *Report, demo DemoTournament, players []models.User) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Tournament{}).Where("name = ?", demo.Name).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up tournament %s: %w", demo.Name, err)
+	}
+	if count > 0 {
+		s.logf("tournament %s already exists", demo.Name)
+		report.Skipped++
+		return nil
+	}
+
+	if !s.apply {
+		if demo.Finished {
+			s.logf("would create finished tournament %s with %d entrants", demo.Name, len(players))
+		} else {
+			s.logf("would schedule tournament %s", demo.Name)
+		}
+		report.Created++
+		return nil
+	}
+
+	tournament := models.Tournament{
+		Name:            demo.Name,
+		TournamentType:  "scheduled",
+		BuyIn:           demo.BuyIn,
+		MaxPlayers:      demo.MaxPlayers,
+		Status:          "registering",
+		BlindStructure:  demoBlindStructure,
+		PayoutStructure: demoPayoutStructure,
+	}
+	start := s.now().Add(48 * time.Hour).Truncate(time.Hour)
+	tournament.StartTime = &start
+
+	var registrations []models.TournamentRegistration
+	if demo.Finished {
+		if len(players) < 2 {
+			s.logf("skipped finished tournament %s: it needs at least 2 demo players", demo.Name)
+			report.Failed++
+			return nil
+		}
+		start = s.now().Add(-7 * 24 * time.Hour).Truncate(time.Hour)
+		end := start.Add(3 * time.Hour)
+		tournament.StartTime = &start
+		tournament.EndTime = &end
+		tournament.Status = "finished"
+		tournament.RegisteredPlayers = len(players)
+		tournament.PrizePool = demo.BuyIn * int64(len(players))
+		tournament.CurrentLevel = 5
+
+		registrations = finishedRegistrations(players, tournament.PrizePool)
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&tournament).Error; err != nil {
+			return err
+		}
+		for i := range registrations {
+			registrations[i].TournamentID = tournament.ID
+		}
+		if len(registrations) > 0 {
+			return tx.Create(&registrations).Error
+		}
+		return nil
+	})
+	if err != nil {
+		s.logf("failed to create tournament %s: %v", demo.Name, err)
+		report.Failed++
+		return nil
+	}
+
+	s.logf("created tournament %s (%s)", demo.Name, tournament.Status)
+	report.Created++
+	return nil
+}
+
+// finishedRegistrations places the players in order and pays the top three
+// from the prize pool by the demo payout structure. Rounding is left with
+// the winner.
+func finishedRegistrations(players []models.User, prizePool int64) []models.TournamentRegistration {
+	places, _ := models.ParsePayoutStructure(demoPayoutStructure)
+	prizes := make(map[int]int64, len(places))
+	var paid int64
+	for _, place := range places {
+		if place.Position > len(players) {
+			continue
+		}
+		prizes[place.Position] = int64(float64(prizePool) * place.Percentage / 100)
+		paid += prizes[place.Position]
+	}
+	prizes[1] += prizePool - paid
+
+	registrations := make([]models.TournamentRegistration, len(players))
+	for i, player := range players {
+		position := i + 1
+		registrations[i] = models.TournamentRegistration{
+			UserID:        player.ID,
+			FinalPosition: &position,
+			PrizeAmount:   prizes[position],
+		}
+	}
+	return registrations
+}
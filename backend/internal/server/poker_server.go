@@ -2,21 +2,30 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/auth"
 	"github.com/anhbaysgalan1/gp/internal/config"
 	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/enginerpc"
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
 	"github.com/anhbaysgalan1/gp/internal/formance"
 	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/logging"
 	custommiddleware "github.com/anhbaysgalan1/gp/internal/middleware"
+	"github.com/anhbaysgalan1/gp/internal/models"
 	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
+	"github.com/anhbaysgalan1/gp/internal/workers"
 	"github.com/anhbaysgalan1/gp/server"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -33,6 +42,38 @@ type PokerServer struct {
 	authMiddleware  *auth.AuthMiddleware
 	roleMiddleware  *auth.RoleMiddleware
 	authService     *services.AuthService
+	loyaltyService  *services.LoyaltyService
+	affiliates      *services.AffiliateService
+	velocity        *services.VelocityService
+	exposure        *services.ExposureService
+	withdrawalFees  *services.WithdrawalFeeService
+	usernames       *services.UsernameService
+	impersonation   *services.ImpersonationService
+	passwordResets  *services.PasswordResetService
+	bankroll        *services.BankrollService
+	handHistory     *services.HandHistoryService
+	featureFlags    *services.FeatureFlagService
+	backups         *services.BackupService
+	skillRatings    *services.SkillRatingService
+	maintenance     *services.MaintenanceService
+	rakeFree        *services.RakeFreeService
+	seatHolds       *services.SeatReservationService
+	spins           *services.SpinService
+	bankDeposits    *services.BankDepositService
+	regulatorExport *services.RegulatorExportService // nil until REGULATOR_EXPORT_KEY is set
+	pushService     *services.PushService
+	statsEvents     statsevents.Publisher
+	recorderFiles   *flightrecorder.FileSink // nil when recordings stay in memory
+	engineAPI       *enginerpc.Server        // nil when ENGINE_GRPC_ADDR is unset
+	nightlyWorkers  *workers.NightlyWorkers
+	tableAutoscaler *workers.PeriodicWorker
+	accessNotices   *workers.PeriodicWorker
+	backupMarkers   *workers.PeriodicWorker // nil when markers are taken on demand only
+	skillRater      *workers.PeriodicWorker
+	maintenanceTick *workers.PeriodicWorker
+	rakeFreeTick    *workers.PeriodicWorker
+	seatHoldExpiry  *workers.PeriodicWorker
+	spinStarter     *workers.PeriodicWorker
 	apiRateLimiter  *custommiddleware.RateLimiter
 	authRateLimiter *custommiddleware.RateLimiter
 	server          *http.Server
@@ -41,7 +82,13 @@ type PokerServer struct {
 
 func NewPokerServer() (*PokerServer, error) {
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Redact secrets and card data from everything logged from here on
+	slog.SetDefault(logging.NewLogger(os.Stdout, cfg.LogFormat, slog.LevelInfo))
 
 	// Setup database
 	db, err := database.NewConnection(cfg)
@@ -82,8 +129,13 @@ func NewPokerServer() (*PokerServer, error) {
 		}
 	}
 
-	// Setup Formance service
+	// Setup Formance service. A sandbox keeps its ledger in memory so staging
+	// and demos never touch real money.
 	formanceService := formance.NewService(cfg)
+	if cfg.Sandbox {
+		formanceService = formance.NewSandboxService(cfg)
+		slog.Warn("SANDBOX mode: balances are play money in an in-memory ledger and reset on restart")
+	}
 
 	// Initialize Formance (create ledger, etc.)
 	if err := formanceService.Initialize(context.Background()); err != nil {
@@ -92,12 +144,122 @@ func NewPokerServer() (*PokerServer, error) {
 
 	// Setup JWT manager
 	jwtManager := auth.NewJWTManager(cfg.JWTSecret, "poker-platform")
+	roleIdleTimeouts := make(map[models.UserRole]time.Duration, len(cfg.SessionIdleTimeoutRoles))
+	for role, timeout := range cfg.SessionIdleTimeoutRoles {
+		roleIdleTimeouts[models.UserRole(role)] = timeout
+	}
+	jwtManager.SetSessionPolicy(auth.SessionPolicy{
+		IdleTimeout:      cfg.SessionIdleTimeout,
+		RoleIdleTimeouts: roleIdleTimeouts,
+		MaxLifetime:      cfg.SessionMaxLifetime,
+	})
 	authMiddleware := auth.NewAuthMiddleware(jwtManager)
 	roleMiddleware := auth.NewRoleMiddleware(db)
 
 	// Setup services
 	emailService := services.NewEmailService(cfg)
 	authService := services.NewAuthService(db, jwtManager, emailService, formanceService)
+	passwordResetService := services.NewPasswordResetService(db, emailService, services.PasswordResetOptions{
+		TTL:          cfg.PasswordResetTTL,
+		AccountLimit: cfg.PasswordResetAccountLimit,
+		IPLimit:      cfg.PasswordResetIPLimit,
+		Window:       cfg.PasswordResetWindow,
+	})
+	authMiddleware.SetSessionRevocations(passwordResetService)
+	loyaltyService := services.NewLoyaltyService(db, formanceService)
+	affiliateService := services.NewAffiliateService(db, formanceService, cfg.AffiliateRevenueShare)
+	pushService := services.NewPushService(db, cfg)
+	velocityService := services.NewVelocityService(db, services.VelocityRules{
+		DepositsPerHour:    cfg.VelocityDepositsPerHour,
+		DepositsPerDay:     cfg.VelocityDepositsPerDay,
+		WithdrawalsPerDay:  cfg.VelocityWithdrawalsPerDay,
+		WithdrawalCooldown: cfg.VelocityWithdrawalCooldown,
+		DailyCaps:          cfg.VelocityDailyCaps,
+	})
+	exposureService := services.NewExposureService(db, formanceService, services.ExposureRules{
+		ByKYCTier: cfg.MaxExposure,
+		ByRole:    cfg.MaxExposureRoles,
+	})
+	feeSchedule := make(services.WithdrawalFeeSchedule, len(cfg.WithdrawalFees))
+	for i, fee := range cfg.WithdrawalFees {
+		feeSchedule[i] = services.WithdrawalFeeRule{
+			Asset:     fee.Asset,
+			KYCTier:   fee.KYCTier,
+			Minimum:   fee.Minimum,
+			FlatFee:   fee.FlatFee,
+			FeeBps:    fee.FeeBps,
+			FreeAbove: fee.FreeAbove,
+		}
+	}
+	withdrawalFeeService := services.NewWithdrawalFeeService(db, feeSchedule, cfg.FormanceCurrency)
+	usernameService := services.NewUsernameService(db, services.UsernameRules{
+		Cooldown:    cfg.UsernameChangeCooldown,
+		Reservation: cfg.UsernameReservation,
+	})
+	impersonationService := services.NewImpersonationService(db, jwtManager, emailService)
+	rebuyRules := make(map[string]services.RebuyRule, len(cfg.RebuyLimits))
+	for _, limit := range cfg.RebuyLimits {
+		rebuyRules[limit.Jurisdiction] = services.RebuyRule{
+			Cooldown:    limit.Cooldown,
+			WarnPercent: limit.WarnPercent,
+		}
+	}
+	bankrollService := services.NewBankrollService(db, services.BankrollRules{
+		DefaultJurisdiction: cfg.DefaultJurisdiction,
+		Jurisdictions:       rebuyRules,
+	})
+	authMiddleware.SetImpersonationAuditor(impersonationService)
+	handHistoryService := services.NewHandHistoryService(db, services.HandHistoryPolicy{
+		StoreMucked:      cfg.HandHistoryStoreMucked,
+		RevealMucked:     cfg.HandHistoryRevealMucked,
+		AnonymizeExports: cfg.HandHistoryAnonymizeExports,
+	})
+
+	// Setup nightly background jobs
+	nightlyWorkers := workers.NewNightlyWorkers(cfg.NightlyWorkersHour)
+	nightlyWorkers.Register("rakeback_payout", func(ctx context.Context, now time.Time) error {
+		return loyaltyService.PayRakeback(ctx, now)
+	})
+	nightlyWorkers.Register("affiliate_revenue_share", func(ctx context.Context, now time.Time) error {
+		return affiliateService.PayRevenueShare(ctx, now)
+	})
+	fairnessService := services.NewFairnessService(db)
+	nightlyWorkers.Register("fairness_reports", func(ctx context.Context, now time.Time) error {
+		return fairnessService.GenerateDailyReports(ctx, now.AddDate(0, 0, -1))
+	})
+
+	// Open and close templated tables as demand changes
+	stakeTemplateService := services.NewStakeTemplateService(db)
+	tableAutoscaler := workers.NewPeriodicWorker("table_autoscale", cfg.TableAutoscaleInterval, func(ctx context.Context, now time.Time) error {
+		return stakeTemplateService.BalanceTemplateTables(ctx)
+	})
+
+	// Tell users when support has finished looking at their account
+	accessNotices := workers.NewPeriodicWorker("impersonation_notices", time.Minute, func(ctx context.Context, now time.Time) error {
+		return impersonationService.NotifyEndedSessions(ctx, now)
+	})
+
+	// Record ledger and hand positions for reconciling restored backups
+	backupService := services.NewBackupService(db, formanceService, services.BackupOptions{
+		QuiesceTimeout: cfg.BackupQuiesceTimeout,
+		ManifestDir:    cfg.BackupManifestDir,
+	})
+	var backupMarkers *workers.PeriodicWorker
+	if cfg.BackupMarkerInterval > 0 {
+		backupMarkers = workers.NewPeriodicWorker("backup_marker", cfg.BackupMarkerInterval, func(ctx context.Context, now time.Time) error {
+			_, err := backupService.TakeMarker(ctx, models.BackupMarkerScheduled, nil)
+			return err
+		})
+	}
+
+	// Fold finished tournaments and cash sessions into skill ratings
+	skillRatingService := services.NewSkillRatingService(db, services.SkillRatingOptions{
+		LowStakesBigBlind: cfg.QuickSeatLowStakesBigBlind,
+	})
+	skillRater := workers.NewPeriodicWorker("skill_ratings", cfg.SkillRatingInterval, func(ctx context.Context, now time.Time) error {
+		_, err := skillRatingService.RateNewResults(ctx)
+		return err
+	})
 
 	// Setup rate limiters
 	apiRateLimiter := custommiddleware.NewAPIRateLimiter()
@@ -108,6 +270,84 @@ func NewPokerServer() (*PokerServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WebSocket hub: %w", err)
 	}
+	hub.SetPushService(pushService)
+	hub.SetOriginCheck(custommiddleware.NewOriginPolicy("websocket", cfg.WSAllowedOrigins).CheckOrigin)
+	hub.SetSendQueueSize(cfg.WSSendQueueSize)
+	hub.SetChatModeration(cfg.ChatBlockedWords, cfg.ChatRateLimit, cfg.ChatRateWindow)
+	hub.SetReconnectWindow(cfg.WSReconnectWindow)
+	hub.SetLatencyAllowance(cfg.WSLatencyAllowance)
+	hub.SetPracticeTablePlayers(cfg.PracticeTablePlayers)
+	hub.SetInstanceID(cfg.InstanceID)
+	hub.SetBankrollService(bankrollService)
+	hub.SetExposureService(exposureService)
+	hub.SetLoyaltyService(loyaltyService)
+	hub.SetHandHistoryService(handHistoryService)
+	statsEvents := statsevents.New(cfg)
+	hub.SetStatsEvents(statsEvents)
+
+	// Keep each table's recent commands and hands for replaying bugs
+	var recorderFiles *flightrecorder.FileSink
+	var recorderSink flightrecorder.Sink
+	if cfg.FlightRecorderDir != "" {
+		recorderFiles, err = flightrecorder.NewFileSink(cfg.FlightRecorderDir)
+		if err != nil {
+			return nil, err
+		}
+		recorderSink = recorderFiles
+	}
+	hub.SetFlightRecorder(cfg.FlightRecorderEntries, recorderSink)
+
+	// Start and lift scheduled maintenance, freezing tables for windows
+	// covering all play
+	maintenanceService := services.NewMaintenanceService(db, emailService, services.MaintenanceOptions{
+		NoticeLead: cfg.MaintenanceNoticeLead,
+	})
+	maintenanceService.SetPlayFreezer(hub)
+	maintenanceTick := workers.NewPeriodicWorker("maintenance_windows", time.Minute, func(ctx context.Context, now time.Time) error {
+		return maintenanceService.Enforce(ctx, now)
+	})
+
+	// Rake-free windows stop the rake at their tables, which hear when
+	// they start and end
+	rakeFreeService := services.NewRakeFreeService(db)
+	hub.SetRakeFreeService(rakeFreeService)
+	rakeFreeTick := workers.NewPeriodicWorker("rake_free_windows", time.Minute, func(ctx context.Context, now time.Time) error {
+		return rakeFreeService.Enforce(ctx, now)
+	})
+
+	// Seats held for named players go back to everyone once they expire
+	seatReservations := services.NewSeatReservationService(db)
+	hub.SetSeatReservations(seatReservations)
+	seatHoldExpiry := workers.NewPeriodicWorker("seat_reservations", time.Minute, func(ctx context.Context, now time.Time) error {
+		_, err := seatReservations.Expire(ctx, now)
+		return err
+	})
+
+	// Spins start the moment they fill; this picks up any that failed to
+	spinService := services.NewSpinService(db, formanceService)
+	spinStarter := workers.NewPeriodicWorker("spin_start", 30*time.Second, func(ctx context.Context, now time.Time) error {
+		return spinService.StartFull(ctx)
+	})
+
+	// Signed betting exports for regulators need a key to sign them with
+	var regulatorExport *services.RegulatorExportService
+	if cfg.RegulatorExportKey != "" {
+		regulatorExport, err = services.NewRegulatorExportService(db, cfg.RegulatorExportKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load regulator export key: %w", err)
+		}
+	}
+
+	// Other services drive tables through the engine API over mutual TLS
+	var engineAPI *enginerpc.Server
+	if cfg.EngineGRPCAddr != "" {
+		tlsConfig, err := enginerpc.ServerTLSConfig(cfg.EngineGRPCCertFile, cfg.EngineGRPCKeyFile, cfg.EngineGRPCClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		engine := server.NewEngineService(hub, formanceService, db.DB)
+		engineAPI = enginerpc.NewServer(engine, enginerpc.Policy(cfg.EngineGRPCAllow), tlsConfig)
+	}
 
 	return &PokerServer{
 		config:          cfg,
@@ -118,6 +358,38 @@ func NewPokerServer() (*PokerServer, error) {
 		authMiddleware:  authMiddleware,
 		roleMiddleware:  roleMiddleware,
 		authService:     authService,
+		loyaltyService:  loyaltyService,
+		affiliates:      affiliateService,
+		velocity:        velocityService,
+		exposure:        exposureService,
+		withdrawalFees:  withdrawalFeeService,
+		usernames:       usernameService,
+		impersonation:   impersonationService,
+		passwordResets:  passwordResetService,
+		bankroll:        bankrollService,
+		handHistory:     handHistoryService,
+		featureFlags:    services.NewFeatureFlagService(db),
+		backups:         backupService,
+		skillRatings:    skillRatingService,
+		maintenance:     maintenanceService,
+		rakeFree:        rakeFreeService,
+		seatHolds:       seatReservations,
+		spins:           spinService,
+		bankDeposits:    services.NewBankDepositService(db, formanceService),
+		regulatorExport: regulatorExport,
+		pushService:     pushService,
+		statsEvents:     statsEvents,
+		recorderFiles:   recorderFiles,
+		engineAPI:       engineAPI,
+		nightlyWorkers:  nightlyWorkers,
+		tableAutoscaler: tableAutoscaler,
+		accessNotices:   accessNotices,
+		backupMarkers:   backupMarkers,
+		skillRater:      skillRater,
+		maintenanceTick: maintenanceTick,
+		rakeFreeTick:    rakeFreeTick,
+		seatHoldExpiry:  seatHoldExpiry,
+		spinStarter:     spinStarter,
 		apiRateLimiter:  apiRateLimiter,
 		authRateLimiter: authRateLimiter,
 		hub:             hub,
@@ -137,6 +409,19 @@ func (s *PokerServer) Start() error {
 	// Start WebSocket hub
 	go s.hub.Run()
 
+	// Start nightly background jobs
+	s.nightlyWorkers.Start()
+	s.tableAutoscaler.Start()
+	s.accessNotices.Start()
+	if s.backupMarkers != nil {
+		s.backupMarkers.Start()
+	}
+	s.skillRater.Start()
+	s.maintenanceTick.Start()
+	s.rakeFreeTick.Start()
+	s.seatHoldExpiry.Start()
+	s.spinStarter.Start()
+
 	// Start server in goroutine
 	go func() {
 		slog.Info("Starting poker server", "port", s.config.Port)
@@ -145,6 +430,15 @@ func (s *PokerServer) Start() error {
 		}
 	}()
 
+	if s.engineAPI != nil {
+		go func() {
+			slog.Info("Starting engine API", "addr", s.config.EngineGRPCAddr)
+			if err := s.engineAPI.ListenAndServe(s.config.EngineGRPCAddr); err != nil {
+				slog.Error("Engine API failed to start", "error", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -162,6 +456,32 @@ func (s *PokerServer) Shutdown() error {
 	if err := s.server.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 	}
+	if s.engineAPI != nil {
+		s.engineAPI.Stop()
+	}
+
+	// Stop background jobs before closing their dependencies
+	s.nightlyWorkers.Stop()
+	s.tableAutoscaler.Stop()
+	s.accessNotices.Stop()
+	if s.backupMarkers != nil {
+		s.backupMarkers.Stop()
+	}
+	s.skillRater.Stop()
+	s.maintenanceTick.Stop()
+	s.rakeFreeTick.Stop()
+	s.seatHoldExpiry.Stop()
+	s.spinStarter.Stop()
+
+	// Send stats events still buffered
+	if err := s.statsEvents.Close(); err != nil {
+		slog.Error("Failed to close stats events publisher", "error", err)
+	}
+
+	// Write table recordings still queued
+	if s.recorderFiles != nil {
+		s.recorderFiles.Close()
+	}
 
 	// Close Redis connection
 	if s.redisClient != nil {
@@ -187,19 +507,22 @@ func (s *PokerServer) setupRouter() chi.Router {
 	r := chi.NewRouter()
 
 	// Basic middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(custommiddleware.RequestLogger(slog.Default()))
+	r.Use(middleware.Recoverer)
 	r.Use(auth.SecurityHeaders)
 	r.Use(s.apiRateLimiter.RateLimit) // Apply global rate limiting
+	if s.config.Sandbox {
+		r.Use(custommiddleware.Sandbox)
+	}
 
 	// CORS middleware
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Configure properly for production
+		AllowOriginFunc:  custommiddleware.NewOriginPolicy("rest", s.config.AllowedOrigins).AllowOriginFunc,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", custommiddleware.SandboxHeader, custommiddleware.CorrelationIDHeader, auth.SessionExpiresHeader, auth.SessionMaxExpiresHeader, auth.RefreshedTokenHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -210,6 +533,9 @@ func (s *PokerServer) setupRouter() chi.Router {
 		w.Write([]byte("OK"))
 	})
 
+	// Prometheus scrape target for per-table and WebSocket metrics
+	r.Get("/metrics", s.serveMetrics)
+
 	// WebSocket endpoint
 	r.Get("/ws", s.serveWebSocket)
 
@@ -219,6 +545,12 @@ func (s *PokerServer) setupRouter() chi.Router {
 	r.Route("/api/v1", func(r chi.Router) {
 		// Create auth handler
 		authHandler := handlers.NewAuthHandler(s.authService)
+		authHandler.SetSessionRecovery(services.NewSessionRecoveryService(s.db, s.formanceService, s.hub))
+		authHandler.SetAffiliates(s.affiliates)
+		authHandler.SetUsernames(s.usernames)
+		authHandler.SetFeatureFlags(s.featureFlags)
+		authHandler.SetPasswordResets(s.passwordResets)
+		authHandler.SetIdentity(services.NewIdentityService(s.db, s.authService, s.jwtManager, s.config), s.config.OAuthCallbackURL)
 
 		// Public auth routes with stricter rate limiting
 		r.Group(func(r chi.Router) {
@@ -229,41 +561,155 @@ func (s *PokerServer) setupRouter() chi.Router {
 		// Protected routes group
 		r.Group(func(r chi.Router) {
 			r.Use(s.authMiddleware.RequireAuth)
+			r.Use(custommiddleware.LogUser)
 
 			// Protected auth routes under /user (different path to avoid conflicts)
 			r.Mount("/user", authHandler.ProtectedRoutes())
 
+			// Loyalty tier and rakeback progress
+			loyaltyHandler := handlers.NewLoyaltyHandler(s.loyaltyService)
+			r.Mount("/user/loyalty", loyaltyHandler.Routes())
+
+			// Consent for support staff to view the account, and its history
+			supportAccessHandler := handlers.NewSupportAccessHandler(s.impersonation)
+			r.Mount("/user/support-access", supportAccessHandler.Routes())
+
+			// Responsible gaming limits on rebuys
+			bankrollHandler := handlers.NewBankrollHandler(s.bankroll)
+			r.Mount("/user/bankroll", bankrollHandler.Routes())
+
+			// Tables and tournaments in play, for resuming on another device
+			activePlayHandler := handlers.NewActivePlayHandler(services.NewActivePlayService(s.db, s.hub, s.jwtManager))
+			r.Mount("/user/active-play", activePlayHandler.Routes())
+
+			// Active game sessions, and closing one whose chips are stuck
+			userSessionHandler := handlers.NewUserSessionHandler(services.NewUserSessionService(s.db, s.formanceService, s.hub))
+			r.Mount("/user/sessions", userSessionHandler.Routes())
+
+			// Referral code, referred players and revenue share payouts
+			affiliateHandler := handlers.NewAffiliateHandler(s.affiliates)
+			r.Mount("/affiliate", affiliateHandler.Routes())
+
+			// Push device registration and notification preferences
+			notificationHandler := handlers.NewNotificationHandler(s.pushService)
+			r.Mount("/notifications", notificationHandler.Routes())
+
+			// Direct message history, block lists and reports
+			directMessageHandler := handlers.NewDirectMessageHandler(services.NewDirectMessageService(s.db))
+			r.Mount("/messages", directMessageHandler.Routes())
+
+			// Friends, lobby presence and joining a friend's table
+			friendHandler := handlers.NewFriendHandler(services.NewPresenceService(s.db, s.hub))
+			r.Mount("/friends", friendHandler.Routes())
+
 			// Balance management routes
-			balanceHandler := handlers.NewBalanceHandler(s.formanceService, s.db.DB)
+			balanceHandler := handlers.NewBalanceHandler(s.formanceService, s.db.DB, s.pushService)
+			balanceHandler.SetVelocityService(s.velocity)
+			balanceHandler.SetExposureService(s.exposure)
+			balanceHandler.SetWithdrawalFees(s.withdrawalFees)
+			balanceHandler.SetFeatureFlags(s.featureFlags)
+			balanceHandler.SetMaintenance(s.maintenance)
+			balanceHandler.SetBankDeposits(s.bankDeposits)
 			r.Mount("/balance", balanceHandler.Routes())
 
 			// Table management routes
 			tableHandler := handlers.NewTableHandler(s.db, s.formanceService)
+			tableHandler.SetExposureService(s.exposure)
+			tableHandler.SetSkillRatings(s.skillRatings)
+			tableHandler.SetMaintenance(s.maintenance)
+			tableHandler.SetRakeFree(s.rakeFree)
+			tableHandler.SetSeatReservations(s.seatHolds)
+			tableHandler.SetTableBans(services.NewTableBanService(s.db))
 			r.Mount("/tables", tableHandler.Routes())
 
 			// Tournament management routes
-			tournamentHandler := handlers.NewTournamentHandler(s.db, s.formanceService)
-			r.Mount("/tournaments", tournamentHandler.Routes())
+			tournamentHandler := handlers.NewTournamentHandler(s.db, s.formanceService, s.pushService)
+			tournamentHandler.SetFeatureFlags(s.featureFlags)
+			tournamentHandler.SetStatsEvents(s.statsEvents)
+			tournamentHandler.SetTableMoveNotifier(s.hub)
+			r.Mount("/tournaments", tournamentHandler.Routes(s.roleMiddleware))
+
+			// Three-player spins with a drawn prize pool
+			spinHandler := handlers.NewSpinHandler(s.spins)
+			spinHandler.SetFeatureFlags(s.featureFlags)
+			r.Mount("/spins", spinHandler.Routes())
+
+			// Histories of hands the user played or watched
+			handHistoryHandler := handlers.NewHandHistoryHandler(s.handHistory)
+			r.Mount("/hands", handHistoryHandler.Routes())
+
+			// Public player profiles and tournament history
+			userHandler := handlers.NewUserHandler(services.NewTournamentStatsService(s.db))
+			userHandler.SetUsernames(s.usernames)
+			userHandler.SetSkillRatings(s.skillRatings)
+			r.Mount("/users", userHandler.Routes())
 
 			// Admin routes (role-based authorization)
 			adminHandler := handlers.NewAdminHandler(s.db, s.formanceService)
+			adminHandler.SetTableMaintenance(s.hub)
+			adminHandler.SetHandDisputes(s.hub)
+			adminHandler.SetTableControl(s.hub)
+			adminHandler.SetSendQueueMonitor(s.hub)
+			adminHandler.SetTableRecorder(s.hub)
+			adminHandler.SetLiveTableMonitor(s.hub)
+			adminHandler.SetClusterRegistry(s.hub)
+			adminHandler.SetVelocityService(s.velocity)
+			adminHandler.SetExposureService(s.exposure)
+			adminHandler.SetImpersonationService(s.impersonation)
+			adminHandler.SetBankrollService(s.bankroll)
+			adminHandler.SetFeatureFlags(s.featureFlags)
+			adminHandler.SetBackupService(s.backups)
+			adminHandler.SetMaintenance(s.maintenance)
+			adminHandler.SetRakeFree(s.rakeFree)
+			adminHandler.SetSeatReservations(s.seatHolds)
+			adminHandler.SetSpins(s.spins)
+			adminHandler.SetBankDeposits(s.bankDeposits)
+			adminHandler.SetRegulatorExports(s.regulatorExport)
+			adminHandler.SetPushService(s.pushService)
 			r.Mount("/admin", adminHandler.Routes(s.roleMiddleware))
-
-			// TODO: Add leaderboard routes
 		})
 
 		// Optional auth routes (can be accessed with or without auth)
 		r.Group(func(r chi.Router) {
 			r.Use(s.authMiddleware.OptionalAuth)
+			r.Use(custommiddleware.LogUser)
+
+			// Platform-wide RNG fairness report, when published
+			if s.config.PublicFairnessReport {
+				fairnessHandler := handlers.NewFairnessHandler(services.NewFairnessService(s.db))
+				r.Mount("/fairness", fairnessHandler.Routes())
+			}
+
+			// Banners for maintenance in force or coming up
+			maintenanceHandler := handlers.NewMaintenanceHandler(s.maintenance)
+			r.Mount("/maintenance", maintenanceHandler.Routes())
+
+			// Daily, weekly and monthly leaderboards from hand history
+			leaderboardHandler := handlers.NewLeaderboardHandler(services.NewLeaderboardService(s.db))
+			r.Mount("/leaderboards", leaderboardHandler.Routes())
 
 			// TODO: Add public table listing
-			// TODO: Add public leaderboards
 		})
 	})
 
 	return r
 }
 
+// serveMetrics writes metrics in the Prometheus text format, requiring the
+// configured bearer token when one is set
+func (s *PokerServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.config.MetricsToken != "" {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.MetricsToken)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.hub.WriteMetrics(w)
+}
+
 // serveWebSocket handles WebSocket upgrade with authentication
 func (s *PokerServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Extract JWT token from query parameter or Authorization header
@@ -280,6 +726,31 @@ func (s *PokerServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 		token = r.URL.Query().Get("token")
 	}
 
+	// A reconnect ticket opens the connection already joined to its table
+	if token == "" {
+		if ticket := r.URL.Query().Get("ticket"); ticket != "" {
+			claims, err := s.jwtManager.ValidateReconnectTicket(ticket)
+			if err != nil {
+				http.Error(w, "Invalid ticket", http.StatusUnauthorized)
+				return
+			}
+			if !s.checkWebSocketSession(w, r, claims.SessionClaims()) {
+				return
+			}
+			custommiddleware.SetLogUser(r.Context(), claims.UserID)
+			server.ServeWsWithTicket(s.hub, w, r, claims.UserID, claims.Username, claims.Table, s.formanceService, s.db.DB)
+			return
+		}
+	}
+
+	// Anyone may watch a running table without signing in
+	if token == "" {
+		if tableName := r.URL.Query().Get("spectate"); tableName != "" {
+			server.ServeWsSpectator(s.hub, w, r, tableName)
+			return
+		}
+	}
+
 	if token == "" {
 		http.Error(w, "Authentication required", http.StatusUnauthorized)
 		return
@@ -291,7 +762,31 @@ func (s *PokerServer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid token", http.StatusUnauthorized)
 		return
 	}
+	if !s.checkWebSocketSession(w, r, claims) {
+		return
+	}
+
+	custommiddleware.SetLogUser(r.Context(), claims.UserID)
 
 	// Create WebSocket connection with authenticated user info
 	server.ServeWsWithAuth(s.hub, w, r, claims.UserID, claims.Username, s.formanceService, s.db.DB)
 }
+
+// checkWebSocketSession refuses the connection when the sign-in behind it has
+// been revoked
+func (s *PokerServer) checkWebSocketSession(w http.ResponseWriter, r *http.Request, claims *auth.Claims) bool {
+	if err := s.authMiddleware.CheckSession(r.Context(), claims); err != nil {
+		if errors.Is(err, auth.ErrSessionRevoked) {
+			http.Error(w, "Session revoked", http.StatusUnauthorized)
+			return false
+		}
+		if errors.Is(err, auth.ErrAccountDisabled) {
+			http.Error(w, "Account disabled", http.StatusUnauthorized)
+			return false
+		}
+		slog.Error("Failed to check session", "user_id", claims.UserID, "error", err)
+		http.Error(w, "Failed to check session", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
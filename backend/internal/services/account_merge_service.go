@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMergeSameAccount  = errors.New("an account cannot be merged into itself")
+	ErrAccountDisabled   = errors.New("account is disabled")
+	ErrMergeActivePlay   = errors.New("duplicate account is seated at a table or in an unfinished tournament")
+	ErrMergeAdminAccount = errors.New("only player accounts can be merged")
+)
+
+// AccountMergeService folds duplicate registrations into the account the
+// player keeps: the wallet is moved on the ledger, their play history and
+// notes are reassigned, and the duplicate is disabled
+type AccountMergeService struct {
+	db              *database.DB
+	formanceService *formance.Service
+}
+
+func NewAccountMergeService(db *database.DB, formanceService *formance.Service) *AccountMergeService {
+	return &AccountMergeService{db: db, formanceService: formanceService}
+}
+
+// Merge moves everything the duplicate account owns to the survivor and
+// disables the duplicate. The wallet is moved first; if reassigning the rest
+// fails the merge is recorded as failed and can be run again, which moves
+// only what is left.
+func (ms *AccountMergeService) Merge(ctx context.Context, survivorID, duplicateID, adminID uuid.UUID, reason string) (*models.AccountMerge, error) {
+	if survivorID == duplicateID {
+		return nil, ErrMergeSameAccount
+	}
+	survivor, err := ms.mergeableUser(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := ms.mergeableUser(ctx, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ms.checkNoActivePlay(ctx, duplicateID); err != nil {
+		return nil, err
+	}
+
+	merge := models.AccountMerge{
+		DuplicateID: duplicateID,
+		SurvivorID:  survivorID,
+		AdminID:     adminID,
+		Reason:      reason,
+		Status:      models.AccountMergePending,
+	}
+	if err := ms.db.WithContext(ctx).Create(&merge).Error; err != nil {
+		return nil, fmt.Errorf("failed to record account merge: %w", err)
+	}
+
+	if err := ms.mergeWallet(ctx, &merge); err != nil {
+		return nil, ms.fail(ctx, &merge, err)
+	}
+
+	err = ms.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return ms.reassign(tx, &merge, survivor, duplicate)
+	})
+	if err != nil {
+		return nil, ms.fail(ctx, &merge, err)
+	}
+
+	slog.Info("Accounts merged",
+		"merge_id", merge.ID,
+		"duplicate_id", duplicateID,
+		"survivor_id", survivorID,
+		"admin_id", adminID,
+		"wallet_amount", merge.WalletAmount,
+		"transaction_id", merge.TransactionID,
+		"sessions", merge.Sessions,
+		"hand_histories", merge.HandHistories,
+		"tournament_entries", merge.TournamentEntries,
+		"notes", merge.Notes,
+	)
+	return &merge, nil
+}
+
+// ListMerges returns the merge records involving a user, or every merge when
+// userID is nil, newest first
+func (ms *AccountMergeService) ListMerges(ctx context.Context, userID *uuid.UUID, limit int) ([]models.AccountMerge, error) {
+	query := ms.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if userID != nil {
+		query = query.Where("duplicate_id = ? OR survivor_id = ?", *userID, *userID)
+	}
+
+	var merges []models.AccountMerge
+	if err := query.Find(&merges).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account merges: %w", err)
+	}
+	return merges, nil
+}
+
+// AddNote records a support note on a user's account
+func (ms *AccountMergeService) AddNote(ctx context.Context, userID, authorID uuid.UUID, body string) (*models.UserNote, error) {
+	var count int64
+	if err := ms.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	note := models.UserNote{UserID: userID, AuthorID: authorID, Body: body}
+	if err := ms.db.WithContext(ctx).Create(&note).Error; err != nil {
+		return nil, fmt.Errorf("failed to add note: %w", err)
+	}
+	return &note, nil
+}
+
+// Notes returns the support notes on a user's account, newest first
+func (ms *AccountMergeService) Notes(ctx context.Context, userID uuid.UUID) ([]models.UserNote, error) {
+	var notes []models.UserNote
+	if err := ms.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	return notes, nil
+}
+
+func (ms *AccountMergeService) mergeableUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := ms.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+	if user.Role != models.UserRolePlayer {
+		return nil, ErrMergeAdminAccount
+	}
+	return &user, nil
+}
+
+// checkNoActivePlay refuses duplicates with chips in play, whose session
+// and tournament pool accounts are still tied to them on the ledger
+func (ms *AccountMergeService) checkNoActivePlay(ctx context.Context, userID uuid.UUID) error {
+	var sessions int64
+	err := ms.db.WithContext(ctx).Model(&models.GameSession{}).
+		Where("user_id = ? AND status = ?", userID, models.GameSessionStatusActive).
+		Count(&sessions).Error
+	if err != nil {
+		return fmt.Errorf("failed to check active sessions: %w", err)
+	}
+
+	var entries int64
+	err = ms.db.WithContext(ctx).Model(&models.TournamentRegistration{}).
+		Joins("JOIN tournaments ON tournaments.id = tournament_registrations.tournament_id").
+		Where("tournament_registrations.user_id = ? AND tournaments.status <> ?", userID, "finished").
+		Count(&entries).Error
+	if err != nil {
+		return fmt.Errorf("failed to check tournament entries: %w", err)
+	}
+
+	if sessions > 0 || entries > 0 {
+		return ErrMergeActivePlay
+	}
+	return nil
+}
+
+// mergeWallet moves the duplicate's wallet balance to the survivor
+func (ms *AccountMergeService) mergeWallet(ctx context.Context, merge *models.AccountMerge) error {
+	balance, err := ms.formanceService.GetUserBalance(ctx, merge.DuplicateID, ms.db.DB)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+	if balance.MainBalance <= 0 {
+		return nil
+	}
+
+	transactionID, err := ms.formanceService.MergeWallets(ctx, merge.DuplicateID, merge.SurvivorID, merge.ID, balance.MainBalance)
+	if err != nil {
+		return err
+	}
+	merge.WalletAmount = balance.MainBalance
+	merge.TransactionID = transactionID
+	return nil
+}
+
+// reassign moves the duplicate's records to the survivor, disables the
+// duplicate and completes the merge record, all in one transaction
+func (ms *AccountMergeService) reassign(tx *gorm.DB, merge *models.AccountMerge, survivor, duplicate *models.User) error {
+	result := tx.Model(&models.GameSession{}).Where("user_id = ?", duplicate.ID).Update("user_id", survivor.ID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to reassign game sessions: %w", result.Error)
+	}
+	merge.Sessions = result.RowsAffected
+
+	result = tx.Model(&models.TournamentRegistration{}).Where("user_id = ?", duplicate.ID).Update("user_id", survivor.ID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to reassign tournament entries: %w", result.Error)
+	}
+	merge.TournamentEntries = result.RowsAffected
+
+	result = tx.Model(&models.UserNote{}).Where("user_id = ?", duplicate.ID).Update("user_id", survivor.ID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to reassign notes: %w", result.Error)
+	}
+	merge.Notes = result.RowsAffected
+
+	hands, err := ms.reassignHands(tx, duplicate.ID, survivor)
+	if err != nil {
+		return err
+	}
+	merge.HandHistories = hands
+
+	merge.PlayChips = duplicate.PlayChips
+	err = tx.Model(&models.User{}).Where("id = ?", survivor.ID).Updates(map[string]interface{}{
+		"play_chips":         gorm.Expr("play_chips + ?", duplicate.PlayChips),
+		"total_hands_played": gorm.Expr("total_hands_played + ?", duplicate.TotalHandsPlayed),
+		"total_winnings":     gorm.Expr("total_winnings + ?", duplicate.TotalWinnings),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update surviving account: %w", err)
+	}
+
+	now := time.Now()
+	err = tx.Model(&models.User{}).Where("id = ?", duplicate.ID).Updates(map[string]interface{}{
+		"disabled_at":         now,
+		"sessions_revoked_at": now,
+		"merged_into_id":      survivor.ID,
+		"play_chips":          0,
+		"total_hands_played":  0,
+		"total_winnings":      0,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to disable duplicate account: %w", err)
+	}
+
+	note := models.UserNote{
+		UserID:   survivor.ID,
+		AuthorID: merge.AdminID,
+		Body:     fmt.Sprintf("Merged duplicate account %s (%s) into this account: %s", duplicate.Username, duplicate.ID, merge.Reason),
+	}
+	if err := tx.Create(&note).Error; err != nil {
+		return fmt.Errorf("failed to add merge note: %w", err)
+	}
+
+	merge.Status = models.AccountMergeCompleted
+	merge.CompletedAt = &now
+	if err := tx.Save(merge).Error; err != nil {
+		return fmt.Errorf("failed to complete merge record: %w", err)
+	}
+	return nil
+}
+
+// reassignHands moves the duplicate's hand presence and the cards and awards
+// recorded in those hands. Hands both accounts were at keep one presence.
+func (ms *AccountMergeService) reassignHands(tx *gorm.DB, duplicateID uuid.UUID, survivor *models.User) (int64, error) {
+	var handIDs []string
+	if err := tx.Model(&models.HandPresence{}).Where("user_id = ?", duplicateID).Pluck("hand_id", &handIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to list hands: %w", err)
+	}
+	if len(handIDs) == 0 {
+		return 0, nil
+	}
+
+	err := tx.Where("user_id = ? AND hand_id IN (?)", duplicateID,
+		tx.Model(&models.HandPresence{}).Select("hand_id").Where("user_id = ?", survivor.ID),
+	).Delete(&models.HandPresence{}).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove shared hand presence: %w", err)
+	}
+	if err := tx.Model(&models.HandPresence{}).Where("user_id = ?", duplicateID).Update("user_id", survivor.ID).Error; err != nil {
+		return 0, fmt.Errorf("failed to reassign hand presence: %w", err)
+	}
+
+	var histories []models.HandHistory
+	if err := tx.Where("hand_id IN ?", handIDs).Find(&histories).Error; err != nil {
+		return 0, fmt.Errorf("failed to load hand histories: %w", err)
+	}
+	for i := range histories {
+		changed, err := histories[i].ReassignUser(duplicateID, survivor.ID, survivor.Username)
+		if err != nil {
+			slog.Warn("Skipping unreadable hand history in merge", "hand_id", histories[i].HandID, "error", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		err = tx.Model(&histories[i]).Updates(map[string]interface{}{
+			"hole_cards": histories[i].HoleCards,
+			"winners":    histories[i].Winners,
+		}).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to update hand %s: %w", histories[i].HandID, err)
+		}
+	}
+	return int64(len(handIDs)), nil
+}
+
+// fail records why a merge stopped and returns the error
+func (ms *AccountMergeService) fail(ctx context.Context, merge *models.AccountMerge, cause error) error {
+	merge.Status = models.AccountMergeFailed
+	merge.Error = cause.Error()
+	if len(merge.Error) > 500 {
+		merge.Error = merge.Error[:500]
+	}
+	if err := ms.db.WithContext(ctx).Save(merge).Error; err != nil {
+		slog.Error("Failed to record account merge failure", "merge_id", merge.ID, "error", err)
+	}
+
+	slog.Error("Account merge failed",
+		"merge_id", merge.ID,
+		"duplicate_id", merge.DuplicateID,
+		"survivor_id", merge.SurvivorID,
+		"admin_id", merge.AdminID,
+		"transaction_id", merge.TransactionID,
+		"error", cause,
+	)
+	return cause
+}
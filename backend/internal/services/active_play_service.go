@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// LiveSeatReader reports a user's seats at the tables running right now
+type LiveSeatReader interface {
+	LiveSeats(userID uuid.UUID) []models.LiveSeat
+}
+
+// ReconnectTicketIssuer issues tickets for opening a WebSocket straight into
+// a table
+type ReconnectTicketIssuer interface {
+	GenerateReconnectTicket(userID uuid.UUID, username, table string) (string, time.Time, error)
+}
+
+// ActivePlayService gathers every table and tournament a user is playing so
+// they can pick all of them up again on another device
+type ActivePlayService struct {
+	db      *database.DB
+	seats   LiveSeatReader
+	tickets ReconnectTicketIssuer
+}
+
+// NewActivePlayService creates a new active play service
+func NewActivePlayService(db *database.DB, seats LiveSeatReader, tickets ReconnectTicketIssuer) *ActivePlayService {
+	return &ActivePlayService{
+		db:      db,
+		seats:   seats,
+		tickets: tickets,
+	}
+}
+
+// ActivePlay returns the user's seats and tournament entries. Seats at
+// running tables carry the live stack and blinds and a reconnect ticket;
+// sessions whose table is gone are listed from the database without one.
+func (as *ActivePlayService) ActivePlay(ctx context.Context, userID uuid.UUID, username string) (*models.ActivePlay, error) {
+	tables, err := as.activeTables(ctx, userID, username)
+	if err != nil {
+		return nil, err
+	}
+	tournaments, err := as.activeTournaments(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ActivePlay{Tables: tables, Tournaments: tournaments}, nil
+}
+
+func (as *ActivePlayService) activeTables(ctx context.Context, userID uuid.UUID, username string) ([]models.ActiveTable, error) {
+	var sessions []models.GameSession
+	err := as.db.WithContext(ctx).
+		Preload("Table").
+		Where("user_id = ? AND status = ?", userID, models.GameSessionStatusActive).
+		Order("joined_at ASC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active sessions: %w", err)
+	}
+
+	live := make(map[uuid.UUID]models.LiveSeat)
+	var liveOrder []uuid.UUID
+	if as.seats != nil {
+		for _, seat := range as.seats.LiveSeats(userID) {
+			live[seat.TableID] = seat
+			liveOrder = append(liveOrder, seat.TableID)
+		}
+	}
+
+	tables := make([]models.ActiveTable, 0, len(sessions)+len(live))
+	for _, session := range sessions {
+		sessionID := session.ID
+		entry := models.ActiveTable{
+			TableID:    session.TableID,
+			TableName:  session.Table.Name,
+			SessionID:  &sessionID,
+			SeatNumber: session.SeatNumber,
+			Stack:      session.CurrentChips,
+			SmallBlind: session.Table.SmallBlind,
+			BigBlind:   session.Table.BigBlind,
+		}
+		if seat, ok := live[session.TableID]; ok {
+			as.applyLiveSeat(&entry, seat, userID, username)
+			delete(live, session.TableID)
+		}
+		tables = append(tables, entry)
+	}
+
+	// Seats without a session, such as practice games
+	for _, tableID := range liveOrder {
+		seat, ok := live[tableID]
+		if !ok {
+			continue
+		}
+		entry := models.ActiveTable{TableID: seat.TableID}
+		as.applyLiveSeat(&entry, seat, userID, username)
+		tables = append(tables, entry)
+	}
+
+	return tables, nil
+}
+
+func (as *ActivePlayService) applyLiveSeat(entry *models.ActiveTable, seat models.LiveSeat, userID uuid.UUID, username string) {
+	seatNumber := seat.SeatNumber
+	entry.TableName = seat.TableName
+	entry.SeatNumber = &seatNumber
+	entry.Stack = seat.Stack
+	entry.SmallBlind = seat.SmallBlind
+	entry.BigBlind = seat.BigBlind
+	entry.Ante = seat.Ante
+	entry.HandInProgress = seat.HandInProgress
+	entry.ToAct = seat.ToAct
+	entry.Live = true
+
+	if as.tickets == nil {
+		return
+	}
+	ticket, expiresAt, err := as.tickets.GenerateReconnectTicket(userID, username, seat.TableName)
+	if err != nil {
+		slog.Warn("Failed to issue reconnect ticket", "user_id", userID, "table", seat.TableName, "error", err)
+		return
+	}
+	entry.ReconnectTicket = ticket
+	entry.TicketExpiresAt = &expiresAt
+}
+
+func (as *ActivePlayService) activeTournaments(ctx context.Context, userID uuid.UUID) ([]models.ActiveTournament, error) {
+	var registrations []models.TournamentRegistration
+	err := as.db.WithContext(ctx).
+		Preload("Tournament").
+		Joins("JOIN tournaments ON tournaments.id = tournament_registrations.tournament_id AND tournaments.deleted_at IS NULL").
+		Where("tournament_registrations.user_id = ? AND tournament_registrations.final_position IS NULL", userID).
+		Where("tournaments.status IN ?", []string{"registering", "running"}).
+		Order("tournaments.start_time ASC").
+		Find(&registrations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tournament registrations: %w", err)
+	}
+
+	tournaments := make([]models.ActiveTournament, 0, len(registrations))
+	for _, reg := range registrations {
+		t := reg.Tournament
+		entry := models.ActiveTournament{
+			TournamentID: t.ID,
+			Name:         t.Name,
+			Status:       t.Status,
+			StartTime:    t.StartTime,
+			Chips:        reg.Chips,
+			TableNumber:  reg.TableNumber,
+			SeatNumber:   reg.SeatNumber,
+			Level:        t.CurrentLevel,
+		}
+		if t.CurrentLevel > 0 {
+			levels, err := models.ParseBlindStructure(t.BlindStructure)
+			if err == nil && t.CurrentLevel <= len(levels) {
+				level := levels[t.CurrentLevel-1]
+				entry.SmallBlind = level.SmallBlind
+				entry.BigBlind = level.BigBlind
+				entry.Ante = level.Ante
+			}
+		}
+		tournaments = append(tournaments, entry)
+	}
+
+	return tournaments, nil
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidReferralCode is returned when a sign-up names a code that doesn't exist
+var ErrInvalidReferralCode = errors.New("invalid referral code")
+
+// DefaultAffiliateRevenueShare is the share of referred players' rake paid to affiliates
+const DefaultAffiliateRevenueShare = 0.25
+
+const (
+	// referralCodeAlphabet leaves out characters that are easily confused when read aloud
+	referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	referralCodeLength   = 8
+
+	// affiliateActiveDays is the window in which a referred player must have
+	// paid rake to count as active
+	affiliateActiveDays = 30
+)
+
+// AffiliateService manages referral codes, sign-up attribution and the
+// revenue share paid to affiliates
+type AffiliateService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	revenueShare    float64
+}
+
+// NewAffiliateService creates a new affiliate service paying revenueShare of
+// referred players' rake
+func NewAffiliateService(db *database.DB, formanceService *formance.Service, revenueShare float64) *AffiliateService {
+	return &AffiliateService{
+		db:              db,
+		formanceService: formanceService,
+		revenueShare:    revenueShare,
+	}
+}
+
+// NormalizeReferralCode makes codes case-insensitive and ignores surrounding whitespace
+func NormalizeReferralCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Commission is the revenue share owed on an amount of rake, rounded down
+func (as *AffiliateService) Commission(rake int64) int64 {
+	return int64(float64(rake) * as.revenueShare)
+}
+
+// GetOrCreateCode returns the user's referral code, creating one the first time
+func (as *AffiliateService) GetOrCreateCode(ctx context.Context, userID uuid.UUID) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	err := as.db.WithContext(ctx).Where("user_id = ?", userID).First(&code).Error
+	if err == nil {
+		return &code, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get referral code: %w", err)
+	}
+
+	// Retry on the rare collision with an existing code
+	for attempt := 0; attempt < 5; attempt++ {
+		generated, err := generateReferralCode()
+		if err != nil {
+			return nil, err
+		}
+
+		code = models.ReferralCode{UserID: userID, Code: generated}
+		result := as.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&code)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to create referral code: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return &code, nil
+		}
+
+		// Either the code is taken or a concurrent request created the user's code
+		var existing models.ReferralCode
+		if err := as.db.WithContext(ctx).Where("user_id = ?", userID).First(&existing).Error; err == nil {
+			return &existing, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to create referral code: no unique code found")
+}
+
+// LookupCode returns the referral code record for a code entered at sign-up
+func (as *AffiliateService) LookupCode(ctx context.Context, code string) (*models.ReferralCode, error) {
+	var referralCode models.ReferralCode
+	err := as.db.WithContext(ctx).Where("code = ?", NormalizeReferralCode(code)).First(&referralCode).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidReferralCode
+		}
+		return nil, fmt.Errorf("failed to look up referral code: %w", err)
+	}
+	return &referralCode, nil
+}
+
+// AttributeSignup records that a newly registered player was referred by the
+// owner of code. Players can't refer themselves and are only attributed once.
+func (as *AffiliateService) AttributeSignup(ctx context.Context, code string, userID uuid.UUID) error {
+	referralCode, err := as.LookupCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if referralCode.UserID == userID {
+		return ErrInvalidReferralCode
+	}
+
+	referral := models.Referral{
+		AffiliateID:    referralCode.UserID,
+		ReferredUserID: userID,
+		Code:           referralCode.Code,
+	}
+	if err := as.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&referral).Error; err != nil {
+		return fmt.Errorf("failed to record referral: %w", err)
+	}
+
+	slog.Info("Referral recorded", "affiliate_id", referralCode.UserID, "referred_user_id", userID, "code", referralCode.Code)
+	return nil
+}
+
+// GetDashboard summarises an affiliate's referrals and earnings
+func (as *AffiliateService) GetDashboard(ctx context.Context, affiliateID uuid.UUID) (*models.AffiliateDashboard, error) {
+	code, err := as.GetOrCreateCode(ctx, affiliateID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &models.AffiliateDashboard{
+		Code:         code.Code,
+		RevenueShare: as.revenueShare,
+	}
+
+	db := as.db.WithContext(ctx)
+	if err := db.Model(&models.Referral{}).Where("affiliate_id = ?", affiliateID).Count(&dashboard.ReferredPlayers).Error; err != nil {
+		return nil, fmt.Errorf("failed to count referrals: %w", err)
+	}
+
+	err = db.Model(&models.RakeContribution{}).
+		Joins("JOIN referrals ON referrals.referred_user_id = rake_contributions.user_id AND referrals.deleted_at IS NULL").
+		Where("referrals.affiliate_id = ? AND rake_contributions.created_at >= ?", affiliateID, time.Now().AddDate(0, 0, -affiliateActiveDays)).
+		Select("COUNT(DISTINCT rake_contributions.user_id)").
+		Scan(&dashboard.ActivePlayers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active referrals: %w", err)
+	}
+
+	err = db.Model(&models.RakeContribution{}).
+		Joins("JOIN referrals ON referrals.referred_user_id = rake_contributions.user_id AND referrals.deleted_at IS NULL").
+		Where("referrals.affiliate_id = ? AND rake_contributions.affiliate_payout_id IS NULL", affiliateID).
+		Select("COALESCE(SUM(rake_contributions.amount), 0)").
+		Scan(&dashboard.PendingRake).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending rake: %w", err)
+	}
+	dashboard.PendingCommission = as.Commission(dashboard.PendingRake)
+
+	err = db.Model(&models.AffiliatePayout{}).
+		Where("affiliate_id = ?", affiliateID).
+		Select("COALESCE(SUM(payout_amount), 0)").
+		Scan(&dashboard.LifetimeCommission).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifetime commission: %w", err)
+	}
+
+	return dashboard, nil
+}
+
+// ListReferredPlayers returns the affiliate's referred players, newest first
+func (as *AffiliateService) ListReferredPlayers(ctx context.Context, affiliateID uuid.UUID, limit, offset int) ([]models.ReferredPlayer, int64, error) {
+	db := as.db.WithContext(ctx)
+
+	var total int64
+	if err := db.Model(&models.Referral{}).Where("affiliate_id = ?", affiliateID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count referrals: %w", err)
+	}
+
+	players := []models.ReferredPlayer{}
+	err := db.Table("referrals").
+		Select(`referrals.referred_user_id AS user_id, users.username, referrals.created_at AS referred_at,
+			COALESCE(SUM(rake_contributions.amount), 0) AS lifetime_rake,
+			COALESCE(SUM(rake_contributions.amount) FILTER (WHERE rake_contributions.affiliate_payout_id IS NULL), 0) AS pending_rake`).
+		Joins("JOIN users ON users.id = referrals.referred_user_id").
+		Joins("LEFT JOIN rake_contributions ON rake_contributions.user_id = referrals.referred_user_id AND rake_contributions.deleted_at IS NULL").
+		Where("referrals.affiliate_id = ? AND referrals.deleted_at IS NULL", affiliateID).
+		Group("referrals.referred_user_id, users.username, referrals.created_at").
+		Order("referrals.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&players).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list referred players: %w", err)
+	}
+
+	return players, total, nil
+}
+
+// ListPayouts returns the affiliate's revenue share payouts, newest first
+func (as *AffiliateService) ListPayouts(ctx context.Context, affiliateID uuid.UUID, limit, offset int) ([]models.AffiliatePayout, int64, error) {
+	query := as.db.WithContext(ctx).Model(&models.AffiliatePayout{}).Where("affiliate_id = ?", affiliateID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count affiliate payouts: %w", err)
+	}
+
+	var payouts []models.AffiliatePayout
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payouts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list affiliate payouts: %w", err)
+	}
+
+	return payouts, total, nil
+}
+
+// PayRevenueShare pays every affiliate their share of the rake their referred
+// players generated before periodEnd that hasn't been shared yet. Like
+// rakeback, covered contributions are linked to the payout so re-runs skip them.
+func (as *AffiliateService) PayRevenueShare(ctx context.Context, periodEnd time.Time) error {
+	slog.Info("Starting affiliate revenue share payout", "period_end", periodEnd)
+
+	var pending []uuid.UUID
+	err := as.db.WithContext(ctx).Model(&models.RakeContribution{}).
+		Distinct("referrals.affiliate_id").
+		Joins("JOIN referrals ON referrals.referred_user_id = rake_contributions.user_id AND referrals.deleted_at IS NULL").
+		Where("rake_contributions.affiliate_payout_id IS NULL AND rake_contributions.created_at < ?", periodEnd).
+		Pluck("referrals.affiliate_id", &pending).Error
+	if err != nil {
+		return fmt.Errorf("failed to get affiliates with pending referred rake: %w", err)
+	}
+
+	paid := 0
+	for _, affiliateID := range pending {
+		if err := as.payAffiliate(ctx, affiliateID, periodEnd); err != nil {
+			// Keep going so one failing affiliate doesn't block everyone else's payout
+			slog.Error("Failed to pay affiliate revenue share", "affiliate_id", affiliateID, "error", err)
+			continue
+		}
+		paid++
+	}
+
+	slog.Info("Affiliate revenue share payout completed", "period_end", periodEnd, "affiliates", len(pending), "paid", paid)
+	return nil
+}
+
+func (as *AffiliateService) payAffiliate(ctx context.Context, affiliateID uuid.UUID, periodEnd time.Time) error {
+	return as.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the unshared contributions so a concurrent run waits for this
+		// one and then finds them already linked to its payout
+		referred := tx.Model(&models.Referral{}).Select("referred_user_id").Where("affiliate_id = ?", affiliateID)
+		var contributions []models.RakeContribution
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id IN (?) AND affiliate_payout_id IS NULL AND created_at < ?", referred, periodEnd).
+			Find(&contributions).Error; err != nil {
+			return fmt.Errorf("failed to lock referred rake: %w", err)
+		}
+		if len(contributions) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, len(contributions))
+		var rakeAmount int64
+		for i, contribution := range contributions {
+			ids[i] = contribution.ID
+			rakeAmount += contribution.Amount
+		}
+
+		payout := &models.AffiliatePayout{
+			AffiliateID:  affiliateID,
+			Percentage:   as.revenueShare,
+			RakeAmount:   rakeAmount,
+			PayoutAmount: as.Commission(rakeAmount),
+			PeriodEnd:    periodEnd,
+		}
+		if err := tx.Create(payout).Error; err != nil {
+			return fmt.Errorf("failed to create affiliate payout: %w", err)
+		}
+
+		if err := tx.Model(&models.RakeContribution{}).
+			Where("id IN ?", ids).
+			Update("affiliate_payout_id", payout.ID).Error; err != nil {
+			return fmt.Errorf("failed to mark referred rake as shared: %w", err)
+		}
+
+		if payout.PayoutAmount <= 0 {
+			return nil
+		}
+
+		// The ledger reference is unique per affiliate and period, so a payout
+		// whose commit was lost is found rather than paid twice
+		transactionID, err := as.formanceService.PayAffiliateCommission(ctx, affiliateID, payout.PayoutAmount, periodEnd.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(payout).Update("transaction_id", transactionID).Error
+	})
+}
+
+// generateReferralCode draws a random code from referralCodeAlphabet
+func generateReferralCode() (string, error) {
+	buf := make([]byte, referralCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate referral code: %w", err)
+	}
+
+	// The alphabet has 32 letters, so taking each byte modulo 32 is unbiased
+	code := make([]byte, referralCodeLength)
+	for i, b := range buf {
+		code[i] = referralCodeAlphabet[int(b)%len(referralCodeAlphabet)]
+	}
+	return string(code), nil
+}
@@ -42,6 +42,13 @@ func (s *AuthService) RegisterUser(req models.CreateUserRequest) (*models.User,
 		return nil, fmt.Errorf("failed to check existing user: %w", err)
 	}
 
+	// Names given up by other players stay theirs for a while
+	if reserved, err := usernameReserved(s.db.DB, req.Username, time.Now()); err != nil {
+		return nil, err
+	} else if reserved {
+		return nil, ErrUsernameReserved
+	}
+
 	// Hash password
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
@@ -61,6 +68,16 @@ func (s *AuthService) RegisterUser(req models.CreateUserRequest) (*models.User,
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	s.setUpAccount(&user)
+
+	slog.Info("User registered successfully", "user_id", user.ID, "username", user.Username)
+	return &user, nil
+}
+
+// setUpAccount creates a new account's wallet and, unless its email is
+// already verified, sends the verification email. Failures are logged; the
+// wallet is created on first use and the email can be sent again.
+func (s *AuthService) setUpAccount(user *models.User) {
 	// Create wallet in Formance ledger
 	ctx := context.Background()
 	if err := s.formanceService.CreateUserWallet(ctx, user.ID); err != nil {
@@ -71,6 +88,10 @@ func (s *AuthService) RegisterUser(req models.CreateUserRequest) (*models.User,
 		slog.Info("User wallet created successfully", "user_id", user.ID)
 	}
 
+	if user.IsVerified {
+		return
+	}
+
 	// Create and send email verification
 	verification, err := s.CreateEmailVerification(user.ID)
 	if err != nil {
@@ -83,9 +104,6 @@ func (s *AuthService) RegisterUser(req models.CreateUserRequest) (*models.User,
 			slog.Info("Verification email sent successfully", "user_id", user.ID)
 		}
 	}
-
-	slog.Info("User registered successfully", "user_id", user.ID, "username", user.Username)
-	return &user, nil
 }
 
 func (s *AuthService) LoginUser(req models.LoginRequest) (*models.LoginResponse, error) {
@@ -103,18 +121,21 @@ func (s *AuthService) LoginUser(req models.LoginRequest) (*models.LoginResponse,
 	if err := auth.VerifyPassword(req.Password, user.PasswordHash); err != nil {
 		return nil, fmt.Errorf("invalid credentials")
 	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
 
-	// Generate JWT token
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email)
+	token, expiry, err := s.StartSession(&user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
 	slog.Info("User logged in successfully", "user_id", user.ID, "username", user.Username)
 
 	return &models.LoginResponse{
-		User:  user,
-		Token: token,
+		User:          user,
+		Token:         token,
+		SessionExpiry: expiry,
 	}, nil
 }
 
@@ -173,12 +194,50 @@ func (s *AuthService) VerifyEmail(token string) error {
 	return nil
 }
 
+// IssueToken signs a new token for the user, e.g. after their username changed
+func (s *AuthService) IssueToken(user *models.User) (string, error) {
+	token, _, err := s.StartSession(user)
+	return token, err
+}
+
+// StartSession signs the user in: their token lasts the idle timeout for
+// their role and is renewed by activity until the session's maximum lifetime
+func (s *AuthService) StartSession(user *models.User) (string, models.SessionExpiry, error) {
+	now := time.Now()
+	token, expiresAt, err := s.jwtManager.GenerateSessionToken(user.ID, user.Username, user.Email, user.Role, now)
+	if err != nil {
+		return "", models.SessionExpiry{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return token, s.sessionExpiry(expiresAt, now), nil
+}
+
+// RefreshSession renews the token of the session claims belong to. It fails
+// with auth.ErrSessionExpired once the session has reached its maximum
+// lifetime.
+func (s *AuthService) RefreshSession(claims *auth.Claims) (*models.SessionToken, error) {
+	token, expiresAt, err := s.jwtManager.RefreshToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SessionToken{Token: token, SessionExpiry: s.sessionExpiry(expiresAt, claims.StartedAt())}, nil
+}
+
+func (s *AuthService) sessionExpiry(expiresAt, sessionStart time.Time) models.SessionExpiry {
+	expiry := models.SessionExpiry{ExpiresAt: expiresAt}
+	if end, ok := s.jwtManager.SessionEnd(sessionStart); ok {
+		expiry.SessionExpiresAt = &end
+	}
+	return expiry
+}
+
 func (s *AuthService) UpdateUserProfile(userID uuid.UUID, updates map[string]interface{}) error {
 	// Remove sensitive fields
 	delete(updates, "password_hash")
 	delete(updates, "id")
 	delete(updates, "created_at")
 	delete(updates, "updated_at")
+	// Renames go through UsernameService so the old name is kept and reserved
+	delete(updates, "username")
 
 	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update user profile: %w", err)
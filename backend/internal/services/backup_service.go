@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// BackupOptions configures how consistency markers are taken
+type BackupOptions struct {
+	QuiesceTimeout time.Duration // Longest wait for ledger writes in flight
+	ManifestDir    string
+}
+
+// BackupService takes the consistency markers that let a database restored
+// from backups be reconciled with the Formance ledger
+type BackupService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	opts            BackupOptions
+}
+
+func NewBackupService(db *database.DB, formanceService *formance.Service, opts BackupOptions) *BackupService {
+	return &BackupService{db: db, formanceService: formanceService, opts: opts}
+}
+
+// TakeMarker briefly pauses ledger writes, records the latest ledger log and
+// each table's latest hand, and writes the marker's manifest. Returns an
+// error wrapping formance.ErrWritesBusy if writes in flight don't finish
+// within the quiesce timeout; nothing is recorded then.
+func (bs *BackupService) TakeMarker(ctx context.Context, trigger string, requestedBy *uuid.UUID) (*models.BackupMarker, error) {
+	quiesceCtx, cancel := context.WithTimeout(ctx, bs.opts.QuiesceTimeout)
+	defer cancel()
+
+	release, err := bs.formanceService.Quiesce(quiesceCtx)
+	if err != nil {
+		slog.Warn("Backup marker skipped, ledger writes did not pause", "trigger", trigger, "error", err)
+		return nil, err
+	}
+	paused := time.Now()
+	latest, err := bs.formanceService.LatestLog(ctx)
+	var tables []models.BackupTableMarker
+	if err == nil {
+		tables, err = bs.latestHands(ctx)
+	}
+	release()
+	quiesced := time.Since(paused)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesJSON, err := json.Marshal(tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal table markers: %w", err)
+	}
+	marker := &models.BackupMarker{
+		Trigger:     trigger,
+		RequestedBy: requestedBy,
+		Ledger:      bs.formanceService.LedgerName(),
+		Tables:      tablesJSON,
+		QuiescedMs:  quiesced.Milliseconds(),
+	}
+	if latest != nil {
+		marker.LedgerLogID = &latest.ID
+	}
+	if err := bs.db.WithContext(ctx).Create(marker).Error; err != nil {
+		return nil, fmt.Errorf("failed to record backup marker: %w", err)
+	}
+
+	path, err := bs.writeManifest(marker, tables)
+	if err != nil {
+		return marker, err
+	}
+	marker.ManifestPath = path
+	if err := bs.db.WithContext(ctx).Model(marker).Update("manifest_path", path).Error; err != nil {
+		return marker, fmt.Errorf("failed to record manifest path: %w", err)
+	}
+
+	slog.Info("Backup marker taken", "marker_id", marker.ID, "trigger", trigger, "ledger_log_id", marker.LedgerLogID, "tables", len(tables), "quiesced", quiesced, "manifest", path)
+	return marker, nil
+}
+
+// ListMarkers returns the newest markers first
+func (bs *BackupService) ListMarkers(ctx context.Context, limit int) ([]models.BackupMarker, error) {
+	var markers []models.BackupMarker
+	if err := bs.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&markers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list backup markers: %w", err)
+	}
+	return markers, nil
+}
+
+// latestHands returns the hand with the highest sequence at every table
+func (bs *BackupService) latestHands(ctx context.Context) ([]models.BackupTableMarker, error) {
+	var hands []models.HandHistory
+	err := bs.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (table_name) table_name, hand_id, sequence, ended_at
+		FROM hand_histories
+		WHERE deleted_at IS NULL
+		ORDER BY table_name, sequence DESC`).Scan(&hands).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest hands: %w", err)
+	}
+
+	tables := make([]models.BackupTableMarker, len(hands))
+	for i, hand := range hands {
+		tables[i] = models.BackupTableMarker{
+			TableName: hand.TableName,
+			HandID:    hand.HandID,
+			Sequence:  hand.Sequence,
+			Ended:     hand.EndedAt != nil,
+		}
+	}
+	return tables, nil
+}
+
+// writeManifest writes the marker's manifest to the manifest directory,
+// replacing the file in one step so a backup never picks up half of it
+func (bs *BackupService) writeManifest(marker *models.BackupMarker, tables []models.BackupTableMarker) (string, error) {
+	manifest := models.BackupManifest{
+		MarkerID:    marker.ID,
+		TakenAt:     marker.CreatedAt,
+		Trigger:     marker.Trigger,
+		Database:    bs.db.Migrator().CurrentDatabase(),
+		Ledger:      marker.Ledger,
+		LedgerLogID: marker.LedgerLogID,
+		QuiescedMs:  marker.QuiescedMs,
+		Tables:      tables,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal backup manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(bs.opts.ManifestDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	name := fmt.Sprintf("backup-marker-%s-%s.json", marker.CreatedAt.UTC().Format("20060102T150405Z"), marker.ID)
+	path := filepath.Join(bs.opts.ManifestDir, name)
+
+	tmp, err := os.CreateTemp(bs.opts.ManifestDir, name+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStatementRowNotFound = errors.New("statement row not found")
+	ErrStatementRowSettled  = errors.New("statement row has already been credited or rejected")
+)
+
+const (
+	// Deposit references are the prefix, random characters from the referral
+	// code alphabet and a check character
+	depositReferencePrefix = "GP"
+	depositReferenceRandom = 8
+	depositReferenceLength = len(depositReferencePrefix) + depositReferenceRandom + 1
+)
+
+// BankDepositService gives each player a deposit reference and credits the
+// bank transfers that quote it
+type BankDepositService struct {
+	db              *database.DB
+	formanceService *formance.Service
+}
+
+func NewBankDepositService(db *database.DB, formanceService *formance.Service) *BankDepositService {
+	return &BankDepositService{db: db, formanceService: formanceService}
+}
+
+// GetOrCreateReference returns the user's deposit reference, creating one
+// the first time
+func (bs *BankDepositService) GetOrCreateReference(ctx context.Context, userID uuid.UUID) (*models.DepositReference, error) {
+	var reference models.DepositReference
+	err := bs.db.WithContext(ctx).Where("user_id = ?", userID).First(&reference).Error
+	if err == nil {
+		return &reference, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get deposit reference: %w", err)
+	}
+
+	// Retry on the rare collision with an existing reference
+	for attempt := 0; attempt < 5; attempt++ {
+		generated, err := generateDepositReference()
+		if err != nil {
+			return nil, err
+		}
+
+		reference = models.DepositReference{UserID: userID, Code: generated}
+		result := bs.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&reference)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to create deposit reference: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return &reference, nil
+		}
+
+		// Either the code is taken or a concurrent request created the user's reference
+		var existing models.DepositReference
+		if err := bs.db.WithContext(ctx).Where("user_id = ?", userID).First(&existing).Error; err == nil {
+			return &existing, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to create deposit reference: no unique code found")
+}
+
+// ImportStatement records the incoming transfers of a bank statement and
+// credits those quoting exactly one player's deposit reference. Everything
+// else goes to the review queue. Rows imported before are skipped, so
+// overlapping statements can be imported safely.
+func (bs *BankDepositService) ImportStatement(ctx context.Context, importedBy uuid.UUID, rows []models.BankStatementRowInput) (*models.BankStatementImport, error) {
+	summary := &models.BankStatementImport{Rows: []models.BankStatementRow{}}
+
+	for _, input := range rows {
+		row := models.BankStatementRow{
+			BankTransactionID: strings.TrimSpace(input.BankTransactionID),
+			Amount:            input.Amount,
+			Description:       input.Description,
+			PayerName:         input.PayerName,
+			PostedAt:          input.PostedAt,
+			Status:            models.StatementRowInReview,
+			ImportedBy:        importedBy,
+		}
+		bs.match(ctx, &row)
+
+		result := bs.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if result.Error != nil {
+			return summary, fmt.Errorf("failed to record statement row %s: %w", row.BankTransactionID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			summary.Duplicates++
+			continue
+		}
+		summary.Imported++
+
+		if row.UserID != nil {
+			if err := bs.credit(ctx, &row, models.StatementMatchAuto); err != nil {
+				// The row stays in review, where it can be assigned once the ledger is back
+				slog.Error("Failed to credit matched bank deposit", "bank_transaction_id", row.BankTransactionID, "user_id", *row.UserID, "error", err)
+				bs.db.WithContext(ctx).Model(&row).Update("review_reason", "Matched but the credit failed")
+				row.ReviewReason = "Matched but the credit failed"
+			}
+		}
+
+		if row.Status == models.StatementRowCredited {
+			summary.Credited++
+		} else {
+			summary.InReview++
+		}
+		summary.Rows = append(summary.Rows, row)
+	}
+
+	slog.Info("Bank statement imported", "imported_by", importedBy, "imported", summary.Imported, "credited", summary.Credited, "in_review", summary.InReview, "duplicates", summary.Duplicates)
+	return summary, nil
+}
+
+// match finds the player the row's payment details name, leaving the row
+// for review with the reason when there isn't exactly one
+func (bs *BankDepositService) match(ctx context.Context, row *models.BankStatementRow) {
+	codes := FindDepositReferences(row.Description)
+	if len(codes) == 0 {
+		row.ReviewReason = "No deposit reference in the payment details"
+		return
+	}
+
+	var references []models.DepositReference
+	if err := bs.db.WithContext(ctx).Where("code IN ?", codes).Find(&references).Error; err != nil {
+		slog.Error("Failed to look up deposit references", "bank_transaction_id", row.BankTransactionID, "error", err)
+		row.ReviewReason = "Deposit reference lookup failed"
+		return
+	}
+	if len(references) == 0 {
+		row.ReviewReason = "Deposit reference belongs to no player"
+		return
+	}
+	if len(references) > 1 {
+		row.ReviewReason = "Payment details name several players"
+		return
+	}
+
+	var user models.User
+	if err := bs.db.WithContext(ctx).First(&user, "id = ?", references[0].UserID).Error; err != nil || user.DisabledAt != nil {
+		row.ReviewReason = "Deposit reference belongs to a disabled account"
+		return
+	}
+
+	row.Reference = references[0].Code
+	row.UserID = &references[0].UserID
+}
+
+// credit pays the row into the user's wallet and marks it credited
+func (bs *BankDepositService) credit(ctx context.Context, row *models.BankStatementRow, matchedBy string) error {
+	transactionID, err := bs.formanceService.CreditBankDeposit(ctx, *row.UserID, row.Amount, row.BankTransactionID, row.Reference)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"status":         models.StatementRowCredited,
+		"user_id":        *row.UserID,
+		"reference":      row.Reference,
+		"matched_by":     matchedBy,
+		"transaction_id": transactionID,
+		"review_reason":  "",
+	}
+	if err := bs.db.WithContext(ctx).Model(row).Updates(updates).Error; err != nil {
+		// The money has moved: crediting the row again from review is refused
+		// by the ledger, as the bank transaction ID is the reference
+		slog.Error("Bank deposit credited but not recorded", "bank_transaction_id", row.BankTransactionID, "user_id", *row.UserID, "transaction_id", transactionID, "error", err)
+	}
+
+	row.Status = models.StatementRowCredited
+	row.MatchedBy = matchedBy
+	row.TransactionID = transactionID
+	row.ReviewReason = ""
+	return nil
+}
+
+// ListRows returns statement rows, newest first, optionally in one state.
+// The review queue is the rows in review.
+func (bs *BankDepositService) ListRows(ctx context.Context, status string, limit, offset int) ([]models.BankStatementRow, int64, error) {
+	query := bs.db.WithContext(ctx).Model(&models.BankStatementRow{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count statement rows: %w", err)
+	}
+
+	rows := []models.BankStatementRow{}
+	if err := query.Order("posted_at DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list statement rows: %w", err)
+	}
+
+	return rows, total, nil
+}
+
+// AssignRow credits a row from the review queue to the player finance staff
+// identified as the payer
+func (bs *BankDepositService) AssignRow(ctx context.Context, rowID, userID, reviewerID uuid.UUID, note string) (*models.BankStatementRow, error) {
+	row, err := bs.reviewableRow(ctx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := bs.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	now := time.Now()
+	row.UserID = &userID
+	row.ReviewedBy = &reviewerID
+	row.ReviewNote = note
+	row.ReviewedAt = &now
+	if err := bs.db.WithContext(ctx).Model(row).Updates(map[string]interface{}{
+		"reviewed_by": reviewerID,
+		"review_note": note,
+		"reviewed_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	if err := bs.credit(ctx, row, models.StatementMatchManual); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Bank deposit assigned from review", "bank_transaction_id", row.BankTransactionID, "user_id", userID, "reviewer_id", reviewerID)
+	return row, nil
+}
+
+// RejectRow takes a row out of the review queue without crediting anyone,
+// e.g. a transfer that was sent back to the payer
+func (bs *BankDepositService) RejectRow(ctx context.Context, rowID, reviewerID uuid.UUID, note string) (*models.BankStatementRow, error) {
+	row, err := bs.reviewableRow(ctx, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := bs.db.WithContext(ctx).Model(&models.BankStatementRow{}).
+		Where("id = ? AND status = ?", rowID, models.StatementRowInReview).
+		Updates(map[string]interface{}{
+			"status":      models.StatementRowRejected,
+			"reviewed_by": reviewerID,
+			"review_note": note,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to reject statement row: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrStatementRowSettled
+	}
+
+	row.Status = models.StatementRowRejected
+	row.ReviewedBy = &reviewerID
+	row.ReviewNote = note
+	row.ReviewedAt = &now
+
+	slog.Info("Bank deposit rejected from review", "bank_transaction_id", row.BankTransactionID, "reviewer_id", reviewerID)
+	return row, nil
+}
+
+func (bs *BankDepositService) reviewableRow(ctx context.Context, rowID uuid.UUID) (*models.BankStatementRow, error) {
+	var row models.BankStatementRow
+	if err := bs.db.WithContext(ctx).First(&row, "id = ?", rowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStatementRowNotFound
+		}
+		return nil, fmt.Errorf("failed to get statement row: %w", err)
+	}
+	if row.Status != models.StatementRowInReview {
+		return nil, ErrStatementRowSettled
+	}
+	return &row, nil
+}
+
+// FindDepositReferences returns the well-formed deposit references in a
+// bank transfer's payment details. Banks and payers add spaces, dashes and
+// lower case, so those are ignored.
+func FindDepositReferences(details string) []string {
+	var compact strings.Builder
+	for _, r := range strings.ToUpper(details) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			compact.WriteRune(r)
+		}
+	}
+	text := compact.String()
+
+	var found []string
+	seen := map[string]bool{}
+	for i := 0; i+depositReferenceLength <= len(text); i++ {
+		candidate := text[i : i+depositReferenceLength]
+		if ValidDepositReference(candidate) && !seen[candidate] {
+			seen[candidate] = true
+			found = append(found, candidate)
+		}
+	}
+	return found
+}
+
+// ValidDepositReference reports whether code is a deposit reference with a
+// correct check character
+func ValidDepositReference(code string) bool {
+	if len(code) != depositReferenceLength || !strings.HasPrefix(code, depositReferencePrefix) {
+		return false
+	}
+	body := code[len(depositReferencePrefix) : depositReferenceLength-1]
+	for _, c := range body {
+		if !strings.ContainsRune(referralCodeAlphabet, c) {
+			return false
+		}
+	}
+	return code[depositReferenceLength-1] == depositReferenceCheck(body)
+}
+
+// depositReferenceCheck weights each character by its position modulo 31.
+// A prime modulus catches every mistyped character and swapped pair of
+// neighbours except between A and 9, the two letters 31 apart.
+func depositReferenceCheck(body string) byte {
+	sum := 0
+	for i, c := range body {
+		sum += strings.IndexRune(referralCodeAlphabet, c) * (i + 1)
+	}
+	return referralCodeAlphabet[sum%31]
+}
+
+// generateDepositReference draws the random characters of a new reference
+// and appends its check character
+func generateDepositReference() (string, error) {
+	buf := make([]byte, depositReferenceRandom)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate deposit reference: %w", err)
+	}
+
+	// The alphabet has 32 letters, so taking each byte modulo 32 is unbiased
+	body := make([]byte, depositReferenceRandom)
+	for i, b := range buf {
+		body[i] = referralCodeAlphabet[int(b)%len(referralCodeAlphabet)]
+	}
+	return depositReferencePrefix + string(body) + string(depositReferenceCheck(string(body))), nil
+}
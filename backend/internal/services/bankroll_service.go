@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrUnknownJurisdiction = errors.New("unknown jurisdiction")
+
+// RebuyRule limits buying back in at a table after losing a full stack
+// there. Zero disables a limit.
+type RebuyRule struct {
+	Cooldown    time.Duration // Wait before rebuying at the same table
+	WarnPercent int           // Warn when a rebuy is more than this share of the wallet
+}
+
+// CooldownRemaining returns how long a player who lost their stack at
+// bustedAt must still wait to rebuy
+func (r RebuyRule) CooldownRemaining(bustedAt, now time.Time) time.Duration {
+	if r.Cooldown <= 0 {
+		return 0
+	}
+	if remaining := bustedAt.Add(r.Cooldown).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// ExceedsWarning reports whether a rebuy is more than the warning share of
+// the wallet balance it comes out of
+func (r RebuyRule) ExceedsWarning(buyIn, walletBalance int64) bool {
+	if r.WarnPercent <= 0 || buyIn <= 0 {
+		return false
+	}
+	return buyIn*100 > walletBalance*int64(r.WarnPercent)
+}
+
+// Tighten applies a user's own limits on top of the rule, keeping whichever
+// is stricter: the longer cool-down and the lower warning share
+func (r RebuyRule) Tighten(cooldownMinutes, warnPercent *int) RebuyRule {
+	if cooldownMinutes != nil {
+		if own := time.Duration(*cooldownMinutes) * time.Minute; own > r.Cooldown {
+			r.Cooldown = own
+		}
+	}
+	if warnPercent != nil && *warnPercent > 0 && (r.WarnPercent <= 0 || *warnPercent < r.WarnPercent) {
+		r.WarnPercent = *warnPercent
+	}
+	return r
+}
+
+func (r RebuyRule) limits() models.RebuyLimits {
+	return models.RebuyLimits{
+		CooldownMinutes: int(r.Cooldown / time.Minute),
+		WarnPercent:     r.WarnPercent,
+	}
+}
+
+// BankrollRules are the rebuy rules of each jurisdiction. The "*" entry
+// covers jurisdictions without their own.
+type BankrollRules struct {
+	DefaultJurisdiction string
+	Jurisdictions       map[string]RebuyRule
+}
+
+// ForJurisdiction returns a jurisdiction's rebuy rule
+func (r BankrollRules) ForJurisdiction(jurisdiction string) RebuyRule {
+	if rule, ok := r.Jurisdictions[jurisdiction]; ok {
+		return rule
+	}
+	return r.Jurisdictions["*"]
+}
+
+// Known reports whether a jurisdiction can be assigned to users
+func (r BankrollRules) Known(jurisdiction string) bool {
+	if jurisdiction == r.DefaultJurisdiction {
+		return true
+	}
+	_, ok := r.Jurisdictions[jurisdiction]
+	return ok && jurisdiction != "*"
+}
+
+// BankrollService decides the rebuy limits of each user from their
+// jurisdiction and their own responsible gaming settings
+type BankrollService struct {
+	db    *database.DB
+	rules BankrollRules
+}
+
+func NewBankrollService(db *database.DB, rules BankrollRules) *BankrollService {
+	return &BankrollService{db: db, rules: rules}
+}
+
+// RebuyRule returns the limits enforced on a user's rebuys
+func (bs *BankrollService) RebuyRule(ctx context.Context, userID uuid.UUID) (RebuyRule, error) {
+	settings, err := bs.settings(ctx, userID)
+	if err != nil {
+		return RebuyRule{}, err
+	}
+	return bs.rules.ForJurisdiction(bs.jurisdiction(settings)).Tighten(settings.RebuyCooldownMinutes, settings.RebuyWarnPercent), nil
+}
+
+// Summary reports a user's jurisdiction and rebuy limits
+func (bs *BankrollService) Summary(ctx context.Context, userID uuid.UUID) (*models.BankrollSummary, error) {
+	settings, err := bs.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	jurisdiction := bs.jurisdiction(settings)
+	rule := bs.rules.ForJurisdiction(jurisdiction)
+	return &models.BankrollSummary{
+		Jurisdiction:         jurisdiction,
+		JurisdictionLimits:   rule.limits(),
+		RebuyCooldownMinutes: settings.RebuyCooldownMinutes,
+		RebuyWarnPercent:     settings.RebuyWarnPercent,
+		Effective:            rule.Tighten(settings.RebuyCooldownMinutes, settings.RebuyWarnPercent).limits(),
+	}, nil
+}
+
+// Jurisdictions lists every jurisdiction that can be assigned and its limits
+func (bs *BankrollService) Jurisdictions() map[string]models.RebuyLimits {
+	limits := map[string]models.RebuyLimits{
+		bs.rules.DefaultJurisdiction: bs.rules.ForJurisdiction(bs.rules.DefaultJurisdiction).limits(),
+	}
+	for jurisdiction, rule := range bs.rules.Jurisdictions {
+		if jurisdiction != "*" {
+			limits[jurisdiction] = rule.limits()
+		}
+	}
+	return limits
+}
+
+// UpdateSettings replaces the user's own rebuy limits
+func (bs *BankrollService) UpdateSettings(ctx context.Context, userID uuid.UUID, req models.UpdateBankrollSettingsRequest) (*models.BankrollSummary, error) {
+	settings, err := bs.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.RebuyCooldownMinutes = req.RebuyCooldownMinutes
+	settings.RebuyWarnPercent = req.RebuyWarnPercent
+	if err := bs.save(ctx, settings, "rebuy_cooldown_minutes", "rebuy_warn_percent"); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Rebuy limits updated", "user_id", userID, "cooldown_minutes", req.RebuyCooldownMinutes, "warn_percent", req.RebuyWarnPercent)
+	return bs.Summary(ctx, userID)
+}
+
+// SetJurisdiction assigns the jurisdiction whose rebuy limits apply to a user
+func (bs *BankrollService) SetJurisdiction(ctx context.Context, userID, adminID uuid.UUID, jurisdiction string) (*models.BankrollSummary, error) {
+	jurisdiction = strings.TrimSpace(jurisdiction)
+	if !bs.rules.Known(jurisdiction) {
+		return nil, ErrUnknownJurisdiction
+	}
+
+	settings, err := bs.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := bs.jurisdiction(settings)
+
+	settings.Jurisdiction = jurisdiction
+	if err := bs.save(ctx, settings, "jurisdiction"); err != nil {
+		return nil, err
+	}
+
+	slog.Info("User jurisdiction updated", "user_id", userID, "from", previous, "to", jurisdiction, "admin_id", adminID)
+	return bs.Summary(ctx, userID)
+}
+
+// settings returns the user's bankroll settings, empty if they never set any
+func (bs *BankrollService) settings(ctx context.Context, userID uuid.UUID) (*models.BankrollSettings, error) {
+	var user models.User
+	if err := bs.db.WithContext(ctx).Select("id").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	settings := &models.BankrollSettings{UserID: userID}
+	err := bs.db.WithContext(ctx).First(settings, "user_id = ?", userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get bankroll settings: %w", err)
+	}
+	return settings, nil
+}
+
+// save writes the given columns of the settings, creating the row if needed
+func (bs *BankrollService) save(ctx context.Context, settings *models.BankrollSettings, columns ...string) error {
+	err := bs.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(settings).Error
+	if err != nil {
+		return fmt.Errorf("failed to save bankroll settings: %w", err)
+	}
+	return nil
+}
+
+func (bs *BankrollService) jurisdiction(settings *models.BankrollSettings) string {
+	if settings.Jurisdiction != "" {
+		return settings.Jurisdiction
+	}
+	return bs.rules.DefaultJurisdiction
+}
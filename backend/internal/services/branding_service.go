@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidBranding is returned for branding the lobby could not show safely
+var ErrInvalidBranding = errors.New("invalid branding")
+
+// BrandingService sets the sponsor and partner branding of tables and
+// tournaments
+type BrandingService struct {
+	db *database.DB
+}
+
+func NewBrandingService(db *database.DB) *BrandingService {
+	return &BrandingService{db: db}
+}
+
+// NormalizeBranding checks branding from an admin and returns it as stored:
+// trimmed, with the banner served over HTTPS and the accent color as
+// "#rrggbb"
+func NormalizeBranding(req models.UpdateBrandingRequest) (models.Branding, error) {
+	branding := models.Branding{
+		BannerURL:   strings.TrimSpace(req.BannerURL),
+		SponsorName: strings.TrimSpace(req.SponsorName),
+		AccentColor: strings.ToLower(strings.TrimSpace(req.AccentColor)),
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	if branding.BannerURL != "" {
+		banner, err := url.Parse(branding.BannerURL)
+		if err != nil || banner.Scheme != "https" || banner.Host == "" {
+			return models.Branding{}, fmt.Errorf("%w: the banner must be an https URL", ErrInvalidBranding)
+		}
+	}
+
+	switch color := branding.AccentColor; len(color) {
+	case 0, 7:
+	case 4:
+		// Short form, e.g. "#f60"
+		branding.AccentColor = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	default:
+		return models.Branding{}, fmt.Errorf("%w: the accent color must be #rgb or #rrggbb", ErrInvalidBranding)
+	}
+
+	return branding, nil
+}
+
+// SetTableBranding replaces a table's branding
+func (s *BrandingService) SetTableBranding(ctx context.Context, tableID uuid.UUID, req models.UpdateBrandingRequest, adminID uuid.UUID) (*models.PokerTable, error) {
+	branding, err := NormalizeBranding(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var table models.PokerTable
+	if err := s.db.WithContext(ctx).First(&table, "id = ?", tableID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to get table: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&table).Updates(brandingColumns(branding)).Error; err != nil {
+		return nil, fmt.Errorf("failed to update table branding: %w", err)
+	}
+	table.Branding = branding
+
+	slog.Info("Table branding updated", "table_id", tableID, "admin_id", adminID, "sponsor", branding.SponsorName)
+	return &table, nil
+}
+
+// SetTournamentBranding replaces a tournament's branding
+func (s *BrandingService) SetTournamentBranding(ctx context.Context, tournamentID uuid.UUID, req models.UpdateBrandingRequest, adminID uuid.UUID) (*models.Tournament, error) {
+	branding, err := NormalizeBranding(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var tournament models.Tournament
+	if err := s.db.WithContext(ctx).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&tournament).Updates(brandingColumns(branding)).Error; err != nil {
+		return nil, fmt.Errorf("failed to update tournament branding: %w", err)
+	}
+	tournament.Branding = branding
+
+	slog.Info("Tournament branding updated", "tournament_id", tournamentID, "admin_id", adminID, "sponsor", branding.SponsorName)
+	return &tournament, nil
+}
+
+// brandingColumns lists every branding column, so that cleared fields are
+// written too
+func brandingColumns(branding models.Branding) map[string]interface{} {
+	return map[string]interface{}{
+		"branding_banner_url":   branding.BannerURL,
+		"branding_sponsor_name": branding.SponsorName,
+		"branding_accent_color": branding.AccentColor,
+		"branding_description":  branding.Description,
+	}
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrModeratedMessageNotFound = errors.New("moderated message not found")
+	// ErrNotModerator is returned when someone other than a moderator or
+	// admin tries to mute a player
+	ErrNotModerator = errors.New("only moderators can mute players")
+	// ErrCannotMuteModerator is returned for a mute aimed at a moderator or admin
+	ErrCannotMuteModerator = errors.New("moderators can't be muted")
+	// ErrChatMuteNotFound is returned when lifting a mute that isn't in force
+	ErrChatMuteNotFound = errors.New("player is not muted at this table")
+)
+
+// chatPenalties escalate with each offence inside chatPenaltyWindow: a
+// warning first, then longer and longer mutes
+var chatPenalties = []time.Duration{0, 10 * time.Minute, time.Hour, 24 * time.Hour}
+
+const chatPenaltyWindow = 7 * 24 * time.Hour
+
+// ChatModerationResult is the outcome of moderating one chat message
+type ChatModerationResult struct {
+	Allowed    bool
+	Reasons    []string   // Why the message was blocked
+	Strike     int        // Offences in the penalty window, including this one
+	MutedUntil *time.Time // Set while the sender is muted
+}
+
+// ChatModerationService screens table chat and direct messages, mutes repeat
+// offenders and keeps blocked messages for moderator review
+type ChatModerationService struct {
+	db        *database.DB
+	moderator ContentModerator
+	rate      slidingWindowLimiter[uuid.UUID]
+}
+
+// NewChatModerationService creates a moderation service using the built-in
+// keyword moderator
+func NewChatModerationService(db *database.DB) *ChatModerationService {
+	return &ChatModerationService{
+		db:        db,
+		moderator: NewKeywordModerator(),
+	}
+}
+
+// SetModerator replaces the content moderator, e.g. with an external service
+func (cms *ChatModerationService) SetModerator(moderator ContentModerator) {
+	cms.moderator = moderator
+}
+
+// SetBlockedWords has the built-in keyword moderator block words on top of
+// its own list. Words ending in "*" also match any word starting with them.
+func (cms *ChatModerationService) SetBlockedWords(words []string) {
+	cms.moderator = NewKeywordModerator(words...)
+}
+
+// SetRateLimit lets each user send at most limit chat messages per window,
+// 0 for no limit
+func (cms *ChatModerationService) SetRateLimit(limit int, window time.Duration) {
+	cms.rate.reset(limit, window)
+}
+
+// AllowRate reports whether userID may send another chat message at now,
+// counting it if so
+func (cms *ChatModerationService) AllowRate(userID uuid.UUID, now time.Time) bool {
+	return cms.rate.allow(userID, now)
+}
+
+// PenaltyFor returns how long a sender is muted for their nth offence
+func PenaltyFor(strike int) time.Duration {
+	if strike < 1 {
+		return 0
+	}
+	if strike > len(chatPenalties) {
+		strike = len(chatPenalties)
+	}
+	return chatPenalties[strike-1]
+}
+
+// ReviewMessage decides whether userID may send text on a channel. Muted
+// senders are refused without a new offence; flagged messages are recorded
+// and penalised.
+func (cms *ChatModerationService) ReviewMessage(ctx context.Context, userID uuid.UUID, channel, target, text string) (*ChatModerationResult, error) {
+	now := time.Now()
+
+	mutedUntil, err := cms.MutedUntil(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mutedUntil != nil {
+		return &ChatModerationResult{MutedUntil: mutedUntil}, nil
+	}
+
+	verdict, err := cms.moderator.Check(ctx, userID, text)
+	if err != nil {
+		// A moderation outage shouldn't take chat down with it
+		slog.Warn("Content moderation failed, allowing message", "user_id", userID, "error", err)
+		return &ChatModerationResult{Allowed: true}, nil
+	}
+	if !verdict.Flagged() {
+		return &ChatModerationResult{Allowed: true}, nil
+	}
+
+	var offences int64
+	err = cms.db.WithContext(ctx).Model(&models.ModeratedMessage{}).
+		Where("user_id = ? AND status <> ? AND created_at > ?", userID, models.ModerationOverturned, now.Add(-chatPenaltyWindow)).
+		Count(&offences).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chat offences: %w", err)
+	}
+
+	result := &ChatModerationResult{
+		Reasons: verdict.Reasons,
+		Strike:  int(offences) + 1,
+	}
+	if penalty := PenaltyFor(result.Strike); penalty > 0 {
+		until := now.Add(penalty)
+		result.MutedUntil = &until
+	}
+
+	if runes := []rune(text); len(runes) > 1000 {
+		text = string(runes[:1000])
+	}
+	record := &models.ModeratedMessage{
+		UserID:     userID,
+		Channel:    channel,
+		Target:     target,
+		Body:       text,
+		Reasons:    strings.Join(verdict.Reasons, ","),
+		Strike:     result.Strike,
+		MutedUntil: result.MutedUntil,
+		Status:     models.ModerationPending,
+	}
+	if err := cms.db.WithContext(ctx).Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record moderated message: %w", err)
+	}
+
+	slog.Info("Chat message blocked", "user_id", userID, "channel", channel, "reasons", record.Reasons, "strike", result.Strike, "muted_until", result.MutedUntil)
+	return result, nil
+}
+
+// MutedUntil returns when the user's current mute ends, or nil if they may chat
+func (cms *ChatModerationService) MutedUntil(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var record models.ModeratedMessage
+	err := cms.db.WithContext(ctx).
+		Where("user_id = ? AND status <> ? AND muted_until > ?", userID, models.ModerationOverturned, time.Now()).
+		Order("muted_until DESC").
+		First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check chat mute: %w", err)
+	}
+	return record.MutedUntil, nil
+}
+
+// ListModeratedMessages returns automatically blocked messages with the given
+// status, oldest first
+func (cms *ChatModerationService) ListModeratedMessages(ctx context.Context, status string, limit, offset int) ([]models.ModeratedMessage, int64, error) {
+	query := cms.db.WithContext(ctx).Model(&models.ModeratedMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count moderated messages: %w", err)
+	}
+
+	var messages []models.ModeratedMessage
+	if err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list moderated messages: %w", err)
+	}
+	return messages, total, nil
+}
+
+// ReviewModeratedMessage records a moderator's decision. Overturned messages
+// no longer count as offences and any mute they caused is lifted.
+func (cms *ChatModerationService) ReviewModeratedMessage(ctx context.Context, messageID, reviewerID uuid.UUID, status string) (*models.ModeratedMessage, error) {
+	var message models.ModeratedMessage
+	if err := cms.db.WithContext(ctx).First(&message, "id = ?", messageID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrModeratedMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to get moderated message: %w", err)
+	}
+
+	now := time.Now()
+	message.Status = status
+	message.ReviewedBy = &reviewerID
+	message.ReviewedAt = &now
+	if err := cms.db.WithContext(ctx).Save(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to review moderated message: %w", err)
+	}
+
+	slog.Info("Moderated message reviewed", "message_id", message.ID, "user_id", message.UserID, "status", status, "reviewer_id", reviewerID)
+	return &message, nil
+}
+
+// MuteAtTable silences a player in one table's chat for a number of
+// minutes, 0 until the mute is lifted. Only moderators and admins may mute,
+// and they can't be muted themselves. A player already muted there has the
+// mute replaced.
+func (cms *ChatModerationService) MuteAtTable(ctx context.Context, table string, userID, moderatorID uuid.UUID, minutes int, reason string) (*models.TableChatMute, error) {
+	if err := cms.requireModerator(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+
+	var target models.User
+	if err := cms.db.WithContext(ctx).Select("id", "username", "role").First(&target, "id = ?", userID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if target.Role == models.UserRoleMod || target.Role == models.UserRoleAdmin {
+		return nil, ErrCannotMuteModerator
+	}
+
+	now := time.Now()
+	mute := &models.TableChatMute{
+		Table:    table,
+		UserID:   userID,
+		Username: target.Username,
+		MutedBy:  moderatorID,
+		Reason:   reason,
+	}
+	if minutes > 0 {
+		expires := now.Add(time.Duration(minutes) * time.Minute)
+		mute.ExpiresAt = &expires
+	}
+
+	err := cms.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := liftTableMutes(tx, table, userID, moderatorID, now); err != nil {
+			return err
+		}
+		return tx.Create(mute).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mute player: %w", err)
+	}
+
+	slog.Info("Player muted at table", "table", table, "user_id", userID, "moderator_id", moderatorID, "expires_at", mute.ExpiresAt, "reason", reason)
+	return mute, nil
+}
+
+// UnmuteAtTable lifts a player's mute in one table's chat, returning it
+func (cms *ChatModerationService) UnmuteAtTable(ctx context.Context, table string, userID, moderatorID uuid.UUID) (*models.TableChatMute, error) {
+	if err := cms.requireModerator(ctx, moderatorID); err != nil {
+		return nil, err
+	}
+	mute, err := cms.TableMute(ctx, table, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mute == nil {
+		return nil, ErrChatMuteNotFound
+	}
+	now := time.Now()
+	if err := liftTableMutes(cms.db.WithContext(ctx), table, userID, moderatorID, now); err != nil {
+		return nil, fmt.Errorf("failed to unmute player: %w", err)
+	}
+	mute.LiftedAt = &now
+	mute.LiftedBy = &moderatorID
+
+	slog.Info("Player unmuted at table", "table", table, "user_id", userID, "moderator_id", moderatorID)
+	return mute, nil
+}
+
+// LiftTableMute lifts a mute by its ID, wherever it was set
+func (cms *ChatModerationService) LiftTableMute(ctx context.Context, muteID, moderatorID uuid.UUID) (*models.TableChatMute, error) {
+	var mute models.TableChatMute
+	if err := cms.db.WithContext(ctx).First(&mute, "id = ?", muteID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrChatMuteNotFound
+		}
+		return nil, fmt.Errorf("failed to get chat mute: %w", err)
+	}
+	now := time.Now()
+	if !mute.ActiveAt(now) {
+		return nil, ErrChatMuteNotFound
+	}
+
+	mute.LiftedAt = &now
+	mute.LiftedBy = &moderatorID
+	if err := cms.db.WithContext(ctx).Save(&mute).Error; err != nil {
+		return nil, fmt.Errorf("failed to lift chat mute: %w", err)
+	}
+
+	slog.Info("Table chat mute lifted", "mute_id", mute.ID, "table", mute.Table, "user_id", mute.UserID, "moderator_id", moderatorID)
+	return &mute, nil
+}
+
+// TableMute returns the mute silencing a player at a table, or nil
+func (cms *ChatModerationService) TableMute(ctx context.Context, table string, userID uuid.UUID) (*models.TableChatMute, error) {
+	var mute models.TableChatMute
+	err := cms.db.WithContext(ctx).
+		Where("table_name = ? AND user_id = ? AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", table, userID, time.Now()).
+		Order("created_at DESC").
+		First(&mute).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check table mute: %w", err)
+	}
+	return &mute, nil
+}
+
+// ListTableMutes returns the mutes in force, at one table if given, newest
+// first
+func (cms *ChatModerationService) ListTableMutes(ctx context.Context, table string) ([]models.TableChatMute, error) {
+	query := cms.db.WithContext(ctx).Where("lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	if table != "" {
+		query = query.Where("table_name = ?", table)
+	}
+
+	var mutes []models.TableChatMute
+	if err := query.Order("created_at DESC").Find(&mutes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list table mutes: %w", err)
+	}
+	return mutes, nil
+}
+
+// RecordMessage keeps a table chat message in the chat history with what
+// became of it
+func (cms *ChatModerationService) RecordMessage(ctx context.Context, message *models.ChatMessage) error {
+	if runes := []rune(message.Body); len(runes) > 1000 {
+		message.Body = string(runes[:1000])
+	}
+	if err := cms.db.WithContext(ctx).Create(message).Error; err != nil {
+		return fmt.Errorf("failed to record chat message: %w", err)
+	}
+	return nil
+}
+
+// ChatHistoryFilter narrows the chat history. Empty fields match everything.
+type ChatHistoryFilter struct {
+	Table  string
+	UserID uuid.UUID
+	Status string
+	Since  time.Time
+}
+
+// ListChatHistory returns table chat messages matching filter, newest first
+func (cms *ChatModerationService) ListChatHistory(ctx context.Context, filter ChatHistoryFilter, limit, offset int) ([]models.ChatMessage, int64, error) {
+	query := cms.db.WithContext(ctx).Model(&models.ChatMessage{})
+	if filter.Table != "" {
+		query = query.Where("table_name = ?", filter.Table)
+	}
+	if filter.UserID != uuid.Nil {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count chat history: %w", err)
+	}
+
+	var messages []models.ChatMessage
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list chat history: %w", err)
+	}
+	return messages, total, nil
+}
+
+// requireModerator returns ErrNotModerator unless userID is a moderator or
+// admin
+func (cms *ChatModerationService) requireModerator(ctx context.Context, userID uuid.UUID) error {
+	var user models.User
+	if err := cms.db.WithContext(ctx).Select("id", "role").First(&user, "id = ?", userID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return ErrNotModerator
+		}
+		return fmt.Errorf("failed to get moderator: %w", err)
+	}
+	if user.Role != models.UserRoleMod && user.Role != models.UserRoleAdmin {
+		return ErrNotModerator
+	}
+	return nil
+}
+
+// liftTableMutes lifts every mute in force on a player at a table
+func liftTableMutes(tx *gorm.DB, table string, userID, moderatorID uuid.UUID, now time.Time) error {
+	return tx.Model(&models.TableChatMute{}).
+		Where("table_name = ? AND user_id = ? AND lifted_at IS NULL", table, userID).
+		Updates(map[string]interface{}{"lifted_at": now, "lifted_by": moderatorID}).Error
+}
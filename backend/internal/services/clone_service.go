@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCloneCallTime  = errors.New("call time is only available at private tables")
+	ErrCloneStartTime = errors.New("start time is required for scheduled tournaments")
+)
+
+// CloneService copies the configuration of existing tables and tournaments
+// so admins can schedule similar events without entering it all again
+type CloneService struct {
+	db *database.DB
+}
+
+// NewCloneService creates a new clone service
+func NewCloneService(db *database.DB) *CloneService {
+	return &CloneService{db: db}
+}
+
+// ClonedTable returns a new table with the source's stakes and policies.
+// Seated players, status and call time are not copied; the copy is not tied
+// to the source's stake template so template balancing leaves it alone.
+func ClonedTable(source *models.PokerTable, name string, callTime *time.Time, createdBy uuid.UUID) models.PokerTable {
+	return models.PokerTable{
+		Name:                name,
+		TableType:           source.TableType,
+		GameType:            source.GameType,
+		HiLo:                source.HiLo,
+		MaxPlayers:          source.MaxPlayers,
+		MinBuyIn:            source.MinBuyIn,
+		MaxBuyIn:            source.MaxBuyIn,
+		SmallBlind:          source.SmallBlind,
+		BigBlind:            source.BigBlind,
+		IsPrivate:           source.IsPrivate,
+		PasswordHash:        source.PasswordHash,
+		Status:              "waiting",
+		CreatedBy:           createdBy,
+		SeatSelection:       source.SeatSelection,
+		EnforceSeparation:   source.EnforceSeparation,
+		AllowPartialCashOut: source.AllowPartialCashOut,
+		CallTime:            callTime,
+		CallTimeHands:       source.CallTimeHands,
+		MinPlayMinutes:      source.MinPlayMinutes,
+		BigWinAmount:        source.BigWinAmount,
+		DealersChoice:       source.DealersChoice,
+		MinPlayers:          source.MinPlayers,
+		ShortHandedMinutes:  source.ShortHandedMinutes,
+		StackType:           source.StackType,
+		ChipCap:             source.ChipCap,
+
+		ActionTimeoutSeconds: source.ActionTimeoutSeconds,
+		SitOutAfterTimeouts:  source.SitOutAfterTimeouts,
+		SitOutCashOutMinutes: source.SitOutCashOutMinutes,
+
+		RakePercentage: source.RakePercentage,
+		RakeCap:        source.RakeCap,
+		RakeMinPot:     source.RakeMinPot,
+
+		Branding: source.Branding,
+	}
+}
+
+// ClonedTournament returns a new tournament with the source's buy-in, blind
+// structure and payouts, open for registration at the given start time
+func ClonedTournament(source *models.Tournament, name string, startTime *time.Time) models.Tournament {
+	return models.Tournament{
+		Name:            name,
+		TournamentType:  source.TournamentType,
+		BuyIn:           source.BuyIn,
+		MaxPlayers:      source.MaxPlayers,
+		Status:          "registering",
+		StartTime:       startTime,
+		BlindStructure:  source.BlindStructure,
+		PayoutStructure: source.PayoutStructure,
+		PayoutModel:     source.PayoutModel,
+		StartingChips:   source.StartingChips,
+		Branding:        source.Branding,
+	}
+}
+
+// CloneTable creates a copy of a table under a new name
+func (cs *CloneService) CloneTable(ctx context.Context, sourceID uuid.UUID, req models.CloneTableRequest, adminID uuid.UUID) (*models.PokerTable, error) {
+	var source models.PokerTable
+	if err := cs.db.WithContext(ctx).First(&source, "id = ?", sourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to get table: %w", err)
+	}
+	if req.CallTime != nil && !source.IsPrivate {
+		return nil, ErrCloneCallTime
+	}
+
+	table := ClonedTable(&source, req.Name, req.CallTime, adminID)
+	err := cs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&table).Error; err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+		// Zero is skipped on insert in favour of the column default
+		if zeroed := source.ZeroedDefaults(); len(zeroed) > 0 {
+			if err := tx.Model(&table).Updates(zeroed).Error; err != nil {
+				return fmt.Errorf("failed to create table: %w", err)
+			}
+			table.ShortHandedMinutes = source.ShortHandedMinutes
+			table.ActionTimeoutSeconds = source.ActionTimeoutSeconds
+			table.SitOutAfterTimeouts = source.SitOutAfterTimeouts
+			table.SitOutCashOutMinutes = source.SitOutCashOutMinutes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Table cloned", "source_id", source.ID, "table_id", table.ID, "name", table.Name, "admin_id", adminID)
+	return &table, nil
+}
+
+// CloneTournament creates a copy of a tournament under a new name and start time
+func (cs *CloneService) CloneTournament(ctx context.Context, sourceID uuid.UUID, req models.CloneTournamentRequest, adminID uuid.UUID) (*models.Tournament, error) {
+	var source models.Tournament
+	if err := cs.db.WithContext(ctx).First(&source, "id = ?", sourceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to get tournament: %w", err)
+	}
+	if req.StartTime == nil && source.TournamentType == "scheduled" {
+		return nil, ErrCloneStartTime
+	}
+
+	tournament := ClonedTournament(&source, req.Name, req.StartTime)
+	if err := cs.db.WithContext(ctx).Create(&tournament).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tournament: %w", err)
+	}
+
+	slog.Info("Tournament cloned", "source_id", source.ID, "tournament_id", tournament.ID, "name", tournament.Name, "start_time", tournament.StartTime, "admin_id", adminID)
+	return &tournament, nil
+}
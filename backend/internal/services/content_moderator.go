@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a chat message can be flagged for
+const (
+	ModerationReasonProfanity = "profanity"
+	ModerationReasonRepeat    = "repeat"
+	ModerationReasonLink      = "link"
+	ModerationReasonPhone     = "phone_number"
+)
+
+// ModerationVerdict lists why a message was flagged. A message with no
+// reasons may be delivered.
+type ModerationVerdict struct {
+	Reasons []string
+}
+
+// Flagged reports whether the message should be blocked
+func (v ModerationVerdict) Flagged() bool {
+	return len(v.Reasons) > 0
+}
+
+// ContentModerator decides whether a chat message may be delivered. The
+// built-in KeywordModerator can be replaced with an external service.
+type ContentModerator interface {
+	Check(ctx context.Context, senderID uuid.UUID, text string) (ModerationVerdict, error)
+}
+
+// profanity lists English and Mongolian (Cyrillic and common Latin
+// spellings) words that are blocked outright. Entries ending in "*" also
+// match any word starting with them.
+var profanity = []string{
+	// English
+	"fuck*", "motherfuck*", "shit*", "bullshit", "bitch*", "cunt*", "asshole*",
+	"bastard*", "dickhead*", "whore*", "slut*", "wanker*", "retard*",
+	// Mongolian
+	"новш*", "гичий*", "янхан*", "өлөгчин*", "пизд*", "бляд*", "хуй*",
+	"novsh*", "gichii*", "yanhan*", "ulugchin*", "olgochin*", "blyad*",
+}
+
+// leetReplacer undoes common character swaps used to dodge word filters
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+var (
+	linkPattern  = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\b[a-z0-9-]+\.(com|net|org|mn|io|gg|xyz|ru|info|link|me|tk|top)\b`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-().]{6,}\d`)
+)
+
+const (
+	mongolianPhoneDigits = 8
+	repeatWindow         = 2 * time.Minute
+	repeatLimit          = 3 // The same message this many times within repeatWindow is spam
+)
+
+// KeywordModerator is the built-in detector for profanity and spam: repeated
+// messages, links and phone numbers
+type KeywordModerator struct {
+	words  map[string]bool
+	stems  []string
+	recent sync.Map // sender ID -> *recentMessages
+}
+
+type recentMessages struct {
+	mu       sync.Mutex
+	messages []sentMessage
+}
+
+type sentMessage struct {
+	text string
+	at   time.Time
+}
+
+// NewKeywordModerator creates the built-in moderator. extraWords are blocked
+// in addition to the built-in lists, using the same "*" suffix for stems.
+func NewKeywordModerator(extraWords ...string) *KeywordModerator {
+	km := &KeywordModerator{words: make(map[string]bool)}
+	for _, word := range append(append([]string{}, profanity...), extraWords...) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if stem, ok := strings.CutSuffix(word, "*"); ok {
+			km.stems = append(km.stems, squeeze(stem))
+		} else if word != "" {
+			km.words[squeeze(word)] = true
+		}
+	}
+	return km
+}
+
+// Check flags profanity, links, phone numbers and repeated messages
+func (km *KeywordModerator) Check(ctx context.Context, senderID uuid.UUID, text string) (ModerationVerdict, error) {
+	var verdict ModerationVerdict
+
+	if km.profane(text) {
+		verdict.Reasons = append(verdict.Reasons, ModerationReasonProfanity)
+	}
+	if linkPattern.MatchString(text) {
+		verdict.Reasons = append(verdict.Reasons, ModerationReasonLink)
+	}
+	if containsPhoneNumber(text) {
+		verdict.Reasons = append(verdict.Reasons, ModerationReasonPhone)
+	}
+	if km.repeated(senderID, text, time.Now()) {
+		verdict.Reasons = append(verdict.Reasons, ModerationReasonRepeat)
+	}
+
+	return verdict, nil
+}
+
+func (km *KeywordModerator) profane(text string) bool {
+	normalized := leetReplacer.Replace(strings.ToLower(text))
+	words := strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, word := range words {
+		word = squeeze(word)
+		if km.words[word] {
+			return true
+		}
+		for _, stem := range km.stems {
+			if strings.HasPrefix(word, stem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// repeated records the message and reports whether the sender has now sent
+// it repeatLimit times within repeatWindow
+func (km *KeywordModerator) repeated(senderID uuid.UUID, text string, now time.Time) bool {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	value, _ := km.recent.LoadOrStore(senderID, &recentMessages{})
+	recent := value.(*recentMessages)
+
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+
+	kept := recent.messages[:0]
+	for _, m := range recent.messages {
+		if now.Sub(m.at) < repeatWindow {
+			kept = append(kept, m)
+		}
+	}
+	recent.messages = append(kept, sentMessage{text: normalized, at: now})
+
+	count := 0
+	for _, m := range recent.messages {
+		if m.text == normalized {
+			count++
+		}
+	}
+	return count >= repeatLimit
+}
+
+// containsPhoneNumber looks for Mongolian numbers (eight digits starting 6-9,
+// optionally with the +976 prefix) and other international numbers. Chip
+// counts like "10 000 000" don't start with a mobile prefix, so pass.
+func containsPhoneNumber(text string) bool {
+	for _, match := range phonePattern.FindAllString(text, -1) {
+		digits := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, match)
+
+		if strings.HasPrefix(match, "+") && len(digits) >= 10 {
+			return true
+		}
+		digits = strings.TrimPrefix(digits, "976")
+		if len(digits) == mongolianPhoneDigits && strings.ContainsRune("6789", rune(digits[0])) {
+			return true
+		}
+	}
+	return false
+}
+
+// squeeze collapses runs of the same letter so "fuuuck" matches "fuck"
+func squeeze(word string) string {
+	var b strings.Builder
+	var last rune
+	for i, r := range word {
+		if i > 0 && r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+)
+
+const (
+	maxDirectMessageLength = 1000
+	defaultHistoryLimit    = 50
+	maxHistoryLimit        = 200
+)
+
+var (
+	ErrCannotMessageSelf  = errors.New("cannot send a direct message to yourself")
+	ErrRecipientNotFound  = errors.New("recipient not found")
+	ErrMessageBlocked     = errors.New("direct messages between these users are blocked")
+	ErrMessageThrottled   = errors.New("sending direct messages too quickly")
+	ErrEmptyMessage       = errors.New("message cannot be empty")
+	ErrMessageTooLong     = errors.New("message is too long")
+	ErrMessageNotFound    = errors.New("message not found")
+	ErrReportNotFound     = errors.New("message report not found")
+	ErrCannotBlockSelf    = errors.New("cannot block yourself")
+	ErrNotMessageReceiver = errors.New("only the recipient can report a message")
+)
+
+// DirectMessageService stores private messages between players and enforces
+// block lists and per-sender throttling
+type DirectMessageService struct {
+	db       *database.DB
+	limiters sync.Map // sender ID -> *rate.Limiter
+	every    time.Duration
+	burst    int
+}
+
+// NewDirectMessageService creates a direct message service allowing a short
+// burst of messages and then one per second per sender
+func NewDirectMessageService(db *database.DB) *DirectMessageService {
+	return &DirectMessageService{
+		db:    db,
+		every: time.Second,
+		burst: 5,
+	}
+}
+
+// SendMessage validates and stores a direct message
+func (dms *DirectMessageService) SendMessage(ctx context.Context, senderID, recipientID uuid.UUID, body string) (*models.DirectMessage, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyMessage
+	}
+	if len(body) > maxDirectMessageLength {
+		return nil, ErrMessageTooLong
+	}
+	if senderID == recipientID {
+		return nil, ErrCannotMessageSelf
+	}
+
+	if !dms.allow(senderID) {
+		return nil, ErrMessageThrottled
+	}
+
+	var recipientCount int64
+	if err := dms.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", recipientID).Count(&recipientCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up recipient: %w", err)
+	}
+	if recipientCount == 0 {
+		return nil, ErrRecipientNotFound
+	}
+
+	blocked, err := dms.IsBlockedEitherWay(ctx, senderID, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrMessageBlocked
+	}
+
+	message := &models.DirectMessage{
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Body:        body,
+	}
+	if err := dms.db.WithContext(ctx).Create(message).Error; err != nil {
+		return nil, fmt.Errorf("failed to store direct message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetConversation returns messages exchanged between two users, newest first.
+// Pass a non-zero before to page back through older messages.
+func (dms *DirectMessageService) GetConversation(ctx context.Context, userID, otherID uuid.UUID, limit int, before time.Time) ([]models.DirectMessage, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	query := dms.db.WithContext(ctx).
+		Where("(sender_id = ? AND recipient_id = ?) OR (sender_id = ? AND recipient_id = ?)", userID, otherID, otherID, userID)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+
+	var messages []models.DirectMessage
+	if err := query.Order("created_at DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return messages, nil
+}
+
+// ListConversations returns the latest message and unread count for each
+// player the user has exchanged messages with
+func (dms *DirectMessageService) ListConversations(ctx context.Context, userID uuid.UUID) ([]models.Conversation, error) {
+	var latest []models.DirectMessage
+	err := dms.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (partner_id) id, sender_id, recipient_id, body, read_at, created_at
+		FROM (
+			SELECT *, CASE WHEN sender_id = ? THEN recipient_id ELSE sender_id END AS partner_id
+			FROM direct_messages
+			WHERE (sender_id = ? OR recipient_id = ?) AND deleted_at IS NULL
+		) AS dm
+		ORDER BY partner_id, created_at DESC`, userID, userID, userID).Scan(&latest).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	conversations := make([]models.Conversation, 0, len(latest))
+	for _, message := range latest {
+		partnerID := message.SenderID
+		if partnerID == userID {
+			partnerID = message.RecipientID
+		}
+
+		var partner models.User
+		if err := dms.db.WithContext(ctx).Select("id", "username").First(&partner, "id = ?", partnerID).Error; err != nil {
+			slog.Warn("Skipping conversation with missing user", "user_id", userID, "partner_id", partnerID, "error", err)
+			continue
+		}
+
+		var unread int64
+		dms.db.WithContext(ctx).Model(&models.DirectMessage{}).
+			Where("sender_id = ? AND recipient_id = ? AND read_at IS NULL", partnerID, userID).
+			Count(&unread)
+
+		conversations = append(conversations, models.Conversation{
+			UserID:      partnerID,
+			Username:    partner.Username,
+			LastMessage: message,
+			UnreadCount: unread,
+		})
+	}
+
+	return conversations, nil
+}
+
+// MarkConversationRead marks every message from otherID to userID as read
+func (dms *DirectMessageService) MarkConversationRead(ctx context.Context, userID, otherID uuid.UUID) error {
+	err := dms.db.WithContext(ctx).Model(&models.DirectMessage{}).
+		Where("sender_id = ? AND recipient_id = ? AND read_at IS NULL", otherID, userID).
+		Update("read_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return nil
+}
+
+// BlockUser adds blockedID to the user's block list. Blocking is idempotent.
+func (dms *DirectMessageService) BlockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	if userID == blockedID {
+		return ErrCannotBlockSelf
+	}
+
+	block := models.UserBlock{BlockerID: userID, BlockedID: blockedID}
+	err := dms.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", userID, blockedID).
+		FirstOrCreate(&block).Error
+	if err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	slog.Info("User blocked", "user_id", userID, "blocked_id", blockedID)
+	return nil
+}
+
+// UnblockUser removes blockedID from the user's block list
+func (dms *DirectMessageService) UnblockUser(ctx context.Context, userID, blockedID uuid.UUID) error {
+	err := dms.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", userID, blockedID).
+		Delete(&models.UserBlock{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// ListBlocked returns the user's block list
+func (dms *DirectMessageService) ListBlocked(ctx context.Context, userID uuid.UUID) ([]models.UserBlock, error) {
+	var blocks []models.UserBlock
+	err := dms.db.WithContext(ctx).
+		Preload("Blocked", func(db *gorm.DB) *gorm.DB { return db.Select("id", "username") }).
+		Where("blocker_id = ?", userID).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	return blocks, nil
+}
+
+// IsBlockedEitherWay reports whether either user has blocked the other
+func (dms *DirectMessageService) IsBlockedEitherWay(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	var count int64
+	err := dms.db.WithContext(ctx).Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check block list: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ReportMessage flags a received message for moderator review
+func (dms *DirectMessageService) ReportMessage(ctx context.Context, reporterID uuid.UUID, req models.ReportMessageRequest) (*models.MessageReport, error) {
+	var message models.DirectMessage
+	if err := dms.db.WithContext(ctx).First(&message, "id = ?", req.MessageID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if message.RecipientID != reporterID {
+		return nil, ErrNotMessageReceiver
+	}
+
+	report := &models.MessageReport{
+		MessageID:  message.ID,
+		ReporterID: reporterID,
+		Reason:     req.Reason,
+		Status:     models.MessageReportOpen,
+	}
+	if err := dms.db.WithContext(ctx).Create(report).Error; err != nil {
+		return nil, fmt.Errorf("failed to report message: %w", err)
+	}
+
+	slog.Info("Direct message reported", "report_id", report.ID, "message_id", message.ID, "reporter_id", reporterID, "sender_id", message.SenderID)
+	return report, nil
+}
+
+// ListReports returns message reports with the given status, oldest first
+func (dms *DirectMessageService) ListReports(ctx context.Context, status string, limit, offset int) ([]models.MessageReport, int64, error) {
+	query := dms.db.WithContext(ctx).Model(&models.MessageReport{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count message reports: %w", err)
+	}
+
+	var reports []models.MessageReport
+	if err := query.Preload("Message").Order("created_at ASC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list message reports: %w", err)
+	}
+	return reports, total, nil
+}
+
+// ReviewReport closes a message report with the moderator's decision
+func (dms *DirectMessageService) ReviewReport(ctx context.Context, reportID, reviewerID uuid.UUID, status string) (*models.MessageReport, error) {
+	var report models.MessageReport
+	if err := dms.db.WithContext(ctx).First(&report, "id = ?", reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get message report: %w", err)
+	}
+
+	now := time.Now()
+	report.Status = status
+	report.ReviewedBy = &reviewerID
+	report.ReviewedAt = &now
+	if err := dms.db.WithContext(ctx).Save(&report).Error; err != nil {
+		return nil, fmt.Errorf("failed to review message report: %w", err)
+	}
+	return &report, nil
+}
+
+// allow applies the per-sender throttle
+func (dms *DirectMessageService) allow(senderID uuid.UUID) bool {
+	limiter, _ := dms.limiters.LoadOrStore(senderID, rate.NewLimiter(rate.Every(dms.every), dms.burst))
+	return limiter.(*rate.Limiter).Allow()
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"fmt"
+	"html"
 	"net/smtp"
 	"strings"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/config"
 )
@@ -73,8 +75,9 @@ func (es *EmailService) SendVerificationEmail(to, username, verificationToken st
 	return es.SendEmail(to, subject, body)
 }
 
-// SendPasswordResetEmail sends a password reset email
-func (es *EmailService) SendPasswordResetEmail(to, username, resetToken string) error {
+// SendPasswordResetEmail sends a password reset email with a link that works
+// for expiresIn
+func (es *EmailService) SendPasswordResetEmail(to, username, resetToken string, expiresIn time.Duration) error {
 	subject := "Reset your password - Poker Platform"
 
 	// In production, this should be your actual frontend URL
@@ -89,13 +92,94 @@ func (es *EmailService) SendPasswordResetEmail(to, username, resetToken string)
 			<p><a href="%s">Reset Password</a></p>
 			<p>If you cannot click the link, copy and paste this URL into your browser:</p>
 			<p>%s</p>
-			<p>This password reset link will expire in 1 hour.</p>
+			<p>This password reset link will expire in %s and can only be used once.</p>
 			<p>If you did not request a password reset, please ignore this email.</p>
 			<br>
 			<p>Best regards,<br>The Poker Platform Team</p>
 		</body>
 		</html>
-	`, strings.Title(username), resetURL, resetURL)
+	`, strings.Title(username), resetURL, resetURL, formatLinkLifetime(expiresIn))
+
+	return es.SendEmail(to, subject, body)
+}
+
+// formatLinkLifetime writes how long a link works, e.g. "1 hour" or
+// "30 minutes"
+func formatLinkLifetime(d time.Duration) string {
+	if d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	}
+	return fmt.Sprintf("%d minutes", d/time.Minute)
+}
+
+// SendPasswordChangedEmail tells a user their password was reset and that
+// they have been signed out everywhere
+func (es *EmailService) SendPasswordChangedEmail(to, username string, changedAt time.Time) error {
+	subject := "Your password was changed - Poker Platform"
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Your password was changed</h2>
+			<p>Hello %s,</p>
+			<p>The password for your account was reset on %s (UTC). You have been signed out on every device and need to sign in again with the new password.</p>
+			<p>If you did not reset your password, contact us straight away.</p>
+			<br>
+			<p>Best regards,<br>The Poker Platform Team</p>
+		</body>
+		</html>
+	`, html.EscapeString(username), changedAt.UTC().Format("2006-01-02 15:04"))
+
+	return es.SendEmail(to, subject, body)
+}
+
+// SendImpersonationNoticeEmail tells a user that support staff viewed their
+// account
+func (es *EmailService) SendImpersonationNoticeEmail(to, username string, startedAt, endedAt time.Time, reason string, requests int64) error {
+	subject := "Support viewed your account - Poker Platform"
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Support viewed your account</h2>
+			<p>Hello %s,</p>
+			<p>With the support access you granted, a member of our support team viewed your account from %s to %s (UTC).</p>
+			<p>Reason given: %s</p>
+			<p>They could only look: %d requests were recorded, and no changes, bets or money movements can be made this way.</p>
+			<p>If you did not expect this, revoke support access from your account settings and contact us.</p>
+			<br>
+			<p>Best regards,<br>The Poker Platform Team</p>
+		</body>
+		</html>
+	`, html.EscapeString(username), startedAt.UTC().Format("2006-01-02 15:04"), endedAt.UTC().Format("2006-01-02 15:04"), html.EscapeString(reason), requests)
+
+	return es.SendEmail(to, subject, body)
+}
+
+// SendMaintenanceNoticeEmail warns a tournament registrant about scheduled
+// maintenance that stops play
+func (es *EmailService) SendMaintenanceNoticeEmail(to, username, title, message string, startsAt, endsAt time.Time) error {
+	subject := "Scheduled maintenance - Poker Platform"
+
+	details := ""
+	if message != "" {
+		details = fmt.Sprintf("<p>%s</p>", html.EscapeString(message))
+	}
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>%s</h2>
+			<p>Hello %s,</p>
+			<p>Play is paused for scheduled maintenance from %s to %s (UTC). You are registered for a tournament that may be affected: tables stop dealing new hands while maintenance is under way and carry on once it ends.</p>
+			%s
+			<br>
+			<p>Best regards,<br>The Poker Platform Team</p>
+		</body>
+		</html>
+	`, html.EscapeString(title), html.EscapeString(username), startsAt.UTC().Format("2006-01-02 15:04"), endsAt.UTC().Format("2006-01-02 15:04"), details)
 
 	return es.SendEmail(to, subject, body)
 }
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/parity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrParityMismatch        = errors.New("the engine does not play this table's game like the legacy game")
+	ErrEngineUnsupportedGame = errors.New("the engine does not deal this table's game")
+)
+
+// EngineMigrationService moves tables between the legacy game and the
+// internal engine. A table only goes onto the engine once the engine plays
+// the parity suite at the table's game and stakes exactly as the legacy game.
+type EngineMigrationService struct {
+	db *database.DB
+}
+
+// NewEngineMigrationService creates a new engine migration service
+func NewEngineMigrationService(db *database.DB) *EngineMigrationService {
+	return &EngineMigrationService{db: db}
+}
+
+// CheckParity runs the parity suite at the table's game and stakes. It
+// returns ErrParityMismatch, with the reports, if any script played out
+// differently.
+func (ems *EngineMigrationService) CheckParity(ctx context.Context, tableID uuid.UUID) (*models.PokerTable, []parity.Report, error) {
+	table, err := ems.getTable(ctx, tableID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reports, err := checkParity(table)
+	return table, reports, err
+}
+
+// SetExecutionPath moves the table to the legacy game or the engine. Moving
+// to the engine runs the parity suite first and is refused on any
+// difference; moving back to the legacy game always succeeds. The table
+// switches the next time it opens.
+func (ems *EngineMigrationService) SetExecutionPath(ctx context.Context, tableID uuid.UUID, path string) (*models.PokerTable, []parity.Report, error) {
+	table, err := ems.getTable(ctx, tableID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reports []parity.Report
+	if path == models.ExecutionPathEngine {
+		if reports, err = checkParity(table); err != nil {
+			return table, reports, err
+		}
+	}
+
+	if err := ems.db.WithContext(ctx).Model(table).Update("execution_path", path).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update table execution path: %w", err)
+	}
+
+	slog.Info("Table execution path changed", "table_id", table.ID, "table", table.Name, "execution_path", path)
+	return table, reports, nil
+}
+
+func (ems *EngineMigrationService) getTable(ctx context.Context, tableID uuid.UUID) (*models.PokerTable, error) {
+	var table models.PokerTable
+	if err := ems.db.WithContext(ctx).First(&table, "id = ?", tableID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to get table: %w", err)
+	}
+	return &table, nil
+}
+
+func checkParity(table *models.PokerTable) ([]parity.Report, error) {
+	if _, err := game.ParseVariant(table.GameType); err != nil {
+		return nil, ErrEngineUnsupportedGame
+	}
+
+	var reports []parity.Report
+	agree := true
+	for _, script := range parity.Suite(table.GameType, table.SmallBlind, table.BigBlind) {
+		report, err := parity.Run(script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run parity script %q: %w", script.Name, err)
+		}
+		agree = agree && report.Agree()
+		reports = append(reports, report)
+	}
+
+	if !agree {
+		return reports, ErrParityMismatch
+	}
+	return reports, nil
+}
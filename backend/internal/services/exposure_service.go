@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrExposureOverrideNotFound = errors.New("exposure override not found")
+
+// ExposureRules cap the MNT a user may have in game sessions at once, so a
+// single user can't lock their whole bankroll into simultaneous games. Zero
+// is no limit.
+type ExposureRules struct {
+	// Indexed by KYC tier. Tiers past the end use the last limit.
+	ByKYCTier []int64
+	// Replace the tier's limit for users with these roles
+	ByRole map[string]int64
+}
+
+// Limit returns the maximum exposure for a user and where it comes from
+func (r ExposureRules) Limit(role models.UserRole, kycTier int) (int64, string) {
+	if limit, ok := r.ByRole[string(role)]; ok {
+		return limit, models.ExposureLimitRole
+	}
+	if len(r.ByKYCTier) == 0 {
+		return 0, models.ExposureLimitKYCTier
+	}
+	if kycTier < 0 {
+		kycTier = 0
+	}
+	if kycTier >= len(r.ByKYCTier) {
+		kycTier = len(r.ByKYCTier) - 1
+	}
+	return r.ByKYCTier[kycTier], models.ExposureLimitKYCTier
+}
+
+// ExposureLimitError refuses a buy-in that would take a user's exposure over
+// their maximum
+type ExposureLimitError struct {
+	MaxExposure int64
+	Exposure    int64
+	BuyIn       int64
+}
+
+func (e *ExposureLimitError) Error() string {
+	if available := e.Available(); available > 0 {
+		return fmt.Sprintf("You already have %d MNT in play and can have at most %d MNT across all tables. The most you can buy in for now is %d MNT.", e.Exposure, e.MaxExposure, available)
+	}
+	return fmt.Sprintf("You already have %d MNT in play, the most you can have across all tables. Leave a table before buying in again.", e.Exposure)
+}
+
+// Available is how much more the user may buy in for
+func (e *ExposureLimitError) Available() int64 {
+	return max(e.MaxExposure-e.Exposure, 0)
+}
+
+// EvaluateExposure checks a buy-in against a maximum exposure, 0 for none
+func EvaluateExposure(maxExposure, exposure, buyIn int64) error {
+	if maxExposure <= 0 || exposure+buyIn <= maxExposure {
+		return nil
+	}
+	return &ExposureLimitError{MaxExposure: maxExposure, Exposure: exposure, BuyIn: buyIn}
+}
+
+// ExposureService enforces the maximum exposure at buy-in and manages the
+// admin overrides that change it for a user
+type ExposureService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	rules           ExposureRules
+}
+
+func NewExposureService(db *database.DB, formanceService *formance.Service, rules ExposureRules) *ExposureService {
+	return &ExposureService{db: db, formanceService: formanceService, rules: rules}
+}
+
+// Check returns an *ExposureLimitError if buying in for buyIn would take the
+// user over their maximum exposure
+func (es *ExposureService) Check(ctx context.Context, userID uuid.UUID, buyIn int64) error {
+	summary, err := es.Summary(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := EvaluateExposure(summary.MaxExposure, summary.Exposure, buyIn); err != nil {
+		slog.Info("Buy-in refused over maximum exposure", "user_id", userID, "buy_in", buyIn, "exposure", summary.Exposure, "max_exposure", summary.MaxExposure, "source", summary.Source)
+		return err
+	}
+	return nil
+}
+
+// Summary reports the MNT the user has in active game sessions and their
+// maximum exposure
+func (es *ExposureService) Summary(ctx context.Context, userID uuid.UUID) (*models.ExposureSummary, error) {
+	var user models.User
+	if err := es.db.WithContext(ctx).Select("id", "role", "kyc_tier").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	summary := &models.ExposureSummary{}
+	summary.MaxExposure, summary.Source = es.rules.Limit(user.Role, user.KYCTier)
+
+	override, err := es.activeOverride(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		summary.MaxExposure = override.MaxExposure
+		summary.Source = models.ExposureLimitOverride
+		summary.OverrideUntil = override.ExpiresAt
+	}
+
+	var sessions int64
+	if err := es.db.WithContext(ctx).Model(&models.GameSession{}).
+		Where("user_id = ? AND status = ?", userID, models.GameSessionStatusActive).
+		Count(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count active sessions: %w", err)
+	}
+	summary.ActiveSessions = int(sessions)
+
+	if sessions > 0 {
+		summary.Exposure, err = es.formanceService.GetTotalSessionBalances(ctx, userID, es.db.DB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session balances: %w", err)
+		}
+	}
+	return summary, nil
+}
+
+// GrantOverride replaces a user's maximum exposure, for a number of hours or
+// until revoked. A newer override takes precedence over older ones.
+func (es *ExposureService) GrantOverride(ctx context.Context, userID, adminID uuid.UUID, req models.GrantExposureOverrideRequest) (*models.ExposureOverride, error) {
+	if _, err := userKYCTier(ctx, es.db, userID); err != nil {
+		return nil, err
+	}
+
+	override := &models.ExposureOverride{
+		UserID:      userID,
+		MaxExposure: req.MaxExposure,
+		Reason:      req.Reason,
+		GrantedBy:   adminID,
+	}
+	if req.Hours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.Hours) * time.Hour)
+		override.ExpiresAt = &expiresAt
+	}
+	if err := es.db.WithContext(ctx).Create(override).Error; err != nil {
+		return nil, fmt.Errorf("failed to grant exposure override: %w", err)
+	}
+
+	slog.Info("Exposure override granted", "user_id", userID, "max_exposure", req.MaxExposure, "expires_at", override.ExpiresAt, "admin_id", adminID, "reason", req.Reason)
+	return override, nil
+}
+
+// RevokeOverride ends an override before it expires
+func (es *ExposureService) RevokeOverride(ctx context.Context, overrideID, adminID uuid.UUID) error {
+	result := es.db.WithContext(ctx).Model(&models.ExposureOverride{}).
+		Where("id = ? AND revoked_at IS NULL", overrideID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke exposure override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExposureOverrideNotFound
+	}
+
+	slog.Info("Exposure override revoked", "override_id", overrideID, "admin_id", adminID)
+	return nil
+}
+
+// ListOverrides returns a user's overrides, newest first
+func (es *ExposureService) ListOverrides(ctx context.Context, userID uuid.UUID) ([]models.ExposureOverride, error) {
+	var overrides []models.ExposureOverride
+	if err := es.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to list exposure overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+func (es *ExposureService) activeOverride(ctx context.Context, userID uuid.UUID) (*models.ExposureOverride, error) {
+	var override models.ExposureOverride
+	err := es.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at DESC").
+		First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check exposure override: %w", err)
+	}
+	return &override, nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/fairness"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TableFairness is the analysed fairness report of one table and day
+type TableFairness struct {
+	TableName string    `json:"table_name"`
+	Day       time.Time `json:"day"`
+	fairness.Report
+}
+
+// FairnessService builds daily RNG fairness reports from recorded hands
+type FairnessService struct {
+	db *database.DB
+}
+
+// NewFairnessService creates a new fairness service
+func NewFairnessService(db *database.DB) *FairnessService {
+	return &FairnessService{db: db}
+}
+
+// GenerateDailyReports tallies every hand that ended on the UTC day containing
+// day and stores one report per table. Rerunning a day replaces its reports.
+func (fs *FairnessService) GenerateDailyReports(ctx context.Context, day time.Time) error {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.AddDate(0, 0, 1)
+
+	tallies := make(map[string]*fairness.Tally)
+	var hands []models.HandHistory
+	err := fs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Select("id", "table_name", "hole_cards", "board").
+		// The expected rates are for Hold'em's 52 cards and two hole cards
+		Where("ended_at >= ? AND ended_at < ? AND hole_cards IS NOT NULL", start, end).
+		Where("variant IS NULL OR variant IN ?", []string{"", "texas_holdem"}).
+		FindInBatches(&hands, 500, func(tx *gorm.DB, batch int) error {
+			for _, hand := range hands {
+				holes, board, err := decodeDealtCards(hand)
+				if err != nil {
+					slog.Warn("Skipping hand with unreadable cards", "table", hand.TableName, "error", err)
+					continue
+				}
+				tally, ok := tallies[hand.TableName]
+				if !ok {
+					tally = &fairness.Tally{}
+					tallies[hand.TableName] = tally
+				}
+				tally.AddHand(holes, board)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to read dealt cards: %w", err)
+	}
+
+	for tableName, tally := range tallies {
+		tallyJSON, err := json.Marshal(tally)
+		if err != nil {
+			return fmt.Errorf("failed to marshal fairness tally: %w", err)
+		}
+
+		report := models.FairnessReport{
+			TableName: tableName,
+			Day:       start,
+			Hands:     tally.Hands,
+			Flagged:   fairness.Analyze(*tally).Flagged,
+			Tally:     tallyJSON,
+		}
+		err = fs.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "table_name"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{"hands", "flagged", "tally", "updated_at"}),
+		}).Create(&report).Error
+		if err != nil {
+			return fmt.Errorf("failed to save fairness report: %w", err)
+		}
+
+		if report.Flagged {
+			slog.Warn("Card distribution outside expected range", "table", tableName, "day", start.Format("2006-01-02"), "hands", tally.Hands)
+		}
+	}
+
+	slog.Info("Fairness reports generated", "day", start.Format("2006-01-02"), "tables", len(tallies))
+	return nil
+}
+
+// ListTableReports returns the analysed reports of every table for a UTC day
+func (fs *FairnessService) ListTableReports(ctx context.Context, day time.Time) ([]TableFairness, error) {
+	var reports []models.FairnessReport
+	err := fs.db.WithContext(ctx).
+		Where("day = ?", day.UTC().Truncate(24*time.Hour)).
+		Order("flagged DESC, table_name").
+		Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fairness reports: %w", err)
+	}
+
+	result := make([]TableFairness, 0, len(reports))
+	for _, report := range reports {
+		var tally fairness.Tally
+		if err := json.Unmarshal(report.Tally, &tally); err != nil {
+			return nil, fmt.Errorf("failed to decode fairness tally: %w", err)
+		}
+		result = append(result, TableFairness{
+			TableName: report.TableName,
+			Day:       report.Day,
+			Report:    fairness.Analyze(tally),
+		})
+	}
+	return result, nil
+}
+
+// PlatformReport merges every table's tallies for the UTC days in [from, to)
+func (fs *FairnessService) PlatformReport(ctx context.Context, from, to time.Time) (*fairness.Report, error) {
+	var tallies []json.RawMessage
+	err := fs.db.WithContext(ctx).Model(&models.FairnessReport{}).
+		Where("day >= ? AND day < ?", from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)).
+		Pluck("tally", &tallies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fairness tallies: %w", err)
+	}
+
+	var total fairness.Tally
+	for _, raw := range tallies {
+		var tally fairness.Tally
+		if err := json.Unmarshal(raw, &tally); err != nil {
+			return nil, fmt.Errorf("failed to decode fairness tally: %w", err)
+		}
+		total.Merge(tally)
+	}
+
+	report := fairness.Analyze(total)
+	return &report, nil
+}
+
+func decodeDealtCards(hand models.HandHistory) ([][2]eval.Card, []eval.Card, error) {
+	var players []models.HandPlayerCards
+	if err := json.Unmarshal(hand.HoleCards, &players); err != nil {
+		return nil, nil, err
+	}
+	var boardStrings []string
+	if len(hand.Board) > 0 {
+		if err := json.Unmarshal(hand.Board, &boardStrings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	holes := make([][2]eval.Card, len(players))
+	for i, player := range players {
+		if len(player.Cards) != 2 {
+			return nil, nil, fmt.Errorf("expected 2 hole cards, got %d", len(player.Cards))
+		}
+		for j, s := range player.Cards {
+			card, err := eval.ParseCardBytes([]byte(s))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid hole card %q: %w", s, err)
+			}
+			holes[i][j] = card
+		}
+	}
+
+	board := make([]eval.Card, len(boardStrings))
+	for i, s := range boardStrings {
+		card, err := eval.ParseCardBytes([]byte(s))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid board card %q: %w", s, err)
+		}
+		board[i] = card
+	}
+	return holes, board, nil
+}
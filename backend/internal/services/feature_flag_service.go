@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownFeatureFlag is returned for a flag the server does not check
+var ErrUnknownFeatureFlag = errors.New("unknown feature flag")
+
+// featureFlagCacheTTL bounds how long another instance keeps serving a flag
+// after an admin switches it
+const featureFlagCacheTTL = 10 * time.Second
+
+// FeatureFlagService stores the operator switches checked by handlers. Reads
+// are cached briefly since they sit on hot paths like buy-ins.
+type FeatureFlagService struct {
+	db       *database.DB
+	mu       sync.Mutex
+	disabled map[string]bool
+	loadedAt time.Time
+}
+
+func NewFeatureFlagService(db *database.DB) *FeatureFlagService {
+	return &FeatureFlagService{db: db}
+}
+
+// Enabled reports whether a feature is switched on. If the flags can't be
+// read the feature stays on, so a database hiccup doesn't take it down.
+func (s *FeatureFlagService) Enabled(ctx context.Context, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled == nil || time.Since(s.loadedAt) > featureFlagCacheTTL {
+		var flags []models.FeatureFlag
+		if err := s.db.WithContext(ctx).Where("enabled = ?", false).Find(&flags).Error; err != nil {
+			slog.Warn("Failed to load feature flags", "error", err)
+			return !s.disabled[key]
+		}
+		s.disabled = make(map[string]bool, len(flags))
+		for _, flag := range flags {
+			s.disabled[flag.Key] = true
+		}
+		s.loadedAt = time.Now()
+	}
+	return !s.disabled[key]
+}
+
+// List returns every known flag, including those never switched
+func (s *FeatureFlagService) List(ctx context.Context) ([]models.FeatureFlag, error) {
+	var stored []models.FeatureFlag
+	if err := s.db.WithContext(ctx).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+
+	byKey := make(map[string]models.FeatureFlag, len(stored))
+	for _, flag := range stored {
+		byKey[flag.Key] = flag
+	}
+	flags := make([]models.FeatureFlag, 0, len(models.FeatureFlags))
+	for _, key := range models.FeatureFlags {
+		flag, ok := byKey[key]
+		if !ok {
+			flag = models.FeatureFlag{Key: key, Enabled: true}
+		}
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+// Set switches a flag on or off. The change applies on this instance
+// immediately and on the others within featureFlagCacheTTL.
+func (s *FeatureFlagService) Set(ctx context.Context, key string, enabled bool, adminID uuid.UUID, reason string) (*models.FeatureFlag, error) {
+	if !slices.Contains(models.FeatureFlags, key) {
+		return nil, ErrUnknownFeatureFlag
+	}
+
+	flag := models.FeatureFlag{
+		Key:       key,
+		Enabled:   enabled,
+		Reason:    reason,
+		UpdatedBy: &adminID,
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "reason", "updated_by", "updated_at"}),
+	}).Create(&flag).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save feature flag: %w", err)
+	}
+
+	s.mu.Lock()
+	s.disabled = nil
+	s.mu.Unlock()
+
+	slog.Warn("Feature flag changed", "flag", key, "enabled", enabled, "admin_id", adminID, "reason", reason)
+	return &flag, nil
+}
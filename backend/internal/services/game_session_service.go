@@ -173,3 +173,45 @@ func (gs *GameSessionService) IsRealMoneySession(ctx context.Context, sessionID
 
 	return count > 0, nil
 }
+
+// RecordPartialCashOut stores the audit record of a mid-session withdrawal
+func (gs *GameSessionService) RecordPartialCashOut(ctx context.Context, record *models.PartialCashOut) error {
+	if err := gs.db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to record partial cash-out: %w", err)
+	}
+	return nil
+}
+
+// ListPartialCashOuts returns partial cash-out records, newest first,
+// optionally limited to one user
+func (gs *GameSessionService) ListPartialCashOuts(ctx context.Context, userID *uuid.UUID, limit, offset int) ([]models.PartialCashOut, int64, error) {
+	query := gs.db.WithContext(ctx).Model(&models.PartialCashOut{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count partial cash-outs: %w", err)
+	}
+
+	var records []models.PartialCashOut
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&records).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list partial cash-outs: %w", err)
+	}
+	return records, total, nil
+}
+
+// SetPartialCashOutPolicy allows or forbids partial cash-outs at a table
+func (gs *GameSessionService) SetPartialCashOutPolicy(ctx context.Context, tableID uuid.UUID, allow bool) error {
+	result := gs.db.WithContext(ctx).Model(&models.PokerTable{}).
+		Where("id = ?", tableID).
+		Update("allow_partial_cash_out", allow)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update partial cash-out policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTableNotFound
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoHandInProgress = errors.New("no hand is in progress")
+	ErrHandNotFrozen    = errors.New("the hand must be frozen before it is adjudicated")
+	ErrInvalidRuling    = errors.New("the ruling does not fit the hand")
+	ErrRulingNotAllowed = errors.New("hands at this table cannot be adjudicated")
+)
+
+// HandAdjudicationService keeps the audit log of rulings on disputed hands
+// and notes each ruling in the hand's history
+type HandAdjudicationService struct {
+	db *database.DB
+}
+
+func NewHandAdjudicationService(db *database.DB) *HandAdjudicationService {
+	return &HandAdjudicationService{db: db}
+}
+
+// Record stores a ruling in the audit log and on the hand history row, if
+// the hand has one
+func (hs *HandAdjudicationService) Record(ctx context.Context, adjudication *models.HandAdjudication) error {
+	return hs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(adjudication).Error; err != nil {
+			return fmt.Errorf("failed to record adjudication: %w", err)
+		}
+
+		ruling, err := json.Marshal(models.HandRuling{
+			Outcome: adjudication.Outcome,
+			Reason:  adjudication.Reason,
+			RuledAt: adjudication.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal ruling: %w", err)
+		}
+		if err := tx.Model(&models.HandHistory{}).Where("hand_id = ?", adjudication.HandID).Update("ruling", ruling).Error; err != nil {
+			return fmt.Errorf("failed to record ruling in hand history: %w", err)
+		}
+		return nil
+	})
+}
+
+// List returns the most recent rulings, optionally only those at one table
+func (hs *HandAdjudicationService) List(ctx context.Context, tableName string, limit int) ([]models.HandAdjudication, error) {
+	query := hs.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if tableName != "" {
+		query = query.Where("table_name = ?", tableName)
+	}
+
+	var adjudications []models.HandAdjudication
+	if err := query.Find(&adjudications).Error; err != nil {
+		return nil, fmt.Errorf("failed to list adjudications: %w", err)
+	}
+	return adjudications, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+// handEquitySamples is how many runouts are dealt for a street whose board
+// can be completed in more ways than this, such as preflop
+const handEquitySamples = 20000
+
+// handEquityStreets names the streets by how many board cards they show
+var handEquityStreets = []struct {
+	name  string
+	board int
+}{
+	{"preflop", 0},
+	{"flop", 3},
+	{"turn", 4},
+	{"river", 5},
+}
+
+// ensureEquities works out the hand's win-probability timeline the first
+// time it is asked for and stores it, so each hand is only computed once. A
+// failure to store it is logged; the timeline is still served.
+func (hs *HandHistoryService) ensureEquities(ctx context.Context, history *models.HandHistory) {
+	if len(history.Equities) > 0 {
+		return
+	}
+
+	raw, err := json.Marshal(HandEquities(*history))
+	if err != nil {
+		slog.Warn("Failed to encode hand equities", "hand_id", history.HandID, "error", err)
+		return
+	}
+	history.Equities = raw
+
+	err = hs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Where("hand_id = ?", history.HandID).
+		Update("equities", raw).Error
+	if err != nil {
+		slog.Warn("Failed to store hand equities", "hand_id", history.HandID, "error", err)
+	}
+}
+
+// HandEquities works out each street's equities for the players whose hands
+// were shown down. Hands won without a showdown, or whose cards can't be
+// read, have no timeline.
+func HandEquities(history models.HandHistory) []models.HandEquityStreet {
+	streets := []models.HandEquityStreet{}
+
+	var board []string
+	if len(history.Board) > 0 && json.Unmarshal(history.Board, &board) != nil {
+		return streets
+	}
+	dealt, ok := parseCards(board)
+	if !ok {
+		return streets
+	}
+
+	var seats []uint
+	var holes [][]eval.Card
+	for _, p := range history.DealtPlayers() {
+		if !p.Shown {
+			continue
+		}
+		hole, ok := parseCards(p.Cards)
+		if !ok || len(hole) < 2 {
+			return streets
+		}
+		seats = append(seats, p.SeatID)
+		holes = append(holes, hole)
+	}
+	if len(holes) < 2 {
+		return streets
+	}
+
+	variant := poker.Variant(history.Variant)
+	if variant == "" {
+		variant = poker.VariantHoldem
+	}
+
+	// Seeded by the hand so the timeline is the same however often it is
+	// worked out
+	seed := fnv.New64a()
+	seed.Write([]byte(history.HandID))
+	rng := rand.New(rand.NewSource(int64(seed.Sum64())))
+	for _, street := range handEquityStreets {
+		if street.board > len(dealt) {
+			break
+		}
+		equities := poker.ShowdownEquities(variant, holes, dealt[:street.board], handEquitySamples, rng)
+		seatEquities := make([]models.HandSeatEquity, len(seats))
+		for i, seat := range seats {
+			seatEquities[i] = models.HandSeatEquity{SeatID: seat, Equity: equities[i]}
+		}
+		streets = append(streets, models.HandEquityStreet{
+			Street:   street.name,
+			Board:    append([]string{}, board[:street.board]...),
+			Equities: seatEquities,
+		})
+	}
+	return streets
+}
+
+// parseCards reads cards written like "As"
+func parseCards(written []string) ([]eval.Card, bool) {
+	cards := make([]eval.Card, len(written))
+	for i, w := range written {
+		c, err := eval.ParseCardBytes([]byte(w))
+		if err != nil {
+			return nil, false
+		}
+		cards[i] = c
+	}
+	return cards, true
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrHandNotFound = errors.New("hand not found")
+
+// handExportLimit is the most hands a single export returns
+const handExportLimit = 5000
+
+// HandHistoryPolicy is the room's policy on what hand histories keep and
+// show. Players' own privacy settings can only add to it.
+type HandHistoryPolicy struct {
+	StoreMucked      bool // Keep hole cards of players who did not show down
+	RevealMucked     bool // Show those cards to the other players
+	AnonymizeExports bool // Replace opponents' names with seats in exports
+}
+
+// DefaultHandHistoryPolicy keeps mucked cards for their owners only and
+// anonymizes exports
+func DefaultHandHistoryPolicy() HandHistoryPolicy {
+	return HandHistoryPolicy{StoreMucked: true, AnonymizeExports: true}
+}
+
+// HandHistoryService persists hand summaries keyed by their correlation hand ID
+type HandHistoryService struct {
+	db     *database.DB
+	policy HandHistoryPolicy
+}
+
+// NewHandHistoryService creates a new hand history service
+func NewHandHistoryService(db *database.DB, policy HandHistoryPolicy) *HandHistoryService {
+	return &HandHistoryService{db: db, policy: policy}
+}
+
+// RecordHandStart stores a new hand history row when a hand is dealt
+func (hs *HandHistoryService) RecordHandStart(ctx context.Context, handID string, tableID uuid.UUID, tableName string, sequence int64, variant string) error {
+	history := &models.HandHistory{
+		HandID:    handID,
+		TableID:   tableID,
+		TableName: tableName,
+		Sequence:  sequence,
+		Variant:   variant,
+		StartedAt: time.Now(),
+	}
+
+	if err := hs.db.WithContext(ctx).Create(history).Error; err != nil {
+		return fmt.Errorf("failed to record hand start: %w", err)
+	}
+	return nil
+}
+
+// RecordHandEnd completes the hand history row with the pot total, the rake
+// taken from it and the winners
+func (hs *HandHistoryService) RecordHandEnd(ctx context.Context, handID string, totalPot, rake int64, winners []models.HandWinner) error {
+	winnersJSON, err := json.Marshal(winners)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hand winners: %w", err)
+	}
+
+	now := time.Now()
+	err = hs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Where("hand_id = ?", handID).
+		Updates(map[string]interface{}{
+			"ended_at":  &now,
+			"total_pot": totalPot,
+			"rake":      rake,
+			"winners":   winnersJSON,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record hand end: %w", err)
+	}
+	return nil
+}
+
+// RecordDealtCards stores the hole cards of every dealt-in player and the
+// board as far as it was dealt, e.g. ["2C","9D","JH"]. Cards that were not
+// shown down are dropped when the room or their owner doesn't keep them.
+func (hs *HandHistoryService) RecordDealtCards(ctx context.Context, handID string, players []models.HandPlayerCards, board []string) error {
+	userIDs := make([]uuid.UUID, len(players))
+	for i, p := range players {
+		userIDs[i] = p.UserID
+	}
+	privacy, err := hs.privacySettings(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range players {
+		if !players[i].Shown && (!hs.policy.StoreMucked || !privacy[players[i].UserID].StoreMucked) {
+			players[i].Cards = nil
+		}
+	}
+
+	playersJSON, err := json.Marshal(players)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hole cards: %w", err)
+	}
+	boardJSON, err := json.Marshal(board)
+	if err != nil {
+		return fmt.Errorf("failed to marshal board: %w", err)
+	}
+
+	err = hs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Where("hand_id = ?", handID).
+		Updates(map[string]interface{}{
+			"hole_cards": playersJSON,
+			"board":      boardJSON,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record dealt cards: %w", err)
+	}
+	return nil
+}
+
+// RecordPresence notes that users were at the table during a hand, so they
+// can read its history later. Repeated calls for the same user are ignored.
+func (hs *HandHistoryService) RecordPresence(ctx context.Context, handID string, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	presence := make([]models.HandPresence, len(userIDs))
+	for i, userID := range userIDs {
+		presence[i] = models.HandPresence{HandID: handID, UserID: userID}
+	}
+
+	err := hs.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&presence).Error
+	if err != nil {
+		return fmt.Errorf("failed to record hand presence: %w", err)
+	}
+	return nil
+}
+
+// ListForViewer returns the finished hands the user was present for, newest
+// first, as that user may see them
+func (hs *HandHistoryService) ListForViewer(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.HandHistoryView, int64, error) {
+	query := hs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Joins("JOIN hand_presences ON hand_presences.hand_id = hand_histories.hand_id").
+		Where("hand_presences.user_id = ? AND hand_histories.ended_at IS NOT NULL", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count hand histories: %w", err)
+	}
+
+	var histories []models.HandHistory
+	if err := query.Order("hand_histories.started_at DESC").Limit(limit).Offset(offset).Find(&histories).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list hand histories: %w", err)
+	}
+
+	views, err := hs.viewsFor(ctx, userID, histories, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	return views, total, nil
+}
+
+// GetForViewer returns one finished hand as the user may see it, with its
+// win-probability timeline. Hands the user was not present for are reported
+// as ErrHandNotFound.
+func (hs *HandHistoryService) GetForViewer(ctx context.Context, handID string, userID uuid.UUID) (*models.HandHistoryView, error) {
+	var history models.HandHistory
+	err := hs.db.WithContext(ctx).
+		Joins("JOIN hand_presences ON hand_presences.hand_id = hand_histories.hand_id").
+		Where("hand_histories.hand_id = ? AND hand_presences.user_id = ? AND hand_histories.ended_at IS NOT NULL", handID, userID).
+		First(&history).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHandNotFound
+		}
+		return nil, fmt.Errorf("failed to get hand history: %w", err)
+	}
+	hs.ensureEquities(ctx, &history)
+
+	views, err := hs.viewsFor(ctx, userID, []models.HandHistory{history}, false)
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// ExportForViewer returns the finished hands the user was present for that
+// started in [from, to), oldest first. Opponents are anonymized when the
+// room's policy says so, on top of their own privacy settings.
+func (hs *HandHistoryService) ExportForViewer(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.HandHistoryView, bool, error) {
+	var histories []models.HandHistory
+	err := hs.db.WithContext(ctx).
+		Joins("JOIN hand_presences ON hand_presences.hand_id = hand_histories.hand_id").
+		Where("hand_presences.user_id = ? AND hand_histories.ended_at IS NOT NULL", userID).
+		Where("hand_histories.started_at >= ? AND hand_histories.started_at < ?", from, to).
+		Order("hand_histories.started_at ASC").
+		Limit(handExportLimit).
+		Find(&histories).Error
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to export hand histories: %w", err)
+	}
+
+	views, err := hs.viewsFor(ctx, userID, histories, hs.policy.AnonymizeExports)
+	if err != nil {
+		return nil, false, err
+	}
+	return views, hs.policy.AnonymizeExports, nil
+}
+
+// GetPrivacy returns the user's hand history privacy settings
+func (hs *HandHistoryService) GetPrivacy(ctx context.Context, userID uuid.UUID) (*models.HandPrivacySettings, error) {
+	settings, err := hs.privacySettings(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	s := settings[userID]
+	return &s, nil
+}
+
+// UpdatePrivacy changes the privacy settings that are set in the request.
+// Hands already recorded keep the cards stored at the time.
+func (hs *HandHistoryService) UpdatePrivacy(ctx context.Context, userID uuid.UUID, req models.UpdateHandPrivacyRequest) (*models.HandPrivacySettings, error) {
+	settings, err := hs.GetPrivacy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.StoreMucked != nil {
+		settings.StoreMucked = *req.StoreMucked
+	}
+	if req.Visibility != nil {
+		settings.Visibility = *req.Visibility
+	}
+
+	if err := hs.db.WithContext(ctx).Save(settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to update hand privacy settings: %w", err)
+	}
+	return settings, nil
+}
+
+// viewsFor builds each hand as the viewer may see it. Opponents who keep
+// their hands from the viewer, or every opponent when anonymizeAll is set,
+// appear by seat only.
+func (hs *HandHistoryService) viewsFor(ctx context.Context, viewerID uuid.UUID, histories []models.HandHistory, anonymizeAll bool) ([]models.HandHistoryView, error) {
+	dealt := make([][]models.HandPlayerCards, len(histories))
+	var userIDs []uuid.UUID
+	for i, history := range histories {
+		dealt[i] = history.DealtPlayers()
+		for _, p := range dealt[i] {
+			userIDs = append(userIDs, p.UserID)
+		}
+	}
+	privacy, err := hs.privacySettings(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]models.HandHistoryView, len(histories))
+	for i, history := range histories {
+		viewerDealtIn := false
+		for _, p := range dealt[i] {
+			if p.UserID == viewerID {
+				viewerDealtIn = true
+			}
+		}
+
+		policy := models.HandViewPolicy{
+			RevealMucked: hs.policy.RevealMucked,
+			Anonymous:    make(map[uuid.UUID]bool),
+		}
+		for _, p := range dealt[i] {
+			if p.UserID != viewerID && (anonymizeAll || !privacy[p.UserID].VisibleTo(viewerDealtIn)) {
+				policy.Anonymous[p.UserID] = true
+			}
+		}
+		views[i] = history.ViewWith(viewerID, policy)
+	}
+	return views, nil
+}
+
+// privacySettings returns the hand privacy settings of each user, with
+// defaults for users who never changed them
+func (hs *HandHistoryService) privacySettings(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]models.HandPrivacySettings, error) {
+	settings := make(map[uuid.UUID]models.HandPrivacySettings, len(userIDs))
+	for _, userID := range userIDs {
+		settings[userID] = models.DefaultHandPrivacySettings(userID)
+	}
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+
+	var stored []models.HandPrivacySettings
+	if err := hs.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to get hand privacy settings: %w", err)
+	}
+	for _, s := range stored {
+		settings[s.UserID] = s
+	}
+	return settings, nil
+}
+
+// GetByHandID retrieves a hand history row by its correlation ID
+func (hs *HandHistoryService) GetByHandID(ctx context.Context, handID string) (*models.HandHistory, error) {
+	var history models.HandHistory
+	if err := hs.db.WithContext(ctx).Where("hand_id = ?", handID).First(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get hand history: %w", err)
+	}
+	return &history, nil
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrIdentityRejected is returned when a provider refuses an authorization
+// code, or answers with an identity that isn't for us
+var ErrIdentityRejected = errors.New("identity provider rejected the sign-in")
+
+// ExternalIdentity is who a provider says signed in
+type ExternalIdentity struct {
+	Provider      models.IdentityProvider
+	Subject       string // The provider's ID for the account, stable across email changes
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// IdentityProvider signs players in through the OAuth2 authorization code
+// flow
+type IdentityProvider interface {
+	// AuthCodeURL is where to send the player to sign in. The provider sends
+	// them back with a code and the same state. codeChallenge is the PKCE
+	// S256 challenge of the verifier Exchange is later given.
+	AuthCodeURL(state, codeChallenge string) string
+	// Exchange trades an authorization code for the player's identity
+	Exchange(ctx context.Context, code, codeVerifier string) (*ExternalIdentity, error)
+}
+
+// PKCEChallenge is the S256 code challenge of a PKCE code verifier (RFC 7636)
+func PKCEChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OAuthEndpoint is a provider's authorization and token URLs
+type OAuthEndpoint struct {
+	AuthURL  string
+	TokenURL string
+	Issuers  []string // Accepted "iss" of its ID tokens
+}
+
+var (
+	GoogleEndpoint = OAuthEndpoint{
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		Issuers:  []string{"https://accounts.google.com", "accounts.google.com"},
+	}
+	AppleEndpoint = OAuthEndpoint{
+		AuthURL:  "https://appleid.apple.com/auth/authorize",
+		TokenURL: "https://appleid.apple.com/auth/token",
+		Issuers:  []string{"https://appleid.apple.com"},
+	}
+)
+
+// GoogleProvider signs players in with their Google account
+type GoogleProvider struct {
+	httpClient   *http.Client
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	endpoint     OAuthEndpoint
+}
+
+func NewGoogleProvider(clientID, clientSecret, redirectURL string, endpoint OAuthEndpoint) *GoogleProvider {
+	return &GoogleProvider{
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		endpoint:     endpoint,
+	}
+}
+
+func (p *GoogleProvider) AuthCodeURL(state, codeChallenge string) string {
+	return p.endpoint.AuthURL + "?" + url.Values{
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"response_type":         {"code"},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"prompt":                {"select_account"},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+}
+
+func (p *GoogleProvider) Exchange(ctx context.Context, code, codeVerifier string) (*ExternalIdentity, error) {
+	idToken, err := exchangeCode(ctx, p.httpClient, p.endpoint.TokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"redirect_uri":  {p.redirectURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return identityFromIDToken(models.IdentityProviderGoogle, idToken, p.endpoint.Issuers, p.clientID)
+}
+
+// AppleProvider signs players in with their Apple ID. Apple posts its
+// response to the redirect URL rather than redirecting to it.
+type AppleProvider struct {
+	httpClient  *http.Client
+	clientID    string
+	teamID      string
+	keyID       string
+	privateKey  *ecdsa.PrivateKey
+	redirectURL string
+	endpoint    OAuthEndpoint
+}
+
+func NewAppleProvider(clientID, teamID, keyID, privateKeyPEM, redirectURL string, endpoint OAuthEndpoint) (*AppleProvider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple private key: %w", err)
+	}
+
+	return &AppleProvider{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		clientID:    clientID,
+		teamID:      teamID,
+		keyID:       keyID,
+		privateKey:  key,
+		redirectURL: redirectURL,
+		endpoint:    endpoint,
+	}, nil
+}
+
+func (p *AppleProvider) AuthCodeURL(state, codeChallenge string) string {
+	return p.endpoint.AuthURL + "?" + url.Values{
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"response_type":         {"code"},
+		"response_mode":         {"form_post"}, // Required when asking for the email
+		"scope":                 {"name email"},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+}
+
+func (p *AppleProvider) Exchange(ctx context.Context, code, codeVerifier string) (*ExternalIdentity, error) {
+	secret, err := p.clientSecret()
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := exchangeCode(ctx, p.httpClient, p.endpoint.TokenURL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.clientID},
+		"client_secret": {secret},
+		"redirect_uri":  {p.redirectURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return identityFromIDToken(models.IdentityProviderApple, idToken, p.endpoint.Issuers, p.clientID)
+}
+
+// clientSecret signs the token Apple takes in place of a client secret
+func (p *AppleProvider) clientSecret() (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+		"aud": "https://appleid.apple.com",
+		"sub": p.clientID,
+	})
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Apple client secret: %w", err)
+	}
+	return signed, nil
+}
+
+// exchangeCode posts an authorization code to a token endpoint and returns
+// the ID token it answers with
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		// invalid_grant and the like: the code was used, expired or forged
+		return "", fmt.Errorf("%w: %s", ErrIdentityRejected, string(body))
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("token endpoint HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if result.IDToken == "" {
+		return "", fmt.Errorf("%w: no ID token in the response", ErrIdentityRejected)
+	}
+	return result.IDToken, nil
+}
+
+// idTokenClaims are the OpenID Connect claims read from an ID token. Apple
+// sends email_verified as a string.
+type idTokenClaims struct {
+	Email         string          `json:"email"`
+	EmailVerified json.RawMessage `json:"email_verified"`
+	Name          string          `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// identityFromIDToken reads the identity from an ID token. The token came
+// straight from the provider's token endpoint over TLS, so its signature is
+// not checked (OpenID Connect Core 3.1.3.7); its issuer, audience and expiry
+// still are.
+func identityFromIDToken(provider models.IdentityProvider, idToken string, issuers []string, clientID string) (*ExternalIdentity, error) {
+	var claims idTokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed ID token: %v", ErrIdentityRejected, err)
+	}
+	if !slices.Contains(issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: ID token issued by %q", ErrIdentityRejected, claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, clientID) {
+		return nil, fmt.Errorf("%w: ID token is for another client", ErrIdentityRejected)
+	}
+	if claims.ExpiresAt == nil || time.Now().After(claims.ExpiresAt.Time) {
+		return nil, fmt.Errorf("%w: ID token expired", ErrIdentityRejected)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: ID token has no subject", ErrIdentityRejected)
+	}
+
+	verified := strings.Trim(string(claims.EmailVerified), `"`) == "true"
+	return &ExternalIdentity{
+		Provider:      provider,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: verified && claims.Email != "",
+		Name:          claims.Name,
+	}, nil
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("sign-in provider is not available")
+	ErrInvalidSignupToken      = errors.New("sign-up has expired, sign in with the provider again")
+	ErrIdentityLinked          = errors.New("this sign-in is already linked to an account")
+	ErrEmailTaken              = errors.New("an account with this email already exists, log in with its password")
+	ErrInvalidOAuthState       = errors.New("sign-in has expired or was not started here, try again")
+)
+
+// oauthStateTTL is how long a player has to sign in with the provider once
+// they have been sent there
+const oauthStateTTL = 10 * time.Minute
+
+// IdentityService signs players in with external identity providers. A
+// provider account is linked to ours the first time it is used: to the
+// account with the same email when the provider has verified it, otherwise
+// to a new account once the player has picked a username. Signing in issues
+// the same JWT as a password login.
+type IdentityService struct {
+	db          *database.DB
+	authService *AuthService
+	jwtManager  *auth.JWTManager
+	providers   map[models.IdentityProvider]IdentityProvider
+	now         func() time.Time
+}
+
+// NewIdentityService creates an identity service with the providers
+// configured in cfg. Providers without credentials are skipped.
+func NewIdentityService(db *database.DB, authService *AuthService, jwtManager *auth.JWTManager, cfg *config.Config) *IdentityService {
+	providers := make(map[models.IdentityProvider]IdentityProvider)
+
+	if cfg.GoogleClientID != "" {
+		providers[models.IdentityProviderGoogle] = NewGoogleProvider(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.OAuthCallbackURL, GoogleEndpoint)
+	}
+
+	if cfg.AppleClientID != "" {
+		apple, err := NewAppleProvider(cfg.AppleClientID, cfg.AppleTeamID, cfg.AppleKeyID, cfg.ApplePrivateKey, cfg.AppleRedirectURL, AppleEndpoint)
+		if err != nil {
+			slog.Warn("Sign in with Apple disabled", "error", err)
+		} else {
+			providers[models.IdentityProviderApple] = apple
+		}
+	}
+
+	return NewIdentityServiceWithProviders(db, authService, jwtManager, providers)
+}
+
+// NewIdentityServiceWithProviders creates an identity service with explicit
+// providers
+func NewIdentityServiceWithProviders(db *database.DB, authService *AuthService, jwtManager *auth.JWTManager, providers map[models.IdentityProvider]IdentityProvider) *IdentityService {
+	return &IdentityService{
+		db:          db,
+		authService: authService,
+		jwtManager:  jwtManager,
+		providers:   providers,
+		now:         time.Now,
+	}
+}
+
+// Providers lists the providers players can sign in with
+func (s *IdentityService) Providers() []models.IdentityProvider {
+	providers := make([]models.IdentityProvider, 0, len(s.providers))
+	for name := range s.providers {
+		providers = append(providers, name)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+// Start returns where to send the player to sign in with a provider. The
+// state and the PKCE code verifier are kept until the player comes back.
+func (s *IdentityService) Start(ctx context.Context, name models.IdentityProvider) (*models.OAuthStartResponse, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownIdentityProvider
+	}
+	state, err := auth.GenerateToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := auth.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	// Expired sign-ins are cleared as new ones start
+	if err := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.OAuthState{}).Error; err != nil {
+		slog.Warn("Failed to clear expired sign-in states", "error", err)
+	}
+	pending := models.OAuthState{
+		StateHash:    hashOAuthState(state),
+		Provider:     name,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oauthStateTTL),
+	}
+	if err := s.db.WithContext(ctx).Create(&pending).Error; err != nil {
+		return nil, fmt.Errorf("failed to save sign-in state: %w", err)
+	}
+	return &models.OAuthStartResponse{URL: provider.AuthCodeURL(state, PKCEChallenge(verifier)), State: state}, nil
+}
+
+// SignIn completes a sign-in with the code and state a provider sent the
+// player back with. The state must be one Start issued for the provider; it
+// is used up either way. It returns a login for a linked or matching
+// account, or a signup for the player to finish by picking a username.
+func (s *IdentityService) SignIn(ctx context.Context, name models.IdentityProvider, code, state string) (*models.LoginResponse, *models.OAuthSignup, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, nil, ErrUnknownIdentityProvider
+	}
+	verifier, err := s.takeState(ctx, name, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.linkedUser(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		signup, err := s.signup(ctx, identity)
+		return nil, signup, err
+	}
+
+	login, err := s.login(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return login, nil, nil
+}
+
+// takeState uses up a state from Start and returns its code verifier
+func (s *IdentityService) takeState(ctx context.Context, name models.IdentityProvider, state string) (string, error) {
+	var pending models.OAuthState
+	result := s.db.WithContext(ctx).Clauses(clause.Returning{}).
+		Where("state_hash = ? AND provider = ? AND expires_at > ?", hashOAuthState(state), name, s.now()).
+		Delete(&pending)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to check sign-in state: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrInvalidOAuthState
+	}
+	return pending.CodeVerifier, nil
+}
+
+// hashOAuthState is the hex SHA-256 of a state, as stored in OAuthState
+func hashOAuthState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// linkedUser finds the account a provider identity signs in to, linking it
+// to the account with the same verified email the first time. It returns
+// nil when there is no such account.
+func (s *IdentityService) linkedUser(ctx context.Context, identity *ExternalIdentity) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
+	var link models.UserIdentity
+	err := db.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, "id = ?", link.UserID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get linked user: %w", err)
+		}
+		if err := db.Model(&link).Update("last_used_at", s.now()).Error; err != nil {
+			slog.Warn("Failed to record identity use", "user_id", user.ID, "provider", identity.Provider, "error", err)
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up identity: %w", err)
+	}
+
+	// An unverified email could belong to anyone, so it never links
+	if !identity.EmailVerified {
+		return nil, nil
+	}
+	var user models.User
+	err = db.Where("LOWER(email) = ?", identity.Email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user by email: %w", err)
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+
+	if err := s.link(db, user.ID, identity); err != nil {
+		return nil, err
+	}
+	slog.Info("Identity linked to existing account", "user_id", user.ID, "provider", identity.Provider)
+	return &user, nil
+}
+
+// link records that a provider identity signs in to an account
+func (s *IdentityService) link(db *gorm.DB, userID uuid.UUID, identity *ExternalIdentity) error {
+	link := models.UserIdentity{
+		UserID:     userID,
+		Provider:   identity.Provider,
+		Subject:    identity.Subject,
+		Email:      identity.Email,
+		LastUsedAt: s.now(),
+	}
+	if err := db.Create(&link).Error; err != nil {
+		if database.IsUniqueConstraintError(err) {
+			return ErrIdentityLinked
+		}
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// CompleteSignup creates the account for a signup from SignIn with the
+// username the player picked, and logs them in
+func (s *IdentityService) CompleteSignup(ctx context.Context, req models.CompleteOAuthSignupRequest) (*models.LoginResponse, error) {
+	claims, err := s.jwtManager.ValidateOAuthSignupToken(req.SignupToken)
+	if err != nil {
+		return nil, ErrInvalidSignupToken
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: no email address was shared", ErrIdentityRejected)
+	}
+	identity := &ExternalIdentity{
+		Provider:      models.IdentityProvider(claims.Provider),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+	}
+
+	password, err := auth.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	// The account signs in through the provider; nobody knows this password
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := models.User{
+		Email:        identity.Email,
+		Username:     req.Username,
+		PasswordHash: hash,
+		Role:         models.UserRolePlayer,
+		IsVerified:   identity.EmailVerified,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.User
+		err := tx.Where("LOWER(email) = ? OR username = ?", identity.Email, req.Username).First(&existing).Error
+		if err == nil {
+			if strings.EqualFold(existing.Email, identity.Email) {
+				return ErrEmailTaken
+			}
+			return ErrUsernameTaken
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check existing user: %w", err)
+		}
+		if reserved, err := usernameReserved(tx, req.Username, s.now()); err != nil {
+			return err
+		} else if reserved {
+			return ErrUsernameReserved
+		}
+
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return s.link(tx, user.ID, identity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.authService.setUpAccount(&user)
+	slog.Info("User registered with identity provider", "user_id", user.ID, "username", user.Username, "provider", identity.Provider)
+	return s.login(&user)
+}
+
+// suggestUsername offers a free username made from the email's local part,
+// or "" when there isn't one
+func (s *IdentityService) suggestUsername(ctx context.Context, identity *ExternalIdentity) string {
+	local, _, _ := strings.Cut(identity.Email, "@")
+	var b strings.Builder
+	for _, r := range local {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case r == '.' || r == '-' || r == '+':
+			b.WriteRune('_')
+		}
+	}
+	base := b.String()
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		return ""
+	}
+
+	db := s.db.WithContext(ctx)
+	for i := 0; i < 5; i++ {
+		candidate := base
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", base, i+1)
+		}
+		var taken int64
+		if err := db.Model(&models.User{}).Where("LOWER(username) = LOWER(?)", candidate).Count(&taken).Error; err != nil {
+			return ""
+		}
+		if taken > 0 {
+			continue
+		}
+		if reserved, err := usernameReserved(db, candidate, s.now()); err != nil || reserved {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+// signup hands the player a token for creating their account
+func (s *IdentityService) signup(ctx context.Context, identity *ExternalIdentity) (*models.OAuthSignup, error) {
+	token, expiresAt, err := s.jwtManager.GenerateOAuthSignupToken(string(identity.Provider), identity.Subject, identity.Email, identity.EmailVerified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue signup token: %w", err)
+	}
+	return &models.OAuthSignup{
+		Provider:          identity.Provider,
+		Email:             identity.Email,
+		SuggestedUsername: s.suggestUsername(ctx, identity),
+		SignupToken:       token,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// login issues the token a password login would
+func (s *IdentityService) login(user *models.User) (*models.LoginResponse, error) {
+	if user.DisabledAt != nil {
+		return nil, ErrAccountDisabled
+	}
+	token, expiry, err := s.authService.StartSession(user)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("User logged in with identity provider", "user_id", user.ID, "username", user.Username)
+	return &models.LoginResponse{User: *user, Token: token, SessionExpiry: expiry}, nil
+}
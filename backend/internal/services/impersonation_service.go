@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultImpersonationDuration is used when the admin doesn't ask for a length
+const defaultImpersonationDuration = 15 * time.Minute
+
+var (
+	ErrNoSupportAccess              = errors.New("user has not granted support access")
+	ErrCannotImpersonate            = errors.New("only player accounts can be impersonated")
+	ErrImpersonationSessionNotFound = errors.New("impersonation session not found")
+)
+
+// ImpersonationService lets admins view the API as a user sees it, with the
+// user's consent, read-only and with every request recorded
+type ImpersonationService struct {
+	db           *database.DB
+	jwtManager   *auth.JWTManager
+	emailService *EmailService
+}
+
+// NewImpersonationService creates a new impersonation service. The email
+// service tells users about sessions once they end and may be nil.
+func NewImpersonationService(db *database.DB, jwtManager *auth.JWTManager, emailService *EmailService) *ImpersonationService {
+	return &ImpersonationService{
+		db:           db,
+		jwtManager:   jwtManager,
+		emailService: emailService,
+	}
+}
+
+// GrantSupportAccess lets support staff impersonate the user for a while
+func (is *ImpersonationService) GrantSupportAccess(ctx context.Context, userID uuid.UUID, duration time.Duration) (*models.SupportAccessGrant, error) {
+	grant := models.SupportAccessGrant{
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(duration),
+	}
+	if err := is.db.WithContext(ctx).Create(&grant).Error; err != nil {
+		return nil, fmt.Errorf("failed to grant support access: %w", err)
+	}
+
+	slog.Info("Support access granted", "user_id", userID, "expires_at", grant.ExpiresAt)
+	return &grant, nil
+}
+
+// RevokeSupportAccess withdraws the user's consent and ends any session
+// still open under it
+func (is *ImpersonationService) RevokeSupportAccess(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	err := is.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SupportAccessGrant{}).
+			Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+			Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke support access: %w", err)
+		}
+		if err := tx.Model(&models.ImpersonationSession{}).
+			Where("user_id = ? AND ended_at IS NULL AND expires_at > ?", userID, now).
+			Update("ended_at", now).Error; err != nil {
+			return fmt.Errorf("failed to end impersonation sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Support access revoked", "user_id", userID)
+	return nil
+}
+
+// SupportAccess returns the user's current grant, nil if none, and the
+// impersonation sessions held on their account, newest first
+func (is *ImpersonationService) SupportAccess(ctx context.Context, userID uuid.UUID) (*models.SupportAccessGrant, []models.ImpersonationSession, error) {
+	grant, err := activeSupportGrant(is.db.WithContext(ctx), userID, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions, err := is.ListSessions(ctx, &userID, 50)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grant, sessions, nil
+}
+
+// Start opens an impersonation session and issues its token. The session
+// never outlasts the user's grant.
+func (is *ImpersonationService) Start(ctx context.Context, adminID, userID uuid.UUID, reason string, duration time.Duration) (*models.ImpersonationTokenResponse, error) {
+	if duration <= 0 {
+		duration = defaultImpersonationDuration
+	}
+	if duration > auth.MaxImpersonationDuration {
+		duration = auth.MaxImpersonationDuration
+	}
+	if adminID == userID {
+		return nil, ErrCannotImpersonate
+	}
+
+	db := is.db.WithContext(ctx)
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if user.Role != models.UserRolePlayer {
+		return nil, ErrCannotImpersonate
+	}
+
+	now := time.Now()
+	grant, err := activeSupportGrant(db, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil {
+		return nil, ErrNoSupportAccess
+	}
+
+	session := models.ImpersonationSession{
+		AdminID:   adminID,
+		UserID:    userID,
+		Reason:    reason,
+		ExpiresAt: now.Add(duration),
+	}
+	if grant.ExpiresAt.Before(session.ExpiresAt) {
+		session.ExpiresAt = grant.ExpiresAt
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	token, err := is.jwtManager.GenerateImpersonationToken(user.ID, user.Username, user.Email, adminID, session.ID, session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+
+	slog.Warn("Impersonation session started",
+		"session_id", session.ID,
+		"admin_id", adminID,
+		"user_id", userID,
+		"expires_at", session.ExpiresAt,
+		"reason", reason)
+
+	return &models.ImpersonationTokenResponse{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		Session:   session,
+	}, nil
+}
+
+// End closes a session before it expires
+func (is *ImpersonationService) End(ctx context.Context, sessionID, adminID uuid.UUID) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := is.db.WithContext(ctx).First(&session, "id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImpersonationSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to load impersonation session: %w", err)
+	}
+
+	now := time.Now()
+	if session.Active(now) {
+		if err := is.db.WithContext(ctx).Model(&session).Update("ended_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to end impersonation session: %w", err)
+		}
+		session.EndedAt = &now
+		slog.Info("Impersonation session ended", "session_id", sessionID, "ended_by", adminID)
+	}
+	return &session, nil
+}
+
+// ImpersonationActive reports whether requests may still be made under the
+// session
+func (is *ImpersonationService) ImpersonationActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	var session models.ImpersonationSession
+	if err := is.db.WithContext(ctx).First(&session, "id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load impersonation session: %w", err)
+	}
+	return session.Active(time.Now()), nil
+}
+
+// RecordImpersonatedRequest adds a request to the session's audit trail.
+// Failures are logged with the full request so nothing goes unrecorded.
+func (is *ImpersonationService) RecordImpersonatedRequest(ctx context.Context, sessionID uuid.UUID, method, path string, status int, blocked bool) {
+	action := models.ImpersonationAction{
+		SessionID: sessionID,
+		Method:    method,
+		Path:      path,
+		Status:    status,
+		Blocked:   blocked,
+	}
+	if err := is.db.WithContext(context.WithoutCancel(ctx)).Create(&action).Error; err != nil {
+		slog.Error("Failed to record impersonated request",
+			"session_id", sessionID,
+			"method", method,
+			"path", path,
+			"status", status,
+			"blocked", blocked,
+			"error", err)
+	}
+}
+
+// ListSessions returns impersonation sessions, newest first, optionally for
+// one user only
+func (is *ImpersonationService) ListSessions(ctx context.Context, userID *uuid.UUID, limit int) ([]models.ImpersonationSession, error) {
+	query := is.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := query.Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list impersonation sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Actions returns the audit trail of a session in the order made
+func (is *ImpersonationService) Actions(ctx context.Context, sessionID uuid.UUID) ([]models.ImpersonationAction, error) {
+	var actions []models.ImpersonationAction
+	if err := is.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get impersonation actions: %w", err)
+	}
+	return actions, nil
+}
+
+// NotifyEndedSessions emails users about impersonation sessions that ended
+// or expired since the last run. Sessions whose email fails are retried on
+// the next run.
+func (is *ImpersonationService) NotifyEndedSessions(ctx context.Context, now time.Time) error {
+	if is.emailService == nil {
+		return nil
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := is.db.WithContext(ctx).
+		Where("notified_at IS NULL AND (ended_at IS NOT NULL OR expires_at <= ?)", now).
+		Find(&sessions).Error; err != nil {
+		return fmt.Errorf("failed to find ended impersonation sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		var user models.User
+		if err := is.db.WithContext(ctx).First(&user, "id = ?", session.UserID).Error; err != nil {
+			slog.Warn("Skipping impersonation notice, failed to load user", "session_id", session.ID, "error", err)
+			continue
+		}
+
+		var requests int64
+		is.db.WithContext(ctx).Model(&models.ImpersonationAction{}).Where("session_id = ?", session.ID).Count(&requests)
+
+		endedAt := session.ExpiresAt
+		if session.EndedAt != nil && session.EndedAt.Before(endedAt) {
+			endedAt = *session.EndedAt
+		}
+		if err := is.emailService.SendImpersonationNoticeEmail(user.Email, user.Username, session.CreatedAt, endedAt, session.Reason, requests); err != nil {
+			slog.Warn("Failed to send impersonation notice", "session_id", session.ID, "user_id", user.ID, "error", err)
+			continue
+		}
+
+		if err := is.db.WithContext(ctx).Model(&session).Update("notified_at", now).Error; err != nil {
+			return fmt.Errorf("failed to mark impersonation session notified: %w", err)
+		}
+	}
+	return nil
+}
+
+func activeSupportGrant(db *gorm.DB, userID uuid.UUID, now time.Time) (*models.SupportAccessGrant, error) {
+	var grant models.SupportAccessGrant
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("expires_at DESC").
+		First(&grant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check support access: %w", err)
+	}
+	return &grant, nil
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrLastLongerNotFound      = errors.New("last-longer pool not found")
+	ErrLastLongerClosed        = errors.New("last-longer pools only take entries before the tournament starts")
+	ErrLastLongerNotRegistered = errors.New("register for the tournament before joining its last-longer pools")
+	ErrAlreadyInLastLonger     = errors.New("already in this last-longer pool")
+	ErrNotInLastLonger         = errors.New("not in this last-longer pool")
+	ErrLastLongerStake         = errors.New("last-longer stake failed")
+)
+
+// LastLongerService runs last-longer pools: entrants of a tournament stake
+// into a pool before it starts, and the pool is paid to whichever of them
+// lasts longest once the others have busted
+type LastLongerService struct {
+	db              *database.DB
+	formanceService *formance.Service
+}
+
+// NewLastLongerService creates a new last-longer service
+func NewLastLongerService(db *database.DB, formanceService *formance.Service) *LastLongerService {
+	return &LastLongerService{db: db, formanceService: formanceService}
+}
+
+// List returns a tournament's last-longer pools with their entrants, newest
+// first
+func (ls *LastLongerService) List(ctx context.Context, tournamentID uuid.UUID) ([]models.LastLongerPool, error) {
+	var pools []models.LastLongerPool
+	err := ls.db.WithContext(ctx).
+		Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("tournament_id = ?", tournamentID).
+		Order("created_at DESC").
+		Find(&pools).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list last-longer pools: %w", err)
+	}
+	if err := ls.describe(ctx, tournamentID, pools); err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
+// Create opens a last-longer pool and stakes the creator into it
+func (ls *LastLongerService) Create(ctx context.Context, tournamentID, userID uuid.UUID, req models.CreateLastLongerPoolRequest) (*models.LastLongerPool, error) {
+	pool := models.LastLongerPool{
+		TournamentID: tournamentID,
+		Name:         req.Name,
+		Stake:        req.Stake,
+		Status:       models.LastLongerOpen,
+		CreatedBy:    userID,
+	}
+
+	var transactionID string
+	err := ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkLastLongerEntrant(tx, tournamentID, userID); err != nil {
+			return err
+		}
+		if err := tx.Create(&pool).Error; err != nil {
+			return fmt.Errorf("failed to create last-longer pool: %w", err)
+		}
+
+		var err error
+		transactionID, err = ls.stake(ctx, tx, &pool, userID)
+		return err
+	})
+	if err != nil {
+		if transactionID != "" {
+			ls.refund(ctx, userID, pool)
+		}
+		return nil, err
+	}
+
+	slog.Info("Last-longer pool created", "pool_id", pool.ID, "tournament_id", tournamentID, "user_id", userID, "stake", pool.Stake)
+	return ls.get(ctx, tournamentID, pool.ID)
+}
+
+// Join stakes a registered player into an open pool
+func (ls *LastLongerService) Join(ctx context.Context, tournamentID, poolID, userID uuid.UUID) (*models.LastLongerPool, error) {
+	var pool models.LastLongerPool
+	var transactionID string
+	err := ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockLastLongerPool(tx, tournamentID, poolID, &pool); err != nil {
+			return err
+		}
+		if pool.Status != models.LastLongerOpen {
+			return ErrLastLongerClosed
+		}
+		if err := checkLastLongerEntrant(tx, tournamentID, userID); err != nil {
+			return err
+		}
+
+		var entries int64
+		if err := tx.Model(&models.LastLongerEntry{}).Where("pool_id = ? AND user_id = ?", poolID, userID).Count(&entries).Error; err != nil {
+			return fmt.Errorf("failed to check last-longer entry: %w", err)
+		}
+		if entries > 0 {
+			return ErrAlreadyInLastLonger
+		}
+
+		var err error
+		transactionID, err = ls.stake(ctx, tx, &pool, userID)
+		return err
+	})
+	if err != nil {
+		if transactionID != "" {
+			ls.refund(ctx, userID, pool)
+		}
+		return nil, err
+	}
+
+	slog.Info("Player joined last-longer pool", "pool_id", poolID, "user_id", userID)
+	return ls.get(ctx, tournamentID, poolID)
+}
+
+// Leave refunds a player's stake in a pool before the tournament starts. A
+// pool its last entrant leaves is cancelled.
+func (ls *LastLongerService) Leave(ctx context.Context, tournamentID, poolID, userID uuid.UUID) error {
+	return ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pool models.LastLongerPool
+		if err := lockLastLongerPool(tx, tournamentID, poolID, &pool); err != nil {
+			return err
+		}
+		if pool.Status != models.LastLongerOpen {
+			return ErrLastLongerClosed
+		}
+		var tournament models.Tournament
+		if err := tx.Select("status").First(&tournament, "id = ?", tournamentID).Error; err != nil {
+			return fmt.Errorf("failed to load tournament: %w", err)
+		}
+		if tournament.Status != "registering" {
+			return ErrLastLongerClosed
+		}
+		return ls.withdraw(ctx, tx, pool, userID)
+	})
+}
+
+// LeaveTournament refunds a player's stakes in every open pool of a
+// tournament they unregistered from
+func (ls *LastLongerService) LeaveTournament(ctx context.Context, tournamentID, userID uuid.UUID) error {
+	var poolIDs []uuid.UUID
+	err := ls.db.WithContext(ctx).Model(&models.LastLongerEntry{}).
+		Joins("JOIN last_longer_pools ON last_longer_pools.id = last_longer_entries.pool_id").
+		Where("last_longer_pools.tournament_id = ? AND last_longer_pools.status = ? AND last_longer_entries.user_id = ?", tournamentID, models.LastLongerOpen, userID).
+		Pluck("last_longer_entries.pool_id", &poolIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to find last-longer entries: %w", err)
+	}
+
+	for _, poolID := range poolIDs {
+		err := ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var pool models.LastLongerPool
+			if err := lockLastLongerPool(tx, tournamentID, poolID, &pool); err != nil {
+				return err
+			}
+			if pool.Status != models.LastLongerOpen {
+				return nil
+			}
+			return ls.withdraw(ctx, tx, pool, userID)
+		})
+		if err != nil && !errors.Is(err, ErrNotInLastLonger) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Settle pays every open pool of a tournament whose winner is known: all
+// but one entrant has busted, or the tournament is over. A finished
+// tournament's pool with entrants left unplaced can't be decided, so their
+// stakes are refunded. It returns the pools settled or cancelled.
+func (ls *LastLongerService) Settle(ctx context.Context, tournamentID uuid.UUID) ([]models.LastLongerPool, error) {
+	var tournament models.Tournament
+	if err := ls.db.WithContext(ctx).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to load tournament: %w", err)
+	}
+	if tournament.Status == "registering" {
+		return nil, nil
+	}
+	finished := tournament.Status == "finished"
+
+	var poolIDs []uuid.UUID
+	err := ls.db.WithContext(ctx).Model(&models.LastLongerPool{}).
+		Where("tournament_id = ? AND status = ?", tournamentID, models.LastLongerOpen).
+		Pluck("id", &poolIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open last-longer pools: %w", err)
+	}
+
+	var settled []models.LastLongerPool
+	for _, poolID := range poolIDs {
+		pool, err := ls.settle(ctx, tournamentID, poolID, finished)
+		if err != nil {
+			slog.Error("Failed to settle last-longer pool", "pool_id", poolID, "tournament_id", tournamentID, "error", err)
+			continue
+		}
+		if pool != nil {
+			settled = append(settled, *pool)
+		}
+	}
+	return settled, nil
+}
+
+// settle pays or cancels one pool if it can be decided, and returns nil if
+// it can't be yet
+func (ls *LastLongerService) settle(ctx context.Context, tournamentID, poolID uuid.UUID, finished bool) (*models.LastLongerPool, error) {
+	var pool models.LastLongerPool
+	var cancel bool
+	err := ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockLastLongerPool(tx, tournamentID, poolID, &pool); err != nil {
+			return err
+		}
+		if pool.Status != models.LastLongerOpen {
+			return nil
+		}
+		if err := tx.Where("pool_id = ?", poolID).Order("created_at").Find(&pool.Entries).Error; err != nil {
+			return fmt.Errorf("failed to load last-longer entries: %w", err)
+		}
+		if err := lastLongerPositions(tx, tournamentID, pool.Entries); err != nil {
+			return err
+		}
+
+		winner, decided := LastLongerWinner(pool.Entries)
+		if !decided {
+			cancel = finished
+			return nil
+		}
+
+		pool.Payout = pool.Stake * int64(len(pool.Entries))
+		transactionID, err := ls.formanceService.PayLastLonger(ctx, winner, pool.ID, pool.Payout)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		pool.Status = models.LastLongerSettled
+		pool.WinnerID = &winner
+		pool.SettleTransactionID = &transactionID
+		pool.SettledAt = &now
+		return tx.Model(&pool).Updates(map[string]interface{}{
+			"status":                pool.Status,
+			"winner_id":             pool.WinnerID,
+			"payout":                pool.Payout,
+			"settle_transaction_id": pool.SettleTransactionID,
+			"settled_at":            pool.SettledAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cancel {
+		// Refunded one entrant at a time, so a failure leaves only the
+		// unrefunded entries for the next attempt
+		for _, entry := range pool.Entries {
+			err := ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				var locked models.LastLongerPool
+				if err := lockLastLongerPool(tx, tournamentID, poolID, &locked); err != nil {
+					return err
+				}
+				return ls.withdraw(ctx, tx, locked, entry.UserID)
+			})
+			if err != nil && !errors.Is(err, ErrNotInLastLonger) {
+				return nil, err
+			}
+		}
+		slog.Info("Last-longer pool cancelled", "pool_id", poolID, "tournament_id", tournamentID, "entrants", len(pool.Entries))
+		return ls.get(ctx, tournamentID, poolID)
+	}
+	if pool.SettledAt == nil {
+		return nil, nil
+	}
+
+	slog.Info("Last-longer pool settled", "pool_id", poolID, "tournament_id", tournamentID, "winner_id", *pool.WinnerID, "payout", pool.Payout)
+	return ls.get(ctx, tournamentID, poolID)
+}
+
+// LastLongerWinner picks the winner of a pool from its entrants' finishing
+// positions: the last entrant still in once the others have busted, or the
+// best placed when none are left. It reports false while two or more are
+// still unplaced.
+func LastLongerWinner(entries []models.LastLongerEntry) (uuid.UUID, bool) {
+	var alive []uuid.UUID
+	var best *models.LastLongerEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.FinalPosition == nil {
+			alive = append(alive, entry.UserID)
+			continue
+		}
+		if best == nil || *entry.FinalPosition < *best.FinalPosition {
+			best = entry
+		}
+	}
+
+	switch {
+	case len(alive) == 1:
+		return alive[0], true
+	case len(alive) == 0 && best != nil:
+		return best.UserID, true
+	default:
+		return uuid.Nil, false
+	}
+}
+
+// stake takes a player's stake into the pool's escrow and records their
+// entry. It returns the stake's transaction ID even when recording the entry
+// fails, so the caller can refund it.
+func (ls *LastLongerService) stake(ctx context.Context, tx *gorm.DB, pool *models.LastLongerPool, userID uuid.UUID) (string, error) {
+	transactionID, err := ls.formanceService.StakeLastLonger(ctx, userID, pool.ID, pool.TournamentID, pool.Stake)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrLastLongerStake, err)
+	}
+
+	entry := models.LastLongerEntry{PoolID: pool.ID, UserID: userID, StakeTransactionID: transactionID}
+	if err := tx.Create(&entry).Error; err != nil {
+		return transactionID, fmt.Errorf("failed to record last-longer entry: %w", err)
+	}
+	return transactionID, nil
+}
+
+// withdraw refunds a player's stake and removes their entry, cancelling the
+// pool if nobody is left in it
+func (ls *LastLongerService) withdraw(ctx context.Context, tx *gorm.DB, pool models.LastLongerPool, userID uuid.UUID) error {
+	result := tx.Where("pool_id = ? AND user_id = ?", pool.ID, userID).Delete(&models.LastLongerEntry{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove last-longer entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotInLastLonger
+	}
+
+	var remaining int64
+	if err := tx.Model(&models.LastLongerEntry{}).Where("pool_id = ?", pool.ID).Count(&remaining).Error; err != nil {
+		return fmt.Errorf("failed to count last-longer entries: %w", err)
+	}
+	if remaining == 0 {
+		now := time.Now()
+		err := tx.Model(&pool).Updates(map[string]interface{}{"status": models.LastLongerCancelled, "settled_at": now}).Error
+		if err != nil {
+			return fmt.Errorf("failed to cancel last-longer pool: %w", err)
+		}
+	}
+
+	// Refunded last, so nothing is paid back unless the entry is gone
+	if _, err := ls.formanceService.RefundLastLonger(ctx, userID, pool.ID, pool.Stake); err != nil {
+		return err
+	}
+	slog.Info("Player left last-longer pool", "pool_id", pool.ID, "user_id", userID)
+	return nil
+}
+
+// refund returns a stake taken for an entry that then failed
+func (ls *LastLongerService) refund(ctx context.Context, userID uuid.UUID, pool models.LastLongerPool) {
+	if _, err := ls.formanceService.RefundLastLonger(ctx, userID, pool.ID, pool.Stake); err != nil {
+		slog.Error("Failed to refund last-longer stake", "user_id", userID, "pool_id", pool.ID, "error", err)
+	}
+}
+
+func (ls *LastLongerService) get(ctx context.Context, tournamentID, poolID uuid.UUID) (*models.LastLongerPool, error) {
+	var pool models.LastLongerPool
+	err := ls.db.WithContext(ctx).
+		Preload("Entries", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&pool, "id = ? AND tournament_id = ?", poolID, tournamentID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLastLongerNotFound
+		}
+		return nil, fmt.Errorf("failed to load last-longer pool: %w", err)
+	}
+	pools := []models.LastLongerPool{pool}
+	if err := ls.describe(ctx, tournamentID, pools); err != nil {
+		return nil, err
+	}
+	return &pools[0], nil
+}
+
+// describe fills in the pots, entrants' usernames and how far they got
+func (ls *LastLongerService) describe(ctx context.Context, tournamentID uuid.UUID, pools []models.LastLongerPool) error {
+	db := ls.db.WithContext(ctx)
+	for i := range pools {
+		pool := &pools[i]
+		if pool.Status == models.LastLongerSettled {
+			pool.Pot = pool.Payout
+		} else {
+			pool.Pot = pool.Stake * int64(len(pool.Entries))
+		}
+		if err := lastLongerPositions(db, tournamentID, pool.Entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastLongerPositions fills in entrants' usernames and finishing positions
+func lastLongerPositions(db *gorm.DB, tournamentID uuid.UUID, entries []models.LastLongerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	userIDs := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		userIDs[i] = entry.UserID
+	}
+
+	var rows []struct {
+		UserID        uuid.UUID
+		Username      string
+		FinalPosition *int
+	}
+	err := db.Table("users").
+		Select("users.id AS user_id, users.username, tournament_registrations.final_position").
+		Joins("LEFT JOIN tournament_registrations ON tournament_registrations.user_id = users.id AND tournament_registrations.tournament_id = ? AND tournament_registrations.deleted_at IS NULL", tournamentID).
+		Where("users.id IN ?", userIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to load last-longer entrants: %w", err)
+	}
+
+	byUser := make(map[uuid.UUID]int, len(rows))
+	for i, row := range rows {
+		byUser[row.UserID] = i
+	}
+	for i := range entries {
+		if j, ok := byUser[entries[i].UserID]; ok {
+			entries[i].Username = rows[j].Username
+			entries[i].FinalPosition = rows[j].FinalPosition
+		}
+	}
+	return nil
+}
+
+// lockLastLongerPool locks a pool so entries and settlement happen one at a
+// time
+func lockLastLongerPool(tx *gorm.DB, tournamentID, poolID uuid.UUID, pool *models.LastLongerPool) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(pool, "id = ? AND tournament_id = ?", poolID, tournamentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLastLongerNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load last-longer pool: %w", err)
+	}
+	return nil
+}
+
+// checkLastLongerEntrant checks the player is registered for a tournament
+// that hasn't started
+func checkLastLongerEntrant(tx *gorm.DB, tournamentID, userID uuid.UUID) error {
+	var tournament models.Tournament
+	if err := tx.First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTournamentNotFound
+		}
+		return fmt.Errorf("failed to load tournament: %w", err)
+	}
+	if tournament.Status != "registering" {
+		return ErrLastLongerClosed
+	}
+
+	var registrations int64
+	err := tx.Model(&models.TournamentRegistration{}).
+		Where("tournament_id = ? AND user_id = ?", tournamentID, userID).
+		Count(&registrations).Error
+	if err != nil {
+		return fmt.Errorf("failed to check registration: %w", err)
+	}
+	if registrations == 0 {
+		return ErrLastLongerNotRegistered
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownLeaderboard is returned for a leaderboard kind that doesn't exist
+	ErrUnknownLeaderboard = errors.New("unknown leaderboard")
+	// ErrUnknownLeaderboardPeriod is returned for a period that isn't daily,
+	// weekly or monthly
+	ErrUnknownLeaderboardPeriod = errors.New("unknown leaderboard period")
+)
+
+// leaderboardCacheTTL is how long rankings are served before the period's
+// hands are counted again
+const leaderboardCacheTTL = time.Minute
+
+// leaderboardHandBatch is how many hands are read at a time while ranking
+const leaderboardHandBatch = 1000
+
+// LeaderboardKinds are the leaderboards that can be ranked
+var LeaderboardKinds = []string{models.LeaderboardBiggestWinners, models.LeaderboardMostHands, models.LeaderboardBiggestPots}
+
+// LeaderboardService ranks players by their results in the hands of the
+// current day, week or month. Each period's rankings are cached, and a new
+// period starts with fresh rankings the first time it is asked for.
+type LeaderboardService struct {
+	db      *database.DB
+	periods map[string]*leaderboardCache // Fixed at construction
+}
+
+// leaderboardCache holds every kind of ranking of one period
+type leaderboardCache struct {
+	mu           sync.Mutex
+	from         time.Time
+	calculatedAt time.Time
+	boards       map[string][]models.LeaderboardStanding
+}
+
+func NewLeaderboardService(db *database.DB) *LeaderboardService {
+	return &LeaderboardService{
+		db: db,
+		periods: map[string]*leaderboardCache{
+			models.LeaderboardDaily:   {},
+			models.LeaderboardWeekly:  {},
+			models.LeaderboardMonthly: {},
+		},
+	}
+}
+
+// LeaderboardWindow returns the period that now falls in, [from, to)
+func LeaderboardWindow(period string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case models.LeaderboardDaily:
+		return day, day.AddDate(0, 0, 1), nil
+	case models.LeaderboardWeekly:
+		// Weeks start on Monday
+		from := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 7), nil
+	case models.LeaderboardMonthly:
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, ErrUnknownLeaderboardPeriod
+}
+
+// Get returns the top limit players of a leaderboard for the current period,
+// and the viewer's own standing when they are on it
+func (ls *LeaderboardService) Get(ctx context.Context, kind, period string, limit int, viewerID uuid.UUID, now time.Time) (*models.LeaderboardStandings, error) {
+	if !slices.Contains(LeaderboardKinds, kind) {
+		return nil, ErrUnknownLeaderboard
+	}
+	cache, ok := ls.periods[period]
+	if !ok {
+		return nil, ErrUnknownLeaderboardPeriod
+	}
+	from, to, err := LeaderboardWindow(period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if !cache.from.Equal(from) || now.Sub(cache.calculatedAt) > leaderboardCacheTTL {
+		boards, err := ls.rank(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		cache.from, cache.calculatedAt, cache.boards = from, now, boards
+	}
+
+	board := cache.boards[kind]
+	standings := &models.LeaderboardStandings{
+		Type:         kind,
+		Period:       period,
+		PeriodStart:  from,
+		PeriodEnd:    to,
+		CalculatedAt: cache.calculatedAt,
+		Entries:      slices.Clone(board[:min(limit, len(board))]),
+	}
+	if viewerID != uuid.Nil {
+		if i := slices.IndexFunc(board, func(s models.LeaderboardStanding) bool { return s.UserID == viewerID }); i >= 0 {
+			standing := board[i]
+			standings.UserRank = &standing
+		}
+	}
+	return standings, nil
+}
+
+// rank counts up the finished hands that started in [from, to) into every
+// kind of leaderboard
+func (ls *LeaderboardService) rank(ctx context.Context, from, to time.Time) (map[string][]models.LeaderboardStanding, error) {
+	totals := newLeaderboardTotals()
+	var batch []models.HandHistory
+	err := ls.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Select("id", "hand_id", "hole_cards", "winners").
+		Where("ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", from, to).
+		FindInBatches(&batch, leaderboardHandBatch, func(_ *gorm.DB, _ int) error {
+			for _, hand := range batch {
+				totals.add(hand)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank hands: %w", err)
+	}
+
+	names, err := ls.usernames(ctx, totals.players())
+	if err != nil {
+		return nil, err
+	}
+	return totals.boards(names), nil
+}
+
+// usernames looks up the players' current names. Deleted accounts and
+// players who keep their name out of hand histories are left out, so they
+// drop off the leaderboards.
+func (ls *LeaderboardService) usernames(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(userIDs))
+	for batch := range slices.Chunk(userIDs, leaderboardHandBatch) {
+		var users []models.User
+		if err := ls.db.WithContext(ctx).Select("id", "username").Where("id IN ?", batch).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up users: %w", err)
+		}
+		for _, user := range users {
+			names[user.ID] = user.Username
+		}
+
+		var private []uuid.UUID
+		if err := ls.db.WithContext(ctx).Model(&models.HandPrivacySettings{}).
+			Where("user_id IN ? AND visibility = ?", batch, models.HandVisibilitySelf).
+			Pluck("user_id", &private).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up hand privacy: %w", err)
+		}
+		for _, userID := range private {
+			delete(names, userID)
+		}
+	}
+	return names, nil
+}
+
+// RankHands ranks the players of the hands on a kind of leaderboard, using
+// the names recorded in the hands
+func RankHands(kind string, hands []models.HandHistory) []models.LeaderboardStanding {
+	totals := newLeaderboardTotals()
+	names := make(map[uuid.UUID]string)
+	for _, hand := range hands {
+		totals.add(hand)
+		for _, p := range hand.DealtPlayers() {
+			names[p.UserID] = p.Username
+		}
+	}
+	return totals.boards(names)[kind]
+}
+
+// leaderboardTotals are each player's results over a period
+type leaderboardTotals struct {
+	net     map[uuid.UUID]int64 // Won less wagered
+	hands   map[uuid.UUID]int64
+	bestPot map[uuid.UUID]int64 // Most won in one hand
+}
+
+func newLeaderboardTotals() *leaderboardTotals {
+	return &leaderboardTotals{
+		net:     make(map[uuid.UUID]int64),
+		hands:   make(map[uuid.UUID]int64),
+		bestPot: make(map[uuid.UUID]int64),
+	}
+}
+
+func (lt *leaderboardTotals) add(hand models.HandHistory) {
+	for _, p := range hand.DealtPlayers() {
+		if p.UserID == uuid.Nil {
+			continue
+		}
+		lt.hands[p.UserID]++
+		lt.net[p.UserID] -= p.Wagered
+	}
+
+	won := make(map[uuid.UUID]int64)
+	for _, winner := range hand.PotWinners() {
+		if winner.UserID != uuid.Nil {
+			won[winner.UserID] += winner.Amount
+		}
+	}
+	for userID, amount := range won {
+		lt.net[userID] += amount
+		lt.bestPot[userID] = max(lt.bestPot[userID], amount)
+	}
+}
+
+func (lt *leaderboardTotals) players() []uuid.UUID {
+	userIDs := make([]uuid.UUID, 0, len(lt.net))
+	for userID := range lt.net {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs
+}
+
+// boards ranks the named players on every leaderboard. Only winners are
+// ranked by winnings and pots.
+func (lt *leaderboardTotals) boards(names map[uuid.UUID]string) map[string][]models.LeaderboardStanding {
+	return map[string][]models.LeaderboardStanding{
+		models.LeaderboardBiggestWinners: rankStandings(lt.net, names),
+		models.LeaderboardMostHands:      rankStandings(lt.hands, names),
+		models.LeaderboardBiggestPots:    rankStandings(lt.bestPot, names),
+	}
+}
+
+// rankStandings orders the named players with a positive value, highest
+// first. Ties share a rank and are listed by name.
+func rankStandings(values map[uuid.UUID]int64, names map[uuid.UUID]string) []models.LeaderboardStanding {
+	standings := make([]models.LeaderboardStanding, 0, len(values))
+	for userID, value := range values {
+		name, ok := names[userID]
+		if !ok || value <= 0 {
+			continue
+		}
+		standings = append(standings, models.LeaderboardStanding{UserID: userID, Username: name, Value: value})
+	}
+	slices.SortFunc(standings, func(a, b models.LeaderboardStanding) int {
+		if a.Value != b.Value {
+			if a.Value > b.Value {
+				return -1
+			}
+			return 1
+		}
+		if a.Username != b.Username {
+			if a.Username < b.Username {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(a.UserID[:], b.UserID[:])
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Value == standings[i-1].Value {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoyaltyTierConfig defines the rake threshold and rakeback rate for a tier
+type LoyaltyTierConfig struct {
+	Tier       models.LoyaltyTier
+	Threshold  int64   // Minimum MNT raked in the qualifying window
+	Percentage float64 // Share of rake paid back (e.g., 0.10 for 10%)
+}
+
+// DefaultLoyaltyTiers are ordered from lowest to highest threshold
+var DefaultLoyaltyTiers = []LoyaltyTierConfig{
+	{Tier: models.LoyaltyTierBronze, Threshold: 0, Percentage: 0.05},
+	{Tier: models.LoyaltyTierSilver, Threshold: 10000, Percentage: 0.10},
+	{Tier: models.LoyaltyTierGold, Threshold: 50000, Percentage: 0.20},
+	{Tier: models.LoyaltyTierPlatinum, Threshold: 200000, Percentage: 0.30},
+}
+
+// loyaltyQualifyingDays is the rolling window of rake used to assign tiers
+const loyaltyQualifyingDays = 30
+
+// LoyaltyService tracks rake per player and pays tiered rakeback
+type LoyaltyService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	tiers           []LoyaltyTierConfig
+}
+
+// NewLoyaltyService creates a new loyalty service using the default tiers
+func NewLoyaltyService(db *database.DB, formanceService *formance.Service) *LoyaltyService {
+	return &LoyaltyService{
+		db:              db,
+		formanceService: formanceService,
+		tiers:           DefaultLoyaltyTiers,
+	}
+}
+
+// CollectRake collects rake through Formance and attributes it to the contributing players
+func (ls *LoyaltyService) CollectRake(ctx context.Context, config formance.RakeConfig, playerSessions map[uuid.UUID]uuid.UUID) (string, error) {
+	collection, err := ls.formanceService.CollectRakeShares(ctx, config, playerSessions)
+	if err != nil || collection == nil {
+		return "", err
+	}
+
+	if err := ls.RecordRake(ctx, config.TableID, config.HandID, collection.TransactionID, collection.Shares); err != nil {
+		// Rake is already in the ledger; a missing contribution only affects rakeback
+		slog.Error("Failed to record rake contributions", "table_id", config.TableID, "transaction_id", collection.TransactionID, "error", err)
+	}
+
+	return collection.TransactionID, nil
+}
+
+// RecordRake stores per-player rake contributions for a rake collection
+func (ls *LoyaltyService) RecordRake(ctx context.Context, tableID uuid.UUID, handID, transactionID string, shares map[uuid.UUID]int64) error {
+	contributions := make([]models.RakeContribution, 0, len(shares))
+	for userID, amount := range shares {
+		if amount <= 0 {
+			continue
+		}
+		contributions = append(contributions, models.RakeContribution{
+			UserID:        userID,
+			TableID:       tableID,
+			HandID:        handID,
+			Amount:        amount,
+			TransactionID: transactionID,
+		})
+	}
+
+	if len(contributions) == 0 {
+		return nil
+	}
+
+	if err := ls.db.WithContext(ctx).Create(&contributions).Error; err != nil {
+		return fmt.Errorf("failed to record rake contributions: %w", err)
+	}
+	return nil
+}
+
+// TierFor returns the tier configuration matching the qualifying rake amount
+func (ls *LoyaltyService) TierFor(qualifyingRake int64) LoyaltyTierConfig {
+	current := ls.tiers[0]
+	for _, tier := range ls.tiers {
+		if qualifyingRake >= tier.Threshold {
+			current = tier
+		}
+	}
+	return current
+}
+
+// GetLoyaltyStatus returns the player's tier and progress towards the next tier
+func (ls *LoyaltyService) GetLoyaltyStatus(ctx context.Context, userID uuid.UUID) (*models.LoyaltyStatus, error) {
+	qualifyingRake, err := ls.qualifyingRake(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingRake int64
+	err = ls.db.WithContext(ctx).Model(&models.RakeContribution{}).
+		Where("user_id = ? AND rakeback_payout_id IS NULL", userID).
+		Select("COALESCE(SUM(amount), 0)").Scan(&pendingRake).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending rake: %w", err)
+	}
+
+	var lifetimeRakeback int64
+	err = ls.db.WithContext(ctx).Model(&models.RakebackPayout{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(payout_amount), 0)").Scan(&lifetimeRakeback).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lifetime rakeback: %w", err)
+	}
+
+	tier := ls.TierFor(qualifyingRake)
+	status := &models.LoyaltyStatus{
+		Tier:               tier.Tier,
+		RakebackPercentage: tier.Percentage,
+		QualifyingRake:     qualifyingRake,
+		QualifyingDays:     loyaltyQualifyingDays,
+		PendingRake:        pendingRake,
+		PendingRakeback:    int64(float64(pendingRake) * tier.Percentage),
+		LifetimeRakeback:   lifetimeRakeback,
+	}
+
+	for _, next := range ls.tiers {
+		if next.Threshold > qualifyingRake {
+			nextTier := next.Tier
+			status.NextTier = &nextTier
+			status.NextTierThreshold = next.Threshold
+			status.RakeToNextTier = next.Threshold - qualifyingRake
+			break
+		}
+	}
+
+	return status, nil
+}
+
+// PayRakeback pays every player their tier percentage of rake not yet paid out
+// up to periodEnd. It is safe to re-run, even alongside another run:
+// contributions are locked and linked to the payout that covered them, and
+// each player's ledger transfer for a period can only be made once.
+func (ls *LoyaltyService) PayRakeback(ctx context.Context, periodEnd time.Time) error {
+	slog.Info("Starting rakeback payout", "period_end", periodEnd)
+
+	var pending []struct {
+		UserID uuid.UUID
+		Total  int64
+	}
+	err := ls.db.WithContext(ctx).Model(&models.RakeContribution{}).
+		Select("user_id, SUM(amount) AS total").
+		Where("rakeback_payout_id IS NULL AND created_at < ?", periodEnd).
+		Group("user_id").
+		Scan(&pending).Error
+	if err != nil {
+		return fmt.Errorf("failed to get pending rake: %w", err)
+	}
+
+	paid := 0
+	for _, p := range pending {
+		if err := ls.payUserRakeback(ctx, p.UserID, periodEnd); err != nil {
+			// Keep going so one failing player doesn't block everyone else's payout
+			slog.Error("Failed to pay rakeback", "user_id", p.UserID, "rake", p.Total, "error", err)
+			continue
+		}
+		paid++
+	}
+
+	slog.Info("Rakeback payout completed", "period_end", periodEnd, "players", len(pending), "paid", paid)
+	return nil
+}
+
+func (ls *LoyaltyService) payUserRakeback(ctx context.Context, userID uuid.UUID, periodEnd time.Time) error {
+	qualifyingRake, err := ls.qualifyingRake(ctx, userID, periodEnd)
+	if err != nil {
+		return err
+	}
+	tier := ls.TierFor(qualifyingRake)
+
+	return ls.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the unpaid contributions so a concurrent run waits for this one
+		// and then finds them already linked to its payout
+		var contributions []models.RakeContribution
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND rakeback_payout_id IS NULL AND created_at < ?", userID, periodEnd).
+			Find(&contributions).Error; err != nil {
+			return fmt.Errorf("failed to lock rake contributions: %w", err)
+		}
+		if len(contributions) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, len(contributions))
+		var rakeAmount int64
+		for i, contribution := range contributions {
+			ids[i] = contribution.ID
+			rakeAmount += contribution.Amount
+		}
+
+		payout := &models.RakebackPayout{
+			UserID:       userID,
+			Tier:         tier.Tier,
+			Percentage:   tier.Percentage,
+			RakeAmount:   rakeAmount,
+			PayoutAmount: int64(float64(rakeAmount) * tier.Percentage),
+			PeriodEnd:    periodEnd,
+		}
+		if err := tx.Create(payout).Error; err != nil {
+			return fmt.Errorf("failed to create rakeback payout: %w", err)
+		}
+
+		if err := tx.Model(&models.RakeContribution{}).
+			Where("id IN ?", ids).
+			Update("rakeback_payout_id", payout.ID).Error; err != nil {
+			return fmt.Errorf("failed to mark rake contributions as paid: %w", err)
+		}
+
+		// Contributions are still marked when the payout rounds down to zero so
+		// they don't accumulate forever
+		if payout.PayoutAmount <= 0 {
+			return nil
+		}
+
+		// The ledger reference is unique per player and period, so a payout
+		// whose commit was lost is found rather than paid twice
+		transactionID, err := ls.formanceService.PayRakeback(ctx, userID, payout.PayoutAmount, string(tier.Tier), periodEnd.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(payout).Update("transaction_id", transactionID).Error
+	})
+}
+
+// qualifyingRake sums the player's rake over the rolling qualifying window ending at asOf
+func (ls *LoyaltyService) qualifyingRake(ctx context.Context, userID uuid.UUID, asOf time.Time) (int64, error) {
+	var total int64
+	windowStart := asOf.AddDate(0, 0, -loyaltyQualifyingDays)
+	err := ls.db.WithContext(ctx).Model(&models.RakeContribution{}).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, windowStart, asOf).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get qualifying rake: %w", err)
+	}
+	return total, nil
+}
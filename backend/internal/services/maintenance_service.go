@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrMaintenanceWindowNotFound is returned for a window that doesn't exist
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+	// ErrMaintenanceWindowEnded is returned when changing a window that is over
+	ErrMaintenanceWindowEnded = errors.New("maintenance window has ended")
+	// ErrMaintenanceWindowInPast is returned for a window scheduled to end
+	// before it is created
+	ErrMaintenanceWindowInPast = errors.New("maintenance window would already be over")
+)
+
+// maintenanceCacheTTL bounds how long a request may be checked against an
+// out of date schedule after an admin changes it
+const maintenanceCacheTTL = 10 * time.Second
+
+// PlayFreezer puts every running table into or out of read-only mode.
+// Implemented by the WebSocket hub.
+type PlayFreezer interface {
+	SetAllTablesReadOnly(enabled bool, reason string)
+}
+
+// MaintenanceOptions configures when players hear about upcoming windows
+type MaintenanceOptions struct {
+	NoticeLead time.Duration // How far ahead banners show and registrants are emailed
+}
+
+// MaintenanceService schedules maintenance windows and enforces them: the
+// scopes a window covers are refused while it is active, and all play
+// windows put every table into read-only mode until they end.
+type MaintenanceService struct {
+	db           *database.DB
+	emailService *EmailService
+	options      MaintenanceOptions
+	freezer      PlayFreezer
+
+	mu       sync.Mutex
+	pending  []models.MaintenanceWindow // Windows not yet over, cached for request checks
+	loadedAt time.Time
+	frozen   bool // All play is stopped on this instance
+}
+
+func NewMaintenanceService(db *database.DB, emailService *EmailService, options MaintenanceOptions) *MaintenanceService {
+	return &MaintenanceService{db: db, emailService: emailService, options: options}
+}
+
+// SetPlayFreezer lets all play windows freeze and release the tables
+func (s *MaintenanceService) SetPlayFreezer(freezer PlayFreezer) {
+	s.freezer = freezer
+}
+
+// Create schedules a maintenance window
+func (s *MaintenanceService) Create(ctx context.Context, req models.CreateMaintenanceWindowRequest, adminID uuid.UUID, now time.Time) (*models.MaintenanceWindow, error) {
+	window := models.MaintenanceWindow{
+		Title:     req.Title,
+		Message:   req.Message,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.StartsAt.UTC().Add(time.Duration(req.DurationMinutes) * time.Minute),
+		Scopes:    strings.Join(req.Scopes, ","),
+		CreatedBy: adminID,
+	}
+	if !window.EndsAt.After(now) {
+		return nil, ErrMaintenanceWindowInPast
+	}
+	if err := s.db.WithContext(ctx).Create(&window).Error; err != nil {
+		return nil, fmt.Errorf("failed to create maintenance window: %w", err)
+	}
+	s.invalidate()
+
+	slog.Warn("Maintenance window scheduled", "window_id", window.ID, "title", window.Title, "starts_at", window.StartsAt, "ends_at", window.EndsAt, "scopes", window.Scopes, "admin_id", adminID)
+	return &window, nil
+}
+
+// Update reschedules or changes a window that has not ended. A window moved
+// to start later or stop covering all play is lifted on the next Enforce.
+func (s *MaintenanceService) Update(ctx context.Context, id uuid.UUID, req models.UpdateMaintenanceWindowRequest, adminID uuid.UUID, now time.Time) (*models.MaintenanceWindow, error) {
+	window, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if window.StatusAt(now) == models.MaintenanceStatusEnded {
+		return nil, ErrMaintenanceWindowEnded
+	}
+
+	if req.Title != nil {
+		window.Title = *req.Title
+	}
+	if req.Message != nil {
+		window.Message = *req.Message
+	}
+	duration := window.EndsAt.Sub(window.StartsAt)
+	if req.DurationMinutes != nil {
+		duration = time.Duration(*req.DurationMinutes) * time.Minute
+	}
+	if req.StartsAt != nil {
+		window.StartsAt = req.StartsAt.UTC()
+	}
+	window.EndsAt = window.StartsAt.Add(duration)
+	if req.Scopes != nil {
+		window.Scopes = strings.Join(req.Scopes, ",")
+	}
+	if !window.EndsAt.After(now) {
+		return nil, ErrMaintenanceWindowInPast
+	}
+	// A rescheduled window gets fresh notices
+	if req.StartsAt != nil || req.DurationMinutes != nil {
+		window.NoticesSentAt = nil
+	}
+
+	if err := s.db.WithContext(ctx).Save(window).Error; err != nil {
+		return nil, fmt.Errorf("failed to update maintenance window: %w", err)
+	}
+	s.invalidate()
+
+	slog.Warn("Maintenance window changed", "window_id", window.ID, "starts_at", window.StartsAt, "ends_at", window.EndsAt, "scopes", window.Scopes, "admin_id", adminID)
+	return window, nil
+}
+
+// Delete cancels a window. One already in force is lifted on the next Enforce.
+func (s *MaintenanceService) Delete(ctx context.Context, id uuid.UUID, adminID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&models.MaintenanceWindow{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete maintenance window: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrMaintenanceWindowNotFound
+	}
+	s.invalidate()
+
+	slog.Warn("Maintenance window cancelled", "window_id", id, "admin_id", adminID)
+	return nil
+}
+
+// Get returns a window
+func (s *MaintenanceService) Get(ctx context.Context, id uuid.UUID) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	if err := s.db.WithContext(ctx).First(&window, "id = ?", id).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrMaintenanceWindowNotFound
+		}
+		return nil, fmt.Errorf("failed to get maintenance window: %w", err)
+	}
+	return &window, nil
+}
+
+// List returns windows ending after since, soonest first
+func (s *MaintenanceService) List(ctx context.Context, since time.Time) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	if err := s.db.WithContext(ctx).Where("ends_at > ?", since).Order("starts_at ASC").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return windows, nil
+}
+
+// Active returns the window taking scope offline at now, or nil. If the
+// schedule can't be read the last one loaded is used, so a database hiccup
+// neither blocks nor opens everything.
+func (s *MaintenanceService) Active(ctx context.Context, scope string, now time.Time) *models.MaintenanceWindow {
+	for _, window := range s.pendingWindows(ctx, now) {
+		if window.ActiveAt(now) && window.Covers(scope) {
+			return &window
+		}
+	}
+	return nil
+}
+
+// Notices returns the windows players should be told about at now: those in
+// force and those starting within the notice lead
+func (s *MaintenanceService) Notices(ctx context.Context, now time.Time) []models.MaintenanceNotice {
+	notices := []models.MaintenanceNotice{}
+	for _, window := range s.pendingWindows(ctx, now) {
+		if !window.EndsAt.After(now) || window.StartsAt.After(now.Add(s.options.NoticeLead)) {
+			continue
+		}
+		notices = append(notices, models.MaintenanceNotice{
+			ID:       window.ID,
+			Title:    window.Title,
+			Message:  window.Message,
+			StartsAt: window.StartsAt,
+			EndsAt:   window.EndsAt,
+			Scopes:   window.ScopeList(),
+			Status:   window.StatusAt(now),
+		})
+	}
+	return notices
+}
+
+func (s *MaintenanceService) pendingWindows(ctx context.Context, now time.Time) []models.MaintenanceWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loadedAt.IsZero() || time.Since(s.loadedAt) > maintenanceCacheTTL {
+		var windows []models.MaintenanceWindow
+		if err := s.db.WithContext(ctx).Where("ends_at > ?", now).Order("starts_at ASC").Find(&windows).Error; err != nil {
+			slog.Warn("Failed to load maintenance windows", "error", err)
+			return s.pending
+		}
+		s.pending = windows
+		s.loadedAt = time.Now()
+	}
+	return s.pending
+}
+
+func (s *MaintenanceService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// Enforce brings the server in line with the schedule: it records windows
+// starting and ending, freezes or releases the tables for all play windows
+// and emails registrants of tournaments an upcoming all play window affects.
+func (s *MaintenanceService) Enforce(ctx context.Context, now time.Time) error {
+	var windows []models.MaintenanceWindow
+	if err := s.db.WithContext(ctx).Where("ends_at > ? OR (started_at IS NOT NULL AND lifted_at IS NULL)", now).Find(&windows).Error; err != nil {
+		return fmt.Errorf("failed to load maintenance windows: %w", err)
+	}
+
+	var freezeFor *models.MaintenanceWindow
+	for i := range windows {
+		window := &windows[i]
+		switch {
+		case window.ActiveAt(now) && window.StartedAt == nil:
+			if err := s.db.WithContext(ctx).Model(window).Update("started_at", now).Error; err != nil {
+				return fmt.Errorf("failed to mark maintenance window started: %w", err)
+			}
+			slog.Warn("Maintenance window started", "window_id", window.ID, "title", window.Title, "scopes", window.Scopes, "ends_at", window.EndsAt)
+		case !window.ActiveAt(now) && window.StartedAt != nil && window.LiftedAt == nil:
+			if err := s.db.WithContext(ctx).Model(window).Update("lifted_at", now).Error; err != nil {
+				return fmt.Errorf("failed to mark maintenance window lifted: %w", err)
+			}
+			slog.Warn("Maintenance window lifted", "window_id", window.ID, "title", window.Title, "scopes", window.Scopes)
+		}
+		if window.ActiveAt(now) && window.Covers(models.MaintenanceScopeAllPlay) && freezeFor == nil {
+			freezeFor = window
+		}
+	}
+	// Windows cancelled while in force are deleted, so lift them here
+	if err := s.db.WithContext(ctx).Unscoped().Model(&models.MaintenanceWindow{}).
+		Where("deleted_at IS NOT NULL AND started_at IS NOT NULL AND lifted_at IS NULL").
+		Update("lifted_at", now).Error; err != nil {
+		return fmt.Errorf("failed to lift cancelled maintenance windows: %w", err)
+	}
+
+	s.setFrozen(freezeFor)
+	s.invalidate()
+
+	return s.sendNotices(ctx, windows, now)
+}
+
+// setFrozen freezes every table while an all play window is in force and
+// releases them once none is
+func (s *MaintenanceService) setFrozen(window *models.MaintenanceWindow) {
+	s.mu.Lock()
+	changed := s.frozen != (window != nil)
+	s.frozen = window != nil
+	s.mu.Unlock()
+	if !changed || s.freezer == nil {
+		return
+	}
+
+	if window != nil {
+		s.freezer.SetAllTablesReadOnly(true, "Scheduled maintenance: "+window.Title)
+		return
+	}
+	s.freezer.SetAllTablesReadOnly(false, "")
+}
+
+// sendNotices emails the registrants of tournaments that start before an all
+// play window within the notice lead ends, once per window
+func (s *MaintenanceService) sendNotices(ctx context.Context, windows []models.MaintenanceWindow, now time.Time) error {
+	if s.emailService == nil {
+		return nil
+	}
+
+	for _, window := range windows {
+		if window.NoticesSentAt != nil || !window.Covers(models.MaintenanceScopeAllPlay) ||
+			!window.EndsAt.After(now) || window.StartsAt.After(now.Add(s.options.NoticeLead)) {
+			continue
+		}
+
+		var userIDs []uuid.UUID
+		if err := s.db.WithContext(ctx).Model(&models.TournamentRegistration{}).
+			Joins("JOIN tournaments ON tournaments.id = tournament_registrations.tournament_id AND tournaments.deleted_at IS NULL").
+			Where("tournaments.status IN ? AND (tournaments.start_time IS NULL OR tournaments.start_time < ?)", []string{"registering", "running"}, window.EndsAt).
+			Distinct().Pluck("tournament_registrations.user_id", &userIDs).Error; err != nil {
+			return fmt.Errorf("failed to find tournament registrants: %w", err)
+		}
+		var users []models.User
+		if len(userIDs) > 0 {
+			if err := s.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+				return fmt.Errorf("failed to load tournament registrants: %w", err)
+			}
+		}
+
+		for _, user := range users {
+			if err := s.emailService.SendMaintenanceNoticeEmail(user.Email, user.Username, window.Title, window.Message, window.StartsAt, window.EndsAt); err != nil {
+				slog.Warn("Failed to send maintenance notice", "window_id", window.ID, "user_id", user.ID, "error", err)
+			}
+		}
+		if err := s.db.WithContext(ctx).Model(&window).Update("notices_sent_at", now).Error; err != nil {
+			return fmt.Errorf("failed to mark maintenance notices sent: %w", err)
+		}
+		slog.Info("Sent maintenance notices", "window_id", window.ID, "recipients", len(users))
+	}
+	return nil
+}
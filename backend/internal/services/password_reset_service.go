@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidResetToken        = errors.New("password reset link is invalid or has expired")
+	ErrPasswordResetRateLimited = errors.New("too many password reset requests, try again later")
+)
+
+// PasswordResetOptions are how long reset links work and how often they can
+// be requested
+type PasswordResetOptions struct {
+	TTL          time.Duration
+	AccountLimit int // Resets emailed per account per window
+	IPLimit      int // Reset requests per IP address per window
+	Window       time.Duration
+}
+
+// PasswordResetService emails single-use password reset links and resets
+// passwords with them, signing the user out everywhere
+type PasswordResetService struct {
+	db           *database.DB
+	emailService *EmailService
+	options      PasswordResetOptions
+	requests     slidingWindowLimiter[string]
+}
+
+func NewPasswordResetService(db *database.DB, emailService *EmailService, options PasswordResetOptions) *PasswordResetService {
+	prs := &PasswordResetService{
+		db:           db,
+		emailService: emailService,
+		options:      options,
+	}
+	prs.requests.reset(options.IPLimit, options.Window)
+	return prs
+}
+
+// RequestReset emails a reset link to the account with the address. So the
+// response doesn't say whether an account exists, nothing is returned for
+// unknown or disabled accounts, or for accounts that have had their share
+// of links this window; only the per-IP limit is reported.
+func (prs *PasswordResetService) RequestReset(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	if !prs.requests.allow(ip, now) {
+		return ErrPasswordResetRateLimited
+	}
+
+	var user models.User
+	err := prs.db.WithContext(ctx).Where("email = ?", strings.TrimSpace(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.DisabledAt != nil {
+		return nil
+	}
+
+	var recent int64
+	if err := prs.db.WithContext(ctx).Model(&models.PasswordReset{}).
+		Where("user_id = ? AND created_at > ?", user.ID, now.Add(-prs.options.Window)).
+		Count(&recent).Error; err != nil {
+		return fmt.Errorf("failed to count password resets: %w", err)
+	}
+	if recent >= int64(prs.options.AccountLimit) {
+		slog.Warn("Password reset limit reached", "user_id", user.ID, "ip", ip)
+		return nil
+	}
+
+	token, err := auth.GenerateToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(prs.options.TTL),
+		RequestIP: ip,
+	}
+	err = prs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only the newest link works
+		if err := supersedeResets(tx, user.ID, now); err != nil {
+			return err
+		}
+		return tx.Create(&reset).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	slog.Info("Password reset requested", "user_id", user.ID, "reset_id", reset.ID, "ip", ip)
+	// Sent in the background so the response takes as long for unknown addresses
+	prs.notify("reset link", user.ID, func() error {
+		return prs.emailService.SendPasswordResetEmail(user.Email, user.Username, token, prs.options.TTL)
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a token from RequestReset. The
+// token is used up, and every sign-in the user had is revoked.
+func (prs *PasswordResetService) ResetPassword(ctx context.Context, token, password, ip string) error {
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	var user models.User
+	err = prs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the token keeps two requests from both using it
+		var reset models.PasswordReset
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashResetToken(token), now).
+			First(&reset).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find password reset: %w", err)
+		}
+
+		if err := tx.First(&user, "id = ?", reset.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+		if user.DisabledAt != nil {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"password_hash":       passwordHash,
+			"sessions_revoked_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		return supersedeResets(tx, user.ID, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Password reset", "user_id", user.ID, "ip", ip)
+	prs.notify("password changed notice", user.ID, func() error {
+		return prs.emailService.SendPasswordChangedEmail(user.Email, user.Username, now)
+	})
+	return nil
+}
+
+// SessionsRevokedAt is when the user's sign-ins were last revoked, for the
+// auth middleware. Disabled accounts have no working sign-ins at all.
+func (prs *PasswordResetService) SessionsRevokedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var user models.User
+	err := prs.db.WithContext(ctx).Select("sessions_revoked_at", "disabled_at").First(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+	if user.DisabledAt != nil {
+		return nil, auth.ErrAccountDisabled
+	}
+	return user.SessionsRevokedAt, nil
+}
+
+// notify sends an email in the background, logging failures
+func (prs *PasswordResetService) notify(what string, userID uuid.UUID, send func() error) {
+	if prs.emailService == nil {
+		return
+	}
+	go func() {
+		if err := send(); err != nil {
+			slog.Warn("Failed to send password reset email", "email", what, "user_id", userID, "error", err)
+		}
+	}()
+}
+
+// supersedeResets uses up the user's outstanding reset links
+func supersedeResets(tx *gorm.DB, userID uuid.UUID, now time.Time) error {
+	return tx.Model(&models.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
+
+// hashResetToken is what is stored for a reset token, so a leaked table
+// can't be used to reset passwords
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotFriendSelf       = errors.New("cannot send a friend request to yourself")
+	ErrFriendRequestExists    = errors.New("friend request already sent")
+	ErrFriendRequestNotFound  = errors.New("friend request not found")
+	ErrFriendshipBlocked      = errors.New("friend requests between these users are blocked")
+	ErrNotFriends             = errors.New("not friends with this user")
+	ErrFriendNotAtTable       = errors.New("friend is not at a table you can join")
+	ErrFriendJoinNotAllowed   = errors.New("friend does not allow joining their table")
+	ErrFriendTableFull        = errors.New("friend's table is full")
+	ErrFriendTableTournament  = errors.New("tournament tables cannot be joined")
+	ErrFriendTableUnavailable = errors.New("friend's table is closed")
+)
+
+// OnlineChecker reports whether a user has an open connection
+type OnlineChecker interface {
+	IsOnline(userID uuid.UUID) bool
+}
+
+// PresenceService manages friend lists and what friends can see of each
+// other: whether they are online and which table or tournament they are
+// playing, subject to each player's presence settings
+type PresenceService struct {
+	db     *database.DB
+	online OnlineChecker
+}
+
+func NewPresenceService(db *database.DB, online OnlineChecker) *PresenceService {
+	return &PresenceService{db: db, online: online}
+}
+
+// SendFriendRequest asks another player, by username, to be friends. If they
+// already asked the sender, the friendship is accepted instead.
+func (ps *PresenceService) SendFriendRequest(ctx context.Context, userID uuid.UUID, username string) (*models.Friendship, error) {
+	var other models.User
+	err := ps.db.WithContext(ctx).Select("id", "username").First(&other, "username = ?", strings.TrimSpace(username)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	if other.ID == userID {
+		return nil, ErrCannotFriendSelf
+	}
+
+	blocked, err := NewDirectMessageService(ps.db).IsBlockedEitherWay(ctx, userID, other.ID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrFriendshipBlocked
+	}
+
+	existing, err := ps.friendship(ctx, userID, other.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Status == models.FriendshipPending && existing.RequesterID == other.ID {
+			return ps.AcceptFriendRequest(ctx, userID, other.ID)
+		}
+		return nil, ErrFriendRequestExists
+	}
+
+	friendship := &models.Friendship{
+		RequesterID: userID,
+		AddresseeID: other.ID,
+		Status:      models.FriendshipPending,
+	}
+	if err := ps.db.WithContext(ctx).Create(friendship).Error; err != nil {
+		return nil, fmt.Errorf("failed to send friend request: %w", err)
+	}
+	return friendship, nil
+}
+
+// AcceptFriendRequest accepts a pending request from requesterID
+func (ps *PresenceService) AcceptFriendRequest(ctx context.Context, userID, requesterID uuid.UUID) (*models.Friendship, error) {
+	var friendship models.Friendship
+	err := ps.db.WithContext(ctx).
+		Where("requester_id = ? AND addressee_id = ? AND status = ?", requesterID, userID, models.FriendshipPending).
+		First(&friendship).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFriendRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get friend request: %w", err)
+	}
+
+	now := time.Now()
+	friendship.Status = models.FriendshipAccepted
+	friendship.AcceptedAt = &now
+	if err := ps.db.WithContext(ctx).Save(&friendship).Error; err != nil {
+		return nil, fmt.Errorf("failed to accept friend request: %w", err)
+	}
+
+	slog.Info("Friend request accepted", "user_id", userID, "requester_id", requesterID)
+	return &friendship, nil
+}
+
+// RemoveFriend ends a friendship, or declines or withdraws a pending request
+func (ps *PresenceService) RemoveFriend(ctx context.Context, userID, otherID uuid.UUID) error {
+	result := ps.db.WithContext(ctx).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", userID, otherID, otherID, userID).
+		Delete(&models.Friendship{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove friend: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+// ListFriendRequests returns the requests waiting for the user's answer
+func (ps *PresenceService) ListFriendRequests(ctx context.Context, userID uuid.UUID) ([]models.FriendRequest, error) {
+	var requests []models.FriendRequest
+	err := ps.db.WithContext(ctx).Model(&models.Friendship{}).
+		Select("friendships.requester_id AS user_id, users.username, friendships.created_at").
+		Joins("JOIN users ON users.id = friendships.requester_id").
+		Where("friendships.addressee_id = ? AND friendships.status = ?", userID, models.FriendshipPending).
+		Order("friendships.created_at DESC").
+		Scan(&requests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ListFriends returns the user's friends with whatever presence each of them
+// shares. Invisible friends always appear offline.
+func (ps *PresenceService) ListFriends(ctx context.Context, userID uuid.UUID) ([]models.FriendPresence, error) {
+	var friends []struct {
+		ID       uuid.UUID
+		Username string
+	}
+	err := ps.db.WithContext(ctx).Model(&models.User{}).
+		Select("users.id, users.username").
+		Joins("JOIN friendships ON (friendships.requester_id = ? AND friendships.addressee_id = users.id) OR (friendships.addressee_id = ? AND friendships.requester_id = users.id)", userID, userID).
+		Where("friendships.status = ?", models.FriendshipAccepted).
+		Order("users.username ASC").
+		Scan(&friends).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+
+	presences := make([]models.FriendPresence, 0, len(friends))
+	for _, friend := range friends {
+		presence, err := ps.presence(ctx, friend.ID, friend.Username)
+		if err != nil {
+			return nil, err
+		}
+		presences = append(presences, *presence)
+	}
+	return presences, nil
+}
+
+// GetSettings returns the user's presence settings
+func (ps *PresenceService) GetSettings(ctx context.Context, userID uuid.UUID) (*models.PresenceSettings, error) {
+	settings := models.DefaultPresenceSettings(userID)
+	err := ps.db.WithContext(ctx).First(&settings, "user_id = ?", userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get presence settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpdateSettings changes the presence settings that are set in the request
+func (ps *PresenceService) UpdateSettings(ctx context.Context, userID uuid.UUID, req models.UpdatePresenceSettingsRequest) (*models.PresenceSettings, error) {
+	settings, err := ps.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Invisible != nil {
+		settings.Invisible = *req.Invisible
+	}
+	if req.ShowActivity != nil {
+		settings.ShowActivity = *req.ShowActivity
+	}
+	if req.AllowJoin != nil {
+		settings.AllowJoin = *req.AllowJoin
+	}
+
+	if err := ps.db.WithContext(ctx).Save(settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to update presence settings: %w", err)
+	}
+	return settings, nil
+}
+
+// JoinFriendTable finds the cash table a friend is playing so the user can
+// join it. Private tables keep their password; the join itself goes through
+// the table's join endpoint.
+func (ps *PresenceService) JoinFriendTable(ctx context.Context, userID, friendID uuid.UUID) (*models.JoinFriendTarget, error) {
+	friendship, err := ps.friendship(ctx, userID, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if friendship == nil || friendship.Status != models.FriendshipAccepted {
+		return nil, ErrNotFriends
+	}
+
+	settings, err := ps.GetSettings(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Invisible || !settings.ShowActivity {
+		return nil, ErrFriendNotAtTable
+	}
+	if !settings.AllowJoin {
+		return nil, ErrFriendJoinNotAllowed
+	}
+
+	table, err := ps.currentTable(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, ErrFriendNotAtTable
+	}
+	if err := friendTableJoinError(table); err != nil {
+		return nil, err
+	}
+
+	return &models.JoinFriendTarget{
+		TableID:          table.ID,
+		TableName:        table.Name,
+		MinBuyIn:         table.MinBuyIn,
+		MaxBuyIn:         table.MaxBuyIn,
+		RequiresPassword: table.IsPrivate && table.PasswordHash != nil,
+	}, nil
+}
+
+func (ps *PresenceService) presence(ctx context.Context, friendID uuid.UUID, username string) (*models.FriendPresence, error) {
+	presence := &models.FriendPresence{UserID: friendID, Username: username}
+
+	settings, err := ps.GetSettings(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Invisible {
+		return presence, nil
+	}
+	presence.Online = ps.online != nil && ps.online.IsOnline(friendID)
+	if !settings.ShowActivity {
+		return presence, nil
+	}
+
+	table, err := ps.currentTable(ctx, friendID)
+	if err != nil {
+		return nil, err
+	}
+	if table != nil {
+		presence.Table = &models.PresenceTable{
+			ID:        table.ID,
+			Name:      table.Name,
+			TableType: table.TableType,
+			IsPrivate: table.IsPrivate,
+		}
+		presence.CanJoin = settings.AllowJoin && friendTableJoinError(table) == nil
+	}
+
+	var tournament models.Tournament
+	err = ps.db.WithContext(ctx).
+		Select("tournaments.id, tournaments.name").
+		Joins("JOIN tournament_registrations ON tournament_registrations.tournament_id = tournaments.id AND tournament_registrations.deleted_at IS NULL").
+		Where("tournament_registrations.user_id = ? AND tournament_registrations.final_position IS NULL AND tournaments.status = ?", friendID, "running").
+		First(&tournament).Error
+	if err == nil {
+		presence.Tournament = &models.PresenceTournament{ID: tournament.ID, Name: tournament.Name}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get friend's tournament: %w", err)
+	}
+
+	return presence, nil
+}
+
+// friendTableJoinError reports why a table can't be joined through the
+// shortcut, or nil if it can
+func friendTableJoinError(table *models.PokerTable) error {
+	switch {
+	case table.TableType == "tournament":
+		return ErrFriendTableTournament
+	case table.Status == "finished":
+		return ErrFriendTableUnavailable
+	case table.CurrentPlayers >= table.MaxPlayers:
+		return ErrFriendTableFull
+	}
+	return nil
+}
+
+// currentTable returns the table of the user's most recent active session
+func (ps *PresenceService) currentTable(ctx context.Context, userID uuid.UUID) (*models.PokerTable, error) {
+	var table models.PokerTable
+	err := ps.db.WithContext(ctx).
+		Joins("JOIN game_sessions ON game_sessions.table_id = poker_tables.id").
+		Where("game_sessions.user_id = ? AND game_sessions.status = ?", userID, models.GameSessionStatusActive).
+		Order("game_sessions.joined_at DESC").
+		First(&table).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get current table: %w", err)
+	}
+	return &table, nil
+}
+
+func (ps *PresenceService) friendship(ctx context.Context, a, b uuid.UUID) (*models.Friendship, error) {
+	var friendship models.Friendship
+	err := ps.db.WithContext(ctx).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", a, b, b, a).
+		First(&friendship).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get friendship: %w", err)
+	}
+	return &friendship, nil
+}
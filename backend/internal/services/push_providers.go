@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidDeviceToken is returned by a provider when a token is no longer
+// valid and should be removed
+var ErrInvalidDeviceToken = errors.New("invalid device token")
+
+// PushNotification is the provider-neutral content of a push message
+type PushNotification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushProvider delivers a notification to a single device token
+type PushProvider interface {
+	Send(ctx context.Context, token string, notification PushNotification) error
+}
+
+// FCMProvider sends notifications through the Firebase Cloud Messaging HTTP API
+type FCMProvider struct {
+	httpClient *http.Client
+	serverKey  string
+	endpoint   string
+}
+
+func NewFCMProvider(serverKey string) *FCMProvider {
+	return &FCMProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		serverKey:  serverKey,
+		endpoint:   "https://fcm.googleapis.com/fcm/send",
+	}
+}
+
+func (p *FCMProvider) Send(ctx context.Context, token string, notification PushNotification) error {
+	payload := map[string]interface{}{
+		"to": token,
+		"notification": map[string]string{
+			"title": notification.Title,
+			"body":  notification.Body,
+		},
+		"data": notification.Data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCM payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create FCM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+p.serverKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("FCM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("FCM HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Failure int `json:"failure"`
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse FCM response: %w", err)
+	}
+	if result.Failure > 0 && len(result.Results) > 0 {
+		switch result.Results[0].Error {
+		case "NotRegistered", "InvalidRegistration":
+			return ErrInvalidDeviceToken
+		default:
+			return fmt.Errorf("FCM error: %s", result.Results[0].Error)
+		}
+	}
+	return nil
+}
+
+// APNsProvider sends notifications through Apple Push Notification service using token auth
+type APNsProvider struct {
+	httpClient *http.Client
+	keyID      string
+	teamID     string
+	bundleID   string
+	privateKey *ecdsa.PrivateKey
+	host       string
+
+	mu          sync.Mutex
+	authToken   string
+	authExpires time.Time
+}
+
+func NewAPNsProvider(keyID, teamID, bundleID, privateKeyPEM string, production bool) (*APNsProvider, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APNs private key: %w", err)
+	}
+
+	host := "https://api.sandbox.push.apple.com"
+	if production {
+		host = "https://api.push.apple.com"
+	}
+
+	return &APNsProvider{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keyID:      keyID,
+		teamID:     teamID,
+		bundleID:   bundleID,
+		privateKey: key,
+		host:       host,
+	}, nil
+}
+
+// bearerToken returns a cached provider token; Apple rejects tokens older than an hour
+func (p *APNsProvider) bearerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.authToken != "" && time.Now().Before(p.authExpires) {
+		return p.authToken, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": time.Now().Unix(),
+	})
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs token: %w", err)
+	}
+
+	p.authToken = signed
+	p.authExpires = time.Now().Add(50 * time.Minute)
+	return signed, nil
+}
+
+func (p *APNsProvider) Send(ctx context.Context, token string, notification PushNotification) error {
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for k, v := range notification.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal APNs payload: %w", err)
+	}
+
+	bearer, err := p.bearerToken()
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/3/device/%s", p.host, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create APNs request: %w", err)
+	}
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", p.bundleID)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("APNs request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(respBody, &apnsErr)
+
+	if resp.StatusCode == http.StatusGone || apnsErr.Reason == "BadDeviceToken" || apnsErr.Reason == "Unregistered" {
+		return ErrInvalidDeviceToken
+	}
+	return fmt.Errorf("APNs HTTP %d: %s", resp.StatusCode, apnsErr.Reason)
+}
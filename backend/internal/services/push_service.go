@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+)
+
+// pushRateCaps limits how often a single user can receive each kind of push
+var pushRateCaps = map[models.PushEvent]struct {
+	every time.Duration
+	burst int
+}{
+	models.PushEventTurnAlert:        {every: 30 * time.Second, burst: 2},
+	models.PushEventTournament:       {every: time.Minute, burst: 5},
+	models.PushEventWithdrawalStatus: {every: 10 * time.Minute, burst: 5},
+	models.PushEventTableStatus:      {every: time.Minute, burst: 3},
+}
+
+// PushService delivers push notifications to registered devices respecting
+// per-event opt-in preferences and per-user rate caps
+type PushService struct {
+	db        *database.DB
+	providers map[models.PushPlatform]PushProvider
+	limiters  sync.Map // "userID:event" -> *rate.Limiter
+}
+
+// NewPushService creates a push service with the providers configured in cfg.
+// Platforms without credentials are skipped so development setups work without keys.
+func NewPushService(db *database.DB, cfg *config.Config) *PushService {
+	providers := make(map[models.PushPlatform]PushProvider)
+
+	if cfg.FCMServerKey != "" {
+		providers[models.PushPlatformFCM] = NewFCMProvider(cfg.FCMServerKey)
+	}
+
+	if cfg.APNsPrivateKey != "" {
+		apns, err := NewAPNsProvider(cfg.APNsKeyID, cfg.APNsTeamID, cfg.APNsBundleID, cfg.APNsPrivateKey, cfg.APNsProduction)
+		if err != nil {
+			slog.Warn("APNs disabled", "error", err)
+		} else {
+			providers[models.PushPlatformAPNs] = apns
+		}
+	}
+
+	return NewPushServiceWithProviders(db, providers)
+}
+
+// NewPushServiceWithProviders creates a push service with explicit providers
+func NewPushServiceWithProviders(db *database.DB, providers map[models.PushPlatform]PushProvider) *PushService {
+	return &PushService{
+		db:        db,
+		providers: providers,
+	}
+}
+
+// RegisterDevice stores (or moves) a device token for the user
+func (ps *PushService) RegisterDevice(ctx context.Context, userID uuid.UUID, token string, platform models.PushPlatform) (*models.DeviceToken, error) {
+	var device models.DeviceToken
+	err := ps.db.WithContext(ctx).Unscoped().Where("token = ?", token).First(&device).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up device token: %w", err)
+	}
+
+	// Tokens are unique per device, so a re-registration moves it to the new user
+	device.Token = token
+	device.UserID = userID
+	device.Platform = platform
+	device.DeletedAt = gorm.DeletedAt{}
+
+	if err := ps.db.WithContext(ctx).Unscoped().Save(&device).Error; err != nil {
+		return nil, fmt.Errorf("failed to register device token: %w", err)
+	}
+
+	slog.Info("Registered push device", "user_id", userID, "platform", platform)
+	return &device, nil
+}
+
+// UnregisterDevice removes a device token belonging to the user
+func (ps *PushService) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	result := ps.db.WithContext(ctx).Where("user_id = ? AND token = ?", userID, token).Delete(&models.DeviceToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unregister device token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("device token not found")
+	}
+	return nil
+}
+
+// GetPreferences returns the user's notification preferences, defaulting to all enabled
+func (ps *PushService) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	prefs := models.NotificationPreferences{
+		UserID:            userID,
+		TurnAlerts:        true,
+		TournamentAlerts:  true,
+		WithdrawalUpdates: true,
+		TableUpdates:      true,
+	}
+
+	err := ps.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences applies the provided opt-in changes for the user
+func (ps *PushService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	prefs, err := ps.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TurnAlerts != nil {
+		prefs.TurnAlerts = *req.TurnAlerts
+	}
+	if req.TournamentAlerts != nil {
+		prefs.TournamentAlerts = *req.TournamentAlerts
+	}
+	if req.WithdrawalUpdates != nil {
+		prefs.WithdrawalUpdates = *req.WithdrawalUpdates
+	}
+	if req.TableUpdates != nil {
+		prefs.TableUpdates = *req.TableUpdates
+	}
+
+	// Columns are selected explicitly so false values aren't replaced by column defaults
+	columns := []string{"turn_alerts", "tournament_alerts", "withdrawal_updates", "table_updates"}
+	if prefs.ID == uuid.Nil {
+		err = ps.db.WithContext(ctx).Select(append(columns, "user_id")).Create(prefs).Error
+	} else {
+		err = ps.db.WithContext(ctx).Model(prefs).Select(columns).Updates(prefs).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// Notify sends a push notification to every device of the user if they opted
+// in to the event and the event's rate cap allows it. Delivery problems are
+// logged rather than returned so callers never fail on push.
+func (ps *PushService) Notify(ctx context.Context, userID uuid.UUID, event models.PushEvent, notification PushNotification) {
+	if len(ps.providers) == 0 {
+		return
+	}
+
+	prefs, err := ps.GetPreferences(ctx, userID)
+	if err != nil {
+		slog.Warn("Skipping push, failed to load preferences", "user_id", userID, "event", event, "error", err)
+		return
+	}
+	if !prefs.Allows(event) {
+		return
+	}
+
+	if !ps.allow(userID, event) {
+		slog.Debug("Push rate capped", "user_id", userID, "event", event)
+		return
+	}
+
+	var devices []models.DeviceToken
+	if err := ps.db.WithContext(ctx).Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		slog.Warn("Skipping push, failed to load devices", "user_id", userID, "error", err)
+		return
+	}
+
+	if notification.Data == nil {
+		notification.Data = make(map[string]string)
+	}
+	notification.Data["event"] = string(event)
+
+	for _, device := range devices {
+		provider, ok := ps.providers[device.Platform]
+		if !ok {
+			continue
+		}
+
+		err := provider.Send(ctx, device.Token, notification)
+		if errors.Is(err, ErrInvalidDeviceToken) {
+			slog.Info("Removing invalid push device", "user_id", userID, "platform", device.Platform)
+			ps.db.WithContext(ctx).Delete(&device)
+			continue
+		}
+		if err != nil {
+			slog.Warn("Push delivery failed", "user_id", userID, "platform", device.Platform, "event", event, "error", err)
+			continue
+		}
+
+		now := time.Now()
+		ps.db.WithContext(ctx).Model(&device).Update("last_used_at", &now)
+	}
+}
+
+// NotifyAsync runs Notify in the background so request and game paths aren't delayed
+func (ps *PushService) NotifyAsync(userID uuid.UUID, event models.PushEvent, notification PushNotification) {
+	if ps == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ps.Notify(ctx, userID, event, notification)
+	}()
+}
+
+// allow applies the per-user, per-event rate cap
+func (ps *PushService) allow(userID uuid.UUID, event models.PushEvent) bool {
+	capConfig, ok := pushRateCaps[event]
+	if !ok {
+		return true
+	}
+
+	key := userID.String() + ":" + string(event)
+	limiter, _ := ps.limiters.LoadOrStore(key, rate.NewLimiter(rate.Every(capConfig.every), capConfig.burst))
+	return limiter.(*rate.Limiter).Allow()
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRakeFreeWindowNotFound is returned for a window that doesn't exist
+	ErrRakeFreeWindowNotFound = errors.New("rake-free window not found")
+	// ErrRakeFreeWindowEnded is returned when changing a window that is over
+	ErrRakeFreeWindowEnded = errors.New("rake-free window has ended")
+	// ErrRakeFreeWindowInPast is returned for a window scheduled to end
+	// before it is created
+	ErrRakeFreeWindowInPast = errors.New("rake-free window would already be over")
+)
+
+// rakeFreeCacheTTL bounds how long hands may be settled against an out of
+// date schedule after an admin changes it on another instance
+const rakeFreeCacheTTL = 10 * time.Second
+
+// RakeFreeAnnouncer posts a message in the chat of every table a rake-free
+// window covers. Implemented by the WebSocket hub.
+type RakeFreeAnnouncer interface {
+	AnnounceRakeFree(window models.RakeFreeWindow, message string)
+}
+
+// RakeFreeService schedules rake-free windows, tells settlements whether a
+// hand is rake-free and announces the schedule at the tables it covers
+type RakeFreeService struct {
+	db        *database.DB
+	announcer RakeFreeAnnouncer
+
+	mu       sync.Mutex
+	pending  []models.RakeFreeWindow // Windows not yet over, cached for settlements
+	loadedAt time.Time
+}
+
+func NewRakeFreeService(db *database.DB) *RakeFreeService {
+	return &RakeFreeService{db: db}
+}
+
+// SetAnnouncer lets the service announce schedule changes at the tables
+func (s *RakeFreeService) SetAnnouncer(announcer RakeFreeAnnouncer) {
+	s.announcer = announcer
+}
+
+// Create schedules a rake-free window for a table or stake template
+func (s *RakeFreeService) Create(ctx context.Context, req models.CreateRakeFreeWindowRequest, adminID uuid.UUID, now time.Time) (*models.RakeFreeWindow, error) {
+	if req.TableID != nil {
+		if err := s.db.WithContext(ctx).Select("id").First(&models.PokerTable{}, "id = ?", *req.TableID).Error; err != nil {
+			if database.IsNotFoundError(err) {
+				return nil, ErrTableNotFound
+			}
+			return nil, fmt.Errorf("failed to get table: %w", err)
+		}
+	} else if err := s.db.WithContext(ctx).Select("id").First(&models.StakeTemplate{}, "id = ?", *req.TemplateID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get stake template: %w", err)
+	}
+
+	window := models.RakeFreeWindow{
+		Title:      req.Title,
+		TableID:    req.TableID,
+		TemplateID: req.TemplateID,
+		StartsAt:   req.StartsAt.UTC(),
+		EndsAt:     req.StartsAt.UTC().Add(time.Duration(req.DurationMinutes) * time.Minute),
+		CreatedBy:  adminID,
+	}
+	if !window.EndsAt.After(now) {
+		return nil, ErrRakeFreeWindowInPast
+	}
+	if err := s.db.WithContext(ctx).Create(&window).Error; err != nil {
+		return nil, fmt.Errorf("failed to create rake-free window: %w", err)
+	}
+	s.invalidate()
+
+	slog.Info("Rake-free window scheduled", "window_id", window.ID, "title", window.Title, "table_id", window.TableID, "template_id", window.TemplateID, "starts_at", window.StartsAt, "ends_at", window.EndsAt, "admin_id", adminID)
+	if window.StatusAt(now) == models.RakeFreeStatusScheduled {
+		s.announce(window, fmt.Sprintf("%s: no rake will be taken from %s.", window.Title, rakeFreeSpan(window)))
+	}
+	return &window, nil
+}
+
+// Update reschedules or renames a window that has not ended. A window moved
+// so that it starts or ends is announced on the next Enforce.
+func (s *RakeFreeService) Update(ctx context.Context, id uuid.UUID, req models.UpdateRakeFreeWindowRequest, adminID uuid.UUID, now time.Time) (*models.RakeFreeWindow, error) {
+	window, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if window.StatusAt(now) == models.RakeFreeStatusEnded {
+		return nil, ErrRakeFreeWindowEnded
+	}
+
+	if req.Title != nil {
+		window.Title = *req.Title
+	}
+	duration := window.EndsAt.Sub(window.StartsAt)
+	if req.DurationMinutes != nil {
+		duration = time.Duration(*req.DurationMinutes) * time.Minute
+	}
+	if req.StartsAt != nil {
+		window.StartsAt = req.StartsAt.UTC()
+	}
+	window.EndsAt = window.StartsAt.Add(duration)
+	if !window.EndsAt.After(now) {
+		return nil, ErrRakeFreeWindowInPast
+	}
+	// A window moved back into the future starts again
+	if window.StatusAt(now) == models.RakeFreeStatusScheduled {
+		window.StartedAt = nil
+	}
+
+	if err := s.db.WithContext(ctx).Save(window).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rake-free window: %w", err)
+	}
+	s.invalidate()
+
+	slog.Info("Rake-free window changed", "window_id", window.ID, "starts_at", window.StartsAt, "ends_at", window.EndsAt, "admin_id", adminID)
+	if req.StartsAt != nil || req.DurationMinutes != nil {
+		s.announce(*window, fmt.Sprintf("%s has been moved: no rake will be taken from %s.", window.Title, rakeFreeSpan(*window)))
+	}
+	return window, nil
+}
+
+// Delete cancels a window, ending it at once if it has started
+func (s *RakeFreeService) Delete(ctx context.Context, id uuid.UUID, adminID uuid.UUID, now time.Time) error {
+	window, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(ctx).Delete(window).Error; err != nil {
+		return fmt.Errorf("failed to delete rake-free window: %w", err)
+	}
+	s.invalidate()
+
+	slog.Info("Rake-free window cancelled", "window_id", id, "admin_id", adminID)
+	switch window.StatusAt(now) {
+	case models.RakeFreeStatusScheduled:
+		s.announce(*window, fmt.Sprintf("%s has been cancelled.", window.Title))
+	case models.RakeFreeStatusActive:
+		s.announce(*window, fmt.Sprintf("%s has been cancelled. Rake applies again from the next hand.", window.Title))
+	}
+	return nil
+}
+
+// Get returns a window
+func (s *RakeFreeService) Get(ctx context.Context, id uuid.UUID) (*models.RakeFreeWindow, error) {
+	var window models.RakeFreeWindow
+	if err := s.db.WithContext(ctx).First(&window, "id = ?", id).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrRakeFreeWindowNotFound
+		}
+		return nil, fmt.Errorf("failed to get rake-free window: %w", err)
+	}
+	return &window, nil
+}
+
+// List returns windows ending after since, soonest first
+func (s *RakeFreeService) List(ctx context.Context, since time.Time) ([]models.RakeFreeWindow, error) {
+	var windows []models.RakeFreeWindow
+	if err := s.db.WithContext(ctx).Where("ends_at > ?", since).Order("starts_at ASC").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list rake-free windows: %w", err)
+	}
+	return windows, nil
+}
+
+// Active returns the window making hands at a table rake-free at now, or
+// nil. If the schedule can't be read the last one loaded is used.
+func (s *RakeFreeService) Active(ctx context.Context, tableID uuid.UUID, templateID *uuid.UUID, now time.Time) *models.RakeFreeWindow {
+	for _, window := range s.pendingWindows(ctx, now) {
+		if window.ActiveAt(now) && window.Covers(tableID, templateID) {
+			return &window
+		}
+	}
+	return nil
+}
+
+func (s *RakeFreeService) pendingWindows(ctx context.Context, now time.Time) []models.RakeFreeWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loadedAt.IsZero() || time.Since(s.loadedAt) > rakeFreeCacheTTL {
+		var windows []models.RakeFreeWindow
+		if err := s.db.WithContext(ctx).Where("ends_at > ?", now).Order("starts_at ASC").Find(&windows).Error; err != nil {
+			slog.Warn("Failed to load rake-free windows", "error", err)
+			return s.pending
+		}
+		s.pending = windows
+		s.loadedAt = time.Now()
+	}
+	return s.pending
+}
+
+func (s *RakeFreeService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// Enforce announces windows starting and ending at the tables they cover,
+// recording each so it is announced once
+func (s *RakeFreeService) Enforce(ctx context.Context, now time.Time) error {
+	var windows []models.RakeFreeWindow
+	if err := s.db.WithContext(ctx).Where("ended_at IS NULL AND starts_at <= ?", now).Find(&windows).Error; err != nil {
+		return fmt.Errorf("failed to load rake-free windows: %w", err)
+	}
+
+	for _, window := range windows {
+		switch {
+		case window.ActiveAt(now) && window.StartedAt == nil:
+			if err := s.db.WithContext(ctx).Model(&window).Update("started_at", now).Error; err != nil {
+				return fmt.Errorf("failed to mark rake-free window started: %w", err)
+			}
+			slog.Info("Rake-free window started", "window_id", window.ID, "title", window.Title, "ends_at", window.EndsAt)
+			s.announce(window, fmt.Sprintf("%s has started: no rake is taken until %s.", window.Title, window.EndsAt.UTC().Format("15:04 UTC")))
+		case !window.ActiveAt(now):
+			if err := s.db.WithContext(ctx).Model(&window).Update("ended_at", now).Error; err != nil {
+				return fmt.Errorf("failed to mark rake-free window ended: %w", err)
+			}
+			// Windows that passed while the server was down end quietly
+			if window.StartedAt == nil {
+				continue
+			}
+			slog.Info("Rake-free window ended", "window_id", window.ID, "title", window.Title)
+			s.announce(window, fmt.Sprintf("%s is over. Rake applies again from the next hand.", window.Title))
+		}
+	}
+	return nil
+}
+
+func (s *RakeFreeService) announce(window models.RakeFreeWindow, message string) {
+	if s.announcer != nil {
+		s.announcer.AnnounceRakeFree(window, message)
+	}
+}
+
+// rakeFreeSpan describes when a window runs, for announcements
+func rakeFreeSpan(window models.RakeFreeWindow) string {
+	end := window.EndsAt.UTC().Format("15:04 UTC")
+	if window.EndsAt.UTC().YearDay() != window.StartsAt.UTC().YearDay() {
+		end = window.EndsAt.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	return fmt.Sprintf("%s to %s", window.StartsAt.UTC().Format("2006-01-02 15:04"), end)
+}
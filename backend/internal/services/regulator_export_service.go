@@ -0,0 +1,528 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+var ErrRegulatorExportInvalid = errors.New("regulator export signature or checksums do not match")
+
+// Files in a regulator export archive besides the CSVs
+const (
+	regulatorManifestFile  = "manifest.json"
+	regulatorSignatureFile = "manifest.json.sig"
+)
+
+// regulatorQueryBatch is how many hand IDs or users go in one IN query
+const regulatorQueryBatch = 1000
+
+// RegulatorExportScope is whose betting an export covers, a player's or a
+// table's, and the period: hands and sessions that started in [From, To)
+type RegulatorExportScope struct {
+	UserID  *uuid.UUID
+	TableID *uuid.UUID
+	From    time.Time
+	To      time.Time
+}
+
+// RegulatorExport is a finished export: its CSV files, the manifest listing
+// them and the manifest's signature
+type RegulatorExport struct {
+	Manifest  []byte
+	Signature string
+	Files     map[string][]byte
+}
+
+// RegulatorExportService builds signed CSV reports of a player's or a
+// table's betting for regulators. Exports are signed with an Ed25519 key
+// whose public half is all a regulator needs to verify them.
+type RegulatorExportService struct {
+	db         *database.DB
+	signingKey ed25519.PrivateKey
+}
+
+// NewRegulatorExportService signs exports with a PEM encoded PKCS #8 Ed25519
+// private key, as made by openssl genpkey -algorithm ed25519
+func NewRegulatorExportService(db *database.DB, privateKeyPEM string) (*RegulatorExportService, error) {
+	key, err := ParseRegulatorExportKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &RegulatorExportService{db: db, signingKey: key}, nil
+}
+
+// ParseRegulatorExportKey reads a PEM encoded PKCS #8 Ed25519 private key
+func ParseRegulatorExportKey(privateKeyPEM string) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("regulator export key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse regulator export key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("regulator export key is not an Ed25519 key")
+	}
+	return key, nil
+}
+
+// PublicKeyPEM returns the PEM encoded public key that verifies the exports,
+// for publishing to regulators
+func (rs *RegulatorExportService) PublicKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(rs.signingKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode regulator export public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Export reports every wager and its result, the rake paid, deposits and
+// withdrawals and table sessions in the scope, one CSV for each. For a
+// table, the wallet movements are those of everyone who sat there.
+func (rs *RegulatorExportService) Export(ctx context.Context, scope RegulatorExportScope, generatedBy uuid.UUID) (*RegulatorExport, error) {
+	hands, err := rs.hands(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	rake, err := rs.rakePaid(ctx, hands)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := rs.sessions(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everyone in the report, for names and wallet movements
+	var userIDs []uuid.UUID
+	if scope.UserID != nil {
+		userIDs = []uuid.UUID{*scope.UserID}
+	} else {
+		for _, session := range sessions {
+			userIDs = append(userIDs, session.UserID)
+		}
+		for _, hand := range hands {
+			for _, player := range hand.DealtPlayers() {
+				userIDs = append(userIDs, player.UserID)
+			}
+		}
+		slices.SortFunc(userIDs, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+		userIDs = slices.Compact(userIDs)
+	}
+	usernames, err := rs.usernames(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := rs.walletOperations(ctx, userIDs, scope)
+	if err != nil {
+		return nil, err
+	}
+	tableNames, err := rs.tableNames(ctx, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := models.RegulatorExportManifest{
+		ExportID:    uuid.New(),
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: generatedBy,
+		UserID:      scope.UserID,
+		TableID:     scope.TableID,
+		From:        scope.From.UTC(),
+		To:          scope.To.UTC(),
+	}
+	export, err := SealRegulatorExport(rs.signingKey, manifest, map[string][][]string{
+		"wagers.csv":   wagerRows(hands, rake, scope.UserID),
+		"wallet.csv":   walletRows(wallet, usernames),
+		"sessions.csv": sessionRows(sessions, usernames, tableNames),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Regulator export generated",
+		"export_id", manifest.ExportID,
+		"generated_by", generatedBy,
+		"user_id", scope.UserID,
+		"table_id", scope.TableID,
+		"from", scope.From,
+		"to", scope.To)
+	return export, nil
+}
+
+// hands returns the finished hands in scope, oldest first. For a player,
+// only hands they were dealt into count.
+func (rs *RegulatorExportService) hands(ctx context.Context, scope RegulatorExportScope) ([]models.HandHistory, error) {
+	query := rs.db.WithContext(ctx).Model(&models.HandHistory{}).
+		Where("hand_histories.ended_at IS NOT NULL").
+		Where("hand_histories.started_at >= ? AND hand_histories.started_at < ?", scope.From, scope.To)
+	if scope.UserID != nil {
+		query = query.Joins("JOIN hand_presences ON hand_presences.hand_id = hand_histories.hand_id").
+			Where("hand_presences.user_id = ?", *scope.UserID)
+	}
+	if scope.TableID != nil {
+		query = query.Where("hand_histories.table_id = ?", *scope.TableID)
+	}
+
+	var histories []models.HandHistory
+	if err := query.Order("hand_histories.started_at ASC").Find(&histories).Error; err != nil {
+		return nil, fmt.Errorf("failed to export hands: %w", err)
+	}
+	if scope.UserID == nil {
+		return histories, nil
+	}
+
+	// Being at the table isn't enough, they had to be dealt in
+	return slices.DeleteFunc(histories, func(h models.HandHistory) bool {
+		return !slices.ContainsFunc(h.DealtPlayers(), func(p models.HandPlayerCards) bool {
+			return p.UserID == *scope.UserID
+		})
+	}), nil
+}
+
+// rakePaid totals the rake each player paid in each hand, by hand ID
+func (rs *RegulatorExportService) rakePaid(ctx context.Context, hands []models.HandHistory) (map[string]map[uuid.UUID]int64, error) {
+	handIDs := make([]string, len(hands))
+	for i, hand := range hands {
+		handIDs[i] = hand.HandID
+	}
+
+	rake := make(map[string]map[uuid.UUID]int64)
+	for batch := range slices.Chunk(handIDs, regulatorQueryBatch) {
+		var contributions []models.RakeContribution
+		if err := rs.db.WithContext(ctx).Where("hand_id IN ?", batch).Find(&contributions).Error; err != nil {
+			return nil, fmt.Errorf("failed to export rake: %w", err)
+		}
+		for _, c := range contributions {
+			if rake[c.HandID] == nil {
+				rake[c.HandID] = make(map[uuid.UUID]int64)
+			}
+			rake[c.HandID][c.UserID] += c.Amount
+		}
+	}
+	return rake, nil
+}
+
+// sessions returns the table sessions in scope that started before the end
+// of the period and were still open at its start, oldest first
+func (rs *RegulatorExportService) sessions(ctx context.Context, scope RegulatorExportScope) ([]models.GameSession, error) {
+	query := rs.db.WithContext(ctx).
+		Where("joined_at < ? AND (left_at IS NULL OR left_at >= ?)", scope.To, scope.From)
+	if scope.UserID != nil {
+		query = query.Where("user_id = ?", *scope.UserID)
+	}
+	if scope.TableID != nil {
+		query = query.Where("table_id = ?", *scope.TableID)
+	}
+
+	var sessions []models.GameSession
+	if err := query.Order("joined_at ASC").Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to export sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// walletOperations returns the users' deposits and withdrawals in the
+// period, oldest first
+func (rs *RegulatorExportService) walletOperations(ctx context.Context, userIDs []uuid.UUID, scope RegulatorExportScope) ([]models.WalletOperation, error) {
+	var operations []models.WalletOperation
+	for batch := range slices.Chunk(userIDs, regulatorQueryBatch) {
+		var found []models.WalletOperation
+		err := rs.db.WithContext(ctx).
+			Where("user_id IN ? AND created_at >= ? AND created_at < ?", batch, scope.From, scope.To).
+			Find(&found).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to export wallet operations: %w", err)
+		}
+		operations = append(operations, found...)
+	}
+	slices.SortStableFunc(operations, func(a, b models.WalletOperation) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return operations, nil
+}
+
+// usernames looks up the users' names, including deleted accounts
+func (rs *RegulatorExportService) usernames(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	names := make(map[uuid.UUID]string, len(userIDs))
+	for batch := range slices.Chunk(userIDs, regulatorQueryBatch) {
+		var users []models.User
+		if err := rs.db.WithContext(ctx).Unscoped().Select("id", "username").Where("id IN ?", batch).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up users: %w", err)
+		}
+		for _, user := range users {
+			names[user.ID] = user.Username
+		}
+	}
+	return names, nil
+}
+
+// tableNames looks up the names of the sessions' tables, including closed ones
+func (rs *RegulatorExportService) tableNames(ctx context.Context, sessions []models.GameSession) (map[uuid.UUID]string, error) {
+	var tableIDs []uuid.UUID
+	for _, session := range sessions {
+		if !slices.Contains(tableIDs, session.TableID) {
+			tableIDs = append(tableIDs, session.TableID)
+		}
+	}
+
+	names := make(map[uuid.UUID]string, len(tableIDs))
+	for batch := range slices.Chunk(tableIDs, regulatorQueryBatch) {
+		var tables []models.PokerTable
+		if err := rs.db.WithContext(ctx).Unscoped().Select("id", "name").Where("id IN ?", batch).Find(&tables).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up tables: %w", err)
+		}
+		for _, table := range tables {
+			names[table.ID] = table.Name
+		}
+	}
+	return names, nil
+}
+
+// wagerRows is a row for every player dealt into each hand, or only the
+// player when the export is for one. Won is what they were paid after rake,
+// so net is won less wagered.
+func wagerRows(hands []models.HandHistory, rake map[string]map[uuid.UUID]int64, userID *uuid.UUID) [][]string {
+	rows := [][]string{{
+		"hand_id", "table_id", "table_name", "started_at", "ended_at",
+		"user_id", "username", "seat", "wagered", "won", "rake_paid", "net",
+	}}
+	for _, hand := range hands {
+		won := make(map[uuid.UUID]int64)
+		for _, winner := range hand.PotWinners() {
+			won[winner.UserID] += winner.Amount
+		}
+		for _, player := range hand.DealtPlayers() {
+			if userID != nil && player.UserID != *userID {
+				continue
+			}
+			rows = append(rows, []string{
+				hand.HandID,
+				hand.TableID.String(),
+				hand.TableName,
+				formatExportTime(&hand.StartedAt),
+				formatExportTime(hand.EndedAt),
+				player.UserID.String(),
+				player.Username,
+				strconv.FormatUint(uint64(player.SeatID), 10),
+				strconv.FormatInt(player.Wagered, 10),
+				strconv.FormatInt(won[player.UserID], 10),
+				strconv.FormatInt(rake[hand.HandID][player.UserID], 10),
+				strconv.FormatInt(won[player.UserID]-player.Wagered, 10),
+			})
+		}
+	}
+	return rows
+}
+
+// walletRows is a row for each deposit and withdrawal
+func walletRows(operations []models.WalletOperation, usernames map[uuid.UUID]string) [][]string {
+	rows := [][]string{{"created_at", "user_id", "username", "operation", "amount", "transaction_id"}}
+	for _, op := range operations {
+		rows = append(rows, []string{
+			formatExportTime(&op.CreatedAt),
+			op.UserID.String(),
+			usernames[op.UserID],
+			op.Operation,
+			strconv.FormatInt(op.Amount, 10),
+			op.TransactionID,
+		})
+	}
+	return rows
+}
+
+// sessionRows is a row for each table session. Sessions still open have no
+// left_at and are timed up to now.
+func sessionRows(sessions []models.GameSession, usernames, tableNames map[uuid.UUID]string) [][]string {
+	rows := [][]string{{
+		"session_id", "user_id", "username", "table_id", "table_name", "seat",
+		"joined_at", "left_at", "duration_seconds", "buy_in", "status",
+	}}
+	now := time.Now()
+	for _, session := range sessions {
+		seat := ""
+		if session.SeatNumber != nil {
+			seat = strconv.Itoa(*session.SeatNumber)
+		}
+		left := now
+		if session.LeftAt != nil {
+			left = *session.LeftAt
+		}
+		rows = append(rows, []string{
+			session.ID.String(),
+			session.UserID.String(),
+			usernames[session.UserID],
+			session.TableID.String(),
+			tableNames[session.TableID],
+			seat,
+			formatExportTime(&session.JoinedAt),
+			formatExportTime(session.LeftAt),
+			strconv.FormatInt(int64(left.Sub(session.JoinedAt).Seconds()), 10),
+			strconv.FormatInt(session.BuyInAmount, 10),
+			string(session.Status),
+		})
+	}
+	return rows
+}
+
+// formatExportTime writes times in UTC, and nothing for times not yet reached
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// SealRegulatorExport writes each table of rows, header first, as a CSV
+// file, lists the files with their checksums in the manifest and signs it
+// with the key
+func SealRegulatorExport(key ed25519.PrivateKey, manifest models.RegulatorExportManifest, tables map[string][][]string) (*RegulatorExport, error) {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	export := &RegulatorExport{Files: make(map[string][]byte, len(tables))}
+	manifest.Currency = "MNT"
+	manifest.SignatureAlgorithm = models.RegulatorExportSignatureAlgorithm
+	fingerprint, err := publicKeyFingerprint(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	manifest.PublicKeySHA256 = fingerprint
+	manifest.Files = make([]models.RegulatorExportFile, 0, len(names))
+	for _, name := range names {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(tables[name]); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		export.Files[name] = buf.Bytes()
+
+		sum := sha256.Sum256(buf.Bytes())
+		manifest.Files = append(manifest.Files, models.RegulatorExportFile{
+			Name:   name,
+			Rows:   max(len(tables[name])-1, 0),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export manifest: %w", err)
+	}
+	export.Manifest = data
+	export.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return export, nil
+}
+
+// WriteZip writes the export as a zip archive of the CSV files, the
+// manifest and its signature
+func (e *RegulatorExport) WriteZip(w io.Writer) error {
+	names := make([]string, 0, len(e.Files))
+	for name := range e.Files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	archive := zip.NewWriter(w)
+	write := func(name string, data []byte) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	for _, name := range names {
+		if err := write(name, e.Files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := write(regulatorManifestFile, e.Manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := write(regulatorSignatureFile, []byte(e.Signature+"\n")); err != nil {
+		return fmt.Errorf("failed to write manifest signature: %w", err)
+	}
+	return archive.Close()
+}
+
+// VerifyRegulatorExport checks an export archive's manifest signature with
+// the public key and every listed file against its checksum, returning the
+// manifest when they all match
+func VerifyRegulatorExport(key ed25519.PublicKey, archive io.ReaderAt, size int64) (*models.RegulatorExportManifest, error) {
+	r, err := zip.NewReader(archive, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	files := make(map[string][]byte, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		files[f.Name] = data
+	}
+
+	data := files[regulatorManifestFile]
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(files[regulatorSignatureFile])))
+	if err != nil || !ed25519.Verify(key, data, signature) {
+		return nil, ErrRegulatorExportInvalid
+	}
+
+	var manifest models.RegulatorExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse export manifest: %w", err)
+	}
+	for _, listed := range manifest.Files {
+		content, ok := files[listed.Name]
+		if !ok {
+			return nil, ErrRegulatorExportInvalid
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != listed.SHA256 {
+			return nil, ErrRegulatorExportInvalid
+		}
+	}
+	return &manifest, nil
+}
+
+// publicKeyFingerprint is the hex SHA-256 of the public key's DER encoding,
+// naming the key an export was signed with
+func publicKeyFingerprint(key ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode regulator export public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
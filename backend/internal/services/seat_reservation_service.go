@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSeatReservationNotFound = errors.New("seat reservation not found")
+	// ErrSeatReserved is returned to a player taking a seat held for
+	// someone else
+	ErrSeatReserved = errors.New("seat is reserved for another player")
+	// ErrSeatsReserved is returned when every free seat at a table is held
+	ErrSeatsReserved = errors.New("the remaining seats at this table are reserved")
+	// ErrSeatAlreadyReserved is returned when reserving a seat that is
+	// already held
+	ErrSeatAlreadyReserved = errors.New("seat is already reserved")
+	// ErrPlayerAlreadyReserved is returned when reserving a second seat at a
+	// table for the same player
+	ErrPlayerAlreadyReserved = errors.New("player already has a seat reserved at this table")
+	// ErrSeatOutOfRange is returned for a seat number the table doesn't have
+	ErrSeatOutOfRange = errors.New("table has no such seat")
+)
+
+// SeatReservationService holds seats at tables for named players. A
+// reservation is released when the player sits down, when an admin cancels
+// it, or once it expires.
+type SeatReservationService struct {
+	db *database.DB
+}
+
+func NewSeatReservationService(db *database.DB) *SeatReservationService {
+	return &SeatReservationService{db: db}
+}
+
+// Create reserves a seat at a table for a player
+func (srs *SeatReservationService) Create(ctx context.Context, tableID uuid.UUID, req models.CreateSeatReservationRequest, adminID uuid.UUID, now time.Time) (*models.SeatReservation, error) {
+	var table models.PokerTable
+	if err := srs.db.WithContext(ctx).Select("id", "max_players").First(&table, "id = ?", tableID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to get table: %w", err)
+	}
+	if req.SeatNumber > table.MaxPlayers {
+		return nil, ErrSeatOutOfRange
+	}
+
+	var user models.User
+	if err := srs.db.WithContext(ctx).Select("id", "username").First(&user, "id = ?", req.UserID).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	reservation := &models.SeatReservation{
+		TableID:    tableID,
+		SeatNumber: req.SeatNumber,
+		UserID:     req.UserID,
+		Username:   user.Username,
+		Note:       req.Note,
+		ExpiresAt:  now.Add(time.Duration(req.ExpiresInMinutes) * time.Minute),
+		CreatedBy:  adminID,
+	}
+	err := srs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var held []models.SeatReservation
+		if err := heldReservations(tx, tableID, now).Find(&held).Error; err != nil {
+			return fmt.Errorf("failed to load seat reservations: %w", err)
+		}
+		for _, other := range held {
+			if other.SeatNumber == req.SeatNumber {
+				return ErrSeatAlreadyReserved
+			}
+			if other.UserID == req.UserID {
+				return ErrPlayerAlreadyReserved
+			}
+		}
+		if err := tx.Create(reservation).Error; err != nil {
+			return fmt.Errorf("failed to create seat reservation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Seat reserved", "reservation_id", reservation.ID, "table_id", tableID, "seat", req.SeatNumber, "user_id", req.UserID, "expires_at", reservation.ExpiresAt, "admin_id", adminID)
+	return reservation, nil
+}
+
+// Cancel releases a reservation before it is claimed or expires
+func (srs *SeatReservationService) Cancel(ctx context.Context, id, adminID uuid.UUID, now time.Time) error {
+	var reservation models.SeatReservation
+	if err := srs.db.WithContext(ctx).First(&reservation, "id = ?", id).Error; err != nil {
+		if database.IsNotFoundError(err) {
+			return ErrSeatReservationNotFound
+		}
+		return fmt.Errorf("failed to get seat reservation: %w", err)
+	}
+	if !reservation.HeldAt(now) {
+		return ErrSeatReservationNotFound
+	}
+
+	if err := srs.release(srs.db.WithContext(ctx).Where("id = ?", id), models.SeatReservationCancelled, now); err != nil {
+		return err
+	}
+	slog.Info("Seat reservation cancelled", "reservation_id", id, "table_id", reservation.TableID, "seat", reservation.SeatNumber, "admin_id", adminID)
+	return nil
+}
+
+// List returns the reservations still holding seats at a table, by seat
+func (srs *SeatReservationService) List(ctx context.Context, tableID uuid.UUID, now time.Time) ([]models.SeatReservation, error) {
+	var reservations []models.SeatReservation
+	if err := heldReservations(srs.db.WithContext(ctx), tableID, now).Order("seat_number ASC").Find(&reservations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list seat reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+// Seats returns the seat held for userID at a table, 0 if none, and the
+// seats held for everyone else
+func (srs *SeatReservationService) Seats(ctx context.Context, tableID, userID uuid.UUID, now time.Time) (int, map[int]bool, error) {
+	reservations, err := srs.List(ctx, tableID, now)
+	if err != nil {
+		return 0, nil, err
+	}
+	own, others := ReservedSeats(reservations, userID)
+	return own, others, nil
+}
+
+// CheckJoin refuses userID a place at a table whose free seats are all held
+// for other players. occupied is how many seats are taken.
+func (srs *SeatReservationService) CheckJoin(ctx context.Context, table *models.PokerTable, userID uuid.UUID, occupied int, now time.Time) error {
+	own, others, err := srs.Seats(ctx, table.ID, userID, now)
+	if err != nil {
+		return err
+	}
+	if own == 0 && occupied+len(others) >= table.MaxPlayers {
+		return ErrSeatsReserved
+	}
+	return nil
+}
+
+// Claim releases the seat held for userID at a table once they sit down
+func (srs *SeatReservationService) Claim(ctx context.Context, tableID, userID uuid.UUID, now time.Time) error {
+	query := heldReservations(srs.db.WithContext(ctx), tableID, now).Where("user_id = ?", userID)
+	return srs.release(query, models.SeatReservationClaimed, now)
+}
+
+// Expire releases reservations whose players didn't arrive in time,
+// returning how many there were
+func (srs *SeatReservationService) Expire(ctx context.Context, now time.Time) (int, error) {
+	result := srs.db.WithContext(ctx).Model(&models.SeatReservation{}).
+		Where("released_at IS NULL AND expires_at <= ?", now).
+		Updates(map[string]interface{}{"released_at": now, "release_reason": models.SeatReservationExpired})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire seat reservations: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("Seat reservations expired", "count", result.RowsAffected)
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (srs *SeatReservationService) release(query *gorm.DB, reason string, now time.Time) error {
+	err := query.Model(&models.SeatReservation{}).
+		Updates(map[string]interface{}{"released_at": now, "release_reason": reason}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release seat reservation: %w", err)
+	}
+	return nil
+}
+
+// ReservedSeats splits held reservations into the seat held for userID, 0
+// if none, and the seats held for everyone else
+func ReservedSeats(reservations []models.SeatReservation, userID uuid.UUID) (int, map[int]bool) {
+	own := 0
+	others := make(map[int]bool)
+	for _, reservation := range reservations {
+		if reservation.UserID == userID {
+			own = reservation.SeatNumber
+		} else {
+			others[reservation.SeatNumber] = true
+		}
+	}
+	return own, others
+}
+
+// heldReservations selects the reservations holding seats at a table at now
+func heldReservations(db *gorm.DB, tableID uuid.UUID, now time.Time) *gorm.DB {
+	return db.Where("table_id = ? AND released_at IS NULL AND expires_at > ?", tableID, now)
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoEmptySeat        = errors.New("no empty seat available")
+	ErrSeparationNotFound = errors.New("seating separation not found")
+)
+
+// SeatingService assigns seats server-side and keeps flagged player pairs
+// apart. Randomness comes from crypto/rand so seat draws can't be predicted.
+type SeatingService struct {
+	db *database.DB
+}
+
+// NewSeatingService creates a new seating service
+func NewSeatingService(db *database.DB) *SeatingService {
+	return &SeatingService{db: db}
+}
+
+// FlagPair records that two players must not be seated together. Flagging an
+// existing pair again updates its reason and expiry.
+func (ss *SeatingService) FlagPair(ctx context.Context, req models.FlagSeatingPairRequest, flaggedBy *uuid.UUID) (*models.SeatingSeparation, error) {
+	userA, userB := orderedPair(req.UserAID, req.UserBID)
+	source := req.Source
+	if source == "" {
+		source = models.SeparationSourceFraud
+	}
+
+	var separation models.SeatingSeparation
+	err := ss.db.WithContext(ctx).
+		Where("user_a_id = ? AND user_b_id = ?", userA, userB).
+		Assign(models.SeatingSeparation{Source: source, Reason: req.Reason, FlaggedBy: flaggedBy, ExpiresAt: req.ExpiresAt}).
+		FirstOrCreate(&separation, models.SeatingSeparation{UserAID: userA, UserBID: userB}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag seating pair: %w", err)
+	}
+
+	slog.Info("Seating pair flagged", "separation_id", separation.ID, "user_a_id", userA, "user_b_id", userB, "source", source)
+	return &separation, nil
+}
+
+// RemovePair lifts a seating separation
+func (ss *SeatingService) RemovePair(ctx context.Context, id uuid.UUID) error {
+	result := ss.db.WithContext(ctx).Delete(&models.SeatingSeparation{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove seating separation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSeparationNotFound
+	}
+	return nil
+}
+
+// ListPairs returns the active seating separations, newest first
+func (ss *SeatingService) ListPairs(ctx context.Context, limit, offset int) ([]models.SeatingSeparation, int64, error) {
+	query := ss.db.WithContext(ctx).Model(&models.SeatingSeparation{}).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now())
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count seating separations: %w", err)
+	}
+
+	var separations []models.SeatingSeparation
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&separations).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list seating separations: %w", err)
+	}
+	return separations, total, nil
+}
+
+// SeparatedFrom returns which of others the user is flagged to be kept apart from
+func (ss *SeatingService) SeparatedFrom(ctx context.Context, userID uuid.UUID, others []uuid.UUID) ([]uuid.UUID, error) {
+	if len(others) == 0 {
+		return nil, nil
+	}
+
+	pairs, err := ss.activePairsAmong(ctx, append([]uuid.UUID{userID}, others...))
+	if err != nil {
+		return nil, err
+	}
+
+	var separated []uuid.UUID
+	for _, other := range others {
+		if pairs.contains(userID, other) {
+			separated = append(separated, other)
+		}
+	}
+	return separated, nil
+}
+
+// TablePolicy returns the seat selection mode and separation setting of the
+// table with the given name. Tables without a database record use free seat
+// choice and no separation.
+func (ss *SeatingService) TablePolicy(ctx context.Context, tableName string) (string, bool, error) {
+	var table models.PokerTable
+	err := ss.db.WithContext(ctx).Select("seat_selection", "enforce_separation").First(&table, "name = ?", tableName).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.SeatSelectionChoice, false, nil
+		}
+		return "", false, fmt.Errorf("failed to get table seating policy: %w", err)
+	}
+
+	if table.SeatSelection == "" {
+		table.SeatSelection = models.SeatSelectionChoice
+	}
+	return table.SeatSelection, table.EnforceSeparation, nil
+}
+
+// UpdateTablePolicy changes how seats are assigned at a table
+func (ss *SeatingService) UpdateTablePolicy(ctx context.Context, tableID uuid.UUID, req models.UpdateSeatingPolicyRequest) (*models.PokerTable, error) {
+	var table models.PokerTable
+	if err := ss.db.WithContext(ctx).First(&table, "id = ?", tableID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTableNotFound
+		}
+		return nil, fmt.Errorf("failed to get table: %w", err)
+	}
+
+	err := ss.db.WithContext(ctx).Model(&table).Updates(map[string]interface{}{
+		"seat_selection":     req.SeatSelection,
+		"enforce_separation": req.EnforceSeparation,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update table seating policy: %w", err)
+	}
+	return &table, nil
+}
+
+// AssignTournamentSeats draws tables and seats for every registered player,
+// keeping flagged pairs at different tables where the field allows it, and
+// stores the draw on the registrations
+func (ss *SeatingService) AssignTournamentSeats(ctx context.Context, tournamentID uuid.UUID, tableSize int) ([]models.SeatAssignment, error) {
+	var registrations []models.TournamentRegistration
+	if err := ss.db.WithContext(ctx).Where("tournament_id = ?", tournamentID).Find(&registrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tournament registrations: %w", err)
+	}
+
+	players := make([]uuid.UUID, len(registrations))
+	for i, registration := range registrations {
+		players[i] = registration.UserID
+	}
+
+	pairs, err := ss.activePairsAmong(ctx, players)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := DrawSeats(players, tableSize, pairs.contains)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, assignment := range assignments {
+			tableNumber, seatNumber := assignment.TableNumber, assignment.SeatNumber
+			err := tx.Model(&models.TournamentRegistration{}).
+				Where("tournament_id = ? AND user_id = ?", tournamentID, assignment.UserID).
+				Updates(map[string]interface{}{"table_number": tableNumber, "seat_number": seatNumber}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store tournament seat draw: %w", err)
+	}
+
+	slog.Info("Tournament seats drawn", "tournament_id", tournamentID, "players", len(assignments), "separated_pairs", len(pairs))
+	return assignments, nil
+}
+
+// separationSet holds flagged pairs keyed by their ordered IDs
+type separationSet map[[2]uuid.UUID]bool
+
+func (s separationSet) contains(a, b uuid.UUID) bool {
+	first, second := orderedPair(a, b)
+	return s[[2]uuid.UUID{first, second}]
+}
+
+// activePairsAmong loads the unexpired separations where both players are in users
+func (ss *SeatingService) activePairsAmong(ctx context.Context, users []uuid.UUID) (separationSet, error) {
+	pairs := make(separationSet)
+	if len(users) < 2 {
+		return pairs, nil
+	}
+
+	var separations []models.SeatingSeparation
+	err := ss.db.WithContext(ctx).
+		Where("user_a_id IN ? AND user_b_id IN ?", users, users).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&separations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seating separations: %w", err)
+	}
+
+	for _, separation := range separations {
+		pairs[[2]uuid.UUID{separation.UserAID, separation.UserBID}] = true
+	}
+	return pairs, nil
+}
+
+func orderedPair(a, b uuid.UUID) (uuid.UUID, uuid.UUID) {
+	if a.String() > b.String() {
+		return b, a
+	}
+	return a, b
+}
+
+// RandomSeat picks a uniformly random empty seat numbered 1..maxSeats
+func RandomSeat(occupied map[int]bool, maxSeats int) (int, error) {
+	var empty []int
+	for seat := 1; seat <= maxSeats; seat++ {
+		if !occupied[seat] {
+			empty = append(empty, seat)
+		}
+	}
+	if len(empty) == 0 {
+		return 0, ErrNoEmptySeat
+	}
+
+	i, err := randomIndex(len(empty))
+	if err != nil {
+		return 0, err
+	}
+	return empty[i], nil
+}
+
+// DrawSeats splits players across as few tables of tableSize as possible, with
+// table sizes differing by at most one, and shuffles everyone into random
+// seats. Players for whom separated reports true are put at different tables
+// when there is room to do so.
+func DrawSeats(players []uuid.UUID, tableSize int, separated func(a, b uuid.UUID) bool) ([]models.SeatAssignment, error) {
+	if tableSize < 2 {
+		return nil, fmt.Errorf("table size must be at least 2, got %d", tableSize)
+	}
+	if len(players) == 0 {
+		return nil, nil
+	}
+
+	order := append([]uuid.UUID(nil), players...)
+	if err := shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] }); err != nil {
+		return nil, err
+	}
+
+	// Place the most constrained players first so their partners still have
+	// other tables to go to. The sort is stable, so order stays random otherwise.
+	if separated != nil {
+		partners := make(map[uuid.UUID]int, len(order))
+		for i := range order {
+			for j := i + 1; j < len(order); j++ {
+				if separated(order[i], order[j]) {
+					partners[order[i]]++
+					partners[order[j]]++
+				}
+			}
+		}
+		sort.SliceStable(order, func(i, j int) bool { return partners[order[i]] > partners[order[j]] })
+	}
+
+	tableCount := (len(order) + tableSize - 1) / tableSize
+	capacity := (len(order) + tableCount - 1) / tableCount
+	tables := make([][]uuid.UUID, tableCount)
+
+	for _, player := range order {
+		best := -1
+		for i, seated := range tables {
+			if len(seated) >= capacity || conflicts(player, seated, separated) {
+				continue
+			}
+			if best < 0 || len(seated) < len(tables[best]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			// Every table with room has a flagged partner; fall back to the emptiest
+			for i, seated := range tables {
+				if len(seated) < capacity && (best < 0 || len(seated) < len(tables[best])) {
+					best = i
+				}
+			}
+			slog.Warn("Could not separate flagged player during seat draw", "user_id", player, "table_number", best+1)
+		}
+		tables[best] = append(tables[best], player)
+	}
+
+	assignments := make([]models.SeatAssignment, 0, len(order))
+	for i, seated := range tables {
+		seats := make([]int, tableSize)
+		for s := range seats {
+			seats[s] = s + 1
+		}
+		if err := shuffle(len(seats), func(a, b int) { seats[a], seats[b] = seats[b], seats[a] }); err != nil {
+			return nil, err
+		}
+		for j, player := range seated {
+			assignments = append(assignments, models.SeatAssignment{UserID: player, TableNumber: i + 1, SeatNumber: seats[j]})
+		}
+	}
+	return assignments, nil
+}
+
+func conflicts(player uuid.UUID, seated []uuid.UUID, separated func(a, b uuid.UUID) bool) bool {
+	if separated == nil {
+		return false
+	}
+	for _, other := range seated {
+		if separated(player, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// shuffle is a Fisher-Yates shuffle driven by crypto/rand
+func shuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return err
+		}
+		swap(i, j)
+	}
+	return nil
+}
+
+func randomIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random seat: %w", err)
+	}
+	return int(v.Int64()), nil
+}
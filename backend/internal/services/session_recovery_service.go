@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// LiveSessionChecker reports whether a game session is still backed by a
+// running table, so its chips must be left alone
+type LiveSessionChecker interface {
+	HasLiveSession(userID, tableID, sessionID uuid.UUID) bool
+}
+
+// SessionRecoveryService returns chips stranded in game accounts whose table
+// disappeared, typically after a crash or restart, to the player's wallet
+type SessionRecoveryService struct {
+	db              *database.DB
+	sessions        *GameSessionService
+	formanceService *formance.Service
+	live            LiveSessionChecker
+}
+
+// NewSessionRecoveryService creates a new session recovery service
+func NewSessionRecoveryService(db *database.DB, formanceService *formance.Service, live LiveSessionChecker) *SessionRecoveryService {
+	return &SessionRecoveryService{
+		db:              db,
+		sessions:        NewGameSessionService(db),
+		formanceService: formanceService,
+		live:            live,
+	}
+}
+
+// RecoverOrphanedSessions cashes out every active session of the user that no
+// running table knows about and marks it abandoned. A session whose transfer
+// fails stays active so the next login retries it.
+func (rs *SessionRecoveryService) RecoverOrphanedSessions(ctx context.Context, userID uuid.UUID) ([]models.RecoveredSession, error) {
+	var sessions []models.GameSession
+	err := rs.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, models.GameSessionStatusActive).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active sessions: %w", err)
+	}
+
+	var recovered []models.RecoveredSession
+	for _, session := range sessions {
+		if rs.live.HasLiveSession(userID, session.TableID, session.ID) {
+			continue
+		}
+
+		result, err := rs.recoverSession(ctx, session)
+		if err != nil {
+			slog.Error("Failed to recover orphaned session", "user_id", userID, "session_id", session.ID, "error", err)
+			continue
+		}
+		recovered = append(recovered, *result)
+	}
+
+	return recovered, nil
+}
+
+func (rs *SessionRecoveryService) recoverSession(ctx context.Context, session models.GameSession) (*models.RecoveredSession, error) {
+	balance, err := rs.formanceService.GetSessionBalance(ctx, session.UserID, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session balance: %w", err)
+	}
+
+	result := &models.RecoveredSession{
+		SessionID: session.ID,
+		TableID:   session.TableID,
+		Amount:    balance,
+	}
+
+	if balance > 0 {
+		result.TransactionID, err = rs.formanceService.TransferFromGameWithMetadata(ctx, session.UserID, balance, session.ID, map[string]string{
+			"reason": "orphaned_session_recovery",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cash out session: %w", err)
+		}
+	}
+
+	// The chips are already back in the wallet, so a failure here only leaves
+	// an empty session active until the next login
+	if err := rs.sessions.AbandonSession(ctx, session.ID); err != nil {
+		slog.Warn("Failed to mark orphaned session abandoned", "session_id", session.ID, "error", err)
+	}
+
+	slog.Info("Recovered orphaned game session",
+		"user_id", session.UserID,
+		"session_id", session.ID,
+		"table_id", session.TableID,
+		"amount", balance,
+		"transaction_id", result.TransactionID)
+
+	return result, nil
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How far one result moves a rating. A tournament finish is split across
+// the field, a cash session counts once against the table's average.
+const (
+	tournamentRatingK = 32.0
+	cashRatingK       = 16.0
+
+	// Results rated per kind on each run, oldest first
+	ratingBatchSize = 200
+)
+
+// SkillRatingOptions configures quick seat matchmaking
+type SkillRatingOptions struct {
+	// Tables with big blinds up to this many MNT keep beginners and sharks
+	// apart, 0 for no low stakes protection
+	LowStakesBigBlind int64
+}
+
+// SkillRatingService keeps Elo-style skill ratings from finished
+// tournaments and cash sessions, and uses their bands to suggest tables
+type SkillRatingService struct {
+	db   *database.DB
+	opts SkillRatingOptions
+}
+
+func NewSkillRatingService(db *database.DB, opts SkillRatingOptions) *SkillRatingService {
+	return &SkillRatingService{db: db, opts: opts}
+}
+
+// ExpectedScore is the share of the points a player rated a expects
+// against a player rated b
+func ExpectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// TournamentRatingChanges rates a finished tournament as every entrant
+// playing every other: a better finish wins, the same finish draws. Each
+// entrant's change is split across the field so large tournaments don't
+// swing ratings more than small ones.
+func TournamentRatingChanges(ratings []float64, positions []int) []float64 {
+	changes := make([]float64, len(ratings))
+	if len(ratings) < 2 {
+		return changes
+	}
+	k := tournamentRatingK / float64(len(ratings)-1)
+	for i := range ratings {
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+			score := 0.5
+			if positions[i] < positions[j] {
+				score = 1
+			} else if positions[i] > positions[j] {
+				score = 0
+			}
+			changes[i] += k * (score - ExpectedScore(ratings[i], ratings[j]))
+		}
+	}
+	return changes
+}
+
+// CashRatingChange rates a finished cash session against the average rating
+// of the players it overlapped with. Doubling the buy-in scores a full win,
+// losing all of it a full loss, and breaking even a draw.
+func CashRatingChange(rating, opponents float64, net, buyIn int64) float64 {
+	if buyIn <= 0 {
+		return 0
+	}
+	result := math.Max(-1, math.Min(1, float64(net)/float64(buyIn)))
+	return cashRatingK * (0.5 + result/2 - ExpectedScore(rating, opponents))
+}
+
+// RateNewResults folds tournaments and cash sessions finished since the
+// last run into ratings, oldest first, and returns how many were rated
+func (ss *SkillRatingService) RateNewResults(ctx context.Context) (int, error) {
+	var tournaments []uuid.UUID
+	err := ss.db.WithContext(ctx).Model(&models.Tournament{}).
+		Where("status = ? AND NOT EXISTS (SELECT 1 FROM rated_results rr WHERE rr.kind = ? AND rr.ref_id = tournaments.id)", "finished", models.RatedResultTournament).
+		Order("end_time").Limit(ratingBatchSize).
+		Pluck("id", &tournaments).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unrated tournaments: %w", err)
+	}
+
+	var sessions []models.GameSession
+	err = ss.db.WithContext(ctx).
+		Where("status = ? AND left_at IS NOT NULL AND NOT EXISTS (SELECT 1 FROM rated_results rr WHERE rr.kind = ? AND rr.ref_id = game_sessions.id)", models.GameSessionStatusFinished, models.RatedResultCashSession).
+		Order("left_at").Limit(ratingBatchSize).
+		Find(&sessions).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unrated cash sessions: %w", err)
+	}
+
+	rated := 0
+	for _, tournamentID := range tournaments {
+		if err := ss.rateTournament(ctx, tournamentID); err != nil {
+			return rated, err
+		}
+		rated++
+	}
+	for _, session := range sessions {
+		if err := ss.rateCashSession(ctx, session); err != nil {
+			return rated, err
+		}
+		rated++
+	}
+
+	if rated > 0 {
+		slog.Info("Skill ratings updated", "tournaments", len(tournaments), "cash_sessions", len(sessions))
+	}
+	return rated, nil
+}
+
+func (ss *SkillRatingService) rateTournament(ctx context.Context, tournamentID uuid.UUID) error {
+	return ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var registrations []models.TournamentRegistration
+		if err := tx.Where("tournament_id = ? AND final_position IS NOT NULL", tournamentID).Find(&registrations).Error; err != nil {
+			return fmt.Errorf("failed to get tournament finishes: %w", err)
+		}
+
+		userIDs := make([]uuid.UUID, len(registrations))
+		positions := make([]int, len(registrations))
+		for i, registration := range registrations {
+			userIDs[i] = registration.UserID
+			positions[i] = *registration.FinalPosition
+		}
+		ratings, err := loadRatings(tx, userIDs)
+		if err != nil {
+			return err
+		}
+
+		current := make([]float64, len(userIDs))
+		for i, userID := range userIDs {
+			current[i] = ratings[userID].Rating
+		}
+		for i, change := range TournamentRatingChanges(current, positions) {
+			rating := ratings[userIDs[i]]
+			rating.Rating += change
+			rating.Results++
+		}
+		if err := saveRatings(tx, ratings); err != nil {
+			return err
+		}
+		return markRated(tx, models.RatedResultTournament, tournamentID)
+	})
+}
+
+func (ss *SkillRatingService) rateCashSession(ctx context.Context, session models.GameSession) error {
+	return ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var opponents []uuid.UUID
+		err := tx.Model(&models.GameSession{}).
+			Where("table_id = ? AND user_id <> ? AND joined_at < ? AND (left_at IS NULL OR left_at > ?)", session.TableID, session.UserID, session.LeftAt, session.JoinedAt).
+			Distinct().Pluck("user_id", &opponents).Error
+		if err != nil {
+			return fmt.Errorf("failed to get cash session opponents: %w", err)
+		}
+
+		// A session nobody else played in says nothing about skill
+		if len(opponents) > 0 {
+			ratings, err := loadRatings(tx, append(opponents, session.UserID))
+			if err != nil {
+				return err
+			}
+			var field float64
+			for _, opponent := range opponents {
+				field += ratings[opponent].Rating
+			}
+			field /= float64(len(opponents))
+
+			player := ratings[session.UserID]
+			player.Rating += CashRatingChange(player.Rating, field, session.GetNetResult(), session.BuyInAmount)
+			player.Results++
+			if err := saveRatings(tx, map[uuid.UUID]*models.SkillRating{session.UserID: player}); err != nil {
+				return err
+			}
+		}
+		return markRated(tx, models.RatedResultCashSession, session.ID)
+	})
+}
+
+// loadRatings returns the ratings of the users, starting anyone unrated at
+// the initial rating
+func loadRatings(tx *gorm.DB, userIDs []uuid.UUID) (map[uuid.UUID]*models.SkillRating, error) {
+	var existing []models.SkillRating
+	if len(userIDs) > 0 {
+		if err := tx.Where("user_id IN ?", userIDs).Find(&existing).Error; err != nil {
+			return nil, fmt.Errorf("failed to get skill ratings: %w", err)
+		}
+	}
+
+	ratings := make(map[uuid.UUID]*models.SkillRating, len(userIDs))
+	for i := range existing {
+		ratings[existing[i].UserID] = &existing[i]
+	}
+	for _, userID := range userIDs {
+		if _, ok := ratings[userID]; !ok {
+			ratings[userID] = &models.SkillRating{UserID: userID, Rating: models.SkillRatingInitial}
+		}
+	}
+	return ratings, nil
+}
+
+func saveRatings(tx *gorm.DB, ratings map[uuid.UUID]*models.SkillRating) error {
+	for _, rating := range ratings {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rating", "results", "updated_at"}),
+		}).Create(rating).Error
+		if err != nil {
+			return fmt.Errorf("failed to save skill rating: %w", err)
+		}
+	}
+	return nil
+}
+
+func markRated(tx *gorm.DB, kind string, refID uuid.UUID) error {
+	if err := tx.Create(&models.RatedResult{Kind: kind, RefID: refID}).Error; err != nil {
+		return fmt.Errorf("failed to mark result rated: %w", err)
+	}
+	return nil
+}
+
+// GetProfile returns the skill band shown on a player's profile
+func (ss *SkillRatingService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.SkillProfile, error) {
+	bands, err := ss.bands(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	profile := bands[userID]
+	return &profile, nil
+}
+
+// bands returns the skill profile of each user, unrated users included
+func (ss *SkillRatingService) bands(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]models.SkillProfile, error) {
+	var ratings []models.SkillRating
+	if len(userIDs) > 0 {
+		if err := ss.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&ratings).Error; err != nil {
+			return nil, fmt.Errorf("failed to get skill ratings: %w", err)
+		}
+	}
+
+	profiles := make(map[uuid.UUID]models.SkillProfile, len(userIDs))
+	for _, userID := range userIDs {
+		profiles[userID] = models.SkillProfile{UserID: userID, Band: models.SkillBandNew}
+	}
+	for _, rating := range ratings {
+		profiles[rating.UserID] = models.SkillProfile{UserID: rating.UserID, Band: rating.Band(), Results: rating.Results}
+	}
+	return profiles, nil
+}
+
+// MismatchedBands reports whether two bands are kept apart at low stakes:
+// players still learning never sit with verified sharks
+func MismatchedBands(a, b string) bool {
+	learning := func(band string) bool {
+		return band == models.SkillBandNew || band == models.SkillBandBeginner
+	}
+	return (learning(a) && b == models.SkillBandShark) || (a == models.SkillBandShark && learning(b))
+}
+
+// SuggestTables returns open public cash tables for quick seat, busiest
+// first and then cheapest. At low stakes, tables where the player's band is
+// mismatched with anyone seated are left out. maxBigBlind of 0 allows any
+// stakes.
+func (ss *SkillRatingService) SuggestTables(ctx context.Context, userID uuid.UUID, maxBigBlind int64, limit int) ([]models.TableSuggestion, error) {
+	query := ss.db.WithContext(ctx).
+		Where("table_type = ? AND is_private = false AND status IN ? AND current_players < max_players", "cash", []string{"waiting", "active"})
+	if maxBigBlind > 0 {
+		query = query.Where("big_blind <= ?", maxBigBlind)
+	}
+	var tables []models.PokerTable
+	if err := query.Find(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open tables: %w", err)
+	}
+	if len(tables) == 0 {
+		return []models.TableSuggestion{}, nil
+	}
+
+	tableIDs := make([]uuid.UUID, len(tables))
+	for i, table := range tables {
+		tableIDs[i] = table.ID
+	}
+	var seated []models.GameSession
+	err := ss.db.WithContext(ctx).
+		Where("table_id IN ? AND status = ?", tableIDs, models.GameSessionStatusActive).
+		Find(&seated).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seated players: %w", err)
+	}
+
+	byTable := make(map[uuid.UUID][]uuid.UUID)
+	userIDs := []uuid.UUID{userID}
+	for _, session := range seated {
+		if session.UserID == userID || slices.Contains(byTable[session.TableID], session.UserID) {
+			continue
+		}
+		byTable[session.TableID] = append(byTable[session.TableID], session.UserID)
+		userIDs = append(userIDs, session.UserID)
+	}
+	profiles, err := ss.bands(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	band := profiles[userID].Band
+
+	suggestions := make([]models.TableSuggestion, 0, len(tables))
+	for _, table := range tables {
+		players := byTable[table.ID]
+		if ss.isLowStakes(table) && slices.ContainsFunc(players, func(other uuid.UUID) bool {
+			return MismatchedBands(band, profiles[other].Band)
+		}) {
+			continue
+		}
+		suggestions = append(suggestions, models.TableSuggestion{Table: table, Players: len(players)})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Players != suggestions[j].Players {
+			return suggestions[i].Players > suggestions[j].Players
+		}
+		return suggestions[i].Table.BigBlind < suggestions[j].Table.BigBlind
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+func (ss *SkillRatingService) isLowStakes(table models.PokerTable) bool {
+	return ss.opts.LowStakesBigBlind > 0 && table.BigBlind <= ss.opts.LowStakesBigBlind
+}
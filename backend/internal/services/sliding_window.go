@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// slidingWindowLimiter allows each key at most limit events in any window,
+// e.g. a user's chat messages
+type slidingWindowLimiter[K comparable] struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	sent    map[K][]time.Time
+	sweptAt time.Time
+}
+
+// reset sets the limit, 0 for none, and forgets events counted so far
+func (rl *slidingWindowLimiter[K]) reset(limit int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limit, rl.window = limit, window
+	rl.sent = nil
+}
+
+// allow counts an event for key at now, unless key has reached the limit
+func (rl *slidingWindowLimiter[K]) allow(key K, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.limit <= 0 {
+		return true
+	}
+	if rl.sent == nil {
+		rl.sent = make(map[K][]time.Time)
+	}
+	// Forget keys that have gone quiet so the map doesn't grow forever
+	cutoff := now.Add(-rl.window)
+	if now.Sub(rl.sweptAt) > rl.window {
+		for key, times := range rl.sent {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(rl.sent, key)
+			}
+		}
+		rl.sweptAt = now
+	}
+
+	recent := rl.sent[key]
+	kept := recent[:0]
+	for _, at := range recent {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	if len(kept) >= rl.limit {
+		rl.sent[key] = kept
+		return false
+	}
+	rl.sent[key] = append(kept, now)
+	return true
+}
@@ -0,0 +1,602 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/spin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSpinFormatNotFound = errors.New("spin format not found")
+	ErrSpinFormatInactive = errors.New("spin format is not taking players")
+	ErrAlreadyInSpinQueue = errors.New("already waiting for a spin of this format")
+	ErrNotInSpinQueue     = errors.New("not waiting for a spin of this format")
+	ErrSpinBuyIn          = errors.New("spin buy-in failed")
+)
+
+// defaultSpinBlindStructure is a hyper-turbo structure for the default
+// 500 chip starting stacks, with three minute levels
+const defaultSpinBlindStructure = `[
+	{"level": 1, "small_blind": 10, "big_blind": 20, "duration": 180},
+	{"level": 2, "small_blind": 15, "big_blind": 30, "duration": 180},
+	{"level": 3, "small_blind": 20, "big_blind": 40, "duration": 180},
+	{"level": 4, "small_blind": 30, "big_blind": 60, "duration": 180},
+	{"level": 5, "small_blind": 40, "big_blind": 80, "duration": 180},
+	{"level": 6, "small_blind": 50, "big_blind": 100, "duration": 180},
+	{"level": 7, "small_blind": 75, "big_blind": 150, "duration": 180},
+	{"level": 8, "small_blind": 100, "big_blind": 200, "duration": 180}
+]`
+
+// SpinService runs spins: players queue for a format and are seated as
+// soon as three are waiting, when the prize pool is drawn
+type SpinService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	seating         *SeatingService
+	chips           *TournamentChipService
+
+	// Draws are settled one at a time, so the jackpot balance a settlement
+	// reads is still there when it pays out
+	settleMu sync.Mutex
+}
+
+// NewSpinService creates a new spin service
+func NewSpinService(db *database.DB, formanceService *formance.Service) *SpinService {
+	return &SpinService{
+		db:              db,
+		formanceService: formanceService,
+		seating:         NewSeatingService(db),
+		chips:           NewTournamentChipService(db),
+	}
+}
+
+// SpinFormatSummary is a spin format with what its distribution pays back
+// and how many players are waiting for it
+type SpinFormatSummary struct {
+	models.SpinFormat
+	ExpectedMultiplier float64 `json:"expected_multiplier"` // Prize pool in buy-ins, on average
+	HouseEdge          float64 `json:"house_edge"`          // Share of the buy-ins kept, e.g. 0.05
+	Waiting            int     `json:"waiting"`             // Players queued for a spin that hasn't filled
+}
+
+// SpinJoin is a player's place in a spin
+type SpinJoin struct {
+	Tournament models.Tournament `json:"tournament"`
+	Proof      *spin.Proof       `json:"proof"`
+	Started    bool              `json:"started"`
+}
+
+// SpinJackpotReport sums up what spins have drawn against what players paid
+// in
+type SpinJackpotReport struct {
+	Balance     int64 `json:"balance"`       // MNT in the jackpot account
+	Spins       int64 `json:"spins"`         // Drawn so far
+	Entries     int64 `json:"entries"`       // MNT paid in
+	PrizePools  int64 `json:"prize_pools"`   // MNT drawn
+	HouseTopUps int64 `json:"house_top_ups"` // MNT the house added when the jackpot fell short
+}
+
+// CreateFormat stores a new spin format, with a hyper-turbo blind structure
+// when none is given
+func (ss *SpinService) CreateFormat(ctx context.Context, req models.CreateSpinFormatRequest, adminID uuid.UUID) (*models.SpinFormat, error) {
+	blindStructure := req.BlindStructure
+	if len(blindStructure) == 0 {
+		blindStructure = json.RawMessage(defaultSpinBlindStructure)
+	}
+	if _, err := models.ParseBlindStructure(blindStructure); err != nil {
+		return nil, err
+	}
+
+	tiers, err := encodeSpinTiers(req.Tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	format := &models.SpinFormat{
+		Name:           req.Name,
+		BuyIn:          req.BuyIn,
+		StartingChips:  req.StartingChips,
+		BlindStructure: blindStructure,
+		Tiers:          tiers,
+		Active:         true,
+		CreatedBy:      adminID,
+	}
+	if format.StartingChips == 0 {
+		format.StartingChips = 500
+	}
+
+	if err := ss.db.WithContext(ctx).Create(format).Error; err != nil {
+		return nil, fmt.Errorf("failed to create spin format: %w", err)
+	}
+
+	slog.Info("Spin format created", "format_id", format.ID, "name", format.Name, "buy_in", format.BuyIn, "house_edge", models.SpinHouseEdge(req.Tiers), "admin_id", adminID)
+	return format, nil
+}
+
+// UpdateFormat switches a format on or off or replaces its tiers. Spins
+// already open keep the tiers they opened with, which their players were
+// shown.
+func (ss *SpinService) UpdateFormat(ctx context.Context, formatID uuid.UUID, req models.UpdateSpinFormatRequest, adminID uuid.UUID) (*models.SpinFormat, error) {
+	format, err := ss.GetFormat(ctx, formatID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+	if req.Tiers != nil {
+		tiers, err := encodeSpinTiers(req.Tiers)
+		if err != nil {
+			return nil, err
+		}
+		updates["tiers"] = tiers
+	}
+	if len(updates) > 0 {
+		if err := ss.db.WithContext(ctx).Model(format).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update spin format: %w", err)
+		}
+	}
+
+	slog.Info("Spin format updated", "format_id", formatID, "changes", len(updates), "admin_id", adminID)
+	return ss.GetFormat(ctx, formatID)
+}
+
+// GetFormat retrieves a spin format by ID
+func (ss *SpinService) GetFormat(ctx context.Context, formatID uuid.UUID) (*models.SpinFormat, error) {
+	var format models.SpinFormat
+	if err := ss.db.WithContext(ctx).First(&format, "id = ?", formatID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSpinFormatNotFound
+		}
+		return nil, fmt.Errorf("failed to get spin format: %w", err)
+	}
+	return &format, nil
+}
+
+// ListFormats returns the spin formats by buy-in, only those taking players
+// when activeOnly is set
+func (ss *SpinService) ListFormats(ctx context.Context, activeOnly bool) ([]SpinFormatSummary, error) {
+	query := ss.db.WithContext(ctx).Order("buy_in ASC, name ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var formats []models.SpinFormat
+	if err := query.Find(&formats).Error; err != nil {
+		return nil, fmt.Errorf("failed to list spin formats: %w", err)
+	}
+
+	var waiting []struct {
+		FormatID uuid.UUID
+		Players  int
+	}
+	err := ss.db.WithContext(ctx).Table("spin_draws").
+		Select("spin_draws.format_id, COUNT(tournament_registrations.id) AS players").
+		Joins("JOIN tournaments ON tournaments.id = spin_draws.tournament_id AND tournaments.deleted_at IS NULL").
+		Joins("JOIN tournament_registrations ON tournament_registrations.tournament_id = tournaments.id AND tournament_registrations.deleted_at IS NULL").
+		Where("tournaments.status = ?", "registering").
+		Group("spin_draws.format_id").
+		Scan(&waiting).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count players waiting for spins: %w", err)
+	}
+	waitingByFormat := make(map[uuid.UUID]int, len(waiting))
+	for _, w := range waiting {
+		waitingByFormat[w.FormatID] = w.Players
+	}
+
+	summaries := make([]SpinFormatSummary, 0, len(formats))
+	for _, format := range formats {
+		summary := SpinFormatSummary{SpinFormat: format, Waiting: waitingByFormat[format.ID]}
+		if tiers, err := models.ParseSpinTiers(format.Tiers); err == nil {
+			summary.ExpectedMultiplier = models.SpinExpectedMultiplier(tiers)
+			summary.HouseEdge = models.SpinHouseEdge(tiers)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Join buys the player into the oldest spin of the format still filling,
+// or a new one, and starts it when the player fills it. A spin where the
+// player would sit with someone they are flagged to be kept apart from is
+// passed over. clientSeed is mixed into the draw; one is generated when it
+// is empty.
+func (ss *SpinService) Join(ctx context.Context, userID, formatID uuid.UUID, clientSeed string) (*SpinJoin, error) {
+	if clientSeed == "" {
+		seed, err := spin.NewSeed()
+		if err != nil {
+			return nil, err
+		}
+		clientSeed = seed
+	}
+
+	var tournament models.Tournament
+	var transactionID string
+	err := ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Joins to a format queue one at a time, so a spin never overfills
+		var format models.SpinFormat
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&format, "id = ?", formatID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSpinFormatNotFound
+			}
+			return fmt.Errorf("failed to get spin format: %w", err)
+		}
+		if !format.Active {
+			return ErrSpinFormatInactive
+		}
+
+		open, err := ss.seatFor(ctx, tx, userID, formatID)
+		if err != nil {
+			return err
+		}
+		if open == nil {
+			if open, err = openSpin(tx, format); err != nil {
+				return err
+			}
+		}
+		tournament = *open
+
+		transactionID, err = ss.formanceService.ProcessTournamentBuyIn(ctx, userID, tournament.ID, format.BuyIn)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrSpinBuyIn, err)
+		}
+
+		registration := models.TournamentRegistration{
+			TournamentID:       tournament.ID,
+			UserID:             userID,
+			BuyInTransactionID: &transactionID,
+			ClientSeed:         clientSeed,
+		}
+		if err := tx.Create(&registration).Error; err != nil {
+			return fmt.Errorf("failed to create spin registration: %w", err)
+		}
+
+		tournament.RegisteredPlayers++
+		tournament.PrizePool += format.BuyIn
+		err = tx.Model(&tournament).Updates(map[string]interface{}{
+			"registered_players": tournament.RegisteredPlayers,
+			"prize_pool":         tournament.PrizePool,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update spin: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if transactionID != "" {
+			ss.refund(ctx, userID, tournament)
+		}
+		return nil, err
+	}
+
+	slog.Info("Player joined spin", "user_id", userID, "format_id", formatID, "tournament_id", tournament.ID, "players", tournament.RegisteredPlayers)
+
+	join := &SpinJoin{Tournament: tournament}
+	if tournament.RegisteredPlayers >= models.SpinPlayers {
+		// A spin that fails to start here is started by StartFull
+		if err := ss.start(ctx, tournament.ID); err != nil {
+			slog.Error("Failed to start spin", "tournament_id", tournament.ID, "error", err)
+		} else {
+			join.Started = true
+			ss.db.WithContext(ctx).First(&join.Tournament, "id = ?", tournament.ID)
+		}
+	}
+
+	if join.Proof, err = ss.Proof(ctx, tournament.ID); err != nil {
+		return nil, err
+	}
+	return join, nil
+}
+
+// Leave takes the player out of the spin they are waiting in and refunds
+// the buy-in. A full spin can't be left.
+func (ss *SpinService) Leave(ctx context.Context, userID, formatID uuid.UUID) error {
+	var tournament models.Tournament
+	err := ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.SpinFormat{}, "id = ?", formatID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSpinFormatNotFound
+			}
+			return fmt.Errorf("failed to get spin format: %w", err)
+		}
+
+		err := openSpins(tx, formatID).
+			Joins("JOIN tournament_registrations ON tournament_registrations.tournament_id = tournaments.id AND tournament_registrations.deleted_at IS NULL").
+			Where("tournament_registrations.user_id = ? AND tournaments.registered_players < ?", userID, models.SpinPlayers).
+			First(&tournament).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotInSpinQueue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find spin: %w", err)
+		}
+
+		if err := tx.Where("tournament_id = ? AND user_id = ?", tournament.ID, userID).Delete(&models.TournamentRegistration{}).Error; err != nil {
+			return fmt.Errorf("failed to delete spin registration: %w", err)
+		}
+		err = tx.Model(&tournament).Updates(map[string]interface{}{
+			"registered_players": tournament.RegisteredPlayers - 1,
+			"prize_pool":         tournament.PrizePool - tournament.BuyIn,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update spin: %w", err)
+		}
+
+		// Refunded last, so nothing is paid back unless the player is out
+		if _, err := ss.formanceService.RefundTournamentBuyIn(ctx, userID, tournament.ID, tournament.BuyIn); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Player left spin queue", "user_id", userID, "format_id", formatID, "tournament_id", tournament.ID)
+	return nil
+}
+
+// StartFull starts spins that filled but failed to start when they did
+func (ss *SpinService) StartFull(ctx context.Context) error {
+	var tournamentIDs []uuid.UUID
+	err := ss.db.WithContext(ctx).Model(&models.Tournament{}).
+		Where("tournament_type = ? AND status = ? AND registered_players >= ?", models.TournamentTypeSpin, "registering", models.SpinPlayers).
+		Pluck("id", &tournamentIDs).Error
+	if err != nil {
+		return fmt.Errorf("failed to find full spins: %w", err)
+	}
+
+	for _, tournamentID := range tournamentIDs {
+		if err := ss.start(ctx, tournamentID); err != nil {
+			slog.Error("Failed to start spin", "tournament_id", tournamentID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Proof returns what a spin's draw can be checked with. The server seed and
+// the players' seeds are only given once the draw is made.
+func (ss *SpinService) Proof(ctx context.Context, tournamentID uuid.UUID) (*spin.Proof, error) {
+	var draw models.SpinDraw
+	if err := ss.db.WithContext(ctx).First(&draw, "tournament_id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to get spin draw: %w", err)
+	}
+
+	var tiers []models.SpinTier
+	if err := json.Unmarshal(draw.Tiers, &tiers); err != nil {
+		return nil, fmt.Errorf("invalid spin draw tiers: %w", err)
+	}
+
+	proof := &spin.Proof{
+		TournamentID:   tournamentID,
+		Algorithm:      spin.Algorithm,
+		ServerSeedHash: draw.ServerSeedHash,
+		Tiers:          tiers,
+	}
+	if draw.DrawnAt != nil {
+		proof.ServerSeed = draw.ServerSeed
+		proof.ClientSeed = draw.ClientSeed
+		proof.Tier = draw.Tier
+		proof.Multiplier = draw.Multiplier
+	}
+	return proof, nil
+}
+
+// JackpotReport sums up the spins drawn so far and what the jackpot holds
+func (ss *SpinService) JackpotReport(ctx context.Context) (*SpinJackpotReport, error) {
+	var report SpinJackpotReport
+	err := ss.db.WithContext(ctx).Model(&models.SpinDraw{}).
+		Select("COUNT(*) AS spins, COALESCE(SUM(buy_in * ?), 0) AS entries, COALESCE(SUM(prize_pool), 0) AS prize_pools, COALESCE(SUM(house_top_up), 0) AS house_top_ups", models.SpinPlayers).
+		Where("drawn_at IS NOT NULL").
+		Scan(&report).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum spin draws: %w", err)
+	}
+
+	if report.Balance, err = ss.formanceService.SpinJackpotBalance(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get spin jackpot balance: %w", err)
+	}
+	return &report, nil
+}
+
+// seatFor returns the oldest open spin of the format the player can sit in,
+// or nil when there is none
+func (ss *SpinService) seatFor(ctx context.Context, tx *gorm.DB, userID, formatID uuid.UUID) (*models.Tournament, error) {
+	var open []models.Tournament
+	if err := openSpins(tx, formatID).Where("tournaments.registered_players < ?", models.SpinPlayers).Find(&open).Error; err != nil {
+		return nil, fmt.Errorf("failed to find open spins: %w", err)
+	}
+
+	for i := range open {
+		var players []uuid.UUID
+		if err := tx.Model(&models.TournamentRegistration{}).Where("tournament_id = ?", open[i].ID).Pluck("user_id", &players).Error; err != nil {
+			return nil, fmt.Errorf("failed to get spin registrations: %w", err)
+		}
+		for _, player := range players {
+			if player == userID {
+				return nil, ErrAlreadyInSpinQueue
+			}
+		}
+
+		separated, err := ss.seating.SeparatedFrom(ctx, userID, players)
+		if err != nil {
+			return nil, err
+		}
+		if len(separated) == 0 {
+			return &open[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// openSpins selects the format's spins still filling, oldest first
+func openSpins(tx *gorm.DB, formatID uuid.UUID) *gorm.DB {
+	return tx.Model(&models.Tournament{}).
+		Joins("JOIN spin_draws ON spin_draws.tournament_id = tournaments.id").
+		Where("spin_draws.format_id = ? AND tournaments.status = ?", formatID, "registering").
+		Order("tournaments.created_at ASC")
+}
+
+// openSpin creates a spin of the format, committing to its server seed
+// before anyone is in it
+func openSpin(tx *gorm.DB, format models.SpinFormat) (*models.Tournament, error) {
+	serverSeed, err := spin.NewSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	tournament := &models.Tournament{
+		Name:           fmt.Sprintf("%s #%s", format.Name, uuid.NewString()[:8]),
+		TournamentType: models.TournamentTypeSpin,
+		BuyIn:          format.BuyIn,
+		MaxPlayers:     models.SpinPlayers,
+		Status:         "registering",
+		BlindStructure: format.BlindStructure,
+		// Replaced by the drawn tier's payouts
+		PayoutStructure: json.RawMessage(`[{"position": 1, "percentage": 100}]`),
+		StartingChips:   format.StartingChips,
+	}
+	if err := tx.Create(tournament).Error; err != nil {
+		return nil, fmt.Errorf("failed to create spin: %w", err)
+	}
+
+	draw := models.SpinDraw{
+		TournamentID:   tournament.ID,
+		FormatID:       format.ID,
+		BuyIn:          format.BuyIn,
+		Tiers:          format.Tiers,
+		ServerSeedHash: spin.Commitment(serverSeed),
+		ServerSeed:     serverSeed,
+	}
+	if err := tx.Create(&draw).Error; err != nil {
+		return nil, fmt.Errorf("failed to create spin draw: %w", err)
+	}
+	return tournament, nil
+}
+
+// start draws a full spin's prize pool, settles it with the jackpot, seats
+// the players and sets it running. A spin already drawn is left alone.
+func (ss *SpinService) start(ctx context.Context, tournamentID uuid.UUID) error {
+	ss.settleMu.Lock()
+	defer ss.settleMu.Unlock()
+
+	var draw models.SpinDraw
+	if err := ss.db.WithContext(ctx).First(&draw, "tournament_id = ?", tournamentID).Error; err != nil {
+		return fmt.Errorf("failed to get spin draw: %w", err)
+	}
+	if draw.DrawnAt != nil {
+		return nil
+	}
+
+	var tournament models.Tournament
+	if err := ss.db.WithContext(ctx).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		return fmt.Errorf("failed to get spin: %w", err)
+	}
+
+	var registrations []models.TournamentRegistration
+	if err := ss.db.WithContext(ctx).Where("tournament_id = ?", tournamentID).Order("registered_at ASC, id ASC").Find(&registrations).Error; err != nil {
+		return fmt.Errorf("failed to get spin registrations: %w", err)
+	}
+	seeds := make([]string, len(registrations))
+	for i, registration := range registrations {
+		seeds[i] = registration.ClientSeed
+	}
+
+	tiers, err := models.ParseSpinTiers(draw.Tiers)
+	if err != nil {
+		return err
+	}
+	clientSeed := spin.ClientSeed(seeds)
+	tier := spin.Draw(draw.ServerSeed, clientSeed, tournamentID, tiers)
+	drawn := tiers[tier]
+	prizePool := drawn.Multiplier * draw.BuyIn
+	payouts, err := json.Marshal(drawn.Payouts)
+	if err != nil {
+		return fmt.Errorf("failed to encode spin payouts: %w", err)
+	}
+
+	if _, err := ss.seating.AssignTournamentSeats(ctx, tournamentID, models.SpinPlayers); err != nil {
+		return err
+	}
+	if err := ss.chips.SeedChips(ctx, tournamentID, tournament.StartingChips); err != nil {
+		return err
+	}
+
+	settlement, err := ss.formanceService.SettleSpinDraw(ctx, tournamentID, draw.BuyIn*int64(len(registrations)), prizePool)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = ss.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		drawUpdates := map[string]interface{}{
+			"client_seed":      clientSeed,
+			"tier":             tier,
+			"multiplier":       drawn.Multiplier,
+			"prize_pool":       prizePool,
+			"jackpot_transfer": settlement.JackpotTransfer,
+			"house_top_up":     settlement.HouseTopUp,
+			"drawn_at":         now,
+		}
+		if settlement.TransactionID != "" {
+			drawUpdates["transaction_id"] = settlement.TransactionID
+		}
+		if err := tx.Model(&draw).Updates(drawUpdates).Error; err != nil {
+			return fmt.Errorf("failed to save spin draw: %w", err)
+		}
+
+		err := tx.Model(&tournament).Updates(map[string]interface{}{
+			"status":           "running",
+			"start_time":       now,
+			"current_level":    1,
+			"prize_pool":       prizePool,
+			"payout_structure": payouts,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to start spin: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Spin started", "tournament_id", tournamentID, "multiplier", drawn.Multiplier, "prize_pool", prizePool)
+	return nil
+}
+
+// refund returns a buy-in taken for a join that then failed
+func (ss *SpinService) refund(ctx context.Context, userID uuid.UUID, tournament models.Tournament) {
+	if _, err := ss.formanceService.RefundTournamentBuyIn(ctx, userID, tournament.ID, tournament.BuyIn); err != nil {
+		slog.Error("Failed to refund spin buy-in", "user_id", userID, "tournament_id", tournament.ID, "error", err)
+	}
+}
+
+// encodeSpinTiers validates tiers and encodes them for storage
+func encodeSpinTiers(tiers []models.SpinTier) (json.RawMessage, error) {
+	if err := models.ValidateSpinTiers(tiers); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(tiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spin tiers: %w", err)
+	}
+	return raw, nil
+}
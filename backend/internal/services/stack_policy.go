@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// Effective stack policies. Standard tables take buy-ins of up to
+// StandardMaxBuyInBigBlinds, deep tables raise that to DeepMaxBuyInBigBlinds,
+// and cap tables limit the chips a player may have in play at once.
+const (
+	StackTypeStandard = "standard"
+	StackTypeCap      = "cap"
+	StackTypeDeep     = "deep"
+
+	StandardMaxBuyInBigBlinds = 200
+	DeepMaxBuyInBigBlinds     = 500
+)
+
+// StackPolicyError checks a table's stack type against its buy-ins and
+// returns a message for the first problem, or "" if acceptable
+func StackPolicyError(stackType string, maxBuyIn, bigBlind, chipCap int64) string {
+	switch stackType {
+	case StackTypeStandard, StackTypeCap, StackTypeDeep:
+	default:
+		return "Stack type must be standard, cap or deep"
+	}
+	if stackType != StackTypeCap && chipCap != 0 {
+		return "Only cap tables have a chip cap"
+	}
+	if bigBlind <= 0 {
+		return ""
+	}
+
+	maxBigBlinds := int64(StandardMaxBuyInBigBlinds)
+	if stackType == StackTypeDeep {
+		maxBigBlinds = DeepMaxBuyInBigBlinds
+		if maxBuyIn <= StandardMaxBuyInBigBlinds*bigBlind {
+			return fmt.Sprintf("Deep tables need a max buy-in above %d big blinds", StandardMaxBuyInBigBlinds)
+		}
+	}
+	if maxBuyIn > maxBigBlinds*bigBlind {
+		return fmt.Sprintf("Max buy-in can be at most %d big blinds at %s tables", maxBigBlinds, stackType)
+	}
+
+	if stackType == StackTypeCap {
+		// A buy-in must fit under the cap, or it would be cashed out at once
+		if chipCap < maxBuyIn {
+			return "Cap tables need a chip cap of at least the max buy-in"
+		}
+		if chipCap > StandardMaxBuyInBigBlinds*bigBlind {
+			return fmt.Sprintf("Chip cap can be at most %d big blinds", StandardMaxBuyInBigBlinds)
+		}
+	}
+	return ""
+}
+
+// BuyInRangeError refuses a buy-in outside a table's range
+type BuyInRangeError struct {
+	MinBuyIn int64
+	MaxBuyIn int64
+	BuyIn    int64
+}
+
+func (e *BuyInRangeError) Error() string {
+	return fmt.Sprintf("Buy-in must be between %d and %d MNT at this table", e.MinBuyIn, e.MaxBuyIn)
+}
+
+// CheckBuyIn checks a buy-in against the table's range. At cap tables the
+// range never goes above the chip cap.
+func CheckBuyIn(table *models.PokerTable, buyIn int64) error {
+	maxBuyIn := table.MaxBuyIn
+	if table.StackType == StackTypeCap && table.ChipCap > 0 {
+		maxBuyIn = min(maxBuyIn, table.ChipCap)
+	}
+	if buyIn < table.MinBuyIn || buyIn > maxBuyIn {
+		return &BuyInRangeError{MinBuyIn: table.MinBuyIn, MaxBuyIn: maxBuyIn, BuyIn: buyIn}
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// autoOpenOccupancy is the seat occupancy at which every table of a template
+// counts as busy and a new one is opened
+const autoOpenOccupancy = 0.8
+
+// ErrTemplateNotFound is returned when a stake template does not exist
+var ErrTemplateNotFound = errors.New("stake template not found")
+
+// StakeTemplateService manages stake templates and the tables opened from them
+type StakeTemplateService struct {
+	db *database.DB
+}
+
+// NewStakeTemplateService creates a new stake template service
+func NewStakeTemplateService(db *database.DB) *StakeTemplateService {
+	return &StakeTemplateService{db: db}
+}
+
+// CreateTemplate stores a new stake template, filling in defaults for optional fields
+func (sts *StakeTemplateService) CreateTemplate(ctx context.Context, req models.CreateStakeTemplateRequest, createdBy uuid.UUID) (*models.StakeTemplate, error) {
+	template := &models.StakeTemplate{
+		Name:           req.Name,
+		GameType:       req.GameType,
+		MaxPlayers:     req.MaxPlayers,
+		SmallBlind:     req.SmallBlind,
+		BigBlind:       req.BigBlind,
+		MinBuyIn:       req.MinBuyIn,
+		MaxBuyIn:       req.MaxBuyIn,
+		RakePercentage: req.RakePercentage,
+		RakeCap:        req.RakeCap,
+		RakeMinPot:     req.RakeMinPot,
+		Speed:          req.Speed,
+		AutoManage:     req.AutoManage,
+		MinTables:      req.MinTables,
+		MaxTables:      req.MaxTables,
+		CreatedBy:      createdBy,
+	}
+
+	if template.GameType == "" {
+		template.GameType = "texas_holdem"
+	}
+	if template.MaxPlayers == 0 {
+		template.MaxPlayers = 9
+	}
+	if template.Speed == "" {
+		template.Speed = models.TableSpeedRegular
+	}
+	if template.MaxTables == 0 {
+		template.MaxTables = 10
+	}
+	if template.MinTables > template.MaxTables {
+		return nil, fmt.Errorf("min tables cannot exceed max tables")
+	}
+
+	if err := sts.db.WithContext(ctx).Create(template).Error; err != nil {
+		return nil, fmt.Errorf("failed to create stake template: %w", err)
+	}
+
+	slog.Info("Stake template created", "template_id", template.ID, "name", template.Name, "created_by", createdBy)
+	return template, nil
+}
+
+// ListTemplates returns all stake templates ordered by stake size
+func (sts *StakeTemplateService) ListTemplates(ctx context.Context) ([]models.StakeTemplate, error) {
+	var templates []models.StakeTemplate
+	if err := sts.db.WithContext(ctx).Order("big_blind ASC, name ASC").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list stake templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetTemplate retrieves a stake template by ID
+func (sts *StakeTemplateService) GetTemplate(ctx context.Context, id uuid.UUID) (*models.StakeTemplate, error) {
+	var template models.StakeTemplate
+	if err := sts.db.WithContext(ctx).First(&template, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get stake template: %w", err)
+	}
+	return &template, nil
+}
+
+// UpdateTemplate applies partial changes to a template. Tables that are
+// already open keep their settings; only newly opened tables pick them up.
+func (sts *StakeTemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, req models.UpdateStakeTemplateRequest) (*models.StakeTemplate, error) {
+	template, err := sts.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SmallBlind != nil {
+		template.SmallBlind = *req.SmallBlind
+	}
+	if req.BigBlind != nil {
+		template.BigBlind = *req.BigBlind
+	}
+	if req.MinBuyIn != nil {
+		template.MinBuyIn = *req.MinBuyIn
+	}
+	if req.MaxBuyIn != nil {
+		template.MaxBuyIn = *req.MaxBuyIn
+	}
+	if req.RakePercentage != nil {
+		template.RakePercentage = *req.RakePercentage
+	}
+	if req.RakeCap != nil {
+		template.RakeCap = *req.RakeCap
+	}
+	if req.RakeMinPot != nil {
+		template.RakeMinPot = *req.RakeMinPot
+	}
+	if req.Speed != nil {
+		template.Speed = *req.Speed
+	}
+	if req.AutoManage != nil {
+		template.AutoManage = *req.AutoManage
+	}
+	if req.MinTables != nil {
+		template.MinTables = *req.MinTables
+	}
+	if req.MaxTables != nil {
+		template.MaxTables = *req.MaxTables
+	}
+
+	if template.BigBlind <= template.SmallBlind {
+		return nil, fmt.Errorf("big blind must be greater than small blind")
+	}
+	if template.MaxBuyIn <= template.MinBuyIn {
+		return nil, fmt.Errorf("max buy-in must be greater than min buy-in")
+	}
+	if template.MinTables > template.MaxTables {
+		return nil, fmt.Errorf("min tables cannot exceed max tables")
+	}
+
+	if err := sts.db.WithContext(ctx).Save(template).Error; err != nil {
+		return nil, fmt.Errorf("failed to update stake template: %w", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes a template. Its open tables are left running.
+func (sts *StakeTemplateService) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	result := sts.db.WithContext(ctx).Delete(&models.StakeTemplate{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete stake template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// RakeConfigFor returns the per-hand rake schedule for a templated table
+func (sts *StakeTemplateService) RakeConfigFor(template *models.StakeTemplate, tableID uuid.UUID) formance.RakeConfig {
+	return formance.RakeConfig{
+		Strategy:   formance.RakeStrategyPerHand,
+		Percentage: template.RakePercentage,
+		MaxRake:    template.RakeCap,
+		MinPot:     template.RakeMinPot,
+		TableID:    tableID,
+	}
+}
+
+// OpenTables opens count new tables from a template. Tables are named after
+// the template with an increasing number, e.g. "NL 100/200 #3".
+func (sts *StakeTemplateService) OpenTables(ctx context.Context, templateID uuid.UUID, count int, openedBy uuid.UUID) ([]models.PokerTable, error) {
+	template, err := sts.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []models.PokerTable
+	err = sts.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		next, err := nextTableNumber(tx, template.Name)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < count; i++ {
+			table := newTemplatedTable(template, next+i, openedBy)
+			if err := tx.Create(&table).Error; err != nil {
+				return fmt.Errorf("failed to create table %s: %w", table.Name, err)
+			}
+			tables = append(tables, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Opened tables from stake template", "template_id", template.ID, "template", template.Name, "count", len(tables), "opened_by", openedBy)
+	return tables, nil
+}
+
+// CloseTables closes the given tables. Tables with seated players or active
+// game sessions are skipped and reported back so nobody's chips get stranded.
+func (sts *StakeTemplateService) CloseTables(ctx context.Context, tableIDs []uuid.UUID) (closed []uuid.UUID, skipped []uuid.UUID, err error) {
+	for _, tableID := range tableIDs {
+		ok, err := sts.closeTableIfEmpty(ctx, tableID)
+		if err != nil {
+			return closed, skipped, err
+		}
+		if ok {
+			closed = append(closed, tableID)
+		} else {
+			skipped = append(skipped, tableID)
+		}
+	}
+	return closed, skipped, nil
+}
+
+// BalanceTemplateTables opens and closes tables for every auto-managed
+// template. A table is opened when all of a template's tables are at least
+// 80% full, and surplus empty tables are closed down to the template minimum
+// while keeping one empty table available for new arrivals.
+func (sts *StakeTemplateService) BalanceTemplateTables(ctx context.Context) error {
+	var templates []models.StakeTemplate
+	if err := sts.db.WithContext(ctx).Where("auto_manage = ?", true).Find(&templates).Error; err != nil {
+		return fmt.Errorf("failed to load auto-managed templates: %w", err)
+	}
+
+	for i := range templates {
+		if err := sts.balanceTemplate(ctx, &templates[i]); err != nil {
+			slog.Error("Failed to balance template tables", "template_id", templates[i].ID, "template", templates[i].Name, "error", err)
+		}
+	}
+	return nil
+}
+
+func (sts *StakeTemplateService) balanceTemplate(ctx context.Context, template *models.StakeTemplate) error {
+	var tables []models.PokerTable
+	if err := sts.db.WithContext(ctx).
+		Where("template_id = ? AND status <> ?", template.ID, "finished").
+		Order("created_at ASC").
+		Find(&tables).Error; err != nil {
+		return fmt.Errorf("failed to load template tables: %w", err)
+	}
+
+	if len(tables) < template.MinTables {
+		_, err := sts.OpenTables(ctx, template.ID, template.MinTables-len(tables), template.CreatedBy)
+		return err
+	}
+
+	if len(tables) < template.MaxTables && allTablesBusy(tables) {
+		_, err := sts.OpenTables(ctx, template.ID, 1, template.CreatedBy)
+		return err
+	}
+
+	// Close empty tables, newest first, but always leave one spare
+	open := len(tables)
+	keptEmpty := false
+	for i := len(tables) - 1; i >= 0 && open > template.MinTables; i-- {
+		if tables[i].CurrentPlayers > 0 {
+			continue
+		}
+		if !keptEmpty {
+			keptEmpty = true
+			continue
+		}
+
+		closed, err := sts.closeTableIfEmpty(ctx, tables[i].ID)
+		if err != nil {
+			return err
+		}
+		if closed {
+			open--
+			slog.Info("Closed idle templated table", "template", template.Name, "table", tables[i].Name)
+		}
+	}
+	return nil
+}
+
+// closeTableIfEmpty soft deletes a table if nobody is seated at it
+func (sts *StakeTemplateService) closeTableIfEmpty(ctx context.Context, tableID uuid.UUID) (bool, error) {
+	closed := false
+	err := sts.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var activeSessions int64
+		if err := tx.Model(&models.GameSession{}).
+			Where("table_id = ? AND status = ?", tableID, "active").
+			Count(&activeSessions).Error; err != nil {
+			return fmt.Errorf("failed to count active sessions: %w", err)
+		}
+		if activeSessions > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.PokerTable{}).
+			Where("id = ? AND current_players = 0", tableID).
+			Update("status", "finished").Error; err != nil {
+			return fmt.Errorf("failed to finish table: %w", err)
+		}
+
+		result := tx.Where("id = ? AND current_players = 0", tableID).Delete(&models.PokerTable{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to close table: %w", result.Error)
+		}
+		closed = result.RowsAffected > 0
+		return nil
+	})
+	return closed, err
+}
+
+// allTablesBusy reports whether every table is at or above the auto-open occupancy
+func allTablesBusy(tables []models.PokerTable) bool {
+	if len(tables) == 0 {
+		return true
+	}
+	for _, table := range tables {
+		if table.MaxPlayers == 0 || float64(table.CurrentPlayers)/float64(table.MaxPlayers) < autoOpenOccupancy {
+			return false
+		}
+	}
+	return true
+}
+
+// nextTableNumber finds the next free "<name> #N" suffix. Closed tables are
+// included because table names stay unique after soft deletion.
+func nextTableNumber(tx *gorm.DB, templateName string) (int, error) {
+	var names []string
+	prefix := templateName + " #"
+	if err := tx.Unscoped().Model(&models.PokerTable{}).
+		Where("name LIKE ?", strings.NewReplacer("%", `\%`, "_", `\_`).Replace(prefix)+"%").
+		Pluck("name", &names).Error; err != nil {
+		return 0, fmt.Errorf("failed to look up existing table names: %w", err)
+	}
+
+	highest := 0
+	for _, name := range names {
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, prefix)); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest + 1, nil
+}
+
+func newTemplatedTable(template *models.StakeTemplate, number int, createdBy uuid.UUID) models.PokerTable {
+	templateID := template.ID
+	return models.PokerTable{
+		Name:           fmt.Sprintf("%s #%d", template.Name, number),
+		TableType:      "cash",
+		GameType:       template.GameType,
+		MaxPlayers:     template.MaxPlayers,
+		MinBuyIn:       template.MinBuyIn,
+		MaxBuyIn:       template.MaxBuyIn,
+		SmallBlind:     template.SmallBlind,
+		BigBlind:       template.BigBlind,
+		RakePercentage: template.RakePercentage,
+		RakeCap:        template.RakeCap,
+		RakeMinPot:     template.RakeMinPot,
+		Status:         "waiting",
+		CreatedBy:      createdBy,
+		TemplateID:     &templateID,
+	}
+}
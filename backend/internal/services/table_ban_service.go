@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTableBanNotFound = errors.New("user is not on your ban list")
+	ErrCannotBanSelf    = errors.New("you cannot ban yourself from your own tables")
+)
+
+// TableBanService keeps the ban lists private table owners hold against
+// other players. A ban applies to every private table the owner created and
+// stops the user taking a seat there; it has no effect on public tables.
+type TableBanService struct {
+	db *database.DB
+}
+
+// NewTableBanService creates a new table ban service
+func NewTableBanService(db *database.DB) *TableBanService {
+	return &TableBanService{db: db}
+}
+
+// Ban adds a user to the owner's ban list. Banning them again updates the
+// reason. Players already seated keep their seat until they leave.
+func (tbs *TableBanService) Ban(ctx context.Context, ownerID uuid.UUID, req models.BanTableUserRequest) (*models.TableBan, error) {
+	if req.UserID == ownerID {
+		return nil, ErrCannotBanSelf
+	}
+
+	var user models.User
+	if err := tbs.db.WithContext(ctx).Select("id", "username").First(&user, "id = ?", req.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var ban models.TableBan
+	err := tbs.db.WithContext(ctx).
+		Where("owner_id = ? AND user_id = ?", ownerID, req.UserID).
+		Assign(models.TableBan{Reason: req.Reason}).
+		FirstOrCreate(&ban, models.TableBan{OwnerID: ownerID, UserID: req.UserID}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to ban user: %w", err)
+	}
+	ban.Username = user.Username
+
+	slog.Info("User banned from owner's tables", "owner_id", ownerID, "user_id", req.UserID)
+	return &ban, nil
+}
+
+// Unban removes a user from the owner's ban list
+func (tbs *TableBanService) Unban(ctx context.Context, ownerID, userID uuid.UUID) error {
+	result := tbs.db.WithContext(ctx).Delete(&models.TableBan{}, "owner_id = ? AND user_id = ?", ownerID, userID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to unban user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTableBanNotFound
+	}
+
+	slog.Info("User unbanned from owner's tables", "owner_id", ownerID, "user_id", userID)
+	return nil
+}
+
+// List returns the owner's ban list, most recent first
+func (tbs *TableBanService) List(ctx context.Context, ownerID uuid.UUID) ([]models.TableBan, error) {
+	var bans []models.TableBan
+	if err := tbs.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at DESC").Find(&bans).Error; err != nil {
+		return nil, fmt.Errorf("failed to list table bans: %w", err)
+	}
+	if len(bans) == 0 {
+		return bans, nil
+	}
+
+	userIDs := make([]uuid.UUID, len(bans))
+	for i, ban := range bans {
+		userIDs[i] = ban.UserID
+	}
+	var users []models.User
+	if err := tbs.db.WithContext(ctx).Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get banned users: %w", err)
+	}
+	usernames := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	for i := range bans {
+		bans[i].Username = usernames[bans[i].UserID]
+	}
+	return bans, nil
+}
+
+// IsBanned reports whether the table's owner has banned the user. Only
+// private tables enforce their owner's list.
+func (tbs *TableBanService) IsBanned(ctx context.Context, table *models.PokerTable, userID uuid.UUID) (bool, error) {
+	if !table.IsPrivate || table.CreatedBy == userID {
+		return false, nil
+	}
+
+	var count int64
+	err := tbs.db.WithContext(ctx).Model(&models.TableBan{}).
+		Where("owner_id = ? AND user_id = ?", table.CreatedBy, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check table ban: %w", err)
+	}
+	return count > 0, nil
+}
+
+// BannedFromTable is IsBanned for the table with the given name. Tables with
+// no database record, such as practice tables opened ad hoc, ban no one.
+func (tbs *TableBanService) BannedFromTable(ctx context.Context, tableName string, userID uuid.UUID) (bool, error) {
+	var table models.PokerTable
+	err := tbs.db.WithContext(ctx).Select("id", "is_private", "created_by").First(&table, "name = ?", tableName).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get table: %w", err)
+	}
+	return tbs.IsBanned(ctx, &table, userID)
+}
@@ -2,13 +2,30 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/database"
 	"github.com/anhbaysgalan1/gp/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTableNotFound is returned when a poker table does not exist
+var ErrTableNotFound = errors.New("table not found")
+
+// ErrHandInProgress is returned when a table can't change until its hand ends
+var ErrHandInProgress = errors.New("hand in progress")
+
+// Errors from moderators stepping in at a running table
+var (
+	ErrNotPlayersTurn   = errors.New("it is not the player's turn to act")
+	ErrPlayerNotAtTable = errors.New("player is not at the table")
+	ErrHandFrozen       = errors.New("the hand is frozen")
+	ErrTableNotPaused   = errors.New("table is not paused")
 )
 
 // TableService provides simple GORM-based table operations
@@ -50,13 +67,60 @@ func (ts *TableService) CreateTable(ctx context.Context, name string, createdBy
 	return table, nil
 }
 
+// PromoteTable writes a table that so far only existed in memory to the
+// database, owned by the system account. A table already saved under its
+// name is returned instead, as it is.
+func (ts *TableService) PromoteTable(ctx context.Context, virtual *models.PokerTable) (*models.PokerTable, error) {
+	if existing, err := ts.GetTableByName(ctx, virtual.Name); err == nil {
+		return existing, nil
+	}
+
+	if err := ts.ensureSystemUser(ctx); err != nil {
+		return nil, err
+	}
+
+	table := *virtual
+	table.ID = uuid.Nil
+	table.CreatedBy = models.SystemUserID
+	table.CurrentPlayers = 0
+	if err := ts.db.WithContext(ctx).Create(&table).Error; err != nil {
+		// Another server promoted a table of the same name first
+		if database.IsUniqueConstraintError(err) {
+			return ts.GetTableByName(ctx, virtual.Name)
+		}
+		return nil, fmt.Errorf("failed to promote table: %w", err)
+	}
+
+	slog.Info("Table promoted to the database", "table_id", table.ID, "name", table.Name)
+	return &table, nil
+}
+
+// ensureSystemUser creates the system account the first time it is needed
+func (ts *TableService) ensureSystemUser(ctx context.Context) error {
+	now := time.Now()
+	user := models.User{
+		ID:       models.SystemUserID,
+		Email:    models.SystemUsername + "@system.invalid",
+		Username: models.SystemUsername,
+		// Not a bcrypt hash, so no password matches it
+		PasswordHash: "!",
+		Role:         models.UserRolePlayer,
+		IsVerified:   true,
+		DisabledAt:   &now,
+	}
+	if err := ts.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&user).Error; err != nil {
+		return fmt.Errorf("failed to create system account: %w", err)
+	}
+	return nil
+}
+
 // GetTableByID retrieves a table by ID using direct GORM operations
 func (ts *TableService) GetTableByID(ctx context.Context, id uuid.UUID) (*models.PokerTable, error) {
 	var table models.PokerTable
 
 	if err := ts.db.WithContext(ctx).First(&table, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("table not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", ErrTableNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get table: %w", err)
 	}
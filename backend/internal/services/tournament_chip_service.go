@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTournamentNotFound   = errors.New("tournament not found")
+	ErrTournamentNotRunning = errors.New("tournament is not running")
+	ErrFinalBlindLevel      = errors.New("tournament is already at its final blind level")
+)
+
+// TournamentChipService tracks tournament chip counts, blind level changes
+// and the color-ups that come with them in live-style events
+type TournamentChipService struct {
+	db *database.DB
+}
+
+// NewTournamentChipService creates a new tournament chip service
+func NewTournamentChipService(db *database.DB) *TournamentChipService {
+	return &TournamentChipService{db: db}
+}
+
+// SeedChips gives every registered player the tournament's starting stack
+func (tcs *TournamentChipService) SeedChips(ctx context.Context, tournamentID uuid.UUID, startingChips int64) error {
+	err := tcs.db.WithContext(ctx).Model(&models.TournamentRegistration{}).
+		Where("tournament_id = ?", tournamentID).
+		Update("chips", startingChips).Error
+	if err != nil {
+		return fmt.Errorf("failed to seed tournament chips: %w", err)
+	}
+	return nil
+}
+
+// AdvanceLevel moves a running tournament to its next blind level. When the
+// new level takes chip denominations out of play, the color-up and race-off
+// run in the same transaction and the resulting event is returned.
+func (tcs *TournamentChipService) AdvanceLevel(ctx context.Context, tournamentID uuid.UUID) (*models.Tournament, *models.ColorUpEvent, error) {
+	var tournament models.Tournament
+	var event *models.ColorUpEvent
+
+	err := tcs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTournamentNotFound
+			}
+			return fmt.Errorf("failed to load tournament: %w", err)
+		}
+		if tournament.Status != "running" {
+			return ErrTournamentNotRunning
+		}
+
+		levels, err := models.ParseBlindStructure(tournament.BlindStructure)
+		if err != nil {
+			return err
+		}
+		next := tournament.CurrentLevel + 1
+		if next > len(levels) {
+			return ErrFinalBlindLevel
+		}
+
+		if removed, smallest := models.ColorUpAt(levels, next); len(removed) > 0 {
+			event, err = colorUp(tx, tournament.ID, next, removed, smallest)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&tournament).Update("current_level", next).Error; err != nil {
+			return fmt.Errorf("failed to advance blind level: %w", err)
+		}
+		tournament.CurrentLevel = next
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if event != nil {
+		slog.Info("Tournament colored up", "tournament_id", tournament.ID, "level", event.Level, "new_smallest", event.NewSmallest, "chips_raced", event.ChipsRaced)
+	}
+	return &tournament, event, nil
+}
+
+func colorUp(tx *gorm.DB, tournamentID uuid.UUID, level int, removed []int64, smallest int64) (*models.ColorUpEvent, error) {
+	var registrations []models.TournamentRegistration
+	err := tx.Where("tournament_id = ? AND final_position IS NULL AND chips > 0", tournamentID).
+		Find(&registrations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tournament stacks: %w", err)
+	}
+
+	entrants := make([]models.ColorUpResult, len(registrations))
+	for i, reg := range registrations {
+		entrants[i] = models.ColorUpResult{UserID: reg.UserID, StackBefore: reg.Chips}
+		if reg.TableNumber != nil {
+			entrants[i].TableNumber = *reg.TableNumber
+		}
+		if reg.SeatNumber != nil {
+			entrants[i].SeatNumber = *reg.SeatNumber
+		}
+	}
+
+	results, raced, err := RaceOff(entrants, removed, smallest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.StackAfter == result.StackBefore {
+			continue
+		}
+		err := tx.Model(&models.TournamentRegistration{}).
+			Where("tournament_id = ? AND user_id = ?", tournamentID, result.UserID).
+			Update("chips", result.StackAfter).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to update chips after color-up: %w", err)
+		}
+	}
+
+	removedJSON, err := json.Marshal(removed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode removed denominations: %w", err)
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode color-up results: %w", err)
+	}
+
+	event := &models.ColorUpEvent{
+		TournamentID:         tournamentID,
+		Level:                level,
+		RemovedDenominations: removedJSON,
+		NewSmallest:          smallest,
+		ChipsRaced:           raced,
+		Results:              resultsJSON,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, fmt.Errorf("failed to record color-up: %w", err)
+	}
+	return event, nil
+}
+
+// ListColorUps returns the color-ups of a tournament in level order
+func (tcs *TournamentChipService) ListColorUps(ctx context.Context, tournamentID uuid.UUID) ([]models.ColorUpEvent, error) {
+	var events []models.ColorUpEvent
+	err := tcs.db.WithContext(ctx).Where("tournament_id = ?", tournamentID).Order("level").Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list color-ups: %w", err)
+	}
+	return events, nil
+}
+
+// Standings ranks a tournament's players by chip count, with eliminated
+// players after them in finishing order
+func (tcs *TournamentChipService) Standings(ctx context.Context, tournamentID uuid.UUID) ([]models.TournamentStanding, error) {
+	var registrations []models.TournamentRegistration
+	err := tcs.db.WithContext(ctx).Preload("User").
+		Where("tournament_id = ?", tournamentID).
+		Order("final_position IS NOT NULL, chips DESC, final_position").
+		Find(&registrations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load standings: %w", err)
+	}
+
+	standings := make([]models.TournamentStanding, len(registrations))
+	for i, reg := range registrations {
+		standings[i] = models.TournamentStanding{
+			Rank:          i + 1,
+			UserID:        reg.UserID,
+			Username:      reg.User.Username,
+			Chips:         reg.Chips,
+			TableNumber:   reg.TableNumber,
+			SeatNumber:    reg.SeatNumber,
+			FinalPosition: reg.FinalPosition,
+		}
+	}
+	return standings, nil
+}
+
+// RaceOff colors up the given stacks, removing the removed denominations in
+// favour of smallest, the smallest chip left in play. It follows the usual
+// live rules:
+//
+//   - Removed chips that make up whole chips of the new denomination are
+//     exchanged directly. Only the remainder, the odd chips, is raced.
+//   - Races are held per table. Each player is dealt one card per odd chip,
+//     or fewer if a single deck can't cover everyone at the table.
+//   - The total odd chip value at the table is converted to new chips, rounding
+//     up when the remainder is at least half a chip. Those chips go to the
+//     players holding the highest cards, ranked by rank then suit (spades,
+//     hearts, diamonds, clubs), with at most one chip per player.
+//   - A player can't be raced out: one left with nothing gets a new chip.
+//
+// It returns each entrant's result and the number of chips won in races.
+func RaceOff(entrants []models.ColorUpResult, removed []int64, smallest int64) ([]models.ColorUpResult, int, error) {
+	if smallest <= 0 {
+		return nil, 0, fmt.Errorf("invalid smallest denomination %d", smallest)
+	}
+	denominations := append([]int64(nil), removed...)
+	sort.Slice(denominations, func(i, j int) bool { return denominations[i] > denominations[j] })
+
+	results := append([]models.ColorUpResult(nil), entrants...)
+	tables := make(map[int][]int)
+	var tableOrder []int
+	for i := range results {
+		results[i].OddChips = results[i].StackBefore % smallest
+		results[i].StackAfter = results[i].StackBefore - results[i].OddChips
+		results[i].Cards = nil
+		results[i].WonRace = false
+		results[i].Protected = false
+
+		table := results[i].TableNumber
+		if _, ok := tables[table]; !ok {
+			tableOrder = append(tableOrder, table)
+		}
+		tables[table] = append(tables[table], i)
+	}
+	sort.Ints(tableOrder)
+
+	raced := 0
+	for _, table := range tableOrder {
+		seats := tables[table]
+		sort.Slice(seats, func(a, b int) bool { return results[seats[a]].SeatNumber < results[seats[b]].SeatNumber })
+
+		won, err := raceTable(results, seats, denominations, smallest)
+		if err != nil {
+			return nil, 0, err
+		}
+		raced += won
+	}
+
+	return results, raced, nil
+}
+
+// raceTable runs the race for one table, dealing from seat 1 clockwise
+func raceTable(results []models.ColorUpResult, seats []int, denominations []int64, smallest int64) (int, error) {
+	var racers []int
+	var totalOdd int64
+	for _, i := range seats {
+		if results[i].OddChips > 0 {
+			racers = append(racers, i)
+			totalOdd += results[i].OddChips
+		}
+	}
+	if len(racers) == 0 {
+		return 0, nil
+	}
+
+	deck := make([]raceCard, 52)
+	for c := range deck {
+		deck[c] = raceCard(c)
+	}
+	if err := shuffle(len(deck), func(a, b int) { deck[a], deck[b] = deck[b], deck[a] }); err != nil {
+		return 0, err
+	}
+
+	perPlayer := len(deck) / len(racers)
+	best := make(map[int]raceCard, len(racers))
+	for _, i := range racers {
+		cards := oddChipCount(results[i].OddChips, denominations)
+		if cards > perPlayer {
+			cards = perPlayer
+		}
+		for c := 0; c < cards; c++ {
+			card := deck[0]
+			deck = deck[1:]
+			results[i].Cards = append(results[i].Cards, card.String())
+			if c == 0 || card > best[i] {
+				best[i] = card
+			}
+		}
+	}
+
+	award := int(totalOdd / smallest)
+	if (totalOdd%smallest)*2 >= smallest {
+		award++
+	}
+	if award > len(racers) {
+		award = len(racers)
+	}
+
+	sort.Slice(racers, func(a, b int) bool { return best[racers[a]] > best[racers[b]] })
+	for n, i := range racers {
+		switch {
+		case n < award:
+			results[i].WonRace = true
+			results[i].StackAfter += smallest
+		case results[i].StackAfter == 0:
+			results[i].Protected = true
+			results[i].StackAfter = smallest
+		}
+	}
+
+	return award, nil
+}
+
+// oddChipCount is how many removed chips make up an odd chip value, using
+// the largest denominations first
+func oddChipCount(value int64, denominations []int64) int {
+	count := 0
+	for _, d := range denominations {
+		count += int(value / d)
+		value %= d
+	}
+	if value > 0 {
+		count++
+	}
+	return count
+}
+
+// raceCard orders cards by rank, then by suit: clubs, diamonds, hearts, spades
+type raceCard int
+
+func (c raceCard) String() string {
+	return string("23456789TJQKA"[int(c)/4]) + string("cdhs"[int(c)%4])
+}
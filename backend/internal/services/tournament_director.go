@@ -0,0 +1,515 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotInTournament          = errors.New("player is not playing in this tournament")
+	ErrTournamentTableNotFound  = errors.New("tournament table not found")
+	ErrTournamentSeatTaken      = errors.New("seat is taken")
+	ErrTournamentSeatOutOfRange = errors.New("no such seat at the table")
+)
+
+// TableMoveNotifier tells players they have been moved to another table.
+// Implemented by the WebSocket hub.
+type TableMoveNotifier interface {
+	NotifyTableMoves(moves []models.TableMove)
+}
+
+// TournamentDirector opens a tournament's tables when it starts and keeps
+// them balanced as players bust: short tables break and their players move
+// to the others, and players move from the biggest table to the smallest.
+type TournamentDirector struct {
+	db        *database.DB
+	seating   *SeatingService
+	tables    *TableService
+	notifier  TableMoveNotifier
+	tableSize int
+}
+
+// NewTournamentDirector creates a director for tournaments played at tables
+// of tableSize seats
+func NewTournamentDirector(db *database.DB, tableSize int) *TournamentDirector {
+	return &TournamentDirector{
+		db:        db,
+		seating:   NewSeatingService(db),
+		tables:    NewTableService(db),
+		tableSize: tableSize,
+	}
+}
+
+// SetNotifier tells moved players where to go
+func (td *TournamentDirector) SetNotifier(notifier TableMoveNotifier) {
+	td.notifier = notifier
+}
+
+// OpenTables draws every registered player a random table and seat and
+// opens as many tables as the draw needs. Tables opened by an earlier
+// attempt at starting the tournament are reused.
+func (td *TournamentDirector) OpenTables(ctx context.Context, tournament *models.Tournament) ([]models.TournamentTable, error) {
+	assignments, err := td.seating.AssignTournamentSeats(ctx, tournament.ID, td.tableSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var smallBlind, bigBlind int64
+	if levels, err := models.ParseBlindStructure(tournament.BlindStructure); err == nil && len(levels) > 0 {
+		smallBlind, bigBlind = levels[0].SmallBlind, levels[0].BigBlind
+	}
+
+	opened := make(map[int]bool)
+	for _, assignment := range assignments {
+		number := assignment.TableNumber
+		if opened[number] {
+			continue
+		}
+		opened[number] = true
+
+		tournamentID := tournament.ID
+		_, err := td.tables.PromoteTable(ctx, &models.PokerTable{
+			Name:            tournamentTableName(tournament, number),
+			TableType:       "tournament",
+			GameType:        "texas_holdem",
+			MaxPlayers:      td.tableSize,
+			MinBuyIn:        tournament.StartingChips,
+			MaxBuyIn:        tournament.StartingChips,
+			SmallBlind:      smallBlind,
+			BigBlind:        bigBlind,
+			Status:          "waiting",
+			SeatSelection:   models.SeatSelectionRandom,
+			TournamentID:    &tournamentID,
+			TournamentTable: number,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open table %d: %w", number, err)
+		}
+	}
+
+	slog.Info("Tournament tables opened", "tournament_id", tournament.ID, "tables", len(opened), "players", len(assignments))
+	return td.Tables(ctx, tournament.ID)
+}
+
+// tournamentTableName names a tournament's table. The ID keeps it unique
+// between tournaments of the same name.
+func tournamentTableName(tournament *models.Tournament, number int) string {
+	name := []rune(tournament.Name)
+	if len(name) > 70 {
+		name = name[:70]
+	}
+	return fmt.Sprintf("%s (%s) - Table %d", string(name), tournament.ID.String()[:8], number)
+}
+
+// Tables lists a tournament's open tables and who sits where
+func (td *TournamentDirector) Tables(ctx context.Context, tournamentID uuid.UUID) ([]models.TournamentTable, error) {
+	db := td.db.WithContext(ctx)
+	open, err := openTournamentTables(db, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	seating, err := tournamentSeating(db, tournamentID, open)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]models.TournamentTable, 0, len(open))
+	for number, record := range open {
+		seats := make(map[int]string, len(seating[number]))
+		for seat, userID := range seating[number] {
+			seats[seat] = userID.String()
+		}
+		tables = append(tables, models.TournamentTable{
+			TableNumber: number,
+			TableID:     record.ID,
+			Name:        record.Name,
+			Status:      record.Status,
+			Seats:       seats,
+		})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].TableNumber < tables[j].TableNumber })
+	return tables, nil
+}
+
+// EliminatePlayer records that a player busted, finishing in the place of
+// the number of players left, and rebalances the tables without them. It
+// returns the finishing position and the moves made.
+func (td *TournamentDirector) EliminatePlayer(ctx context.Context, tournamentID, userID uuid.UUID) (int, []models.TableMove, error) {
+	var position int
+	var moves []models.TableMove
+
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockRunningTournament(tx, tournamentID); err != nil {
+			return err
+		}
+
+		var registration models.TournamentRegistration
+		err := tx.Where("tournament_id = ? AND user_id = ? AND final_position IS NULL", tournamentID, userID).First(&registration).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotInTournament
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load registration: %w", err)
+		}
+
+		var remaining int64
+		if err := tx.Model(&models.TournamentRegistration{}).
+			Where("tournament_id = ? AND final_position IS NULL", tournamentID).
+			Count(&remaining).Error; err != nil {
+			return fmt.Errorf("failed to count remaining players: %w", err)
+		}
+		position = int(remaining)
+
+		err = tx.Model(&registration).Updates(map[string]interface{}{
+			"final_position": position,
+			"chips":          0,
+			"table_number":   nil,
+			"seat_number":    nil,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to eliminate player: %w", err)
+		}
+
+		moves, err = td.rebalance(tx, tournamentID)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	slog.Info("Tournament player eliminated", "tournament_id", tournamentID, "user_id", userID, "position", position, "moves", len(moves))
+	td.notify(moves)
+	return position, moves, nil
+}
+
+// Rebalance breaks tables the field no longer needs and evens out the rest
+func (td *TournamentDirector) Rebalance(ctx context.Context, tournamentID uuid.UUID) ([]models.TableMove, error) {
+	var moves []models.TableMove
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockRunningTournament(tx, tournamentID); err != nil {
+			return err
+		}
+		var err error
+		moves, err = td.rebalance(tx, tournamentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	td.notify(moves)
+	return moves, nil
+}
+
+// MovePlayer moves a player to another of the tournament's tables, at the
+// requested seat or a random free one
+func (td *TournamentDirector) MovePlayer(ctx context.Context, tournamentID uuid.UUID, req models.MoveTournamentPlayerRequest) (*models.TableMove, error) {
+	var move models.TableMove
+	err := td.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockRunningTournament(tx, tournamentID); err != nil {
+			return err
+		}
+
+		open, err := openTournamentTables(tx, tournamentID)
+		if err != nil {
+			return err
+		}
+		target, ok := open[req.TableNumber]
+		if !ok {
+			return ErrTournamentTableNotFound
+		}
+		seating, err := tournamentSeating(tx, tournamentID, open)
+		if err != nil {
+			return err
+		}
+
+		from, fromSeat, ok := seatOf(seating, req.UserID)
+		if !ok {
+			return ErrNotInTournament
+		}
+
+		seat := req.SeatNumber
+		occupied := make(map[int]bool, len(seating[req.TableNumber]))
+		for s := range seating[req.TableNumber] {
+			occupied[s] = true
+		}
+		switch {
+		case seat > td.tableSize:
+			return ErrTournamentSeatOutOfRange
+		case seat > 0 && occupied[seat]:
+			return ErrTournamentSeatTaken
+		case seat == 0:
+			if seat, err = RandomSeat(occupied, td.tableSize); err != nil {
+				return err
+			}
+		}
+
+		move = models.TableMove{
+			TournamentID: tournamentID,
+			UserID:       req.UserID,
+			FromTable:    from,
+			FromSeat:     fromSeat,
+			ToTable:      req.TableNumber,
+			ToSeat:       seat,
+			TableID:      &target.ID,
+			TableName:    target.Name,
+			Reason:       models.TableMoveDirector,
+		}
+		return applyTableMoves(tx, tournamentID, []models.TableMove{move})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Tournament player moved", "tournament_id", tournamentID, "user_id", req.UserID, "from_table", move.FromTable, "to_table", move.ToTable, "to_seat", move.ToSeat)
+	td.notify([]models.TableMove{move})
+	return &move, nil
+}
+
+// rebalance plans and stores the moves that balance the tables, and closes
+// the tables that break
+func (td *TournamentDirector) rebalance(tx *gorm.DB, tournamentID uuid.UUID) ([]models.TableMove, error) {
+	open, err := openTournamentTables(tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	seating, err := tournamentSeating(tx, tournamentID, open)
+	if err != nil {
+		return nil, err
+	}
+
+	moves, broken, err := PlanTableMoves(seating, td.tableSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := range moves {
+		moves[i].TournamentID = tournamentID
+		if record, ok := open[moves[i].ToTable]; ok {
+			moves[i].TableID = &record.ID
+			moves[i].TableName = record.Name
+		}
+	}
+	if err := applyTableMoves(tx, tournamentID, moves); err != nil {
+		return nil, err
+	}
+
+	if len(broken) > 0 {
+		err := tx.Model(&models.PokerTable{}).
+			Where("tournament_id = ? AND tournament_table IN ?", tournamentID, broken).
+			Update("status", "finished").Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to close broken tables: %w", err)
+		}
+		slog.Info("Tournament tables broken", "tournament_id", tournamentID, "tables", broken)
+	}
+	return moves, nil
+}
+
+func (td *TournamentDirector) notify(moves []models.TableMove) {
+	if td.notifier != nil && len(moves) > 0 {
+		td.notifier.NotifyTableMoves(moves)
+	}
+}
+
+// PlanTableMoves works out the moves that keep a tournament's tables even.
+// seating holds the user in each seat of each open table. While fewer
+// tables would seat everyone, the table with the fewest players breaks and
+// they go to the tables with the most room; then players move from the
+// biggest table to the smallest until no two differ by more than one.
+// Movers and their new seats are drawn at random. It returns the moves and
+// the tables that broke, and updates seating to match.
+func PlanTableMoves(seating map[int]map[int]uuid.UUID, tableSize int) ([]models.TableMove, []int, error) {
+	if tableSize < 2 {
+		return nil, nil, fmt.Errorf("table size must be at least 2, got %d", tableSize)
+	}
+
+	players := 0
+	for _, seats := range seating {
+		players += len(seats)
+	}
+	needed := max((players+tableSize-1)/tableSize, 1)
+
+	var moves []models.TableMove
+	var broken []int
+
+	moveTo := func(userID uuid.UUID, from, fromSeat, to int, reason string) error {
+		occupied := make(map[int]bool, len(seating[to]))
+		for seat := range seating[to] {
+			occupied[seat] = true
+		}
+		seat, err := RandomSeat(occupied, tableSize)
+		if err != nil {
+			return err
+		}
+		delete(seating[from], fromSeat)
+		seating[to][seat] = userID
+		moves = append(moves, models.TableMove{UserID: userID, FromTable: from, FromSeat: fromSeat, ToTable: to, ToSeat: seat, Reason: reason})
+		return nil
+	}
+
+	for len(seating) > needed {
+		numbers := tableNumbers(seating)
+		// Break the shortest table, the last one of those as short
+		breaking := numbers[0]
+		for _, number := range numbers {
+			if len(seating[number]) <= len(seating[breaking]) {
+				breaking = number
+			}
+		}
+
+		for _, seat := range sortedSeats(seating[breaking]) {
+			to := 0
+			for _, number := range numbers {
+				if number == breaking || len(seating[number]) >= tableSize {
+					continue
+				}
+				if to == 0 || len(seating[number]) < len(seating[to]) {
+					to = number
+				}
+			}
+			if to == 0 {
+				return nil, nil, ErrNoEmptySeat
+			}
+			if err := moveTo(seating[breaking][seat], breaking, seat, to, models.TableMoveBreak); err != nil {
+				return nil, nil, err
+			}
+		}
+		delete(seating, breaking)
+		broken = append(broken, breaking)
+	}
+
+	for {
+		numbers := tableNumbers(seating)
+		if len(numbers) < 2 {
+			break
+		}
+		biggest, smallest := numbers[0], numbers[0]
+		for _, number := range numbers {
+			if len(seating[number]) >= len(seating[biggest]) {
+				biggest = number
+			}
+			if len(seating[number]) < len(seating[smallest]) {
+				smallest = number
+			}
+		}
+		if len(seating[biggest])-len(seating[smallest]) <= 1 {
+			break
+		}
+
+		seats := sortedSeats(seating[biggest])
+		i, err := randomIndex(len(seats))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := moveTo(seating[biggest][seats[i]], biggest, seats[i], smallest, models.TableMoveBalance); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return moves, broken, nil
+}
+
+func tableNumbers(seating map[int]map[int]uuid.UUID) []int {
+	numbers := make([]int, 0, len(seating))
+	for number := range seating {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+func sortedSeats(seats map[int]uuid.UUID) []int {
+	numbers := make([]int, 0, len(seats))
+	for seat := range seats {
+		numbers = append(numbers, seat)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+func seatOf(seating map[int]map[int]uuid.UUID, userID uuid.UUID) (int, int, bool) {
+	for table, seats := range seating {
+		for seat, seated := range seats {
+			if seated == userID {
+				return table, seat, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// lockRunningTournament locks a tournament's row so busts and moves at its
+// tables are balanced one at a time
+func lockRunningTournament(tx *gorm.DB, tournamentID uuid.UUID) (*models.Tournament, error) {
+	var tournament models.Tournament
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to load tournament: %w", err)
+	}
+	if tournament.Status != "running" {
+		return nil, ErrTournamentNotRunning
+	}
+	return &tournament, nil
+}
+
+// openTournamentTables loads a tournament's tables that haven't broken, by
+// table number
+func openTournamentTables(db *gorm.DB, tournamentID uuid.UUID) (map[int]models.PokerTable, error) {
+	var records []models.PokerTable
+	err := db.Where("tournament_id = ? AND status <> ?", tournamentID, "finished").Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tournament tables: %w", err)
+	}
+
+	open := make(map[int]models.PokerTable, len(records))
+	for _, record := range records {
+		open[record.TournamentTable] = record
+	}
+	return open, nil
+}
+
+// tournamentSeating maps each open table's seats to the players still in
+// the tournament sitting there
+func tournamentSeating(db *gorm.DB, tournamentID uuid.UUID, open map[int]models.PokerTable) (map[int]map[int]uuid.UUID, error) {
+	var registrations []models.TournamentRegistration
+	err := db.Where("tournament_id = ? AND final_position IS NULL AND table_number IS NOT NULL AND seat_number IS NOT NULL", tournamentID).
+		Find(&registrations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tournament seating: %w", err)
+	}
+
+	seating := make(map[int]map[int]uuid.UUID, len(open))
+	for number := range open {
+		seating[number] = make(map[int]uuid.UUID)
+	}
+	for _, registration := range registrations {
+		if seats, ok := seating[*registration.TableNumber]; ok {
+			seats[*registration.SeatNumber] = registration.UserID
+		}
+	}
+	return seating, nil
+}
+
+// applyTableMoves stores players' new tables and seats
+func applyTableMoves(tx *gorm.DB, tournamentID uuid.UUID, moves []models.TableMove) error {
+	for _, move := range moves {
+		err := tx.Model(&models.TournamentRegistration{}).
+			Where("tournament_id = ? AND user_id = ?", tournamentID, move.UserID).
+			Updates(map[string]interface{}{"table_number": move.ToTable, "seat_number": move.ToSeat}).Error
+		if err != nil {
+			return fmt.Errorf("failed to move player: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPayoutsLocked      = errors.New("payouts can't be changed once the money bubble has burst")
+	ErrTournamentFinished = errors.New("tournament is finished")
+)
+
+// TournamentPayoutService generates payout structures from the size of the
+// field and lets admins adjust them until players start cashing
+type TournamentPayoutService struct {
+	db *database.DB
+}
+
+// NewTournamentPayoutService creates a new tournament payout service
+func NewTournamentPayoutService(db *database.DB) *TournamentPayoutService {
+	return &TournamentPayoutService{db: db}
+}
+
+// PayoutPreview is a payout structure as it would be generated, or as it
+// stands, for a tournament
+type PayoutPreview struct {
+	Entrants  int                  `json:"entrants"`
+	Model     *models.PayoutModel  `json:"model,omitempty"`
+	Places    []models.PayoutPlace `json:"places"`
+	PrizePool int64                `json:"prize_pool"` // MNT
+	Prizes    []int64              `json:"prizes"`     // MNT by place, the rounding remainder to first
+	Remaining int                  `json:"remaining"`  // Players not yet eliminated
+	Locked    bool                 `json:"locked"`     // In the money, so no more changes
+
+	// Awarded is what the players placed so far have won, in a prize preview
+	Awarded []models.TournamentPrize `json:"awarded,omitempty"`
+}
+
+// Preview generates the payout structure for the tournament without saving
+// it. A nil model uses the tournament's own, or the default; zero entrants
+// uses the current registrations.
+func (ps *TournamentPayoutService) Preview(ctx context.Context, tournamentID uuid.UUID, model *models.PayoutModel, entrants int) (*PayoutPreview, error) {
+	var tournament models.Tournament
+	if err := ps.db.WithContext(ctx).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to load tournament: %w", err)
+	}
+
+	if model == nil {
+		model = tournamentPayoutModel(tournament)
+	}
+	if entrants <= 0 {
+		entrants = tournament.RegisteredPlayers
+	}
+
+	places, err := models.GeneratePayoutStructure(entrants, *model)
+	if err != nil {
+		return nil, err
+	}
+
+	// A preview for another field size estimates the pool from the buy-in
+	prizePool := tournament.PrizePool
+	if entrants != tournament.RegisteredPlayers || prizePool == 0 {
+		prizePool = tournament.BuyIn * int64(entrants)
+	}
+	return ps.preview(ctx, tournament, places, model, entrants, prizePool)
+}
+
+// Current returns the tournament's payout structure as it stands
+func (ps *TournamentPayoutService) Current(ctx context.Context, tournamentID uuid.UUID) (*PayoutPreview, error) {
+	var tournament models.Tournament
+	if err := ps.db.WithContext(ctx).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTournamentNotFound
+		}
+		return nil, fmt.Errorf("failed to load tournament: %w", err)
+	}
+
+	places, err := models.ParsePayoutStructure(tournament.PayoutStructure)
+	if err != nil {
+		return nil, err
+	}
+
+	var model *models.PayoutModel
+	if len(tournament.PayoutModel) > 0 {
+		model = tournamentPayoutModel(tournament)
+	}
+	return ps.preview(ctx, tournament, places, model, tournament.RegisteredPlayers, tournament.PrizePool)
+}
+
+// PrizePreview is a dry run of paying out the tournament: the prize for each
+// place from the actual prize pool, and what the players eliminated so far
+// would be paid for their finishing positions
+func (ps *TournamentPayoutService) PrizePreview(ctx context.Context, tournamentID uuid.UUID) (*PayoutPreview, error) {
+	preview, err := ps.Current(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var registrations []models.TournamentRegistration
+	if err := ps.db.WithContext(ctx).
+		Where("tournament_id = ? AND final_position IS NOT NULL", tournamentID).
+		Order("final_position").
+		Find(&registrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load finishing positions: %w", err)
+	}
+	results := make([]models.FinishingPosition, 0, len(registrations))
+	for _, registration := range registrations {
+		results = append(results, models.FinishingPosition{UserID: registration.UserID, Position: *registration.FinalPosition})
+	}
+
+	preview.Awarded, err = models.AwardPrizes(preview.PrizePool, preview.Places, results)
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// GenerateAtRegistrationClose replaces the tournament's payout structure
+// with one generated for the final field, when it has a payout model.
+// Tournaments without one keep their static structure.
+func (ps *TournamentPayoutService) GenerateAtRegistrationClose(ctx context.Context, tournamentID uuid.UUID) ([]models.PayoutPlace, error) {
+	var places []models.PayoutPlace
+	err := ps.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tournament models.Tournament
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTournamentNotFound
+			}
+			return fmt.Errorf("failed to load tournament: %w", err)
+		}
+		if len(tournament.PayoutModel) == 0 {
+			return nil
+		}
+
+		var err error
+		places, err = models.GeneratePayoutStructure(tournament.RegisteredPlayers, *tournamentPayoutModel(tournament))
+		if err != nil {
+			return err
+		}
+		return savePayoutStructure(tx, &tournament, places)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if places != nil {
+		slog.Info("Generated tournament payouts", "tournament_id", tournamentID, "paid_places", len(places))
+	}
+	return places, nil
+}
+
+// Adjust replaces the payout structure by hand. It is allowed until the
+// money bubble bursts, that is while more players remain than places paid.
+func (ps *TournamentPayoutService) Adjust(ctx context.Context, tournamentID, adminID uuid.UUID, places []models.PayoutPlace) (*PayoutPreview, error) {
+	if err := models.ValidatePayoutStructure(places); err != nil {
+		return nil, err
+	}
+
+	err := ps.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tournament models.Tournament
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, "id = ?", tournamentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTournamentNotFound
+			}
+			return fmt.Errorf("failed to load tournament: %w", err)
+		}
+		if tournament.Status == "finished" {
+			return ErrTournamentFinished
+		}
+
+		if tournament.Status != "registering" {
+			remaining, err := remainingPlayers(tx, tournamentID)
+			if err != nil {
+				return err
+			}
+			// Nobody may be moved into or out of the money once it is reached
+			paid := len(places)
+			if current, err := models.ParsePayoutStructure(tournament.PayoutStructure); err == nil && len(current) > paid {
+				paid = len(current)
+			}
+			if remaining <= paid {
+				return ErrPayoutsLocked
+			}
+		}
+
+		return savePayoutStructure(tx, &tournament, places)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Tournament payouts adjusted", "tournament_id", tournamentID, "admin_id", adminID, "paid_places", len(places))
+	return ps.Current(ctx, tournamentID)
+}
+
+func (ps *TournamentPayoutService) preview(ctx context.Context, tournament models.Tournament, places []models.PayoutPlace, model *models.PayoutModel, entrants int, prizePool int64) (*PayoutPreview, error) {
+	remaining := entrants
+	if tournament.Status != "registering" {
+		var err error
+		remaining, err = remainingPlayers(ps.db.WithContext(ctx), tournament.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prizes := models.PlacePrizes(prizePool, places)
+
+	return &PayoutPreview{
+		Entrants:  entrants,
+		Model:     model,
+		Places:    places,
+		PrizePool: prizePool,
+		Prizes:    prizes,
+		Remaining: remaining,
+		Locked:    tournament.Status == "finished" || (tournament.Status != "registering" && remaining <= len(places)),
+	}, nil
+}
+
+// tournamentPayoutModel returns the tournament's payout model, or the
+// default when it has none or it can't be read
+func tournamentPayoutModel(tournament models.Tournament) *models.PayoutModel {
+	model := models.DefaultPayoutModel()
+	if len(tournament.PayoutModel) > 0 {
+		if err := json.Unmarshal(tournament.PayoutModel, &model); err != nil {
+			slog.Warn("Invalid tournament payout model, using the default", "tournament_id", tournament.ID, "error", err)
+			model = models.DefaultPayoutModel()
+		}
+	}
+	return &model
+}
+
+func savePayoutStructure(tx *gorm.DB, tournament *models.Tournament, places []models.PayoutPlace) error {
+	raw, err := json.Marshal(places)
+	if err != nil {
+		return fmt.Errorf("failed to encode payout structure: %w", err)
+	}
+	if err := tx.Model(tournament).Update("payout_structure", raw).Error; err != nil {
+		return fmt.Errorf("failed to save payout structure: %w", err)
+	}
+	return nil
+}
+
+// remainingPlayers counts registered players not yet eliminated
+func remainingPlayers(db *gorm.DB, tournamentID uuid.UUID) (int, error) {
+	var remaining int64
+	err := db.Model(&models.TournamentRegistration{}).
+		Where("tournament_id = ? AND final_position IS NULL", tournamentID).
+		Count(&remaining).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count remaining players: %w", err)
+	}
+	return int(remaining), nil
+}
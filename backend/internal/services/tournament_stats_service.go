@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TournamentStatsService derives player tournament results from the
+// registrations of finished tournaments
+type TournamentStatsService struct {
+	db *database.DB
+}
+
+// NewTournamentStatsService creates a new tournament stats service
+func NewTournamentStatsService(db *database.DB) *TournamentStatsService {
+	return &TournamentStatsService{db: db}
+}
+
+// tournamentResultRow is a TournamentResult with the owning user, as scanned
+// from the registrations join
+type tournamentResultRow struct {
+	UserID uuid.UUID
+	models.TournamentResult
+}
+
+func (ts *TournamentStatsService) finishedResults(ctx context.Context) *gorm.DB {
+	return ts.db.WithContext(ctx).
+		Table("tournament_registrations AS r").
+		Select(`r.user_id, t.id AS tournament_id, t.name, t.tournament_type, t.buy_in,
+			t.registered_players AS entrants, r.final_position, r.prize_amount, t.end_time AS finished_at`).
+		Joins("JOIN tournaments t ON t.id = r.tournament_id").
+		Where("t.status = ? AND r.deleted_at IS NULL AND t.deleted_at IS NULL", "finished")
+}
+
+// GetHistory returns a page of the user's finished tournaments, newest first
+func (ts *TournamentStatsService) GetHistory(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.TournamentResult, int64, error) {
+	query := ts.finishedResults(ctx).Where("r.user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tournament history: %w", err)
+	}
+
+	var rows []tournamentResultRow
+	if err := query.Order("t.end_time DESC").Limit(limit).Offset(offset).Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get tournament history: %w", err)
+	}
+
+	results := make([]models.TournamentResult, len(rows))
+	for i, row := range rows {
+		results[i] = row.TournamentResult
+	}
+	return results, total, nil
+}
+
+// GetStats returns the user's lifetime tournament statistics
+func (ts *TournamentStatsService) GetStats(ctx context.Context, userID uuid.UUID) (*models.TournamentStats, error) {
+	var rows []tournamentResultRow
+	if err := ts.finishedResults(ctx).Where("r.user_id = ?", userID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tournament results: %w", err)
+	}
+
+	stats := &models.TournamentStats{UserID: userID}
+	for _, row := range rows {
+		stats.Add(row.TournamentResult)
+	}
+	return stats, nil
+}
+
+// GetStatsForPeriod returns statistics for every player with a tournament
+// that finished in [from, to), for leaderboard points
+func (ts *TournamentStatsService) GetStatsForPeriod(ctx context.Context, from, to time.Time) ([]models.TournamentStats, error) {
+	var rows []tournamentResultRow
+	err := ts.finishedResults(ctx).
+		Where("t.end_time >= ? AND t.end_time < ?", from, to).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tournament results: %w", err)
+	}
+
+	byUser := make(map[uuid.UUID]*models.TournamentStats)
+	var order []uuid.UUID
+	for _, row := range rows {
+		stats, ok := byUser[row.UserID]
+		if !ok {
+			stats = &models.TournamentStats{UserID: row.UserID}
+			byUser[row.UserID] = stats
+			order = append(order, row.UserID)
+		}
+		stats.Add(row.TournamentResult)
+	}
+
+	result := make([]models.TournamentStats, 0, len(order))
+	for _, userID := range order {
+		result = append(result, *byUser[userID])
+	}
+	return result, nil
+}
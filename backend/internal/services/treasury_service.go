@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// treasuryCacheTTL is how long a treasury summary is served before the
+// ledger is walked again. Walking it reads every account.
+const treasuryCacheTTL = time.Minute
+
+const treasuryPageSize = 100
+
+// Ledger address prefixes the treasury sorts accounts by
+var (
+	tournamentPoolPrefix = formance.SystemAccountPrefix + ":tournament_pool:"
+	lastLongerPrefix     = formance.SystemAccountPrefix + ":last_longer:"
+	revenuePrefix        = "revenue:"
+)
+
+// TreasuryService sums the ledger into the house's liabilities and funds for
+// the finance dashboard. Summaries are cached since each one reads every
+// ledger account.
+type TreasuryService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	mu              sync.Mutex
+	cached          *models.TreasurySummary
+}
+
+func NewTreasuryService(db *database.DB, formanceService *formance.Service) *TreasuryService {
+	return &TreasuryService{db: db, formanceService: formanceService}
+}
+
+// Summary returns the treasury summary, from the cache unless it is older
+// than treasuryCacheTTL or refresh is set
+func (ts *TreasuryService) Summary(ctx context.Context, refresh bool) (*models.TreasurySummary, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if !refresh && ts.cached != nil && time.Since(ts.cached.GeneratedAt) < treasuryCacheTTL {
+		return ts.cached, nil
+	}
+
+	var accounts []formance.AccountData
+	cursor := ""
+	for {
+		page, err := ts.formanceService.QueryAccounts(ctx, "", treasuryPageSize, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query ledger accounts: %w", err)
+		}
+		accounts = append(accounts, page.Accounts...)
+		if !page.HasMore || page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+
+	summary := SummarizeTreasury(accounts, time.Now())
+
+	var inReview struct {
+		Count  int
+		Amount int64
+	}
+	if err := ts.db.WithContext(ctx).Model(&models.BankStatementRow{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Where("status = ?", models.StatementRowInReview).
+		Scan(&inReview).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum deposits in review: %w", err)
+	}
+	summary.DepositsInReview = inReview.Amount
+	summary.DepositsInReviewCount = inReview.Count
+
+	ts.cached = summary
+	return summary, nil
+}
+
+// SummarizeTreasury sorts ledger account balances into liabilities and house
+// funds, per asset. Withdrawals leave the ledger in the transaction that
+// requests them, so none are ever pending in it.
+func SummarizeTreasury(accounts []formance.AccountData, now time.Time) *models.TreasurySummary {
+	byAsset := make(map[string]*models.TreasuryAsset)
+	for _, account := range accounts {
+		for asset, volumes := range account.Volumes {
+			row, ok := byAsset[asset]
+			if !ok {
+				row = &models.TreasuryAsset{Asset: asset, Revenue: make(map[string]int64)}
+				byAsset[asset] = row
+			}
+			addTreasuryBalance(row, account.Address, volumes.Balance)
+		}
+	}
+
+	summary := &models.TreasurySummary{
+		Assets:      make([]models.TreasuryAsset, 0, len(byAsset)),
+		Accounts:    len(accounts),
+		GeneratedAt: now,
+	}
+	for _, row := range byAsset {
+		row.Liabilities = row.WalletLiabilities + row.SessionFunds + row.TournamentEscrow + row.LastLongerEscrow + row.PendingWithdrawals
+		row.HouseFunds = row.House + row.SpinJackpot + row.RevenueTotal
+		row.NetExposure = row.Liabilities - row.HouseFunds
+		summary.Assets = append(summary.Assets, *row)
+	}
+	slices.SortFunc(summary.Assets, func(a, b models.TreasuryAsset) int {
+		return strings.Compare(a.Asset, b.Asset)
+	})
+	return summary
+}
+
+// addTreasuryBalance counts an account's balance under what it holds
+func addTreasuryBalance(row *models.TreasuryAsset, address string, balance int64) {
+	switch {
+	case address == formance.WorldAccount:
+		row.World += balance
+	case address == formance.SystemHouseAccount:
+		row.House += balance
+	case address == formance.SpinJackpotAccount:
+		row.SpinJackpot += balance
+	case strings.HasPrefix(address, tournamentPoolPrefix):
+		row.TournamentEscrow += balance
+	case strings.HasPrefix(address, lastLongerPrefix):
+		row.LastLongerEscrow += balance
+	case strings.HasPrefix(address, revenuePrefix):
+		row.Revenue[address] += balance
+		row.RevenueTotal += balance
+	case strings.HasPrefix(address, formance.PlayerAccountPrefix+":") && strings.HasSuffix(address, ":"+formance.WalletSuffix):
+		row.WalletLiabilities += balance
+	case strings.HasPrefix(address, formance.SessionAccountPrefix+":"):
+		row.SessionFunds += balance
+	default:
+		row.Other += balance
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TutorialRewardChips is the play-chip reward for finishing the tutorial
+const TutorialRewardChips int64 = 5000
+
+var ErrTutorialAlreadyCompleted = errors.New("tutorial already completed")
+
+// TutorialService records tutorial completions and pays the one-off reward
+type TutorialService struct {
+	db *database.DB
+}
+
+// NewTutorialService creates a new tutorial service
+func NewTutorialService(db *database.DB) *TutorialService {
+	return &TutorialService{db: db}
+}
+
+// Complete marks the tutorial as finished and credits the reward to the
+// user's play chips. Replaying the tutorial returns ErrTutorialAlreadyCompleted
+// and the original completion without paying again.
+func (ts *TutorialService) Complete(ctx context.Context, userID uuid.UUID) (*models.TutorialCompletion, error) {
+	completion := &models.TutorialCompletion{
+		UserID:      userID,
+		RewardChips: TutorialRewardChips,
+	}
+
+	err := ts.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(completion)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record tutorial completion: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			if err := tx.Where("user_id = ?", userID).First(completion).Error; err != nil {
+				return fmt.Errorf("failed to load tutorial completion: %w", err)
+			}
+			return ErrTutorialAlreadyCompleted
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("play_chips", gorm.Expr("play_chips + ?", completion.RewardChips)).Error
+		if err != nil {
+			return fmt.Errorf("failed to credit tutorial reward: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrTutorialAlreadyCompleted) {
+			return completion, err
+		}
+		return nil, err
+	}
+
+	slog.Info("Tutorial completed", "user_id", userID, "reward_chips", completion.RewardChips)
+	return completion, nil
+}
+
+// HasCompleted reports whether the user has already finished the tutorial
+func (ts *TutorialService) HasCompleted(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := ts.db.WithContext(ctx).Model(&models.TutorialCompletion{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check tutorial completion: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGameSessionNotFound = errors.New("game session not found")
+	// ErrSessionNotLive is returned by a LiveSessionCloser when no running
+	// table holds the session's chips
+	ErrSessionNotLive = errors.New("game session is not held by a running table")
+)
+
+// LiveSessionCloser cashes a player out of a running table at their own
+// request. Returns ErrHandInProgress while they are dealt into a hand.
+type LiveSessionCloser interface {
+	CloseLiveSession(userID, tableID, sessionID uuid.UUID) (*models.RecoveredSession, error)
+}
+
+// UserSessionTables is what the running tables report and do for players
+// managing their own sessions. Implemented by the WebSocket hub.
+type UserSessionTables interface {
+	LiveSeatReader
+	LiveSessionChecker
+	LiveSessionCloser
+}
+
+// UserSessionService lets players see their active game sessions and close
+// one that is stuck, returning its chips to the wallet
+type UserSessionService struct {
+	db              *database.DB
+	formanceService *formance.Service
+	tables          UserSessionTables
+	recovery        *SessionRecoveryService
+}
+
+// NewUserSessionService creates a new user session service
+func NewUserSessionService(db *database.DB, formanceService *formance.Service, tables UserSessionTables) *UserSessionService {
+	return &UserSessionService{
+		db:              db,
+		formanceService: formanceService,
+		tables:          tables,
+		recovery:        NewSessionRecoveryService(db, formanceService, tables),
+	}
+}
+
+// ListSessions returns the user's active game sessions, oldest first
+func (us *UserSessionService) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	var sessions []models.GameSession
+	err := us.db.WithContext(ctx).
+		Preload("Table").
+		Where("user_id = ? AND status = ?", userID, models.GameSessionStatusActive).
+		Order("joined_at ASC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active sessions: %w", err)
+	}
+
+	live := make(map[uuid.UUID]models.LiveSeat)
+	for _, seat := range us.tables.LiveSeats(userID) {
+		live[seat.TableID] = seat
+	}
+
+	result := make([]models.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		entry := models.UserSession{
+			SessionID:  session.ID,
+			TableID:    session.TableID,
+			TableName:  session.Table.Name,
+			SeatNumber: session.SeatNumber,
+			BuyIn:      session.BuyInAmount,
+			Balance:    session.CurrentChips,
+			JoinedAt:   session.JoinedAt,
+		}
+		if seat, ok := live[session.TableID]; ok {
+			entry.Live = true
+			entry.HandInProgress = seat.HandInProgress
+		}
+
+		balance, err := us.formanceService.GetSessionBalance(ctx, userID, session.ID)
+		if err != nil {
+			// The last chip count recorded is better than no session at all
+			slog.Warn("Failed to get session balance", "user_id", userID, "session_id", session.ID, "error", err)
+		} else {
+			entry.Balance = balance
+		}
+
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// CloseSession ends one of the user's active sessions and returns its chips
+// to their wallet. A seat at a running table is cashed out by the table,
+// which refuses while the user is in a hand; any other session is cashed out
+// from its ledger account.
+func (us *UserSessionService) CloseSession(ctx context.Context, userID, sessionID uuid.UUID) (*models.RecoveredSession, error) {
+	var session models.GameSession
+	err := us.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND status = ?", sessionID, userID, models.GameSessionStatusActive).
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrGameSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	result, err := us.tables.CloseLiveSession(userID, session.TableID, session.ID)
+	if err == nil {
+		slog.Info("Player closed live game session", "user_id", userID, "session_id", session.ID, "amount", result.Amount)
+		return result, nil
+	}
+	if !errors.Is(err, ErrSessionNotLive) {
+		return nil, err
+	}
+
+	return us.recovery.recoverSession(ctx, session)
+}
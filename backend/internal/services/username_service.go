@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUsernameUnchanged = errors.New("that is already your username")
+	ErrUsernameTaken     = errors.New("username is already taken")
+	ErrUsernameReserved  = errors.New("username was recently used by another player and is reserved")
+	ErrUsernameNotFound  = errors.New("no player has used that username")
+)
+
+// UsernameRules limit how often users may rename themselves and how long a
+// name they give up stays reserved, so nobody can pick it up to impersonate
+// them. Zero disables either rule.
+type UsernameRules struct {
+	Cooldown    time.Duration // Minimum time between two changes by one user
+	Reservation time.Duration // How long an old name is held for its former owner
+}
+
+// UsernameCooldownError refuses a change made too soon after the last one
+type UsernameCooldownError struct {
+	NextChangeAt time.Time
+}
+
+func (e *UsernameCooldownError) Error() string {
+	return fmt.Sprintf("username can be changed again after %s", e.NextChangeAt.UTC().Format(time.RFC3339))
+}
+
+// NextChangeAt returns when a user whose last change was at lastChange may
+// change again. The zero time means never changed.
+func (r UsernameRules) NextChangeAt(lastChange time.Time) time.Time {
+	if lastChange.IsZero() {
+		return time.Time{}
+	}
+	return lastChange.Add(r.Cooldown)
+}
+
+// Evaluate checks a change from current to requested. formerHolders are the
+// history rows of everyone who gave up the requested name; the user may
+// always take back a name of their own. Names compare case-insensitively.
+func (r UsernameRules) Evaluate(userID uuid.UUID, current, requested string, lastChange time.Time, formerHolders []models.UsernameHistory, now time.Time) error {
+	if current == requested {
+		return ErrUsernameUnchanged
+	}
+	if next := r.NextChangeAt(lastChange); now.Before(next) {
+		return &UsernameCooldownError{NextChangeAt: next}
+	}
+	for _, holder := range formerHolders {
+		if holder.UserID != userID && strings.EqualFold(holder.OldUsername, requested) && now.Before(holder.ReservedUntil) {
+			return ErrUsernameReserved
+		}
+	}
+	return nil
+}
+
+// UsernameService renames users and keeps the history needed to resolve
+// their old names
+type UsernameService struct {
+	db    *database.DB
+	rules UsernameRules
+}
+
+// NewUsernameService creates a new username service
+func NewUsernameService(db *database.DB, rules UsernameRules) *UsernameService {
+	return &UsernameService{
+		db:    db,
+		rules: rules,
+	}
+}
+
+// ChangeUsername renames the user and reserves their old name. It returns the
+// updated user and when they may change again.
+func (us *UsernameService) ChangeUsername(ctx context.Context, userID uuid.UUID, requested string) (*models.User, time.Time, error) {
+	now := time.Now()
+	var user models.User
+
+	err := us.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+
+		lastChange, err := lastUsernameChange(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		var formerHolders []models.UsernameHistory
+		if err := tx.Where("LOWER(old_username) = LOWER(?)", requested).Find(&formerHolders).Error; err != nil {
+			return fmt.Errorf("failed to check username history: %w", err)
+		}
+
+		if err := us.rules.Evaluate(userID, user.Username, requested, lastChange, formerHolders, now); err != nil {
+			return err
+		}
+
+		// Deleted accounts keep their name in the unique index, so count them too
+		var taken int64
+		if err := tx.Unscoped().Model(&models.User{}).
+			Where("LOWER(username) = LOWER(?) AND id <> ?", requested, userID).
+			Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check username: %w", err)
+		}
+		if taken > 0 {
+			return ErrUsernameTaken
+		}
+
+		change := models.UsernameHistory{
+			UserID:        userID,
+			OldUsername:   user.Username,
+			NewUsername:   requested,
+			ChangedAt:     now,
+			ReservedUntil: now.Add(us.rules.Reservation),
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return fmt.Errorf("failed to record username change: %w", err)
+		}
+
+		if err := tx.Model(&user).Update("username", requested).Error; err != nil {
+			if database.IsUniqueConstraintError(err) {
+				return ErrUsernameTaken
+			}
+			return fmt.Errorf("failed to update username: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	slog.Info("Username changed", "user_id", userID, "username", requested)
+	return &user, us.rules.NextChangeAt(now), nil
+}
+
+// History returns the usernames the user has given up, newest first
+func (us *UsernameService) History(ctx context.Context, userID uuid.UUID) ([]models.UsernameHistory, error) {
+	var history []models.UsernameHistory
+	if err := us.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("changed_at DESC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get username history: %w", err)
+	}
+	return history, nil
+}
+
+// NextChangeAt returns when the user may next change their username
+func (us *UsernameService) NextChangeAt(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	lastChange, err := lastUsernameChange(us.db.WithContext(ctx), userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return us.rules.NextChangeAt(lastChange), nil
+}
+
+// Resolve finds the user behind a username as it was written at a point in
+// time, such as in a hand history or chat log. The zero time asks for the
+// current holder, falling back to whoever last gave the name up.
+func (us *UsernameService) Resolve(ctx context.Context, username string, at time.Time) (*models.UsernameResolution, error) {
+	db := us.db.WithContext(ctx)
+
+	// Whoever held the name at that time gave it up after it
+	if !at.IsZero() {
+		var change models.UsernameHistory
+		err := db.Where("LOWER(old_username) = LOWER(?) AND changed_at > ?", username, at).
+			Order("changed_at ASC").
+			First(&change).Error
+		if err == nil {
+			return us.formerHolder(db, change)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to resolve username: %w", err)
+		}
+	}
+
+	var user models.User
+	err := db.Where("LOWER(username) = LOWER(?)", username).First(&user).Error
+	if err == nil {
+		return &models.UsernameResolution{
+			Username:        username,
+			UserID:          user.ID,
+			CurrentUsername: user.Username,
+			Current:         true,
+		}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to resolve username: %w", err)
+	}
+
+	var change models.UsernameHistory
+	if err := db.Where("LOWER(old_username) = LOWER(?)", username).
+		Order("changed_at DESC").
+		First(&change).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUsernameNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve username: %w", err)
+	}
+	return us.formerHolder(db, change)
+}
+
+func (us *UsernameService) formerHolder(db *gorm.DB, change models.UsernameHistory) (*models.UsernameResolution, error) {
+	var user models.User
+	if err := db.Unscoped().Select("id", "username").First(&user, "id = ?", change.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	changedAt := change.ChangedAt
+	return &models.UsernameResolution{
+		Username:        change.OldUsername,
+		UserID:          user.ID,
+		CurrentUsername: user.Username,
+		ChangedAt:       &changedAt,
+	}, nil
+}
+
+// usernameReserved reports whether another user gave up the name recently
+// enough that it is still held for them
+func usernameReserved(db *gorm.DB, username string, now time.Time) (bool, error) {
+	var reserved int64
+	if err := db.Model(&models.UsernameHistory{}).
+		Where("LOWER(old_username) = LOWER(?) AND reserved_until > ?", username, now).
+		Count(&reserved).Error; err != nil {
+		return false, fmt.Errorf("failed to check username history: %w", err)
+	}
+	return reserved > 0, nil
+}
+
+func lastUsernameChange(db *gorm.DB, userID uuid.UUID) (time.Time, error) {
+	var change models.UsernameHistory
+	err := db.Where("user_id = ?", userID).Order("changed_at DESC").First(&change).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get username history: %w", err)
+	}
+	return change.ChangedAt, nil
+}
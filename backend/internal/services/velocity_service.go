@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUserNotFound             = errors.New("user not found")
+	ErrVelocityOverrideNotFound = errors.New("velocity override not found")
+)
+
+// Velocity limit rules, reported to clients so they can explain a refusal
+const (
+	VelocityRuleHourlyCount = "hourly_count"
+	VelocityRuleDailyCount  = "daily_count"
+	VelocityRuleCooldown    = "cooldown"
+	VelocityRuleDailyCap    = "daily_cap"
+)
+
+const velocityWindow = 24 * time.Hour
+
+// VelocityRules limit how often and how much a user may move money in and
+// out of their wallet. Zero disables a limit.
+type VelocityRules struct {
+	DepositsPerHour    int
+	DepositsPerDay     int
+	WithdrawalsPerDay  int
+	WithdrawalCooldown time.Duration // Minimum time between two withdrawals
+	// Most MNT deposited, and separately withdrawn, in any 24 hours,
+	// indexed by KYC tier. Tiers past the end use the last cap.
+	DailyCaps []int64
+}
+
+// DefaultVelocityRules are the limits used when none are configured
+func DefaultVelocityRules() VelocityRules {
+	return VelocityRules{
+		DepositsPerHour:    5,
+		DepositsPerDay:     20,
+		WithdrawalsPerDay:  3,
+		WithdrawalCooldown: time.Hour,
+		DailyCaps:          []int64{2_000_000, 20_000_000, 200_000_000},
+	}
+}
+
+// DailyCap returns the cumulative daily cap for a KYC tier, 0 for no cap
+func (r VelocityRules) DailyCap(kycTier int) int64 {
+	if len(r.DailyCaps) == 0 {
+		return 0
+	}
+	if kycTier < 0 {
+		kycTier = 0
+	}
+	if kycTier >= len(r.DailyCaps) {
+		kycTier = len(r.DailyCaps) - 1
+	}
+	return r.DailyCaps[kycTier]
+}
+
+// VelocityLimitError explains which rule refused an operation and when it
+// will be allowed again. NextAllowedAt is zero when waiting won't help
+// because the amount is over the cap on its own.
+type VelocityLimitError struct {
+	Rule          string
+	Message       string
+	NextAllowedAt time.Time
+}
+
+func (e *VelocityLimitError) Error() string {
+	return e.Message
+}
+
+// Evaluate checks an operation against the user's completed operations of
+// the same kind in the last 24 hours, oldest first
+func (r VelocityRules) Evaluate(operation string, kycTier int, amount int64, history []models.WalletOperation, now time.Time) error {
+	var recent []models.WalletOperation
+	for _, op := range history {
+		if op.Operation == operation && now.Sub(op.CreatedAt) < velocityWindow {
+			recent = append(recent, op)
+		}
+	}
+
+	dailyLimit := r.DepositsPerDay
+	noun := "deposits"
+	if operation == models.WalletOperationWithdrawal {
+		dailyLimit = r.WithdrawalsPerDay
+		noun = "withdrawals"
+
+		if r.WithdrawalCooldown > 0 && len(recent) > 0 {
+			if last := recent[len(recent)-1].CreatedAt; now.Sub(last) < r.WithdrawalCooldown {
+				return &VelocityLimitError{
+					Rule:          VelocityRuleCooldown,
+					Message:       fmt.Sprintf("Please wait %s between withdrawals", r.WithdrawalCooldown),
+					NextAllowedAt: last.Add(r.WithdrawalCooldown),
+				}
+			}
+		}
+	} else if r.DepositsPerHour > 0 {
+		var lastHour []models.WalletOperation
+		for _, op := range recent {
+			if now.Sub(op.CreatedAt) < time.Hour {
+				lastHour = append(lastHour, op)
+			}
+		}
+		if len(lastHour) >= r.DepositsPerHour {
+			return &VelocityLimitError{
+				Rule:          VelocityRuleHourlyCount,
+				Message:       fmt.Sprintf("You can make at most %d deposits per hour", r.DepositsPerHour),
+				NextAllowedAt: lastHour[len(lastHour)-r.DepositsPerHour].CreatedAt.Add(time.Hour),
+			}
+		}
+	}
+
+	if dailyLimit > 0 && len(recent) >= dailyLimit {
+		return &VelocityLimitError{
+			Rule:          VelocityRuleDailyCount,
+			Message:       fmt.Sprintf("You can make at most %d %s per day", dailyLimit, noun),
+			NextAllowedAt: recent[len(recent)-dailyLimit].CreatedAt.Add(velocityWindow),
+		}
+	}
+
+	limit := r.DailyCap(kycTier)
+	if limit <= 0 {
+		return nil
+	}
+	if amount > limit {
+		return &VelocityLimitError{
+			Rule:    VelocityRuleDailyCap,
+			Message: fmt.Sprintf("The most your verification level allows in %s is %d MNT per day", noun, limit),
+		}
+	}
+
+	var total int64
+	for _, op := range recent {
+		total += op.Amount
+	}
+	if total+amount <= limit {
+		return nil
+	}
+	// Find when enough of the window's operations age out for this one to fit
+	for _, op := range recent {
+		total -= op.Amount
+		if total+amount <= limit {
+			return &VelocityLimitError{
+				Rule:          VelocityRuleDailyCap,
+				Message:       fmt.Sprintf("This would take your %s over %d MNT in 24 hours, the most your verification level allows", noun, limit),
+				NextAllowedAt: op.CreatedAt.Add(velocityWindow),
+			}
+		}
+	}
+	return nil
+}
+
+// VelocityService enforces deposit and withdrawal velocity limits and
+// manages the admin overrides that lift them
+type VelocityService struct {
+	db    *database.DB
+	rules VelocityRules
+}
+
+func NewVelocityService(db *database.DB, rules VelocityRules) *VelocityService {
+	return &VelocityService{db: db, rules: rules}
+}
+
+// Rules returns the limits being enforced
+func (vs *VelocityService) Rules() VelocityRules {
+	return vs.rules
+}
+
+// Reserve counts an operation against the user's limits before its money
+// moves, or returns a *VelocityLimitError if it would break one of them. An
+// active admin override lets everything through. The user's row is locked
+// while the limits are checked, so two requests at once can't both take the
+// last slot. Pass the reservation to Complete once the money has moved, or
+// to Release if it didn't.
+func (vs *VelocityService) Reserve(ctx context.Context, userID uuid.UUID, operation string, amount int64) (*models.WalletOperation, error) {
+	reservation := &models.WalletOperation{
+		UserID:    userID,
+		Operation: operation,
+		Amount:    amount,
+	}
+	err := vs.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "kyc_tier").First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		override, err := activeVelocityOverride(tx, userID, operation)
+		if err != nil {
+			return err
+		}
+		if override != nil {
+			reservation.OverrideID = &override.ID
+		} else {
+			now := time.Now()
+			history, err := walletOperations(tx, userID, operation, now)
+			if err != nil {
+				return err
+			}
+			if err := vs.rules.Evaluate(operation, user.KYCTier, amount, history, now); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(reservation).Error; err != nil {
+			return fmt.Errorf("failed to reserve wallet operation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// Complete records the ledger transaction of a reserved operation
+func (vs *VelocityService) Complete(ctx context.Context, reservation *models.WalletOperation, transactionID string) error {
+	if err := vs.db.WithContext(ctx).Model(reservation).Update("transaction_id", transactionID).Error; err != nil {
+		return fmt.Errorf("failed to record wallet operation: %w", err)
+	}
+	return nil
+}
+
+// Release gives back the slot of a reserved operation whose money didn't move
+func (vs *VelocityService) Release(ctx context.Context, reservation *models.WalletOperation) error {
+	if err := vs.db.WithContext(ctx).Delete(reservation).Error; err != nil {
+		return fmt.Errorf("failed to release wallet operation: %w", err)
+	}
+	return nil
+}
+
+// Summary reports the user's usage of each limit and when they may next
+// deposit or withdraw
+func (vs *VelocityService) Summary(ctx context.Context, userID uuid.UUID) (*models.VelocitySummary, error) {
+	tier, err := vs.kycTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deposits, err := vs.usage(ctx, userID, models.WalletOperationDeposit, tier, now)
+	if err != nil {
+		return nil, err
+	}
+	withdrawals, err := vs.usage(ctx, userID, models.WalletOperationWithdrawal, tier, now)
+	if err != nil {
+		return nil, err
+	}
+	return &models.VelocitySummary{KYCTier: tier, Deposits: *deposits, Withdrawals: *withdrawals}, nil
+}
+
+func (vs *VelocityService) usage(ctx context.Context, userID uuid.UUID, operation string, tier int, now time.Time) (*models.VelocityUsage, error) {
+	usage := &models.VelocityUsage{
+		Operation:  operation,
+		DailyLimit: vs.rules.WithdrawalsPerDay,
+		DailyCap:   vs.rules.DailyCap(tier),
+	}
+	if operation == models.WalletOperationDeposit {
+		usage.HourlyLimit = vs.rules.DepositsPerHour
+		usage.DailyLimit = vs.rules.DepositsPerDay
+	}
+
+	history, err := walletOperations(vs.db.WithContext(ctx), userID, operation, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range history {
+		usage.LastDayCount++
+		usage.LastDayAmount += op.Amount
+		if now.Sub(op.CreatedAt) < time.Hour {
+			usage.LastHourCount++
+		}
+	}
+
+	override, err := activeVelocityOverride(vs.db.WithContext(ctx), userID, operation)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		usage.OverrideUntil = &override.ExpiresAt
+		return usage, nil
+	}
+
+	// The smallest possible operation shows when the count limits lift
+	var limitErr *VelocityLimitError
+	if errors.As(vs.rules.Evaluate(operation, tier, 1, history, now), &limitErr) && !limitErr.NextAllowedAt.IsZero() {
+		usage.NextAllowedAt = &limitErr.NextAllowedAt
+	}
+	return usage, nil
+}
+
+// GrantOverride lifts a user's limits for one operation for a number of hours
+func (vs *VelocityService) GrantOverride(ctx context.Context, userID, adminID uuid.UUID, req models.GrantVelocityOverrideRequest) (*models.VelocityOverride, error) {
+	if _, err := vs.kycTier(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	override := &models.VelocityOverride{
+		UserID:    userID,
+		Operation: req.Operation,
+		Reason:    req.Reason,
+		GrantedBy: adminID,
+		ExpiresAt: time.Now().Add(time.Duration(req.Hours) * time.Hour),
+	}
+	if err := vs.db.WithContext(ctx).Create(override).Error; err != nil {
+		return nil, fmt.Errorf("failed to grant velocity override: %w", err)
+	}
+
+	slog.Info("Velocity override granted", "user_id", userID, "operation", req.Operation, "expires_at", override.ExpiresAt, "admin_id", adminID, "reason", req.Reason)
+	return override, nil
+}
+
+// RevokeOverride ends an override before it expires
+func (vs *VelocityService) RevokeOverride(ctx context.Context, overrideID, adminID uuid.UUID) error {
+	result := vs.db.WithContext(ctx).Model(&models.VelocityOverride{}).
+		Where("id = ? AND revoked_at IS NULL", overrideID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke velocity override: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrVelocityOverrideNotFound
+	}
+
+	slog.Info("Velocity override revoked", "override_id", overrideID, "admin_id", adminID)
+	return nil
+}
+
+// ListOverrides returns a user's overrides, newest first
+func (vs *VelocityService) ListOverrides(ctx context.Context, userID uuid.UUID) ([]models.VelocityOverride, error) {
+	var overrides []models.VelocityOverride
+	if err := vs.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to list velocity overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// SetKYCTier records the identity checks a user has passed
+func (vs *VelocityService) SetKYCTier(ctx context.Context, userID, adminID uuid.UUID, tier int) error {
+	result := vs.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("kyc_tier", tier)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update KYC tier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	slog.Info("KYC tier updated", "user_id", userID, "kyc_tier", tier, "admin_id", adminID)
+	return nil
+}
+
+func (vs *VelocityService) kycTier(ctx context.Context, userID uuid.UUID) (int, error) {
+	return userKYCTier(ctx, vs.db, userID)
+}
+
+// userKYCTier returns the identity checks a user has passed
+func userKYCTier(ctx context.Context, db *database.DB, userID uuid.UUID) (int, error) {
+	var user models.User
+	if err := db.WithContext(ctx).Select("id", "kyc_tier").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.KYCTier, nil
+}
+
+// walletOperations returns the user's operations of a kind in the 24 hours
+// before now, reserved ones included, oldest first
+func walletOperations(db *gorm.DB, userID uuid.UUID, operation string, now time.Time) ([]models.WalletOperation, error) {
+	var history []models.WalletOperation
+	err := db.
+		Where("user_id = ? AND operation = ? AND created_at > ?", userID, operation, now.Add(-velocityWindow)).
+		Order("created_at ASC").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet operations: %w", err)
+	}
+	return history, nil
+}
+
+func activeVelocityOverride(db *gorm.DB, userID uuid.UUID, operation string) (*models.VelocityOverride, error) {
+	var override models.VelocityOverride
+	err := db.
+		Where("user_id = ? AND operation = ? AND revoked_at IS NULL AND expires_at > ?", userID, operation, time.Now()).
+		Order("expires_at DESC").
+		First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check velocity override: %w", err)
+	}
+	return &override, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anhbaysgalan1/gp/internal/database"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// AnyAsset matches every asset in a withdrawal fee rule
+const AnyAsset = "*"
+
+// WithdrawalFeeRule sets the minimum and fees for withdrawals of an asset
+// by users at or above a KYC tier
+type WithdrawalFeeRule struct {
+	Asset     string // AnyAsset for every asset without a rule of its own
+	KYCTier   int    // Applies from this tier up to the next rule's tier
+	Minimum   int64
+	FlatFee   int64
+	FeeBps    int64 // Basis points of the amount, 100 = 1%
+	FreeAbove int64 // Withdrawals of at least this much pay no fee, 0 never
+}
+
+// WithdrawalFeeSchedule is the set of withdrawal fee rules
+type WithdrawalFeeSchedule []WithdrawalFeeRule
+
+// DefaultWithdrawalFeeSchedule keeps a 1,000 minimum and charges no fees
+func DefaultWithdrawalFeeSchedule() WithdrawalFeeSchedule {
+	return WithdrawalFeeSchedule{{Asset: AnyAsset, Minimum: 1000}}
+}
+
+// WithdrawalMinimumError refuses a withdrawal below the minimum, or one
+// whose fees would take all of it
+type WithdrawalMinimumError struct {
+	Asset   string
+	Minimum int64
+}
+
+func (e *WithdrawalMinimumError) Error() string {
+	return fmt.Sprintf("Minimum withdrawal amount is %d %s", e.Minimum, e.Asset)
+}
+
+// Terms returns the rule for an asset and KYC tier: the highest tier rule
+// not above the user's tier, preferring rules for the asset itself over
+// AnyAsset. The zero rule applies when nothing matches.
+func (s WithdrawalFeeSchedule) Terms(asset string, kycTier int) models.WithdrawalFeeTerms {
+	var best *WithdrawalFeeRule
+	for i := range s {
+		rule := &s[i]
+		if rule.KYCTier > kycTier || (rule.Asset != asset && rule.Asset != AnyAsset) {
+			continue
+		}
+		if best == nil {
+			best = rule
+			continue
+		}
+		exact, bestExact := rule.Asset == asset, best.Asset == asset
+		if (exact && !bestExact) || (exact == bestExact && rule.KYCTier > best.KYCTier) {
+			best = rule
+		}
+	}
+
+	terms := models.WithdrawalFeeTerms{Asset: asset, KYCTier: kycTier}
+	if best != nil {
+		terms.Minimum = best.Minimum
+		terms.FlatFee = best.FlatFee
+		terms.FeeBps = best.FeeBps
+		terms.FreeAbove = best.FreeAbove
+	}
+	return terms
+}
+
+// Quote itemizes a withdrawal under the terms for the asset and KYC tier.
+// The percentage fee is rounded down to a whole unit.
+func (s WithdrawalFeeSchedule) Quote(asset string, kycTier int, amount int64) (*models.WithdrawalQuote, error) {
+	terms := s.Terms(asset, kycTier)
+	if amount < terms.Minimum {
+		return nil, &WithdrawalMinimumError{Asset: asset, Minimum: terms.Minimum}
+	}
+
+	quote := &models.WithdrawalQuote{Asset: asset, Amount: amount}
+	if terms.FreeAbove > 0 && amount >= terms.FreeAbove {
+		quote.FeeWaived = terms.FlatFee > 0 || terms.FeeBps > 0
+	} else {
+		quote.FlatFee = terms.FlatFee
+		quote.PercentFee = amount * terms.FeeBps / 10000
+	}
+	quote.Fee = quote.FlatFee + quote.PercentFee
+	quote.NetAmount = amount - quote.Fee
+
+	if quote.NetAmount <= 0 {
+		return nil, &WithdrawalMinimumError{Asset: asset, Minimum: quote.Fee + 1}
+	}
+	return quote, nil
+}
+
+// WithdrawalFeeService applies the withdrawal fee schedule to users
+// according to their KYC tier
+type WithdrawalFeeService struct {
+	db       *database.DB
+	schedule WithdrawalFeeSchedule
+	asset    string
+}
+
+// NewWithdrawalFeeService creates a withdrawal fee service for the ledger's
+// asset
+func NewWithdrawalFeeService(db *database.DB, schedule WithdrawalFeeSchedule, asset string) *WithdrawalFeeService {
+	return &WithdrawalFeeService{
+		db:       db,
+		schedule: schedule,
+		asset:    asset,
+	}
+}
+
+// Terms returns the minimum and fees for the user's withdrawals
+func (fs *WithdrawalFeeService) Terms(ctx context.Context, userID uuid.UUID) (*models.WithdrawalFeeTerms, error) {
+	tier, err := userKYCTier(ctx, fs.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	terms := fs.schedule.Terms(fs.asset, tier)
+	return &terms, nil
+}
+
+// Quote itemizes a withdrawal by the user
+func (fs *WithdrawalFeeService) Quote(ctx context.Context, userID uuid.UUID, amount int64) (*models.WithdrawalQuote, error) {
+	tier, err := userKYCTier(ctx, fs.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return fs.schedule.Quote(fs.asset, tier, amount)
+}
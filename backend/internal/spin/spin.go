@@ -0,0 +1,131 @@
+// Package spin draws the prize pools of spins, three-player hyper-turbo
+// sit-n-gos whose prize pool is a multiple of the buy-in drawn when they fill.
+//
+// The draw is provably fair. When a spin opens the server picks a random
+// server seed and publishes its SHA-256 hash. Each player joining adds a
+// client seed. Once the spin is full the draw is
+//
+//	roll = first 8 bytes, big-endian, of HMAC-SHA256(server seed, client seeds joined by ":" + ":" + tournament ID + ":" + round)
+//
+// starting at round 0. A roll at or above the largest multiple of the total
+// weight is rejected and the next round tried, so every tier is drawn with
+// exactly its weight's share. The tier is the one whose cumulative weight
+// range holds roll mod total weight. The server seed is revealed after the
+// draw, so anyone can check it against the published hash and repeat the
+// draw.
+package spin
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// Algorithm names the draw described in the package documentation
+const Algorithm = "hmac-sha256-weighted-v1"
+
+var (
+	ErrCommitmentMismatch = errors.New("server seed does not match the published hash")
+	ErrNotDrawn           = errors.New("spin has not been drawn yet")
+	ErrDrawMismatch       = errors.New("the seeds draw a different tier")
+)
+
+// NewSeed returns a random seed, hex encoded
+func NewSeed() (string, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return "", fmt.Errorf("failed to generate seed: %w", err)
+	}
+	return hex.EncodeToString(seed), nil
+}
+
+// Commitment is the hash published for a server seed before the draw
+func Commitment(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// ClientSeed combines the players' seeds in the order they joined
+func ClientSeed(seeds []string) string {
+	return strings.Join(seeds, ":")
+}
+
+// Roll is the random number for one round of a spin's draw
+func Roll(serverSeed, clientSeed string, tournamentID uuid.UUID, round int) uint64 {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(clientSeed + ":" + tournamentID.String() + ":" + strconv.Itoa(round)))
+	return binary.BigEndian.Uint64(mac.Sum(nil)[:8])
+}
+
+// Draw picks the tier of a spin from its seeds. The tiers must be valid.
+func Draw(serverSeed, clientSeed string, tournamentID uuid.UUID, tiers []models.SpinTier) int {
+	var total uint64
+	for _, tier := range tiers {
+		total += uint64(tier.Weight)
+	}
+
+	// Rolls from limit up would favour the first tiers
+	rem := (math.MaxUint64%total + 1) % total
+	for round := 0; ; round++ {
+		roll := Roll(serverSeed, clientSeed, tournamentID, round)
+		if rem != 0 && roll > math.MaxUint64-rem {
+			continue
+		}
+
+		point := roll % total
+		for i, tier := range tiers {
+			if point < uint64(tier.Weight) {
+				return i
+			}
+			point -= uint64(tier.Weight)
+		}
+	}
+}
+
+// Proof is everything needed to check a spin's draw
+type Proof struct {
+	TournamentID   uuid.UUID         `json:"tournament_id"`
+	Algorithm      string            `json:"algorithm"`
+	ServerSeedHash string            `json:"server_seed_hash"`
+	ServerSeed     string            `json:"server_seed,omitempty"` // Revealed once drawn
+	ClientSeed     string            `json:"client_seed,omitempty"`
+	Tiers          []models.SpinTier `json:"tiers"`
+	Tier           *int              `json:"tier,omitempty"`
+	Multiplier     int64             `json:"multiplier,omitempty"`
+}
+
+// Verify checks that the revealed server seed is the one committed to and
+// that the seeds draw the tier the spin was given
+func Verify(proof Proof) error {
+	if proof.Tier == nil || proof.ServerSeed == "" {
+		return ErrNotDrawn
+	}
+	if Commitment(proof.ServerSeed) != proof.ServerSeedHash {
+		return ErrCommitmentMismatch
+	}
+	if err := models.ValidateSpinTiers(proof.Tiers); err != nil {
+		return err
+	}
+
+	tier := Draw(proof.ServerSeed, proof.ClientSeed, proof.TournamentID, proof.Tiers)
+	if tier != *proof.Tier || proof.Tiers[tier].Multiplier != proof.Multiplier {
+		return fmt.Errorf("%w: tier %d, %dx", ErrDrawMismatch, tier+1, proof.Tiers[tier].Multiplier)
+	}
+	return nil
+}
+
+// Prizes splits a prize pool by its payout places, rounding down, with
+// what rounding leaves over going to the winner so the pool is paid in full
+func Prizes(prizePool int64, places []models.PayoutPlace) []int64 {
+	return models.PlacePrizes(prizePool, places)
+}
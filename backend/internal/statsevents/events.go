@@ -0,0 +1,122 @@
+// Package statsevents publishes structured domain events from the game
+// server, such as completed hands and finished tournaments, for analytics,
+// fraud and notification consumers that run independently of it.
+package statsevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types, also the last part of the subject an event is published on
+const (
+	TypeHandCompleted      = "hand.completed"
+	TypePotAwarded         = "pot.awarded"
+	TypePlayerSeated       = "player.seated"
+	TypeTournamentFinished = "tournament.finished"
+)
+
+// Event is the envelope every domain event is published in
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewEvent wraps data in an envelope of the given type
+func NewEvent(eventType string, data interface{}) (Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       encoded,
+	}, nil
+}
+
+// Publisher delivers events to their consumers. Publish must not block on
+// slow consumers: it is called from the game loop.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Emit publishes an event built from data, logging rather than returning
+// failures so that analytics never hold up a hand. A nil publisher drops
+// the event.
+func Emit(p Publisher, eventType string, data interface{}) {
+	if p == nil {
+		return
+	}
+	event, err := NewEvent(eventType, data)
+	if err != nil {
+		slog.Warn("Failed to build stats event", "type", eventType, "error", err)
+		return
+	}
+	if err := p.Publish(context.Background(), event); err != nil {
+		slog.Warn("Failed to publish stats event", "type", eventType, "event_id", event.ID, "error", err)
+	}
+}
+
+// Winner is a player paid from a hand or pot
+type Winner struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Amount   int64     `json:"amount"`
+	Half     string    `json:"half,omitempty"` // Split games: "high", "low" or "high_low"
+}
+
+// HandCompleted is published when a hand is settled
+type HandCompleted struct {
+	HandID    string    `json:"hand_id"`
+	TableID   uuid.UUID `json:"table_id,omitempty"`
+	TableName string    `json:"table_name"`
+	TotalPot  int64     `json:"total_pot"`
+	Board     []string  `json:"board"`
+	Players   int       `json:"players"` // Dealt into the hand
+	Winners   []Winner  `json:"winners"`
+}
+
+// PotAwarded is published for each pot of a hand, main pot first
+type PotAwarded struct {
+	HandID    string   `json:"hand_id"`
+	TableName string   `json:"table_name"`
+	Pot       int      `json:"pot"` // 0 for the main pot, then side pots in order
+	Amount    int64    `json:"amount"`
+	Winners   []Winner `json:"winners"`
+}
+
+// PlayerSeated is published when a player buys in and takes a seat
+type PlayerSeated struct {
+	TableID    uuid.UUID `json:"table_id,omitempty"`
+	TableName  string    `json:"table_name"`
+	UserID     uuid.UUID `json:"user_id"`
+	SessionID  uuid.UUID `json:"session_id,omitempty"`
+	SeatNumber int       `json:"seat_number"`
+	BuyIn      int64     `json:"buy_in"`
+}
+
+// TournamentFinished is published when a tournament's results are final
+type TournamentFinished struct {
+	TournamentID uuid.UUID         `json:"tournament_id"`
+	Name         string            `json:"name"`
+	Entrants     int               `json:"entrants"`
+	PrizePool    int64             `json:"prize_pool"`
+	Results      []TournamentPlace `json:"results"`
+}
+
+// TournamentPlace is one player's finish in a tournament
+type TournamentPlace struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Position int       `json:"position"`
+	Prize    int64     `json:"prize"`
+}
@@ -0,0 +1,98 @@
+package statsevents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// inProcessBuffer is how many events may wait for a slow subscriber before
+// new ones are dropped for it
+const inProcessBuffer = 1024
+
+// ErrPublisherClosed is returned when publishing after Close
+var ErrPublisherClosed = errors.New("stats event publisher is closed")
+
+// InProcessPublisher hands events to subscribers in the same process. It is
+// used when no message bus is configured or reachable, so consumers can be
+// built and tested without one.
+type InProcessPublisher struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	closed      bool
+}
+
+type subscriber struct {
+	events  chan Event
+	handler func(Event)
+	done    chan struct{}
+}
+
+// NewInProcessPublisher creates a publisher with no subscribers
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{}
+}
+
+// Subscribe calls handler with every event published from now on, in order,
+// on a goroutine of its own
+func (p *InProcessPublisher) Subscribe(handler func(Event)) {
+	s := &subscriber{
+		events:  make(chan Event, inProcessBuffer),
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for event := range s.events {
+			s.handler(event)
+		}
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		close(s.events)
+		return
+	}
+	p.subscribers = append(p.subscribers, s)
+}
+
+// Publish queues the event for every subscriber. A subscriber whose queue is
+// full misses it rather than holding up the caller.
+func (p *InProcessPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPublisherClosed
+	}
+
+	for _, s := range p.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			slog.Warn("Stats event subscriber is falling behind, dropping event", "type", event.Type, "event_id", event.ID)
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events and waits for subscribers to handle those
+// already queued
+func (p *InProcessPublisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	subscribers := p.subscribers
+	p.subscribers = nil
+	p.mu.Unlock()
+
+	for _, s := range subscribers {
+		close(s.events)
+		<-s.done
+	}
+	return nil
+}
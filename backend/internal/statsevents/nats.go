@@ -0,0 +1,60 @@
+package statsevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes events to a NATS server, each on the subject
+// <prefix>.<type>, e.g. "gp.stats.hand.completed"
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher connects to the NATS server at url. While the connection
+// is down events are buffered by the client and sent on reconnecting.
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("gp-stats-events"),
+		nats.Timeout(5*time.Second),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("Stats events bus disconnected", "error", err)
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			slog.Info("Stats events bus reconnected", "url", conn.ConnectedUrlRedacted())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return &NATSPublisher{conn: conn, prefix: prefix}, nil
+}
+
+// Publish sends the event without waiting for the server to acknowledge it
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if err := p.conn.Publish(p.Subject(event.Type), payload); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Subject is where events of the given type are published
+func (p *NATSPublisher) Subject(eventType string) string {
+	return p.prefix + "." + eventType
+}
+
+// Close sends any buffered events, then disconnects
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
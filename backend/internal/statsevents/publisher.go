@@ -0,0 +1,23 @@
+package statsevents
+
+import (
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+)
+
+// New returns the publisher the configuration asks for: NATS when
+// STATS_EVENTS_NATS_URL is set and reachable, otherwise in process
+func New(cfg *config.Config) Publisher {
+	if cfg.StatsEventsNATSURL == "" {
+		return NewInProcessPublisher()
+	}
+
+	publisher, err := NewNATSPublisher(cfg.StatsEventsNATSURL, cfg.StatsEventsSubjectPrefix)
+	if err != nil {
+		slog.Warn("Stats events bus unreachable, publishing in process", "error", err)
+		return NewInProcessPublisher()
+	}
+	slog.Info("Publishing stats events to NATS", "subject_prefix", cfg.StatsEventsSubjectPrefix)
+	return publisher
+}
@@ -39,7 +39,8 @@ func (suite *AuthIntegrationTestSuite) SetupSuite() {
 	os.Setenv("JWT_SECRET", "test-secret-key-for-jwt-tokens")
 
 	// Load test configuration
-	cfg := config.Load()
+	cfg, err := config.Load()
+	require.NoError(suite.T(), err)
 
 	// Connect to test database
 	db, err := database.NewConnection(cfg)
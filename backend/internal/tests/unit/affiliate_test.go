@@ -0,0 +1,34 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffiliateService_Commission(t *testing.T) {
+	service := services.NewAffiliateService(nil, nil, services.DefaultAffiliateRevenueShare)
+
+	tests := []struct {
+		name     string
+		rake     int64
+		expected int64
+	}{
+		{"no rake", 0, 0},
+		{"rounds down", 3, 0},
+		{"exact share", 1000, 250},
+		{"fractional share", 1001, 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.Commission(tt.rake))
+		})
+	}
+}
+
+func TestNormalizeReferralCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345", services.NormalizeReferralCode("  abcd2345 "))
+	assert.Equal(t, "", services.NormalizeReferralCode("   "))
+}
@@ -1,6 +1,9 @@
 package unit
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -228,3 +231,165 @@ func TestJWTManager_SigningMethod(t *testing.T) {
 	assert.Equal(t, "HS256", parsedToken.Header["alg"])
 	assert.Equal(t, "JWT", parsedToken.Header["typ"])
 }
+
+func TestJWTManager_ReconnectTicket(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret", "test-issuer")
+	userID := uuid.New()
+
+	ticket, expiresAt, err := jwtManager.GenerateReconnectTicket(userID, "testuser", "table-1")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(auth.ReconnectTicketTTL).Unix(), expiresAt.Unix(), 5)
+
+	claims, err := jwtManager.ValidateReconnectTicket(ticket)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, "testuser", claims.Username)
+	assert.Equal(t, "table-1", claims.Table)
+
+	// Tickets and access tokens are not interchangeable
+	_, err = jwtManager.ValidateToken(ticket)
+	assert.Error(t, err)
+
+	token, err := jwtManager.GenerateToken(userID, "testuser", "test@example.com")
+	require.NoError(t, err)
+	_, err = jwtManager.ValidateReconnectTicket(token)
+	assert.Error(t, err)
+
+	// Tickets from another key are rejected
+	wrongManager := auth.NewJWTManager("wrong-secret", "test-issuer")
+	_, err = wrongManager.ValidateReconnectTicket(ticket)
+	assert.Error(t, err)
+
+	// Revoking the user's sessions revokes tickets issued before it
+	sessionClaims := claims.SessionClaims()
+	assert.Equal(t, userID, sessionClaims.UserID)
+	revokedAt := time.Now().Add(2 * time.Second)
+	assert.True(t, auth.SessionRevoked(sessionClaims, &revokedAt))
+	revokedAt = time.Now().Add(-time.Minute)
+	assert.False(t, auth.SessionRevoked(sessionClaims, &revokedAt))
+}
+
+func TestJWTManager_ImpersonationToken(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret", "test-issuer")
+	userID, adminID, sessionID := uuid.New(), uuid.New(), uuid.New()
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	token, err := jwtManager.GenerateImpersonationToken(userID, "testuser", "test@example.com", adminID, sessionID, expiresAt)
+	require.NoError(t, err)
+
+	claims, err := jwtManager.ValidateImpersonationToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, "testuser", claims.Username)
+	assert.Equal(t, adminID, claims.ImpersonatorID)
+	assert.Equal(t, sessionID, claims.SessionID)
+	assert.Equal(t, expiresAt.Unix(), claims.ExpiresAt.Unix())
+
+	// Impersonation tokens never pass as the user's own
+	_, err = jwtManager.ValidateToken(token)
+	assert.Error(t, err)
+
+	userToken, err := jwtManager.GenerateToken(userID, "testuser", "test@example.com")
+	require.NoError(t, err)
+	_, err = jwtManager.ValidateImpersonationToken(userToken)
+	assert.Error(t, err)
+
+	ticket, _, err := jwtManager.GenerateReconnectTicket(userID, "testuser", "table-1")
+	require.NoError(t, err)
+	_, err = jwtManager.ValidateImpersonationToken(ticket)
+	assert.Error(t, err)
+
+	expired, err := jwtManager.GenerateImpersonationToken(userID, "testuser", "test@example.com", adminID, sessionID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = jwtManager.ValidateImpersonationToken(expired)
+	assert.Error(t, err)
+}
+
+func TestJWTManager_OAuthSignupToken(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret", "test-issuer")
+
+	token, expiresAt, err := jwtManager.GenerateOAuthSignupToken("google", "google-sub-1", "player@example.com", true)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(auth.OAuthSignupTTL), expiresAt, time.Second)
+
+	claims, err := jwtManager.ValidateOAuthSignupToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "google", claims.Provider)
+	assert.Equal(t, "google-sub-1", claims.Subject)
+	assert.Equal(t, "player@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+
+	// Signup tokens never pass as access tokens, nor access tokens as signup tokens
+	_, err = jwtManager.ValidateToken(token)
+	assert.Error(t, err)
+
+	userToken, err := jwtManager.GenerateToken(uuid.New(), "testuser", "test@example.com")
+	require.NoError(t, err)
+	_, err = jwtManager.ValidateOAuthSignupToken(userToken)
+	assert.Error(t, err)
+
+	wrongManager := auth.NewJWTManager("wrong-secret", "test-issuer")
+	_, err = wrongManager.ValidateOAuthSignupToken(token)
+	assert.Error(t, err)
+}
+
+type recordedRequest struct {
+	method  string
+	status  int
+	blocked bool
+}
+
+type fakeImpersonationAuditor struct {
+	active   bool
+	requests []recordedRequest
+}
+
+func (f *fakeImpersonationAuditor) ImpersonationActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return f.active, nil
+}
+
+func (f *fakeImpersonationAuditor) RecordImpersonatedRequest(ctx context.Context, sessionID uuid.UUID, method, path string, status int, blocked bool) {
+	f.requests = append(f.requests, recordedRequest{method: method, status: status, blocked: blocked})
+}
+
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret", "test-issuer")
+	userID, adminID := uuid.New(), uuid.New()
+	token, err := jwtManager.GenerateImpersonationToken(userID, "testuser", "test@example.com", adminID, uuid.New(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	var seenUser, seenAdmin uuid.UUID
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenUser, _ = auth.GetUserIDFromContext(r.Context())
+		seenAdmin, _ = auth.GetImpersonatorIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(m *auth.AuthMiddleware, method string) int {
+		req := httptest.NewRequest(method, "/balance", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.RequireAuth(next).ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Refused outright when nothing can record the session
+	assert.Equal(t, http.StatusUnauthorized, serve(auth.NewAuthMiddleware(jwtManager), http.MethodGet))
+
+	auditor := &fakeImpersonationAuditor{active: true}
+	m := auth.NewAuthMiddleware(jwtManager)
+	m.SetImpersonationAuditor(auditor)
+
+	assert.Equal(t, http.StatusOK, serve(m, http.MethodGet))
+	assert.Equal(t, userID, seenUser)
+	assert.Equal(t, adminID, seenAdmin)
+
+	// Writes are refused but still recorded
+	assert.Equal(t, http.StatusForbidden, serve(m, http.MethodPost))
+	assert.Equal(t, []recordedRequest{
+		{method: http.MethodGet, status: http.StatusOK},
+		{method: http.MethodPost, status: http.StatusForbidden, blocked: true},
+	}, auditor.requests)
+
+	auditor.active = false
+	assert.Equal(t, http.StatusUnauthorized, serve(m, http.MethodGet))
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/backfill"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const backfillInput = `time=2026-03-01T10:00:00.000Z level=INFO msg="Hand started" table="Table 1" hand_id=TAB-1A2B-000001
+{"time":"2026-03-01T10:00:00Z","level":"INFO","msg":"Hand started","table":"Table 1","hand_id":"TAB-1A2B-000001"}
+{"id":"7f1c9a52-2b1e-4c55-9d52-0b8a1f0b6f11","type":"hand.completed","occurred_at":"2026-03-01T10:02:00Z","data":{"hand_id":"TAB-1A2B-000001","table_name":"Table 1","total_pot":400,"board":["2C","9D","JH","QS","AD"],"players":2,"winners":[{"user_id":"0b6f7d8e-6a2f-4f5e-9a43-2c1d0e9f8a7b","username":"alice","amount":400}]}}
+time=2026-03-01T10:02:01.000Z level=INFO msg="Hand ended, game state reset" table="Table 1" hand_id=TAB-1A2B-000001
+time=2026-03-01T10:03:00.000Z level=INFO msg="Hand started" table="Table 1" hand_id=TAB-1A2B-000002
+time=2026-03-01T10:03:05.000Z level=WARN msg="Hand voided by misdeal" table="Table 1" hand_id=TAB-1A2B-000002 reason=exposed_card
+time=2026-03-01T10:04:00.000Z level=INFO msg="Player cashed out" table="Table 1" amount=400
+time=2026-03-01T10:05:00.000Z level=INFO msg="Hand started" table="Table 1" hand_id=not-a-hand
+panic: something went wrong
+`
+
+func TestBackfillParser(t *testing.T) {
+	parser := backfill.NewParser()
+	require.NoError(t, parser.Read(strings.NewReader(backfillInput)))
+
+	hands := parser.Hands()
+	require.Len(t, hands, 2)
+	assert.Equal(t, 9, parser.Lines)
+	assert.Equal(t, 1, parser.Duplicates, "the same start logged in both formats counts once")
+	assert.Equal(t, 1, parser.Unreadable)
+
+	settled := hands[0]
+	assert.Equal(t, "TAB-1A2B-000001", settled.HandID)
+	assert.Equal(t, int64(1), settled.Sequence)
+	assert.Equal(t, "Table 1", settled.TableName)
+	assert.True(t, settled.Settled)
+	assert.Equal(t, int64(400), settled.TotalPot)
+	require.Len(t, settled.Winners, 1)
+	assert.Equal(t, "alice", settled.Winners[0].Username)
+	assert.Equal(t, []string{"2C", "9D", "JH", "QS", "AD"}, settled.Board)
+	require.NotNil(t, settled.EndedAt)
+	assert.Equal(t, "2026-03-01T10:02:01Z", settled.EndedAt.UTC().Format("2006-01-02T15:04:05Z07:00"), "the log's end time wins over the event's")
+
+	voided := hands[1]
+	assert.Equal(t, "TAB-1A2B-000002", voided.HandID)
+	assert.True(t, voided.Voided)
+	assert.Zero(t, voided.TotalPot)
+	require.NotNil(t, voided.EndedAt)
+}
@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const depositAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// depositReference completes body with the one check character that makes
+// it a valid reference
+func depositReference(t *testing.T, body string) string {
+	var valid []string
+	for _, c := range depositAlphabet {
+		if code := "GP" + body + string(c); services.ValidDepositReference(code) {
+			valid = append(valid, code)
+		}
+	}
+	require.Len(t, valid, 1, "every body has exactly one check character")
+	return valid[0]
+}
+
+func TestValidDepositReference(t *testing.T) {
+	code := depositReference(t, "ABCD2345")
+
+	assert.True(t, services.ValidDepositReference(code))
+	assert.False(t, services.ValidDepositReference(code[:len(code)-1]), "too short")
+	assert.False(t, services.ValidDepositReference("XX"+code[2:]), "wrong prefix")
+	assert.False(t, services.ValidDepositReference("GPABCD2345"+"1"), "1 isn't in the alphabet")
+
+	// A single mistyped character or swapped pair of neighbours is caught
+	assert.False(t, services.ValidDepositReference("GPABCE2345"+code[len(code)-1:]))
+	assert.False(t, services.ValidDepositReference("GPABDC2345"+code[len(code)-1:]))
+}
+
+func TestFindDepositReferences(t *testing.T) {
+	first := depositReference(t, "ABCD2345")
+	second := depositReference(t, "ZZZZ9999")
+
+	spaced := strings.ToLower(first[:2] + "-" + first[2:6] + " " + first[6:])
+	assert.Equal(t, []string{first}, services.FindDepositReferences("Deposit for poker "+spaced+" thanks"))
+	assert.Equal(t, []string{first}, services.FindDepositReferences(first+" "+first), "repeats are reported once")
+	assert.Equal(t, []string{first, second}, services.FindDepositReferences(first+" and "+second))
+
+	assert.Empty(t, services.FindDepositReferences("Deposit for poker"))
+	assert.Empty(t, services.FindDepositReferences("GPABCD2345"), "no check character")
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuyRule_CooldownRemaining(t *testing.T) {
+	now := time.Now()
+	rule := services.RebuyRule{Cooldown: 30 * time.Minute}
+
+	assert.Equal(t, 20*time.Minute, rule.CooldownRemaining(now.Add(-10*time.Minute), now))
+	assert.Zero(t, rule.CooldownRemaining(now.Add(-time.Hour), now))
+	assert.Zero(t, services.RebuyRule{}.CooldownRemaining(now, now), "no cool-down configured")
+}
+
+func TestRebuyRule_ExceedsWarning(t *testing.T) {
+	rule := services.RebuyRule{WarnPercent: 50}
+
+	assert.False(t, rule.ExceedsWarning(500, 1000), "exactly the threshold is allowed")
+	assert.True(t, rule.ExceedsWarning(501, 1000))
+	assert.True(t, rule.ExceedsWarning(100, 0))
+	assert.False(t, services.RebuyRule{}.ExceedsWarning(1000, 10), "no warning configured")
+}
+
+func TestRebuyRule_TightenKeepsStricterLimits(t *testing.T) {
+	jurisdiction := services.RebuyRule{Cooldown: 15 * time.Minute, WarnPercent: 50}
+	minutes := func(n int) *int { return &n }
+
+	assert.Equal(t, jurisdiction, jurisdiction.Tighten(nil, nil))
+	assert.Equal(t,
+		services.RebuyRule{Cooldown: time.Hour, WarnPercent: 25},
+		jurisdiction.Tighten(minutes(60), minutes(25)))
+	assert.Equal(t, jurisdiction, jurisdiction.Tighten(minutes(5), minutes(80)), "users can't loosen their jurisdiction's limits")
+	assert.Equal(t,
+		services.RebuyRule{WarnPercent: 40},
+		services.RebuyRule{}.Tighten(minutes(0), minutes(40)), "a user's warning applies where the jurisdiction has none")
+}
+
+func TestBankrollRules_Jurisdictions(t *testing.T) {
+	rules := services.BankrollRules{
+		DefaultJurisdiction: "MN",
+		Jurisdictions: map[string]services.RebuyRule{
+			"*":  {WarnPercent: 50},
+			"KR": {Cooldown: 30 * time.Minute, WarnPercent: 25},
+		},
+	}
+
+	assert.Equal(t, services.RebuyRule{Cooldown: 30 * time.Minute, WarnPercent: 25}, rules.ForJurisdiction("KR"))
+	assert.Equal(t, services.RebuyRule{WarnPercent: 50}, rules.ForJurisdiction("MN"), "falls back to *")
+
+	assert.True(t, rules.Known("MN"))
+	assert.True(t, rules.Known("KR"))
+	assert.False(t, rules.Known("*"))
+	assert.False(t, rules.Known("US"))
+}
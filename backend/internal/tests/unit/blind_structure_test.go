@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlindStructure(t *testing.T) {
+	t.Run("Big blind ante levels", func(t *testing.T) {
+		levels, err := models.ParseBlindStructure(json.RawMessage(`[
+			{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 300},
+			{"level": 2, "small_blind": 50, "big_blind": 100, "ante": 100, "big_blind_ante": true, "duration": 300}
+		]`))
+		require.NoError(t, err)
+		require.Len(t, levels, 2)
+
+		assert.Zero(t, levels[0].Ante)
+		assert.Equal(t, int64(100), levels[1].Ante)
+		assert.True(t, levels[1].BigBlindAnte)
+	})
+
+	tests := []struct {
+		name      string
+		structure string
+		errorMsg  string
+	}{
+		{"empty", `[]`, "at least one level"},
+		{"not json", `{"level": 1}`, "invalid blind structure"},
+		{"out of order", `[{"level": 2, "small_blind": 25, "big_blind": 50, "duration": 300}]`, "out of order"},
+		{"small blind too large", `[{"level": 1, "small_blind": 60, "big_blind": 50, "duration": 300}]`, "small blind larger"},
+		{"big blind ante too large", `[{"level": 1, "small_blind": 25, "big_blind": 50, "ante": 75, "big_blind_ante": true, "duration": 300}]`, "big blind ante larger"},
+		{"decreasing blinds", `[
+			{"level": 1, "small_blind": 50, "big_blind": 100, "duration": 300},
+			{"level": 2, "small_blind": 25, "big_blind": 50, "duration": 300}
+		]`, "lower big blind"},
+		{"no duration", `[{"level": 1, "small_blind": 25, "big_blind": 50}]`, "positive duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.ParseBlindStructure(json.RawMessage(tt.structure))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/bots"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotDecide(t *testing.T) {
+	bot := bots.New(1)
+
+	t.Run("raises aces", func(t *testing.T) {
+		d := bot.Decide(bots.Spot{Hole: cards("As", "Ad"), Board: cards(), Opponents: 1, Pot: 30, ToCall: 10, Stack: 1000, MinRaise: 20})
+		assert.Equal(t, bots.ActionRaise, d.Action)
+		// Call 10, then two thirds of the 40 pot or the minimum raise
+		assert.Equal(t, uint(10+26), d.Amount)
+	})
+
+	t.Run("folds trash to a big bet", func(t *testing.T) {
+		d := bot.Decide(bots.Spot{Hole: cards("7c", "2d"), Board: cards("As", "Kd", "Qh"), Opponents: 1, Pot: 100, ToCall: 300, Stack: 1000, MinRaise: 300})
+		assert.Equal(t, bots.ActionFold, d.Action)
+	})
+
+	t.Run("checks when it is free", func(t *testing.T) {
+		d := bot.Decide(bots.Spot{Hole: cards("7c", "2d"), Board: cards("As", "Kd", "Qh"), Opponents: 2, Pot: 60, Stack: 1000, MinRaise: 20})
+		assert.Equal(t, bots.ActionCheck, d.Action)
+	})
+
+	t.Run("calls a cheap price with a draw", func(t *testing.T) {
+		d := bot.Decide(bots.Spot{Hole: cards("9h", "8h"), Board: cards("Th", "Jc", "2h"), Opponents: 1, Pot: 200, ToCall: 20, Stack: 1000, MinRaise: 20})
+		assert.Contains(t, []string{bots.ActionCall, bots.ActionRaise}, d.Action)
+	})
+
+	t.Run("goes all in when the stack can't cover a full raise", func(t *testing.T) {
+		d := bot.Decide(bots.Spot{Hole: cards("Ks", "Kd"), Board: cards(), Opponents: 1, Pot: 300, ToCall: 100, Stack: 150, MinRaise: 100})
+		assert.Equal(t, bots.ActionRaise, d.Action)
+		assert.Equal(t, uint(150), d.Amount)
+	})
+
+	t.Run("never raises without Hold'em equity", func(t *testing.T) {
+		omaha := cards("As", "Ad", "Ks", "Kd")
+		assert.Equal(t, bots.ActionCheck, bot.Decide(bots.Spot{Hole: omaha, Opponents: 1, Pot: 40, Stack: 1000, MinRaise: 20}).Action)
+		assert.Equal(t, bots.ActionCall, bot.Decide(bots.Spot{Hole: omaha, Opponents: 1, Pot: 100, ToCall: 20, Stack: 1000, MinRaise: 20}).Action)
+		assert.Equal(t, bots.ActionFold, bot.Decide(bots.Spot{Hole: omaha, Opponents: 1, Pot: 100, ToCall: 500, Stack: 1000, MinRaise: 500}).Action)
+	})
+}
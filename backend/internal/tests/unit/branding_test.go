@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBranding(t *testing.T) {
+	branding, err := services.NormalizeBranding(models.UpdateBrandingRequest{
+		BannerURL:   " https://cdn.example.com/banners/sunday.png ",
+		SponsorName: "  Khan Bank ",
+		AccentColor: "#F60",
+		Description: "**Sunday Major**, sponsored by Khan Bank\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.Branding{
+		BannerURL:   "https://cdn.example.com/banners/sunday.png",
+		SponsorName: "Khan Bank",
+		AccentColor: "#ff6600",
+		Description: "**Sunday Major**, sponsored by Khan Bank",
+	}, branding)
+
+	// Everything empty clears the branding
+	branding, err = services.NormalizeBranding(models.UpdateBrandingRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, models.Branding{}, branding)
+}
+
+func TestNormalizeBranding_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.UpdateBrandingRequest
+	}{
+		{"banner over http", models.UpdateBrandingRequest{BannerURL: "http://cdn.example.com/banner.png"}},
+		{"banner script", models.UpdateBrandingRequest{BannerURL: "javascript:alert(1)"}},
+		{"banner without a host", models.UpdateBrandingRequest{BannerURL: "https:///banner.png"}},
+		{"color with alpha", models.UpdateBrandingRequest{AccentColor: "#ff660080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.NormalizeBranding(tt.req)
+			assert.ErrorIs(t, err, services.ErrInvalidBranding)
+		})
+	}
+}
+
+func TestUpdateBrandingRequest_Validation(t *testing.T) {
+	valid := models.UpdateBrandingRequest{BannerURL: "https://cdn.example.com/banner.png", AccentColor: "#0a7a3f"}
+	assert.NoError(t, validation.Validate(&valid))
+
+	notURL := models.UpdateBrandingRequest{BannerURL: "banner.png"}
+	assert.Error(t, validation.Validate(&notURL))
+
+	notColor := models.UpdateBrandingRequest{AccentColor: "green"}
+	assert.Error(t, validation.Validate(&notColor))
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClonedTable_CopiesConfiguration(t *testing.T) {
+	hash := "hashed"
+	templateID := uuid.New()
+	callTime := time.Now().Add(-time.Hour)
+	source := &models.PokerTable{
+		ID:                  uuid.New(),
+		Name:                "Friday Home Game",
+		TableType:           "cash",
+		GameType:            "omaha",
+		MaxPlayers:          6,
+		MinBuyIn:            10000,
+		MaxBuyIn:            50000,
+		SmallBlind:          100,
+		BigBlind:            200,
+		IsPrivate:           true,
+		PasswordHash:        &hash,
+		Status:              "active",
+		CurrentPlayers:      5,
+		CreatedBy:           uuid.New(),
+		TemplateID:          &templateID,
+		SeatSelection:       "random",
+		EnforceSeparation:   true,
+		AllowPartialCashOut: true,
+		CallTime:            &callTime,
+		CallTimeHands:       3,
+		MinPlayMinutes:      60,
+		BigWinAmount:        100000,
+		MinPlayers:          3,
+		ShortHandedMinutes:  0,
+		StackType:           "deep",
+
+		ActionTimeoutSeconds: 45,
+		SitOutAfterTimeouts:  0,
+		SitOutCashOutMinutes: 20,
+
+		Branding: models.Branding{SponsorName: "Khan Bank", AccentColor: "#0a7a3f"},
+	}
+	adminID := uuid.New()
+	nextCall := time.Now().Add(7 * 24 * time.Hour)
+
+	table := services.ClonedTable(source, "Friday Home Game 2", &nextCall, adminID)
+
+	assert.Equal(t, "Friday Home Game 2", table.Name)
+	assert.Equal(t, uuid.Nil, table.ID)
+	assert.Equal(t, "omaha", table.GameType)
+	assert.Equal(t, 6, table.MaxPlayers)
+	assert.Equal(t, int64(10000), table.MinBuyIn)
+	assert.Equal(t, int64(50000), table.MaxBuyIn)
+	assert.Equal(t, int64(100), table.SmallBlind)
+	assert.Equal(t, int64(200), table.BigBlind)
+	assert.True(t, table.IsPrivate)
+	assert.Equal(t, &hash, table.PasswordHash)
+	assert.Equal(t, "random", table.SeatSelection)
+	assert.Equal(t, "deep", table.StackType)
+	assert.True(t, table.EnforceSeparation)
+	assert.True(t, table.AllowPartialCashOut)
+	assert.Equal(t, 3, table.CallTimeHands)
+	assert.Equal(t, 60, table.MinPlayMinutes)
+	assert.Equal(t, int64(100000), table.BigWinAmount)
+	assert.Equal(t, 3, table.MinPlayers)
+	assert.Equal(t, 0, table.ShortHandedMinutes)
+	assert.Equal(t, 45, table.ActionTimeoutSeconds)
+	assert.Equal(t, 0, table.SitOutAfterTimeouts)
+	assert.Equal(t, 20, table.SitOutCashOutMinutes)
+	assert.Equal(t, source.Branding, table.Branding)
+
+	// Per-run state is not copied
+	assert.Equal(t, "waiting", table.Status)
+	assert.Equal(t, 0, table.CurrentPlayers)
+	assert.Equal(t, adminID, table.CreatedBy)
+	assert.Equal(t, &nextCall, table.CallTime)
+	assert.True(t, table.TemplateID == nil)
+}
+
+func TestPokerTable_ZeroedDefaults(t *testing.T) {
+	table := &models.PokerTable{
+		ShortHandedMinutes:   10,
+		ActionTimeoutSeconds: 0,
+		SitOutAfterTimeouts:  2,
+		SitOutCashOutMinutes: 0,
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"action_timeout_seconds":   0,
+		"sit_out_cash_out_minutes": 0,
+	}, table.ZeroedDefaults())
+}
+
+func TestClonedTournament_CopiesStructure(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour)
+	end := time.Now().Add(-time.Hour)
+	source := &models.Tournament{
+		ID:                uuid.New(),
+		Name:              "Sunday Major",
+		TournamentType:    "scheduled",
+		BuyIn:             50000,
+		PrizePool:         2500000,
+		MaxPlayers:        200,
+		RegisteredPlayers: 50,
+		Status:            "finished",
+		StartTime:         &start,
+		EndTime:           &end,
+		BlindStructure:    json.RawMessage(`[{"level":1,"small_blind":25,"big_blind":50,"duration":600}]`),
+		PayoutStructure:   json.RawMessage(`[{"position":1,"percentage":100}]`),
+		PayoutModel:       json.RawMessage(`{"paid_percent":15,"steepness":1.2}`),
+		StartingChips:     20000,
+		CurrentLevel:      12,
+		Branding:          models.Branding{BannerURL: "https://cdn.example.com/sunday.png", Description: "**Sponsored** by Khan Bank"},
+	}
+	nextStart := time.Now().Add(7 * 24 * time.Hour)
+
+	tournament := services.ClonedTournament(source, "Sunday Major #2", &nextStart)
+
+	assert.Equal(t, "Sunday Major #2", tournament.Name)
+	assert.Equal(t, uuid.Nil, tournament.ID)
+	assert.Equal(t, "scheduled", tournament.TournamentType)
+	assert.Equal(t, int64(50000), tournament.BuyIn)
+	assert.Equal(t, 200, tournament.MaxPlayers)
+	assert.Equal(t, source.BlindStructure, tournament.BlindStructure)
+	assert.Equal(t, source.PayoutStructure, tournament.PayoutStructure)
+	assert.Equal(t, source.PayoutModel, tournament.PayoutModel)
+	assert.Equal(t, int64(20000), tournament.StartingChips)
+	assert.Equal(t, &nextStart, tournament.StartTime)
+	assert.Equal(t, source.Branding, tournament.Branding)
+
+	// A clone starts over with registration open
+	assert.Equal(t, "registering", tournament.Status)
+	assert.Equal(t, int64(0), tournament.PrizePool)
+	assert.Equal(t, 0, tournament.RegisteredPlayers)
+	assert.Equal(t, 0, tournament.CurrentLevel)
+	assert.True(t, tournament.EndTime == nil)
+}
+
+func TestCloneRequests_Validation(t *testing.T) {
+	assert.NoError(t, validation.Validate(&models.CloneTableRequest{Name: "Table 2"}))
+	assert.Error(t, validation.Validate(&models.CloneTableRequest{Name: "T"}))
+	assert.NoError(t, validation.Validate(&models.CloneTournamentRequest{Name: "Sunday Major #2"}))
+	assert.Error(t, validation.Validate(&models.CloneTournamentRequest{}))
+}
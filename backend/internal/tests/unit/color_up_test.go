@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorUpAt(t *testing.T) {
+	levels, err := models.ParseBlindStructure(json.RawMessage(`[
+		{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 600, "chip_denominations": [25, 100, 500]},
+		{"level": 2, "small_blind": 50, "big_blind": 100, "duration": 600, "chip_denominations": [25, 100, 500]},
+		{"level": 3, "small_blind": 100, "big_blind": 200, "ante": 200, "big_blind_ante": true, "duration": 600, "chip_denominations": [100, 500, 1000]}
+	]`))
+	require.NoError(t, err)
+	assert.True(t, models.HasChipDenominations(levels))
+
+	removed, _ := models.ColorUpAt(levels, 2)
+	assert.Empty(t, removed)
+
+	removed, smallest := models.ColorUpAt(levels, 3)
+	assert.Equal(t, []int64{25}, removed)
+	assert.Equal(t, int64(100), smallest)
+}
+
+func TestParseBlindStructure_Denominations(t *testing.T) {
+	tests := []struct {
+		name      string
+		structure string
+		errorMsg  string
+	}{
+		{"missing on later level", `[
+			{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 600, "chip_denominations": [25, 100]},
+			{"level": 2, "small_blind": 50, "big_blind": 100, "duration": 600}
+		]`, "missing chip denominations"},
+		{"blinds not payable", `[
+			{"level": 1, "small_blind": 25, "big_blind": 50, "duration": 600, "chip_denominations": [100, 500]}
+		]`, "multiples of the smallest chip"},
+		{"removing a large chip", `[
+			{"level": 1, "small_blind": 100, "big_blind": 200, "duration": 600, "chip_denominations": [100, 500, 1000]},
+			{"level": 2, "small_blind": 100, "big_blind": 200, "duration": 600, "chip_denominations": [100, 500]}
+		]`, "only chips smaller than"},
+		{"not ascending", `[
+			{"level": 1, "small_blind": 100, "big_blind": 200, "duration": 600, "chip_denominations": [500, 100]}
+		]`, "positive and ascending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.ParseBlindStructure(json.RawMessage(tt.structure))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestRaceOff(t *testing.T) {
+	entrants := []models.ColorUpResult{
+		{UserID: uuid.New(), TableNumber: 1, SeatNumber: 1, StackBefore: 10075}, // 3 odd chips
+		{UserID: uuid.New(), TableNumber: 1, SeatNumber: 4, StackBefore: 5050},  // 2 odd chips
+		{UserID: uuid.New(), TableNumber: 1, SeatNumber: 7, StackBefore: 25},    // only odd chips
+		{UserID: uuid.New(), TableNumber: 2, SeatNumber: 2, StackBefore: 8000},  // nothing to race
+	}
+
+	results, raced, err := services.RaceOff(entrants, []int64{25}, 100)
+	require.NoError(t, err)
+	require.Len(t, results, len(entrants))
+
+	// 150 odd at table 1 rounds up to two 100 chips
+	assert.Equal(t, 2, raced)
+
+	byUser := make(map[uuid.UUID]models.ColorUpResult)
+	winners := 0
+	for _, r := range results {
+		byUser[r.UserID] = r
+		assert.Zero(t, r.StackAfter%100, "every stack must be in chips still in play")
+		if r.WonRace {
+			winners++
+			assert.Equal(t, r.StackBefore-r.OddChips+100, r.StackAfter, "a player wins at most one chip")
+		}
+	}
+	assert.Equal(t, 2, winners)
+
+	assert.Len(t, byUser[entrants[0].UserID].Cards, 3)
+	assert.Len(t, byUser[entrants[1].UserID].Cards, 2)
+	assert.Len(t, byUser[entrants[2].UserID].Cards, 1)
+	assert.Empty(t, byUser[entrants[3].UserID].Cards)
+	assert.Equal(t, int64(8000), byUser[entrants[3].UserID].StackAfter)
+
+	// The player holding only odd chips can never be raced out
+	short := byUser[entrants[2].UserID]
+	assert.Equal(t, int64(100), short.StackAfter)
+	assert.True(t, short.WonRace || short.Protected)
+}
+
+func TestRaceOff_NoOddChips(t *testing.T) {
+	entrants := []models.ColorUpResult{
+		{UserID: uuid.New(), TableNumber: 1, SeatNumber: 1, StackBefore: 1000},
+		{UserID: uuid.New(), TableNumber: 1, SeatNumber: 2, StackBefore: 2500},
+	}
+
+	results, raced, err := services.RaceOff(entrants, []int64{25}, 100)
+	require.NoError(t, err)
+	assert.Zero(t, raced)
+	for i, r := range results {
+		assert.Equal(t, entrants[i].StackBefore, r.StackAfter)
+	}
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywordModerator_Check(t *testing.T) {
+	moderator := services.NewKeywordModerator("tilter*")
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"clean english", "nice hand, well played", nil},
+		{"clean mongolian", "сайхан тоглолт байлаа", nil},
+		{"english profanity", "what the fuck", []string{services.ModerationReasonProfanity}},
+		{"disguised profanity", "sh1iiit river", []string{services.ModerationReasonProfanity}},
+		{"mongolian profanity", "чи новш юм", []string{services.ModerationReasonProfanity}},
+		{"latin mongolian profanity", "gichii min", []string{services.ModerationReasonProfanity}},
+		{"extra word", "such a tilterboy", []string{services.ModerationReasonProfanity}},
+		{"no scunthorpe", "shipping the pot to the classic player", nil},
+		{"link", "free chips at www.example.com", []string{services.ModerationReasonLink}},
+		{"bare domain", "join bestpoker.mn now", []string{services.ModerationReasonLink}},
+		{"mongolian phone", "call me 9911-2233", []string{services.ModerationReasonPhone}},
+		{"international phone", "whatsapp +976 88 11 22 33", []string{services.ModerationReasonPhone}},
+		{"chip count", "I lost 10 000 000 on that hand", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := moderator.Check(context.Background(), uuid.New(), tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, verdict.Reasons)
+			assert.Equal(t, len(tt.expected) > 0, verdict.Flagged())
+		})
+	}
+}
+
+func TestKeywordModerator_RepeatedMessages(t *testing.T) {
+	moderator := services.NewKeywordModerator()
+	sender := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		verdict, err := moderator.Check(context.Background(), sender, "Join my table!")
+		require.NoError(t, err)
+		assert.False(t, verdict.Flagged())
+	}
+
+	verdict, err := moderator.Check(context.Background(), sender, "join my  TABLE!")
+	require.NoError(t, err)
+	assert.Equal(t, []string{services.ModerationReasonRepeat}, verdict.Reasons)
+
+	// Other senders are tracked separately
+	verdict, err = moderator.Check(context.Background(), uuid.New(), "Join my table!")
+	require.NoError(t, err)
+	assert.False(t, verdict.Flagged())
+}
+
+func TestPenaltyFor_Escalates(t *testing.T) {
+	assert.Equal(t, time.Duration(0), services.PenaltyFor(1))
+	assert.Equal(t, 10*time.Minute, services.PenaltyFor(2))
+	assert.Equal(t, time.Hour, services.PenaltyFor(3))
+	assert.Equal(t, 24*time.Hour, services.PenaltyFor(4))
+	assert.Equal(t, 24*time.Hour, services.PenaltyFor(10))
+}
+
+func TestChatModerationService_RateLimit(t *testing.T) {
+	cms := services.NewChatModerationService(nil)
+	sender, other := uuid.New(), uuid.New()
+	now := time.Now()
+
+	assert.True(t, cms.AllowRate(sender, now), "no limit until one is set")
+
+	cms.SetRateLimit(3, 10*time.Second)
+	for i := 0; i < 3; i++ {
+		assert.True(t, cms.AllowRate(sender, now.Add(time.Duration(i)*time.Second)))
+	}
+	assert.False(t, cms.AllowRate(sender, now.Add(3*time.Second)), "a fourth message inside the window")
+	assert.True(t, cms.AllowRate(other, now.Add(3*time.Second)), "each sender has their own limit")
+
+	// Refused messages don't count, so the first slot frees up on time
+	assert.True(t, cms.AllowRate(sender, now.Add(10*time.Second+time.Millisecond)))
+	assert.False(t, cms.AllowRate(sender, now.Add(10500*time.Millisecond)))
+
+	cms.SetRateLimit(0, 10*time.Second)
+	assert.True(t, cms.AllowRate(sender, now.Add(11*time.Second)), "0 turns the limit off")
+}
+
+func TestTableChatMute_ActiveAt(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(10 * time.Minute)
+
+	untilLifted := models.TableChatMute{}
+	assert.True(t, untilLifted.ActiveAt(now.Add(24*time.Hour)))
+
+	timed := models.TableChatMute{ExpiresAt: &expires}
+	assert.True(t, timed.ActiveAt(now))
+	assert.False(t, timed.ActiveAt(expires))
+
+	lifted := models.TableChatMute{ExpiresAt: &expires, LiftedAt: &now}
+	assert.False(t, lifted.ActiveAt(now.Add(time.Minute)))
+}
@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectMessageService_SendMessageValidation(t *testing.T) {
+	// These checks run before any database access
+	service := services.NewDirectMessageService(nil)
+	ctx := context.Background()
+	sender := uuid.New()
+
+	_, err := service.SendMessage(ctx, sender, uuid.New(), "   ")
+	assert.ErrorIs(t, err, services.ErrEmptyMessage)
+
+	_, err = service.SendMessage(ctx, sender, uuid.New(), strings.Repeat("a", 1001))
+	assert.ErrorIs(t, err, services.ErrMessageTooLong)
+
+	_, err = service.SendMessage(ctx, sender, sender, "hello me")
+	assert.ErrorIs(t, err, services.ErrCannotMessageSelf)
+}
+
+func TestDirectMessageService_BlockSelf(t *testing.T) {
+	service := services.NewDirectMessageService(nil)
+	userID := uuid.New()
+
+	err := service.BlockUser(context.Background(), userID, userID)
+	assert.ErrorIs(t, err, services.ErrCannotBlockSelf)
+}
+
+func TestReportMessageRequest_Validation(t *testing.T) {
+	valid := models.ReportMessageRequest{MessageID: uuid.New(), Reason: "spam links"}
+	assert.NoError(t, validation.Validate(&valid))
+
+	missingReason := models.ReportMessageRequest{MessageID: uuid.New()}
+	assert.Error(t, validation.Validate(&missingReason))
+
+	review := models.ReviewMessageReportRequest{Status: "ignored"}
+	assert.Error(t, validation.Validate(&review))
+}
@@ -222,7 +222,7 @@ func TestEmailService_SendPasswordResetEmail(t *testing.T) {
 	emailService := services.NewEmailService(cfg)
 
 	// Test sending password reset email
-	err = emailService.SendPasswordResetEmail("user@example.com", "testuser", "reset-token-456")
+	err = emailService.SendPasswordResetEmail("user@example.com", "testuser", "reset-token-456", time.Hour)
 	assert.NoError(t, err)
 
 	// Give some time for the message to be processed
@@ -330,7 +330,7 @@ func TestEmailService_Templates(t *testing.T) {
 		{
 			name: "Password reset email template",
 			function: func(es *services.EmailService) error {
-				return es.SendPasswordResetEmail("test@example.com", "jane_smith", "reset456")
+				return es.SendPasswordResetEmail("test@example.com", "jane_smith", "reset456", time.Hour)
 			},
 			subject: "Reset your password - Poker Platform",
 			keywords: []string{
@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/aggregates"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/table"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hiLoShowdown plays three 100 chip stacks all in with the given hole cards
+// and board at a hi-lo table
+func hiLoShowdown(t *testing.T, holes [][]string, board []string) *game.Game {
+	t.Helper()
+	g, ids := allInGame(t, []int64{100, 100, 100}, holes, board)
+	g.HiLo = true
+	for _, id := range ids {
+		require.NoError(t, g.Actions.PlayerBet(g, id, 100))
+	}
+	runOut(t, g)
+	require.Len(t, g.Pots, 1)
+	return g
+}
+
+func TestEngineHiLo_SplitsHighAndLow(t *testing.T) {
+	g := hiLoShowdown(t, [][]string{{"As", "Ah"}, {"3s", "4h"}, {"Kh", "Js"}}, []string{"2c", "5s", "7h", "Kd", "Qc"})
+
+	assert.Equal(t, []uuid.UUID{g.Players[0].ID}, g.Pots[0].WinningPlayers)
+	assert.Equal(t, []uuid.UUID{g.Players[1].ID}, g.Pots[0].LowPlayers)
+	assert.Len(t, g.Pots[0].LowHand, 5)
+	assert.Equal(t, int64(150), g.Players[0].Chips, "aces take the high half")
+	assert.Equal(t, int64(150), g.Players[1].Chips, "seven-five low takes the low half")
+	assert.Zero(t, g.Players[2].Chips)
+}
+
+func TestEngineHiLo_HighScoopsWithoutALow(t *testing.T) {
+	g := hiLoShowdown(t, [][]string{{"As", "Ah"}, {"3s", "4h"}, {"Kh", "2s"}}, []string{"Tc", "9s", "7h", "Kd", "Qc"})
+
+	assert.Empty(t, g.Pots[0].LowPlayers)
+	assert.Equal(t, int64(300), g.Players[0].Chips)
+	assert.Zero(t, g.Players[1].Chips)
+}
+
+func TestEngineHiLo_QuartersATiedLow(t *testing.T) {
+	// Both ace-four hands make the same low, only the hearts make a flush
+	g := hiLoShowdown(t, [][]string{{"Ah", "4h"}, {"Ad", "4s"}, {"Ks", "Kc"}}, []string{"2h", "3h", "7h", "Kd", "8c"})
+
+	assert.Equal(t, []uuid.UUID{g.Players[0].ID}, g.Pots[0].WinningPlayers)
+	assert.ElementsMatch(t, []uuid.UUID{g.Players[0].ID, g.Players[1].ID}, g.Pots[0].LowPlayers)
+	assert.Equal(t, int64(225), g.Players[0].Chips, "half and a quarter")
+	assert.Equal(t, int64(75), g.Players[1].Chips, "a quarter")
+	assert.Zero(t, g.Players[2].Chips)
+}
+
+func TestEngineHiLo_OffPaysHighOnly(t *testing.T) {
+	g, ids := allInGame(t, []int64{100, 100, 100}, [][]string{{"As", "Ah"}, {"3s", "4h"}, {"Kh", "Js"}}, []string{"2c", "5s", "7h", "Kd", "Qc"})
+	for _, id := range ids {
+		require.NoError(t, g.Actions.PlayerBet(g, id, 100))
+	}
+	runOut(t, g)
+
+	assert.Empty(t, g.Pots[0].LowPlayers)
+	assert.Equal(t, int64(300), g.Players[0].Chips)
+}
+
+func TestVariant_EvaluateLow(t *testing.T) {
+	_, wheel, ok := game.VariantHoldem.EvaluateLow(engineCards(t, "A♠", "2♥"), engineCards(t, "3♣", "4♦", "5♠", "K♣", "K♦"))
+	require.True(t, ok)
+	_, sixLow, ok := game.VariantHoldem.EvaluateLow(engineCards(t, "A♠", "2♥"), engineCards(t, "3♣", "4♦", "6♠", "K♣", "K♦"))
+	require.True(t, ok)
+	assert.Less(t, wheel, sixLow, "the wheel is the best low")
+
+	_, _, ok = game.VariantHoldem.EvaluateLow(engineCards(t, "A♠", "9♥"), engineCards(t, "3♣", "4♦", "5♠", "K♣", "K♦"))
+	assert.False(t, ok, "a nine doesn't qualify")
+
+	// Omaha lows use exactly two hole cards and three from the board
+	_, _, ok = game.VariantOmaha.EvaluateLow(engineCards(t, "A♠", "2♥", "3♦", "4♣"), engineCards(t, "5♠", "K♣", "K♦", "Q♠", "J♥"))
+	assert.False(t, ok, "only one low card on the board")
+	hand, _, ok := game.VariantOmaha.EvaluateLow(engineCards(t, "A♠", "2♥", "K♥", "K♠"), engineCards(t, "3♣", "4♦", "5♠", "Q♠", "J♥"))
+	require.True(t, ok)
+	assert.Len(t, hand, 5)
+}
+
+func TestTableAggregate_KeepsHiLo(t *testing.T) {
+	aggregate := aggregates.NewTableAggregate(uuid.New(), "O8", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{GameType: game.VariantOmaha, HiLo: true})
+	require.True(t, aggregate.Table.Game.HiLo)
+
+	loaded := &aggregates.TableAggregate{
+		AggregateRoot: aggregates.AggregateRoot{ID: aggregate.ID},
+		Table:         &table.Table{},
+	}
+	loaded.LoadFromHistory(aggregate.GetUncommittedChanges())
+	require.NotNil(t, loaded.Table.Game)
+	assert.True(t, loaded.Table.Game.HiLo)
+	assert.Equal(t, game.VariantOmaha, loaded.Table.Game.Variant)
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/table"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// engineCards parses cards written like "A♠"
+func engineCards(t *testing.T, written ...string) []game.Card {
+	values := map[string]int{"2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9, "T": 10, "J": 11, "Q": 12, "K": 13, "A": 14}
+	parsed := make([]game.Card, 0, len(written))
+	for _, w := range written {
+		rank := w[:1]
+		require.Contains(t, values, rank)
+		parsed = append(parsed, game.Card{Rank: rank, Suit: w[1:], Value: values[rank]})
+	}
+	return parsed
+}
+
+func TestParseVariant(t *testing.T) {
+	variant, err := game.ParseVariant("")
+	require.NoError(t, err)
+	assert.Equal(t, game.VariantHoldem, variant)
+
+	variant, err = game.ParseVariant("omaha")
+	require.NoError(t, err)
+	assert.Equal(t, game.VariantOmaha, variant)
+
+	_, err = game.ParseVariant("razz")
+	assert.ErrorIs(t, err, game.ErrUnsupportedVariant)
+}
+
+func TestOmaha_DealsFourHoleCards(t *testing.T) {
+	tbl := table.NewTable("PLO", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{GameType: game.VariantOmaha})
+	g := tbl.Game
+	require.Equal(t, game.VariantOmaha, g.Variant)
+
+	actions := game.NewGameActions()
+	for seat := 1; seat <= 3; seat++ {
+		require.NoError(t, actions.AddPlayer(g, uuid.NewString(), "player", seat, 1000))
+	}
+	require.NoError(t, actions.StartHand(g))
+
+	for _, player := range g.Players {
+		assert.Len(t, player.HoleCards, 4)
+	}
+}
+
+func TestOmaha_UsesExactlyTwoHoleCards(t *testing.T) {
+	board := engineCards(t, "2♥", "7♣", "9♦", "J♠", "K♣")
+
+	// Four hearts in hand and one on the board is no flush in Omaha
+	hole := engineCards(t, "A♥", "Q♥", "8♥", "5♥")
+	_, holdemScore, holdemName := game.VariantHoldem.Evaluate(hole[:2], board)
+	_, score, name := game.VariantOmaha.Evaluate(hole, board)
+	assert.NotEqual(t, "Flush", name)
+	assert.Equal(t, "High Card", name)
+	assert.Equal(t, "High Card", holdemName)
+	assert.Greater(t, score, 0)
+	assert.Greater(t, holdemScore, 0)
+
+	// One hole card pairing the board still plays with a second from the hand
+	hand, _, name := game.VariantOmaha.Evaluate(engineCards(t, "K♥", "Q♦", "4♠", "3♠"), board)
+	assert.Equal(t, "One Pair", name)
+	assert.Len(t, hand, 5)
+
+	// Three hearts on the board do make a flush with two from the hand
+	_, _, name = game.VariantOmaha.Evaluate(hole, engineCards(t, "2♥", "7♥", "9♥", "J♠", "K♣"))
+	assert.Equal(t, "Flush", name)
+}
+
+func TestHoldem_EvaluatesBestOfSeven(t *testing.T) {
+	_, _, name := game.VariantHoldem.Evaluate(engineCards(t, "A♥", "Q♥"), engineCards(t, "2♥", "7♥", "9♥", "J♠", "K♣"))
+	assert.Equal(t, "Flush", name)
+
+	_, _, name = game.VariantHoldem.Evaluate(engineCards(t, "K♥", "K♦"), engineCards(t, "K♠", "7♣", "7♦", "J♠", "2♣"))
+	assert.Equal(t, "Full House", name)
+}
+
+func TestOmaha_RejectsBetsOverThePot(t *testing.T) {
+	tbl := table.NewTable("PLO", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{GameType: game.VariantOmaha})
+	g := tbl.Game
+	actions := game.NewGameActions()
+
+	ids := []string{uuid.NewString(), uuid.NewString()}
+	for i, id := range ids {
+		require.NoError(t, actions.AddPlayer(g, id, "player", i+1, 1000))
+	}
+	g.Stage = game.Flop
+	g.Players[0].CurrentBet, g.Players[0].TotalBet = 20, 50
+	g.Players[1].TotalBet = 30
+
+	// 20 to call, then the pot of 100 it makes
+	player := g.Players[1]
+	assert.Equal(t, int64(120), g.PotLimitMax(player))
+	assert.ErrorIs(t, actions.PlayerBet(g, ids[1], 121), game.ErrExceedsPotLimit)
+	assert.Equal(t, int64(1000), player.Chips)
+
+	require.NoError(t, actions.PlayerBet(g, ids[1], 120))
+	assert.Equal(t, int64(880), player.Chips)
+}
+
+func TestHoldem_AllowsBetsOverThePot(t *testing.T) {
+	tbl := table.NewTable("NLHE", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{})
+	g := tbl.Game
+	require.Equal(t, game.VariantHoldem, g.Variant)
+	actions := game.NewGameActions()
+
+	ids := []string{uuid.NewString(), uuid.NewString()}
+	for i, id := range ids {
+		require.NoError(t, actions.AddPlayer(g, id, "player", i+1, 1000))
+	}
+	g.Stage = game.Flop
+
+	require.NoError(t, actions.PlayerBet(g, ids[0], 900))
+}
@@ -0,0 +1,232 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type potWant struct {
+	amount   int64
+	eligible []int // Indexes into the scenario's players
+}
+
+func TestPotManager_Pots(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []int64
+		folded  []bool
+		want    []potWant
+	}{
+		{
+			name:    "three way all in at different stacks",
+			amounts: []int64{100, 300, 300},
+			want:    []potWant{{300, []int{0, 1, 2}}, {400, []int{1, 2}}},
+		},
+		{
+			name:    "four way all in with two short stacks",
+			amounts: []int64{50, 200, 500, 500},
+			want:    []potWant{{200, []int{0, 1, 2, 3}}, {450, []int{1, 2, 3}}, {600, []int{2, 3}}},
+		},
+		{
+			name:    "four way all in, every stack different",
+			amounts: []int64{400, 100, 300, 200},
+			want:    []potWant{{400, []int{1, 3, 2, 0}}, {300, []int{3, 2, 0}}, {200, []int{2, 0}}, {100, []int{0}}},
+		},
+		{
+			name:    "two players all in for the same amount",
+			amounts: []int64{100, 100, 400, 400},
+			want:    []potWant{{400, []int{0, 1, 2, 3}}, {600, []int{2, 3}}},
+		},
+		{
+			name:    "five way all in",
+			amounts: []int64{10, 20, 30, 40, 40},
+			want:    []potWant{{50, []int{0, 1, 2, 3, 4}}, {40, []int{1, 2, 3, 4}}, {30, []int{2, 3, 4}}, {20, []int{3, 4}}},
+		},
+		{
+			name:    "folded player's chips stay in the pots they reached",
+			amounts: []int64{100, 250, 300, 300},
+			folded:  []bool{false, true, false, false},
+			want:    []potWant{{400, []int{0, 2, 3}}, {550, []int{2, 3}}},
+		},
+		{
+			name:    "chips above every live player go to the pot below",
+			amounts: []int64{100, 150, 100},
+			folded:  []bool{false, true, false},
+			want:    []potWant{{350, []int{0, 2}}},
+		},
+		{
+			name:    "folded blinds are dead money in the main pot",
+			amounts: []int64{5, 10, 200, 150},
+			folded:  []bool{true, true, false, false},
+			want:    []potWant{{315, []int{3, 2}}, {50, []int{2}}},
+		},
+		{
+			name:    "nothing bet",
+			amounts: []int64{0, 0, 0},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]uuid.UUID, len(tt.amounts))
+			contributions := make([]game.Contribution, len(tt.amounts))
+			var total int64
+			for i, amount := range tt.amounts {
+				ids[i] = uuid.New()
+				folded := tt.folded != nil && tt.folded[i]
+				contributions[i] = game.Contribution{PlayerID: ids[i], Amount: amount, InHand: !folded}
+				total += amount
+			}
+
+			pots := game.PotManager{}.Pots(contributions)
+			require.Len(t, pots, len(tt.want))
+
+			var potTotal int64
+			for i, want := range tt.want {
+				eligible := make([]uuid.UUID, len(want.eligible))
+				for j, index := range want.eligible {
+					eligible[j] = ids[index]
+				}
+				assert.Equal(t, want.amount, pots[i].Amount, "pot %d", i)
+				assert.ElementsMatch(t, eligible, pots[i].EligiblePlayers, "pot %d", i)
+				assert.Equal(t, i > 0, pots[i].IsSidePot, "pot %d", i)
+				potTotal += pots[i].Amount
+			}
+			assert.Equal(t, total, potTotal, "every chip bet is in a pot")
+		})
+	}
+}
+
+func TestPotManager_UncalledBet(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	pm := game.PotManager{}
+
+	player, amount := pm.UncalledBet([]game.Contribution{{PlayerID: a, Amount: 100}, {PlayerID: b, Amount: 300}, {PlayerID: c, Amount: 500}})
+	assert.Equal(t, c, player)
+	assert.Equal(t, int64(200), amount, "the bet over the second biggest stack goes back")
+
+	player, amount = pm.UncalledBet([]game.Contribution{{PlayerID: a, Amount: 10}, {PlayerID: b, Amount: 40, InHand: true}, {PlayerID: c, Amount: 10}})
+	assert.Equal(t, b, player)
+	assert.Equal(t, int64(30), amount, "a raise everyone folded to goes back")
+
+	player, amount = pm.UncalledBet([]game.Contribution{{PlayerID: a, Amount: 300}, {PlayerID: b, Amount: 300}, {PlayerID: c, Amount: 100}})
+	assert.Equal(t, uuid.Nil, player)
+	assert.Zero(t, amount, "a called bet stays in")
+
+	_, amount = pm.UncalledBet(nil)
+	assert.Zero(t, amount)
+}
+
+func TestPotManager_Split(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	pm := game.PotManager{}
+
+	assert.Equal(t, map[uuid.UUID]int64{a: 50, b: 50}, pm.Split(100, []uuid.UUID{a, b}))
+	assert.Equal(t, map[uuid.UUID]int64{a: 51, b: 50}, pm.Split(101, []uuid.UUID{a, b}))
+	assert.Equal(t, map[uuid.UUID]int64{b: 34, a: 34, c: 33}, pm.Split(101, []uuid.UUID{b, a, c}), "odd chips go one each to the first winners")
+	assert.Equal(t, map[uuid.UUID]int64{c: 7}, pm.Split(7, []uuid.UUID{c}))
+	assert.Empty(t, pm.Split(7, nil))
+}
+
+func TestPotManager_CallAmount(t *testing.T) {
+	pm := game.PotManager{}
+	assert.Equal(t, int64(90), pm.CallAmount(100, 10, 1000))
+	assert.Equal(t, int64(40), pm.CallAmount(100, 10, 40), "a short stack calls all in")
+	assert.Zero(t, pm.CallAmount(100, 100, 1000))
+	assert.Zero(t, pm.CallAmount(50, 100, 1000))
+}
+
+// allInGame seats players with the given stacks, deals them the given hole
+// cards and board, and starts a 5/10 hand with the first player on the button
+func allInGame(t *testing.T, stacks []int64, holes [][]string, board []string) (*game.Game, []string) {
+	t.Helper()
+	g := game.NewGame(uuid.New(), 5, 10, len(stacks))
+
+	ids := make([]string, len(stacks))
+	for i, stack := range stacks {
+		ids[i] = uuid.NewString()
+		require.NoError(t, g.Actions.AddPlayer(g, ids[i], "player", i+1, stack))
+	}
+
+	var order []game.Card
+	parse := func(text string) game.Card {
+		card, err := game.ParseCard(text)
+		require.NoError(t, err)
+		return card
+	}
+	for _, hole := range holes {
+		for _, card := range hole {
+			order = append(order, parse(card))
+		}
+	}
+	burn := []game.Card{parse("2d"), parse("3d"), parse("4d")}
+	order = append(order, burn[0], parse(board[0]), parse(board[1]), parse(board[2]), burn[1], parse(board[3]), burn[2], parse(board[4]))
+	require.NoError(t, g.Deck.Stack(order))
+	require.NoError(t, g.Actions.StartHand(g))
+	return g, ids
+}
+
+func runOut(t *testing.T, g *game.Game) {
+	t.Helper()
+	for g.Stage != game.River {
+		require.NoError(t, g.Actions.DealCards(g))
+	}
+	require.NoError(t, g.Actions.EndHand(g))
+}
+
+func TestEnginePots_ThreeWayAllInPaysEachPotAndReturnsUncalledChips(t *testing.T) {
+	board := []string{"2c", "7s", "9h", "Js", "5c"}
+	g, ids := allInGame(t, []int64{100, 300, 500}, [][]string{{"As", "Ah"}, {"Ks", "Kh"}, {"Qs", "Qh"}}, board)
+
+	for _, id := range ids {
+		require.NoError(t, g.Actions.PlayerBet(g, id, 500))
+	}
+	runOut(t, g)
+
+	require.Len(t, g.Pots, 2)
+	assert.Equal(t, int64(300), g.Pots[0].Amount)
+	assert.Equal(t, int64(400), g.Pots[1].Amount)
+	assert.Equal(t, int64(300), g.Players[0].Chips, "aces win the main pot")
+	assert.Equal(t, int64(400), g.Players[1].Chips, "kings win the side pot")
+	assert.Equal(t, int64(200), g.Players[2].Chips, "the bet nobody could call goes back")
+}
+
+func TestEnginePots_ShortStackCanCallAllIn(t *testing.T) {
+	board := []string{"2c", "7s", "9h", "Js", "5c"}
+	g, ids := allInGame(t, []int64{1000, 1000, 60}, [][]string{{"Ks", "Kh"}, {"Qs", "Qh"}, {"As", "Ah"}}, board)
+
+	// The button raises, the small blind calls and the big blind can only
+	// call for what's left of their stack
+	require.NoError(t, g.Actions.PlayerBet(g, ids[0], 200))
+	require.NoError(t, g.Actions.PlayerBet(g, ids[1], g.Actions.CallAmount(g, g.Players[1])))
+	bigBlind := g.Players[2]
+	assert.Equal(t, int64(50), g.Actions.CallAmount(g, bigBlind))
+	require.NoError(t, g.Actions.PlayerBet(g, ids[2], g.Actions.CallAmount(g, bigBlind)))
+	assert.True(t, bigBlind.IsAllIn)
+	runOut(t, g)
+
+	assert.Equal(t, int64(180), bigBlind.Chips, "aces win three times their stack")
+	assert.Equal(t, int64(1000-200+280), g.Players[0].Chips, "kings win the side pot")
+	assert.Equal(t, int64(800), g.Players[1].Chips)
+}
+
+func TestEnginePots_RaiseEveryoneFoldsToIsReturned(t *testing.T) {
+	board := []string{"2c", "7s", "9h", "Js", "5c"}
+	g, ids := allInGame(t, []int64{1000, 1000, 1000}, [][]string{{"As", "Ah"}, {"Ks", "Kh"}, {"Qs", "Qh"}}, board)
+
+	require.NoError(t, g.Actions.PlayerBet(g, ids[0], 300))
+	require.NoError(t, g.Actions.PlayerFold(g, ids[1]))
+	require.NoError(t, g.Actions.PlayerFold(g, ids[2]))
+	require.NoError(t, g.Actions.EndHand(g))
+
+	require.Len(t, g.Pots, 1)
+	assert.Equal(t, int64(25), g.Pots[0].Amount, "only the blinds are won")
+	assert.Equal(t, int64(1015), g.Players[0].Chips)
+	assert.Equal(t, int64(995), g.Players[1].Chips)
+	assert.Equal(t, int64(990), g.Players[2].Chips)
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/table"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineSitOut_SkipsPlayerWhenStartingHand(t *testing.T) {
+	tbl := table.NewTable("Sit out", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{})
+	g := tbl.Game
+
+	actions := game.NewGameActions()
+	ids := make([]string, 3)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+		require.NoError(t, actions.AddPlayer(g, ids[i], "player", i+1, 1000))
+	}
+	require.NoError(t, actions.SitOut(g, ids[2]))
+	assert.ErrorIs(t, actions.SitOut(g, ids[2]), game.ErrIllegalAction)
+	require.NoError(t, actions.StartHand(g))
+
+	away := g.Players[2]
+	assert.True(t, away.SittingOut)
+	assert.Empty(t, away.HoleCards)
+	assert.False(t, away.IsInHand())
+	assert.Zero(t, away.TotalBet)
+	assert.Equal(t, int64(1000), away.Chips, "a player sitting out posts no blinds")
+	assert.Len(t, g.GetPlayersInHand(), 2)
+
+	require.NoError(t, actions.SitIn(g, ids[2]))
+	assert.True(t, away.IsDealtIn())
+	assert.ErrorIs(t, actions.SitIn(g, ids[2]), game.ErrIllegalAction)
+}
+
+func TestEngineSitOut_NeedsTwoPlayersDealtIn(t *testing.T) {
+	tbl := table.NewTable("Sit out", table.TableTypeCashGame, 6, 5, 10, table.TableConfig{})
+	g := tbl.Game
+
+	actions := game.NewGameActions()
+	first, second := uuid.NewString(), uuid.NewString()
+	require.NoError(t, actions.AddPlayer(g, first, "player", 1, 1000))
+	require.NoError(t, actions.AddPlayer(g, second, "player", 2, 1000))
+	require.NoError(t, actions.SitOut(g, second))
+
+	assert.False(t, g.CanStart())
+	assert.ErrorIs(t, actions.StartHand(g), game.ErrCannotStartGame)
+}
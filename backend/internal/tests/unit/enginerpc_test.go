@@ -0,0 +1,242 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/enginerpc"
+	"github.com/anhbaysgalan1/gp/server"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeEngine records the calls made through the engine API
+type fakeEngine struct {
+	mu      sync.Mutex
+	tables  map[string]bool
+	actions []enginerpc.SubmitActionRequest
+	refuse  error
+}
+
+func (e *fakeEngine) CreateTable(ctx context.Context, name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.tables[name] {
+		return server.ErrTableExists
+	}
+	e.tables[name] = true
+	return nil
+}
+
+func (e *fakeEngine) SeatPlayer(ctx context.Context, table string, userID uuid.UUID, seatID, buyIn uint) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.tables[table] {
+		return server.ErrTableNotFound
+	}
+	return e.refuse
+}
+
+func (e *fakeEngine) SubmitAction(ctx context.Context, table string, userID uuid.UUID, action string, amount uint, actionToken string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.actions = append(e.actions, enginerpc.SubmitActionRequest{Table: table, UserID: userID, Action: action, Amount: amount, ActionToken: actionToken})
+	return nil
+}
+
+func (e *fakeEngine) StreamState(ctx context.Context, table string, send func([]byte) error) error {
+	for _, message := range []string{`{"action":"update-game"}`, `{"action":"new-log"}`} {
+		if err := send([]byte(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// engineCerts writes a CA and certificates it signed for the server and
+// the named callers, returning the directory they are in
+func engineCerts(t *testing.T, callers ...string) string {
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "engine-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", caDER)
+
+	issue := func(name string, usage x509.ExtKeyUsage, serial int64) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+		writePEM(t, filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+	}
+	issue("localhost", x509.ExtKeyUsageServerAuth, 2)
+	for i, caller := range callers {
+		issue(caller, x509.ExtKeyUsageClientAuth, int64(i+3))
+	}
+	return dir
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// startEngineAPI serves the engine on a local port with the policy
+func startEngineAPI(t *testing.T, dir string, engine enginerpc.Engine, policy enginerpc.Policy) string {
+	tlsConfig, err := enginerpc.ServerTLSConfig(filepath.Join(dir, "localhost.pem"), filepath.Join(dir, "localhost-key.pem"), filepath.Join(dir, "ca.pem"))
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := enginerpc.NewServer(engine, policy, tlsConfig)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func dialEngineAPI(t *testing.T, dir, addr, caller string) *enginerpc.Client {
+	tlsConfig, err := enginerpc.ClientTLSConfig(filepath.Join(dir, caller+".pem"), filepath.Join(dir, caller+"-key.pem"), filepath.Join(dir, "ca.pem"))
+	require.NoError(t, err)
+	tlsConfig.ServerName = "localhost"
+
+	client, err := enginerpc.Dial(addr, tlsConfig)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestEngineAPI_PerMethodAuthorization(t *testing.T) {
+	dir := engineCerts(t, "matchmaker", "game-server")
+	engine := &fakeEngine{tables: map[string]bool{}}
+	addr := startEngineAPI(t, dir, engine, enginerpc.Policy{
+		enginerpc.MethodCreateTable:  {"matchmaker"},
+		enginerpc.MethodSeatPlayer:   {"matchmaker"},
+		enginerpc.MethodSubmitAction: {"game-server"},
+		enginerpc.MethodStreamState:  {enginerpc.AnyCaller},
+	})
+	matchmaker := dialEngineAPI(t, dir, addr, "matchmaker")
+	gameServer := dialEngineAPI(t, dir, addr, "game-server")
+	ctx := context.Background()
+
+	_, err := matchmaker.CreateTable(ctx, &enginerpc.CreateTableRequest{Table: "grpc-1"})
+	require.NoError(t, err)
+	_, err = gameServer.CreateTable(ctx, &enginerpc.CreateTableRequest{Table: "grpc-2"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	userID := uuid.New()
+	_, err = gameServer.SubmitAction(ctx, &enginerpc.SubmitActionRequest{Table: "grpc-1", UserID: userID, Action: "raise", Amount: 40, ActionToken: "turn-1"})
+	require.NoError(t, err)
+	_, err = matchmaker.SubmitAction(ctx, &enginerpc.SubmitActionRequest{Table: "grpc-1", UserID: userID, Action: "fold"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []enginerpc.SubmitActionRequest{{Table: "grpc-1", UserID: userID, Action: "raise", Amount: 40, ActionToken: "turn-1"}}, engine.actions)
+
+	var updates []string
+	err = gameServer.StreamState(ctx, &enginerpc.StreamStateRequest{Table: "grpc-1"}, func(update *enginerpc.StateUpdate) error {
+		assert.Equal(t, "grpc-1", update.Table)
+		updates = append(updates, string(update.Message))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"action":"update-game"}`, `{"action":"new-log"}`}, updates)
+}
+
+func TestEngineAPI_MapsEngineErrors(t *testing.T) {
+	dir := engineCerts(t, "matchmaker")
+	engine := &fakeEngine{
+		tables: map[string]bool{"grpc-1": true},
+		refuse: &server.CommandError{Code: "INSUFFICIENT_BALANCE", Message: "Insufficient balance for buy-in"},
+	}
+	addr := startEngineAPI(t, dir, engine, enginerpc.Policy{
+		enginerpc.MethodCreateTable: {"matchmaker"},
+		enginerpc.MethodSeatPlayer:  {"matchmaker"},
+	})
+	client := dialEngineAPI(t, dir, addr, "matchmaker")
+	ctx := context.Background()
+
+	_, err := client.CreateTable(ctx, &enginerpc.CreateTableRequest{Table: "grpc-1"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.SeatPlayer(ctx, &enginerpc.SeatPlayerRequest{Table: "missing", UserID: uuid.New(), BuyIn: 1000})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SeatPlayer(ctx, &enginerpc.SeatPlayerRequest{Table: "grpc-1", UserID: uuid.New(), BuyIn: 1000})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "INSUFFICIENT_BALANCE")
+
+	_, err = client.SeatPlayer(ctx, &enginerpc.SeatPlayerRequest{Table: "grpc-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestEngineAPI_RequiresClientCertificate(t *testing.T) {
+	dir := engineCerts(t, "matchmaker")
+	addr := startEngineAPI(t, dir, &fakeEngine{tables: map[string]bool{}}, enginerpc.Policy{
+		enginerpc.MethodCreateTable: {enginerpc.AnyCaller},
+	})
+
+	pool := x509.NewCertPool()
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+	require.NoError(t, err)
+	require.True(t, pool.AppendCertsFromPEM(caPEM))
+
+	client, err := enginerpc.Dial(addr, &tls.Config{RootCAs: pool, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.CreateTable(ctx, &enginerpc.CreateTableRequest{Table: "grpc-1"})
+	assert.Error(t, err)
+}
+
+func TestEnginePolicy_Allows(t *testing.T) {
+	policy := enginerpc.Policy{
+		enginerpc.MethodSeatPlayer:  {"matchmaker", "lobby"},
+		enginerpc.MethodStreamState: {enginerpc.AnyCaller},
+	}
+
+	assert.True(t, policy.Allows(enginerpc.MethodSeatPlayer, []string{"lobby"}))
+	assert.True(t, policy.Allows(enginerpc.MethodStreamState, []string{"anyone"}))
+	assert.False(t, policy.Allows(enginerpc.MethodSeatPlayer, []string{"game-server"}))
+	assert.False(t, policy.Allows(enginerpc.MethodCreateTable, []string{"matchmaker"}), "methods missing from the policy are closed")
+}
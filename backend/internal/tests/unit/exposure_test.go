@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposureRules_Limit(t *testing.T) {
+	rules := services.ExposureRules{
+		ByKYCTier: []int64{1000, 10000},
+		ByRole:    map[string]int64{"admin": 0},
+	}
+
+	limit, source := rules.Limit(models.UserRolePlayer, models.KYCTierNone)
+	assert.Equal(t, int64(1000), limit)
+	assert.Equal(t, models.ExposureLimitKYCTier, source)
+
+	limit, _ = rules.Limit(models.UserRolePlayer, models.KYCTierFull)
+	assert.Equal(t, int64(10000), limit, "tiers past the end use the last limit")
+
+	limit, source = rules.Limit(models.UserRoleAdmin, models.KYCTierNone)
+	assert.Equal(t, int64(0), limit, "a role limit replaces the tier's")
+	assert.Equal(t, models.ExposureLimitRole, source)
+
+	limit, _ = services.ExposureRules{}.Limit(models.UserRolePlayer, models.KYCTierFull)
+	assert.Equal(t, int64(0), limit)
+}
+
+func TestEvaluateExposure(t *testing.T) {
+	assert.NoError(t, services.EvaluateExposure(1000, 600, 400))
+	assert.NoError(t, services.EvaluateExposure(0, 1000000, 1000000), "0 is no limit")
+
+	err := services.EvaluateExposure(1000, 600, 500)
+	var limitErr *services.ExposureLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, int64(400), limitErr.Available())
+	assert.Contains(t, limitErr.Error(), "400 MNT")
+
+	err = services.EvaluateExposure(1000, 1200, 100)
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, int64(0), limitErr.Available(), "an override lowered below the exposure leaves nothing available")
+	assert.Contains(t, limitErr.Error(), "Leave a table")
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/fairness"
+	"github.com/stretchr/testify/assert"
+)
+
+func cards(s ...string) []eval.Card {
+	result := make([]eval.Card, len(s))
+	for i, c := range s {
+		result[i] = eval.MustParseCardString(c)
+	}
+	return result
+}
+
+func TestFairnessTally_AddHand(t *testing.T) {
+	var tally fairness.Tally
+
+	holes := [][2]eval.Card{
+		{eval.MustParseCardString("As"), eval.MustParseCardString("Ad")},
+		{eval.MustParseCardString("7h"), eval.MustParseCardString("2c")},
+		{0, 0}, // Empty seat
+	}
+	tally.AddHand(holes, cards("Kh", "Kc", "3d", "9s", "4h"))
+	tally.AddHand(nil, append(cards("Qs", "Js", "Ts"), 0, 0))
+
+	assert.Equal(t, int64(2), tally.Hands)
+	assert.Equal(t, int64(2), tally.HoleHands)
+	assert.Equal(t, int64(1), tally.PocketPairs)
+	assert.Equal(t, int64(2), tally.Flops)
+	assert.Equal(t, int64(1), tally.PairedFlops)
+	assert.Equal(t, int64(1), tally.Boards)
+	assert.Equal(t, int64(1), tally.PairedBoards)
+	assert.Equal(t, int64(2), tally.RankCounts[12], "aces")
+	assert.Equal(t, int64(2), tally.RankCounts[11], "kings")
+	assert.Equal(t, [4]int64{5, 3, 2, 2}, tally.SuitCounts)
+}
+
+func TestFairnessTally_Merge(t *testing.T) {
+	a := fairness.Tally{Hands: 3, PocketPairs: 1, RankCounts: [13]int64{0: 2}}
+	b := fairness.Tally{Hands: 4, PocketPairs: 2, RankCounts: [13]int64{0: 1, 12: 5}}
+
+	a.Merge(b)
+	assert.Equal(t, int64(7), a.Hands)
+	assert.Equal(t, int64(3), a.PocketPairs)
+	assert.Equal(t, int64(3), a.RankCounts[0])
+	assert.Equal(t, int64(5), a.RankCounts[12])
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	// Critical values from standard chi-square tables
+	assert.InDelta(t, 0.05, fairness.ChiSquarePValue(3.841, 1), 0.0005)
+	assert.InDelta(t, 0.01, fairness.ChiSquarePValue(6.635, 1), 0.0005)
+	assert.InDelta(t, 0.05, fairness.ChiSquarePValue(7.815, 3), 0.0005)
+	assert.InDelta(t, 0.05, fairness.ChiSquarePValue(21.026, 12), 0.0005)
+	assert.InDelta(t, 0.01, fairness.ChiSquarePValue(26.217, 12), 0.0005)
+	assert.Equal(t, 1.0, fairness.ChiSquarePValue(0, 12))
+}
+
+func TestFairnessAnalyze(t *testing.T) {
+	t.Run("Shuffled deck passes", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(7))
+		var tally fairness.Tally
+		for i := 0; i < 5000; i++ {
+			deck := append(eval.Deck{}, eval.DefaultDeck...)
+			rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+			holes := [][2]eval.Card{{deck[0], deck[1]}, {deck[2], deck[3]}, {deck[4], deck[5]}}
+			tally.AddHand(holes, deck[6:11])
+		}
+
+		report := fairness.Analyze(tally)
+		assert.False(t, report.Flagged)
+		assert.InDelta(t, fairness.ExpectedPocketPairRate, report.PocketPairs.Observed, 0.01)
+		assert.InDelta(t, fairness.ExpectedPairedBoardRate, report.PairedBoards.Observed, 0.02)
+	})
+
+	t.Run("Biased deck is flagged", func(t *testing.T) {
+		var tally fairness.Tally
+		for i := 0; i < 500; i++ {
+			holes := [][2]eval.Card{{eval.MustParseCardString("As"), eval.MustParseCardString("Ah")}}
+			tally.AddHand(holes, cards("Ks", "Qs", "Js", "Ts", "9s"))
+		}
+
+		report := fairness.Analyze(tally)
+		assert.True(t, report.Flagged)
+		assert.True(t, report.Ranks.Flagged)
+		assert.True(t, report.Suits.Flagged)
+		assert.True(t, report.PocketPairs.Flagged)
+		assert.Equal(t, 12, report.Ranks.DF)
+	})
+
+	t.Run("Empty tally is not flagged", func(t *testing.T) {
+		report := fairness.Analyze(fairness.Tally{})
+		assert.False(t, report.Flagged)
+		assert.Equal(t, 1.0, report.Ranks.PValue)
+	})
+}
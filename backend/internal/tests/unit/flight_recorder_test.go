@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedHand deals a three-handed hand, recording it and the given
+// actions as the table would
+func recordedHand(t *testing.T, rec *flightrecorder.Recorder, actions ...string) {
+	t.Helper()
+
+	game := poker.NewGame()
+	require.NoError(t, game.SetConfig(poker.GameConfig{SmallBlind: 50, BigBlind: 100}))
+	for i := 0; i < 3; i++ {
+		pn := game.AddPlayer()
+		require.NoError(t, poker.BuyIn(game, pn, 10000))
+		require.NoError(t, poker.ToggleReady(game, pn, 0))
+	}
+	require.NoError(t, poker.Deal(game, game.GenerateOmniView().DealerNum, 0))
+	rec.Snapshot("T1-1", game.GenerateOmniView())
+
+	for _, name := range actions {
+		pre := game.GenerateOmniView()
+		pn := pre.ActionNum
+		var amount uint
+		action := poker.Bet
+		switch name {
+		case "fold":
+			action = poker.Fold
+		case "raise":
+			amount = 300
+		}
+		err := action(game, pn, amount)
+		rec.Action("T1-1", "", name, pn, amount, pre, game.GenerateOmniView(), err)
+	}
+}
+
+func TestFlightRecorder_RingBuffer(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 3, nil)
+	for i := 0; i < 5; i++ {
+		rec.Outbound([]byte(`{"action":"new-log"}`))
+	}
+
+	recording := rec.Recording()
+	assert.Equal(t, "Table 1", recording.Table)
+	assert.Equal(t, 3, recording.Capacity)
+	assert.Equal(t, uint64(2), recording.Overwritten)
+	require.Len(t, recording.Entries, 3)
+	for i, entry := range recording.Entries {
+		assert.Equal(t, uint64(i+3), entry.Seq, "oldest first")
+		assert.Equal(t, "new-log", entry.Action)
+	}
+}
+
+func TestFlightRecorder_Off(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 0, nil)
+	assert.Nil(t, rec)
+
+	// Nothing is recorded, and nothing breaks
+	rec.Inbound("user", "player-check", []byte(`{"action":"player-check"}`), nil)
+	rec.Outbound([]byte(`{"action":"update-game"}`))
+	assert.Nil(t, rec.Recording())
+}
+
+func TestFlightRecorder_Inbound(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 10, nil)
+	rec.Inbound("user-1", "player-raise", []byte(`{"action":"player-raise","amount":300}`), nil)
+	rec.Inbound("user-1", "", []byte(`not json`), assert.AnError)
+
+	entries := rec.Recording().Entries
+	require.Len(t, entries, 2)
+	assert.Equal(t, flightrecorder.KindInbound, entries[0].Kind)
+	assert.JSONEq(t, `{"action":"player-raise","amount":300}`, string(entries[0].Message))
+	assert.Nil(t, entries[1].Message, "only JSON is kept")
+	assert.Equal(t, assert.AnError.Error(), entries[1].Error)
+}
+
+func TestFlightRecorder_Replay(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 100, nil)
+	recordedHand(t, rec, "raise", "call", "fold")
+
+	result, err := flightrecorder.Replay(rec.Recording())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Hands)
+	assert.Equal(t, 3, result.Actions)
+	assert.Nil(t, result.Divergence)
+}
+
+func TestFlightRecorder_ReplayDiverges(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 100, nil)
+	recordedHand(t, rec, "raise", "call", "fold")
+
+	// As if the table had applied the raise for a different amount than the
+	// game ended up showing
+	recording := rec.Recording()
+	for i := range recording.Entries {
+		if recording.Entries[i].Action == "raise" {
+			recording.Entries[i].Amount = 400
+		}
+	}
+
+	result, err := flightrecorder.Replay(recording)
+	require.NoError(t, err)
+	require.NotNil(t, result.Divergence)
+	assert.Equal(t, "raise", result.Divergence.Action)
+	assert.Equal(t, "action left the game in a different state", result.Divergence.Reason)
+	assert.NotEqual(t, result.Divergence.Want, result.Divergence.Got)
+	assert.NotNil(t, result.Divergence.State)
+}
+
+func TestFlightRecorder_ReplayNeedsSnapshot(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 100, nil)
+	recordedHand(t, rec, "call")
+
+	// The snapshot has been overwritten
+	recording := rec.Recording()
+	recording.Entries = recording.Entries[1:]
+
+	result, err := flightrecorder.Replay(recording)
+	assert.ErrorIs(t, err, flightrecorder.ErrNoSnapshot)
+	assert.Equal(t, 1, result.Skipped)
+}
+
+func TestFlightRecorder_ReadRecording(t *testing.T) {
+	rec := flightrecorder.New("Table 1", 100, nil)
+	recordedHand(t, rec, "call", "call")
+
+	dump, err := json.Marshal(rec.Recording())
+	require.NoError(t, err)
+	recording, err := flightrecorder.ReadRecording(bytes.NewReader(dump))
+	require.NoError(t, err)
+	assert.Equal(t, "Table 1", recording.Table)
+	assert.Len(t, recording.Entries, 3)
+
+	result, err := flightrecorder.Replay(recording)
+	require.NoError(t, err)
+	assert.Nil(t, result.Divergence, "the deck survives the round trip")
+}
+
+func TestFlightRecorder_FileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := flightrecorder.NewFileSink(dir)
+	require.NoError(t, err)
+
+	rec := flightrecorder.New("Table 1/../x", 0, sink)
+	require.NotNil(t, rec, "a sink alone is enough to record")
+	recordedHand(t, rec, "raise", "fold")
+	require.NoError(t, sink.Close())
+
+	path := sink.Path("Table 1/../x")
+	assert.Equal(t, dir, filepath.Dir(path), "table names cannot leave the directory")
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	recording, err := flightrecorder.ReadRecording(f)
+	require.NoError(t, err)
+	assert.Equal(t, "Table 1/../x", recording.Table)
+	assert.Len(t, recording.Entries, 3)
+
+	result, err := flightrecorder.Replay(recording)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Actions)
+	assert.Nil(t, result.Divergence)
+}
@@ -0,0 +1,418 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/formancemock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockLedger(t *testing.T, opts formancemock.Options) (*formance.Client, *formancemock.Server) {
+	t.Helper()
+
+	opts.Ledgers = []string{"poker"}
+	mock := formancemock.NewServer(opts)
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+
+	client := formance.NewClient(&config.Config{
+		FormanceAPIURL:     server.URL,
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	return client, mock
+}
+
+func TestFormanceMock_TransactionsAndBalances(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client, _ := mockLedger(t, formancemock.Options{Start: start})
+
+	require.NoError(t, client.CreateLedger(ctx))
+
+	txID, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+		{Source: "world", Destination: "player:u-1:wallet", Amount: 500, Asset: "MNT"},
+	}, map[string]string{"type": "deposit"})
+	require.NoError(t, err)
+	assert.Equal(t, "0", txID)
+
+	_, err = client.CreateTransaction(ctx, []formance.PostingSimple{
+		{Source: "player:u-1:wallet", Destination: "session:u-1:s-1", Amount: 200, Asset: "MNT"},
+	}, map[string]string{"type": "buy_in"})
+	require.NoError(t, err)
+
+	balance, err := client.GetBalance(ctx, "player:u-1:wallet")
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), balance)
+
+	balance, err = client.GetBalance(ctx, "player:u-2:wallet")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance)
+
+	t.Run("Overdraft is rejected", func(t *testing.T) {
+		_, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+			{Source: "player:u-1:wallet", Destination: "world", Amount: 1000, Asset: "MNT"},
+		}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "INSUFFICIENT_FUND")
+
+		balance, err := client.GetBalance(ctx, "player:u-1:wallet")
+		require.NoError(t, err)
+		assert.Equal(t, int64(300), balance)
+	})
+
+	t.Run("Duplicate reference conflicts", func(t *testing.T) {
+		postings := []formance.PostingSimple{{Source: "world", Destination: "player:u-2:wallet", Amount: 10, Asset: "MNT"}}
+		_, err := client.CreateTransactionWithOptions(ctx, postings, nil, formance.TransactionOptions{Reference: "ref-1"})
+		require.NoError(t, err)
+		_, err = client.CreateTransactionWithOptions(ctx, postings, nil, formance.TransactionOptions{Reference: "ref-1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "CONFLICT")
+	})
+
+	t.Run("Deterministic clock", func(t *testing.T) {
+		tx, err := client.GetTransaction(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(time.Second).Format(time.RFC3339), tx.Date)
+	})
+
+	t.Run("Metadata is merged", func(t *testing.T) {
+		require.NoError(t, client.AddTransactionMetadata(ctx, 0, map[string]string{"source": "bank"}))
+
+		tx, err := client.GetTransaction(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"type": "deposit", "source": "bank"}, tx.Metadata)
+
+		_, err = client.GetTransaction(ctx, 99)
+		assert.True(t, formance.IsNotFound(err))
+	})
+}
+
+func TestFormanceMock_QueriesAndPaging(t *testing.T) {
+	ctx := context.Background()
+	client, _ := mockLedger(t, formancemock.Options{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)})
+
+	for _, user := range []string{"u-1", "u-2", "u-3"} {
+		_, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+			{Source: "world", Destination: "player:" + user + ":wallet", Amount: 100, Asset: "MNT"},
+		}, map[string]string{"type": "deposit"})
+		require.NoError(t, err)
+	}
+	_, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+		{Source: "player:u-1:wallet", Destination: "house:rake", Amount: 5, Asset: "MNT"},
+	}, map[string]string{"type": "rake"})
+	require.NoError(t, err)
+
+	page, err := client.QueryTransactions(ctx, formance.TransactionFilter{MetadataType: "deposit"}, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	assert.Equal(t, int64(2), page.Transactions[0].ID, "newest first")
+	assert.True(t, page.HasMore)
+
+	page, err = client.QueryTransactions(ctx, formance.TransactionFilter{}, 2, page.Next)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1, "the cursor keeps the filter")
+	assert.Equal(t, int64(0), page.Transactions[0].ID)
+	assert.False(t, page.HasMore)
+
+	page, err = client.QueryTransactions(ctx, formance.TransactionFilter{
+		Account:   "player:u-1:wallet",
+		StartTime: time.Date(2026, 3, 1, 0, 0, 1, 0, time.UTC),
+	}, 10, "")
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, int64(3), page.Transactions[0].ID)
+
+	accounts, err := client.QueryAccounts(ctx, "player::wallet", 10, "")
+	require.NoError(t, err)
+	require.Len(t, accounts.Accounts, 3)
+	assert.Equal(t, "player:u-1:wallet", accounts.Accounts[0].Address)
+	assert.Equal(t, int64(95), accounts.Accounts[0].Balance("MNT"))
+
+	all, err := client.ListAccounts(ctx, 10, "")
+	require.NoError(t, err)
+	assert.Len(t, all.Accounts, 5, "world and house:rake too")
+}
+
+func TestFormanceMock_FaultInjection(t *testing.T) {
+	ctx := context.Background()
+	run := func() []bool {
+		client, _ := mockLedger(t, formancemock.Options{
+			FailRate:   0.5,
+			FailStatus: http.StatusServiceUnavailable,
+			FailPaths:  []string{"/transactions"},
+			Seed:       7,
+		})
+		require.NoError(t, client.CreateLedger(ctx), "paths outside fail-paths are served")
+
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := client.ListTransactions(ctx, 10, "")
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first := run()
+	assert.Equal(t, first, run(), "the same seed fails the same requests")
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+
+	t.Run("Reset restarts the ledger", func(t *testing.T) {
+		client, mock := mockLedger(t, formancemock.Options{})
+		_, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+			{Source: "world", Destination: "player:u-1:wallet", Amount: 100, Asset: "MNT"},
+		}, nil)
+		require.NoError(t, err)
+
+		mock.Reset()
+		balance, err := client.GetBalance(ctx, "player:u-1:wallet")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), balance)
+	})
+}
+
+func TestFormanceSandboxService(t *testing.T) {
+	ctx := context.Background()
+	service := formance.NewSandboxService(&config.Config{
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	require.True(t, service.Sandbox())
+	require.NoError(t, service.Initialize(ctx))
+
+	userID := uuid.New()
+	sessionID := uuid.New()
+	_, err := service.DepositMoney(ctx, userID, 1000)
+	require.NoError(t, err)
+	_, err = service.TransferToGame(ctx, userID, 400, sessionID)
+	require.NoError(t, err)
+
+	balance, err := service.Client().GetBalance(ctx, formance.PlayerWalletAccount(userID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), balance)
+
+	balance, err = service.GetSessionBalance(ctx, userID, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(400), balance)
+
+	assert.Error(t, service.ValidateMainBalance(ctx, userID, 700), "sandbox balances must still be enforced")
+	assert.False(t, formance.NewService(&config.Config{}).Sandbox())
+}
+
+func TestFormanceService_MoveSession(t *testing.T) {
+	ctx := context.Background()
+	service := formance.NewSandboxService(&config.Config{
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	require.NoError(t, service.Initialize(ctx))
+
+	userID := uuid.New()
+	fromSession := uuid.New()
+	toSession := uuid.New()
+	_, err := service.DepositMoney(ctx, userID, 1000)
+	require.NoError(t, err)
+	_, err = service.TransferToGame(ctx, userID, 500, fromSession)
+	require.NoError(t, err)
+
+	balance := func(account string) int64 {
+		t.Helper()
+		b, err := service.Client().GetBalance(ctx, account)
+		require.NoError(t, err)
+		return b
+	}
+
+	txID, err := service.MoveSessionToSession(ctx, userID, fromSession, toSession, 300, 200)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance(formance.SessionAccount(userID, fromSession)))
+	assert.Equal(t, int64(300), balance(formance.SessionAccount(userID, toSession)))
+	assert.Equal(t, int64(700), balance(formance.PlayerWalletAccount(userID)))
+
+	id, err := strconv.ParseInt(txID, 10, 64)
+	require.NoError(t, err)
+	tx, err := service.GetTransaction(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, tx.Postings, 2, "the buy-in and the remainder are one transaction")
+	assert.Equal(t, "session_transfer", tx.Metadata["type"])
+	assert.Equal(t, fromSession.String(), tx.Metadata["from_session_id"])
+
+	_, err = service.MoveSessionToSession(ctx, userID, fromSession, uuid.New(), 1, 0)
+	assert.Error(t, err, "a session can only be moved once")
+
+	tournamentID := uuid.New()
+	_, err = service.MoveSessionToTournament(ctx, userID, toSession, tournamentID, 300, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance(formance.SessionAccount(userID, toSession)))
+	assert.Equal(t, int64(300), balance(formance.TournamentPoolAccount(tournamentID)))
+	assert.Equal(t, int64(700), balance(formance.PlayerWalletAccount(userID)))
+}
+
+func TestFormanceService_DistributePot(t *testing.T) {
+	ctx := context.Background()
+	service := formance.NewSandboxService(&config.Config{
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	require.NoError(t, service.Initialize(ctx))
+
+	alice, bob := uuid.New(), uuid.New()
+	sessions := map[uuid.UUID]uuid.UUID{alice: uuid.New(), bob: uuid.New()}
+	for userID, sessionID := range sessions {
+		_, err := service.DepositMoney(ctx, userID, 1000)
+		require.NoError(t, err)
+		_, err = service.TransferToGame(ctx, userID, 500, sessionID)
+		require.NoError(t, err)
+	}
+	wallet := func(userID uuid.UUID) int64 {
+		t.Helper()
+		b, err := service.Client().GetBalance(ctx, formance.PlayerWalletAccount(userID))
+		require.NoError(t, err)
+		return b
+	}
+
+	potID := formance.PotID("Main", "hand-1", 0)
+	winners := map[uuid.UUID]int64{alice: 200, bob: 100}
+	txID, err := service.DistributePot(ctx, potID, winners, sessions, map[string]string{"hand_id": "hand-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(700), wallet(alice))
+	assert.Equal(t, int64(600), wallet(bob))
+
+	id, err := strconv.ParseInt(txID, 10, 64)
+	require.NoError(t, err)
+	tx, err := service.GetTransaction(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, tx.Postings, 2, "every winner is paid in one transaction")
+	assert.Equal(t, potID, tx.Metadata["pot_id"])
+	assert.Equal(t, "hand-1", tx.Metadata["hand_id"])
+
+	again, err := service.DistributePot(ctx, potID, winners, sessions, nil)
+	require.NoError(t, err)
+	assert.Equal(t, txID, again, "a retry returns the transaction that settled the pot")
+	assert.Equal(t, int64(700), wallet(alice), "a pot is never paid twice")
+
+	_, err = service.DistributePot(ctx, formance.PotID("Main", "hand-1", 1), map[uuid.UUID]int64{alice: 100, bob: 1000}, sessions, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int64(700), wallet(alice), "no winner is paid when one share can't be")
+
+	_, err = service.DistributePot(ctx, formance.PotID("Main", "hand-2", 0), map[uuid.UUID]int64{uuid.New(): 100}, sessions, nil)
+	assert.Error(t, err, "winners need a session to be paid from")
+}
+
+func TestFormanceService_DistributeRakedPot(t *testing.T) {
+	ctx := context.Background()
+	service := formance.NewSandboxService(&config.Config{
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	require.NoError(t, service.Initialize(ctx))
+
+	alice := uuid.New()
+	sessions := map[uuid.UUID]uuid.UUID{alice: uuid.New()}
+	_, err := service.DepositMoney(ctx, alice, 1000)
+	require.NoError(t, err)
+	_, err = service.TransferToGame(ctx, alice, 500, sessions[alice])
+	require.NoError(t, err)
+
+	txID, err := service.DistributeRakedPot(ctx, formance.PotID("Main", "hand-1", 0),
+		map[uuid.UUID]int64{alice: 190}, map[uuid.UUID]int64{alice: 10}, sessions, nil)
+	require.NoError(t, err)
+
+	wallet, err := service.Client().GetBalance(ctx, formance.PlayerWalletAccount(alice))
+	require.NoError(t, err)
+	assert.Equal(t, int64(690), wallet)
+	house, err := service.Client().GetBalance(ctx, formance.RevenueRakeAccount)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), house)
+
+	id, err := strconv.ParseInt(txID, 10, 64)
+	require.NoError(t, err)
+	tx, err := service.GetTransaction(ctx, id)
+	require.NoError(t, err)
+	assert.Len(t, tx.Postings, 2, "the rake is taken in the pot's transaction")
+	assert.Equal(t, "10", tx.Metadata["rake"])
+
+	_, err = service.DistributeRakedPot(ctx, formance.PotID("Main", "hand-2", 0),
+		map[uuid.UUID]int64{alice: 100}, map[uuid.UUID]int64{uuid.New(): 10}, sessions, nil)
+	assert.Error(t, err, "rake is only taken from the pot's winners")
+}
+
+func TestRakeConfig_HandRake(t *testing.T) {
+	config := formance.RakeConfig{Percentage: 0.05, MaxRake: 300, MinPot: 1000}
+
+	assert.Equal(t, int64(0), config.HandRake(999), "pots below the minimum are not raked")
+	assert.Equal(t, int64(50), config.HandRake(1000))
+	assert.Equal(t, int64(300), config.HandRake(100000), "rake is capped")
+
+	config.MaxRake = 0
+	assert.Equal(t, int64(5000), config.HandRake(100000), "no cap when MaxRake is 0")
+}
+
+func TestFormanceMock_LatestLog(t *testing.T) {
+	ctx := context.Background()
+	client, _ := mockLedger(t, formancemock.Options{})
+
+	latest, err := client.LatestLog(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest, "an empty ledger has no log")
+
+	_, err = client.CreateTransaction(ctx, []formance.PostingSimple{
+		{Source: "world", Destination: "player:u-1:wallet", Amount: 100, Asset: "MNT"},
+	}, nil)
+	require.NoError(t, err)
+	latest, err = client.LatestLog(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, int64(0), latest.ID)
+	assert.Equal(t, "NEW_TRANSACTION", latest.Type)
+
+	require.NoError(t, client.AddTransactionMetadata(ctx, 0, map[string]string{"hand_id": "h-1"}))
+	latest, err = client.LatestLog(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), latest.ID)
+	assert.Equal(t, "SET_METADATA", latest.Type)
+}
+
+func TestFormanceClient_Quiesce(t *testing.T) {
+	ctx := context.Background()
+	client, _ := mockLedger(t, formancemock.Options{})
+
+	release, err := client.Quiesce(ctx)
+	require.NoError(t, err)
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := client.CreateTransaction(ctx, []formance.PostingSimple{
+			{Source: "world", Destination: "player:u-1:wallet", Amount: 100, Asset: "MNT"},
+		}, nil)
+		written <- err
+	}()
+	select {
+	case <-written:
+		t.Fatal("a ledger write went through while writes were paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	require.NoError(t, <-written)
+
+	t.Run("Times out behind a write in flight", func(t *testing.T) {
+		release, err := client.Quiesce(ctx)
+		require.NoError(t, err)
+		defer release()
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = client.Quiesce(timeoutCtx)
+		assert.ErrorIs(t, err, formance.ErrWritesBusy)
+	})
+}
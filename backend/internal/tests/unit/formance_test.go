@@ -9,17 +9,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
-func TestFormanceService_GetUserBalance(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
+// sandboxFormanceService returns a service backed by the in-process mock ledger
+func sandboxFormanceService(t *testing.T) *formance.Service {
+	t.Helper()
+	service := formance.NewSandboxService(&config.Config{
 		FormanceLedgerName: "poker-test",
 		FormanceCurrency:   "MNT",
-	}
+	})
+	require.NoError(t, service.Initialize(context.Background()))
+	return service
+}
 
-	service := formance.NewService(cfg)
+// dryRunDB answers the session queries GetUserBalance makes with no rows,
+// without a database
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestFormanceService_GetUserBalance(t *testing.T) {
+	service := sandboxFormanceService(t)
 	userID := uuid.New()
 
 	tests := []struct {
@@ -34,13 +49,13 @@ func TestFormanceService_GetUserBalance(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			balance, err := service.GetUserBalance(context.Background(), userID)
+			balance, err := service.GetUserBalance(context.Background(), userID, dryRunDB(t))
 
 			if tt.expectError {
 				assert.Error(t, err)
 				assert.Nil(t, balance)
 			} else {
-				// Should not error even if Formance is not available (fallback to 0)
+				// A new user has nothing in the ledger yet
 				assert.NoError(t, err)
 				assert.NotNil(t, balance)
 				assert.Equal(t, int64(0), balance.MainBalance)
@@ -52,16 +67,11 @@ func TestFormanceService_GetUserBalance(t *testing.T) {
 }
 
 func TestFormanceService_TransferOperations(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
+	service := sandboxFormanceService(t)
 	userID := uuid.New()
 	sessionID := uuid.New()
+	_, err := service.DepositMoney(context.Background(), userID, 10000)
+	require.NoError(t, err)
 
 	tests := []struct {
 		name        string
@@ -73,7 +83,7 @@ func TestFormanceService_TransferOperations(t *testing.T) {
 			name:        "Transfer to game with valid amount",
 			operation:   "to_game",
 			amount:      10000,
-			expectError: false, // Should not error even if Formance unavailable (returns mock ID)
+			expectError: false,
 		},
 		{
 			name:        "Transfer from game with valid amount",
@@ -119,16 +129,11 @@ func TestFormanceService_TransferOperations(t *testing.T) {
 }
 
 func TestFormanceService_TournamentOperations(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
+	service := sandboxFormanceService(t)
 	userID := uuid.New()
 	tournamentID := uuid.New()
+	_, err := service.DepositMoney(context.Background(), userID, 50000)
+	require.NoError(t, err)
 
 	tests := []struct {
 		name        string
@@ -145,7 +150,7 @@ func TestFormanceService_TournamentOperations(t *testing.T) {
 		{
 			name:        "Tournament prize distribution",
 			operation:   "prize",
-			amount:      100000,
+			amount:      50000, // Paid from the buy-in above
 			expectError: false,
 		},
 		{
@@ -186,79 +191,66 @@ func TestFormanceService_TournamentOperations(t *testing.T) {
 }
 
 func TestFormanceService_RakeCollection(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
-	gameID := "game-123"
-	players := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	service := sandboxFormanceService(t)
+	tableID := uuid.New()
+	sessions := map[uuid.UUID]uuid.UUID{uuid.New(): uuid.New(), uuid.New(): uuid.New(), uuid.New(): uuid.New()}
 
 	tests := []struct {
 		name        string
-		amount      int64
-		players     []uuid.UUID
+		config      formance.RakeConfig
+		sessions    map[uuid.UUID]uuid.UUID
+		expectID    string
 		expectError bool
 	}{
 		{
-			name:        "Collect rake with valid amount and players",
-			amount:      1500,
-			players:     players,
-			expectError: false,
+			name:     "Per-hand rake with valid config and players",
+			config:   formance.RakeConfig{Strategy: formance.RakeStrategyPerHand, Percentage: 0.05, MaxRake: 3000, TableID: tableID, HandID: "hand-1"},
+			sessions: sessions,
 		},
 		{
-			name:        "Collect rake with zero amount",
-			amount:      0,
-			players:     players,
-			expectError: true,
+			name:     "Per-hand rake below the minimum pot",
+			config:   formance.RakeConfig{Strategy: formance.RakeStrategyPerHand, Percentage: 0.05, MinPot: 1 << 40, TableID: tableID, HandID: "hand-2"},
+			sessions: sessions,
 		},
 		{
-			name:        "Collect rake with negative amount",
-			amount:      -500,
-			players:     players,
-			expectError: true,
+			name:     "Tournament rake was taken with the buy-in",
+			config:   formance.RakeConfig{Strategy: formance.RakeStrategyTournament, TableID: tableID},
+			sessions: sessions,
+			expectID: "tournament-rake-collected",
 		},
 		{
-			name:        "Collect rake with no players",
-			amount:      1000,
-			players:     []uuid.UUID{},
-			expectError: false, // Should return empty transaction ID
+			name:        "Unsupported strategy",
+			config:      formance.RakeConfig{Strategy: formance.RakeStrategy("unknown"), TableID: tableID},
+			sessions:    sessions,
+			expectError: true,
 		},
 		{
-			name:        "Collect rake with small amount per player",
-			amount:      1,
-			players:     players,
-			expectError: false, // Should return empty transaction ID (no rake per player)
+			name:     "Collect rake with no players",
+			config:   formance.RakeConfig{Strategy: formance.RakeStrategyPerHand, Percentage: 0.05, TableID: tableID, HandID: "hand-3"},
+			sessions: map[uuid.UUID]uuid.UUID{},
+			expectID: "", // No players, no rake to collect
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			transactionID, err := service.CollectRake(context.Background(), tt.amount, gameID, tt.players)
+			transactionID, err := service.CollectRake(context.Background(), tt.config, tt.sessions)
 
 			if tt.expectError {
 				assert.Error(t, err)
+				assert.Empty(t, transactionID)
 			} else {
 				assert.NoError(t, err)
-				// Transaction ID might be empty for cases where no rake is collected
-				_ = transactionID
+				if tt.expectID != "" {
+					assert.Equal(t, tt.expectID, transactionID)
+				}
 			}
 		})
 	}
 }
 
 func TestFormanceService_GetTransactionHistory(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
+	service := sandboxFormanceService(t)
 	userID := uuid.New()
 
 	tests := []struct {
@@ -287,7 +279,7 @@ func TestFormanceService_GetTransactionHistory(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			transactions, err := service.GetTransactionHistory(context.Background(), userID, tt.limit, tt.offset)
 
-			// Should not error even if Formance is not available (returns empty slice)
+			// A new user has no transactions yet
 			assert.NoError(t, err)
 			assert.NotNil(t, transactions)
 			// Transactions might be empty since this is just a test without real data
@@ -296,39 +288,27 @@ func TestFormanceService_GetTransactionHistory(t *testing.T) {
 }
 
 func TestFormanceService_Initialize(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
+	service := sandboxFormanceService(t)
 
 	t.Run("Initialize Formance service", func(t *testing.T) {
 		err := service.Initialize(context.Background())
-		// Should not error even if Formance is not available
+		// Initializing again is harmless
 		assert.NoError(t, err)
 	})
 }
 
 func TestFormanceService_EdgeCases(t *testing.T) {
-	cfg := &config.Config{
-		FormanceAPIURL:     "http://localhost:8080",
-		FormanceAPIKey:     "test",
-		FormanceLedgerName: "poker-test",
-		FormanceCurrency:   "MNT",
-	}
-
-	service := formance.NewService(cfg)
+	service := sandboxFormanceService(t)
 
 	t.Run("Operations with nil UUID", func(t *testing.T) {
 		// Test with nil UUID - these should still work as the UUID will be converted to string
-		_, err := service.GetUserBalance(context.Background(), uuid.Nil)
+		_, err := service.GetUserBalance(context.Background(), uuid.Nil, dryRunDB(t))
 		assert.NoError(t, err) // Should not error, returns 0 balances
 
+		_, err = service.DepositMoney(context.Background(), uuid.Nil, 1000)
+		require.NoError(t, err)
 		_, err = service.TransferToGame(context.Background(), uuid.Nil, 1000, uuid.New())
-		assert.NoError(t, err) // Should not error, returns mock transaction ID
+		assert.NoError(t, err)
 	})
 
 	t.Run("Operations with context cancellation", func(t *testing.T) {
@@ -336,11 +316,11 @@ func TestFormanceService_EdgeCases(t *testing.T) {
 		cancel() // Cancel immediately
 
 		// These operations should handle context cancellation gracefully
-		_, err := service.GetUserBalance(ctx, uuid.New())
+		_, err := service.GetUserBalance(ctx, uuid.New(), dryRunDB(t))
 		// Might error due to context cancellation, but should not panic
 		_ = err // Ignore error for this test
 		assert.NotPanics(t, func() {
-			service.GetUserBalance(ctx, uuid.New())
+			service.GetUserBalance(ctx, uuid.New(), dryRunDB(t))
 		})
 	})
 
@@ -348,8 +328,10 @@ func TestFormanceService_EdgeCases(t *testing.T) {
 		userID := uuid.New()
 		sessionID := uuid.New()
 		largeAmount := int64(999999999999) // Very large amount
+		_, err := service.DepositMoney(context.Background(), userID, largeAmount)
+		require.NoError(t, err)
 
-		_, err := service.TransferToGame(context.Background(), userID, largeAmount, sessionID)
+		_, err = service.TransferToGame(context.Background(), userID, largeAmount, sessionID)
 		assert.NoError(t, err) // Should handle large amounts
 
 		_, err = service.TransferFromGame(context.Background(), userID, largeAmount, sessionID)
@@ -379,7 +361,7 @@ func TestFormanceService_RealIntegration(t *testing.T) {
 	// Test full workflow
 	t.Run("Complete transfer workflow", func(t *testing.T) {
 		// Get initial balance
-		_, err := service.GetUserBalance(context.Background(), userID)
+		_, err := service.GetUserBalance(context.Background(), userID, dryRunDB(t))
 		require.NoError(t, err)
 
 		// Note: In real scenario, user would need to have balance first
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/gpctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdminAPI records the last request made to it and replies with body
+func fakeAdminAPI(t *testing.T, status int, body string) (*gpctl.Client, *http.Request, *[]byte) {
+	t.Helper()
+
+	var lastRequest http.Request
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = *r
+		lastBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return gpctl.New(server.URL+"/api/v1/", "admin-token"), &lastRequest, &lastBody
+}
+
+func TestGpctlAdjustBalance(t *testing.T) {
+	client, req, body := fakeAdminAPI(t, http.StatusOK, `{"transaction_id":"42"}`)
+
+	result, err := client.AdjustBalance(context.Background(), "5d1c9a6e-3f0b-4c55-9a57-6f1e9b0c2d11", -2500, "TICKET-7 double payout")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"transaction_id":"42"}`, string(result))
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/api/v1/admin/users/5d1c9a6e-3f0b-4c55-9a57-6f1e9b0c2d11/adjustments", req.URL.Path)
+	assert.Equal(t, "Bearer admin-token", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	var sent map[string]interface{}
+	require.NoError(t, json.Unmarshal(*body, &sent))
+	assert.Equal(t, float64(-2500), sent["amount"])
+	assert.Equal(t, "TICKET-7 double payout", sent["reason"])
+}
+
+func TestGpctlSetFlag(t *testing.T) {
+	client, req, body := fakeAdminAPI(t, http.StatusOK, `{"key":"withdrawals","enabled":false}`)
+
+	_, err := client.SetFlag(context.Background(), "withdrawals", false, "payment provider outage")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.Equal(t, "/api/v1/admin/feature-flags/withdrawals", req.URL.Path)
+	assert.JSONEq(t, `{"enabled":false,"reason":"payment provider outage"}`, string(*body))
+}
+
+func TestGpctlListTablesWithoutBody(t *testing.T) {
+	client, req, body := fakeAdminAPI(t, http.StatusOK, `{"tables":[]}`)
+
+	_, err := client.ListTables(context.Background(), "active")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "/api/v1/tables", req.URL.Path)
+	assert.Equal(t, "active", req.URL.Query().Get("status"))
+	assert.Empty(t, req.Header.Get("Content-Type"))
+	assert.Empty(t, *body)
+}
+
+func TestGpctlTableRecording(t *testing.T) {
+	client, req, _ := fakeAdminAPI(t, http.StatusOK, `{"table":"Table 1","entries":[]}`)
+
+	_, err := client.TableRecording(context.Background(), "Table 1")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "/api/v1/admin/websocket/recording", req.URL.Path)
+	assert.Equal(t, "Table 1", req.URL.Query().Get("table"))
+}
+
+func TestGpctlAPIError(t *testing.T) {
+	client, _, _ := fakeAdminAPI(t, http.StatusConflict, `{"error":"A hand is in progress"}`)
+
+	_, err := client.ForceCloseTable(context.Background(), "Table 1", "")
+	require.Error(t, err)
+
+	var apiErr *gpctl.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+	assert.Equal(t, "A hand is in progress", apiErr.Message)
+}
+
+func TestGpctlAPIErrorWithoutJSON(t *testing.T) {
+	client, _, _ := fakeAdminAPI(t, http.StatusBadGateway, "upstream down\n")
+
+	_, err := client.Reconcile(context.Background())
+
+	var apiErr *gpctl.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "upstream down", apiErr.Message)
+}
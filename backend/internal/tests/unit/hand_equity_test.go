@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandEquities(t *testing.T) {
+	players, err := json.Marshal([]models.HandPlayerCards{
+		{UserID: uuid.New(), Username: "alice", SeatID: 1, Cards: []string{"AS", "AD"}, Shown: true},
+		{UserID: uuid.New(), Username: "bob", SeatID: 4, Cards: []string{"KH", "KC"}, Shown: true},
+		{UserID: uuid.New(), Username: "carol", SeatID: 6, Cards: []string{"7D", "2C"}},
+	})
+	require.NoError(t, err)
+
+	history := models.HandHistory{
+		HandID:    "HIGHRO-1A2B-000042",
+		Variant:   "texas_holdem",
+		HoleCards: players,
+		Board:     json.RawMessage(`["2H","9S","JD","KD","QH"]`),
+	}
+
+	streets := services.HandEquities(history)
+	require.Len(t, streets, 4)
+	for i, name := range []string{"preflop", "flop", "turn", "river"} {
+		assert.Equal(t, name, streets[i].Street)
+		require.Len(t, streets[i].Equities, 2, "only hands shown down are graphed")
+		assert.Equal(t, uint(1), streets[i].Equities[0].SeatID)
+		assert.Equal(t, uint(4), streets[i].Equities[1].SeatID)
+		assert.InDelta(t, 1, streets[i].Equities[0].Equity+streets[i].Equities[1].Equity, 0.0001)
+	}
+	assert.Empty(t, streets[0].Board)
+	assert.Equal(t, []string{"2H", "9S", "JD", "KD"}, streets[2].Board)
+	assert.Greater(t, streets[0].Equities[0].Equity, 0.75, "aces are the favourite preflop")
+	assert.Equal(t, 1.0, streets[3].Equities[1].Equity, "kings hit a set by the river")
+	assert.Equal(t, streets, services.HandEquities(history), "the same hand always graphs the same")
+
+	t.Run("No showdown, no timeline", func(t *testing.T) {
+		folded, err := json.Marshal([]models.HandPlayerCards{
+			{UserID: uuid.New(), SeatID: 1, Cards: []string{"AS", "AD"}, Shown: false},
+			{UserID: uuid.New(), SeatID: 2, Cards: []string{"KH", "KC"}, Shown: false},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, services.HandEquities(models.HandHistory{HoleCards: folded, Board: json.RawMessage(`[]`)}))
+	})
+
+	t.Run("Timeline is served with the hand", func(t *testing.T) {
+		history := history
+		raw, err := json.Marshal(services.HandEquities(history))
+		require.NoError(t, err)
+		history.Equities = raw
+		assert.Len(t, history.ViewFor(uuid.New()).Equity, 4)
+	})
+}
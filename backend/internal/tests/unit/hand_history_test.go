@@ -0,0 +1,191 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandHistoryViewFor(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+
+	players, err := json.Marshal([]models.HandPlayerCards{
+		{UserID: alice, Username: "alice", SeatID: 1, Cards: []string{"AS", "AD"}, Shown: true},
+		{UserID: bob, Username: "bob", SeatID: 2, Cards: []string{"KH", "KC"}, Shown: true},
+		{UserID: carol, Username: "carol", SeatID: 3, Cards: []string{"7D", "2C"}},
+	})
+	require.NoError(t, err)
+
+	history := models.HandHistory{
+		HandID:    "HIGHRO-1A2B-000042",
+		TableName: "High Rollers",
+		TotalPot:  1200,
+		HoleCards: players,
+		Board:     json.RawMessage(`["2H","9S","JD","4C","QH"]`),
+		Winners:   json.RawMessage(`[{"user_id":"` + alice.String() + `","username":"alice","amount":1200}]`),
+	}
+
+	cardsBySeat := func(view models.HandHistoryView) map[uint][]string {
+		cards := make(map[uint][]string)
+		for _, p := range view.Players {
+			cards[p.SeatID] = p.Cards
+		}
+		return cards
+	}
+
+	t.Run("Spectator sees only showdown hands", func(t *testing.T) {
+		view := history.ViewFor(uuid.New())
+
+		cards := cardsBySeat(view)
+		assert.Equal(t, []string{"AS", "AD"}, cards[1])
+		assert.Equal(t, []string{"KH", "KC"}, cards[2])
+		assert.Nil(t, cards[3])
+		assert.Equal(t, []string{"2H", "9S", "JD", "4C", "QH"}, view.Board)
+		require.Len(t, view.Winners, 1)
+		assert.Equal(t, int64(1200), view.Winners[0].Amount)
+	})
+
+	t.Run("Folded player sees own cards", func(t *testing.T) {
+		cards := cardsBySeat(history.ViewFor(carol))
+		assert.Equal(t, []string{"7D", "2C"}, cards[3])
+	})
+
+	t.Run("Redacted cards are omitted from JSON", func(t *testing.T) {
+		body, err := json.Marshal(history.ViewFor(uuid.New()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "7D")
+	})
+
+	t.Run("Hand without recorded cards", func(t *testing.T) {
+		view := models.HandHistory{HandID: "TBL-0000-000001"}.ViewFor(alice)
+		assert.Empty(t, view.Players)
+		assert.Empty(t, view.Board)
+		assert.Nil(t, view.Ruling)
+	})
+
+	t.Run("Moderator ruling is shown", func(t *testing.T) {
+		ruled := history
+		ruled.Ruling = json.RawMessage(`{"outcome":"void","reason":"Disconnect during all-in","ruled_at":"2026-10-15T12:00:00Z"}`)
+
+		view := ruled.ViewFor(uuid.New())
+		require.NotNil(t, view.Ruling)
+		assert.Equal(t, models.HandRulingVoid, view.Ruling.Outcome)
+		assert.Equal(t, "Disconnect during all-in", view.Ruling.Reason)
+	})
+}
+
+func TestHandHistoryViewWith(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+
+	players, err := json.Marshal([]models.HandPlayerCards{
+		{UserID: alice, Username: "alice", SeatID: 1, Cards: []string{"AS", "AD"}, Shown: true},
+		{UserID: bob, Username: "bob", SeatID: 2, Cards: []string{"KH", "KC"}},
+		{UserID: carol, Username: "carol", SeatID: 3, Cards: []string{"7D", "2C"}},
+	})
+	require.NoError(t, err)
+
+	history := models.HandHistory{
+		HandID:    "HIGHRO-1A2B-000043",
+		HoleCards: players,
+		Winners:   json.RawMessage(`[{"user_id":"` + alice.String() + `","username":"alice","amount":800,"transaction_id":"tx-1"}]`),
+	}
+
+	playerAt := func(view models.HandHistoryView, seat uint) models.HandPlayerCards {
+		for _, p := range view.Players {
+			if p.SeatID == seat {
+				return p
+			}
+		}
+		t.Fatalf("no player at seat %d", seat)
+		return models.HandPlayerCards{}
+	}
+
+	t.Run("Room policy reveals mucked cards", func(t *testing.T) {
+		view := history.ViewWith(uuid.New(), models.HandViewPolicy{RevealMucked: true})
+		assert.Equal(t, []string{"KH", "KC"}, playerAt(view, 2).Cards)
+	})
+
+	t.Run("Anonymous player is shown by seat", func(t *testing.T) {
+		view := history.ViewWith(uuid.New(), models.HandViewPolicy{
+			RevealMucked: true,
+			Anonymous:    map[uuid.UUID]bool{bob: true},
+		})
+
+		p := playerAt(view, 2)
+		assert.Equal(t, uuid.Nil, p.UserID)
+		assert.Equal(t, "Seat 2", p.Username)
+		assert.Nil(t, p.Cards)
+		assert.Equal(t, []string{"7D", "2C"}, playerAt(view, 3).Cards)
+	})
+
+	t.Run("Anonymous winner keeps shown cards but loses identity", func(t *testing.T) {
+		view := history.ViewWith(uuid.New(), models.HandViewPolicy{Anonymous: map[uuid.UUID]bool{alice: true}})
+
+		assert.Equal(t, []string{"AS", "AD"}, playerAt(view, 1).Cards)
+		require.Len(t, view.Winners, 1)
+		assert.Equal(t, "Seat 1", view.Winners[0].Username)
+		assert.Equal(t, uuid.Nil, view.Winners[0].UserID)
+		assert.Empty(t, view.Winners[0].TransactionID)
+		assert.Equal(t, int64(800), view.Winners[0].Amount)
+	})
+
+	t.Run("Viewer is never anonymous to themselves", func(t *testing.T) {
+		view := history.ViewWith(bob, models.HandViewPolicy{Anonymous: map[uuid.UUID]bool{bob: true}})
+
+		p := playerAt(view, 2)
+		assert.Equal(t, bob, p.UserID)
+		assert.Equal(t, []string{"KH", "KC"}, p.Cards)
+	})
+}
+
+func TestHandPrivacySettingsVisibleTo(t *testing.T) {
+	settings := models.DefaultHandPrivacySettings(uuid.New())
+	assert.True(t, settings.VisibleTo(false))
+
+	settings.Visibility = models.HandVisibilityPlayers
+	assert.True(t, settings.VisibleTo(true))
+	assert.False(t, settings.VisibleTo(false))
+
+	settings.Visibility = models.HandVisibilitySelf
+	assert.False(t, settings.VisibleTo(true))
+}
+
+func TestHandHistoryReassignUser(t *testing.T) {
+	duplicate, survivor, bob := uuid.New(), uuid.New(), uuid.New()
+
+	players, err := json.Marshal([]models.HandPlayerCards{
+		{UserID: duplicate, Username: "alice2", SeatID: 1, Cards: []string{"AS", "AD"}, Shown: true},
+		{UserID: bob, Username: "bob", SeatID: 2, Cards: []string{"KH", "KC"}},
+	})
+	require.NoError(t, err)
+
+	history := models.HandHistory{
+		HandID:    "HIGHRO-1A2B-000044",
+		HoleCards: players,
+		Winners:   json.RawMessage(`[{"user_id":"` + duplicate.String() + `","username":"alice2","amount":600}]`),
+	}
+
+	changed, err := history.ReassignUser(duplicate, survivor, "alice")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	dealt := history.DealtPlayers()
+	require.Len(t, dealt, 2)
+	assert.Equal(t, survivor, dealt[0].UserID)
+	assert.Equal(t, "alice", dealt[0].Username)
+	assert.Equal(t, []string{"AS", "AD"}, dealt[0].Cards)
+	assert.Equal(t, bob, dealt[1].UserID)
+
+	view := history.ViewFor(uuid.New())
+	require.Len(t, view.Winners, 1)
+	assert.Equal(t, survivor, view.Winners[0].UserID)
+	assert.Equal(t, int64(600), view.Winners[0].Amount)
+
+	changed, err = history.ReassignUser(duplicate, survivor, "alice")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,219 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenEndpoint answers code exchanges with an ID token carrying claims, and
+// records the form it was posted
+func tokenEndpoint(t *testing.T, claims jwt.MapClaims, form *url.Values) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if form != nil {
+			*form = r.PostForm
+		}
+		if r.PostForm.Get("code") == "used-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("provider-key"))
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "id_token": idToken})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func idClaims(issuer, audience string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"aud":            audience,
+		"sub":            "provider-user-1",
+		"email":          "Player@Example.com",
+		"email_verified": true,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestGoogleProvider_AuthCodeURL(t *testing.T) {
+	provider := services.NewGoogleProvider("google-client", "secret", "https://poker.example/oauth/callback", services.GoogleEndpoint)
+
+	parsed, err := url.Parse(provider.AuthCodeURL("state-123", services.PKCEChallenge("verifier-123")))
+	require.NoError(t, err)
+	assert.Equal(t, "accounts.google.com", parsed.Host)
+	query := parsed.Query()
+	assert.Equal(t, "google-client", query.Get("client_id"))
+	assert.Equal(t, "https://poker.example/oauth/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "state-123", query.Get("state"))
+	assert.Contains(t, query.Get("scope"), "email")
+	assert.Empty(t, query.Get("client_secret"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, services.PKCEChallenge("verifier-123"), query.Get("code_challenge"))
+	assert.NotContains(t, parsed.RawQuery, "verifier-123")
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	sum := sha256.Sum256([]byte("verifier-123"))
+	challenge := services.PKCEChallenge("verifier-123")
+	// Unpadded base64url of the SHA-256, as S256 asks for
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), challenge)
+	assert.Len(t, challenge, 43)
+}
+
+func TestGoogleProvider_Exchange(t *testing.T) {
+	var form url.Values
+	server := tokenEndpoint(t, idClaims("https://accounts.google.com", "google-client"), &form)
+	endpoint := services.OAuthEndpoint{TokenURL: server.URL, Issuers: services.GoogleEndpoint.Issuers}
+	provider := services.NewGoogleProvider("google-client", "secret", "https://poker.example/oauth/callback", endpoint)
+
+	identity, err := provider.Exchange(context.Background(), "auth-code", "verifier-123")
+	require.NoError(t, err)
+	assert.Equal(t, models.IdentityProviderGoogle, identity.Provider)
+	assert.Equal(t, "provider-user-1", identity.Subject)
+	assert.Equal(t, "player@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+
+	assert.Equal(t, "auth-code", form.Get("code"))
+	assert.Equal(t, "secret", form.Get("client_secret"))
+	assert.Equal(t, "authorization_code", form.Get("grant_type"))
+	assert.Equal(t, "verifier-123", form.Get("code_verifier"))
+
+	_, err = provider.Exchange(context.Background(), "used-code", "verifier-123")
+	assert.ErrorIs(t, err, services.ErrIdentityRejected)
+}
+
+func TestGoogleProvider_RejectsForeignIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"another client", idClaims("https://accounts.google.com", "other-client")},
+		{"another issuer", idClaims("https://evil.example", "google-client")},
+		{"expired", func() jwt.MapClaims {
+			claims := idClaims("https://accounts.google.com", "google-client")
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return claims
+		}()},
+		{"no subject", func() jwt.MapClaims {
+			claims := idClaims("https://accounts.google.com", "google-client")
+			delete(claims, "sub")
+			return claims
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tokenEndpoint(t, tt.claims, nil)
+			endpoint := services.OAuthEndpoint{TokenURL: server.URL, Issuers: services.GoogleEndpoint.Issuers}
+			provider := services.NewGoogleProvider("google-client", "secret", "https://poker.example/oauth/callback", endpoint)
+
+			_, err := provider.Exchange(context.Background(), "auth-code", "verifier-123")
+			assert.ErrorIs(t, err, services.ErrIdentityRejected)
+		})
+	}
+}
+
+func TestAppleProvider_Exchange(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	// Apple sends email_verified as a string
+	claims := idClaims("https://appleid.apple.com", "com.example.poker")
+	claims["email_verified"] = "true"
+	var form url.Values
+	server := tokenEndpoint(t, claims, &form)
+	endpoint := services.OAuthEndpoint{TokenURL: server.URL, Issuers: services.AppleEndpoint.Issuers}
+
+	provider, err := services.NewAppleProvider("com.example.poker", "TEAM123", "KEY123", keyPEM, "https://api.poker.example/api/v1/auth/oauth/apple/callback", endpoint)
+	require.NoError(t, err)
+
+	identity, err := provider.Exchange(context.Background(), "auth-code", "verifier-123")
+	require.NoError(t, err)
+	assert.Equal(t, models.IdentityProviderApple, identity.Provider)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "verifier-123", form.Get("code_verifier"))
+
+	// The client secret is a token signed with the team's key
+	secret, err := jwt.Parse(form.Get("client_secret"), func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience("https://appleid.apple.com"), jwt.WithIssuer("TEAM123"))
+	require.NoError(t, err)
+	assert.Equal(t, "KEY123", secret.Header["kid"])
+	subject, _ := secret.Claims.GetSubject()
+	assert.Equal(t, "com.example.poker", subject)
+
+	_, err = services.NewAppleProvider("com.example.poker", "TEAM123", "KEY123", "not a key", "https://api.poker.example/cb", endpoint)
+	assert.Error(t, err)
+}
+
+func TestAuthHandler_AppleCallbackForwardsToFrontend(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil)
+	handler.SetIdentity(nil, "https://poker.example/oauth/callback")
+
+	form := url.Values{"code": {"apple-code"}, "state": {"state-123"}}
+	req := httptest.NewRequest(http.MethodPost, "/oauth/apple/callback", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr := httptest.NewRecorder()
+
+	handler.AppleCallback(rr, req)
+
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	location, err := url.Parse(rr.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "poker.example", location.Host)
+	assert.Equal(t, "/oauth/callback", location.Path)
+	assert.Equal(t, "apple", location.Query().Get("provider"))
+	assert.Equal(t, "apple-code", location.Query().Get("code"))
+	assert.Equal(t, "state-123", location.Query().Get("state"))
+}
+
+func TestAuthHandler_OAuthProvidersWithoutIdentityService(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil)
+	rr := httptest.NewRecorder()
+
+	handler.OAuthProviders(rr, httptest.NewRequest(http.MethodGet, "/oauth/providers", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"providers":[]}`, rr.Body.String())
+}
+
+func TestAuthHandler_OAuthSignInRequiresState(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil)
+	handler.SetIdentity(services.NewIdentityServiceWithProviders(nil, nil, nil, nil), "https://poker.example/oauth/callback")
+	router := chi.NewRouter()
+	router.Post("/oauth/{provider}", handler.OAuthSignIn)
+
+	req := httptest.NewRequest(http.MethodPost, "/oauth/google", strings.NewReader(`{"code":"auth-code"}`))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
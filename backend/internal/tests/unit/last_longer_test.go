@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastLongerWinner(t *testing.T) {
+	position := func(p int) *int { return &p }
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		positions []*int // For a, b and c
+		winner    uuid.UUID
+		decided   bool
+	}{
+		{"everyone still in", []*int{nil, nil, nil}, uuid.Nil, false},
+		{"two still in", []*int{position(40), nil, nil}, uuid.Nil, false},
+		{"last one standing", []*int{position(40), nil, position(12)}, b, true},
+		{"all placed", []*int{position(3), position(1), position(7)}, b, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []models.LastLongerEntry{
+				{UserID: a, FinalPosition: tt.positions[0]},
+				{UserID: b, FinalPosition: tt.positions[1]},
+				{UserID: c, FinalPosition: tt.positions[2]},
+			}
+			winner, decided := services.LastLongerWinner(entries)
+			assert.Equal(t, tt.decided, decided)
+			assert.Equal(t, tt.winner, winner)
+		})
+	}
+
+	winner, decided := services.LastLongerWinner([]models.LastLongerEntry{{UserID: c}})
+	assert.True(t, decided, "a pool nobody else joined goes back to its creator")
+	assert.Equal(t, c, winner)
+}
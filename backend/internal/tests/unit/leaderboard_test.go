@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboardWindow(t *testing.T) {
+	// A Sunday evening, so the week started six days earlier
+	now := time.Date(2026, 3, 15, 21, 30, 0, 0, time.UTC)
+	tests := []struct {
+		period   string
+		from, to time.Time
+	}{
+		{models.LeaderboardDaily, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{models.LeaderboardWeekly, time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{models.LeaderboardMonthly, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			from, to, err := services.LeaderboardWindow(tt.period, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+
+	// Monday midnight starts the next week
+	from, _, err := services.LeaderboardWindow(models.LeaderboardWeekly, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), from)
+
+	_, _, err = services.LeaderboardWindow("yearly", now)
+	assert.ErrorIs(t, err, services.ErrUnknownLeaderboardPeriod)
+}
+
+func leaderboardHand(t *testing.T, players []models.HandPlayerCards, winners []models.HandWinner) models.HandHistory {
+	t.Helper()
+
+	holeCards, err := json.Marshal(players)
+	require.NoError(t, err)
+	winnerList, err := json.Marshal(winners)
+	require.NoError(t, err)
+	return models.HandHistory{HoleCards: holeCards, Winners: winnerList}
+}
+
+func TestRankHands(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	hands := []models.HandHistory{
+		leaderboardHand(t,
+			[]models.HandPlayerCards{{UserID: alice, Username: "alice", Wagered: 100}, {UserID: bob, Username: "bob", Wagered: 100}},
+			[]models.HandWinner{{UserID: alice, Username: "alice", Amount: 190}}),
+		leaderboardHand(t,
+			[]models.HandPlayerCards{{UserID: alice, Username: "alice", Wagered: 300}, {UserID: bob, Username: "bob", Wagered: 300}, {UserID: carol, Username: "carol", Wagered: 50}},
+			// A main pot and a side pot to the same player are one hand won
+			[]models.HandWinner{{UserID: bob, Username: "bob", Amount: 150}, {UserID: bob, Username: "bob", Amount: 470}}),
+		leaderboardHand(t,
+			[]models.HandPlayerCards{{UserID: alice, Username: "alice", Wagered: 20}, {UserID: carol, Username: "carol", Wagered: 20}},
+			[]models.HandWinner{{UserID: carol, Username: "carol", Amount: 38}}),
+	}
+
+	winners := services.RankHands(models.LeaderboardBiggestWinners, hands)
+	require.Len(t, winners, 1)
+	assert.Equal(t, models.LeaderboardStanding{Rank: 1, UserID: bob, Username: "bob", Value: 220}, winners[0])
+
+	mostHands := services.RankHands(models.LeaderboardMostHands, hands)
+	require.Len(t, mostHands, 3)
+	assert.Equal(t, alice, mostHands[0].UserID)
+	assert.Equal(t, int64(3), mostHands[0].Value)
+	// Tied on two hands each, so both are second and listed by name
+	assert.Equal(t, []string{"bob", "carol"}, []string{mostHands[1].Username, mostHands[2].Username})
+	assert.Equal(t, []int{2, 2}, []int{mostHands[1].Rank, mostHands[2].Rank})
+
+	pots := services.RankHands(models.LeaderboardBiggestPots, hands)
+	require.Len(t, pots, 3)
+	assert.Equal(t, []int64{620, 190, 38}, []int64{pots[0].Value, pots[1].Value, pots[2].Value})
+	assert.Equal(t, []int{1, 2, 3}, []int{pots[0].Rank, pots[1].Rank, pots[2].Rank})
+}
+
+func TestGetLeaderboard_Validation(t *testing.T) {
+	r := chi.NewRouter()
+	r.Mount("/leaderboards", handlers.NewLeaderboardHandler(services.NewLeaderboardService(nil)).Routes())
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"unknown kind", "/leaderboards/richest?period=weekly", http.StatusNotFound},
+		{"unknown period", "/leaderboards/winners?period=yearly", http.StatusBadRequest},
+		{"limit too high", "/leaderboards/hands?limit=500", http.StatusBadRequest},
+		{"bad limit", "/leaderboards/pots?limit=ten", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLedger records the last request made to it and replies with body
+func fakeLedger(t *testing.T, status int, body string) (*formance.Client, *http.Request, *[]byte) {
+	t.Helper()
+
+	var lastRequest http.Request
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = *r
+		lastBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := formance.NewClient(&config.Config{
+		FormanceAPIURL:     server.URL,
+		FormanceLedgerName: "poker",
+		FormanceCurrency:   "MNT",
+	})
+	return client, &lastRequest, &lastBody
+}
+
+func TestQueryTransactions_Filters(t *testing.T) {
+	client, req, body := fakeLedger(t, http.StatusOK, `{"cursor": {"hasMore": true, "next": "abc=", "data": [{"id": 7, "metadata": {"type": "deposit"}}]}}`)
+
+	filter := formance.TransactionFilter{
+		Account:      "player::wallet",
+		MetadataType: "deposit",
+		StartTime:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:      time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	page, err := client.QueryTransactions(context.Background(), filter, 20, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v2/poker/transactions", req.URL.Path)
+	assert.Equal(t, "20", req.URL.Query().Get("pageSize"))
+	assert.JSONEq(t, `{"$and": [
+		{"$match": {"account": "player::wallet"}},
+		{"$match": {"metadata[type]": "deposit"}},
+		{"$gte": {"timestamp": "2026-03-01T00:00:00Z"}},
+		{"$lt": {"timestamp": "2026-04-01T00:00:00Z"}}
+	]}`, string(*body))
+
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, int64(7), page.Transactions[0].ID)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "abc=", page.Next)
+
+	t.Run("Single filter is not wrapped", func(t *testing.T) {
+		_, err := client.QueryTransactions(context.Background(), formance.TransactionFilter{MetadataType: "rakeback"}, 20, "")
+		require.NoError(t, err)
+
+		var query map[string]interface{}
+		require.NoError(t, json.Unmarshal(*body, &query))
+		assert.Equal(t, map[string]interface{}{"$match": map[string]interface{}{"metadata[type]": "rakeback"}}, query)
+	})
+
+	t.Run("Cursor replaces filters", func(t *testing.T) {
+		_, err := client.QueryTransactions(context.Background(), filter, 20, "abc=")
+		require.NoError(t, err)
+
+		assert.Equal(t, "abc=", req.URL.Query().Get("cursor"))
+		assert.Empty(t, *body)
+	})
+}
+
+func TestQueryAccounts_AddressPattern(t *testing.T) {
+	client, req, body := fakeLedger(t, http.StatusOK, `{"cursor": {"data": [{"address": "player:u-1:wallet", "volumes": {"MNT": {"input": 500, "output": 200, "balance": 300}}}]}}`)
+
+	page, err := client.QueryAccounts(context.Background(), "player::wallet", 10, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "volumes", req.URL.Query().Get("expand"))
+	assert.JSONEq(t, `{"$match": {"address": "player::wallet"}}`, string(*body))
+	require.Len(t, page.Accounts, 1)
+	assert.Equal(t, int64(300), page.Accounts[0].Balance("MNT"))
+	assert.False(t, page.HasMore)
+}
+
+func TestGetAccount_NotFound(t *testing.T) {
+	client, req, _ := fakeLedger(t, http.StatusNotFound, `{"code": "NOT_FOUND", "message": "account not found"}`)
+
+	_, err := client.GetAccount(context.Background(), "player:u-1:wallet")
+	require.Error(t, err)
+	assert.Equal(t, "/v2/poker/accounts/player:u-1:wallet", req.URL.Path)
+	assert.True(t, formance.IsNotFound(err))
+}
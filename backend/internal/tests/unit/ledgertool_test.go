@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/ledgertool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountTemplate_MatchAndRender(t *testing.T) {
+	from, err := ledgertool.ParseAccountTemplate("session:{user}:{session}")
+	require.NoError(t, err)
+	to, err := ledgertool.ParseAccountTemplate("session:{user}:game:{session}")
+	require.NoError(t, err)
+
+	values, ok := from.Match("session:u-1:s-2")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"user": "u-1", "session": "s-2"}, values)
+
+	rendered, err := to.Render(values)
+	require.NoError(t, err)
+	assert.Equal(t, "session:u-1:game:s-2", rendered)
+
+	_, ok = from.Match("player:u-1:wallet")
+	assert.False(t, ok)
+	_, ok = from.Match("session:u-1:game:s-2")
+	assert.False(t, ok, "placeholders must not span segments")
+}
+
+func TestAccountTemplate_Invalid(t *testing.T) {
+	_, err := ledgertool.ParseAccountTemplate("")
+	assert.Error(t, err)
+
+	_, err = ledgertool.ParseAccountTemplate("system:house")
+	assert.Error(t, err, "templates need at least one placeholder")
+
+	_, err = ledgertool.ParseAccountTemplate("session:{user}:{user}")
+	assert.Error(t, err)
+
+	tmpl, err := ledgertool.ParseAccountTemplate("session:{user}:{session}")
+	require.NoError(t, err)
+	_, err = tmpl.Render(map[string]string{"user": "u-1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLiveTables []models.LiveTable
+
+func (f fakeLiveTables) LiveTables() []models.LiveTable {
+	return f
+}
+
+func TestAdminLiveTables(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+	h.SetLiveTableMonitor(fakeLiveTables{
+		{Table: "alpha", State: models.TableStateHandRunning, Reason: "hand_started"},
+		{Table: "bravo", State: models.TableStateWaitingForPlayers, Reason: "not_enough_players", Ready: 1, MinPlayers: 2},
+	})
+
+	w := httptest.NewRecorder()
+	h.GetLiveTables(w, httptest.NewRequest(http.MethodGet, "/admin/websocket/tables?state=waiting_for_players", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Tables []models.LiveTable `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Tables, 1)
+	assert.Equal(t, "bravo", body.Tables[0].Table)
+	assert.Equal(t, "not_enough_players", body.Tables[0].Reason)
+}
+
+func TestAdminLiveTables_Unavailable(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+
+	w := httptest.NewRecorder()
+	h.GetLiveTables(w, httptest.NewRequest(http.MethodGet, "/admin/websocket/tables", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoyaltyService_TierFor(t *testing.T) {
+	service := services.NewLoyaltyService(nil, nil)
+
+	tests := []struct {
+		name           string
+		qualifyingRake int64
+		expectedTier   models.LoyaltyTier
+	}{
+		{"no rake", 0, models.LoyaltyTierBronze},
+		{"just below silver", 9999, models.LoyaltyTierBronze},
+		{"exactly silver", 10000, models.LoyaltyTierSilver},
+		{"gold", 75000, models.LoyaltyTierGold},
+		{"platinum", 500000, models.LoyaltyTierPlatinum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := service.TierFor(tt.qualifyingRake)
+			assert.Equal(t, tt.expectedTier, tier.Tier)
+		})
+	}
+}
+
+func TestDefaultLoyaltyTiers_Ordered(t *testing.T) {
+	for i := 1; i < len(services.DefaultLoyaltyTiers); i++ {
+		prev := services.DefaultLoyaltyTiers[i-1]
+		curr := services.DefaultLoyaltyTiers[i]
+		assert.Less(t, prev.Threshold, curr.Threshold, "thresholds must increase")
+		assert.Less(t, prev.Percentage, curr.Percentage, "rakeback must increase with tier")
+	}
+}
@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceWindow_Covers(t *testing.T) {
+	payments := &models.MaintenanceWindow{Scopes: "deposits,withdrawals"}
+	assert.True(t, payments.Covers(models.MaintenanceScopeDeposits))
+	assert.True(t, payments.Covers(models.MaintenanceScopeWithdrawals))
+	assert.False(t, payments.Covers(models.MaintenanceScopeNewTables))
+	assert.False(t, payments.Covers(models.MaintenanceScopeAllPlay))
+
+	// Stopping all play stops new tables too, but leaves the cashier alone
+	play := &models.MaintenanceWindow{Scopes: "all_play"}
+	assert.True(t, play.Covers(models.MaintenanceScopeAllPlay))
+	assert.True(t, play.Covers(models.MaintenanceScopeNewTables))
+	assert.False(t, play.Covers(models.MaintenanceScopeDeposits))
+
+	assert.Empty(t, (&models.MaintenanceWindow{}).ScopeList())
+}
+
+func TestMaintenanceWindow_Schedule(t *testing.T) {
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	window := &models.MaintenanceWindow{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		active bool
+		status string
+	}{
+		{"before", start.Add(-time.Minute), false, models.MaintenanceStatusScheduled},
+		{"at the start", start, true, models.MaintenanceStatusActive},
+		{"during", start.Add(30 * time.Minute), true, models.MaintenanceStatusActive},
+		{"at the end", start.Add(time.Hour), false, models.MaintenanceStatusEnded},
+		{"after", start.Add(2 * time.Hour), false, models.MaintenanceStatusEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.active, window.ActiveAt(tt.at))
+			assert.Equal(t, tt.status, window.StatusAt(tt.at))
+		})
+	}
+}
+
+func TestCreateMaintenanceWindowRequest_Validation(t *testing.T) {
+	valid := func() models.CreateMaintenanceWindowRequest {
+		return models.CreateMaintenanceWindowRequest{
+			Title:           "Database upgrade",
+			StartsAt:        time.Now().Add(time.Hour),
+			DurationMinutes: 60,
+			Scopes:          []string{models.MaintenanceScopeAllPlay},
+		}
+	}
+
+	req := valid()
+	assert.NoError(t, validation.Validate(&req))
+
+	req = valid()
+	req.Scopes = []string{"tournaments"}
+	assert.Error(t, validation.Validate(&req))
+
+	req = valid()
+	req.Scopes = nil
+	assert.Error(t, validation.Validate(&req))
+
+	req = valid()
+	req.DurationMinutes = 0
+	assert.Error(t, validation.Validate(&req))
+
+	req = valid()
+	req.DurationMinutes = 7*24*60 + 1
+	assert.Error(t, validation.Validate(&req))
+}
+
+func TestAdminMaintenanceWindows_Unavailable(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+
+	w := httptest.NewRecorder()
+	h.ListMaintenanceWindows(w, httptest.NewRequest(http.MethodGet, "/admin/maintenance-windows", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/parity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParitySuiteAgrees(t *testing.T) {
+	stakes := []struct {
+		gameType             string
+		smallBlind, bigBlind int64
+	}{
+		{"texas_holdem", 50, 100},
+		{"texas_holdem", 100, 100},
+		{"omaha", 500, 1000},
+	}
+
+	for _, stake := range stakes {
+		for _, script := range parity.Suite(stake.gameType, stake.smallBlind, stake.bigBlind) {
+			report, err := parity.Run(script)
+			require.NoError(t, err, script.Name)
+			assert.True(t, report.Agree(), "%s %s: %v", stake.gameType, script.Name, report.Diffs)
+		}
+	}
+}
+
+func TestParityReportsWhereTheGamesDisagree(t *testing.T) {
+	script := parity.Script{
+		Name:       "big blind acts out of turn",
+		SmallBlind: 50,
+		BigBlind:   100,
+		Stacks:     []int64{10000, 10000, 10000},
+		Holes:      [][]string{{"As", "Ad"}, {"Ks", "Kd"}, {"Qs", "Qd"}},
+		Board:      []string{"2c", "7h", "9d", "Jc", "3s"},
+		// Under the gun is seat 0. The legacy game refuses the big blind's
+		// check; the engine leaves turn order to its caller.
+		Steps: []parity.Step{{Seat: 2, Action: parity.ActionCheck}},
+	}
+
+	report, err := parity.Run(script)
+	require.NoError(t, err)
+	require.Len(t, report.Diffs, 1)
+	assert.Equal(t, 1, report.Diffs[0].Step)
+	assert.Equal(t, "action check", report.Diffs[0].Field)
+	assert.Contains(t, report.Diffs[0].Legacy, "rejected")
+	assert.Equal(t, "accepted", report.Diffs[0].Engine)
+}
+
+func TestParityRejectsInvalidScripts(t *testing.T) {
+	valid := parity.Suite("texas_holdem", 50, 100)[0]
+
+	duplicate := valid
+	duplicate.Board = []string{"7c", "9d", "4c", "Th", "5s"}
+	_, err := parity.Run(duplicate)
+	assert.ErrorIs(t, err, parity.ErrInvalidScript, "7c is also a hole card")
+
+	shortBoard := valid
+	shortBoard.Board = valid.Board[:3]
+	_, err = parity.Run(shortBoard)
+	assert.ErrorIs(t, err, parity.ErrInvalidScript)
+
+	shortDeck := valid
+	shortDeck.GameType = "short_deck"
+	_, err = parity.Run(shortDeck)
+	assert.ErrorIs(t, err, parity.ErrInvalidScript, "the engine doesn't deal short deck")
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePayoutStructure(t *testing.T) {
+	t.Run("Pays the top share of the field on a falling curve", func(t *testing.T) {
+		places, err := models.GeneratePayoutStructure(100, models.PayoutModel{PaidPercent: 15, Steepness: 1})
+		require.NoError(t, err)
+		require.Len(t, places, 15)
+
+		assert.NoError(t, models.ValidatePayoutStructure(places))
+		assert.Greater(t, places[0].Percentage, places[1].Percentage)
+		assert.Greater(t, places[1].Percentage, places[14].Percentage)
+	})
+
+	t.Run("Paid places round up and never exceed the field", func(t *testing.T) {
+		model := models.PayoutModel{PaidPercent: 10, Steepness: 1}
+		assert.Equal(t, 1, model.PaidPlaces(2))
+		assert.Equal(t, 2, model.PaidPlaces(11))
+		assert.Equal(t, 10, model.PaidPlaces(100))
+
+		model.PaidPercent = 50
+		assert.Equal(t, 1, model.PaidPlaces(1))
+	})
+
+	t.Run("Steeper curves pay the winner more", func(t *testing.T) {
+		flat, err := models.GeneratePayoutStructure(30, models.PayoutModel{PaidPercent: 10, Steepness: 0})
+		require.NoError(t, err)
+		steep, err := models.GeneratePayoutStructure(30, models.PayoutModel{PaidPercent: 10, Steepness: 2})
+		require.NoError(t, err)
+
+		require.Len(t, flat, 3)
+		assert.Equal(t, 33.34, flat[0].Percentage)
+		assert.Equal(t, 33.33, flat[2].Percentage)
+		assert.Greater(t, steep[0].Percentage, flat[0].Percentage)
+		assert.NoError(t, models.ValidatePayoutStructure(steep))
+	})
+
+	t.Run("Large fields still add up to 100", func(t *testing.T) {
+		places, err := models.GeneratePayoutStructure(5000, models.PayoutModel{PaidPercent: 50, Steepness: 3})
+		require.NoError(t, err)
+		require.Len(t, places, 2500)
+		assert.NoError(t, models.ValidatePayoutStructure(places))
+	})
+
+	t.Run("Rejects empty fields and bad models", func(t *testing.T) {
+		_, err := models.GeneratePayoutStructure(0, models.DefaultPayoutModel())
+		assert.ErrorIs(t, err, models.ErrNoPayoutEntrants)
+
+		_, err = models.GeneratePayoutStructure(10, models.PayoutModel{PaidPercent: 60, Steepness: 1})
+		assert.ErrorIs(t, err, models.ErrInvalidPayoutModel)
+	})
+}
+
+func TestParsePayoutStructure(t *testing.T) {
+	places, err := models.ParsePayoutStructure(json.RawMessage(`[
+		{"position": 1, "percentage": 60},
+		{"position": 2, "percentage": 30},
+		{"position": 3, "percentage": 10}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, places, 3)
+
+	tests := []struct {
+		name      string
+		structure string
+		errorMsg  string
+	}{
+		{"empty", `[]`, "at least one place"},
+		{"not json", `{"position": 1}`, "invalid payout structure"},
+		{"out of order", `[{"position": 2, "percentage": 100}]`, "out of order"},
+		{"zero share", `[{"position": 1, "percentage": 100}, {"position": 2, "percentage": 0}]`, "positive percentage"},
+		{"lower place paid more", `[{"position": 1, "percentage": 40}, {"position": 2, "percentage": 60}]`, "pays more than place 1"},
+		{"short of 100", `[{"position": 1, "percentage": 60}, {"position": 2, "percentage": 30}]`, "add up to 90.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.ParsePayoutStructure(json.RawMessage(tt.structure))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestAwardPrizes(t *testing.T) {
+	places := []models.PayoutPlace{{Position: 1, Percentage: 50}, {Position: 2, Percentage: 30}, {Position: 3, Percentage: 20}}
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("rounding remainder goes to the winner", func(t *testing.T) {
+		prizes, err := models.AwardPrizes(1001, places, []models.FinishingPosition{{UserID: c, Position: 3}, {UserID: a, Position: 1}, {UserID: d, Position: 4}, {UserID: b, Position: 2}})
+		require.NoError(t, err)
+		assert.Equal(t, []models.TournamentPrize{
+			{UserID: a, Position: 1, Prize: 501},
+			{UserID: b, Position: 2, Prize: 300},
+			{UserID: c, Position: 3, Prize: 200},
+			{UserID: d, Position: 4, Prize: 0},
+		}, prizes)
+	})
+
+	t.Run("dead heat splits the places it covers", func(t *testing.T) {
+		prizes, err := models.AwardPrizes(1000, places, []models.FinishingPosition{{UserID: a, Position: 1}, {UserID: b, Position: 2}, {UserID: c, Position: 2}, {UserID: d, Position: 4}})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{500, 250, 250, 0}, prizeAmounts(prizes))
+	})
+
+	t.Run("odd chips in a dead heat go to the first listed", func(t *testing.T) {
+		prizes, err := models.AwardPrizes(1000, places, []models.FinishingPosition{{UserID: a, Position: 1}, {UserID: b, Position: 1}, {UserID: c, Position: 1}})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{334, 333, 333}, prizeAmounts(prizes))
+	})
+
+	t.Run("dead heat on the bubble shares the last paid place", func(t *testing.T) {
+		prizes, err := models.AwardPrizes(1000, places, []models.FinishingPosition{{UserID: a, Position: 1}, {UserID: b, Position: 2}, {UserID: c, Position: 3}, {UserID: d, Position: 3}})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{500, 300, 100, 100}, prizeAmounts(prizes))
+	})
+
+	invalid := map[string][]models.FinishingPosition{
+		"player finished twice":        {{UserID: a, Position: 1}, {UserID: a, Position: 2}},
+		"place covered by a dead heat": {{UserID: a, Position: 2}, {UserID: b, Position: 2}, {UserID: c, Position: 3}},
+		"no position":                  {{UserID: a, Position: 0}},
+	}
+	for name, results := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := models.AwardPrizes(1000, places, results)
+			assert.ErrorIs(t, err, models.ErrInvalidResults)
+		})
+	}
+}
+
+func prizeAmounts(prizes []models.TournamentPrize) []int64 {
+	amounts := make([]int64, len(prizes))
+	for i, prize := range prizes {
+		amounts[i] = prize.Prize
+	}
+	return amounts
+}
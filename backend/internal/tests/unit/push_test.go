@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferences_Allows(t *testing.T) {
+	prefs := models.NotificationPreferences{
+		TurnAlerts:        true,
+		TournamentAlerts:  false,
+		WithdrawalUpdates: true,
+		TableUpdates:      false,
+	}
+
+	assert.True(t, prefs.Allows(models.PushEventTurnAlert))
+	assert.False(t, prefs.Allows(models.PushEventTournament))
+	assert.True(t, prefs.Allows(models.PushEventWithdrawalStatus))
+	assert.False(t, prefs.Allows(models.PushEventTableStatus))
+	assert.False(t, prefs.Allows(models.PushEvent("unknown")))
+}
+
+func TestPushService_NotifyWithoutProviders(t *testing.T) {
+	// With no providers configured Notify must return before touching the database
+	service := services.NewPushServiceWithProviders(nil, map[models.PushPlatform]services.PushProvider{})
+
+	assert.NotPanics(t, func() {
+		service.Notify(context.Background(), uuid.New(), models.PushEventTurnAlert, services.PushNotification{
+			Title: "It's your turn",
+		})
+	})
+}
+
+func TestPushService_NotifyAsyncNilSafe(t *testing.T) {
+	var service *services.PushService
+
+	assert.NotPanics(t, func() {
+		service.NotifyAsync(uuid.New(), models.PushEventTournament, services.PushNotification{})
+	})
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRakeFreeWindow_Covers(t *testing.T) {
+	tableID, otherTableID := uuid.New(), uuid.New()
+	templateID, otherTemplateID := uuid.New(), uuid.New()
+
+	table := &models.RakeFreeWindow{TableID: &tableID}
+	assert.True(t, table.Covers(tableID, nil))
+	assert.True(t, table.Covers(tableID, &templateID))
+	assert.False(t, table.Covers(otherTableID, nil))
+
+	// A stake template's window covers every table opened from it
+	stake := &models.RakeFreeWindow{TemplateID: &templateID}
+	assert.True(t, stake.Covers(tableID, &templateID))
+	assert.True(t, stake.Covers(otherTableID, &templateID))
+	assert.False(t, stake.Covers(tableID, &otherTemplateID))
+	assert.False(t, stake.Covers(tableID, nil))
+}
+
+func TestRakeFreeWindow_Schedule(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	window := &models.RakeFreeWindow{StartsAt: start, EndsAt: start.Add(time.Hour)}
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		active bool
+		status string
+	}{
+		{"before", start.Add(-time.Minute), false, models.RakeFreeStatusScheduled},
+		{"at the start", start, true, models.RakeFreeStatusActive},
+		{"during", start.Add(30 * time.Minute), true, models.RakeFreeStatusActive},
+		{"at the end", start.Add(time.Hour), false, models.RakeFreeStatusEnded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.active, window.ActiveAt(tt.at))
+			assert.Equal(t, tt.status, window.StatusAt(tt.at))
+		})
+	}
+}
+
+func TestCreateRakeFreeWindowRequest_Validation(t *testing.T) {
+	tableID, templateID := uuid.New(), uuid.New()
+	valid := func() models.CreateRakeFreeWindowRequest {
+		return models.CreateRakeFreeWindowRequest{
+			Title:           "Happy hour",
+			TableID:         &tableID,
+			StartsAt:        time.Now().Add(time.Hour),
+			DurationMinutes: 60,
+		}
+	}
+
+	req := valid()
+	assert.NoError(t, validation.Validate(&req))
+
+	req = valid()
+	req.TableID, req.TemplateID = nil, &templateID
+	assert.NoError(t, validation.Validate(&req))
+
+	// Exactly one of a table or a stake template
+	req = valid()
+	req.TableID = nil
+	assert.Error(t, validation.Validate(&req))
+
+	req = valid()
+	req.TemplateID = &templateID
+	assert.Error(t, validation.Validate(&req))
+
+	req = valid()
+	req.DurationMinutes = 0
+	assert.Error(t, validation.Validate(&req))
+}
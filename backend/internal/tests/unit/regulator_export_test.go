@@ -0,0 +1,185 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var regulatorPublicKey, regulatorKey, _ = ed25519.GenerateKey(nil)
+
+// regulatorKeyPEM encodes a private key as REGULATOR_EXPORT_KEY holds it
+func regulatorKeyPEM(t *testing.T, key ed25519.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func sealedRegulatorExport(t *testing.T) []byte {
+	t.Helper()
+
+	userID := uuid.New()
+	export, err := services.SealRegulatorExport(regulatorKey, models.RegulatorExportManifest{
+		ExportID: uuid.New(),
+		UserID:   &userID,
+		From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, map[string][][]string{
+		"wagers.csv": {
+			{"hand_id", "wagered", "won"},
+			{"HIGHRO-1A2B-000001", "200", "0"},
+			{"HIGHRO-1A2B-000002", "150", "285"},
+		},
+		"wallet.csv": {{"created_at", "operation", "amount"}},
+	})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	require.NoError(t, export.WriteZip(&archive))
+	return archive.Bytes()
+}
+
+// rezip copies an archive, replacing the named file's contents
+func rezip(t *testing.T, archive []byte, name string, contents []byte) []byte {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	var out bytes.Buffer
+	w := zip.NewWriter(&out)
+	for _, f := range r.File {
+		data := contents
+		if f.Name != name {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, err = io.ReadAll(rc)
+			require.NoError(t, err)
+			rc.Close()
+		}
+		fw, err := w.Create(f.Name)
+		require.NoError(t, err)
+		_, err = fw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return out.Bytes()
+}
+
+func TestRegulatorExport_Verifies(t *testing.T) {
+	archive := sealedRegulatorExport(t)
+
+	manifest, err := services.VerifyRegulatorExport(regulatorPublicKey, bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	assert.Equal(t, models.RegulatorExportSignatureAlgorithm, manifest.SignatureAlgorithm)
+	assert.Len(t, manifest.PublicKeySHA256, 64)
+	assert.Equal(t, "MNT", manifest.Currency)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "wagers.csv", manifest.Files[0].Name)
+	assert.Equal(t, 2, manifest.Files[0].Rows)
+	assert.Equal(t, "wallet.csv", manifest.Files[1].Name)
+	assert.Equal(t, 0, manifest.Files[1].Rows)
+}
+
+func TestRegulatorExport_DetectsTampering(t *testing.T) {
+	archive := sealedRegulatorExport(t)
+
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = services.VerifyRegulatorExport(otherKey, bytes.NewReader(archive), int64(len(archive)))
+	assert.ErrorIs(t, err, services.ErrRegulatorExportInvalid)
+
+	edited := rezip(t, archive, "wagers.csv", []byte("hand_id,wagered,won\nHIGHRO-1A2B-000001,0,0\n"))
+	_, err = services.VerifyRegulatorExport(regulatorPublicKey, bytes.NewReader(edited), int64(len(edited)))
+	assert.ErrorIs(t, err, services.ErrRegulatorExportInvalid)
+
+	resigned := rezip(t, archive, "manifest.json.sig", []byte("00\n"))
+	_, err = services.VerifyRegulatorExport(regulatorPublicKey, bytes.NewReader(resigned), int64(len(resigned)))
+	assert.ErrorIs(t, err, services.ErrRegulatorExportInvalid)
+}
+
+func TestAdminRegulatorExport_Unavailable(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+
+	w := httptest.NewRecorder()
+	h.ExportForRegulator(w, httptest.NewRequest(http.MethodGet, "/admin/regulator-export", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAdminRegulatorExport_Validation(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+	regulatorExports, err := services.NewRegulatorExportService(nil, regulatorKeyPEM(t, regulatorKey))
+	require.NoError(t, err)
+	h.SetRegulatorExports(regulatorExports)
+
+	id := uuid.New().String()
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"no subject", "from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z"},
+		{"both subjects", "user_id=" + id + "&table_id=" + id + "&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z"},
+		{"bad user", "user_id=nope&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z"},
+		{"missing from", "table_id=" + id + "&to=2026-02-01T00:00:00Z"},
+		{"backwards", "table_id=" + id + "&from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z"},
+		{"over a year", "user_id=" + id + "&from=2024-01-01T00:00:00Z&to=2026-01-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/regulator-export?"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, uuid.New()))
+			w := httptest.NewRecorder()
+			h.ExportForRegulator(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestRegulatorExportService_Keys(t *testing.T) {
+	_, err := services.NewRegulatorExportService(nil, "0123456789abcdef0123456789abcdef")
+	assert.Error(t, err, "a shared secret is not a signing key")
+
+	regulatorExports, err := services.NewRegulatorExportService(nil, regulatorKeyPEM(t, regulatorKey))
+	require.NoError(t, err)
+	publicKeyPEM, err := regulatorExports.PublicKeyPEM()
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(publicKeyPEM)
+	require.NotNil(t, block)
+	assert.Equal(t, "PUBLIC KEY", block.Type)
+	published, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, regulatorPublicKey, published, "only the public half is published")
+}
+
+func TestAdminRegulatorExport_PublicKey(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+	w := httptest.NewRecorder()
+	h.GetRegulatorExportPublicKey(w, httptest.NewRequest(http.MethodGet, "/admin/regulator-export/public-key", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	regulatorExports, err := services.NewRegulatorExportService(nil, regulatorKeyPEM(t, regulatorKey))
+	require.NoError(t, err)
+	h.SetRegulatorExports(regulatorExports)
+	w = httptest.NewRecorder()
+	h.GetRegulatorExportPublicKey(w, httptest.NewRequest(http.MethodGet, "/admin/regulator-export/public-key", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "-----BEGIN PUBLIC KEY-----")
+	assert.NotContains(t, w.Body.String(), "PRIVATE KEY")
+}
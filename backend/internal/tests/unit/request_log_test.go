@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/logging"
+	"github.com/anhbaysgalan1/gp/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, key := range []string{"password", "new_password", "Authorization", "refresh_token", "api-key", "card_number", "cvv", "ticket", "JWT_SECRET"} {
+		assert.True(t, logging.IsSensitiveKey(key), key)
+	}
+	for _, key := range []string{"user_id", "action", "command_id", "timespan", "table", "status"} {
+		assert.False(t, logging.IsSensitiveKey(key), key)
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	assert.Equal(t, "card ****1111 declined", logging.RedactString("card 4111 1111 1111 1111 declined"))
+	assert.Equal(t, "card ****4444 declined", logging.RedactString("card 5555-5555-5555-4444 declined"))
+	assert.Equal(t, "ledger tx 1234567890123456", logging.RedactString("ledger tx 1234567890123456"), "long numbers failing the checksum are kept")
+}
+
+func TestRedactURL(t *testing.T) {
+	u, err := url.Parse("/ws?token=eyJhbGciOi&table=main")
+	require.NoError(t, err)
+	assert.Equal(t, "/ws?table=main&token=%5BREDACTED%5D", logging.RedactURL(u))
+
+	u, err = url.Parse("/api/v1/tables")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/tables", logging.RedactURL(u))
+}
+
+func TestRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewLogger(&buf, "json", slog.LevelInfo).With("api_key", "sk_live_123")
+
+	logger.Info("Deposit with 4111111111111111 failed",
+		"password", "hunter2",
+		"error", errors.New("processor rejected 4111111111111111"),
+		slog.Group("request", "authorization", "Bearer abc", "method", "POST"),
+		"user_id", "u1",
+	)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "Deposit with ****1111 failed", entry["msg"])
+	assert.Equal(t, logging.Redacted, entry["api_key"])
+	assert.Equal(t, logging.Redacted, entry["password"])
+	assert.Equal(t, "processor rejected ****1111", entry["error"])
+	assert.Equal(t, map[string]interface{}{"authorization": logging.Redacted, "method": "POST"}, entry["request"])
+	assert.Equal(t, "u1", entry["user_id"])
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.NotContains(t, buf.String(), "sk_live_123")
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewLogger(&buf, "json", slog.LevelInfo)
+	userID := uuid.New()
+
+	r := chi.NewRouter()
+	r.Use(chimiddleware.RequestID)
+	r.Use(middleware.RequestLogger(logger))
+	r.Group(func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				middleware.SetLogUser(req.Context(), userID)
+				next.ServeHTTP(w, req)
+			})
+		})
+		r.Get("/tables/{tableID}", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tables/42?token=secret", nil))
+
+	correlationID := rec.Header().Get(middleware.CorrelationIDHeader)
+	require.NotEmpty(t, correlationID)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "HTTP request", entry["msg"])
+	assert.Equal(t, correlationID, entry["correlation_id"])
+	assert.Equal(t, userID.String(), entry["user_id"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/tables/{tableID}", entry["route"])
+	assert.Equal(t, "/tables/42?token=%5BREDACTED%5D", entry["path"])
+	assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+	assert.Contains(t, entry, "latency")
+	assert.NotContains(t, buf.String(), "secret")
+}
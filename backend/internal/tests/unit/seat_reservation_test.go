@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeatReservation_HeldAt(t *testing.T) {
+	now := time.Now()
+	reservation := models.SeatReservation{ExpiresAt: now.Add(30 * time.Minute)}
+
+	assert.True(t, reservation.HeldAt(now))
+	assert.False(t, reservation.HeldAt(reservation.ExpiresAt), "released the moment it expires")
+
+	reservation.ReleasedAt = &now
+	assert.False(t, reservation.HeldAt(now), "a claimed or cancelled reservation holds nothing")
+}
+
+func TestReservedSeats(t *testing.T) {
+	player, guest := uuid.New(), uuid.New()
+	reservations := []models.SeatReservation{
+		{SeatNumber: 2, UserID: guest},
+		{SeatNumber: 5, UserID: player},
+		{SeatNumber: 7, UserID: uuid.New()},
+	}
+
+	own, others := services.ReservedSeats(reservations, player)
+	assert.Equal(t, 5, own)
+	assert.Equal(t, map[int]bool{2: true, 7: true}, others)
+
+	own, others = services.ReservedSeats(reservations, uuid.New())
+	assert.Zero(t, own)
+	assert.Len(t, others, 3)
+
+	// Drawing random seats never lands on a held one
+	occupied := map[int]bool{1: true, 3: true}
+	for seat := range others {
+		occupied[seat] = true
+	}
+	for i := 0; i < 30; i++ {
+		seat, err := services.RandomSeat(occupied, 9)
+		assert.NoError(t, err)
+		assert.NotContains(t, []int{1, 2, 3, 5, 7}, seat)
+	}
+}
+
+func TestCreateSeatReservationRequest_Validation(t *testing.T) {
+	valid := models.CreateSeatReservationRequest{UserID: uuid.New(), SeatNumber: 3, ExpiresInMinutes: 60}
+	assert.NoError(t, validation.Validate(&valid))
+
+	noSeat := valid
+	noSeat.SeatNumber = 10
+	assert.Error(t, validation.Validate(&noSeat))
+
+	noExpiry := valid
+	noExpiry.ExpiresInMinutes = 0
+	assert.Error(t, validation.Validate(&noExpiry))
+
+	noPlayer := valid
+	noPlayer.UserID = uuid.Nil
+	assert.Error(t, validation.Validate(&noPlayer))
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSeat(t *testing.T) {
+	occupied := map[int]bool{1: true, 2: true, 4: true}
+
+	for i := 0; i < 50; i++ {
+		seat, err := services.RandomSeat(occupied, 5)
+		require.NoError(t, err)
+		assert.Contains(t, []int{3, 5}, seat)
+	}
+
+	_, err := services.RandomSeat(map[int]bool{1: true, 2: true}, 2)
+	assert.ErrorIs(t, err, services.ErrNoEmptySeat)
+}
+
+func TestDrawSeats_BalancesTablesAndSeats(t *testing.T) {
+	players := make([]uuid.UUID, 20)
+	for i := range players {
+		players[i] = uuid.New()
+	}
+
+	assignments, err := services.DrawSeats(players, 9, nil)
+	require.NoError(t, err)
+	require.Len(t, assignments, 20)
+
+	perTable := make(map[int]int)
+	seats := make(map[[2]int]bool)
+	seen := make(map[uuid.UUID]bool)
+	for _, a := range assignments {
+		perTable[a.TableNumber]++
+		key := [2]int{a.TableNumber, a.SeatNumber}
+		assert.False(t, seats[key], "seat assigned twice")
+		seats[key] = true
+		assert.GreaterOrEqual(t, a.SeatNumber, 1)
+		assert.LessOrEqual(t, a.SeatNumber, 9)
+		seen[a.UserID] = true
+	}
+
+	assert.Len(t, seen, 20)
+	assert.Len(t, perTable, 3)
+	for _, count := range perTable {
+		assert.InDelta(t, 7, count, 1)
+	}
+}
+
+func TestDrawSeats_SeparatesFlaggedPairs(t *testing.T) {
+	players := make([]uuid.UUID, 12)
+	for i := range players {
+		players[i] = uuid.New()
+	}
+	flagged := map[uuid.UUID]uuid.UUID{
+		players[0]: players[1],
+		players[2]: players[3],
+	}
+	separated := func(a, b uuid.UUID) bool {
+		return flagged[a] == b || flagged[b] == a
+	}
+
+	for i := 0; i < 25; i++ {
+		assignments, err := services.DrawSeats(players, 6, separated)
+		require.NoError(t, err)
+
+		tableOf := make(map[uuid.UUID]int)
+		for _, a := range assignments {
+			tableOf[a.UserID] = a.TableNumber
+		}
+		assert.NotEqual(t, tableOf[players[0]], tableOf[players[1]])
+		assert.NotEqual(t, tableOf[players[2]], tableOf[players[3]])
+	}
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/seed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedForEnvironment(t *testing.T) {
+	_, err := seed.ForEnvironment("production")
+	assert.ErrorIs(t, err, seed.ErrProductionSeed)
+
+	_, err = seed.ForEnvironment("qa")
+	assert.Error(t, err)
+
+	for _, env := range []string{"development", "staging"} {
+		t.Run(env, func(t *testing.T) {
+			data, err := seed.ForEnvironment(env)
+			require.NoError(t, err)
+
+			admins, usernames := 0, map[string]bool{}
+			for _, user := range data.Users {
+				assert.False(t, usernames[user.Username], "usernames are unique")
+				usernames[user.Username] = true
+				assert.Positive(t, user.Balance)
+				if user.Admin {
+					admins++
+				}
+			}
+			assert.Equal(t, 1, admins, "the admin owns the demo tables")
+			assert.GreaterOrEqual(t, len(data.Users)-admins, 3, "enough players to place a finished tournament")
+
+			stakes := map[int64]bool{}
+			for _, table := range data.Tables {
+				assert.LessOrEqual(t, table.SmallBlind, table.BigBlind)
+				stakes[table.BigBlind] = true
+			}
+			assert.GreaterOrEqual(t, len(stakes), 3, "tables spread across the stakes")
+
+			finished := 0
+			for _, tournament := range data.Tournaments {
+				if tournament.Finished {
+					finished++
+				}
+			}
+			assert.Equal(t, 1, finished)
+			assert.Len(t, data.Tournaments, 2)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sessionManager() *auth.JWTManager {
+	manager := auth.NewJWTManager("test-secret", "test-issuer")
+	manager.SetSessionPolicy(auth.SessionPolicy{
+		IdleTimeout:      30 * time.Minute,
+		RoleIdleTimeouts: map[models.UserRole]time.Duration{models.UserRoleAdmin: 10 * time.Minute},
+		MaxLifetime:      8 * time.Hour,
+	})
+	return manager
+}
+
+func TestSessionToken_IdleTimeoutDependsOnRole(t *testing.T) {
+	manager := sessionManager()
+	now := time.Now()
+
+	_, expiresAt, err := manager.GenerateSessionToken(uuid.New(), "player", "p@example.com", models.UserRolePlayer, now)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(30*time.Minute), expiresAt, 5*time.Second)
+
+	token, expiresAt, err := manager.GenerateSessionToken(uuid.New(), "admin", "a@example.com", models.UserRoleAdmin, now)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(10*time.Minute), expiresAt, 5*time.Second, "admins time out sooner")
+
+	claims, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRoleAdmin, claims.Role)
+	assert.WithinDuration(t, now, claims.StartedAt(), time.Second)
+}
+
+func TestSessionToken_CappedAtMaxLifetime(t *testing.T) {
+	manager := sessionManager()
+	start := time.Now().Add(-7*time.Hour - 50*time.Minute)
+
+	_, expiresAt, err := manager.GenerateSessionToken(uuid.New(), "player", "p@example.com", models.UserRolePlayer, start)
+	require.NoError(t, err)
+	assert.WithinDuration(t, start.Add(8*time.Hour), expiresAt, time.Second, "a token never outlives its session")
+}
+
+func TestSessionToken_Refresh(t *testing.T) {
+	manager := sessionManager()
+	now := time.Now()
+	start := now.Add(-2 * time.Hour)
+	claims := &auth.Claims{
+		UserID:       uuid.New(),
+		Username:     "player",
+		Role:         models.UserRolePlayer,
+		SessionStart: jwt.NewNumericDate(start),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(20 * time.Minute)),
+		},
+	}
+	assert.False(t, manager.ShouldRefresh(claims, now), "more than half the idle timeout left")
+
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(10 * time.Minute))
+	assert.True(t, manager.ShouldRefresh(claims, now))
+
+	token, expiresAt, err := manager.RefreshToken(claims)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(30*time.Minute), expiresAt, 5*time.Second)
+	refreshed, err := manager.ValidateToken(token)
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, refreshed.StartedAt(), time.Second, "the session keeps its start")
+
+	claims.SessionStart = jwt.NewNumericDate(now.Add(-8*time.Hour - time.Minute))
+	_, _, err = manager.RefreshToken(claims)
+	assert.ErrorIs(t, err, auth.ErrSessionExpired)
+
+	claims.SessionStart = jwt.NewNumericDate(now.Add(-8*time.Hour + 5*time.Minute))
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(5 * time.Minute))
+	assert.False(t, manager.ShouldRefresh(claims, now), "already runs to the end of the session")
+}
+
+func TestAuthMiddleware_SlidesSession(t *testing.T) {
+	manager := sessionManager()
+	m := auth.NewAuthMiddleware(manager)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := auth.GetClaimsFromContext(r.Context())
+		assert.True(t, ok)
+	})
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.RequireAuth(next).ServeHTTP(rec, req)
+		return rec
+	}
+
+	fresh, _, err := manager.GenerateSessionToken(uuid.New(), "player", "p@example.com", models.UserRolePlayer, time.Now())
+	require.NoError(t, err)
+	rec := serve(fresh)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(auth.RefreshedTokenHeader))
+	assert.NotEmpty(t, rec.Header().Get(auth.SessionExpiresHeader))
+	assert.NotEmpty(t, rec.Header().Get(auth.SessionMaxExpiresHeader))
+
+	// Issued long enough ago that it's past half its idle timeout
+	manager.SetSessionPolicy(auth.SessionPolicy{IdleTimeout: 2 * time.Minute})
+	stale, _, err := manager.GenerateSessionToken(uuid.New(), "player", "p@example.com", models.UserRolePlayer, time.Now())
+	require.NoError(t, err)
+	manager.SetSessionPolicy(auth.SessionPolicy{IdleTimeout: 30 * time.Minute})
+	rec = serve(stale)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(auth.RefreshedTokenHeader))
+	assert.Empty(t, rec.Header().Get(auth.SessionMaxExpiresHeader), "no maximum lifetime")
+}
+
+type fakeRevocations map[uuid.UUID]time.Time
+
+func (f fakeRevocations) SessionsRevokedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	if at, ok := f[userID]; ok {
+		return &at, nil
+	}
+	return nil, nil
+}
+
+func TestAuthMiddleware_RefusesRevokedSessions(t *testing.T) {
+	manager := sessionManager()
+	m := auth.NewAuthMiddleware(manager)
+	userID := uuid.New()
+	revokedAt := time.Now().Add(-time.Hour)
+	m.SetSessionRevocations(fakeRevocations{userID: revokedAt})
+	serve := func(start time.Time) *httptest.ResponseRecorder {
+		token, _, err := manager.GenerateSessionToken(userID, "player", "p@example.com", models.UserRolePlayer, start)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(revokedAt.Add(-time.Minute)).Code, "signed in before the reset")
+	assert.Equal(t, http.StatusOK, serve(revokedAt.Add(time.Minute)).Code, "signed in again afterwards")
+	assert.Equal(t, http.StatusOK, serve(revokedAt.Truncate(time.Second)).Code, "same second as the reset")
+}
+
+type disabledAccounts struct{}
+
+func (disabledAccounts) SessionsRevokedAt(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	return nil, auth.ErrAccountDisabled
+}
+
+func TestAuthMiddleware_RefusesDisabledAccounts(t *testing.T) {
+	manager := sessionManager()
+	m := auth.NewAuthMiddleware(manager)
+	m.SetSessionRevocations(disabledAccounts{})
+
+	token, _, err := manager.GenerateSessionToken(uuid.New(), "player", "p@example.com", models.UserRolePlayer, time.Now())
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, rec.Header().Get(auth.RefreshedTokenHeader))
+}
+
+func TestSessionRevoked(t *testing.T) {
+	now := time.Now()
+	claims := &auth.Claims{SessionStart: jwt.NewNumericDate(now)}
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+
+	assert.False(t, auth.SessionRevoked(claims, nil))
+	assert.True(t, auth.SessionRevoked(claims, &later))
+	assert.False(t, auth.SessionRevoked(claims, &earlier))
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedScore(t *testing.T) {
+	assert.InDelta(t, 0.5, services.ExpectedScore(1500, 1500), 1e-9)
+	assert.InDelta(t, 0.909, services.ExpectedScore(1800, 1400), 1e-3)
+	assert.InDelta(t, 1, services.ExpectedScore(1600, 1400)+services.ExpectedScore(1400, 1600), 1e-9)
+}
+
+func TestTournamentRatingChanges(t *testing.T) {
+	t.Run("winner gains what the field loses", func(t *testing.T) {
+		changes := services.TournamentRatingChanges([]float64{1500, 1500, 1500}, []int{1, 2, 3})
+		assert.InDelta(t, 16, changes[0], 1e-9)
+		assert.InDelta(t, 0, changes[1], 1e-9)
+		assert.InDelta(t, -16, changes[2], 1e-9)
+	})
+
+	t.Run("field size doesn't change the swing", func(t *testing.T) {
+		heads := services.TournamentRatingChanges([]float64{1500, 1500}, []int{1, 2})
+		ratings := make([]float64, 9)
+		positions := make([]int, 9)
+		for i := range ratings {
+			ratings[i] = 1500
+			positions[i] = i + 1
+		}
+		nine := services.TournamentRatingChanges(ratings, positions)
+		assert.InDelta(t, heads[0], nine[0], 1e-9)
+	})
+
+	t.Run("beating a stronger field pays more", func(t *testing.T) {
+		upset := services.TournamentRatingChanges([]float64{1400, 1700}, []int{1, 2})
+		expected := services.TournamentRatingChanges([]float64{1700, 1400}, []int{1, 2})
+		assert.Greater(t, upset[0], expected[0])
+	})
+
+	t.Run("a lone entrant doesn't move", func(t *testing.T) {
+		assert.Equal(t, []float64{0}, services.TournamentRatingChanges([]float64{1500}, []int{1}))
+	})
+}
+
+func TestCashRatingChange(t *testing.T) {
+	assert.InDelta(t, 0, services.CashRatingChange(1500, 1500, 0, 10000), 1e-9, "breaking even against equals is a draw")
+	assert.InDelta(t, 8, services.CashRatingChange(1500, 1500, 30000, 10000), 1e-9, "winning past a double counts once")
+	assert.InDelta(t, -8, services.CashRatingChange(1500, 1500, -10000, 10000), 1e-9)
+	assert.Less(t, services.CashRatingChange(1700, 1400, 0, 10000), 0.0, "a shark breaking even against beginners loses rating")
+	assert.Zero(t, services.CashRatingChange(1500, 1500, 500, 0))
+}
+
+func TestSkillRatingBand(t *testing.T) {
+	tests := []struct {
+		name   string
+		rating models.SkillRating
+		want   string
+	}{
+		{"provisional", models.SkillRating{Rating: 1900, Results: 9}, models.SkillBandNew},
+		{"beginner", models.SkillRating{Rating: 1399, Results: 10}, models.SkillBandBeginner},
+		{"regular", models.SkillRating{Rating: 1500, Results: 10}, models.SkillBandRegular},
+		{"strong", models.SkillRating{Rating: 1650, Results: 40}, models.SkillBandStrong},
+		{"unverified shark", models.SkillRating{Rating: 1800, Results: 29}, models.SkillBandStrong},
+		{"shark", models.SkillRating{Rating: 1750, Results: 30}, models.SkillBandShark},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rating.Band())
+		})
+	}
+}
+
+func TestMismatchedBands(t *testing.T) {
+	assert.True(t, services.MismatchedBands(models.SkillBandNew, models.SkillBandShark))
+	assert.True(t, services.MismatchedBands(models.SkillBandShark, models.SkillBandBeginner))
+	assert.False(t, services.MismatchedBands(models.SkillBandRegular, models.SkillBandShark))
+	assert.False(t, services.MismatchedBands(models.SkillBandBeginner, models.SkillBandStrong))
+	assert.False(t, services.MismatchedBands(models.SkillBandShark, models.SkillBandShark))
+}
@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/spin"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSpinTiers(t *testing.T) {
+	tiers := models.DefaultSpinTiers()
+	require.NoError(t, models.ValidateSpinTiers(tiers))
+	assert.InDelta(t, 2.8525, models.SpinExpectedMultiplier(tiers), 1e-9)
+	assert.InDelta(t, 0.0492, models.SpinHouseEdge(tiers), 0.0001)
+}
+
+func TestValidateSpinTiers(t *testing.T) {
+	winnerTakesAll := []models.PayoutPlace{{Position: 1, Percentage: 100}}
+
+	tests := []struct {
+		name  string
+		tiers []models.SpinTier
+	}{
+		{"no tiers", nil},
+		{"no house edge", []models.SpinTier{{Multiplier: 3, Weight: 1, Payouts: winnerTakesAll}}},
+		{"zero weight", []models.SpinTier{{Multiplier: 2, Weight: 0, Payouts: winnerTakesAll}}},
+		{"zero multiplier", []models.SpinTier{{Multiplier: 0, Weight: 1, Payouts: winnerTakesAll}}},
+		{"payouts short of 100%", []models.SpinTier{{Multiplier: 2, Weight: 1, Payouts: []models.PayoutPlace{{Position: 1, Percentage: 90}}}}},
+		{"more places than players", []models.SpinTier{{Multiplier: 2, Weight: 1, Payouts: []models.PayoutPlace{
+			{Position: 1, Percentage: 70}, {Position: 2, Percentage: 10}, {Position: 3, Percentage: 10}, {Position: 4, Percentage: 10},
+		}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, models.ValidateSpinTiers(tt.tiers))
+		})
+	}
+}
+
+func TestSpinDraw_Verifies(t *testing.T) {
+	serverSeed, err := spin.NewSeed()
+	require.NoError(t, err)
+	tournamentID := uuid.New()
+	tiers := models.DefaultSpinTiers()
+	clientSeed := spin.ClientSeed([]string{"alice", "bob", "carol"})
+
+	tier := spin.Draw(serverSeed, clientSeed, tournamentID, tiers)
+	assert.Equal(t, tier, spin.Draw(serverSeed, clientSeed, tournamentID, tiers), "the same seeds draw the same tier")
+
+	proof := spin.Proof{
+		TournamentID:   tournamentID,
+		Algorithm:      spin.Algorithm,
+		ServerSeedHash: spin.Commitment(serverSeed),
+		ServerSeed:     serverSeed,
+		ClientSeed:     clientSeed,
+		Tiers:          tiers,
+		Tier:           &tier,
+		Multiplier:     tiers[tier].Multiplier,
+	}
+	require.NoError(t, spin.Verify(proof))
+
+	t.Run("Another server seed", func(t *testing.T) {
+		other := proof
+		other.ServerSeed, _ = spin.NewSeed()
+		assert.ErrorIs(t, spin.Verify(other), spin.ErrCommitmentMismatch)
+	})
+
+	t.Run("Another tier", func(t *testing.T) {
+		other := proof
+		wrong := (tier + 1) % len(tiers)
+		other.Tier = &wrong
+		other.Multiplier = tiers[wrong].Multiplier
+		assert.ErrorIs(t, spin.Verify(other), spin.ErrDrawMismatch)
+	})
+
+	t.Run("Not drawn yet", func(t *testing.T) {
+		other := proof
+		other.ServerSeed, other.Tier = "", nil
+		assert.ErrorIs(t, spin.Verify(other), spin.ErrNotDrawn)
+	})
+}
+
+func TestSpinDraw_FollowsWeights(t *testing.T) {
+	winnerTakesAll := []models.PayoutPlace{{Position: 1, Percentage: 100}}
+	tiers := []models.SpinTier{
+		{Multiplier: 2, Weight: 3, Payouts: winnerTakesAll},
+		{Multiplier: 4, Weight: 1, Payouts: winnerTakesAll},
+	}
+
+	counts := make([]int, len(tiers))
+	for i := 0; i < 4000; i++ {
+		counts[spin.Draw("server-seed", "client-seed", uuid.New(), tiers)]++
+	}
+	assert.InDelta(t, 3000, counts[0], 150)
+	assert.InDelta(t, 1000, counts[1], 150)
+}
+
+func TestSpinPrizes(t *testing.T) {
+	shared := []models.PayoutPlace{{Position: 1, Percentage: 80}, {Position: 2, Percentage: 10}, {Position: 3, Percentage: 10}}
+	assert.Equal(t, []int64{800001, 100000, 100000}, spin.Prizes(1000001, shared), "the winner gets what rounding leaves")
+	assert.Equal(t, []int64{2000}, spin.Prizes(2000, []models.PayoutPlace{{Position: 1, Percentage: 100}}))
+}
+
+func TestJoinSpinRequest_Validation(t *testing.T) {
+	for seed, valid := range map[string]bool{"": true, "my lucky seed": true, "seed\n": false} {
+		req := models.JoinSpinRequest{ClientSeed: seed}
+		if valid {
+			assert.NoError(t, validation.Validate(&req), seed)
+		} else {
+			assert.Error(t, validation.Validate(&req), seed)
+		}
+	}
+}
+
+func TestAdminSpins_Unavailable(t *testing.T) {
+	h := handlers.NewAdminHandler(nil, nil)
+
+	w := httptest.NewRecorder()
+	h.ListSpinFormats(w, httptest.NewRequest(http.MethodGet, "/admin/spin-formats", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	h.GetSpinJackpot(w, httptest.NewRequest(http.MethodGet, "/admin/spins/jackpot", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackPolicyError(t *testing.T) {
+	// Blinds of 50/100 throughout
+	tests := []struct {
+		name      string
+		stackType string
+		maxBuyIn  int64
+		chipCap   int64
+		wantErr   string
+	}{
+		{"standard", services.StackTypeStandard, 20000, 0, ""},
+		{"standard above 200 big blinds", services.StackTypeStandard, 20100, 0, "at most 200 big blinds"},
+		{"unknown type", "turbo", 10000, 0, "standard, cap or deep"},
+		{"cap on a standard table", services.StackTypeStandard, 10000, 15000, "Only cap tables"},
+		{"deep", services.StackTypeDeep, 50000, 0, ""},
+		{"deep no deeper than standard", services.StackTypeDeep, 20000, 0, "above 200 big blinds"},
+		{"deep above 500 big blinds", services.StackTypeDeep, 50100, 0, "at most 500 big blinds"},
+		{"cap", services.StackTypeCap, 4000, 10000, ""},
+		{"cap below the max buy-in", services.StackTypeCap, 4000, 3000, "at least the max buy-in"},
+		{"cap without a cap", services.StackTypeCap, 4000, 0, "at least the max buy-in"},
+		{"cap above 200 big blinds", services.StackTypeCap, 4000, 30000, "Chip cap can be at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := services.StackPolicyError(tt.stackType, tt.maxBuyIn, 100, tt.chipCap)
+			if tt.wantErr == "" {
+				assert.Empty(t, msg)
+			} else {
+				assert.Contains(t, msg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckBuyIn(t *testing.T) {
+	table := &models.PokerTable{MinBuyIn: 2000, MaxBuyIn: 10000, StackType: services.StackTypeStandard}
+	assert.NoError(t, services.CheckBuyIn(table, 2000))
+	assert.NoError(t, services.CheckBuyIn(table, 10000))
+
+	err := services.CheckBuyIn(table, 10001)
+	var rangeErr *services.BuyInRangeError
+	require.True(t, errors.As(err, &rangeErr))
+	assert.Equal(t, int64(10000), rangeErr.MaxBuyIn)
+	assert.Error(t, services.CheckBuyIn(table, 1999))
+
+	t.Run("Cap tables never take more than the cap", func(t *testing.T) {
+		capped := &models.PokerTable{MinBuyIn: 2000, MaxBuyIn: 10000, StackType: services.StackTypeCap, ChipCap: 8000}
+		err := services.CheckBuyIn(capped, 9000)
+		require.True(t, errors.As(err, &rangeErr))
+		assert.Equal(t, int64(8000), rangeErr.MaxBuyIn)
+	})
+}
@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/validation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStakeTemplateService_RakeConfigFor(t *testing.T) {
+	service := services.NewStakeTemplateService(nil)
+	template := &models.StakeTemplate{
+		RakePercentage: 0.05,
+		RakeCap:        3000,
+		RakeMinPot:     1000,
+	}
+	tableID := uuid.New()
+
+	config := service.RakeConfigFor(template, tableID)
+
+	assert.Equal(t, formance.RakeStrategyPerHand, config.Strategy)
+	assert.Equal(t, 0.05, config.Percentage)
+	assert.Equal(t, int64(3000), config.MaxRake)
+	assert.Equal(t, int64(1000), config.MinPot)
+	assert.Equal(t, tableID, config.TableID)
+}
+
+func TestCreateStakeTemplateRequest_Validation(t *testing.T) {
+	valid := models.CreateStakeTemplateRequest{
+		Name:       "NL 100/200",
+		SmallBlind: 100,
+		BigBlind:   200,
+		MinBuyIn:   4000,
+		MaxBuyIn:   20000,
+		Speed:      "turbo",
+		MinTables:  1,
+		MaxTables:  5,
+	}
+	assert.NoError(t, validation.Validate(&valid))
+
+	invalidBlinds := valid
+	invalidBlinds.BigBlind = 50
+	assert.Error(t, validation.Validate(&invalidBlinds))
+
+	invalidSpeed := valid
+	invalidSpeed.Speed = "ludicrous"
+	assert.Error(t, validation.Validate(&invalidSpeed))
+
+	invalidTables := valid
+	invalidTables.MinTables = 10
+	assert.Error(t, validation.Validate(&invalidTables))
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/config"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInProcessPublisher_DeliversInOrder(t *testing.T) {
+	publisher := statsevents.NewInProcessPublisher()
+
+	var received []statsevents.Event
+	publisher.Subscribe(func(event statsevents.Event) {
+		received = append(received, event)
+	})
+
+	for pot := 0; pot < 3; pot++ {
+		statsevents.Emit(publisher, statsevents.TypePotAwarded, statsevents.PotAwarded{HandID: "ABC-1", Pot: pot, Amount: 100})
+	}
+	require.NoError(t, publisher.Close(), "close waits for queued events")
+
+	require.Len(t, received, 3)
+	for i, event := range received {
+		assert.Equal(t, statsevents.TypePotAwarded, event.Type)
+		assert.NotEqual(t, uuid.Nil, event.ID)
+
+		var data statsevents.PotAwarded
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Equal(t, i, data.Pot)
+	}
+
+	event, err := statsevents.NewEvent(statsevents.TypeHandCompleted, statsevents.HandCompleted{HandID: "ABC-2"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, publisher.Publish(context.Background(), event), statsevents.ErrPublisherClosed)
+}
+
+func TestStatsEvents_FallBackInProcess(t *testing.T) {
+	assert.IsType(t, &statsevents.InProcessPublisher{}, statsevents.New(&config.Config{}))
+
+	// Nothing listens on the discard port, so the bus is unreachable
+	publisher := statsevents.New(&config.Config{StatsEventsNATSURL: "nats://127.0.0.1:9", StatsEventsSubjectPrefix: "gp.stats"})
+	assert.IsType(t, &statsevents.InProcessPublisher{}, publisher)
+
+	// A missing publisher drops events instead of failing the caller
+	statsevents.Emit(nil, statsevents.TypePlayerSeated, statsevents.PlayerSeated{UserID: uuid.New()})
+}
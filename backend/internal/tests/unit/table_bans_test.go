@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableBans_Unavailable(t *testing.T) {
+	h := handlers.NewTableHandler(nil, nil)
+
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+	}{
+		{"list", h.ListTableBans, http.MethodGet},
+		{"ban", h.BanTableUser, http.MethodPost},
+		{"unban", h.UnbanTableUser, http.MethodDelete},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.handler(w, httptest.NewRequest(tc.method, "/tables/bans", nil))
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		})
+	}
+}
+
+func TestTableBans_OnlyPrivateTablesEnforce(t *testing.T) {
+	// Neither check reaches the database
+	bans := services.NewTableBanService(nil)
+	owner, player := uuid.New(), uuid.New()
+
+	banned, err := bans.IsBanned(context.Background(), &models.PokerTable{IsPrivate: false, CreatedBy: owner}, player)
+	require.NoError(t, err)
+	assert.False(t, banned, "public tables ignore their creator's ban list")
+
+	banned, err = bans.IsBanned(context.Background(), &models.PokerTable{IsPrivate: true, CreatedBy: owner}, owner)
+	require.NoError(t, err)
+	assert.False(t, banned, "owners always sit at their own tables")
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTableControl records the forced actions and fails with err
+type fakeTableControl struct {
+	err     error
+	actions []string
+}
+
+func (f *fakeTableControl) ForceFold(tableID, userID uuid.UUID, reason string) error {
+	f.actions = append(f.actions, "fold")
+	return f.err
+}
+
+func (f *fakeTableControl) PauseTable(tableID uuid.UUID, reason string) error {
+	f.actions = append(f.actions, "pause")
+	return f.err
+}
+
+func (f *fakeTableControl) ResumeTable(tableID uuid.UUID) error {
+	f.actions = append(f.actions, "resume")
+	return f.err
+}
+
+func (f *fakeTableControl) KickPlayer(tableID, userID uuid.UUID, reason string) (*models.RecoveredSession, error) {
+	f.actions = append(f.actions, "kick")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.RecoveredSession{TableID: tableID, Amount: 500}, nil
+}
+
+func (f *fakeTableControl) CancelHand(tableID uuid.UUID, reason string) ([]models.HandPayout, error) {
+	f.actions = append(f.actions, "cancel")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []models.HandPayout{{Position: 0, Amount: 20}}, nil
+}
+
+func tableControlRoutes(control handlers.TableControl) chi.Router {
+	h := handlers.NewAdminHandler(nil, nil)
+	if control != nil {
+		h.SetTableControl(control)
+	}
+	r := chi.NewRouter()
+	r.Post("/tables/{tableID}/force-fold", h.ForceFold)
+	r.Post("/tables/{tableID}/pause", h.PauseTable)
+	r.Post("/tables/{tableID}/resume", h.ResumeTable)
+	r.Post("/tables/{tableID}/kick", h.KickPlayer)
+	r.Post("/tables/{tableID}/cancel-hand", h.CancelHand)
+	return r
+}
+
+func postTableControl(routes chi.Router, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, uuid.New()))
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	return w
+}
+
+func TestTableControl_Actions(t *testing.T) {
+	control := &fakeTableControl{}
+	routes := tableControlRoutes(control)
+	table := "/tables/" + uuid.NewString()
+	player := `{"user_id": "` + uuid.NewString() + `", "reason": "stalling"}`
+
+	assert.Equal(t, http.StatusOK, postTableControl(routes, table+"/force-fold", player).Code)
+	assert.Equal(t, http.StatusOK, postTableControl(routes, table+"/pause", `{"reason": "investigating"}`).Code)
+	assert.Equal(t, http.StatusOK, postTableControl(routes, table+"/resume", "").Code)
+
+	w := postTableControl(routes, table+"/kick", player)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"amount":500`)
+
+	w = postTableControl(routes, table+"/cancel-hand", `{"reason": "dealer error"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"refunds"`)
+
+	assert.Equal(t, []string{"fold", "pause", "resume", "kick", "cancel"}, control.actions)
+}
+
+func TestTableControl_RejectsBadRequests(t *testing.T) {
+	control := &fakeTableControl{}
+	routes := tableControlRoutes(control)
+	table := "/tables/" + uuid.NewString()
+
+	assert.Equal(t, http.StatusBadRequest, postTableControl(routes, "/tables/not-a-table/pause", `{"reason": "x"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, postTableControl(routes, table+"/force-fold", `{"reason": "no player"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, postTableControl(routes, table+"/cancel-hand", `{}`).Code, "a reason is required")
+	assert.Empty(t, control.actions)
+
+	assert.Equal(t, http.StatusServiceUnavailable, postTableControl(tableControlRoutes(nil), table+"/resume", "").Code)
+}
+
+func TestTableControl_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{services.ErrTableNotFound, http.StatusNotFound},
+		{services.ErrPlayerNotAtTable, http.StatusNotFound},
+		{services.ErrNotPlayersTurn, http.StatusConflict},
+		{services.ErrHandInProgress, http.StatusConflict},
+		{services.ErrHandFrozen, http.StatusConflict},
+		{services.ErrTableNotPaused, http.StatusConflict},
+		{services.ErrNoHandInProgress, http.StatusConflict},
+		{assert.AnError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			routes := tableControlRoutes(&fakeTableControl{err: tt.err})
+			w := postTableControl(routes, "/tables/"+uuid.NewString()+"/kick", `{"user_id": "`+uuid.NewString()+`", "reason": "abuse"}`)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
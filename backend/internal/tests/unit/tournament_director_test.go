@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seatedTables seats the given number of players at each table, from seat 1
+func seatedTables(counts map[int]int) (map[int]map[int]uuid.UUID, int) {
+	seating := make(map[int]map[int]uuid.UUID)
+	players := 0
+	for table, count := range counts {
+		seating[table] = make(map[int]uuid.UUID)
+		for seat := 1; seat <= count; seat++ {
+			seating[table][seat] = uuid.New()
+			players++
+		}
+	}
+	return seating, players
+}
+
+func TestPlanTableMoves_MovesFromBiggestToSmallest(t *testing.T) {
+	seating, players := seatedTables(map[int]int{1: 9, 2: 6, 3: 8})
+	before := make(map[uuid.UUID]bool)
+	for _, seats := range seating {
+		for _, userID := range seats {
+			before[userID] = true
+		}
+	}
+
+	moves, broken, err := services.PlanTableMoves(seating, 9)
+	require.NoError(t, err)
+	assert.Empty(t, broken)
+	require.Len(t, moves, 1)
+	assert.Equal(t, 1, moves[0].FromTable)
+	assert.Equal(t, 2, moves[0].ToTable)
+	assert.Equal(t, models.TableMoveBalance, moves[0].Reason)
+	assert.True(t, before[moves[0].UserID])
+	assert.Equal(t, moves[0].UserID, seating[2][moves[0].ToSeat], "seating is updated with the move")
+
+	total := 0
+	for _, seats := range seating {
+		total += len(seats)
+	}
+	assert.Equal(t, players, total)
+}
+
+func TestPlanTableMoves_BreaksShortTables(t *testing.T) {
+	// 17 players fit at two tables of nine
+	seating, players := seatedTables(map[int]int{1: 7, 2: 5, 3: 5})
+
+	moves, broken, err := services.PlanTableMoves(seating, 9)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, broken, "ties break the highest numbered table")
+	require.Len(t, seating, 2)
+
+	brokenMoves := 0
+	for _, move := range moves {
+		if move.Reason == models.TableMoveBreak {
+			assert.Equal(t, 3, move.FromTable)
+			brokenMoves++
+		}
+	}
+	assert.Equal(t, 5, brokenMoves)
+
+	seen := make(map[uuid.UUID]bool)
+	for table, seats := range seating {
+		assert.InDelta(t, 8.5, len(seats), 0.5, "table %d", table)
+		for seat, userID := range seats {
+			assert.GreaterOrEqual(t, seat, 1)
+			assert.LessOrEqual(t, seat, 9)
+			assert.False(t, seen[userID], "player seated twice")
+			seen[userID] = true
+		}
+	}
+	assert.Len(t, seen, players)
+}
+
+func TestPlanTableMoves_FinalTable(t *testing.T) {
+	seating, _ := seatedTables(map[int]int{1: 2, 2: 3})
+
+	moves, broken, err := services.PlanTableMoves(seating, 6)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, broken)
+	assert.Len(t, moves, 2)
+	require.Contains(t, seating, 2)
+	assert.Len(t, seating[2], 5)
+}
+
+func TestPlanTableMoves_BalancedTablesStay(t *testing.T) {
+	seating, _ := seatedTables(map[int]int{1: 8, 2: 7, 3: 8})
+
+	moves, broken, err := services.PlanTableMoves(seating, 9)
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+	assert.Empty(t, broken)
+
+	_, _, err = services.PlanTableMoves(seating, 1)
+	assert.Error(t, err)
+}
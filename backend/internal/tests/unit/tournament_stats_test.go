@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestTournamentResultPoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		result models.TournamentResult
+		points int
+	}{
+		{"Win in field of 100", models.TournamentResult{Entrants: 100, FinalPosition: intPtr(1), PrizeAmount: 5000}, 1000},
+		{"Fourth in field of 100", models.TournamentResult{Entrants: 100, FinalPosition: intPtr(4), PrizeAmount: 1000}, 500},
+		{"Out of the money", models.TournamentResult{Entrants: 100, FinalPosition: intPtr(20)}, 0},
+		{"No recorded position", models.TournamentResult{Entrants: 100, PrizeAmount: 1000}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.points, tt.result.Points())
+		})
+	}
+}
+
+func TestTournamentStatsAdd(t *testing.T) {
+	var stats models.TournamentStats
+
+	stats.Add(models.TournamentResult{BuyIn: 1000, Entrants: 9, FinalPosition: intPtr(5)})
+	stats.Add(models.TournamentResult{BuyIn: 1000, Entrants: 9, FinalPosition: intPtr(1), PrizeAmount: 4500})
+	stats.Add(models.TournamentResult{BuyIn: 2000, Entrants: 9, FinalPosition: intPtr(3), PrizeAmount: 1500})
+	stats.Add(models.TournamentResult{BuyIn: 1000, Entrants: 9})
+
+	assert.Equal(t, 4, stats.Entries)
+	assert.Equal(t, 2, stats.Cashes)
+	assert.Equal(t, 1, stats.Wins)
+	require.NotNil(t, stats.BestFinish)
+	assert.Equal(t, 1, *stats.BestFinish)
+	assert.Equal(t, int64(5000), stats.TotalBuyIns)
+	assert.Equal(t, int64(6000), stats.TotalWinnings)
+	assert.Equal(t, 20.0, stats.ROI)
+	assert.Equal(t, 300+173, stats.Points)
+}
+
+func TestTournamentStatsNoEntries(t *testing.T) {
+	var stats models.TournamentStats
+
+	assert.Nil(t, stats.BestFinish)
+	assert.Zero(t, stats.ROI)
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeTreasury(t *testing.T) {
+	var accounts []formance.AccountData
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"address": "world", "volumes": {"MNT": {"balance": -10000}, "USD": {"balance": -50}}},
+		{"address": "player:a:wallet", "volumes": {"MNT": {"balance": 3000}, "USD": {"balance": 50}}},
+		{"address": "player:b:wallet", "volumes": {"MNT": {"balance": 2000}}},
+		{"address": "session:a:s1", "volumes": {"MNT": {"balance": 1500}}},
+		{"address": "system:tournament_pool:t1", "volumes": {"MNT": {"balance": 1000}}},
+		{"address": "system:last_longer:p1", "volumes": {"MNT": {"balance": 400}}},
+		{"address": "system:house", "volumes": {"MNT": {"balance": 700}}},
+		{"address": "system:spin_jackpot", "volumes": {"MNT": {"balance": 300}}},
+		{"address": "revenue:rake", "volumes": {"MNT": {"balance": 800}}},
+		{"address": "revenue:withdrawal_fees", "volumes": {"MNT": {"balance": 200}}},
+		{"address": "system:unknown", "volumes": {"MNT": {"balance": 100}}}
+	]`), &accounts))
+
+	now := time.Now()
+	summary := services.SummarizeTreasury(accounts, now)
+
+	assert.Equal(t, len(accounts), summary.Accounts)
+	assert.Equal(t, now, summary.GeneratedAt)
+	require.Len(t, summary.Assets, 2)
+
+	mnt := summary.Assets[0]
+	assert.Equal(t, "MNT", mnt.Asset)
+	assert.Equal(t, int64(5000), mnt.WalletLiabilities)
+	assert.Equal(t, int64(1500), mnt.SessionFunds)
+	assert.Equal(t, int64(1000), mnt.TournamentEscrow)
+	assert.Equal(t, int64(400), mnt.LastLongerEscrow)
+	assert.Equal(t, int64(7900), mnt.Liabilities)
+	assert.Equal(t, map[string]int64{"revenue:rake": 800, "revenue:withdrawal_fees": 200}, mnt.Revenue)
+	assert.Equal(t, int64(1000), mnt.RevenueTotal)
+	assert.Equal(t, int64(2000), mnt.HouseFunds)
+	assert.Equal(t, int64(100), mnt.Other)
+	assert.Equal(t, int64(5900), mnt.NetExposure)
+	// Every account is counted once, so the ledger balances to zero
+	assert.Zero(t, mnt.Liabilities+mnt.HouseFunds+mnt.Other+mnt.World)
+
+	usd := summary.Assets[1]
+	assert.Equal(t, "USD", usd.Asset)
+	assert.Equal(t, int64(50), usd.WalletLiabilities)
+	assert.Equal(t, int64(50), usd.NetExposure)
+	assert.Equal(t, int64(-50), usd.World)
+}
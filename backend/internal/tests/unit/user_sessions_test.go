@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/auth"
+	"github.com/anhbaysgalan1/gp/internal/handlers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSessionHandler_RejectsBadRequests(t *testing.T) {
+	routes := handlers.NewUserSessionHandler(nil).Routes()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		authenticated  bool
+		expectedStatus int
+	}{
+		{"List needs a user", http.MethodGet, "/", false, http.StatusUnauthorized},
+		{"Close needs a user", http.MethodDelete, "/" + uuid.NewString(), false, http.StatusUnauthorized},
+		{"Get needs a session ID", http.MethodGet, "/not-a-session", true, http.StatusBadRequest},
+		{"Close needs a session ID", http.MethodDelete, "/not-a-session", true, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authenticated {
+				req = req.WithContext(context.WithValue(req.Context(), auth.UserIDKey, uuid.New()))
+			}
+
+			w := httptest.NewRecorder()
+			routes.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
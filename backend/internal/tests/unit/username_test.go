@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsernameRules_Cooldown(t *testing.T) {
+	now := time.Now()
+	userID := uuid.New()
+	rules := services.UsernameRules{Cooldown: 30 * 24 * time.Hour}
+
+	assert.NoError(t, rules.Evaluate(userID, "alice", "alice2", time.Time{}, nil, now), "first change is always allowed")
+
+	lastChange := now.Add(-10 * 24 * time.Hour)
+	err := rules.Evaluate(userID, "alice", "alice2", lastChange, nil, now)
+	var cooldownErr *services.UsernameCooldownError
+	require.True(t, errors.As(err, &cooldownErr), "expected a cooldown error, got %v", err)
+	assert.Equal(t, lastChange.Add(rules.Cooldown), cooldownErr.NextChangeAt)
+
+	assert.NoError(t, rules.Evaluate(userID, "alice", "alice2", now.Add(-31*24*time.Hour), nil, now))
+	assert.NoError(t, services.UsernameRules{}.Evaluate(userID, "alice", "alice2", now, nil, now), "zero cooldown")
+}
+
+func TestUsernameRules_Reservation(t *testing.T) {
+	now := time.Now()
+	userID, formerOwner := uuid.New(), uuid.New()
+	rules := services.UsernameRules{Reservation: 90 * 24 * time.Hour}
+
+	reserved := []models.UsernameHistory{{
+		UserID:        formerOwner,
+		OldUsername:   "Bob",
+		ChangedAt:     now.Add(-24 * time.Hour),
+		ReservedUntil: now.Add(89 * 24 * time.Hour),
+	}}
+	assert.ErrorIs(t, rules.Evaluate(userID, "alice", "bob", time.Time{}, reserved, now), services.ErrUsernameReserved, "names compare case-insensitively")
+
+	// The former owner may take their own name back
+	assert.NoError(t, rules.Evaluate(formerOwner, "bobby", "Bob", time.Time{}, reserved, now))
+
+	expired := []models.UsernameHistory{{
+		UserID:        formerOwner,
+		OldUsername:   "bob",
+		ChangedAt:     now.Add(-91 * 24 * time.Hour),
+		ReservedUntil: now.Add(-24 * time.Hour),
+	}}
+	assert.NoError(t, rules.Evaluate(userID, "alice", "bob", time.Time{}, expired, now))
+}
+
+func TestUsernameRules_Unchanged(t *testing.T) {
+	userID := uuid.New()
+	rules := services.UsernameRules{}
+
+	assert.ErrorIs(t, rules.Evaluate(userID, "alice", "alice", time.Time{}, nil, time.Now()), services.ErrUsernameUnchanged)
+	assert.NoError(t, rules.Evaluate(userID, "alice", "Alice", time.Time{}, nil, time.Now()), "changing case is a change")
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func walletOps(operation string, now time.Time, amount int64, agos ...time.Duration) []models.WalletOperation {
+	ops := make([]models.WalletOperation, 0, len(agos))
+	for _, ago := range agos {
+		ops = append(ops, models.WalletOperation{Operation: operation, Amount: amount, CreatedAt: now.Add(-ago)})
+	}
+	return ops
+}
+
+func requireVelocityError(t *testing.T, err error) *services.VelocityLimitError {
+	t.Helper()
+	var limitErr *services.VelocityLimitError
+	require.True(t, errors.As(err, &limitErr), "expected a velocity limit error, got %v", err)
+	return limitErr
+}
+
+func TestVelocityRules_DailyCap(t *testing.T) {
+	rules := services.VelocityRules{DailyCaps: []int64{100, 1000}}
+
+	assert.Equal(t, int64(100), rules.DailyCap(models.KYCTierNone))
+	assert.Equal(t, int64(1000), rules.DailyCap(models.KYCTierBasic))
+	assert.Equal(t, int64(1000), rules.DailyCap(models.KYCTierFull), "tiers past the end use the last cap")
+	assert.Equal(t, int64(0), services.VelocityRules{}.DailyCap(models.KYCTierFull))
+}
+
+func TestVelocityRules_HourlyDeposits(t *testing.T) {
+	now := time.Now()
+	rules := services.VelocityRules{DepositsPerHour: 2}
+	history := walletOps(models.WalletOperationDeposit, now, 10, 50*time.Minute, 10*time.Minute)
+
+	limitErr := requireVelocityError(t, rules.Evaluate(models.WalletOperationDeposit, 0, 10, history, now))
+	assert.Equal(t, services.VelocityRuleHourlyCount, limitErr.Rule)
+	assert.WithinDuration(t, now.Add(10*time.Minute), limitErr.NextAllowedAt, time.Second)
+
+	// Withdrawals are not counted against deposits
+	assert.NoError(t, rules.Evaluate(models.WalletOperationWithdrawal, 0, 10, history, now))
+}
+
+func TestVelocityRules_WithdrawalCooldownAndDailyCount(t *testing.T) {
+	now := time.Now()
+	rules := services.VelocityRules{WithdrawalsPerDay: 2, WithdrawalCooldown: time.Hour}
+
+	recent := walletOps(models.WalletOperationWithdrawal, now, 10, 20*time.Minute)
+	limitErr := requireVelocityError(t, rules.Evaluate(models.WalletOperationWithdrawal, 0, 10, recent, now))
+	assert.Equal(t, services.VelocityRuleCooldown, limitErr.Rule)
+	assert.WithinDuration(t, now.Add(40*time.Minute), limitErr.NextAllowedAt, time.Second)
+
+	twice := walletOps(models.WalletOperationWithdrawal, now, 10, 20*time.Hour, 2*time.Hour)
+	limitErr = requireVelocityError(t, rules.Evaluate(models.WalletOperationWithdrawal, 0, 10, twice, now))
+	assert.Equal(t, services.VelocityRuleDailyCount, limitErr.Rule)
+	assert.WithinDuration(t, now.Add(4*time.Hour), limitErr.NextAllowedAt, time.Second)
+
+	old := walletOps(models.WalletOperationWithdrawal, now, 10, 30*time.Hour, 25*time.Hour)
+	assert.NoError(t, rules.Evaluate(models.WalletOperationWithdrawal, 0, 10, old, now))
+}
+
+func TestVelocityRules_DailyCapByKYCTier(t *testing.T) {
+	now := time.Now()
+	rules := services.VelocityRules{DailyCaps: []int64{1000, 5000}}
+	history := walletOps(models.WalletOperationDeposit, now, 400, 12*time.Hour, 6*time.Hour)
+
+	limitErr := requireVelocityError(t, rules.Evaluate(models.WalletOperationDeposit, models.KYCTierNone, 500, history, now))
+	assert.Equal(t, services.VelocityRuleDailyCap, limitErr.Rule)
+	assert.WithinDuration(t, now.Add(12*time.Hour), limitErr.NextAllowedAt, time.Second)
+
+	assert.NoError(t, rules.Evaluate(models.WalletOperationDeposit, models.KYCTierBasic, 500, history, now))
+
+	// Waiting can't help an amount over the cap on its own
+	limitErr = requireVelocityError(t, rules.Evaluate(models.WalletOperationDeposit, models.KYCTierNone, 2000, nil, now))
+	assert.True(t, limitErr.NextAllowedAt.IsZero())
+}
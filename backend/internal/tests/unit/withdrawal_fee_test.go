@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithdrawalFeeSchedule_Terms(t *testing.T) {
+	schedule := services.WithdrawalFeeSchedule{
+		{Asset: services.AnyAsset, Minimum: 1000, FlatFee: 500},
+		{Asset: services.AnyAsset, KYCTier: models.KYCTierFull, Minimum: 1000},
+		{Asset: "USDT", Minimum: 10, FeeBps: 100},
+	}
+
+	assert.Equal(t, int64(500), schedule.Terms("MNT", models.KYCTierNone).FlatFee)
+	assert.Equal(t, int64(500), schedule.Terms("MNT", models.KYCTierBasic).FlatFee, "tiers use the highest rule not above them")
+	assert.Equal(t, int64(0), schedule.Terms("MNT", models.KYCTierFull).FlatFee)
+
+	usdt := schedule.Terms("USDT", models.KYCTierFull)
+	assert.Equal(t, int64(10), usdt.Minimum, "a rule for the asset wins over the wildcard")
+	assert.Equal(t, int64(100), usdt.FeeBps)
+
+	assert.Equal(t, models.WithdrawalFeeTerms{Asset: "MNT"}, services.WithdrawalFeeSchedule{}.Terms("MNT", models.KYCTierNone))
+}
+
+func TestWithdrawalFeeSchedule_Quote(t *testing.T) {
+	schedule := services.WithdrawalFeeSchedule{
+		{Asset: services.AnyAsset, Minimum: 1000, FlatFee: 500, FeeBps: 150, FreeAbove: 1_000_000},
+	}
+
+	quote, err := schedule.Quote("MNT", models.KYCTierNone, 100_000)
+	require.NoError(t, err)
+	assert.Equal(t, models.WithdrawalQuote{
+		Asset:      "MNT",
+		Amount:     100_000,
+		FlatFee:    500,
+		PercentFee: 1500,
+		Fee:        2000,
+		NetAmount:  98_000,
+	}, *quote)
+
+	quote, err = schedule.Quote("MNT", models.KYCTierNone, 1_000_000)
+	require.NoError(t, err)
+	assert.True(t, quote.FeeWaived)
+	assert.Equal(t, int64(0), quote.Fee)
+	assert.Equal(t, int64(1_000_000), quote.NetAmount)
+
+	var minimumErr *services.WithdrawalMinimumError
+	_, err = schedule.Quote("MNT", models.KYCTierNone, 999)
+	require.True(t, errors.As(err, &minimumErr))
+	assert.Equal(t, int64(1000), minimumErr.Minimum)
+
+	// Fees may never take the whole withdrawal
+	_, err = services.WithdrawalFeeSchedule{{Asset: services.AnyAsset, FlatFee: 500}}.Quote("MNT", models.KYCTierNone, 500)
+	require.True(t, errors.As(err, &minimumErr))
+	assert.Equal(t, int64(501), minimumErr.Minimum)
+}
+
+func TestDefaultWithdrawalFeeSchedule(t *testing.T) {
+	quote, err := services.DefaultWithdrawalFeeSchedule().Quote("MNT", models.KYCTierNone, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), quote.Fee)
+	assert.False(t, quote.FeeWaived)
+
+	_, err = services.DefaultWithdrawalFeeSchedule().Quote("MNT", models.KYCTierNone, 999)
+	assert.Error(t, err)
+}
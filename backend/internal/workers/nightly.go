@@ -0,0 +1,101 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// JobFunc is a unit of background work. now is the scheduled run time.
+type JobFunc func(ctx context.Context, now time.Time) error
+
+type job struct {
+	name string
+	run  JobFunc
+}
+
+// NightlyWorkers runs registered jobs once a day at a fixed UTC hour
+type NightlyWorkers struct {
+	hour   int
+	jobs   []job
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNightlyWorkers creates a scheduler that fires every day at hour (0-23, UTC)
+func NewNightlyWorkers(hour int) *NightlyWorkers {
+	if hour < 0 || hour > 23 {
+		hour = 3
+	}
+	return &NightlyWorkers{hour: hour}
+}
+
+// Register adds a job to the nightly run. Jobs run sequentially in registration order.
+func (nw *NightlyWorkers) Register(name string, run JobFunc) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	nw.jobs = append(nw.jobs, job{name: name, run: run})
+}
+
+// Start begins the nightly schedule in the background
+func (nw *NightlyWorkers) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	nw.cancel = cancel
+	nw.done = make(chan struct{})
+
+	go func() {
+		defer close(nw.done)
+		for {
+			next := nw.nextRun(time.Now().UTC())
+			slog.Info("Next nightly workers run scheduled", "at", next)
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				nw.RunAll(ctx, next)
+			}
+		}
+	}()
+}
+
+// Stop cancels the schedule and waits for a running job to observe cancellation
+func (nw *NightlyWorkers) Stop() {
+	if nw.cancel == nil {
+		return
+	}
+	nw.cancel()
+	<-nw.done
+}
+
+// RunAll runs every registered job immediately
+func (nw *NightlyWorkers) RunAll(ctx context.Context, now time.Time) {
+	nw.mu.Lock()
+	jobs := make([]job, len(nw.jobs))
+	copy(jobs, nw.jobs)
+	nw.mu.Unlock()
+
+	for _, j := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		if err := j.run(ctx, now); err != nil {
+			slog.Error("Nightly job failed", "job", j.name, "error", err, "duration", time.Since(start))
+			continue
+		}
+		slog.Info("Nightly job completed", "job", j.name, "duration", time.Since(start))
+	}
+}
+
+func (nw *NightlyWorkers) nextRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), nw.hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PeriodicWorker runs a single job at a fixed interval
+type PeriodicWorker struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewPeriodicWorker creates a worker that runs fn every interval
+func NewPeriodicWorker(name string, interval time.Duration, run JobFunc) *PeriodicWorker {
+	return &PeriodicWorker{name: name, interval: interval, run: run}
+}
+
+// Start begins running the job in the background
+func (pw *PeriodicWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	pw.cancel = cancel
+	pw.done = make(chan struct{})
+
+	go func() {
+		defer close(pw.done)
+		ticker := time.NewTicker(pw.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err := pw.run(ctx, now); err != nil {
+					slog.Error("Periodic job failed", "job", pw.name, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop cancels the worker and waits for an in-flight run to finish
+func (pw *PeriodicWorker) Stop() {
+	if pw.cancel == nil {
+		return
+	}
+	pw.cancel()
+	<-pw.done
+}
@@ -2,6 +2,8 @@ package poker
 
 import (
 	"sort"
+
+	. "github.com/alexclewontin/riverboat/eval"
 )
 
 // Action is the generic type of all state machine transitions, formalized to better allow external agents to interact with the game.
@@ -55,6 +57,9 @@ func bet(g *Game, pn uint, data uint) error {
 	if !g.canOpen(pn) {
 		//Won't hit now, reserved for future implementations
 		betLegalError = ErrIllegalAction
+	} else if g.config.Variant.potLimit() && min(betVal, p.Stack) > g.potLimitMax(pn) {
+		// More than the pot in a pot limit game, all-in or not
+		betLegalError = ErrIllegalAction
 	} else if betVal >= maxBet {
 		//You can always go all-in
 		betLegalError = nil
@@ -122,6 +127,79 @@ func buyIn(g *Game, pn uint, data uint) error {
 	return nil
 }
 
+// CashOut removes chips from the player's stack. For CashOut, data is the amount to remove.
+// CashOut will return an error if the player attempting it is in the current round, or if
+// data is zero or more than the player's stack. Limits on how much may be removed (e.g. only
+// chips above the maximum buy in) are table policy and are left to the caller.
+func CashOut(g *Game, pn uint, data uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return cashOut(g, pn, data)
+}
+
+func cashOut(g *Game, pn uint, data uint) error {
+	p := g.getPlayer(pn)
+
+	//Can't take chips off the table mid-hand
+	if p.In {
+		return ErrIllegalAction
+	}
+
+	if data == 0 || data > p.Stack {
+		return ErrIllegalAction
+	}
+
+	p.Stack = p.Stack - data
+
+	return nil
+}
+
+// RestoreChips puts chips taken off by CashOut, CapStack or TakeRake back on the
+// player's stack, for when the matching ledger transfer failed. Only that
+// player's stack is touched.
+func RestoreChips(g *Game, pn uint, data uint) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	p := g.getPlayer(pn)
+	p.Stack = p.Stack + data
+}
+
+// CapStack removes the chips above limit from the player's stack and returns how many
+// were removed. Unlike CashOut it is allowed for players still marked in the last hand,
+// whose cards stay shown until the next deal, but not while a hand is running.
+func CapStack(g *Game, pn uint, limit uint) (uint, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return 0, ErrIllegalAction
+	}
+	p := g.getPlayer(pn)
+	if p.Stack <= limit {
+		return 0, nil
+	}
+	excess := p.Stack - limit
+	p.Stack = limit
+	return excess, nil
+}
+
+// TakeRake removes the rake due on a pot the player was just paid from their
+// stack. Like CapStack it is only allowed between hands.
+func TakeRake(g *Game, pn uint, amount uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+	p := g.getPlayer(pn)
+	if p.Stack < amount {
+		return ErrIllegalAction
+	}
+	p.Stack -= amount
+	return nil
+}
+
 // SetUsername sets a player's username
 func SetUsername(g *Game, pn uint, data string) error {
 	g.mtx.Lock()
@@ -213,6 +291,15 @@ func deal(g *Game, pn uint, data uint) error {
 
 	//TODO: if all or all but one are all-in and its not the end, don't set betting to true on the next deal
 
+	// A hand that can't be dealt correctly is void rather than dealt with
+	// missing or repeated cards
+	if stage != PreDeal {
+		if reason, detail, ok := g.checkDeck(stage); !ok {
+			g.voidHand(reason, detail)
+			return ErrMisdeal
+		}
+	}
+
 	switch stage {
 	case PreDeal:
 
@@ -227,25 +314,46 @@ func deal(g *Game, pn uint, data uint) error {
 
 		g.actionNum = g.utgNum
 
-		for i := 0; i < 3; i++ {
-			g.deck.Shuffle()
+		if g.stackedDeck != nil {
+			g.deck = g.stackedDeck
+			g.stackedDeck = nil
+		} else {
+			g.shuffleDeck()
+		}
+
+		if reason, detail, ok := g.checkDeck(stage); !ok {
+			g.voidHand(reason, detail)
+			return ErrMisdeal
 		}
 
+		holeCards := g.config.Variant.holeCards()
 		for i, p := range g.players {
+			// Views handed out for the last hand may share the old slice
+			g.players[i].Cards = make([]Card, holeCards)
 			if p.Ready {
-				g.players[i].Cards[0] = g.deck.Pop()
-				g.players[i].Cards[1] = g.deck.Pop()
+				for j := range g.players[i].Cards {
+					g.players[i].Cards[j] = g.deck.Pop()
+				}
 				g.players[i].In = true
 			} else {
-				g.players[i].Cards[0] = 0
-				g.players[i].Cards[1] = 0
+				// Players stay in after a showdown so their cards can be
+				// shown, so anyone not dealt in has to be taken out here
+				g.players[i].In = false
 			}
 
 			g.players[i].Called = false
 		}
 
+		// Classic antes come out of the stack before the blinds, a big blind
+		// ante after them
+		if !g.config.BigBlindAnte {
+			g.postAntes()
+		}
 		g.players[g.sbNum].putInChips(g.config.SmallBlind)
 		g.players[g.bbNum].putInChips(g.config.BigBlind)
+		if g.config.BigBlindAnte {
+			g.postAntes()
+		}
 
 	case PreFlop:
 
@@ -338,6 +446,84 @@ func leave(g *Game, pn uint, data uint) error {
 	return nil
 }
 
+// Unseat marks a player as having left between hands. Unlike Leave it is allowed for players
+// still marked in the last hand, whose shown cards go with them, but not while a hand is running.
+func Unseat(g *Game, pn uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+	p := g.getPlayer(pn)
+	p.In = false
+	p.Ready = false
+	p.Left = true
+	p.clearCards()
+
+	g.passButton(pn)
+	g.updateBlindNums()
+
+	return nil
+}
+
+// passButton moves the button on from a seat that won't be dealt in, rather than leave it there
+func (g *Game) passButton(pn uint) {
+	if pn != g.dealerNum {
+		return
+	}
+	for i := 1; i < len(g.players); i++ {
+		next := (pn + uint(i)) % uint(len(g.players))
+		if g.players[next].Ready {
+			g.dealerNum = next
+			return
+		}
+	}
+}
+
+// SitOut stops dealing a player in while keeping their seat and stack. A player in the hand
+// being played finishes it first and is not dealt the next one.
+func SitOut(g *Game, pn uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	p := g.getPlayer(pn)
+	if p.Left {
+		return ErrIllegalAction
+	}
+	p.SittingOut = true
+	if p.In {
+		return nil
+	}
+	p.Ready = false
+
+	if g.getStage() == PreDeal && !g.getBetting() {
+		g.passButton(pn)
+		g.updateBlindNums()
+	}
+
+	return nil
+}
+
+// SitIn deals a player who was sitting out back in from the next hand
+func SitIn(g *Game, pn uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	p := g.getPlayer(pn)
+	if !p.SittingOut || p.Left || p.Stack == 0 {
+		return ErrIllegalAction
+	}
+	p.SittingOut = false
+	p.Ready = true
+
+	if g.getStage() == PreDeal && !g.getBetting() {
+		g.updateBlindNums()
+	}
+
+	return nil
+}
+
 // ToggleReady marks a player as "ready" if they are currently "not ready"
 // or "not ready" if they are currently "ready." If the player attempting it is in the current round
 // ToggleReady will return an error. If the player attempting it has no money, ToggleReady will return an error.
@@ -357,10 +543,10 @@ func toggleReady(g *Game, pn uint, data uint) error {
 
 	if p.Ready {
 		p.Ready = false
-		p.Cards[0] = 0
-		p.Cards[1] = 0
+		p.clearCards()
 	} else {
-		if p.Stack == 0 {
+		// Players sitting out come back through SitIn
+		if p.Stack == 0 || p.SittingOut {
 			return ErrIllegalAction
 		}
 		p.Ready = true
@@ -1,7 +1,10 @@
 package poker
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
 )
 
 func TestIntegration_Scenarios(t *testing.T) {
@@ -642,3 +645,310 @@ func TestIntegration_Scenarios(t *testing.T) {
 		}
 	})
 }
+
+func TestCashOut(t *testing.T) {
+	g := NewGame()
+	pn := g.AddPlayer()
+
+	if err := BuyIn(g, pn, 500); err != nil {
+		t.Fatalf("Test failed - Error buying in: %s", err)
+	}
+
+	if err := CashOut(g, pn, 0); err != ErrIllegalAction {
+		t.Error("Test failed - CashOut of zero chips must return ErrIllegalAction")
+	}
+	if err := CashOut(g, pn, 501); err != ErrIllegalAction {
+		t.Error("Test failed - CashOut of more than the stack must return ErrIllegalAction")
+	}
+
+	if err := CashOut(g, pn, 200); err != nil {
+		t.Errorf("Test failed - Error cashing out: %s", err)
+	}
+	if g.players[pn].Stack != 300 {
+		t.Errorf("Test failed - stack should be 300 after cashing out 200, got %d", g.players[pn].Stack)
+	}
+
+	g.players[pn].In = true
+	if err := CashOut(g, pn, 100); err != ErrIllegalAction {
+		t.Error("Test failed - CashOut while in the hand must return ErrIllegalAction")
+	}
+}
+
+func setupAnteGame(t *testing.T, config GameConfig, stacks ...uint) *Game {
+	g := NewGame()
+	if err := g.SetConfig(config); err != nil {
+		t.Fatalf("Test failed - Error setting config: %s", err)
+	}
+	for _, stack := range stacks {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, stack); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+	return g
+}
+
+func TestBigBlindAnte(t *testing.T) {
+	t.Run("Big blind posts the table ante", func(t *testing.T) {
+		g := setupAnteGame(t, GameConfig{SmallBlind: 10, BigBlind: 20, Ante: 20, BigBlindAnte: true}, 1000, 1000, 1000)
+		before := ChipTotal(g.GenerateOmniView())
+
+		for i, p := range g.players {
+			if uint(i) == g.bbNum {
+				if p.Ante != 20 || p.Bet != 20 || p.Stack != 960 {
+					t.Errorf("Test failed - big blind should post 20 + 20 ante, got bet %d ante %d stack %d", p.Bet, p.Ante, p.Stack)
+				}
+			} else if p.Ante != 0 {
+				t.Errorf("Test failed - player %d should not post an ante with a big blind ante, got %d", i, p.Ante)
+			}
+		}
+
+		// Everyone folds to the big blind, who wins blinds and ante
+		if err := Fold(g, g.actionNum, 0); err != nil {
+			t.Fatalf("Test failed - Error folding: %s", err)
+		}
+		if err := Fold(g, g.actionNum, 0); err != nil {
+			t.Fatalf("Test failed - Error folding: %s", err)
+		}
+
+		if after := ChipTotal(g.GenerateOmniView()); after != before {
+			t.Errorf("Test failed - chips not conserved, %d before and %d after", before, after)
+		}
+		if g.players[2].Stack != 1010 {
+			t.Errorf("Test failed - big blind should win the small blind and get back the ante, stack is %d", g.players[2].Stack)
+		}
+		if g.players[2].Ante != 0 {
+			t.Error("Test failed - antes must be cleared when the hand ends")
+		}
+	})
+
+	t.Run("Short big blind covers the blind before the ante", func(t *testing.T) {
+		g := setupAnteGame(t, GameConfig{SmallBlind: 10, BigBlind: 20, Ante: 20, BigBlindAnte: true}, 1000, 1000, 30)
+
+		bb := g.players[g.bbNum]
+		if bb.Bet != 20 || bb.Ante != 10 || bb.Stack != 0 {
+			t.Errorf("Test failed - short big blind should post the full blind and 10 ante, got bet %d ante %d stack %d", bb.Bet, bb.Ante, bb.Stack)
+		}
+		if !bb.allIn() {
+			t.Error("Test failed - short big blind should be all in")
+		}
+
+		// Both others call, so the main pot holds the blinds plus the partial ante
+		if err := Bet(g, g.actionNum, 20); err != nil {
+			t.Fatalf("Test failed - Error calling: %s", err)
+		}
+		if err := Bet(g, g.actionNum, 10); err != nil {
+			t.Fatalf("Test failed - Error calling: %s", err)
+		}
+
+		if len(g.pots) == 0 || g.pots[0].Amt != 70 {
+			t.Fatalf("Test failed - main pot should be 70, got %+v", g.pots)
+		}
+		eligible := false
+		for _, pn := range g.pots[0].EligiblePlayerNums {
+			if pn == g.bbNum {
+				eligible = true
+			}
+		}
+		if !eligible {
+			t.Error("Test failed - all-in big blind must be eligible for the main pot")
+		}
+	})
+
+	t.Run("Classic ante is posted by every player", func(t *testing.T) {
+		g := setupAnteGame(t, GameConfig{SmallBlind: 10, BigBlind: 20, Ante: 5}, 1000, 1000, 1000)
+
+		for i, p := range g.players {
+			if p.Ante != 5 {
+				t.Errorf("Test failed - player %d should post a 5 ante, got %d", i, p.Ante)
+			}
+		}
+	})
+
+	t.Run("Config can only change between hands", func(t *testing.T) {
+		g := setupAnteGame(t, GameConfig{SmallBlind: 10, BigBlind: 20}, 1000, 1000)
+
+		if err := g.SetConfig(GameConfig{SmallBlind: 20, BigBlind: 40, Ante: 40, BigBlindAnte: true}); err != ErrIllegalAction {
+			t.Error("Test failed - SetConfig during a hand must return ErrIllegalAction")
+		}
+	})
+}
+
+func TestStackDeck(t *testing.T) {
+	order := []eval.Card{
+		eval.MustParseCardString("As"), eval.MustParseCardString("Ah"), // player 0
+		eval.MustParseCardString("7c"), eval.MustParseCardString("2d"), // player 1
+		eval.MustParseCardString("Ks"), eval.MustParseCardString("Kh"), eval.MustParseCardString("3c"), // flop
+		eval.MustParseCardString("9d"), // turn
+		eval.MustParseCardString("4s"), // river
+	}
+
+	g := NewGame()
+	for i := 0; i < 2; i++ {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, 1000); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := g.StackDeck(order); err != nil {
+		t.Fatalf("Test failed - Error stacking deck: %s", err)
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+
+	if !slices.Equal(g.players[0].Cards, order[0:2]) || !slices.Equal(g.players[1].Cards, order[2:4]) {
+		t.Errorf("Test failed - hole cards not dealt in stacked order, got %v and %v", g.players[0].Cards, g.players[1].Cards)
+	}
+	if len(g.deck) != len(eval.DefaultDeck)-4 {
+		t.Errorf("Test failed - rest of the deck should stay in play, %d cards left", len(g.deck))
+	}
+
+	// Check it down to the river
+	for g.getStage() != PreDeal || g.getBetting() {
+		if err := Bet(g, g.actionNum, g.toCall()-g.players[g.actionNum].Bet); err != nil {
+			t.Fatalf("Test failed - Error checking down: %s", err)
+		}
+	}
+	for i, c := range g.communityCards {
+		if c != order[4+i] {
+			t.Errorf("Test failed - community card %d should be %s, got %s", i, order[4+i], c)
+		}
+	}
+	if len(g.pots) == 0 || len(g.pots[0].WinningPlayerNums) != 1 || g.pots[0].WinningPlayerNums[0] != 0 {
+		t.Errorf("Test failed - aces full should win, got %+v", g.pots)
+	}
+
+	if g.StackDeck([]eval.Card{order[0], order[0]}) != ErrIllegalAction {
+		t.Error("Test failed - duplicate cards should not be stacked")
+	}
+	if g.StackDeck([]eval.Card{0}) != ErrIllegalAction {
+		t.Error("Test failed - invalid cards should not be stacked")
+	}
+}
+
+func TestCapStack(t *testing.T) {
+	g := NewGame()
+	pn := g.AddPlayer()
+	if err := BuyIn(g, pn, 500); err != nil {
+		t.Fatalf("Test failed - Error buying in: %s", err)
+	}
+
+	// Still marked in the last hand, whose showdown is shown until the next deal
+	g.players[pn].In = true
+	excess, err := CapStack(g, pn, 300)
+	if err != nil || excess != 200 || g.players[pn].Stack != 300 {
+		t.Errorf("Test failed - CapStack removed %d to leave %d: %v, want 200 and 300", excess, g.players[pn].Stack, err)
+	}
+	if excess, err := CapStack(g, pn, 400); err != nil || excess != 0 {
+		t.Errorf("Test failed - CapStack under the limit removed %d: %v", excess, err)
+	}
+
+	g.setStageAndBetting(PreFlop, true)
+	if _, err := CapStack(g, pn, 100); err != ErrIllegalAction {
+		t.Error("Test failed - CapStack while a hand is running must return ErrIllegalAction")
+	}
+}
+
+func TestTakeRake(t *testing.T) {
+	g := NewGame()
+	pn := g.AddPlayer()
+	if err := BuyIn(g, pn, 500); err != nil {
+		t.Fatalf("Test failed - Error buying in: %s", err)
+	}
+
+	if err := TakeRake(g, pn, 25); err != nil || g.players[pn].Stack != 475 {
+		t.Errorf("Test failed - TakeRake left %d: %v, want 475", g.players[pn].Stack, err)
+	}
+	if err := TakeRake(g, pn, 1000); err != ErrIllegalAction || g.players[pn].Stack != 475 {
+		t.Error("Test failed - TakeRake of more than the stack must return ErrIllegalAction")
+	}
+
+	g.setStageAndBetting(PreFlop, true)
+	if err := TakeRake(g, pn, 25); err != ErrIllegalAction {
+		t.Error("Test failed - TakeRake while a hand is running must return ErrIllegalAction")
+	}
+}
+
+func TestUnseat(t *testing.T) {
+	g := dealVariant(t, VariantHoldem)
+	dealer := g.dealerNum
+	if err := Unseat(g, dealer); err != ErrIllegalAction {
+		t.Fatal("Test failed - Unseat while a hand is running must return ErrIllegalAction")
+	}
+
+	// Still marked in the last hand, whose showdown is shown until the next deal
+	g.EndHandAndReset()
+	if err := Unseat(g, dealer); err != nil {
+		t.Fatalf("Test failed - Unseat between hands: %s", err)
+	}
+	p := g.players[dealer]
+	if p.In || p.Ready || !p.Left || p.dealtIn() {
+		t.Errorf("Test failed - unseated player is in %t, ready %t, left %t, with cards %v", p.In, p.Ready, p.Left, p.Cards)
+	}
+	if g.dealerNum == dealer {
+		t.Error("Test failed - the button stayed at the empty seat")
+	}
+
+	// The players left can carry on without the one who went
+	if err := g.Start(); err != nil {
+		t.Fatalf("Test failed - Start after a player left: %s", err)
+	}
+	if g.players[dealer].dealtIn() {
+		t.Error("Test failed - the unseated player was dealt in")
+	}
+}
+
+func TestSitOut(t *testing.T) {
+	g := dealVariant(t, VariantHoldem)
+	dealer := g.dealerNum
+
+	// A player in the hand finishes it and sits out from the next one
+	if err := SitOut(g, dealer); err != nil {
+		t.Fatalf("Test failed - SitOut during a hand: %s", err)
+	}
+	if !g.players[dealer].In {
+		t.Error("Test failed - sitting out took the player out of the hand being played")
+	}
+	g.EndHandAndReset()
+	if g.players[dealer].Ready {
+		t.Error("Test failed - a player sitting out is still ready after the hand")
+	}
+	if err := ToggleReady(g, dealer, 0); err != ErrIllegalAction {
+		t.Error("Test failed - a player sitting out marked themselves ready")
+	}
+
+	if err := g.Start(); err != nil {
+		t.Fatalf("Test failed - Start with a player sitting out: %s", err)
+	}
+	if g.players[dealer].dealtIn() || g.players[dealer].In {
+		t.Error("Test failed - the player sitting out was dealt in")
+	}
+	if g.dealerNum == dealer {
+		t.Error("Test failed - the button stayed with the player sitting out")
+	}
+
+	// Sitting back in deals the player in from the next hand
+	if err := SitIn(g, dealer); err != nil {
+		t.Fatalf("Test failed - SitIn: %s", err)
+	}
+	g.EndHandAndReset()
+	if err := g.Start(); err != nil {
+		t.Fatalf("Test failed - Start after sitting back in: %s", err)
+	}
+	if !g.players[dealer].In {
+		t.Error("Test failed - the player who sat back in was not dealt in")
+	}
+	if err := SitIn(g, dealer); err != ErrIllegalAction {
+		t.Error("Test failed - SitIn for a player who is not sitting out must return ErrIllegalAction")
+	}
+}
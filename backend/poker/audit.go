@@ -0,0 +1,137 @@
+package poker
+
+import (
+	"fmt"
+)
+
+// Violation describes a single way in which the state after an action differs
+// from what the rules allow given the state before it.
+type Violation struct {
+	PlayerNum int    `json:"playerNum"` // -1 for table-wide violations
+	Rule      string `json:"rule"`
+	Detail    string `json:"detail"`
+}
+
+func (v Violation) String() string {
+	if v.PlayerNum < 0 {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Detail)
+	}
+	return fmt.Sprintf("%s (player %d): %s", v.Rule, v.PlayerNum, v.Detail)
+}
+
+// ChipTotal returns every chip on the table: stacks plus chips committed to the current hand.
+func ChipTotal(gv *GameView) uint {
+	var total uint
+	for _, p := range gv.Players {
+		total += p.Stack + p.TotalBet + p.Ante
+	}
+	return total
+}
+
+// AuditAction recomputes what a betting action by player pn putting in chips
+// (0 for checks and folds) may have done to the stacks in pre, and reports
+// every way post diverges from that. An empty result means post is
+// reachable from pre by that single action.
+//
+// Mid-hand, chips only move from a stack into that player's bets (or back, for
+// uncalled bets), so each player's stack plus total bet is conserved and only
+// the actor may commit new chips. When the action ends the hand, pots are paid
+// out, so stacks may only grow, except for the actor's own contribution, and
+// at most one chip per player may be lost to split remainders.
+func AuditAction(pre, post *GameView, pn uint, chips uint) []Violation {
+	var violations []Violation
+
+	if len(pre.Players) != len(post.Players) {
+		return append(violations, Violation{
+			PlayerNum: -1,
+			Rule:      "player_count",
+			Detail:    fmt.Sprintf("players changed from %d to %d", len(pre.Players), len(post.Players)),
+		})
+	}
+
+	handEnded := pre.Running && !post.Running
+	preTotal, postTotal := ChipTotal(pre), ChipTotal(post)
+
+	if !handEnded && postTotal != preTotal {
+		violations = append(violations, Violation{
+			PlayerNum: -1,
+			Rule:      "chip_conservation",
+			Detail:    fmt.Sprintf("table total changed from %d to %d", preTotal, postTotal),
+		})
+	}
+	if handEnded && (postTotal > preTotal || preTotal-postTotal >= uint(len(pre.Players))) {
+		violations = append(violations, Violation{
+			PlayerNum: -1,
+			Rule:      "settlement_conservation",
+			Detail:    fmt.Sprintf("table total changed from %d to %d at settlement", preTotal, postTotal),
+		})
+	}
+
+	for i := range pre.Players {
+		before, after := pre.Players[i], post.Players[i]
+
+		committed := uint(0)
+		if uint(i) == pn {
+			committed = chips
+			if committed > before.Stack {
+				committed = before.Stack
+			}
+		}
+
+		if after.Stack+committed < before.Stack {
+			violations = append(violations, Violation{
+				PlayerNum: i,
+				Rule:      "stack_decrease",
+				Detail:    fmt.Sprintf("stack went from %d to %d but at most %d could be committed", before.Stack, after.Stack, committed),
+			})
+		}
+
+		if handEnded {
+			continue
+		}
+
+		if after.Stack+after.TotalBet+after.Ante != before.Stack+before.TotalBet+before.Ante {
+			violations = append(violations, Violation{
+				PlayerNum: i,
+				Rule:      "player_conservation",
+				Detail:    fmt.Sprintf("stack+bets went from %d to %d", before.Stack+before.TotalBet+before.Ante, after.Stack+after.TotalBet+after.Ante),
+			})
+		}
+		if after.TotalBet > before.TotalBet+committed {
+			violations = append(violations, Violation{
+				PlayerNum: i,
+				Rule:      "bet_increase",
+				Detail:    fmt.Sprintf("total bet went from %d to %d but only %d was committed", before.TotalBet, after.TotalBet, committed),
+			})
+		}
+	}
+
+	return violations
+}
+
+// DiffStacks reports players whose stack or committed chips differ between two
+// views of the same hand. It is used to detect writes made between actions.
+func DiffStacks(expected, actual *GameView) []Violation {
+	var violations []Violation
+
+	if len(expected.Players) != len(actual.Players) {
+		return append(violations, Violation{
+			PlayerNum: -1,
+			Rule:      "player_count",
+			Detail:    fmt.Sprintf("players changed from %d to %d", len(expected.Players), len(actual.Players)),
+		})
+	}
+
+	for i := range expected.Players {
+		e, a := expected.Players[i], actual.Players[i]
+		if e.Stack != a.Stack || e.TotalBet != a.TotalBet {
+			violations = append(violations, Violation{
+				PlayerNum: i,
+				Rule:      "out_of_band_write",
+				Detail:    fmt.Sprintf("stack/bet changed from %d/%d to %d/%d outside an action", e.Stack, e.TotalBet, a.Stack, a.TotalBet),
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,118 @@
+package poker
+
+import (
+	"testing"
+)
+
+func newAuditTestGame(t *testing.T) *Game {
+	t.Helper()
+	g := NewGame()
+
+	for i := 0; i < 3; i++ {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, 1000); err != nil {
+			t.Fatalf("Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Error marking ready: %s", err)
+		}
+	}
+
+	if err := g.Start(); err != nil {
+		t.Fatalf("Error starting game: %s", err)
+	}
+	return g
+}
+
+func TestAuditAction(t *testing.T) {
+	t.Run("legal call passes", func(t *testing.T) {
+		g := newAuditTestGame(t)
+		pre := g.GenerateOmniView()
+		pn := pre.ActionNum
+		toCall := g.toCall() - pre.Players[pn].Bet
+
+		if err := Bet(g, pn, toCall); err != nil {
+			t.Fatalf("Error calling: %s", err)
+		}
+
+		post := g.GenerateOmniView()
+		if violations := AuditAction(pre, post, pn, toCall); len(violations) != 0 {
+			t.Errorf("Expected no violations, got %v", violations)
+		}
+	})
+
+	t.Run("legal fold passes", func(t *testing.T) {
+		g := newAuditTestGame(t)
+		pre := g.GenerateOmniView()
+		pn := pre.ActionNum
+
+		if err := Fold(g, pn, 0); err != nil {
+			t.Fatalf("Error folding: %s", err)
+		}
+
+		post := g.GenerateOmniView()
+		if violations := AuditAction(pre, post, pn, 0); len(violations) != 0 {
+			t.Errorf("Expected no violations, got %v", violations)
+		}
+	})
+
+	t.Run("direct stack write is flagged", func(t *testing.T) {
+		g := newAuditTestGame(t)
+		pre := g.GenerateOmniView()
+		pn := pre.ActionNum
+
+		post := g.GenerateOmniView()
+		other := (pn + 1) % uint(len(post.Players))
+		post.Players[other].Stack += 500
+
+		violations := AuditAction(pre, post, pn, 0)
+		if len(violations) == 0 {
+			t.Fatal("Expected violations for a stack write outside the action")
+		}
+		if !hasRule(violations, "chip_conservation") || !hasRule(violations, "player_conservation") {
+			t.Errorf("Expected conservation violations, got %v", violations)
+		}
+	})
+
+	t.Run("double processed call is flagged", func(t *testing.T) {
+		g := newAuditTestGame(t)
+		pre := g.GenerateOmniView()
+		pn := pre.ActionNum
+		toCall := g.toCall() - pre.Players[pn].Bet
+
+		post := g.GenerateOmniView()
+		post.Players[pn].Stack -= 2 * toCall
+		post.Players[pn].Bet += 2 * toCall
+		post.Players[pn].TotalBet += 2 * toCall
+
+		violations := AuditAction(pre, post, pn, toCall)
+		if !hasRule(violations, "bet_increase") || !hasRule(violations, "stack_decrease") {
+			t.Errorf("Expected the second call to be flagged, got %v", violations)
+		}
+	})
+}
+
+func TestDiffStacks(t *testing.T) {
+	g := newAuditTestGame(t)
+	expected := g.GenerateOmniView()
+	actual := g.GenerateOmniView()
+
+	if violations := DiffStacks(expected, actual); len(violations) != 0 {
+		t.Errorf("Expected identical views to match, got %v", violations)
+	}
+
+	actual.Players[0].Stack++
+	violations := DiffStacks(expected, actual)
+	if len(violations) != 1 || violations[0].PlayerNum != 0 {
+		t.Errorf("Expected a single out of band write for player 0, got %v", violations)
+	}
+}
+
+func hasRule(violations []Violation, rule string) bool {
+	for _, v := range violations {
+		if v.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
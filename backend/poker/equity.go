@@ -0,0 +1,169 @@
+package poker
+
+import (
+	"math/rand"
+
+	. "github.com/alexclewontin/riverboat/eval"
+)
+
+// Equity estimates the share of the pot that hole wins at showdown against
+// the given number of opponents holding random hands, by dealing out the
+// unseen cards iterations times. board holds the community cards dealt so
+// far; zero cards are treated as not yet dealt. Split pots count as a
+// fractional win.
+func Equity(hole [2]Card, board []Card, opponents int, iterations int, rng *rand.Rand) float64 {
+	if opponents < 1 || iterations < 1 {
+		return 1
+	}
+
+	known := make([]Card, 0, 5)
+	for _, c := range board {
+		if c != 0 {
+			known = append(known, c)
+		}
+	}
+
+	dead := map[Card]bool{hole[0]: true, hole[1]: true}
+	for _, c := range known {
+		dead[c] = true
+	}
+	unseen := make([]Card, 0, len(DefaultDeck))
+	for _, c := range DefaultDeck {
+		if !dead[c] {
+			unseen = append(unseen, c)
+		}
+	}
+
+	missing := 5 - len(known)
+	needed := missing + 2*opponents
+	if needed > len(unseen) {
+		return 0
+	}
+
+	var won float64
+	runout := make([]Card, 5)
+	copy(runout, known)
+
+	for i := 0; i < iterations; i++ {
+		// Partial Fisher-Yates: the first needed cards become the deal
+		for j := 0; j < needed; j++ {
+			k := j + rng.Intn(len(unseen)-j)
+			unseen[j], unseen[k] = unseen[k], unseen[j]
+		}
+		copy(runout[len(known):], unseen[:missing])
+
+		_, heroScore := BestFiveOfSeven(hole[0], hole[1], runout[0], runout[1], runout[2], runout[3], runout[4])
+
+		// Lower scores are better hands
+		ties := 1
+		beaten := false
+		for o := 0; o < opponents; o++ {
+			a, b := unseen[missing+2*o], unseen[missing+2*o+1]
+			_, score := BestFiveOfSeven(a, b, runout[0], runout[1], runout[2], runout[3], runout[4])
+			if score < heroScore {
+				beaten = true
+				break
+			}
+			if score == heroScore {
+				ties++
+			}
+		}
+		if !beaten {
+			won += 1 / float64(ties)
+		}
+	}
+
+	return won / float64(iterations)
+}
+
+// ShowdownEquities returns each hand's share of the pot at showdown once the
+// board is run out, for hands whose cards are all known, such as those
+// turned over at the end of a hand. board holds the community cards dealt so
+// far. When there are no more runouts than samples every one is dealt,
+// otherwise samples of them are drawn at random. High hands only: split pot
+// games are scored as if the whole pot went high.
+func ShowdownEquities(v Variant, holes [][]Card, board []Card, samples int, rng *rand.Rand) []float64 {
+	equities := make([]float64, len(holes))
+	if len(holes) == 0 || len(board) > 5 {
+		return equities
+	}
+
+	dead := make(map[Card]bool)
+	for _, hole := range holes {
+		for _, c := range hole {
+			dead[c] = true
+		}
+	}
+	for _, c := range board {
+		dead[c] = true
+	}
+	unseen := make([]Card, 0, len(DefaultDeck))
+	for _, c := range v.fullDeck() {
+		if !dead[c] {
+			unseen = append(unseen, c)
+		}
+	}
+	missing := 5 - len(board)
+	if missing > len(unseen) {
+		return equities
+	}
+
+	runout := make([]Card, 5)
+	copy(runout, board)
+	scores := make([]int, len(holes))
+	runouts := 0
+	score := func(rest []Card) {
+		copy(runout[len(board):], rest)
+		best := 0
+		for i, hole := range holes {
+			_, scores[i] = v.bestHand(hole, runout)
+			if i == 0 || scores[i] < best {
+				best = scores[i]
+			}
+		}
+		// Lower scores are better hands; ties split the pot
+		winners := 0
+		for _, s := range scores {
+			if s == best {
+				winners++
+			}
+		}
+		for i, s := range scores {
+			if s == best {
+				equities[i] += 1 / float64(winners)
+			}
+		}
+		runouts++
+	}
+
+	if samples < 1 || combinations(len(unseen), missing) <= samples {
+		eachCombination(unseen, missing, score)
+	} else {
+		for i := 0; i < samples; i++ {
+			// Partial Fisher-Yates: the first missing cards become the runout
+			for j := 0; j < missing; j++ {
+				k := j + rng.Intn(len(unseen)-j)
+				unseen[j], unseen[k] = unseen[k], unseen[j]
+			}
+			score(unseen[:missing])
+		}
+	}
+
+	for i := range equities {
+		equities[i] /= float64(runouts)
+	}
+	return equities
+}
+
+// combinations returns n choose k, capped so it can't overflow
+func combinations(n, k int) int {
+	const limit = 1 << 30
+	result := 1
+	for i := 0; i < k; i++ {
+		result = result * (n - i) / (i + 1)
+		if result > limit {
+			return limit
+		}
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package poker
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+func TestEquity(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	aces := [2]eval.Card{eval.MustParseCardString("As"), eval.MustParseCardString("Ah")}
+
+	t.Run("Aces preflop heads up", func(t *testing.T) {
+		equity := Equity(aces, make([]eval.Card, 5), 1, 5000, rng)
+		if equity < 0.80 || equity > 0.90 {
+			t.Errorf("Test failed - pocket aces should have about 85%% equity heads up, got %.3f", equity)
+		}
+	})
+
+	t.Run("More opponents lower equity", func(t *testing.T) {
+		headsUp := Equity(aces, nil, 1, 3000, rng)
+		multiway := Equity(aces, nil, 4, 3000, rng)
+		if multiway >= headsUp {
+			t.Errorf("Test failed - equity against 4 opponents (%.3f) should be below heads up (%.3f)", multiway, headsUp)
+		}
+	})
+
+	t.Run("Nuts on the river", func(t *testing.T) {
+		hole := [2]eval.Card{eval.MustParseCardString("As"), eval.MustParseCardString("Ks")}
+		board := []eval.Card{
+			eval.MustParseCardString("Qs"),
+			eval.MustParseCardString("Js"),
+			eval.MustParseCardString("Ts"),
+			eval.MustParseCardString("2d"),
+			eval.MustParseCardString("3c"),
+		}
+		if equity := Equity(hole, board, 3, 500, rng); equity != 1 {
+			t.Errorf("Test failed - a royal flush should always win, got %.3f", equity)
+		}
+	})
+}
+
+func TestShowdownEquities(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	parse := func(cards ...string) []eval.Card {
+		parsed := make([]eval.Card, len(cards))
+		for i, c := range cards {
+			parsed[i] = eval.MustParseCardString(c)
+		}
+		return parsed
+	}
+	aces, kings := parse("As", "Ah"), parse("Ks", "Kh")
+
+	t.Run("Aces over kings preflop", func(t *testing.T) {
+		equities := ShowdownEquities(VariantHoldem, [][]eval.Card{aces, kings}, nil, 20000, rng)
+		if equities[0] < 0.78 || equities[0] > 0.86 {
+			t.Errorf("Test failed - aces should have about 82%% equity against kings, got %.3f", equities[0])
+		}
+		if sum := equities[0] + equities[1]; sum < 0.999 || sum > 1.001 {
+			t.Errorf("Test failed - equities should add up to 1, got %.3f", sum)
+		}
+	})
+
+	t.Run("Turn is counted exactly", func(t *testing.T) {
+		// Kings need one of the two kings left in 44 river cards
+		board := parse("2c", "7d", "9h", "3s")
+		equities := ShowdownEquities(VariantHoldem, [][]eval.Card{aces, kings}, board, 1000, rng)
+		if want := 2.0 / 44; equities[1] < want-0.0001 || equities[1] > want+0.0001 {
+			t.Errorf("Test failed - kings should have %.4f equity on the turn, got %.4f", want, equities[1])
+		}
+	})
+
+	t.Run("River split pot", func(t *testing.T) {
+		board := parse("Qs", "Js", "Td", "9c", "8h")
+		equities := ShowdownEquities(VariantHoldem, [][]eval.Card{parse("2c", "3d"), parse("4c", "5d")}, board, 100, rng)
+		if equities[0] != 0.5 || equities[1] != 0.5 {
+			t.Errorf("Test failed - both players play the board, got %v", equities)
+		}
+	})
+}
@@ -14,6 +14,10 @@ var ErrOutOfBounds = errors.New("error adding player at position (out of bounds)
 var ErrInvalidPosition = errors.New("error assigning player position, position already taken")
 var ErrStartGame = errors.New("error starting the game, one or more players not ready")
 
+// ErrMisdeal is returned when a deal could not be completed and the hand was
+// voided instead, see Game.TakeMisdeal for the details
+var ErrMisdeal = errors.New("misdeal, the hand was voided and all bets returned")
+
 /*
 var ErrBuyTooBig = errors.New("this would exceed the maximum configured purchased stack size")
 var ErrNotEnoughPlayers = errors.New("need more players to start the round")
@@ -2,6 +2,7 @@ package poker
 
 import (
 	"math"
+	"math/rand"
 	"sort"
 	"sync"
 
@@ -51,12 +52,28 @@ type Pot struct {
 	WinningPlayerNums  []uint `json:"winningPlayerNums"`
 	WinningHand        []Card `json:"winningHand"`
 	WinningScore       int    `json:"winningScore"`
+	// Split games only: the best qualifying low, which wins half the pot
+	LowPlayerNums []uint `json:"lowPlayerNums,omitempty"`
+	LowHand       []Card `json:"lowHand,omitempty"`
+	LowScore      int    `json:"lowScore,omitempty"`
+	// Chips each player won from the pot at showdown, by player number
+	Awards map[uint]uint `json:"awards,omitempty"`
 }
 
 type GameConfig struct {
 	MaxBuy     uint `json:"maxBuy"`
 	BigBlind   uint `json:"bb"`
 	SmallBlind uint `json:"sb"`
+	// Ante is dead money posted before the cards are dealt. With BigBlindAnte
+	// the big blind posts it once for the whole table, otherwise every player
+	// dealt in posts it.
+	Ante         uint `json:"ante"`
+	BigBlindAnte bool `json:"bbAnte"`
+	// HiLo splits each pot between the best high hand and the best low hand
+	// of eight or better. Nothing is declared: both halves go by the cards.
+	HiLo bool `json:"hiLo"`
+	// Variant is the game dealt, Hold'em when empty
+	Variant Variant `json:"variant,omitempty"`
 }
 
 // Game represents a game of poker. It internally keeps track of state, can be mutated by actions,
@@ -76,9 +93,11 @@ type Game struct {
 	config         GameConfig
 	players        []player
 	deck           Deck
+	stackedDeck    Deck // Used instead of a shuffle for the next hand, see StackDeck
 	pots           []Pot
 	minRaise       uint
 	calledNum      uint
+	misdeal        *Misdeal // Last voided hand, until taken
 }
 
 func (g *Game) getStage() GameStage {
@@ -128,6 +147,26 @@ func (g *Game) isCalled(pn uint) bool {
 	return g.players[pn].allIn() || (g.players[pn].Called)
 }
 
+// postAntes collects the antes for the hand being dealt. A big blind ante is
+// posted after the big blind, so a big blind who can't cover both posts the
+// full blind first and only what is left towards the ante.
+func (g *Game) postAntes() {
+	if g.config.Ante == 0 {
+		return
+	}
+
+	if g.config.BigBlindAnte {
+		g.players[g.bbNum].postAnte(g.config.Ante)
+		return
+	}
+
+	for i := range g.players {
+		if g.players[i].In {
+			g.players[i].postAnte(g.config.Ante)
+		}
+	}
+}
+
 //Returns nil if there are more than 2 players ready, ErrIllegalAction otherwise
 func (g *Game) updateBlindNums() {
 	readyCount := g.readyCount()
@@ -189,15 +228,17 @@ func (g *Game) resetForNextHand() {
 	for i := range g.players {
 		g.players[i].Bet = 0
 		g.players[i].TotalBet = 0
+		g.players[i].Ante = 0
 
-		if g.players[i].Stack == 0 {
+		if g.players[i].Stack == 0 || g.players[i].SittingOut {
 			g.players[i].Ready = false
 		}
 
 	}
 
+	// Everyone may be sitting out, so stop after going round the table once
 	g.dealerNum = (g.dealerNum + 1) % uint(len(g.players))
-	for !g.players[g.dealerNum].Ready {
+	for i := 1; i < len(g.players) && !g.players[g.dealerNum].Ready; i++ {
 		g.dealerNum = (g.dealerNum + 1) % uint(len(g.players))
 	}
 
@@ -223,6 +264,20 @@ func (g *Game) updateRoundInfo() {
 		}
 	}
 
+	// Once betting is closed with at most one player who isn't all-in, chips
+	// nobody else matched go back to the player who bet them. This happens
+	// before the pots are built so they aren't paid out a second time.
+	if len(inPlayerNums) >= 2 && allCalled && len(inPlayerNums)-len(allInPlayerNums) < 2 {
+		g.returnUncalledChips(inPlayerNums)
+
+		allInPlayerNums = allInPlayerNums[:0]
+		for _, ndx := range inPlayerNums {
+			if g.players[ndx].allIn() {
+				allInPlayerNums = append(allInPlayerNums, ndx)
+			}
+		}
+	}
+
 	// Update the pot information
 
 	sort.Slice(allInPlayerNums, func(i, j int) bool {
@@ -267,6 +322,21 @@ func (g *Game) updateRoundInfo() {
 
 	g.pots = append(g.pots, finalPot)
 
+	// Chips folded players put in beyond what anyone still in has at stake
+	// have nobody eligible to win them, so they go to the pot below
+	for i := len(g.pots) - 1; i > 0; i-- {
+		if len(g.pots[i].EligiblePlayerNums) == 0 && g.pots[i].Amt > 0 {
+			g.pots[i-1].Amt += g.pots[i].Amt
+			g.pots[i].Amt = 0
+		}
+	}
+
+	// Antes are dead money that every player still in can win, so they all go
+	// to the main pot
+	for _, p := range g.players {
+		g.pots[0].Amt += p.Ante
+	}
+
 	// If less than two players are still in, the hand has been conceded
 	if len(inPlayerNums) < 2 {
 		//the sole number in the array is the winner by default
@@ -280,7 +350,7 @@ func (g *Game) updateRoundInfo() {
 
 		// But this is special because cards do not need to be shown
 		for _, p := range g.players {
-			g.players[inPlayerNums[0]].Stack += p.TotalBet
+			g.players[inPlayerNums[0]].Stack += p.TotalBet + p.Ante
 		}
 
 		g.resetForNextHand()
@@ -298,27 +368,6 @@ func (g *Game) updateRoundInfo() {
 		return
 	}
 
-	//If there are two or more players in, and everybody has either called or is all-in, and at this point we determine that only one player is
-	//in but not all in, we take all the money above and beyond the second highest better (who is all in) and return it to the people who bet it
-	//If the only players in are both all in for the exact same amount of money, nothing happens here
-	//(but we can't skip in the "0 not all in" case because technically before this step happens a player who after this step may read as not all in
-	//could return true for the isAllIn method)
-	if (len(inPlayerNums) - len(allInPlayerNums)) < 2 {
-		var topBettor1 uint = 0
-		var topBettor2 uint = 0
-		// TODO: what if everyone is all in?
-		for _, ndx := range inPlayerNums {
-			if g.players[ndx].TotalBet > g.players[topBettor1].TotalBet {
-				topBettor2 = topBettor1
-				topBettor1 = ndx
-			} else if g.players[ndx].TotalBet > g.players[topBettor2].TotalBet {
-				topBettor2 = ndx
-			}
-		}
-
-		g.players[topBettor1].returnChips(g.players[topBettor1].TotalBet - g.players[topBettor2].TotalBet)
-	}
-
 	//If there are two or more players in, and everybody has called or is all in, then end the hand f we've just finished river betting
 	if g.getStage() == River {
 
@@ -327,15 +376,7 @@ func (g *Game) updateRoundInfo() {
 
 			for _, num := range g.pots[i].EligiblePlayerNums {
 
-				hand, score := BestFiveOfSeven(
-					g.players[num].Cards[0],
-					g.players[num].Cards[1],
-					g.communityCards[0],
-					g.communityCards[1],
-					g.communityCards[2],
-					g.communityCards[3],
-					g.communityCards[4],
-				)
+				hand, score := g.bestHand(g.players[num].Cards)
 				// lower is better for the score
 				if score < g.pots[i].WinningScore {
 					g.pots[i].WinningScore = score
@@ -346,8 +387,16 @@ func (g *Game) updateRoundInfo() {
 				}
 			}
 
+			if g.config.HiLo {
+				g.settleHiLo(&g.pots[i])
+				continue
+			}
+
+			g.pots[i].Awards = make(map[uint]uint, len(g.pots[i].WinningPlayerNums))
 			for _, num := range g.pots[i].WinningPlayerNums {
-				g.players[num].Stack += (g.pots[i].Amt / uint(len(g.pots[i].WinningPlayerNums)))
+				share := g.pots[i].Amt / uint(len(g.pots[i].WinningPlayerNums))
+				g.pots[i].Awards[num] = share
+				g.players[num].Stack += share
 				//TODO: leave the remainder in the middle! (fractional money will disappear currently)
 			}
 		}
@@ -361,6 +410,25 @@ func (g *Game) updateRoundInfo() {
 	}
 }
 
+// returnUncalledChips gives the top bettor among the players still in back
+// whatever the second highest bettor could not match
+func (g *Game) returnUncalledChips(inPlayerNums []uint) {
+	top, second := inPlayerNums[0], inPlayerNums[1]
+	if g.players[second].TotalBet > g.players[top].TotalBet {
+		top, second = second, top
+	}
+	for _, ndx := range inPlayerNums[2:] {
+		if g.players[ndx].TotalBet > g.players[top].TotalBet {
+			second = top
+			top = ndx
+		} else if g.players[ndx].TotalBet > g.players[second].TotalBet {
+			second = ndx
+		}
+	}
+
+	g.players[top].returnChips(g.players[top].TotalBet - g.players[second].TotalBet)
+}
+
 //Exported functions related to game management (not "Actions")
 
 // NewGame is a factory method that returns a pointer to an initialized game.
@@ -388,10 +456,10 @@ func NewGame() *Game {
 	return &newGame
 }
 
-// Start checks that all players are ready, then sets running to true and deals the first hand
+// Start checks that all players still seated and not sitting out are ready, then sets running to true and deals the first hand
 func (g *Game) Start() error {
 	for _, p := range g.players {
-		if !p.Ready {
+		if !p.Ready && !p.Left && !p.SittingOut {
 			return ErrStartGame
 		}
 	}
@@ -403,13 +471,73 @@ func (g *Game) Start() error {
 	return nil
 }
 
+// SetConfig replaces the blinds, ante and maximum buy-in, e.g. when a tournament
+// moves to its next level. It is only allowed between hands so that amounts
+// already posted are never changed.
+func (g *Game) SetConfig(config GameConfig) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+	if config.BigBlind == 0 || config.SmallBlind > config.BigBlind || !config.Variant.Valid() {
+		return ErrIllegalAction
+	}
+
+	g.config = config
+	return nil
+}
+
+// StackDeck arranges the deck for the next hand instead of shuffling it, for
+// scripted deals. Cards come off the deck in the order given: the hole cards
+// to each ready player in player order, then the flop, turn and river. The
+// rest of the deck is shuffled behind them.
+func (g *Game) StackDeck(order []Card) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+
+	fullDeck := g.config.Variant.fullDeck()
+	known := make(map[Card]bool, len(fullDeck))
+	for _, c := range fullDeck {
+		known[c] = true
+	}
+	for _, c := range order {
+		if !known[c] {
+			return ErrIllegalAction
+		}
+		known[c] = false
+	}
+
+	var deck Deck
+	for _, c := range fullDeck {
+		if known[c] {
+			deck = append(deck, c)
+		}
+	}
+	rand.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+
+	// Cards are popped from the end
+	for i := len(order) - 1; i >= 0; i-- {
+		deck = append(deck, order[i])
+	}
+
+	g.stackedDeck = deck
+	return nil
+}
+
 // Reset resets the game to a blank game
 func (g *Game) Reset() {
 	g.running = false
 	g.players = []player{}
 	g.pots = []Pot{}
 	g.communityCards = make([]Card, 5)
-	g.deck = DefaultDeck
+	g.deck = append(Deck{}, g.config.Variant.fullDeck()...)
+	g.stackedDeck = nil
 	g.setStageAndBetting(PreDeal, false)
 }
 
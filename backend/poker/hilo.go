@@ -0,0 +1,167 @@
+package poker
+
+import (
+	"sort"
+
+	. "github.com/alexclewontin/riverboat/eval"
+)
+
+// lowQualifier is the highest card a low hand may hold: eight or better
+const lowQualifier = 8
+
+// lowRank is a card's rank for low hands, where the ace counts as one
+func lowRank(c Card) int {
+	rank := int(c>>8) & 0x0F // deuce=0, ..., ace=12
+	if rank == 12 {
+		return 1
+	}
+	return rank + 2
+}
+
+// lowHandValue scores five cards as a low hand. A low needs five different
+// ranks, all eight or under; straights and flushes don't count against it.
+// Lower scores are better: the highest card is compared first, then the next.
+func lowHandValue(cards []Card) (int, bool) {
+	ranks := make([]int, 0, 5)
+	seen := 0
+	for _, c := range cards {
+		r := lowRank(c)
+		if r > lowQualifier || seen&(1<<r) != 0 {
+			return 0, false
+		}
+		seen |= 1 << r
+		ranks = append(ranks, r)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ranks)))
+
+	score := 0
+	for _, r := range ranks {
+		score = score<<4 | r
+	}
+	return score, true
+}
+
+// bestLow finds the best qualifying low among the five-card hands made from
+// a player's hole cards and the board. fromHole is how many cards must come
+// from the hole cards, as in Omaha, or 0 to allow any five.
+func bestLow(hole, board []Card, fromHole int) ([]Card, int, bool) {
+	var best []Card
+	bestScore, found := 0, false
+
+	consider := func(hand []Card) {
+		if score, ok := lowHandValue(hand); ok && (!found || score < bestScore) {
+			best = append([]Card{}, hand...)
+			bestScore, found = score, true
+		}
+	}
+
+	if fromHole == 0 {
+		eachCombination(append(append([]Card{}, hole...), board...), 5, consider)
+	} else {
+		eachCombination(hole, fromHole, func(fromHand []Card) {
+			eachCombination(board, 5-fromHole, func(fromBoard []Card) {
+				consider(append(append([]Card{}, fromHand...), fromBoard...))
+			})
+		})
+	}
+	return best, bestScore, found
+}
+
+// eachCombination calls fn with every k-card combination of cards. The slice
+// passed to fn is reused between calls.
+func eachCombination(cards []Card, k int, fn func([]Card)) {
+	combination := make([]Card, k)
+	var pick func(start, depth int)
+	pick = func(start, depth int) {
+		if depth == k {
+			fn(combination)
+			return
+		}
+		for i := start; i <= len(cards)-(k-depth); i++ {
+			combination[depth] = cards[i]
+			pick(i+1, depth+1)
+		}
+	}
+	pick(0, 0)
+}
+
+// SetHiLo turns splitting pots between high and low hands on or off from
+// the next hand on. It is only allowed between hands.
+func (g *Game) SetHiLo(hiLo bool) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+	g.config.HiLo = hiLo
+	return nil
+}
+
+// HiLo reports whether pots are split between high and low hands
+func (g *Game) HiLo() bool {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return g.config.HiLo
+}
+
+// settleHiLo pays a showdown pot in a split game. The best high hand, already
+// in WinningPlayerNums, takes half and the best qualifying low takes the
+// other half; players tied on a half share it, so a high winner who also ties
+// for low is quartered. Without a qualifying low the high hand scoops.
+func (g *Game) settleHiLo(pot *Pot) {
+	if len(pot.WinningPlayerNums) == 0 {
+		return
+	}
+
+	for _, num := range pot.EligiblePlayerNums {
+		fromHole := 0
+		if g.config.Variant == VariantOmaha {
+			fromHole = 2
+		}
+		hand, score, ok := bestLow(g.players[num].Cards, g.communityCards, fromHole)
+		if !ok {
+			continue
+		}
+		if len(pot.LowPlayerNums) == 0 || score < pot.LowScore {
+			pot.LowScore = score
+			pot.LowPlayerNums = []uint{num}
+			pot.LowHand = hand
+		} else if score == pot.LowScore {
+			pot.LowPlayerNums = append(pot.LowPlayerNums, num)
+		}
+	}
+
+	pot.Awards = make(map[uint]uint)
+	if len(pot.LowPlayerNums) == 0 {
+		g.splitChips(pot.Amt, pot.WinningPlayerNums, pot.Awards)
+	} else {
+		// The odd chip of an uneven pot goes to the high half
+		lowHalf := pot.Amt / 2
+		g.splitChips(pot.Amt-lowHalf, pot.WinningPlayerNums, pot.Awards)
+		g.splitChips(lowHalf, pot.LowPlayerNums, pot.Awards)
+	}
+
+	for num, amt := range pot.Awards {
+		g.players[num].Stack += amt
+	}
+}
+
+// splitChips divides amt evenly between players, adding each share to
+// awards. Chips that don't divide go one each to the players closest to the
+// dealer's left.
+func (g *Game) splitChips(amt uint, playerNums []uint, awards map[uint]uint) {
+	seats := uint(len(g.players))
+	ordered := append([]uint{}, playerNums...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return (ordered[i]+seats-g.dealerNum-1)%seats < (ordered[j]+seats-g.dealerNum-1)%seats
+	})
+
+	share, odd := amt/uint(len(ordered)), amt%uint(len(ordered))
+	for i, num := range ordered {
+		awards[num] += share
+		if uint(i) < odd {
+			awards[num]++
+		}
+	}
+}
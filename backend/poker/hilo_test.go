@@ -0,0 +1,194 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+func cards(t *testing.T, s ...string) []eval.Card {
+	t.Helper()
+	ret := make([]eval.Card, len(s))
+	for i, c := range s {
+		ret[i] = eval.MustParseCardString(c)
+	}
+	return ret
+}
+
+// playHiLoHand deals a stacked hi-lo hand to three players with 1000 chips
+// each and checks it down. holes holds two cards per player, then the board.
+func playHiLoHand(t *testing.T, order []eval.Card) (*Game, uint) {
+	t.Helper()
+
+	g := NewGame()
+	if err := g.SetConfig(GameConfig{SmallBlind: 10, BigBlind: 20, HiLo: true}); err != nil {
+		t.Fatalf("Test failed - Error setting config: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, 1000); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := g.StackDeck(order); err != nil {
+		t.Fatalf("Test failed - Error stacking deck: %s", err)
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+	before := ChipTotal(g.GenerateOmniView())
+
+	for g.getStage() != PreDeal || g.getBetting() {
+		if err := Bet(g, g.actionNum, g.toCall()-g.players[g.actionNum].Bet); err != nil {
+			t.Fatalf("Test failed - Error checking down: %s", err)
+		}
+	}
+	return g, before
+}
+
+func TestLowHandValue(t *testing.T) {
+	wheel, ok := lowHandValue(cards(t, "5s", "4h", "3d", "2c", "Ac"))
+	if !ok {
+		t.Fatal("Test failed - the wheel must qualify for low")
+	}
+	eightLow, ok := lowHandValue(cards(t, "8s", "6h", "4d", "3c", "2c"))
+	if !ok {
+		t.Fatal("Test failed - an eight low must qualify")
+	}
+	sevenLow, _ := lowHandValue(cards(t, "7s", "6h", "5d", "4c", "3c"))
+	if !(wheel < sevenLow && sevenLow < eightLow) {
+		t.Errorf("Test failed - expected wheel < 76543 < 86432, got %x %x %x", wheel, sevenLow, eightLow)
+	}
+
+	if _, ok := lowHandValue(cards(t, "9s", "5h", "4d", "3c", "2c")); ok {
+		t.Error("Test failed - a nine low must not qualify")
+	}
+	if _, ok := lowHandValue(cards(t, "7s", "7h", "4d", "3c", "2c")); ok {
+		t.Error("Test failed - a paired hand must not qualify for low")
+	}
+}
+
+func TestBestLow(t *testing.T) {
+	t.Run("Any five cards in hold'em", func(t *testing.T) {
+		_, score, ok := bestLow(cards(t, "Ac", "4d"), cards(t, "Ks", "9d", "7c", "3h", "2s"), 0)
+		want, _ := lowHandValue(cards(t, "7c", "4d", "3h", "2s", "Ac"))
+		if !ok || score != want {
+			t.Errorf("Test failed - expected 7-4-3-2-A, got %x (qualified %t)", score, ok)
+		}
+	})
+
+	t.Run("Exactly two hole cards in Omaha", func(t *testing.T) {
+		_, score, ok := bestLow(cards(t, "Ac", "2d", "Ks", "Kh"), cards(t, "3s", "4d", "5c", "Qh", "Js"), 2)
+		want, _ := lowHandValue(cards(t, "5c", "4d", "3s", "2d", "Ac"))
+		if !ok || score != want {
+			t.Errorf("Test failed - expected the wheel, got %x (qualified %t)", score, ok)
+		}
+
+		// Four low hole cards are no use with only one low card on the board
+		if _, _, ok := bestLow(cards(t, "Ac", "2d", "3s", "4h"), cards(t, "5c", "Ks", "Qd", "Jh", "Ts"), 2); ok {
+			t.Error("Test failed - Omaha must use three board cards for low")
+		}
+	})
+}
+
+func TestHiLoSettlement(t *testing.T) {
+	t.Run("High and low split the pot", func(t *testing.T) {
+		order := cards(t,
+			"Kd", "Qc", // player 0: pair of kings, no low
+			"Ac", "4d", // player 1: seven low
+			"Qd", "Jc", // player 2: nothing
+			"Ks", "9d", "7c", "3h", "2s",
+		)
+		g, before := playHiLoHand(t, order)
+		pot := g.pots[0]
+
+		if len(pot.WinningPlayerNums) != 1 || pot.WinningPlayerNums[0] != 0 {
+			t.Errorf("Test failed - player 0 should win high, got %v", pot.WinningPlayerNums)
+		}
+		if len(pot.LowPlayerNums) != 1 || pot.LowPlayerNums[0] != 1 {
+			t.Errorf("Test failed - player 1 should win low, got %v", pot.LowPlayerNums)
+		}
+		if pot.Awards[0] != 30 || pot.Awards[1] != 30 {
+			t.Errorf("Test failed - each half should be 30, got %v", pot.Awards)
+		}
+		if g.players[0].Stack != 1010 || g.players[1].Stack != 1010 || g.players[2].Stack != 980 {
+			t.Errorf("Test failed - unexpected stacks %d %d %d", g.players[0].Stack, g.players[1].Stack, g.players[2].Stack)
+		}
+		if after := ChipTotal(g.GenerateOmniView()); after != before {
+			t.Errorf("Test failed - chips not conserved, %d before and %d after", before, after)
+		}
+	})
+
+	t.Run("High scoops without a qualifying low", func(t *testing.T) {
+		order := cards(t,
+			"Kd", "Qc", // player 0: two pair
+			"Ac", "4d",
+			"6d", "5c",
+			"Ks", "9d", "Tc", "Qh", "2s",
+		)
+		g, _ := playHiLoHand(t, order)
+		pot := g.pots[0]
+
+		if len(pot.LowPlayerNums) != 0 {
+			t.Errorf("Test failed - nobody should have a low, got %v", pot.LowPlayerNums)
+		}
+		if pot.Awards[0] != 60 || g.players[0].Stack != 1040 {
+			t.Errorf("Test failed - player 0 should scoop 60, got %v and stack %d", pot.Awards, g.players[0].Stack)
+		}
+	})
+
+	t.Run("High winner tied for low is quartered", func(t *testing.T) {
+		order := cards(t,
+			"Ac", "Kd", // player 0: pair of kings and a seven low
+			"Ad", "9c", // player 1: the same seven low
+			"Qc", "Qd", // player 2: pair of queens
+			"Ks", "7d", "5c", "3h", "2s",
+		)
+		g, before := playHiLoHand(t, order)
+		pot := g.pots[0]
+
+		if pot.Awards[0] != 45 || pot.Awards[1] != 15 {
+			t.Errorf("Test failed - expected 45 for high and a quarter, 15 for a quarter, got %v", pot.Awards)
+		}
+		if after := ChipTotal(g.GenerateOmniView()); after != before {
+			t.Errorf("Test failed - chips not conserved, %d before and %d after", before, after)
+		}
+
+		view := g.GeneratePlayerView(2)
+		if view.Players[1].Cards[0] == 0 {
+			t.Error("Test failed - a low winner's cards must be shown at showdown")
+		}
+	})
+}
+
+func TestSplitChips(t *testing.T) {
+	g := NewGame()
+	for i := 0; i < 3; i++ {
+		g.AddPlayer()
+	}
+	g.dealerNum = 0
+
+	awards := make(map[uint]uint)
+	g.splitChips(7, []uint{0, 2}, awards)
+	if awards[2] != 4 || awards[0] != 3 {
+		t.Errorf("Test failed - the odd chip should go to player 2, left of the dealer first, got %v", awards)
+	}
+}
+
+func TestSetHiLoBetweenHandsOnly(t *testing.T) {
+	g := dealVariant(t, VariantHoldem)
+	if err := g.SetHiLo(true); err != ErrIllegalAction {
+		t.Fatalf("Test failed - hi-lo changed during a hand: %v", err)
+	}
+
+	g.EndHandAndReset()
+	if err := g.SetHiLo(true); err != nil {
+		t.Fatalf("Test failed - hi-lo refused between hands: %s", err)
+	}
+	if !g.HiLo() {
+		t.Fatal("Test failed - hi-lo not turned on")
+	}
+}
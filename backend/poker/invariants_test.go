@@ -0,0 +1,331 @@
+package poker
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// The tests in this file play random tables against the engine and check
+// betting-round invariants after every action. Each table is driven by a
+// single seed, so a failure can be replayed with
+//
+//	go test ./poker -run TestBettingInvariants -poker.seed=<seed> -v
+//
+// FuzzBettingInvariants explores the same space with the native fuzzer, which
+// saves failing inputs under testdata/fuzz for replay by a plain go test.
+
+var (
+	invariantSeed = flag.Int64("poker.seed", 0, "replay TestBettingInvariants for a single seed")
+	invariantRuns = flag.Int("poker.runs", 300, "number of random tables TestBettingInvariants plays")
+)
+
+const (
+	invariantHands       = 25   // Hands played per table
+	maxActionsPerHand    = 1000 // A hand taking more actions than this never terminates
+	illegalAttemptsPerGo = 2    // Rejected actions tried before each legal one, at most
+	maxFuzzDecisions     = 256  // Fuzz input bytes used to steer a table
+)
+
+// decider makes every random choice for a table. Fuzz input bytes are used
+// first so the fuzzer can steer the game, then the seeded generator takes over.
+type decider struct {
+	data []byte
+	rng  *rand.Rand
+}
+
+func newDecider(seed int64, data []byte) *decider {
+	return &decider{data: data, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (d *decider) intn(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	if len(d.data) > 0 {
+		b := d.data[0]
+		d.data = d.data[1:]
+		return int(b) % n
+	}
+	return d.rng.Intn(n)
+}
+
+func (d *decider) uintn(n uint) uint {
+	return uint(d.intn(int(n)))
+}
+
+// handContributions is every chip committed to the hand so far
+func handContributions(gv *GameView) uint {
+	var total uint
+	for _, p := range gv.Players {
+		total += p.TotalBet + p.Ante
+	}
+	return total
+}
+
+func potTotal(gv *GameView) uint {
+	var total uint
+	for _, pot := range gv.Pots {
+		total += pot.Amt
+	}
+	return total
+}
+
+// splitRemainder is the odd chips lost when pots are split between tied
+// winners, which the engine does not award yet
+func splitRemainder(gv *GameView) uint {
+	var lost uint
+	for _, pot := range gv.Pots {
+		if n := uint(len(pot.WinningPlayerNums)); n > 1 {
+			lost += pot.Amt % n
+		}
+	}
+	return lost
+}
+
+// checkBettingState validates a mid-hand snapshot against the chip count the
+// table started the hand with
+func checkBettingState(gv *GameView, tableChips uint) error {
+	if total := ChipTotal(gv); total != tableChips {
+		return fmt.Errorf("chips not conserved: table holds %d, expected %d", total, tableChips)
+	}
+	for i, p := range gv.Players {
+		// Stacks are unsigned, so a negative stack shows up as a wrapped value
+		if p.Stack > tableChips {
+			return fmt.Errorf("player %d has a negative stack (%d)", i, p.Stack)
+		}
+	}
+	if len(gv.Pots) > 0 {
+		if pot, committed := potTotal(gv), handContributions(gv); pot != committed {
+			return fmt.Errorf("pot is %d but players committed %d", pot, committed)
+		}
+	}
+	if gv.Betting {
+		actor := gv.Players[gv.ActionNum]
+		if !actor.In {
+			return fmt.Errorf("action is on player %d who is not in the hand", gv.ActionNum)
+		}
+	}
+	return nil
+}
+
+// checkSettlement validates the state once a hand has been paid out
+func checkSettlement(pre, post *GameView) error {
+	preTotal, postTotal := ChipTotal(pre), ChipTotal(post)
+	if lost := splitRemainder(post); postTotal+lost != preTotal {
+		return fmt.Errorf("chips not conserved at settlement: %d before, %d after, %d lost to split pots", preTotal, postTotal, lost)
+	}
+	for i, p := range post.Players {
+		if p.Stack > preTotal {
+			return fmt.Errorf("player %d has a negative stack (%d) after settlement", i, p.Stack)
+		}
+		if p.Bet != 0 || p.TotalBet != 0 || p.Ante != 0 {
+			return fmt.Errorf("player %d still has chips committed after settlement", i)
+		}
+	}
+	return nil
+}
+
+// checkRejected verifies a rejected action left the game untouched
+func checkRejected(before, after *GameView, err error, what string) error {
+	if err == nil {
+		return fmt.Errorf("%s was accepted", what)
+	}
+	if len(DiffStacks(before, after)) > 0 || before.ActionNum != after.ActionNum || before.Stage != after.Stage {
+		return fmt.Errorf("%s was rejected but changed the game", what)
+	}
+	return nil
+}
+
+// newInvariantTable seats between two and six players with random stacks
+// under random blinds and antes
+func newInvariantTable(d *decider) (*Game, error) {
+	g := NewGame()
+
+	sb := 1 + d.uintn(10)
+	config := GameConfig{SmallBlind: sb, BigBlind: sb * (1 + d.uintn(2))}
+	if d.intn(3) == 0 {
+		config.Ante = 1 + d.uintn(sb)
+		config.BigBlindAnte = d.intn(2) == 0
+	}
+	if err := g.SetConfig(config); err != nil {
+		return nil, fmt.Errorf("set config: %w", err)
+	}
+
+	players := 2 + d.intn(5)
+	for i := 0; i < players; i++ {
+		pn := g.AddPlayer()
+		// Mostly deep stacks, with some too short to cover the blinds
+		stack := 1 + d.uintn(config.BigBlind*80)
+		if err := BuyIn(g, pn, stack); err != nil {
+			return nil, fmt.Errorf("buy in: %w", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			return nil, fmt.Errorf("ready: %w", err)
+		}
+	}
+	return g, nil
+}
+
+// nextBet picks the chips the player to act puts in: a fold (nil), a check
+// or call, a raise or an all-in
+func nextBet(d *decider, g *Game, gv *GameView) (uint, bool) {
+	p := gv.Players[gv.ActionNum]
+	toCall := g.toCall() - p.Bet
+	if p.Stack == 0 {
+		// The engine still gives all-in players the action, and a call is
+		// the only thing they can do with it
+		return toCall, true
+	}
+
+	minRaise := toCall + gv.MinRaise
+
+	switch choice := d.intn(10); {
+	case choice == 0:
+		return 0, false
+	case choice < 6 || p.Stack <= toCall:
+		// A short stack calls for the full amount and the engine takes what
+		// is there
+		return toCall, true
+	case choice < 9 && minRaise < p.Stack:
+		return minRaise + d.uintn(p.Stack-minRaise+1), true
+	case p.Stack < minRaise:
+		// The engine has no incomplete raises, so a stack between a call and
+		// a minimum raise can only call
+		return toCall, true
+	default:
+		return p.Stack, true
+	}
+}
+
+// tryIllegal attempts an action the engine must refuse: acting out of turn,
+// or betting between a call and a minimum raise
+func tryIllegal(d *decider, g *Game, gv *GameView) error {
+	actor := gv.ActionNum
+	p := gv.Players[actor]
+	toCall := g.toCall() - p.Bet
+
+	if d.intn(2) == 0 {
+		other := d.uintn(uint(len(gv.Players)))
+		if other == actor {
+			return nil
+		}
+		err := Bet(g, other, toCall)
+		return checkRejected(gv, g.GenerateOmniView(), err, fmt.Sprintf("bet by player %d out of turn", other))
+	}
+
+	// Anything strictly between a call and a minimum raise, short of all-in
+	if gv.MinRaise < 2 || toCall+gv.MinRaise-1 >= p.Stack {
+		return nil
+	}
+	amount := toCall + 1 + d.uintn(gv.MinRaise-1)
+	err := Bet(g, actor, amount)
+	return checkRejected(gv, g.GenerateOmniView(), err, fmt.Sprintf("under-raise of %d (call %d, min raise %d)", amount, toCall, gv.MinRaise))
+}
+
+// playHand deals one hand and plays it out with random actions
+func playHand(d *decider, g *Game) error {
+	order := append(eval.Deck{}, eval.DefaultDeck...)
+	d.rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	if err := g.StackDeck(order); err != nil {
+		return fmt.Errorf("stack deck: %w", err)
+	}
+
+	start := g.GenerateOmniView()
+	tableChips := ChipTotal(start)
+	if err := Deal(g, start.DealerNum, 0); err != nil {
+		return fmt.Errorf("deal with %d ready players: %w", start.ReadyCount, err)
+	}
+
+	for actions := 0; ; actions++ {
+		gv := g.GenerateOmniView()
+		if gv.Stage == PreDeal && !gv.Betting {
+			return checkSettlement(start, gv)
+		}
+		if actions >= maxActionsPerHand {
+			return fmt.Errorf("betting did not terminate after %d actions", actions)
+		}
+		if err := checkBettingState(gv, tableChips); err != nil {
+			return fmt.Errorf("action %d, stage %d: %w", actions, gv.Stage, err)
+		}
+
+		for i := d.intn(illegalAttemptsPerGo + 1); i > 0; i-- {
+			if err := tryIllegal(d, g, gv); err != nil {
+				return fmt.Errorf("action %d, stage %d: %w", actions, gv.Stage, err)
+			}
+		}
+
+		pn := gv.ActionNum
+		chips, stays := nextBet(d, g, gv)
+		var err error
+		if stays {
+			err = Bet(g, pn, chips)
+		} else {
+			err = Fold(g, pn, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("action %d, stage %d: legal action by player %d (bet %d, fold %t) rejected: %w", actions, gv.Stage, pn, chips, !stays, err)
+		}
+	}
+}
+
+// playInvariantTable plays hands until one player has all the chips
+func playInvariantTable(seed int64, data []byte) error {
+	d := newDecider(seed, data)
+	g, err := newInvariantTable(d)
+	if err != nil {
+		return err
+	}
+
+	for hand := 0; hand < invariantHands; hand++ {
+		if g.readyCount() < 2 {
+			return nil
+		}
+		if err := playHand(d, g); err != nil {
+			return fmt.Errorf("hand %d: %w", hand, err)
+		}
+	}
+	return nil
+}
+
+func TestBettingInvariants(t *testing.T) {
+	if *invariantSeed != 0 {
+		if err := playInvariantTable(*invariantSeed, nil); err != nil {
+			t.Fatalf("seed %d: %s", *invariantSeed, err)
+		}
+		return
+	}
+
+	runs := *invariantRuns
+	if testing.Short() {
+		runs = 50
+	}
+
+	base := rand.Int63()
+	for i := 0; i < runs; i++ {
+		seed := base + int64(i)
+		if err := playInvariantTable(seed, nil); err != nil {
+			t.Fatalf("%s\nreplay with: go test ./poker -run TestBettingInvariants -poker.seed=%d -v", err, seed)
+		}
+	}
+}
+
+func FuzzBettingInvariants(f *testing.F) {
+	f.Add(int64(1), []byte{})
+	f.Add(int64(2), []byte{0, 0, 0, 9, 9, 9, 9})          // All-ins from the start
+	f.Add(int64(3), []byte{4, 1, 4, 3, 3, 3, 3, 3, 3, 3}) // Short stacked calling stations
+
+	f.Fuzz(func(t *testing.T, seed int64, data []byte) {
+		// Longer inputs add little over the seeded generator and make
+		// minimizing a failure very slow
+		if len(data) > maxFuzzDecisions {
+			t.Skip()
+		}
+		if err := playInvariantTable(seed, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
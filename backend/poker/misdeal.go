@@ -0,0 +1,218 @@
+package poker
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+
+	. "github.com/alexclewontin/riverboat/eval"
+)
+
+// MisdealReason says why a hand was voided
+type MisdealReason string
+
+const (
+	// The deck ran out of cards before the deal was complete
+	MisdealDeckExhausted MisdealReason = "deck_exhausted"
+	// A card was missing from the deck or in play twice
+	MisdealCorruptDeck MisdealReason = "corrupt_deck"
+	// A hole card was exposed before anyone acted on the hand
+	MisdealExposedCard MisdealReason = "exposed_card"
+	// The hand was voided from outside the engine, e.g. by an admin
+	MisdealManual MisdealReason = "manual"
+)
+
+// Misdeal describes a voided hand. Every chip bet or anted on the hand is
+// returned to the player who put it in and the same dealer deals again.
+type Misdeal struct {
+	Reason  MisdealReason `json:"reason"`
+	Stage   GameStage     `json:"stage"`
+	Detail  string        `json:"detail"`
+	Refunds []uint        `json:"refunds"` // Chips returned, by player number
+}
+
+// cardsNeeded is how many cards the deal from stage takes off the deck
+func (g *Game) cardsNeeded(stage GameStage) int {
+	switch stage {
+	case PreDeal:
+		return g.config.Variant.holeCards() * int(g.readyCount())
+	case PreFlop:
+		return 3
+	case Flop, Turn:
+		return 1
+	}
+	return 0
+}
+
+// checkDeck makes sure the deal from stage can be completed with cards that
+// are each in play exactly once. Hole cards and the board only count once a
+// hand is under way, as they are left over from the last hand before that.
+func (g *Game) checkDeck(stage GameStage) (MisdealReason, string, bool) {
+	if need := g.cardsNeeded(stage); len(g.deck) < need {
+		return MisdealDeckExhausted, fmt.Sprintf("%d cards needed but %d left in the deck", need, len(g.deck)), false
+	}
+
+	fullDeck := g.config.Variant.fullDeck()
+	seen := make(map[Card]bool, len(fullDeck))
+	for _, c := range fullDeck {
+		seen[c] = false
+	}
+	check := func(c Card, where string) (string, bool) {
+		used, known := seen[c]
+		if !known {
+			return fmt.Sprintf("unknown card %d in %s", c, where), false
+		}
+		if used {
+			return fmt.Sprintf("%s in play twice, found again in %s", c, where), false
+		}
+		seen[c] = true
+		return "", true
+	}
+
+	for _, c := range g.deck {
+		if detail, ok := check(c, "the deck"); !ok {
+			return MisdealCorruptDeck, detail, false
+		}
+	}
+	if stage == PreDeal {
+		return "", "", true
+	}
+	for i, p := range g.players {
+		if !p.In {
+			continue
+		}
+		for _, c := range p.Cards {
+			if detail, ok := check(c, fmt.Sprintf("player %d's hand", i)); !ok {
+				return MisdealCorruptDeck, detail, false
+			}
+		}
+	}
+	for _, c := range g.communityCards {
+		if c == 0 {
+			continue
+		}
+		if detail, ok := check(c, "the board"); !ok {
+			return MisdealCorruptDeck, detail, false
+		}
+	}
+	return "", "", true
+}
+
+// voidHand ends the hand in progress without a winner: bets and antes go back
+// to their owners, the cards are collected and the deck is reshuffled. The
+// button does not move, so the same dealer deals again.
+func (g *Game) voidHand(reason MisdealReason, detail string) {
+	misdeal := &Misdeal{
+		Reason:  reason,
+		Stage:   g.getStage(),
+		Detail:  detail,
+		Refunds: make([]uint, len(g.players)),
+	}
+
+	for i := range g.players {
+		p := &g.players[i]
+		misdeal.Refunds[i] = p.TotalBet + p.Ante
+		p.Stack += p.TotalBet + p.Ante
+		p.Bet = 0
+		p.TotalBet = 0
+		p.Ante = 0
+		p.clearCards()
+		p.In = false
+		p.Called = false
+	}
+
+	for i := range g.communityCards {
+		g.communityCards[i] = 0
+	}
+	g.pots = []Pot{}
+	g.stackedDeck = nil
+	g.shuffleDeck()
+
+	g.misdeal = misdeal
+	g.running = false
+	g.setStageAndBetting(PreDeal, false)
+}
+
+// VoidHand declares a misdeal on the hand in progress, returning every bet.
+// It returns ErrIllegalAction between hands.
+func (g *Game) VoidHand(reason MisdealReason, detail string) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() == PreDeal && !g.getBetting() {
+		return ErrIllegalAction
+	}
+	g.voidHand(reason, detail)
+	return nil
+}
+
+// ExposeCard applies the exposed card rules to a card that was shown when
+// it should not have been, and reports whether the hand was voided:
+//
+//   - a hole card exposed before anyone has acted on the hand is a misdeal
+//   - a hole card exposed later stays live and the hand continues
+//   - a card exposed from the deck is taken out of play and the rest of the
+//     deck reshuffled, so it can't come on the board
+//
+// Cards already on the board are public and are ignored.
+func (g *Game) ExposeCard(card Card) (bool, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() == PreDeal && !g.getBetting() {
+		return false, ErrIllegalAction
+	}
+
+	for i, p := range g.players {
+		if !p.In || !slices.Contains(p.Cards, card) {
+			continue
+		}
+		if g.getStage() == PreFlop && !g.actionTaken() {
+			g.voidHand(MisdealExposedCard, fmt.Sprintf("%s exposed from player %d's hand before any action", card, i))
+			return true, nil
+		}
+		return false, nil
+	}
+
+	for i, c := range g.deck {
+		if c != card {
+			continue
+		}
+		g.deck = append(g.deck[:i], g.deck[i+1:]...)
+		shuffleCards(g.deck)
+		return false, nil
+	}
+
+	for _, c := range g.communityCards {
+		if c == card {
+			return false, nil
+		}
+	}
+	return false, ErrIllegalAction
+}
+
+func shuffleCards(cards []Card) {
+	rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+}
+
+// actionTaken reports whether anyone has acted since the cards were dealt.
+// Posting blinds and antes doesn't count.
+func (g *Game) actionTaken() bool {
+	for _, p := range g.players {
+		if p.Called || (!p.In && p.dealtIn()) {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeMisdeal returns the last misdeal, if any, and clears it so that each
+// misdeal is reported once
+func (g *Game) TakeMisdeal() *Misdeal {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	misdeal := g.misdeal
+	g.misdeal = nil
+	return misdeal
+}
@@ -0,0 +1,155 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+func setupMisdealGame(t *testing.T, stacks ...uint) *Game {
+	g := NewGame()
+	for _, stack := range stacks {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, stack); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+	return g
+}
+
+func checkVoided(t *testing.T, g *Game, reason MisdealReason, stacks ...uint) {
+	t.Helper()
+
+	if g.getStage() != PreDeal || g.getBetting() || g.running {
+		t.Error("Test failed - a voided hand must leave the game between hands")
+	}
+	for i, p := range g.players {
+		if p.Stack != stacks[i] || p.TotalBet != 0 || p.Ante != 0 || p.In {
+			t.Errorf("Test failed - player %d should have all %d chips back and be out of the hand, got %+v", i, stacks[i], p)
+		}
+	}
+	if len(g.deck) != len(eval.DefaultDeck) {
+		t.Errorf("Test failed - deck should be reshuffled with all cards, has %d", len(g.deck))
+	}
+
+	misdeal := g.TakeMisdeal()
+	if misdeal == nil || misdeal.Reason != reason {
+		t.Fatalf("Test failed - expected a %s misdeal, got %+v", reason, misdeal)
+	}
+	if g.TakeMisdeal() != nil {
+		t.Error("Test failed - a misdeal must only be reported once")
+	}
+}
+
+func TestMisdeal(t *testing.T) {
+	t.Run("Deck exhausted on a street voids the hand", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000, 1000)
+		dealer := g.dealerNum
+
+		g.deck = g.deck[:2]
+		for g.getStage() == PreFlop {
+			if err := Bet(g, g.actionNum, g.toCall()-g.players[g.actionNum].Bet); err != nil {
+				t.Fatalf("Test failed - Error calling: %s", err)
+			}
+		}
+
+		checkVoided(t, g, MisdealDeckExhausted, 1000, 1000, 1000)
+		if g.dealerNum != dealer {
+			t.Error("Test failed - the button must not move after a misdeal")
+		}
+	})
+
+	t.Run("Card in play twice voids the hand", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000)
+
+		g.deck[len(g.deck)-1] = g.players[0].Cards[0]
+		if err := Deal(g, g.dealerNum, 0); err != ErrIllegalAction {
+			t.Fatalf("Test failed - dealing while betting must return ErrIllegalAction, got %v", err)
+		}
+		g.setBetting(false)
+		if err := Deal(g, g.dealerNum, 0); err != ErrMisdeal {
+			t.Fatalf("Test failed - dealing a duplicate card must return ErrMisdeal, got %v", err)
+		}
+
+		checkVoided(t, g, MisdealCorruptDeck, 1000, 1000)
+	})
+
+	t.Run("Hole card exposed before any action", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000, 1000)
+
+		voided, err := g.ExposeCard(g.players[1].Cards[1])
+		if err != nil || !voided {
+			t.Fatalf("Test failed - exposing a hole card before action must void the hand, got %v, %v", voided, err)
+		}
+		checkVoided(t, g, MisdealExposedCard, 1000, 1000, 1000)
+	})
+
+	t.Run("Hole card exposed after action stays live", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000, 1000)
+
+		if err := Fold(g, g.actionNum, 0); err != nil {
+			t.Fatalf("Test failed - Error folding: %s", err)
+		}
+		var card eval.Card
+		for _, p := range g.players {
+			if p.In {
+				card = p.Cards[0]
+			}
+		}
+
+		voided, err := g.ExposeCard(card)
+		if err != nil || voided {
+			t.Fatalf("Test failed - exposing a hole card after action must not void the hand, got %v, %v", voided, err)
+		}
+		if g.getStage() != PreFlop {
+			t.Error("Test failed - the hand must continue")
+		}
+	})
+
+	t.Run("Card exposed from the deck is taken out of play", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000)
+
+		next := g.deck[len(g.deck)-1]
+		if voided, err := g.ExposeCard(next); err != nil || voided {
+			t.Fatalf("Test failed - exposing a card from the deck must not void the hand, got %v, %v", voided, err)
+		}
+		for _, c := range g.deck {
+			if c == next {
+				t.Fatal("Test failed - exposed card must be removed from the deck")
+			}
+		}
+	})
+
+	t.Run("Void hand returns bets and antes", func(t *testing.T) {
+		g := NewGame()
+		if err := g.SetConfig(GameConfig{SmallBlind: 10, BigBlind: 20, Ante: 5}); err != nil {
+			t.Fatalf("Test failed - Error setting config: %s", err)
+		}
+		for i := 0; i < 3; i++ {
+			pn := g.AddPlayer()
+			BuyIn(g, pn, 500)
+			ToggleReady(g, pn, 0)
+		}
+		if err := Deal(g, g.dealerNum, 0); err != nil {
+			t.Fatalf("Test failed - Error dealing: %s", err)
+		}
+		if err := Bet(g, g.actionNum, 60); err != nil {
+			t.Fatalf("Test failed - Error raising: %s", err)
+		}
+
+		if err := g.VoidHand(MisdealManual, "test"); err != nil {
+			t.Fatalf("Test failed - Error voiding hand: %s", err)
+		}
+		checkVoided(t, g, MisdealManual, 500, 500, 500)
+
+		if err := g.VoidHand(MisdealManual, "test"); err != ErrIllegalAction {
+			t.Error("Test failed - voiding between hands must return ErrIllegalAction")
+		}
+	})
+}
@@ -14,11 +14,23 @@ type player struct {
 	In         bool    `json:"in"`
 	Called     bool    `json:"called"`
 	Left       bool    `json:"left"`
+	SittingOut bool    `json:"sittingOut"` // Keeps the seat but is not dealt in
 	TotalBuyIn uint    `json:"totalBuyIn"`
 	Stack      uint    `json:"stack"`
 	Bet        uint    `json:"bet"`
 	TotalBet   uint    `json:"totalBet"`
-	Cards      [2]Card `json:"cards"`
+	Ante       uint    `json:"ante"` // Dead money posted this hand, not part of Bet or TotalBet
+	Cards      []Card  `json:"cards"`
+}
+
+// clearCards takes back the player's hole cards
+func (p *player) clearCards() {
+	p.Cards = make([]Card, len(p.Cards))
+}
+
+// dealtIn reports whether the player was dealt cards this hand
+func (p *player) dealtIn() bool {
+	return len(p.Cards) > 0 && p.Cards[0] != 0
 }
 
 func (p *player) allIn() bool {
@@ -29,6 +41,7 @@ func (p *player) initialize() {
 	*p = player{}
 
 	p.UUID = uuid.New().String()
+	p.Cards = make([]Card, 2)
 	p.Ready = false
 	p.In = false
 	p.Called = false
@@ -48,6 +61,15 @@ func (p *player) putInChips(amt uint) {
 	}
 }
 
+// postAnte moves up to amt from the stack into the player's ante
+func (p *player) postAnte(amt uint) {
+	if amt > p.Stack {
+		amt = p.Stack
+	}
+	p.Ante += amt
+	p.Stack -= amt
+}
+
 func (p *player) returnChips(amt uint) {
 	if p.TotalBet > amt {
 		p.TotalBet -= amt
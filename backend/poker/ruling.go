@@ -0,0 +1,49 @@
+package poker
+
+// AwardHand ends the hand in progress by a ruling instead of by the cards:
+// every chip bet or anted on the hand goes to playerNums as a single pot,
+// split evenly with any odd chips to the players closest to the dealer's
+// left. Anyone dealt into the hand can be awarded it, folded or not. The
+// hand ends as a played hand would, so the pot is left in the view for the
+// caller to settle. Returns ErrIllegalAction between hands, or when a player
+// named was not dealt in.
+func (g *Game) AwardHand(playerNums []uint) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.getStage() == PreDeal && !g.getBetting() {
+		return ErrIllegalAction
+	}
+	if len(playerNums) == 0 {
+		return ErrIllegalAction
+	}
+	named := make(map[uint]bool, len(playerNums))
+	for _, num := range playerNums {
+		if num >= uint(len(g.players)) || !g.players[num].dealtIn() || named[num] {
+			return ErrIllegalAction
+		}
+		named[num] = true
+	}
+
+	pot := Pot{
+		EligiblePlayerNums: []uint{},
+		WinningPlayerNums:  append([]uint{}, playerNums...),
+		Awards:             make(map[uint]uint, len(playerNums)),
+	}
+	for i, p := range g.players {
+		pot.Amt += p.TotalBet + p.Ante
+		if p.In {
+			pot.EligiblePlayerNums = append(pot.EligiblePlayerNums, uint(i))
+		}
+	}
+	pot.TopShare = pot.Amt
+
+	g.splitChips(pot.Amt, pot.WinningPlayerNums, pot.Awards)
+	for num, amt := range pot.Awards {
+		g.players[num].Stack += amt
+	}
+	g.pots = []Pot{pot}
+
+	g.resetForNextHand()
+	return nil
+}
@@ -0,0 +1,73 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestAwardHand(t *testing.T) {
+	t.Run("Whole pot goes to the player named", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000, 1000)
+		before := ChipTotal(g.GenerateOmniView())
+
+		// Everyone calls the big blind, then the player to act folds
+		for g.getStage() == PreFlop {
+			if err := Bet(g, g.actionNum, g.toCall()-g.players[g.actionNum].Bet); err != nil {
+				t.Fatalf("Test failed - Error calling: %s", err)
+			}
+		}
+		folded := g.actionNum
+		if err := Fold(g, folded, 0); err != nil {
+			t.Fatalf("Test failed - Error folding: %s", err)
+		}
+
+		if err := g.AwardHand([]uint{folded}); err != nil {
+			t.Fatalf("Test failed - Error awarding hand: %s", err)
+		}
+
+		if g.getStage() != PreDeal || g.getBetting() || g.running {
+			t.Error("Test failed - an awarded hand must leave the game between hands")
+		}
+		if len(g.pots) != 1 || g.pots[0].Amt != 60 || g.pots[0].Awards[folded] != 60 {
+			t.Fatalf("Test failed - expected one pot of 60 awarded to player %d, got %+v", folded, g.pots)
+		}
+		if g.players[folded].Stack != 1040 {
+			t.Errorf("Test failed - player %d should have 1040 chips, got %d", folded, g.players[folded].Stack)
+		}
+		if after := ChipTotal(g.GenerateOmniView()); after != before {
+			t.Errorf("Test failed - chips not conserved, %d before and %d after", before, after)
+		}
+	})
+
+	t.Run("Split award gives the odd chip left of the dealer", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000, 1000)
+		g.players[g.dealerNum].TotalBet++
+		g.players[g.dealerNum].Stack--
+		left := (g.dealerNum + 1) % 3
+
+		if err := g.AwardHand([]uint{g.dealerNum, left}); err != nil {
+			t.Fatalf("Test failed - Error awarding hand: %s", err)
+		}
+		if g.pots[0].Awards[left] != 16 || g.pots[0].Awards[(left+2)%3] != 15 {
+			t.Errorf("Test failed - expected 16 to the player left of the dealer and 15 to the dealer, got %v", g.pots[0].Awards)
+		}
+	})
+
+	t.Run("Illegal awards leave the hand untouched", func(t *testing.T) {
+		g := setupMisdealGame(t, 1000, 1000)
+		pn := g.AddPlayer()
+
+		for _, nums := range [][]uint{nil, {0, 0}, {pn}, {7}} {
+			if err := g.AwardHand(nums); err != ErrIllegalAction {
+				t.Errorf("Test failed - awarding %v must return ErrIllegalAction, got %v", nums, err)
+			}
+		}
+		if g.getStage() != PreFlop {
+			t.Error("Test failed - the hand must still be in progress")
+		}
+
+		g.VoidHand(MisdealManual, "")
+		if err := g.AwardHand([]uint{0}); err != ErrIllegalAction {
+			t.Errorf("Test failed - awarding between hands must return ErrIllegalAction, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package poker
+
+import (
+	"math/rand"
+
+	. "github.com/alexclewontin/riverboat/eval"
+)
+
+// Variant is the game dealt for a hand. It can change between hands, as at a
+// dealer's choice table.
+type Variant string
+
+const (
+	// Texas Hold'em, no limit: two hole cards, any five of seven
+	VariantHoldem Variant = "texas_holdem"
+	// Pot limit Omaha: four hole cards, exactly two of them with three from
+	// the board
+	VariantOmaha Variant = "omaha"
+	// Short deck Hold'em: sixes and up, a flush beats a full house and
+	// A-6-7-8-9 is the lowest straight
+	VariantShortDeck Variant = "short_deck"
+)
+
+// Variants lists every variant the game can deal
+var Variants = []Variant{VariantHoldem, VariantOmaha, VariantShortDeck}
+
+// Valid reports whether v is a variant the game can deal. The zero value is
+// Hold'em.
+func (v Variant) Valid() bool {
+	switch v {
+	case "", VariantHoldem, VariantOmaha, VariantShortDeck:
+		return true
+	}
+	return false
+}
+
+// holeCards is how many cards each player is dealt
+func (v Variant) holeCards() int {
+	if v == VariantOmaha {
+		return 4
+	}
+	return 2
+}
+
+// potLimit reports whether a bet may be no bigger than the pot
+func (v Variant) potLimit() bool {
+	return v == VariantOmaha
+}
+
+// shortDeck is the 36 cards from six to ace
+var shortDeck = func() Deck {
+	var deck Deck
+	for _, c := range DefaultDeck {
+		if int(c>>8)&0x0F >= 4 { // deuce=0, ..., six=4
+			deck = append(deck, c)
+		}
+	}
+	return deck
+}()
+
+// fullDeck returns the cards the variant is played with
+func (v Variant) fullDeck() Deck {
+	if v == VariantShortDeck {
+		return shortDeck
+	}
+	return DefaultDeck
+}
+
+// SetVariant changes the game dealt from the next hand on. It is only
+// allowed between hands.
+func (g *Game) SetVariant(v Variant) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if !v.Valid() || g.getStage() != PreDeal || g.getBetting() {
+		return ErrIllegalAction
+	}
+
+	g.config.Variant = v
+	// A stacked deck was arranged with the old game's cards
+	g.stackedDeck = nil
+	return nil
+}
+
+// Variant returns the game being dealt, or to be dealt next between hands
+func (g *Game) Variant() Variant {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.config.Variant == "" {
+		return VariantHoldem
+	}
+	return g.config.Variant
+}
+
+// shuffleDeck gathers every card of the variant and shuffles them
+func (g *Game) shuffleDeck() {
+	g.deck = append(Deck{}, g.config.Variant.fullDeck()...)
+	rand.Shuffle(len(g.deck), func(i, j int) { g.deck[i], g.deck[j] = g.deck[j], g.deck[i] })
+}
+
+// bestHand finds a player's best five card high hand with the full board and
+// its score, lower being better
+func (g *Game) bestHand(hole []Card) ([]Card, int) {
+	return g.config.Variant.bestHand(hole, g.communityCards)
+}
+
+// bestHand finds the best five card high hand the variant makes from hole
+// cards and a full board, and its score, lower being better
+func (v Variant) bestHand(hole, board []Card) ([]Card, int) {
+	switch v {
+	case VariantOmaha:
+		var best []Card
+		bestScore := 0
+		eachCombination(hole, 2, func(fromHand []Card) {
+			eachCombination(board, 3, func(fromBoard []Card) {
+				hand := append(append([]Card{}, fromHand...), fromBoard...)
+				if score := HandValue(hand[0], hand[1], hand[2], hand[3], hand[4]); best == nil || score < bestScore {
+					best, bestScore = hand, score
+				}
+			})
+		})
+		return best, bestScore
+
+	case VariantShortDeck:
+		var best []Card
+		bestScore := 0
+		eachCombination(append(append([]Card{}, hole...), board...), 5, func(hand []Card) {
+			if score := shortDeckValue(hand); best == nil || score < bestScore {
+				best, bestScore = append([]Card{}, hand...), score
+			}
+		})
+		return best, bestScore
+	}
+
+	return BestFiveOfSeven(hole[0], hole[1], board[0], board[1], board[2], board[3], board[4])
+}
+
+// Scores from HandValue, lower being better
+const (
+	scoreFullHouse = 167  // Best full house
+	scoreFlush     = 323  // Best flush
+	scoreStraight  = 1600 // Best straight
+	fullHouseCount = scoreFlush - scoreFullHouse
+	flushCount     = scoreStraight - scoreFlush
+
+	scoreNineHighStraightFlush = 6
+	scoreNineHighStraight      = 1605
+)
+
+// shortDeckWheel is the rank bits of A-6-7-8-9
+const shortDeckWheel = 1<<12 | 0xF<<4
+
+// shortDeckValue scores five cards under short deck rules on the same scale
+// as HandValue. A-6-7-8-9 takes the place of the 9-high straight, which can't
+// be made without a five, and flushes swap places with full houses.
+func shortDeckValue(cards []Card) int {
+	c0, c1, c2, c3, c4 := cards[0], cards[1], cards[2], cards[3], cards[4]
+	flush := c0&c1&c2&c3&c4&0xF000 != 0
+
+	if (c0|c1|c2|c3|c4)>>16 == shortDeckWheel {
+		if flush {
+			return scoreNineHighStraightFlush
+		}
+		return scoreNineHighStraight
+	}
+
+	score := HandValue(c0, c1, c2, c3, c4)
+	switch {
+	case score >= scoreFullHouse && score < scoreFlush:
+		return score + flushCount
+	case score >= scoreFlush && score < scoreStraight:
+		return score - fullHouseCount
+	}
+	return score
+}
+
+// potLimitMax is the most a player may put in with one bet in a pot limit
+// game: enough to call, plus the pot as it will stand after calling
+func (g *Game) potLimitMax(pn uint) uint {
+	toCall := g.toCall() - g.players[pn].Bet
+
+	var pot uint
+	for _, p := range g.players {
+		pot += p.TotalBet + p.Ante
+	}
+	return 2*toCall + pot
+}
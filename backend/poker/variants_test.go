@@ -0,0 +1,152 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// dealVariant deals a hand of the given variant to three players with 1000
+// chips each and blinds of 10/20
+func dealVariant(t *testing.T, v Variant) *Game {
+	t.Helper()
+
+	g := NewGame()
+	if err := g.SetConfig(GameConfig{SmallBlind: 10, BigBlind: 20}); err != nil {
+		t.Fatalf("Test failed - Error setting config: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, 1000); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := g.SetVariant(v); err != nil {
+		t.Fatalf("Test failed - Error setting variant: %s", err)
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+	return g
+}
+
+func handValue(c []eval.Card) int {
+	return eval.HandValue(c[0], c[1], c[2], c[3], c[4])
+}
+
+func TestOmahaDealsFourHoleCards(t *testing.T) {
+	g := dealVariant(t, VariantOmaha)
+
+	seen := make(map[eval.Card]bool)
+	for pn, p := range g.players {
+		if len(p.Cards) != 4 {
+			t.Fatalf("Test failed - player %d was dealt %d cards, want 4", pn, len(p.Cards))
+		}
+		for _, c := range p.Cards {
+			if c == 0 || seen[c] {
+				t.Fatalf("Test failed - player %d was dealt a missing or repeated card %v", pn, c)
+			}
+			seen[c] = true
+		}
+	}
+}
+
+func TestOmahaUsesExactlyTwoHoleCards(t *testing.T) {
+	g := NewGame()
+	g.config.Variant = VariantOmaha
+	copy(g.communityCards, cards(t, "As", "Ks", "Qs", "Js", "2d"))
+
+	// One spade in hand makes a flush in Hold'em but not in Omaha, and the
+	// wheel would need a five from the board
+	_, score := g.bestHand(cards(t, "3s", "4h", "5h", "6h"))
+	if score < handValue(cards(t, "As", "Ks", "Qs", "Jd", "9h")) {
+		t.Fatalf("Test failed - Omaha hand scored %d, better than high card", score)
+	}
+
+	best, _ := g.bestHand(cards(t, "Ts", "9s", "2c", "3c"))
+	// The royal flush would take four cards from the board
+	if want := handValue(cards(t, "Ks", "Qs", "Js", "Ts", "9s")); handValue(best) != want {
+		t.Fatalf("Test failed - Omaha best hand %v, want the king-high straight flush", best)
+	}
+}
+
+func TestShortDeckDeck(t *testing.T) {
+	if len(shortDeck) != 36 {
+		t.Fatalf("Test failed - short deck has %d cards, want 36", len(shortDeck))
+	}
+	for _, c := range shortDeck {
+		if rank := c.String()[0]; rank >= '2' && rank <= '5' {
+			t.Fatalf("Test failed - short deck holds %s", c)
+		}
+	}
+
+	g := dealVariant(t, VariantShortDeck)
+	for pn, p := range g.players {
+		for _, c := range p.Cards {
+			if rank := c.String()[0]; rank >= '2' && rank <= '5' {
+				t.Fatalf("Test failed - player %d was dealt %s", pn, c)
+			}
+		}
+	}
+}
+
+func TestShortDeckHandValues(t *testing.T) {
+	flush := shortDeckValue(cards(t, "Kh", "Th", "8h", "7h", "6h"))
+	fullHouse := shortDeckValue(cards(t, "Ac", "Ad", "Ah", "Ks", "Kd"))
+	if flush >= fullHouse {
+		t.Errorf("Test failed - flush scored %d, not better than full house %d", flush, fullHouse)
+	}
+	quads := shortDeckValue(cards(t, "6c", "6d", "6h", "6s", "7d"))
+	if quads >= flush {
+		t.Errorf("Test failed - quads scored %d, not better than flush %d", quads, flush)
+	}
+
+	lowStraight := shortDeckValue(cards(t, "Ac", "6d", "7h", "8s", "9d"))
+	sixHigh := shortDeckValue(cards(t, "6c", "7d", "8h", "9s", "Td"))
+	trips := shortDeckValue(cards(t, "Ac", "Ad", "Ah", "Ks", "Qd"))
+	if lowStraight <= sixHigh || lowStraight >= trips {
+		t.Errorf("Test failed - A-6-7-8-9 scored %d, want between T-high straight %d and trips %d", lowStraight, sixHigh, trips)
+	}
+
+	lowStraightFlush := shortDeckValue(cards(t, "Ac", "6c", "7c", "8c", "9c"))
+	if lowStraightFlush >= quads || lowStraightFlush <= shortDeckValue(cards(t, "6c", "7c", "8c", "9c", "Tc")) {
+		t.Errorf("Test failed - A-6-7-8-9 suited scored %d, want the lowest straight flush", lowStraightFlush)
+	}
+}
+
+func TestPotLimitBet(t *testing.T) {
+	g := dealVariant(t, VariantOmaha)
+	pn := g.actionNum
+
+	// Call 20 and raise the pot of 10+20+20: 70 in all
+	if err := Bet(g, pn, 71); err != ErrIllegalAction {
+		t.Fatalf("Test failed - a bet over the pot was allowed: %v", err)
+	}
+	if err := Bet(g, pn, 1000); err != ErrIllegalAction {
+		t.Fatalf("Test failed - an all-in over the pot was allowed: %v", err)
+	}
+	if err := Bet(g, pn, 70); err != nil {
+		t.Fatalf("Test failed - a pot sized bet was refused: %s", err)
+	}
+}
+
+func TestSetVariantBetweenHandsOnly(t *testing.T) {
+	g := dealVariant(t, VariantHoldem)
+	if err := g.SetVariant(VariantOmaha); err != ErrIllegalAction {
+		t.Fatalf("Test failed - variant changed during a hand: %v", err)
+	}
+
+	g.EndHandAndReset()
+	if err := g.SetVariant(VariantOmaha); err != nil {
+		t.Fatalf("Test failed - variant refused between hands: %s", err)
+	}
+	if g.Variant() != VariantOmaha {
+		t.Fatalf("Test failed - variant is %s, want %s", g.Variant(), VariantOmaha)
+	}
+	if err := g.SetVariant("stud"); err != ErrIllegalAction {
+		t.Fatalf("Test failed - unknown variant accepted: %v", err)
+	}
+}
@@ -51,7 +51,7 @@ func (g *Game) copyToView() *GameView {
 		Stage:          g.getStage(),
 		Betting:        g.getBetting(),
 		Config:         g.config,
-		Players:        append([]player{}, g.players...),
+		Players:        copyPlayers(g.players),
 		Deck:           append([]eval.Card{}, g.deck...),
 		Pots:           copyPots(g.pots),
 		MinRaise:       g.minRaise,
@@ -61,6 +61,14 @@ func (g *Game) copyToView() *GameView {
 	return view
 }
 
+func copyPlayers(src []player) []player {
+	ret := append([]player{}, src...)
+	for i := range ret {
+		ret[i].Cards = append([]eval.Card{}, src[i].Cards...)
+	}
+	return ret
+}
+
 func copyPots(src []Pot) []Pot {
 	ret := make([]Pot, len(src))
 	for i := range src {
@@ -70,6 +78,17 @@ func copyPots(src []Pot) []Pot {
 		ret[i].EligiblePlayerNums = append([]uint{}, src[i].EligiblePlayerNums...)
 		ret[i].WinningPlayerNums = append([]uint{}, src[i].WinningPlayerNums...)
 		ret[i].WinningHand = append([]eval.Card{}, src[i].WinningHand...)
+		ret[i].LowScore = src[i].LowScore
+		if src[i].LowPlayerNums != nil {
+			ret[i].LowPlayerNums = append([]uint{}, src[i].LowPlayerNums...)
+			ret[i].LowHand = append([]eval.Card{}, src[i].LowHand...)
+		}
+		if src[i].Awards != nil {
+			ret[i].Awards = make(map[uint]uint, len(src[i].Awards))
+			for num, amt := range src[i].Awards {
+				ret[i].Awards[num] = amt
+			}
+		}
 	}
 
 	return ret
@@ -89,7 +108,7 @@ func (g *Game) FillFromView(gv *GameView) {
 	g.communityCards = append([]eval.Card{}, gv.CommunityCards...)
 	g.setStageAndBetting(gv.Stage, gv.Betting)
 	g.config = gv.Config
-	g.players = append([]player{}, gv.Players...)
+	g.players = copyPlayers(gv.Players)
 	g.deck = append([]eval.Card{}, gv.Deck...)
 	g.pots = copyPots(gv.Pots)
 	g.minRaise = gv.MinRaise
@@ -105,8 +124,8 @@ func (g *Game) GeneratePlayerView(pn uint) *GameView {
 	gv.Deck = nil
 
 	// D. R. Y.!
-	hideCards := func(pn2 uint) { gv.Players[pn2].Cards = [2]eval.Card{0, 0} }
-	showCards := func(pn2 uint) { gv.Players[pn2].Cards = [2]eval.Card{g.players[pn2].Cards[0], g.players[pn2].Cards[1]} }
+	hideCards := func(pn2 uint) { gv.Players[pn2].Cards = make([]eval.Card, len(g.players[pn2].Cards)) }
+	showCards := func(pn2 uint) { gv.Players[pn2].Cards = append([]eval.Card{}, g.players[pn2].Cards...) }
 
 	allInCount := 0
 	inCount := 0
@@ -142,27 +161,11 @@ func (g *Game) GeneratePlayerView(pn uint) *GameView {
 	if g.getStage() == PreDeal && !g.getBetting() && inCount > 1 {
 
 		showCards(g.calledNum)
-		_, scoreToBeat := eval.BestFiveOfSeven(
-			g.players[g.calledNum].Cards[0],
-			g.players[g.calledNum].Cards[1],
-			g.communityCards[0],
-			g.communityCards[1],
-			g.communityCards[2],
-			g.communityCards[3],
-			g.communityCards[4],
-		)
+		_, scoreToBeat := g.bestHand(g.players[g.calledNum].Cards)
 
 		for i := range g.players {
 			pni := (g.calledNum + uint(i)) % uint(len(g.players))
-			_, iScore := eval.BestFiveOfSeven(
-				g.players[pni].Cards[0],
-				g.players[pni].Cards[1],
-				g.communityCards[0],
-				g.communityCards[1],
-				g.communityCards[2],
-				g.communityCards[3],
-				g.communityCards[4],
-			)
+			_, iScore := g.bestHand(g.players[pni].Cards)
 
 			if (iScore <= scoreToBeat) && g.players[pni].In {
 				showCards(pni)
@@ -175,12 +178,25 @@ func (g *Game) GeneratePlayerView(pn uint) *GameView {
 			for _, j := range pot.WinningPlayerNums {
 				showCards(j)
 			}
+			for _, j := range pot.LowPlayerNums {
+				showCards(j)
+			}
 		}
 	}
 
 	return gv
 }
 
+// spectatorNum is a player number no seat ever has
+const spectatorNum = ^uint(0)
+
+// GenerateSpectatorView is GeneratePlayerView for someone without a seat: it
+// holds no hole cards besides those shown to the whole table, such as at
+// showdown or when everyone left in the hand is all in
+func (g *Game) GenerateSpectatorView() *GameView {
+	return g.GeneratePlayerView(spectatorNum)
+}
+
 // GenerateOmniView is primarily for creating a view that can be serialized for delivery to a persistance layer, like a db or in-memory store
 // Nothing is censored, not even the contents of the deck
 func (g *Game) GenerateOmniView() *GameView {
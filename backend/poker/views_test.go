@@ -144,7 +144,7 @@ func TestGame_GenerateOmniViewChangedVals(t *testing.T) {
 						Stack:      105,
 						Bet:        10,
 						TotalBet:   20,
-						Cards: [2]Card{
+						Cards: []Card{
 							33564957,
 							67115551,
 						},
@@ -195,7 +195,7 @@ func TestGame_GenerateOmniViewChangedVals(t *testing.T) {
 						Stack:      105,
 						Bet:        10,
 						TotalBet:   20,
-						Cards: [2]Card{
+						Cards: []Card{
 							33564957,
 							67115551,
 						},
@@ -275,3 +275,42 @@ func (g *Game) String() string {
 		g.minRaise,
 	)
 }
+
+func TestGame_GenerateSpectatorView(t *testing.T) {
+	g := NewGame()
+	if err := g.SetConfig(GameConfig{SmallBlind: 10, BigBlind: 20}); err != nil {
+		t.Fatalf("Test failed - Error setting config: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		pn := g.AddPlayer()
+		if err := BuyIn(g, pn, 1000); err != nil {
+			t.Fatalf("Test failed - Error buying in: %s", err)
+		}
+		if err := ToggleReady(g, pn, 0); err != nil {
+			t.Fatalf("Test failed - Error marking ready: %s", err)
+		}
+	}
+	if err := Deal(g, g.dealerNum, 0); err != nil {
+		t.Fatalf("Test failed - Error dealing: %s", err)
+	}
+
+	spectator := g.GenerateSpectatorView()
+	for i, p := range spectator.Players {
+		for _, c := range p.Cards {
+			if c != 0 {
+				t.Errorf("Test failed - a spectator must not see player %d's hole cards", i)
+			}
+		}
+	}
+	if spectator.Deck != nil {
+		t.Error("Test failed - a spectator must not see the deck")
+	}
+
+	player := g.GeneratePlayerView(1)
+	if player.Players[1].Cards[0] == 0 {
+		t.Error("Test failed - a player must see their own hole cards")
+	}
+	if player.Players[0].Cards[0] != 0 || player.Players[2].Cards[0] != 0 {
+		t.Error("Test failed - a player must not see anyone else's hole cards")
+	}
+}
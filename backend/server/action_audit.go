@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+// errActionAuditFailed is returned when an action produced stacks the rules
+// can't explain. The game is rolled back to its state before the action.
+var errActionAuditFailed = errors.New("action rejected by stack audit")
+
+// actionAuditor remembers the state after the last audited action so that
+// changes made between actions can be detected as well
+type actionAuditor struct {
+	mu       sync.Mutex
+	handID   string
+	lastPost *poker.GameView
+}
+
+// auditedAction applies a betting action to the legacy game and checks the
+// resulting stacks against the prior state plus the action. On divergence the
+// game is restored to the prior state, a forensic dump is logged and the actor
+// receives a structured error.
+func auditedAction(c *Client, name string, pn uint, amount uint, action poker.Action) error {
+	t := c.table
+	game := t.game.GetLegacyGame()
+	handID := t.game.CurrentHandID()
+
+	t.audit.mu.Lock()
+	defer t.audit.mu.Unlock()
+
+	pre := game.GenerateOmniView()
+
+	// Stacks must not move between actions of the same hand. We can't tell
+	// which side is right, so this is flagged rather than rolled back.
+	if t.audit.lastPost != nil && t.audit.handID == handID && pre.Running {
+		if drift := poker.DiffStacks(t.audit.lastPost, pre); len(drift) > 0 {
+			logForensicDump(t, "stack changed outside an action", name, pn, amount, drift, t.audit.lastPost, pre)
+		}
+	}
+
+	actionErr := action(game, pn, amount)
+	post := game.GenerateOmniView()
+
+	var violations []poker.Violation
+	if actionErr != nil {
+		// Rejected actions must leave the game untouched
+		violations = poker.DiffStacks(pre, post)
+	} else {
+		violations = poker.AuditAction(pre, post, pn, amount)
+	}
+
+	if len(violations) > 0 {
+		logForensicDump(t, "action diverged from engine rules", name, pn, amount, violations, pre, post)
+		game.FillFromView(pre)
+		t.recorder.Action(handID, c.userID.String(), name, pn, amount, pre, pre, actionErr)
+		t.audit.handID = handID
+		t.audit.lastPost = pre
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Action rejected: game state check failed"))
+		return errActionAuditFailed
+	}
+
+	t.recorder.Action(handID, c.userID.String(), name, pn, amount, pre, post, actionErr)
+	t.audit.handID = handID
+	t.audit.lastPost = post
+	if actionErr == nil {
+		t.activity.recordAction(post, time.Now())
+		t.recordTrainingDecision(c, name, pn, amount, pre)
+		t.broadcastBoardCues(handID, pre.CommunityCards, post.CommunityCards)
+	}
+	return actionErr
+}
+
+// logForensicDump records everything needed to reconstruct a divergence
+func logForensicDump(t *table, reason, action string, pn uint, amount uint, violations []poker.Violation, before, after *poker.GameView) {
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+
+	slog.Error("Stack audit violation",
+		"reason", reason,
+		"table", t.name,
+		"hand_id", t.game.CurrentHandID(),
+		"action", action,
+		"player_num", pn,
+		"amount", amount,
+		"violations", violationDetails(violations),
+		"chips_before", poker.ChipTotal(before),
+		"chips_after", poker.ChipTotal(after),
+		"state_before", string(beforeJSON),
+		"state_after", string(afterJSON),
+	)
+}
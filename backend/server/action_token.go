@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// turnState hands out one action token per decision in a hand. Betting
+// actions are applied one at a time under its lock, and an action spends the
+// token it was made with, so a repeated message can't be applied twice.
+type turnState struct {
+	mu        sync.Mutex
+	handID    string
+	sequence  int64 // Decisions issued so far in the hand
+	actionNum uint
+	token     string
+	actor     uuid.UUID // Nil for seats without a user, such as guests
+	open      bool      // A decision is waiting on the token
+	spent     map[string]uuid.UUID
+}
+
+// actionTurn tells clients whose decision the table is waiting on and the
+// token the action must carry. Only sent to clients with the action-tokens
+// capability.
+type actionTurn struct {
+	base            // actionActionTurn
+	HandID   string `json:"hand_id"`
+	Sequence int64  `json:"sequence"`
+	Seat     uint   `json:"seat"`
+	UserID   string `json:"user_id,omitempty"`
+	Token    string `json:"action_token"`
+	// Seconds the player has before they check or fold, 0 for no limit
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// currentTurnLocked returns the decision the table is waiting on, issuing a new
+// token when the hand or the acting seat has moved on since the last one
+func (t *table) currentTurnLocked() (actionTurn, bool) {
+	ts := &t.turn
+	handID, actionNum, ok := t.pendingAction()
+	if !ok {
+		ts.open = false
+		return actionTurn{}, false
+	}
+
+	if handID != ts.handID {
+		ts.handID = handID
+		ts.sequence = 0
+		ts.open = false
+		ts.spent = make(map[string]uuid.UUID)
+	}
+	if !ts.open || ts.actionNum != actionNum {
+		ts.sequence++
+		ts.actionNum = actionNum
+		ts.token = uuid.NewString()
+		ts.actor = t.seatUser(actionNum)
+		ts.open = true
+	}
+
+	turn := actionTurn{
+		base:     base{actionActionTurn},
+		HandID:   ts.handID,
+		Sequence: ts.sequence,
+		Seat:     ts.actionNum,
+		Token:    ts.token,
+	}
+	if ts.actor != uuid.Nil {
+		turn.UserID = ts.actor.String()
+	}
+	if actionTimeout, _, _ := t.sitOutPolicy(); actionTimeout > 0 {
+		turn.TimeoutSeconds = int(actionTimeout / time.Second)
+	}
+	return turn, true
+}
+
+// seatUser returns the user playing a seat, or nil if it has none
+func (t *table) seatUser(actionNum uint) uuid.UUID {
+	engineView, ok := getEngineView(t.game.GenerateOmniView())
+	if !ok || int(actionNum) >= len(engineView.Players) {
+		return uuid.Nil
+	}
+	userID, err := uuid.Parse(engineView.Players[actionNum].UUID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+// announceTurn tells the table whose decision is next and its token
+func (t *table) announceTurn() {
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+	t.announceTurnLocked()
+}
+
+func (t *table) announceTurnLocked() {
+	turn, ok := t.currentTurnLocked()
+	if !ok {
+		t.stopActionTimeout()
+		return
+	}
+	t.broadcast <- createActionTurn(turn)
+	t.scheduleBotTurn(turn)
+	t.scheduleActionTimeout(turn)
+}
+
+// sequencedAction applies a betting action only when the client is the one
+// the table is waiting on. Clients that negotiated action tokens must send
+// the current token; a retry with a token already spent by the same player
+// is answered with the current state instead of being applied again.
+// Clients without the capability are checked against the acting seat only.
+func sequencedAction(c *Client, token string, apply func() bool) {
+	t := c.table
+	if t == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeNotYourTurn, "Join a table before acting"))
+		return
+	}
+	ts := &t.turn
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if t.isHandFrozen() {
+		safeSend(c, createCodedErrorMessage(errorCodeHandFrozen, handFrozenMessage))
+		return
+	}
+
+	if token != "" {
+		if actor, ok := ts.spent[token]; ok && actor == c.userID {
+			safeSend(c, createUpdatedGame(c))
+			return
+		}
+	}
+
+	turn, ok := t.currentTurnLocked()
+	if !ok {
+		safeSend(c, createCodedErrorMessage(errorCodeNotYourTurn, "No action is pending"))
+		return
+	}
+	if ts.actor != uuid.Nil && ts.actor != c.userID {
+		safeSend(c, createCodedErrorMessage(errorCodeNotYourTurn, "It is not your turn to act"))
+		return
+	}
+	if token != turn.Token && (token != "" || c.supports(capabilityActionTokens)) {
+		slog.Info("Rejected action with stale token", "table", t.name, "hand_id", turn.HandID, "user_id", c.userID, "sequence", turn.Sequence)
+		safeSend(c, createCodedErrorMessage(errorCodeStaleActionToken, "This action is out of date, please act again"))
+		safeSend(c, createActionTurn(turn))
+		return
+	}
+
+	// Acting in time ends a streak of timeouts, even if the action is refused
+	t.sitOut.acted(c.userID)
+	if !apply() {
+		return
+	}
+
+	ts.spent[turn.Token] = c.userID
+	ts.open = false
+	t.announceTurnLocked()
+}
+
+func createActionTurn(turn actionTurn) []byte {
+	resp, err := json.Marshal(turn)
+	if err != nil {
+		slog.Default().Warn("Marshal action turn", "error", err)
+	}
+	return resp
+}
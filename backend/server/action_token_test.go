@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newActionTable starts a heads-up hand and returns the table with a client
+// for each seat, the first of them to act
+func newActionTable(t *testing.T) (*table, *Client, *Client) {
+	tbl := newTable("action-token-test", nil, nil, nil, nil, nil)
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-tbl.broadcast:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	users := []uuid.UUID{uuid.New(), uuid.New()}
+	for i, userID := range users {
+		require.NoError(t, tbl.game.SeatPlayer(context.Background(), userID, uuid.New(), userID.String()[:8], i+1, 1000))
+	}
+	require.NoError(t, tbl.game.Start())
+
+	_, actionNum, ok := tbl.pendingAction()
+	require.True(t, ok, "the hand is waiting on a decision")
+	actor := tbl.seatUser(actionNum)
+	other := users[0]
+	if other == actor {
+		other = users[1]
+	}
+	return tbl, newActionClient(t, tbl, actor), newActionClient(t, tbl, other)
+}
+
+func newActionClient(t *testing.T, tbl *table, userID uuid.UUID) *Client {
+	// Game updates look up the client's session, which a dry run answers
+	// without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	c := &Client{userID: userID, table: tbl, send: newSendQueue(0, nil), db: db}
+	c.setCapabilities(newCapabilitySet([]string{capabilityActionTokens}))
+	return c
+}
+
+func currentToken(t *testing.T, tbl *table) string {
+	tbl.turn.mu.Lock()
+	defer tbl.turn.mu.Unlock()
+	turn, ok := tbl.currentTurnLocked()
+	require.True(t, ok)
+	return turn.Token
+}
+
+// replies returns the action, and the code for errors, of each message sent
+// to the client
+func replies(t *testing.T, c *Client) []string {
+	var got []string
+	for {
+		message, ok := c.send.pop()
+		if !ok {
+			return got
+		}
+		var msg struct {
+			Action string `json:"action"`
+			Code   string `json:"code"`
+		}
+		require.NoError(t, json.Unmarshal(message, &msg))
+		if msg.Code != "" {
+			got = append(got, msg.Action+":"+msg.Code)
+		} else {
+			got = append(got, msg.Action)
+		}
+	}
+}
+
+func TestSequencedAction_StaleTokenRejected(t *testing.T) {
+	tbl, actor, _ := newActionTable(t)
+	applied := 0
+	apply := func() bool { applied++; return true }
+
+	sequencedAction(actor, uuid.NewString(), apply)
+	assert.Zero(t, applied)
+	assert.Equal(t, []string{actionError + ":" + errorCodeStaleActionToken, actionActionTurn}, replies(t, actor))
+
+	sequencedAction(actor, "", apply)
+	assert.Zero(t, applied, "clients with the capability must send a token")
+	assert.Equal(t, []string{actionError + ":" + errorCodeStaleActionToken, actionActionTurn}, replies(t, actor))
+
+	sequencedAction(actor, currentToken(t, tbl), apply)
+	assert.Equal(t, 1, applied)
+}
+
+func TestSequencedAction_SpentTokenRetryNotApplied(t *testing.T) {
+	tbl, actor, _ := newActionTable(t)
+	applied := 0
+	apply := func() bool { applied++; return true }
+
+	token := currentToken(t, tbl)
+	sequencedAction(actor, token, apply)
+	require.Equal(t, 1, applied)
+	assert.NotEqual(t, token, currentToken(t, tbl), "the next decision has a new token")
+	replies(t, actor)
+
+	sequencedAction(actor, token, apply)
+	assert.Equal(t, 1, applied, "a retry is not applied again")
+	assert.Equal(t, []string{actionUpdateGame}, replies(t, actor), "the retry is answered with the current state")
+}
+
+func TestSequencedAction_RefusedActionKeepsToken(t *testing.T) {
+	tbl, actor, _ := newActionTable(t)
+
+	token := currentToken(t, tbl)
+	sequencedAction(actor, token, func() bool { return false })
+	assert.Equal(t, token, currentToken(t, tbl))
+
+	applied := 0
+	sequencedAction(actor, token, func() bool { applied++; return true })
+	assert.Equal(t, 1, applied, "the token wasn't spent by a refused action")
+}
+
+func TestSequencedAction_WrongActorRejected(t *testing.T) {
+	tbl, _, other := newActionTable(t)
+	applied := 0
+
+	sequencedAction(other, currentToken(t, tbl), func() bool { applied++; return true })
+	assert.Zero(t, applied)
+	assert.Equal(t, []string{actionError + ":" + errorCodeNotYourTurn}, replies(t, other))
+}
+
+func TestSequencedAction_WithoutCapabilityChecksSeat(t *testing.T) {
+	_, actor, other := newActionTable(t)
+	actor.setCapabilities(newCapabilitySet(nil))
+	other.setCapabilities(newCapabilitySet(nil))
+	applied := 0
+	apply := func() bool { applied++; return true }
+
+	sequencedAction(other, "", apply)
+	assert.Zero(t, applied)
+	assert.Equal(t, []string{actionError + ":" + errorCodeNotYourTurn}, replies(t, other))
+
+	sequencedAction(actor, "", apply)
+	assert.Equal(t, 1, applied, "the acting seat may act without a token")
+
+	sequencedAction(actor, uuid.NewString(), apply)
+	assert.Equal(t, 1, applied, "a token that was sent is still checked")
+	assert.Contains(t, replies(t, actor), actionError+":"+errorCodeStaleActionToken)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LiveSeats returns the user's seats at the tables running on this server
+func (h *Hub) LiveSeats(userID uuid.UUID) []models.LiveSeat {
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	var seats []models.LiveSeat
+	for _, t := range tables {
+		if seat, ok := t.liveSeat(userID); ok {
+			seats = append(seats, seat)
+		}
+	}
+	return seats
+}
+
+func (t *table) liveSeat(userID uuid.UUID) (models.LiveSeat, bool) {
+	position, ok := t.game.PlayerPosition(userID)
+	if !ok {
+		return models.LiveSeat{}, false
+	}
+
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(position) >= len(view.Players) || view.Players[position].Left {
+		return models.LiveSeat{}, false
+	}
+	p := view.Players[position]
+
+	seat := models.LiveSeat{
+		TableName:      t.name,
+		SeatNumber:     int(p.SeatID),
+		Stack:          int64(p.Stack),
+		SmallBlind:     int64(view.Config.SmallBlind),
+		BigBlind:       int64(view.Config.BigBlind),
+		Ante:           int64(view.Config.Ante),
+		HandInProgress: view.Running && view.Stage != poker.PreDeal,
+		ToAct:          view.Betting && p.In && view.ActionNum == position,
+	}
+	if id := t.game.GetTableID(); id != nil {
+		seat.TableID = *id
+	}
+	return seat, true
+}
+
+// ServeWsWithTicket opens an authenticated WebSocket already joined to the
+// table a reconnect ticket was issued for. The table must still be running;
+// a closed table leaves the client connected but not seated anywhere. A seat
+// held there since the user's connection dropped is theirs again.
+func ServeWsWithTicket(hub *Hub, w http.ResponseWriter, r *http.Request, userID uuid.UUID, username, tableName string, formanceService *formance.Service, db *gorm.DB) {
+	conn, err := hub.upgrader().Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	client := newClientWithAuth(conn, hub, userID, username, formanceService, db, capabilitiesFromRequest(r), protocolFromRequest(r))
+	client.correlationID = correlationIDFromRequest(r)
+
+	client.hub.register <- client
+
+	go client.writePump()
+	if hub.resumeSeat(client, tableName) {
+		go client.readPump()
+		return
+	}
+	if t := hub.findTableByName(tableName); t != nil {
+		client.table = t
+		t.register <- client
+	}
+	go client.readPump()
+}
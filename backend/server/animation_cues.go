@@ -0,0 +1,226 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexclewontin/riverboat/eval"
+)
+
+// Recommended animation lengths sent with each cue, so every client paces
+// the board and the pot the same way. Clients should play cues in sequence
+// and wait out each duration before the next.
+const (
+	flopDealtDuration  = 1200 * time.Millisecond
+	turnDealtDuration  = 800 * time.Millisecond
+	riverDealtDuration = 800 * time.Millisecond
+	potAwardedDuration = 1500 * time.Millisecond
+)
+
+// cueSequence numbers the animation cues of a hand
+type cueSequence struct {
+	mu     sync.Mutex
+	handID string
+	last   int
+}
+
+func (s *cueSequence) next(handID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handID != handID {
+		s.handID = handID
+		s.last = 0
+	}
+	s.last++
+	return s.last
+}
+
+// broadcastBoardCues announces each street dealt between two views of the
+// board. An all-in run out deals several streets in one action, and each
+// gets a cue of its own.
+func (t *table) broadcastBoardCues(handID string, before, after []eval.Card) {
+	dealtBefore, board := dealtBoard(before), dealtBoard(after)
+	if len(board) <= len(dealtBefore) {
+		return
+	}
+
+	streets := []struct {
+		action   string
+		name     string
+		from, to int
+		duration time.Duration
+	}{
+		{actionFlopDealt, "Flop", 0, 3, flopDealtDuration},
+		{actionTurnDealt, "Turn", 3, 4, turnDealtDuration},
+		{actionRiverDealt, "River", 4, 5, riverDealtDuration},
+	}
+	for _, street := range streets {
+		if len(dealtBefore) >= street.to || len(board) < street.to {
+			continue
+		}
+		cards := board[street.from:street.to]
+		t.broadcast <- createBoardDealt(street.action, handID, t.cues.next(handID), cards, board[:street.to], street.duration,
+			fmt.Sprintf("%s: %s", street.name, strings.Join(cards, ", ")))
+	}
+}
+
+// broadcastPotAwarded announces one pot going to its winners
+func (t *table) broadcastPotAwarded(handID string, pot int, amount int64, winners []potWinner) {
+	names := make([]string, len(winners))
+	for i, winner := range winners {
+		names[i] = winner.Username
+	}
+	potName := "the main pot"
+	if pot > 0 {
+		potName = fmt.Sprintf("side pot %d", pot)
+	}
+	narration := fmt.Sprintf("%s wins %s of %d", strings.Join(names, " and "), potName, amount)
+	if len(winners) > 1 {
+		narration = fmt.Sprintf("%s split %s of %d", strings.Join(names, " and "), potName, amount)
+	}
+	if halves := splitNarration(winners); halves != "" {
+		narration = fmt.Sprintf("%s of %d: %s", strings.ToUpper(potName[:1])+potName[1:], amount, halves)
+	}
+
+	t.broadcast <- createPotAwarded(handID, t.cues.next(handID), pot, amount, winners, narration)
+}
+
+// potWinners lists the players a pot went to, each with their share
+func potWinners(view *EngineGameView, pot EnginePot) []potWinner {
+	shares := potShares(pot, view.Config.HiLo)
+	winners := make([]potWinner, 0, len(shares))
+	for _, share := range shares {
+		if int(share.position) >= len(view.Players) {
+			continue
+		}
+		player := view.Players[share.position]
+		winners = append(winners, potWinner{
+			UUID:     player.UUID,
+			Username: player.Username,
+			SeatID:   player.SeatID,
+			Amount:   share.amount,
+			Half:     share.half,
+		})
+	}
+	return winners
+}
+
+// potShare is what one player won from a pot
+type potShare struct {
+	position uint
+	amount   int64
+	half     string // Split games only
+}
+
+// potShares lists each player who won part of a pot with the chips they
+// won: high winners first, then players who only won the low half. Pots
+// settled without awards, such as uncontested ones, split evenly.
+func potShares(pot EnginePot, hiLo bool) []potShare {
+	if len(pot.WinningPlayerNums) == 0 {
+		return nil
+	}
+
+	positions := append([]uint{}, pot.WinningPlayerNums...)
+	for _, position := range pot.LowPlayerNums {
+		if !slices.Contains(positions, position) {
+			positions = append(positions, position)
+		}
+	}
+
+	shares := make([]potShare, len(positions))
+	for i, position := range positions {
+		shares[i] = potShare{position: position, amount: int64(pot.Amt) / int64(len(positions))}
+		if amount, ok := pot.Awards[position]; ok {
+			shares[i].amount = int64(amount)
+		}
+		if !hiLo {
+			continue
+		}
+		high := slices.Contains(pot.WinningPlayerNums, position)
+		low := slices.Contains(pot.LowPlayerNums, position)
+		switch {
+		case high && low:
+			shares[i].half = "high_low"
+		case low:
+			shares[i].half = "low"
+		default:
+			shares[i].half = "high"
+		}
+	}
+	return shares
+}
+
+// splitNarration describes who won each half of a split pot, or returns ""
+// for pots that weren't split by hand
+func splitNarration(winners []potWinner) string {
+	var high, low []string
+	for _, winner := range winners {
+		switch winner.Half {
+		case "high":
+			high = append(high, winner.Username)
+		case "low":
+			low = append(low, winner.Username)
+		case "high_low":
+			high = append(high, winner.Username)
+			low = append(low, winner.Username)
+		}
+	}
+	if len(high) == 0 {
+		return ""
+	}
+	if len(low) == 0 {
+		return fmt.Sprintf("%s scoops with no qualifying low", strings.Join(high, " and "))
+	}
+	return fmt.Sprintf("high to %s, low to %s", strings.Join(high, " and "), strings.Join(low, " and "))
+}
+
+// dealtBoard returns the board cards dealt so far, skipping empty slots
+func dealtBoard(cards []eval.Card) []string {
+	board := make([]string, 0, len(cards))
+	for _, card := range cards {
+		if card != 0 {
+			board = append(board, card.String())
+		}
+	}
+	return board
+}
+
+func createBoardDealt(action, handID string, sequence int, cards, board []string, duration time.Duration, narration string) []byte {
+	message := boardDealt{
+		base{action},
+		handID,
+		sequence,
+		cards,
+		board,
+		duration.Milliseconds(),
+		narration,
+	}
+	resp, err := json.Marshal(message)
+	if err != nil {
+		slog.Default().Warn("Marshal board dealt", "error", err)
+	}
+	return resp
+}
+
+func createPotAwarded(handID string, sequence, pot int, amount int64, winners []potWinner, narration string) []byte {
+	message := potAwarded{
+		base{actionPotAwarded},
+		handID,
+		sequence,
+		pot,
+		amount,
+		winners,
+		potAwardedDuration.Milliseconds(),
+		narration,
+	}
+	resp, err := json.Marshal(message)
+	if err != nil {
+		slog.Default().Warn("Marshal pot awarded", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// callTimeWarnings are how long before call time the table announces it
+var callTimeWarnings = []time.Duration{30 * time.Minute, 15 * time.Minute, 5 * time.Minute, time.Minute}
+
+// tablePolicy holds the private game rules set by the table creator
+type tablePolicy struct {
+	callTime      *time.Time
+	callTimeHands int           // Hands still dealt once call time is reached
+	minPlay       time.Duration // How long a big winner must stay before leaving
+	bigWin        int64         // Profit that counts as a big win, 0 for any profit
+	// Cash tables close after shortHanded below minPlayers
+	cash        bool
+	minPlayers  int
+	shortHanded time.Duration
+	// The table's game, and at dealer's choice tables the games the button
+	// picks from for each hand
+	gameType poker.Variant
+	games    []poker.Variant
+	// Pots are split between the high hand and a low of eight or better
+	hiLo bool
+	// Cap tables: most chips a player may have in play, 0 for no cap
+	chipCap  int64
+	maxBuyIn int64
+	// Play chips only: no money moves and bots fill empty seats
+	practice bool
+	// Cash tables: how long a player has to act, timeouts in a row before
+	// they are sat out, and how long they may sit out before being cashed
+	// out. 0 turns each off.
+	actionTimeout time.Duration
+	sitOutAfter   int
+	sitOutCashOut time.Duration
+	// Cash tables: per-hand rake, see formance.RakeConfig
+	rakePercentage float64
+	rakeCap        int64
+	rakeMinPot     int64
+	// The stake template the table was opened from, for rake-free windows
+	templateID *uuid.UUID
+}
+
+// callTimeState tracks a table's countdown to call time. Once call time is
+// reached the table deals its final hands and then closes.
+type callTimeState struct {
+	mu        sync.Mutex
+	policy    tablePolicy
+	timer     *time.Timer
+	armedFor  time.Time // Call time the countdown was started for
+	reached   bool
+	handsLeft int
+	closed    bool
+}
+
+// refreshPolicy reloads the table's private game rules and restarts the call
+// time countdown if the creator moved it
+func (t *table) refreshPolicy() {
+	if t.tableService == nil {
+		return
+	}
+	record, err := t.tableService.GetTableByName(ctx, t.name)
+	if err != nil {
+		return
+	}
+
+	policy := tablePolicy{
+		callTime:      record.CallTime,
+		callTimeHands: record.CallTimeHands,
+		minPlay:       time.Duration(record.MinPlayMinutes) * time.Minute,
+		bigWin:        record.BigWinAmount,
+		cash:          record.TableType == "cash",
+		minPlayers:    record.MinPlayers,
+		shortHanded:   time.Duration(record.ShortHandedMinutes) * time.Minute,
+		gameType:      tableVariant(record.GameType),
+		hiLo:          record.HiLo,
+		maxBuyIn:      record.MaxBuyIn,
+		practice:      record.TableType == "practice",
+		templateID:    record.TemplateID,
+	}
+	if policy.cash {
+		policy.actionTimeout = time.Duration(record.ActionTimeoutSeconds) * time.Second
+		policy.sitOutAfter = record.SitOutAfterTimeouts
+		policy.sitOutCashOut = time.Duration(record.SitOutCashOutMinutes) * time.Minute
+		policy.rakePercentage = record.RakePercentage
+		policy.rakeCap = record.RakeCap
+		policy.rakeMinPot = record.RakeMinPot
+	}
+	if record.StackType == services.StackTypeCap {
+		policy.chipCap = record.ChipCap
+	}
+	if record.DealersChoice != "" {
+		for _, game := range strings.Split(record.DealersChoice, ",") {
+			policy.games = append(policy.games, poker.Variant(game))
+		}
+	}
+	t.applyTableGame(policy)
+
+	s := &t.callTime
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policy = policy
+	if s.closed {
+		return
+	}
+
+	var callTime time.Time
+	if policy.callTime != nil {
+		callTime = *policy.callTime
+	}
+	if callTime.Equal(s.armedFor) {
+		return
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.armedFor = callTime
+	s.reached = false
+	s.handsLeft = 0
+	if !callTime.IsZero() {
+		slog.Info("Call time set", "table", t.name, "call_time", callTime, "final_hands", policy.callTimeHands)
+		t.scheduleCallTimeLocked()
+	}
+}
+
+// scheduleCallTimeLocked arms the timer for the next countdown announcement,
+// or for call time itself
+func (t *table) scheduleCallTimeLocked() {
+	s := &t.callTime
+	callTime := s.armedFor
+	remaining := time.Until(callTime)
+
+	next := remaining
+	for _, warning := range callTimeWarnings {
+		if remaining > warning {
+			next = remaining - warning
+			break
+		}
+	}
+	if next < 0 {
+		next = 0
+	}
+
+	s.timer = time.AfterFunc(next, func() { t.callTimeTick(callTime) })
+}
+
+// callTimeTick announces the countdown and, once call time arrives, starts
+// the final hands
+func (t *table) callTimeTick(callTime time.Time) {
+	s := &t.callTime
+	s.mu.Lock()
+	if s.closed || !s.armedFor.Equal(callTime) {
+		// The creator moved or cleared call time since this was scheduled
+		s.mu.Unlock()
+		return
+	}
+
+	remaining := time.Until(callTime)
+	if remaining > time.Second {
+		t.scheduleCallTimeLocked()
+		s.mu.Unlock()
+		minutes := int((remaining + 30*time.Second) / time.Minute)
+		t.announce(fmt.Sprintf("Call time in %d minute%s.", minutes, plural(minutes)))
+		return
+	}
+
+	s.reached = true
+	s.handsLeft = s.policy.callTimeHands
+	s.timer = nil
+	handsLeft := s.handsLeft
+	s.mu.Unlock()
+
+	slog.Info("Call time reached", "table", t.name, "final_hands", handsLeft)
+	if handsLeft > 0 {
+		t.announce(fmt.Sprintf("Call time! %d more hand%s will be dealt, then the table closes.", handsLeft, plural(handsLeft)))
+		return
+	}
+
+	t.announce("Call time! The table closes after the current hand.")
+	if view, ok := getEngineView(t.game.GenerateOmniView()); ok && !view.Running {
+		t.closeForCallTime()
+	}
+}
+
+// callTimeAllowsHand reports whether another hand may start. Once the final
+// hands have been dealt it closes the table instead.
+func (t *table) callTimeAllowsHand() bool {
+	s := &t.callTime
+	s.mu.Lock()
+	closed := s.closed
+	done := s.reached && s.handsLeft <= 0
+	s.mu.Unlock()
+
+	if closed {
+		return false
+	}
+	if done {
+		t.closeForCallTime()
+		return false
+	}
+	return true
+}
+
+// countCallTimeHand counts a newly started hand against the final hands and
+// tells the table how many remain
+func (t *table) countCallTimeHand(handID string) {
+	s := &t.callTime
+	s.mu.Lock()
+	if !s.reached || s.closed || s.handsLeft <= 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.handsLeft--
+	handsLeft := s.handsLeft
+	s.mu.Unlock()
+
+	if handsLeft == 0 {
+		t.announceHand(handID, "Last hand before the table closes.")
+		return
+	}
+	t.announceHand(handID, fmt.Sprintf("%d hand%s left after this one before the table closes.", handsLeft, plural(handsLeft)))
+}
+
+// closeForCallTime stops the table dealing for good. Players keep their seats
+// until they leave, and no minimum play time applies any more.
+func (t *table) closeForCallTime() {
+	s := &t.callTime
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	if id := t.game.GetTableID(); id != nil && t.tableService != nil {
+		if err := t.tableService.UpdateTableStatus(ctx, *id, "finished"); err != nil {
+			slog.Warn("Failed to mark table finished at call time", "table", t.name, "error", err)
+		}
+	}
+
+	slog.Info("Table closed at call time", "table", t.name)
+	t.announce("The table is closed for call time. Thanks for playing! Leave the table to cash out your chips.")
+}
+
+// callTimeClosed reports whether the table has closed at call time
+func (t *table) callTimeClosed() bool {
+	t.callTime.mu.Lock()
+	defer t.callTime.mu.Unlock()
+	return t.callTime.closed
+}
+
+// minPlayRemaining is how much longer a player who is up a big win must stay
+// before cashing out. Minimum play time no longer applies once call time is
+// reached.
+func (t *table) minPlayRemaining(c *Client) (time.Duration, int64) {
+	s := &t.callTime
+	s.mu.Lock()
+	policy := s.policy
+	over := s.reached || s.closed
+	s.mu.Unlock()
+
+	if policy.minPlay <= 0 || over || c.sessionID == uuid.Nil || t.sessionService == nil {
+		return 0, 0
+	}
+
+	session, err := t.sessionService.GetSessionByID(ctx, c.sessionID)
+	if err != nil {
+		return 0, 0
+	}
+	position, seated := t.game.PlayerPosition(c.userID)
+	if !seated {
+		return 0, 0
+	}
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(position) >= len(view.Players) {
+		return 0, 0
+	}
+
+	profit := int64(view.Players[position].Stack) - session.BuyInAmount
+	if profit <= 0 || profit < policy.bigWin {
+		return 0, 0
+	}
+
+	remaining := policy.minPlay - time.Since(session.JoinedAt)
+	if remaining <= 0 {
+		return 0, 0
+	}
+	return remaining, profit
+}
+
+// rejectHitAndRun refuses to let a big winner leave before the table's
+// minimum play time and reports whether it did
+func rejectHitAndRun(c *Client) bool {
+	if c.table == nil {
+		return false
+	}
+	remaining, profit := c.table.minPlayRemaining(c)
+	if remaining <= 0 {
+		return false
+	}
+
+	minutes := int((remaining + time.Minute - 1) / time.Minute)
+	safeSend(c, createCodedErrorMessage(errorCodeMinPlayTime, fmt.Sprintf("You're up %d MNT. This table asks winners to keep playing for a while; you can cash out in %d minute%s.", profit, minutes, plural(minutes))))
+	return true
+}
+
+func (t *table) announce(message string) {
+	t.announceHand(t.game.CurrentHandID(), message)
+}
+
+func (t *table) announceHand(handID, message string) {
+	t.broadcast <- createNewLog(handID, message)
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// client capabilities that can be negotiated at connect time
+const (
+	capabilityDeltaUpdates     string = "delta-updates"
+	capabilityStructuredErrors string = "structured-errors"
+	capabilityAnimationCues    string = "animation-cues"
+	capabilityActionTokens     string = "action-tokens"
+)
+
+// supportedCapabilities lists every capability the server knows how to serve.
+// Anything a client advertises outside of this list is ignored.
+var supportedCapabilities = []string{
+	capabilityDeltaUpdates,
+	capabilityStructuredErrors,
+	capabilityAnimationCues,
+	capabilityActionTokens,
+}
+
+// capabilitySet holds the capabilities negotiated for a single connection
+type capabilitySet map[string]bool
+
+// newCapabilitySet keeps only the requested capabilities the server supports
+func newCapabilitySet(requested []string) capabilitySet {
+	set := make(capabilitySet)
+	for _, name := range requested {
+		name = strings.TrimSpace(strings.ToLower(name))
+		for _, supported := range supportedCapabilities {
+			if name == supported {
+				set[name] = true
+			}
+		}
+	}
+	return set
+}
+
+// capabilitiesFromRequest reads the comma separated "capabilities" query
+// parameter or the X-Client-Capabilities header sent during the upgrade
+func capabilitiesFromRequest(r *http.Request) capabilitySet {
+	raw := r.URL.Query().Get("capabilities")
+	if raw == "" {
+		raw = r.Header.Get("X-Client-Capabilities")
+	}
+	if raw == "" {
+		return make(capabilitySet)
+	}
+	return newCapabilitySet(strings.Split(raw, ","))
+}
+
+func (cs capabilitySet) has(name string) bool {
+	return cs[name]
+}
+
+// list returns the negotiated capabilities in a stable order
+func (cs capabilitySet) list() []string {
+	names := make([]string, 0, len(cs))
+	for _, supported := range supportedCapabilities {
+		if cs[supported] {
+			names = append(names, supported)
+		}
+	}
+	return names
+}
+
+// outboundState tracks what has already been sent to a client so that
+// capability specific formats (e.g. delta updates) can be produced
+type outboundState struct {
+	mu       sync.Mutex
+	lastGame map[string]json.RawMessage
+}
+
+// supports reports whether the client negotiated the given capability
+func (c *Client) supports(capability string) bool {
+	c.capabilitiesMu.RLock()
+	defer c.capabilitiesMu.RUnlock()
+	return c.capabilities.has(capability)
+}
+
+// setCapabilities replaces the negotiated capabilities for the client
+func (c *Client) setCapabilities(capabilities capabilitySet) {
+	c.capabilitiesMu.Lock()
+	c.capabilities = capabilities
+	c.capabilitiesMu.Unlock()
+
+	// Force the next game update to be a full snapshot
+	c.outbound.mu.Lock()
+	c.outbound.lastGame = nil
+	c.outbound.mu.Unlock()
+}
+
+// negotiatedCapabilities returns the client's capabilities in a stable order
+func (c *Client) negotiatedCapabilities() []string {
+	c.capabilitiesMu.RLock()
+	defer c.capabilitiesMu.RUnlock()
+	return c.capabilities.list()
+}
+
+// adaptOutbound tailors a server message to the viewer, capabilities and
+// protocol version of the client. Nil means the message is not for this
+// client at all.
+func (c *Client) adaptOutbound(message []byte) []byte {
+	var msg base
+	if err := json.Unmarshal(message, &msg); err == nil && msg.Action == actionUpdateGame {
+		if message = c.viewGame(message); message == nil {
+			return nil
+		}
+	}
+	message = c.adaptCapabilities(message)
+	if message != nil && c.protocolVersion() == protocolVersionEnvelope {
+		return wrapEnvelope(message)
+	}
+	return message
+}
+
+// adaptCapabilities tailors a server message to the capabilities of the
+// client. Clients without any capabilities receive the message unchanged so
+// older clients keep working as new formats ship.
+func (c *Client) adaptCapabilities(message []byte) []byte {
+	var msg base
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return message
+	}
+
+	switch msg.Action {
+	case actionUpdateGame:
+		if c.supports(capabilityDeltaUpdates) {
+			return c.createGameDelta(message)
+		}
+	case actionError:
+		// Protocol 2 clients always get error codes
+		if !c.supports(capabilityStructuredErrors) && c.protocolVersion() == protocolVersionFlat {
+			return stripErrorCode(message)
+		}
+	case actionFlopDealt, actionTurnDealt, actionRiverDealt, actionPotAwarded:
+		if !c.supports(capabilityAnimationCues) {
+			return nil
+		}
+	case actionActionTurn:
+		if !c.supports(capabilityActionTokens) {
+			return nil
+		}
+	}
+	return message
+}
+
+// createGameDelta converts a full update-game message into an update-game-delta
+// message containing only the top level game fields that changed since the
+// last update sent to this client
+func (c *Client) createGameDelta(message []byte) []byte {
+	var full struct {
+		Game        map[string]json.RawMessage `json:"game"`
+		SessionInfo *SessionInfo               `json:"session_info,omitempty"`
+		Resync      bool                       `json:"resync,omitempty"`
+	}
+	if err := json.Unmarshal(message, &full); err != nil || full.Game == nil {
+		return message
+	}
+
+	c.outbound.mu.Lock()
+	defer c.outbound.mu.Unlock()
+
+	// First update after connect (or renegotiation) and resyncs are always a
+	// full snapshot
+	if c.outbound.lastGame == nil || full.Resync {
+		c.outbound.lastGame = full.Game
+		return message
+	}
+
+	changed := make(map[string]json.RawMessage)
+	for key, value := range full.Game {
+		if previous, ok := c.outbound.lastGame[key]; !ok || !bytes.Equal(previous, value) {
+			changed[key] = value
+		}
+	}
+	removed := make([]string, 0)
+	for key := range c.outbound.lastGame {
+		if _, ok := full.Game[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	c.outbound.lastGame = full.Game
+
+	delta := updateGameDelta{
+		base{actionUpdateGameDelta},
+		changed,
+		removed,
+		full.SessionInfo,
+	}
+	resp, err := json.Marshal(delta)
+	if err != nil {
+		slog.Default().Warn("Marshal update game delta", "error", err)
+		return message
+	}
+	return resp
+}
+
+// stripErrorCode removes the structured error code for clients that only
+// understand the original {action, message, time} error shape
+func stripErrorCode(message []byte) []byte {
+	var errorMsg map[string]interface{}
+	if err := json.Unmarshal(message, &errorMsg); err != nil {
+		return message
+	}
+	if _, ok := errorMsg["code"]; !ok {
+		return message
+	}
+	delete(errorMsg, "code")
+	resp, err := json.Marshal(errorMsg)
+	if err != nil {
+		return message
+	}
+	return resp
+}
+
+// handleClientHello lets a client (re)negotiate its capabilities, and its
+// protocol version when it gives one, after connect
+func handleClientHello(c *Client, requested []string, version int) {
+	c.setCapabilities(newCapabilitySet(requested))
+	if version != 0 {
+		c.setProtocolVersion(version)
+	}
+	safeSend(c, createServerHello(c))
+}
+
+// negotiate enables the capabilities and protocol version the client asked
+// for when connecting, and tells it which it got
+func (c *Client) negotiate(capabilities capabilitySet, protocol int) {
+	c.capabilities = capabilities
+	if protocol != 0 {
+		c.setProtocolVersion(protocol)
+	}
+	if len(capabilities) > 0 || protocol != 0 {
+		c.send.push(createServerHello(c))
+	}
+}
+
+func createServerHello(c *Client) []byte {
+	hello := serverHello{
+		base:              base{actionServerHello},
+		Capabilities:      c.negotiatedCapabilities(),
+		Supported:         supportedCapabilities,
+		ProtocolVersion:   c.protocolVersion(),
+		SupportedVersions: supportedProtocolVersions,
+	}
+	if hello.ProtocolVersion == protocolVersionEnvelope {
+		hello.Actions = inboundActions()
+	}
+	resp, err := json.Marshal(hello)
+	if err != nil {
+		slog.Default().Warn("Marshal server hello", "error", err)
+	}
+	return resp
+}
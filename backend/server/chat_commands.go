@@ -0,0 +1,284 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// chatCommandPrefix marks a table chat message as a command for the server
+// rather than a message for the table
+const chatCommandPrefix = "/"
+
+// callClockTimeout is how long a player has to act once the clock is called
+// on them
+const callClockTimeout = 30 * time.Second
+
+// historyCommandHands is how many of their latest hands /history lists
+const historyCommandHands = 5
+
+// Who may use a chat command
+const (
+	chatCommandAtTable = iota // Anyone signed in at the table
+	chatCommandSeated         // Players with a seat
+	chatCommandDealtIn        // Players dealt into the hand being played
+)
+
+// chatCommand is a table utility run from chat. Replies go only to the
+// player who typed it.
+type chatCommand struct {
+	usage      string
+	permission int
+	limit      int           // Uses allowed per window
+	window     time.Duration // 0 for no limit
+	run        func(c *Client, args []string)
+}
+
+var chatCommands map[string]chatCommand
+
+func init() {
+	chatCommands = map[string]chatCommand{
+		"stack":   {"/stack - your stack in chips and big blinds", chatCommandSeated, 10, time.Minute, runStackCommand},
+		"time":    {"/time - call the clock on the player to act", chatCommandDealtIn, 3, 5 * time.Minute, runTimeCommand},
+		"handid":  {"/handid - the ID of the hand being played", chatCommandAtTable, 10, time.Minute, runHandIDCommand},
+		"sitout":  {"/sitout - sit out from the next hand", chatCommandSeated, 3, time.Minute, runSitOutCommand},
+		"history": {"/history - your latest hands", chatCommandAtTable, 3, time.Minute, runHistoryCommand},
+		"help":    {"/help - these commands", chatCommandAtTable, 5, time.Minute, runHelpCommand},
+	}
+}
+
+// chatCommandLimits counts each user's recent uses of each command at a table
+type chatCommandLimits struct {
+	mu   sync.Mutex
+	uses map[chatCommandUse][]time.Time
+}
+
+type chatCommandUse struct {
+	userID  uuid.UUID
+	command string
+}
+
+// allow records a use of the command and reports whether it was within the
+// command's limit
+func (l *chatCommandLimits) allow(userID uuid.UUID, name string, cmd chatCommand, now time.Time) bool {
+	if cmd.window <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.uses == nil {
+		l.uses = make(map[chatCommandUse][]time.Time)
+	}
+	key := chatCommandUse{userID, name}
+	recent := l.uses[key][:0]
+	for _, at := range l.uses[key] {
+		if now.Sub(at) < cmd.window {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= cmd.limit {
+		l.uses[key] = recent
+		return false
+	}
+	l.uses[key] = append(recent, now)
+	return true
+}
+
+// handleChatCommand runs a table chat message that starts with a slash as a
+// command. Returns false for messages that aren't commands.
+func handleChatCommand(c *Client, message string) bool {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, chatCommandPrefix) || strings.HasPrefix(message, chatCommandPrefix+chatCommandPrefix) {
+		return false
+	}
+	fields := strings.Fields(strings.TrimPrefix(message, chatCommandPrefix))
+	if len(fields) == 0 {
+		return false
+	}
+	name := strings.ToLower(fields[0])
+
+	cmd, ok := chatCommands[name]
+	if !ok {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, fmt.Sprintf("Unknown command /%s. Type /help for the commands.", name)))
+		return true
+	}
+	if c.userID == uuid.Nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Sign in to use chat commands"))
+		return true
+	}
+	if reason := c.table.chatCommandDenied(c.userID, cmd.permission); reason != "" {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, reason))
+		return true
+	}
+	if !c.table.chatCommandLimits.allow(c.userID, name, cmd, time.Now()) {
+		safeSend(c, createCodedErrorMessage(errorCodeRateLimited, fmt.Sprintf("You've used /%s too often. Wait a moment and try again.", name)))
+		return true
+	}
+
+	slog.Debug("Chat command", "table", c.table.name, "user_id", c.userID, "command", name)
+	cmd.run(c, fields[1:])
+	return true
+}
+
+// chatCommandDenied returns why the user may not use a command needing the
+// permission, or "" if they may
+func (t *table) chatCommandDenied(userID uuid.UUID, permission int) string {
+	if permission == chatCommandAtTable {
+		return ""
+	}
+	position, seated := t.game.PlayerPosition(userID)
+	if !seated {
+		return "Take a seat first"
+	}
+	if permission == chatCommandSeated {
+		return ""
+	}
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if !view.Running || int(position) >= len(view.Players) || !view.Players[position].In {
+		return "Only players in the hand can do that"
+	}
+	return ""
+}
+
+// replyToCommand answers a chat command in the sender's table log only
+func replyToCommand(c *Client, message string) {
+	safeSend(c, createNewLog(c.table.game.CurrentHandID(), message))
+}
+
+func runStackCommand(c *Client, _ []string) {
+	position, seated := c.table.game.PlayerPosition(c.userID)
+	view := c.table.game.GetLegacyGame().GenerateOmniView()
+	if !seated || int(position) >= len(view.Players) {
+		replyToCommand(c, "You don't have a seat")
+		return
+	}
+	stack := view.Players[position].Stack
+	if bb := view.Config.BigBlind; bb > 0 {
+		replyToCommand(c, fmt.Sprintf("Your stack is %d (%.1f big blinds)", stack, float64(stack)/float64(bb)))
+		return
+	}
+	replyToCommand(c, fmt.Sprintf("Your stack is %d", stack))
+}
+
+func runHandIDCommand(c *Client, _ []string) {
+	handID := c.table.game.CurrentHandID()
+	if handID == "" {
+		replyToCommand(c, "No hand is being played")
+		return
+	}
+	replyToCommand(c, "Hand ID: "+handID)
+}
+
+func runSitOutCommand(c *Client, _ []string) {
+	handleSitOut(c)
+}
+
+func runHistoryCommand(c *Client, _ []string) {
+	if c.table.handHistoryService == nil {
+		replyToCommand(c, "Hand history is not available")
+		return
+	}
+	hands, _, err := c.table.handHistoryService.ListForViewer(ctx, c.userID, historyCommandHands, 0)
+	if err != nil {
+		slog.Warn("Failed to list hands for chat command", "user_id", c.userID, "error", err)
+		safeSend(c, createErrorMessage("Couldn't load your hand history. Try again later."))
+		return
+	}
+	if len(hands) == 0 {
+		replyToCommand(c, "You haven't played any hands yet")
+		return
+	}
+
+	replyToCommand(c, "Your latest hands:")
+	for _, hand := range hands {
+		var won int64
+		for _, winner := range hand.Winners {
+			if winner.UserID == c.userID {
+				won += winner.Amount
+			}
+		}
+		line := fmt.Sprintf("%s at %s, pot %d", hand.HandID, hand.TableName, hand.TotalPot)
+		if won > 0 {
+			line += fmt.Sprintf(", you won %d", won)
+		}
+		replyToCommand(c, line)
+	}
+}
+
+func runHelpCommand(c *Client, _ []string) {
+	usages := make([]string, 0, len(chatCommands))
+	for _, cmd := range chatCommands {
+		usages = append(usages, cmd.usage)
+	}
+	sort.Strings(usages)
+	replyToCommand(c, "Chat commands:")
+	for _, usage := range usages {
+		replyToCommand(c, usage)
+	}
+}
+
+func runTimeCommand(c *Client, _ []string) {
+	t := c.table
+	username, reason := t.callClock(c.userID)
+	if reason != "" {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, reason))
+		return
+	}
+	slog.Info("Clock called", "table", t.name, "user_id", c.userID, "actor", username)
+	t.announce(fmt.Sprintf("%s called the clock on %s, who has %d seconds to act", c.username, username, int(callClockTimeout/time.Second)))
+}
+
+// callClock gives the player to act callClockTimeout to decide before they
+// check or fold, once per decision. Returns the actor's name, or why the
+// clock can't be called.
+func (t *table) callClock(caller uuid.UUID) (string, string) {
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	turn, ok := t.currentTurnLocked()
+	if !ok {
+		return "", "Nobody is deciding right now"
+	}
+	actor, err := uuid.Parse(turn.UserID)
+	if err != nil || t.practiceBot(actor) != nil {
+		return "", "The clock can only be called on players"
+	}
+	if actor == caller {
+		return "", "You can't call the clock on yourself"
+	}
+
+	s := &t.sitOut
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clockCalled == turn.Token {
+		return "", "The clock has already been called on this decision"
+	}
+	if !s.deadline.IsZero() && time.Until(s.deadline) <= callClockTimeout {
+		return "", "The player to act already has less time than the clock allows"
+	}
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	turn.TimeoutSeconds = int(callClockTimeout / time.Second)
+	s.timer = time.AfterFunc(callClockTimeout+t.latency.allowance(actor), func() {
+		t.actionTimedOut(turn.Token)
+	})
+	s.deadline = time.Now().Add(callClockTimeout)
+	s.clockCalled = turn.Token
+	t.setTurnClock(turnClock{turn: turn, deadline: s.deadline})
+
+	username := turn.UserID
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(turn.Seat) < len(view.Players) {
+		username = view.Players[turn.Seat].Username
+	}
+	return username, ""
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/formance"
@@ -30,7 +32,7 @@ var upgrader = websocket.Upgrader{
 type Client struct {
 	hub             *Hub
 	conn            *websocket.Conn // Websocket connection
-	send            chan []byte     // Buffered channel of outbound bytes
+	send            *sendQueue      // Bounded queue of outbound messages
 	uuid            string          // UUID
 	username        string
 	userID          uuid.UUID         // Authenticated user ID
@@ -38,29 +40,43 @@ type Client struct {
 	table           *table            // Player's table
 	formanceService *formance.Service // Access to balance operations
 	db              *gorm.DB          // Database connection
+	capabilities    capabilitySet     // Capabilities negotiated with the client
+	capabilitiesMu  sync.RWMutex
+	outbound        outboundState // Per-connection state for tailored message formats
+	trainingMode    atomic.Bool   // Opted in to post-hand training summaries
+	tutorial        *tutorialSession
+	commands        commandCache           // Command IDs seen recently, to drop retried frames
+	correlationID   string                 // ID the upgrade request was logged under
+	protocol        atomic.Int32           // Protocol version negotiated, 0 for the default
+	request         atomic.Pointer[string] // ID of the enveloped request being handled
+	latency         latencyState           // Round trip time, measured with pings
 }
 
 func newClient(conn *websocket.Conn, hub *Hub) *Client {
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, 1024),
-		uuid: uuid.New().String(),
+		hub:          hub,
+		conn:         conn,
+		send:         newSendQueue(hub.sendQueueSize, hub.sendMetrics),
+		uuid:         uuid.New().String(),
+		capabilities: make(capabilitySet),
 	}
 }
 
-func newClientWithAuth(conn *websocket.Conn, hub *Hub, userID uuid.UUID, username string, formanceService *formance.Service, db *gorm.DB) *Client {
+func newClientWithAuth(conn *websocket.Conn, hub *Hub, userID uuid.UUID, username string, formanceService *formance.Service, db *gorm.DB, capabilities capabilitySet, protocol int) *Client {
 	client := &Client{
 		hub:             hub,
 		conn:            conn,
-		send:            make(chan []byte, 1024),
+		send:            newSendQueue(hub.sendQueueSize, hub.sendMetrics),
 		uuid:            uuid.New().String(),
 		userID:          userID,
 		username:        username,
 		formanceService: formanceService,
 		db:              db,
+		capabilities:    capabilities,
 	}
 
+	client.negotiate(capabilities, protocol)
+
 	// Send initial balance update when client connects
 	go func() {
 		// Small delay to ensure client is ready to receive messages
@@ -74,14 +90,15 @@ func newClientWithAuth(conn *websocket.Conn, hub *Hub, userID uuid.UUID, usernam
 func (c *Client) disconnect() {
 	// Handle cash-out BEFORE unregistering from hub to avoid sending on closed channel
 	if c.table != nil {
-		// Handle cash-out before leaving table
-		if c.formanceService != nil && c.userID != uuid.Nil {
+		// A seated player keeps their seat and stack for a while to reconnect;
+		// anyone else is cashed out before leaving the table
+		if !c.hub.holdSeat(c) && c.formanceService != nil && c.userID != uuid.Nil {
 			handlePlayerCashOut(c)
 		}
 		c.table.unregister <- c
 	}
 
-	// Unregister from hub (this closes the send channel)
+	// Unregister from hub (this closes the send queue)
 	c.hub.unregister <- c
 	c.conn.Close()
 }
@@ -92,14 +109,16 @@ func (c *Client) disconnect() {
 // ensures that there is at most one reader on a connection by executing all
 // reads from this goroutine.
 func (c *Client) readPump() {
+	connected := time.Now()
 	defer func() {
 		c.disconnect()
+		slog.Info("WebSocket closed", "correlation_id", c.correlationID, "user_id", c.userID, "duration", time.Since(connected))
 	}()
 	c.conn.SetReadLimit(maxMessageSize)
 	if err := c.conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 		slog.Default().Warn("set read deadline", "error", err)
 	}
-	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
+	c.conn.SetPongHandler(c.handlePong)
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
@@ -109,9 +128,11 @@ func (c *Client) readPump() {
 			slog.Default().Warn("Read from websocket", "error", err)
 			break
 		}
-		if err = c.processEvents(message); err != nil {
-			slog.Default().Warn("Process websocket message", "error", err)
-		}
+		start := time.Now()
+		at := c.table
+		err = c.processEvents(message)
+		c.logCommand(message, start, err)
+		c.recordCommand(at, message, err)
 	}
 }
 
@@ -119,35 +140,45 @@ func (c *Client) readPump() {
 //
 // A goroutine running writePump is started for each connection. The
 // application ensures that there is at most one writer to a connection by
-// executing all writes from this goroutine.
+// executing all writes from this goroutine. Messages are adapted to the
+// client's capabilities here, as they are written, so that delta updates are
+// always relative to the last view the client actually received.
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
 	}()
+	// A first round trip sample, so decisions are allowed for latency early
+	c.probeLatency()
 	for {
 		select {
-		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel.
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				slog.Default().Warn("Write websocket message", "error", err)
+		case <-c.send.ready:
+			for {
+				message, ok := c.send.pop()
+				if !ok {
+					break
+				}
+				message = c.adaptOutbound(message)
+				if message == nil {
+					continue
+				}
+				if err := c.writeMessage(message); err != nil {
+					slog.Default().Warn("Write websocket message", "error", err)
+					return
+				}
 			}
-			w.Write(message)
 
-			if err := w.Close(); err != nil {
+			if c.send.isClosed() {
+				// The hub closed the queue.
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := c.conn.WriteMessage(websocket.PingMessage, pingPayload(time.Now())); err != nil {
 				slog.Default().Warn("Write websocket ping", "error", err)
 				return
 			}
@@ -155,10 +186,29 @@ func (c *Client) writePump() {
 	}
 }
 
+func (c *Client) writeMessage(message []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	w, err := c.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(message)
+	return w.Close()
+}
+
+// upgrader returns a WebSocket upgrader applying the hub's origin policy
+func (h *Hub) upgrader() *websocket.Upgrader {
+	u := upgrader
+	u.CheckOrigin = h.checkOrigin
+	if u.CheckOrigin == nil {
+		u.CheckOrigin = func(r *http.Request) bool { return true }
+	}
+	return &u
+}
+
 // serveWs handles websocket requests from the peer.
 func serveWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
-	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := hub.upgrader().Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
@@ -174,6 +224,12 @@ func serveWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *Client) processEvents(rawMessage []byte) error {
+	defer c.endRequest()
+	rawMessage, ok := c.openEnvelope(rawMessage)
+	if !ok {
+		return nil
+	}
+
 	var baseMessage base
 	err := json.Unmarshal(rawMessage, &baseMessage)
 	if err != nil {
@@ -184,6 +240,25 @@ func (c *Client) processEvents(rawMessage []byte) error {
 		return errors.New("deserialize message")
 	}
 
+	// A retried frame must not place a bet, post a message or buy in twice
+	if c.duplicateCommand(rawMessage, baseMessage.Action) {
+		return nil
+	}
+
+	// Betting actions go to the private tutorial table while one is in progress
+	if action, ok := tutorialActions[baseMessage.Action]; ok && c.tutorial != nil {
+		var raise playerRaise
+		if err := json.Unmarshal(rawMessage, &raise); err != nil {
+			return err
+		}
+		handleTutorialAction(c, action, raise.Amount)
+		return nil
+	}
+
+	if rejectSpectator(c, baseMessage.Action) || rejectReadOnly(c, baseMessage.Action) || rejectFrozen(c, baseMessage.Action) {
+		return nil
+	}
+
 	switch baseMessage.Action {
 
 	case actionJoinTable:
@@ -213,6 +288,24 @@ func (c *Client) processEvents(rawMessage []byte) error {
 		handleSendMessage(c, message.Username, message.Message)
 		return nil
 
+	case actionMutePlayer:
+		var mute mutePlayer
+		err := json.Unmarshal(rawMessage, &mute)
+		if err != nil {
+			return err
+		}
+		handleMutePlayer(c, mute.UserID, mute.Minutes, mute.Reason)
+		return nil
+
+	case actionUnmutePlayer:
+		var unmute unmutePlayer
+		err := json.Unmarshal(rawMessage, &unmute)
+		if err != nil {
+			return err
+		}
+		handleUnmutePlayer(c, unmute.UserID)
+		return nil
+
 	case actionSendLog:
 		var log sendLog
 		err := json.Unmarshal(rawMessage, &log)
@@ -253,11 +346,21 @@ func (c *Client) processEvents(rawMessage []byte) error {
 		return nil
 
 	case actionPlayerCall:
-		handleCall(c)
+		var call playerCall
+		err := json.Unmarshal(rawMessage, &call)
+		if err != nil {
+			return err
+		}
+		sequencedAction(c, call.ActionToken, func() bool { return handleCall(c) })
 		return nil
 
 	case actionPlayerCheck:
-		handleCheck(c)
+		var check playerCheck
+		err := json.Unmarshal(rawMessage, &check)
+		if err != nil {
+			return err
+		}
+		sequencedAction(c, check.ActionToken, func() bool { return handleCheck(c) })
 		return nil
 
 	case actionPlayerRaise:
@@ -266,37 +369,121 @@ func (c *Client) processEvents(rawMessage []byte) error {
 		if err != nil {
 			return err
 		}
-		handleRaise(c, raise.Amount)
+		sequencedAction(c, raise.ActionToken, func() bool { return handleRaise(c, raise.Amount) })
 		return nil
 
 	case actionPlayerFold:
-		handleFold(c)
+		var fold playerFold
+		err := json.Unmarshal(rawMessage, &fold)
+		if err != nil {
+			return err
+		}
+		sequencedAction(c, fold.ActionToken, func() bool { return handleFold(c) })
 		return nil
 
 	case actionGetBalance:
 		handleGetBalance(c)
 		return nil
 
+	case actionClientHello:
+		var hello clientHello
+		err := json.Unmarshal(rawMessage, &hello)
+		if err != nil {
+			return err
+		}
+		handleClientHello(c, hello.Capabilities, hello.Version)
+		return nil
+
+	case actionTimeSync:
+		var clock timeSync
+		err := json.Unmarshal(rawMessage, &clock)
+		if err != nil {
+			return err
+		}
+		handleTimeSync(c, clock.ClientTime)
+		return nil
+
+	case actionSendDirectMessage:
+		var dm sendDirectMessage
+		err := json.Unmarshal(rawMessage, &dm)
+		if err != nil {
+			return err
+		}
+		handleSendDirectMessage(c, dm.RecipientID, dm.Message)
+		return nil
+
+	case actionPartialCashOut:
+		var cashOut partialCashOut
+		err := json.Unmarshal(rawMessage, &cashOut)
+		if err != nil {
+			return err
+		}
+		handlePartialCashOut(c, cashOut.Amount)
+		return nil
+
+	case actionSetTrainingMode:
+		var training setTrainingMode
+		err := json.Unmarshal(rawMessage, &training)
+		if err != nil {
+			return err
+		}
+		handleSetTrainingMode(c, training.Enabled)
+		return nil
+
+	case actionChooseGame:
+		var choice chooseGame
+		if err := json.Unmarshal(rawMessage, &choice); err != nil {
+			return err
+		}
+		handleChooseGame(c, choice.Game)
+		return nil
+
+	case actionSitIn:
+		handleSitIn(c)
+		return nil
+
+	case actionSitOut:
+		handleSitOut(c)
+		return nil
+
+	case actionStartTutorial:
+		handleStartTutorial(c)
+		return nil
+
+	case actionLeaveTutorial:
+		handleLeaveTutorial(c)
+		return nil
+
 	// Frontend compatibility actions (map to existing handlers)
 	case "call":
-		handleCall(c)
+		var call playerCall
+		if err := json.Unmarshal(rawMessage, &call); err != nil {
+			return err
+		}
+		sequencedAction(c, call.ActionToken, func() bool { return handleCall(c) })
 		return nil
 	case "check":
-		handleCheck(c)
+		var check playerCheck
+		if err := json.Unmarshal(rawMessage, &check); err != nil {
+			return err
+		}
+		sequencedAction(c, check.ActionToken, func() bool { return handleCheck(c) })
 		return nil
 	case "fold":
-		handleFold(c)
+		var fold playerFold
+		if err := json.Unmarshal(rawMessage, &fold); err != nil {
+			return err
+		}
+		sequencedAction(c, fold.ActionToken, func() bool { return handleFold(c) })
 		return nil
 	case "raise":
 		// Parse amount from message for raise
-		var raise struct {
-			Amount uint `json:"amount"`
-		}
+		var raise playerRaise
 		err := json.Unmarshal(rawMessage, &raise)
 		if err != nil {
 			return err
 		}
-		handleRaise(c, raise.Amount)
+		sequencedAction(c, raise.ActionToken, func() bool { return handleRaise(c, raise.Amount) })
 		return nil
 
 	default:
@@ -306,18 +493,21 @@ func (c *Client) processEvents(rawMessage []byte) error {
 
 // ServeWsWithAuth handles websocket requests with authentication and balance services
 func ServeWsWithAuth(hub *Hub, w http.ResponseWriter, r *http.Request, userID uuid.UUID, username string, formanceService *formance.Service, db *gorm.DB) {
-	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := hub.upgrader().Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := newClientWithAuth(conn, hub, userID, username, formanceService, db)
+	client := newClientWithAuth(conn, hub, userID, username, formanceService, db, capabilitiesFromRequest(r), protocolFromRequest(r))
+	client.correlationID = correlationIDFromRequest(r)
 
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
 	go client.writePump()
+	// A player back within the reconnect window is put straight back in
+	// their seat
+	hub.resumeSeat(client, "")
 	go client.readPump()
 }
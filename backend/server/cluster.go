@@ -0,0 +1,335 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+const (
+	clusterHeartbeatInterval = 10 * time.Second
+	// Instances that miss a few heartbeats are reported stale and lose
+	// their table leases to any instance still running those tables
+	clusterStaleAfter = 3 * clusterHeartbeatInterval
+	// Heartbeats are kept well past staleness so a crashed instance stays
+	// visible for a while before dropping off the list
+	clusterInstanceTTL = 30 * clusterHeartbeatInterval
+
+	clusterInstancePrefix = "cluster:instance:"
+	clusterLeasesKey      = "cluster:table-leases"
+)
+
+// ErrClusterUnavailable is returned when the hub has no Redis connection to
+// share state with other instances
+var ErrClusterUnavailable = errors.New("cluster registry requires Redis")
+
+// clusterState is this instance's view of which tables it owns. Leases map
+// each table to the instance that should host it, so the load balancer and
+// the move to several game servers have one place to look; instances hold
+// them while they heartbeat and admins can move them by hand.
+type clusterState struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	owned     map[string]bool
+}
+
+// noteOwnership records whether this instance holds a table's lease and logs
+// when that changes
+func (s *clusterState) noteOwnership(instanceID, table, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owned == nil {
+		s.owned = make(map[string]bool)
+	}
+
+	owned := owner == instanceID
+	if owned == s.owned[table] {
+		return
+	}
+	s.owned[table] = owned
+	if owned {
+		slog.Info("Table ownership lease acquired", "table", table, "instance", instanceID)
+	} else {
+		slog.Warn("Table ownership lease moved to another instance", "table", table, "instance", instanceID, "owner", owner)
+	}
+}
+
+// SetInstanceID names this server in the cluster registry
+func (h *Hub) SetInstanceID(id string) {
+	if id != "" {
+		h.instanceID = id
+	}
+}
+
+// runClusterHeartbeat reports this instance to the registry until the
+// process exits
+func (h *Hub) runClusterHeartbeat() {
+	ticker := time.NewTicker(clusterHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.clusterHeartbeat(ctx, time.Now()); err != nil {
+			slog.Warn("Cluster heartbeat failed", "instance", h.instanceID, "error", err)
+		}
+		<-ticker.C
+	}
+}
+
+// clusterHeartbeat publishes this instance's tables and health, taking the
+// lease of any table it runs that nobody live holds
+func (h *Hub) clusterHeartbeat(ctx context.Context, now time.Time) error {
+	instances, err := h.clusterInstances(ctx, now)
+	if err != nil {
+		return err
+	}
+	live := liveInstances(instances)
+	live[h.instanceID] = true
+
+	leases, err := h.rdb.HGetAll(ctx, clusterLeasesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read table leases: %w", err)
+	}
+
+	tables := h.clusterTables(now)
+	stalled := 0
+	for i := range tables {
+		name := tables[i].Name
+		owner := leases[name]
+		if !live[owner] {
+			if err := h.rdb.HSet(ctx, clusterLeasesKey, name, h.instanceID).Err(); err != nil {
+				return fmt.Errorf("failed to take table lease: %w", err)
+			}
+			owner = h.instanceID
+		}
+		tables[i].LeaseOwner = owner
+		h.cluster.noteOwnership(h.instanceID, name, owner)
+
+		if tables[i].Stalled {
+			stalled++
+		}
+	}
+
+	instance := models.ClusterInstance{
+		ID:                 h.instanceID,
+		StartedAt:          h.cluster.startedAt,
+		LastSeen:           now,
+		Clients:            h.connected.Load(),
+		StalledTables:      stalled,
+		SendQueueOverflows: h.sendMetrics.overflows.Load(),
+		Tables:             tables,
+	}
+	payload, err := json.Marshal(instance)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+	if err := h.rdb.Set(ctx, clusterInstancePrefix+h.instanceID, payload, clusterInstanceTTL).Err(); err != nil {
+		return fmt.Errorf("failed to publish heartbeat: %w", err)
+	}
+	return nil
+}
+
+// claimTableLease takes the lease of a newly opened table unless another
+// instance already holds it
+func (h *Hub) claimTableLease(name string) {
+	if h.rdb == nil {
+		return
+	}
+
+	if _, err := h.rdb.HSetNX(ctx, clusterLeasesKey, name, h.instanceID).Result(); err != nil {
+		slog.Warn("Failed to claim table lease", "table", name, "instance", h.instanceID, "error", err)
+		return
+	}
+	owner, err := h.rdb.HGet(ctx, clusterLeasesKey, name).Result()
+	if err != nil {
+		slog.Warn("Failed to read table lease", "table", name, "error", err)
+		return
+	}
+	h.cluster.noteOwnership(h.instanceID, name, owner)
+}
+
+// tableLeasedElsewhere reports whether another live instance holds the lease
+// of the table with the ID, so the table may still be running there. When
+// the registry can't be read it reports true: chips are left alone rather
+// than taken from a table that might still be playing.
+func (h *Hub) tableLeasedElsewhere(tableID uuid.UUID) bool {
+	if h.rdb == nil || h.tableService == nil {
+		return false
+	}
+
+	record, err := h.tableService.GetTableByID(ctx, tableID)
+	if errors.Is(err, services.ErrTableNotFound) {
+		return false
+	}
+	if err != nil {
+		slog.Warn("Failed to look up table for its lease", "table_id", tableID, "error", err)
+		return true
+	}
+	instances, err := h.clusterInstances(ctx, time.Now())
+	if err != nil {
+		slog.Warn("Failed to read cluster instances", "table", record.Name, "error", err)
+		return true
+	}
+	leases, err := h.rdb.HGetAll(ctx, clusterLeasesKey).Result()
+	if err != nil {
+		slog.Warn("Failed to read table leases", "table", record.Name, "error", err)
+		return true
+	}
+	return leaseHeldElsewhere(leases, liveInstances(instances), h.instanceID, record.Name)
+}
+
+// leaseHeldElsewhere reports whether a live instance other than self holds
+// the table's lease
+func leaseHeldElsewhere(leases map[string]string, live map[string]bool, self, table string) bool {
+	owner, ok := leases[table]
+	return ok && owner != self && live[owner]
+}
+
+// clusterTables summarises the tables running on this instance
+func (h *Hub) clusterTables(now time.Time) []models.ClusterTable {
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	summaries := make([]models.ClusterTable, len(tables))
+	for i, t := range tables {
+		s := t.sample(now)
+		summaries[i] = models.ClusterTable{
+			Name:        s.name,
+			Clients:     s.clients,
+			Seated:      s.seated,
+			HandRunning: s.running,
+			Stalled:     s.autoStartStalled || s.actionStalled,
+			ReadOnly:    s.readOnly,
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries
+}
+
+// clusterInstances reads every instance's last heartbeat
+func (h *Hub) clusterInstances(ctx context.Context, now time.Time) ([]models.ClusterInstance, error) {
+	var keys []string
+	iter := h.rdb.Scan(ctx, 0, clusterInstancePrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	if len(keys) == 0 {
+		return []models.ClusterInstance{}, nil
+	}
+
+	values, err := h.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instances: %w", err)
+	}
+
+	instances := make([]models.ClusterInstance, 0, len(values))
+	for i, value := range values {
+		payload, ok := value.(string)
+		if !ok {
+			continue // Expired between the scan and the read
+		}
+		var instance models.ClusterInstance
+		if err := json.Unmarshal([]byte(payload), &instance); err != nil {
+			slog.Warn("Skipping unreadable instance heartbeat", "key", keys[i], "error", err)
+			continue
+		}
+		instance.Status = instanceStatus(instance, now)
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances, nil
+}
+
+// ClusterStatus lists the instances sharing this Redis, this one as it is
+// right now and the others as of their last heartbeat, with table leases
+func (h *Hub) ClusterStatus(ctx context.Context) (models.ClusterStatus, error) {
+	if h.rdb == nil {
+		return models.ClusterStatus{}, ErrClusterUnavailable
+	}
+
+	now := time.Now()
+	if err := h.clusterHeartbeat(ctx, now); err != nil {
+		return models.ClusterStatus{}, err
+	}
+	instances, err := h.clusterInstances(ctx, now)
+	if err != nil {
+		return models.ClusterStatus{}, err
+	}
+	leases, err := h.rdb.HGetAll(ctx, clusterLeasesKey).Result()
+	if err != nil {
+		return models.ClusterStatus{}, fmt.Errorf("failed to read table leases: %w", err)
+	}
+
+	live := liveInstances(instances)
+	orphaned := []string{}
+	for table, owner := range leases {
+		if !live[owner] {
+			orphaned = append(orphaned, table)
+		}
+	}
+	sort.Strings(orphaned)
+
+	return models.ClusterStatus{
+		Instance:  h.instanceID,
+		Instances: instances,
+		Leases:    leases,
+		Orphaned:  orphaned,
+	}, nil
+}
+
+// ReassignTable hands a table's ownership lease to another instance. The
+// previous owner notices at its next heartbeat.
+func (h *Hub) ReassignTable(ctx context.Context, table, instanceID string) error {
+	if h.rdb == nil {
+		return ErrClusterUnavailable
+	}
+
+	previous, err := h.rdb.HGet(ctx, clusterLeasesKey, table).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read table lease: %w", err)
+	}
+	if err := h.rdb.HSet(ctx, clusterLeasesKey, table, instanceID).Err(); err != nil {
+		return fmt.Errorf("failed to reassign table lease: %w", err)
+	}
+
+	slog.Warn("Table ownership lease reassigned", "table", table, "from", previous, "to", instanceID, "via", h.instanceID)
+	h.cluster.noteOwnership(h.instanceID, table, instanceID)
+	return nil
+}
+
+func instanceStatus(instance models.ClusterInstance, now time.Time) string {
+	switch {
+	case now.Sub(instance.LastSeen) > clusterStaleAfter:
+		return models.ClusterInstanceStale
+	case instance.StalledTables > 0:
+		return models.ClusterInstanceDegraded
+	default:
+		return models.ClusterInstanceHealthy
+	}
+}
+
+func liveInstances(instances []models.ClusterInstance) map[string]bool {
+	live := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if instance.Status != models.ClusterInstanceStale {
+			live[instance.ID] = true
+		}
+	}
+	return live
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaseHeldElsewhere(t *testing.T) {
+	now := time.Now()
+	instances := []models.ClusterInstance{
+		{ID: "game-1", LastSeen: now},
+		{ID: "game-2", LastSeen: now.Add(-time.Second)},
+		{ID: "game-3", LastSeen: now.Add(-2 * clusterStaleAfter)},
+	}
+	for i := range instances {
+		instances[i].Status = instanceStatus(instances[i], now)
+	}
+	live := liveInstances(instances)
+	leases := map[string]string{
+		"mine":    "game-1",
+		"theirs":  "game-2",
+		"crashed": "game-3",
+	}
+
+	assert.False(t, leaseHeldElsewhere(leases, live, "game-1", "mine"), "held by this instance")
+	assert.True(t, leaseHeldElsewhere(leases, live, "game-1", "theirs"), "held by a live instance")
+	assert.False(t, leaseHeldElsewhere(leases, live, "game-1", "crashed"), "held by a stale instance")
+	assert.False(t, leaseHeldElsewhere(leases, live, "game-1", "unleased"), "never leased")
+}
+
+func TestHasLiveSession_WithoutCluster(t *testing.T) {
+	h := &Hub{}
+	userID, tableID, sessionID := uuid.New(), uuid.New(), uuid.New()
+
+	// A single instance that doesn't run the table has the only say
+	assert.False(t, h.HasLiveSession(userID, tableID, sessionID))
+
+	h.userClients = map[uuid.UUID]map[*Client]bool{
+		userID: {{userID: userID, sessionID: sessionID, table: &table{}}: true},
+	}
+	assert.True(t, h.HasLiveSession(userID, tableID, sessionID), "still connected with the session")
+	assert.False(t, h.HasLiveSession(userID, tableID, uuid.New()), "connected with another session")
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+const (
+	// commandDedupeTTL is how long a connection remembers a command ID, long
+	// enough to cover a client's retries of the same frame
+	commandDedupeTTL = 2 * time.Minute
+	// commandDedupeSize bounds the IDs remembered per connection
+	commandDedupeSize = 256
+	// maxCommandIDLength keeps a client from filling the cache with large keys
+	maxCommandIDLength = 64
+)
+
+// command carries the ID a client gives each request so the server can drop
+// a retried frame instead of applying it again
+type command struct {
+	CommandID string `json:"command_id,omitempty"`
+}
+
+// commandDuplicate tells the client a command was already received on this
+// connection and has not been applied again
+type commandDuplicate struct {
+	base             // actionCommandDuplicate
+	CommandID string `json:"command_id"`
+}
+
+// commandCache remembers the command IDs a connection has sent recently. It
+// is only used from the connection's read pump, so it needs no lock.
+type commandCache struct {
+	seen map[string]time.Time
+}
+
+// firstSeen records id and reports whether it is new. IDs expire after
+// commandDedupeTTL; when the cache is full the oldest is forgotten.
+func (cc *commandCache) firstSeen(id string, now time.Time) bool {
+	if cc.seen == nil {
+		cc.seen = make(map[string]time.Time)
+	}
+	if seenAt, ok := cc.seen[id]; ok && now.Sub(seenAt) < commandDedupeTTL {
+		return false
+	}
+
+	if len(cc.seen) >= commandDedupeSize {
+		oldestID, oldestAt := "", now
+		for seenID, seenAt := range cc.seen {
+			if now.Sub(seenAt) >= commandDedupeTTL {
+				delete(cc.seen, seenID)
+				continue
+			}
+			if seenAt.Before(oldestAt) {
+				oldestID, oldestAt = seenID, seenAt
+			}
+		}
+		if len(cc.seen) >= commandDedupeSize {
+			delete(cc.seen, oldestID)
+		}
+	}
+
+	cc.seen[id] = now
+	return true
+}
+
+// duplicateCommand reports whether the message repeats a command this
+// connection has already sent, answering the client if it does. Messages
+// without a command ID are never treated as duplicates.
+func (c *Client) duplicateCommand(rawMessage []byte, action string) bool {
+	var cmd command
+	if err := json.Unmarshal(rawMessage, &cmd); err != nil || cmd.CommandID == "" || len(cmd.CommandID) > maxCommandIDLength {
+		return false
+	}
+	if c.commands.firstSeen(cmd.CommandID, time.Now()) {
+		return false
+	}
+
+	slog.Debug("Dropping duplicate websocket command", "user_id", c.userID, "action", action, "command_id", cmd.CommandID)
+	safeSend(c, createCommandDuplicate(cmd.CommandID))
+	return true
+}
+
+func createCommandDuplicate(commandID string) []byte {
+	resp, err := json.Marshal(commandDuplicate{
+		base:      base{actionCommandDuplicate},
+		CommandID: commandID,
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal command duplicate", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandCache_FirstSeen(t *testing.T) {
+	now := time.Now()
+
+	t.Run("duplicate within the TTL", func(t *testing.T) {
+		var cc commandCache
+		assert.True(t, cc.firstSeen("cmd-1", now))
+		assert.False(t, cc.firstSeen("cmd-1", now.Add(commandDedupeTTL-time.Second)))
+		assert.True(t, cc.firstSeen("cmd-2", now), "other IDs are still new")
+	})
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		var cc commandCache
+		assert.True(t, cc.firstSeen("cmd-1", now))
+		assert.True(t, cc.firstSeen("cmd-1", now.Add(commandDedupeTTL)))
+		assert.False(t, cc.firstSeen("cmd-1", now.Add(commandDedupeTTL+time.Second)), "seen again from the retry")
+	})
+
+	t.Run("evicts the oldest when full", func(t *testing.T) {
+		var cc commandCache
+		for i := 0; i < commandDedupeSize; i++ {
+			assert.True(t, cc.firstSeen(fmt.Sprintf("cmd-%d", i), now.Add(time.Duration(i)*time.Millisecond)))
+		}
+		later := now.Add(time.Second)
+		assert.True(t, cc.firstSeen("cmd-new", later))
+		assert.Len(t, cc.seen, commandDedupeSize)
+
+		assert.False(t, cc.firstSeen("cmd-1", later), "newer IDs are kept")
+		assert.True(t, cc.firstSeen("cmd-0", later), "the oldest was forgotten")
+	})
+
+	t.Run("drops expired IDs before evicting", func(t *testing.T) {
+		var cc commandCache
+		for i := 0; i < commandDedupeSize; i++ {
+			cc.firstSeen(fmt.Sprintf("cmd-%d", i), now)
+		}
+		assert.True(t, cc.firstSeen("cmd-new", now.Add(commandDedupeTTL)))
+		assert.Len(t, cc.seen, 1)
+	})
+}
+
+func TestClient_DuplicateCommand(t *testing.T) {
+	c := &Client{send: newSendQueue(0, nil)}
+	message := func(id string) []byte {
+		return []byte(fmt.Sprintf(`{"action":"player-call","command_id":%q}`, id))
+	}
+
+	assert.False(t, c.duplicateCommand(message("cmd-1"), "player-call"))
+	assert.True(t, c.duplicateCommand(message("cmd-1"), "player-call"))
+
+	reply, ok := c.send.pop()
+	require.True(t, ok, "the client is told its command was dropped")
+	assert.Contains(t, string(reply), `"command_id":"cmd-1"`)
+
+	assert.False(t, c.duplicateCommand([]byte(`{"action":"player-call"}`), "player-call"), "no command ID")
+	assert.False(t, c.duplicateCommand([]byte(`{"action":"player-call"}`), "player-call"))
+
+	oversized := strings.Repeat("x", maxCommandIDLength+1)
+	assert.False(t, c.duplicateCommand(message(oversized), "player-call"))
+	assert.False(t, c.duplicateCommand(message(oversized), "player-call"), "oversized IDs are never deduplicated")
+	assert.NotContains(t, c.commands.seen, oversized, "oversized IDs are not remembered")
+}
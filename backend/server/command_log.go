@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// loggedCommand is what the audit log keeps of a WebSocket command. The rest
+// of the payload, chat text included, is never logged.
+type loggedCommand struct {
+	Action    string `json:"action"`
+	CommandID string `json:"command_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"` // Protocol 2 envelopes only
+}
+
+// correlationIDFromRequest returns the ID the upgrade request was logged
+// under, so a connection's commands can be traced back to it
+func correlationIDFromRequest(r *http.Request) string {
+	return middleware.GetReqID(r.Context())
+}
+
+// logCommand writes a structured entry for a processed WebSocket command
+func (c *Client) logCommand(message []byte, start time.Time, err error) {
+	var cmd loggedCommand
+	_ = json.Unmarshal(message, &cmd)
+
+	attrs := []slog.Attr{
+		slog.String("correlation_id", c.correlationID),
+		slog.String("user_id", c.userID.String()),
+		slog.String("action", cmd.Action),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if cmd.CommandID != "" && len(cmd.CommandID) <= maxCommandIDLength {
+		attrs = append(attrs, slog.String("command_id", cmd.CommandID))
+	}
+	if cmd.RequestID != "" && len(cmd.RequestID) <= maxRequestIDLength {
+		attrs = append(attrs, slog.String("request_id", cmd.RequestID))
+	}
+	if c.table != nil {
+		attrs = append(attrs, slog.String("table", c.table.name))
+	}
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, level, "WebSocket command", attrs...)
+}
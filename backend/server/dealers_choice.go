@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// gameChoiceTimeout is how long the button has to choose the next hand's game
+// before the current game is dealt again
+const gameChoiceTimeout = 15 * time.Second
+
+// variantNames are the games as shown to players
+var variantNames = map[poker.Variant]string{
+	poker.VariantHoldem:    "Hold'em",
+	poker.VariantOmaha:     "Pot Limit Omaha",
+	poker.VariantShortDeck: "Short Deck",
+}
+
+// gameChoiceState tracks the button's choice of game at a dealer's choice
+// table. A choice is pending while chooser is set.
+type gameChoiceState struct {
+	mu      sync.Mutex
+	chooser uuid.UUID
+	games   []poker.Variant
+	done    chan struct{}
+	// Set once the button has been asked, after which the table's game type
+	// no longer decides what is dealt
+	prompted bool
+}
+
+// tableVariant maps a table's game type to the game dealt. Game types the
+// engine can't deal are played as Hold'em.
+func tableVariant(gameType string) poker.Variant {
+	if v := poker.Variant(gameType); v != "" && v.Valid() {
+		return v
+	}
+	return poker.VariantHoldem
+}
+
+// applyTableGame deals the table's game type from the next hand, unless the
+// button at a dealer's choice table has taken over. Whether pots are split
+// hi-lo goes with the table whatever game is dealt.
+func (t *table) applyTableGame(policy tablePolicy) {
+	s := &t.gameChoice
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	legacyGame := t.game.GetLegacyGame()
+	if legacyGame.HiLo() != policy.hiLo {
+		// Refused while a hand is running; the next refresh between hands applies it
+		if err := legacyGame.SetHiLo(policy.hiLo); err == nil {
+			slog.Info("Table hi-lo split changed", "table", t.name, "hi_lo", policy.hiLo)
+		}
+	}
+
+	if len(policy.games) > 0 && s.prompted {
+		return
+	}
+	s.prompted = false
+	if legacyGame.Variant() == policy.gameType {
+		return
+	}
+	// Refused while a hand is running; the next refresh between hands applies it
+	if err := legacyGame.SetVariant(policy.gameType); err == nil {
+		slog.Info("Table game changed", "table", t.name, "game", policy.gameType)
+	}
+}
+
+// awaitGameChoice asks the player on the button to choose the next hand's
+// game at a dealer's choice table, and waits until they do or the time for
+// choosing runs out
+func (t *table) awaitGameChoice() {
+	t.callTime.mu.Lock()
+	games := t.callTime.policy.games
+	t.callTime.mu.Unlock()
+
+	// The engine only deals Hold'em
+	if len(games) == 0 || t.executionPath() == executionEngine {
+		return
+	}
+	engineView, ok := getEngineView(t.game.GenerateOmniView())
+	if !ok || int(engineView.DealerNum) >= len(engineView.Players) {
+		return
+	}
+	chooser := t.seatUser(engineView.DealerNum)
+	if chooser == uuid.Nil {
+		return
+	}
+	current := t.game.GetLegacyGame().Variant()
+
+	done := make(chan struct{})
+	s := &t.gameChoice
+	s.mu.Lock()
+	s.chooser = chooser
+	s.games = games
+	s.done = done
+	s.prompted = true
+	s.mu.Unlock()
+
+	handID := t.game.CurrentHandID()
+	username := engineView.Players[engineView.DealerNum].Username
+	t.broadcast <- createGameChoicePrompt(chooser, engineView.DealerNum, games, current)
+	t.broadcast <- createNewLog(handID, fmt.Sprintf("Dealer's choice: %s is choosing the next game", username))
+
+	select {
+	case <-done:
+	case <-time.After(gameChoiceTimeout):
+		t.broadcast <- createNewLog(handID, fmt.Sprintf("No game chosen, dealing %s again", variantNames[current]))
+	}
+
+	s.mu.Lock()
+	s.chooser = uuid.Nil
+	s.done = nil
+	s.mu.Unlock()
+}
+
+// handleChooseGame applies the button's choice of game for the next hand
+func handleChooseGame(c *Client, game string) {
+	t := c.table
+	if t == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeGameChoiceRejected, "Join a table before choosing a game"))
+		return
+	}
+
+	s := &t.gameChoice
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chooser == uuid.Nil || s.chooser != c.userID {
+		safeSend(c, createCodedErrorMessage(errorCodeGameChoiceRejected, "It is not your turn to choose the game"))
+		return
+	}
+	variant := poker.Variant(game)
+	if !slices.Contains(s.games, variant) {
+		safeSend(c, createCodedErrorMessage(errorCodeGameChoiceRejected, "That game is not played at this table"))
+		return
+	}
+	if err := t.game.GetLegacyGame().SetVariant(variant); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeGameChoiceRejected, "The game can only be chosen between hands"))
+		return
+	}
+
+	close(s.done)
+	s.chooser = uuid.Nil
+	slog.Info("Dealer's choice game chosen", "table", t.name, "user_id", c.userID, "game", variant)
+	t.broadcast <- createNewLog(t.game.CurrentHandID(), fmt.Sprintf("%s chose %s for the next hand", c.username, variantNames[variant]))
+	t.broadcast <- createUpdatedGame(c)
+}
+
+func createGameChoicePrompt(chooser uuid.UUID, seat uint, games []poker.Variant, current poker.Variant) []byte {
+	prompt := gameChoicePrompt{
+		base:           base{actionGameChoicePrompt},
+		UserID:         chooser.String(),
+		Seat:           seat,
+		Current:        string(current),
+		TimeoutSeconds: int(gameChoiceTimeout / time.Second),
+	}
+	for _, game := range games {
+		prompt.Games = append(prompt.Games, string(game))
+	}
+
+	resp, err := json.Marshal(prompt)
+	if err != nil {
+		slog.Default().Warn("Marshal game choice prompt", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// directMessageChannel carries direct messages between server instances
+const directMessageChannel = "direct-messages"
+
+// directMessageEnvelope wraps an outbound message with the users it is for
+type directMessageEnvelope struct {
+	UserIDs []uuid.UUID     `json:"user_ids"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// trackUserClient indexes an authenticated client by user so direct messages
+// reach every connection of that user, whether or not they are at a table
+func (h *Hub) trackUserClient(client *Client) {
+	if client.userID == uuid.Nil {
+		return
+	}
+	h.usersMu.Lock()
+	defer h.usersMu.Unlock()
+
+	if h.userClients[client.userID] == nil {
+		h.userClients[client.userID] = make(map[*Client]bool)
+	}
+	h.userClients[client.userID][client] = true
+}
+
+func (h *Hub) untrackUserClient(client *Client) {
+	if client.userID == uuid.Nil {
+		return
+	}
+	h.usersMu.Lock()
+	defer h.usersMu.Unlock()
+
+	delete(h.userClients[client.userID], client)
+	if len(h.userClients[client.userID]) == 0 {
+		delete(h.userClients, client.userID)
+	}
+}
+
+// IsOnline reports whether the user has a connection to this server
+func (h *Hub) IsOnline(userID uuid.UUID) bool {
+	h.usersMu.RLock()
+	defer h.usersMu.RUnlock()
+	return len(h.userClients[userID]) > 0
+}
+
+// deliverToUsers sends a message to every local connection of the given users
+func (h *Hub) deliverToUsers(userIDs []uuid.UUID, message []byte) {
+	h.usersMu.RLock()
+	defer h.usersMu.RUnlock()
+
+	for _, userID := range userIDs {
+		for client := range h.userClients[userID] {
+			safeSend(client, message)
+		}
+	}
+}
+
+// publishToUsers delivers a message to users on every server instance
+func (h *Hub) publishToUsers(userIDs []uuid.UUID, message []byte) {
+	if h.rdb == nil {
+		h.deliverToUsers(userIDs, message)
+		return
+	}
+
+	envelope, err := json.Marshal(directMessageEnvelope{UserIDs: userIDs, Payload: message})
+	if err != nil {
+		slog.Warn("Marshal direct message envelope", "error", err)
+		return
+	}
+	if err := h.rdb.Publish(ctx, directMessageChannel, envelope).Err(); err != nil {
+		slog.Warn("Failed to publish direct message, delivering locally", "error", err)
+		h.deliverToUsers(userIDs, message)
+	}
+}
+
+func (h *Hub) subscribeDirectMessages() {
+	pubsub := h.rdb.Subscribe(ctx, directMessageChannel)
+	ch := pubsub.Channel()
+
+	for msg := range ch {
+		var envelope directMessageEnvelope
+		if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+			slog.Warn("Unmarshal direct message envelope", "error", err)
+			continue
+		}
+		h.deliverToUsers(envelope.UserIDs, envelope.Payload)
+	}
+}
+
+// handleSendDirectMessage stores a direct message and delivers it to the
+// recipient and to the sender's other connections
+func handleSendDirectMessage(c *Client, recipient string, message string) {
+	if c.userID == uuid.Nil {
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required to send direct messages"))
+		return
+	}
+	if c.hub.directMessages == nil {
+		safeSend(c, createErrorMessage("Direct messages are unavailable"))
+		return
+	}
+
+	recipientID, err := uuid.Parse(recipient)
+	if err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, "Invalid recipient"))
+		return
+	}
+
+	if !moderateChat(c, models.ChatChannelDirect, recipientID.String(), message) {
+		return
+	}
+
+	dm, err := c.hub.directMessages.SendMessage(ctx, c.userID, recipientID, message)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMessageThrottled):
+			safeSend(c, createCodedErrorMessage(errorCodeRateLimited, "You are sending messages too quickly"))
+		case errors.Is(err, services.ErrMessageBlocked):
+			safeSend(c, createCodedErrorMessage(errorCodeMessageBlocked, "You can't message this player"))
+		case errors.Is(err, services.ErrEmptyMessage), errors.Is(err, services.ErrMessageTooLong),
+			errors.Is(err, services.ErrCannotMessageSelf), errors.Is(err, services.ErrRecipientNotFound):
+			safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, err.Error()))
+		default:
+			slog.Warn("Failed to send direct message", "sender_id", c.userID, "recipient_id", recipientID, "error", err)
+			safeSend(c, createErrorMessage("Failed to send message"))
+		}
+		return
+	}
+
+	c.hub.publishToUsers([]uuid.UUID{dm.RecipientID, dm.SenderID}, createNewDirectMessage(dm.ID, dm.SenderID, c.username, dm.RecipientID, dm.Body, dm.CreatedAt))
+}
+
+func createNewDirectMessage(id, senderID uuid.UUID, senderUsername string, recipientID uuid.UUID, body string, sentAt time.Time) []byte {
+	message := newDirectMessage{
+		base{actionNewDirectMessage},
+		id.String(),
+		senderID.String(),
+		senderUsername,
+		recipientID.String(),
+		body,
+		sentAt.Format(time.RFC3339),
+	}
+
+	resp, err := json.Marshal(message)
+	if err != nil {
+		slog.Default().Warn("Marshal new direct message", "error", err)
+	}
+	return resp
+}
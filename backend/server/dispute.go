@@ -0,0 +1,267 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+const handFrozenMessage = "This hand is paused while a moderator reviews it. Play resumes once it is resolved."
+
+// disputeState freezes a table's hand in place while a moderator looks into
+// a dispute. No betting action, and nothing read-only mode refuses, is
+// accepted until the hand is resumed or settled by a ruling.
+type disputeState struct {
+	mu     sync.RWMutex
+	frozen bool
+	handID string
+	reason string
+	since  time.Time
+}
+
+func (s *disputeState) freeze(handID, reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frozen {
+		return false
+	}
+	s.frozen, s.handID, s.reason, s.since = true, handID, reason, time.Now()
+	return true
+}
+
+func (s *disputeState) clear() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.frozen
+	*s = disputeState{}
+	return changed
+}
+
+func (s *disputeState) get() (bool, string, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.frozen, s.reason, s.since
+}
+
+// isHandFrozen reports whether the hand in progress is frozen for a dispute
+func (t *table) isHandFrozen() bool {
+	frozen, _, _ := t.dispute.get()
+	return frozen
+}
+
+// rejectFrozen answers an action read-only mode would block with a dispute
+// error while the hand is frozen, and reports whether it was blocked.
+// Betting actions are refused by sequencedAction.
+func rejectFrozen(c *Client, action string) bool {
+	if c.table == nil || !readOnlyActions[action] || !c.table.isHandFrozen() {
+		return false
+	}
+	safeSend(c, createCodedErrorMessage(errorCodeHandFrozen, handFrozenMessage))
+	return true
+}
+
+// FreezeHand stops the hand in progress at a table where it stands: nobody
+// can act until a moderator resumes it or rules on it
+func (h *Hub) FreezeHand(name, reason string) error {
+	t := h.findTableByName(name)
+	if t == nil {
+		return ErrTableNotFound
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	if !t.game.GetLegacyGame().GenerateOmniView().Running {
+		return services.ErrNoHandInProgress
+	}
+	handID := t.game.CurrentHandID()
+	if !t.dispute.freeze(handID, reason) {
+		return nil
+	}
+
+	slog.Warn("Hand frozen for dispute", "table", t.name, "hand_id", handID, "reason", reason)
+	t.transition(models.TableStatePaused, reasonHandFrozen)
+	t.broadcast <- createNewLog(handID, handFrozenMessage)
+	return nil
+}
+
+// ResumeHand lets a frozen hand carry on from where it was stopped
+func (h *Hub) ResumeHand(name string) error {
+	t := h.findTableByName(name)
+	if t == nil {
+		return ErrTableNotFound
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	if !t.dispute.clear() {
+		return services.ErrHandNotFrozen
+	}
+
+	handID := t.game.CurrentHandID()
+	slog.Warn("Frozen hand resumed", "table", t.name, "hand_id", handID)
+	t.transition(models.TableStateHandRunning, reasonHandResumed)
+	t.broadcast <- createNewLog(handID, "The moderator has resumed the hand. Play continues.")
+	t.announceTurnLocked()
+	t.scheduleTurnNudge()
+	return nil
+}
+
+// InspectHand returns the full state of a table's hand, every hole card
+// included, for a moderator looking into a dispute
+func (h *Hub) InspectHand(name string) (*models.DisputedHand, error) {
+	t := h.findTableByName(name)
+	if t == nil {
+		return nil, ErrTableNotFound
+	}
+
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	frozen, reason, since := t.dispute.get()
+
+	hand := &models.DisputedHand{
+		Table:        t.name,
+		HandID:       t.game.CurrentHandID(),
+		Running:      view.Running,
+		Frozen:       frozen,
+		FrozenReason: reason,
+		Stage:        streetName(view.Stage),
+		Board:        dealtBoard(view.CommunityCards),
+		Players:      make([]models.DisputedHandSeat, 0, len(view.Players)),
+	}
+	if frozen {
+		hand.FrozenAt = &since
+	}
+	if view.Running && view.Betting {
+		actionOn := view.ActionNum
+		hand.ActionOn = &actionOn
+	}
+
+	for i, p := range view.Players {
+		userID, _ := uuid.Parse(p.UUID)
+		seat := models.DisputedHandSeat{
+			Position: uint(i),
+			SeatID:   p.SeatID,
+			UserID:   userID,
+			Username: p.Username,
+			In:       p.In,
+			Stack:    int64(p.Stack),
+			Bet:      int64(p.Bet),
+			TotalBet: int64(p.TotalBet + p.Ante),
+		}
+		if view.Running && len(p.Cards) > 0 && p.Cards[0] != 0 {
+			for _, card := range p.Cards {
+				seat.Cards = append(seat.Cards, card.String())
+			}
+		}
+		hand.Pot += seat.TotalBet
+		hand.Players = append(hand.Players, seat)
+	}
+	return hand, nil
+}
+
+// AdjudicateHand settles a frozen hand by a moderator's ruling. An award
+// gives every chip bet on the hand to the players at positions and pays them
+// as a played hand would; a void returns every bet, as a misdeal does. The
+// hand is then closed in its history and the next one scheduled. The
+// returned record still needs the moderator and reason filled in before it
+// goes to the audit log.
+func (h *Hub) AdjudicateHand(name, outcome string, positions []uint) (*models.HandAdjudication, error) {
+	t := h.findTableByName(name)
+	if t == nil {
+		return nil, ErrTableNotFound
+	}
+	// Rulings are applied to the legacy game, which the engine would not see
+	if t.executionPath() == executionEngine {
+		return nil, services.ErrRulingNotAllowed
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	frozen, _, since := t.dispute.get()
+	if !frozen {
+		return nil, services.ErrHandNotFrozen
+	}
+
+	game := t.game.GetLegacyGame()
+	handID := t.game.CurrentHandID()
+	before := game.GenerateOmniView()
+
+	adjudication := &models.HandAdjudication{
+		HandID:    handID,
+		TableName: t.name,
+		Outcome:   outcome,
+		FrozenAt:  since,
+	}
+	var payouts []models.HandPayout
+	payout := func(num uint, amount uint) {
+		p := before.Players[num]
+		userID, _ := uuid.Parse(p.UUID)
+		payouts = append(payouts, models.HandPayout{Position: num, UserID: userID, Username: p.Username, Amount: int64(amount)})
+		adjudication.TotalPot += int64(amount)
+	}
+
+	switch outcome {
+	case models.HandRulingVoid:
+		if len(positions) > 0 {
+			return nil, services.ErrInvalidRuling
+		}
+		if err := game.VoidHand(poker.MisdealManual, "voided by a moderator after a dispute"); err != nil {
+			return nil, services.ErrNoHandInProgress
+		}
+		for i, p := range before.Players {
+			if refund := p.TotalBet + p.Ante; refund > 0 {
+				payout(uint(i), refund)
+			}
+		}
+		t.dispute.clear()
+		handleMisdeal(t)
+
+	case models.HandRulingAward:
+		if err := game.AwardHand(positions); err != nil {
+			return nil, services.ErrInvalidRuling
+		}
+		awards := game.GenerateOmniView().Pots[0].Awards
+		for _, num := range positions {
+			payout(num, awards[num])
+		}
+		t.dispute.clear()
+		handlePotDistribution(t.settlementClient())
+
+	default:
+		return nil, services.ErrInvalidRuling
+	}
+
+	sort.Slice(payouts, func(i, j int) bool { return payouts[i].Position < payouts[j].Position })
+	encoded, err := json.Marshal(payouts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payouts: %w", err)
+	}
+	adjudication.Payouts = encoded
+
+	slog.Warn("Disputed hand adjudicated", "table", t.name, "hand_id", handID, "outcome", outcome, "payouts", payouts)
+	t.broadcast <- createNewLog(handID, "The moderator has ruled on the disputed hand.")
+	t.broadcast <- createTableUpdate(t)
+	t.announceTurnLocked()
+	return adjudication, nil
+}
+
+// settlementClient is the client pot distribution runs as when no player
+// action ended the hand. Winners are still paid through their own clients.
+func (t *table) settlementClient() *Client {
+	for _, client := range t.connectedClients() {
+		if client != nil && client.table == t {
+			return client
+		}
+	}
+	return &Client{table: t}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTableExists is returned when creating a table that is already running
+	ErrTableExists = errors.New("table already exists")
+	// ErrUnknownAction is returned for an action other than call, check, fold or raise
+	ErrUnknownAction = errors.New("unknown action")
+	// ErrUserNotFound is returned when seating a user that does not exist or
+	// can no longer sign in
+	ErrUserNotFound = errors.New("user not found")
+	// ErrStreamBehind is returned when a state stream stops reading for long
+	// enough that its queue fills with messages that can't be shed
+	ErrStreamBehind = errors.New("state stream fell behind")
+)
+
+// CommandError is a command the table refused, with the code and message a
+// WebSocket client would have been sent
+type CommandError struct {
+	Code    string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// engineActions are the betting actions other services may submit, by the
+// WebSocket action they are played as
+var engineActions = map[string]string{
+	"call":  actionPlayerCall,
+	"check": actionPlayerCheck,
+	"fold":  actionPlayerFold,
+	"raise": actionPlayerRaise,
+}
+
+// EngineService lets other services, such as matchmakers and dedicated game
+// servers, drive the hub's tables without a WebSocket connection. Every
+// command is played through a client with no connection, as practice bots
+// are, so it takes the same path and passes the same checks as a player's.
+type EngineService struct {
+	hub             *Hub
+	formanceService *formance.Service
+	db              *gorm.DB
+}
+
+func NewEngineService(hub *Hub, formanceService *formance.Service, db *gorm.DB) *EngineService {
+	return &EngineService{
+		hub:             hub,
+		formanceService: formanceService,
+		db:              db,
+	}
+}
+
+// CreateTable opens a table under the given name
+func (e *EngineService) CreateTable(ctx context.Context, name string) error {
+	if e.hub.findTableByName(name) != nil {
+		return ErrTableExists
+	}
+	e.hub.createTable(name)
+	slog.Info("Table created through the engine API", "table", name)
+	return nil
+}
+
+// SeatPlayer buys a user in at a running table from their wallet, exactly
+// as taking a seat over the WebSocket does
+func (e *EngineService) SeatPlayer(ctx context.Context, tableName string, userID uuid.UUID, seatID, buyIn uint) error {
+	t := e.hub.findTableByName(tableName)
+	if t == nil {
+		return ErrTableNotFound
+	}
+
+	var user models.User
+	if err := e.db.WithContext(ctx).Where("id = ? AND disabled_at IS NULL", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	c := e.client(t, user.ID, user.Username)
+	return e.dispatch(c, takeSeat{base{actionTakeSeat}, user.Username, seatID, buyIn})
+}
+
+// SubmitAction plays a betting action for a seated user. A non-empty
+// action token must match the turn being acted on.
+func (e *EngineService) SubmitAction(ctx context.Context, tableName string, userID uuid.UUID, action string, amount uint, actionToken string) error {
+	wsAction, ok := engineActions[action]
+	if !ok {
+		return ErrUnknownAction
+	}
+	t := e.hub.findTableByName(tableName)
+	if t == nil {
+		return ErrTableNotFound
+	}
+
+	username := userID.String()
+	if view := t.game.GetLegacyGame().GenerateOmniView(); view != nil {
+		if position, ok := t.game.PlayerPosition(userID); ok && int(position) < len(view.Players) {
+			username = view.Players[position].Username
+		}
+	}
+
+	c := e.client(t, userID, username)
+	return e.dispatch(c, playerRaise{base{wsAction}, amount, actionToken})
+}
+
+// StreamState sends the table's current state and then every message
+// broadcast at it, until ctx is done or send fails. The messages are those
+// WebSocket clients at the table receive.
+func (e *EngineService) StreamState(ctx context.Context, tableName string, send func([]byte) error) error {
+	t := e.hub.findTableByName(tableName)
+	if t == nil {
+		return ErrTableNotFound
+	}
+
+	observer := &Client{
+		hub:          e.hub,
+		send:         newSendQueue(e.hub.sendQueueSize, e.hub.sendMetrics),
+		uuid:         uuid.New().String(),
+		table:        t,
+		capabilities: make(capabilitySet),
+	}
+	t.register <- observer
+	defer func() {
+		t.unregister <- observer
+		observer.send.close()
+	}()
+
+	if err := send(createUpdatedGame(observer)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-observer.send.ready:
+		}
+		for {
+			message, ok := observer.send.pop()
+			if !ok {
+				break
+			}
+			if err := send(message); err != nil {
+				return err
+			}
+		}
+		if observer.send.isClosed() {
+			return ErrStreamBehind
+		}
+	}
+}
+
+// client is a connectionless client for one command by a user at a table
+func (e *EngineService) client(t *table, userID uuid.UUID, username string) *Client {
+	return &Client{
+		hub:             e.hub,
+		send:            newSendQueue(0, nil),
+		uuid:            userID.String(),
+		userID:          userID,
+		username:        username,
+		table:           t,
+		formanceService: e.formanceService,
+		db:              e.db,
+		capabilities:    make(capabilitySet),
+		correlationID:   "engine-api",
+	}
+}
+
+// dispatch runs a command through the client and returns the first error
+// the table sent back for it
+func (e *EngineService) dispatch(c *Client, command interface{}) error {
+	defer c.send.close()
+
+	message, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	// Logged and recorded like a WebSocket command, so table replays
+	// include what other services did
+	start := time.Now()
+	err = c.processEvents(message)
+	c.logCommand(message, start, err)
+	c.recordCommand(c.table, message, err)
+	if err != nil {
+		return err
+	}
+
+	for {
+		reply, ok := c.send.pop()
+		if !ok {
+			return nil
+		}
+		var refused struct {
+			Action  string `json:"action"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(reply, &refused) == nil && refused.Action == actionError {
+			return &CommandError{Code: refused.Code, Message: refused.Message}
+		}
+	}
+}
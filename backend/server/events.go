@@ -3,10 +3,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/engine/domain/game"
+	"github.com/anhbaysgalan1/gp/internal/formance"
 	"github.com/anhbaysgalan1/gp/internal/models"
 	"github.com/anhbaysgalan1/gp/poker"
 	"github.com/google/uuid"
@@ -20,24 +25,28 @@ func getEngineView(viewInterface interface{}) (*EngineGameView, bool) {
 	return engineView, ok
 }
 
-// safeSend safely sends a message to a client's send channel without panicking on closed channels
+// safeSend queues a message for a client. Messages to clients that have
+// disconnected or fallen too far behind are discarded.
 func safeSend(c *Client, message []byte) {
-	defer func() {
-		if r := recover(); r != nil {
-			slog.Default().Warn("Attempted to send message to closed channel", "user_id", c.userID)
-		}
-	}()
-
-	select {
-	case c.send <- message:
-		// Message sent successfully
-	default:
-		// Channel is full or closed, skip sending
-		slog.Default().Warn("Unable to send message to client, channel unavailable", "user_id", c.userID)
+	if err := c.send.push(c.tagReply(message)); err != nil {
+		slog.Default().Warn("Unable to send message to client", "user_id", c.userID, "error", err)
 	}
 }
 
 func handleJoinTable(c *Client, tablename string) {
+	if c.isSpectator() {
+		handleSpectatorJoin(c, tablename)
+		return
+	}
+
+	// Joining a real table ends any tutorial in progress
+	c.tutorial = nil
+
+	// Rejoining a table where the user's seat is held takes it back
+	if c.hub.resumeSeat(c, tablename) {
+		return
+	}
+
 	table := c.hub.findTableByName(tablename)
 	if table == nil {
 		table = c.hub.createTable(tablename)
@@ -47,10 +56,22 @@ func handleJoinTable(c *Client, tablename string) {
 }
 
 func handleLeaveTable(c *Client, tablename string) {
+	if c.isSpectator() {
+		handleSpectatorLeave(c)
+		return
+	}
+
 	table := c.hub.findTableByName(tablename)
 
-	// Handle cash-out before leaving table
+	// Private games may ask big winners to keep playing for a while
+	if rejectHitAndRun(c) {
+		return
+	}
+
+	// Handle cash-out before leaving table, which settles any seat held for
+	// a dropped connection too
 	handlePlayerCashOut(c)
+	c.hub.dropHeldSeat(c.userID)
 
 	// Clear session ID since player is leaving table
 	c.sessionID = uuid.Nil
@@ -64,47 +85,119 @@ func handleLeaveTable(c *Client, tablename string) {
 }
 
 func handleSendMessage(c *Client, username string, message string) {
-	c.table.broadcast <- createNewMessage(username, message)
+	if handleChatCommand(c, message) {
+		return
+	}
+	handID := c.table.game.CurrentHandID()
+	status := screenChat(c, models.ChatChannelTable, c.table.name, message)
+	if status == models.ChatStatusDelivered {
+		c.table.broadcast <- createNewMessage(handID, username, message)
+	}
+	recordTableChat(c, handID, username, message, status)
 }
 
 func handleSendLog(c *Client, message string) {
-	c.table.broadcast <- createNewLog(message)
+	c.table.broadcast <- createNewLog(c.table.game.CurrentHandID(), message)
 }
 
 func handleNewPlayer(c *Client, username string) {
 	c.username = username
 	safeSend(c, createUpdatedGame(c))
-	c.table.broadcast <- createNewMessage(gameAdminName, fmt.Sprintf("%s has joined", username))
+	c.table.broadcast <- createNewMessage(c.table.game.CurrentHandID(), gameAdminName, fmt.Sprintf("%s has joined", username))
 }
 
 func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 	slog.Default().Info("Processing take seat request", "user_id", c.userID, "username", username, "seat_id", seatID, "buy_in", buyIn)
 	// Check if client is authenticated
 	if c.userID == uuid.Nil || c.formanceService == nil {
-		safeSend(c, createErrorMessage("Authentication required for seat actions"))
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required for seat actions"))
 		return
 	}
 
 	// Validate buy-in amount
 	if buyIn <= 0 {
-		safeSend(c, createErrorMessage("Buy-in amount must be positive"))
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidBuyIn, "Buy-in amount must be positive"))
+		return
+	}
+
+	// Private table owners keep their own ban list
+	if rejectTableBan(c) {
+		return
+	}
+
+	// Practice tables play for play chips and never touch the wallet
+	if c.table.isPractice() {
+		takePracticeSeat(c, username, seatID, buyIn)
 		return
 	}
 
 	buyInAmount := int64(buyIn)
 
+	// Sessions need a table that exists, so a table opened over the
+	// WebSocket is saved before anyone buys in
+	if err := c.table.game.Promote(ctx); err != nil {
+		slog.Default().Error("Failed to promote table", "table", c.table.name, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to assign seat. Please try again."))
+		return
+	}
+
+	// Chips may only sit at one table at a time, and a session belonging to
+	// another table is never reused for this one
+	var tableID uuid.UUID
+	if id := c.table.game.GetTableID(); id != nil {
+		tableID = *id
+	}
+	existingSession, otherSessions, err := activeSessions(c, tableID)
+	if err != nil {
+		slog.Default().Warn("Failed to check active sessions", "user_id", c.userID, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to assign seat. Please try again."))
+		return
+	}
+	if len(otherSessions) > 0 {
+		rejectSessionConflict(c, otherSessions)
+		return
+	}
+
+	if c.table.callTimeClosed() {
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "This game has reached call time and is closed."))
+		return
+	}
+
+	// Rejoining with an open session brings no new chips to the table
+	if existingSession == nil && !checkBuyInRange(c, buyInAmount) {
+		return
+	}
+
+	// Apply the table's seating policy before any funds move
+	seatID, err = resolveSeat(c, seatID)
+	if err != nil {
+		rejectSeat(c, err)
+		return
+	}
+
 	// Check user balance
 	ctx := context.Background()
 	balance, err := c.formanceService.GetUserBalance(ctx, c.userID, c.db)
 	if err != nil {
 		slog.Default().Warn("Failed to get user balance", "user_id", c.userID, "error", err)
-		safeSend(c, createErrorMessage("Failed to check balance. Please try again."))
+		safeSend(c, createCodedErrorMessage(errorCodeBalanceUnavailable, "Failed to check balance. Please try again."))
 		return
 	}
 
 	// Check if user has sufficient main balance
 	if balance.MainBalance < buyInAmount {
-		safeSend(c, createErrorMessage(fmt.Sprintf("Insufficient balance for buy-in. You have %d MNT but need %d MNT. Please deposit more funds in your wallet.", balance.MainBalance, buyInAmount)))
+		safeSend(c, createCodedErrorMessage(errorCodeInsufficientBalance, fmt.Sprintf("Insufficient balance for buy-in. You have %d MNT but need %d MNT. Please deposit more funds in your wallet.", balance.MainBalance, buyInAmount)))
+		return
+	}
+
+	// Responsible gaming limits on buying back in after losing a stack here
+	if !checkRebuy(c, buyInAmount, balance.MainBalance) {
+		return
+	}
+
+	// Rejoining with an open session moves no money, a new one adds to the
+	// player's exposure across tables
+	if existingSession == nil && !checkExposure(c, buyInAmount) {
 		return
 	}
 
@@ -125,24 +218,20 @@ func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 		safeSend(c, createWarningMessage(fmt.Sprintf("Warning: After this buy-in, you'll have %d MNT remaining. You may want to deposit more funds soon.", remainingBalance)))
 	}
 
-	// Check if user already has an active session
-	var existingSession models.GameSession
-	err = c.db.Where("user_id = ? AND status = 'active'", c.userID).First(&existingSession).Error
-
 	var sessionID uuid.UUID
 	var transactionID string
 
-	if err == nil {
-		// User has existing session, use that
+	if existingSession != nil {
+		// Rejoining this table with a session that is still open
 		sessionID = existingSession.ID
 		c.sessionID = sessionID
 
 		// Update the seat number in the existing session
 		seatNumberInt := int(seatID)
 		existingSession.SeatNumber = &seatNumberInt
-		if err := c.db.Save(&existingSession).Error; err != nil {
+		if err := c.db.Save(existingSession).Error; err != nil {
 			slog.Default().Warn("Failed to update seat number in session", "user_id", c.userID, "seat_id", seatID, "error", err)
-			safeSend(c, createErrorMessage("Failed to assign seat. Please try again."))
+			safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to assign seat. Please try again."))
 			return
 		}
 
@@ -159,17 +248,13 @@ func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 		transactionID, err = c.formanceService.TransferToGame(ctx, c.userID, buyInAmount, sessionID)
 		if err != nil {
 			slog.Default().Warn("Failed to transfer funds to game", "user_id", c.userID, "amount", buyInAmount, "error", err)
-			safeSend(c, createErrorMessage("Failed to transfer funds for buy-in. Please try again."))
+			safeSend(c, createCodedErrorMessage(errorCodeTransferFailed, "Failed to transfer funds for buy-in. Please try again."))
 			return
 		}
 
 		// Create database session record for real money game
 		if c.table.sessionService != nil {
-			// Get or create a virtual table ID for the session
-			var tableID uuid.UUID
-			if c.table.game.GetTableID() != nil {
-				tableID = *c.table.game.GetTableID()
-			} else {
+			if tableID == uuid.Nil {
 				// Use a default table ID for virtual tables
 				tableID = uuid.New()
 			}
@@ -209,7 +294,7 @@ func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 		slog.Default().Warn("Seat player failed", "error", err)
 		// Clear session on failure
 		c.sessionID = uuid.Nil
-		safeSend(c, createErrorMessage("Failed to take seat. Please try again."))
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to take seat. Please try again."))
 		return
 	}
 
@@ -218,6 +303,7 @@ func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 
 	// Seating succeeded, broadcast updated state
 	slog.Info("Seating successful", "user_id", c.userID, "seat_id", seatID)
+	claimSeatReservation(c)
 
 	// Log successful buy-in
 	slog.Info("Player successfully bought in",
@@ -236,33 +322,54 @@ func handleTakeSeat(c *Client, username string, seatID uint, buyIn uint) {
 
 	// Send player UUID update to sync frontend
 	safeSend(c, createUpdatedPlayerUUID(c))
+	c.table.emitPlayerSeated(c, int(seatID), buyInAmount)
 
 	// Broadcast updated game state
 	c.table.broadcast <- createUpdatedGame(c)
 }
 
 func handleStartGame(c *Client) {
-	// Try engine-based approach first
-	if c.table.game.engine != nil {
-		ctx := context.Background()
+	if !c.table.callTimeAllowsHand() {
+		c.table.transition(models.TableStatePaused, reasonCallTime)
+		safeSend(c, createWarningMessage("This game has reached call time and no more hands will be dealt."))
+		return
+	}
+
+	// The engine owns the table's state when it has one; never retry on legacy
+	if c.table.executionPath() == executionEngine {
 		err := c.table.game.engine.StartHand(ctx, c.table.game.tableID)
 		if err != nil {
-			slog.Default().Warn("Engine start hand failed, falling back to legacy", "error", err)
-		} else {
-			// Engine succeeded, broadcast updated state
-			broadcastDeal(c.table)
-			c.table.broadcast <- createUpdatedGame(c)
+			slog.Default().Warn("Engine start hand failed", "table", c.table.name, "error", err)
+			c.table.transition(models.TableStateWaitingForPlayers, reasonStartFailed)
+			safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "The hand could not be started"))
 			return
 		}
+		c.table.beginHand()
+		if mismatches := c.table.engineMismatches(c.table.game.GetLegacyGame().GenerateOmniView()); len(mismatches) > 0 {
+			c.table.desync(c, "engine started a hand the game does not show", mismatches)
+			return
+		}
+		broadcastDeal(c.table)
+		c.table.broadcast <- createUpdatedGame(c)
+		c.table.scheduleTurnNudge()
+		c.table.announceTurn()
+		return
 	}
 
-	// Legacy approach as fallback
 	err := c.table.game.Start()
-	if err != nil {
-		fmt.Println(err)
+	if errors.Is(err, poker.ErrMisdeal) {
+		c.table.beginHand()
+		handleMisdeal(c.table)
+	} else if err != nil {
+		slog.Default().Warn("Start hand failed", "table", c.table.name, "error", err)
+		c.table.transition(models.TableStateWaitingForPlayers, reasonStartFailed)
+	} else {
+		c.table.beginHand()
 	}
 	broadcastDeal(c.table)
 	c.table.broadcast <- createUpdatedGame(c)
+	c.table.scheduleTurnNudge()
+	c.table.announceTurn()
 }
 
 func handleResetGame(c *Client) {
@@ -281,168 +388,151 @@ func handleDealGame(c *Client) {
 	}
 
 	err := poker.Deal(c.table.game.GetLegacyGame(), engineView.DealerNum, 0)
-	if err != nil {
+	if errors.Is(err, poker.ErrMisdeal) {
+		handleMisdeal(c.table)
+	} else if err != nil {
 		slog.Default().Warn("Deal table", "error", err)
 	}
 	c.table.broadcast <- createUpdatedGame(c)
 }
 
-func handleCall(c *Client) {
-	// Try engine-based approach first if client is authenticated
-	if c.userID != uuid.Nil && c.table.game.engine != nil {
-		ctx := context.Background()
-		err := c.table.game.HandlePlayerAction(ctx, c.userID, "call", 0)
-		if err != nil {
-			slog.Default().Warn("Engine call action failed, falling back to legacy", "error", err)
-		} else {
-			// Engine succeeded, broadcast updated state
+func handleCall(c *Client) bool {
+	if c.table.executionPath() == executionEngine {
+		if engineAction(c, "call", 0) {
 			c.table.broadcast <- createUpdatedGame(c)
-			return
+			c.table.scheduleTurnNudge()
+			return true
 		}
+		return false
 	}
 
-	// Legacy approach as fallback
 	viewInterface := c.table.game.GenerateOmniView()
 	engineView, ok := getEngineView(viewInterface)
 	if !ok {
 		slog.Default().Error("Failed to cast view to EngineGameView in handleCall")
-		return
+		return false
 	}
 
 	pn := engineView.ActionNum
 	currentPlayer := engineView.Players[pn]
 
-	// compute amount needed to call
-	maxBet := engineView.Players[0].TotalBet
+	// The amount needed to call, all in if the player can't cover it
+	var maxBet uint
 	for _, p := range engineView.Players {
-		if p.TotalBet > maxBet {
-			maxBet = p.TotalBet
-		}
+		maxBet = max(maxBet, p.TotalBet)
 	}
-	callAmount := maxBet - currentPlayer.TotalBet
+	callAmount := game.PotManager{}.CallAmount(int64(maxBet), int64(currentPlayer.TotalBet), int64(currentPlayer.Stack))
 
-	// if player must go all in to call
-	if callAmount >= currentPlayer.Stack {
-		callAmount = currentPlayer.Stack
-	}
-
-	err := poker.Bet(c.table.game.GetLegacyGame(), pn, callAmount)
+	err := auditedAction(c, "call", pn, uint(callAmount), poker.Bet)
 	if err != nil {
-		slog.Default().Warn("Handle call", "error", err)
+		slog.Default().Warn("Handle call", "hand_id", c.table.game.CurrentHandID(), "error", err)
 	}
 
 	// Check if hand ended and handle pot distribution
 	handlePotDistribution(c)
 
 	c.table.broadcast <- createUpdatedGame(c)
+	c.table.scheduleTurnNudge()
+	return err == nil
 }
 
-func handleRaise(c *Client, raise uint) {
-	// Try engine-based approach first if client is authenticated
-	if c.userID != uuid.Nil && c.table.game.engine != nil {
-		ctx := context.Background()
-		err := c.table.game.HandlePlayerAction(ctx, c.userID, "raise", int64(raise))
-		if err != nil {
-			slog.Default().Warn("Engine raise action failed, falling back to legacy", "error", err)
-		} else {
-			// Engine succeeded, broadcast updated state
+func handleRaise(c *Client, raise uint) bool {
+	if c.table.executionPath() == executionEngine {
+		if engineAction(c, "raise", int64(raise)) {
 			c.table.broadcast <- createUpdatedGame(c)
-			return
+			c.table.scheduleTurnNudge()
+			return true
 		}
+		return false
 	}
 
-	// Legacy approach as fallback
 	viewInterface := c.table.game.GenerateOmniView()
 	engineView, ok := getEngineView(viewInterface)
 	if !ok {
 		slog.Default().Error("Failed to cast view to EngineGameView in handleRaise")
-		return
+		return false
 	}
 
 	pn := engineView.ActionNum
-	err := poker.Bet(c.table.game.GetLegacyGame(), pn, raise)
+	err := auditedAction(c, "raise", pn, raise, poker.Bet)
 	if err != nil {
-		slog.Default().Warn("Handle raise", "error", err)
+		slog.Default().Warn("Handle raise", "hand_id", c.table.game.CurrentHandID(), "error", err)
 	}
 
 	// Check if hand ended and handle pot distribution
 	handlePotDistribution(c)
 
 	c.table.broadcast <- createUpdatedGame(c)
+	c.table.scheduleTurnNudge()
+	return err == nil
 }
 
-func handleCheck(c *Client) {
-	// Try engine-based approach first if client is authenticated
-	if c.userID != uuid.Nil && c.table.game.engine != nil {
-		ctx := context.Background()
-		err := c.table.game.HandlePlayerAction(ctx, c.userID, "check", 0)
-		if err != nil {
-			slog.Default().Warn("Engine check action failed, falling back to legacy", "error", err)
-		} else {
-			// Engine succeeded, broadcast updated state
+func handleCheck(c *Client) bool {
+	if c.table.executionPath() == executionEngine {
+		if engineAction(c, "check", 0) {
 			c.table.broadcast <- createUpdatedGame(c)
-			return
+			c.table.scheduleTurnNudge()
+			return true
 		}
+		return false
 	}
 
-	// Legacy approach as fallback
 	viewInterface := c.table.game.GenerateOmniView()
 	engineView, ok := getEngineView(viewInterface)
 	if !ok {
 		slog.Default().Error("Failed to cast view to EngineGameView in handleCheck")
-		return
+		return false
 	}
 
 	pn := engineView.ActionNum
-	err := poker.Bet(c.table.game.GetLegacyGame(), pn, 0)
+	err := auditedAction(c, "check", pn, 0, poker.Bet)
 	if err != nil {
-		slog.Default().Warn("Handle check", "error", err)
+		slog.Default().Warn("Handle check", "hand_id", c.table.game.CurrentHandID(), "error", err)
 	}
 
 	// Check if hand ended and handle pot distribution
 	handlePotDistribution(c)
 
 	c.table.broadcast <- createUpdatedGame(c)
+	c.table.scheduleTurnNudge()
+	return err == nil
 }
 
-func handleFold(c *Client) {
-	// Try engine-based approach first if client is authenticated
-	if c.userID != uuid.Nil && c.table.game.engine != nil {
-		ctx := context.Background()
-		err := c.table.game.HandlePlayerAction(ctx, c.userID, "fold", 0)
-		if err != nil {
-			slog.Default().Warn("Engine fold action failed, falling back to legacy", "error", err)
-		} else {
-			// Engine succeeded, broadcast updated state
+func handleFold(c *Client) bool {
+	if c.table.executionPath() == executionEngine {
+		if engineAction(c, "fold", 0) {
 			c.table.broadcast <- createUpdatedGame(c)
-			return
+			c.table.scheduleTurnNudge()
+			return true
 		}
+		return false
 	}
 
-	// Legacy approach as fallback
 	viewInterface := c.table.game.GenerateOmniView()
 	engineView, ok := getEngineView(viewInterface)
 	if !ok {
 		slog.Default().Error("Failed to cast view to EngineGameView in handleFold")
-		return
+		return false
 	}
 
 	pn := engineView.ActionNum
-	err := poker.Fold(c.table.game.GetLegacyGame(), pn, 0)
+	err := auditedAction(c, "fold", pn, 0, poker.Fold)
 	if err != nil {
-		slog.Default().Warn("Handle fold", "error", err)
-		return
+		slog.Default().Warn("Handle fold", "hand_id", c.table.game.CurrentHandID(), "error", err)
+		return false
 	}
 
 	// Check if hand ended and handle pot distribution
 	handlePotDistribution(c)
 
 	c.table.broadcast <- createUpdatedGame(c)
+	c.table.scheduleTurnNudge()
+	return err == nil
 }
 
 func handleGetBalance(c *Client) {
 	if c.formanceService == nil || c.userID == uuid.Nil {
-		safeSend(c, createErrorMessage("Authentication required for balance information"))
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required for balance information"))
 		return
 	}
 
@@ -450,13 +540,14 @@ func handleGetBalance(c *Client) {
 	sendBalanceUpdateToClient(c, "balance_check", 0, "")
 }
 
-func createNewMessage(username string, message string) []byte {
+func createNewMessage(handID string, username string, message string) []byte {
 	new := newMessage{
 		base{actionNewMessage},
 		uuid.New().String(),
 		message,
 		username,
 		currentTime(),
+		handID,
 	}
 	resp, err := json.Marshal(new)
 	if err != nil {
@@ -465,12 +556,13 @@ func createNewMessage(username string, message string) []byte {
 	return resp
 }
 
-func createNewLog(message string) []byte {
+func createNewLog(handID string, message string) []byte {
 	log := newLog{
 		base{actionNewLog},
 		uuid.New().String(),
 		message,
 		currentTime(),
+		handID,
 	}
 	resp, err := json.Marshal(log)
 	if err != nil {
@@ -511,6 +603,36 @@ func createUpdatedGame(c *Client) []byte {
 		base{actionUpdateGame},
 		gameState,
 		sessionInfo,
+		false,
+		tableViews(c.table),
+	}
+
+	resp, err := json.Marshal(game)
+	if err != nil {
+		slog.Default().Warn("Marshal update game", "error", err)
+	}
+	return resp
+}
+
+// createTableUpdate builds an update-game message for everyone at the table,
+// without any client's session info
+func createTableUpdate(t *table) []byte {
+	return createTableGame(t, false)
+}
+
+// createTableResync builds an update-game message that every client applies
+// as a full snapshot, after the state they were sent turned out to be wrong
+func createTableResync(t *table) []byte {
+	return createTableGame(t, true)
+}
+
+func createTableGame(t *table, resync bool) []byte {
+	game := updateGame{
+		base{actionUpdateGame},
+		t.game.GenerateOmniView(),
+		nil,
+		resync,
+		tableViews(t),
 	}
 
 	resp, err := json.Marshal(game)
@@ -600,21 +722,30 @@ func broadcastDeal(table *table) {
 		return
 	}
 
-	startMsg := "starting new hand"
-	table.broadcast <- createNewLog(startMsg)
+	handID := table.game.CurrentHandID()
+	startMsg := fmt.Sprintf("starting hand %s", handID)
+	table.broadcast <- createNewLog(handID, startMsg)
 
 	if len(engineView.Players) > int(engineView.SBNum) {
 		sbUser := engineView.Players[engineView.SBNum].Username
 		sb := engineView.Config.SmallBlind
 		sbMsg := fmt.Sprintf("%s is small blind (%d)", sbUser, sb)
-		table.broadcast <- createNewLog(sbMsg)
+		table.broadcast <- createNewLog(handID, sbMsg)
 	}
 
 	if len(engineView.Players) > int(engineView.BBNum) {
 		bbUser := engineView.Players[engineView.BBNum].Username
 		bb := engineView.Config.BigBlind
 		bbMsg := fmt.Sprintf("%s is big blind (%d)", bbUser, bb)
-		table.broadcast <- createNewLog(bbMsg)
+		table.broadcast <- createNewLog(handID, bbMsg)
+
+		if ante := engineView.Players[engineView.BBNum].Ante; engineView.Config.BigBlindAnte && ante > 0 {
+			table.broadcast <- createNewLog(handID, fmt.Sprintf("%s posts the big blind ante (%d)", bbUser, ante))
+		}
+	}
+
+	if engineView.Config.Ante > 0 && !engineView.Config.BigBlindAnte {
+		table.broadcast <- createNewLog(handID, fmt.Sprintf("each player posts an ante (%d)", engineView.Config.Ante))
 	}
 }
 
@@ -623,12 +754,18 @@ func currentTime() string {
 }
 
 func createErrorMessage(message string) []byte {
-	errorMsg := map[string]interface{}{
-		"action":  "error",
-		"message": message,
-		"time":    currentTime(),
-	}
-	resp, err := json.Marshal(errorMsg)
+	return createCodedErrorMessage(errorCodeGeneric, message)
+}
+
+// createCodedErrorMessage builds an error message carrying a machine readable
+// code. The code is stripped for clients without the structured-errors capability.
+func createCodedErrorMessage(code string, message string) []byte {
+	resp, err := json.Marshal(errorMessage{
+		base:    base{actionError},
+		Code:    code,
+		Message: message,
+		Time:    currentTime(),
+	})
 	if err != nil {
 		slog.Default().Warn("Marshal error message", "error", err)
 	}
@@ -636,27 +773,21 @@ func createErrorMessage(message string) []byte {
 }
 
 func createWarningMessage(message string) []byte {
-	warningMsg := map[string]interface{}{
-		"action":  "warning",
-		"message": message,
-		"time":    currentTime(),
-	}
-	resp, err := json.Marshal(warningMsg)
-	if err != nil {
-		slog.Default().Warn("Marshal warning message", "error", err)
-	}
-	return resp
+	return createNoticeMessage(actionWarning, message)
 }
 
 func createSuccessMessage(message string) []byte {
-	successMsg := map[string]interface{}{
-		"action":  "success",
-		"message": message,
-		"time":    currentTime(),
-	}
-	resp, err := json.Marshal(successMsg)
+	return createNoticeMessage(actionSuccess, message)
+}
+
+func createNoticeMessage(action string, message string) []byte {
+	resp, err := json.Marshal(noticeMessage{
+		base:    base{action},
+		Message: message,
+		Time:    currentTime(),
+	})
 	if err != nil {
-		slog.Default().Warn("Marshal success message", "error", err)
+		slog.Default().Warn("Marshal notice message", "action", action, "error", err)
 	}
 	return resp
 }
@@ -685,6 +816,13 @@ func sendBalanceUpdateToClient(c *Client, changeType string, changeAmount int64,
 		return // Skip if no Formance service or not authenticated
 	}
 
+	// Balances stay frozen in the session while the table is read-only; the
+	// player can cash out after it reopens
+	if c.table != nil && c.table.isReadOnly() {
+		slog.Warn("Skipping cash-out at read-only table", "user_id", c.userID, "table", c.table.name, "session_id", c.sessionID)
+		return
+	}
+
 	ctx := context.Background()
 	balance, err := c.formanceService.GetUserBalance(ctx, c.userID, c.db)
 	if err != nil {
@@ -714,6 +852,8 @@ func sendBalanceUpdateToClient(c *Client, changeType string, changeAmount int64,
 // broadcastBalanceUpdateToUser sends balance update to all clients for a specific user
 func broadcastBalanceUpdateToUser(hub *Hub, userID uuid.UUID, changeType string, changeAmount int64, transactionID string) {
 	// Find all clients for this user across all tables
+	hub.tablesMu.RLock()
+	defer hub.tablesMu.RUnlock()
 	for table := range hub.tables {
 		for client := range table.clients {
 			if client.userID == userID {
@@ -725,6 +865,11 @@ func broadcastBalanceUpdateToUser(hub *Hub, userID uuid.UUID, changeType string,
 
 // handlePotDistribution checks if a hand has ended and distributes winnings via Formance
 func handlePotDistribution(c *Client) {
+	// A voided hand has nothing to distribute, its bets were already returned
+	if handleMisdeal(c.table) {
+		return
+	}
+
 	viewInterface := c.table.game.GenerateOmniView()
 	engineView, ok := getEngineView(viewInterface)
 	if !ok {
@@ -738,22 +883,47 @@ func handlePotDistribution(c *Client) {
 	}
 
 	ctx := context.Background()
+	handID := engineView.HandID
+	var totalPot int64
+	winners := make([]models.HandWinner, 0)
 
 	// Determine if this is a practice game (no Formance service or issues with real money transfers)
-	isPracticeGame := c.formanceService == nil
+	isPracticeGame := c.formanceService == nil || c.table.isPractice()
+
+	// The house takes its rake from the winners of each pot before they
+	// are paid
+	var rakes []int64
+	var handRake int64
+	rakeConfig := c.table.rakeConfig(handID)
+	if !isPracticeGame {
+		rakes = potRakes(rakeConfig, engineView.Pots)
+	}
 
 	// Process each pot (there can be multiple pots in case of side pots)
-	for _, pot := range engineView.Pots {
+	for i, pot := range engineView.Pots {
 		if len(pot.WinningPlayerNums) == 0 {
 			continue // No winners for this pot
 		}
 
 		potAmount := int64(pot.Amt)
-		winnerCount := len(pot.WinningPlayerNums)
-		winningsPerPlayer := potAmount / int64(winnerCount)
+		totalPot += potAmount
+
+		winnersOfPot := potWinners(engineView, pot)
+		c.table.broadcastPotAwarded(handID, i, potAmount, winnersOfPot)
+		c.table.emitPotAwarded(handID, i, potAmount, winnersOfPot)
+
+		// Find who won each share; in split games the high and low halves
+		// can pay different amounts
+		type paidShare struct {
+			potShare
+			client *Client
+			userID uuid.UUID
+			rake   int64 // Taken from amount before it was paid
+		}
+		var shares []paidShare
+		for _, share := range potShares(pot, engineView.Config.HiLo) {
+			winnerPosition := share.position
 
-		// Distribute winnings to each winner
-		for _, winnerPosition := range pot.WinningPlayerNums {
 			// Find the winner player and their user ID
 			var winnerClient *Client
 			var winnerUserID uuid.UUID
@@ -774,50 +944,111 @@ func handlePotDistribution(c *Client) {
 					break
 				}
 			}
+			if winnerClient == nil && int(winnerPosition) < len(engineView.Players) {
+				if userID, err := uuid.Parse(engineView.Players[winnerPosition].UUID); err == nil {
+					if bot := c.table.practiceBot(userID); bot != nil {
+						winnerClient, winnerUserID = bot.client, userID
+					}
+				}
+			}
 
 			if winnerClient == nil || winnerUserID == uuid.Nil {
 				slog.Default().Warn("Could not find winner client for pot distribution",
-					"winner_position", winnerPosition, "pot_amount", potAmount)
+					"hand_id", handID, "winner_position", winnerPosition, "pot_amount", potAmount)
 				continue
 			}
+			shares = append(shares, paidShare{potShare: share, client: winnerClient, userID: winnerUserID})
+		}
 
-			var transactionID string
-			var shouldSendBalanceUpdate bool
+		// Pay every winner of the pot in one transaction, keyed by the pot so
+		// a retry can't pay it twice
+		var transactionID string
+		if !isPracticeGame && len(shares) > 0 {
+			potID := formance.PotID(c.table.name, handID, i)
+			amounts := make(map[uuid.UUID]int64, len(shares))
+			sessions := make(map[uuid.UUID]uuid.UUID, len(shares))
+			var paid []int
+			var paidAmounts []int64
+			for j, share := range shares {
+				if share.client.sessionID == uuid.Nil {
+					slog.Default().Warn("No session ID stored for winner client, leaving them out of pot distribution",
+						"hand_id", handID, "pot_id", potID, "user_id", share.userID)
+					continue
+				}
+				paid = append(paid, j)
+				paidAmounts = append(paidAmounts, share.amount)
+				sessions[share.userID] = share.client.sessionID
+			}
 
-			if !isPracticeGame {
-				// Try real money transfer
-				sessionID := winnerClient.sessionID
-				if sessionID == uuid.Nil {
-					sessionID = uuid.New()
-					slog.Default().Warn("No session ID stored for winner client, generating new one for pot distribution", "user_id", winnerUserID)
+			// Only winners paid through the ledger are raked
+			rake := make(map[uuid.UUID]int64, len(paid))
+			for k, r := range splitRake(rakes[i], paidAmounts) {
+				shares[paid[k]].rake = r
+			}
+			for _, j := range paid {
+				amounts[shares[j].userID] += shares[j].amount - shares[j].rake
+				if shares[j].rake > 0 {
+					rake[shares[j].userID] += shares[j].rake
 				}
+			}
 
+			if len(amounts) > 0 {
 				var err error
-				transactionID, err = c.formanceService.TransferFromGame(ctx, winnerUserID, winningsPerPlayer, sessionID)
+				transactionID, err = c.formanceService.DistributeRakedPot(ctx, potID, amounts, rake, sessions, map[string]string{
+					"hand_id": handID,
+					"table":   c.table.name,
+				})
 				if err != nil {
-					slog.Default().Error("Failed to transfer pot winnings to winner",
-						"winner_user_id", winnerUserID,
-						"amount", winningsPerPlayer,
+					slog.Default().Error("Failed to transfer pot winnings to winners",
+						"hand_id", handID,
+						"pot_id", potID,
+						"winners", len(amounts),
 						"pot_total", potAmount,
 						"error", err)
-					// Fallback to practice mode for this winner
-					isPracticeGame = true
 					transactionID = ""
-					winnerClient.send <- createErrorMessage("Failed to transfer winnings. Game continuing in practice mode.")
+					for j := range shares {
+						shares[j].rake = 0
+						safeSend(shares[j].client, createErrorMessage("Failed to transfer winnings. Contact support if your balance is not updated."))
+					}
 				} else {
-					shouldSendBalanceUpdate = true
+					var rakeFailures []string
+					for _, share := range shares {
+						if share.rake > 0 {
+							if err := c.table.takeRakeChips(handID, share.position, share.rake); err != nil {
+								rakeFailures = append(rakeFailures, err.Error())
+							}
+							handRake += share.rake
+						}
+					}
+					if len(rakeFailures) > 0 {
+						c.table.desync(c, "ledger took rake the stacks still hold", rakeFailures)
+					}
+					recordRake(ctx, c, rakeConfig, transactionID, rake)
+
 					slog.Info("Real money pot distribution completed",
-						"winner_user_id", winnerUserID,
-						"amount", winningsPerPlayer,
+						"hand_id", handID,
+						"pot_id", potID,
+						"winners", len(amounts),
 						"pot_total", potAmount,
-						"session_id", sessionID,
 						"transaction_id", transactionID)
 				}
 			}
+		}
+
+		for _, share := range shares {
+			winnerPosition, winningsPerPlayer := share.position, share.amount-share.rake
+			winnerClient, winnerUserID := share.client, share.userID
+
+			// Only winners included in the pot's transaction were paid by it
+			shareTransactionID := ""
+			if transactionID != "" && winnerClient.sessionID != uuid.Nil {
+				shareTransactionID = transactionID
+			}
 
 			if isPracticeGame {
 				// Practice table - no real money transfer, just continue game
 				slog.Info("Practice table pot distribution (no real money transfer)",
+					"hand_id", handID,
 					"winner_user_id", winnerUserID,
 					"amount", winningsPerPlayer,
 					"pot_total", potAmount)
@@ -825,32 +1056,64 @@ func handlePotDistribution(c *Client) {
 
 			// Log successful pot distribution
 			slog.Info("Pot winnings distributed to winner",
+				"hand_id", handID,
 				"winner_user_id", winnerUserID,
 				"amount", winningsPerPlayer,
 				"pot_total", potAmount,
-				"transaction_id", transactionID,
+				"transaction_id", shareTransactionID,
 				"is_practice", isPracticeGame)
 
 			// Send success message to winner
-			if transactionID != "" && shouldSendBalanceUpdate {
-				winnerClient.send <- createSuccessMessage(fmt.Sprintf("You won %d MNT! Transaction ID: %s", winningsPerPlayer, transactionID))
+			if shareTransactionID != "" {
+				safeSend(winnerClient, createSuccessMessage(fmt.Sprintf("You won %d MNT! Transaction ID: %s", winningsPerPlayer, shareTransactionID)))
 				// Send real-time balance update to winner
-				sendBalanceUpdateToClient(winnerClient, "win", winningsPerPlayer, transactionID)
+				sendBalanceUpdateToClient(winnerClient, "win", winningsPerPlayer, shareTransactionID)
 			} else {
 				// For practice tables or when no transaction occurred
-				winnerClient.send <- createSuccessMessage(fmt.Sprintf("You won %d chips!", winningsPerPlayer))
+				safeSend(winnerClient, createSuccessMessage(fmt.Sprintf("You won %d chips!", winningsPerPlayer)))
 			}
 
 			// Broadcast winning message to table
 			winnerPlayer := engineView.Players[winnerPosition]
-			if transactionID != "" {
-				c.table.broadcast <- createNewLog(fmt.Sprintf("%s wins %d MNT from the pot", winnerPlayer.Username, winningsPerPlayer))
-			} else {
-				c.table.broadcast <- createNewLog(fmt.Sprintf("%s wins %d chips from the pot", winnerPlayer.Username, winningsPerPlayer))
+			winners = append(winners, models.HandWinner{
+				UserID:        winnerUserID,
+				Username:      winnerPlayer.Username,
+				Amount:        winningsPerPlayer,
+				TransactionID: shareTransactionID,
+				Half:          share.half,
+			})
+			unit := "chips"
+			if shareTransactionID != "" {
+				unit = "MNT"
+			}
+			switch share.half {
+			case "high", "low":
+				c.table.broadcast <- createNewLog(handID, fmt.Sprintf("%s wins %d %s from the pot with the %s hand", winnerPlayer.Username, winningsPerPlayer, unit, share.half))
+			case "high_low":
+				c.table.broadcast <- createNewLog(handID, fmt.Sprintf("%s wins %d %s from the pot with both the high and low hands", winnerPlayer.Username, winningsPerPlayer, unit))
+			default:
+				c.table.broadcast <- createNewLog(handID, fmt.Sprintf("%s wins %d %s from the pot", winnerPlayer.Username, winningsPerPlayer, unit))
 			}
 		}
 	}
 
+	if handRake > 0 {
+		c.table.broadcast <- createNewLog(handID, fmt.Sprintf("%d MNT rake taken from the pot", handRake))
+	}
+
+	if handID != "" && c.table.handHistoryService != nil {
+		players, board := dealtCards(engineView)
+		// Stacks now have the winnings and rake applied
+		c.table.stakes.addWagers(handID, c.table.game.GetLegacyGame().GenerateOmniView(), winners, players)
+		if err := c.table.handHistoryService.RecordDealtCards(ctx, handID, players, board); err != nil {
+			slog.Default().Warn("Failed to record dealt cards", "hand_id", handID, "error", err)
+		}
+		if err := c.table.handHistoryService.RecordHandEnd(ctx, handID, totalPot, handRake, winners); err != nil {
+			slog.Default().Warn("Failed to record hand end", "hand_id", handID, "error", err)
+		}
+	}
+	c.table.emitHandCompleted(engineView, totalPot, winners)
+
 	// End the current hand by setting running = false and resetting for next hand
 	// This ensures the game state is properly reset before auto-start
 	if c.table.game != nil {
@@ -858,14 +1121,53 @@ func handlePotDistribution(c *Client) {
 		if legacyGame != nil {
 			// End hand and reset for next hand (sets running = false)
 			legacyGame.EndHandAndReset()
-			slog.Info("Hand ended, game state reset", "table", c.table.name)
+			slog.Info("Hand ended, game state reset", "table", c.table.name, "hand_id", handID)
+			c.table.noteBusts(time.Now())
+			if !isPracticeGame {
+				c.table.cashOutAboveCap(handID)
+			}
 		}
 	}
 
+	// Private decision reviews for players in training mode
+	c.table.sendTrainingSummaries(handID)
+
 	// Always attempt auto-start after pot distribution processing is complete
 	// This ensures the game continues even if there were payment failures
-	slog.Info("Pot distribution completed, scheduling auto-start", "table", c.table.name, "is_practice", isPracticeGame)
-	scheduleAutoHandStart(c.table)
+	scheduleAutoHandStart(c.table, reasonHandEnded)
+}
+
+// dealtCards lists every dealt-in player's hole cards, folded hands included,
+// and the board as far as it was dealt. Cards of players still in when more
+// than one remained were turned over at showdown and are marked shown.
+func dealtCards(view *EngineGameView) ([]models.HandPlayerCards, []string) {
+	inHand := 0
+	for _, player := range view.Players {
+		if player.In {
+			inHand++
+		}
+	}
+
+	players := make([]models.HandPlayerCards, 0, len(view.Players))
+	for _, player := range view.Players {
+		if len(player.Cards) < 2 || slices.Contains(player.Cards, 0) {
+			continue
+		}
+		cards := make([]string, len(player.Cards))
+		for i, card := range player.Cards {
+			cards[i] = eval.Card(card).String()
+		}
+		userID, _ := uuid.Parse(player.UUID)
+		players = append(players, models.HandPlayerCards{
+			UserID:   userID,
+			Username: player.Username,
+			SeatID:   player.SeatID,
+			Cards:    cards,
+			Shown:    player.In && inHand > 1,
+		})
+	}
+
+	return players, dealtBoard(view.CommunityCards)
 }
 
 // handlePlayerCashOut transfers any remaining funds from player's game session back to main wallet
@@ -873,6 +1175,9 @@ func handlePlayerCashOut(c *Client) {
 	if c.formanceService == nil || c.userID == uuid.Nil {
 		return // Skip if no Formance service or not authenticated
 	}
+	if c.table != nil && c.table.isPractice() {
+		return // Play chips never reach the wallet
+	}
 
 	// Check if player has any active game balance to cash out
 	// For now, we'll implement a simple approach where we check the user's current game balance
@@ -923,110 +1228,79 @@ func handlePlayerCashOut(c *Client) {
 	}
 }
 
-// scheduleAutoHandStart schedules automatic next hand start after a delay
-func scheduleAutoHandStart(table *table) {
+// scheduleAutoHandStart deals the next hand after a delay if the table can
+// carry on. reason is what prompted it, and is recorded as the reason the
+// next hand is pending.
+func scheduleAutoHandStart(table *table, reason string) {
+	if reason == reasonHandEnded || reason == reasonMisdeal {
+		table.transition(models.TableStateSettling, reason)
+	}
+
 	go func() {
 		// Wait 3 seconds to allow players to see the hand results
 		time.Sleep(3 * time.Second)
 
-		// Check if we should auto-start the next hand
-		if shouldAutoStartNextHand(table) {
-			slog.Info("Auto-starting next hand", "table", table.name)
+		// Bots at practice tables make way for humans who joined meanwhile
+		table.balanceBots()
+
+		if state, blocked := autoStartBlocker(table); state != "" {
+			table.transition(state, blocked)
+			return
+		}
+		table.transition(models.TableStateAutoStartPending, reason)
 
-			// Broadcast notification that next hand is starting
-			table.broadcast <- createNewLog("Next hand starting automatically...")
+		// At dealer's choice tables the button picks the game first
+		table.awaitGameChoice()
 
-			// Wait 1 more second for the message to be seen
-			time.Sleep(1 * time.Second)
+		// Broadcast notification that next hand is starting
+		table.broadcast <- createNewLog(table.game.CurrentHandID(), "Next hand starting automatically...")
 
-			// Trigger start game logic - need a dummy client for the existing handler
-			autoStartNextHand(table)
-		} else {
-			slog.Info("Not auto-starting next hand - insufficient players or game conditions not met", "table", table.name)
-		}
+		// Wait 1 more second for the message to be seen
+		time.Sleep(1 * time.Second)
+
+		// Trigger start game logic - need a dummy client for the existing handler
+		autoStartNextHand(table)
 	}()
 }
 
-// shouldAutoStartNextHand checks if conditions are met for automatic hand start
-func shouldAutoStartNextHand(table *table) bool {
+// autoStartBlocker returns the state the table stays in, and why, when the
+// next hand can't be dealt. It returns an empty state when it can.
+func autoStartBlocker(table *table) (string, string) {
 	if table.game == nil {
-		slog.Info("Auto-start validation failed: no game", "table", table.name)
-		return false
+		return models.TableStateWaitingForPlayers, reasonNoGame
 	}
-
-	// Get current game view
-	gameView := table.game.GenerateOmniView()
-	engineView, ok := getEngineView(gameView)
-	if !ok {
-		slog.Info("Auto-start validation failed: cannot get engine view", "table", table.name)
-		return false
+	if table.isReadOnly() {
+		return models.TableStatePaused, reasonReadOnly
 	}
-
-	// Count active players with chips and busted players (for practice mode)
-	activePlayersWithChips := 0
-	bustedPlayers := 0
-	totalConnectedPlayers := 0
-
-	for i, player := range engineView.Players {
-		slog.Info("Checking player for auto-start",
-			"table", table.name,
-			"player_index", i,
-			"username", player.Username,
-			"ready", player.Ready,
-			"stack", player.Stack,
-			"in", player.In)
-
-		// Count all players who are connected (regardless of chips)
-		if player.Username != "" { // Non-empty username means connected player
-			totalConnectedPlayers++
-		}
-
-		if player.Ready && player.Stack > 0 {
-			activePlayersWithChips++
-		} else if player.Username != "" && player.Stack == 0 {
-			// Player is connected but has no chips (busted in practice mode)
-			bustedPlayers++
-		}
+	if table.isPaused() {
+		return models.TableStatePaused, reasonTablePaused
 	}
-
-	slog.Info("Auto-start validation",
-		"table", table.name,
-		"active_players_with_chips", activePlayersWithChips,
-		"busted_players", bustedPlayers,
-		"total_connected_players", totalConnectedPlayers,
-		"running", engineView.Running,
-		"betting", engineView.Betting,
-		"stage", engineView.Stage)
-
-	// Log the situation for debugging
-	if totalConnectedPlayers >= 2 && activePlayersWithChips < 2 {
-		slog.Info("Insufficient active players for auto-start",
-			"table", table.name,
-			"total_connected", totalConnectedPlayers,
-			"active_with_chips", activePlayersWithChips,
-			"busted", bustedPlayers)
+	if !table.callTimeAllowsHand() {
+		return models.TableStatePaused, reasonCallTime
 	}
 
-	// Need at least 2 players with chips to continue
-	if activePlayersWithChips < 2 {
-		slog.Info("Auto-start validation failed: insufficient players", "table", table.name, "count", activePlayersWithChips)
-		return false
+	engineView, ok := getEngineView(table.game.GenerateOmniView())
+	if !ok {
+		return models.TableStateWaitingForPlayers, reasonNoGame
 	}
 
-	// Check game is in correct state (not already running)
-	if engineView.Running {
-		slog.Info("Auto-start validation failed: game already running", "table", table.name)
-		return false
+	// A player started the hand in the meantime
+	if engineView.Running || engineView.Betting {
+		return models.TableStateHandRunning, reasonHandStarted
 	}
 
-	// Game should be in PreDeal stage and not betting
-	if engineView.Betting {
-		slog.Info("Auto-start validation failed: game is betting", "table", table.name)
-		return false
+	// Need at least 2 players with chips, or the table's minimum, to continue
+	ready := 0
+	for _, player := range engineView.Players {
+		if player.Ready && player.Stack > 0 {
+			ready++
+		}
+	}
+	if ready < table.minPlayersToDeal() {
+		return models.TableStateWaitingForPlayers, reasonNotEnoughPlayers
 	}
 
-	slog.Info("Auto-start validation passed", "table", table.name)
-	return true
+	return "", ""
 }
 
 // autoStartNextHand triggers the start game logic automatically
@@ -1048,9 +1322,13 @@ func autoStartNextHand(table *table) {
 			err := legacyGame.Start()
 			if err != nil {
 				slog.Warn("Auto-start failed with legacy game", "error", err, "table", table.name)
+				table.transition(models.TableStateWaitingForPlayers, reasonStartFailed)
 			} else {
+				table.beginHand()
 				// Broadcast game state update
 				table.broadcast <- createUpdatedGame(nil)
+				table.scheduleTurnNudge()
+				table.announceTurn()
 				slog.Info("Auto-started next hand successfully", "table", table.name)
 			}
 		}
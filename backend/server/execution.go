@@ -0,0 +1,190 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/anhbaysgalan1/gp/internal/application/dto"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// executionPath is the one code path allowed to change a table's game state.
+// It is picked when the table is created and a failure on it is never retried
+// on the other path, so an action can't be applied twice.
+type executionPath int
+
+const (
+	executionLegacy executionPath = iota // poker.Game, guarded by the stack audit
+	executionEngine                      // internal/engine, mirrored into poker.Game for clients
+)
+
+func (p executionPath) String() string {
+	if p == executionEngine {
+		return "engine"
+	}
+	return "legacy"
+}
+
+// executionState is a table's execution path and how often the state
+// players see was found to disagree with it
+type executionState struct {
+	mu      sync.Mutex
+	path    executionPath
+	desyncs int
+}
+
+// selectExecutionPath picks the engine when the table has one, and the legacy
+// game otherwise
+func selectExecutionPath(game *SimpleGameAdapter) executionPath {
+	if game != nil && game.engine != nil {
+		return executionEngine
+	}
+	return executionLegacy
+}
+
+// applyExecutionPath puts a table an admin has moved to the engine on the
+// engine path. It runs before the table starts, as the path never changes
+// while it is open. Tables are only moved once the engine has passed the
+// parity suite at their game and stakes, see internal/parity.
+func (t *table) applyExecutionPath(record *models.PokerTable) {
+	if record.ExecutionPath != models.ExecutionPathEngine || t.engine == nil {
+		return
+	}
+
+	t.game.engine = t.engine
+	t.exec.mu.Lock()
+	t.exec.path = selectExecutionPath(t.game)
+	t.exec.mu.Unlock()
+	slog.Info("Table opened on the engine", "table", t.name, "table_id", record.ID)
+}
+
+// executionPath returns the path every action at the table goes through
+func (t *table) executionPath() executionPath {
+	t.exec.mu.Lock()
+	defer t.exec.mu.Unlock()
+	return t.exec.path
+}
+
+// engineAction applies a player action through the engine and checks the
+// integrity of the result. A failed action must leave the game untouched and
+// a successful one must leave the legacy view matching the engine's state.
+// Reports whether the action went through.
+func engineAction(c *Client, name string, amount int64) bool {
+	if c.userID == uuid.Nil {
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required to act at this table"))
+		return false
+	}
+
+	t := c.table
+	game := t.game.GetLegacyGame()
+	pre := game.GenerateOmniView()
+
+	actionErr := t.game.HandlePlayerAction(ctx, c.userID, name, amount)
+	post := game.GenerateOmniView()
+
+	if actionErr != nil {
+		// The engine reported failure, so nothing it did before failing counts
+		if drift := poker.DiffStacks(pre, post); len(drift) > 0 {
+			game.FillFromView(pre)
+			t.desync(c, fmt.Sprintf("engine %s failed after changing stacks", name), violationDetails(drift))
+			return false
+		}
+		slog.Warn("Engine action failed", "table", t.name, "hand_id", t.game.CurrentHandID(), "action", name, "user_id", c.userID, "error", actionErr)
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Action rejected: "+actionErr.Error()))
+		return false
+	}
+
+	if mismatches := t.engineMismatches(post); len(mismatches) > 0 {
+		t.desync(c, fmt.Sprintf("engine %s left the table out of sync", name), mismatches)
+		return false
+	}
+	return true
+}
+
+// engineMismatches compares the legacy view clients are sent against the
+// engine's own state and describes every difference
+func (t *table) engineMismatches(view *poker.GameView) []string {
+	state, err := t.game.engine.GetGameState(ctx, t.game.tableID)
+	if err != nil {
+		return []string{fmt.Sprintf("engine state unavailable: %v", err)}
+	}
+	return compareEngineState(view, state, t.game.playerPositionToUUID)
+}
+
+// compareEngineState matches the seated players of a legacy view to the
+// engine's players by user ID and reports stacks, bets or folds that differ
+func compareEngineState(view *poker.GameView, state *dto.GameStateView, positions map[uint]string) []string {
+	var mismatches []string
+	if view.Running != state.IsRunning {
+		mismatches = append(mismatches, fmt.Sprintf("hand running is %t in the game but %t in the engine", view.Running, state.IsRunning))
+	}
+
+	enginePlayers := make(map[uuid.UUID]dto.PlayerView, len(state.Players))
+	for _, p := range state.Players {
+		enginePlayers[p.ID] = p
+	}
+
+	seen := 0
+	for i, p := range view.Players {
+		if p.Left {
+			continue
+		}
+		userID, err := uuid.Parse(positions[uint(i)])
+		if err != nil {
+			continue
+		}
+		seen++
+
+		ep, ok := enginePlayers[userID]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("player %d (%s) is seated in the game but not in the engine", i, userID))
+			continue
+		}
+		if int64(p.Stack) != ep.Chips {
+			mismatches = append(mismatches, fmt.Sprintf("player %d stack is %d in the game but %d in the engine", i, p.Stack, ep.Chips))
+		}
+		if int64(p.TotalBet) != ep.TotalBet {
+			mismatches = append(mismatches, fmt.Sprintf("player %d total bet is %d in the game but %d in the engine", i, p.TotalBet, ep.TotalBet))
+		}
+		if view.Running && !p.In != ep.IsFolded {
+			mismatches = append(mismatches, fmt.Sprintf("player %d folded is %t in the game but %t in the engine", i, !p.In, ep.IsFolded))
+		}
+	}
+	if seen != len(state.Players) {
+		mismatches = append(mismatches, fmt.Sprintf("%d players are seated in the game but %d in the engine", seen, len(state.Players)))
+	}
+	return mismatches
+}
+
+// desync handles the state players see disagreeing with the execution path:
+// the details are logged, the actor gets a table_desync error and everyone
+// at the table is sent a full snapshot to replace what they hold
+func (t *table) desync(c *Client, reason string, details []string) {
+	t.exec.mu.Lock()
+	t.exec.desyncs++
+	path, desyncs := t.exec.path, t.exec.desyncs
+	t.exec.mu.Unlock()
+
+	slog.Error("Table state out of sync",
+		"table", t.name,
+		"hand_id", t.game.CurrentHandID(),
+		"execution_path", path.String(),
+		"reason", reason,
+		"details", details,
+		"desyncs", desyncs,
+	)
+
+	safeSend(c, createCodedErrorMessage(errorCodeTableDesync, "The table got out of sync and your action was not applied. The table has been refreshed, please try again."))
+	t.broadcast <- createTableResync(t)
+}
+
+func violationDetails(violations []poker.Violation) []string {
+	details := make([]string, len(violations))
+	for i, v := range violations {
+		details[i] = v.String()
+	}
+	return details
+}
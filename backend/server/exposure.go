@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+)
+
+// SetExposureService caps the MNT a user may have in game sessions at once
+func (h *Hub) SetExposureService(exposure *services.ExposureService) {
+	h.exposure = exposure
+}
+
+// checkExposure refuses a buy-in that would take the player over their
+// maximum exposure across all their tables. It reports whether the buy-in
+// may go ahead.
+func checkExposure(c *Client, buyIn int64) bool {
+	if c.hub == nil || c.hub.exposure == nil {
+		return true
+	}
+
+	err := c.hub.exposure.Check(ctx, c.userID, buyIn)
+	if err == nil {
+		return true
+	}
+
+	var limitErr *services.ExposureLimitError
+	if errors.As(err, &limitErr) {
+		safeSend(c, createCodedErrorMessage(errorCodeExposureLimit, limitErr.Error()))
+		return false
+	}
+	slog.Warn("Failed to check maximum exposure", "user_id", c.userID, "error", err)
+	safeSend(c, createCodedErrorMessage(errorCodeBalanceUnavailable, "Failed to check balance. Please try again."))
+	return false
+}
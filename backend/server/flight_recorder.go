@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
+)
+
+// Commands and messages recorded without their text
+var unrecordedText = map[string]bool{
+	actionSendMessage:       true,
+	actionSendDirectMessage: true,
+	actionNewMessage:        true,
+	actionNewDirectMessage:  true,
+}
+
+// SetFlightRecorder has tables opened from now on keep their most recent
+// commands, broadcasts and hand states, up to entries of them, passing each
+// to sink as well when there is one
+func (h *Hub) SetFlightRecorder(entries int, sink flightrecorder.Sink) {
+	h.recorderEntries = entries
+	h.recorderSink = sink
+}
+
+// TableRecording returns what the table has recorded, oldest first. It
+// reports false when no such table is open here.
+func (h *Hub) TableRecording(name string) (*flightrecorder.Recording, bool) {
+	t := h.findTableByName(name)
+	if t == nil {
+		return nil, false
+	}
+	if recording := t.recorder.Recording(); recording != nil {
+		return recording, true
+	}
+	return &flightrecorder.Recording{Table: t.name, Entries: []flightrecorder.Entry{}}, true
+}
+
+// recordCommand records a command read from the client at the table it was
+// at, or the one it joined
+func (c *Client) recordCommand(at *table, message []byte, err error) {
+	if at == nil {
+		at = c.table
+	}
+	if at == nil || at.recorder == nil {
+		return
+	}
+
+	var cmd base
+	_ = json.Unmarshal(message, &cmd)
+	if unrecordedText[cmd.Action] {
+		message = nil
+	}
+	at.recorder.Inbound(c.userID.String(), cmd.Action, message, err)
+}
+
+// recordBroadcast records a message sent to everyone at the table
+func (t *table) recordBroadcast(message []byte) {
+	if t.recorder == nil {
+		return
+	}
+
+	var msg base
+	_ = json.Unmarshal(message, &msg)
+	// A tick a second of the action clock would push everything else out
+	if msg.Action == actionActionTimer {
+		return
+	}
+	if unrecordedText[msg.Action] {
+		t.recorder.Record(flightrecorder.Entry{Kind: flightrecorder.KindOutbound, Action: msg.Action})
+		return
+	}
+	t.recorder.Outbound(message)
+}
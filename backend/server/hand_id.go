@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// maxShortCodeLetters caps the readable part of a table short code
+const maxShortCodeLetters = 6
+
+// fallbackHandSeq is used when Redis is unavailable. Seeding with the current
+// time keeps IDs from colliding with sequences issued before a restart.
+var fallbackHandSeq = time.Now().Unix()
+
+// tableShortCode builds a stable, human-readable code for a table from its name,
+// e.g. "High Rollers #2" -> "HIGHRO-1A2B". The hash suffix keeps codes for
+// similarly named tables apart.
+func tableShortCode(name string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if letters.Len() >= maxShortCodeLetters {
+			break
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			letters.WriteRune(r)
+		}
+	}
+	if letters.Len() == 0 {
+		letters.WriteString("TBL")
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s-%04X", letters.String(), h.Sum32()&0xFFFF)
+}
+
+// formatHandID combines a table short code and sequence into a hand ID
+func formatHandID(shortCode string, sequence int64) string {
+	return fmt.Sprintf("%s-%06d", shortCode, sequence)
+}
+
+// nextHandSequence returns the next hand number for the table. Sequences live
+// in Redis so they survive restarts and are shared by every server instance.
+func (t *table) nextHandSequence() int64 {
+	if t.rdb != nil {
+		seq, err := t.rdb.Incr(ctx, "hand_seq:"+t.shortCode).Result()
+		if err == nil {
+			return seq
+		}
+		slog.Warn("Failed to increment hand sequence in Redis, using local sequence", "table", t.name, "error", err)
+	}
+	return atomic.AddInt64(&fallbackHandSeq, 1)
+}
+
+// SetHandHistoryService records hands under the room's privacy policy
+func (h *Hub) SetHandHistoryService(handHistory *services.HandHistoryService) {
+	h.handHistory = handHistory
+}
+
+// beginHand assigns a new hand ID at deal time and records the hand history row
+func (t *table) beginHand() string {
+	sequence := t.nextHandSequence()
+	handID := formatHandID(t.shortCode, sequence)
+	t.game.SetHandID(handID)
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	t.activity.recordAction(view, time.Now())
+	t.recorder.Snapshot(handID, view)
+	t.stakes.begin(handID, view)
+	t.transition(models.TableStateHandRunning, reasonHandStarted)
+
+	if t.handHistoryService != nil {
+		tableID := t.id
+		if id := t.game.GetTableID(); id != nil {
+			tableID = *id
+		}
+		if err := t.handHistoryService.RecordHandStart(ctx, handID, tableID, t.name, sequence, string(t.game.GetLegacyGame().Variant())); err != nil {
+			slog.Warn("Failed to record hand start", "table", t.name, "hand_id", handID, "error", err)
+		}
+
+		// Everyone at the table, seated or watching, may read this hand later
+		var present []uuid.UUID
+		for _, c := range t.connectedClients() {
+			if c.userID != uuid.Nil {
+				present = append(present, c.userID)
+			}
+		}
+		if err := t.handHistoryService.RecordPresence(ctx, handID, present); err != nil {
+			slog.Warn("Failed to record hand presence", "table", t.name, "hand_id", handID, "error", err)
+		}
+	}
+
+	// Pick up policy changes made since the last hand, then count it
+	// against the final hands if call time has passed
+	t.refreshPolicy()
+	t.countCallTimeHand(handID)
+
+	slog.Info("Hand started", "table", t.name, "hand_id", handID)
+	return handID
+}
+
+// recordLatePresence lets a user who joins mid-hand read the rest of that
+// hand's history. Called from the table loop, so the write runs separately.
+func (t *table) recordLatePresence(c *Client) {
+	if t.handHistoryService == nil || c.userID == uuid.Nil {
+		return
+	}
+
+	handID := t.game.CurrentHandID()
+	view, ok := getEngineView(t.game.GenerateOmniView())
+	if handID == "" || !ok || !view.Running {
+		return
+	}
+
+	go func() {
+		if err := t.handHistoryService.RecordPresence(ctx, handID, []uuid.UUID{c.userID}); err != nil {
+			slog.Warn("Failed to record hand presence", "table", t.name, "hand_id", handID, "error", err)
+		}
+	}()
+}
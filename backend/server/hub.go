@@ -1,10 +1,18 @@
 package server
 
 import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/anhbaysgalan1/gp/internal/database"
 	"github.com/anhbaysgalan1/gp/internal/engine"
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
 	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -17,9 +25,52 @@ type Hub struct {
 	register       chan *Client
 	unregister     chan *Client
 	tables         map[*table]bool
+	tablesMu       sync.RWMutex
 	pokerEngine    engine.PokerEngine
 	tableService   *services.TableService
 	sessionService *services.GameSessionService
+	handHistory    *services.HandHistoryService
+	pushService    *services.PushService
+	directMessages *services.DirectMessageService
+	seating        *services.SeatingService
+	tableBans      *services.TableBanService
+	seatHolds      *services.SeatReservationService
+	tutorials      *services.TutorialService
+	moderation     *services.ChatModerationService
+	// Authenticated connections by user, for direct messages
+	userClients map[uuid.UUID]map[*Client]bool
+	usersMu     sync.RWMutex
+	// Origin check for WebSocket upgrades, nil allows every origin
+	checkOrigin func(r *http.Request) bool
+	// Platform-wide read-only switch, inherited by tables opened while set
+	readOnly readOnlyState
+	// Outbound queue size for new clients and the metrics all queues report to
+	sendQueueSize int
+	sendMetrics   *sendQueueMetrics
+	// Most time added to a player's decisions for their connection's latency
+	latencyAllowance time.Duration
+	// Open WebSocket connections, readable outside the hub loop
+	connected atomic.Int64
+	// Seats of players whose connection dropped, held for them to reconnect
+	reconnect reconnectRegistry
+	// Name in the cluster registry and the table leases held under it
+	instanceID string
+	cluster    clusterState
+	// Responsible gaming limits on rebuys after losing a full stack
+	bankroll *services.BankrollService
+	// Most MNT a user may have in game sessions at once
+	exposure *services.ExposureService
+	// Rake taken from live hands, credited towards rakeback
+	loyalty *services.LoyaltyService
+	// Promotional windows in which hands are not raked
+	rakeFree *services.RakeFreeService
+	// Domain events for analytics, fraud and notification consumers
+	statsEvents statsevents.Publisher
+	// Players bots fill practice tables up to
+	practiceTablePlayers int
+	// What each table records for debugging, see SetFlightRecorder
+	recorderEntries int
+	recorderSink    flightrecorder.Sink
 }
 
 func NewHub(db *gorm.DB) (*Hub, error) {
@@ -42,6 +93,12 @@ func NewHubWithRedis(db *gorm.DB, redisClient *redis.Client) (*Hub, error) {
 	var pokerEngine engine.PokerEngine
 	var tableService *services.TableService
 	var sessionService *services.GameSessionService
+	var handHistory *services.HandHistoryService
+	var directMessages *services.DirectMessageService
+	var seating *services.SeatingService
+	var tableBans *services.TableBanService
+	var tutorials *services.TutorialService
+	var moderation *services.ChatModerationService
 
 	// Initialize poker engine and services only if database is provided
 	if db != nil {
@@ -61,6 +118,12 @@ func NewHubWithRedis(db *gorm.DB, redisClient *redis.Client) (*Hub, error) {
 		wrappedDB := &database.DB{DB: db}
 		tableService = services.NewTableService(wrappedDB)
 		sessionService = services.NewGameSessionService(wrappedDB)
+		handHistory = services.NewHandHistoryService(wrappedDB, services.DefaultHandHistoryPolicy())
+		directMessages = services.NewDirectMessageService(wrappedDB)
+		seating = services.NewSeatingService(wrappedDB)
+		tableBans = services.NewTableBanService(wrappedDB)
+		tutorials = services.NewTutorialService(wrappedDB)
+		moderation = services.NewChatModerationService(wrappedDB)
 	}
 
 	hub := &Hub{
@@ -73,11 +136,34 @@ func NewHubWithRedis(db *gorm.DB, redisClient *redis.Client) (*Hub, error) {
 		pokerEngine:    pokerEngine,
 		tableService:   tableService,
 		sessionService: sessionService,
+		handHistory:    handHistory,
+		directMessages: directMessages,
+		seating:        seating,
+		tableBans:      tableBans,
+		tutorials:      tutorials,
+		moderation:     moderation,
+		userClients:    make(map[uuid.UUID]map[*Client]bool),
+		sendQueueSize:  defaultSendQueueSize,
+		sendMetrics:    &sendQueueMetrics{},
+		instanceID:     "local",
 	}
+	hub.cluster.startedAt = time.Now()
+	hub.reconnect.window = defaultReconnectWindow
+	hub.reconnect.seats = make(map[uuid.UUID]*heldSeat)
 	return hub, nil
 }
 
+// SetOriginCheck restricts which origins may open WebSocket connections
+func (h *Hub) SetOriginCheck(check func(r *http.Request) bool) {
+	h.checkOrigin = check
+}
+
 func (h *Hub) Run() {
+	if h.rdb != nil {
+		go h.subscribeDirectMessages()
+		go h.runClusterHeartbeat()
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -92,34 +178,63 @@ func (h *Hub) Run() {
 
 func (h *Hub) registerClient(client *Client) {
 	h.clients[client] = true
+	h.connected.Store(int64(len(h.clients)))
+	h.trackUserClient(client)
 }
 
 func (h *Hub) unregisterClient(client *Client) {
 	if _, ok := h.clients[client]; ok {
 		delete(h.clients, client)
-		close(client.send)
+		h.connected.Store(int64(len(h.clients)))
+		h.untrackUserClient(client)
+		client.send.close()
 	}
 }
 
 func (h *Hub) broadcastToClients(message []byte) {
 	for client := range h.clients {
-		select {
-		case client.send <- message:
-		default:
-			close(client.send)
+		if err := client.send.push(message); err != nil {
+			h.untrackUserClient(client)
+			client.send.close()
 			delete(h.clients, client)
+			h.connected.Store(int64(len(h.clients)))
 		}
 	}
 }
 
 func (h *Hub) createTable(name string) *table {
-	table := newTable(name, h.rdb, h.pokerEngine, h.tableService, h.sessionService)
+	table := newTable(name, h.rdb, h.pokerEngine, h.tableService, h.sessionService, h.handHistory)
+	table.pushService = h.pushService
+	table.rakeFree = h.rakeFree
+	table.statsEvents = h.statsEvents
+	table.bots.players = h.practiceTablePlayers
+	table.latency.max = h.latencyAllowance
+	table.recorder = flightrecorder.New(name, h.recorderEntries, h.recorderSink)
+	if enabled, reason := h.readOnly.get(); enabled {
+		table.readOnly.set(true, reason)
+	}
+	if h.tableService != nil {
+		if record, err := h.tableService.GetTableByName(ctx, name); err == nil {
+			table.game.adopt(record)
+			table.applyExecutionPath(record)
+		}
+	}
+	go table.refreshPolicy()
+	go table.watchShortHanded()
+	go table.watchSittingOut()
+	go table.runTurnClock()
 	go table.run()
+	go h.claimTableLease(name)
+	h.tablesMu.Lock()
 	h.tables[table] = true
+	h.tablesMu.Unlock()
 	return table
 }
 
 func (h *Hub) findTableByName(name string) *table {
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+
 	var foundTable *table
 	for table := range h.tables {
 		if table.name == name {
@@ -128,3 +243,29 @@ func (h *Hub) findTableByName(name string) *table {
 	}
 	return foundTable
 }
+
+// HasLiveSession reports whether a game session still belongs to a running
+// table, either because the table is open here or on another live instance,
+// or because the user is connected with that session. Sessions at tables
+// lost in a restart report false.
+func (h *Hub) HasLiveSession(userID, tableID, sessionID uuid.UUID) bool {
+	h.tablesMu.RLock()
+	for t := range h.tables {
+		if id := t.game.GetTableID(); id != nil && *id == tableID {
+			h.tablesMu.RUnlock()
+			return true
+		}
+	}
+	h.tablesMu.RUnlock()
+
+	h.usersMu.RLock()
+	for c := range h.userClients[userID] {
+		if c.table != nil && c.sessionID == sessionID {
+			h.usersMu.RUnlock()
+			return true
+		}
+	}
+	h.usersMu.RUnlock()
+
+	return h.tableLeasedElsewhere(tableID)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// latencySmoothing is how many samples it takes a new round trip time to
+// fully replace the estimate, as in TCP's smoothed RTT
+const latencySmoothing = 8
+
+// minLatencyProbeInterval keeps a client asking to sync from flooding its own
+// connection with pings
+const minLatencyProbeInterval = time.Second
+
+// latencyState estimates a connection's round trip time from WebSocket
+// pings that carry the time they were sent. Browsers answer pings
+// themselves, so page scripts can't slow the answer to earn more time.
+type latencyState struct {
+	mu        sync.Mutex
+	rtt       time.Duration // Smoothed
+	measured  bool
+	lastProbe time.Time
+}
+
+// timeSync asks for the server's clock. Clients send it on connecting and
+// now and then after, to line action countdowns up with the server's.
+type timeSync struct {
+	base             // actionTimeSync
+	ClientTime int64 `json:"client_time"` // Milliseconds since the epoch by the client's clock
+}
+
+// timeSyncReply gives the client the server's clock. The client works out
+// its offset as server_time less the midpoint of sending the request and
+// reading the reply.
+type timeSyncReply struct {
+	base              // actionTimeSyncReply
+	ClientTime  int64 `json:"client_time"`  // Echoed from the request
+	ServerTime  int64 `json:"server_time"`  // Milliseconds since the epoch
+	RTTMs       int64 `json:"rtt_ms"`       // The server's estimate, 0 until it has measured one
+	AllowanceMs int64 `json:"allowance_ms"` // Added to this player's decisions at their table
+}
+
+// SetLatencyAllowance caps the time added to a player's decisions for their
+// connection's round trip, 0 to add none
+func (h *Hub) SetLatencyAllowance(limit time.Duration) {
+	h.latencyAllowance = limit
+}
+
+// observe folds a round trip sample into the estimate
+func (l *latencyState) observe(sample time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.measured {
+		l.rtt, l.measured = sample, true
+		return
+	}
+	l.rtt += (sample - l.rtt) / latencySmoothing
+}
+
+// estimate returns the smoothed round trip time, and false before any has
+// been measured
+func (l *latencyState) estimate() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rtt, l.measured
+}
+
+// startProbe reports whether it's been long enough since the last probe to
+// send another
+func (l *latencyState) startProbe(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastProbe) < minLatencyProbeInterval {
+		return false
+	}
+	l.lastProbe = now
+	return true
+}
+
+// pingPayload is a ping's application data: when it was sent
+func pingPayload(now time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano()))
+}
+
+// handlePong resets the read deadline and, for pings that carried their
+// send time, takes a round trip sample
+func (c *Client) handlePong(appData string) error {
+	now := time.Now()
+	c.conn.SetReadDeadline(now.Add(pongWait))
+	if len(appData) != 8 {
+		return nil
+	}
+	sent := time.Unix(0, int64(binary.BigEndian.Uint64([]byte(appData))))
+	if rtt := now.Sub(sent); rtt >= 0 && rtt < pongWait {
+		c.latency.observe(rtt)
+	}
+	return nil
+}
+
+// probeLatency pings the client for a round trip sample. Control frames may
+// be written alongside writePump.
+func (c *Client) probeLatency() {
+	now := time.Now()
+	if c.conn == nil || !c.latency.startProbe(now) {
+		return
+	}
+	if err := c.conn.WriteControl(websocket.PingMessage, pingPayload(now), now.Add(writeWait)); err != nil {
+		slog.Default().Debug("Write latency probe", "user_id", c.userID, "error", err)
+	}
+}
+
+// handleTimeSync answers a time-sync with the server's clock and pings the
+// client to refine the round trip estimate
+func handleTimeSync(c *Client, clientTime int64) {
+	c.probeLatency()
+
+	rtt, _ := c.latency.estimate()
+	var allowance time.Duration
+	if t := c.table; t != nil {
+		allowance = t.latency.allowance(c.userID)
+	}
+	resp, err := json.Marshal(timeSyncReply{
+		base:        base{actionTimeSyncReply},
+		ClientTime:  clientTime,
+		ServerTime:  time.Now().UnixMilli(),
+		RTTMs:       rtt.Milliseconds(),
+		AllowanceMs: allowance.Milliseconds(),
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal time sync reply", "error", err)
+		return
+	}
+	safeSend(c, resp)
+}
+
+// tableLatency is the connection latency of each user at a table, for the
+// allowance on their decisions. Clients are tracked by the table loop as
+// they come and go, and read wherever a decision is timed.
+type tableLatency struct {
+	mu    sync.Mutex
+	max   time.Duration // Cap on the allowance, 0 to add none
+	users map[uuid.UUID]*latencyState
+}
+
+// track notes the client's connection as the one its user is at the table on
+func (l *tableLatency) track(c *Client) {
+	if c.userID == uuid.Nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.users == nil {
+		l.users = make(map[uuid.UUID]*latencyState)
+	}
+	l.users[c.userID] = &c.latency
+}
+
+// untrack forgets the client's connection, unless its user has a newer one
+func (l *tableLatency) untrack(c *Client) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.users[c.userID] == &c.latency {
+		delete(l.users, c.userID)
+	}
+}
+
+// allowance is the time added to the user's decisions: the round trip of
+// their connection to the table, up to the cap
+func (l *tableLatency) allowance(userID uuid.UUID) time.Duration {
+	l.mu.Lock()
+	state, limit := l.users[userID], l.max
+	l.mu.Unlock()
+	if state == nil || limit <= 0 {
+		return 0
+	}
+
+	rtt, ok := state.estimate()
+	if !ok {
+		return 0
+	}
+	return min(rtt, limit)
+}
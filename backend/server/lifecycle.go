@@ -0,0 +1,156 @@
+package server
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// lifecycleHistory is how many transitions a table keeps for the admin live
+// view
+const lifecycleHistory = 20
+
+// Reasons for lifecycle transitions
+const (
+	reasonTableOpened      = "table_opened"
+	reasonHandStarted      = "hand_started"
+	reasonHandEnded        = "hand_ended"
+	reasonMisdeal          = "misdeal"
+	reasonAutoStart        = "auto_start"
+	reasonStartFailed      = "start_failed"
+	reasonNotEnoughPlayers = "not_enough_players"
+	reasonNoGame           = "no_game"
+	reasonAlreadyRunning   = "already_running"
+	reasonBetting          = "betting"
+	reasonReadOnly         = "read_only"
+	reasonReadOnlyLifted   = "read_only_lifted"
+	reasonCallTime         = "call_time"
+	reasonHandFrozen       = "hand_frozen"
+	reasonHandResumed      = "hand_resumed"
+	reasonPlayerSeated     = "player_seated"
+	reasonTablePaused      = "table_paused"
+	reasonTableResumed     = "table_resumed"
+)
+
+// tableLifecycle is where a table is in the cycle of dealing hands, and how
+// it got there. Every move is logged and counted, so "why didn't the next
+// hand start?" can be answered from metrics and the admin live view.
+type tableLifecycle struct {
+	mu          sync.Mutex
+	state       string
+	reason      string
+	since       time.Time
+	history     []models.TableTransition // Oldest first
+	transitions map[lifecycleKey]int64
+}
+
+// lifecycleKey counts transitions into a state for a reason
+type lifecycleKey struct {
+	to     string
+	reason string
+}
+
+// open starts a new table's lifecycle waiting for players
+func (l *tableLifecycle) open(now time.Time) {
+	l.state = models.TableStateWaitingForPlayers
+	l.reason = reasonTableOpened
+	l.since = now
+	l.transitions = make(map[lifecycleKey]int64)
+}
+
+// transition moves the table to state. Staying in the same state for the
+// same reason is not a transition, so repeated checks aren't counted twice.
+func (t *table) transition(state, reason string) {
+	l := &t.lifecycle
+	l.mu.Lock()
+	if l.state == state && l.reason == reason {
+		l.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	from := l.state
+	l.history = append(l.history, models.TableTransition{From: from, To: state, Reason: reason, At: now})
+	if len(l.history) > lifecycleHistory {
+		l.history = l.history[len(l.history)-lifecycleHistory:]
+	}
+	l.transitions[lifecycleKey{to: state, reason: reason}]++
+	l.state, l.reason, l.since = state, reason, now
+	l.mu.Unlock()
+
+	slog.Info("Table lifecycle", "table", t.name, "from", from, "to", state, "reason", reason, "hand_id", t.game.CurrentHandID())
+}
+
+// lifecycleState returns the table's state, why it entered it and when
+func (t *table) lifecycleState() (string, string, time.Time) {
+	t.lifecycle.mu.Lock()
+	defer t.lifecycle.mu.Unlock()
+	return t.lifecycle.state, t.lifecycle.reason, t.lifecycle.since
+}
+
+// liveTable is the table as shown in the admin live view
+func (t *table) liveTable() models.LiveTable {
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	live := models.LiveTable{
+		Table:      t.name,
+		HandID:     t.game.CurrentHandID(),
+		Clients:    int(t.connected.Load()),
+		MinPlayers: t.minPlayersToDeal(),
+	}
+	if t.game.IsPersistent() {
+		live.TableID = t.game.GetTableID()
+	}
+
+	t.lifecycle.mu.Lock()
+	live.State, live.Reason, live.Since = t.lifecycle.state, t.lifecycle.reason, t.lifecycle.since
+	live.Transitions = make([]models.TableTransition, 0, len(t.lifecycle.history))
+	for i := len(t.lifecycle.history) - 1; i >= 0; i-- {
+		live.Transitions = append(live.Transitions, t.lifecycle.history[i])
+	}
+	t.lifecycle.mu.Unlock()
+
+	for _, p := range view.Players {
+		if p.Left {
+			continue
+		}
+		live.Seated++
+		if p.Ready && p.Stack > 0 {
+			live.Ready++
+		}
+	}
+	return live
+}
+
+// LiveTables lists the tables open on this server with their lifecycle
+// state, for the admin live view
+func (h *Hub) LiveTables() []models.LiveTable {
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	live := make([]models.LiveTable, len(tables))
+	for i, t := range tables {
+		live[i] = t.liveTable()
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Table < live[j].Table })
+	return live
+}
+
+// lifecycleCounts returns how often the table has entered each state for
+// each reason
+func (t *table) lifecycleCounts() map[lifecycleKey]int64 {
+	t.lifecycle.mu.Lock()
+	defer t.lifecycle.mu.Unlock()
+
+	counts := make(map[lifecycleKey]int64, len(t.lifecycle.transitions))
+	for key, count := range t.lifecycle.transitions {
+		counts[key] = count
+	}
+	return counts
+}
@@ -1,21 +1,36 @@
 package server
 
+import "encoding/json"
+
 // inbound (client) actions
 const (
-	actionJoinTable    string = "join-table"
-	actionLeaveTable   string = "leave-table"
-	actionSendMessage  string = "send-message"
-	actionSendLog      string = "send-log"
-	actionNewPlayer    string = "new-player"
-	actionTakeSeat     string = "take-seat"
-	actionStartGame    string = "start-game"
-	actionDealGame     string = "deal-game"
-	actionResetGame    string = "reset-game"
-	actionPlayerCall   string = "player-call"
-	actionPlayerCheck  string = "player-check"
-	actionPlayerRaise  string = "player-raise"
-	actionPlayerFold   string = "player-fold"
-	actionGetBalance   string = "get-balance"
+	actionJoinTable   string = "join-table"
+	actionLeaveTable  string = "leave-table"
+	actionSendMessage string = "send-message"
+	actionSendLog     string = "send-log"
+	actionNewPlayer   string = "new-player"
+	actionTakeSeat    string = "take-seat"
+	actionStartGame   string = "start-game"
+	actionDealGame    string = "deal-game"
+	actionResetGame   string = "reset-game"
+	actionPlayerCall  string = "player-call"
+	actionPlayerCheck string = "player-check"
+	actionPlayerRaise string = "player-raise"
+	actionPlayerFold  string = "player-fold"
+	actionGetBalance  string = "get-balance"
+	actionClientHello string = "client-hello"
+	actionTimeSync    string = "time-sync"
+
+	actionSendDirectMessage string = "send-direct-message"
+	actionPartialCashOut    string = "partial-cash-out"
+	actionSetTrainingMode   string = "set-training-mode"
+	actionStartTutorial     string = "start-tutorial"
+	actionLeaveTutorial     string = "leave-tutorial"
+	actionChooseGame        string = "choose-game"
+	actionSitIn             string = "sit-in"
+	actionSitOut            string = "sit-out"
+	actionMutePlayer        string = "mute-player"
+	actionUnmutePlayer      string = "unmute-player"
 )
 
 type base struct {
@@ -68,27 +83,89 @@ type dealGame struct {
 	base // actionDealGame
 }
 
+// Betting actions carry the action token of the decision they answer. It is
+// required from clients with the action-tokens capability.
+
 type playerCall struct {
-	base // actionPlayerCall
+	base               // actionPlayerCall
+	ActionToken string `json:"action_token,omitempty"`
 }
 
 type playerCheck struct {
-	base // actionPlayerCheck
+	base               // actionPlayerCheck
+	ActionToken string `json:"action_token,omitempty"`
 }
 
 type playerRaise struct {
-	base        // actionPlayerRaise
-	Amount uint `json:"amount"`
+	base               // actionPlayerRaise
+	Amount      uint   `json:"amount"`
+	ActionToken string `json:"action_token,omitempty"`
 }
 
 type playerFold struct {
-	base // actionPlayerFold
+	base               // actionPlayerFold
+	ActionToken string `json:"action_token,omitempty"`
 }
 
 type getBalance struct {
 	base // actionGetBalance
 }
 
+type sitIn struct {
+	base // actionSitIn
+}
+
+type sitOut struct {
+	base // actionSitOut
+}
+
+type startTutorial struct {
+	base // actionStartTutorial
+}
+
+type leaveTutorial struct {
+	base // actionLeaveTutorial
+}
+
+type clientHello struct {
+	base                  // actionClientHello
+	Capabilities []string `json:"capabilities"`
+	Version      int      `json:"version,omitempty"` // Protocol version to switch to, 0 to keep the current one
+}
+
+type sendDirectMessage struct {
+	base               // actionSendDirectMessage
+	RecipientID string `json:"recipient_id"`
+	Message     string `json:"message"`
+}
+
+type partialCashOut struct {
+	base        // actionPartialCashOut
+	Amount uint `json:"amount"` // 0 withdraws everything above the table maximum
+}
+
+type setTrainingMode struct {
+	base         // actionSetTrainingMode
+	Enabled bool `json:"enabled"`
+}
+
+type chooseGame struct {
+	base        // actionChooseGame
+	Game string `json:"game"` // One of the games offered in the prompt
+}
+
+type mutePlayer struct {
+	base           // actionMutePlayer
+	UserID  string `json:"user_id"`
+	Minutes int    `json:"minutes"` // 0 until unmuted
+	Reason  string `json:"reason,omitempty"`
+}
+
+type unmutePlayer struct {
+	base          // actionUnmutePlayer
+	UserID string `json:"user_id"`
+}
+
 // outbound (server) actions
 const (
 	actionNewMessage       string = "new-message"
@@ -96,6 +173,64 @@ const (
 	actionUpdateGame       string = "update-game"
 	actionUpdatePlayerUUID string = "update-player-uuid"
 	actionUpdateBalance    string = "update-balance"
+	actionUpdateGameDelta  string = "update-game-delta"
+	actionServerHello      string = "server-hello"
+	actionError            string = "error"
+	actionWarning          string = "warning"
+	actionSuccess          string = "success"
+	actionNewDirectMessage string = "new-direct-message"
+	actionTrainingSummary  string = "training-summary"
+	actionTutorialStep     string = "tutorial-step"
+	actionTutorialComplete string = "tutorial-complete"
+	actionActionTurn       string = "action-turn" // Only sent to clients with the action-tokens capability
+	actionCommandDuplicate string = "command-duplicate"
+	actionGameChoicePrompt string = "choose-game-prompt"
+	actionPlayerStatus     string = "player-status"
+	actionActionTimer      string = "action-timer"
+	actionTableMove        string = "table-move"
+	actionTimeSyncReply    string = "time-sync-reply"
+
+	// Animation cues, only sent to clients with the animation-cues capability
+	actionFlopDealt  string = "flop-dealt"
+	actionTurnDealt  string = "turn-dealt"
+	actionRiverDealt string = "river-dealt"
+	actionPotAwarded string = "pot-awarded"
+)
+
+// structured error codes, only sent to clients with the structured-errors capability
+const (
+	errorCodeGeneric             string = "generic"
+	errorCodeAuthRequired        string = "auth_required"
+	errorCodeInvalidBuyIn        string = "invalid_buy_in"
+	errorCodeInsufficientBalance string = "insufficient_balance"
+	errorCodeBalanceUnavailable  string = "balance_unavailable"
+	errorCodeSeatUnavailable     string = "seat_unavailable"
+	errorCodeTransferFailed      string = "transfer_failed"
+	errorCodeActionRejected      string = "action_rejected"
+	errorCodeInvalidMessage      string = "invalid_message"
+	errorCodeMessageBlocked      string = "message_blocked"
+	errorCodeRateLimited         string = "rate_limited"
+	errorCodeCashOutDenied       string = "cash_out_denied"
+	errorCodeTrainingUnavailable string = "training_unavailable"
+	errorCodeTutorialUnavailable string = "tutorial_unavailable"
+	errorCodeMaintenance         string = "table_maintenance"
+	errorCodeSessionConflict     string = "session_conflict"
+	errorCodeMinPlayTime         string = "min_play_time"
+	errorCodeChatMuted           string = "chat_muted"
+	errorCodeMessageModerated    string = "message_moderated"
+	errorCodeRebuyCooldown       string = "rebuy_cooldown"
+	errorCodeTableDesync         string = "table_desync"
+	errorCodeNotYourTurn         string = "not_your_turn"
+	errorCodeStaleActionToken    string = "stale_action_token"
+	errorCodeHandFrozen          string = "hand_frozen"
+	errorCodeExposureLimit       string = "exposure_limit"
+	errorCodeGameChoiceRejected  string = "game_choice_rejected"
+	errorCodeTableBanned         string = "table_banned"
+	errorCodeRemovedFromTable    string = "removed_from_table"
+	errorCodeNotModerator        string = "not_moderator"
+	errorCodeUnsupportedVersion  string = "unsupported_version"
+	errorCodeUnknownAction       string = "unknown_action"
+	errorCodeTableNotFound       string = "table_not_found"
 )
 
 type newMessage struct {
@@ -104,6 +239,7 @@ type newMessage struct {
 	Message   string `json:"message"`
 	Username  string `json:"username"`
 	Timestamp string `json:"timestamp"`
+	HandID    string `json:"hand_id,omitempty"`
 }
 
 type newLog struct {
@@ -111,20 +247,25 @@ type newLog struct {
 	Id        string `json:"uuid"`
 	Message   string `json:"message"`
 	Timestamp string `json:"timestamp"`
+	HandID    string `json:"hand_id,omitempty"`
 }
 
 type updateGame struct {
-	base                 // actionUpdateGame
-	Game interface{} `json:"game"`
+	base                     // actionUpdateGame
+	Game        interface{}  `json:"game"`
 	SessionInfo *SessionInfo `json:"session_info,omitempty"`
+	Resync      bool         `json:"resync,omitempty"` // Full snapshot replacing the client's state, never sent as a delta
+	// The game as each viewer may see it, built with Game and picked from
+	// on delivery. Never sent to a client.
+	Views map[string]interface{} `json:"views,omitempty"`
 }
 
 type SessionInfo struct {
-	UserID       string `json:"user_id"`
-	SessionID    string `json:"session_id,omitempty"`
-	SeatNumber   *int   `json:"seat_number,omitempty"`
-	IsSeated     bool   `json:"is_seated"`
-	HasSession   bool   `json:"has_session"`
+	UserID     string `json:"user_id"`
+	SessionID  string `json:"session_id,omitempty"`
+	SeatNumber *int   `json:"seat_number,omitempty"`
+	IsSeated   bool   `json:"is_seated"`
+	HasSession bool   `json:"has_session"`
 }
 
 type updatePlayerUUID struct {
@@ -133,12 +274,125 @@ type updatePlayerUUID struct {
 }
 
 type updateBalance struct {
-	base                    // actionUpdateBalance
-	MainBalance    int64    `json:"main_balance"`
-	GameBalance    int64    `json:"game_balance"`
-	Currency       string   `json:"currency"`
-	TransactionID  string   `json:"transaction_id,omitempty"`
-	ChangeAmount   int64    `json:"change_amount,omitempty"`
-	ChangeType     string   `json:"change_type,omitempty"` // "buy_in", "win", "cash_out", "transfer_in", "transfer_out"
-	Timestamp      string   `json:"timestamp"`
+	base                 // actionUpdateBalance
+	MainBalance   int64  `json:"main_balance"`
+	GameBalance   int64  `json:"game_balance"`
+	Currency      string `json:"currency"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	ChangeAmount  int64  `json:"change_amount,omitempty"`
+	ChangeType    string `json:"change_type,omitempty"` // "buy_in", "win", "cash_out", "transfer_in", "transfer_out"
+	Timestamp     string `json:"timestamp"`
+}
+
+type updateGameDelta struct {
+	base                                   // actionUpdateGameDelta
+	Changed     map[string]json.RawMessage `json:"changed"`
+	Removed     []string                   `json:"removed,omitempty"`
+	SessionInfo *SessionInfo               `json:"session_info,omitempty"`
+}
+
+type serverHello struct {
+	base                       // actionServerHello
+	Capabilities      []string `json:"capabilities"`
+	Supported         []string `json:"supported"`
+	ProtocolVersion   int      `json:"protocol_version"`
+	SupportedVersions []int    `json:"supported_versions"`
+	Actions           []string `json:"actions,omitempty"` // Actions accepted in envelopes, for protocol 2 clients
+}
+
+type errorMessage struct {
+	base                     // actionError
+	Code              string `json:"code"` // Stripped for clients without the structured-errors capability
+	Message           string `json:"message"`
+	Time              string `json:"time"`
+	SupportedVersions []int  `json:"supported_versions,omitempty"` // With errorCodeUnsupportedVersion
+}
+
+type noticeMessage struct {
+	base           // actionWarning, actionSuccess
+	Message string `json:"message"`
+	Time    string `json:"time"`
+}
+
+type newDirectMessage struct {
+	base                  // actionNewDirectMessage
+	Id             string `json:"uuid"`
+	SenderID       string `json:"sender_id"`
+	SenderUsername string `json:"sender_username"`
+	RecipientID    string `json:"recipient_id"`
+	Message        string `json:"message"`
+	Timestamp      string `json:"timestamp"`
+}
+
+type trainingSummary struct {
+	base                         // actionTrainingSummary
+	HandID    string             `json:"hand_id"`
+	Decisions []trainingDecision `json:"decisions"`
+}
+
+type trainingDecision struct {
+	Street     string             `json:"street"`
+	Action     string             `json:"action"`
+	Amount     uint               `json:"amount"`
+	Pot        uint               `json:"pot"`
+	ToCall     uint               `json:"to_call"`
+	PotOdds    float64            `json:"pot_odds"`
+	Equity     float64            `json:"equity"`
+	Opponents  int                `json:"opponents"`
+	EV         map[string]float64 `json:"ev"`
+	BestAction string             `json:"best_action"`
+}
+
+type tutorialStepMessage struct {
+	base           // actionTutorialStep
+	Step    string `json:"step"`
+	Title   string `json:"title"`
+	Number  int    `json:"number"`
+	Total   int    `json:"total"`
+	Message string `json:"message"`
+}
+
+type tutorialComplete struct {
+	base              // actionTutorialComplete
+	RewardChips int64 `json:"reward_chips"` // 0 when the reward was already claimed
+}
+
+type boardDealt struct {
+	base                // actionFlopDealt, actionTurnDealt or actionRiverDealt
+	HandID     string   `json:"hand_id"`
+	Sequence   int      `json:"sequence"` // Order of the cue within the hand
+	Cards      []string `json:"cards"`    // Cards dealt on this street
+	Board      []string `json:"board"`    // The whole board so far
+	DurationMs int64    `json:"duration_ms"`
+	Narration  string   `json:"narration"`
+}
+
+type potAwarded struct {
+	base                   // actionPotAwarded
+	HandID     string      `json:"hand_id"`
+	Sequence   int         `json:"sequence"`
+	Pot        int         `json:"pot"` // 0 is the main pot, then side pots in order
+	Amount     int64       `json:"amount"`
+	Winners    []potWinner `json:"winners"`
+	DurationMs int64       `json:"duration_ms"`
+	Narration  string      `json:"narration"`
+}
+
+type potWinner struct {
+	UUID     string `json:"uuid"`
+	Username string `json:"username"`
+	SeatID   uint   `json:"seat_id"`
+	Amount   int64  `json:"amount"`
+	Half     string `json:"half,omitempty"` // Split games: "high", "low" or "high_low"
+}
+
+// gameChoicePrompt asks the button at a dealer's choice table to choose the
+// next hand's game. It is sent to the whole table; only the chooser answers.
+type gameChoicePrompt struct {
+	base                    // actionGameChoicePrompt
+	UserID         string   `json:"user_id"`
+	Seat           uint     `json:"seat"`
+	Games          []string `json:"games"`
+	Current        string   `json:"current"` // Dealt again if no choice is made
+	TimeoutSeconds int      `json:"timeout_seconds"`
 }
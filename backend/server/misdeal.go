@@ -0,0 +1,40 @@
+package server
+
+import (
+	"log/slog"
+)
+
+// handleMisdeal reports a hand the engine voided, if there is one: the
+// incident is logged, the table told that every bet was returned and the
+// hand closed in the history without a winner. The next hand is scheduled
+// as after any other hand. It returns false when nothing was voided.
+func handleMisdeal(t *table) bool {
+	legacyGame := t.game.GetLegacyGame()
+	if legacyGame == nil {
+		return false
+	}
+	misdeal := legacyGame.TakeMisdeal()
+	if misdeal == nil {
+		return false
+	}
+
+	handID := t.game.CurrentHandID()
+	slog.Warn("Hand voided by misdeal",
+		"table", t.name,
+		"hand_id", handID,
+		"reason", misdeal.Reason,
+		"stage", misdeal.Stage,
+		"detail", misdeal.Detail,
+		"refunds", misdeal.Refunds)
+
+	t.broadcast <- createNewLog(handID, "Misdeal: the hand is void and all bets have been returned")
+
+	if handID != "" && t.handHistoryService != nil {
+		if err := t.handHistoryService.RecordHandEnd(ctx, handID, 0, 0, nil); err != nil {
+			slog.Warn("Failed to record voided hand", "table", t.name, "hand_id", handID, "error", err)
+		}
+	}
+
+	scheduleAutoHandStart(t, reasonMisdeal)
+	return true
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// moderationReasonText describes each moderation reason to the sender
+var moderationReasonText = map[string]string{
+	services.ModerationReasonProfanity: "offensive language",
+	services.ModerationReasonRepeat:    "repeated messages",
+	services.ModerationReasonLink:      "links",
+	services.ModerationReasonPhone:     "phone numbers",
+}
+
+// moderateChat screens a chat message before it is delivered and reports
+// whether it may be sent. Anonymous clients and hubs without a database are
+// not moderated.
+func moderateChat(c *Client, channel, target, message string) bool {
+	return screenChat(c, channel, target, message) == models.ChatStatusDelivered
+}
+
+// muteRemaining rounds the rest of a mute up to the minute for display
+func muteRemaining(until time.Time) string {
+	remaining := time.Until(until).Round(time.Minute)
+	if remaining < time.Minute {
+		remaining = time.Minute
+	}
+	if remaining >= time.Hour {
+		hours := int(remaining / time.Hour)
+		return fmt.Sprintf("%d hour%s", hours, plural(hours))
+	}
+	minutes := int(remaining / time.Minute)
+	return fmt.Sprintf("%d minute%s", minutes, plural(minutes))
+}
+
+// maxTableMuteMinutes is the longest a moderator may mute a player at a
+// table for, short of muting them until unmuted
+const maxTableMuteMinutes = 7 * 24 * 60
+
+// SetChatModeration configures table chat: words blocked on top of the
+// built-in list, and how many messages each player may send per window, 0
+// for no limit
+func (h *Hub) SetChatModeration(blockedWords []string, rateLimit int, rateWindow time.Duration) {
+	if h.moderation == nil {
+		return
+	}
+	if len(blockedWords) > 0 {
+		h.moderation.SetBlockedWords(blockedWords)
+	}
+	h.moderation.SetRateLimit(rateLimit, rateWindow)
+}
+
+// screenChat decides what becomes of a chat message before it is delivered,
+// telling the sender when it is not. Returns models.ChatStatusDelivered when
+// it may be sent.
+func screenChat(c *Client, channel, target, message string) string {
+	if c.userID == uuid.Nil || c.hub.moderation == nil {
+		return models.ChatStatusDelivered
+	}
+
+	if !c.hub.moderation.AllowRate(c.userID, time.Now()) {
+		safeSend(c, createCodedErrorMessage(errorCodeRateLimited, "You're sending messages too quickly. Wait a moment and try again."))
+		return models.ChatStatusRateLimited
+	}
+
+	if channel == models.ChatChannelTable {
+		mute, err := c.hub.moderation.TableMute(ctx, target, c.userID)
+		if err != nil {
+			slog.Warn("Failed to check table mute", "user_id", c.userID, "table", target, "error", err)
+		} else if mute != nil {
+			notice := "A moderator has muted you in this table's chat."
+			if mute.ExpiresAt != nil {
+				notice = fmt.Sprintf("A moderator has muted you in this table's chat for another %s.", muteRemaining(*mute.ExpiresAt))
+			}
+			safeSend(c, createCodedErrorMessage(errorCodeChatMuted, notice))
+			return models.ChatStatusMuted
+		}
+	}
+
+	result, err := c.hub.moderation.ReviewMessage(ctx, c.userID, channel, target, message)
+	if err != nil {
+		slog.Warn("Failed to moderate chat message", "user_id", c.userID, "channel", channel, "error", err)
+		return models.ChatStatusDelivered
+	}
+	if result.Allowed {
+		return models.ChatStatusDelivered
+	}
+
+	if len(result.Reasons) == 0 {
+		safeSend(c, createCodedErrorMessage(errorCodeChatMuted, fmt.Sprintf("You are muted for another %s.", muteRemaining(*result.MutedUntil))))
+		return models.ChatStatusMuted
+	}
+
+	reasons := make([]string, 0, len(result.Reasons))
+	for _, reason := range result.Reasons {
+		if text, ok := moderationReasonText[reason]; ok {
+			reasons = append(reasons, text)
+		}
+	}
+	notice := fmt.Sprintf("Your message wasn't sent: chat doesn't allow %s.", strings.Join(reasons, ", "))
+	if result.MutedUntil != nil {
+		notice += fmt.Sprintf(" You are muted for %s.", muteRemaining(*result.MutedUntil))
+	} else {
+		notice += " Further violations will get you muted."
+	}
+	safeSend(c, createCodedErrorMessage(errorCodeMessageModerated, notice))
+	return models.ChatStatusBlocked
+}
+
+// recordTableChat keeps a table chat message in the chat history
+func recordTableChat(c *Client, handID, username, message, status string) {
+	if c.hub.moderation == nil {
+		return
+	}
+	record := &models.ChatMessage{
+		Table:    c.table.name,
+		HandID:   handID,
+		UserID:   c.userID,
+		Username: username,
+		Body:     message,
+		Status:   status,
+	}
+	if err := c.hub.moderation.RecordMessage(ctx, record); err != nil {
+		slog.Warn("Failed to record chat message", "user_id", c.userID, "table", c.table.name, "error", err)
+	}
+}
+
+// handleMutePlayer lets a moderator at a table mute a player in its chat
+func handleMutePlayer(c *Client, userID string, minutes int, reason string) {
+	targetID, ok := checkMuteRequest(c, userID)
+	if !ok {
+		return
+	}
+	if minutes < 0 || minutes > maxTableMuteMinutes {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, fmt.Sprintf("A mute lasts between 1 and %d minutes, or 0 until unmuted", maxTableMuteMinutes)))
+		return
+	}
+	if len([]rune(reason)) > 200 {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, "The reason can be at most 200 characters"))
+		return
+	}
+
+	mute, err := c.hub.moderation.MuteAtTable(ctx, c.table.name, targetID, c.userID, minutes, reason)
+	if err != nil {
+		rejectMute(c, err)
+		return
+	}
+
+	notice := fmt.Sprintf("%s was muted by a moderator", mute.Username)
+	if mute.ExpiresAt != nil {
+		notice += " for " + muteRemaining(*mute.ExpiresAt)
+	}
+	if reason != "" {
+		notice += ". Reason: " + reason
+	}
+	c.table.announce(notice)
+}
+
+// handleUnmutePlayer lets a moderator at a table lift a player's mute there
+func handleUnmutePlayer(c *Client, userID string) {
+	targetID, ok := checkMuteRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	mute, err := c.hub.moderation.UnmuteAtTable(ctx, c.table.name, targetID, c.userID)
+	if err != nil {
+		rejectMute(c, err)
+		return
+	}
+	c.table.announce(fmt.Sprintf("%s was unmuted by a moderator", mute.Username))
+}
+
+// checkMuteRequest parses the player a mute or unmute is aimed at, answering
+// requests that can't be carried out
+func checkMuteRequest(c *Client, userID string) (uuid.UUID, bool) {
+	if c.userID == uuid.Nil || c.hub.moderation == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required to moderate chat"))
+		return uuid.Nil, false
+	}
+	if c.table == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Join the table to moderate its chat"))
+		return uuid.Nil, false
+	}
+	targetID, err := uuid.Parse(userID)
+	if err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, "Invalid user ID"))
+		return uuid.Nil, false
+	}
+	if targetID == c.userID {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "You can't mute yourself"))
+		return uuid.Nil, false
+	}
+	return targetID, true
+}
+
+// rejectMute tells a moderator why a mute or unmute failed
+func rejectMute(c *Client, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotModerator):
+		safeSend(c, createCodedErrorMessage(errorCodeNotModerator, "Only moderators can mute players"))
+	case errors.Is(err, services.ErrCannotMuteModerator):
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Moderators can't be muted"))
+	case errors.Is(err, services.ErrUserNotFound):
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Player not found"))
+	case errors.Is(err, services.ErrChatMuteNotFound):
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "The player is not muted at this table"))
+	default:
+		slog.Error("Failed to change table chat mute", "user_id", c.userID, "table", c.table.name, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeGeneric, "Failed to change the player's mute, please try again"))
+	}
+}
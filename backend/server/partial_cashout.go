@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// handlePartialCashOut moves chips above the table maximum buy-in from the
+// player's stack back to their wallet without leaving the table. Tables must
+// opt in, the player must not be in a hand, and every withdrawal is recorded.
+func handlePartialCashOut(c *Client, amount uint) {
+	if c.userID == uuid.Nil || c.formanceService == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Authentication required to cash out"))
+		return
+	}
+
+	t := c.table
+	position, seated := t.game.PlayerPosition(c.userID)
+	if !seated || c.sessionID == uuid.Nil {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "You are not seated at this table"))
+		return
+	}
+
+	if c.hub.tableService == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "Partial cash-out is not allowed at this table"))
+		return
+	}
+	tableRecord, err := c.hub.tableService.GetTableByName(ctx, t.name)
+	if err != nil || !tableRecord.AllowPartialCashOut {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "Partial cash-out is not allowed at this table"))
+		return
+	}
+
+	// Hold the action lock so no hand or action can move the stack between
+	// the check and the cash-out
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	game := t.game.GetLegacyGame()
+	pre := game.GenerateOmniView()
+	if int(position) >= len(pre.Players) {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "You are not seated at this table"))
+		return
+	}
+	stack := int64(pre.Players[position].Stack)
+	if pre.Players[position].In {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "You can't cash out while you are in a hand"))
+		return
+	}
+	if rejectHitAndRun(c) {
+		return
+	}
+
+	excess := stack - tableRecord.MaxBuyIn
+	if excess <= 0 {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, fmt.Sprintf("Only chips above the %d MNT table maximum can be cashed out", tableRecord.MaxBuyIn)))
+		return
+	}
+
+	withdraw := int64(amount)
+	if withdraw == 0 {
+		withdraw = excess
+	}
+	if withdraw > excess {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, fmt.Sprintf("You can cash out at most %d MNT, the chips above the %d MNT table maximum", excess, tableRecord.MaxBuyIn)))
+		return
+	}
+
+	if err := poker.CashOut(game, position, uint(withdraw)); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeCashOutDenied, "You can't cash out right now"))
+		return
+	}
+
+	handID := t.game.CurrentHandID()
+	transactionID, err := c.formanceService.TransferFromGameWithMetadata(ctx, c.userID, withdraw, c.sessionID, map[string]string{
+		"cashout_kind":     "partial",
+		"table_name":       t.name,
+		"table_max_buy_in": strconv.FormatInt(tableRecord.MaxBuyIn, 10),
+	})
+	if err != nil {
+		// Put the chips back so the stack matches the session account
+		poker.RestoreChips(game, position, uint(withdraw))
+		slog.Error("Failed to transfer partial cash-out", "user_id", c.userID, "session_id", c.sessionID, "amount", withdraw, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeTransferFailed, "Failed to cash out. Please try again."))
+		return
+	}
+
+	tableID := tableRecord.ID
+	record := &models.PartialCashOut{
+		UserID:        c.userID,
+		SessionID:     c.sessionID,
+		TableID:       &tableID,
+		TableName:     t.name,
+		HandID:        handID,
+		StackBefore:   stack,
+		Amount:        withdraw,
+		StackAfter:    stack - withdraw,
+		TableMaxBuyIn: tableRecord.MaxBuyIn,
+		TransactionID: transactionID,
+		Kind:          "manual",
+	}
+	if t.sessionService != nil {
+		if err := t.sessionService.RecordPartialCashOut(ctx, record); err != nil {
+			// The money has moved; the ledger transaction carries the same details
+			slog.Error("Failed to record partial cash-out audit", "user_id", c.userID, "transaction_id", transactionID, "error", err)
+		}
+	}
+
+	slog.Info("Player partially cashed out",
+		"user_id", c.userID,
+		"table", t.name,
+		"amount", withdraw,
+		"stack_before", stack,
+		"stack_after", stack-withdraw,
+		"transaction_id", transactionID,
+		"session_id", c.sessionID)
+
+	safeSend(c, createSuccessMessage(fmt.Sprintf("Cashed out %d MNT to your wallet. Transaction ID: %s", withdraw, transactionID)))
+	sendBalanceUpdateToClient(c, "cash_out", withdraw, transactionID)
+	t.broadcast <- createNewLog(handID, fmt.Sprintf("%s took %d MNT off the table", c.username, withdraw))
+	t.broadcast <- createUpdatedGame(c)
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/bots"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// botThinkTime is how long a bot takes over a decision, so players can
+// follow its play
+const botThinkTime = 1500 * time.Millisecond
+
+// botNames are handed out in turn. Every bot sits as "<name> (bot)" so
+// nobody mistakes one for another player.
+var botNames = []string{"Ada", "Bold", "Chip", "Dara", "Erdene", "Flint", "Gala", "Huxley", "Iris"}
+
+// practiceBotState tracks the bots seated at a practice table. Bots fill
+// the table while fewer humans than players are sitting, and give their
+// seats up between hands as humans join.
+type practiceBotState struct {
+	mu      sync.Mutex
+	players int // Players bots fill the table up to, 0 for no bots
+	seated  map[uuid.UUID]*practiceBot
+	named   int // Bots seated so far, for picking names
+}
+
+// practiceBot is a seat played by a bot through a client with no
+// connection, so its actions take the same path as everyone else's
+type practiceBot struct {
+	client *Client
+	bot    *bots.Bot
+}
+
+// SetPracticeTablePlayers sets how many players bots fill practice tables
+// up to, for tables opened afterwards
+func (h *Hub) SetPracticeTablePlayers(players int) {
+	h.practiceTablePlayers = players
+}
+
+// isPractice reports whether the table plays for play chips only
+func (t *table) isPractice() bool {
+	t.callTime.mu.Lock()
+	defer t.callTime.mu.Unlock()
+	return t.callTime.policy.practice
+}
+
+// practiceBot returns the bot playing as userID, or nil
+func (t *table) practiceBot(userID uuid.UUID) *practiceBot {
+	t.bots.mu.Lock()
+	defer t.bots.mu.Unlock()
+	return t.bots.seated[userID]
+}
+
+// takePracticeSeat sits a player down at a practice table with play chips.
+// The wallet is never checked and no game session is opened.
+func takePracticeSeat(c *Client, username string, seatID uint, chips uint) {
+	if !checkBuyInRange(c, int64(chips)) {
+		return
+	}
+	seatID, err := resolveSeat(c, seatID)
+	if err != nil {
+		rejectSeat(c, err)
+		return
+	}
+
+	// Nothing is dealing yet if fewer than two players could play a hand
+	ready := 0
+	for _, p := range c.table.game.GetLegacyGame().GenerateOmniView().Players {
+		if p.Ready && p.Stack > 0 {
+			ready++
+		}
+	}
+
+	if err := c.table.game.JoinTable(ctx, c.userID, username, ""); err != nil {
+		slog.Warn("Join table failed", "error", err)
+		safeSend(c, createErrorMessage("Failed to join table. Please try again."))
+		return
+	}
+	if err := c.table.game.SeatPlayer(ctx, c.userID, uuid.Nil, c.username, int(seatID), int64(chips)); err != nil {
+		slog.Warn("Seat player failed", "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to take seat. Please try again."))
+		return
+	}
+	c.uuid = c.userID.String()
+
+	slog.Info("Player sat down to practice", "user_id", c.userID, "table", c.table.name, "seat_id", seatID, "chips", chips)
+	safeSend(c, createSuccessMessage(fmt.Sprintf("Sat down with %d practice chips", chips)))
+	safeSend(c, createUpdatedPlayerUUID(c))
+	c.table.broadcast <- createUpdatedGame(c)
+
+	c.table.balanceBots()
+	if ready < 2 {
+		scheduleAutoHandStart(c.table, reasonPlayerSeated)
+	}
+}
+
+// balanceBots seats bots while fewer humans than the practice table's
+// players are sitting, and unseats them as humans join or leave. Bots that
+// lost their stack are replaced. Seats only change between hands.
+func (t *table) balanceBots() {
+	if !t.isPractice() {
+		return
+	}
+	game := t.game.GetLegacyGame()
+	view := game.GenerateOmniView()
+	if view.Stage != poker.PreDeal || view.Betting {
+		return
+	}
+
+	// Humans still at the table lend their hub and database to the bots
+	occupied, seatedUsers := t.game.SeatedPlayers()
+	seated := make(map[uuid.UUID]bool, len(seatedUsers))
+	for _, userID := range seatedUsers {
+		seated[userID] = true
+	}
+	var host *Client
+	humans := 0
+	for _, c := range t.connectedClients() {
+		if c.userID != uuid.Nil && seated[c.userID] {
+			host = c
+			humans++
+		}
+	}
+
+	t.bots.mu.Lock()
+	defer t.bots.mu.Unlock()
+
+	want := 0
+	if humans > 0 && humans < t.bots.players {
+		want = t.bots.players - humans
+	}
+
+	handID := t.game.CurrentHandID()
+	for userID, b := range t.bots.seated {
+		position, ok := t.game.PlayerPosition(userID)
+		busted := ok && int(position) < len(view.Players) && view.Players[position].Stack == 0
+		if len(t.bots.seated) <= want && !busted {
+			continue
+		}
+		if ok {
+			if err := poker.Unseat(game, position); err != nil {
+				slog.Warn("Failed to unseat practice bot", "table", t.name, "bot", b.client.username, "error", err)
+				continue
+			}
+			delete(occupied, int(view.Players[position].SeatID))
+		}
+		t.game.ForgetPlayer(userID)
+		delete(t.bots.seated, userID)
+		b.client.send.close()
+		t.broadcast <- createNewLog(handID, fmt.Sprintf("%s left the table", b.client.username))
+	}
+
+	for host != nil && len(t.bots.seated) < want {
+		seat, err := services.RandomSeat(occupied, defaultTableSeats)
+		if err != nil {
+			break
+		}
+		b, err := t.seatBot(host, seat)
+		if err != nil {
+			slog.Warn("Failed to seat practice bot", "table", t.name, "error", err)
+			break
+		}
+		occupied[seat] = true
+		t.bots.seated[b.client.userID] = b
+		t.broadcast <- createNewLog(handID, fmt.Sprintf("%s sat down to practice with you", b.client.username))
+	}
+}
+
+// seatBot sits a new bot down with a full practice stack
+func (t *table) seatBot(host *Client, seat int) (*practiceBot, error) {
+	name := fmt.Sprintf("%s (bot)", botNames[t.bots.named%len(botNames)])
+	t.bots.named++
+
+	client := &Client{
+		hub:          host.hub,
+		send:         newSendQueue(0, nil),
+		userID:       uuid.New(),
+		username:     name,
+		table:        t,
+		db:           host.db,
+		capabilities: make(capabilitySet),
+	}
+	client.uuid = client.userID.String()
+
+	if err := t.game.SeatPlayer(ctx, client.userID, uuid.Nil, name, seat, t.practiceStack()); err != nil {
+		return nil, err
+	}
+
+	// Nobody reads a bot's messages; drain them until it leaves
+	go func() {
+		for range client.send.ready {
+			for {
+				if _, ok := client.send.pop(); !ok {
+					break
+				}
+			}
+			if client.send.isClosed() {
+				return
+			}
+		}
+	}()
+
+	slog.Info("Practice bot seated", "table", t.name, "bot", name, "seat", seat)
+	return &practiceBot{client: client, bot: bots.New(time.Now().UnixNano())}, nil
+}
+
+// practiceStack is the play chips a bot sits down with: the table's max
+// buy-in, or 100 big blinds
+func (t *table) practiceStack() int64 {
+	t.callTime.mu.Lock()
+	maxBuyIn := t.callTime.policy.maxBuyIn
+	t.callTime.mu.Unlock()
+	if maxBuyIn > 0 {
+		return maxBuyIn
+	}
+	return 100 * int64(t.game.GetLegacyGame().GenerateOmniView().Config.BigBlind)
+}
+
+// scheduleBotTurn has a bot act on the turn once it has thought it over
+func (t *table) scheduleBotTurn(turn actionTurn) {
+	actor, err := uuid.Parse(turn.UserID)
+	if err != nil {
+		return
+	}
+	b := t.practiceBot(actor)
+	if b == nil {
+		return
+	}
+	time.AfterFunc(botThinkTime, func() {
+		t.playBot(b, turn.Token)
+	})
+}
+
+// playBot decides and applies a bot's action with the turn's token, so a
+// turn that moved on in the meantime is left alone
+func (t *table) playBot(b *practiceBot, token string) {
+	c := b.client
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	position, ok := t.game.PlayerPosition(c.userID)
+	if !ok || position != view.ActionNum || int(position) >= len(view.Players) {
+		return
+	}
+	decision := b.bot.Decide(botSpot(view, position))
+
+	sequencedAction(c, token, func() bool {
+		var ok bool
+		switch decision.Action {
+		case bots.ActionRaise:
+			ok = handleRaise(c, decision.Amount)
+		case bots.ActionCall:
+			ok = handleCall(c)
+		case bots.ActionCheck:
+			ok = handleCheck(c)
+		}
+		// A refused action would leave the table waiting on the bot
+		return ok || handleFold(c)
+	})
+}
+
+// botSpot is what the player at pn can see of the hand
+func botSpot(view *poker.GameView, pn uint) bots.Spot {
+	actor := view.Players[pn]
+	spot := bots.Spot{
+		Hole:     actor.Cards,
+		Board:    view.CommunityCards,
+		Stack:    actor.Stack,
+		MinRaise: view.MinRaise,
+	}
+	var maxBet uint
+	for i, p := range view.Players {
+		spot.Pot += p.TotalBet + p.Ante
+		maxBet = max(maxBet, p.Bet)
+		if uint(i) != pn && p.In {
+			spot.Opponents++
+		}
+	}
+	spot.ToCall = min(maxBet-actor.Bet, actor.Stack)
+	return spot
+}
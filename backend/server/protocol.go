@@ -0,0 +1,271 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Protocol versions. Version 1 is the original protocol of flat messages,
+// {"action": "take-seat", "seatID": 3, ...}, which carry no version; it is
+// what clients get unless they ask for more. Version 2 wraps every message
+// in a typed envelope:
+//
+//	{"version": 2, "action": "take-seat", "payload": {"seatID": 3}, "request_id": "r-17"}
+//
+// Replies to an enveloped request carry its request_id so the client can
+// match them up.
+const (
+	protocolVersionFlat     = 1
+	protocolVersionEnvelope = 2
+	// maxRequestIDLength keeps request IDs echoed back to a sensible size
+	maxRequestIDLength = 64
+)
+
+var supportedProtocolVersions = []int{protocolVersionFlat, protocolVersionEnvelope}
+
+// envelope is a protocol 2 message in either direction
+type envelope struct {
+	Version   int             `json:"version"`
+	Action    string          `json:"action"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+// inboundMessages registers the message type of every action a client may
+// send in an envelope. Payloads must decode as their action's type. The
+// frontend's short aliases ("call", "fold", ...) are only accepted flat.
+var inboundMessages = map[string]func() any{
+	actionJoinTable:         func() any { return &joinTable{} },
+	actionLeaveTable:        func() any { return &leaveTable{} },
+	actionSendMessage:       func() any { return &sendMessage{} },
+	actionSendLog:           func() any { return &sendLog{} },
+	actionNewPlayer:         func() any { return &newPlayer{} },
+	actionTakeSeat:          func() any { return &takeSeat{} },
+	actionStartGame:         func() any { return &startGame{} },
+	actionDealGame:          func() any { return &dealGame{} },
+	actionResetGame:         func() any { return &resetGame{} },
+	actionPlayerCall:        func() any { return &playerCall{} },
+	actionPlayerCheck:       func() any { return &playerCheck{} },
+	actionPlayerRaise:       func() any { return &playerRaise{} },
+	actionPlayerFold:        func() any { return &playerFold{} },
+	actionGetBalance:        func() any { return &getBalance{} },
+	actionClientHello:       func() any { return &clientHello{} },
+	actionTimeSync:          func() any { return &timeSync{} },
+	actionSendDirectMessage: func() any { return &sendDirectMessage{} },
+	actionPartialCashOut:    func() any { return &partialCashOut{} },
+	actionSetTrainingMode:   func() any { return &setTrainingMode{} },
+	actionStartTutorial:     func() any { return &startTutorial{} },
+	actionLeaveTutorial:     func() any { return &leaveTutorial{} },
+	actionChooseGame:        func() any { return &chooseGame{} },
+	actionSitIn:             func() any { return &sitIn{} },
+	actionSitOut:            func() any { return &sitOut{} },
+	actionMutePlayer:        func() any { return &mutePlayer{} },
+	actionUnmutePlayer:      func() any { return &unmutePlayer{} },
+}
+
+// replyActions are the outbound actions that answer a request, and carry
+// its request_id when sent while the request is being handled
+var replyActions = map[string]bool{
+	actionError:            true,
+	actionWarning:          true,
+	actionSuccess:          true,
+	actionCommandDuplicate: true,
+	actionServerHello:      true,
+	actionUpdateBalance:    true,
+	actionTimeSyncReply:    true,
+}
+
+// inboundActions lists the registered inbound actions in a stable order
+func inboundActions() []string {
+	actions := make([]string, 0, len(inboundMessages))
+	for action := range inboundMessages {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+// protocolFromRequest reads the protocol version asked for in the "protocol"
+// query parameter or the X-Protocol-Version header sent during the upgrade.
+// Returns 0 when none was asked for.
+func protocolFromRequest(r *http.Request) int {
+	raw := r.URL.Query().Get("protocol")
+	if raw == "" {
+		raw = r.Header.Get("X-Protocol-Version")
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// protocolVersion returns the protocol the client's messages are written in
+func (c *Client) protocolVersion() int {
+	if version := c.protocol.Load(); version != 0 {
+		return int(version)
+	}
+	return protocolVersionFlat
+}
+
+// setProtocolVersion switches the client to a protocol version, answering
+// with an unsupported_version error and keeping the current one if the
+// server doesn't speak it. Reports whether the version was accepted.
+func (c *Client) setProtocolVersion(version int) bool {
+	if !slices.Contains(supportedProtocolVersions, version) {
+		safeSend(c, createUnsupportedVersion(version))
+		return false
+	}
+	c.protocol.Store(int32(version))
+	return true
+}
+
+// beginRequest notes the request the read pump is handling, so replies
+// queued for the client meanwhile carry its ID
+func (c *Client) beginRequest(requestID string) {
+	if requestID == "" {
+		c.request.Store(nil)
+		return
+	}
+	c.request.Store(&requestID)
+}
+
+func (c *Client) endRequest() {
+	c.request.Store(nil)
+}
+
+// tagReply adds the ID of the request being handled to a reply
+func (c *Client) tagReply(message []byte) []byte {
+	requestID := c.request.Load()
+	if requestID == nil {
+		return message
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return message
+	}
+	var action string
+	if err := json.Unmarshal(fields["action"], &action); err != nil || !replyActions[action] {
+		return message
+	}
+	fields["request_id"], _ = json.Marshal(*requestID)
+	tagged, err := json.Marshal(fields)
+	if err != nil {
+		return message
+	}
+	return tagged
+}
+
+// openEnvelope unwraps a protocol 2 message into the flat form the handlers
+// read, and notes its request ID for the replies. Flat messages pass through
+// unchanged. Returns false for an envelope that was refused, having told the
+// client why.
+func (c *Client) openEnvelope(raw []byte) ([]byte, bool) {
+	var probe struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil || probe.Version == nil {
+		return raw, true
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, "Malformed message envelope"))
+		return nil, false
+	}
+	if len(env.RequestID) > maxRequestIDLength {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, fmt.Sprintf("request_id can be at most %d characters", maxRequestIDLength)))
+		return nil, false
+	}
+	c.beginRequest(env.RequestID)
+
+	// Flat messages are protocol 1; an envelope claiming any other version
+	// than 2 is from a client the server can't serve
+	if env.Version != protocolVersionEnvelope {
+		safeSend(c, createUnsupportedVersion(env.Version))
+		return nil, false
+	}
+	// A client sending envelopes reads them too
+	if c.protocolVersion() != protocolVersionEnvelope {
+		c.setProtocolVersion(protocolVersionEnvelope)
+	}
+
+	newMessage, ok := inboundMessages[env.Action]
+	if !ok {
+		safeSend(c, createCodedErrorMessage(errorCodeUnknownAction, fmt.Sprintf("Unknown action %q", env.Action)))
+		return nil, false
+	}
+
+	payload := env.Payload
+	if len(payload) == 0 || string(payload) == "null" {
+		payload = json.RawMessage("{}")
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, "The payload must be an object"))
+		return nil, false
+	}
+	if err := json.Unmarshal(payload, newMessage()); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidMessage, fmt.Sprintf("Invalid payload for %s: %v", env.Action, err)))
+		return nil, false
+	}
+
+	fields["action"], _ = json.Marshal(env.Action)
+	flat, err := json.Marshal(fields)
+	if err != nil {
+		slog.Default().Warn("Marshal unwrapped envelope", "action", env.Action, "error", err)
+		return nil, false
+	}
+	return flat, true
+}
+
+// wrapEnvelope puts a flat server message in a protocol 2 envelope, moving
+// any request_id out of the payload
+func wrapEnvelope(message []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return message
+	}
+
+	env := envelope{Version: protocolVersionEnvelope}
+	if err := json.Unmarshal(fields["action"], &env.Action); err != nil {
+		return message
+	}
+	if raw, ok := fields["request_id"]; ok {
+		json.Unmarshal(raw, &env.RequestID)
+	}
+	delete(fields, "action")
+	delete(fields, "request_id")
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return message
+	}
+	env.Payload = payload
+	wrapped, err := json.Marshal(env)
+	if err != nil {
+		slog.Default().Warn("Marshal message envelope", "action", env.Action, "error", err)
+		return message
+	}
+	return wrapped
+}
+
+func createUnsupportedVersion(version int) []byte {
+	resp, err := json.Marshal(errorMessage{
+		base:              base{actionError},
+		Code:              errorCodeUnsupportedVersion,
+		Message:           fmt.Sprintf("Protocol version %d is not supported", version),
+		Time:              currentTime(),
+		SupportedVersions: supportedProtocolVersions,
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal unsupported version error", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// turnNudgeDelay is how long a player can sit on their action before we send
+// an "it's your turn" push, aimed at multi-tablers who tabbed away
+const turnNudgeDelay = 15 * time.Second
+
+// SetPushService enables push notifications for tables created by this hub
+func (h *Hub) SetPushService(pushService *services.PushService) {
+	h.pushService = pushService
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+	for t := range h.tables {
+		t.pushService = pushService
+	}
+}
+
+// scheduleTurnNudge arms a timer for the player currently to act. Any later
+// call replaces the timer, and the push is only sent if the same seat is still
+// acting in the same hand when it fires.
+func (t *table) scheduleTurnNudge() {
+	if t.pushService == nil {
+		return
+	}
+
+	handID, actionNum, ok := t.pendingAction()
+	if !ok {
+		return
+	}
+
+	t.nudgeMu.Lock()
+	defer t.nudgeMu.Unlock()
+
+	if t.nudgeTimer != nil {
+		t.nudgeTimer.Stop()
+	}
+	t.nudgeTimer = time.AfterFunc(turnNudgeDelay, func() {
+		t.sendTurnNudge(handID, actionNum)
+	})
+}
+
+// pendingAction returns the hand and seat currently waiting on a decision
+func (t *table) pendingAction() (string, uint, bool) {
+	engineView, ok := getEngineView(t.game.GenerateOmniView())
+	if !ok || !engineView.Running || !engineView.Betting {
+		return "", 0, false
+	}
+	if int(engineView.ActionNum) >= len(engineView.Players) {
+		return "", 0, false
+	}
+	return t.game.CurrentHandID(), engineView.ActionNum, true
+}
+
+func (t *table) sendTurnNudge(handID string, actionNum uint) {
+	currentHandID, currentAction, ok := t.pendingAction()
+	if !ok || currentHandID != handID || currentAction != actionNum || t.isHandFrozen() {
+		return
+	}
+
+	engineView, ok := getEngineView(t.game.GenerateOmniView())
+	if !ok || int(actionNum) >= len(engineView.Players) {
+		return
+	}
+
+	userID, err := uuid.Parse(engineView.Players[actionNum].UUID)
+	if err != nil {
+		slog.Debug("Skipping turn nudge for player without user ID", "table", t.name, "hand_id", handID)
+		return
+	}
+
+	t.pushService.NotifyAsync(userID, models.PushEventTurnAlert, services.PushNotification{
+		Title: "It's your turn",
+		Body:  fmt.Sprintf("Action is on you at %s", t.name),
+		Data: map[string]string{
+			"table":   t.name,
+			"hand_id": handID,
+		},
+	})
+}
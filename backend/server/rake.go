@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/formance"
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// SetLoyaltyService credits the rake taken from live hands towards each
+// player's rakeback
+func (h *Hub) SetLoyaltyService(loyalty *services.LoyaltyService) {
+	h.loyalty = loyalty
+}
+
+// SetRakeFreeService stops rake being taken at tables during their
+// rake-free windows, and announces the windows there
+func (h *Hub) SetRakeFreeService(rakeFree *services.RakeFreeService) {
+	h.rakeFree = rakeFree
+	rakeFree.SetAnnouncer(h)
+}
+
+// AnnounceRakeFree posts a message about a rake-free window in the chat of
+// the open tables it covers
+func (h *Hub) AnnounceRakeFree(window models.RakeFreeWindow, message string) {
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	for _, t := range tables {
+		if window.Covers(t.recordID(), t.templateID()) {
+			t.announce(message)
+		}
+	}
+}
+
+// recordID is the table's ID in the database
+func (t *table) recordID() uuid.UUID {
+	if id := t.game.GetTableID(); id != nil {
+		return *id
+	}
+	return t.id
+}
+
+// templateID is the stake template the table was opened from, if any
+func (t *table) templateID() *uuid.UUID {
+	t.callTime.mu.Lock()
+	defer t.callTime.mu.Unlock()
+	return t.callTime.policy.templateID
+}
+
+// rakeFreeWindow returns the rake-free window the table is in, or nil
+func (t *table) rakeFreeWindow() *models.RakeFreeWindow {
+	if t.rakeFree == nil {
+		return nil
+	}
+	return t.rakeFree.Active(ctx, t.recordID(), t.templateID(), time.Now())
+}
+
+// rakeConfig returns the table's per-hand rake schedule for a hand, which
+// takes nothing during a rake-free window
+func (t *table) rakeConfig(handID string) formance.RakeConfig {
+	t.callTime.mu.Lock()
+	policy := t.callTime.policy
+	t.callTime.mu.Unlock()
+
+	tableID := t.recordID()
+	if policy.rakePercentage > 0 {
+		if window := t.rakeFreeWindow(); window != nil {
+			slog.Info("Hand is rake-free", "table", t.name, "hand_id", handID, "window_id", window.ID)
+			policy.rakePercentage = 0
+		}
+	}
+	return formance.RakeConfig{
+		Strategy:   formance.RakeStrategyPerHand,
+		Percentage: policy.rakePercentage,
+		MaxRake:    policy.rakeCap,
+		MinPot:     policy.rakeMinPot,
+		TableID:    tableID,
+		HandID:     handID,
+	}
+}
+
+// potRakes works out the hand's rake from every pot that was won and spreads
+// it across those pots by size. Pots without winners are not raked.
+func potRakes(config formance.RakeConfig, pots []EnginePot) []int64 {
+	amounts := make([]int64, len(pots))
+	var total int64
+	for i, pot := range pots {
+		if len(pot.WinningPlayerNums) > 0 {
+			amounts[i] = int64(pot.Amt)
+			total += amounts[i]
+		}
+	}
+	return splitRake(config.HandRake(total), amounts)
+}
+
+// splitRake divides rake in proportion to amounts. What rounding leaves over
+// is taken from the first amount large enough to pay it.
+func splitRake(rake int64, amounts []int64) []int64 {
+	split := make([]int64, len(amounts))
+	var total int64
+	for _, amount := range amounts {
+		total += amount
+	}
+	if rake <= 0 || total <= 0 {
+		return split
+	}
+	if rake > total {
+		rake = total
+	}
+
+	left := rake
+	for i, amount := range amounts {
+		split[i] = rake * amount / total
+		left -= split[i]
+	}
+	for i, amount := range amounts {
+		if left == 0 {
+			break
+		}
+		extra := min(left, amount-split[i])
+		split[i] += extra
+		left -= extra
+	}
+	return split
+}
+
+// takeRakeChips removes rake paid through the ledger from the winner's
+// stack, so their chips keep matching their session. The ledger has
+// already moved the rake, so a failure leaves the stack out of sync with
+// it and is returned for the caller to flag.
+func (t *table) takeRakeChips(handID string, position uint, rake int64) error {
+	if err := poker.TakeRake(t.game.GetLegacyGame(), position, uint(rake)); err != nil {
+		slog.Error("Failed to take rake from stack", "table", t.name, "hand_id", handID, "position", position, "rake", rake, "error", err)
+		return fmt.Errorf("seat %d: rake %d not taken from stack: %w", position, rake, err)
+	}
+	return nil
+}
+
+// recordRake credits the rake taken from a pot towards its winners'
+// rakeback. The rake is already in the ledger, so a failure only affects
+// rakeback.
+func recordRake(ctx context.Context, c *Client, config formance.RakeConfig, transactionID string, rake map[uuid.UUID]int64) {
+	if c.hub == nil || c.hub.loyalty == nil || len(rake) == 0 {
+		return
+	}
+	if err := c.hub.loyalty.RecordRake(ctx, config.TableID, config.HandID, transactionID, rake); err != nil {
+		slog.Error("Failed to record rake contributions", "table_id", config.TableID, "hand_id", config.HandID, "transaction_id", transactionID, "error", err)
+	}
+}
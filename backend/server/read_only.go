@@ -0,0 +1,155 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+)
+
+// ErrTableNotFound is returned when no running table has the given name
+var ErrTableNotFound = errors.New("table not found")
+
+// readOnlyActions are rejected while a table is read-only. Betting is left
+// alone so the hand in progress can finish; chat and balance queries carry on.
+var readOnlyActions = map[string]bool{
+	actionTakeSeat:       true,
+	actionLeaveTable:     true,
+	actionStartGame:      true,
+	actionDealGame:       true,
+	actionResetGame:      true,
+	actionPartialCashOut: true,
+}
+
+// readOnlyState is a table's incident switch. While set, no new hand starts
+// and no chips move between the table and wallets.
+type readOnlyState struct {
+	mu      sync.RWMutex
+	enabled bool
+	reason  string
+}
+
+func (s *readOnlyState) set(enabled bool, reason string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.enabled != enabled
+	s.enabled = enabled
+	s.reason = reason
+	return changed
+}
+
+func (s *readOnlyState) get() (bool, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enabled, s.reason
+}
+
+// isReadOnly reports whether the table is frozen for maintenance
+func (t *table) isReadOnly() bool {
+	enabled, _ := t.readOnly.get()
+	return enabled
+}
+
+// rejectReadOnly answers a blocked action with a maintenance error and
+// reports whether the action was blocked
+func rejectReadOnly(c *Client, action string) bool {
+	if c.table == nil || !readOnlyActions[action] || !c.table.isReadOnly() {
+		return false
+	}
+	safeSend(c, createCodedErrorMessage(errorCodeMaintenance, "This table is in maintenance mode. The current hand will finish, but seats and balances are frozen until it reopens."))
+	return true
+}
+
+// setTableReadOnly flips one table's switch and tells everyone at the table
+func (h *Hub) setTableReadOnly(t *table, enabled bool, reason string) {
+	if !t.readOnly.set(enabled, reason) {
+		return
+	}
+
+	message := "Table reopened. Seats and balances are available again."
+	if enabled {
+		message = "Table is now in maintenance mode: the current hand will finish, then play pauses. Chat stays open."
+		if reason != "" {
+			message += " Reason: " + reason
+		}
+	}
+	slog.Warn("Table read-only mode changed", "table", t.name, "read_only", enabled, "reason", reason)
+	t.broadcast <- createNewLog(t.game.CurrentHandID(), message)
+
+	// Play was paused between hands, so pick up where it left off. A hand in
+	// progress finishes first and is paused when the next one is due.
+	if !enabled {
+		scheduleAutoHandStart(t, reasonReadOnlyLifted)
+	} else if !t.game.GetLegacyGame().GenerateOmniView().Running {
+		t.transition(models.TableStatePaused, reasonReadOnly)
+	}
+}
+
+// SetTableReadOnly puts a single running table into or out of read-only mode
+func (h *Hub) SetTableReadOnly(name string, enabled bool, reason string) error {
+	t := h.findTableByName(name)
+	if t == nil {
+		return ErrTableNotFound
+	}
+	h.setTableReadOnly(t, enabled, reason)
+	return nil
+}
+
+// SetAllTablesReadOnly switches every running table, and any table opened
+// while the switch is on, into or out of read-only mode
+func (h *Hub) SetAllTablesReadOnly(enabled bool, reason string) {
+	h.readOnly.set(enabled, reason)
+
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	for _, t := range tables {
+		h.setTableReadOnly(t, enabled, reason)
+	}
+}
+
+// ReadOnlyTables maps each table in read-only mode to the reason given, and
+// reports whether the platform-wide switch is on
+func (h *Hub) ReadOnlyTables() (map[string]string, bool) {
+	all, _ := h.readOnly.get()
+
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+
+	tables := make(map[string]string)
+	for t := range h.tables {
+		if enabled, reason := t.readOnly.get(); enabled {
+			tables[t.name] = reason
+		}
+	}
+	return tables, all
+}
+
+// ForceCloseTable closes a running table between hands, cashing out everyone
+// still seated. Returns services.ErrHandInProgress while a hand is being
+// played, so nobody's bets are taken off the table mid-hand.
+func (h *Hub) ForceCloseTable(name, reason string) error {
+	t := h.findTableByName(name)
+	if t == nil {
+		return ErrTableNotFound
+	}
+	if t.game.GetLegacyGame().GenerateOmniView().Running {
+		return services.ErrHandInProgress
+	}
+
+	notice := "The table is being closed by the operators. Remaining chips are going back to your wallet."
+	if reason != "" {
+		notice += " Reason: " + reason
+	}
+	if t.closeAndCashOut(notice, fmt.Sprintf("%s was closed by the operators. Your chips are back in your wallet.", t.name)) {
+		slog.Warn("Table force-closed", "table", t.name, "reason", reason)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// rebuyWindow is how long after losing a full stack buying back in at the
+// same table counts as a rebuy
+const rebuyWindow = 12 * time.Hour
+
+// bustState records when players last lost their whole stack at a table
+type bustState struct {
+	mu    sync.Mutex
+	at    map[uuid.UUID]time.Time
+	broke map[uuid.UUID]bool // Seated without chips as of the last hand
+}
+
+// observe notes a seated player's stack after a hand and reports whether
+// they just lost the last of it
+func (s *bustState) observe(userID uuid.UUID, stack uint, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.at == nil {
+		s.at = make(map[uuid.UUID]time.Time)
+		s.broke = make(map[uuid.UUID]bool)
+	}
+	if stack > 0 {
+		delete(s.broke, userID)
+		return false
+	}
+	if s.broke[userID] {
+		return false // Still sitting without chips since an earlier hand
+	}
+	s.broke[userID] = true
+	s.at[userID] = now
+
+	// Forget busts too old to count, so the map doesn't grow forever
+	for id, at := range s.at {
+		if now.Sub(at) > rebuyWindow {
+			delete(s.at, id)
+		}
+	}
+	return true
+}
+
+// last returns when the user last lost a full stack, if recently enough for
+// a buy-in to be a rebuy
+func (s *bustState) last(userID uuid.UUID, now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.at[userID]
+	if !ok || now.Sub(at) > rebuyWindow {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// SetBankrollService applies rebuy cool-downs and warnings to buy-ins
+func (h *Hub) SetBankrollService(bankroll *services.BankrollService) {
+	h.bankroll = bankroll
+}
+
+// noteBusts records the players left without chips when a hand ends
+func (t *table) noteBusts(now time.Time) {
+	for _, p := range t.game.GetLegacyGame().GenerateOmniView().Players {
+		if p.Left {
+			continue
+		}
+		mapped, ok := t.game.playerPositionToUUID[p.Position]
+		if !ok {
+			continue
+		}
+		userID, err := uuid.Parse(mapped)
+		if err != nil {
+			continue
+		}
+		if t.busts.observe(userID, p.Stack, now) {
+			slog.Info("Player lost a full stack", "table", t.name, "user_id", userID)
+		}
+	}
+}
+
+// checkRebuy applies the player's rebuy limits to a buy-in at a table where
+// they recently lost a full stack. It refuses buy-ins during the cool-down
+// and warns when the rebuy is a large share of the wallet. It reports
+// whether the buy-in may go ahead.
+func checkRebuy(c *Client, buyIn, walletBalance int64) bool {
+	if c.hub == nil || c.hub.bankroll == nil {
+		return true
+	}
+	now := time.Now()
+	bustedAt, rebuy := c.table.busts.last(c.userID, now)
+	if !rebuy {
+		return true
+	}
+
+	rule, err := c.hub.bankroll.RebuyRule(ctx, c.userID)
+	if err != nil {
+		// Limits are a safeguard, not a reason to keep players off tables
+		slog.Warn("Failed to get rebuy limits", "user_id", c.userID, "error", err)
+		return true
+	}
+
+	if remaining := rule.CooldownRemaining(bustedAt, now); remaining > 0 {
+		minutes := int((remaining + time.Minute - 1) / time.Minute)
+		slog.Info("Rebuy refused during cool-down", "table", c.table.name, "user_id", c.userID, "remaining", remaining)
+		safeSend(c, createCodedErrorMessage(errorCodeRebuyCooldown, fmt.Sprintf("You lost your stack at this table recently. You can buy back in here in %d minute%s.", minutes, plural(minutes))))
+		return false
+	}
+
+	if rule.ExceedsWarning(buyIn, walletBalance) {
+		safeSend(c, createWarningMessage(fmt.Sprintf("This rebuy of %d MNT is more than %d%% of your %d MNT wallet balance. Consider a smaller buy-in or taking a break.", buyIn, rule.WarnPercent, walletBalance)))
+	}
+	return true
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultReconnectWindow is how long a dropped player's seat is held unless
+// the hub is configured otherwise
+const defaultReconnectWindow = time.Minute
+
+// missedEventLimit is how many chat and log lines a table keeps to replay to
+// players who reconnect
+const missedEventLimit = 100
+
+// Broadcasts replayed to a reconnecting player. Game updates are not: the
+// snapshot sent on reconnect supersedes them.
+var (
+	missedMessagePrefix = []byte(`{"action":"` + actionNewMessage + `"`)
+	missedLogPrefix     = []byte(`{"action":"` + actionNewLog + `"`)
+)
+
+// heldSeat is a dropped player's place at a table, kept with its stack and
+// session until they reconnect or the window runs out
+type heldSeat struct {
+	client *Client // The dropped connection, cashed out if it never returns
+	table  *table
+	since  time.Time
+	timer  *time.Timer
+}
+
+// reconnectRegistry holds the seats of players whose connection dropped
+// while they were seated
+type reconnectRegistry struct {
+	mu     sync.Mutex
+	window time.Duration
+	seats  map[uuid.UUID]*heldSeat // By user
+}
+
+// SetReconnectWindow sets how long a dropped player's seat is held for them
+// to reconnect. 0 cashes them out as soon as the connection closes.
+func (h *Hub) SetReconnectWindow(window time.Duration) {
+	h.reconnect.mu.Lock()
+	defer h.reconnect.mu.Unlock()
+	h.reconnect.window = window
+}
+
+// holdSeat keeps a dropped client's seat for the reconnect window instead of
+// cashing them out. It reports whether the seat stays at the table, either
+// held or because the user is still at the table on another connection.
+func (h *Hub) holdSeat(c *Client) bool {
+	if c.userID == uuid.Nil || c.table == nil {
+		return false
+	}
+	if h.atTableElsewhere(c) {
+		return true
+	}
+	if _, seated := c.table.liveSeat(c.userID); !seated {
+		return false
+	}
+
+	h.reconnect.mu.Lock()
+	window := h.reconnect.window
+	if window <= 0 {
+		h.reconnect.mu.Unlock()
+		return false
+	}
+	previous := h.reconnect.seats[c.userID]
+	seat := &heldSeat{client: c, table: c.table, since: time.Now()}
+	seat.timer = time.AfterFunc(window, func() { h.expireSeat(c.userID, seat) })
+	h.reconnect.seats[c.userID] = seat
+	h.reconnect.mu.Unlock()
+
+	// A seat still held at another table is given up for this one
+	if previous != nil && previous.timer.Stop() {
+		go h.releaseSeat(c.userID, previous)
+	}
+
+	slog.Info("Seat held for reconnect", "table", c.table.name, "user_id", c.userID, "session_id", c.sessionID, "window", window)
+	c.table.broadcast <- createNewLog(c.table.game.CurrentHandID(), fmt.Sprintf("%s disconnected, their seat is held for %s", c.username, window))
+	return true
+}
+
+// atTableElsewhere reports whether the client's user is at the same table on
+// another connection
+func (h *Hub) atTableElsewhere(c *Client) bool {
+	h.usersMu.RLock()
+	defer h.usersMu.RUnlock()
+	for other := range h.userClients[c.userID] {
+		if other != c && other.table == c.table {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeSeat binds a reconnecting client to the seat held for its user, at
+// the named table or, with no name, wherever it is. The client is sent a
+// full snapshot of the game and the chat and log lines it missed.
+func (h *Hub) resumeSeat(c *Client, tableName string) bool {
+	if c.userID == uuid.Nil {
+		return false
+	}
+
+	h.reconnect.mu.Lock()
+	seat := h.reconnect.seats[c.userID]
+	if seat == nil || (tableName != "" && seat.table.name != tableName) || !seat.timer.Stop() {
+		// Past the window the expiry already running cashes the player out
+		h.reconnect.mu.Unlock()
+		return false
+	}
+	delete(h.reconnect.seats, c.userID)
+	h.reconnect.mu.Unlock()
+
+	dropped, t := seat.client, seat.table
+	c.table = t
+	c.uuid = dropped.uuid
+	c.sessionID = dropped.sessionID
+	c.trainingMode.Store(dropped.trainingMode.Load())
+	t.register <- c
+
+	safeSend(c, createUpdatedPlayerUUID(c))
+	safeSend(c, createUpdatedGame(c))
+	for _, message := range t.missed.since(seat.since) {
+		safeSend(c, message)
+	}
+
+	slog.Info("Player reconnected to held seat", "table", t.name, "user_id", c.userID, "session_id", c.sessionID, "away", time.Since(seat.since))
+	t.broadcast <- createNewLog(t.game.CurrentHandID(), fmt.Sprintf("%s reconnected", c.username))
+	return true
+}
+
+// heldSeatClient returns the dropped connection holding the user's seat at
+// the table, or nil if no seat is held for them there
+func (h *Hub) heldSeatClient(t *table, userID uuid.UUID) *Client {
+	h.reconnect.mu.Lock()
+	defer h.reconnect.mu.Unlock()
+	if seat := h.reconnect.seats[userID]; seat != nil && seat.table == t {
+		return seat.client
+	}
+	return nil
+}
+
+// dropHeldSeat forgets any seat held for the user, who has left the table
+// and been cashed out on another connection
+func (h *Hub) dropHeldSeat(userID uuid.UUID) {
+	h.reconnect.mu.Lock()
+	defer h.reconnect.mu.Unlock()
+	if seat := h.reconnect.seats[userID]; seat != nil && seat.timer.Stop() {
+		delete(h.reconnect.seats, userID)
+	}
+}
+
+// expireSeat releases a held seat whose window ran out, unless the player
+// reconnected or dropped again in the meantime
+func (h *Hub) expireSeat(userID uuid.UUID, seat *heldSeat) {
+	h.reconnect.mu.Lock()
+	if h.reconnect.seats[userID] != seat {
+		h.reconnect.mu.Unlock()
+		return
+	}
+	delete(h.reconnect.seats, userID)
+	h.reconnect.mu.Unlock()
+
+	h.releaseSeat(userID, seat)
+}
+
+// releaseSeat cashes out a player who didn't come back for their seat
+func (h *Hub) releaseSeat(userID uuid.UUID, seat *heldSeat) {
+	slog.Info("Held seat released", "table", seat.table.name, "user_id", userID, "session_id", seat.client.sessionID, "away", time.Since(seat.since))
+	handlePlayerCashOut(seat.client)
+}
+
+// heldSeatCount returns how many seats are waiting for their players
+func (h *Hub) heldSeatCount() int {
+	h.reconnect.mu.Lock()
+	defer h.reconnect.mu.Unlock()
+	return len(h.reconnect.seats)
+}
+
+// missedEvent is a chat or log line broadcast at a table
+type missedEvent struct {
+	at      time.Time
+	message []byte
+}
+
+// missedEvents keeps a table's recent chat and log lines for players who
+// reconnect
+type missedEvents struct {
+	mu     sync.Mutex
+	events []missedEvent // Oldest first
+}
+
+// record keeps the message if it is a chat or log line
+func (m *missedEvents) record(message []byte) {
+	if !bytes.HasPrefix(message, missedMessagePrefix) && !bytes.HasPrefix(message, missedLogPrefix) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, missedEvent{at: time.Now(), message: message})
+	if len(m.events) > missedEventLimit {
+		m.events = m.events[len(m.events)-missedEventLimit:]
+	}
+}
+
+// since returns the lines broadcast after the given time, oldest first
+func (m *missedEvents) since(at time.Time) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var messages [][]byte
+	for _, event := range m.events {
+		if event.at.After(at) {
+			messages = append(messages, event.message)
+		}
+	}
+	return messages
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/services"
+)
+
+// SetSeatReservations keeps seats held for named players out of everyone
+// else's reach
+func (h *Hub) SetSeatReservations(seatHolds *services.SeatReservationService) {
+	h.seatHolds = seatHolds
+}
+
+// heldSeats returns the seat held for the client at its table, 0 if none,
+// and the seats held for everyone else. Tables not saved to the database
+// have no reservations.
+func heldSeats(c *Client) (int, map[int]bool, error) {
+	tableID := c.table.game.GetTableID()
+	if c.hub.seatHolds == nil || tableID == nil {
+		return 0, nil, nil
+	}
+	return c.hub.seatHolds.Seats(ctx, *tableID, c.userID, time.Now())
+}
+
+// claimSeatReservation releases the seat held for a player who has sat down
+func claimSeatReservation(c *Client) {
+	tableID := c.table.game.GetTableID()
+	if c.hub.seatHolds == nil || tableID == nil {
+		return
+	}
+	if err := c.hub.seatHolds.Claim(ctx, *tableID, c.userID, time.Now()); err != nil {
+		slog.Warn("Failed to claim seat reservation", "user_id", c.userID, "table", c.table.name, "error", err)
+	}
+}
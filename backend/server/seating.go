@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+)
+
+// defaultTableSeats matches the seat count of virtual WebSocket tables
+const defaultTableSeats = 9
+
+var errSeparatedPlayer = errors.New("player is kept apart from someone at this table")
+
+// resolveSeat applies the table's seat reservations and seating policy to a
+// seat request. A player with a seat held for them is put in it, and no one
+// else may take a held seat. Tables with random seating ignore the requested
+// seat and draw an empty one, and tables enforcing separation refuse players
+// flagged as a pair with someone already seated. Players who already hold a
+// position keep it.
+func resolveSeat(c *Client, requested uint) (uint, error) {
+	if c.table.game.IsSeated(c.userID) {
+		return requested, nil
+	}
+
+	occupied, seatedUsers := c.table.game.SeatedPlayers()
+	own, reserved, err := heldSeats(c)
+	if err != nil {
+		return 0, err
+	}
+	if own > 0 && !occupied[own] {
+		slog.Info("Reserved seat assigned", "user_id", c.userID, "table", c.table.name, "requested_seat", requested, "seat", own)
+		return uint(own), nil
+	}
+
+	seating := c.hub.seating
+	if seating == nil {
+		return unreservedSeat(c, requested, reserved)
+	}
+
+	mode, enforceSeparation, err := seating.TablePolicy(ctx, c.table.name)
+	if err != nil {
+		// Fall back to the player's choice rather than blocking the table
+		slog.Warn("Failed to load seating policy", "table", c.table.name, "error", err)
+		return unreservedSeat(c, requested, reserved)
+	}
+
+	if enforceSeparation {
+		separated, err := seating.SeparatedFrom(ctx, c.userID, seatedUsers)
+		if err != nil {
+			return 0, err
+		}
+		if len(separated) > 0 {
+			slog.Info("Seat refused to separated player", "user_id", c.userID, "table", c.table.name, "separated_from", separated)
+			return 0, errSeparatedPlayer
+		}
+	}
+
+	if mode != models.SeatSelectionRandom {
+		return unreservedSeat(c, requested, reserved)
+	}
+
+	// Held seats are never drawn
+	for seat := range reserved {
+		occupied[seat] = true
+	}
+	seat, err := services.RandomSeat(occupied, defaultTableSeats)
+	if err != nil {
+		return 0, err
+	}
+	slog.Info("Random seat assigned", "user_id", c.userID, "table", c.table.name, "requested_seat", requested, "seat", seat)
+	return uint(seat), nil
+}
+
+// unreservedSeat refuses a requested seat that is held for someone else
+func unreservedSeat(c *Client, requested uint, reserved map[int]bool) (uint, error) {
+	if reserved[int(requested)] {
+		slog.Info("Reserved seat refused", "user_id", c.userID, "table", c.table.name, "seat", requested)
+		return 0, services.ErrSeatReserved
+	}
+	return requested, nil
+}
+
+// rejectSeat tells the player why resolveSeat refused them a seat
+func rejectSeat(c *Client, err error) {
+	switch {
+	case errors.Is(err, errSeparatedPlayer):
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "You can't sit at this table right now. Please choose another table."))
+	case errors.Is(err, services.ErrNoEmptySeat):
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "This table is full."))
+	case errors.Is(err, services.ErrSeatReserved):
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "That seat is reserved for another player. Please choose another seat."))
+	default:
+		slog.Default().Warn("Failed to assign seat", "user_id", c.userID, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to assign seat. Please try again."))
+	}
+}
@@ -0,0 +1,242 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+)
+
+// defaultSendQueueSize is how many outbound messages a client may have
+// waiting before the queue starts shedding
+const defaultSendQueueSize = 256
+
+var (
+	errSendQueueClosed   = errors.New("send queue closed")
+	errSendQueueOverflow = errors.New("send queue overflow")
+)
+
+// sendPriority decides what happens to a message when its client falls behind
+type sendPriority int
+
+const (
+	// sendCritical messages (balance updates, acks, errors) are never
+	// dropped and are delivered in the order they were queued
+	sendCritical sendPriority = iota
+	// sendSnapshot messages are full game views. Only the newest matters, so
+	// queuing one replaces any older view still waiting.
+	sendSnapshot
+	// sendDroppable messages (chat, logs) wait behind everything else and are
+	// the first to go when the queue is full
+	sendDroppable
+)
+
+// classifyOutbound picks the priority of a server message from its action
+func classifyOutbound(message []byte) sendPriority {
+	var msg base
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return sendCritical
+	}
+
+	switch msg.Action {
+	case actionUpdateGame:
+		return sendSnapshot
+	case actionNewMessage, actionNewLog:
+		return sendDroppable
+	default:
+		return sendCritical
+	}
+}
+
+type queuedMessage struct {
+	data     []byte
+	priority sendPriority
+}
+
+// sendQueueMetrics is shared by every client queue on a hub
+type sendQueueMetrics struct {
+	queued    atomic.Int64 // Messages waiting across all clients
+	peak      atomic.Int64 // Deepest any single queue has been
+	enqueued  atomic.Int64
+	delivered atomic.Int64
+	coalesced atomic.Int64 // Game views replaced by a newer one before sending
+	dropped   atomic.Int64 // Messages shed because a queue was full
+	overflows atomic.Int64 // Clients disconnected with a queue full of critical messages
+}
+
+func (m *sendQueueMetrics) recordDepth(depth int) {
+	for {
+		peak := m.peak.Load()
+		if int64(depth) <= peak || m.peak.CompareAndSwap(peak, int64(depth)) {
+			return
+		}
+	}
+}
+
+// sendQueue is a client's bounded outbound queue. Any goroutine may push; the
+// client's writePump is the only consumer.
+type sendQueue struct {
+	mu       sync.Mutex
+	items    []queuedMessage
+	capacity int
+	closed   bool
+	ready    chan struct{} // Signalled when a message is queued or the queue closes
+	metrics  *sendQueueMetrics
+}
+
+func newSendQueue(capacity int, metrics *sendQueueMetrics) *sendQueue {
+	if capacity <= 0 {
+		capacity = defaultSendQueueSize
+	}
+	if metrics == nil {
+		metrics = &sendQueueMetrics{}
+	}
+	return &sendQueue{
+		capacity: capacity,
+		ready:    make(chan struct{}, 1),
+		metrics:  metrics,
+	}
+}
+
+// push queues a message for the client. When the queue is full, droppable
+// messages are shed first, then stale game views. A client whose queue is
+// full of critical messages has stopped reading, so the queue is closed and
+// errSendQueueOverflow returned.
+func (q *sendQueue) push(message []byte) error {
+	priority := classifyOutbound(message)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return errSendQueueClosed
+	}
+
+	if priority == sendSnapshot {
+		if i := q.oldest(sendSnapshot); i >= 0 {
+			q.remove(i)
+			q.metrics.coalesced.Add(1)
+		}
+	}
+
+	if len(q.items) >= q.capacity {
+		if i := q.oldest(sendDroppable); i >= 0 {
+			q.remove(i)
+		} else if priority == sendDroppable {
+			q.metrics.dropped.Add(1)
+			return nil
+		} else if i := q.oldest(sendSnapshot); i >= 0 {
+			q.remove(i)
+		} else {
+			q.metrics.overflows.Add(1)
+			q.closeLocked(true)
+			return errSendQueueOverflow
+		}
+		q.metrics.dropped.Add(1)
+	}
+
+	q.items = append(q.items, queuedMessage{data: message, priority: priority})
+	q.metrics.enqueued.Add(1)
+	q.metrics.queued.Add(1)
+	q.metrics.recordDepth(len(q.items))
+	q.signal()
+	return nil
+}
+
+// pop takes the next message to write. Critical messages and game views go
+// out in order ahead of any droppable ones. ok is false when nothing is waiting.
+func (q *sendQueue) pop() (message []byte, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return nil, false
+	}
+
+	next := 0
+	for i, item := range q.items {
+		if item.priority != sendDroppable {
+			next = i
+			break
+		}
+	}
+
+	message = q.items[next].data
+	q.remove(next)
+	q.metrics.delivered.Add(1)
+	return message, true
+}
+
+// isClosed reports whether the queue has been closed. Messages queued before
+// closing are still returned by pop.
+func (q *sendQueue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// close stops the queue accepting messages. It is safe to call more than once.
+func (q *sendQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closeLocked(false)
+}
+
+// closeLocked closes the queue, discarding anything still waiting when the
+// client is too far behind for it to matter
+func (q *sendQueue) closeLocked(discard bool) {
+	if q.closed {
+		return
+	}
+	q.closed = true
+	if discard {
+		q.metrics.queued.Add(-int64(len(q.items)))
+		q.items = nil
+	}
+	q.signal()
+}
+
+func (q *sendQueue) oldest(priority sendPriority) int {
+	for i, item := range q.items {
+		if item.priority == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *sendQueue) remove(i int) {
+	q.items = append(q.items[:i], q.items[i+1:]...)
+	q.metrics.queued.Add(-1)
+}
+
+func (q *sendQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// SendQueueStats reports outbound queue metrics for every WebSocket client
+func (h *Hub) SendQueueStats() models.SendQueueStats {
+	m := h.sendMetrics
+	return models.SendQueueStats{
+		QueueSize: h.sendQueueSize,
+		Queued:    m.queued.Load(),
+		PeakDepth: m.peak.Load(),
+		Enqueued:  m.enqueued.Load(),
+		Delivered: m.delivered.Load(),
+		Coalesced: m.coalesced.Load(),
+		Dropped:   m.dropped.Load(),
+		Overflows: m.overflows.Load(),
+	}
+}
+
+// SetSendQueueSize sets the outbound queue size for clients that connect afterwards
+func (h *Hub) SetSendQueueSize(size int) {
+	if size > 0 {
+		h.sendQueueSize = size
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// sessionConflict describes an active session at another table that blocks
+// taking a seat here
+type sessionConflict struct {
+	SessionID  string `json:"session_id"`
+	TableID    string `json:"table_id"`
+	TableName  string `json:"table_name,omitempty"` // Empty when the table is no longer running
+	SeatNumber *int   `json:"seat_number,omitempty"`
+	Chips      int64  `json:"chips"` // MNT
+	Live       bool   `json:"live"`
+}
+
+// activeSessions splits the user's active sessions into the one belonging to
+// this table, if any, and those at other tables
+func activeSessions(c *Client, tableID uuid.UUID) (*models.GameSession, []models.GameSession, error) {
+	var sessions []models.GameSession
+	if err := c.db.Where("user_id = ? AND status = ?", c.userID, models.GameSessionStatusActive).
+		Order("joined_at ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load active sessions: %w", err)
+	}
+
+	var current *models.GameSession
+	var others []models.GameSession
+	for i := range sessions {
+		if tableID != uuid.Nil && sessions[i].TableID == tableID && current == nil {
+			current = &sessions[i]
+			continue
+		}
+		others = append(others, sessions[i])
+	}
+	return current, others, nil
+}
+
+// tableNameByID returns the name of the running table with the given ID
+func (h *Hub) tableNameByID(tableID uuid.UUID) string {
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+	for t := range h.tables {
+		if id := t.game.GetTableID(); id != nil && *id == tableID {
+			return t.name
+		}
+	}
+	return ""
+}
+
+// rejectSessionConflict tells the user to cash out of their other tables
+// before buying in here
+func rejectSessionConflict(c *Client, sessions []models.GameSession) {
+	conflicts := make([]sessionConflict, len(sessions))
+	names := make([]string, 0, len(sessions))
+	for i, s := range sessions {
+		conflicts[i] = sessionConflict{
+			SessionID:  s.ID.String(),
+			TableID:    s.TableID.String(),
+			SeatNumber: s.SeatNumber,
+			Chips:      s.CurrentChips,
+			Live:       c.hub.HasLiveSession(c.userID, s.TableID, s.ID),
+		}
+		if name := c.hub.tableNameByID(s.TableID); name != "" {
+			conflicts[i].TableName = name
+			names = append(names, name)
+		}
+	}
+
+	slog.Info("Take seat refused, user has active sessions at other tables",
+		"user_id", c.userID, "table", c.table.name, "sessions", len(sessions))
+
+	message := "You already have chips at another table. Cash out there before taking a seat here."
+	if len(names) > 0 {
+		message = fmt.Sprintf("You already have chips at %s. Cash out there before taking a seat here.", strings.Join(names, ", "))
+	}
+	safeSend(c, createSessionConflictMessage(message, conflicts))
+}
+
+// sessionConflictError is a coded error listing the conflicting sessions
+type sessionConflictError struct {
+	errorMessage
+	Sessions []sessionConflict `json:"sessions"`
+}
+
+// createSessionConflictMessage is a coded error listing the conflicting
+// sessions so clients can offer to jump to those tables
+func createSessionConflictMessage(message string, conflicts []sessionConflict) []byte {
+	resp, err := json.Marshal(sessionConflictError{
+		errorMessage: errorMessage{
+			base:    base{actionError},
+			Code:    errorCodeSessionConflict,
+			Message: message,
+			Time:    currentTime(),
+		},
+		Sessions: conflicts,
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal session conflict message", "error", err)
+	}
+	return resp
+}
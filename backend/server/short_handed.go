@@ -0,0 +1,277 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+const (
+	// shortHandedCheckInterval is how often cash tables count their players
+	shortHandedCheckInterval = 30 * time.Second
+	// shortHandedWarning is how long before closing the players left are warned
+	shortHandedWarning = 2 * time.Minute
+)
+
+// shortHandedState tracks how long a cash table has been below its minimum
+// players. Past the table's limit it cashes out whoever is left and closes.
+type shortHandedState struct {
+	mu     sync.Mutex
+	since  time.Time // Zero while the table has enough players
+	warned bool
+}
+
+// watchShortHanded checks the table's player count until it closes
+func (t *table) watchShortHanded() {
+	ticker := time.NewTicker(shortHandedCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if t.callTimeClosed() {
+			return
+		}
+		t.checkShortHanded(time.Now())
+	}
+}
+
+// checkShortHanded starts, warns about or finishes the countdown to closing
+// a cash table with too few players ready to play. Tables nobody has used
+// yet are left for the lobby.
+func (t *table) checkShortHanded(now time.Time) {
+	t.callTime.mu.Lock()
+	policy := t.callTime.policy
+	t.callTime.mu.Unlock()
+
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	seated, ready := 0, 0
+	for _, p := range view.Players {
+		if p.Left {
+			continue
+		}
+		seated++
+		if p.Ready && p.Stack > 0 {
+			ready++
+		}
+	}
+
+	unused := seated == 0 && t.game.CurrentHandID() == ""
+	if !policy.cash || policy.shortHanded <= 0 || view.Running || ready >= policy.minPlayers || unused {
+		if t.shortHanded.reset() {
+			slog.Info("Table no longer short-handed", "table", t.name, "ready", ready)
+			t.setLobbyStatus("active")
+		}
+		return
+	}
+
+	since, started, warn := t.shortHanded.mark(now, policy.shortHanded)
+	remaining := policy.shortHanded - now.Sub(since)
+	switch {
+	case remaining <= 0:
+		t.closeShortHanded(policy.minPlayers)
+	case started:
+		slog.Info("Table short-handed", "table", t.name, "ready", ready, "min_players", policy.minPlayers, "closes_in", remaining)
+		t.setLobbyStatus("waiting")
+		minutes := int((remaining + 30*time.Second) / time.Minute)
+		t.announce(fmt.Sprintf("Waiting for players. The table needs %d ready to play and closes in %d minute%s if nobody joins.", policy.minPlayers, minutes, plural(minutes)))
+	case warn:
+		minutes := int((remaining + 30*time.Second) / time.Minute)
+		t.announce(fmt.Sprintf("Still short of players: the table closes in %d minute%s and remaining chips go back to your wallet.", minutes, plural(minutes)))
+	}
+}
+
+// minPlayersToDeal is how many players ready to play a new hand needs
+func (t *table) minPlayersToDeal() int {
+	t.callTime.mu.Lock()
+	defer t.callTime.mu.Unlock()
+
+	if policy := t.callTime.policy; policy.cash && policy.minPlayers > 2 {
+		return policy.minPlayers
+	}
+	return 2
+}
+
+// mark notes that the table is short-handed and reports since when, whether
+// the countdown just started and whether the closing warning is due
+func (s *shortHandedState) mark(now time.Time, limit time.Duration) (since time.Time, started, warn bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.since.IsZero() {
+		s.since = now
+		s.warned = false
+		started = true
+	}
+	if !started && !s.warned && limit-now.Sub(s.since) <= shortHandedWarning {
+		s.warned = true
+		warn = true
+	}
+	return s.since, started, warn
+}
+
+// reset clears the countdown and reports whether one was running
+func (s *shortHandedState) reset() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	running := !s.since.IsZero()
+	s.since = time.Time{}
+	s.warned = false
+	return running
+}
+
+// closeShortHanded stops the table dealing for good, returns the chips of
+// everyone still seated to their wallets and lets them know
+func (t *table) closeShortHanded(minPlayers int) {
+	notice := fmt.Sprintf("The table is closing: fewer than %d players were ready to play. Remaining chips are going back to your wallet.", minPlayers)
+	if t.closeAndCashOut(notice, fmt.Sprintf("%s closed for lack of players. Your chips are back in your wallet.", t.name)) {
+		slog.Info("Closed short-handed table", "table", t.name, "min_players", minPlayers)
+	}
+}
+
+// closeAndCashOut stops the table dealing for good and returns the chips of
+// everyone still seated to their wallets. The notice goes to the table and
+// pushBody to each player cashed out. Reports false if it was already closed.
+func (t *table) closeAndCashOut(notice, pushBody string) bool {
+	s := &t.callTime
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	t.announce(notice)
+
+	clients := t.connectedClients()
+	t.turn.mu.Lock()
+	for _, c := range clients {
+		if c.table == t && c.userID != uuid.Nil {
+			t.cashOutClosedSeat(c, pushBody)
+		}
+	}
+	// Players who disconnected were cashed out as they went; free their seats
+	game := t.game.GetLegacyGame()
+	for i, p := range game.GenerateOmniView().Players {
+		if !p.Left && !p.In {
+			if err := poker.Leave(game, uint(i), 0); err != nil {
+				slog.Warn("Failed to free seat at closing table", "table", t.name, "position", i, "error", err)
+			}
+		}
+	}
+	t.turn.mu.Unlock()
+	t.setLobbyStatus("finished")
+	if id := t.game.GetTableID(); id != nil && t.tableService != nil {
+		if err := t.tableService.UpdatePlayerCount(ctx, *id, 0); err != nil {
+			slog.Warn("Failed to clear player count of closed table", "table", t.name, "error", err)
+		}
+	}
+
+	t.broadcast <- createTableUpdate(t)
+	return true
+}
+
+// cashOutClosedSeat takes a player's whole stack off a closing table, moves
+// it to their wallet and finishes their game session
+func (t *table) cashOutClosedSeat(c *Client, pushBody string) {
+	cashOut, err := t.cashOutSeat(c, "table_closed")
+	if errors.Is(err, errSeatNotHeld) {
+		return
+	}
+	if err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeTransferFailed, "The table closed but your chips could not be returned yet. Leave the table to cash out, or contact support."))
+		return
+	}
+	if cashOut.TransactionID != "" {
+		safeSend(c, createSuccessMessage(fmt.Sprintf("The table closed. Cashed out %d MNT to your wallet. Transaction ID: %s", cashOut.Amount, cashOut.TransactionID)))
+		sendBalanceUpdateToClient(c, "cash_out", cashOut.Amount, cashOut.TransactionID)
+	}
+
+	t.pushService.NotifyAsync(c.userID, models.PushEventTableStatus, services.PushNotification{
+		Title: "Table closed",
+		Body:  pushBody,
+		Data: map[string]string{
+			"table": t.name,
+		},
+	})
+}
+
+// errSeatNotHeld is returned when a player has no seat left to cash out
+var errSeatNotHeld = errors.New("no seat held at the table")
+
+// cashOutSeat takes a player's whole stack off the table, moves it to their
+// wallet, finishes their game session and frees the seat. kind is recorded
+// on the ledger transaction. The player must not be in a hand, and the caller
+// holds t.turn.mu.
+func (t *table) cashOutSeat(c *Client, kind string) (*models.RecoveredSession, error) {
+	position, seated := t.game.PlayerPosition(c.userID)
+	if !seated {
+		return nil, errSeatNotHeld
+	}
+
+	game := t.game.GetLegacyGame()
+	pre := game.GenerateOmniView()
+	if int(position) >= len(pre.Players) || pre.Players[position].Left {
+		return nil, errSeatNotHeld
+	}
+	stack := int64(pre.Players[position].Stack)
+
+	result := &models.RecoveredSession{SessionID: c.sessionID, Amount: stack}
+	if id := t.game.GetTableID(); id != nil {
+		result.TableID = *id
+	}
+
+	if stack > 0 && c.sessionID != uuid.Nil && c.formanceService != nil {
+		if err := poker.CashOut(game, position, uint(stack)); err != nil {
+			slog.Error("Failed to take chips off the table", "table", t.name, "user_id", c.userID, "error", err)
+			return nil, err
+		}
+		transactionID, err := c.formanceService.TransferFromGameWithMetadata(ctx, c.userID, stack, c.sessionID, map[string]string{
+			"cashout_kind": kind,
+			"table_name":   t.name,
+		})
+		if err != nil {
+			// Put the chips back so the stack matches the session account
+			poker.RestoreChips(game, position, uint(stack))
+			slog.Error("Failed to cash out player", "table", t.name, "user_id", c.userID, "session_id", c.sessionID, "amount", stack, "kind", kind, "error", err)
+			return nil, err
+		}
+		result.TransactionID = transactionID
+
+		slog.Info("Player cashed out", "table", t.name, "user_id", c.userID, "amount", stack, "kind", kind, "transaction_id", transactionID, "session_id", c.sessionID)
+	}
+
+	if t.sessionService != nil && c.sessionID != uuid.Nil {
+		if err := t.sessionService.FinishSession(ctx, c.sessionID, stack); err != nil {
+			slog.Warn("Failed to finish session", "table", t.name, "session_id", c.sessionID, "error", err)
+		}
+	}
+	if err := poker.Leave(game, position, 0); err != nil {
+		slog.Warn("Failed to unseat player", "table", t.name, "user_id", c.userID, "error", err)
+	}
+	c.sessionID = uuid.Nil
+
+	return result, nil
+}
+
+// setLobbyStatus updates the status players see for the table in the lobby
+func (t *table) setLobbyStatus(status string) {
+	id := t.game.GetTableID()
+	if id == nil || t.tableService == nil {
+		return
+	}
+	if err := t.tableService.UpdateTableStatus(ctx, *id, status); err != nil {
+		slog.Warn("Failed to update table lobby status", "table", t.name, "status", status, "error", err)
+	}
+}
@@ -23,18 +23,25 @@ type EnginePlayer struct {
 	In         bool   `json:"in"`
 	Called     bool   `json:"called"`
 	Left       bool   `json:"left"`
+	SittingOut bool   `json:"sittingOut"`
 	TotalBuyIn uint   `json:"totalBuyIn"`
 	Stack      uint   `json:"stack"`
 	Bet        uint   `json:"bet"`
 	TotalBet   uint   `json:"totalBet"`
+	Ante       uint   `json:"ante"`
 	Cards      []int  `json:"cards"`
 }
 
 // EngineGameConfig represents pure engine-based game config
 type EngineGameConfig struct {
-	MaxBuy     uint `json:"maxBuy"`
-	BigBlind   uint `json:"bb"`
-	SmallBlind uint `json:"sb"`
+	MaxBuy       uint `json:"maxBuy"`
+	BigBlind     uint `json:"bb"`
+	SmallBlind   uint `json:"sb"`
+	Ante         uint `json:"ante"`
+	BigBlindAnte bool `json:"bbAnte"`
+	HiLo         bool `json:"hiLo"`
+	// Empty for Hold'em
+	Variant string `json:"variant,omitempty"`
 }
 
 // EnginePot represents pure engine-based pot
@@ -42,6 +49,9 @@ type EnginePot struct {
 	Amt                uint   `json:"amount"`
 	EligiblePlayerNums []uint `json:"eligiblePlayerNums"`
 	WinningPlayerNums  []uint `json:"winningPlayerNums"`
+	// Split games: the players with the best qualifying low
+	LowPlayerNums []uint        `json:"lowPlayerNums,omitempty"`
+	Awards        map[uint]uint `json:"awards,omitempty"` // Chips won from the pot, by position
 }
 
 // EngineGameView represents a pure engine-based game view
@@ -60,6 +70,7 @@ type EngineGameView struct {
 	Pots           []EnginePot      `json:"pots"`
 	MinRaise       uint             `json:"minRaise"`
 	ReadyCount     uint             `json:"readyCount"`
+	HandID         string           `json:"handId,omitempty"`
 }
 
 // SimpleGameAdapter provides a clean, simple bridge between legacy poker.Game
@@ -68,6 +79,8 @@ type SimpleGameAdapter struct {
 	tableService *services.TableService
 	tableName    string
 	tableRecord  *models.PokerTable
+	// Whether tableRecord is a row in poker_tables rather than virtual
+	persistent bool
 	// Embed a legacy poker game for compatibility during migration
 	legacyGame *poker.Game
 	// Compatibility fields for events.go direct field access
@@ -77,6 +90,8 @@ type SimpleGameAdapter struct {
 	playerPositionToUUID map[uint]string
 	// Map user UUIDs to their current player positions for reconnection
 	userUUIDToPosition map[string]uint
+	// Correlation ID of the current (or most recently finished) hand
+	handID string
 }
 
 // NewSimpleGameAdapter creates a new simplified adapter
@@ -117,7 +132,7 @@ func (sga *SimpleGameAdapter) ensureTableExists() error {
 		IsPrivate:      false,
 		Status:         "waiting",
 		CurrentPlayers: 0,
-		CreatedBy:      uuid.New(), // Virtual creator ID
+		CreatedBy:      models.SystemUserID,
 	}
 	sga.tableID = sga.tableRecord.ID
 
@@ -125,6 +140,58 @@ func (sga *SimpleGameAdapter) ensureTableExists() error {
 	return nil
 }
 
+// adopt runs the table as a row already in poker_tables
+func (sga *SimpleGameAdapter) adopt(record *models.PokerTable) {
+	sga.tableRecord = record
+	sga.tableID = record.ID
+	sga.persistent = true
+}
+
+// Promote makes the table a row in poker_tables, owned by the system
+// account, so sessions, hand history and admin tools reference a table that
+// exists rather than a virtual one. A table already saved under the name is
+// used as it is. Tables without a table service, like the tutorial's, stay
+// virtual.
+func (sga *SimpleGameAdapter) Promote(ctx context.Context) error {
+	if sga.persistent || sga.tableService == nil {
+		return nil
+	}
+	if err := sga.ensureTableExists(); err != nil {
+		return err
+	}
+
+	record, err := sga.tableService.PromoteTable(ctx, sga.tableRecord)
+	if err != nil {
+		return err
+	}
+	virtualID := sga.tableRecord.ID
+	status := sga.tableRecord.Status
+	sga.adopt(record)
+	sga.setStatus(status)
+
+	slog.Info("Virtual table promoted", "virtual_table_id", virtualID, "table_id", record.ID, "table_name", sga.tableName)
+	return nil
+}
+
+// IsPersistent reports whether the table is a row in poker_tables
+func (sga *SimpleGameAdapter) IsPersistent() bool {
+	return sga.persistent
+}
+
+// setStatus records whether hands are being dealt, in the database too for
+// a persistent table
+func (sga *SimpleGameAdapter) setStatus(status string) {
+	if sga.tableRecord.Status == status {
+		return
+	}
+	sga.tableRecord.Status = status
+	if sga.persistent {
+		if err := sga.tableService.UpdateTableStatus(context.Background(), sga.tableRecord.ID, status); err != nil {
+			slog.Warn("Failed to save table status", "table_id", sga.tableRecord.ID, "status", status, "error", err)
+		}
+	}
+}
+
 // GetLegacyGame returns the legacy poker game for direct access
 func (sga *SimpleGameAdapter) GetLegacyGame() *poker.Game {
 	return sga.legacyGame
@@ -149,6 +216,19 @@ func (sga *SimpleGameAdapter) GenerateOmniView() interface{} {
 	return sga.convertLegacyToEngineView(legacyView)
 }
 
+// GenerateViewFor generates the view a user is entitled to: a seated player
+// sees their own hole cards, anyone else only the cards shown to the table.
+// Unlike GenerateOmniView it is safe to send to clients.
+func (sga *SimpleGameAdapter) GenerateViewFor(userID uuid.UUID) interface{} {
+	var legacyView *poker.GameView
+	if position, ok := sga.PlayerPosition(userID); ok && userID != uuid.Nil {
+		legacyView = sga.legacyGame.GeneratePlayerView(position)
+	} else {
+		legacyView = sga.legacyGame.GenerateSpectatorView()
+	}
+	return sga.convertLegacyToEngineView(legacyView)
+}
+
 // getEmptyEngineView returns a consistent empty engine view
 func (sga *SimpleGameAdapter) getEmptyEngineView() *EngineGameView {
 	return &EngineGameView{
@@ -178,9 +258,8 @@ func (sga *SimpleGameAdapter) Start() error {
 		return err
 	}
 
-	// Update virtual table status to active
-	sga.tableRecord.Status = "active"
-	slog.Info("Virtual table status updated to active", "table_id", sga.tableRecord.ID)
+	sga.setStatus("active")
+	slog.Info("Table status updated to active", "table_id", sga.tableRecord.ID)
 
 	// Start the legacy game
 	return sga.legacyGame.Start()
@@ -190,10 +269,10 @@ func (sga *SimpleGameAdapter) Reset() {
 	// Reset legacy game
 	sga.legacyGame.Reset()
 
-	// Update virtual table status back to waiting
+	// Update table status back to waiting
 	if sga.tableRecord != nil {
-		sga.tableRecord.Status = "waiting"
-		slog.Info("Virtual table status reset to waiting", "table_id", sga.tableRecord.ID)
+		sga.setStatus("waiting")
+		slog.Info("Table status reset to waiting", "table_id", sga.tableRecord.ID)
 	}
 }
 
@@ -320,6 +399,56 @@ func (sga *SimpleGameAdapter) GetTableID() *uuid.UUID {
 	return &sga.tableRecord.ID
 }
 
+// SetHandID sets the correlation ID for the hand being dealt
+func (sga *SimpleGameAdapter) SetHandID(handID string) {
+	sga.handID = handID
+}
+
+// CurrentHandID returns the ID of the current or most recently finished hand
+func (sga *SimpleGameAdapter) CurrentHandID() string {
+	return sga.handID
+}
+
+// SeatedPlayers returns the seat numbers in use and the users sitting in them
+func (sga *SimpleGameAdapter) SeatedPlayers() (map[int]bool, []uuid.UUID) {
+	occupied := make(map[int]bool)
+	var users []uuid.UUID
+
+	for _, p := range sga.legacyGame.GenerateOmniView().Players {
+		if p.Left {
+			continue
+		}
+		occupied[int(p.SeatID)] = true
+		if mapped, ok := sga.playerPositionToUUID[p.Position]; ok {
+			if userID, err := uuid.Parse(mapped); err == nil {
+				users = append(users, userID)
+			}
+		}
+	}
+	return occupied, users
+}
+
+// PlayerPosition returns the legacy player number of a seated user
+func (sga *SimpleGameAdapter) PlayerPosition(userID uuid.UUID) (uint, bool) {
+	position, ok := sga.userUUIDToPosition[userID.String()]
+	return position, ok
+}
+
+// IsSeated reports whether the user already holds a position at this table
+func (sga *SimpleGameAdapter) IsSeated(userID uuid.UUID) bool {
+	_, ok := sga.userUUIDToPosition[userID.String()]
+	return ok
+}
+
+// ForgetPlayer drops a user's position mapping once they have left for good,
+// so the position is not taken for theirs if they come back
+func (sga *SimpleGameAdapter) ForgetPlayer(userID uuid.UUID) {
+	if position, ok := sga.userUUIDToPosition[userID.String()]; ok {
+		delete(sga.playerPositionToUUID, position)
+		delete(sga.userUUIDToPosition, userID.String())
+	}
+}
+
 // GetTableName returns the table name
 func (sga *SimpleGameAdapter) GetTableName() string {
 	return sga.tableName
@@ -330,8 +459,10 @@ func (sga *SimpleGameAdapter) convertLegacyToEngineView(legacyView *poker.GameVi
 	// Convert legacy players to engine players
 	enginePlayers := make([]EnginePlayer, len(legacyView.Players))
 	for i, legacyPlayer := range legacyView.Players {
-		// Convert [2]eval.Card to []int
-		cards := []int{int(legacyPlayer.Cards[0]), int(legacyPlayer.Cards[1])}
+		cards := make([]int, len(legacyPlayer.Cards))
+		for j, card := range legacyPlayer.Cards {
+			cards[j] = int(card)
+		}
 
 		// Use the real user UUID from our mapping instead of legacy game UUID
 		realUUID := legacyPlayer.UUID // fallback to legacy UUID
@@ -348,10 +479,12 @@ func (sga *SimpleGameAdapter) convertLegacyToEngineView(legacyView *poker.GameVi
 			In:         legacyPlayer.In,
 			Called:     legacyPlayer.Called,
 			Left:       legacyPlayer.Left,
+			SittingOut: legacyPlayer.SittingOut,
 			TotalBuyIn: legacyPlayer.TotalBuyIn,
 			Stack:      legacyPlayer.Stack,
 			Bet:        legacyPlayer.Bet,
 			TotalBet:   legacyPlayer.TotalBet,
+			Ante:       legacyPlayer.Ante,
 			Cards:      cards,
 		}
 	}
@@ -363,6 +496,8 @@ func (sga *SimpleGameAdapter) convertLegacyToEngineView(legacyView *poker.GameVi
 			Amt:                legacyPot.Amt,
 			EligiblePlayerNums: legacyPot.EligiblePlayerNums,
 			WinningPlayerNums:  legacyPot.WinningPlayerNums,
+			LowPlayerNums:      legacyPot.LowPlayerNums,
+			Awards:             legacyPot.Awards,
 		}
 	}
 
@@ -392,13 +527,18 @@ func (sga *SimpleGameAdapter) convertLegacyToEngineView(legacyView *poker.GameVi
 		Stage:          stage,
 		Betting:        legacyView.Betting,
 		Config: EngineGameConfig{
-			MaxBuy:     legacyView.Config.MaxBuy,
-			BigBlind:   legacyView.Config.BigBlind,
-			SmallBlind: legacyView.Config.SmallBlind,
+			MaxBuy:       legacyView.Config.MaxBuy,
+			BigBlind:     legacyView.Config.BigBlind,
+			SmallBlind:   legacyView.Config.SmallBlind,
+			Ante:         legacyView.Config.Ante,
+			BigBlindAnte: legacyView.Config.BigBlindAnte,
+			HiLo:         legacyView.Config.HiLo,
+			Variant:      string(legacyView.Config.Variant),
 		},
 		Players:    enginePlayers,
 		Pots:       enginePots,
 		MinRaise:   legacyView.MinRaise,
 		ReadyCount: legacyView.ReadyCount,
+		HandID:     sga.handID,
 	}
 }
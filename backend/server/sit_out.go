@@ -0,0 +1,406 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// sitOutCheckInterval is how often cash tables look for players who have sat
+// out long enough to be cashed out
+const sitOutCheckInterval = 30 * time.Second
+
+// Player statuses broadcast as they change
+const (
+	playerStatusSittingOut = "sitting_out"
+	playerStatusActive     = "active"
+	playerStatusCashedOut  = "cashed_out"
+)
+
+// sitOutState runs a cash table's action clock. A player who lets it run out
+// checks or folds; enough timeouts in a row and they are sat out, and after
+// sitting out for the table's limit they are cashed out to free the seat.
+type sitOutState struct {
+	mu       sync.Mutex
+	timer    *time.Timer
+	deadline time.Time               // When the decision being timed runs out
+	timeouts map[uuid.UUID]int       // Turns in a row each player let time out
+	since    map[uuid.UUID]time.Time // When each player sitting out was sat out
+	// The token of the decision the clock was called on, which keeps its
+	// shorter deadline if the turn is announced again
+	clockCalled string
+}
+
+// playerStatusUpdate tells the table a player was sat out, sat back in or
+// cashed out
+type playerStatusUpdate struct {
+	base            // actionPlayerStatus
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Seat     uint   `json:"seat"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"` // "timed_out", "sat_out", "sat_in", "idle"
+}
+
+// sitOutPolicy returns the table's action timeout and sitting out rules
+func (t *table) sitOutPolicy() (actionTimeout time.Duration, sitOutAfter int, sitOutCashOut time.Duration) {
+	t.callTime.mu.Lock()
+	defer t.callTime.mu.Unlock()
+	policy := t.callTime.policy
+	return policy.actionTimeout, policy.sitOutAfter, policy.sitOutCashOut
+}
+
+// scheduleActionTimeout starts the clock on a decision, replacing the clock of
+// the one before. Bots don't need one.
+func (t *table) scheduleActionTimeout(turn actionTurn) {
+	s := &t.sitOut
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clockCalled == turn.Token && s.timer != nil {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.deadline = time.Time{}
+	if turn.TimeoutSeconds <= 0 {
+		t.setTurnClock(turnClock{})
+		return
+	}
+	actor, err := uuid.Parse(turn.UserID)
+	if err != nil || t.practiceBot(actor) != nil {
+		t.setTurnClock(turnClock{})
+		return
+	}
+	// Everyone is shown the same deadline. The actor's connection round trip
+	// is allowed on top, so a decision made in time on a slow connection
+	// isn't timed out on its way in.
+	timeout := time.Duration(turn.TimeoutSeconds) * time.Second
+	s.timer = time.AfterFunc(timeout+t.latency.allowance(actor), func() {
+		t.actionTimedOut(turn.Token)
+	})
+	s.deadline = time.Now().Add(timeout)
+	t.setTurnClock(turnClock{turn: turn, deadline: s.deadline})
+}
+
+// stopActionTimeout stops the clock once nobody is left to act
+func (t *table) stopActionTimeout() {
+	s := &t.sitOut
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.deadline = time.Time{}
+	t.setTurnClock(turnClock{})
+}
+
+// actionTimedOut checks or folds for a player who ran out of time on the
+// decision token was issued for, and sits them out once they have done so
+// too many times in a row
+func (t *table) actionTimedOut(token string) {
+	t.turn.mu.Lock()
+	current := t.turn.open && t.turn.token == token
+	actor := t.turn.actor
+	t.turn.mu.Unlock()
+	if !current || actor == uuid.Nil {
+		return
+	}
+
+	c := t.timeoutClient(actor)
+	if c == nil {
+		return
+	}
+	position, ok := t.game.PlayerPosition(actor)
+	if !ok {
+		return
+	}
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(position) >= len(view.Players) {
+		return
+	}
+	p := view.Players[position]
+	canCheck := true
+	for _, q := range view.Players {
+		if q.Bet > p.Bet {
+			canCheck = false
+		}
+	}
+
+	var streak int
+	sequencedAction(c, token, func() bool {
+		ok := canCheck && handleCheck(c) || handleFold(c)
+		if ok {
+			streak = t.sitOut.timedOut(actor)
+		}
+		return ok
+	})
+	if streak == 0 {
+		return
+	}
+
+	_, sitOutAfter, _ := t.sitOutPolicy()
+	slog.Info("Player timed out", "table", t.name, "user_id", actor, "timeouts", streak)
+	if sitOutAfter <= 0 || streak < sitOutAfter {
+		move := "folded"
+		if canCheck {
+			move = "checked"
+		}
+		t.announce(fmt.Sprintf("%s ran out of time and %s", p.Username, move))
+		return
+	}
+
+	if err := poker.SitOut(t.game.GetLegacyGame(), position); err != nil {
+		slog.Warn("Failed to sit out player", "table", t.name, "user_id", actor, "error", err)
+		return
+	}
+	t.sitOut.satOut(actor, time.Now())
+	slog.Info("Player sat out after timing out", "table", t.name, "user_id", actor, "timeouts", streak)
+	t.announce(fmt.Sprintf("%s ran out of time %d times in a row and is sitting out", p.Username, streak))
+	t.broadcastPlayerStatus(actor, p.Username, position, playerStatusSittingOut, "timed_out")
+}
+
+// timeoutClient returns the connection of the player whose time ran out. A
+// player who has gone is acted for by a stand-in, borrowing the services of
+// whoever is still at the table.
+func (t *table) timeoutClient(userID uuid.UUID) *Client {
+	var host *Client
+	for _, c := range t.connectedClients() {
+		if c.table != t || c.userID == uuid.Nil || t.practiceBot(c.userID) != nil {
+			continue
+		}
+		if c.userID == userID {
+			return c
+		}
+		host = c
+	}
+	if host == nil {
+		return nil
+	}
+
+	standIn := &Client{
+		hub:             host.hub,
+		send:            newSendQueue(0, nil),
+		userID:          userID,
+		table:           t,
+		db:              host.db,
+		formanceService: host.formanceService,
+		capabilities:    make(capabilitySet),
+	}
+	standIn.uuid = userID.String()
+	return standIn
+}
+
+// timedOut counts a timeout against the player and returns their streak
+func (s *sitOutState) timedOut(userID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeouts[userID]++
+	return s.timeouts[userID]
+}
+
+// acted ends the player's streak of timeouts
+func (s *sitOutState) acted(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.timeouts, userID)
+}
+
+func (s *sitOutState) satOut(userID uuid.UUID, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.timeouts, userID)
+	s.since[userID] = now
+}
+
+// forget clears what is tracked for a player who sat back in or left
+func (s *sitOutState) forget(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.timeouts, userID)
+	delete(s.since, userID)
+}
+
+// dueForCashOut returns the players who have been sitting out for limit
+func (s *sitOutState) dueForCashOut(now time.Time, limit time.Duration) []uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []uuid.UUID
+	for userID, since := range s.since {
+		if now.Sub(since) >= limit {
+			due = append(due, userID)
+		}
+	}
+	return due
+}
+
+// watchSittingOut cashes out players who sit out too long, until the table
+// closes
+func (t *table) watchSittingOut() {
+	ticker := time.NewTicker(sitOutCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if t.callTimeClosed() {
+			return
+		}
+		_, _, limit := t.sitOutPolicy()
+		if limit <= 0 {
+			continue
+		}
+		for _, userID := range t.sitOut.dueForCashOut(time.Now(), limit) {
+			t.cashOutSittingOut(userID)
+		}
+	}
+}
+
+// cashOutSittingOut moves the stack of a player who sat out too long to their
+// wallet and frees the seat. A player still in a hand is left until it ends.
+func (t *table) cashOutSittingOut(userID uuid.UUID) {
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	position, seated := t.game.PlayerPosition(userID)
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if !seated || int(position) >= len(view.Players) || view.Players[position].Left || !view.Players[position].SittingOut {
+		t.sitOut.forget(userID)
+		return
+	}
+	if view.Running && view.Players[position].In {
+		return
+	}
+	username := view.Players[position].Username
+
+	var holder *Client
+	for _, c := range t.connectedClients() {
+		if c.table == t && c.userID == userID {
+			holder = c
+			break
+		}
+	}
+	// Chips leave the table with a player who disconnects
+	if holder == nil {
+		t.sitOut.forget(userID)
+		return
+	}
+
+	result, err := t.cashOutSeat(holder, "sat_out")
+	if errors.Is(err, errSeatNotHeld) {
+		t.sitOut.forget(userID)
+		return
+	}
+	if err != nil {
+		safeSend(holder, createCodedErrorMessage(errorCodeTransferFailed, "You were sitting out too long but your chips could not be returned yet. Leave the table to cash out, or contact support."))
+		return
+	}
+	t.sitOut.forget(userID)
+
+	slog.Info("Cashed out player sitting out", "table", t.name, "user_id", userID, "amount", result.Amount)
+	if result.TransactionID != "" {
+		safeSend(holder, createSuccessMessage(fmt.Sprintf("You were sitting out too long. Cashed out %d MNT to your wallet. Transaction ID: %s", result.Amount, result.TransactionID)))
+		sendBalanceUpdateToClient(holder, "cash_out", result.Amount, result.TransactionID)
+	}
+	t.pushService.NotifyAsync(userID, models.PushEventTableStatus, services.PushNotification{
+		Title: "Cashed out",
+		Body:  fmt.Sprintf("You sat out too long at %s. Your chips are back in your wallet.", t.name),
+		Data: map[string]string{
+			"table": t.name,
+		},
+	})
+
+	t.announce(fmt.Sprintf("%s sat out too long and was cashed out", username))
+	t.broadcastPlayerStatus(userID, username, position, playerStatusCashedOut, "idle")
+	t.broadcast <- createTableUpdate(t)
+}
+
+// handleSitOut keeps a player's seat and stack but stops dealing them in. A
+// player in the current hand plays it out and sits out from the next one.
+// The table's limit on sitting out applies as if they had timed out.
+func handleSitOut(c *Client) {
+	t := c.table
+	if t == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Join a table first"))
+		return
+	}
+	position, seated := t.game.PlayerPosition(c.userID)
+	if !seated {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Take a seat first"))
+		return
+	}
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(position) < len(view.Players) && view.Players[position].SittingOut {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "You are already sitting out"))
+		return
+	}
+	if err := poker.SitOut(t.game.GetLegacyGame(), position); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "You cannot sit out now"))
+		return
+	}
+	t.sitOut.satOut(c.userID, time.Now())
+
+	username := c.username
+	if int(position) < len(view.Players) {
+		username = view.Players[position].Username
+	}
+	slog.Info("Player sat out", "table", t.name, "user_id", c.userID)
+	t.announce(fmt.Sprintf("%s is sitting out", username))
+	t.broadcastPlayerStatus(c.userID, username, position, playerStatusSittingOut, "sat_out")
+}
+
+// handleSitIn deals a player who is sitting out back in from the next hand
+func handleSitIn(c *Client) {
+	t := c.table
+	if t == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Join a table first"))
+		return
+	}
+	position, seated := t.game.PlayerPosition(c.userID)
+	if !seated {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "Take a seat first"))
+		return
+	}
+	if err := poker.SitIn(t.game.GetLegacyGame(), position); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "You are not sitting out"))
+		return
+	}
+	t.sitOut.forget(c.userID)
+
+	username := c.username
+	if view := t.game.GetLegacyGame().GenerateOmniView(); int(position) < len(view.Players) {
+		username = view.Players[position].Username
+	}
+	slog.Info("Player sat back in", "table", t.name, "user_id", c.userID)
+	t.broadcastPlayerStatus(c.userID, username, position, playerStatusActive, "sat_in")
+}
+
+// broadcastPlayerStatus tells the table about a change in a player's status,
+// followed by the game it changed
+func (t *table) broadcastPlayerStatus(userID uuid.UUID, username string, seat uint, status, reason string) {
+	resp, err := json.Marshal(playerStatusUpdate{
+		base:     base{actionPlayerStatus},
+		UserID:   userID.String(),
+		Username: username,
+		Seat:     seat,
+		Status:   status,
+		Reason:   reason,
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal player status", "error", err)
+		return
+	}
+	t.broadcast <- resp
+	t.broadcast <- createTableUpdate(t)
+}
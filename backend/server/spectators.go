@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// spectatorActions are all a spectator without an account may send
+var spectatorActions = map[string]bool{
+	actionClientHello: true,
+	actionTimeSync:    true,
+	actionJoinTable:   true,
+	actionLeaveTable:  true,
+}
+
+// ServeWsSpectator opens a WebSocket for someone without an account to
+// watch a running table. They see the game as anyone without a seat does,
+// with no hole cards until they are shown, and can't act or chat.
+func ServeWsSpectator(hub *Hub, w http.ResponseWriter, r *http.Request, tableName string) {
+	t := hub.findTableByName(tableName)
+	if t == nil {
+		http.Error(w, "Table not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := hub.upgrader().Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	client := newClient(conn, hub)
+	client.correlationID = correlationIDFromRequest(r)
+	client.negotiate(capabilitiesFromRequest(r), protocolFromRequest(r))
+
+	client.hub.register <- client
+
+	go client.writePump()
+	watchTable(client, t)
+	go client.readPump()
+}
+
+// isSpectator reports whether the client is watching without an account
+func (c *Client) isSpectator() bool {
+	return c.userID == uuid.Nil
+}
+
+// rejectSpectator answers an action a spectator may not take and reports
+// whether it was rejected
+func rejectSpectator(c *Client, action string) bool {
+	if !c.isSpectator() || spectatorActions[action] {
+		return false
+	}
+	safeSend(c, createCodedErrorMessage(errorCodeAuthRequired, "Sign in to play or chat at this table"))
+	return true
+}
+
+// watchTable joins a spectator to a running table and sends them the game
+// so far. Spectators can't open tables.
+func watchTable(c *Client, t *table) {
+	c.table = t
+	t.register <- c
+	safeSend(c, createTableUpdate(t))
+}
+
+// handleSpectatorJoin moves a spectator to another running table
+func handleSpectatorJoin(c *Client, tableName string) {
+	t := c.hub.findTableByName(tableName)
+	if t == nil {
+		safeSend(c, createCodedErrorMessage(errorCodeTableNotFound, "Table not found"))
+		return
+	}
+	if c.table != nil && c.table != t {
+		c.table.unregister <- c
+	}
+	watchTable(c, t)
+}
+
+// handleSpectatorLeave stops a spectator watching their table
+func handleSpectatorLeave(c *Client) {
+	if c.table != nil {
+		c.table.unregister <- c
+		c.table = nil
+	}
+}
+
+// spectatorView is the key of the view for clients without a seat in an
+// update's views
+const spectatorView = ""
+
+// tableViews builds the game as each seated player and anyone without a seat
+// may see it, together with the update it goes out in. Each client is sent
+// its view of the state that was broadcast rather than of whatever the table
+// has moved on to by the time the message is written.
+func tableViews(t *table) map[string]interface{} {
+	views := map[string]interface{}{spectatorView: t.game.GenerateViewFor(uuid.Nil)}
+	engineView, ok := getEngineView(t.game.GenerateOmniView())
+	if !ok {
+		return views
+	}
+	for _, p := range engineView.Players {
+		userID, err := uuid.Parse(p.UUID)
+		if err != nil || userID == uuid.Nil {
+			continue
+		}
+		views[p.UUID] = t.game.GenerateViewFor(userID)
+	}
+	return views
+}
+
+// viewGame replaces the game in an update-game message with the view this
+// client is entitled to: their own hole cards if they are seated and nobody
+// else's until they are shown. Updates are broadcast with every card in
+// them, so they never reach a client without going through here.
+func (c *Client) viewGame(message []byte) []byte {
+	var update updateGame
+	if err := json.Unmarshal(message, &update); err != nil {
+		slog.Default().Warn("Unmarshal update game", "error", err)
+		return nil
+	}
+	views := update.Views
+	update.Views = nil
+
+	// Tutorial tables only deal to the one client and its bots
+	if c.tutorial == nil {
+		view, ok := views[c.userID.String()]
+		if !ok || c.userID == uuid.Nil {
+			view, ok = views[spectatorView]
+		}
+		if !ok {
+			// Nothing safe to show without the views built with the update
+			return nil
+		}
+		update.Game = view
+	}
+
+	resp, err := json.Marshal(update)
+	if err != nil {
+		slog.Default().Warn("Marshal update game", "error", err)
+		return nil
+	}
+	return resp
+}
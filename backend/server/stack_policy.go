@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// checkBuyInRange refuses a buy-in outside the table's range, which deep
+// tables raise and cap tables keep under the chip cap. Virtual tables
+// without a record take any buy-in. It reports whether the buy-in may go
+// ahead.
+func checkBuyInRange(c *Client, buyIn int64) bool {
+	if c.table.tableService == nil {
+		return true
+	}
+	record, err := c.table.tableService.GetTableByName(ctx, c.table.name)
+	if err != nil {
+		return true
+	}
+
+	err = services.CheckBuyIn(record, buyIn)
+	if err == nil {
+		return true
+	}
+	var rangeErr *services.BuyInRangeError
+	if errors.As(err, &rangeErr) {
+		safeSend(c, createCodedErrorMessage(errorCodeInvalidBuyIn, rangeErr.Error()))
+	}
+	return false
+}
+
+// cashOutAboveCap moves the chips above a cap table's limit from each seated
+// player's stack back to their wallet. It runs between hands, once the pots
+// have been paid, and every cash-out is recorded like a partial cash-out.
+// Pot distribution calls it with t.turn.mu held, so no action can move a
+// stack between the cap check and the cash-out.
+func (t *table) cashOutAboveCap(handID string) {
+	t.callTime.mu.Lock()
+	chipCap, maxBuyIn := t.callTime.policy.chipCap, t.callTime.policy.maxBuyIn
+	t.callTime.mu.Unlock()
+
+	// Chips stay put while balances are frozen; the next hand catches up
+	if chipCap <= 0 || t.isReadOnly() {
+		return
+	}
+
+	game := t.game.GetLegacyGame()
+	for _, c := range t.connectedClients() {
+		if c.userID == uuid.Nil || c.formanceService == nil || c.sessionID == uuid.Nil {
+			continue
+		}
+		position, seated := t.game.PlayerPosition(c.userID)
+		if !seated {
+			continue
+		}
+
+		pre := game.GenerateOmniView()
+		if int(position) >= len(pre.Players) {
+			continue
+		}
+		stack := int64(pre.Players[position].Stack)
+		excess, err := poker.CapStack(game, position, uint(chipCap))
+		if err != nil || excess == 0 {
+			continue
+		}
+		amount := int64(excess)
+
+		transactionID, err := c.formanceService.TransferFromGameWithMetadata(ctx, c.userID, amount, c.sessionID, map[string]string{
+			"cashout_kind":   "chip_cap",
+			"table_name":     t.name,
+			"hand_id":        handID,
+			"table_chip_cap": strconv.FormatInt(chipCap, 10),
+		})
+		if err != nil {
+			// Put the chips back so the stack matches the session account
+			poker.RestoreChips(game, position, excess)
+			slog.Error("Failed to transfer chips above the cap", "user_id", c.userID, "session_id", c.sessionID, "amount", amount, "error", err)
+			continue
+		}
+
+		record := &models.PartialCashOut{
+			UserID:        c.userID,
+			SessionID:     c.sessionID,
+			TableID:       t.game.GetTableID(),
+			TableName:     t.name,
+			HandID:        handID,
+			StackBefore:   stack,
+			Amount:        amount,
+			StackAfter:    chipCap,
+			TableMaxBuyIn: maxBuyIn,
+			TransactionID: transactionID,
+			Kind:          "chip_cap",
+		}
+		if t.sessionService != nil {
+			if err := t.sessionService.RecordPartialCashOut(ctx, record); err != nil {
+				slog.Error("Failed to record chip cap cash-out audit", "user_id", c.userID, "transaction_id", transactionID, "error", err)
+			}
+		}
+
+		slog.Info("Chips above the cap cashed out",
+			"user_id", c.userID,
+			"table", t.name,
+			"amount", amount,
+			"chip_cap", chipCap,
+			"transaction_id", transactionID,
+			"session_id", c.sessionID)
+
+		safeSend(c, createSuccessMessage(fmt.Sprintf("%d MNT above the %d MNT table cap was moved to your wallet. Transaction ID: %s", amount, chipCap, transactionID)))
+		sendBalanceUpdateToClient(c, "cash_out", amount, transactionID)
+		t.broadcast <- createNewLog(handID, fmt.Sprintf("%s is at the %d MNT cap, %d MNT went back to their wallet", c.username, chipCap, amount))
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
+	"github.com/google/uuid"
+)
+
+// SetStatsEvents publishes domain events from tables created by this hub
+func (h *Hub) SetStatsEvents(publisher statsevents.Publisher) {
+	h.statsEvents = publisher
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+	for t := range h.tables {
+		t.statsEvents = publisher
+	}
+}
+
+// statsTableID is the table's ID in the database, or its in-memory ID for
+// tables that have no row
+func (t *table) statsTableID() uuid.UUID {
+	if id := t.game.GetTableID(); id != nil {
+		return *id
+	}
+	return t.id
+}
+
+func (t *table) emitPotAwarded(handID string, pot int, amount int64, winners []potWinner) {
+	event := statsevents.PotAwarded{
+		HandID:    handID,
+		TableName: t.name,
+		Pot:       pot,
+		Amount:    amount,
+		Winners:   make([]statsevents.Winner, 0, len(winners)),
+	}
+	for _, winner := range winners {
+		userID, _ := uuid.Parse(winner.UUID)
+		event.Winners = append(event.Winners, statsevents.Winner{
+			UserID:   userID,
+			Username: winner.Username,
+			Amount:   winner.Amount,
+			Half:     winner.Half,
+		})
+	}
+	statsevents.Emit(t.statsEvents, statsevents.TypePotAwarded, event)
+}
+
+func (t *table) emitHandCompleted(view *EngineGameView, totalPot int64, winners []models.HandWinner) {
+	players, board := dealtCards(view)
+	event := statsevents.HandCompleted{
+		HandID:    view.HandID,
+		TableID:   t.statsTableID(),
+		TableName: t.name,
+		TotalPot:  totalPot,
+		Board:     board,
+		Players:   len(players),
+		Winners:   make([]statsevents.Winner, 0, len(winners)),
+	}
+	for _, winner := range winners {
+		event.Winners = append(event.Winners, statsevents.Winner{
+			UserID:   winner.UserID,
+			Username: winner.Username,
+			Amount:   winner.Amount,
+			Half:     winner.Half,
+		})
+	}
+	statsevents.Emit(t.statsEvents, statsevents.TypeHandCompleted, event)
+}
+
+func (t *table) emitPlayerSeated(c *Client, seatNumber int, buyIn int64) {
+	statsevents.Emit(t.statsEvents, statsevents.TypePlayerSeated, statsevents.PlayerSeated{
+		TableID:    t.statsTableID(),
+		TableName:  t.name,
+		UserID:     c.userID,
+		SessionID:  c.sessionID,
+		SeatNumber: seatNumber,
+		BuyIn:      buyIn,
+	})
+}
@@ -3,9 +3,14 @@ package server
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/anhbaysgalan1/gp/internal/engine"
+	"github.com/anhbaysgalan1/gp/internal/flightrecorder"
 	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/internal/statsevents"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 )
@@ -19,14 +24,72 @@ type table struct {
 	register       chan *Client
 	unregister     chan *Client
 	broadcast      chan []byte
+	clientList     chan chan []*Client
 	engine         engine.PokerEngine
 	game           *SimpleGameAdapter           // Simplified compatibility layer using direct GORM operations
 	sessionService *services.GameSessionService // Service for managing real money game sessions
+	// Human-readable code used as the prefix of hand IDs
+	shortCode          string
+	handHistoryService *services.HandHistoryService
+	// Push nudges for players who are slow to act
+	pushService *services.PushService
+	nudgeMu     sync.Mutex
+	nudgeTimer  *time.Timer
+	// Stack invariant checks around player actions
+	audit actionAuditor
+	// Decisions of players in training mode, analysed when the hand ends
+	training trainingRecorder
+	// Incident switch freezing seats and balances
+	readOnly readOnlyState
+	// A hand stopped in place while a moderator reviews a dispute
+	dispute disputeState
+	// Play stopped by a moderator until they resume it
+	pause pauseState
+	// Promotional windows in which hands are not raked
+	rakeFree *services.RakeFreeService
+	// Private game rules and the countdown to call time
+	tableService *services.TableService
+	callTime     callTimeState
+	// Progress and connection counts for the metrics endpoint
+	activity  tableActivity
+	connected atomic.Int32
+	// Numbering of the board and pot animation cues within a hand
+	cues cueSequence
+	// Countdown to closing a cash table left without enough players
+	shortHanded shortHandedState
+	// When players last lost a full stack here, for rebuy limits
+	busts bustState
+	// Chips each player was dealt the hand with, for recording wagers
+	stakes stakeState
+	// Round trips of players' connections, allowed for on their decisions
+	latency tableLatency
+	// Each player's recent uses of chat commands, for their rate limits
+	chatCommandLimits chatCommandLimits
+	// The single path actions take to change the game state
+	exec executionState
+	// Action tokens making each decision in a hand a single write
+	turn turnState
+	// The button's pending choice of game at a dealer's choice table
+	gameChoice gameChoiceState
+	// Bots keeping a practice table's game going
+	bots practiceBotState
+	// The action clock and players sat out for letting it run out
+	sitOut sitOutState
+	// The countdown of the action clock shown to everyone at the table
+	clock turnClockState
+	// Where the table is in the cycle of dealing hands, and why
+	lifecycle tableLifecycle
+	// Domain events for analytics, fraud and notification consumers
+	statsEvents statsevents.Publisher
+	// Commands, broadcasts and hand states kept for debugging, nil when off
+	recorder *flightrecorder.Recorder
+	// Recent chat and log lines, replayed to players who reconnect
+	missed missedEvents
 }
 
 // newTable creates a new table using the simplified adapter
-func newTable(name string, redisClient *redis.Client, pokerEngine engine.PokerEngine, tableService *services.TableService, sessionService *services.GameSessionService) *table {
-	return &table{
+func newTable(name string, redisClient *redis.Client, pokerEngine engine.PokerEngine, tableService *services.TableService, sessionService *services.GameSessionService, handHistoryService *services.HandHistoryService) *table {
+	t := &table{
 		id:             uuid.New(),
 		name:           name,
 		rdb:            redisClient,
@@ -34,10 +97,23 @@ func newTable(name string, redisClient *redis.Client, pokerEngine engine.PokerEn
 		register:       make(chan *Client),
 		unregister:     make(chan *Client),
 		broadcast:      make(chan []byte),
+		clientList:     make(chan chan []*Client),
 		engine:         pokerEngine,
 		game:           NewSimpleGameAdapter(tableService, name),
 		sessionService: sessionService,
+		tableService:   tableService,
+
+		shortCode:          tableShortCode(name),
+		handHistoryService: handHistoryService,
 	}
+	t.exec.path = selectExecutionPath(t.game)
+	t.turn.spent = make(map[string]uuid.UUID)
+	t.bots.seated = make(map[uuid.UUID]*practiceBot)
+	t.sitOut.timeouts = make(map[uuid.UUID]int)
+	t.sitOut.since = make(map[uuid.UUID]time.Time)
+	t.clock.updates = make(chan turnClock, 1)
+	t.lifecycle.open(time.Now())
+	return t
 }
 
 func (t *table) run() {
@@ -51,27 +127,48 @@ func (t *table) run() {
 			t.unregisterClient(client)
 		case message := <-t.broadcast:
 			t.publishMessages(message)
+		case reply := <-t.clientList:
+			list := make([]*Client, 0, len(t.clients))
+			for client := range t.clients {
+				list = append(list, client)
+			}
+			reply <- list
 		}
 	}
 }
 
+// connectedClients returns the clients at the table, read by the run loop so
+// that work on other goroutines never touches t.clients. Must not be called
+// from the run loop itself.
+func (t *table) connectedClients() []*Client {
+	reply := make(chan []*Client, 1)
+	t.clientList <- reply
+	return <-reply
+}
+
 func (t *table) registerClient(client *Client) {
 	t.clients[client] = true
+	t.connected.Store(int32(len(t.clients)))
+	t.latency.track(client)
+	t.recordLatePresence(client)
 }
 
 func (t *table) unregisterClient(client *Client) {
 	if _, ok := t.clients[client]; ok {
 		delete(t.clients, client)
+		t.connected.Store(int32(len(t.clients)))
+		t.latency.untrack(client)
 	}
 }
 
 func (t *table) broadcastToClients(message []byte) {
+	t.recordBroadcast(message)
+	t.missed.record(message)
 	for client := range t.clients {
-		select {
-		case client.send <- message:
-		default:
-			close(client.send)
+		if err := client.send.push(message); err != nil {
 			delete(t.clients, client)
+			t.connected.Store(int32(len(t.clients)))
+			t.latency.untrack(client)
 		}
 	}
 }
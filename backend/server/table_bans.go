@@ -0,0 +1,25 @@
+package server
+
+import "log/slog"
+
+// rejectTableBan refuses a seat to a player the private table's owner has
+// banned. It reports whether the player was refused.
+func rejectTableBan(c *Client) bool {
+	if c.hub == nil || c.hub.tableBans == nil {
+		return false
+	}
+
+	banned, err := c.hub.tableBans.BannedFromTable(ctx, c.table.name, c.userID)
+	if err != nil {
+		slog.Warn("Failed to check table ban", "user_id", c.userID, "table", c.table.name, "error", err)
+		safeSend(c, createCodedErrorMessage(errorCodeSeatUnavailable, "Failed to assign seat. Please try again."))
+		return true
+	}
+	if !banned {
+		return false
+	}
+
+	slog.Info("Seat refused to player banned by table owner", "user_id", c.userID, "table", c.table.name)
+	safeSend(c, createCodedErrorMessage(errorCodeTableBanned, "The host of this table has banned you from their games."))
+	return true
+}
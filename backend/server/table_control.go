@@ -0,0 +1,301 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// pauseState is a table a moderator has paused. No hand is dealt while it is
+// set, and the hand in progress when it was paused stays frozen until then.
+type pauseState struct {
+	mu        sync.RWMutex
+	paused    bool
+	reason    string
+	frozeHand bool // The pause froze a hand, rather than a dispute
+}
+
+func (s *pauseState) set(reason string, frozeHand bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		return false
+	}
+	s.paused, s.reason, s.frozeHand = true, reason, frozeHand
+	return true
+}
+
+// clear lifts the pause, reporting whether it was paused and whether the
+// pause froze the hand in progress
+func (s *pauseState) clear() (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paused, frozeHand := s.paused, s.frozeHand
+	*s = pauseState{}
+	return paused, frozeHand
+}
+
+// isPaused reports whether a moderator has paused the table
+func (t *table) isPaused() bool {
+	t.pause.mu.RLock()
+	defer t.pause.mu.RUnlock()
+	return t.pause.paused
+}
+
+// seatedUsername returns the name of the player seated at position
+func (t *table) seatedUsername(position uint) string {
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	if int(position) < len(view.Players) {
+		return view.Players[position].Username
+	}
+	return ""
+}
+
+// ForceFold folds a player's hand for them when the table is waiting on
+// them, as if their time had run out
+func (h *Hub) ForceFold(tableID, userID uuid.UUID, reason string) error {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return services.ErrTableNotFound
+	}
+	if t.isHandFrozen() {
+		return services.ErrHandFrozen
+	}
+
+	t.turn.mu.Lock()
+	open, actor, token := t.turn.open, t.turn.actor, t.turn.token
+	t.turn.mu.Unlock()
+	if !open {
+		return services.ErrNoHandInProgress
+	}
+	if actor != userID {
+		return services.ErrNotPlayersTurn
+	}
+	position, ok := t.game.PlayerPosition(userID)
+	c := t.timeoutClient(userID)
+	if !ok || c == nil {
+		return services.ErrPlayerNotAtTable
+	}
+
+	folded := false
+	sequencedAction(c, token, func() bool {
+		folded = handleFold(c)
+		return folded
+	})
+	// The player acted, or the hand moved on, in the meantime
+	if !folded {
+		return services.ErrNotPlayersTurn
+	}
+
+	slog.Warn("Player force-folded by a moderator", "table", t.name, "user_id", userID, "reason", reason)
+	notice := fmt.Sprintf("%s was folded by a moderator", t.seatedUsername(position))
+	if reason != "" {
+		notice += ". Reason: " + reason
+	}
+	t.announce(notice)
+	return nil
+}
+
+// PauseTable stops play at a table: the hand in progress is frozen where it
+// stands and no new hand is dealt until the table is resumed
+func (h *Hub) PauseTable(tableID uuid.UUID, reason string) error {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return services.ErrTableNotFound
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	handID := t.game.CurrentHandID()
+	frozeHand := false
+	if t.game.GetLegacyGame().GenerateOmniView().Running {
+		frozeHand = t.dispute.freeze(handID, reason)
+	}
+	if !t.pause.set(reason, frozeHand) {
+		return nil
+	}
+
+	slog.Warn("Table paused by a moderator", "table", t.name, "hand_id", handID, "reason", reason)
+	t.transition(models.TableStatePaused, reasonTablePaused)
+	notice := "A moderator has paused the table. Play continues once it is resumed."
+	if reason != "" {
+		notice += " Reason: " + reason
+	}
+	t.announceHand(handID, notice)
+	return nil
+}
+
+// ResumeTable lets play carry on at a paused table, from where the hand was
+// stopped or with the next hand. A hand frozen for a dispute stays frozen.
+func (h *Hub) ResumeTable(tableID uuid.UUID) error {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return services.ErrTableNotFound
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	paused, frozeHand := t.pause.clear()
+	if !paused {
+		return services.ErrTableNotPaused
+	}
+
+	handID := t.game.CurrentHandID()
+	slog.Warn("Table resumed by a moderator", "table", t.name, "hand_id", handID)
+	t.announceHand(handID, "The moderator has resumed the table. Play continues.")
+	if frozeHand && t.dispute.clear() {
+		t.transition(models.TableStateHandRunning, reasonTableResumed)
+		t.announceTurnLocked()
+		t.scheduleTurnNudge()
+		return nil
+	}
+	if !t.isHandFrozen() {
+		scheduleAutoHandStart(t, reasonTableResumed)
+	}
+	return nil
+}
+
+// KickPlayer removes a player from a table, cashing their stack out to
+// their wallet and freeing the seat. Returns services.ErrHandInProgress while
+// they are dealt into a hand: fold them first, or wait for it to end.
+func (h *Hub) KickPlayer(tableID, userID uuid.UUID, reason string) (*models.RecoveredSession, error) {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return nil, services.ErrTableNotFound
+	}
+
+	holder, result, err := h.cashOutKicked(t, userID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	notice := "A moderator removed you from the table."
+	if reason != "" {
+		notice += " Reason: " + reason
+	}
+	safeSend(holder, createCodedErrorMessage(errorCodeRemovedFromTable, notice))
+	if result.TransactionID != "" {
+		safeSend(holder, createSuccessMessage(fmt.Sprintf("Cashed out %d MNT to your wallet. Transaction ID: %s", result.Amount, result.TransactionID)))
+		sendBalanceUpdateToClient(holder, "cash_out", result.Amount, result.TransactionID)
+	}
+	holder.uuid = ""
+	t.unregister <- holder
+	t.broadcast <- createTableUpdate(t)
+	return result, nil
+}
+
+// cashOutKicked takes a kicked player's stack off the table, returning the
+// connection they held the seat with
+func (h *Hub) cashOutKicked(t *table, userID uuid.UUID, reason string) (*Client, *models.RecoveredSession, error) {
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	position, seated := t.game.PlayerPosition(userID)
+	if !seated {
+		return nil, nil, services.ErrPlayerNotAtTable
+	}
+	if seat, ok := t.liveSeat(userID); ok && seat.HandInProgress && t.dealtIn(userID) {
+		return nil, nil, services.ErrHandInProgress
+	}
+
+	var holder *Client
+	h.usersMu.RLock()
+	for c := range h.userClients[userID] {
+		if c.table == t {
+			holder = c
+			break
+		}
+	}
+	h.usersMu.RUnlock()
+	// A player who dropped holds the seat through their old connection until
+	// they reconnect or the window runs out
+	held := false
+	if holder == nil {
+		holder = h.heldSeatClient(t, userID)
+		held = holder != nil
+	}
+	// Chips leave the table with a player who disconnects
+	if holder == nil {
+		return nil, nil, services.ErrPlayerNotAtTable
+	}
+
+	username := t.seatedUsername(position)
+	result, err := t.cashOutSeat(holder, "moderator_kick")
+	if errors.Is(err, errSeatNotHeld) {
+		return nil, nil, services.ErrPlayerNotAtTable
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to cash out seat: %w", err)
+	}
+	t.sitOut.forget(userID)
+	if held {
+		h.dropHeldSeat(userID)
+	}
+
+	slog.Warn("Player kicked by a moderator", "table", t.name, "user_id", userID, "reason", reason, "amount", result.Amount)
+	t.announce(fmt.Sprintf("%s was removed from the table by a moderator", username))
+	t.broadcastPlayerStatus(userID, username, position, playerStatusCashedOut, "kicked")
+	return holder, result, nil
+}
+
+// CancelHand voids the hand in progress and gives every player back what
+// they bet on it, as a misdeal does. Bets only reach the ledger when a pot
+// is paid, so returning them to the players' stacks is the whole refund.
+// The refunds are returned by position.
+func (h *Hub) CancelHand(tableID uuid.UUID, reason string) ([]models.HandPayout, error) {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return nil, services.ErrTableNotFound
+	}
+	// Voiding is applied to the legacy game, which the engine would not see
+	if t.executionPath() == executionEngine {
+		return nil, services.ErrRulingNotAllowed
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	game := t.game.GetLegacyGame()
+	handID := t.game.CurrentHandID()
+	before := game.GenerateOmniView()
+	if !before.Running {
+		return nil, services.ErrNoHandInProgress
+	}
+	detail := "cancelled by a moderator"
+	if reason != "" {
+		detail += ": " + reason
+	}
+	if err := game.VoidHand(poker.MisdealManual, detail); err != nil {
+		return nil, services.ErrNoHandInProgress
+	}
+
+	refunds := make([]models.HandPayout, 0, len(before.Players))
+	for i, p := range before.Players {
+		if refund := p.TotalBet + p.Ante; refund > 0 {
+			userID, _ := uuid.Parse(p.UUID)
+			refunds = append(refunds, models.HandPayout{Position: uint(i), UserID: userID, Username: p.Username, Amount: int64(refund)})
+		}
+	}
+
+	// A hand frozen for a dispute or a pause is over; a paused table still
+	// waits to be resumed before the next hand
+	t.dispute.clear()
+	t.pause.mu.Lock()
+	t.pause.frozeHand = false
+	t.pause.mu.Unlock()
+
+	slog.Warn("Hand cancelled by a moderator", "table", t.name, "hand_id", handID, "reason", reason, "refunds", refunds)
+	t.announceHand(handID, "A moderator has cancelled the hand.")
+	handleMisdeal(t)
+	t.broadcast <- createTableUpdate(t)
+	t.announceTurnLocked()
+	return refunds, nil
+}
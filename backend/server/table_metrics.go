@@ -0,0 +1,264 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+const (
+	// A table with enough ready players should deal within a few seconds of
+	// the last hand ending; much longer means auto-start never fired
+	autoStartStallAfter = 30 * time.Second
+	// A hand with nobody acting for this long is stuck rather than slow
+	actionStallAfter = 10 * time.Minute
+)
+
+// tableActivity tracks when a table last made progress. It is updated from
+// player actions and hand starts, and checked again at every scrape so hands
+// ending or streets changing without an action are still noticed.
+type tableActivity struct {
+	mu         sync.Mutex
+	lastAction time.Time
+	stage      string
+	stageSince time.Time
+}
+
+// observe records the table's current stage, restarting the stage clock if
+// it moved on
+func (a *tableActivity) observe(view *poker.GameView, now time.Time) {
+	stage := metricsStage(view)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if stage != a.stage || a.stageSince.IsZero() {
+		a.stage = stage
+		a.stageSince = now
+	}
+	if a.lastAction.IsZero() {
+		a.lastAction = now
+	}
+}
+
+// recordAction notes that a player acted or a hand was dealt
+func (a *tableActivity) recordAction(view *poker.GameView, now time.Time) {
+	a.observe(view, now)
+
+	a.mu.Lock()
+	a.lastAction = now
+	a.mu.Unlock()
+}
+
+func metricsStage(view *poker.GameView) string {
+	if !view.Running {
+		return "waiting"
+	}
+	return streetName(view.Stage)
+}
+
+// tableSample is one table's state at scrape time
+type tableSample struct {
+	name             string
+	stage            string
+	running          bool
+	sinceLastAction  time.Duration
+	inStage          time.Duration
+	clients          int
+	seated           int
+	ready            int
+	readOnly         bool
+	closed           bool
+	autoStartStalled bool
+	actionStalled    bool
+	lifecycle        string
+	lifecycleReason  string
+	inLifecycle      time.Duration
+	transitions      map[lifecycleKey]int64
+}
+
+func (t *table) sample(now time.Time) tableSample {
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	t.activity.observe(view, now)
+
+	t.activity.mu.Lock()
+	s := tableSample{
+		name:            t.name,
+		stage:           t.activity.stage,
+		running:         view.Running,
+		sinceLastAction: now.Sub(t.activity.lastAction),
+		inStage:         now.Sub(t.activity.stageSince),
+		clients:         int(t.connected.Load()),
+	}
+	t.activity.mu.Unlock()
+
+	for _, p := range view.Players {
+		if p.Left {
+			continue
+		}
+		s.seated++
+		if p.Ready && p.Stack > 0 {
+			s.ready++
+		}
+	}
+	s.readOnly = t.isReadOnly()
+	s.closed = t.callTimeClosed()
+
+	var since time.Time
+	s.lifecycle, s.lifecycleReason, since = t.lifecycleState()
+	s.inLifecycle = now.Sub(since)
+	s.transitions = t.lifecycleCounts()
+
+	canStart := !s.running && s.ready >= t.minPlayersToDeal() && !s.readOnly && !s.closed
+	s.autoStartStalled = canStart && s.inStage > autoStartStallAfter
+	s.actionStalled = s.running && s.sinceLastAction > actionStallAfter
+	return s
+}
+
+// WriteMetrics writes per-table gauges and WebSocket counters in the
+// Prometheus text exposition format
+func (h *Hub) WriteMetrics(w io.Writer) {
+	now := time.Now()
+
+	h.tablesMu.RLock()
+	tables := make([]*table, 0, len(h.tables))
+	for t := range h.tables {
+		tables = append(tables, t)
+	}
+	h.tablesMu.RUnlock()
+
+	samples := make([]tableSample, len(tables))
+	for i, t := range tables {
+		samples[i] = t.sample(now)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].name < samples[j].name })
+
+	m := &metricsWriter{w: w}
+
+	m.header("gp_tables", "gauge", "Tables open on this server.")
+	m.value("gp_tables", "", float64(len(samples)))
+	m.header("gp_websocket_clients", "gauge", "WebSocket connections to this server.")
+	m.value("gp_websocket_clients", "", float64(h.connected.Load()))
+	m.header("gp_held_seats", "gauge", "Seats held for players whose connection dropped, waiting for them to reconnect.")
+	m.value("gp_held_seats", "", float64(h.heldSeatCount()))
+
+	m.header("gp_table_seconds_since_last_action", "gauge", "Seconds since a player last acted or a hand was dealt.")
+	for _, s := range samples {
+		m.value("gp_table_seconds_since_last_action", label("table", s.name), s.sinceLastAction.Seconds())
+	}
+	m.header("gp_table_seconds_in_stage", "gauge", "Seconds the table has spent in its current stage (waiting, preflop, flop, turn or river).")
+	for _, s := range samples {
+		m.value("gp_table_seconds_in_stage", label("table", s.name)+","+label("stage", s.stage), s.inStage.Seconds())
+	}
+	m.header("gp_table_hand_running", "gauge", "Whether a hand is in progress.")
+	for _, s := range samples {
+		m.value("gp_table_hand_running", label("table", s.name), boolValue(s.running))
+	}
+	m.header("gp_table_connected_clients", "gauge", "WebSocket clients watching or playing at the table.")
+	for _, s := range samples {
+		m.value("gp_table_connected_clients", label("table", s.name), float64(s.clients))
+	}
+	m.header("gp_table_seated_players", "gauge", "Players holding a seat.")
+	for _, s := range samples {
+		m.value("gp_table_seated_players", label("table", s.name), float64(s.seated))
+	}
+	m.header("gp_table_ready_players", "gauge", "Seated players who are ready and have chips.")
+	for _, s := range samples {
+		m.value("gp_table_ready_players", label("table", s.name), float64(s.ready))
+	}
+	m.header("gp_table_read_only", "gauge", "Whether the table is frozen by the read-only switch.")
+	for _, s := range samples {
+		m.value("gp_table_read_only", label("table", s.name), boolValue(s.readOnly))
+	}
+	m.header("gp_table_closed", "gauge", "Whether the table closed at call time.")
+	for _, s := range samples {
+		m.value("gp_table_closed", label("table", s.name), boolValue(s.closed))
+	}
+	m.header("gp_table_stalled", "gauge", fmt.Sprintf("Whether the table looks wedged: auto_start when two or more ready players have waited over %s for a hand, no_action when nobody has acted in a running hand for over %s.", autoStartStallAfter, actionStallAfter))
+	for _, s := range samples {
+		m.value("gp_table_stalled", label("table", s.name)+","+label("reason", "auto_start"), boolValue(s.autoStartStalled))
+		m.value("gp_table_stalled", label("table", s.name)+","+label("reason", "no_action"), boolValue(s.actionStalled))
+	}
+
+	m.header("gp_table_lifecycle_state", "gauge", "Whether the table is in each lifecycle state: waiting_for_players, hand_running, settling, auto_start_pending or paused.")
+	for _, s := range samples {
+		for _, state := range models.TableStates {
+			m.value("gp_table_lifecycle_state", label("table", s.name)+","+label("state", state), boolValue(s.lifecycle == state))
+		}
+	}
+	m.header("gp_table_lifecycle_state_seconds", "gauge", "Seconds the table has been in its lifecycle state, labelled with the reason it entered it.")
+	for _, s := range samples {
+		m.value("gp_table_lifecycle_state_seconds", label("table", s.name)+","+label("state", s.lifecycle)+","+label("reason", s.lifecycleReason), s.inLifecycle.Seconds())
+	}
+	m.header("gp_table_lifecycle_transitions_total", "counter", "Lifecycle transitions by the state entered and the reason, e.g. waiting_for_players for not_enough_players.")
+	for _, s := range samples {
+		keys := make([]lifecycleKey, 0, len(s.transitions))
+		for key := range s.transitions {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].to != keys[j].to {
+				return keys[i].to < keys[j].to
+			}
+			return keys[i].reason < keys[j].reason
+		})
+		for _, key := range keys {
+			m.value("gp_table_lifecycle_transitions_total", label("table", s.name)+","+label("to", key.to)+","+label("reason", key.reason), float64(s.transitions[key]))
+		}
+	}
+
+	stats := h.SendQueueStats()
+	m.header("gp_ws_send_queue_size", "gauge", "Messages each client may have waiting.")
+	m.value("gp_ws_send_queue_size", "", float64(stats.QueueSize))
+	m.header("gp_ws_send_queue_messages", "gauge", "Messages waiting across all clients.")
+	m.value("gp_ws_send_queue_messages", "", float64(stats.Queued))
+	m.header("gp_ws_send_queue_peak_depth", "gauge", "Deepest any single client's queue has been.")
+	m.value("gp_ws_send_queue_peak_depth", "", float64(stats.PeakDepth))
+	for _, c := range []struct {
+		name, help string
+		value      int64
+	}{
+		{"gp_ws_messages_enqueued_total", "Messages queued for clients.", stats.Enqueued},
+		{"gp_ws_messages_delivered_total", "Messages written to clients.", stats.Delivered},
+		{"gp_ws_messages_coalesced_total", "Game views replaced by a newer one before they were sent.", stats.Coalesced},
+		{"gp_ws_messages_dropped_total", "Messages shed from full queues.", stats.Dropped},
+		{"gp_ws_send_queue_overflows_total", "Clients disconnected for falling too far behind.", stats.Overflows},
+	} {
+		m.header(c.name, "counter", c.help)
+		m.value(c.name, "", float64(c.value))
+	}
+}
+
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (m *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (m *metricsWriter) value(name, labels string, value float64) {
+	if labels != "" {
+		fmt.Fprintf(m.w, "%s{%s} %g\n", name, labels, value)
+		return
+	}
+	fmt.Fprintf(m.w, "%s %g\n", name, value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func label(name, value string) string {
+	return name + `="` + labelEscaper.Replace(value) + `"`
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
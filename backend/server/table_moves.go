@@ -0,0 +1,45 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/google/uuid"
+)
+
+// tableMove tells a tournament player which table and seat to go to
+type tableMove struct {
+	base                    // actionTableMove
+	TournamentID string     `json:"tournament_id"`
+	FromTable    int        `json:"from_table"`
+	FromSeat     int        `json:"from_seat"`
+	ToTable      int        `json:"to_table"`
+	ToSeat       int        `json:"to_seat"`
+	TableID      *uuid.UUID `json:"table_id,omitempty"`
+	TableName    string     `json:"table_name"` // The table to join
+	Reason       string     `json:"reason"`     // "balance", "table_broken" or "director"
+}
+
+// NotifyTableMoves tells each moved tournament player, on every server
+// instance, where their new seat is
+func (h *Hub) NotifyTableMoves(moves []models.TableMove) {
+	for _, move := range moves {
+		message, err := json.Marshal(tableMove{
+			base:         base{actionTableMove},
+			TournamentID: move.TournamentID.String(),
+			FromTable:    move.FromTable,
+			FromSeat:     move.FromSeat,
+			ToTable:      move.ToTable,
+			ToSeat:       move.ToSeat,
+			TableID:      move.TableID,
+			TableName:    move.TableName,
+			Reason:       move.Reason,
+		})
+		if err != nil {
+			slog.Warn("Marshal table move", "error", err)
+			continue
+		}
+		h.publishToUsers([]uuid.UUID{move.UserID}, message)
+	}
+}
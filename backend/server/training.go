@@ -0,0 +1,202 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/poker"
+)
+
+// trainingIterations is the number of Monte Carlo runouts per decision
+const trainingIterations = 2000
+
+// trainingDecisionSnapshot is what a player could see when they acted
+type trainingDecisionSnapshot struct {
+	client    *Client
+	action    string
+	committed uint
+	stage     poker.GameStage
+	hole      [2]eval.Card
+	board     []eval.Card
+	pot       uint
+	toCall    uint
+	opponents int
+}
+
+// trainingRecorder collects the decisions of opted-in players during a hand
+type trainingRecorder struct {
+	mu        sync.Mutex
+	handID    string
+	decisions []trainingDecisionSnapshot
+}
+
+// handleSetTrainingMode opts a player in or out of post-hand training summaries
+func handleSetTrainingMode(c *Client, enabled bool) {
+	if enabled && c.table != nil && !c.table.isPractice() {
+		safeSend(c, createCodedErrorMessage(errorCodeTrainingUnavailable, "Training mode is only available at play-money tables"))
+		return
+	}
+
+	c.trainingMode.Store(enabled)
+	if enabled {
+		safeSend(c, createSuccessMessage("Training mode on: you'll get a private summary of your decisions after each hand"))
+	} else {
+		safeSend(c, createSuccessMessage("Training mode off"))
+	}
+}
+
+// recordTrainingDecision snapshots a successful action by an opted-in player,
+// using the state before the action was applied
+func (t *table) recordTrainingDecision(c *Client, action string, pn uint, committed uint, pre *poker.GameView) {
+	if !c.trainingMode.Load() || int(pn) >= len(pre.Players) || !t.isPractice() {
+		return
+	}
+	// Equity is only worked out for Hold'em
+	if v := pre.Config.Variant; v != "" && v != poker.VariantHoldem {
+		return
+	}
+
+	actor := pre.Players[pn]
+	var pot, maxBet uint
+	opponents := 0
+	for i, p := range pre.Players {
+		pot += p.TotalBet + p.Ante
+		if p.Bet > maxBet {
+			maxBet = p.Bet
+		}
+		if uint(i) != pn && p.In {
+			opponents++
+		}
+	}
+	toCall := maxBet - actor.Bet
+	if toCall > actor.Stack {
+		toCall = actor.Stack
+	}
+	if action == "call" || action == "check" {
+		committed = toCall
+	}
+
+	handID := t.game.CurrentHandID()
+
+	t.training.mu.Lock()
+	defer t.training.mu.Unlock()
+	if t.training.handID != handID {
+		t.training.handID = handID
+		t.training.decisions = nil
+	}
+	t.training.decisions = append(t.training.decisions, trainingDecisionSnapshot{
+		client:    c,
+		action:    action,
+		committed: committed,
+		stage:     pre.Stage,
+		hole:      [2]eval.Card{actor.Cards[0], actor.Cards[1]},
+		board:     append([]eval.Card{}, pre.CommunityCards...),
+		pot:       pot,
+		toCall:    toCall,
+		opponents: opponents,
+	})
+}
+
+// sendTrainingSummaries analyses the decisions recorded for a finished hand
+// and privately sends each opted-in player their own summary
+func (t *table) sendTrainingSummaries(handID string) {
+	t.training.mu.Lock()
+	if t.training.handID != handID || len(t.training.decisions) == 0 {
+		t.training.mu.Unlock()
+		return
+	}
+	decisions := t.training.decisions
+	t.training.decisions = nil
+	t.training.mu.Unlock()
+
+	// Equity runs take a few milliseconds each; keep them off the action path
+	go func() {
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		byClient := make(map[*Client][]trainingDecision)
+		for _, d := range decisions {
+			byClient[d.client] = append(byClient[d.client], analyseDecision(d, rng))
+		}
+		for client, analysed := range byClient {
+			safeSend(client, createTrainingSummary(handID, analysed))
+		}
+	}()
+}
+
+// analyseDecision compares the chosen action with its alternatives. EVs are
+// in chips relative to folding and assume the hand goes to showdown with a
+// single caller for raises, so they ignore fold equity and later streets.
+func analyseDecision(d trainingDecisionSnapshot, rng *rand.Rand) trainingDecision {
+	equity := poker.Equity(d.hole, d.board, d.opponents, trainingIterations, rng)
+	pot := float64(d.pot)
+	toCall := float64(d.toCall)
+
+	ev := map[string]float64{}
+	if d.toCall > 0 {
+		ev["fold"] = 0
+		ev["call"] = equity*(pot+toCall) - toCall
+	} else {
+		ev["check"] = equity * pot
+	}
+	if d.action == "raise" {
+		raise := float64(d.committed)
+		ev["raise"] = equity*(pot+2*raise-toCall) - raise
+	}
+
+	best := ""
+	for action, value := range ev {
+		if best == "" || value > ev[best] {
+			best = action
+		}
+	}
+
+	potOdds := 0.0
+	if d.toCall > 0 {
+		potOdds = toCall / (pot + toCall)
+	}
+
+	return trainingDecision{
+		Street:     streetName(d.stage),
+		Action:     d.action,
+		Amount:     d.committed,
+		Pot:        d.pot,
+		ToCall:     d.toCall,
+		PotOdds:    potOdds,
+		Equity:     equity,
+		Opponents:  d.opponents,
+		EV:         ev,
+		BestAction: best,
+	}
+}
+
+func streetName(stage poker.GameStage) string {
+	switch stage {
+	case poker.PreFlop:
+		return "preflop"
+	case poker.Flop:
+		return "flop"
+	case poker.Turn:
+		return "turn"
+	case poker.River:
+		return "river"
+	default:
+		return "predeal"
+	}
+}
+
+func createTrainingSummary(handID string, decisions []trainingDecision) []byte {
+	message := trainingSummary{
+		base{actionTrainingSummary},
+		handID,
+		decisions,
+	}
+
+	resp, err := json.Marshal(message)
+	if err != nil {
+		slog.Default().Warn("Marshal training summary", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// turnClockInterval is how often the table is told how long the player to
+// act has left
+const turnClockInterval = time.Second
+
+// turnClockState passes each decision's deadline to the table's clock
+// goroutine, which counts it down to everyone at the table. Running out of
+// time is handled by the action timeout; the clock only shows it.
+type turnClockState struct {
+	updates chan turnClock // Holds the latest update only
+}
+
+// turnClock is the decision being timed. A zero deadline stops the clock.
+type turnClock struct {
+	turn     actionTurn
+	deadline time.Time
+}
+
+// actionTimer tells every client at the table how long the player to act
+// has before they check or fold
+type actionTimer struct {
+	base                       // actionActionTimer
+	HandID           string    `json:"hand_id"`
+	Seat             uint      `json:"seat"`
+	UserID           string    `json:"user_id,omitempty"`
+	RemainingSeconds int       `json:"remaining_seconds"`
+	TimeoutSeconds   int       `json:"timeout_seconds"`
+	Deadline         time.Time `json:"deadline"`
+	// The player acted, or the hand moved on, before time ran out
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// setTurnClock hands the clock goroutine its next decision, replacing any
+// update it hasn't picked up yet. Callers hold the sit out lock, so there is
+// only ever one writer.
+func (t *table) setTurnClock(clock turnClock) {
+	select {
+	case <-t.clock.updates:
+	default:
+	}
+	t.clock.updates <- clock
+}
+
+// runTurnClock broadcasts the time left on the current decision every
+// turnClockInterval, and once more when it stops early
+func (t *table) runTurnClock() {
+	var current turnClock
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	stopTicker := func() {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+	}
+
+	for {
+		select {
+		case next := <-t.clock.updates:
+			stopTicker()
+			if !current.deadline.IsZero() && time.Now().Before(current.deadline) && next.turn.Token != current.turn.Token {
+				t.broadcast <- createActionTimer(current, true)
+			}
+			current = next
+			if current.deadline.IsZero() {
+				continue
+			}
+			t.broadcast <- createActionTimer(current, false)
+			ticker = time.NewTicker(turnClockInterval)
+			tick = ticker.C
+
+		case <-tick:
+			t.broadcast <- createActionTimer(current, false)
+			if !time.Now().Before(current.deadline) {
+				stopTicker()
+				current = turnClock{}
+			}
+		}
+	}
+}
+
+func createActionTimer(clock turnClock, stopped bool) []byte {
+	remaining := time.Until(clock.deadline)
+	if remaining < 0 || stopped {
+		remaining = 0
+	}
+	resp, err := json.Marshal(actionTimer{
+		base:   base{actionActionTimer},
+		HandID: clock.turn.HandID,
+		Seat:   clock.turn.Seat,
+		UserID: clock.turn.UserID,
+		// Rounded up, so the count reaches 0 only when time is up
+		RemainingSeconds: int((remaining + time.Second - 1) / time.Second),
+		TimeoutSeconds:   clock.turn.TimeoutSeconds,
+		Deadline:         clock.deadline.UTC(),
+		Stopped:          stopped,
+	})
+	if err != nil {
+		slog.Default().Warn("Marshal action timer", "error", err)
+	}
+	return resp
+}
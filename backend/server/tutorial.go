@@ -0,0 +1,438 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alexclewontin/riverboat/eval"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+const (
+	tutorialStack      = 2000
+	tutorialSmallBlind = 50
+	tutorialBigBlind   = 100
+)
+
+// tutorialBot is how a scripted opponent plays
+type tutorialBot int
+
+const (
+	botCaller tutorialBot = iota // Checks when it can, otherwise calls
+	botFolder                    // Checks when it can, otherwise folds
+)
+
+// tutorialActions maps the betting messages a client can send to tutorial actions
+var tutorialActions = map[string]string{
+	actionPlayerCall:  "call",
+	actionPlayerCheck: "check",
+	actionPlayerRaise: "raise",
+	actionPlayerFold:  "fold",
+	"call":            "call",
+	"check":           "check",
+	"raise":           "raise",
+	"fold":            "fold",
+}
+
+// tutorialHand is what the user did in one tutorial hand
+type tutorialHand struct {
+	actedPreflop bool
+	raised       bool
+	folded       bool
+	showdown     bool
+	prompted     map[poker.GameStage]bool // Streets the user has been prompted on
+}
+
+// tutorialStep is one lesson of the walkthrough. Each step deals a fresh
+// three-handed game with player 0 on the button, so the user's blind and
+// position are fixed by userNum.
+type tutorialStep struct {
+	name    string
+	title   string
+	intro   string
+	prompts map[poker.GameStage]string // Shown when it's the user's turn
+	success string
+	retry   string
+	userNum uint
+	holes   [3][2]string // Hole cards by player number
+	board   [5]string
+	bots    map[uint]tutorialBot
+	goal    func(h tutorialHand) bool
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		name:  "post-blind",
+		title: "Posting the blinds",
+		intro: "Welcome! Every hand starts with two forced bets called blinds, posted by the two players left of the dealer button. " +
+			"You're the big blind this hand, so 100 chips went in before the cards were dealt.",
+		prompts: tutorialPrompts(
+			"Both opponents called your big blind. You already have 100 in, so you can check to see the flop for free.",
+			"The flop is out. Check or bet, the hand plays out as normal from here.",
+		),
+		success: "Nice! Posting blinds and taking your option is how every hand begins.",
+		retry:   "Folding the big blind when nobody raised throws away a free look at the flop. Let's play that hand again.",
+		userNum: 2,
+		holes:   [3][2]string{{"8c", "8d"}, {"Kd", "4c"}, {"Qh", "9h"}},
+		board:   [5]string{"2s", "7h", "Jc", "3d", "Qs"},
+		bots:    map[uint]tutorialBot{0: botCaller, 1: botCaller},
+		goal:    func(h tutorialHand) bool { return h.actedPreflop },
+	},
+	{
+		name:  "first-raise",
+		title: "Making your first raise",
+		intro: "You're on the dealer button this time, so you act first before the flop. " +
+			"A raise puts more chips in than the current bet and makes everyone else pay to continue.",
+		prompts: tutorialPrompts(
+			"You've been dealt pocket aces, the best starting hand there is. Raise by at least 200: "+
+				"a raise of 300 is a good size.",
+			"Your opponent called. With aces you can keep betting to grow the pot.",
+		),
+		success: "Great raise! Betting strong hands builds the pot you're likely to win.",
+		retry:   "Aces are worth a raise. Let's try that hand again.",
+		userNum: 0,
+		holes:   [3][2]string{{"As", "Ad"}, {"Jc", "5h"}, {"Th", "9c"}},
+		board:   [5]string{"Kc", "8d", "3s", "6h", "2d"},
+		bots:    map[uint]tutorialBot{1: botFolder, 2: botCaller},
+		goal:    func(h tutorialHand) bool { return h.raised },
+	},
+	{
+		name:  "showdown",
+		title: "Seeing a showdown",
+		intro: "When the river betting is over and more than one player is left, everyone shows their cards. " +
+			"The best five-card hand from your two cards and the five on the board wins the pot.",
+		prompts: tutorialPrompts(
+			"Kings! Call the big blind to see the flop.",
+			"Keep checking or calling until the river to reach the showdown.",
+		),
+		success: "That's a showdown: three kings beat three sevens. You've finished the tutorial!",
+		retry:   "Folding ends your hand before the showdown. Stay in until the river this time.",
+		userNum: 1,
+		holes:   [3][2]string{{"Qs", "Jd"}, {"Kh", "Kc"}, {"7c", "7d"}},
+		board:   [5]string{"Kd", "7s", "2c", "9h", "4d"},
+		bots:    map[uint]tutorialBot{0: botCaller, 2: botCaller},
+		goal:    func(h tutorialHand) bool { return h.showdown && !h.folded },
+	},
+}
+
+// tutorialPrompts shows one prompt before the flop and another on the first
+// postflop street the user acts on
+func tutorialPrompts(preflop, postflop string) map[poker.GameStage]string {
+	return map[poker.GameStage]string{
+		poker.PreFlop: preflop,
+		poker.Flop:    postflop,
+	}
+}
+
+// tutorialSession is a private tutorial table for one client. It is only
+// used from the client's read loop, so it needs no locking.
+type tutorialSession struct {
+	step    int
+	game    *poker.Game
+	adapter *SimpleGameAdapter // Converts views to the format the table UI expects
+	hand    tutorialHand
+}
+
+// handleStartTutorial deals the first tutorial hand at a private table
+func handleStartTutorial(c *Client) {
+	if c.table != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeTutorialUnavailable, "Leave your table before starting the tutorial"))
+		return
+	}
+
+	c.tutorial = &tutorialSession{adapter: NewSimpleGameAdapter(nil, "tutorial")}
+	safeSend(c, createUpdatedPlayerUUID(c))
+	dealTutorialStep(c)
+}
+
+// handleLeaveTutorial abandons the tutorial without a reward
+func handleLeaveTutorial(c *Client) {
+	if c.tutorial == nil {
+		return
+	}
+	c.tutorial = nil
+	safeSend(c, createSuccessMessage("Tutorial closed. You can start it again at any time."))
+}
+
+// handleTutorialAction applies one of the user's betting actions, lets the
+// bots respond and moves the step machine on when the hand is over
+func handleTutorialAction(c *Client, action string, amount uint) {
+	ts := c.tutorial
+	step := tutorialSteps[ts.step]
+
+	view := ts.game.GenerateOmniView()
+	if !view.Betting || view.ActionNum != step.userNum {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, "It's not your turn yet"))
+		return
+	}
+
+	if err := applyTutorialAction(ts.game, view, step.userNum, action, amount); err != nil {
+		safeSend(c, createCodedErrorMessage(errorCodeActionRejected, fmt.Sprintf("You can't %s right now", action)))
+		return
+	}
+	switch {
+	case action == "fold":
+		ts.hand.folded = true
+	case action == "raise":
+		ts.hand.raised = true
+	}
+	if view.Stage == poker.PreFlop && action != "fold" {
+		ts.hand.actedPreflop = true
+	}
+
+	runTutorialBots(c)
+	advanceTutorial(c)
+}
+
+// dealTutorialStep starts a fresh hand for the current step
+func dealTutorialStep(c *Client) {
+	ts := c.tutorial
+	step := tutorialSteps[ts.step]
+
+	game, err := newTutorialGame(step, c.username)
+	if err != nil {
+		slog.Default().Error("Failed to deal tutorial hand", "step", step.name, "error", err)
+		c.tutorial = nil
+		safeSend(c, createCodedErrorMessage(errorCodeTutorialUnavailable, "The tutorial is unavailable right now"))
+		return
+	}
+
+	ts.game = game
+	ts.hand = tutorialHand{prompted: make(map[poker.GameStage]bool)}
+	ts.adapter.legacyGame = game
+	ts.adapter.playerPositionToUUID = map[uint]string{step.userNum: c.uuid}
+	ts.adapter.SetHandID(fmt.Sprintf("TUTORIAL-%d", ts.step+1))
+
+	safeSend(c, createTutorialStep(ts.step, step.intro))
+	runTutorialBots(c)
+	advanceTutorial(c)
+}
+
+// advanceTutorial prompts the user while the hand is running, and once it is
+// over either moves to the next step or replays this one
+func advanceTutorial(c *Client) {
+	ts := c.tutorial
+	step := tutorialSteps[ts.step]
+	view := ts.game.GenerateOmniView()
+
+	safeSend(c, createTutorialUpdate(c))
+
+	if view.Running {
+		if view.Betting && view.ActionNum == step.userNum {
+			if prompt, ok := step.prompts[view.Stage]; ok && !ts.hand.prompted[view.Stage] {
+				ts.hand.prompted[view.Stage] = true
+				safeSend(c, createTutorialStep(ts.step, prompt))
+			}
+		}
+		return
+	}
+
+	ts.hand.showdown = len(view.Pots) > 0 && view.Pots[0].WinningScore < 8000
+	if !step.goal(ts.hand) {
+		safeSend(c, createTutorialStep(ts.step, step.retry))
+		dealTutorialStep(c)
+		return
+	}
+
+	safeSend(c, createTutorialStep(ts.step, step.success))
+	if ts.step+1 < len(tutorialSteps) {
+		ts.step++
+		dealTutorialStep(c)
+		return
+	}
+
+	completeTutorial(c)
+}
+
+// completeTutorial pays the reward to signed-in users who haven't had it yet
+func completeTutorial(c *Client) {
+	c.tutorial = nil
+
+	var reward int64
+	if c.userID != uuid.Nil && c.hub.tutorials != nil {
+		completion, err := c.hub.tutorials.Complete(context.Background(), c.userID)
+		switch {
+		case err == nil:
+			reward = completion.RewardChips
+		case errors.Is(err, services.ErrTutorialAlreadyCompleted):
+		default:
+			slog.Default().Error("Failed to complete tutorial", "user_id", c.userID, "error", err)
+		}
+	}
+
+	safeSend(c, createTutorialComplete(reward))
+}
+
+// runTutorialBots plays for the bots until it's the user's turn or the hand ends
+func runTutorialBots(c *Client) {
+	ts := c.tutorial
+	step := tutorialSteps[ts.step]
+	handID := ts.adapter.CurrentHandID()
+
+	for {
+		view := ts.game.GenerateOmniView()
+		if !view.Running || !view.Betting || view.ActionNum == step.userNum {
+			return
+		}
+
+		pn := view.ActionNum
+		action := step.bots[pn].decide(view, pn)
+		if err := applyTutorialAction(ts.game, view, pn, action, 0); err != nil {
+			// A scripted hand that can't continue is a bug in the script
+			slog.Default().Error("Tutorial bot action failed", "step", step.name, "player_num", pn, "action", action, "error", err)
+			return
+		}
+		safeSend(c, createNewLog(handID, fmt.Sprintf("%s %ss", view.Players[pn].Username, action)))
+	}
+}
+
+// decide picks a bot's action from the bets in front of it
+func (b tutorialBot) decide(view *poker.GameView, pn uint) string {
+	if tutorialToCall(view, pn) == 0 {
+		return "check"
+	}
+	if b == botFolder {
+		return "fold"
+	}
+	return "call"
+}
+
+// applyTutorialAction performs a betting action. For raises, amount is the
+// number of chips put in, as at real tables.
+func applyTutorialAction(game *poker.Game, view *poker.GameView, pn uint, action string, amount uint) error {
+	switch action {
+	case "fold":
+		return poker.Fold(game, pn, 0)
+	case "check":
+		return poker.Bet(game, pn, 0)
+	case "call":
+		return poker.Bet(game, pn, tutorialToCall(view, pn))
+	case "raise":
+		return poker.Bet(game, pn, amount)
+	default:
+		return poker.ErrIllegalAction
+	}
+}
+
+// tutorialToCall is what a player must add to call, capped at their stack
+func tutorialToCall(view *poker.GameView, pn uint) uint {
+	var maxBet uint
+	for _, p := range view.Players {
+		if p.Bet > maxBet {
+			maxBet = p.Bet
+		}
+	}
+	toCall := maxBet - view.Players[pn].Bet
+	if toCall > view.Players[pn].Stack {
+		toCall = view.Players[pn].Stack
+	}
+	return toCall
+}
+
+// newTutorialGame seats the user and bots for a step and deals its hand
+func newTutorialGame(step tutorialStep, username string) (*poker.Game, error) {
+	if username == "" {
+		username = "You"
+	}
+
+	game := poker.NewGame()
+	if err := game.SetConfig(poker.GameConfig{SmallBlind: tutorialSmallBlind, BigBlind: tutorialBigBlind}); err != nil {
+		return nil, err
+	}
+
+	botNames := []string{"Bot Ana", "Bot Ben"}
+	for pn := uint(0); pn < uint(len(step.holes)); pn++ {
+		name := username
+		if pn != step.userNum {
+			name, botNames = botNames[0], botNames[1:]
+		}
+
+		game.AddPlayer()
+		if err := poker.SetUsername(game, pn, name); err != nil {
+			return nil, err
+		}
+		if err := poker.BuyIn(game, pn, tutorialStack); err != nil {
+			return nil, err
+		}
+		// Seats follow player numbers, so seating doesn't reorder anyone
+		if err := poker.SetSeatID(game, pn, pn+1); err != nil {
+			return nil, err
+		}
+		if err := poker.ToggleReady(game, pn, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	var order []eval.Card
+	for _, hole := range step.holes {
+		order = append(order, eval.MustParseCardString(hole[0]), eval.MustParseCardString(hole[1]))
+	}
+	for _, card := range step.board {
+		order = append(order, eval.MustParseCardString(card))
+	}
+	if err := game.StackDeck(order); err != nil {
+		return nil, err
+	}
+
+	if err := game.Start(); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// createTutorialUpdate is the tutorial table as the user may see it, in the
+// same format as real table updates
+func createTutorialUpdate(c *Client) []byte {
+	ts := c.tutorial
+	view := ts.game.GeneratePlayerView(tutorialSteps[ts.step].userNum)
+
+	game := updateGame{
+		base{actionUpdateGame},
+		ts.adapter.convertLegacyToEngineView(view),
+		nil,
+		false,
+		nil,
+	}
+
+	resp, err := json.Marshal(game)
+	if err != nil {
+		slog.Default().Warn("Marshal tutorial update", "error", err)
+	}
+	return resp
+}
+
+func createTutorialStep(index int, message string) []byte {
+	step := tutorialSteps[index]
+	msg := tutorialStepMessage{
+		base{actionTutorialStep},
+		step.name,
+		step.title,
+		index + 1,
+		len(tutorialSteps),
+		message,
+	}
+
+	resp, err := json.Marshal(msg)
+	if err != nil {
+		slog.Default().Warn("Marshal tutorial step", "error", err)
+	}
+	return resp
+}
+
+func createTutorialComplete(reward int64) []byte {
+	msg := tutorialComplete{
+		base{actionTutorialComplete},
+		reward,
+	}
+
+	resp, err := json.Marshal(msg)
+	if err != nil {
+		slog.Default().Warn("Marshal tutorial complete", "error", err)
+	}
+	return resp
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/internal/services"
+	"github.com/google/uuid"
+)
+
+// CloseLiveSession cashes a player out of the running table holding their
+// game session and frees their seat, at their own request from outside the
+// table. Returns services.ErrHandInProgress while they are dealt into a hand
+// and services.ErrSessionNotLive when no running table holds the session.
+func (h *Hub) CloseLiveSession(userID, tableID, sessionID uuid.UUID) (*models.RecoveredSession, error) {
+	t := h.findTableByID(tableID)
+	if t == nil {
+		return nil, services.ErrSessionNotLive
+	}
+
+	t.turn.mu.Lock()
+	defer t.turn.mu.Unlock()
+
+	if seat, ok := t.liveSeat(userID); ok && seat.HandInProgress && t.dealtIn(userID) {
+		return nil, services.ErrHandInProgress
+	}
+
+	var holder *Client
+	h.usersMu.RLock()
+	for c := range h.userClients[userID] {
+		if c.table == t && c.sessionID == sessionID {
+			holder = c
+			break
+		}
+	}
+	h.usersMu.RUnlock()
+	// Chips leave the table with a player who disconnects
+	if holder == nil {
+		return nil, services.ErrSessionNotLive
+	}
+
+	result, err := t.cashOutSeat(holder, "player_closed_session")
+	if errors.Is(err, errSeatNotHeld) {
+		return nil, services.ErrSessionNotLive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cash out seat: %w", err)
+	}
+
+	slog.Info("Player closed their session from outside the table", "table", t.name, "user_id", userID, "session_id", sessionID, "amount", result.Amount)
+	if result.TransactionID != "" {
+		safeSend(holder, createSuccessMessage(fmt.Sprintf("You closed your session. Cashed out %d MNT to your wallet. Transaction ID: %s", result.Amount, result.TransactionID)))
+		sendBalanceUpdateToClient(holder, "cash_out", result.Amount, result.TransactionID)
+	}
+	t.broadcast <- createTableUpdate(t)
+	return result, nil
+}
+
+func (h *Hub) findTableByID(tableID uuid.UUID) *table {
+	h.tablesMu.RLock()
+	defer h.tablesMu.RUnlock()
+
+	for t := range h.tables {
+		if id := t.game.GetTableID(); id != nil && *id == tableID {
+			return t
+		}
+	}
+	return nil
+}
+
+// dealtIn reports whether the user is still in the hand being played
+func (t *table) dealtIn(userID uuid.UUID) bool {
+	position, ok := t.game.PlayerPosition(userID)
+	if !ok {
+		return false
+	}
+	view := t.game.GetLegacyGame().GenerateOmniView()
+	return int(position) < len(view.Players) && view.Players[position].In
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/anhbaysgalan1/gp/internal/models"
+	"github.com/anhbaysgalan1/gp/poker"
+	"github.com/google/uuid"
+)
+
+// stakeState is the chips every player had when the hand was dealt, for
+// working out what each of them wagered once it is over. The game resets
+// bets before the pots are paid, so they can't be read off the end state.
+type stakeState struct {
+	mu     sync.Mutex
+	handID string
+	stacks map[uuid.UUID]uint
+}
+
+// begin notes the players' chips as the hand is dealt, counting back in
+// any blinds and antes already posted
+func (s *stakeState) begin(handID string, view *poker.GameView) {
+	stacks := make(map[uuid.UUID]uint, len(view.Players))
+	for _, p := range view.Players {
+		if userID, err := uuid.Parse(p.UUID); err == nil {
+			stacks[userID] = p.Stack + p.TotalBet + p.Ante
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handID = handID
+	s.stacks = stacks
+}
+
+// addWagers fills in what each dealt-in player wagered on the hand: the
+// chips they started with, less what they have now, plus what they were
+// paid. Nothing is filled in for a hand dealt before this server took the
+// table.
+func (s *stakeState) addWagers(handID string, view *poker.GameView, winners []models.HandWinner, players []models.HandPlayerCards) {
+	s.mu.Lock()
+	stacks := s.stacks
+	known := s.handID == handID
+	s.mu.Unlock()
+	if !known {
+		return
+	}
+
+	paid := make(map[uuid.UUID]int64, len(winners))
+	for _, winner := range winners {
+		paid[winner.UserID] += winner.Amount
+	}
+	now := make(map[uuid.UUID]uint, len(view.Players))
+	for _, p := range view.Players {
+		if userID, err := uuid.Parse(p.UUID); err == nil {
+			now[userID] = p.Stack
+		}
+	}
+
+	for i := range players {
+		userID := players[i].UserID
+		start, ok := stacks[userID]
+		if !ok {
+			continue
+		}
+		players[i].Wagered = max(int64(start)-int64(now[userID])+paid[userID], 0)
+	}
+}
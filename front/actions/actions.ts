@@ -24,6 +24,23 @@ export function sendMessage(username: string, message: string): boolean {
     });
 }
 
+// Moderators only: mute a player in this table's chat, for 0 minutes until unmuted
+export function mutePlayer(userId: string, minutes: number, reason?: string): boolean {
+    return sendWSMessage({
+        action: "mute-player",
+        user_id: userId,
+        minutes: minutes,
+        reason: reason,
+    });
+}
+
+export function unmutePlayer(userId: string): boolean {
+    return sendWSMessage({
+        action: "unmute-player",
+        user_id: userId,
+    });
+}
+
 export function sendLog(message: string): boolean {
     return sendWSMessage({
         action: "send-log",
@@ -167,6 +167,77 @@ export function WebSocketProvider({ children }: WebSocketProviderProps) {
         alert(event.message);
         break;
 
+      case "command-duplicate":
+        console.debug("WebSocket command already received:", event.command_id);
+        break;
+
+      case "choose-game-prompt":
+        // Dealer's choice: the table shows the picker to the player on the button
+        if (typeof window !== 'undefined') {
+          window.dispatchEvent(new CustomEvent('choose-game-prompt', {
+            detail: {
+              user_id: event.user_id,
+              seat: event.seat,
+              games: event.games,
+              current: event.current,
+              timeout_seconds: event.timeout_seconds,
+            }
+          }));
+        }
+        break;
+
+      case "player-status":
+        // A player was sat out for timing out, sat back in or cashed out
+        if (typeof window !== 'undefined') {
+          window.dispatchEvent(new CustomEvent('player-status', {
+            detail: {
+              user_id: event.user_id,
+              username: event.username,
+              seat: event.seat,
+              status: event.status,
+              reason: event.reason,
+            }
+          }));
+        }
+        break;
+
+      case "table-move":
+        // Tournament balancing moved this player to another table
+        if (typeof window !== 'undefined') {
+          window.dispatchEvent(new CustomEvent('table-move', {
+            detail: {
+              tournament_id: event.tournament_id,
+              from_table: event.from_table,
+              from_seat: event.from_seat,
+              to_table: event.to_table,
+              to_seat: event.to_seat,
+              table_id: event.table_id,
+              table_name: event.table_name,
+              reason: event.reason,
+            }
+          }));
+        }
+        break;
+
+      case "action-timer":
+        // Time left for the player to act before they check or fold
+        if (typeof window !== 'undefined') {
+          window.dispatchEvent(new CustomEvent('action-timer', {
+            detail: {
+              hand_id: event.hand_id,
+              seat: event.seat,
+              user_id: event.user_id,
+              remaining_seconds: event.remaining_seconds,
+              // Counted against the server's clock, so it ends when the server's does
+              remaining_ms: Math.max(0, Date.parse(event.deadline) - wsManager.serverNow()),
+              timeout_seconds: event.timeout_seconds,
+              deadline: event.deadline,
+              stopped: event.stopped ?? false,
+            }
+          }));
+        }
+        break;
+
       case "success":
         console.log("WebSocket success:", event.message);
         // TODO: Replace with proper toast notification
@@ -271,6 +342,16 @@ export function useTableWebSocket(tableId?: string) {
     leaveTable: (tableName: string) => sendMessage({
       action: "leave-table",
       tablename: tableName
+    }),
+    chooseGame: (game: string) => sendMessage({
+      action: "choose-game",
+      game
+    }),
+    sitIn: () => sendMessage({
+      action: "sit-in"
+    }),
+    sitOut: () => sendMessage({
+      action: "sit-out"
     })
   };
 }
\ No newline at end of file
@@ -29,6 +29,7 @@ export type Player = {
     in: boolean;
     called: boolean;
     left: boolean;
+    sittingOut?: boolean;
     totalBuyIn: number;
     stack: number;
     bet: number;
@@ -14,7 +14,25 @@ import {
   UpdateTableRequest,
   JoinTableRequest,
   JoinTableResponse,
-  TableListResponse
+  MoveTableRequest,
+  MoveTableResponse,
+  TableListResponse,
+  MaintenanceNotice,
+  SpinFormat,
+  SpinJoinResponse,
+  SpinProof,
+  LastLongerPool,
+  CreateLastLongerPoolRequest,
+  TournamentPayoutPreview,
+  TreasurySummary,
+  RakeFreeWindow,
+  CreateRakeFreeWindowRequest,
+  SeatReservation,
+  CreateSeatReservationRequest,
+  ChatHistoryMessage,
+  TableChatMute,
+  LoginResponse,
+  SessionToken
 } from '../types/api';
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080';
@@ -99,6 +117,7 @@ class ApiClient {
 
     try {
       const response = await fetch(url, config);
+      this.trackSession(response);
 
       // Handle different response types
       let data: any;
@@ -144,6 +163,27 @@ class ApiClient {
     }
   }
 
+  /**
+   * Pick up a renewed token and the session expiry hints the server sends
+   * with authenticated responses
+   */
+  private trackSession(response: Response) {
+    const refreshed = response.headers.get('X-Refreshed-Token');
+    if (refreshed) {
+      this.setAuthToken(refreshed);
+    }
+
+    const expiresAt = response.headers.get('X-Session-Expires-At');
+    if (expiresAt && typeof window !== 'undefined') {
+      window.dispatchEvent(new CustomEvent('session-expiry', {
+        detail: {
+          expires_at: expiresAt,
+          session_expires_at: response.headers.get('X-Session-Max-Expires-At') || undefined,
+        },
+      }));
+    }
+  }
+
   // ============= AUTHENTICATION ENDPOINTS =============
 
   /**
@@ -159,8 +199,8 @@ class ApiClient {
   /**
    * Login user
    */
-  async login(credentials: LoginRequest): Promise<{ token: string; user: User }> {
-    const response = await this.request<{ token: string; user: User }>('/api/v1/auth/login', {
+  async login(credentials: LoginRequest): Promise<LoginResponse> {
+    const response = await this.request<LoginResponse>('/api/v1/auth/login', {
       method: 'POST',
       body: JSON.stringify(credentials),
     });
@@ -171,6 +211,17 @@ class ApiClient {
     return response;
   }
 
+  /**
+   * Renew the session's token before it expires from inactivity
+   */
+  async refreshSession(): Promise<SessionToken> {
+    const response = await this.request<SessionToken>('/api/v1/user/session/refresh', {
+      method: 'POST',
+    });
+    this.setAuthToken(response.token);
+    return response;
+  }
+
   /**
    * Logout user
    */
@@ -191,27 +242,24 @@ class ApiClient {
   }
 
   /**
-   * Request password reset
-   * TODO: Implement in backend
+   * Request a password reset link by email. The response is the same
+   * whether or not the address has an account.
    */
   async requestPasswordReset(email: string): Promise<{ message: string }> {
-    throw new Error('Password reset not implemented yet');
-    // return this.request('/api/v1/auth/forgot-password', {
-    //   method: 'POST',
-    //   body: JSON.stringify({ email }),
-    // });
+    return this.request('/api/v1/auth/forgot-password', {
+      method: 'POST',
+      body: JSON.stringify({ email }),
+    });
   }
 
   /**
-   * Reset password with token
-   * TODO: Implement in backend
+   * Reset password with token. Signs the user out on every device.
    */
   async resetPassword(token: string, newPassword: string): Promise<{ message: string }> {
-    throw new Error('Password reset not implemented yet');
-    // return this.request('/api/v1/auth/reset-password', {
-    //   method: 'POST',
-    //   body: JSON.stringify({ token, new_password: newPassword }),
-    // });
+    return this.request('/api/v1/auth/reset-password', {
+      method: 'POST',
+      body: JSON.stringify({ token, new_password: newPassword }),
+    });
   }
 
   // ============= USER MANAGEMENT ENDPOINTS =============
@@ -393,6 +441,99 @@ class ApiClient {
     });
   }
 
+  /**
+   * Get the ledger's liabilities, house funds and net exposure (finance and admin only)
+   */
+  async getTreasury(refresh = false): Promise<TreasurySummary> {
+    return this.request(`/api/v1/admin/treasury${refresh ? '?refresh=true' : ''}`);
+  }
+
+  /**
+   * Get the rake-free windows not yet over (admin only)
+   */
+  async getRakeFreeWindows(includeEnded = false): Promise<{ windows: RakeFreeWindow[] }> {
+    return this.request(`/api/v1/admin/rake-free-windows${includeEnded ? '?include_ended=true' : ''}`);
+  }
+
+  /**
+   * Schedule a rake-free window for a table or stake template (admin only)
+   */
+  async createRakeFreeWindow(data: CreateRakeFreeWindowRequest): Promise<RakeFreeWindow> {
+    return this.request('/api/v1/admin/rake-free-windows', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  /**
+   * Cancel a rake-free window (admin only)
+   */
+  async deleteRakeFreeWindow(windowId: string): Promise<{ message: string }> {
+    return this.request(`/api/v1/admin/rake-free-windows/${windowId}`, {
+      method: 'DELETE',
+    });
+  }
+
+  /**
+   * Get the seats held for named players at a table (admin only)
+   */
+  async getSeatReservations(tableId: string): Promise<{ reservations: SeatReservation[] }> {
+    return this.request(`/api/v1/admin/tables/${tableId}/seat-reservations`);
+  }
+
+  /**
+   * Hold a seat at a table for a named player (admin only)
+   */
+  async createSeatReservation(tableId: string, data: CreateSeatReservationRequest): Promise<SeatReservation> {
+    return this.request(`/api/v1/admin/tables/${tableId}/seat-reservations`, {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  /**
+   * Give a held seat back to everyone (admin only)
+   */
+  async cancelSeatReservation(reservationId: string): Promise<{ message: string }> {
+    return this.request(`/api/v1/admin/seat-reservations/${reservationId}`, {
+      method: 'DELETE',
+    });
+  }
+
+  /**
+   * Get table chat history, newest first (moderator and admin only)
+   */
+  async getChatHistory(params: {
+    table?: string;
+    user_id?: string;
+    status?: ChatHistoryMessage['status'];
+    limit?: number;
+    offset?: number;
+  } = {}): Promise<{ messages: ChatHistoryMessage[]; pagination: { limit: number; offset: number; total: number } }> {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== '') query.set(key, String(value));
+    });
+    const suffix = query.toString() ? `?${query}` : '';
+    return this.request(`/api/v1/admin/chat-history${suffix}`);
+  }
+
+  /**
+   * Get the table chat mutes in force (moderator and admin only)
+   */
+  async getChatMutes(table?: string): Promise<{ mutes: TableChatMute[] }> {
+    return this.request(`/api/v1/admin/chat-mutes${table ? `?table=${encodeURIComponent(table)}` : ''}`);
+  }
+
+  /**
+   * Lift a player's table chat mute (moderator and admin only)
+   */
+  async liftChatMute(muteId: string): Promise<TableChatMute> {
+    return this.request(`/api/v1/admin/chat-mutes/${muteId}`, {
+      method: 'DELETE',
+    });
+  }
+
   // ============= TABLE MANAGEMENT ENDPOINTS =============
 
   /**
@@ -462,6 +603,16 @@ class ApiClient {
     });
   }
 
+  /**
+   * Move to another table, buying in with the chips of the session being left
+   */
+  async moveTable(tableId: string, moveData: MoveTableRequest): Promise<MoveTableResponse> {
+    return this.request(`/api/v1/tables/${tableId}/move`, {
+      method: 'POST',
+      body: JSON.stringify(moveData),
+    });
+  }
+
   /**
    * Leave a poker table
    */
@@ -471,6 +622,97 @@ class ApiClient {
     });
   }
 
+  // ============= MAINTENANCE ENDPOINTS =============
+
+  /**
+   * Get maintenance in force or coming up, for lobby banners
+   */
+  async getMaintenanceNotices(): Promise<{ windows: MaintenanceNotice[] }> {
+    return this.request('/api/v1/maintenance');
+  }
+
+  // ============= SPIN ENDPOINTS =============
+
+  /**
+   * Get the spin formats taking players
+   */
+  async getSpinFormats(): Promise<{ formats: SpinFormat[] }> {
+    return this.request('/api/v1/spins');
+  }
+
+  /**
+   * Queue for the next spin of a format, optionally with a seed of your own
+   * mixed into the prize pool draw
+   */
+  async joinSpin(formatId: string, clientSeed?: string): Promise<SpinJoinResponse> {
+    return this.request(`/api/v1/spins/${formatId}/join`, {
+      method: 'POST',
+      body: JSON.stringify(clientSeed ? { client_seed: clientSeed } : {}),
+    });
+  }
+
+  /**
+   * Leave a spin that hasn't filled, refunding the buy-in
+   */
+  async leaveSpin(formatId: string): Promise<{ message: string }> {
+    return this.request(`/api/v1/spins/${formatId}/leave`, {
+      method: 'POST',
+    });
+  }
+
+  /**
+   * Get a spin's draw proof
+   */
+  async getSpinDraw(tournamentId: string): Promise<SpinProof> {
+    return this.request(`/api/v1/spins/draws/${tournamentId}`);
+  }
+
+  // ============= TOURNAMENT PAYOUT ENDPOINTS =============
+
+  /**
+   * Preview a tournament's payouts from its actual prize pool
+   */
+  async getTournamentPayoutPreview(tournamentId: string): Promise<TournamentPayoutPreview> {
+    return this.request(`/api/v1/tournaments/${tournamentId}/payouts/preview`);
+  }
+
+  // ============= LAST-LONGER ENDPOINTS =============
+
+  /**
+   * Get a tournament's last-longer pools for its lobby
+   */
+  async getLastLongerPools(tournamentId: string): Promise<{ tournament_id: string; pools: LastLongerPool[] }> {
+    return this.request(`/api/v1/tournaments/${tournamentId}/last-longer`);
+  }
+
+  /**
+   * Open a last-longer pool, staking into it from the wallet
+   */
+  async createLastLongerPool(tournamentId: string, pool: CreateLastLongerPoolRequest): Promise<LastLongerPool> {
+    return this.request(`/api/v1/tournaments/${tournamentId}/last-longer`, {
+      method: 'POST',
+      body: JSON.stringify(pool),
+    });
+  }
+
+  /**
+   * Stake into a last-longer pool before the tournament starts
+   */
+  async joinLastLongerPool(tournamentId: string, poolId: string): Promise<LastLongerPool> {
+    return this.request(`/api/v1/tournaments/${tournamentId}/last-longer/${poolId}/join`, {
+      method: 'POST',
+    });
+  }
+
+  /**
+   * Leave a last-longer pool before the tournament starts, refunding the stake
+   */
+  async leaveLastLongerPool(tournamentId: string, poolId: string): Promise<{ message: string; pool_id: string }> {
+    return this.request(`/api/v1/tournaments/${tournamentId}/last-longer/${poolId}/leave`, {
+      method: 'DELETE',
+    });
+  }
+
   // ============= UTILITY METHODS =============
 
   /**
@@ -8,9 +8,37 @@ import { config } from './config';
 
 export interface WebSocketMessage {
   action: string;
+  command_id?: string;
   [key: string]: any;
 }
 
+/**
+ * A protocol 2 message, for clients connecting with ?protocol=2. Replies to
+ * a request (error, warning, success, server-hello, ...) echo its request_id.
+ * This client still speaks the flat protocol 1 WebSocketMessage.
+ */
+export interface WebSocketEnvelope<P = Record<string, any>> {
+  version: 2;
+  action: string;
+  payload: P;
+  request_id?: string;
+}
+
+/**
+ * Generate an ID for an outgoing command. The server drops any frame that
+ * repeats an ID it has already seen on the connection, so a retry can never
+ * apply a bet, chat message or buy-in twice.
+ */
+function newCommandId(): string {
+  if (typeof crypto !== 'undefined' && typeof crypto.randomUUID === 'function') {
+    return crypto.randomUUID();
+  }
+  return `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}`;
+}
+
+/** How often the clock is synced with the server while connected */
+const TIME_SYNC_INTERVAL_MS = 30000;
+
 export interface WebSocketEventHandlers {
   onOpen?: () => void;
   onClose?: () => void;
@@ -27,6 +55,10 @@ class WebSocketManager {
   private eventHandlers: Set<WebSocketEventHandlers> = new Set();
   private messageQueue: WebSocketMessage[] = [];
   private authToken: string | null = null;
+  private timeSyncTimer: ReturnType<typeof setInterval> | null = null;
+  // Server clock less the local clock, from the best time sync so far
+  private clockOffsetMs = 0;
+  private bestSyncRttMs = Infinity;
 
   constructor() {
     // Singleton pattern
@@ -94,6 +126,12 @@ class WebSocketManager {
       this.isConnecting = false;
       this.reconnectAttempts = 0;
 
+      // Line countdowns up with the server's clock
+      this.bestSyncRttMs = Infinity;
+      this.syncClock();
+      if (this.timeSyncTimer) clearInterval(this.timeSyncTimer);
+      this.timeSyncTimer = setInterval(() => this.syncClock(), TIME_SYNC_INTERVAL_MS);
+
       // Process queued messages
       this.processMessageQueue();
 
@@ -108,6 +146,10 @@ class WebSocketManager {
     this.socket.onclose = (event) => {
       console.log('WebSocket disconnected:', event.code, event.reason);
       this.isConnecting = false;
+      if (this.timeSyncTimer) {
+        clearInterval(this.timeSyncTimer);
+        this.timeSyncTimer = null;
+      }
 
       // Notify all registered handlers
       this.eventHandlers.forEach(handler => {
@@ -137,6 +179,9 @@ class WebSocketManager {
     this.socket.onmessage = (event) => {
       try {
         const message: WebSocketMessage = JSON.parse(event.data);
+        if (message.action === 'time-sync-reply') {
+          this.handleTimeSync(message);
+        }
 
         // Notify all registered handlers
         this.eventHandlers.forEach(handler => {
@@ -184,6 +229,11 @@ class WebSocketManager {
    * Send message through WebSocket
    */
   public sendMessage(message: WebSocketMessage): boolean {
+    // Keep the ID across queueing so a retried send stays a duplicate
+    if (!message.command_id) {
+      message = { ...message, command_id: newCommandId() };
+    }
+
     if (!this.isConnected()) {
       console.warn('WebSocket not connected, queueing message:', message);
       this.messageQueue.push(message);
@@ -199,6 +249,37 @@ class WebSocketManager {
     }
   }
 
+  /**
+   * Ask the server for its clock. Not queued: a stale sync is worthless.
+   */
+  private syncClock(): void {
+    if (!this.isConnected()) return;
+    try {
+      this.socket!.send(JSON.stringify({ action: 'time-sync', client_time: Date.now() }));
+    } catch (error) {
+      console.error('Failed to send time sync:', error);
+    }
+  }
+
+  /**
+   * Take the clock offset from a time sync reply. The reply was stamped
+   * about halfway through the round trip, and the quickest round trip so
+   * far gives the tightest offset.
+   */
+  private handleTimeSync(message: WebSocketMessage): void {
+    const rtt = Date.now() - message.client_time;
+    if (rtt < 0 || rtt > this.bestSyncRttMs) return;
+    this.bestSyncRttMs = rtt;
+    this.clockOffsetMs = message.server_time - (message.client_time + rtt / 2);
+  }
+
+  /**
+   * The current time by the server's clock, in milliseconds since the epoch
+   */
+  public serverNow(): number {
+    return Date.now() + this.clockOffsetMs;
+  }
+
   /**
    * Register event handlers
    */
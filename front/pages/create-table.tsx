@@ -144,6 +144,7 @@ function CreateTablePage() {
                   >
                     <option value="cash">Cash Game</option>
                     <option value="tournament">Tournament</option>
+                    <option value="practice">Practice (play chips, bots fill empty seats)</option>
                   </select>
                 </div>
 
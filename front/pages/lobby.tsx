@@ -8,13 +8,15 @@ import Link from 'next/link';
 import { useAuthContext, withAuth } from '../contexts/AuthContext';
 import { formatMNT } from '../lib/api-utils';
 import { apiClient } from '../lib/api-client';
-import { PokerTable } from '../types/api';
+import { MaintenanceNotice, PokerTable } from '../types/api';
 
 function LobbyPage() {
   const { user } = useAuthContext();
   const [tables, setTables] = useState<PokerTable[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [sandbox, setSandbox] = useState(false);
+  const [maintenance, setMaintenance] = useState<MaintenanceNotice[]>([]);
 
   // Fetch tables from backend
   useEffect(() => {
@@ -27,6 +29,7 @@ function LobbyPage() {
           // Remove status filter to see all tables initially
         });
         setTables(response.tables);
+        setSandbox(!!response.sandbox);
       } catch (err) {
         console.error('Failed to fetch tables:', err);
         setError('Failed to load tables. Please try again.');
@@ -38,6 +41,13 @@ function LobbyPage() {
     fetchTables();
   }, []);
 
+  // Banners for scheduled maintenance; the lobby works without them
+  useEffect(() => {
+    apiClient.getMaintenanceNotices()
+      .then((response) => setMaintenance(response.windows))
+      .catch((err) => console.error('Failed to fetch maintenance notices:', err));
+  }, []);
+
   const handleEnterTable = (tableId: string) => {
     // Direct navigation to table view - no buy-in modal needed
     window.location.href = `/game/${tableId}`;
@@ -110,6 +120,23 @@ function LobbyPage() {
             </Link>
           </div>
 
+          {/* Sandbox Notice */}
+          {sandbox && (
+            <div className="mb-6 bg-yellow-100 border border-yellow-400 text-yellow-800 px-4 py-3 rounded">
+              <strong>Sandbox</strong> — balances here are play money and reset when the server restarts.
+            </div>
+          )}
+
+          {/* Maintenance Notices */}
+          {maintenance.map((window) => (
+            <div key={window.id} className="mb-6 bg-orange-100 border border-orange-400 text-orange-800 px-4 py-3 rounded">
+              <strong>{window.status === 'active' ? 'Maintenance in progress' : 'Scheduled maintenance'}: {window.title}</strong>
+              {' — '}
+              {new Date(window.starts_at).toLocaleString()} to {new Date(window.ends_at).toLocaleString()}
+              {window.message && <div className="mt-1 text-sm">{window.message}</div>}
+            </div>
+          ))}
+
           {/* Error Message */}
           {error && (
             <div className="mb-6 bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded">
@@ -130,10 +157,27 @@ function LobbyPage() {
                 {tables.map((table) => {
                   const stakesLevel = getStakesLevel(table.small_blind);
                   return (
-              <div key={table.id} className="bg-white rounded-lg shadow-md overflow-hidden">
+              <div
+                key={table.id}
+                className="bg-white rounded-lg shadow-md overflow-hidden"
+                style={table.branding?.accent_color ? { borderTop: `4px solid ${table.branding.accent_color}` } : undefined}
+              >
+                {table.branding?.banner_url && (
+                  <img src={table.branding.banner_url} alt={table.branding.sponsor_name || ''} className="w-full h-24 object-cover" />
+                )}
                 <div className="p-6">
                   <div className="flex justify-between items-start mb-4">
-                    <h3 className="text-lg font-semibold text-gray-900">{table.name}</h3>
+                    <div>
+                      <h3 className="text-lg font-semibold text-gray-900">{table.name}</h3>
+                      {table.branding?.sponsor_name && (
+                        <p className="text-xs text-gray-500">Sponsored by {table.branding.sponsor_name}</p>
+                      )}
+                      {table.rake_free_until && (
+                        <span className="inline-block mt-1 px-2 py-0.5 rounded-full text-xs font-medium bg-green-100 text-green-800">
+                          Rake-free until {new Date(table.rake_free_until).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}
+                        </span>
+                      )}
+                    </div>
                     <span className={`px-2 py-1 rounded-full text-xs font-medium ${getStakesColor(stakesLevel)}`}>
                       {stakesLevel} Stakes
                     </span>
@@ -167,6 +211,31 @@ function LobbyPage() {
                         {formatMNT(table.min_buy_in)} - {formatMNT(table.max_buy_in)}
                       </span>
                     </div>
+
+                    {table.table_type === 'practice' && (
+                      <div className="flex justify-between">
+                        <span className="text-sm text-gray-600">Practice:</span>
+                        <span className="text-sm font-medium">
+                          Play chips, with bots until players join
+                        </span>
+                      </div>
+                    )}
+
+                    {table.stack_type === 'cap' && table.chip_cap ? (
+                      <div className="flex justify-between">
+                        <span className="text-sm text-gray-600">Cap Table:</span>
+                        <span className="text-sm font-medium">
+                          Max {formatMNT(table.chip_cap)} in play
+                        </span>
+                      </div>
+                    ) : table.stack_type === 'deep' ? (
+                      <div className="flex justify-between">
+                        <span className="text-sm text-gray-600">Deep Stack:</span>
+                        <span className="text-sm font-medium">
+                          Up to {Math.floor(table.max_buy_in / table.big_blind)} BB
+                        </span>
+                      </div>
+                    ) : null}
                   </div>
 
                   <div className="mt-6">
@@ -31,11 +31,42 @@ export interface LoginRequest {
   password: string;
 }
 
-export interface LoginResponse {
+export interface SessionExpiry {
+  expires_at: string; // Token expiry unless the user is active before then
+  session_expires_at?: string; // When the user has to sign in again however active
+}
+
+export interface LoginResponse extends SessionExpiry {
   token: string;
   user: User;
 }
 
+export interface SessionToken extends SessionExpiry {
+  token: string;
+}
+
+export type IdentityProvider = 'google' | 'apple';
+
+export interface OAuthStartResponse {
+  url: string;
+  state: string;
+}
+
+// Returned with 202 instead of a login when the player has no account yet
+export interface OAuthSignup {
+  provider: IdentityProvider;
+  email: string;
+  suggested_username?: string;
+  signup_token: string;
+  expires_at: string;
+}
+
+export interface CompleteOAuthSignupRequest {
+  signup_token: string;
+  username: string;
+  referral_code?: string;
+}
+
 export interface RegisterResponse {
   message: string;
   user_id: string;
@@ -179,10 +210,18 @@ export type RoleRestrictedEndpoint<T> = T & {
 
 // ============= TABLE TYPES =============
 
+// Sponsor or partner branding set by an admin; every field is optional
+export interface Branding {
+  banner_url?: string; // Always https
+  sponsor_name?: string;
+  accent_color?: string; // "#rrggbb"
+  description?: string; // Markdown, render without raw HTML
+}
+
 export interface PokerTable {
   id: string;
   name: string;
-  table_type: 'cash' | 'tournament';
+  table_type: 'cash' | 'tournament' | 'practice';
   game_type: 'texas_holdem' | 'omaha' | 'stud';
   max_players: number;
   min_buy_in: number;
@@ -190,6 +229,19 @@ export interface PokerTable {
   small_blind: number;
   big_blind: number;
   is_private: boolean;
+  stack_type?: 'standard' | 'cap' | 'deep';
+  chip_cap?: number; // Cap tables: chips above this go back to the wallet between hands
+  action_timeout_seconds?: number; // Cash tables: 0 for no action clock
+  sit_out_after_timeouts?: number; // Cash tables: timeouts in a row before sitting out, 0 never
+  sit_out_cash_out_minutes?: number; // Cash tables: cash out after sitting out this long, 0 never
+  rake_percentage?: number; // Cash tables: share of each pot taken as rake, e.g. 0.05
+  rake_cap?: number; // MNT, most rake taken from one hand, 0 for no cap
+  rake_min_pot?: number; // MNT, smaller pots are not raked
+  execution_path?: 'legacy' | 'engine'; // Game that runs the hands from the next time the table opens
+  branding?: Branding;
+  rake_free_until?: string; // Set while a rake-free window covers the table
+  tournament_id?: string; // Tournament tables only
+  tournament_table?: number;
   status: 'waiting' | 'active' | 'full' | 'closed';
   current_players: number;
   created_by: string;
@@ -199,7 +251,7 @@ export interface PokerTable {
 
 export interface CreateTableRequest {
   name: string;
-  table_type: 'cash' | 'tournament';
+  table_type: 'cash' | 'tournament' | 'practice';
   game_type?: 'texas_holdem' | 'omaha' | 'stud';
   max_players?: number;
   min_buy_in: number;
@@ -208,6 +260,11 @@ export interface CreateTableRequest {
   big_blind: number;
   is_private?: boolean;
   password?: string;
+  stack_type?: 'standard' | 'cap' | 'deep';
+  chip_cap?: number;
+  action_timeout_seconds?: number;
+  sit_out_after_timeouts?: number;
+  sit_out_cash_out_minutes?: number;
 }
 
 export interface UpdateTableRequest {
@@ -233,6 +290,18 @@ export interface JoinTableResponse {
   session_id: string;
 }
 
+export interface MoveTableRequest {
+  from_table_id: string;
+  buy_in_amount: number;
+  password?: string;
+}
+
+export interface MoveTableResponse extends JoinTableResponse {
+  from_session_id: string;
+  returned_amount: number;
+  transaction_id: string;
+}
+
 export interface TableListResponse {
   tables: PokerTable[];
   pagination: {
@@ -240,6 +309,255 @@ export interface TableListResponse {
     offset: number;
     total: number;
   };
+  sandbox?: boolean; // Play money from an in-memory ledger
+}
+
+// A player kept out of the private tables of the owner who banned them
+export interface TableBan {
+  id: string;
+  owner_id: string;
+  user_id: string;
+  username: string;
+  reason?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BanTableUserRequest {
+  user_id: string;
+  reason?: string;
+}
+
+export interface TableBanListResponse {
+  bans: TableBan[];
+}
+
+// ============= MAINTENANCE TYPES =============
+
+export type MaintenanceScope = 'deposits' | 'withdrawals' | 'new_tables' | 'all_play';
+
+export interface MaintenanceNotice {
+  id: string;
+  title: string;
+  message?: string;
+  starts_at: string;
+  ends_at: string;
+  scopes: MaintenanceScope[];
+  status: 'scheduled' | 'active';
+}
+
+// ============= SPIN TYPES =============
+
+export interface SpinTier {
+  multiplier: number; // Prize pool in buy-ins
+  weight: number; // Chance of this tier is weight over the total weight
+  payouts: { position: number; percentage: number }[];
+}
+
+export interface SpinFormat {
+  id: string;
+  name: string;
+  buy_in: number;
+  starting_chips: number;
+  tiers: SpinTier[];
+  active: boolean;
+  expected_multiplier: number;
+  house_edge: number; // e.g. 0.05
+  waiting: number; // Players queued for a spin that hasn't filled
+}
+
+// Everything needed to check a spin's prize pool draw. The server seed and
+// client seeds are only given once the spin is drawn.
+export interface SpinProof {
+  tournament_id: string;
+  algorithm: string;
+  server_seed_hash: string;
+  server_seed?: string;
+  client_seed?: string;
+  tiers: SpinTier[];
+  tier?: number;
+  multiplier?: number;
+}
+
+export interface SpinJoinResponse {
+  tournament: { id: string; name: string; status: string; prize_pool: number; registered_players: number };
+  proof: SpinProof;
+  started: boolean;
+}
+
+// A tournament table and who sits in each seat
+export interface TournamentTable {
+  table_number: number;
+  table_id: string;
+  name: string;
+  status: string;
+  seats: Record<number, string>; // Seat number to user ID
+}
+
+// Sent over the WebSocket as "table-move" when a tournament player has to
+// change tables
+export interface TableMove {
+  tournament_id: string;
+  from_table: number;
+  from_seat: number;
+  to_table: number;
+  to_seat: number;
+  table_id?: string;
+  table_name: string; // The table to join
+  reason: 'balance' | 'table_broken' | 'director';
+}
+
+export interface MoveTournamentPlayerRequest {
+  user_id: string;
+  table_number: number;
+  seat_number?: number; // A random free seat when left out
+}
+
+// What each place of a tournament pays from its actual prize pool, and what
+// the players eliminated so far have won
+export interface TournamentPayoutPreview {
+  entrants: number;
+  places: { position: number; percentage: number }[];
+  prize_pool: number; // MNT
+  prizes: number[]; // MNT by place, the rounding remainder to first
+  remaining: number; // Players not yet eliminated
+  locked: boolean;
+  awarded?: TournamentPrize[];
+}
+
+// Players tied for a position split the prizes of the places they cover
+export interface TournamentPrize {
+  user_id: string;
+  position: number;
+  prize: number; // MNT
+}
+
+// A side bet between some of a tournament's entrants, paid to whichever of
+// them lasts longest
+export interface LastLongerPool {
+  id: string;
+  tournament_id: string;
+  name: string;
+  stake: number; // MNT each entrant puts in
+  pot: number; // MNT held for, or paid to, the winner
+  status: 'open' | 'settled' | 'cancelled';
+  created_by: string;
+  winner_id?: string;
+  payout?: number;
+  settled_at?: string;
+  entries: LastLongerEntry[];
+  created_at: string;
+}
+
+export interface LastLongerEntry {
+  id: string;
+  user_id: string;
+  username: string;
+  final_position?: number; // Set once they bust
+  created_at: string;
+}
+
+export interface CreateLastLongerPoolRequest {
+  name: string;
+  stake: number; // MNT
+}
+
+// A promotional window in which hands at a table, or at every table of a
+// stake template, are not raked
+export interface RakeFreeWindow {
+  id: string;
+  title: string;
+  table_id?: string;
+  template_id?: string;
+  starts_at: string;
+  ends_at: string;
+  created_by: string;
+  started_at?: string;
+  ended_at?: string;
+  created_at: string;
+}
+
+export interface CreateRakeFreeWindowRequest {
+  title: string;
+  table_id?: string; // One of table_id or template_id
+  template_id?: string;
+  starts_at: string;
+  duration_minutes: number;
+}
+
+// A seat held at a table for a named player until they sit down or it expires
+export interface SeatReservation {
+  id: string;
+  table_id: string;
+  seat_number: number;
+  user_id: string;
+  username: string;
+  note?: string;
+  expires_at: string;
+  created_by: string;
+  released_at?: string;
+  release_reason?: 'claimed' | 'expired' | 'cancelled';
+  created_at: string;
+}
+
+export interface CreateSeatReservationRequest {
+  user_id: string;
+  seat_number: number; // 1..max_players
+  expires_in_minutes: number;
+  note?: string;
+}
+
+// A table chat message as sent, kept for auditing whether or not it was delivered
+export interface ChatHistoryMessage {
+  id: string;
+  table: string;
+  hand_id?: string;
+  user_id: string;
+  username: string;
+  body: string;
+  status: 'delivered' | 'blocked' | 'muted' | 'rate_limited';
+  created_at: string;
+}
+
+// A player silenced in one table's chat by a moderator
+export interface TableChatMute {
+  id: string;
+  table: string;
+  user_id: string;
+  username: string;
+  muted_by: string;
+  reason?: string;
+  expires_at?: string; // Unset until lifted
+  lifted_at?: string;
+  lifted_by?: string;
+  created_at: string;
+}
+
+// Where the money in one ledger asset sits: owed to players, or the house's own
+export interface TreasuryAsset {
+  asset: string;
+  wallet_liabilities: number;
+  session_funds: number;
+  tournament_escrow: number;
+  last_longer_escrow: number;
+  pending_withdrawals: number;
+  liabilities: number;
+  house: number;
+  spin_jackpot: number;
+  revenue: Record<string, number>; // By revenue account
+  revenue_total: number;
+  house_funds: number;
+  other: number;
+  world: number;
+  net_exposure: number; // Liabilities less house funds
+}
+
+export interface TreasurySummary {
+  assets: TreasuryAsset[];
+  accounts: number;
+  deposits_in_review: number; // MNT received but not yet credited
+  deposits_in_review_count: number;
+  generated_at: string;
 }
 
 // ============= WEBSOCKET GAME TYPES (extending existing) =============